@@ -0,0 +1,131 @@
+// Copyright 2022 Canonical Ltd.
+// Licensed under the LGPLv3, see LICENCE file for details.
+
+package gomaasapi
+
+import (
+	"net/http"
+	"strings"
+
+	"github.com/juju/errors"
+	"github.com/juju/schema"
+)
+
+// AuthorisationToken is an OAuth token that authenticates a user with the
+// MAAS API.
+type AuthorisationToken struct {
+	Name        string
+	ConsumerKey string
+	TokenKey    string
+	TokenSecret string
+}
+
+// APIKey returns the token in the form expected by ControllerArgs.APIKey
+// and Controller.WithAPIKey.
+func (t AuthorisationToken) APIKey() string {
+	return t.ConsumerKey + ":" + t.TokenKey + ":" + t.TokenSecret
+}
+
+// CreateAuthorisationToken implements Controller.
+func (c *controller) CreateAuthorisationToken(name string) (AuthorisationToken, error) {
+	params := NewURLParams()
+	params.MaybeAdd("name", name)
+	source, err := c.post("account", "create_authorisation_token", params.Values)
+	if err != nil {
+		return AuthorisationToken{}, NewUnexpectedError(err)
+	}
+	token, err := readAuthorisationToken(source)
+	if err != nil {
+		return AuthorisationToken{}, errors.Trace(err)
+	}
+	return token, nil
+}
+
+// AuthorisationTokens implements Controller.
+func (c *controller) AuthorisationTokens() ([]AuthorisationToken, error) {
+	source, err := c.getOp("account", "list_authorisation_tokens")
+	if err != nil {
+		return nil, NewUnexpectedError(err)
+	}
+	tokens, err := readAuthorisationTokens(source)
+	if err != nil {
+		return nil, errors.Trace(err)
+	}
+	return tokens, nil
+}
+
+// DeleteAuthorisationToken implements Controller.
+func (c *controller) DeleteAuthorisationToken(tokenKey string) error {
+	if tokenKey == "" {
+		return errors.NotValidf("missing token key")
+	}
+	params := NewURLParams()
+	params.Values.Add("token_key", tokenKey)
+	_, err := c._postRaw("account", "delete_authorisation_token", params.Values, nil)
+	if err != nil {
+		if svrErr, ok := errors.Cause(err).(ServerError); ok {
+			if svrErr.StatusCode == http.StatusNotFound {
+				return wrapError(err, NewNoMatchError(svrErr.BodyMessage))
+			}
+		}
+		return NewUnexpectedError(err)
+	}
+	return nil
+}
+
+func readAuthorisationToken(source interface{}) (AuthorisationToken, error) {
+	fields := schema.Fields{
+		"name":         schema.OneOf(schema.Nil(""), schema.String()),
+		"consumer_key": schema.String(),
+		"token_key":    schema.String(),
+		"token_secret": schema.String(),
+	}
+	defaults := schema.Defaults{
+		"name": "",
+	}
+	checker := schema.FieldMap(fields, defaults)
+	coerced, err := checker.Coerce(source, nil)
+	if err != nil {
+		return AuthorisationToken{}, WrapWithDeserializationError(err, "authorisation token schema check failed")
+	}
+	valid := coerced.(map[string]interface{})
+	name, _ := valid["name"].(string)
+	return AuthorisationToken{
+		Name:        name,
+		ConsumerKey: valid["consumer_key"].(string),
+		TokenKey:    valid["token_key"].(string),
+		TokenSecret: valid["token_secret"].(string),
+	}, nil
+}
+
+// readAuthorisationTokens reads the listed tokens, which MAAS returns with
+// the keys and the secret joined as in an API key.
+func readAuthorisationTokens(source interface{}) ([]AuthorisationToken, error) {
+	checker := schema.List(schema.FieldMap(
+		schema.Fields{
+			"name":  schema.OneOf(schema.Nil(""), schema.String()),
+			"token": schema.String(),
+		},
+		schema.Defaults{"name": ""},
+	))
+	coerced, err := checker.Coerce(source, nil)
+	if err != nil {
+		return nil, WrapWithDeserializationError(err, "authorisation token schema check failed")
+	}
+	var result []AuthorisationToken
+	for i, value := range coerced.([]interface{}) {
+		valid := value.(map[string]interface{})
+		parts := strings.Split(valid["token"].(string), ":")
+		if len(parts) != 3 {
+			return nil, NewDeserializationError("authorisation token %d: unexpected token format", i)
+		}
+		name, _ := valid["name"].(string)
+		result = append(result, AuthorisationToken{
+			Name:        name,
+			ConsumerKey: parts[0],
+			TokenKey:    parts[1],
+			TokenSecret: parts[2],
+		})
+	}
+	return result, nil
+}
@@ -0,0 +1,97 @@
+// Copyright 2022 Canonical Ltd.
+// Licensed under the LGPLv3, see LICENCE file for details.
+
+package gomaasapi
+
+import (
+	"net/http"
+
+	"github.com/juju/errors"
+	"github.com/juju/testing"
+	jc "github.com/juju/testing/checkers"
+	gc "gopkg.in/check.v1"
+)
+
+type accountSuite struct {
+	testing.LoggingCleanupSuite
+}
+
+var _ = gc.Suite(&accountSuite{})
+
+func (*accountSuite) TestReadAuthorisationTokensBadSchema(c *gc.C) {
+	_, err := readAuthorisationTokens("wat?")
+	c.Check(err, jc.Satisfies, IsDeserializationError)
+	c.Assert(err.Error(), gc.Equals, `authorisation token schema check failed: expected list, got string("wat?")`)
+}
+
+func (*accountSuite) TestReadAuthorisationTokensBadToken(c *gc.C) {
+	_, err := readAuthorisationTokens(parseJSON(c, `[{"name": "ci", "token": "abc"}]`))
+	c.Check(err, jc.Satisfies, IsDeserializationError)
+	c.Assert(err.Error(), gc.Equals, "authorisation token 0: unexpected token format")
+}
+
+func (s *accountSuite) TestCreateAuthorisationToken(c *gc.C) {
+	server, controller := createTestServerController(c, s)
+	server.AddPostResponse("/api/2.0/account/?op=create_authorisation_token", http.StatusOK, `
+{
+    "token_key": "tk",
+    "token_secret": "ts",
+    "consumer_key": "ck",
+    "name": "ci"
+}`)
+	token, err := controller.CreateAuthorisationToken("ci")
+	c.Assert(err, jc.ErrorIsNil)
+	c.Check(token, jc.DeepEquals, AuthorisationToken{
+		Name:        "ci",
+		ConsumerKey: "ck",
+		TokenKey:    "tk",
+		TokenSecret: "ts",
+	})
+	c.Check(token.APIKey(), gc.Equals, "ck:tk:ts")
+	c.Check(server.LastRequest().PostForm.Get("name"), gc.Equals, "ci")
+
+	_, err = controller.WithAPIKey(token.APIKey())
+	c.Assert(err, jc.ErrorIsNil)
+}
+
+func (s *accountSuite) TestAuthorisationTokens(c *gc.C) {
+	server, controller := createTestServerController(c, s)
+	server.AddGetResponse("/api/2.0/account/?op=list_authorisation_tokens", http.StatusOK, `
+[
+    {"name": "ci", "token": "ck:tk:ts"},
+    {"name": null, "token": "ck2:tk2:ts2"}
+]`)
+	tokens, err := controller.AuthorisationTokens()
+	c.Assert(err, jc.ErrorIsNil)
+	c.Assert(tokens, jc.DeepEquals, []AuthorisationToken{{
+		Name:        "ci",
+		ConsumerKey: "ck",
+		TokenKey:    "tk",
+		TokenSecret: "ts",
+	}, {
+		ConsumerKey: "ck2",
+		TokenKey:    "tk2",
+		TokenSecret: "ts2",
+	}})
+}
+
+func (s *accountSuite) TestDeleteAuthorisationToken(c *gc.C) {
+	server, controller := createTestServerController(c, s)
+	server.AddPostResponse("/api/2.0/account/?op=delete_authorisation_token", http.StatusNoContent, "")
+	err := controller.DeleteAuthorisationToken("tk")
+	c.Assert(err, jc.ErrorIsNil)
+	c.Assert(server.LastRequest().PostForm.Get("token_key"), gc.Equals, "tk")
+}
+
+func (s *accountSuite) TestDeleteAuthorisationTokenMissing(c *gc.C) {
+	_, controller := createTestServerController(c, s)
+	err := controller.DeleteAuthorisationToken("")
+	c.Assert(err, jc.Satisfies, errors.IsNotValid)
+}
+
+func (s *accountSuite) TestDeleteAuthorisationTokenNotFound(c *gc.C) {
+	server, controller := createTestServerController(c, s)
+	server.AddPostResponse("/api/2.0/account/?op=delete_authorisation_token", http.StatusNotFound, "no such token")
+	err := controller.DeleteAuthorisationToken("tk")
+	c.Assert(err, jc.Satisfies, IsNoMatchError)
+}
@@ -4,18 +4,25 @@
 package gomaasapi
 
 import (
+	"fmt"
+	"net/url"
+	"strings"
+
 	"github.com/juju/errors"
 	"github.com/juju/schema"
 	"github.com/juju/version"
 )
 
 type blockdevice struct {
+	controller *controller
+
 	resourceURI string
 
 	id      int
 	uuid    string
 	name    string
 	model   string
+	serial  string
 	idPath  string
 	path    string
 	usedFor string
@@ -27,6 +34,34 @@ type blockdevice struct {
 
 	filesystem *filesystem
 	partitions []*partition
+
+	// parent is only set for virtual block devices.
+	parent *BlockDeviceParent
+}
+
+// BlockDeviceParent identifies the RAID array, LVM volume group or bcache
+// set that backs a virtual block device.
+type BlockDeviceParent struct {
+	ID   int
+	UUID string
+	// Type is the MAAS filesystem group type, such as "raid-1", "lvm-vg"
+	// or "bcache".
+	Type string
+}
+
+// IsRAID returns true if the parent is a RAID array.
+func (p BlockDeviceParent) IsRAID() bool {
+	return strings.HasPrefix(p.Type, "raid-")
+}
+
+// IsVolumeGroup returns true if the parent is an LVM volume group.
+func (p BlockDeviceParent) IsVolumeGroup() bool {
+	return p.Type == "lvm-vg"
+}
+
+// IsBcache returns true if the parent is a bcache set.
+func (p BlockDeviceParent) IsBcache() bool {
+	return p.Type == "bcache"
 }
 
 // Type implements BlockDevice
@@ -54,6 +89,11 @@ func (b *blockdevice) Model() string {
 	return b.model
 }
 
+// Serial implements BlockDevice.
+func (b *blockdevice) Serial() string {
+	return b.serial
+}
+
 // IDPath implements BlockDevice.
 func (b *blockdevice) IDPath() string {
 	return b.idPath
@@ -91,26 +131,197 @@ func (b *blockdevice) Size() uint64 {
 
 // FileSystem implements BlockDevice.
 func (b *blockdevice) FileSystem() FileSystem {
+	if b.filesystem == nil {
+		return nil
+	}
 	return b.filesystem
 }
 
+// Parent implements BlockDevice.
+func (b *blockdevice) Parent() *BlockDeviceParent {
+	return b.parent
+}
+
 // Partitions implements BlockDevice.
 func (b *blockdevice) Partitions() []Partition {
 	result := make([]Partition, len(b.partitions))
 	for i, v := range b.partitions {
+		v.controller = b.controller
 		result[i] = v
 	}
 	return result
 }
 
+// CreatePartitionArgs is an argument struct for BlockDevice.CreatePartition.
+// If Size is zero, the partition uses all the remaining space on the block
+// device. UUID is optional, MAAS generates one if it isn't specified.
+type CreatePartitionArgs struct {
+	Size     uint64
+	UUID     string
+	Bootable bool
+}
+
+// CreatePartition implements BlockDevice.
+func (b *blockdevice) CreatePartition(args CreatePartitionArgs) (Partition, error) {
+	params := NewURLParams()
+	if args.Size != 0 {
+		params.Values.Add("size", fmt.Sprint(args.Size))
+	}
+	params.MaybeAdd("uuid", args.UUID)
+	params.MaybeAddBool("bootable", args.Bootable)
+	source, err := b.controller.post(b.resourceURI+"partitions/", "", params.Values)
+	if err != nil {
+		return nil, errors.Trace(storageDeviceError(err))
+	}
+
+	response, err := readPartition(b.controller.schemaVersion(), source)
+	if err != nil {
+		return nil, errors.Trace(err)
+	}
+	response.controller = b.controller
+	b.partitions = append(b.partitions, response)
+	return response, nil
+}
+
+// UpdateBlockDeviceArgs is an argument struct for BlockDevice.Update. Only
+// the fields that are set are changed. MAAS only allows the Name, UUID and
+// Size of virtual block devices to be changed.
+type UpdateBlockDeviceArgs struct {
+	Name      string
+	UUID      string
+	Model     string
+	Serial    string
+	IDPath    string
+	Size      uint64
+	BlockSize uint64
+}
+
+// Update implements BlockDevice.
+func (b *blockdevice) Update(args UpdateBlockDeviceArgs) error {
+	params := NewURLParams()
+	params.MaybeAdd("name", args.Name)
+	params.MaybeAdd("uuid", args.UUID)
+	params.MaybeAdd("model", args.Model)
+	params.MaybeAdd("serial", args.Serial)
+	params.MaybeAdd("id_path", args.IDPath)
+	if args.Size != 0 {
+		params.Values.Add("size", fmt.Sprint(args.Size))
+	}
+	if args.BlockSize != 0 {
+		params.Values.Add("block_size", fmt.Sprint(args.BlockSize))
+	}
+	source, err := b.controller.put(b.resourceURI, params.Values)
+	if err != nil {
+		return errors.Trace(storageDeviceError(err))
+	}
+
+	response, err := readBlockDevice(b.controller.schemaVersion(), source)
+	if err != nil {
+		return errors.Trace(err)
+	}
+	b.updateFrom(response)
+	return nil
+}
+
+// Delete implements BlockDevice.
+func (b *blockdevice) Delete() error {
+	err := b.controller.delete(b.resourceURI)
+	if err != nil {
+		return errors.Trace(storageDeviceError(err))
+	}
+	return nil
+}
+
+// Format implements StorageDevice.
+func (b *blockdevice) Format(args FormatStorageDeviceArgs) error {
+	if err := args.Validate(); err != nil {
+		return errors.Trace(err)
+	}
+	return b.performOp("format", args.params())
+}
+
+// Unformat implements StorageDevice.
+func (b *blockdevice) Unformat() error {
+	return b.performOp("unformat", nil)
+}
+
+// Mount implements StorageDevice.
+func (b *blockdevice) Mount(args MountStorageDeviceArgs) error {
+	if err := args.Validate(); err != nil {
+		return errors.Trace(err)
+	}
+	return b.performOp("mount", args.params())
+}
+
+// Unmount implements StorageDevice.
+func (b *blockdevice) Unmount() error {
+	return b.performOp("unmount", nil)
+}
+
+func (b *blockdevice) performOp(op string, params url.Values) error {
+	source, err := b.controller.post(b.resourceURI, op, params)
+	if err != nil {
+		return errors.Trace(storageDeviceError(err))
+	}
+
+	response, err := readBlockDevice(b.controller.schemaVersion(), source)
+	if err != nil {
+		return errors.Trace(err)
+	}
+	b.updateFrom(response)
+	return nil
+}
+
+func (b *blockdevice) updateFrom(other *blockdevice) {
+	b.resourceURI = other.resourceURI
+	b.id = other.id
+	b.uuid = other.uuid
+	b.name = other.name
+	b.model = other.model
+	b.serial = other.serial
+	b.idPath = other.idPath
+	b.path = other.path
+	b.usedFor = other.usedFor
+	b.tags = other.tags
+	b.blockSize = other.blockSize
+	b.usedSize = other.usedSize
+	b.size = other.size
+	b.filesystem = other.filesystem
+	b.partitions = other.partitions
+	b.parent = other.parent
+}
+
+func readBlockDevice(controllerVersion version.Number, source interface{}) (*blockdevice, error) {
+	readFunc, err := getBlockDeviceDeserializationFunc(controllerVersion)
+	if err != nil {
+		return nil, errors.Trace(err)
+	}
+
+	checker := schema.StringMap(schema.Any())
+	coerced, err := checker.Coerce(source, nil)
+	if err != nil {
+		return nil, WrapWithDeserializationError(err, "blockdevice base schema check failed")
+	}
+	valid := coerced.(map[string]interface{})
+	return readFunc(valid)
+}
+
 func readBlockDevices(controllerVersion version.Number, source interface{}) ([]*blockdevice, error) {
+	readFunc, err := getBlockDeviceDeserializationFunc(controllerVersion)
+	if err != nil {
+		return nil, errors.Trace(err)
+	}
+
 	checker := schema.List(schema.StringMap(schema.Any()))
 	coerced, err := checker.Coerce(source, nil)
 	if err != nil {
 		return nil, WrapWithDeserializationError(err, "blockdevice base schema check failed")
 	}
 	valid := coerced.([]interface{})
+	return readBlockDeviceList(valid, readFunc)
+}
 
+func getBlockDeviceDeserializationFunc(controllerVersion version.Number) (blockdeviceDeserializationFunc, error) {
 	var deserialisationVersion version.Number
 	for v := range blockdeviceDeserializationFuncs {
 		if v.Compare(deserialisationVersion) > 0 && v.Compare(controllerVersion) <= 0 {
@@ -120,8 +331,7 @@ func readBlockDevices(controllerVersion version.Number, source interface{}) ([]*
 	if deserialisationVersion == version.Zero {
 		return nil, NewUnsupportedVersionError("no blockdevice read func for version %s", controllerVersion)
 	}
-	readFunc := blockdeviceDeserializationFuncs[deserialisationVersion]
-	return readBlockDeviceList(valid, readFunc)
+	return blockdeviceDeserializationFuncs[deserialisationVersion], nil
 }
 
 // readBlockDeviceList expects the values of the sourceList to be string maps.
@@ -155,6 +365,7 @@ func blockdevice_2_0(source map[string]interface{}) (*blockdevice, error) {
 		"uuid":     schema.OneOf(schema.Nil(""), schema.String()),
 		"name":     schema.String(),
 		"model":    schema.OneOf(schema.Nil(""), schema.String()),
+		"serial":   schema.OneOf(schema.Nil(""), schema.String()),
 		"id_path":  schema.OneOf(schema.Nil(""), schema.String()),
 		"path":     schema.String(),
 		"used_for": schema.String(),
@@ -166,8 +377,23 @@ func blockdevice_2_0(source map[string]interface{}) (*blockdevice, error) {
 
 		"filesystem": schema.OneOf(schema.Nil(""), schema.StringMap(schema.Any())),
 		"partitions": schema.List(schema.StringMap(schema.Any())),
+
+		"parent": schema.OneOf(schema.Nil(""), schema.FieldMap(
+			schema.Fields{
+				"id":   schema.ForceInt(),
+				"uuid": schema.OneOf(schema.Nil(""), schema.String()),
+				"type": schema.String(),
+			},
+			schema.Defaults{
+				"uuid": "",
+			},
+		)),
+	}
+	defaults := schema.Defaults{
+		"serial": "",
+		"parent": nil,
 	}
-	checker := schema.FieldMap(fields, nil)
+	checker := schema.FieldMap(fields, defaults)
 	coerced, err := checker.Coerce(source, nil)
 	if err != nil {
 		return nil, WrapWithDeserializationError(err, "blockdevice 2.0 schema check failed")
@@ -187,8 +413,19 @@ func blockdevice_2_0(source map[string]interface{}) (*blockdevice, error) {
 		return nil, errors.Trace(err)
 	}
 
+	var parent *BlockDeviceParent
+	if parentMap, ok := valid["parent"].(map[string]interface{}); ok {
+		parentUUID, _ := parentMap["uuid"].(string)
+		parent = &BlockDeviceParent{
+			ID:   parentMap["id"].(int),
+			UUID: parentUUID,
+			Type: parentMap["type"].(string),
+		}
+	}
+
 	uuid, _ := valid["uuid"].(string)
 	model, _ := valid["model"].(string)
+	serial, _ := valid["serial"].(string)
 	idPath, _ := valid["id_path"].(string)
 	result := &blockdevice{
 		resourceURI: valid["resource_uri"].(string),
@@ -197,6 +434,7 @@ func blockdevice_2_0(source map[string]interface{}) (*blockdevice, error) {
 		uuid:    uuid,
 		name:    valid["name"].(string),
 		model:   model,
+		serial:  serial,
 		idPath:  idPath,
 		path:    valid["path"].(string),
 		usedFor: valid["used_for"].(string),
@@ -208,6 +446,7 @@ func blockdevice_2_0(source map[string]interface{}) (*blockdevice, error) {
 
 		filesystem: filesystem,
 		partitions: partitions,
+		parent:     parent,
 	}
 	return result, nil
 }
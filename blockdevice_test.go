@@ -28,6 +28,7 @@ func (*blockdeviceSuite) TestReadBlockDevices(c *gc.C) {
 	c.Check(blockdevice.ID(), gc.Equals, 34)
 	c.Check(blockdevice.Name(), gc.Equals, "sda")
 	c.Check(blockdevice.Model(), gc.Equals, "QEMU HARDDISK")
+	c.Check(blockdevice.Serial(), gc.Equals, "QM00001")
 	c.Check(blockdevice.Path(), gc.Equals, "/dev/disk/by-dname/sda")
 	c.Check(blockdevice.IDPath(), gc.Equals, "/dev/disk/by-id/ata-QEMU_HARDDISK_QM00001")
 	c.Check(blockdevice.UUID(), gc.Equals, "6199b7c9-b66f-40f6-a238-a938a58a0adf")
@@ -49,6 +50,28 @@ func (*blockdeviceSuite) TestReadBlockDevices(c *gc.C) {
 	c.Assert(fs.MountPoint(), gc.Equals, "/srv")
 }
 
+func (*blockdeviceSuite) TestReadBlockDeviceUnpartitionedFileSystem(c *gc.C) {
+	json := updateJSONMap(c, blockdeviceResponse, map[string]interface{}{
+		"partitions": []interface{}{},
+		"filesystem": map[string]interface{}{
+			"fstype":        "xfs",
+			"mount_point":   "/srv/data",
+			"mount_options": "noatime",
+			"label":         "data",
+			"uuid":          "0c0f2a3e-8f4d-4b8e-a0b1-9b1f3e3f1d11",
+		},
+	})
+	blockdevice, err := readBlockDevice(twoDotOh, parseJSON(c, json))
+	c.Assert(err, jc.ErrorIsNil)
+	c.Check(blockdevice.Partitions(), gc.HasLen, 0)
+	fs := blockdevice.FileSystem()
+	c.Assert(fs, gc.NotNil)
+	c.Check(FileSystemType(fs.Type()), gc.Equals, FileSystemXFS)
+	c.Check(fs.MountPoint(), gc.Equals, "/srv/data")
+	c.Check(fs.MountOptions(), gc.Equals, "noatime")
+	c.Check(fs.UUID(), gc.Equals, "0c0f2a3e-8f4d-4b8e-a0b1-9b1f3e3f1d11")
+}
+
 func (*blockdeviceSuite) TestReadBlockDevicesWithNulls(c *gc.C) {
 	blockdevices, err := readBlockDevices(twoDotOh, parseJSON(c, blockdevicesWithNullsResponse))
 	c.Assert(err, jc.ErrorIsNil)
@@ -56,10 +79,45 @@ func (*blockdeviceSuite) TestReadBlockDevicesWithNulls(c *gc.C) {
 	blockdevice := blockdevices[0]
 
 	c.Check(blockdevice.Model(), gc.Equals, "")
+	c.Check(blockdevice.Serial(), gc.Equals, "")
 	c.Check(blockdevice.IDPath(), gc.Equals, "")
 	c.Check(blockdevice.FileSystem(), gc.IsNil)
 }
 
+func (*blockdeviceSuite) TestReadBlockDeviceParent(c *gc.C) {
+	json := updateJSONMap(c, blockdeviceResponse, map[string]interface{}{
+		"parent": map[string]interface{}{"id": 12, "uuid": nil, "type": "lvm-vg"},
+	})
+	blockdevice, err := readBlockDevice(twoDotOh, parseJSON(c, json))
+	c.Assert(err, jc.ErrorIsNil)
+	c.Assert(blockdevice.Parent(), jc.DeepEquals, &BlockDeviceParent{ID: 12, Type: "lvm-vg"})
+
+	json = updateJSONMap(c, blockdeviceResponse, map[string]interface{}{"parent": nil})
+	blockdevice, err = readBlockDevice(twoDotOh, parseJSON(c, json))
+	c.Assert(err, jc.ErrorIsNil)
+	c.Assert(blockdevice.Parent(), gc.IsNil)
+}
+
+func (*blockdeviceSuite) TestBlockDeviceParentType(c *gc.C) {
+	for _, test := range []struct {
+		parentType  string
+		raid        bool
+		volumeGroup bool
+		bcache      bool
+	}{
+		{parentType: "raid-1", raid: true},
+		{parentType: "raid-10", raid: true},
+		{parentType: "lvm-vg", volumeGroup: true},
+		{parentType: "bcache", bcache: true},
+		{parentType: "vmfs6"},
+	} {
+		parent := BlockDeviceParent{Type: test.parentType}
+		c.Check(parent.IsRAID(), gc.Equals, test.raid)
+		c.Check(parent.IsVolumeGroup(), gc.Equals, test.volumeGroup)
+		c.Check(parent.IsBcache(), gc.Equals, test.bcache)
+	}
+}
+
 func (*blockdeviceSuite) TestLowVersion(c *gc.C) {
 	_, err := readBlockDevices(version.MustParse("1.9.0"), parseJSON(c, blockdevicesResponse))
 	c.Assert(err, jc.Satisfies, IsUnsupportedVersionError)
@@ -71,57 +129,57 @@ func (*blockdeviceSuite) TestHighVersion(c *gc.C) {
 	c.Assert(blockdevices, gc.HasLen, 1)
 }
 
-var blockdevicesResponse = `
-[
-    {
-        "path": "/dev/disk/by-dname/sda",
-        "name": "sda",
-        "used_for": "MBR partitioned with 1 partition",
-        "partitions": [
-            {
-                "bootable": false,
-                "id": 1,
-                "path": "/dev/disk/by-dname/sda-part1",
-                "filesystem": {
-                    "fstype": "ext4",
-                    "mount_point": "/",
-                    "label": "root",
-                    "mount_options": null,
-                    "uuid": "fcd7745e-f1b5-4f5d-9575-9b0bb796b752"
-                },
-                "type": "partition",
-                "resource_uri": "/MAAS/api/2.0/nodes/4y3ha3/blockdevices/34/partition/1",
-                "uuid": "6199b7c9-b66f-40f6-a238-a938a58a0adf",
-                "used_for": "ext4 formatted filesystem mounted at /",
-                "size": 8581545984
-            }
-        ],
-        "filesystem": {
-            "fstype": "ext4",
-            "mount_point": "/srv",
-            "label": "root",
-            "mount_options": null,
-            "uuid": "fcd7745e-f1b5-4f5d-9575-9b0bb796b752"
-        },
-        "id_path": "/dev/disk/by-id/ata-QEMU_HARDDISK_QM00001",
-        "resource_uri": "/MAAS/api/2.0/nodes/4y3ha3/blockdevices/34/",
-        "id": 34,
-        "serial": "QM00001",
-        "type": "physical",
-        "block_size": 4096,
-        "used_size": 8586788864,
-        "available_size": 0,
-        "partition_table_type": "MBR",
-        "uuid": "6199b7c9-b66f-40f6-a238-a938a58a0adf",
-        "size": 8589934592,
-        "model": "QEMU HARDDISK",
-        "tags": [
-            "rotary"
-        ]
-    }
-]
+var blockdeviceResponse = `
+{
+    "path": "/dev/disk/by-dname/sda",
+    "name": "sda",
+    "used_for": "MBR partitioned with 1 partition",
+    "partitions": [
+        {
+            "bootable": false,
+            "id": 1,
+            "path": "/dev/disk/by-dname/sda-part1",
+            "filesystem": {
+                "fstype": "ext4",
+                "mount_point": "/",
+                "label": "root",
+                "mount_options": null,
+                "uuid": "fcd7745e-f1b5-4f5d-9575-9b0bb796b752"
+            },
+            "type": "partition",
+            "resource_uri": "/MAAS/api/2.0/nodes/4y3ha3/blockdevices/34/partition/1",
+            "uuid": "6199b7c9-b66f-40f6-a238-a938a58a0adf",
+            "used_for": "ext4 formatted filesystem mounted at /",
+            "size": 8581545984
+        }
+    ],
+    "filesystem": {
+        "fstype": "ext4",
+        "mount_point": "/srv",
+        "label": "root",
+        "mount_options": null,
+        "uuid": "fcd7745e-f1b5-4f5d-9575-9b0bb796b752"
+    },
+    "id_path": "/dev/disk/by-id/ata-QEMU_HARDDISK_QM00001",
+    "resource_uri": "/MAAS/api/2.0/nodes/4y3ha3/blockdevices/34/",
+    "id": 34,
+    "serial": "QM00001",
+    "type": "physical",
+    "block_size": 4096,
+    "used_size": 8586788864,
+    "available_size": 0,
+    "partition_table_type": "MBR",
+    "uuid": "6199b7c9-b66f-40f6-a238-a938a58a0adf",
+    "size": 8589934592,
+    "model": "QEMU HARDDISK",
+    "tags": [
+        "rotary"
+    ]
+}
 `
 
+var blockdevicesResponse = "[" + blockdeviceResponse + "]"
+
 var blockdevicesWithNullsResponse = `
 [
     {
@@ -0,0 +1,102 @@
+// Copyright 2022 Canonical Ltd.
+// Licensed under the LGPLv3, see LICENCE file for details.
+
+package gomaasapi
+
+import (
+	"fmt"
+	"strings"
+
+	"github.com/juju/collections/set"
+	"github.com/juju/errors"
+)
+
+// BootOrderWarning reports a machine whose firmware is unlikely to boot from
+// the network first. MAAS can only redeploy, commission or release machines
+// that network boot, so these machines tend to fail their next operation.
+type BootOrderWarning struct {
+	Machine Machine
+	// Reasons describe the evidence that the machine won't network boot.
+	Reasons []string
+}
+
+// netbootStatuses are the statuses that MAAS leaves machines in when an
+// operation that needs a network boot doesn't complete.
+var netbootStatuses = set.NewStrings(
+	"Failed commissioning",
+	"Failed testing",
+	"Failed deployment",
+	"Failed disk erasing",
+)
+
+// bootTypePowerTypes are the power types that choose the boot method of the
+// next boot with the power_boot_type parameter.
+var bootTypePowerTypes = set.NewStrings("ipmi")
+
+// CheckBootOrder implements Controller.
+func (c *controller) CheckBootOrder(args MachinesArgs) ([]BootOrderWarning, error) {
+	machines, err := c.Machines(args)
+	if err != nil {
+		return nil, errors.Trace(err)
+	}
+	var result []BootOrderWarning
+	for _, m := range machines {
+		var powerParams map[string]interface{}
+		if m.BIOSBootMethod() != "" && bootTypePowerTypes.Contains(m.PowerType()) {
+			powerParams, err = m.PowerParameters()
+			if IsPermissionError(err) {
+				// Only administrators can read the power parameters, so
+				// the check is skipped for everyone else.
+				powerParams = nil
+			} else if err != nil {
+				return nil, errors.Annotatef(err, "machine %s", m.SystemID())
+			}
+		}
+		if reasons := bootOrderReasons(m, powerParams); len(reasons) > 0 {
+			result = append(result, BootOrderWarning{Machine: m, Reasons: reasons})
+		}
+	}
+	return result, nil
+}
+
+// bootOrderReasons returns the reasons to believe that the machine won't
+// network boot. The power parameters are nil if they aren't known.
+func bootOrderReasons(m Machine, powerParams map[string]interface{}) []string {
+	var reasons []string
+	status := m.StatusName()
+	if netbootStatuses.Contains(status) && strings.Contains(m.StatusMessage(), "timed out") {
+		// MAAS times out operations when the machine doesn't boot from the
+		// network and so never reports back.
+		reasons = append(reasons, fmt.Sprintf("%s after a timeout: %s", strings.ToLower(status), m.StatusMessage()))
+	}
+	if m.BIOSBootMethod() == "" {
+		switch status {
+		case "New", "Commissioning", "Failed commissioning":
+		default:
+			reasons = append(reasons, "MAAS hasn't seen the machine boot from the network")
+		}
+	}
+	if m.PowerType() == "manual" {
+		reasons = append(reasons, "manual power control can't select a network boot")
+	}
+	if bootType, _ := powerParams["power_boot_type"].(string); bootType != "" {
+		if want := expectedPowerBootType(m.BIOSBootMethod()); want != "" && bootType != "auto" && bootType != want {
+			reasons = append(reasons, fmt.Sprintf(
+				"power driver requests a %s boot but the machine boots with %s",
+				bootType, m.BIOSBootMethod()))
+		}
+	}
+	return reasons
+}
+
+// expectedPowerBootType returns the power_boot_type that matches the boot
+// method of the firmware, or the empty string if any type will do.
+func expectedPowerBootType(biosBootMethod string) string {
+	switch biosBootMethod {
+	case "pxe":
+		return "legacy"
+	case "uefi":
+		return "efi"
+	}
+	return ""
+}
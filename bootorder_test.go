@@ -0,0 +1,126 @@
+// Copyright 2022 Canonical Ltd.
+// Licensed under the LGPLv3, see LICENCE file for details.
+
+package gomaasapi
+
+import (
+	"net/http"
+
+	"github.com/juju/testing"
+	jc "github.com/juju/testing/checkers"
+	gc "gopkg.in/check.v1"
+)
+
+type bootOrderSuite struct {
+	testing.LoggingCleanupSuite
+}
+
+var _ = gc.Suite(&bootOrderSuite{})
+
+func (*bootOrderSuite) TestBootOrderReasons(c *gc.C) {
+	for i, test := range []struct {
+		about       string
+		machine     *machine
+		powerParams map[string]interface{}
+		expected    []string
+	}{{
+		about:   "healthy machine",
+		machine: &machine{statusName: "Deployed", biosBootMethod: "uefi", powerType: "ipmi"},
+	}, {
+		about:   "new machines haven't booted yet",
+		machine: &machine{statusName: "New", powerType: "ipmi"},
+	}, {
+		about:    "never seen network booting",
+		machine:  &machine{statusName: "Ready", powerType: "ipmi"},
+		expected: []string{"MAAS hasn't seen the machine boot from the network"},
+	}, {
+		about: "deployment timed out",
+		machine: &machine{
+			statusName:     "Failed deployment",
+			statusMessage:  "Node operation 'Deploying' timed out after 40 minutes.",
+			biosBootMethod: "pxe",
+			powerType:      "ipmi",
+		},
+		expected: []string{"failed deployment after a timeout: Node operation 'Deploying' timed out after 40 minutes."},
+	}, {
+		about: "failures other than timeouts aren't reported",
+		machine: &machine{
+			statusName:     "Failed deployment",
+			statusMessage:  "Installation failed (refer to the installation log for more information).",
+			biosBootMethod: "pxe",
+			powerType:      "ipmi",
+		},
+	}, {
+		about: "commissioning timed out",
+		machine: &machine{
+			statusName:    "Failed commissioning",
+			statusMessage: "Node operation 'Commissioning' timed out after 20 minutes.",
+			powerType:     "ipmi",
+		},
+		expected: []string{"failed commissioning after a timeout: Node operation 'Commissioning' timed out after 20 minutes."},
+	}, {
+		about:    "manual power",
+		machine:  &machine{statusName: "Ready", biosBootMethod: "pxe", powerType: "manual"},
+		expected: []string{"manual power control can't select a network boot"},
+	}, {
+		about:       "boot type mismatch",
+		machine:     &machine{statusName: "Ready", biosBootMethod: "uefi", powerType: "ipmi"},
+		powerParams: map[string]interface{}{"power_boot_type": "legacy"},
+		expected:    []string{"power driver requests a legacy boot but the machine boots with uefi"},
+	}, {
+		about:       "boot type matches",
+		machine:     &machine{statusName: "Ready", biosBootMethod: "pxe", powerType: "ipmi"},
+		powerParams: map[string]interface{}{"power_boot_type": "legacy"},
+	}, {
+		about:       "automatic boot type",
+		machine:     &machine{statusName: "Ready", biosBootMethod: "uefi", powerType: "ipmi"},
+		powerParams: map[string]interface{}{"power_boot_type": "auto"},
+	}} {
+		c.Logf("test %d: %s", i, test.about)
+		c.Check(bootOrderReasons(test.machine, test.powerParams), jc.DeepEquals, test.expected)
+	}
+}
+
+func (s *bootOrderSuite) TestCheckBootOrder(c *gc.C) {
+	server, controller := createTestServerController(c, s)
+	server.AddGetResponse("/api/2.0/machines/", http.StatusOK, machinesResponse)
+
+	warnings, err := controller.CheckBootOrder(MachinesArgs{})
+	c.Assert(err, jc.ErrorIsNil)
+	// Only the first machine has network booted, the others are Ready.
+	c.Assert(warnings, gc.HasLen, 2)
+	c.Check(warnings[0].Machine.SystemID(), gc.Equals, "4y3ha4")
+	c.Check(warnings[0].Reasons, jc.DeepEquals, []string{"MAAS hasn't seen the machine boot from the network"})
+	c.Check(warnings[1].Machine.SystemID(), gc.Equals, "4y3ha6")
+}
+
+func (s *bootOrderSuite) TestCheckBootOrderPowerParameters(c *gc.C) {
+	server, controller := createTestServerController(c, s)
+	response := updateJSONMap(c, machineResponse, map[string]interface{}{
+		"bios_boot_method": "uefi",
+		"power_type":       "ipmi",
+	})
+	server.AddGetResponse("/api/2.0/machines/", http.StatusOK, "["+response+"]")
+	server.AddGetResponse("/MAAS/api/2.0/machines/4y3ha3/?op=power_parameters", http.StatusOK, `{"power_boot_type": "legacy"}`)
+
+	warnings, err := controller.CheckBootOrder(MachinesArgs{})
+	c.Assert(err, jc.ErrorIsNil)
+	c.Assert(warnings, gc.HasLen, 1)
+	c.Check(warnings[0].Reasons, jc.DeepEquals, []string{
+		"power driver requests a legacy boot but the machine boots with uefi",
+	})
+}
+
+func (s *bootOrderSuite) TestCheckBootOrderNotAdmin(c *gc.C) {
+	server, controller := createTestServerController(c, s)
+	response := updateJSONMap(c, machineResponse, map[string]interface{}{
+		"bios_boot_method": "uefi",
+		"power_type":       "ipmi",
+	})
+	server.AddGetResponse("/api/2.0/machines/", http.StatusOK, "["+response+"]")
+	server.AddGetResponse("/MAAS/api/2.0/machines/4y3ha3/?op=power_parameters", http.StatusForbidden, "admins only")
+
+	warnings, err := controller.CheckBootOrder(MachinesArgs{})
+	c.Assert(err, jc.ErrorIsNil)
+	c.Check(warnings, gc.HasLen, 0)
+}
@@ -4,6 +4,10 @@
 package gomaasapi
 
 import (
+	"fmt"
+	"io"
+	"net/http"
+	"sort"
 	"strings"
 
 	"github.com/juju/collections/set"
@@ -12,9 +16,12 @@ import (
 	"github.com/juju/version"
 )
 
+// bootResourceChunkSize is the size of the chunks in which the content of
+// an uploaded boot resource is sent.
+var bootResourceChunkSize = 4 << 20
+
 type bootResource struct {
-	// Add the controller in when we need to do things with the bootResource.
-	// controller Controller
+	controller *controller
 
 	resourceURI string
 
@@ -24,6 +31,11 @@ type bootResource struct {
 	architecture string
 	subArches    string
 	kernelFlavor string
+
+	// uploadURI is where the content of the file that has not yet been
+	// uploaded is sent. It is only known when the resource has been read
+	// individually.
+	uploadURI string
 }
 
 // ID implements BootResource.
@@ -56,6 +68,171 @@ func (b *bootResource) KernelFlavor() string {
 	return b.kernelFlavor
 }
 
+// Delete implements BootResource.
+func (b *bootResource) Delete() error {
+	err := b.controller.delete(b.resourceURI)
+	if err != nil {
+		if svrErr, ok := errors.Cause(err).(ServerError); ok {
+			switch svrErr.StatusCode {
+			case http.StatusNotFound:
+				return wrapError(err, NewNoMatchError(svrErr.BodyMessage))
+			case http.StatusForbidden:
+				return wrapError(err, NewPermissionError(svrErr.BodyMessage))
+			}
+		}
+		return NewUnexpectedError(err)
+	}
+	return nil
+}
+
+// UploadBootResourceArgs is an argument struct for
+// Controller.UploadBootResource.
+type UploadBootResourceArgs struct {
+	// Name is the name of the resource, such as "custom/my-image" or
+	// "ubuntu/jammy". Required.
+	Name string
+	// Architecture is the architecture the resource is for, such as
+	// "amd64/generic". Required.
+	Architecture string
+	// Title is the name of the resource shown to the users. Optional.
+	Title string
+	// FileType is the type of the content, such as "tgz" or "ddtgz". If
+	// empty MAAS assumes "tgz".
+	FileType string
+	// BaseImage is the image a custom image is based on, such as
+	// "ubuntu/jammy". Optional.
+	BaseImage string
+	// Content is read to get the content of the resource. Required.
+	Content io.Reader
+	// SHA256 is the hex encoded SHA256 digest of the content, which MAAS
+	// checks once all the content has been received. Required.
+	SHA256 string
+	// Size is the number of bytes of the content. Required.
+	Size int64
+}
+
+// Validate ensures that the required fields are set.
+func (a *UploadBootResourceArgs) Validate() error {
+	if a.Name == "" {
+		return errors.NotValidf("missing Name")
+	}
+	if a.Architecture == "" {
+		return errors.NotValidf("missing Architecture")
+	}
+	if a.Content == nil {
+		return errors.NotValidf("missing Content")
+	}
+	if a.SHA256 == "" {
+		return errors.NotValidf("missing SHA256")
+	}
+	if a.Size <= 0 {
+		return errors.NotValidf("Size %d", a.Size)
+	}
+	return nil
+}
+
+// UploadBootResource implements Controller.
+func (c *controller) UploadBootResource(args UploadBootResourceArgs) (BootResource, error) {
+	if err := args.Validate(); err != nil {
+		return nil, errors.Trace(err)
+	}
+	params := NewURLParams()
+	params.Values.Add("name", args.Name)
+	params.Values.Add("architecture", args.Architecture)
+	params.MaybeAdd("title", args.Title)
+	params.MaybeAdd("filetype", args.FileType)
+	params.MaybeAdd("base_image", args.BaseImage)
+	params.Values.Add("sha256", args.SHA256)
+	params.Values.Add("size", fmt.Sprint(args.Size))
+	// Without the content MAAS only creates the resource, and the content is
+	// then sent in chunks to the upload URI of its file.
+	source, err := c.post("boot-resources", "", params.Values)
+	if err != nil {
+		if svrErr, ok := errors.Cause(err).(ServerError); ok {
+			switch svrErr.StatusCode {
+			case http.StatusBadRequest:
+				return nil, wrapError(err, NewBadRequestError(svrErr.BodyMessage))
+			case http.StatusForbidden:
+				return nil, wrapError(err, NewPermissionError(svrErr.BodyMessage))
+			}
+		}
+		return nil, NewUnexpectedError(err)
+	}
+	resource, err := readBootResource(c.schemaVersion(), source)
+	if err != nil {
+		return nil, errors.Trace(err)
+	}
+	resource.controller = c
+	if resource.uploadURI == "" {
+		// MAAS already has the content, from an earlier upload.
+		return resource, nil
+	}
+	if err := c.uploadBootResourceContent(resource.uploadURI, args.Content, args.Size); err != nil {
+		return nil, errors.Trace(err)
+	}
+
+	source, err = c.get(resource.resourceURI)
+	if err != nil {
+		return nil, NewUnexpectedError(err)
+	}
+	resource, err = readBootResource(c.schemaVersion(), source)
+	if err != nil {
+		return nil, errors.Trace(err)
+	}
+	resource.controller = c
+	return resource, nil
+}
+
+// uploadBootResourceContent sends the content to the upload URI, in chunks
+// of bootResourceChunkSize bytes.
+func (c *controller) uploadBootResourceContent(uploadURI string, content io.Reader, size int64) error {
+	chunk := make([]byte, bootResourceChunkSize)
+	reader := io.LimitReader(content, size)
+	var sent int64
+	for sent < size {
+		n, err := io.ReadFull(reader, chunk)
+		if n > 0 {
+			if err := c.putContent(uploadURI, chunk[:n]); err != nil {
+				if svrErr, ok := errors.Cause(err).(ServerError); ok {
+					switch svrErr.StatusCode {
+					case http.StatusBadRequest:
+						return wrapError(err, NewBadRequestError(svrErr.BodyMessage))
+					case http.StatusForbidden:
+						return wrapError(err, NewPermissionError(svrErr.BodyMessage))
+					}
+				}
+				return NewUnexpectedError(err)
+			}
+			sent += int64(n)
+		}
+		if err == io.EOF || err == io.ErrUnexpectedEOF {
+			break
+		}
+		if err != nil {
+			return errors.Annotate(err, "reading content")
+		}
+	}
+	if sent != size {
+		return errors.NotValidf("Content of %d bytes with Size %d", sent, size)
+	}
+	return nil
+}
+
+func readBootResource(controllerVersion version.Number, source interface{}) (*bootResource, error) {
+	readFunc, err := getBootResourceDeserializationFunc(controllerVersion)
+	if err != nil {
+		return nil, errors.Trace(err)
+	}
+
+	checker := schema.StringMap(schema.Any())
+	coerced, err := checker.Coerce(source, nil)
+	if err != nil {
+		return nil, WrapWithDeserializationError(err, "boot resource base schema check failed")
+	}
+	valid := coerced.(map[string]interface{})
+	return readFunc(valid)
+}
+
 func readBootResources(controllerVersion version.Number, source interface{}) ([]*bootResource, error) {
 	checker := schema.List(schema.StringMap(schema.Any()))
 	coerced, err := checker.Coerce(source, nil)
@@ -64,6 +241,14 @@ func readBootResources(controllerVersion version.Number, source interface{}) ([]
 	}
 	valid := coerced.([]interface{})
 
+	readFunc, err := getBootResourceDeserializationFunc(controllerVersion)
+	if err != nil {
+		return nil, errors.Trace(err)
+	}
+	return readBootResourceList(valid, readFunc)
+}
+
+func getBootResourceDeserializationFunc(controllerVersion version.Number) (bootResourceDeserializationFunc, error) {
 	var deserialisationVersion version.Number
 	for v := range bootResourceDeserializationFuncs {
 		if v.Compare(deserialisationVersion) > 0 && v.Compare(controllerVersion) <= 0 {
@@ -73,8 +258,7 @@ func readBootResources(controllerVersion version.Number, source interface{}) ([]
 	if deserialisationVersion == version.Zero {
 		return nil, NewUnsupportedVersionError("no boot resource read func for version %s", controllerVersion)
 	}
-	readFunc := bootResourceDeserializationFuncs[deserialisationVersion]
-	return readBootResourceList(valid, readFunc)
+	return bootResourceDeserializationFuncs[deserialisationVersion], nil
 }
 
 // readBootResourceList expects the values of the sourceList to be string maps.
@@ -109,10 +293,23 @@ func bootResource_2_0(source map[string]interface{}) (*bootResource, error) {
 		"architecture": schema.String(),
 		"subarches":    schema.String(),
 		"kflavor":      schema.String(),
+		"sets": schema.StringMap(schema.FieldMap(
+			schema.Fields{
+				"files": schema.StringMap(schema.FieldMap(
+					schema.Fields{
+						"complete":   schema.Bool(),
+						"upload_uri": schema.String(),
+					},
+					schema.Defaults{"complete": true, "upload_uri": ""},
+				)),
+			},
+			schema.Defaults{"files": schema.Omit},
+		)),
 	}
 	defaults := schema.Defaults{
 		"subarches": "",
 		"kflavor":   "",
+		"sets":      schema.Omit,
 	}
 	checker := schema.FieldMap(fields, defaults)
 	coerced, err := checker.Coerce(source, nil)
@@ -132,5 +329,31 @@ func bootResource_2_0(source map[string]interface{}) (*bootResource, error) {
 		subArches:    valid["subarches"].(string),
 		kernelFlavor: valid["kflavor"].(string),
 	}
+	if sets, ok := valid["sets"].(map[string]interface{}); ok {
+		result.uploadURI = bootResourceUploadURI(sets)
+	}
 	return result, nil
 }
+
+// bootResourceUploadURI returns the upload URI of the incomplete file of the
+// newest set, or an empty string if all its files are complete. The sets are
+// keyed by their labels, which sort by age.
+func bootResourceUploadURI(sets map[string]interface{}) string {
+	if len(sets) == 0 {
+		return ""
+	}
+	labels := make([]string, 0, len(sets))
+	for label := range sets {
+		labels = append(labels, label)
+	}
+	sort.Strings(labels)
+	newest := sets[labels[len(labels)-1]].(map[string]interface{})
+	files, _ := newest["files"].(map[string]interface{})
+	for _, value := range files {
+		file := value.(map[string]interface{})
+		if !file["complete"].(bool) && file["upload_uri"].(string) != "" {
+			return file["upload_uri"].(string)
+		}
+	}
+	return ""
+}
@@ -4,13 +4,21 @@
 package gomaasapi
 
 import (
+	"io"
+	"net/http"
+	"strings"
+
 	"github.com/juju/collections/set"
+	"github.com/juju/errors"
+	"github.com/juju/testing"
 	jc "github.com/juju/testing/checkers"
 	"github.com/juju/version"
 	gc "gopkg.in/check.v1"
 )
 
-type bootResourceSuite struct{}
+type bootResourceSuite struct {
+	testing.LoggingCleanupSuite
+}
 
 var _ = gc.Suite(&bootResourceSuite{})
 
@@ -46,6 +54,241 @@ func (*bootResourceSuite) TestHighVersion(c *gc.C) {
 	c.Assert(bootResources, gc.HasLen, 5)
 }
 
+func (*bootResourceSuite) TestReadBootResourceUploadURI(c *gc.C) {
+	resource, err := readBootResource(twoDotOh, parseJSON(c, bootResourceUploadResponse))
+	c.Assert(err, jc.ErrorIsNil)
+	c.Assert(resource.Name(), gc.Equals, "custom/image")
+	c.Assert(resource.uploadURI, gc.Equals, "/api/2.0/boot-resources/7/upload/12/")
+}
+
+func (*bootResourceSuite) TestReadBootResourceComplete(c *gc.C) {
+	resource, err := readBootResource(twoDotOh, parseJSON(c, bootResourceCompleteResponse))
+	c.Assert(err, jc.ErrorIsNil)
+	c.Assert(resource.uploadURI, gc.Equals, "")
+}
+
+func (s *bootResourceSuite) TestDelete(c *gc.C) {
+	server, controller := createTestServerController(c, s)
+	server.AddGetResponse("/api/2.0/boot-resources/", http.StatusOK, bootResourcesResponse)
+	server.AddDeleteResponse("/MAAS/api/2.0/boot-resources/5/", http.StatusNoContent, "")
+	resources, err := controller.BootResources()
+	c.Assert(err, jc.ErrorIsNil)
+	err = resources[0].Delete()
+	c.Assert(err, jc.ErrorIsNil)
+}
+
+func (s *bootResourceSuite) TestDeleteMissing(c *gc.C) {
+	server, controller := createTestServerController(c, s)
+	server.AddGetResponse("/api/2.0/boot-resources/", http.StatusOK, bootResourcesResponse)
+	resources, err := controller.BootResources()
+	c.Assert(err, jc.ErrorIsNil)
+	err = resources[0].Delete()
+	c.Assert(err, jc.Satisfies, IsNoMatchError)
+}
+
+func (s *bootResourceSuite) TestUploadArgsValidate(c *gc.C) {
+	for i, test := range []struct {
+		args    UploadBootResourceArgs
+		message string
+	}{{
+		args:    UploadBootResourceArgs{},
+		message: "missing Name",
+	}, {
+		args:    UploadBootResourceArgs{Name: "custom/image"},
+		message: "missing Architecture",
+	}, {
+		args:    UploadBootResourceArgs{Name: "custom/image", Architecture: "amd64/generic"},
+		message: "missing Content",
+	}, {
+		args: UploadBootResourceArgs{
+			Name:         "custom/image",
+			Architecture: "amd64/generic",
+			Content:      strings.NewReader("content"),
+		},
+		message: "missing SHA256",
+	}, {
+		args: UploadBootResourceArgs{
+			Name:         "custom/image",
+			Architecture: "amd64/generic",
+			Content:      strings.NewReader("content"),
+			SHA256:       "abc",
+		},
+		message: "Size 0",
+	}, {
+		args: UploadBootResourceArgs{
+			Name:         "custom/image",
+			Architecture: "amd64/generic",
+			Content:      strings.NewReader("content"),
+			SHA256:       "abc",
+			Size:         7,
+		},
+	}} {
+		c.Logf("test %d", i)
+		err := test.args.Validate()
+		if test.message == "" {
+			c.Check(err, jc.ErrorIsNil)
+		} else {
+			c.Check(err, jc.Satisfies, errors.IsNotValid)
+			c.Check(err.Error(), gc.Equals, test.message+" not valid")
+		}
+	}
+}
+
+func (s *bootResourceSuite) uploadArgs(content string) UploadBootResourceArgs {
+	return UploadBootResourceArgs{
+		Name:         "custom/image",
+		Architecture: "amd64/generic",
+		Title:        "My Image",
+		FileType:     "ddtgz",
+		Content:      strings.NewReader(content),
+		SHA256:       "abc",
+		Size:         int64(len(content)),
+	}
+}
+
+func (s *bootResourceSuite) TestUpload(c *gc.C) {
+	s.PatchValue(&bootResourceChunkSize, 4)
+	server, controller := createTestServerController(c, s)
+	server.AddPostResponse("/api/2.0/boot-resources/?op=", http.StatusCreated, bootResourceUploadResponse)
+	for i := 0; i < 3; i++ {
+		server.AddPutResponse("/api/2.0/boot-resources/7/upload/12/", http.StatusOK, "")
+	}
+	server.AddGetResponse("/api/2.0/boot-resources/7/", http.StatusOK, bootResourceCompleteResponse)
+	server.ResetRequests()
+
+	resource, err := controller.UploadBootResource(s.uploadArgs("some content"))
+	c.Assert(err, jc.ErrorIsNil)
+	c.Assert(resource.ID(), gc.Equals, 7)
+
+	requests := server.LastNRequests(5)
+	c.Assert(requests, gc.HasLen, 5)
+	form := requests[0].PostForm
+	c.Check(form.Get("name"), gc.Equals, "custom/image")
+	c.Check(form.Get("architecture"), gc.Equals, "amd64/generic")
+	c.Check(form.Get("title"), gc.Equals, "My Image")
+	c.Check(form.Get("filetype"), gc.Equals, "ddtgz")
+	c.Check(form.Get("sha256"), gc.Equals, "abc")
+	c.Check(form.Get("size"), gc.Equals, "12")
+	c.Check(form["content"], gc.HasLen, 0)
+	var chunks []string
+	for _, request := range requests[1:4] {
+		c.Check(request.Method, gc.Equals, "PUT")
+		content, err := io.ReadAll(request.Body)
+		c.Assert(err, jc.ErrorIsNil)
+		chunks = append(chunks, string(content))
+	}
+	c.Check(chunks, jc.DeepEquals, []string{"some", " con", "tent"})
+	c.Check(requests[4].Method, gc.Equals, "GET")
+}
+
+func (s *bootResourceSuite) TestUploadAlreadyComplete(c *gc.C) {
+	server, controller := createTestServerController(c, s)
+	server.AddPostResponse("/api/2.0/boot-resources/?op=", http.StatusCreated, bootResourceCompleteResponse)
+	server.ResetRequests()
+
+	resource, err := controller.UploadBootResource(s.uploadArgs("some content"))
+	c.Assert(err, jc.ErrorIsNil)
+	c.Assert(resource.ID(), gc.Equals, 7)
+	c.Assert(server.RequestCount(), gc.Equals, 1)
+}
+
+func (s *bootResourceSuite) TestUploadShortContent(c *gc.C) {
+	server, controller := createTestServerController(c, s)
+	server.AddPostResponse("/api/2.0/boot-resources/?op=", http.StatusCreated, bootResourceUploadResponse)
+	server.AddPutResponse("/api/2.0/boot-resources/7/upload/12/", http.StatusOK, "")
+
+	args := s.uploadArgs("some content")
+	args.Size = 20
+	_, err := controller.UploadBootResource(args)
+	c.Assert(err, jc.Satisfies, errors.IsNotValid)
+	c.Assert(err.Error(), gc.Equals, "Content of 12 bytes with Size 20 not valid")
+}
+
+func (s *bootResourceSuite) TestUploadBadRequest(c *gc.C) {
+	server, controller := createTestServerController(c, s)
+	server.AddPostResponse("/api/2.0/boot-resources/?op=", http.StatusBadRequest, "bad architecture")
+	_, err := controller.UploadBootResource(s.uploadArgs("some content"))
+	c.Assert(err, jc.Satisfies, IsBadRequestError)
+	c.Assert(err.Error(), gc.Equals, "bad architecture")
+}
+
+func (s *bootResourceSuite) TestUploadChunkForbidden(c *gc.C) {
+	server, controller := createTestServerController(c, s)
+	server.AddPostResponse("/api/2.0/boot-resources/?op=", http.StatusCreated, bootResourceUploadResponse)
+	server.AddPutResponse("/api/2.0/boot-resources/7/upload/12/", http.StatusForbidden, "no permission")
+	_, err := controller.UploadBootResource(s.uploadArgs("some content"))
+	c.Assert(err, jc.Satisfies, IsPermissionError)
+}
+
+const bootResourceUploadResponse = `
+{
+    "architecture": "amd64/generic",
+    "type": "Uploaded",
+    "subarches": "generic",
+    "name": "custom/image",
+    "id": 7,
+    "resource_uri": "/api/2.0/boot-resources/7/",
+    "sets": {
+        "20220101": {
+            "version": "20220101",
+            "label": "uploaded",
+            "complete": true,
+            "files": {
+                "root-dd.tar.gz": {
+                    "filename": "root-dd.tar.gz",
+                    "filetype": "root-dd.tar.gz",
+                    "sha256": "def",
+                    "size": 10,
+                    "complete": true
+                }
+            }
+        },
+        "20220102": {
+            "version": "20220102",
+            "label": "uploaded",
+            "complete": false,
+            "files": {
+                "root-dd.tar.gz": {
+                    "filename": "root-dd.tar.gz",
+                    "filetype": "root-dd.tar.gz",
+                    "sha256": "abc",
+                    "size": 12,
+                    "complete": false,
+                    "upload_uri": "/api/2.0/boot-resources/7/upload/12/"
+                }
+            }
+        }
+    }
+}
+`
+
+const bootResourceCompleteResponse = `
+{
+    "architecture": "amd64/generic",
+    "type": "Uploaded",
+    "subarches": "generic",
+    "name": "custom/image",
+    "id": 7,
+    "resource_uri": "/api/2.0/boot-resources/7/",
+    "sets": {
+        "20220102": {
+            "version": "20220102",
+            "label": "uploaded",
+            "complete": true,
+            "files": {
+                "root-dd.tar.gz": {
+                    "filename": "root-dd.tar.gz",
+                    "filetype": "root-dd.tar.gz",
+                    "sha256": "abc",
+                    "size": 12,
+                    "complete": true
+                }
+            }
+        }
+    }
+}
+`
+
 var bootResourcesResponse = `
 [
     {
@@ -0,0 +1,403 @@
+// Copyright 2022 Canonical Ltd.
+// Licensed under the LGPLv3, see LICENCE file for details.
+
+package gomaasapi
+
+import (
+	"encoding/base64"
+	"encoding/json"
+	"net/http"
+
+	"github.com/juju/errors"
+	"github.com/juju/schema"
+	"github.com/juju/version"
+)
+
+type bootSource struct {
+	controller *controller
+
+	resourceURI string
+
+	id              int
+	url             string
+	keyringFilename string
+	keyringData     []byte
+}
+
+func (b *bootSource) updateFrom(other *bootSource) {
+	b.resourceURI = other.resourceURI
+	b.id = other.id
+	b.url = other.url
+	b.keyringFilename = other.keyringFilename
+	b.keyringData = other.keyringData
+}
+
+// ID implements BootSource.
+func (b *bootSource) ID() int {
+	return b.id
+}
+
+// URL implements BootSource.
+func (b *bootSource) URL() string {
+	return b.url
+}
+
+// KeyringFilename implements BootSource.
+func (b *bootSource) KeyringFilename() string {
+	return b.keyringFilename
+}
+
+// KeyringData implements BootSource.
+func (b *bootSource) KeyringData() []byte {
+	return b.keyringData
+}
+
+// UpdateBootSourceArgs is an argument struct for BootSource.Update. Only
+// the fields that are set are changed.
+type UpdateBootSourceArgs struct {
+	URL string
+	// KeyringFilename is the path on the region controller of the keyring
+	// that verifies the images.
+	KeyringFilename string
+	// KeyringData is the content of the keyring that verifies the images.
+	KeyringData []byte
+}
+
+// Validate ensures that at most one of the keyrings is set.
+func (a *UpdateBootSourceArgs) Validate() error {
+	if a.KeyringFilename != "" && len(a.KeyringData) > 0 {
+		return errors.NotValidf("both KeyringFilename and KeyringData")
+	}
+	return nil
+}
+
+// Update implements BootSource.
+func (b *bootSource) Update(args UpdateBootSourceArgs) error {
+	if err := args.Validate(); err != nil {
+		return errors.Trace(err)
+	}
+	params := NewURLParams()
+	params.MaybeAdd("url", args.URL)
+	params.MaybeAdd("keyring_filename", args.KeyringFilename)
+	var files map[string][]byte
+	if len(args.KeyringData) > 0 {
+		files = map[string][]byte{"keyring_data": args.KeyringData}
+	}
+	source, err := b.controller.putFiles(b.resourceURI, params.Values, files)
+	if err != nil {
+		if svrErr, ok := errors.Cause(err).(ServerError); ok {
+			switch svrErr.StatusCode {
+			case http.StatusNotFound:
+				return wrapError(err, NewNoMatchError(svrErr.BodyMessage))
+			case http.StatusForbidden:
+				return wrapError(err, NewPermissionError(svrErr.BodyMessage))
+			case http.StatusBadRequest:
+				return wrapError(err, NewBadRequestError(svrErr.BodyMessage))
+			}
+		}
+		return NewUnexpectedError(err)
+	}
+
+	response, err := readBootSource(b.controller.schemaVersion(), source)
+	if err != nil {
+		return errors.Trace(err)
+	}
+	b.updateFrom(response)
+	return nil
+}
+
+// Delete implements BootSource.
+func (b *bootSource) Delete() error {
+	err := b.controller.delete(b.resourceURI)
+	if err != nil {
+		if svrErr, ok := errors.Cause(err).(ServerError); ok {
+			switch svrErr.StatusCode {
+			case http.StatusNotFound:
+				return wrapError(err, NewNoMatchError(svrErr.BodyMessage))
+			case http.StatusForbidden:
+				return wrapError(err, NewPermissionError(svrErr.BodyMessage))
+			}
+		}
+		return NewUnexpectedError(err)
+	}
+	return nil
+}
+
+// Selections implements BootSource.
+func (b *bootSource) Selections() ([]BootSourceSelection, error) {
+	source, err := b.controller.getList(b.resourceURI+"selections", nil, 0)
+	if err != nil {
+		if svrErr, ok := errors.Cause(err).(ServerError); ok {
+			if svrErr.StatusCode == http.StatusNotFound {
+				return nil, wrapError(err, NewNoMatchError(svrErr.BodyMessage))
+			}
+		}
+		return nil, NewUnexpectedError(err)
+	}
+	selections, err := readBootSourceSelections(b.controller.schemaVersion(), source)
+	if err != nil {
+		return nil, errors.Trace(err)
+	}
+	var result []BootSourceSelection
+	for _, s := range selections {
+		s.controller = b.controller
+		result = append(result, s)
+	}
+	return result, nil
+}
+
+// CreateBootSourceSelectionArgs is an argument struct for
+// BootSource.CreateSelection.
+type CreateBootSourceSelectionArgs struct {
+	// OS is the operating system to import, such as "ubuntu". Required.
+	OS string
+	// Release is the release to import, such as "jammy". Required.
+	Release string
+	// Architectures are the architectures to import. If none are given
+	// MAAS imports all of them.
+	Architectures []string
+	// SubArchitectures are the sub-architectures to import. If none are
+	// given MAAS imports all of them.
+	SubArchitectures []string
+	// Labels are the labels of the images to import. If none are given
+	// MAAS imports all of them.
+	Labels []string
+}
+
+// Validate ensures that the OS and the Release are set.
+func (a *CreateBootSourceSelectionArgs) Validate() error {
+	if a.OS == "" {
+		return errors.NotValidf("missing OS")
+	}
+	if a.Release == "" {
+		return errors.NotValidf("missing Release")
+	}
+	return nil
+}
+
+// CreateSelection implements BootSource.
+func (b *bootSource) CreateSelection(args CreateBootSourceSelectionArgs) (BootSourceSelection, error) {
+	if err := args.Validate(); err != nil {
+		return nil, errors.Trace(err)
+	}
+	params := NewURLParams()
+	params.Values.Add("os", args.OS)
+	params.Values.Add("release", args.Release)
+	params.MaybeAddMany("arches", args.Architectures)
+	params.MaybeAddMany("subarches", args.SubArchitectures)
+	params.MaybeAddMany("labels", args.Labels)
+	source, err := b.controller.post(b.resourceURI+"selections", "", params.Values)
+	if err != nil {
+		if svrErr, ok := errors.Cause(err).(ServerError); ok {
+			switch svrErr.StatusCode {
+			case http.StatusNotFound:
+				return nil, wrapError(err, NewNoMatchError(svrErr.BodyMessage))
+			case http.StatusForbidden:
+				return nil, wrapError(err, NewPermissionError(svrErr.BodyMessage))
+			case http.StatusBadRequest:
+				return nil, wrapError(err, NewBadRequestError(svrErr.BodyMessage))
+			}
+		}
+		return nil, NewUnexpectedError(err)
+	}
+
+	selection, err := readBootSourceSelection(b.controller.schemaVersion(), source)
+	if err != nil {
+		return nil, errors.Trace(err)
+	}
+	selection.controller = b.controller
+	return selection, nil
+}
+
+// BootSources implements Controller.
+func (c *controller) BootSources() ([]BootSource, error) {
+	source, err := c.getList("boot-sources", nil, 0)
+	if err != nil {
+		if svrErr, ok := errors.Cause(err).(ServerError); ok {
+			if svrErr.StatusCode == http.StatusForbidden {
+				return nil, wrapError(err, NewPermissionError(svrErr.BodyMessage))
+			}
+		}
+		return nil, NewUnexpectedError(err)
+	}
+	sources, err := readBootSources(c.schemaVersion(), source)
+	if err != nil {
+		return nil, errors.Trace(err)
+	}
+	var result []BootSource
+	for _, s := range sources {
+		s.controller = c
+		result = append(result, s)
+	}
+	return result, nil
+}
+
+// CreateBootSourceArgs is an argument struct for Controller.CreateBootSource.
+type CreateBootSourceArgs struct {
+	// URL is the location of the simplestreams index of the images, such
+	// as the URL of a local mirror. Required.
+	URL string
+	// KeyringFilename is the path on the region controller of the keyring
+	// that verifies the images.
+	KeyringFilename string
+	// KeyringData is the content of the keyring that verifies the images.
+	// Exactly one of KeyringFilename and KeyringData must be set.
+	KeyringData []byte
+}
+
+// Validate ensures that the URL and exactly one of the keyrings are set.
+func (a *CreateBootSourceArgs) Validate() error {
+	if a.URL == "" {
+		return errors.NotValidf("missing URL")
+	}
+	if a.KeyringFilename == "" && len(a.KeyringData) == 0 {
+		return errors.NotValidf("missing KeyringFilename or KeyringData")
+	}
+	if a.KeyringFilename != "" && len(a.KeyringData) > 0 {
+		return errors.NotValidf("both KeyringFilename and KeyringData")
+	}
+	return nil
+}
+
+// CreateBootSource implements Controller.
+func (c *controller) CreateBootSource(args CreateBootSourceArgs) (BootSource, error) {
+	if err := args.Validate(); err != nil {
+		return nil, errors.Trace(err)
+	}
+	params := NewURLParams()
+	params.Values.Add("url", args.URL)
+	params.MaybeAdd("keyring_filename", args.KeyringFilename)
+	var files map[string][]byte
+	if len(args.KeyringData) > 0 {
+		files = map[string][]byte{"keyring_data": args.KeyringData}
+	}
+	bytes, err := c._postRaw("boot-sources", "", params.Values, files)
+	if err != nil {
+		if svrErr, ok := errors.Cause(err).(ServerError); ok {
+			switch svrErr.StatusCode {
+			case http.StatusForbidden:
+				return nil, wrapError(err, NewPermissionError(svrErr.BodyMessage))
+			case http.StatusBadRequest:
+				return nil, wrapError(err, NewBadRequestError(svrErr.BodyMessage))
+			}
+		}
+		return nil, NewUnexpectedError(err)
+	}
+
+	var source interface{}
+	if err := json.Unmarshal(bytes, &source); err != nil {
+		return nil, errors.Trace(err)
+	}
+	bootSource, err := readBootSource(c.schemaVersion(), source)
+	if err != nil {
+		return nil, errors.Trace(err)
+	}
+	bootSource.controller = c
+	return bootSource, nil
+}
+
+func readBootSource(controllerVersion version.Number, source interface{}) (*bootSource, error) {
+	readFunc, err := getBootSourceDeserializationFunc(controllerVersion)
+	if err != nil {
+		return nil, errors.Trace(err)
+	}
+
+	checker := schema.StringMap(schema.Any())
+	coerced, err := checker.Coerce(source, nil)
+	if err != nil {
+		return nil, WrapWithDeserializationError(err, "boot source base schema check failed")
+	}
+	valid := coerced.(map[string]interface{})
+	return readFunc(valid)
+}
+
+func readBootSources(controllerVersion version.Number, source interface{}) ([]*bootSource, error) {
+	readFunc, err := getBootSourceDeserializationFunc(controllerVersion)
+	if err != nil {
+		return nil, errors.Trace(err)
+	}
+
+	checker := schema.List(schema.StringMap(schema.Any()))
+	coerced, err := checker.Coerce(source, nil)
+	if err != nil {
+		return nil, WrapWithDeserializationError(err, "boot source base schema check failed")
+	}
+	valid := coerced.([]interface{})
+	return readBootSourceList(valid, readFunc)
+}
+
+func getBootSourceDeserializationFunc(controllerVersion version.Number) (bootSourceDeserializationFunc, error) {
+	var deserialisationVersion version.Number
+	for v := range bootSourceDeserializationFuncs {
+		if v.Compare(deserialisationVersion) > 0 && v.Compare(controllerVersion) <= 0 {
+			deserialisationVersion = v
+		}
+	}
+	if deserialisationVersion == version.Zero {
+		return nil, NewUnsupportedVersionError("no boot source read func for version %s", controllerVersion)
+	}
+	return bootSourceDeserializationFuncs[deserialisationVersion], nil
+}
+
+// readBootSourceList expects the values of the sourceList to be string maps.
+func readBootSourceList(sourceList []interface{}, readFunc bootSourceDeserializationFunc) ([]*bootSource, error) {
+	result := make([]*bootSource, 0, len(sourceList))
+	for i, value := range sourceList {
+		source, ok := value.(map[string]interface{})
+		if !ok {
+			return nil, NewDeserializationError("unexpected value for boot source %d, %T", i, value)
+		}
+		bootSource, err := readFunc(source)
+		if err != nil {
+			return nil, errors.Annotatef(err, "boot source %d", i)
+		}
+		result = append(result, bootSource)
+	}
+	return result, nil
+}
+
+type bootSourceDeserializationFunc func(map[string]interface{}) (*bootSource, error)
+
+var bootSourceDeserializationFuncs = map[version.Number]bootSourceDeserializationFunc{
+	twoDotOh: bootSource_2_0,
+}
+
+func bootSource_2_0(source map[string]interface{}) (*bootSource, error) {
+	fields := schema.Fields{
+		"resource_uri":     schema.String(),
+		"id":               schema.ForceInt(),
+		"url":              schema.String(),
+		"keyring_filename": schema.OneOf(schema.Nil(""), schema.String()),
+		"keyring_data":     schema.OneOf(schema.Nil(""), schema.String()),
+	}
+	defaults := schema.Defaults{
+		"keyring_filename": "",
+		"keyring_data":     "",
+	}
+	checker := schema.FieldMap(fields, defaults)
+	coerced, err := checker.Coerce(source, nil)
+	if err != nil {
+		return nil, WrapWithDeserializationError(err, "boot source 2.0 schema check failed")
+	}
+	valid := coerced.(map[string]interface{})
+	// From here we know that the map returned from the schema coercion
+	// contains fields of the right type.
+
+	// MAAS sends the keyring data base64 encoded.
+	var keyringData []byte
+	if encoded, _ := valid["keyring_data"].(string); encoded != "" {
+		keyringData, err = base64.StdEncoding.DecodeString(encoded)
+		if err != nil {
+			return nil, NewDeserializationError("boot source keyring_data: %v", err)
+		}
+	}
+	keyringFilename, _ := valid["keyring_filename"].(string)
+	result := &bootSource{
+		resourceURI:     valid["resource_uri"].(string),
+		id:              valid["id"].(int),
+		url:             valid["url"].(string),
+		keyringFilename: keyringFilename,
+		keyringData:     keyringData,
+	}
+	return result, nil
+}
@@ -0,0 +1,269 @@
+// Copyright 2022 Canonical Ltd.
+// Licensed under the LGPLv3, see LICENCE file for details.
+
+package gomaasapi
+
+import (
+	"io"
+	"net/http"
+
+	"github.com/juju/errors"
+	"github.com/juju/testing"
+	jc "github.com/juju/testing/checkers"
+	"github.com/juju/version"
+	gc "gopkg.in/check.v1"
+)
+
+type bootSourceSuite struct {
+	testing.LoggingCleanupSuite
+}
+
+var _ = gc.Suite(&bootSourceSuite{})
+
+func (*bootSourceSuite) TestReadBootSourcesBadSchema(c *gc.C) {
+	_, err := readBootSources(twoDotOh, "wat?")
+	c.Check(err, jc.Satisfies, IsDeserializationError)
+	c.Assert(err.Error(), gc.Equals, `boot source base schema check failed: expected list, got string("wat?")`)
+}
+
+func (*bootSourceSuite) TestReadBootSources(c *gc.C) {
+	sources, err := readBootSources(twoDotOh, parseJSON(c, bootSourcesResponse))
+	c.Assert(err, jc.ErrorIsNil)
+	c.Assert(sources, gc.HasLen, 2)
+
+	source := sources[0]
+	c.Check(source.ID(), gc.Equals, 1)
+	c.Check(source.URL(), gc.Equals, "http://images.maas.io/ephemeral-v3/stable/")
+	c.Check(source.KeyringFilename(), gc.Equals, "/usr/share/keyrings/ubuntu-cloudimage-keyring.gpg")
+	c.Check(source.KeyringData(), gc.HasLen, 0)
+
+	source = sources[1]
+	c.Check(source.KeyringFilename(), gc.Equals, "")
+	c.Check(string(source.KeyringData()), gc.Equals, "keyring")
+}
+
+func (*bootSourceSuite) TestReadBootSourcesBadKeyringData(c *gc.C) {
+	json := updateJSONMap(c, bootSourceResponse, map[string]interface{}{"keyring_data": "not base64!"})
+	_, err := readBootSource(twoDotOh, parseJSON(c, json))
+	c.Assert(err, jc.Satisfies, IsDeserializationError)
+}
+
+func (*bootSourceSuite) TestLowVersion(c *gc.C) {
+	_, err := readBootSources(version.MustParse("1.9.0"), parseJSON(c, bootSourcesResponse))
+	c.Assert(err, jc.Satisfies, IsUnsupportedVersionError)
+}
+
+func (*bootSourceSuite) TestHighVersion(c *gc.C) {
+	sources, err := readBootSources(version.MustParse("2.1.9"), parseJSON(c, bootSourcesResponse))
+	c.Assert(err, jc.ErrorIsNil)
+	c.Assert(sources, gc.HasLen, 2)
+}
+
+func (s *bootSourceSuite) getServerAndSource(c *gc.C) (*SimpleTestServer, BootSource) {
+	server, controller := createTestServerController(c, s)
+	server.AddGetResponse("/api/2.0/boot-sources/", http.StatusOK, bootSourcesResponse)
+	sources, err := controller.BootSources()
+	c.Assert(err, jc.ErrorIsNil)
+	c.Assert(sources, gc.HasLen, 2)
+	return server, sources[0]
+}
+
+func (s *bootSourceSuite) TestBootSourcesForbidden(c *gc.C) {
+	server, controller := createTestServerController(c, s)
+	server.AddGetResponse("/api/2.0/boot-sources/", http.StatusForbidden, "admins only")
+	_, err := controller.BootSources()
+	c.Assert(err, jc.Satisfies, IsPermissionError)
+}
+
+func (s *bootSourceSuite) TestCreateArgsValidate(c *gc.C) {
+	for i, test := range []struct {
+		args    CreateBootSourceArgs
+		message string
+	}{{
+		args:    CreateBootSourceArgs{},
+		message: "missing URL",
+	}, {
+		args:    CreateBootSourceArgs{URL: "http://mirror/"},
+		message: "missing KeyringFilename or KeyringData",
+	}, {
+		args:    CreateBootSourceArgs{URL: "http://mirror/", KeyringFilename: "/keyring", KeyringData: []byte("keyring")},
+		message: "both KeyringFilename and KeyringData",
+	}, {
+		args: CreateBootSourceArgs{URL: "http://mirror/", KeyringFilename: "/keyring"},
+	}, {
+		args: CreateBootSourceArgs{URL: "http://mirror/", KeyringData: []byte("keyring")},
+	}} {
+		c.Logf("test %d", i)
+		err := test.args.Validate()
+		if test.message == "" {
+			c.Check(err, jc.ErrorIsNil)
+		} else {
+			c.Check(err, jc.Satisfies, errors.IsNotValid)
+			c.Check(err.Error(), gc.Equals, test.message+" not valid")
+		}
+	}
+}
+
+func (s *bootSourceSuite) TestCreateBootSource(c *gc.C) {
+	server, controller := createTestServerController(c, s)
+	server.AddPostResponse("/api/2.0/boot-sources/?op=", http.StatusOK, bootSourceResponse)
+	source, err := controller.CreateBootSource(CreateBootSourceArgs{
+		URL:             "http://images.maas.io/ephemeral-v3/stable/",
+		KeyringFilename: "/usr/share/keyrings/ubuntu-cloudimage-keyring.gpg",
+	})
+	c.Assert(err, jc.ErrorIsNil)
+	c.Assert(source.ID(), gc.Equals, 1)
+
+	form := server.LastRequest().PostForm
+	c.Check(form.Get("url"), gc.Equals, "http://images.maas.io/ephemeral-v3/stable/")
+	c.Check(form.Get("keyring_filename"), gc.Equals, "/usr/share/keyrings/ubuntu-cloudimage-keyring.gpg")
+}
+
+func (s *bootSourceSuite) TestCreateBootSourceKeyringData(c *gc.C) {
+	server, controller := createTestServerController(c, s)
+	server.AddPostResponse("/api/2.0/boot-sources/?op=", http.StatusOK, bootSourceResponse)
+	_, err := controller.CreateBootSource(CreateBootSourceArgs{
+		URL:         "http://mirror/",
+		KeyringData: []byte("keyring"),
+	})
+	c.Assert(err, jc.ErrorIsNil)
+
+	request := server.LastRequest()
+	c.Check(request.PostForm.Get("url"), gc.Equals, "http://mirror/")
+	c.Check(request.PostForm["keyring_filename"], gc.HasLen, 0)
+	c.Check(readUploadedFile(c, request, "keyring_data"), gc.Equals, "keyring")
+}
+
+func (s *bootSourceSuite) TestCreateBootSourceBadRequest(c *gc.C) {
+	server, controller := createTestServerController(c, s)
+	server.AddPostResponse("/api/2.0/boot-sources/?op=", http.StatusBadRequest, "bad url")
+	_, err := controller.CreateBootSource(CreateBootSourceArgs{URL: "wat", KeyringFilename: "/keyring"})
+	c.Assert(err, jc.Satisfies, IsBadRequestError)
+	c.Assert(err.Error(), gc.Equals, "bad url")
+}
+
+func (s *bootSourceSuite) TestUpdate(c *gc.C) {
+	server, source := s.getServerAndSource(c)
+	response := updateJSONMap(c, bootSourceResponse, map[string]interface{}{"url": "http://mirror/"})
+	server.AddPutResponse("/api/2.0/boot-sources/1/", http.StatusOK, response)
+	err := source.Update(UpdateBootSourceArgs{URL: "http://mirror/"})
+	c.Assert(err, jc.ErrorIsNil)
+	c.Check(source.URL(), gc.Equals, "http://mirror/")
+
+	form := server.LastRequest().PostForm
+	c.Check(form.Get("url"), gc.Equals, "http://mirror/")
+	c.Check(form["keyring_filename"], gc.HasLen, 0)
+}
+
+func (s *bootSourceSuite) TestUpdateKeyringData(c *gc.C) {
+	server, source := s.getServerAndSource(c)
+	server.AddPutResponse("/api/2.0/boot-sources/1/", http.StatusOK, bootSourceResponse)
+	err := source.Update(UpdateBootSourceArgs{KeyringData: []byte("keyring")})
+	c.Assert(err, jc.ErrorIsNil)
+	c.Check(readUploadedFile(c, server.LastRequest(), "keyring_data"), gc.Equals, "keyring")
+}
+
+func (s *bootSourceSuite) TestUpdateBothKeyrings(c *gc.C) {
+	_, source := s.getServerAndSource(c)
+	err := source.Update(UpdateBootSourceArgs{KeyringFilename: "/keyring", KeyringData: []byte("keyring")})
+	c.Assert(err, jc.Satisfies, errors.IsNotValid)
+}
+
+func (s *bootSourceSuite) TestUpdateMissing(c *gc.C) {
+	_, source := s.getServerAndSource(c)
+	err := source.Update(UpdateBootSourceArgs{URL: "http://mirror/"})
+	c.Assert(err, jc.Satisfies, IsNoMatchError)
+}
+
+func (s *bootSourceSuite) TestDelete(c *gc.C) {
+	server, source := s.getServerAndSource(c)
+	server.AddDeleteResponse("/api/2.0/boot-sources/1/", http.StatusNoContent, "")
+	err := source.Delete()
+	c.Assert(err, jc.ErrorIsNil)
+}
+
+func (s *bootSourceSuite) TestDeleteForbidden(c *gc.C) {
+	server, source := s.getServerAndSource(c)
+	server.AddDeleteResponse("/api/2.0/boot-sources/1/", http.StatusForbidden, "admins only")
+	err := source.Delete()
+	c.Assert(err, jc.Satisfies, IsPermissionError)
+}
+
+func (s *bootSourceSuite) TestSelections(c *gc.C) {
+	server, source := s.getServerAndSource(c)
+	server.AddGetResponse("/api/2.0/boot-sources/1/selections/", http.StatusOK, bootSourceSelectionsResponse)
+	selections, err := source.Selections()
+	c.Assert(err, jc.ErrorIsNil)
+	c.Assert(selections, gc.HasLen, 2)
+	c.Check(selections[0].Release(), gc.Equals, "jammy")
+}
+
+func (s *bootSourceSuite) TestCreateSelection(c *gc.C) {
+	server, source := s.getServerAndSource(c)
+	server.AddPostResponse("/api/2.0/boot-sources/1/selections/?op=", http.StatusOK, bootSourceSelectionResponse)
+	selection, err := source.CreateSelection(CreateBootSourceSelectionArgs{
+		OS:            "ubuntu",
+		Release:       "jammy",
+		Architectures: []string{"amd64", "arm64"},
+	})
+	c.Assert(err, jc.ErrorIsNil)
+	c.Check(selection.ID(), gc.Equals, 1)
+
+	form := server.LastRequest().PostForm
+	c.Check(form.Get("os"), gc.Equals, "ubuntu")
+	c.Check(form.Get("release"), gc.Equals, "jammy")
+	c.Check(form["arches"], jc.DeepEquals, []string{"amd64", "arm64"})
+	c.Check(form["subarches"], gc.HasLen, 0)
+	c.Check(form["labels"], gc.HasLen, 0)
+}
+
+func (s *bootSourceSuite) TestCreateSelectionValidates(c *gc.C) {
+	_, source := s.getServerAndSource(c)
+	_, err := source.CreateSelection(CreateBootSourceSelectionArgs{OS: "ubuntu"})
+	c.Assert(err, jc.Satisfies, errors.IsNotValid)
+	c.Assert(err.Error(), gc.Equals, "missing Release not valid")
+}
+
+func (s *bootSourceSuite) TestCreateSelectionBadRequest(c *gc.C) {
+	server, source := s.getServerAndSource(c)
+	server.AddPostResponse("/api/2.0/boot-sources/1/selections/?op=", http.StatusBadRequest, "already selected")
+	_, err := source.CreateSelection(CreateBootSourceSelectionArgs{OS: "ubuntu", Release: "jammy"})
+	c.Assert(err, jc.Satisfies, IsBadRequestError)
+}
+
+// readUploadedFile returns the content of the file sent with the request.
+func readUploadedFile(c *gc.C, request *http.Request, name string) string {
+	c.Assert(request.MultipartForm, gc.NotNil)
+	files := request.MultipartForm.File[name]
+	c.Assert(files, gc.HasLen, 1)
+	file, err := files[0].Open()
+	c.Assert(err, jc.ErrorIsNil)
+	defer file.Close()
+	content, err := io.ReadAll(file)
+	c.Assert(err, jc.ErrorIsNil)
+	return string(content)
+}
+
+const bootSourceResponse = `
+{
+    "id": 1,
+    "url": "http://images.maas.io/ephemeral-v3/stable/",
+    "keyring_filename": "/usr/share/keyrings/ubuntu-cloudimage-keyring.gpg",
+    "keyring_data": "",
+    "created": "2022-01-01T10:00:00.000",
+    "updated": "2022-01-01T10:00:00.000",
+    "resource_uri": "/api/2.0/boot-sources/1/"
+}
+`
+
+const bootSourcesResponse = "[" + bootSourceResponse + `,
+{
+    "id": 2,
+    "url": "http://mirror.internal/images/",
+    "keyring_filename": "",
+    "keyring_data": "a2V5cmluZw==",
+    "created": "2022-01-02T10:00:00.000",
+    "updated": "2022-01-02T10:00:00.000",
+    "resource_uri": "/api/2.0/boot-sources/2/"
+}
+]`
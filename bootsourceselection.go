@@ -0,0 +1,235 @@
+// Copyright 2022 Canonical Ltd.
+// Licensed under the LGPLv3, see LICENCE file for details.
+
+package gomaasapi
+
+import (
+	"net/http"
+
+	"github.com/juju/errors"
+	"github.com/juju/schema"
+	"github.com/juju/version"
+)
+
+type bootSourceSelection struct {
+	controller *controller
+
+	resourceURI string
+
+	id               int
+	bootSourceID     int
+	os               string
+	release          string
+	architectures    []string
+	subArchitectures []string
+	labels           []string
+}
+
+func (s *bootSourceSelection) updateFrom(other *bootSourceSelection) {
+	s.resourceURI = other.resourceURI
+	s.id = other.id
+	s.bootSourceID = other.bootSourceID
+	s.os = other.os
+	s.release = other.release
+	s.architectures = other.architectures
+	s.subArchitectures = other.subArchitectures
+	s.labels = other.labels
+}
+
+// ID implements BootSourceSelection.
+func (s *bootSourceSelection) ID() int {
+	return s.id
+}
+
+// BootSourceID implements BootSourceSelection.
+func (s *bootSourceSelection) BootSourceID() int {
+	return s.bootSourceID
+}
+
+// OS implements BootSourceSelection.
+func (s *bootSourceSelection) OS() string {
+	return s.os
+}
+
+// Release implements BootSourceSelection.
+func (s *bootSourceSelection) Release() string {
+	return s.release
+}
+
+// Architectures implements BootSourceSelection.
+func (s *bootSourceSelection) Architectures() []string {
+	return s.architectures
+}
+
+// SubArchitectures implements BootSourceSelection.
+func (s *bootSourceSelection) SubArchitectures() []string {
+	return s.subArchitectures
+}
+
+// Labels implements BootSourceSelection.
+func (s *bootSourceSelection) Labels() []string {
+	return s.labels
+}
+
+// UpdateBootSourceSelectionArgs is an argument struct for
+// BootSourceSelection.Update. Only the fields that are set are changed.
+type UpdateBootSourceSelectionArgs struct {
+	OS               string
+	Release          string
+	Architectures    []string
+	SubArchitectures []string
+	Labels           []string
+}
+
+// Update implements BootSourceSelection.
+func (s *bootSourceSelection) Update(args UpdateBootSourceSelectionArgs) error {
+	params := NewURLParams()
+	params.MaybeAdd("os", args.OS)
+	params.MaybeAdd("release", args.Release)
+	params.MaybeAddMany("arches", args.Architectures)
+	params.MaybeAddMany("subarches", args.SubArchitectures)
+	params.MaybeAddMany("labels", args.Labels)
+	source, err := s.controller.put(s.resourceURI, params.Values)
+	if err != nil {
+		if svrErr, ok := errors.Cause(err).(ServerError); ok {
+			switch svrErr.StatusCode {
+			case http.StatusNotFound:
+				return wrapError(err, NewNoMatchError(svrErr.BodyMessage))
+			case http.StatusForbidden:
+				return wrapError(err, NewPermissionError(svrErr.BodyMessage))
+			case http.StatusBadRequest:
+				return wrapError(err, NewBadRequestError(svrErr.BodyMessage))
+			}
+		}
+		return NewUnexpectedError(err)
+	}
+
+	response, err := readBootSourceSelection(s.controller.schemaVersion(), source)
+	if err != nil {
+		return errors.Trace(err)
+	}
+	s.updateFrom(response)
+	return nil
+}
+
+// Delete implements BootSourceSelection.
+func (s *bootSourceSelection) Delete() error {
+	err := s.controller.delete(s.resourceURI)
+	if err != nil {
+		if svrErr, ok := errors.Cause(err).(ServerError); ok {
+			switch svrErr.StatusCode {
+			case http.StatusNotFound:
+				return wrapError(err, NewNoMatchError(svrErr.BodyMessage))
+			case http.StatusForbidden:
+				return wrapError(err, NewPermissionError(svrErr.BodyMessage))
+			}
+		}
+		return NewUnexpectedError(err)
+	}
+	return nil
+}
+
+func readBootSourceSelection(controllerVersion version.Number, source interface{}) (*bootSourceSelection, error) {
+	readFunc, err := getBootSourceSelectionDeserializationFunc(controllerVersion)
+	if err != nil {
+		return nil, errors.Trace(err)
+	}
+
+	checker := schema.StringMap(schema.Any())
+	coerced, err := checker.Coerce(source, nil)
+	if err != nil {
+		return nil, WrapWithDeserializationError(err, "boot source selection base schema check failed")
+	}
+	valid := coerced.(map[string]interface{})
+	return readFunc(valid)
+}
+
+func readBootSourceSelections(controllerVersion version.Number, source interface{}) ([]*bootSourceSelection, error) {
+	readFunc, err := getBootSourceSelectionDeserializationFunc(controllerVersion)
+	if err != nil {
+		return nil, errors.Trace(err)
+	}
+
+	checker := schema.List(schema.StringMap(schema.Any()))
+	coerced, err := checker.Coerce(source, nil)
+	if err != nil {
+		return nil, WrapWithDeserializationError(err, "boot source selection base schema check failed")
+	}
+	valid := coerced.([]interface{})
+	return readBootSourceSelectionList(valid, readFunc)
+}
+
+func getBootSourceSelectionDeserializationFunc(controllerVersion version.Number) (bootSourceSelectionDeserializationFunc, error) {
+	var deserialisationVersion version.Number
+	for v := range bootSourceSelectionDeserializationFuncs {
+		if v.Compare(deserialisationVersion) > 0 && v.Compare(controllerVersion) <= 0 {
+			deserialisationVersion = v
+		}
+	}
+	if deserialisationVersion == version.Zero {
+		return nil, NewUnsupportedVersionError("no boot source selection read func for version %s", controllerVersion)
+	}
+	return bootSourceSelectionDeserializationFuncs[deserialisationVersion], nil
+}
+
+// readBootSourceSelectionList expects the values of the sourceList to be
+// string maps.
+func readBootSourceSelectionList(sourceList []interface{}, readFunc bootSourceSelectionDeserializationFunc) ([]*bootSourceSelection, error) {
+	result := make([]*bootSourceSelection, 0, len(sourceList))
+	for i, value := range sourceList {
+		source, ok := value.(map[string]interface{})
+		if !ok {
+			return nil, NewDeserializationError("unexpected value for boot source selection %d, %T", i, value)
+		}
+		selection, err := readFunc(source)
+		if err != nil {
+			return nil, errors.Annotatef(err, "boot source selection %d", i)
+		}
+		result = append(result, selection)
+	}
+	return result, nil
+}
+
+type bootSourceSelectionDeserializationFunc func(map[string]interface{}) (*bootSourceSelection, error)
+
+var bootSourceSelectionDeserializationFuncs = map[version.Number]bootSourceSelectionDeserializationFunc{
+	twoDotOh: bootSourceSelection_2_0,
+}
+
+func bootSourceSelection_2_0(source map[string]interface{}) (*bootSourceSelection, error) {
+	fields := schema.Fields{
+		"resource_uri":   schema.String(),
+		"id":             schema.ForceInt(),
+		"boot_source_id": schema.ForceInt(),
+		"os":             schema.String(),
+		"release":        schema.String(),
+		"arches":         schema.List(schema.String()),
+		"subarches":      schema.List(schema.String()),
+		"labels":         schema.List(schema.String()),
+	}
+	defaults := schema.Defaults{
+		"arches":    []interface{}{},
+		"subarches": []interface{}{},
+		"labels":    []interface{}{},
+	}
+	checker := schema.FieldMap(fields, defaults)
+	coerced, err := checker.Coerce(source, nil)
+	if err != nil {
+		return nil, WrapWithDeserializationError(err, "boot source selection 2.0 schema check failed")
+	}
+	valid := coerced.(map[string]interface{})
+	// From here we know that the map returned from the schema coercion
+	// contains fields of the right type.
+
+	result := &bootSourceSelection{
+		resourceURI:      valid["resource_uri"].(string),
+		id:               valid["id"].(int),
+		bootSourceID:     valid["boot_source_id"].(int),
+		os:               valid["os"].(string),
+		release:          valid["release"].(string),
+		architectures:    convertToStringSlice(valid["arches"]),
+		subArchitectures: convertToStringSlice(valid["subarches"]),
+		labels:           convertToStringSlice(valid["labels"]),
+	}
+	return result, nil
+}
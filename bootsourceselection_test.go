@@ -0,0 +1,124 @@
+// Copyright 2022 Canonical Ltd.
+// Licensed under the LGPLv3, see LICENCE file for details.
+
+package gomaasapi
+
+import (
+	"net/http"
+
+	"github.com/juju/testing"
+	jc "github.com/juju/testing/checkers"
+	"github.com/juju/version"
+	gc "gopkg.in/check.v1"
+)
+
+type bootSourceSelectionSuite struct {
+	testing.LoggingCleanupSuite
+}
+
+var _ = gc.Suite(&bootSourceSelectionSuite{})
+
+func (*bootSourceSelectionSuite) TestReadBootSourceSelectionsBadSchema(c *gc.C) {
+	_, err := readBootSourceSelections(twoDotOh, "wat?")
+	c.Check(err, jc.Satisfies, IsDeserializationError)
+	c.Assert(err.Error(), gc.Equals, `boot source selection base schema check failed: expected list, got string("wat?")`)
+}
+
+func (*bootSourceSelectionSuite) TestReadBootSourceSelections(c *gc.C) {
+	selections, err := readBootSourceSelections(twoDotOh, parseJSON(c, bootSourceSelectionsResponse))
+	c.Assert(err, jc.ErrorIsNil)
+	c.Assert(selections, gc.HasLen, 2)
+
+	selection := selections[0]
+	c.Check(selection.ID(), gc.Equals, 1)
+	c.Check(selection.BootSourceID(), gc.Equals, 1)
+	c.Check(selection.OS(), gc.Equals, "ubuntu")
+	c.Check(selection.Release(), gc.Equals, "jammy")
+	c.Check(selection.Architectures(), jc.DeepEquals, []string{"amd64", "arm64"})
+	c.Check(selection.SubArchitectures(), jc.DeepEquals, []string{"*"})
+	c.Check(selection.Labels(), jc.DeepEquals, []string{"*"})
+}
+
+func (*bootSourceSelectionSuite) TestLowVersion(c *gc.C) {
+	_, err := readBootSourceSelections(version.MustParse("1.9.0"), parseJSON(c, bootSourceSelectionsResponse))
+	c.Assert(err, jc.Satisfies, IsUnsupportedVersionError)
+}
+
+func (*bootSourceSelectionSuite) TestHighVersion(c *gc.C) {
+	selections, err := readBootSourceSelections(version.MustParse("2.1.9"), parseJSON(c, bootSourceSelectionsResponse))
+	c.Assert(err, jc.ErrorIsNil)
+	c.Assert(selections, gc.HasLen, 2)
+}
+
+func (s *bootSourceSelectionSuite) getServerAndSelection(c *gc.C) (*SimpleTestServer, BootSourceSelection) {
+	server, controller := createTestServerController(c, s)
+	server.AddGetResponse("/api/2.0/boot-sources/", http.StatusOK, bootSourcesResponse)
+	server.AddGetResponse("/api/2.0/boot-sources/1/selections/", http.StatusOK, bootSourceSelectionsResponse)
+	sources, err := controller.BootSources()
+	c.Assert(err, jc.ErrorIsNil)
+	selections, err := sources[0].Selections()
+	c.Assert(err, jc.ErrorIsNil)
+	return server, selections[0]
+}
+
+func (s *bootSourceSelectionSuite) TestUpdate(c *gc.C) {
+	server, selection := s.getServerAndSelection(c)
+	response := updateJSONMap(c, bootSourceSelectionResponse, map[string]interface{}{
+		"arches": []string{"amd64"},
+	})
+	server.AddPutResponse("/api/2.0/boot-sources/1/selections/1/", http.StatusOK, response)
+	err := selection.Update(UpdateBootSourceSelectionArgs{Architectures: []string{"amd64"}})
+	c.Assert(err, jc.ErrorIsNil)
+	c.Check(selection.Architectures(), jc.DeepEquals, []string{"amd64"})
+
+	form := server.LastRequest().PostForm
+	c.Check(form["arches"], jc.DeepEquals, []string{"amd64"})
+	c.Check(form["os"], gc.HasLen, 0)
+	c.Check(form["release"], gc.HasLen, 0)
+}
+
+func (s *bootSourceSelectionSuite) TestUpdateBadRequest(c *gc.C) {
+	server, selection := s.getServerAndSelection(c)
+	server.AddPutResponse("/api/2.0/boot-sources/1/selections/1/", http.StatusBadRequest, "bad release")
+	err := selection.Update(UpdateBootSourceSelectionArgs{Release: "wat"})
+	c.Assert(err, jc.Satisfies, IsBadRequestError)
+}
+
+func (s *bootSourceSelectionSuite) TestDelete(c *gc.C) {
+	server, selection := s.getServerAndSelection(c)
+	server.AddDeleteResponse("/api/2.0/boot-sources/1/selections/1/", http.StatusNoContent, "")
+	err := selection.Delete()
+	c.Assert(err, jc.ErrorIsNil)
+}
+
+func (s *bootSourceSelectionSuite) TestDeleteMissing(c *gc.C) {
+	_, selection := s.getServerAndSelection(c)
+	err := selection.Delete()
+	c.Assert(err, jc.Satisfies, IsNoMatchError)
+}
+
+const bootSourceSelectionResponse = `
+{
+    "id": 1,
+    "boot_source_id": 1,
+    "os": "ubuntu",
+    "release": "jammy",
+    "arches": ["amd64", "arm64"],
+    "subarches": ["*"],
+    "labels": ["*"],
+    "resource_uri": "/api/2.0/boot-sources/1/selections/1/"
+}
+`
+
+const bootSourceSelectionsResponse = "[" + bootSourceSelectionResponse + `,
+{
+    "id": 2,
+    "boot_source_id": 1,
+    "os": "ubuntu",
+    "release": "focal",
+    "arches": ["amd64"],
+    "subarches": ["*"],
+    "labels": ["*"],
+    "resource_uri": "/api/2.0/boot-sources/1/selections/2/"
+}
+]`
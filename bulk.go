@@ -0,0 +1,130 @@
+// Copyright 2022 Canonical Ltd.
+// Licensed under the LGPLv3, see LICENCE file for details.
+
+package gomaasapi
+
+import (
+	"context"
+	"fmt"
+	"sync"
+	"time"
+
+	"github.com/juju/errors"
+)
+
+// ForEachMachineArgs is an argument struct for Controller.ForEachMachine.
+type ForEachMachineArgs struct {
+	// SystemIDs are the machines the operation is run on.
+	SystemIDs []string
+	// Parallelism is the number of operations running at once. It
+	// defaults to 10.
+	Parallelism int
+	// Interval, if set, is the time between the starts of two
+	// operations, which limits the rate of the requests sent to MAAS.
+	Interval time.Duration
+}
+
+// Validate ensures that the settings aren't negative and that no machine
+// is given twice.
+func (a ForEachMachineArgs) Validate() error {
+	if a.Parallelism < 0 {
+		return errors.NotValidf("negative Parallelism")
+	}
+	if a.Interval < 0 {
+		return errors.NotValidf("negative Interval")
+	}
+	seen := make(map[string]bool, len(a.SystemIDs))
+	for _, id := range a.SystemIDs {
+		if seen[id] {
+			return errors.NotValidf("duplicate system ID %q", id)
+		}
+		seen[id] = true
+	}
+	return nil
+}
+
+// ForEachMachine implements Controller.
+func (c *controller) ForEachMachine(ctx context.Context, args ForEachMachineArgs, fn func(Machine) error) error {
+	if err := args.Validate(); err != nil {
+		return errors.Trace(err)
+	}
+	if len(args.SystemIDs) == 0 {
+		return nil
+	}
+	parallelism := args.Parallelism
+	if parallelism == 0 {
+		parallelism = 10
+	}
+	machines, err := c.WithContext(ctx).Machines(MachinesArgs{SystemIDs: args.SystemIDs})
+	if err != nil {
+		return errors.Trace(err)
+	}
+	byID := make(map[string]Machine, len(machines))
+	for _, m := range machines {
+		byID[m.SystemID()] = m
+	}
+
+	var mu sync.Mutex
+	failures := make(map[string]error)
+	fail := func(id string, err error) {
+		mu.Lock()
+		defer mu.Unlock()
+		failures[id] = err
+	}
+	var found []Machine
+	for _, id := range args.SystemIDs {
+		if m, ok := byID[id]; ok {
+			found = append(found, m)
+		} else {
+			fail(id, NewNoMatchError(fmt.Sprintf("machine %q not found", id)))
+		}
+	}
+
+	work := make(chan Machine)
+	var wg sync.WaitGroup
+	for i := 0; i < parallelism && i < len(found); i++ {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			for m := range work {
+				if err := fn(m); err != nil {
+					fail(m.SystemID(), err)
+				}
+			}
+		}()
+	}
+	// The machines that haven't been started when the context is done
+	// fail with its error.
+	var wait <-chan time.Time
+	for i, m := range found {
+		if wait != nil {
+			select {
+			case <-wait:
+			case <-ctx.Done():
+			}
+		}
+		sent := false
+		if ctx.Err() == nil {
+			select {
+			case work <- m:
+				sent = true
+			case <-ctx.Done():
+			}
+		}
+		if !sent {
+			for _, m := range found[i:] {
+				fail(m.SystemID(), ctx.Err())
+			}
+			break
+		}
+		if args.Interval > 0 {
+			wait = time.After(args.Interval)
+		}
+	}
+	close(work)
+	wg.Wait()
+	if len(failures) > 0 {
+		return NewBulkError("machines", len(args.SystemIDs), failures)
+	}
+	return nil
+}
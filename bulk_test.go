@@ -0,0 +1,152 @@
+// Copyright 2022 Canonical Ltd.
+// Licensed under the LGPLv3, see LICENCE file for details.
+
+package gomaasapi
+
+import (
+	"context"
+	"net/http"
+	"sync"
+	"sync/atomic"
+	"time"
+
+	"github.com/juju/errors"
+	"github.com/juju/testing"
+	jc "github.com/juju/testing/checkers"
+	gc "gopkg.in/check.v1"
+)
+
+type bulkSuite struct {
+	testing.LoggingCleanupSuite
+}
+
+var _ = gc.Suite(&bulkSuite{})
+
+// addMachines adds the listing of the machines with the given IDs.
+func (s *bulkSuite) addMachines(c *gc.C, server *SimpleTestServer, query string, ids ...string) {
+	listing := "["
+	for i, id := range ids {
+		if i > 0 {
+			listing += ", "
+		}
+		listing += updateJSONMap(c, machineResponse, map[string]interface{}{"system_id": id})
+	}
+	server.AddGetResponse("/api/2.0/machines/?"+query, http.StatusOK, listing+"]")
+}
+
+func (s *bulkSuite) TestForEachMachine(c *gc.C) {
+	server, controller := createTestServerController(c, s)
+	s.addMachines(c, server, "id=a&id=b&id=c&id=d", "d", "c", "b", "a")
+
+	var mu sync.Mutex
+	var called []string
+	var running, maxRunning int64
+	err := controller.ForEachMachine(context.Background(), ForEachMachineArgs{
+		SystemIDs:   []string{"a", "b", "c", "d"},
+		Parallelism: 2,
+	}, func(m Machine) error {
+		n := atomic.AddInt64(&running, 1)
+		defer atomic.AddInt64(&running, -1)
+		mu.Lock()
+		called = append(called, m.SystemID())
+		if n > maxRunning {
+			maxRunning = n
+		}
+		mu.Unlock()
+		time.Sleep(10 * time.Millisecond)
+		return nil
+	})
+	c.Assert(err, jc.ErrorIsNil)
+	c.Check(called, jc.SameContents, []string{"a", "b", "c", "d"})
+	c.Check(maxRunning, gc.Equals, int64(2))
+}
+
+func (s *bulkSuite) TestForEachMachineFailures(c *gc.C) {
+	server, controller := createTestServerController(c, s)
+	s.addMachines(c, server, "id=a&id=b&id=missing", "a", "b")
+
+	err := controller.ForEachMachine(context.Background(), ForEachMachineArgs{
+		SystemIDs: []string{"a", "b", "missing"},
+	}, func(m Machine) error {
+		if m.SystemID() == "b" {
+			return errors.New("boom")
+		}
+		return nil
+	})
+	c.Assert(err, jc.Satisfies, IsBulkError)
+	c.Check(err, gc.ErrorMatches, "2 of 3 machines failed")
+	failures := errors.Cause(err).(*BulkError).Failures
+	c.Check(failures, gc.HasLen, 2)
+	c.Check(failures["b"], gc.ErrorMatches, "boom")
+	c.Check(failures["missing"], jc.Satisfies, IsNoMatchError)
+}
+
+func (s *bulkSuite) TestForEachMachineInterval(c *gc.C) {
+	server, controller := createTestServerController(c, s)
+	s.addMachines(c, server, "id=a&id=b&id=c", "a", "b", "c")
+
+	var mu sync.Mutex
+	var started []time.Time
+	err := controller.ForEachMachine(context.Background(), ForEachMachineArgs{
+		SystemIDs: []string{"a", "b", "c"},
+		Interval:  20 * time.Millisecond,
+	}, func(m Machine) error {
+		mu.Lock()
+		defer mu.Unlock()
+		started = append(started, time.Now())
+		return nil
+	})
+	c.Assert(err, jc.ErrorIsNil)
+	c.Assert(started, gc.HasLen, 3)
+	c.Check(started[2].Sub(started[0]) >= 40*time.Millisecond, jc.IsTrue)
+}
+
+func (s *bulkSuite) TestForEachMachineCancelled(c *gc.C) {
+	server, controller := createTestServerController(c, s)
+	s.addMachines(c, server, "id=a&id=b&id=c", "a", "b", "c")
+
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+	var called int64
+	err := controller.ForEachMachine(ctx, ForEachMachineArgs{
+		SystemIDs:   []string{"a", "b", "c"},
+		Parallelism: 1,
+		Interval:    time.Minute,
+	}, func(m Machine) error {
+		atomic.AddInt64(&called, 1)
+		cancel()
+		return nil
+	})
+	c.Assert(err, jc.Satisfies, IsBulkError)
+	failures := errors.Cause(err).(*BulkError).Failures
+	c.Check(failures, gc.HasLen, 2)
+	c.Check(failures["b"], gc.Equals, context.Canceled)
+	c.Check(failures["c"], gc.Equals, context.Canceled)
+	c.Check(called, gc.Equals, int64(1))
+}
+
+func (s *bulkSuite) TestForEachMachineListingError(c *gc.C) {
+	_, controller := createTestServerController(c, s)
+	err := controller.ForEachMachine(context.Background(), ForEachMachineArgs{
+		SystemIDs: []string{"a"},
+	}, func(Machine) error {
+		c.Fatalf("unexpected call")
+		return nil
+	})
+	c.Assert(err, gc.NotNil)
+	c.Check(IsBulkError(err), jc.IsFalse)
+}
+
+func (s *bulkSuite) TestForEachMachineValidate(c *gc.C) {
+	c.Check(ForEachMachineArgs{SystemIDs: []string{"a", "b"}}.Validate(), jc.ErrorIsNil)
+	c.Check(ForEachMachineArgs{Parallelism: -1}.Validate(), gc.ErrorMatches, "negative Parallelism not valid")
+	c.Check(ForEachMachineArgs{Interval: -1}.Validate(), gc.ErrorMatches, "negative Interval not valid")
+	c.Check(ForEachMachineArgs{SystemIDs: []string{"a", "a"}}.Validate(), gc.ErrorMatches, `duplicate system ID "a" not valid`)
+
+	_, controller := createTestServerController(c, s)
+	err := controller.ForEachMachine(context.Background(), ForEachMachineArgs{}, func(Machine) error {
+		c.Fatalf("unexpected call")
+		return nil
+	})
+	c.Check(err, jc.ErrorIsNil)
+}
@@ -0,0 +1,239 @@
+// Copyright 2022 Canonical Ltd.
+// Licensed under the LGPLv3, see LICENCE file for details.
+
+package gomaasapi
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"io/ioutil"
+	"net/http"
+	"net/url"
+	"strconv"
+	"strings"
+	"sync"
+
+	"github.com/juju/errors"
+)
+
+// Cassette holds the requests sent to MAAS and their responses, as recorded
+// by a CassetteRecorder and replayed by a CassettePlayer. Cassettes are saved
+// as JSON golden files, so that tests can be run against the payloads of
+// real MAAS regions of different versions.
+type Cassette struct {
+	Interactions []CassetteInteraction `json:"interactions"`
+}
+
+// CassetteInteraction is a request and its response.
+type CassetteInteraction struct {
+	Request  CassetteRequest  `json:"request"`
+	Response CassetteResponse `json:"response"`
+}
+
+// CassetteRequest is a recorded request. The credentials and the fields of
+// the body that hold passwords, secrets, tokens or keys are replaced by
+// "REDACTED", as for a DebugRecorder.
+type CassetteRequest struct {
+	Method string `json:"method"`
+	// URL is the path and the query of the request, so that the cassette
+	// can be replayed for a MAAS at another address.
+	URL    string      `json:"url"`
+	Header http.Header `json:"header,omitempty"`
+	Body   string      `json:"body,omitempty"`
+}
+
+// CassetteResponse is a recorded response, sanitized as the requests.
+type CassetteResponse struct {
+	StatusCode int         `json:"status_code"`
+	Header     http.Header `json:"header,omitempty"`
+	Body       string      `json:"body,omitempty"`
+}
+
+// ReadCassette reads a cassette saved with CassetteRecorder.Save.
+func ReadCassette(path string) (Cassette, error) {
+	data, err := ioutil.ReadFile(path)
+	if err != nil {
+		return Cassette{}, errors.Trace(err)
+	}
+	var cassette Cassette
+	if err := json.Unmarshal(data, &cassette); err != nil {
+		return Cassette{}, errors.NotValidf("cassette %q: %v", path, err)
+	}
+	return cassette, nil
+}
+
+// CassetteRecorder is an http.RoundTripper recording the requests it sends
+// through another one, and their responses, to a Cassette. It is used as
+// the ControllerArgs.Transport of a controller for a real MAAS. Errors that
+// prevented a response from being received aren't recorded.
+type CassetteRecorder struct {
+	base http.RoundTripper
+
+	mu           sync.Mutex
+	interactions []CassetteInteraction
+}
+
+// NewCassetteRecorder returns a recorder sending the requests through the
+// base round tripper, or through http.DefaultTransport if it is nil.
+func NewCassetteRecorder(base http.RoundTripper) *CassetteRecorder {
+	if base == nil {
+		base = http.DefaultTransport
+	}
+	return &CassetteRecorder{base: base}
+}
+
+// RoundTrip implements http.RoundTripper.
+func (r *CassetteRecorder) RoundTrip(request *http.Request) (*http.Response, error) {
+	contentType := request.Header.Get("Content-Type")
+	interaction := CassetteInteraction{
+		Request: CassetteRequest{
+			Method: request.Method,
+			URL:    request.URL.RequestURI(),
+			Header: sanitizeDebugHeader(request.Header),
+		},
+	}
+	if request.Body != nil {
+		content, err := readAndClose(request.Body)
+		if err != nil {
+			return nil, errors.Trace(err)
+		}
+		// The body of the request is consumed by the base round tripper,
+		// so it is replaced by a copy.
+		clone := request.Clone(request.Context())
+		clone.Body = ioutil.NopCloser(bytes.NewReader(content))
+		request = clone
+		interaction.Request.Body = string(sanitizeCassetteBody(contentType, content))
+	}
+	response, err := r.base.RoundTrip(request)
+	if err != nil {
+		return nil, err
+	}
+	content, err := readAndClose(response.Body)
+	if err != nil {
+		return nil, errors.Trace(err)
+	}
+	response.Body = ioutil.NopCloser(bytes.NewReader(content))
+	interaction.Response = CassetteResponse{
+		StatusCode: response.StatusCode,
+		Header:     sanitizeDebugHeader(response.Header),
+		Body:       string(sanitizeCassetteBody(response.Header.Get("Content-Type"), content)),
+	}
+	r.mu.Lock()
+	r.interactions = append(r.interactions, interaction)
+	r.mu.Unlock()
+	return response, nil
+}
+
+// sanitizeCassetteBody redacts the secrets of the body. Multipart bodies,
+// whose parts may hold secrets as well as files, are replaced by a note.
+func sanitizeCassetteBody(contentType string, content []byte) []byte {
+	if len(content) > 0 && strings.HasPrefix(contentType, "multipart/") {
+		return []byte(fmt.Sprintf("[multipart body of %d bytes]", len(content)))
+	}
+	return sanitizeDebugBody(contentType, content)
+}
+
+// Cassette returns the interactions recorded so far.
+func (r *CassetteRecorder) Cassette() Cassette {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	return Cassette{
+		Interactions: append([]CassetteInteraction(nil), r.interactions...),
+	}
+}
+
+// Save writes the interactions recorded so far to a golden file, which can
+// be replayed with ReadCassette and NewCassettePlayer.
+func (r *CassetteRecorder) Save(path string) error {
+	data, err := json.MarshalIndent(r.Cassette(), "", "  ")
+	if err != nil {
+		return errors.Trace(err)
+	}
+	return errors.Trace(ioutil.WriteFile(path, append(data, '\n'), 0644))
+}
+
+// CassettePlayer is an http.RoundTripper answering the requests with the
+// responses of a Cassette, without connecting to MAAS. It is used as the
+// ControllerArgs.Transport of a controller in tests. Requests are matched
+// to the interactions by their method, path and query, and an interaction
+// is only replayed once, so that repeated requests get the successive
+// responses that were recorded. Requests that don't match an interaction
+// that hasn't been replayed fail with a NotFound error.
+type CassettePlayer struct {
+	mu           sync.Mutex
+	interactions []CassetteInteraction
+	replayed     []bool
+}
+
+// NewCassettePlayer returns a player replaying the interactions of the
+// cassette.
+func NewCassettePlayer(cassette Cassette) *CassettePlayer {
+	return &CassettePlayer{
+		interactions: cassette.Interactions,
+		replayed:     make([]bool, len(cassette.Interactions)),
+	}
+}
+
+// RoundTrip implements http.RoundTripper.
+func (p *CassettePlayer) RoundTrip(request *http.Request) (*http.Response, error) {
+	if request.Body != nil {
+		if _, err := readAndClose(request.Body); err != nil {
+			return nil, errors.Trace(err)
+		}
+	}
+	p.mu.Lock()
+	defer p.mu.Unlock()
+	for i, interaction := range p.interactions {
+		if p.replayed[i] || !cassetteRequestMatches(interaction.Request, request) {
+			continue
+		}
+		p.replayed[i] = true
+		recorded := interaction.Response
+		header := recorded.Header.Clone()
+		if header == nil {
+			header = make(http.Header)
+		}
+		header.Set("Content-Length", strconv.Itoa(len(recorded.Body)))
+		return &http.Response{
+			Status:        fmt.Sprintf("%d %s", recorded.StatusCode, http.StatusText(recorded.StatusCode)),
+			StatusCode:    recorded.StatusCode,
+			Proto:         "HTTP/1.1",
+			ProtoMajor:    1,
+			ProtoMinor:    1,
+			Header:        header,
+			Body:          ioutil.NopCloser(bytes.NewReader([]byte(recorded.Body))),
+			ContentLength: int64(len(recorded.Body)),
+			Request:       request,
+		}, nil
+	}
+	return nil, errors.NotFoundf("recorded response to %s %s", request.Method, request.URL.RequestURI())
+}
+
+// cassetteRequestMatches reports whether the request has the method, path
+// and query of the recorded one, in any order of the query parameters.
+func cassetteRequestMatches(recorded CassetteRequest, request *http.Request) bool {
+	if recorded.Method != request.Method {
+		return false
+	}
+	recordedURL, err := url.Parse(recorded.URL)
+	if err != nil {
+		return false
+	}
+	return recordedURL.Path == request.URL.Path &&
+		recordedURL.Query().Encode() == request.URL.Query().Encode()
+}
+
+// Unplayed returns the interactions that haven't been replayed, so that
+// tests can check that the client sent all the requests that were recorded.
+func (p *CassettePlayer) Unplayed() []CassetteInteraction {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+	var result []CassetteInteraction
+	for i, interaction := range p.interactions {
+		if !p.replayed[i] {
+			result = append(result, interaction)
+		}
+	}
+	return result
+}
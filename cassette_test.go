@@ -0,0 +1,179 @@
+// Copyright 2022 Canonical Ltd.
+// Licensed under the LGPLv3, see LICENCE file for details.
+
+package gomaasapi
+
+import (
+	"io/ioutil"
+	"net/http"
+	"net/url"
+	"path/filepath"
+	"strings"
+
+	"github.com/juju/errors"
+	"github.com/juju/testing"
+	jc "github.com/juju/testing/checkers"
+	gc "gopkg.in/check.v1"
+)
+
+type cassetteSuite struct {
+	testing.LoggingCleanupSuite
+}
+
+var _ = gc.Suite(&cassetteSuite{})
+
+// record returns the cassette of the requests sent by fn to a test server,
+// and the content of its golden file.
+func (s *cassetteSuite) record(c *gc.C, setup func(*SimpleTestServer), fn func(Controller)) (Cassette, string) {
+	server := NewSimpleServer()
+	server.AddGetResponse("/api/2.0/users/?op=whoami", http.StatusOK, `"captain awesome"`)
+	server.AddGetResponse("/api/2.0/version/", http.StatusOK, versionResponse)
+	setup(server)
+	server.Start()
+	defer server.Close()
+
+	recorder := NewCassetteRecorder(nil)
+	controller, err := NewController(ControllerArgs{
+		BaseURL:   server.URL,
+		APIKey:    "fake:as:key",
+		Transport: recorder,
+	})
+	c.Assert(err, jc.ErrorIsNil)
+	fn(controller)
+
+	path := filepath.Join(c.MkDir(), "cassette.json")
+	c.Assert(recorder.Save(path), jc.ErrorIsNil)
+	content, err := ioutil.ReadFile(path)
+	c.Assert(err, jc.ErrorIsNil)
+	cassette, err := ReadCassette(path)
+	c.Assert(err, jc.ErrorIsNil)
+	return cassette, string(content)
+}
+
+// replay returns a controller replaying the cassette.
+func (s *cassetteSuite) replay(c *gc.C, player *CassettePlayer) Controller {
+	controller, err := NewController(ControllerArgs{
+		BaseURL:   "http://maas.invalid/",
+		APIKey:    "fake:as:key",
+		Transport: player,
+	})
+	c.Assert(err, jc.ErrorIsNil)
+	return controller
+}
+
+func (s *cassetteSuite) TestRecordAndReplay(c *gc.C) {
+	cassette, _ := s.record(c, func(server *SimpleTestServer) {
+		server.AddGetResponse("/api/2.0/zones/", http.StatusOK, zoneResponse)
+		server.AddGetResponse("/api/2.0/zones/", http.StatusOK, "[]")
+	}, func(controller Controller) {
+		zones, err := controller.Zones()
+		c.Assert(err, jc.ErrorIsNil)
+		c.Assert(zones, gc.HasLen, 2)
+		zones, err = controller.Zones()
+		c.Assert(err, jc.ErrorIsNil)
+		c.Assert(zones, gc.HasLen, 0)
+	})
+	c.Assert(cassette.Interactions, gc.HasLen, 4)
+	c.Check(cassette.Interactions[2].Request.Method, gc.Equals, "GET")
+	c.Check(cassette.Interactions[2].Request.URL, gc.Equals, "/api/2.0/zones/")
+	c.Check(cassette.Interactions[2].Response.StatusCode, gc.Equals, http.StatusOK)
+	c.Check(cassette.Interactions[2].Response.Body, gc.Equals, zoneResponse)
+
+	player := NewCassettePlayer(cassette)
+	controller := s.replay(c, player)
+	zones, err := controller.Zones()
+	c.Assert(err, jc.ErrorIsNil)
+	c.Check(zones, gc.HasLen, 2)
+	c.Check(player.Unplayed(), gc.HasLen, 1)
+	zones, err = controller.Zones()
+	c.Assert(err, jc.ErrorIsNil)
+	c.Check(zones, gc.HasLen, 0)
+	c.Check(player.Unplayed(), gc.HasLen, 0)
+
+	_, err = controller.Zones()
+	c.Check(err, gc.ErrorMatches, `.*recorded response to GET /api/2.0/zones/ not found`)
+}
+
+func (s *cassetteSuite) TestQueryOrderIgnored(c *gc.C) {
+	cassette, _ := s.record(c, func(server *SimpleTestServer) {
+		server.AddGetResponse("/api/2.0/vm-hosts/?op=parameters&type=lxd", http.StatusOK, `{"type": "lxd"}`)
+	}, func(controller Controller) {
+		_, err := controller.Do("GET", "vm-hosts", "parameters", url.Values{"type": {"lxd"}})
+		c.Assert(err, jc.ErrorIsNil)
+	})
+	cassette.Interactions[2].Request.URL = "/api/2.0/vm-hosts/?type=lxd&op=parameters"
+
+	player := NewCassettePlayer(cassette)
+	_, err := s.replay(c, player).Do("GET", "vm-hosts", "parameters", url.Values{"type": {"lxd"}})
+	c.Assert(err, jc.ErrorIsNil)
+	c.Check(player.Unplayed(), gc.HasLen, 0)
+}
+
+func (s *cassetteSuite) TestErrorsReplayed(c *gc.C) {
+	cassette, _ := s.record(c, func(server *SimpleTestServer) {
+		server.AddGetResponse("/api/2.0/switches/", http.StatusNotFound, "no such endpoint")
+	}, func(controller Controller) {
+		_, err := controller.Do("GET", "switches", "", nil)
+		c.Assert(err, gc.NotNil)
+	})
+
+	_, err := s.replay(c, NewCassettePlayer(cassette)).Do("GET", "switches", "", nil)
+	svrErr, ok := GetServerError(err)
+	c.Assert(ok, jc.IsTrue)
+	c.Check(svrErr.StatusCode, gc.Equals, http.StatusNotFound)
+	c.Check(svrErr.BodyMessage, gc.Equals, "no such endpoint")
+}
+
+func (s *cassetteSuite) TestSanitized(c *gc.C) {
+	cassette, content := s.record(c, func(server *SimpleTestServer) {
+		server.AddPostResponse("/api/2.0/machines/abc/?op=update", http.StatusOK, `{"system_id": "abc"}`)
+	}, func(controller Controller) {
+		_, err := controller.Do("POST", "machines/abc", "update", url.Values{"power_pass": {"hunter2"}, "hostname": {"abc"}})
+		c.Assert(err, jc.ErrorIsNil)
+	})
+	c.Check(strings.Contains(content, "hunter2"), jc.IsFalse)
+	c.Check(strings.Contains(content, "fake"), jc.IsFalse)
+	request := cassette.Interactions[2].Request
+	c.Check(request.Header.Get("Authorization"), gc.Equals, "REDACTED")
+	c.Check(request.Body, gc.Equals, "hostname=abc&power_pass=REDACTED")
+
+	// The sanitized requests still match when they are replayed.
+	player := NewCassettePlayer(cassette)
+	_, err := s.replay(c, player).Do("POST", "machines/abc", "update", url.Values{"power_pass": {"hunter2"}})
+	c.Assert(err, jc.ErrorIsNil)
+	c.Check(player.Unplayed(), gc.HasLen, 0)
+}
+
+func (s *cassetteSuite) TestKeysSanitized(c *gc.C) {
+	cassette, content := s.record(c, func(server *SimpleTestServer) {
+		server.AddPostResponse("/api/2.0/pods/?op=", http.StatusOK, updateJSONMap(c, vmHostResponse, map[string]interface{}{"type": "lxd"}))
+		server.AddRouteResponses(SimpleRoute{Method: "GET", Path: "/api/2.0/license-keys/"}, SimpleResponse{
+			Status: http.StatusOK,
+			Header: http.Header{"Content-Type": {"application/json"}},
+			Body:   licenseKeysResponse,
+		})
+	}, func(controller Controller) {
+		_, err := controller.CreateVMHost(CreateVMHostArgs{
+			Type:         VMHostTypeLXD,
+			PowerAddress: "10.0.0.2:8443",
+			Certificate:  "CERTIFICATE",
+			Key:          "PRIVATEKEY",
+		})
+		c.Assert(err, jc.ErrorIsNil)
+		keys, err := controller.LicenseKeys()
+		c.Assert(err, jc.ErrorIsNil)
+		c.Assert(keys, gc.HasLen, 1)
+	})
+	c.Check(strings.Contains(content, "PRIVATEKEY"), jc.IsFalse)
+	c.Check(strings.Contains(content, "CERTIFICATE"), jc.IsFalse)
+	c.Check(strings.Contains(content, "AAAAA-BBBBB"), jc.IsFalse)
+	c.Check(cassette.Interactions[2].Request.Body, gc.Equals,
+		"certificate=REDACTED&key=REDACTED&power_address=10.0.0.2%3A8443&type=lxd")
+}
+
+func (s *cassetteSuite) TestReadCassetteInvalid(c *gc.C) {
+	path := filepath.Join(c.MkDir(), "cassette.json")
+	c.Assert(ioutil.WriteFile(path, []byte("not JSON"), 0644), jc.ErrorIsNil)
+	_, err := ReadCassette(path)
+	c.Check(err, jc.Satisfies, errors.IsNotValid)
+}
@@ -0,0 +1,183 @@
+// Copyright 2022 Canonical Ltd.
+// Licensed under the LGPLv3, see LICENCE file for details.
+
+package gomaasapi
+
+import (
+	"context"
+	"sort"
+	"time"
+
+	"github.com/juju/errors"
+)
+
+// ChangeFeedArgs is an argument struct for Controller.ChangeFeed.
+type ChangeFeedArgs struct {
+	// After is the ID of the last event already handled, such as the
+	// After of the last MachineChanges of a previous feed. If it is zero,
+	// the feed starts after the latest event at the time it is created.
+	After int
+	// Interval is the wait between two queries of the events. It defaults
+	// to 10 seconds.
+	Interval time.Duration
+	// Level is the least severe level of the events considered. It
+	// defaults to EventLevelDebug, so that all changes are reported.
+	Level string
+	// Limit is the number of events read with each request, at most 1000.
+	// It defaults to 100.
+	Limit int
+}
+
+// Validate ensures that the values are in range.
+func (a ChangeFeedArgs) Validate() error {
+	if a.After < 0 {
+		return errors.NotValidf("negative After")
+	}
+	if a.Interval < 0 {
+		return errors.NotValidf("negative Interval")
+	}
+	if a.Level != "" && !eventLevels.Contains(a.Level) {
+		return errors.NotValidf("Level %q", a.Level)
+	}
+	if a.Limit < 0 || a.Limit > maxEventsLimit {
+		return errors.NotValidf("Limit %d", a.Limit)
+	}
+	return nil
+}
+
+// MachineChanges lists the machines that changed since the previous
+// MachineChanges of a ChangeFeed.
+type MachineChanges struct {
+	// SystemIDs are the system IDs of the nodes with new events, in the
+	// order of their first event. Nodes other than machines, such as
+	// devices, are included when MAAS records events for them.
+	SystemIDs []string
+	// After is the ID of the latest event read, from which a new feed can
+	// resume, see ChangeFeedArgs.After.
+	After int
+}
+
+type changeFeed struct {
+	controller Controller
+	args       ChangeFeedArgs
+	after      int
+
+	changes chan MachineChanges
+	done    chan struct{}
+	stop    context.CancelFunc
+}
+
+// ChangeFeed implements Controller.
+func (c *controller) ChangeFeed(ctx context.Context, args ChangeFeedArgs) (ChangeFeed, error) {
+	if err := args.Validate(); err != nil {
+		return nil, errors.Trace(err)
+	}
+	if args.Interval == 0 {
+		args.Interval = 10 * time.Second
+	}
+	if args.Level == "" {
+		args.Level = EventLevelDebug
+	}
+	if args.Limit == 0 {
+		args.Limit = 100
+	}
+	ctx, stop := context.WithCancel(ctx)
+	f := &changeFeed{
+		controller: c.WithContext(ctx),
+		args:       args,
+		after:      args.After,
+		changes:    make(chan MachineChanges),
+		done:       make(chan struct{}),
+		stop:       stop,
+	}
+	if f.after == 0 {
+		// The latest event is read before returning, so that a MAAS whose
+		// events can't be read is reported straight away.
+		page, err := f.controller.Events(EventsArgs{Level: args.Level, Limit: 1})
+		if err != nil {
+			stop()
+			return nil, errors.Trace(err)
+		}
+		for _, event := range page.Events() {
+			f.after = event.ID()
+		}
+	}
+	go f.loop(ctx)
+	return f, nil
+}
+
+// Changes implements ChangeFeed.
+func (f *changeFeed) Changes() <-chan MachineChanges {
+	return f.changes
+}
+
+// Close implements ChangeFeed.
+func (f *changeFeed) Close() error {
+	f.stop()
+	<-f.done
+	return nil
+}
+
+func (f *changeFeed) loop(ctx context.Context) {
+	defer close(f.done)
+	defer close(f.changes)
+	for {
+		select {
+		case <-ctx.Done():
+			return
+		case <-time.After(f.args.Interval):
+		}
+		changes, err := f.poll()
+		if err != nil {
+			if ctx.Err() != nil {
+				return
+			}
+			// The changes read before the failure are still reported,
+			// and the next poll resumes after them.
+			logger.Warningf("reading the MAAS events after %d: %v", changes.After, err)
+		}
+		if len(changes.SystemIDs) != 0 {
+			select {
+			case f.changes <- changes:
+			case <-ctx.Done():
+				return
+			}
+		}
+		f.after = changes.After
+	}
+}
+
+// poll reads the events following the cursor of the feed. MAAS returns
+// the oldest events after the cursor, so the events are read until a page
+// isn't full.
+func (f *changeFeed) poll() (MachineChanges, error) {
+	changes := MachineChanges{After: f.after}
+	seen := make(map[string]bool)
+	for {
+		page, err := f.controller.Events(EventsArgs{
+			Level: f.args.Level,
+			Limit: f.args.Limit,
+			After: changes.After,
+		})
+		if err != nil {
+			return changes, errors.Trace(err)
+		}
+		events := page.Events()
+		// The pages list the newest events first.
+		sort.Slice(events, func(i, j int) bool {
+			return events[i].ID() < events[j].ID()
+		})
+		for _, event := range events {
+			if event.ID() > changes.After {
+				changes.After = event.ID()
+			}
+			if node := event.Node(); node != "" && !seen[node] {
+				seen[node] = true
+				changes.SystemIDs = append(changes.SystemIDs, node)
+			}
+		}
+		if len(events) < f.args.Limit {
+			return changes, nil
+		}
+	}
+}
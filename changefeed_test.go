@@ -0,0 +1,238 @@
+// Copyright 2022 Canonical Ltd.
+// Licensed under the LGPLv3, see LICENCE file for details.
+
+package gomaasapi
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"strconv"
+	"sync"
+	"time"
+
+	"github.com/juju/errors"
+	"github.com/juju/testing"
+	jc "github.com/juju/testing/checkers"
+	gc "gopkg.in/check.v1"
+)
+
+type changeFeedSuite struct {
+	testing.LoggingCleanupSuite
+}
+
+var _ = gc.Suite(&changeFeedSuite{})
+
+// eventsServer serves the events it holds like MAAS: the oldest events
+// after the "after" cursor, or the latest ones, newest first.
+type eventsServer struct {
+	*httptest.Server
+
+	mu      sync.Mutex
+	nodes   []string
+	queries []string
+	status  int
+}
+
+func newEventsServer() *eventsServer {
+	server := &eventsServer{}
+	mux := http.NewServeMux()
+	mux.HandleFunc("/api/2.0/users/", func(w http.ResponseWriter, r *http.Request) {
+		fmt.Fprint(w, `"captain awesome"`)
+	})
+	mux.HandleFunc("/api/2.0/version/", func(w http.ResponseWriter, r *http.Request) {
+		fmt.Fprint(w, versionResponse)
+	})
+	mux.HandleFunc("/api/2.0/events/", server.handleEvents)
+	server.Server = httptest.NewServer(mux)
+	return server
+}
+
+// addEvents adds an event for each node, with IDs following the previous
+// ones, starting from 1.
+func (s *eventsServer) addEvents(nodes ...string) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.nodes = append(s.nodes, nodes...)
+}
+
+func (s *eventsServer) handleEvents(w http.ResponseWriter, r *http.Request) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.queries = append(s.queries, r.URL.RawQuery)
+	if s.status != 0 {
+		w.WriteHeader(s.status)
+		return
+	}
+	query := r.URL.Query()
+	limit, _ := strconv.Atoi(query.Get("limit"))
+	after, _ := strconv.Atoi(query.Get("after"))
+	first, last := after+1, after+limit
+	if query.Get("after") == "" {
+		first, last = len(s.nodes)-limit+1, len(s.nodes)
+	}
+	var events []map[string]interface{}
+	for id := last; id >= first; id-- {
+		if id < 1 || id > len(s.nodes) {
+			continue
+		}
+		event := map[string]interface{}{
+			"id":    id,
+			"level": EventLevelInfo,
+			"type":  "Changed",
+		}
+		if node := s.nodes[id-1]; node != "" {
+			event["node"] = node
+		}
+		events = append(events, event)
+	}
+	json.NewEncoder(w).Encode(map[string]interface{}{"events": events})
+}
+
+func (s *eventsServer) takeQueries() []string {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	queries := s.queries
+	s.queries = nil
+	return queries
+}
+
+func (s *changeFeedSuite) newServerAndController(c *gc.C) (*eventsServer, Controller) {
+	server := newEventsServer()
+	s.AddCleanup(func(*gc.C) { server.Close() })
+	maas, err := NewController(ControllerArgs{
+		BaseURL: server.URL,
+		APIKey:  "fake:as:key",
+	})
+	c.Assert(err, jc.ErrorIsNil)
+	return server, maas
+}
+
+func (s *changeFeedSuite) nextChanges(c *gc.C, feed ChangeFeed) MachineChanges {
+	select {
+	case changes, ok := <-feed.Changes():
+		c.Assert(ok, jc.IsTrue)
+		return changes
+	case <-time.After(testing.LongWait):
+		c.Fatalf("no changes reported")
+	}
+	panic("unreachable")
+}
+
+func (*changeFeedSuite) TestArgsValidate(c *gc.C) {
+	for _, args := range []ChangeFeedArgs{
+		{After: -1},
+		{Interval: -1},
+		{Level: "LOUD"},
+		{Limit: 1001},
+	} {
+		c.Check(args.Validate(), jc.Satisfies, errors.IsNotValid)
+	}
+	c.Check(ChangeFeedArgs{}.Validate(), jc.ErrorIsNil)
+}
+
+func (s *changeFeedSuite) TestStartsAfterLatestEvent(c *gc.C) {
+	server, maas := s.newServerAndController(c)
+	server.addEvents("old")
+	feed, err := maas.ChangeFeed(context.Background(), ChangeFeedArgs{Interval: time.Millisecond})
+	c.Assert(err, jc.ErrorIsNil)
+	defer feed.Close()
+
+	server.addEvents("4y3ha3", "", "4y3ha3", "8k3h2k")
+	c.Check(s.nextChanges(c, feed), jc.DeepEquals, MachineChanges{
+		SystemIDs: []string{"4y3ha3", "8k3h2k"},
+		After:     5,
+	})
+	c.Assert(feed.Close(), jc.ErrorIsNil)
+	queries := server.takeQueries()
+	c.Check(queries[0], gc.Equals, "level=DEBUG&limit=1&op=query")
+	c.Check(queries[1], gc.Equals, "after=1&level=DEBUG&limit=100&op=query")
+}
+
+func (s *changeFeedSuite) TestResumesAfterCursor(c *gc.C) {
+	server, maas := s.newServerAndController(c)
+	server.addEvents("a", "b", "c", "d", "e")
+	feed, err := maas.ChangeFeed(context.Background(), ChangeFeedArgs{
+		After:    2,
+		Interval: time.Millisecond,
+		Limit:    2,
+	})
+	c.Assert(err, jc.ErrorIsNil)
+	defer feed.Close()
+
+	// The events are read until a page isn't full.
+	c.Check(s.nextChanges(c, feed), jc.DeepEquals, MachineChanges{
+		SystemIDs: []string{"c", "d", "e"},
+		After:     5,
+	})
+	server.addEvents("a")
+	c.Check(s.nextChanges(c, feed), jc.DeepEquals, MachineChanges{
+		SystemIDs: []string{"a"},
+		After:     6,
+	})
+}
+
+func (s *changeFeedSuite) TestEventsWithoutNodesAdvance(c *gc.C) {
+	server, maas := s.newServerAndController(c)
+	feed, err := maas.ChangeFeed(context.Background(), ChangeFeedArgs{
+		After:    1,
+		Interval: time.Millisecond,
+	})
+	c.Assert(err, jc.ErrorIsNil)
+	defer feed.Close()
+
+	server.addEvents("", "", "")
+	server.addEvents("a")
+	c.Check(s.nextChanges(c, feed), jc.DeepEquals, MachineChanges{
+		SystemIDs: []string{"a"},
+		After:     4,
+	})
+}
+
+func (s *changeFeedSuite) TestPermissionError(c *gc.C) {
+	server, maas := s.newServerAndController(c)
+	server.status = http.StatusForbidden
+	_, err := maas.ChangeFeed(context.Background(), ChangeFeedArgs{})
+	c.Assert(err, jc.Satisfies, IsPermissionError)
+}
+
+func (s *changeFeedSuite) TestRetriesAfterError(c *gc.C) {
+	server, maas := s.newServerAndController(c)
+	feed, err := maas.ChangeFeed(context.Background(), ChangeFeedArgs{
+		After:    1,
+		Interval: time.Millisecond,
+	})
+	c.Assert(err, jc.ErrorIsNil)
+	defer feed.Close()
+
+	server.mu.Lock()
+	server.status = http.StatusInternalServerError
+	server.mu.Unlock()
+	server.addEvents("a", "b")
+	time.Sleep(10 * time.Millisecond)
+	server.mu.Lock()
+	server.status = 0
+	server.mu.Unlock()
+	c.Check(s.nextChanges(c, feed), jc.DeepEquals, MachineChanges{
+		SystemIDs: []string{"b"},
+		After:     2,
+	})
+}
+
+func (s *changeFeedSuite) TestContextDone(c *gc.C) {
+	_, maas := s.newServerAndController(c)
+	ctx, cancel := context.WithCancel(context.Background())
+	feed, err := maas.ChangeFeed(ctx, ChangeFeedArgs{After: 1, Interval: time.Millisecond})
+	c.Assert(err, jc.ErrorIsNil)
+
+	cancel()
+	select {
+	case _, ok := <-feed.Changes():
+		c.Assert(ok, jc.IsFalse)
+	case <-time.After(testing.LongWait):
+		c.Fatalf("feed not stopped")
+	}
+	c.Assert(feed.Close(), jc.ErrorIsNil)
+}
@@ -5,14 +5,17 @@ package gomaasapi
 
 import (
 	"bytes"
+	"context"
+	"encoding/json"
 	"fmt"
 	"io"
 	"mime/multipart"
 	"net/http"
 	"net/url"
 	"regexp"
-	"strconv"
+	"sort"
 	"strings"
+	"sync"
 	"time"
 
 	"github.com/juju/errors"
@@ -33,6 +36,27 @@ type Client struct {
 	APIURL     *url.URL
 	Signer     OAuthSigner
 	HTTPClient *http.Client
+
+	// Queue, if set, limits the concurrent requests of the client, which
+	// are queued with the given Priority.
+	Queue    *RequestQueue
+	Priority Priority
+
+	// Context, if set, is the context of the requests of the client. The
+	// requests, and the waits before sending them, stop when it is done.
+	Context context.Context
+
+	// RetryPolicy, if set, replaces DefaultRetryPolicy to decide which
+	// failed requests are sent again.
+	RetryPolicy *RetryPolicy
+
+	// Timeout, if set, bounds the time of each call, including the
+	// retries and the reading of the response.
+	Timeout time.Duration
+
+	// middleware wraps the HTTP client used to send the requests. The
+	// chain is built once and shared by the copies of the client.
+	middleware *middlewareChain
 }
 
 // ServerError is an http error (or at least, a non-2xx result) received from
@@ -43,6 +67,102 @@ type ServerError struct {
 	StatusCode  int
 	Header      http.Header
 	BodyMessage string
+
+	// Method, URL and Op describe the failed request. Op is the op
+	// parameter of the request, if any.
+	Method string
+	URL    string
+	Op     string
+	// RequestID is the X-Request-Id header of the response, if any.
+	RequestID string
+	// FieldErrors holds the messages of a JSON error body, such as the
+	// validation errors of MAAS, by field name. Errors that aren't about
+	// a field are under NonFieldErrorsKey. It is nil if the body isn't a
+	// JSON object.
+	FieldErrors map[string][]string
+}
+
+const (
+	// NonFieldErrorsKey is the key of the FieldErrors of a ServerError that
+	// aren't about a specific field.
+	NonFieldErrorsKey = "__all__"
+
+	requestIDHeaderName = "X-Request-Id"
+)
+
+// Messages returns the messages of the FieldErrors, prefixed with the name
+// of their field, or the body of the response if there are none.
+func (e ServerError) Messages() []string {
+	if len(e.FieldErrors) == 0 {
+		if e.BodyMessage == "" {
+			return nil
+		}
+		return []string{e.BodyMessage}
+	}
+	var messages []string
+	messages = append(messages, e.FieldErrors[NonFieldErrorsKey]...)
+	fields := make([]string, 0, len(e.FieldErrors))
+	for field := range e.FieldErrors {
+		if field != NonFieldErrorsKey {
+			fields = append(fields, field)
+		}
+	}
+	sort.Strings(fields)
+	for _, field := range fields {
+		for _, message := range e.FieldErrors[field] {
+			messages = append(messages, field+": "+message)
+		}
+	}
+	return messages
+}
+
+// newServerError returns the ServerError of a non 2XX response.
+func newServerError(request *http.Request, response *http.Response, body []byte) ServerError {
+	var err error
+	if body == nil {
+		err = errors.Errorf("ServerError: %v", response.Status)
+	} else {
+		err = errors.Errorf("ServerError: %v (%s)", response.Status, body)
+	}
+	return ServerError{
+		error:       err,
+		StatusCode:  response.StatusCode,
+		Header:      response.Header,
+		BodyMessage: string(body),
+		Method:      request.Method,
+		URL:         request.URL.String(),
+		Op:          request.URL.Query().Get("op"),
+		RequestID:   response.Header.Get(requestIDHeaderName),
+		FieldErrors: parseFieldErrors(body),
+	}
+}
+
+// parseFieldErrors reads an error body of MAAS such as
+// {"__all__": ["..."], "name": ["..."]}. Single messages are accepted in
+// place of lists.
+func parseFieldErrors(body []byte) map[string][]string {
+	var source map[string]interface{}
+	if err := json.Unmarshal(body, &source); err != nil || source == nil {
+		return nil
+	}
+	result := make(map[string][]string, len(source))
+	for field, value := range source {
+		switch value := value.(type) {
+		case string:
+			result[field] = []string{value}
+		case []interface{}:
+			for _, item := range value {
+				if message, ok := item.(string); ok {
+					result[field] = append(result[field], message)
+				} else {
+					result[field] = append(result[field], fmt.Sprint(item))
+				}
+			}
+		default:
+			result[field] = []string{fmt.Sprint(value)}
+		}
+	}
+	return result
 }
 
 // GetServerError returns the ServerError from the cause of the error if it is a
@@ -68,72 +188,141 @@ func readAndClose(stream io.ReadCloser) ([]byte, error) {
 // Client-side errors will return an empty response and a non-nil error.  For
 // server-side errors however (i.e. responses with a non 2XX status code), the
 // returned error will be ServerError and the returned body will reflect the
-// server's response.  If the server returns a 503 response with a 'Retry-after'
-// header, the request will be transparently retried.
+// server's response.  Failed requests are transparently retried as defined by
+// the retry policy of the client, see RetryPolicy.
 func (client Client) dispatchRequest(request *http.Request) ([]byte, error) {
+	stream, err := client.dispatchStreamRequest(request)
+	if err != nil {
+		if serverError, ok := errors.Cause(err).(ServerError); ok {
+			return []byte(serverError.BodyMessage), err
+		}
+		return nil, err
+	}
+	body, err := readAndClose(stream)
+	if err != nil {
+		return nil, err
+	}
+	return body, nil
+}
+
+// dispatchStreamRequest is dispatchRequest returning the body of successful
+// responses as a stream, which must be closed.
+func (client Client) dispatchStreamRequest(request *http.Request) (io.ReadCloser, error) {
+	if client.Timeout <= 0 {
+		return client.dispatchRetriedRequest(request)
+	}
+	// The timeout runs until the body of the response is closed.
+	ctx, cancel := context.WithTimeout(request.Context(), client.Timeout)
+	stream, err := client.dispatchRetriedRequest(request.WithContext(ctx))
+	if err != nil {
+		cancel()
+		return nil, err
+	}
+	return &releasingBody{ReadCloser: stream, release: cancel}, nil
+}
+
+// dispatchRetriedRequest sends the request until it succeeds or the retry
+// policy gives up.
+func (client Client) dispatchRetriedRequest(request *http.Request) (io.ReadCloser, error) {
 	// First, store the request's body into a byte[] to be able to restore it
 	// after each request.
 	bodyContent, err := readAndClose(request.Body)
 	if err != nil {
 		return nil, err
 	}
-	for retry := 0; retry < NumberOfRetries; retry++ {
+	policy := client.retryPolicy()
+	for attempt := 1; ; attempt++ {
 		// Restore body before issuing request.
 		if request.Body != nil {
 			newBody := io.NopCloser(bytes.NewReader(bodyContent))
 			request.Body = newBody
 		}
 
-		body, err := client.dispatchSingleRequest(request)
-		// If this is a 503 response with a non-void "Retry-After" header: wait
-		// as instructed and retry the request.
-		if err != nil {
-			serverError, ok := errors.Cause(err).(ServerError)
-			if ok && serverError.StatusCode == http.StatusServiceUnavailable {
-				retryTimeInt, errConv := strconv.Atoi(serverError.Header.Get(RetryAfterHeaderName))
-				if errConv == nil {
-					select {
-					case <-time.After(time.Duration(retryTimeInt) * time.Second):
-					}
-					continue
-				}
-			}
+		stream, err := client.dispatchSingleRequest(request)
+		if err == nil {
+			return stream, nil
+		}
+		wait, retry := policy.retryWait(attempt, err)
+		if !retry {
+			return nil, err
+		}
+		logger.Debugf("retrying %s %s in %v: %v", request.Method, request.URL, wait, err)
+		select {
+		case <-time.After(wait):
+		case <-request.Context().Done():
+			return nil, errors.Trace(request.Context().Err())
 		}
-		return body, err
 	}
-	// Restore body before issuing request.
-	if request.Body != nil {
-		newBody := io.NopCloser(bytes.NewReader(bodyContent))
-		request.Body = newBody
+}
+
+func (client Client) retryPolicy() RetryPolicy {
+	if client.RetryPolicy != nil {
+		return *client.RetryPolicy
 	}
+	return DefaultRetryPolicy
+}
 
-	return client.dispatchSingleRequest(request)
+// releasingBody calls release when it is closed, to free the slot of its
+// request in the queue of the client or to stop the timeout of the call.
+type releasingBody struct {
+	io.ReadCloser
+	release func()
+	once    sync.Once
 }
 
-func (client Client) dispatchSingleRequest(request *http.Request) ([]byte, error) {
-	client.Signer.OAuthSign(request)
-	httpClient := &http.Client{}
-	if client.HTTPClient != nil {
-		httpClient = client.HTTPClient
-	}
+// Close implements io.Closer.
+func (b *releasingBody) Close() error {
+	err := b.ReadCloser.Close()
+	b.once.Do(b.release)
+	return err
+}
 
-	// See https://code.google.com/p/go/issues/detail?id=4677
-	// We need to force the connection to close each time so that we don't
-	// hit the above Go bug.
-	request.Close = true
-	response, err := httpClient.Do(request)
-	if err != nil {
-		return nil, err
+func (client Client) dispatchSingleRequest(request *http.Request) (io.ReadCloser, error) {
+	// Requests are queued individually, so that a request waiting to be
+	// retried doesn't hold up others.
+	release := func() {}
+	if client.Queue != nil {
+		var err error
+		release, err = client.Queue.acquireContext(request.Context(), client.Priority)
+		if err != nil {
+			return nil, errors.Trace(err)
+		}
+	}
+	if err := client.Signer.OAuthSign(request); err != nil {
+		release()
+		return nil, errors.Trace(err)
 	}
-	body, err := readAndClose(response.Body)
+	response, err := client.doer().Do(request)
 	if err != nil {
+		release()
 		return nil, err
 	}
 	if response.StatusCode < 200 || response.StatusCode > 299 {
-		err := errors.Errorf("ServerError: %v (%s)", response.Status, body)
-		return body, errors.Trace(ServerError{error: err, StatusCode: response.StatusCode, Header: response.Header, BodyMessage: string(body)})
+		defer release()
+		body, err := readAndClose(response.Body)
+		if err != nil {
+			return nil, err
+		}
+		return nil, errors.Trace(newServerError(request, response, body))
 	}
-	return body, nil
+	// The request keeps its slot in the queue until the body is read.
+	return &releasingBody{ReadCloser: response.Body, release: release}, nil
+}
+
+// newRequest returns a new request with the context of the client.
+func (client Client) newRequest(method, url string, body io.Reader) (*http.Request, error) {
+	ctx := client.Context
+	if ctx == nil {
+		ctx = context.Background()
+	}
+	return http.NewRequestWithContext(ctx, method, url, body)
+}
+
+func (client Client) httpClient() *http.Client {
+	if client.HTTPClient != nil {
+		return client.HTTPClient
+	}
+	return &http.Client{}
 }
 
 // GetURL returns the URL to a given resource on the API, based on its URI.
@@ -147,6 +336,26 @@ func (client Client) GetURL(uri *url.URL) *url.URL {
 // invocation (if you pass its name in "operation") or plain resource
 // retrieval (if you leave "operation" blank).
 func (client Client) Get(uri *url.URL, operation string, parameters url.Values) ([]byte, error) {
+	request, err := client.getRequest(uri, operation, parameters)
+	if err != nil {
+		return nil, err
+	}
+	return client.dispatchRequest(request)
+}
+
+// GetStream is Get returning the body of the response as a stream, so that
+// large responses don't need to be held in memory at once. The stream must
+// be closed. If the client has a Queue, the request holds its slot until
+// then.
+func (client Client) GetStream(uri *url.URL, operation string, parameters url.Values) (io.ReadCloser, error) {
+	request, err := client.getRequest(uri, operation, parameters)
+	if err != nil {
+		return nil, err
+	}
+	return client.dispatchStreamRequest(request)
+}
+
+func (client Client) getRequest(uri *url.URL, operation string, parameters url.Values) (*http.Request, error) {
 	if parameters == nil {
 		parameters = make(url.Values)
 	}
@@ -160,11 +369,42 @@ func (client Client) Get(uri *url.URL, operation string, parameters url.Values)
 	}
 	queryUrl := client.GetURL(uri)
 	queryUrl.RawQuery = parameters.Encode()
-	request, err := http.NewRequest("GET", queryUrl.String(), nil)
+	return client.newRequest("GET", queryUrl.String(), nil)
+}
+
+// Head performs an HTTP "HEAD" to the API, and returns the headers of the
+// response. Non 2XX responses return a ServerError.
+func (client Client) Head(uri *url.URL) (http.Header, error) {
+	request, err := client.newRequest("HEAD", client.GetURL(uri).String(), nil)
 	if err != nil {
 		return nil, err
 	}
-	return client.dispatchRequest(request)
+	if client.Timeout > 0 {
+		ctx, cancel := context.WithTimeout(request.Context(), client.Timeout)
+		defer cancel()
+		request = request.WithContext(ctx)
+	}
+	if client.Queue != nil {
+		release, err := client.Queue.acquireContext(request.Context(), client.Priority)
+		if err != nil {
+			return nil, errors.Trace(err)
+		}
+		defer release()
+	}
+	if err := client.Signer.OAuthSign(request); err != nil {
+		return nil, errors.Trace(err)
+	}
+	response, err := client.doer().Do(request)
+	if err != nil {
+		return nil, err
+	}
+	if _, err := readAndClose(response.Body); err != nil {
+		return nil, err
+	}
+	if response.StatusCode < 200 || response.StatusCode > 299 {
+		return nil, errors.Trace(newServerError(request, response, nil))
+	}
+	return response.Header, nil
 }
 
 // writeMultiPartFiles writes the given files as parts of a multipart message
@@ -214,7 +454,7 @@ func (client Client) nonIdempotentRequestFiles(method string, uri *url.URL, para
 	}
 	writer.Close()
 	url := client.GetURL(uri)
-	request, err := http.NewRequest(method, url.String(), buf)
+	request, err := client.newRequest(method, url.String(), buf)
 	if err != nil {
 		return nil, err
 	}
@@ -227,7 +467,7 @@ func (client Client) nonIdempotentRequestFiles(method string, uri *url.URL, para
 // requests (but not GET or DELETE requests).
 func (client Client) nonIdempotentRequest(method string, uri *url.URL, parameters url.Values) ([]byte, error) {
 	url := client.GetURL(uri)
-	request, err := http.NewRequest(method, url.String(), strings.NewReader(string(parameters.Encode())))
+	request, err := client.newRequest(method, url.String(), strings.NewReader(string(parameters.Encode())))
 	if err != nil {
 		return nil, err
 	}
@@ -252,10 +492,28 @@ func (client Client) Put(uri *url.URL, parameters url.Values) ([]byte, error) {
 	return client.nonIdempotentRequest("PUT", uri, parameters)
 }
 
+// PutFiles updates an object on the API, using an HTTP "PUT" request with
+// the parameters and the files in a multipart body.
+func (client Client) PutFiles(uri *url.URL, parameters url.Values, files map[string][]byte) ([]byte, error) {
+	return client.nonIdempotentRequestFiles("PUT", uri, parameters, files)
+}
+
+// PutContent performs an HTTP "PUT" to the API with the content as the body
+// of the request, rather than form-encoded parameters.
+func (client Client) PutContent(uri *url.URL, contentType string, content []byte) ([]byte, error) {
+	url := client.GetURL(uri)
+	request, err := client.newRequest("PUT", url.String(), bytes.NewReader(content))
+	if err != nil {
+		return nil, err
+	}
+	request.Header.Set("Content-Type", contentType)
+	return client.dispatchRequest(request)
+}
+
 // Delete deletes an object on the API, using an HTTP "DELETE" request.
 func (client Client) Delete(uri *url.URL) error {
 	url := client.GetURL(uri)
-	request, err := http.NewRequest("DELETE", url.String(), strings.NewReader(""))
+	request, err := client.newRequest("DELETE", url.String(), strings.NewReader(""))
 	if err != nil {
 		return err
 	}
@@ -313,13 +571,9 @@ func NewAnonymousClient(BaseURL string, apiVersion string) (*Client, error) {
 	return &Client{Signer: &anonSigner{}, APIURL: parsedURL}, nil
 }
 
-// NewAuthenticatedClient parses the given MAAS API key into the
-// individual OAuth tokens and creates an Client that will use these
-// tokens to sign the requests it issues.
-// versionedURL should be the location of the versioned API root of
-// the MAAS server, e.g.:
-// http://my.maas.server.example.com/MAAS/api/2.0/
-func NewAuthenticatedClient(versionedURL, apiKey string) (*Client, error) {
+// newAPIKeySigner parses the given MAAS API key into the individual OAuth
+// tokens, and returns a signer using them.
+func newAPIKeySigner(apiKey string) (OAuthSigner, error) {
 	elements := strings.Split(apiKey, ":")
 	if len(elements) != 3 {
 		errString := fmt.Sprintf("invalid API key %q; expected \"<consumer secret>:<token key>:<token secret>\"", apiKey)
@@ -332,7 +586,17 @@ func NewAuthenticatedClient(versionedURL, apiKey string) (*Client, error) {
 		TokenKey:       elements[1],
 		TokenSecret:    elements[2],
 	}
-	signer, err := NewPlainTestOAuthSigner(token, "MAAS API")
+	return NewPlainTestOAuthSigner(token, "MAAS API")
+}
+
+// NewAuthenticatedClient parses the given MAAS API key into the
+// individual OAuth tokens and creates an Client that will use these
+// tokens to sign the requests it issues.
+// versionedURL should be the location of the versioned API root of
+// the MAAS server, e.g.:
+// http://my.maas.server.example.com/MAAS/api/2.0/
+func NewAuthenticatedClient(versionedURL, apiKey string) (*Client, error) {
+	signer, err := newAPIKeySigner(apiKey)
 	if err != nil {
 		return nil, err
 	}
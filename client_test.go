@@ -5,14 +5,17 @@ package gomaasapi
 
 import (
 	"bytes"
+	"context"
 	"crypto/tls"
 	"fmt"
 	"io"
 	"net/http"
+	"net/http/httptest"
 	"net/url"
 	"strings"
 	"time"
 
+	"github.com/juju/errors"
 	jc "github.com/juju/testing/checkers"
 	gc "gopkg.in/check.v1"
 )
@@ -57,6 +60,48 @@ func (suite *ClientSuite) TestClientDispatchRequestReturnsServerError(c *gc.C) {
 	c.Check(string(result), gc.Equals, expectedResult)
 }
 
+func (suite *ClientSuite) TestClientDispatchRequestServerErrorDetails(c *gc.C) {
+	server := httptest.NewServer(http.HandlerFunc(func(writer http.ResponseWriter, request *http.Request) {
+		writer.Header().Set("X-Request-Id", "req-42")
+		writer.WriteHeader(http.StatusBadRequest)
+		fmt.Fprint(writer, `{"__all__": ["Invalid request."], "name": ["Required.", "Too short."], "vlan": "Unknown."}`)
+	}))
+	defer server.Close()
+	client, err := NewAnonymousClient(server.URL, "2.0")
+	c.Assert(err, jc.ErrorIsNil)
+
+	_, err = client.Post(&url.URL{Path: "machines/"}, "allocate", url.Values{}, nil)
+
+	svrError, ok := GetServerError(err)
+	c.Assert(ok, jc.IsTrue)
+	c.Check(svrError.StatusCode, gc.Equals, http.StatusBadRequest)
+	c.Check(svrError.Method, gc.Equals, "POST")
+	c.Check(svrError.URL, gc.Equals, server.URL+"/api/2.0/machines/?op=allocate")
+	c.Check(svrError.Op, gc.Equals, "allocate")
+	c.Check(svrError.RequestID, gc.Equals, "req-42")
+	c.Check(svrError.FieldErrors, jc.DeepEquals, map[string][]string{
+		NonFieldErrorsKey: {"Invalid request."},
+		"name":            {"Required.", "Too short."},
+		"vlan":            {"Unknown."},
+	})
+	c.Check(svrError.Messages(), jc.DeepEquals, []string{
+		"Invalid request.",
+		"name: Required.",
+		"name: Too short.",
+		"vlan: Unknown.",
+	})
+}
+
+func (suite *ClientSuite) TestServerErrorPlainBody(c *gc.C) {
+	for i, body := range []string{"No such machine", `"quoted"`, "null", "[1, 2]"} {
+		c.Logf("test %d: %s", i, body)
+		svrError := ServerError{BodyMessage: body, FieldErrors: parseFieldErrors([]byte(body))}
+		c.Check(svrError.FieldErrors, gc.IsNil)
+		c.Check(svrError.Messages(), jc.DeepEquals, []string{body})
+	}
+	c.Check(ServerError{}.Messages(), gc.IsNil)
+}
+
 func (suite *ClientSuite) TestClientDispatchRequestRetries503(c *gc.C) {
 	URI := "/some/url/?param1=test"
 	server := newFlakyServer(URI, 503, NumberOfRetries)
@@ -85,10 +130,11 @@ func (suite *ClientSuite) TestTLSClientDispatchRequestRetries503NilBody(c *gc.C)
 	client, err := NewAnonymousClient(server.URL, "2.0")
 	c.Assert(err, jc.ErrorIsNil)
 
-	client.HTTPClient = &http.Client{Transport: http.DefaultTransport}
-	client.HTTPClient.Transport.(*http.Transport).TLSClientConfig = &tls.Config{
+	transport := http.DefaultTransport.(*http.Transport).Clone()
+	transport.TLSClientConfig = &tls.Config{
 		InsecureSkipVerify: true,
 	}
+	client.HTTPClient = &http.Client{Transport: transport}
 
 	request, err := http.NewRequest("GET", server.URL+URI, nil)
 	c.Assert(err, jc.ErrorIsNil)
@@ -99,6 +145,38 @@ func (suite *ClientSuite) TestTLSClientDispatchRequestRetries503NilBody(c *gc.C)
 	c.Check(*server.nbRequests, gc.Equals, NumberOfRetries+1)
 }
 
+func (suite *ClientSuite) TestClientDispatchRequestRetryWaitCancelled(c *gc.C) {
+	server := httptest.NewServer(http.HandlerFunc(func(writer http.ResponseWriter, request *http.Request) {
+		writer.Header().Set(RetryAfterHeaderName, "10")
+		writer.WriteHeader(http.StatusServiceUnavailable)
+	}))
+	defer server.Close()
+	client, err := NewAnonymousClient(server.URL, "1.0")
+	c.Assert(err, jc.ErrorIsNil)
+	ctx, cancel := context.WithTimeout(context.Background(), 50*time.Millisecond)
+	defer cancel()
+	client.Context = ctx
+
+	start := time.Now()
+	_, err = client.Get(&url.URL{Path: "/some/url/"}, "", nil)
+	c.Assert(errors.Cause(err), gc.Equals, context.DeadlineExceeded)
+	c.Check(time.Since(start) < 10*time.Second, jc.IsTrue)
+}
+
+func (suite *ClientSuite) TestClientRequestCancelled(c *gc.C) {
+	server := newFlakyServer("/some/url/", http.StatusOK, 0)
+	defer server.Close()
+	client, err := NewAnonymousClient(server.URL, "1.0")
+	c.Assert(err, jc.ErrorIsNil)
+	ctx, cancel := context.WithCancel(context.Background())
+	cancel()
+	client.Context = ctx
+
+	_, err = client.Get(&url.URL{Path: "/some/url/"}, "", nil)
+	c.Assert(err, gc.ErrorMatches, ".*context canceled")
+	c.Check(*server.nbRequests, gc.Equals, 0)
+}
+
 func (suite *ClientSuite) TestClientDispatchRequestDoesntRetry200(c *gc.C) {
 	URI := "/some/url/?param1=test"
 	server := newFlakyServer(URI, 200, 10)
@@ -133,6 +211,83 @@ func (suite *ClientSuite) TestClientDispatchRequestRetriesIsLimited(c *gc.C) {
 	c.Assert(svrError.StatusCode, gc.Equals, 503)
 }
 
+func (suite *ClientSuite) TestClientDispatchRequestRetryPolicy(c *gc.C) {
+	URI := "/some/url/?param1=test"
+	server := newFlakyServer(URI, http.StatusBadGateway, 2)
+	defer server.Close()
+	client, err := NewAnonymousClient(server.URL, "1.0")
+	c.Assert(err, jc.ErrorIsNil)
+	client.RetryPolicy = &RetryPolicy{
+		MaxAttempts: 3,
+		Backoff:     time.Millisecond,
+		StatusCodes: []int{http.StatusBadGateway},
+	}
+	content := "content"
+	request, err := http.NewRequest("POST", server.URL+URI, io.NopCloser(strings.NewReader(content)))
+	c.Assert(err, jc.ErrorIsNil)
+
+	_, err = client.dispatchRequest(request)
+
+	c.Assert(err, jc.ErrorIsNil)
+	c.Check(*server.nbRequests, gc.Equals, 3)
+	c.Check(*server.requests, jc.DeepEquals, [][]byte{[]byte(content), []byte(content), []byte(content)})
+}
+
+func (suite *ClientSuite) TestClientDispatchRequestRetryPolicyIsLimited(c *gc.C) {
+	URI := "/some/url/?param1=test"
+	server := newFlakyServer(URI, http.StatusBadGateway, 3)
+	defer server.Close()
+	client, err := NewAnonymousClient(server.URL, "1.0")
+	c.Assert(err, jc.ErrorIsNil)
+	client.RetryPolicy = &RetryPolicy{
+		MaxAttempts: 2,
+		Backoff:     time.Millisecond,
+		StatusCodes: []int{http.StatusBadGateway},
+	}
+	request, err := http.NewRequest("GET", server.URL+URI, nil)
+	c.Assert(err, jc.ErrorIsNil)
+
+	_, err = client.dispatchRequest(request)
+
+	c.Check(*server.nbRequests, gc.Equals, 2)
+	svrError, ok := GetServerError(err)
+	c.Assert(ok, jc.IsTrue)
+	c.Assert(svrError.StatusCode, gc.Equals, http.StatusBadGateway)
+}
+
+func (suite *ClientSuite) TestClientGetStream(c *gc.C) {
+	server := newSingleServingServer("/api/2.0/machines/?op=list", "[1, 2, 3]", http.StatusOK, -1)
+	defer server.Close()
+	client, err := NewAnonymousClient(server.URL, "2.0")
+	c.Assert(err, jc.ErrorIsNil)
+	client.Queue, err = NewRequestQueue(RequestQueueArgs{MaxConcurrent: 1})
+	c.Assert(err, jc.ErrorIsNil)
+
+	stream, err := client.GetStream(&url.URL{Path: "machines/"}, "list", nil)
+	c.Assert(err, jc.ErrorIsNil)
+	// The request holds its slot in the queue until the stream is closed.
+	c.Check(client.Queue.Stats().Active, gc.Equals, 1)
+	content, err := io.ReadAll(stream)
+	c.Assert(err, jc.ErrorIsNil)
+	c.Check(string(content), gc.Equals, "[1, 2, 3]")
+	c.Assert(stream.Close(), jc.ErrorIsNil)
+	c.Assert(stream.Close(), jc.ErrorIsNil)
+	c.Check(client.Queue.Stats().Active, gc.Equals, 0)
+}
+
+func (suite *ClientSuite) TestClientGetStreamServerError(c *gc.C) {
+	server := newSingleServingServer("/api/2.0/machines/", "no machines", http.StatusNotFound, -1)
+	defer server.Close()
+	client, err := NewAnonymousClient(server.URL, "2.0")
+	c.Assert(err, jc.ErrorIsNil)
+
+	_, err = client.GetStream(&url.URL{Path: "machines/"}, "", nil)
+	svrError, ok := GetServerError(err)
+	c.Assert(ok, jc.IsTrue)
+	c.Check(svrError.StatusCode, gc.Equals, http.StatusNotFound)
+	c.Check(svrError.BodyMessage, gc.Equals, "no machines")
+}
+
 func (suite *ClientSuite) TestClientDispatchRequestReturnsNonServerError(c *gc.C) {
 	client, err := NewAnonymousClient("/foo", "1.0")
 	c.Assert(err, jc.ErrorIsNil)
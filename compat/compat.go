@@ -0,0 +1,317 @@
+// Copyright 2022 Canonical Ltd.
+// Licensed under the LGPLv3, see LICENCE file for details.
+
+// Package gomaasapi re-exports the API of the original
+// github.com/juju/gomaasapi package from this module, so that projects can
+// switch by only changing the import path:
+//
+//	import "github.com/juju/gomaasapi/v2/compat"
+//
+// The package name is the same as the original, so existing references such
+// as gomaasapi.NewController keep compiling. The types are aliases, so values
+// can be passed freely between code using this package and code using
+// github.com/juju/gomaasapi/v2 directly, which is needed for the features
+// that are only available there.
+//
+// The interfaces have gained methods since the original package, so types
+// implementing them outside of this module, such as test doubles, need to
+// embed the interface or implement the new methods.
+package gomaasapi
+
+import (
+	gomaasapi "github.com/juju/gomaasapi/v2"
+)
+
+// The client and controller entry points.
+type (
+	Client         = gomaasapi.Client
+	ServerError    = gomaasapi.ServerError
+	ControllerArgs = gomaasapi.ControllerArgs
+	OAuthSigner    = gomaasapi.OAuthSigner
+	OAuthToken     = gomaasapi.OAuthToken
+	URLParams      = gomaasapi.URLParams
+)
+
+// The entities returned by a Controller.
+type (
+	Controller      = gomaasapi.Controller
+	File            = gomaasapi.File
+	Fabric          = gomaasapi.Fabric
+	VLAN            = gomaasapi.VLAN
+	Zone            = gomaasapi.Zone
+	Pool            = gomaasapi.Pool
+	Domain          = gomaasapi.Domain
+	BootResource    = gomaasapi.BootResource
+	Device          = gomaasapi.Device
+	Machine         = gomaasapi.Machine
+	Space           = gomaasapi.Space
+	Subnet          = gomaasapi.Subnet
+	StaticRoute     = gomaasapi.StaticRoute
+	Interface       = gomaasapi.Interface
+	Link            = gomaasapi.Link
+	FileSystem      = gomaasapi.FileSystem
+	StorageDevice   = gomaasapi.StorageDevice
+	Partition       = gomaasapi.Partition
+	BlockDevice     = gomaasapi.BlockDevice
+	OwnerDataHolder = gomaasapi.OwnerDataHolder
+	Tag             = gomaasapi.Tag
+)
+
+// The argument structs.
+type (
+	DevicesArgs             = gomaasapi.DevicesArgs
+	CreateDeviceArgs        = gomaasapi.CreateDeviceArgs
+	MachinesArgs            = gomaasapi.MachinesArgs
+	StorageSpec             = gomaasapi.StorageSpec
+	InterfaceSpec           = gomaasapi.InterfaceSpec
+	AllocateMachineArgs     = gomaasapi.AllocateMachineArgs
+	ConstraintMatches       = gomaasapi.ConstraintMatches
+	ReleaseMachinesArgs     = gomaasapi.ReleaseMachinesArgs
+	AddFileArgs             = gomaasapi.AddFileArgs
+	CreateInterfaceArgs     = gomaasapi.CreateInterfaceArgs
+	UpdateInterfaceArgs     = gomaasapi.UpdateInterfaceArgs
+	InterfaceLinkMode       = gomaasapi.InterfaceLinkMode
+	LinkSubnetArgs          = gomaasapi.LinkSubnetArgs
+	StartArgs               = gomaasapi.StartArgs
+	CreateMachineDeviceArgs = gomaasapi.CreateMachineDeviceArgs
+)
+
+// The errors.
+type (
+	NoMatchError            = gomaasapi.NoMatchError
+	UnexpectedError         = gomaasapi.UnexpectedError
+	UnsupportedVersionError = gomaasapi.UnsupportedVersionError
+	DeserializationError    = gomaasapi.DeserializationError
+	BadRequestError         = gomaasapi.BadRequestError
+	PermissionError         = gomaasapi.PermissionError
+	CannotCompleteError     = gomaasapi.CannotCompleteError
+)
+
+// The legacy MAASObject API.
+type (
+	JSONObject = gomaasapi.JSONObject
+	MAASObject = gomaasapi.MAASObject
+)
+
+// The test servers.
+type (
+	SimpleTestServer = gomaasapi.SimpleTestServer
+	TestServer       = gomaasapi.TestServer
+	TestMAASObject   = gomaasapi.TestMAASObject
+)
+
+const (
+	NumberOfRetries      = gomaasapi.NumberOfRetries
+	RetryAfterHeaderName = gomaasapi.RetryAfterHeaderName
+
+	LinkModeDHCP   = gomaasapi.LinkModeDHCP
+	LinkModeStatic = gomaasapi.LinkModeStatic
+	LinkModeLinkUp = gomaasapi.LinkModeLinkUp
+
+	NetworksManagement      = gomaasapi.NetworksManagement
+	StaticIPAddresses       = gomaasapi.StaticIPAddresses
+	IPv6DeploymentUbuntu    = gomaasapi.IPv6DeploymentUbuntu
+	DevicesManagement       = gomaasapi.DevicesManagement
+	StorageDeploymentUbuntu = gomaasapi.StorageDeploymentUbuntu
+	NetworkDeploymentUbuntu = gomaasapi.NetworkDeploymentUbuntu
+
+	NodeStatusDeclared          = gomaasapi.NodeStatusDeclared
+	NodeStatusCommissioning     = gomaasapi.NodeStatusCommissioning
+	NodeStatusFailedTests       = gomaasapi.NodeStatusFailedTests
+	NodeStatusMissing           = gomaasapi.NodeStatusMissing
+	NodeStatusReady             = gomaasapi.NodeStatusReady
+	NodeStatusReserved          = gomaasapi.NodeStatusReserved
+	NodeStatusDeployed          = gomaasapi.NodeStatusDeployed
+	NodeStatusRetired           = gomaasapi.NodeStatusRetired
+	NodeStatusBroken            = gomaasapi.NodeStatusBroken
+	NodeStatusDeploying         = gomaasapi.NodeStatusDeploying
+	NodeStatusAllocated         = gomaasapi.NodeStatusAllocated
+	NodeStatusFailedDeployment  = gomaasapi.NodeStatusFailedDeployment
+	NodeStatusReleasing         = gomaasapi.NodeStatusReleasing
+	NodeStatusFailedReleasing   = gomaasapi.NodeStatusFailedReleasing
+	NodeStatusDiskErasing       = gomaasapi.NodeStatusDiskErasing
+	NodeStatusFailedDiskErasing = gomaasapi.NodeStatusFailedDiskErasing
+)
+
+// NotImplemented is returned by the MAASObject methods that aren't
+// implemented.
+var NotImplemented = gomaasapi.NotImplemented
+
+// NewController creates an authenticated client to the MAAS API, and
+// checks the capabilities of the server, see gomaasapi.NewController.
+func NewController(args ControllerArgs) (Controller, error) {
+	return gomaasapi.NewController(args)
+}
+
+// NewAnonymousClient creates a client that issues anonymous requests.
+func NewAnonymousClient(BaseURL string, apiVersion string) (*Client, error) {
+	return gomaasapi.NewAnonymousClient(BaseURL, apiVersion)
+}
+
+// NewAuthenticatedClient parses the given MAAS API key into the individual
+// OAuth tokens and creates a Client that will use these tokens to sign the
+// requests it issues.
+func NewAuthenticatedClient(versionedURL, apiKey string) (*Client, error) {
+	return gomaasapi.NewAuthenticatedClient(versionedURL, apiKey)
+}
+
+// NewMAAS returns an interface to the MAAS API as a *MAASObject.
+func NewMAAS(client Client) *MAASObject {
+	return gomaasapi.NewMAAS(client)
+}
+
+// NewPlainTestOAuthSigner returns an OAuthSigner using the PLAINTEXT
+// signature method.
+func NewPlainTestOAuthSigner(token *OAuthToken, realm string) (OAuthSigner, error) {
+	return gomaasapi.NewPlainTestOAuthSigner(token, realm)
+}
+
+// NewURLParams allocates a new URLParams type.
+func NewURLParams() *URLParams {
+	return gomaasapi.NewURLParams()
+}
+
+// Parse takes a JSON response from the MAAS API and parses it into a
+// JSONObject.
+func Parse(client Client, input []byte) (JSONObject, error) {
+	return gomaasapi.Parse(client, input)
+}
+
+// JSONObjectFromStruct takes a struct and converts it to a JSONObject.
+func JSONObjectFromStruct(client Client, input interface{}) (JSONObject, error) {
+	return gomaasapi.JSONObjectFromStruct(client, input)
+}
+
+// GetServerError returns the ServerError from the cause of the error if it
+// is a ServerError, and also returns the bool to indicate if it was a
+// ServerError or not.
+func GetServerError(err error) (ServerError, bool) {
+	return gomaasapi.GetServerError(err)
+}
+
+// AddAPIVersionToURL adds the API version to the base URL of MAAS.
+func AddAPIVersionToURL(BaseURL, apiVersion string) string {
+	return gomaasapi.AddAPIVersionToURL(BaseURL, apiVersion)
+}
+
+// SplitVersionedURL splits a versioned API URL into the base URL and the
+// API version.
+func SplitVersionedURL(url string) (string, string, bool) {
+	return gomaasapi.SplitVersionedURL(url)
+}
+
+// JoinURLs joins a base URL and a subpath together.
+func JoinURLs(baseURL, path string) string {
+	return gomaasapi.JoinURLs(baseURL, path)
+}
+
+// EnsureTrailingSlash appends a slash at the end of the given string unless
+// there already is one.
+func EnsureTrailingSlash(URL string) string {
+	return gomaasapi.EnsureTrailingSlash(URL)
+}
+
+// NewNoMatchError constructs a new NoMatchError and sets the location.
+func NewNoMatchError(message string) error {
+	return gomaasapi.NewNoMatchError(message)
+}
+
+// IsNoMatchError returns true if err is a NoMatchError.
+func IsNoMatchError(err error) bool {
+	return gomaasapi.IsNoMatchError(err)
+}
+
+// NewUnexpectedError constructs a new UnexpectedError and sets the location.
+func NewUnexpectedError(err error) error {
+	return gomaasapi.NewUnexpectedError(err)
+}
+
+// IsUnexpectedError returns true if err is an UnexpectedError.
+func IsUnexpectedError(err error) bool {
+	return gomaasapi.IsUnexpectedError(err)
+}
+
+// NewUnsupportedVersionError constructs a new UnsupportedVersionError and
+// sets the location.
+func NewUnsupportedVersionError(format string, args ...interface{}) error {
+	return gomaasapi.NewUnsupportedVersionError(format, args...)
+}
+
+// IsUnsupportedVersionError returns true if err is an
+// UnsupportedVersionError.
+func IsUnsupportedVersionError(err error) bool {
+	return gomaasapi.IsUnsupportedVersionError(err)
+}
+
+// WrapWithUnsupportedVersionError constructs a new UnsupportedVersionError
+// wrapping the passed error.
+func WrapWithUnsupportedVersionError(err error) error {
+	return gomaasapi.WrapWithUnsupportedVersionError(err)
+}
+
+// NewDeserializationError constructs a new DeserializationError and sets
+// the location.
+func NewDeserializationError(format string, args ...interface{}) error {
+	return gomaasapi.NewDeserializationError(format, args...)
+}
+
+// WrapWithDeserializationError constructs a new DeserializationError with
+// the specified message, and sets the location and returns a new error with
+// the full error stack set including the error passed in.
+func WrapWithDeserializationError(err error, format string, args ...interface{}) error {
+	return gomaasapi.WrapWithDeserializationError(err, format, args...)
+}
+
+// IsDeserializationError returns true if err is a DeserializationError.
+func IsDeserializationError(err error) bool {
+	return gomaasapi.IsDeserializationError(err)
+}
+
+// NewBadRequestError constructs a new BadRequestError and sets the location.
+func NewBadRequestError(message string) error {
+	return gomaasapi.NewBadRequestError(message)
+}
+
+// IsBadRequestError returns true if err is a BadRequestError.
+func IsBadRequestError(err error) bool {
+	return gomaasapi.IsBadRequestError(err)
+}
+
+// NewPermissionError constructs a new PermissionError and sets the location.
+func NewPermissionError(message string) error {
+	return gomaasapi.NewPermissionError(message)
+}
+
+// IsPermissionError returns true if err is a PermissionError.
+func IsPermissionError(err error) bool {
+	return gomaasapi.IsPermissionError(err)
+}
+
+// NewCannotCompleteError constructs a new CannotCompleteError and sets the
+// location.
+func NewCannotCompleteError(message string) error {
+	return gomaasapi.NewCannotCompleteError(message)
+}
+
+// IsCannotCompleteError returns true if err is a CannotCompleteError.
+func IsCannotCompleteError(err error) bool {
+	return gomaasapi.IsCannotCompleteError(err)
+}
+
+// NewSimpleServer creates a new simple test server.
+func NewSimpleServer() *SimpleTestServer {
+	return gomaasapi.NewSimpleServer()
+}
+
+// NewTestServer starts and returns a new MAAS test server. The caller
+// should call Close when finished, to shut it down.
+func NewTestServer(version string) *TestServer {
+	return gomaasapi.NewTestServer(version)
+}
+
+// NewTestMAAS returns a TestMAASObject that implements the MAASObject
+// interface and thus can be used as a test object instead of the one
+// returned by gomaasapi.NewMAAS().
+func NewTestMAAS(version string) *TestMAASObject {
+	return gomaasapi.NewTestMAAS(version)
+}
@@ -0,0 +1,66 @@
+// Copyright 2022 Canonical Ltd.
+// Licensed under the LGPLv3, see LICENCE file for details.
+
+package gomaasapi_test
+
+import (
+	stdtesting "testing"
+
+	"github.com/juju/errors"
+	jc "github.com/juju/testing/checkers"
+	gc "gopkg.in/check.v1"
+
+	gomaasapi "github.com/juju/gomaasapi/v2"
+	compat "github.com/juju/gomaasapi/v2/compat"
+)
+
+func Test(t *stdtesting.T) {
+	gc.TestingT(t)
+}
+
+type compatSuite struct{}
+
+var _ = gc.Suite(&compatSuite{})
+
+func (*compatSuite) TestTypesAreInterchangeable(c *gc.C) {
+	args := compat.MachinesArgs{Hostnames: []string{"foo"}}
+	var original gomaasapi.MachinesArgs = args
+	c.Check(original.Hostnames, jc.DeepEquals, []string{"foo"})
+
+	var mode compat.InterfaceLinkMode = gomaasapi.LinkModeStatic
+	c.Check(mode, gc.Equals, compat.LinkModeStatic)
+}
+
+func (*compatSuite) TestNewAuthenticatedClient(c *gc.C) {
+	client, err := compat.NewAuthenticatedClient("http://maas.example.com/MAAS/api/2.0/", "a:b:c")
+	c.Assert(err, jc.ErrorIsNil)
+	var original *gomaasapi.Client = client
+	c.Check(original.APIURL.String(), gc.Equals, "http://maas.example.com/MAAS/api/2.0/")
+
+	_, err = compat.NewAuthenticatedClient("http://maas.example.com/MAAS/api/2.0/", "invalid")
+	c.Check(err, gc.NotNil)
+}
+
+func (*compatSuite) TestErrors(c *gc.C) {
+	err := errors.Trace(gomaasapi.NewPermissionError("nope"))
+	c.Check(compat.IsPermissionError(err), jc.IsTrue)
+	c.Check(compat.IsNoMatchError(err), jc.IsFalse)
+
+	err = compat.NewNoMatchError("missing")
+	c.Check(gomaasapi.IsNoMatchError(err), jc.IsTrue)
+}
+
+func (*compatSuite) TestNewController(c *gc.C) {
+	server := compat.NewSimpleServer()
+	server.AddGetResponse("/api/2.0/version/", 200, `{"capabilities": ["networks-management"], "version": "2.9.2", "subversion": ""}`)
+	server.AddGetResponse("/api/2.0/users/?op=whoami", 200, `"captain awesome"`)
+	server.Start()
+	defer server.Close()
+
+	controller, err := compat.NewController(compat.ControllerArgs{
+		BaseURL: server.URL,
+		APIKey:  "fake:as:key",
+	})
+	c.Assert(err, jc.ErrorIsNil)
+	c.Check(controller.Capabilities().Contains(compat.NetworksManagement), jc.IsTrue)
+}
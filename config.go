@@ -0,0 +1,142 @@
+// Copyright 2022 Canonical Ltd.
+// Licensed under the LGPLv3, see LICENCE file for details.
+
+package gomaasapi
+
+import (
+	"fmt"
+	"net/http"
+	"net/url"
+	"strings"
+
+	"github.com/juju/errors"
+)
+
+// GetConfig implements Controller.
+func (c *controller) GetConfig(name string) (interface{}, error) {
+	value, err := c.getConfig(name)
+	if err != nil {
+		if svrErr, ok := errors.Cause(err).(ServerError); ok {
+			if svrErr.StatusCode == http.StatusBadRequest {
+				return nil, wrapError(err, NewBadRequestError(svrErr.BodyMessage))
+			}
+		}
+		return nil, NewUnexpectedError(err)
+	}
+	return value, nil
+}
+
+// SetConfig implements Controller.
+func (c *controller) SetConfig(name, value string) error {
+	params := make(url.Values)
+	params.Add("name", name)
+	params.Add("value", value)
+	if _, err := c._postRaw("maas", "set_config", params, nil); err != nil {
+		if svrErr, ok := errors.Cause(err).(ServerError); ok {
+			switch svrErr.StatusCode {
+			case http.StatusBadRequest:
+				return wrapError(err, NewBadRequestError(svrErr.BodyMessage))
+			case http.StatusForbidden:
+				return wrapError(err, NewPermissionError(svrErr.BodyMessage))
+			}
+		}
+		return NewUnexpectedError(err)
+	}
+	return nil
+}
+
+// Settings are the MAAS configuration items that are most often set when
+// a region is bootstrapped.
+type Settings struct {
+	// DefaultOSystem and DefaultDistroSeries are the operating system and
+	// the release machines are deployed with when none is requested.
+	DefaultOSystem      string
+	DefaultDistroSeries string
+	NTPServers          []string
+	// UpstreamDNS are the servers the MAAS DNS forwards queries to.
+	UpstreamDNS []string
+	Proxy       ProxySettings
+}
+
+// Settings implements Controller.
+func (c *controller) Settings() (Settings, error) {
+	var settings Settings
+	for name, target := range map[string]*string{
+		"default_osystem":       &settings.DefaultOSystem,
+		"default_distro_series": &settings.DefaultDistroSeries,
+	} {
+		value, err := c.GetConfig(name)
+		if err != nil {
+			return Settings{}, errors.Annotatef(err, "reading %s", name)
+		}
+		*target, _ = value.(string)
+	}
+	for name, target := range map[string]*[]string{
+		"ntp_servers":  &settings.NTPServers,
+		"upstream_dns": &settings.UpstreamDNS,
+	} {
+		value, err := c.GetConfig(name)
+		if err != nil {
+			return Settings{}, errors.Annotatef(err, "reading %s", name)
+		}
+		// MAAS stores the servers as a single string.
+		servers, _ := value.(string)
+		*target = strings.Fields(strings.Replace(servers, ",", " ", -1))
+	}
+	proxy, err := c.ProxySettings()
+	if err != nil {
+		return Settings{}, errors.Trace(err)
+	}
+	settings.Proxy = proxy
+	return settings, nil
+}
+
+// UpdateSettingsArgs is an argument struct for Controller.UpdateSettings.
+// Only the fields that are set are changed.
+type UpdateSettingsArgs struct {
+	DefaultOSystem      string
+	DefaultDistroSeries string
+	// NTPServers and UpstreamDNS are cleared when set to an empty,
+	// rather than nil, slice.
+	NTPServers  []string
+	UpstreamDNS []string
+
+	EnableHTTPProxy *bool
+	// HTTPProxy is cleared when set to the empty string.
+	HTTPProxy    *string
+	UsePeerProxy *bool
+}
+
+// UpdateSettings implements Controller.
+func (c *controller) UpdateSettings(args UpdateSettingsArgs) error {
+	// The items are set in order, so that a failure leaves the items
+	// before it set.
+	var items [][2]string
+	if args.DefaultOSystem != "" {
+		items = append(items, [2]string{"default_osystem", args.DefaultOSystem})
+	}
+	if args.DefaultDistroSeries != "" {
+		items = append(items, [2]string{"default_distro_series", args.DefaultDistroSeries})
+	}
+	if args.NTPServers != nil {
+		items = append(items, [2]string{"ntp_servers", strings.Join(args.NTPServers, " ")})
+	}
+	if args.UpstreamDNS != nil {
+		items = append(items, [2]string{"upstream_dns", strings.Join(args.UpstreamDNS, " ")})
+	}
+	if args.EnableHTTPProxy != nil {
+		items = append(items, [2]string{"enable_http_proxy", fmt.Sprint(*args.EnableHTTPProxy)})
+	}
+	if args.HTTPProxy != nil {
+		items = append(items, [2]string{"http_proxy", *args.HTTPProxy})
+	}
+	if args.UsePeerProxy != nil {
+		items = append(items, [2]string{"use_peer_proxy", fmt.Sprint(*args.UsePeerProxy)})
+	}
+	for _, item := range items {
+		if err := c.SetConfig(item[0], item[1]); err != nil {
+			return errors.Annotatef(err, "setting %s", item[0])
+		}
+	}
+	return nil
+}
@@ -0,0 +1,140 @@
+// Copyright 2022 Canonical Ltd.
+// Licensed under the LGPLv3, see LICENCE file for details.
+
+package gomaasapi
+
+import (
+	"net/http"
+
+	"github.com/juju/testing"
+	jc "github.com/juju/testing/checkers"
+	gc "gopkg.in/check.v1"
+)
+
+type configSuite struct {
+	testing.LoggingCleanupSuite
+}
+
+var _ = gc.Suite(&configSuite{})
+
+func (s *configSuite) addConfig(server *SimpleTestServer, name string, status int, body string) {
+	server.AddGetResponse("/api/2.0/maas/?name="+name+"&op=get_config", status, body)
+}
+
+func (s *configSuite) TestGetConfig(c *gc.C) {
+	server, controller := createTestServerController(c, s)
+	s.addConfig(server, "maas_name", http.StatusOK, `"lab"`)
+	s.addConfig(server, "enable_analytics", http.StatusOK, "true")
+
+	value, err := controller.GetConfig("maas_name")
+	c.Assert(err, jc.ErrorIsNil)
+	c.Check(value, gc.Equals, "lab")
+
+	value, err = controller.GetConfig("enable_analytics")
+	c.Assert(err, jc.ErrorIsNil)
+	c.Check(value, gc.Equals, true)
+}
+
+func (s *configSuite) TestGetConfigUnknown(c *gc.C) {
+	server, controller := createTestServerController(c, s)
+	s.addConfig(server, "wat", http.StatusBadRequest, "unknown config name")
+	_, err := controller.GetConfig("wat")
+	c.Assert(err, jc.Satisfies, IsBadRequestError)
+}
+
+func (s *configSuite) TestSetConfig(c *gc.C) {
+	server, controller := createTestServerController(c, s)
+	server.AddPostResponse("/api/2.0/maas/?op=set_config", http.StatusOK, "OK")
+	err := controller.SetConfig("maas_name", "lab")
+	c.Assert(err, jc.ErrorIsNil)
+	form := server.LastRequest().PostForm
+	c.Check(form.Get("name"), gc.Equals, "maas_name")
+	c.Check(form.Get("value"), gc.Equals, "lab")
+}
+
+func (s *configSuite) TestSetConfigErrors(c *gc.C) {
+	server, controller := createTestServerController(c, s)
+	server.AddPostResponse("/api/2.0/maas/?op=set_config", http.StatusBadRequest, "bad value")
+	server.AddPostResponse("/api/2.0/maas/?op=set_config", http.StatusForbidden, "admins only")
+	err := controller.SetConfig("maas_proxy_port", "lots")
+	c.Check(err, jc.Satisfies, IsBadRequestError)
+	err = controller.SetConfig("maas_name", "lab")
+	c.Check(err, jc.Satisfies, IsPermissionError)
+}
+
+func (s *configSuite) TestSettings(c *gc.C) {
+	server, controller := createTestServerController(c, s)
+	s.addConfig(server, "default_osystem", http.StatusOK, `"ubuntu"`)
+	s.addConfig(server, "default_distro_series", http.StatusOK, `"jammy"`)
+	s.addConfig(server, "ntp_servers", http.StatusOK, `"ntp.ubuntu.com, 10.0.0.1"`)
+	s.addConfig(server, "upstream_dns", http.StatusOK, "null")
+	s.addConfig(server, "enable_http_proxy", http.StatusOK, "true")
+	s.addConfig(server, "http_proxy", http.StatusOK, `"http://squid.example.com:3128/"`)
+	s.addConfig(server, "use_peer_proxy", http.StatusOK, "false")
+	s.addConfig(server, "maas_proxy_port", http.StatusOK, "8000")
+	s.addConfig(server, "prefer_v4_proxy", http.StatusOK, "false")
+
+	settings, err := controller.Settings()
+	c.Assert(err, jc.ErrorIsNil)
+	c.Check(settings, jc.DeepEquals, Settings{
+		DefaultOSystem:      "ubuntu",
+		DefaultDistroSeries: "jammy",
+		NTPServers:          []string{"ntp.ubuntu.com", "10.0.0.1"},
+		UpstreamDNS:         []string{},
+		Proxy: ProxySettings{
+			Enabled:   true,
+			HTTPProxy: "http://squid.example.com:3128/",
+			Port:      8000,
+			ProxyHost: "127.0.0.1",
+		},
+	})
+}
+
+func (s *configSuite) TestSettingsError(c *gc.C) {
+	server, controller := createTestServerController(c, s)
+	s.addConfig(server, "default_osystem", http.StatusForbidden, "nope")
+	s.addConfig(server, "default_distro_series", http.StatusForbidden, "nope")
+	_, err := controller.Settings()
+	c.Assert(err, jc.Satisfies, IsUnexpectedError)
+}
+
+func (s *configSuite) TestUpdateSettings(c *gc.C) {
+	server, controller := createTestServerController(c, s)
+	for i := 0; i < 5; i++ {
+		server.AddPostResponse("/api/2.0/maas/?op=set_config", http.StatusOK, "OK")
+	}
+	enable := true
+	noProxy := ""
+	err := controller.UpdateSettings(UpdateSettingsArgs{
+		DefaultDistroSeries: "jammy",
+		NTPServers:          []string{"ntp.ubuntu.com", "10.0.0.1"},
+		UpstreamDNS:         []string{},
+		EnableHTTPProxy:     &enable,
+		HTTPProxy:           &noProxy,
+	})
+	c.Assert(err, jc.ErrorIsNil)
+
+	var items [][2]string
+	for _, request := range server.LastNRequests(5) {
+		items = append(items, [2]string{request.PostForm.Get("name"), request.PostForm.Get("value")})
+	}
+	c.Assert(items, jc.DeepEquals, [][2]string{
+		{"default_distro_series", "jammy"},
+		{"ntp_servers", "ntp.ubuntu.com 10.0.0.1"},
+		{"upstream_dns", ""},
+		{"enable_http_proxy", "true"},
+		{"http_proxy", ""},
+	})
+}
+
+func (s *configSuite) TestUpdateSettingsError(c *gc.C) {
+	server, controller := createTestServerController(c, s)
+	server.AddPostResponse("/api/2.0/maas/?op=set_config", http.StatusOK, "OK")
+	server.AddPostResponse("/api/2.0/maas/?op=set_config", http.StatusBadRequest, "no such release")
+	err := controller.UpdateSettings(UpdateSettingsArgs{
+		DefaultOSystem:      "ubuntu",
+		DefaultDistroSeries: "nonesuch",
+	})
+	c.Assert(err, jc.Satisfies, IsBadRequestError)
+	c.Assert(err, gc.ErrorMatches, "setting default_distro_series: no such release")
+}
@@ -0,0 +1,63 @@
+// Copyright 2022 Canonical Ltd.
+// Licensed under the LGPLv3, see LICENCE file for details.
+
+package gomaasapi
+
+import (
+	"net/http"
+	"time"
+
+	"github.com/juju/errors"
+)
+
+// ConnectionPool configures the connections kept open to MAAS between
+// requests. Clients sending many concurrent requests, such as when
+// deploying hundreds of machines, should keep more idle connections than
+// the default of the http package, which is 2, as each connection that
+// can't be kept is closed and leaves an ephemeral port in TIME_WAIT. Zero
+// values keep the defaults of the transport.
+type ConnectionPool struct {
+	// MaxIdleConnsPerHost is the number of idle connections kept open to
+	// MAAS.
+	MaxIdleConnsPerHost int
+	// IdleConnTimeout is how long an idle connection is kept open.
+	IdleConnTimeout time.Duration
+	// MaxConnsPerHost, if set, limits the number of connections to MAAS,
+	// including those in use. Requests wait for a connection beyond it.
+	MaxConnsPerHost int
+}
+
+// Validate ensures that none of the settings are negative.
+func (p ConnectionPool) Validate() error {
+	if p.MaxIdleConnsPerHost < 0 {
+		return errors.NotValidf("negative MaxIdleConnsPerHost")
+	}
+	if p.IdleConnTimeout < 0 {
+		return errors.NotValidf("negative IdleConnTimeout")
+	}
+	if p.MaxConnsPerHost < 0 {
+		return errors.NotValidf("negative MaxConnsPerHost")
+	}
+	return nil
+}
+
+// needsTransport reports whether any of the settings are set.
+func (p ConnectionPool) needsTransport() bool {
+	return p.MaxIdleConnsPerHost > 0 || p.IdleConnTimeout > 0 || p.MaxConnsPerHost > 0
+}
+
+// apply sets the settings on the transport.
+func (p ConnectionPool) apply(httpTransport *http.Transport) {
+	if p.MaxIdleConnsPerHost > 0 {
+		httpTransport.MaxIdleConnsPerHost = p.MaxIdleConnsPerHost
+		if httpTransport.MaxIdleConns != 0 && httpTransport.MaxIdleConns < p.MaxIdleConnsPerHost {
+			httpTransport.MaxIdleConns = p.MaxIdleConnsPerHost
+		}
+	}
+	if p.IdleConnTimeout > 0 {
+		httpTransport.IdleConnTimeout = p.IdleConnTimeout
+	}
+	if p.MaxConnsPerHost > 0 {
+		httpTransport.MaxConnsPerHost = p.MaxConnsPerHost
+	}
+}
@@ -0,0 +1,128 @@
+// Copyright 2022 Canonical Ltd.
+// Licensed under the LGPLv3, see LICENCE file for details.
+
+package gomaasapi
+
+import (
+	"fmt"
+	"net"
+	"net/http"
+	"net/http/httptest"
+	"sync/atomic"
+	"time"
+
+	"github.com/juju/errors"
+	"github.com/juju/testing"
+	jc "github.com/juju/testing/checkers"
+	gc "gopkg.in/check.v1"
+)
+
+type connectionPoolSuite struct {
+	testing.LoggingCleanupSuite
+}
+
+var _ = gc.Suite(&connectionPoolSuite{})
+
+// newCountingServer returns a server that controllers can be created for,
+// and the number of connections made to it.
+func (s *connectionPoolSuite) newCountingServer() (*httptest.Server, *int64) {
+	mux := http.NewServeMux()
+	mux.HandleFunc("/api/2.0/users/", func(w http.ResponseWriter, r *http.Request) {
+		fmt.Fprint(w, `"captain awesome"`)
+	})
+	mux.HandleFunc("/api/2.0/version/", func(w http.ResponseWriter, r *http.Request) {
+		fmt.Fprint(w, versionResponse)
+	})
+	mux.HandleFunc("/api/2.0/zones/", func(w http.ResponseWriter, r *http.Request) {
+		fmt.Fprint(w, zoneResponse)
+	})
+	var connections int64
+	server := httptest.NewUnstartedServer(mux)
+	server.Config.ConnState = func(conn net.Conn, state http.ConnState) {
+		if state == http.StateNew {
+			atomic.AddInt64(&connections, 1)
+		}
+	}
+	server.Start()
+	s.AddCleanup(func(*gc.C) { server.Close() })
+	return server, &connections
+}
+
+func (s *connectionPoolSuite) TestValidate(c *gc.C) {
+	c.Check(ConnectionPool{}.Validate(), jc.ErrorIsNil)
+	c.Check(ConnectionPool{MaxIdleConnsPerHost: -1}.Validate(), jc.Satisfies, errors.IsNotValid)
+	c.Check(ConnectionPool{IdleConnTimeout: -1}.Validate(), jc.Satisfies, errors.IsNotValid)
+	c.Check(ConnectionPool{MaxConnsPerHost: -1}.Validate(), jc.Satisfies, errors.IsNotValid)
+
+	_, err := NewController(ControllerArgs{
+		BaseURL:        "http://maas.invalid/MAAS/",
+		APIKey:         "fake:as:key",
+		ConnectionPool: ConnectionPool{MaxIdleConnsPerHost: -1},
+	})
+	c.Check(err, jc.Satisfies, errors.IsNotValid)
+}
+
+func (s *connectionPoolSuite) TestConnectionsReused(c *gc.C) {
+	server, connections := s.newCountingServer()
+	maas, err := NewController(ControllerArgs{
+		BaseURL: server.URL,
+		APIKey:  "fake:as:key",
+	})
+	c.Assert(err, jc.ErrorIsNil)
+	for i := 0; i < 10; i++ {
+		_, err := maas.Zones()
+		c.Assert(err, jc.ErrorIsNil)
+	}
+	c.Check(atomic.LoadInt64(connections), gc.Equals, int64(1))
+}
+
+func (s *connectionPoolSuite) TestTransportIsCopied(c *gc.C) {
+	server, _ := s.newCountingServer()
+	transport := &http.Transport{MaxIdleConns: 10, TLSHandshakeTimeout: 7 * time.Second}
+	maas, err := NewController(ControllerArgs{
+		BaseURL:    server.URL,
+		APIKey:     "fake:as:key",
+		HTTPClient: &http.Client{Transport: transport},
+		ConnectionPool: ConnectionPool{
+			MaxIdleConnsPerHost: 64,
+			IdleConnTimeout:     time.Minute,
+			MaxConnsPerHost:     128,
+		},
+	})
+	c.Assert(err, jc.ErrorIsNil)
+
+	used := maas.(*controller).client.HTTPClient.Transport.(*http.Transport)
+	c.Check(used == transport, jc.IsFalse)
+	c.Check(used.TLSHandshakeTimeout, gc.Equals, 7*time.Second)
+	c.Check(used.MaxIdleConnsPerHost, gc.Equals, 64)
+	// The overall limit doesn't prevent keeping the connections to MAAS.
+	c.Check(used.MaxIdleConns, gc.Equals, 64)
+	c.Check(used.IdleConnTimeout, gc.Equals, time.Minute)
+	c.Check(used.MaxConnsPerHost, gc.Equals, 128)
+	c.Check(transport.MaxIdleConnsPerHost, gc.Equals, 0)
+}
+
+func (s *connectionPoolSuite) TestWithTimeouts(c *gc.C) {
+	server, _ := s.newCountingServer()
+	maas, err := NewController(ControllerArgs{
+		BaseURL:        server.URL,
+		APIKey:         "fake:as:key",
+		Timeouts:       Timeouts{ResponseHeader: time.Minute},
+		ConnectionPool: ConnectionPool{MaxIdleConnsPerHost: 64},
+	})
+	c.Assert(err, jc.ErrorIsNil)
+
+	used := maas.(*controller).client.HTTPClient.Transport.(*http.Transport)
+	c.Check(used.ResponseHeaderTimeout, gc.Equals, time.Minute)
+	c.Check(used.MaxIdleConnsPerHost, gc.Equals, 64)
+}
+
+func (s *connectionPoolSuite) TestUnsupportedTransport(c *gc.C) {
+	_, err := NewController(ControllerArgs{
+		BaseURL:        "http://maas.invalid/MAAS/",
+		APIKey:         "fake:as:key",
+		Transport:      &recordingTransport{},
+		ConnectionPool: ConnectionPool{MaxIdleConnsPerHost: 64},
+	})
+	c.Assert(err, jc.Satisfies, errors.IsNotValid)
+}
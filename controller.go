@@ -4,16 +4,19 @@
 package gomaasapi
 
 import (
+	"context"
 	"encoding/json"
 	"fmt"
 	"io"
 	"io/ioutil"
+	"net"
 	"net/http"
 	"net/url"
 	"path"
 	"strconv"
 	"strings"
 	"sync/atomic"
+	"time"
 
 	"github.com/juju/collections/set"
 	"github.com/juju/errors"
@@ -29,10 +32,11 @@ var (
 	// least as they will be tried in order.
 	supportedAPIVersions = []string{"2.0"}
 
-	// Each of the api versions that change the request or response structure
-	// for any given call should have a value defined for easy definition of
-	// the deserialization functions.
-	twoDotOh = version.Number{Major: 2, Minor: 0}
+	// Each of the api versions, or releases of MAAS, that change the request
+	// or response structure for any given call should have a value defined
+	// for easy definition of the deserialization functions.
+	twoDotOh    = version.Number{Major: 2, Minor: 0}
+	threeDotTwo = version.Number{Major: 3, Minor: 2}
 
 	// Current request number. Informational only for logging.
 	requestNumber int64
@@ -43,26 +47,180 @@ var (
 type ControllerArgs struct {
 	BaseURL    string
 	APIKey     string
+	// HTTPClient, if set, is used to send all requests to MAAS.
 	HTTPClient *http.Client
+	// Transport, if set, is the round tripper of all requests to MAAS,
+	// for instance to go through a proxy or to instrument requests. If
+	// HTTPClient is also set, a copy of it using Transport is used.
+	Transport http.RoundTripper
+	// TLSConfig, if set, defines how the certificates are checked when
+	// connecting to MAAS over HTTPS. It requires the transport used, if
+	// any, to be an *http.Transport, of which a copy is used.
+	TLSConfig *TLSConfig
+	// Middleware, if set, wraps the HTTP client sending the requests to
+	// MAAS, for instance to log them. The first middleware sees requests
+	// first, see Middleware.
+	Middleware []Middleware
+	// Metrics, if set, records the requests sent to MAAS, including the
+	// retries, see MetricsSink.
+	Metrics MetricsSink
+	// TimeLocation is the location used to interpret timestamps returned
+	// by the MAAS region that don't include a timezone. MAAS stores times
+	// in UTC, so it defaults to UTC. Parsed times are always normalized
+	// to UTC.
+	TimeLocation *time.Location
+	// RequestQueue, if set, limits the number of concurrent requests sent
+	// to MAAS and orders them by the priority of the controller, see
+	// Controller.WithPriority. A queue may be shared between controllers.
+	RequestQueue *RequestQueue
+	// RetryPolicy, if set, defines which failed requests are sent again,
+	// see RetryPolicy. If it is nil, DefaultRetryPolicy is used.
+	RetryPolicy *RetryPolicy
+	// CredentialProvider, if set, provides the API key used to sign each
+	// request instead of APIKey, so that the key can be rotated without
+	// creating a new controller.
+	CredentialProvider CredentialProvider
+	// ResponseCache, if set, keeps the responses to GET requests and
+	// revalidates them with MAAS, see ResponseCache. A cache may be shared
+	// between controllers, including those with different credentials.
+	ResponseCache *ResponseCache
+	// Timeouts, if set, bound the time spent on each request, see
+	// Timeouts. The connect and response header timeouts require the
+	// transport used, if any, to be an *http.Transport, of which a copy
+	// is used.
+	Timeouts Timeouts
+	// Lenient, if set, accepts servers whose version response can't be
+	// read, logging a warning instead of failing. The responses are then
+	// read as those of the highest supported API version, which suits the
+	// point releases of MAAS that change the version endpoint but keep the
+	// payloads compatible. By default the controller is strict and returns
+	// a DeserializationError. Servers that don't serve the version
+	// endpoint at all, as at URLs that aren't MAAS, are still rejected
+	// with an UnsupportedVersionError.
+	Lenient bool
+	// ConnectionPool, if set, configures the connections kept open to
+	// MAAS between requests, see ConnectionPool. It requires the
+	// transport used, if any, to be an *http.Transport, of which a copy
+	// is used.
+	ConnectionPool ConnectionPool
+	// Debug, if set, records the requests sent to MAAS and their
+	// responses, without the credentials, see DebugRecorder and
+	// Controller.DebugLog.
+	Debug *DebugRecorder
 }
 
 // NewController creates an authenticated client to the MAAS API, and
 // checks the capabilities of the server. If the BaseURL specified
 // includes the API version, that version of the API will be used,
 // otherwise the controller will use the highest supported version
-// available.
+// available. MAAS 3.x still serves the 2.0 API, and responses are read
+// according to the release of MAAS, see Controller.ServerVersion.
 //
 // If the APIKey is not valid, a NotValid error is returned.
 // If the credentials are incorrect, a PermissionError is returned.
 func NewController(args ControllerArgs) (Controller, error) {
+	if args.CredentialProvider != nil {
+		apiKey, err := args.CredentialProvider.APIKey()
+		if err != nil {
+			return nil, errors.Annotate(err, "getting API key")
+		}
+		args.APIKey = apiKey
+	}
+	if args.RetryPolicy != nil {
+		if err := args.RetryPolicy.Validate(); err != nil {
+			return nil, errors.Trace(err)
+		}
+	}
+	if err := args.Timeouts.Validate(); err != nil {
+		return nil, errors.Trace(err)
+	}
+	if err := args.ConnectionPool.Validate(); err != nil {
+		return nil, errors.Trace(err)
+	}
+	httpClient, err := args.httpClient()
+	if err != nil {
+		return nil, errors.Trace(err)
+	}
 	base, apiVersion, includesVersion := SplitVersionedURL(args.BaseURL)
 	if includesVersion {
 		if !supportedVersion(apiVersion) {
 			return nil, NewUnsupportedVersionError("version %s", apiVersion)
 		}
-		return newControllerWithVersion(base, apiVersion, args.APIKey, args.HTTPClient)
+		return newControllerWithVersion(args, httpClient, base, apiVersion, args.Lenient)
+	}
+	return newControllerUnknownVersion(args, httpClient)
+}
+
+// httpClient returns the HTTP client used for requests, if any. The
+// transport is copied once for the settings that are set on it.
+func (args ControllerArgs) httpClient() (*http.Client, error) {
+	transport := args.Transport
+	if transport == nil && args.HTTPClient != nil {
+		transport = args.HTTPClient.Transport
+	}
+	var settings []string
+	if args.TLSConfig != nil {
+		settings = append(settings, "TLSConfig")
+	}
+	if args.Timeouts.needsTransport() {
+		settings = append(settings, "Timeouts")
+	}
+	if args.ConnectionPool.needsTransport() {
+		settings = append(settings, "ConnectionPool")
+	}
+	if len(settings) > 0 {
+		base := transport
+		if base == nil {
+			base = http.DefaultTransport
+		}
+		httpTransport, ok := base.(*http.Transport)
+		if !ok {
+			return nil, errors.NotValidf("%s with a %T transport", strings.Join(settings, " and "), base)
+		}
+		httpTransport = httpTransport.Clone()
+		if args.TLSConfig != nil {
+			if err := args.TLSConfig.apply(httpTransport); err != nil {
+				return nil, errors.Trace(err)
+			}
+		}
+		args.Timeouts.apply(httpTransport)
+		args.ConnectionPool.apply(httpTransport)
+		transport = httpTransport
+	}
+	if transport == nil {
+		return args.HTTPClient, nil
+	}
+	var httpClient http.Client
+	if args.HTTPClient != nil {
+		httpClient = *args.HTTPClient
+	}
+	httpClient.Transport = transport
+	return &httpClient, nil
+}
+
+// configure sets up the client to send the requests with the HTTP client
+// as requested by the args.
+func (args ControllerArgs) configure(client *Client, httpClient *http.Client) {
+	client.HTTPClient = httpClient
+	client.Queue = args.RequestQueue
+	client.RetryPolicy = args.RetryPolicy
+	client.Timeout = args.Timeouts.Call
+	if args.ResponseCache != nil {
+		// The cache comes first so that the middleware of the caller
+		// sees the conditional requests and responses.
+		client.AddMiddleware(args.ResponseCache.middleware())
+	}
+	client.AddMiddleware(args.Middleware...)
+	if args.Metrics != nil {
+		// Recording after the middleware of the caller observes the
+		// requests that are actually sent.
+		client.AddMiddleware(metricsMiddleware(args.Metrics))
+	}
+	if args.Debug != nil {
+		// Recording last captures the requests and responses as they
+		// are sent and received.
+		client.AddMiddleware(args.Debug.middleware())
 	}
-	return newControllerUnknownVersion(args)
 }
 
 func supportedVersion(value string) bool {
@@ -74,13 +232,15 @@ func supportedVersion(value string) bool {
 	return false
 }
 
-func newControllerWithVersion(baseURL, apiVersion, apiKey string, httpClient *http.Client) (Controller, error) {
+// newControllerWithVersion creates a controller using the API version. If
+// lenient is set, the version of the server that can't be read is ignored.
+func newControllerWithVersion(args ControllerArgs, httpClient *http.Client, baseURL, apiVersion string, lenient bool) (Controller, error) {
 	major, minor, err := version.ParseMajorMinor(apiVersion)
 	// We should not get an error here. See the test.
 	if err != nil {
 		return nil, errors.Errorf("bad version defined in supported versions: %q", apiVersion)
 	}
-	client, err := NewAuthenticatedClient(AddAPIVersionToURL(baseURL, apiVersion), apiKey)
+	client, err := NewAuthenticatedClient(AddAPIVersionToURL(baseURL, apiVersion), args.APIKey)
 	if err != nil {
 		// If the credentials aren't valid, return now.
 		if errors.IsNotValid(err) {
@@ -90,17 +250,33 @@ func newControllerWithVersion(baseURL, apiVersion, apiKey string, httpClient *ht
 		// is an unexpected error and return now.
 		return nil, NewUnexpectedError(err)
 	}
-
-	client.HTTPClient = httpClient
+	client.Signer, err = newRotatingSigner(args.APIKey, args.CredentialProvider)
+	if err != nil {
+		return nil, errors.Trace(err)
+	}
+	args.configure(client, httpClient)
 	controllerVersion := version.Number{
 		Major: major,
 		Minor: minor,
 	}
-	controller := &controller{client: client, apiVersion: controllerVersion}
-	_, _, controller.capabilities, err = controller.readAPIVersionInfo()
+	timeLocation := args.TimeLocation
+	if timeLocation == nil {
+		timeLocation = time.UTC
+	}
+	controller := &controller{client: client, apiVersion: controllerVersion, timeLocation: timeLocation, lookups: &lookupCache{}, debug: args.Debug}
+	controller.server, err = controller.readServerInfo()
 	if err != nil {
 		logger.Debugf("read version failed: %#v", err)
-		return nil, errors.Trace(err)
+		if !lenient || !IsDeserializationError(err) {
+			return nil, errors.Trace(err)
+		}
+		logger.Warningf("using API version %s for MAAS at %s despite: %v", apiVersion, baseURL, err)
+		controller.server = ServerInfo{APIVersion: apiVersion, Capabilities: set.NewStrings()}
+	}
+	if controller.serverVersion, err = controller.server.VersionNumber(); err != nil {
+		// Development builds may not report a release, in which case
+		// the readers are selected with the API version.
+		logger.Debugf("unrecognised MAAS version %q", controller.server.Version)
 	}
 
 	if err := controller.checkCreds(); err != nil {
@@ -109,22 +285,34 @@ func newControllerWithVersion(baseURL, apiVersion, apiKey string, httpClient *ht
 	return controller, nil
 }
 
-func newControllerUnknownVersion(args ControllerArgs) (Controller, error) {
+func newControllerUnknownVersion(args ControllerArgs, httpClient *http.Client) (Controller, error) {
 	// For now we don't need to test multiple versions. It is expected that at
 	// some time in the future, we will try the most up to date version and then
 	// work our way backwards.
+	// unreadable is the first API version whose version endpoint answered
+	// with a response that couldn't be read.
+	var unreadable string
 	for _, apiVersion := range supportedAPIVersions {
-		controller, err := newControllerWithVersion(args.BaseURL, apiVersion, args.APIKey, args.HTTPClient)
+		controller, err := newControllerWithVersion(args, httpClient, args.BaseURL, apiVersion, false)
 		switch {
 		case err == nil:
 			return controller, nil
 		case IsUnsupportedVersionError(err):
 			// This will only come back from APIVersionInfo for 410/404.
 			continue
+		case args.Lenient && IsDeserializationError(err):
+			if unreadable == "" {
+				unreadable = apiVersion
+			}
+			continue
 		default:
 			return nil, errors.Trace(err)
 		}
 	}
+	if unreadable != "" {
+		// Fall back to the most desirable version that MAAS serves.
+		return newControllerWithVersion(args, httpClient, args.BaseURL, unreadable, true)
+	}
 
 	return nil, NewUnsupportedVersionError("controller at %s does not support any of %s", args.BaseURL, supportedAPIVersions)
 }
@@ -132,134 +320,1052 @@ func newControllerUnknownVersion(args ControllerArgs) (Controller, error) {
 type controller struct {
 	client       *Client
 	apiVersion   version.Number
-	capabilities set.Strings
+	timeLocation *time.Location
+
+	// server describes the MAAS region the controller was created for,
+	// and serverVersion is its release, if it is recognised.
+	server        ServerInfo
+	serverVersion version.Number
+
+	// debug records the exchanges with MAAS, if set.
+	debug *DebugRecorder
+
+	// lookups caches the listings used by the lookup methods. It is shared
+	// by the controllers derived with a different priority.
+	lookups *lookupCache
 }
 
 // Capabilities implements Controller.
 func (c *controller) Capabilities() set.Strings {
-	return c.capabilities
+	return c.server.Capabilities
+}
+
+// DebugLog implements Controller.
+func (c *controller) DebugLog() []DebugExchange {
+	if c.debug == nil {
+		return nil
+	}
+	return c.debug.Exchanges()
+}
+
+// ServerVersion implements Controller.
+func (c *controller) ServerVersion() ServerInfo {
+	return c.server
+}
+
+// schemaVersion returns the version the readers of the responses are
+// selected with. The structure of the responses changes with the releases
+// of MAAS, while MAAS 3.x still serves the 2.0 API, so the release of the
+// server is used if it is known.
+func (c *controller) schemaVersion() version.Number {
+	if c.serverVersion.Compare(c.apiVersion) > 0 {
+		return c.serverVersion
+	}
+	return c.apiVersion
 }
 
 // BootResources implements Controller.
 func (c *controller) BootResources() ([]BootResource, error) {
-	source, err := c.get("boot-resources")
+	source, err := c.getList("boot-resources", nil, 0)
+	if err != nil {
+		return nil, NewUnexpectedError(err)
+	}
+	resources, err := readBootResources(c.schemaVersion(), source)
+	if err != nil {
+		return nil, errors.Trace(err)
+	}
+	var result []BootResource
+	for _, r := range resources {
+		r.controller = c
+		result = append(result, r)
+	}
+	return result, nil
+}
+
+// ImportBootResources implements Controller.
+func (c *controller) ImportBootResources() error {
+	_, err := c._postRaw("boot-resources", "import", nil, nil)
+	if err != nil {
+		if svrErr, ok := errors.Cause(err).(ServerError); ok {
+			if svrErr.StatusCode == http.StatusForbidden {
+				return wrapError(err, NewPermissionError(svrErr.BodyMessage))
+			}
+		}
+		return NewUnexpectedError(err)
+	}
+	return nil
+}
+
+// StopImportBootResources implements Controller.
+func (c *controller) StopImportBootResources() error {
+	_, err := c._postRaw("boot-resources", "stop_import", nil, nil)
+	if err != nil {
+		if svrErr, ok := errors.Cause(err).(ServerError); ok {
+			if svrErr.StatusCode == http.StatusForbidden {
+				return wrapError(err, NewPermissionError(svrErr.BodyMessage))
+			}
+		}
+		return NewUnexpectedError(err)
+	}
+	return nil
+}
+
+// IsImportingBootResources implements Controller.
+func (c *controller) IsImportingBootResources() (bool, error) {
+	source, err := c.getOp("boot-resources", "is_importing")
+	if err != nil {
+		return false, NewUnexpectedError(err)
+	}
+	importing, ok := source.(bool)
+	if !ok {
+		return false, NewDeserializationError("unexpected value for is_importing, %T", source)
+	}
+	return importing, nil
+}
+
+// Fabrics implements Controller.
+func (c *controller) Fabrics() ([]Fabric, error) {
+	source, err := c.getList("fabrics", nil, 0)
+	if err != nil {
+		return nil, NewUnexpectedError(err)
+	}
+	fabrics, err := readFabrics(c.schemaVersion(), source)
+	if err != nil {
+		return nil, errors.Trace(err)
+	}
+	var result []Fabric
+	for _, f := range fabrics {
+		f.controller = c
+		result = append(result, f)
+	}
+	return result, nil
+}
+
+// Spaces implements Controller.
+func (c *controller) Spaces() ([]Space, error) {
+	source, err := c.getList("spaces", nil, 0)
+	if err != nil {
+		return nil, NewUnexpectedError(err)
+	}
+	spaces, err := readSpaces(c.schemaVersion(), source)
+	if err != nil {
+		return nil, errors.Trace(err)
+	}
+	var result []Space
+	for _, space := range spaces {
+		space.controller = c
+		result = append(result, space)
+	}
+	return result, nil
+}
+
+// CreateSpaceArgs is an argument struct for Controller.CreateSpace. Only
+// Name is required.
+type CreateSpaceArgs struct {
+	Name        string
+	Description string
+}
+
+// Validate ensures that the Name is set.
+func (a *CreateSpaceArgs) Validate() error {
+	if a.Name == "" {
+		return errors.NotValidf("missing Name")
+	}
+	return nil
+}
+
+// CreateSpace implements Controller.
+func (c *controller) CreateSpace(args CreateSpaceArgs) (Space, error) {
+	if err := args.Validate(); err != nil {
+		return nil, errors.Trace(err)
+	}
+	params := NewURLParams()
+	params.Values.Add("name", args.Name)
+	params.MaybeAdd("description", args.Description)
+	source, err := c.post("spaces", "", params.Values)
+	c.lookups.invalidate(lookupSpacesByName)
+	if err != nil {
+		if svrErr, ok := errors.Cause(err).(ServerError); ok {
+			switch svrErr.StatusCode {
+			case http.StatusBadRequest:
+				return nil, wrapError(err, NewBadRequestError(svrErr.BodyMessage))
+			case http.StatusForbidden:
+				return nil, wrapError(err, NewPermissionError(svrErr.BodyMessage))
+			}
+		}
+		return nil, NewUnexpectedError(err)
+	}
+
+	space, err := readSpace(c.schemaVersion(), source)
+	if err != nil {
+		return nil, errors.Trace(err)
+	}
+	space.controller = c
+	return space, nil
+}
+
+// Subnets implements Controller.
+func (c *controller) Subnets() ([]Subnet, error) {
+	source, err := c.getList("subnets", nil, 0)
+	if err != nil {
+		return nil, NewUnexpectedError(err)
+	}
+	subnets, err := readSubnets(c.schemaVersion(), source)
+	if err != nil {
+		return nil, errors.Trace(err)
+	}
+	var result []Subnet
+	for _, subnet := range subnets {
+		subnet.controller = c
+		result = append(result, subnet)
+	}
+	return result, nil
+}
+
+// CreateSubnetArgs is an argument struct for Controller.CreateSubnet. Only
+// CIDR is required. If VLAN isn't set, MAAS puts the subnet on the untagged
+// VLAN of the default fabric.
+type CreateSubnetArgs struct {
+	CIDR       string
+	Name       string
+	VLAN       VLAN
+	GatewayIP  string
+	DNSServers []string
+	// Managed, if not nil, sets whether MAAS manages the allocation of
+	// addresses in the subnet. MAAS manages new subnets by default.
+	Managed *bool
+}
+
+// Validate ensures that the CIDR is set and valid.
+func (a *CreateSubnetArgs) Validate() error {
+	if a.CIDR == "" {
+		return errors.NotValidf("missing CIDR")
+	}
+	if _, _, err := net.ParseCIDR(a.CIDR); err != nil {
+		return errors.NotValidf("CIDR %q", a.CIDR)
+	}
+	return nil
+}
+
+// CreateSubnet implements Controller.
+func (c *controller) CreateSubnet(args CreateSubnetArgs) (Subnet, error) {
+	if err := args.Validate(); err != nil {
+		return nil, errors.Trace(err)
+	}
+	params := NewURLParams()
+	params.Values.Add("cidr", args.CIDR)
+	params.MaybeAdd("name", args.Name)
+	if args.VLAN != nil {
+		params.MaybeAddInt("vlan", args.VLAN.ID())
+	}
+	params.MaybeAdd("gateway_ip", args.GatewayIP)
+	params.MaybeAdd("dns_servers", strings.Join(args.DNSServers, ","))
+	if args.Managed != nil {
+		params.Values.Add("managed", fmt.Sprint(*args.Managed))
+	}
+	source, err := c.post("subnets", "", params.Values)
+	c.lookups.invalidate(lookupSubnetsByCIDR)
+	if err != nil {
+		if svrErr, ok := errors.Cause(err).(ServerError); ok {
+			switch svrErr.StatusCode {
+			case http.StatusBadRequest:
+				return nil, wrapError(err, NewBadRequestError(svrErr.BodyMessage))
+			case http.StatusForbidden:
+				return nil, wrapError(err, NewPermissionError(svrErr.BodyMessage))
+			}
+		}
+		return nil, NewUnexpectedError(err)
+	}
+
+	subnet, err := readSubnet(c.schemaVersion(), source)
+	if err != nil {
+		return nil, errors.Trace(err)
+	}
+	subnet.controller = c
+	return subnet, nil
+}
+
+// SubnetUsage implements Controller.
+func (c *controller) SubnetUsage(subnet Subnet) (ResourceUsage, error) {
+	machines, err := c.Machines(MachinesArgs{})
+	if err != nil {
+		return ResourceUsage{}, errors.Trace(err)
+	}
+	addresses, err := c.IPAddresses(IPAddressesArgs{All: true})
+	if err != nil {
+		return ResourceUsage{}, errors.Trace(err)
+	}
+
+	usage := newUsageCollector()
+	for _, machine := range machines {
+		for _, iface := range machine.InterfaceSet() {
+			if interfaceOnSubnet(iface, subnet.ID()) {
+				usage.addLink(machine, iface)
+			}
+		}
+	}
+	for _, address := range addresses {
+		if addressSubnet := address.Subnet(); addressSubnet != nil && addressSubnet.ID() == subnet.ID() {
+			usage.usage.Reservations = append(usage.usage.Reservations, address.IP())
+		}
+	}
+	return usage.usage, nil
+}
+
+// VLANUsage implements Controller.
+func (c *controller) VLANUsage(vlan VLAN) (ResourceUsage, error) {
+	subnets, err := c.Subnets()
+	if err != nil {
+		return ResourceUsage{}, errors.Trace(err)
+	}
+	machines, err := c.Machines(MachinesArgs{})
+	if err != nil {
+		return ResourceUsage{}, errors.Trace(err)
+	}
+	addresses, err := c.IPAddresses(IPAddressesArgs{All: true})
+	if err != nil {
+		return ResourceUsage{}, errors.Trace(err)
+	}
+
+	usage := newUsageCollector()
+	subnetIDs := set.NewInts()
+	for _, subnet := range subnets {
+		if subnetVLAN := subnet.VLAN(); subnetVLAN != nil && subnetVLAN.ID() == vlan.ID() {
+			subnetIDs.Add(subnet.ID())
+			usage.usage.Subnets = append(usage.usage.Subnets, subnet.CIDR())
+		}
+	}
+	for _, machine := range machines {
+		for _, iface := range machine.InterfaceSet() {
+			if ifaceVLAN := iface.VLAN(); ifaceVLAN != nil && ifaceVLAN.ID() == vlan.ID() {
+				usage.addLink(machine, iface)
+			}
+		}
+	}
+	for _, address := range addresses {
+		if addressSubnet := address.Subnet(); addressSubnet != nil && subnetIDs.Contains(addressSubnet.ID()) {
+			usage.usage.Reservations = append(usage.usage.Reservations, address.IP())
+		}
+	}
+	return usage.usage, nil
+}
+
+// DeleteSubnetArgs is an argument struct for Controller.DeleteSubnet.
+type DeleteSubnetArgs struct {
+	Subnet Subnet
+	// CheckUsage, if true, refuses to delete the subnet if anything depends
+	// on it, returning an *InUseError that lists the dependents. The check
+	// lists all the machines and reserved addresses, so it requires
+	// administrator privileges.
+	CheckUsage bool
+}
+
+// DeleteSubnet implements Controller.
+func (c *controller) DeleteSubnet(args DeleteSubnetArgs) error {
+	if args.Subnet == nil {
+		return errors.NotValidf("missing Subnet")
+	}
+	if args.CheckUsage {
+		usage, err := c.SubnetUsage(args.Subnet)
+		if err != nil {
+			return errors.Annotate(err, "checking subnet usage")
+		}
+		if usage.InUse() {
+			return NewInUseError(fmt.Sprintf("subnet %s", args.Subnet.CIDR()), usage)
+		}
+	}
+	if err := args.Subnet.Delete(); err != nil {
+		return errors.Trace(err)
+	}
+	return nil
+}
+
+// DeleteVLANArgs is an argument struct for Controller.DeleteVLAN.
+type DeleteVLANArgs struct {
+	VLAN VLAN
+	// CheckUsage, if true, refuses to delete the VLAN if anything depends
+	// on it, returning an *InUseError that lists the dependents. The check
+	// lists all the machines and reserved addresses, so it requires
+	// administrator privileges.
+	CheckUsage bool
+}
+
+// DeleteVLAN implements Controller.
+func (c *controller) DeleteVLAN(args DeleteVLANArgs) error {
+	if args.VLAN == nil {
+		return errors.NotValidf("missing VLAN")
+	}
+	if args.CheckUsage {
+		usage, err := c.VLANUsage(args.VLAN)
+		if err != nil {
+			return errors.Annotate(err, "checking VLAN usage")
+		}
+		if usage.InUse() {
+			return NewInUseError(fmt.Sprintf("VLAN %d on %s", args.VLAN.VID(), args.VLAN.Fabric()), usage)
+		}
+	}
+	v, ok := args.VLAN.(*vlan)
+	if !ok || v.resourceURI == "" {
+		v = &vlan{resourceURI: path.Join("vlans", fmt.Sprint(args.VLAN.ID()))}
+	}
+	v.controller = c
+	return errors.Trace(v.Delete())
+}
+
+// IPAddressesArgs is an argument struct for Controller.IPAddresses. All
+// the fields are optional.
+type IPAddressesArgs struct {
+	// IPs limits the result to the specified addresses.
+	IPs []string
+	// All includes the addresses reserved by other users, which requires
+	// administrator privileges.
+	All bool
+	// Owner limits the result to the addresses reserved by the specified
+	// user, which requires administrator privileges.
+	Owner string
+}
+
+// IPAddresses implements Controller.
+func (c *controller) IPAddresses(args IPAddressesArgs) ([]IPAddress, error) {
+	params := NewURLParams()
+	params.MaybeAddMany("ip", args.IPs)
+	params.MaybeAddBool("all", args.All)
+	params.MaybeAdd("owner", args.Owner)
+	source, err := c.getList("ipaddresses", params.Values, 0)
+	if err != nil {
+		if svrErr, ok := errors.Cause(err).(ServerError); ok {
+			if svrErr.StatusCode == http.StatusForbidden {
+				return nil, wrapError(err, NewPermissionError(svrErr.BodyMessage))
+			}
+		}
+		return nil, NewUnexpectedError(err)
+	}
+	addresses, err := readIPAddresses(c.schemaVersion(), source)
+	if err != nil {
+		return nil, errors.Trace(err)
+	}
+	var result []IPAddress
+	for _, address := range addresses {
+		address.controller = c
+		result = append(result, address)
+	}
+	return result, nil
+}
+
+// ReserveIPArgs is an argument struct for Controller.ReserveIPAddress.
+// Either Subnet or IP must be set.
+type ReserveIPArgs struct {
+	// Subnet is the subnet to reserve the address in. If IP is also set,
+	// it must be in the subnet.
+	Subnet Subnet
+	IP     string
+	// Hostname, if set, creates a DNS record for the address.
+	Hostname string
+	// MAC, if set, creates a DHCP host entry so that the MAC address is
+	// always given the reserved address.
+	MAC string
+}
+
+// Validate ensures that either Subnet or a valid IP is set.
+func (a *ReserveIPArgs) Validate() error {
+	if a.Subnet == nil && a.IP == "" {
+		return errors.NotValidf("missing Subnet and IP")
+	}
+	if a.IP != "" && net.ParseIP(a.IP) == nil {
+		return errors.NotValidf("IP %q", a.IP)
+	}
+	return nil
+}
+
+// ReserveIPAddress implements Controller.
+func (c *controller) ReserveIPAddress(args ReserveIPArgs) (IPAddress, error) {
+	if err := args.Validate(); err != nil {
+		return nil, errors.Trace(err)
+	}
+	params := NewURLParams()
+	if args.Subnet != nil {
+		params.MaybeAddInt("subnet", args.Subnet.ID())
+	}
+	params.MaybeAdd("ip", args.IP)
+	params.MaybeAdd("hostname", args.Hostname)
+	params.MaybeAdd("mac", args.MAC)
+	source, err := c.post("ipaddresses", "reserve", params.Values)
+	if err != nil {
+		if svrErr, ok := errors.Cause(err).(ServerError); ok {
+			switch svrErr.StatusCode {
+			case http.StatusNotFound, http.StatusBadRequest, http.StatusConflict:
+				return nil, wrapError(err, NewBadRequestError(svrErr.BodyMessage))
+			case http.StatusForbidden:
+				return nil, wrapError(err, NewPermissionError(svrErr.BodyMessage))
+			case http.StatusServiceUnavailable:
+				// MAAS reports that the subnet has no free addresses
+				// left this way.
+				return nil, wrapError(err, NewCannotCompleteError(svrErr.BodyMessage))
+			}
+		}
+		return nil, NewUnexpectedError(err)
+	}
+
+	address, err := readIPAddress(c.schemaVersion(), source)
+	if err != nil {
+		return nil, errors.Trace(err)
+	}
+	address.controller = c
+	return address, nil
+}
+
+// ReleaseIPAddress implements Controller.
+func (c *controller) ReleaseIPAddress(ip string) error {
+	params := NewURLParams()
+	params.Values.Add("ip", ip)
+	// MAAS doesn't return a body when the address is released.
+	_, err := c._postRaw("ipaddresses", "release", params.Values, nil)
+	if err != nil {
+		if svrErr, ok := errors.Cause(err).(ServerError); ok {
+			switch svrErr.StatusCode {
+			case http.StatusNotFound:
+				return wrapError(err, NewNoMatchError(svrErr.BodyMessage))
+			case http.StatusBadRequest:
+				return wrapError(err, NewBadRequestError(svrErr.BodyMessage))
+			case http.StatusForbidden:
+				return wrapError(err, NewPermissionError(svrErr.BodyMessage))
+			}
+		}
+		return NewUnexpectedError(err)
+	}
+	return nil
+}
+
+// IPRanges implements Controller.
+func (c *controller) IPRanges() ([]IPRange, error) {
+	source, err := c.getList("ipranges", nil, 0)
+	if err != nil {
+		return nil, NewUnexpectedError(err)
+	}
+	ipRanges, err := readIPRanges(c.schemaVersion(), source)
+	if err != nil {
+		return nil, errors.Trace(err)
+	}
+	var result []IPRange
+	for _, ipRange := range ipRanges {
+		ipRange.controller = c
+		result = append(result, ipRange)
+	}
+	return result, nil
+}
+
+// CreateIPRangeArgs is an argument struct for Controller.CreateIPRange.
+// Type, StartIP and EndIP are required. If Subnet isn't set, MAAS picks the
+// subnet that contains the addresses.
+type CreateIPRangeArgs struct {
+	Type    IPRangeType
+	StartIP string
+	EndIP   string
+	Subnet  Subnet
+	Comment string
+}
+
+// Validate ensures that the type is known and that the addresses are set
+// and valid.
+func (a *CreateIPRangeArgs) Validate() error {
+	switch a.Type {
+	case IPRangeTypeDynamic, IPRangeTypeReserved:
+	case "":
+		return errors.NotValidf("missing Type")
+	default:
+		return errors.NotValidf("Type %q", a.Type)
+	}
+	if a.StartIP == "" {
+		return errors.NotValidf("missing StartIP")
+	}
+	if net.ParseIP(a.StartIP) == nil {
+		return errors.NotValidf("StartIP %q", a.StartIP)
+	}
+	if a.EndIP == "" {
+		return errors.NotValidf("missing EndIP")
+	}
+	if net.ParseIP(a.EndIP) == nil {
+		return errors.NotValidf("EndIP %q", a.EndIP)
+	}
+	return nil
+}
+
+// CreateIPRange implements Controller.
+func (c *controller) CreateIPRange(args CreateIPRangeArgs) (IPRange, error) {
+	if err := args.Validate(); err != nil {
+		return nil, errors.Trace(err)
+	}
+	params := NewURLParams()
+	params.Values.Add("type", string(args.Type))
+	params.Values.Add("start_ip", args.StartIP)
+	params.Values.Add("end_ip", args.EndIP)
+	if args.Subnet != nil {
+		params.MaybeAddInt("subnet", args.Subnet.ID())
+	}
+	params.MaybeAdd("comment", args.Comment)
+	source, err := c.post("ipranges", "", params.Values)
+	if err != nil {
+		if svrErr, ok := errors.Cause(err).(ServerError); ok {
+			switch svrErr.StatusCode {
+			case http.StatusBadRequest:
+				return nil, wrapError(err, NewBadRequestError(svrErr.BodyMessage))
+			case http.StatusForbidden:
+				return nil, wrapError(err, NewPermissionError(svrErr.BodyMessage))
+			}
+		}
+		return nil, NewUnexpectedError(err)
+	}
+
+	ipRange, err := readIPRange(c.schemaVersion(), source)
+	if err != nil {
+		return nil, errors.Trace(err)
+	}
+	ipRange.controller = c
+	return ipRange, nil
+}
+
+// StaticRoutes implements Controller.
+func (c *controller) StaticRoutes() ([]StaticRoute, error) {
+	source, err := c.getList("static-routes", nil, 0)
+	if err != nil {
+		return nil, NewUnexpectedError(err)
+	}
+	staticRoutes, err := readStaticRoutes(c.schemaVersion(), source)
+	if err != nil {
+		return nil, errors.Trace(err)
+	}
+	var result []StaticRoute
+	for _, staticRoute := range staticRoutes {
+		result = append(result, staticRoute)
+	}
+	return result, nil
+}
+
+// Zones implements Controller.
+func (c *controller) Zones() ([]Zone, error) {
+	source, err := c.getList("zones", nil, 0)
+	if err != nil {
+		return nil, NewUnexpectedError(err)
+	}
+	zones, err := readZones(c.schemaVersion(), source)
+	if err != nil {
+		return nil, errors.Trace(err)
+	}
+	var result []Zone
+	for _, z := range zones {
+		z.controller = c
+		result = append(result, z)
+	}
+	return result, nil
+}
+
+// CreateZoneArgs is an argument struct for Controller.CreateZone. Only Name
+// is required.
+type CreateZoneArgs struct {
+	Name        string
+	Description string
+}
+
+// Validate ensures that the Name is set.
+func (a *CreateZoneArgs) Validate() error {
+	if a.Name == "" {
+		return errors.NotValidf("missing Name")
+	}
+	return nil
+}
+
+// CreateZone implements Controller.
+func (c *controller) CreateZone(args CreateZoneArgs) (Zone, error) {
+	if err := args.Validate(); err != nil {
+		return nil, errors.Trace(err)
+	}
+	params := NewURLParams()
+	params.Values.Add("name", args.Name)
+	params.MaybeAdd("description", args.Description)
+	source, err := c.post("zones", "", params.Values)
+	if err != nil {
+		if svrErr, ok := errors.Cause(err).(ServerError); ok {
+			switch svrErr.StatusCode {
+			case http.StatusBadRequest:
+				return nil, wrapError(err, NewBadRequestError(svrErr.BodyMessage))
+			case http.StatusForbidden:
+				return nil, wrapError(err, NewPermissionError(svrErr.BodyMessage))
+			}
+		}
+		return nil, NewUnexpectedError(err)
+	}
+
+	zone, err := readZone(c.schemaVersion(), source)
+	if err != nil {
+		return nil, errors.Trace(err)
+	}
+	zone.controller = c
+	return zone, nil
+}
+
+// Pools implements Controller.
+func (c *controller) Pools() ([]Pool, error) {
+	var result []Pool
+
+	source, err := c.getList("pools", nil, 0)
+	if err != nil {
+		return nil, NewUnexpectedError(err)
+	}
+
+	pools, err := readPools(c.schemaVersion(), source)
+	if err != nil {
+		return nil, errors.Trace(err)
+	}
+
+	for _, p := range pools {
+		p.controller = c
+		result = append(result, p)
+	}
+	return result, nil
+}
+
+// CreatePoolArgs is an argument struct for Controller.CreatePool. Only Name
+// is required.
+type CreatePoolArgs struct {
+	Name        string
+	Description string
+}
+
+// Validate ensures that the Name is set.
+func (a *CreatePoolArgs) Validate() error {
+	if a.Name == "" {
+		return errors.NotValidf("missing Name")
+	}
+	return nil
+}
+
+// CreatePool implements Controller.
+func (c *controller) CreatePool(args CreatePoolArgs) (Pool, error) {
+	if err := args.Validate(); err != nil {
+		return nil, errors.Trace(err)
+	}
+	params := NewURLParams()
+	params.Values.Add("name", args.Name)
+	params.MaybeAdd("description", args.Description)
+	source, err := c.post("pools", "", params.Values)
+	if err != nil {
+		if svrErr, ok := errors.Cause(err).(ServerError); ok {
+			switch svrErr.StatusCode {
+			case http.StatusBadRequest:
+				return nil, wrapError(err, NewBadRequestError(svrErr.BodyMessage))
+			case http.StatusForbidden:
+				return nil, wrapError(err, NewPermissionError(svrErr.BodyMessage))
+			}
+		}
+		return nil, NewUnexpectedError(err)
+	}
+
+	pool, err := readPool(c.schemaVersion(), source)
+	if err != nil {
+		return nil, errors.Trace(err)
+	}
+	pool.controller = c
+	return pool, nil
+}
+
+// Domains implements Controller
+func (c *controller) Domains() ([]Domain, error) {
+	source, err := c.getList("domains", nil, 0)
+	if err != nil {
+		return nil, NewUnexpectedError(err)
+	}
+	domains, err := readDomains(c.schemaVersion(), source)
+	if err != nil {
+		return nil, errors.Trace(err)
+	}
+	var result []Domain
+	for _, domain := range domains {
+		domain.controller = c
+		result = append(result, domain)
+	}
+	return result, nil
+}
+
+// CreateDomainArgs is an argument struct for Controller.CreateDomain. Name
+// is required.
+type CreateDomainArgs struct {
+	Name string
+	// Authoritative, if not nil, sets whether MAAS is the authoritative DNS
+	// server of the domain. MAAS makes new domains authoritative by default.
+	Authoritative *bool
+	// TTL is the default TTL of the records of the domain. The default TTL
+	// of MAAS applies if it is nil.
+	TTL *int
+}
+
+// Validate ensures that the Name is set.
+func (a *CreateDomainArgs) Validate() error {
+	if a.Name == "" {
+		return errors.NotValidf("missing Name")
+	}
+	return nil
+}
+
+// CreateDomain implements Controller.
+func (c *controller) CreateDomain(args CreateDomainArgs) (Domain, error) {
+	if err := args.Validate(); err != nil {
+		return nil, errors.Trace(err)
+	}
+	params := NewURLParams()
+	params.Values.Add("name", args.Name)
+	if args.Authoritative != nil {
+		params.Values.Add("authoritative", fmt.Sprint(*args.Authoritative))
+	}
+	if args.TTL != nil {
+		params.Values.Add("ttl", fmt.Sprint(*args.TTL))
+	}
+	source, err := c.post("domains", "", params.Values)
 	if err != nil {
+		if svrErr, ok := errors.Cause(err).(ServerError); ok {
+			switch svrErr.StatusCode {
+			case http.StatusBadRequest:
+				return nil, wrapError(err, NewBadRequestError(svrErr.BodyMessage))
+			case http.StatusForbidden:
+				return nil, wrapError(err, NewPermissionError(svrErr.BodyMessage))
+			}
+		}
 		return nil, NewUnexpectedError(err)
 	}
-	resources, err := readBootResources(c.apiVersion, source)
+
+	domain, err := readDomain(c.schemaVersion(), source)
 	if err != nil {
 		return nil, errors.Trace(err)
 	}
-	var result []BootResource
-	for _, r := range resources {
-		result = append(result, r)
-	}
-	return result, nil
+	domain.controller = c
+	return domain, nil
 }
 
-// Fabrics implements Controller.
-func (c *controller) Fabrics() ([]Fabric, error) {
-	source, err := c.get("fabrics")
+// DNSResources implements Controller.
+func (c *controller) DNSResources() ([]DNSResource, error) {
+	source, err := c.getList("dnsresources", nil, 0)
 	if err != nil {
 		return nil, NewUnexpectedError(err)
 	}
-	fabrics, err := readFabrics(c.apiVersion, source)
+	dnsResources, err := readDNSResources(c.schemaVersion(), source)
 	if err != nil {
 		return nil, errors.Trace(err)
 	}
-	var result []Fabric
-	for _, f := range fabrics {
-		result = append(result, f)
+	var result []DNSResource
+	for _, d := range dnsResources {
+		d.controller = c
+		result = append(result, d)
 	}
 	return result, nil
 }
 
-// Spaces implements Controller.
-func (c *controller) Spaces() ([]Space, error) {
-	source, err := c.get("spaces")
-	if err != nil {
-		return nil, NewUnexpectedError(err)
+// CreateDNSResourceArgs is an argument struct for
+// Controller.CreateDNSResource. Either FQDN, or Name along with an optional
+// Domain, is required. MAAS uses the default domain if the Domain isn't set.
+type CreateDNSResourceArgs struct {
+	FQDN   string
+	Name   string
+	Domain string
+	// AddressTTL is the TTL of the address records. The TTL of the domain
+	// applies if it is nil.
+	AddressTTL *int
+	// IPAddresses are the addresses the name resolves to, as A and AAAA
+	// records. MAAS reserves the addresses that aren't already known to it.
+	IPAddresses []string
+}
+
+// Validate ensures that the name is set once and that the addresses are
+// valid.
+func (a *CreateDNSResourceArgs) Validate() error {
+	if err := validateDNSName(a.FQDN, a.Name, a.Domain); err != nil {
+		return errors.Trace(err)
 	}
-	spaces, err := readSpaces(c.apiVersion, source)
-	if err != nil {
-		return nil, errors.Trace(err)
+	for _, address := range a.IPAddresses {
+		if net.ParseIP(address) == nil {
+			return errors.NotValidf("IP address %q", address)
+		}
 	}
-	var result []Space
-	for _, space := range spaces {
-		result = append(result, space)
+	return nil
+}
+
+// validateDNSName ensures that a name is given either as an FQDN or as a
+// name within a domain.
+func validateDNSName(fqdn, name, domain string) error {
+	if fqdn == "" && name == "" {
+		return errors.NotValidf("missing FQDN or Name")
 	}
-	return result, nil
+	if fqdn != "" && (name != "" || domain != "") {
+		return errors.NotValidf("both FQDN and Name or Domain")
+	}
+	return nil
 }
 
-// StaticRoutes implements Controller.
-func (c *controller) StaticRoutes() ([]StaticRoute, error) {
-	source, err := c.get("static-routes")
+// CreateDNSResource implements Controller.
+func (c *controller) CreateDNSResource(args CreateDNSResourceArgs) (DNSResource, error) {
+	if err := args.Validate(); err != nil {
+		return nil, errors.Trace(err)
+	}
+	params := NewURLParams()
+	params.MaybeAdd("fqdn", args.FQDN)
+	params.MaybeAdd("name", args.Name)
+	params.MaybeAdd("domain", args.Domain)
+	if args.AddressTTL != nil {
+		params.Values.Add("address_ttl", fmt.Sprint(*args.AddressTTL))
+	}
+	params.MaybeAdd("ip_addresses", strings.Join(args.IPAddresses, " "))
+	source, err := c.post("dnsresources", "", params.Values)
 	if err != nil {
+		if svrErr, ok := errors.Cause(err).(ServerError); ok {
+			switch svrErr.StatusCode {
+			case http.StatusBadRequest:
+				return nil, wrapError(err, NewBadRequestError(svrErr.BodyMessage))
+			case http.StatusForbidden:
+				return nil, wrapError(err, NewPermissionError(svrErr.BodyMessage))
+			}
+		}
 		return nil, NewUnexpectedError(err)
 	}
-	staticRoutes, err := readStaticRoutes(c.apiVersion, source)
+
+	dnsResource, err := readDNSResource(c.schemaVersion(), source)
 	if err != nil {
 		return nil, errors.Trace(err)
 	}
-	var result []StaticRoute
-	for _, staticRoute := range staticRoutes {
-		result = append(result, staticRoute)
-	}
-	return result, nil
+	dnsResource.controller = c
+	return dnsResource, nil
 }
 
-// Zones implements Controller.
-func (c *controller) Zones() ([]Zone, error) {
-	source, err := c.get("zones")
+// DNSResourceRecordsArgs is an argument struct for selecting DNS resource
+// records. Only the records that match all the criteria that are set are
+// returned.
+type DNSResourceRecordsArgs struct {
+	Domain string
+	Name   string
+	Type   DNSRecordType
+}
+
+// DNSResourceRecords implements Controller.
+func (c *controller) DNSResourceRecords(args DNSResourceRecordsArgs) ([]DNSResourceRecord, error) {
+	params := NewURLParams()
+	params.MaybeAdd("domain", args.Domain)
+	params.MaybeAdd("name", args.Name)
+	params.MaybeAdd("rrtype", string(args.Type))
+	source, err := c.getList("dnsresourcerecords", params.Values, 0)
 	if err != nil {
 		return nil, NewUnexpectedError(err)
 	}
-	zones, err := readZones(c.apiVersion, source)
+	records, err := readDNSResourceRecords(c.schemaVersion(), source)
 	if err != nil {
 		return nil, errors.Trace(err)
 	}
-	var result []Zone
-	for _, z := range zones {
-		result = append(result, z)
+	var result []DNSResourceRecord
+	for _, record := range records {
+		record.controller = c
+		result = append(result, record)
 	}
 	return result, nil
 }
 
-// Pools implements Controller.
-func (c *controller) Pools() ([]Pool, error) {
-	var result []Pool
+// CreateDNSResourceRecordArgs is an argument struct for
+// Controller.CreateDNSResourceRecord. Either FQDN, or Name along with an
+// optional Domain, is required, as are Type and Data.
+type CreateDNSResourceRecordArgs struct {
+	FQDN   string
+	Name   string
+	Domain string
+	Type   DNSRecordType
+	// Data is the content of the record in zone file format, such as
+	// "10 mail.example.com" for an MX record or "0 5 5060 sip.example.com"
+	// for an SRV record.
+	Data string
+	// TTL is the TTL of the record. The TTL of the domain applies if it is
+	// nil.
+	TTL *int
+}
+
+// Validate ensures that the name is set once and that the Type and Data are
+// set. Address records are rejected, they are the IP addresses of a DNS
+// resource.
+func (a *CreateDNSResourceRecordArgs) Validate() error {
+	if err := validateDNSName(a.FQDN, a.Name, a.Domain); err != nil {
+		return errors.Trace(err)
+	}
+	switch strings.ToUpper(string(a.Type)) {
+	case "":
+		return errors.NotValidf("missing Type")
+	case "A", "AAAA":
+		return errors.NotValidf("address record Type %q", a.Type)
+	}
+	if a.Data == "" {
+		return errors.NotValidf("missing Data")
+	}
+	return nil
+}
 
-	source, err := c.get("pools")
+// CreateDNSResourceRecord implements Controller.
+func (c *controller) CreateDNSResourceRecord(args CreateDNSResourceRecordArgs) (DNSResourceRecord, error) {
+	if err := args.Validate(); err != nil {
+		return nil, errors.Trace(err)
+	}
+	params := NewURLParams()
+	params.MaybeAdd("fqdn", args.FQDN)
+	params.MaybeAdd("name", args.Name)
+	params.MaybeAdd("domain", args.Domain)
+	params.Values.Add("rrtype", string(args.Type))
+	params.Values.Add("rrdata", args.Data)
+	if args.TTL != nil {
+		params.Values.Add("ttl", fmt.Sprint(*args.TTL))
+	}
+	source, err := c.post("dnsresourcerecords", "", params.Values)
 	if err != nil {
+		if svrErr, ok := errors.Cause(err).(ServerError); ok {
+			switch svrErr.StatusCode {
+			case http.StatusBadRequest:
+				return nil, wrapError(err, NewBadRequestError(svrErr.BodyMessage))
+			case http.StatusForbidden:
+				return nil, wrapError(err, NewPermissionError(svrErr.BodyMessage))
+			}
+		}
 		return nil, NewUnexpectedError(err)
 	}
 
-	pools, err := readPools(c.apiVersion, source)
+	record, err := readDNSResourceRecord(c.schemaVersion(), source)
 	if err != nil {
 		return nil, errors.Trace(err)
 	}
-
-	for _, p := range pools {
-		result = append(result, p)
-	}
-	return result, nil
+	record.controller = c
+	return record, nil
 }
 
-// Domains implements Controller
-func (c *controller) Domains() ([]Domain, error) {
-	source, err := c.get("domains")
-	if err != nil {
-		return nil, NewUnexpectedError(err)
+// HostRecords implements Controller.
+func (c *controller) HostRecords(machine Machine) ([]HostRecord, error) {
+	records := newHostRecords()
+	fqdn := machine.FQDN()
+	for _, address := range machine.IPAddresses() {
+		records.add(address, fqdn)
+	}
+	// MAAS publishes the addresses of interfaces other than the boot
+	// interface under a name qualified by the interface name.
+	bootInterface := machine.BootInterface()
+	for _, iface := range machine.InterfaceSet() {
+		if bootInterface != nil && iface.ID() == bootInterface.ID() {
+			continue
+		}
+		name := interfaceDNSLabel(iface.Name()) + "." + fqdn
+		for _, link := range iface.Links() {
+			records.add(link.IPAddress(), name)
+		}
 	}
-	domains, err := readDomains(c.apiVersion, source)
+	dnsResources, err := c.DNSResources()
 	if err != nil {
 		return nil, errors.Trace(err)
 	}
-	var result []Domain
-	for _, domain := range domains {
-		result = append(result, domain)
+	for _, dnsResource := range dnsResources {
+		for _, address := range dnsResource.IPAddresses() {
+			if records.has(address) {
+				records.add(address, dnsResource.FQDN())
+			}
+		}
 	}
-	return result, nil
+	return records.records(), nil
 }
 
 // DevicesArgs is a argument struct for selecting Devices.
@@ -272,6 +1378,12 @@ type DevicesArgs struct {
 	Zone         string
 	Pool         string
 	AgentName    string
+	// Limit, if non-zero, caps the number of devices read from the
+	// controller.
+	Limit int
+	// Offset is the number of matching devices skipped, so that listings
+	// can be read in parts along with Limit.
+	Offset int
 }
 
 // Devices implements Controller.
@@ -284,17 +1396,17 @@ func (c *controller) Devices(args DevicesArgs) ([]Device, error) {
 	params.MaybeAdd("zone", args.Zone)
 	params.MaybeAdd("pool", args.Pool)
 	params.MaybeAdd("agent_name", args.AgentName)
-	source, err := c.getQuery("devices", params.Values)
+	source, err := c.getListRange("devices", params.Values, args.Offset, args.Limit)
 	if err != nil {
 		return nil, NewUnexpectedError(err)
 	}
-	devices, err := readDevices(c.apiVersion, source)
+	devices, err := readDevices(c.schemaVersion(), source)
 	if err != nil {
 		return nil, errors.Trace(err)
 	}
 	var result []Device
 	for _, d := range devices {
-		d.controller = c
+		d.setController(c)
 		result = append(result, d)
 	}
 	return result, nil
@@ -323,18 +1435,18 @@ func (c *controller) CreateDevice(args CreateDeviceArgs) (Device, error) {
 	if err != nil {
 		if svrErr, ok := errors.Cause(err).(ServerError); ok {
 			if svrErr.StatusCode == http.StatusBadRequest {
-				return nil, errors.Wrap(err, NewBadRequestError(svrErr.BodyMessage))
+				return nil, wrapError(err, NewBadRequestError(svrErr.BodyMessage))
 			}
 		}
 		// Translate http errors.
 		return nil, NewUnexpectedError(err)
 	}
 
-	device, err := readDevice(c.apiVersion, result)
+	device, err := readDevice(c.schemaVersion(), result)
 	if err != nil {
 		return nil, errors.Trace(err)
 	}
-	device.controller = c
+	device.setController(c)
 	return device, nil
 }
 
@@ -347,42 +1459,155 @@ type MachinesArgs struct {
 	Domain       string
 	Zone         string
 	Pool         string
+	Pod          string
 	AgentName    string
 	Tags         []string
 	OwnerData    map[string]string
+	// ChangedSince, if not zero, only matches machines that MAAS reports
+	// as updated after that time. Machines without an updated timestamp,
+	// as returned by older versions of MAAS, always match.
+	ChangedSince time.Time
+	// Limit, if non-zero, caps the number of machines read from the
+	// controller. The OwnerData and ChangedSince filters are applied
+	// after the limit.
+	Limit int
+	// Offset is the number of machines skipped, before the OwnerData and
+	// ChangedSince filters are applied. See also Controller.MachinesPager.
+	Offset int
+	// SkipInvalid, if true, leaves out machines that fail to deserialize
+	// instead of failing the whole listing. If any machines were skipped,
+	// the remaining machines are returned with a *PartialResultError
+	// describing each failure.
+	SkipInvalid bool
+}
+
+// params returns the query parameters of the filters that MAAS applies.
+// The OwnerData and ChangedSince filters are applied by the client.
+func (a *MachinesArgs) params() *URLParams {
+	params := NewURLParams()
+	params.MaybeAddMany("hostname", a.Hostnames)
+	params.MaybeAddMany("mac_address", a.MACAddresses)
+	params.MaybeAddMany("id", a.SystemIDs)
+	params.MaybeAdd("domain", a.Domain)
+	params.MaybeAdd("zone", a.Zone)
+	params.MaybeAdd("pool", a.Pool)
+	params.MaybeAdd("pod", a.Pod)
+	params.MaybeAdd("agent_name", a.AgentName)
+	params.MaybeAddMany("tags", a.Tags)
+	return params
 }
 
 // Machines implements Controller.
 func (c *controller) Machines(args MachinesArgs) ([]Machine, error) {
-	params := NewURLParams()
-	params.MaybeAddMany("hostname", args.Hostnames)
-	params.MaybeAddMany("mac_address", args.MACAddresses)
-	params.MaybeAddMany("id", args.SystemIDs)
-	params.MaybeAdd("domain", args.Domain)
-	params.MaybeAdd("zone", args.Zone)
-	params.MaybeAdd("pool", args.Pool)
-	params.MaybeAdd("agent_name", args.AgentName)
-	params.MaybeAddMany("tags", args.Tags)
 	// At the moment the MAAS API doesn't support filtering by owner
-	// data so we do that ourselves below.
-	source, err := c.getQuery("machines", params.Values)
+	// data or update time so we do that ourselves below.
+	source, err := c.getListRange("machines", args.params().Values, args.Offset, args.Limit)
 	if err != nil {
 		return nil, NewUnexpectedError(err)
 	}
-	machines, err := readMachines(c.apiVersion, source)
+	var (
+		machines []*machine
+		failures map[string]error
+	)
+	if args.SkipInvalid {
+		machines, failures, err = readMachinesSkipInvalid(c.schemaVersion(), source)
+	} else {
+		machines, err = readMachines(c.schemaVersion(), source)
+	}
 	if err != nil {
 		return nil, errors.Trace(err)
 	}
 	var result []Machine
 	for _, m := range machines {
-		m.controller = c
-		if ownerDataMatches(m.ownerData, args.OwnerData) {
+		m.setController(c)
+		if ownerDataMatches(m.ownerData, args.OwnerData) && changedSince(m, args.ChangedSince) {
 			result = append(result, m)
 		}
 	}
+	if len(failures) > 0 {
+		return result, NewPartialResultError("machines", failures)
+	}
 	return result, nil
 }
 
+// WalkMachines implements Controller.
+func (c *controller) WalkMachines(args MachinesArgs, fn func(Machine) error) error {
+	readFunc, err := getMachineDeserializationFunc(c.schemaVersion())
+	if err != nil {
+		return errors.Trace(err)
+	}
+	failures := make(map[string]error)
+	// The machines of each page are buffered, so that fn isn't called
+	// while the response is open. The errors of fn are kept apart, so that
+	// they are returned as is.
+	var (
+		pending []Machine
+		fnErr   error
+	)
+	decode := func(i int, item interface{}) error {
+		m, id, err := readMachineListItem(i, item, readFunc)
+		if err != nil {
+			if !args.SkipInvalid {
+				return errors.Trace(err)
+			}
+			logger.Debugf("skipping machine %s: %v", id, err)
+			failures[id] = err
+			return nil
+		}
+		m.setController(c)
+		if ownerDataMatches(m.ownerData, args.OwnerData) && changedSince(m, args.ChangedSince) {
+			pending = append(pending, m)
+		}
+		return nil
+	}
+	flush := func() error {
+		machines := pending
+		pending = nil
+		for _, m := range machines {
+			if fnErr = fn(m); fnErr != nil {
+				return fnErr
+			}
+		}
+		return nil
+	}
+	err = c.streamList("machines", args.params().Values, args.Offset, args.Limit, decode, flush)
+	switch {
+	case fnErr != nil:
+		return fnErr
+	case IsDeserializationError(err):
+		return errors.Trace(err)
+	case err != nil:
+		return NewUnexpectedError(err)
+	case len(failures) > 0:
+		return NewPartialResultError("machines", failures)
+	}
+	return nil
+}
+
+// MachinesPager implements Controller.
+func (c *controller) MachinesPager(args MachinesArgs, pageSize int) (MachinesPager, error) {
+	if pageSize <= 0 {
+		return nil, errors.NotValidf("page size %d", pageSize)
+	}
+	if args.Limit != 0 || args.Offset != 0 {
+		return nil, errors.NotValidf("Limit or Offset with a pager")
+	}
+	return newMachinesPager(c, args, pageSize), nil
+}
+
+// MachinesChangedSince implements Controller.
+func (c *controller) MachinesChangedSince(since time.Time) ([]Machine, error) {
+	return c.Machines(MachinesArgs{ChangedSince: since})
+}
+
+func changedSince(m *machine, since time.Time) bool {
+	if since.IsZero() {
+		return true
+	}
+	updated := m.Updated()
+	return updated.IsZero() || updated.After(since)
+}
+
 func ownerDataMatches(ownerData, filter map[string]string) bool {
 	for key, value := range filter {
 		if ownerData[key] != value {
@@ -597,18 +1822,18 @@ func (c *controller) AllocateMachine(args AllocateMachineArgs) (Machine, Constra
 		// A 409 Status code is "No Matching Machines"
 		if svrErr, ok := errors.Cause(err).(ServerError); ok {
 			if svrErr.StatusCode == http.StatusConflict {
-				return nil, matches, errors.Wrap(err, NewNoMatchError(svrErr.BodyMessage))
+				return nil, matches, wrapError(err, NewNoMatchError(svrErr.BodyMessage))
 			}
 		}
 		// Translate http errors.
 		return nil, matches, NewUnexpectedError(err)
 	}
 
-	machine, err := readMachine(c.apiVersion, result)
+	machine, err := readMachine(c.schemaVersion(), result)
 	if err != nil {
 		return nil, matches, errors.Trace(err)
 	}
-	machine.controller = c
+	machine.setController(c)
 
 	// Parse the constraint matches.
 	matches, err = parseAllocateConstraintsResponse(result, machine)
@@ -641,11 +1866,11 @@ func (c *controller) ReleaseMachines(args ReleaseMachinesArgs) error {
 		if svrErr, ok := errors.Cause(err).(ServerError); ok {
 			switch svrErr.StatusCode {
 			case http.StatusBadRequest:
-				return errors.Wrap(err, NewBadRequestError(svrErr.BodyMessage))
+				return wrapError(err, NewBadRequestError(svrErr.BodyMessage))
 			case http.StatusForbidden:
-				return errors.Wrap(err, NewPermissionError(svrErr.BodyMessage))
+				return wrapError(err, NewPermissionError(svrErr.BodyMessage))
 			case http.StatusConflict:
-				return errors.Wrap(err, NewCannotCompleteError(svrErr.BodyMessage))
+				return wrapError(err, NewCannotCompleteError(svrErr.BodyMessage))
 			}
 		}
 		return NewUnexpectedError(err)
@@ -658,11 +1883,11 @@ func (c *controller) ReleaseMachines(args ReleaseMachinesArgs) error {
 func (c *controller) Files(prefix string) ([]File, error) {
 	params := NewURLParams()
 	params.MaybeAdd("prefix", prefix)
-	source, err := c.getQuery("files", params.Values)
+	source, err := c.getList("files", params.Values, 0)
 	if err != nil {
 		return nil, NewUnexpectedError(err)
 	}
-	files, err := readFiles(c.apiVersion, source)
+	files, err := readFiles(c.schemaVersion(), source)
 	if err != nil {
 		return nil, errors.Trace(err)
 	}
@@ -683,12 +1908,12 @@ func (c *controller) GetFile(filename string) (File, error) {
 	if err != nil {
 		if svrErr, ok := errors.Cause(err).(ServerError); ok {
 			if svrErr.StatusCode == http.StatusNotFound {
-				return nil, errors.Wrap(err, NewNoMatchError(svrErr.BodyMessage))
+				return nil, wrapError(err, NewNoMatchError(svrErr.BodyMessage))
 			}
 		}
 		return nil, NewUnexpectedError(err)
 	}
-	file, err := readFile(c.apiVersion, source)
+	file, err := readFile(c.schemaVersion(), source)
 	if err != nil {
 		return nil, errors.Trace(err)
 	}
@@ -751,7 +1976,7 @@ func (c *controller) AddFile(args AddFileArgs) error {
 	if err != nil {
 		if svrErr, ok := errors.Cause(err).(ServerError); ok {
 			if svrErr.StatusCode == http.StatusBadRequest {
-				return errors.Wrap(err, NewBadRequestError(svrErr.BodyMessage))
+				return wrapError(err, NewBadRequestError(svrErr.BodyMessage))
 			}
 		}
 		return NewUnexpectedError(err)
@@ -763,7 +1988,7 @@ func (c *controller) checkCreds() error {
 	if _, err := c.getOp("users", "whoami"); err != nil {
 		if svrErr, ok := errors.Cause(err).(ServerError); ok {
 			if svrErr.StatusCode == http.StatusUnauthorized {
-				return errors.Wrap(err, NewPermissionError(svrErr.BodyMessage))
+				return wrapError(err, NewPermissionError(svrErr.BodyMessage))
 			}
 		}
 		return NewUnexpectedError(err)
@@ -772,10 +1997,21 @@ func (c *controller) checkCreds() error {
 }
 
 func (c *controller) put(path string, params url.Values) (interface{}, error) {
+	return c.putFiles(path, params, nil)
+}
+
+// putFiles is put with the files sent in a multipart body with the params.
+func (c *controller) putFiles(path string, params url.Values, files map[string][]byte) (interface{}, error) {
 	path = EnsureTrailingSlash(path)
 	requestID := nextRequestID()
 	logger.Tracef("request %x: PUT %s%s, params: %s", requestID, c.client.APIURL, path, params.Encode())
-	bytes, err := c.client.Put(&url.URL{Path: path}, params)
+	var bytes []byte
+	var err error
+	if files != nil {
+		bytes, err = c.client.PutFiles(&url.URL{Path: path}, params, files)
+	} else {
+		bytes, err = c.client.Put(&url.URL{Path: path}, params)
+	}
 	if err != nil {
 		logger.Tracef("response %x: error: %q", requestID, err.Error())
 		logger.Tracef("error detail: %#v", err)
@@ -791,6 +2027,22 @@ func (c *controller) put(path string, params url.Values) (interface{}, error) {
 	return parsed, nil
 }
 
+// putContent sends the content as the body of a PUT request, rather than
+// as parameters.
+func (c *controller) putContent(path string, content []byte) error {
+	path = EnsureTrailingSlash(path)
+	requestID := nextRequestID()
+	logger.Tracef("request %x: PUT %s%s, content: %d bytes", requestID, c.client.APIURL, path, len(content))
+	_, err := c.client.PutContent(&url.URL{Path: path}, "application/octet-stream", content)
+	if err != nil {
+		logger.Tracef("response %x: error: %q", requestID, err.Error())
+		logger.Tracef("error detail: %#v", err)
+		return errors.Trace(err)
+	}
+	logger.Tracef("response %x: complete", requestID)
+	return nil
+}
+
 func (c *controller) post(path, op string, params url.Values) (interface{}, error) {
 	bytes, err := c._postRaw(path, op, params, nil)
 	if err != nil {
@@ -870,6 +2122,117 @@ func (c *controller) _get(path, op string, params url.Values) (interface{}, erro
 	return parsed, nil
 }
 
+// getList performs a GET on a collection and returns the items as a list.
+// Newer MAAS versions paginate some collections, returning an object holding
+// the items of one page along with a link to the next page, rather than a
+// plain list. Pages are followed, either through the next link or by offset,
+// until the collection is exhausted or limit items have been read. A limit of
+// zero means no limit.
+func (c *controller) getList(path string, params url.Values, limit int) (interface{}, error) {
+	return c.getListRange(path, params, 0, limit)
+}
+
+// getListRange is getList skipping the first offset items of the collection.
+// Paginated collections are read from the offset, while plain lists are
+// read in full and sliced.
+func (c *controller) getListRange(path string, params url.Values, offset, limit int) (interface{}, error) {
+	var result []interface{}
+	query := make(url.Values)
+	for key, values := range params {
+		query[key] = values
+	}
+	if offset > 0 {
+		query.Set("offset", strconv.Itoa(offset))
+	}
+	for {
+		source, err := c._get(path, "", query)
+		if err != nil {
+			return nil, errors.Trace(err)
+		}
+		page, err := readPage(source)
+		if err != nil {
+			return nil, errors.Trace(err)
+		}
+		if page == nil {
+			// Not a paginated collection, so the source is the list.
+			return truncateList(skipList(source, offset), limit), nil
+		}
+		result = append(result, page.items...)
+		if limit > 0 && len(result) >= limit {
+			return result[:limit], nil
+		}
+		if len(page.items) == 0 {
+			return result, nil
+		}
+		switch {
+		case page.next != "":
+			nextURL, err := url.Parse(page.next)
+			if err != nil {
+				return nil, NewDeserializationError("bad next page link %q: %v", page.next, err)
+			}
+			path = nextURL.Path
+			query = nextURL.Query()
+		case page.total > offset+len(result):
+			query.Set("offset", strconv.Itoa(offset+len(result)))
+		default:
+			return result, nil
+		}
+	}
+}
+
+// page is one page of a paginated collection.
+type page struct {
+	items []interface{}
+	next  string
+	total int
+}
+
+// readPage returns the page held by source, or nil if the source is not a
+// paginated response.
+func readPage(source interface{}) (*page, error) {
+	if _, ok := source.(map[string]interface{}); !ok {
+		return nil, nil
+	}
+	fields := schema.Fields{
+		"items": schema.List(schema.Any()),
+		"next":  schema.OneOf(schema.Nil(""), schema.String()),
+		"total": schema.ForceInt(),
+	}
+	defaults := schema.Defaults{
+		"next":  "",
+		"total": 0,
+	}
+	checker := schema.FieldMap(fields, defaults)
+	coerced, err := checker.Coerce(source, nil)
+	if err != nil {
+		return nil, WrapWithDeserializationError(err, "page schema check failed")
+	}
+	valid := coerced.(map[string]interface{})
+	next, _ := valid["next"].(string)
+	return &page{
+		items: valid["items"].([]interface{}),
+		next:  next,
+		total: valid["total"].(int),
+	}, nil
+}
+
+func skipList(source interface{}, offset int) interface{} {
+	if list, ok := source.([]interface{}); ok && offset > 0 {
+		if offset > len(list) {
+			offset = len(list)
+		}
+		return list[offset:]
+	}
+	return source
+}
+
+func truncateList(source interface{}, limit int) interface{} {
+	if list, ok := source.([]interface{}); ok && limit > 0 && len(list) > limit {
+		return list[:limit]
+	}
+	return source
+}
+
 func (c *controller) _getRaw(path, op string, params url.Values) ([]byte, error) {
 	path = EnsureTrailingSlash(path)
 	requestID := nextRequestID()
@@ -912,30 +2275,94 @@ func indicatesUnsupportedVersion(err error) bool {
 	return false
 }
 
+// WithAPIKey implements Controller.
+func (c *controller) WithAPIKey(apiKey string) (Controller, error) {
+	signer, err := newRotatingSigner(apiKey, nil)
+	if err != nil {
+		return nil, errors.Trace(err)
+	}
+	client := *c.client
+	client.Signer = signer
+	derived := *c
+	derived.client = &client
+	// The user of the key may not see the same entities.
+	derived.lookups = &lookupCache{}
+	return &derived, nil
+}
+
+// SetAPIKey implements Controller.
+func (c *controller) SetAPIKey(apiKey string) error {
+	signer, ok := c.client.Signer.(*rotatingSigner)
+	if !ok {
+		return errors.NotSupportedf("setting the API key of this controller")
+	}
+	return errors.Trace(signer.setAPIKey(apiKey))
+}
+
+// WithPriority implements Controller.
+func (c *controller) WithPriority(priority Priority) Controller {
+	client := *c.client
+	client.Priority = priority
+	derived := *c
+	derived.client = &client
+	return &derived
+}
+
+// WithContext implements Controller.
+func (c *controller) WithContext(ctx context.Context) Controller {
+	client := *c.client
+	client.Context = ctx
+	derived := *c
+	derived.client = &client
+	return &derived
+}
+
+// ServerTime implements Controller.
+func (c *controller) ServerTime() (time.Time, error) {
+	header, err := c.client.Head(&url.URL{Path: "version/"})
+	if err != nil {
+		return time.Time{}, NewUnexpectedError(err)
+	}
+	date := header.Get("Date")
+	if date == "" {
+		return time.Time{}, errors.NotFoundf("server time")
+	}
+	serverTime, err := http.ParseTime(date)
+	if err != nil {
+		return time.Time{}, errors.NotValidf("server time %q", date)
+	}
+	return serverTime.UTC(), nil
+}
+
 // APIVersionInfo returns the version and subversion strings for the MAAS
 // controller.
 func (c *controller) APIVersionInfo() (string, string, error) {
-	version, subversion, _, err := c.readAPIVersionInfo()
-	return version, subversion, err
+	info, err := c.readServerInfo()
+	return info.Version, info.Subversion, err
 }
 
-func (c *controller) readAPIVersionInfo() (string, string, set.Strings, error) {
+func (c *controller) readServerInfo() (ServerInfo, error) {
 	parsed, err := c.get("version")
 	if indicatesUnsupportedVersion(err) {
-		return "", "", nil, WrapWithUnsupportedVersionError(err)
+		return ServerInfo{}, WrapWithUnsupportedVersionError(err)
 	} else if err != nil {
-		return "", "", nil, errors.Trace(err)
+		return ServerInfo{}, errors.Trace(err)
 	}
 
+	// The subversion of MAAS 3.x packages, built from git, may be null or
+	// missing.
 	fields := schema.Fields{
 		"capabilities": schema.List(schema.String()),
 		"version":      schema.String(),
-		"subversion":   schema.String(),
+		"subversion":   schema.OneOf(schema.Nil(""), schema.String()),
 	}
-	checker := schema.FieldMap(fields, nil) // no defaults
+	defaults := schema.Defaults{
+		"subversion": "",
+	}
+	checker := schema.FieldMap(fields, defaults)
 	coerced, err := checker.Coerce(parsed, nil)
 	if err != nil {
-		return "", "", nil, WrapWithDeserializationError(err, "version response")
+		return ServerInfo{}, WrapWithDeserializationError(err, "version response")
 	}
 	// For now, we don't append any subversion, but as it becomes used, we
 	// should parse and check.
@@ -948,11 +2375,14 @@ func (c *controller) readAPIVersionInfo() (string, string, set.Strings, error) {
 	for _, value := range capabilityValues {
 		capabilities.Add(value.(string))
 	}
+	subversion, _ := valid["subversion"].(string)
 
-	version := valid["version"].(string)
-	subversion := valid["subversion"].(string)
-
-	return version, subversion, capabilities, nil
+	return ServerInfo{
+		APIVersion:   fmt.Sprintf("%d.%d", c.apiVersion.Major, c.apiVersion.Minor),
+		Version:      valid["version"].(string),
+		Subversion:   subversion,
+		Capabilities: capabilities,
+	}, nil
 }
 
 func parseAllocateConstraintsResponse(source interface{}, machine *machine) (ConstraintMatches, error) {
@@ -1064,20 +2494,73 @@ func convertConstraintMatchesAny(source interface{}) map[string][]interface{} {
 
 // Tags implements Controller.
 func (c *controller) Tags() ([]Tag, error) {
-	source, err := c.getQuery("tags", nil)
+	source, err := c.getList("tags", nil, 0)
 	if err != nil {
 		return nil, NewUnexpectedError(err)
 	}
 
-	tags, err := readTags(c.apiVersion, source)
+	tags, err := readTags(c.schemaVersion(), source)
 	if err != nil {
 		return nil, errors.Trace(err)
 	}
 
 	result := make([]Tag, len(tags))
 	for i, tag := range tags {
+		tag.controller = c
 		result[i] = tag
 	}
 
 	return result, nil
 }
+
+// CreateTagArgs is an argument struct for Controller.CreateTag. Only Name
+// is required.
+type CreateTagArgs struct {
+	Name    string
+	Comment string
+	// Definition is an XPath expression over the hardware details of the
+	// nodes. MAAS tags the nodes it matches automatically. Tags without
+	// a definition are assigned manually.
+	Definition string
+	// KernelOpts are the kernel options the tagged nodes boot with.
+	KernelOpts string
+}
+
+// Validate ensures that the Name is set.
+func (a *CreateTagArgs) Validate() error {
+	if a.Name == "" {
+		return errors.NotValidf("missing Name")
+	}
+	return nil
+}
+
+// CreateTag implements Controller.
+func (c *controller) CreateTag(args CreateTagArgs) (Tag, error) {
+	if err := args.Validate(); err != nil {
+		return nil, errors.Trace(err)
+	}
+	params := NewURLParams()
+	params.Values.Add("name", args.Name)
+	params.MaybeAdd("comment", args.Comment)
+	params.MaybeAdd("definition", args.Definition)
+	params.MaybeAdd("kernel_opts", args.KernelOpts)
+	source, err := c.post("tags", "", params.Values)
+	if err != nil {
+		if svrErr, ok := errors.Cause(err).(ServerError); ok {
+			switch svrErr.StatusCode {
+			case http.StatusBadRequest:
+				return nil, wrapError(err, NewBadRequestError(svrErr.BodyMessage))
+			case http.StatusForbidden:
+				return nil, wrapError(err, NewPermissionError(svrErr.BodyMessage))
+			}
+		}
+		return nil, NewUnexpectedError(err)
+	}
+
+	tag, err := readTag(c.schemaVersion(), source)
+	if err != nil {
+		return nil, errors.Trace(err)
+	}
+	tag.controller = c
+	return tag, nil
+}
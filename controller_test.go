@@ -5,9 +5,13 @@ package gomaasapi
 
 import (
 	"bytes"
+	"context"
+	stderrors "errors"
 	"io/ioutil"
 	"net/http"
 	"net/url"
+	"sync"
+	"time"
 
 	"github.com/juju/collections/set"
 	"github.com/juju/errors"
@@ -50,6 +54,7 @@ func (s *controllerSuite) SetUpTest(c *gc.C) {
 	server.AddGetResponse("/api/2.0/machines/?hostname=untasted-markita", http.StatusOK, "["+machineResponse+"]")
 	server.AddGetResponse("/api/2.0/spaces/", http.StatusOK, spacesResponse)
 	server.AddGetResponse("/api/2.0/static-routes/", http.StatusOK, staticRoutesResponse)
+	server.AddGetResponse("/api/2.0/subnets/", http.StatusOK, subnetResponse)
 	server.AddGetResponse("/api/2.0/users/?op=whoami", http.StatusOK, `"captain awesome"`)
 	server.AddGetResponse("/api/2.0/version/", http.StatusOK, versionResponse)
 	server.AddGetResponse("/api/2.0/zones/", http.StatusOK, zoneResponse)
@@ -96,6 +101,115 @@ func (s *controllerSuite) TestNewControllerBadAPIKeyFormat(c *gc.C) {
 	c.Assert(err, jc.Satisfies, errors.IsNotValid)
 }
 
+func (s *controllerSuite) TestNewControllerBadRetryPolicy(c *gc.C) {
+	_, err := NewController(ControllerArgs{
+		BaseURL:     s.server.URL,
+		APIKey:      "fake:as:key",
+		RetryPolicy: &RetryPolicy{Jitter: 2},
+	})
+	c.Assert(err, jc.Satisfies, errors.IsNotValid)
+}
+
+func (s *controllerSuite) TestNewControllerRetryPolicy(c *gc.C) {
+	controller, err := NewController(ControllerArgs{
+		BaseURL: s.server.URL,
+		APIKey:  "fake:as:key",
+		RetryPolicy: &RetryPolicy{
+			Backoff:     time.Millisecond,
+			StatusCodes: []int{http.StatusBadGateway},
+		},
+	})
+	c.Assert(err, jc.ErrorIsNil)
+	admin, err := controller.WithAPIKey("admin:token:secret")
+	c.Assert(err, jc.ErrorIsNil)
+	s.server.AddGetResponse("/api/2.0/tags/", http.StatusBadGateway, "restarting")
+	s.server.AddGetResponse("/api/2.0/tags/", http.StatusOK, "[]")
+	s.server.ResetRequests()
+
+	_, err = admin.Tags()
+	c.Assert(err, jc.ErrorIsNil)
+	c.Check(s.server.RequestCount(), gc.Equals, 2)
+}
+
+type recordingTransport struct {
+	requests []*http.Request
+}
+
+func (t *recordingTransport) RoundTrip(request *http.Request) (*http.Response, error) {
+	t.requests = append(t.requests, request)
+	return http.DefaultTransport.RoundTrip(request)
+}
+
+func (s *controllerSuite) TestNewControllerTransport(c *gc.C) {
+	transport := &recordingTransport{}
+	controller, err := NewController(ControllerArgs{
+		BaseURL:   s.server.URL,
+		APIKey:    "fake:as:key",
+		Transport: transport,
+	})
+	c.Assert(err, jc.ErrorIsNil)
+	// The version and credentials checks go through the transport.
+	c.Check(transport.requests, gc.HasLen, 2)
+
+	_, err = controller.Zones()
+	c.Assert(err, jc.ErrorIsNil)
+	c.Assert(transport.requests, gc.HasLen, 3)
+	c.Check(transport.requests[2].URL.Path, gc.Equals, "/api/2.0/zones/")
+}
+
+func (s *controllerSuite) TestNewControllerHTTPClientAndTransport(c *gc.C) {
+	transport := &recordingTransport{}
+	httpClient := &http.Client{Timeout: time.Minute}
+	maas, err := NewController(ControllerArgs{
+		BaseURL:    s.server.URL,
+		APIKey:     "fake:as:key",
+		HTTPClient: httpClient,
+		Transport:  transport,
+	})
+	c.Assert(err, jc.ErrorIsNil)
+	used := maas.(*controller).client.HTTPClient
+	c.Check(used.Timeout, gc.Equals, time.Minute)
+	c.Check(used.Transport, gc.Equals, http.RoundTripper(transport))
+	// The client passed in isn't changed.
+	c.Check(httpClient.Transport, gc.IsNil)
+	c.Check(transport.requests, gc.HasLen, 2)
+}
+
+func (s *controllerSuite) TestNewControllerTransportSettings(c *gc.C) {
+	transport := &http.Transport{MaxIdleConns: 7}
+	maas, err := NewController(ControllerArgs{
+		BaseURL:        s.server.URL,
+		APIKey:         "fake:as:key",
+		Transport:      transport,
+		TLSConfig:      &TLSConfig{ServerName: "maas.example.com"},
+		Timeouts:       Timeouts{ResponseHeader: 5 * time.Second},
+		ConnectionPool: ConnectionPool{MaxConnsPerHost: 3},
+	})
+	c.Assert(err, jc.ErrorIsNil)
+	// A single copy of the transport has all the settings.
+	used := maas.(*controller).client.HTTPClient.Transport.(*http.Transport)
+	c.Check(used == transport, jc.IsFalse)
+	c.Check(used.MaxIdleConns, gc.Equals, 7)
+	c.Check(used.TLSClientConfig.ServerName, gc.Equals, "maas.example.com")
+	c.Check(used.ResponseHeaderTimeout, gc.Equals, 5*time.Second)
+	c.Check(used.MaxConnsPerHost, gc.Equals, 3)
+	c.Check(transport.TLSClientConfig == nil || transport.TLSClientConfig.ServerName == "", jc.IsTrue)
+	c.Check(transport.ResponseHeaderTimeout, gc.Equals, time.Duration(0))
+	c.Check(transport.MaxConnsPerHost, gc.Equals, 0)
+}
+
+func (*controllerSuite) TestNewControllerTransportSettingsUnsupported(c *gc.C) {
+	_, err := NewController(ControllerArgs{
+		BaseURL:        "http://maas.example.com/MAAS/",
+		APIKey:         "fake:as:key",
+		Transport:      &recordingTransport{},
+		Timeouts:       Timeouts{ResponseHeader: 5 * time.Second},
+		ConnectionPool: ConnectionPool{MaxConnsPerHost: 3},
+	})
+	c.Check(err, jc.Satisfies, errors.IsNotValid)
+	c.Check(err, gc.ErrorMatches, `Timeouts and ConnectionPool with a \*gomaasapi.recordingTransport transport not valid`)
+}
+
 func (s *controllerSuite) TestNewControllerNoSupport(c *gc.C) {
 	server := NewSimpleServer()
 	server.Start()
@@ -220,6 +334,73 @@ func (s *controllerSuite) TestNewController404(c *gc.C) {
 	c.Assert(err, jc.Satisfies, IsUnsupportedVersionError)
 }
 
+func (s *controllerSuite) TestNewControllerLenientUnsupportedVersion(c *gc.C) {
+	// URLs that don't serve the version endpoint, such as those that
+	// aren't MAAS, are rejected even when lenient.
+	server := NewSimpleServer()
+	server.AddGetResponse("/api/2.0/users/?op=whoami", http.StatusOK, `"captain awesome"`)
+	server.AddGetResponse("/api/2.0/version/", http.StatusNotFound, "huh?")
+	server.AddGetResponse("/api/2.0/version/", http.StatusGone, "gone")
+	server.Start()
+	defer server.Close()
+
+	for _, baseURL := range []string{server.URL, server.URL + "/api/2.0/"} {
+		maas, err := NewController(ControllerArgs{
+			BaseURL: baseURL,
+			APIKey:  "fake:as:key",
+			Lenient: true,
+		})
+		c.Assert(maas, gc.IsNil)
+		c.Assert(err, jc.Satisfies, IsUnsupportedVersionError)
+	}
+}
+
+func (s *controllerSuite) TestNewControllerLenientBadVersionResponse(c *gc.C) {
+	server := NewSimpleServer()
+	server.AddGetResponse("/api/2.0/users/?op=whoami", http.StatusOK, `"captain awesome"`)
+	server.AddGetResponse("/api/2.0/users/?op=whoami", http.StatusOK, `"captain awesome"`)
+	// The version is read once strictly, twice when falling back and
+	// once with the versioned URL.
+	for i := 0; i < 4; i++ {
+		server.AddGetResponse("/api/2.0/version/", http.StatusOK, `{"version": {"major": 4}}`)
+	}
+	server.Start()
+	defer server.Close()
+
+	_, err := NewController(ControllerArgs{
+		BaseURL: server.URL,
+		APIKey:  "fake:as:key",
+	})
+	c.Assert(err, jc.Satisfies, IsDeserializationError)
+
+	for _, baseURL := range []string{server.URL, server.URL + "/api/2.0/"} {
+		maas, err := NewController(ControllerArgs{
+			BaseURL: baseURL,
+			APIKey:  "fake:as:key",
+			Lenient: true,
+		})
+		c.Assert(err, jc.ErrorIsNil)
+		c.Check(maas.ServerVersion().APIVersion, gc.Equals, "2.0")
+		c.Check(maas.(*controller).schemaVersion(), gc.Equals, twoDotOh)
+	}
+}
+
+func (s *controllerSuite) TestNewControllerLenientUnexpected(c *gc.C) {
+	// Errors that aren't about the version are still returned.
+	server := NewSimpleServer()
+	server.AddGetResponse("/api/2.0/users/?op=whoami", http.StatusOK, `"captain awesome"`)
+	server.AddGetResponse("/api/2.0/version/", http.StatusInternalServerError, "boom")
+	server.Start()
+	defer server.Close()
+
+	_, err := NewController(ControllerArgs{
+		BaseURL: server.URL,
+		APIKey:  "fake:as:key",
+		Lenient: true,
+	})
+	c.Assert(err, gc.ErrorMatches, ".*boom.*")
+}
+
 func (s *controllerSuite) TestNewControllerWith194Bug(c *gc.C) {
 	// 1.9.4 has a bug where if you ask for /api/2.0/version/ without
 	// being logged in (rather than OAuth connection) it redirects you
@@ -246,6 +427,43 @@ func (s *controllerSuite) TestBootResources(c *gc.C) {
 	c.Assert(resources, gc.HasLen, 5)
 }
 
+func (s *controllerSuite) TestImportBootResources(c *gc.C) {
+	s.server.AddPostResponse("/api/2.0/boot-resources/?op=import", http.StatusOK, "Import of boot resources started")
+	controller := s.getController(c)
+	err := controller.ImportBootResources()
+	c.Assert(err, jc.ErrorIsNil)
+	c.Check(s.server.LastRequest().Method, gc.Equals, "POST")
+}
+
+func (s *controllerSuite) TestImportBootResourcesForbidden(c *gc.C) {
+	s.server.AddPostResponse("/api/2.0/boot-resources/?op=import", http.StatusForbidden, "admins only")
+	controller := s.getController(c)
+	err := controller.ImportBootResources()
+	c.Assert(err, jc.Satisfies, IsPermissionError)
+}
+
+func (s *controllerSuite) TestStopImportBootResources(c *gc.C) {
+	s.server.AddPostResponse("/api/2.0/boot-resources/?op=stop_import", http.StatusOK, "Import of boot resources is being stopped")
+	controller := s.getController(c)
+	err := controller.StopImportBootResources()
+	c.Assert(err, jc.ErrorIsNil)
+}
+
+func (s *controllerSuite) TestIsImportingBootResources(c *gc.C) {
+	s.server.AddGetResponse("/api/2.0/boot-resources/?op=is_importing", http.StatusOK, "true")
+	controller := s.getController(c)
+	importing, err := controller.IsImportingBootResources()
+	c.Assert(err, jc.ErrorIsNil)
+	c.Check(importing, jc.IsTrue)
+}
+
+func (s *controllerSuite) TestIsImportingBootResourcesBadResponse(c *gc.C) {
+	s.server.AddGetResponse("/api/2.0/boot-resources/?op=is_importing", http.StatusOK, `"yes"`)
+	controller := s.getController(c)
+	_, err := controller.IsImportingBootResources()
+	c.Assert(err, jc.Satisfies, IsDeserializationError)
+}
+
 func (s *controllerSuite) TestAPIVersionInfo(c *gc.C) {
 	s.server.AddGetResponse("/api/2.0/version/", http.StatusOK, versionResponse)
 	controller := s.getController(c)
@@ -255,6 +473,261 @@ func (s *controllerSuite) TestAPIVersionInfo(c *gc.C) {
 	c.Assert(subversion, gc.Equals, "git+2f25a2cc0930c0e411106f119bc455c161d75b1a")
 }
 
+func (s *controllerSuite) TestServerVersion(c *gc.C) {
+	maas := s.getController(c)
+	info := maas.ServerVersion()
+	c.Check(info.APIVersion, gc.Equals, "2.0")
+	c.Check(info.Version, gc.Equals, "2.5.0 from source")
+	c.Check(info.Subversion, gc.Equals, "git+2f25a2cc0930c0e411106f119bc455c161d75b1a")
+	c.Check(info.Capabilities.Contains(NetworksManagement), jc.IsTrue)
+	c.Check(maas.(*controller).schemaVersion(), gc.Equals, version.MustParse("2.5.0"))
+}
+
+const maas3VersionResponse = `{"capabilities": ["networks-management", "static-ipaddresses", "ipv6-deployment-ubuntu", "devices-management", "storage-deployment-ubuntu", "network-deployment-ubuntu", "bridging-interface-ubuntu", "bridging-automatic-ubuntu", "authenticate-api"], "version": "3.4.0~rc1-14314-g.99ac3ac21", "subversion": null}`
+
+func (s *controllerSuite) newControllerWithVersionResponse(c *gc.C, response string) Controller {
+	server := NewSimpleServer()
+	server.AddGetResponse("/api/2.0/users/?op=whoami", http.StatusOK, `"captain awesome"`)
+	server.AddGetResponse("/api/2.0/version/", http.StatusOK, response)
+	server.AddGetResponse("/api/2.0/zones/", http.StatusOK, zoneResponse)
+	server.Start()
+	s.AddCleanup(func(*gc.C) { server.Close() })
+
+	maas, err := NewController(ControllerArgs{
+		BaseURL: server.URL,
+		APIKey:  "fake:as:key",
+	})
+	c.Assert(err, jc.ErrorIsNil)
+	return maas
+}
+
+func (s *controllerSuite) TestNewControllerMAAS3(c *gc.C) {
+	maas := s.newControllerWithVersionResponse(c, maas3VersionResponse)
+	info := maas.ServerVersion()
+	c.Check(info.APIVersion, gc.Equals, "2.0")
+	c.Check(info.Version, gc.Equals, "3.4.0~rc1-14314-g.99ac3ac21")
+	c.Check(info.Subversion, gc.Equals, "")
+	c.Check(info.Capabilities.Contains("authenticate-api"), jc.IsTrue)
+	c.Check(maas.(*controller).schemaVersion(), gc.Equals, version.MustParse("3.4.0"))
+
+	// The 2.0 readers are used until MAAS changes its responses.
+	zones, err := maas.Zones()
+	c.Assert(err, jc.ErrorIsNil)
+	c.Check(zones, gc.HasLen, 2)
+}
+
+func (s *controllerSuite) TestNewControllerMissingSubversion(c *gc.C) {
+	maas := s.newControllerWithVersionResponse(c, `{"capabilities": [], "version": "3.2.6"}`)
+	info := maas.ServerVersion()
+	c.Check(info.Version, gc.Equals, "3.2.6")
+	c.Check(info.Subversion, gc.Equals, "")
+}
+
+func (s *controllerSuite) TestSchemaVersionUnrecognised(c *gc.C) {
+	maas := s.newControllerWithVersionResponse(c, `{"capabilities": [], "version": "", "subversion": ""}`)
+	c.Check(maas.(*controller).schemaVersion(), gc.Equals, twoDotOh)
+	_, err := maas.Zones()
+	c.Assert(err, jc.ErrorIsNil)
+}
+
+func (s *controllerSuite) TestReadersSelectedByRelease(c *gc.C) {
+	machines := updateJSONMap(c, machineResponse, map[string]interface{}{
+		"enable_hw_sync": true,
+		"is_dpu":         true,
+	})
+	read := func(response string) Machine {
+		server := NewSimpleServer()
+		server.AddGetResponse("/api/2.0/users/?op=whoami", http.StatusOK, `"captain awesome"`)
+		server.AddGetResponse("/api/2.0/version/", http.StatusOK, response)
+		server.AddGetResponse("/api/2.0/machines/", http.StatusOK, "["+machines+"]")
+		server.Start()
+		defer server.Close()
+
+		maas, err := NewController(ControllerArgs{
+			BaseURL: server.URL,
+			APIKey:  "fake:as:key",
+		})
+		c.Assert(err, jc.ErrorIsNil)
+		result, err := maas.Machines(MachinesArgs{})
+		c.Assert(err, jc.ErrorIsNil)
+		c.Assert(result, gc.HasLen, 1)
+		return result[0]
+	}
+
+	// MAAS 3.4 is read with the 3.2 readers.
+	found := read(maas3VersionResponse)
+	c.Check(found.HardwareSync(), jc.IsTrue)
+	c.Check(found.DPU(), jc.IsTrue)
+
+	// The fields aren't known to the 2.0 readers.
+	found = read(versionResponse)
+	c.Check(found.HardwareSync(), jc.IsFalse)
+	c.Check(found.DPU(), jc.IsFalse)
+}
+
+func (s *controllerSuite) TestServerTime(c *gc.C) {
+	s.server.AddGetResponse("/api/2.0/version/", http.StatusOK, versionResponse)
+	controller := s.getController(c)
+	before := time.Now().UTC().Truncate(time.Second)
+	serverTime, err := controller.ServerTime()
+	c.Assert(err, jc.ErrorIsNil)
+	c.Assert(serverTime.Location(), gc.Equals, time.UTC)
+	c.Assert(serverTime.Before(before), jc.IsFalse)
+	request := s.server.LastRequest()
+	c.Assert(request.Method, gc.Equals, "HEAD")
+	c.Assert(request.URL.Path, gc.Equals, "/api/2.0/version/")
+}
+
+func (s *controllerSuite) TestServerTimeError(c *gc.C) {
+	s.server.AddGetResponse("/api/2.0/version/", http.StatusInternalServerError, "")
+	controller := s.getController(c)
+	_, err := controller.ServerTime()
+	c.Assert(err, jc.Satisfies, IsUnexpectedError)
+}
+
+func (s *controllerSuite) TestWithAPIKey(c *gc.C) {
+	controller := s.getController(c)
+	s.server.ResetRequests()
+	admin, err := controller.WithAPIKey("admin:token:secret")
+	c.Assert(err, jc.ErrorIsNil)
+	c.Assert(s.server.RequestCount(), gc.Equals, 0)
+	c.Assert(admin.Capabilities(), jc.DeepEquals, controller.Capabilities())
+
+	_, err = admin.Zones()
+	c.Assert(err, jc.ErrorIsNil)
+	auth := s.server.LastRequest().Header.Get("Authorization")
+	c.Assert(auth, jc.Contains, `oauth_consumer_key="admin"`)
+	c.Assert(auth, jc.Contains, `oauth_token="token"`)
+
+	// The original controller keeps its credentials.
+	s.server.AddGetResponse("/api/2.0/zones/", http.StatusOK, zoneResponse)
+	_, err = controller.Zones()
+	c.Assert(err, jc.ErrorIsNil)
+	auth = s.server.LastRequest().Header.Get("Authorization")
+	c.Assert(auth, jc.Contains, `oauth_consumer_key="fake"`)
+	c.Assert(auth, jc.Contains, `oauth_token="as"`)
+}
+
+func (s *controllerSuite) TestWithAPIKeyKeepsClient(c *gc.C) {
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+	original := s.getController(c).WithContext(ctx).WithPriority(PriorityHigh)
+	admin, err := original.WithAPIKey("admin:token:secret")
+	c.Assert(err, jc.ErrorIsNil)
+
+	// The client only differs by its signer.
+	expected := *original.(*controller).client
+	client := *admin.(*controller).client
+	c.Check(client.Signer == expected.Signer, jc.IsFalse)
+	client.Signer = expected.Signer
+	c.Check(client == expected, jc.IsTrue)
+}
+
+func (s *controllerSuite) TestWithAPIKeyBadFormat(c *gc.C) {
+	controller := s.getController(c)
+	_, err := controller.WithAPIKey("invalid")
+	c.Assert(err, jc.Satisfies, errors.IsNotValid)
+}
+
+func (s *controllerSuite) TestSetAPIKey(c *gc.C) {
+	controller := s.getController(c)
+	highPriority := controller.WithPriority(PriorityHigh)
+	err := controller.SetAPIKey("admin:token:secret")
+	c.Assert(err, jc.ErrorIsNil)
+
+	// The controllers derived with a different priority use the new key.
+	for _, maas := range []Controller{controller, highPriority} {
+		s.server.AddGetResponse("/api/2.0/zones/", http.StatusOK, zoneResponse)
+		_, err = maas.Zones()
+		c.Assert(err, jc.ErrorIsNil)
+		auth := s.server.LastRequest().Header.Get("Authorization")
+		c.Check(auth, jc.Contains, `oauth_consumer_key="admin"`)
+		c.Check(auth, jc.Contains, `oauth_token="token"`)
+	}
+}
+
+func (s *controllerSuite) TestSetAPIKeyBadFormat(c *gc.C) {
+	controller := s.getController(c)
+	err := controller.SetAPIKey("invalid")
+	c.Assert(err, jc.Satisfies, errors.IsNotValid)
+
+	// The previous key is kept.
+	_, err = controller.Zones()
+	c.Assert(err, jc.ErrorIsNil)
+	auth := s.server.LastRequest().Header.Get("Authorization")
+	c.Check(auth, jc.Contains, `oauth_consumer_key="fake"`)
+}
+
+func (s *controllerSuite) TestCredentialProvider(c *gc.C) {
+	apiKey := "fake:as:key"
+	var providerErr error
+	maas, err := NewController(ControllerArgs{
+		BaseURL: s.server.URL,
+		CredentialProvider: CredentialProviderFunc(func() (string, error) {
+			return apiKey, providerErr
+		}),
+	})
+	c.Assert(err, jc.ErrorIsNil)
+	auth := s.server.LastRequest().Header.Get("Authorization")
+	c.Check(auth, jc.Contains, `oauth_token="as"`)
+
+	apiKey = "admin:token:secret"
+	_, err = maas.Zones()
+	c.Assert(err, jc.ErrorIsNil)
+	auth = s.server.LastRequest().Header.Get("Authorization")
+	c.Check(auth, jc.Contains, `oauth_token="token"`)
+
+	err = maas.SetAPIKey("fake:as:key")
+	c.Check(err, jc.Satisfies, errors.IsNotSupported)
+
+	s.server.ResetRequests()
+	providerErr = errors.New("vault sealed")
+	_, err = maas.Zones()
+	c.Check(err, gc.ErrorMatches, ".*getting API key: vault sealed")
+	apiKey, providerErr = "invalid", nil
+	_, err = maas.Zones()
+	c.Check(err, gc.ErrorMatches, `.*invalid API key "invalid".*`)
+	c.Check(s.server.RequestCount(), gc.Equals, 0)
+}
+
+func (s *controllerSuite) TestCredentialProviderError(c *gc.C) {
+	_, err := NewController(ControllerArgs{
+		BaseURL: s.server.URL,
+		CredentialProvider: CredentialProviderFunc(func() (string, error) {
+			return "", errors.New("vault sealed")
+		}),
+	})
+	c.Assert(err, gc.ErrorMatches, "getting API key: vault sealed")
+}
+
+func (s *controllerSuite) TestWithContext(c *gc.C) {
+	controller := s.getController(c)
+	ctx, cancel := context.WithCancel(context.Background())
+	withContext := controller.WithContext(ctx)
+	machines, err := withContext.Machines(MachinesArgs{})
+	c.Assert(err, jc.ErrorIsNil)
+	c.Assert(machines, gc.Not(gc.HasLen), 0)
+
+	cancel()
+	s.server.ResetRequests()
+	err = machines[0].Start(StartArgs{})
+	c.Assert(err, gc.ErrorMatches, ".*context canceled")
+	_, err = withContext.Zones()
+	c.Assert(err, gc.ErrorMatches, ".*context canceled")
+	c.Assert(s.server.RequestCount(), gc.Equals, 0)
+
+	// Controllers derived with other credentials keep the context.
+	admin, err := withContext.WithAPIKey("admin:token:secret")
+	c.Assert(err, jc.ErrorIsNil)
+	_, err = admin.Zones()
+	c.Assert(err, gc.ErrorMatches, ".*context canceled")
+	c.Assert(s.server.RequestCount(), gc.Equals, 0)
+
+	// The original controller isn't cancelled.
+	_, err = controller.Zones()
+	c.Assert(err, jc.ErrorIsNil)
+}
+
 func (s *controllerSuite) TestDevices(c *gc.C) {
 	controller := s.getController(c)
 	devices, err := controller.Devices(DevicesArgs{})
@@ -262,6 +735,25 @@ func (s *controllerSuite) TestDevices(c *gc.C) {
 	c.Assert(devices, gc.HasLen, 1)
 }
 
+func (s *controllerSuite) TestDevicePoolController(c *gc.C) {
+	controller := s.getController(c)
+	devices, err := controller.Devices(DevicesArgs{})
+	c.Assert(err, jc.ErrorIsNil)
+	c.Assert(devices, gc.HasLen, 1)
+	// Reading the zone and the pool concurrently doesn't race to set
+	// their controller.
+	var wg sync.WaitGroup
+	for i := 0; i < 2; i++ {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			c.Check(devices[0].Zone().(*zone).controller, gc.Equals, controller)
+			c.Check(devices[0].Pool().(*pool).controller, gc.Equals, controller)
+		}()
+	}
+	wg.Wait()
+}
+
 func (s *controllerSuite) TestDevicesArgs(c *gc.C) {
 	controller := s.getController(c)
 	// This will fail with a 404 due to the test server not having something  at
@@ -339,6 +831,292 @@ func (s *controllerSuite) TestSpaces(c *gc.C) {
 	c.Assert(spaces, gc.HasLen, 1)
 }
 
+func (s *controllerSuite) TestSubnets(c *gc.C) {
+	controller := s.getController(c)
+	subnets, err := controller.Subnets()
+	c.Assert(err, jc.ErrorIsNil)
+	c.Assert(subnets, gc.HasLen, 2)
+	c.Check(subnets[0].CIDR(), gc.Equals, "192.168.100.0/24")
+}
+
+func (s *controllerSuite) TestCreateSubnet(c *gc.C) {
+	s.server.AddPostResponse("/api/2.0/subnets/?op=", http.StatusOK, subnetItemResponse)
+	controller := s.getController(c)
+	managed := true
+	subnet, err := controller.CreateSubnet(CreateSubnetArgs{
+		CIDR:       "192.168.122.0/24",
+		Name:       "backend",
+		VLAN:       &fakeVLAN{id: 5001},
+		GatewayIP:  "192.168.122.1",
+		DNSServers: []string{"192.168.122.2"},
+		Managed:    &managed,
+	})
+	c.Assert(err, jc.ErrorIsNil)
+	c.Check(subnet.ID(), gc.Equals, 34)
+
+	form := s.server.LastRequest().PostForm
+	// There should be one entry in the form values for each of the args.
+	c.Check(form, gc.HasLen, 6)
+	c.Check(form.Get("cidr"), gc.Equals, "192.168.122.0/24")
+	c.Check(form.Get("vlan"), gc.Equals, "5001")
+	c.Check(form.Get("managed"), gc.Equals, "true")
+}
+
+func (s *controllerSuite) TestCreateSubnetDefaults(c *gc.C) {
+	s.server.AddPostResponse("/api/2.0/subnets/?op=", http.StatusOK, subnetItemResponse)
+	controller := s.getController(c)
+	_, err := controller.CreateSubnet(CreateSubnetArgs{CIDR: "192.168.122.0/24"})
+	c.Assert(err, jc.ErrorIsNil)
+
+	form := s.server.LastRequest().PostForm
+	c.Check(form, gc.HasLen, 1)
+}
+
+func (s *controllerSuite) TestCreateSubnetBadRequest(c *gc.C) {
+	s.server.AddPostResponse("/api/2.0/subnets/?op=", http.StatusBadRequest, "overlapping subnet")
+	controller := s.getController(c)
+	_, err := controller.CreateSubnet(CreateSubnetArgs{CIDR: "192.168.122.0/24"})
+	c.Assert(err, jc.Satisfies, IsBadRequestError)
+	c.Check(err.Error(), gc.Equals, "overlapping subnet")
+}
+
+func (s *controllerSuite) TestCreateSubnetArgsValidate(c *gc.C) {
+	for i, test := range []struct {
+		args    CreateSubnetArgs
+		errText string
+	}{{
+		args: CreateSubnetArgs{CIDR: "10.0.0.0/8"},
+	}, {
+		errText: "missing CIDR not valid",
+	}, {
+		args:    CreateSubnetArgs{CIDR: "10.0.0.0"},
+		errText: `CIDR "10.0.0.0" not valid`,
+	}} {
+		c.Logf("test %d", i)
+		err := test.args.Validate()
+		if test.errText == "" {
+			c.Check(err, jc.ErrorIsNil)
+		} else {
+			c.Check(err, jc.Satisfies, errors.IsNotValid)
+			c.Check(err.Error(), gc.Equals, test.errText)
+		}
+	}
+}
+
+func (s *controllerSuite) TestIPAddresses(c *gc.C) {
+	s.server.AddGetResponse("/api/2.0/ipaddresses/?all=true&ip=192.168.100.20", http.StatusOK, "["+ipAddressResponse+"]")
+	controller := s.getController(c)
+	addresses, err := controller.IPAddresses(IPAddressesArgs{
+		IPs: []string{"192.168.100.20"},
+		All: true,
+	})
+	c.Assert(err, jc.ErrorIsNil)
+	c.Assert(addresses, gc.HasLen, 1)
+	c.Check(addresses[0].IP(), gc.Equals, "192.168.100.20")
+}
+
+func (s *controllerSuite) TestIPAddressesPaginated(c *gc.C) {
+	s.server.AddGetResponse("/api/2.0/ipaddresses/?all=true", http.StatusOK,
+		`{"items": [`+ipAddressResponse+`], "next": "/api/2.0/ipaddresses/?all=true&page=2"}`)
+	s.server.AddGetResponse("/api/2.0/ipaddresses/?all=true&page=2", http.StatusOK,
+		`{"items": [`+ipAddressResponse+`], "next": null}`)
+	controller := s.getController(c)
+	addresses, err := controller.IPAddresses(IPAddressesArgs{All: true})
+	c.Assert(err, jc.ErrorIsNil)
+	c.Check(addresses, gc.HasLen, 2)
+}
+
+func (s *controllerSuite) TestIPAddressesForbidden(c *gc.C) {
+	s.server.AddGetResponse("/api/2.0/ipaddresses/?owner=bob", http.StatusForbidden, "admins only")
+	controller := s.getController(c)
+	_, err := controller.IPAddresses(IPAddressesArgs{Owner: "bob"})
+	c.Assert(err, jc.Satisfies, IsPermissionError)
+}
+
+func (s *controllerSuite) TestReserveIPAddress(c *gc.C) {
+	s.server.AddPostResponse("/api/2.0/ipaddresses/?op=reserve", http.StatusOK, ipAddressResponse)
+	controller := s.getController(c)
+	address, err := controller.ReserveIPAddress(ReserveIPArgs{
+		Subnet:   &subnet{id: 1},
+		IP:       "192.168.100.20",
+		Hostname: "vip",
+		MAC:      "52:54:00:01:02:03",
+	})
+	c.Assert(err, jc.ErrorIsNil)
+	c.Check(address.IP(), gc.Equals, "192.168.100.20")
+
+	form := s.server.LastRequest().PostForm
+	// There should be one entry in the form values for each of the args.
+	c.Check(form, gc.HasLen, 4)
+	c.Check(form.Get("subnet"), gc.Equals, "1")
+	c.Check(form.Get("ip"), gc.Equals, "192.168.100.20")
+	c.Check(form.Get("hostname"), gc.Equals, "vip")
+	c.Check(form.Get("mac"), gc.Equals, "52:54:00:01:02:03")
+}
+
+func (s *controllerSuite) TestReserveIPAddressExhausted(c *gc.C) {
+	s.server.AddPostResponse("/api/2.0/ipaddresses/?op=reserve", http.StatusServiceUnavailable, "no more addresses")
+	controller := s.getController(c)
+	_, err := controller.ReserveIPAddress(ReserveIPArgs{Subnet: &subnet{id: 1}})
+	c.Assert(err, jc.Satisfies, IsCannotCompleteError)
+	c.Check(err.Error(), gc.Equals, "no more addresses")
+}
+
+func (s *controllerSuite) TestReserveIPAddressInUse(c *gc.C) {
+	s.server.AddPostResponse("/api/2.0/ipaddresses/?op=reserve", http.StatusNotFound, "address in use")
+	controller := s.getController(c)
+	_, err := controller.ReserveIPAddress(ReserveIPArgs{IP: "192.168.100.4"})
+	c.Assert(err, jc.Satisfies, IsBadRequestError)
+}
+
+func (s *controllerSuite) TestReserveIPArgsValidate(c *gc.C) {
+	for i, test := range []struct {
+		args    ReserveIPArgs
+		errText string
+	}{{
+		args: ReserveIPArgs{Subnet: &subnet{id: 1}},
+	}, {
+		args: ReserveIPArgs{IP: "2001:db8::1"},
+	}, {
+		errText: "missing Subnet and IP not valid",
+	}, {
+		args:    ReserveIPArgs{IP: "192.168.100"},
+		errText: `IP "192.168.100" not valid`,
+	}} {
+		c.Logf("test %d", i)
+		err := test.args.Validate()
+		if test.errText == "" {
+			c.Check(err, jc.ErrorIsNil)
+		} else {
+			c.Check(err, jc.Satisfies, errors.IsNotValid)
+			c.Check(err.Error(), gc.Equals, test.errText)
+		}
+	}
+}
+
+func (s *controllerSuite) TestReleaseIPAddress(c *gc.C) {
+	s.server.AddPostResponse("/api/2.0/ipaddresses/?op=release", http.StatusNoContent, "")
+	controller := s.getController(c)
+	err := controller.ReleaseIPAddress("192.168.100.20")
+	c.Assert(err, jc.ErrorIsNil)
+	c.Check(s.server.LastRequest().PostForm.Get("ip"), gc.Equals, "192.168.100.20")
+}
+
+func (s *controllerSuite) TestReleaseIPAddressMissing(c *gc.C) {
+	s.server.AddPostResponse("/api/2.0/ipaddresses/?op=release", http.StatusNotFound, "no such address")
+	controller := s.getController(c)
+	err := controller.ReleaseIPAddress("192.168.100.21")
+	c.Assert(err, jc.Satisfies, IsNoMatchError)
+}
+
+func (s *controllerSuite) TestSubnetUsage(c *gc.C) {
+	s.server.AddGetResponse("/api/2.0/ipaddresses/?all=true", http.StatusOK, ipAddressesResponse)
+	controller := s.getController(c)
+	usage, err := controller.SubnetUsage(&subnet{id: 1})
+	c.Assert(err, jc.ErrorIsNil)
+	c.Check(usage, jc.DeepEquals, ResourceUsage{
+		Machines:     []string{"4y3ha3", "4y3ha4", "4y3ha6"},
+		Links:        []string{"untasted-markita:eth0", "untasted-markita:eth0", "lowlier-glady:eth0", "icier-nina:eth0"},
+		Reservations: []string{"192.168.100.20"},
+	})
+}
+
+func (s *controllerSuite) TestVLANUsage(c *gc.C) {
+	s.server.AddGetResponse("/api/2.0/ipaddresses/?all=true", http.StatusOK, ipAddressesResponse)
+	controller := s.getController(c)
+	usage, err := controller.VLANUsage(&vlan{id: 1})
+	c.Assert(err, jc.ErrorIsNil)
+	c.Check(usage, jc.DeepEquals, ResourceUsage{
+		Subnets:      []string{"192.168.100.0/24"},
+		Machines:     []string{"4y3ha3", "4y3ha4", "4y3ha6"},
+		Links:        []string{"untasted-markita:eth0", "untasted-markita:eth0", "lowlier-glady:eth0", "icier-nina:eth0"},
+		Reservations: []string{"192.168.100.20"},
+	})
+}
+
+func (s *controllerSuite) TestDeleteSubnetInUse(c *gc.C) {
+	s.server.AddGetResponse("/api/2.0/ipaddresses/?all=true", http.StatusOK, ipAddressesResponse)
+	controller := s.getController(c)
+	subnets, err := controller.Subnets()
+	c.Assert(err, jc.ErrorIsNil)
+	err = controller.DeleteSubnet(DeleteSubnetArgs{
+		Subnet:     subnets[0],
+		CheckUsage: true,
+	})
+	c.Assert(err, jc.Satisfies, IsInUseError)
+	c.Check(err.Error(), gc.Equals, "subnet 192.168.100.0/24 in use by 3 machines, 4 links, 1 reservation")
+	c.Check(s.server.LastRequest().Method, gc.Equals, "GET")
+}
+
+func (s *controllerSuite) TestDeleteSubnetUnused(c *gc.C) {
+	s.server.AddGetResponse("/api/2.0/ipaddresses/?all=true", http.StatusOK, "[]")
+	s.server.AddDeleteResponse("/MAAS/api/2.0/subnets/34/", http.StatusNoContent, "")
+	controller := s.getController(c)
+	subnets, err := controller.Subnets()
+	c.Assert(err, jc.ErrorIsNil)
+	err = controller.DeleteSubnet(DeleteSubnetArgs{
+		Subnet:     subnets[1],
+		CheckUsage: true,
+	})
+	c.Assert(err, jc.ErrorIsNil)
+	c.Check(s.server.LastRequest().Method, gc.Equals, "DELETE")
+}
+
+func (s *controllerSuite) TestDeleteSubnetUnchecked(c *gc.C) {
+	s.server.AddDeleteResponse("/MAAS/api/2.0/subnets/1/", http.StatusNoContent, "")
+	controller := s.getController(c)
+	subnets, err := controller.Subnets()
+	c.Assert(err, jc.ErrorIsNil)
+	s.server.ResetRequests()
+	err = controller.DeleteSubnet(DeleteSubnetArgs{Subnet: subnets[0]})
+	c.Assert(err, jc.ErrorIsNil)
+	c.Check(s.server.RequestCount(), gc.Equals, 1)
+}
+
+func (s *controllerSuite) TestDeleteSubnetCheckForbidden(c *gc.C) {
+	s.server.AddGetResponse("/api/2.0/ipaddresses/?all=true", http.StatusForbidden, "admins only")
+	controller := s.getController(c)
+	err := controller.DeleteSubnet(DeleteSubnetArgs{
+		Subnet:     &subnet{id: 34},
+		CheckUsage: true,
+	})
+	c.Assert(err, jc.Satisfies, IsPermissionError)
+	c.Check(err.Error(), gc.Equals, "checking subnet usage: admins only")
+}
+
+func (s *controllerSuite) TestDeleteVLANInUse(c *gc.C) {
+	s.server.AddGetResponse("/api/2.0/ipaddresses/?all=true", http.StatusOK, "[]")
+	controller := s.getController(c)
+	err := controller.DeleteVLAN(DeleteVLANArgs{
+		VLAN:       &vlan{id: 1, vid: 0, fabric: "fabric-0"},
+		CheckUsage: true,
+	})
+	c.Assert(err, jc.Satisfies, IsInUseError)
+	c.Check(err.Error(), gc.Equals, "VLAN 0 on fabric-0 in use by 1 subnet, 3 machines, 4 links")
+}
+
+func (s *controllerSuite) TestDeleteVLAN(c *gc.C) {
+	s.server.AddDeleteResponse("/MAAS/api/2.0/vlans/5001/", http.StatusNoContent, "")
+	controller := s.getController(c)
+	err := controller.DeleteVLAN(DeleteVLANArgs{
+		VLAN: &vlan{id: 5001, resourceURI: "/MAAS/api/2.0/vlans/5001/"},
+	})
+	c.Assert(err, jc.ErrorIsNil)
+}
+
+func (s *controllerSuite) TestDeleteVLANByID(c *gc.C) {
+	s.server.AddDeleteResponse("/api/2.0/vlans/5001/", http.StatusNoContent, "")
+	controller := s.getController(c)
+	err := controller.DeleteVLAN(DeleteVLANArgs{VLAN: &fakeVLAN{id: 5001}})
+	c.Assert(err, jc.ErrorIsNil)
+}
+
+func (s *controllerSuite) TestDeleteVLANMissing(c *gc.C) {
+	controller := s.getController(c)
+	err := controller.DeleteVLAN(DeleteVLANArgs{VLAN: &fakeVLAN{id: 5001}})
+	c.Assert(err, jc.Satisfies, IsNoMatchError)
+}
+
 func (s *controllerSuite) TestStaticRoutes(c *gc.C) {
 	controller := s.getController(c)
 	staticRoutes, err := controller.StaticRoutes()
@@ -434,6 +1212,199 @@ func (s *controllerSuite) TestMachinesArgs(c *gc.C) {
 	c.Assert(request.URL.Query(), gc.HasLen, 7)
 }
 
+func (s *controllerSuite) TestMachinesLimit(c *gc.C) {
+	controller := s.getController(c)
+	machines, err := controller.Machines(MachinesArgs{Limit: 2})
+	c.Assert(err, jc.ErrorIsNil)
+	c.Assert(machines, gc.HasLen, 2)
+}
+
+func (s *controllerSuite) TestMachinesSkipInvalid(c *gc.C) {
+	server, controller := createTestServerController(c, s)
+	invalid := updateJSONMap(c, machineResponse, map[string]interface{}{
+		"system_id": "broken",
+		"zone":      "not a zone",
+	})
+	server.AddGetResponse("/api/2.0/machines/", http.StatusOK, "["+machineResponse+", "+invalid+"]")
+	machines, err := controller.Machines(MachinesArgs{SkipInvalid: true})
+	c.Assert(err, jc.Satisfies, IsPartialResultError)
+	c.Assert(err, gc.ErrorMatches, "skipped 1 invalid machines")
+	failures := errors.Cause(err).(*PartialResultError).Failures
+	c.Assert(failures, gc.HasLen, 1)
+	c.Check(failures["broken"], jc.Satisfies, IsDeserializationError)
+	c.Assert(machines, gc.HasLen, 1)
+	c.Check(machines[0].SystemID(), gc.Equals, "4y3ha3")
+}
+
+func (s *controllerSuite) TestMachinesSkipInvalidAllValid(c *gc.C) {
+	controller := s.getController(c)
+	machines, err := controller.Machines(MachinesArgs{SkipInvalid: true})
+	c.Assert(err, jc.ErrorIsNil)
+	c.Assert(machines, gc.HasLen, 3)
+}
+
+func (s *controllerSuite) TestMachinesInvalidFails(c *gc.C) {
+	server, controller := createTestServerController(c, s)
+	invalid := updateJSONMap(c, machineResponse, map[string]interface{}{
+		"zone": "not a zone",
+	})
+	server.AddGetResponse("/api/2.0/machines/", http.StatusOK, "["+machineResponse+", "+invalid+"]")
+	_, err := controller.Machines(MachinesArgs{})
+	c.Assert(err, jc.Satisfies, IsDeserializationError)
+}
+
+func (s *controllerSuite) TestDNSResources(c *gc.C) {
+	server, controller := createTestServerController(c, s)
+	server.AddGetResponse("/api/2.0/dnsresources/", http.StatusOK, dnsResourcesResponse)
+	dnsResources, err := controller.DNSResources()
+	c.Assert(err, jc.ErrorIsNil)
+	c.Assert(dnsResources, gc.HasLen, 2)
+	c.Assert(dnsResources[0].FQDN(), gc.Equals, "www.maas")
+}
+
+func (s *controllerSuite) TestDNSResourcesPaginated(c *gc.C) {
+	server, controller := createTestServerController(c, s)
+	server.AddGetResponse("/api/2.0/dnsresources/", http.StatusOK,
+		`{"items": `+dnsResourcesResponse+`, "next": null}`)
+	dnsResources, err := controller.DNSResources()
+	c.Assert(err, jc.ErrorIsNil)
+	c.Assert(dnsResources, gc.HasLen, 2)
+}
+
+func (s *controllerSuite) TestHostRecords(c *gc.C) {
+	server, controller := createTestServerController(c, s)
+	server.AddGetResponse("/api/2.0/machines/", http.StatusOK, machinesResponse)
+	server.AddGetResponse("/api/2.0/dnsresources/", http.StatusOK, dnsResourcesResponse)
+	machines, err := controller.Machines(MachinesArgs{})
+	c.Assert(err, jc.ErrorIsNil)
+	records, err := controller.HostRecords(machines[0])
+	c.Assert(err, jc.ErrorIsNil)
+	c.Assert(records, jc.DeepEquals, []HostRecord{{
+		Address: "192.168.100.4",
+		Names:   []string{"untasted-markita.maas", "www.maas"},
+	}, {
+		Address: "192.168.100.5",
+		Names:   []string{"eth0.untasted-markita.maas"},
+	}})
+}
+
+func (s *controllerSuite) TestHostRecordsDNSResourcesError(c *gc.C) {
+	server, controller := createTestServerController(c, s)
+	server.AddGetResponse("/api/2.0/machines/", http.StatusOK, machinesResponse)
+	machines, err := controller.Machines(MachinesArgs{})
+	c.Assert(err, jc.ErrorIsNil)
+	_, err = controller.HostRecords(machines[0])
+	c.Assert(err, jc.Satisfies, IsUnexpectedError)
+}
+
+func (s *controllerSuite) TestMachinesChangedSince(c *gc.C) {
+	server, controller := createTestServerController(c, s)
+	old := updateJSONMap(c, machineResponse, map[string]interface{}{
+		"system_id": "old",
+		"updated":   "2022-03-01T10:00:00.000",
+	})
+	recent := updateJSONMap(c, machineResponse, map[string]interface{}{
+		"system_id": "recent",
+		"updated":   "2022-03-02T10:00:00.000",
+	})
+	unknown := updateJSONMap(c, machineResponse, map[string]interface{}{
+		"system_id": "unknown",
+	})
+	server.AddGetResponse("/api/2.0/machines/", http.StatusOK, "["+old+", "+recent+", "+unknown+"]")
+	machines, err := controller.MachinesChangedSince(time.Date(2022, time.March, 1, 12, 0, 0, 0, time.UTC))
+	c.Assert(err, jc.ErrorIsNil)
+	c.Assert(machines, gc.HasLen, 2)
+	c.Check(machines[0].SystemID(), gc.Equals, "recent")
+	c.Check(machines[1].SystemID(), gc.Equals, "unknown")
+}
+
+func (s *controllerSuite) TestMachinesChangedSinceLocation(c *gc.C) {
+	server := NewSimpleServer()
+	server.AddGetResponse("/api/2.0/users/?op=whoami", http.StatusOK, `"captain awesome"`)
+	server.AddGetResponse("/api/2.0/version/", http.StatusOK, versionResponse)
+	server.AddGetResponse("/api/2.0/machines/", http.StatusOK, "["+updateJSONMap(c, machineResponse, map[string]interface{}{
+		"updated": "2022-03-01 12:30:00",
+	})+"]")
+	server.Start()
+	defer server.Close()
+	controller, err := NewController(ControllerArgs{
+		BaseURL:      server.URL,
+		APIKey:       "fake:as:key",
+		TimeLocation: time.FixedZone("UTC+1", 60*60),
+	})
+	c.Assert(err, jc.ErrorIsNil)
+	// 12:30 in UTC+1 is 11:30 UTC, so the machine hasn't changed since noon.
+	machines, err := controller.MachinesChangedSince(time.Date(2022, time.March, 1, 12, 0, 0, 0, time.UTC))
+	c.Assert(err, jc.ErrorIsNil)
+	c.Assert(machines, gc.HasLen, 0)
+}
+
+func (s *controllerSuite) TestMachinesPaginatedNextLink(c *gc.C) {
+	server, controller := createTestServerController(c, s)
+	server.AddGetResponse("/api/2.0/machines/", http.StatusOK,
+		`{"items": [`+machineResponse+`], "next": "/api/2.0/machines/?page=2", "total": 2}`)
+	server.AddGetResponse("/api/2.0/machines/?page=2", http.StatusOK,
+		`{"items": [`+machineResponse+`], "next": null, "total": 2}`)
+	machines, err := controller.Machines(MachinesArgs{})
+	c.Assert(err, jc.ErrorIsNil)
+	c.Assert(machines, gc.HasLen, 2)
+}
+
+func (s *controllerSuite) TestMachinesPaginatedOffset(c *gc.C) {
+	server, controller := createTestServerController(c, s)
+	server.AddGetResponse("/api/2.0/machines/?zone=foo", http.StatusOK,
+		`{"items": [`+machineResponse+`], "total": 2}`)
+	server.AddGetResponse("/api/2.0/machines/?offset=1&zone=foo", http.StatusOK,
+		`{"items": [`+machineResponse+`], "total": 2}`)
+	machines, err := controller.Machines(MachinesArgs{Zone: "foo"})
+	c.Assert(err, jc.ErrorIsNil)
+	c.Assert(machines, gc.HasLen, 2)
+}
+
+func (s *controllerSuite) TestMachinesPaginatedLimit(c *gc.C) {
+	server, controller := createTestServerController(c, s)
+	server.AddGetResponse("/api/2.0/machines/", http.StatusOK,
+		`{"items": [`+machineResponse+`, `+machineResponse+`], "next": "/api/2.0/machines/?page=2"}`)
+	machines, err := controller.Machines(MachinesArgs{Limit: 1})
+	c.Assert(err, jc.ErrorIsNil)
+	c.Assert(machines, gc.HasLen, 1)
+	// The second page should not have been requested.
+	c.Assert(server.LastRequest().URL.String(), gc.Equals, "/api/2.0/machines/")
+}
+
+func (s *controllerSuite) TestMachinesPaginatedStartOffset(c *gc.C) {
+	server, controller := createTestServerController(c, s)
+	server.AddGetResponse("/api/2.0/machines/?offset=1", http.StatusOK,
+		`{"items": [`+machineResponse+`], "total": 2}`)
+	machines, err := controller.Machines(MachinesArgs{Offset: 1})
+	c.Assert(err, jc.ErrorIsNil)
+	c.Assert(machines, gc.HasLen, 1)
+	c.Assert(server.RequestCount(), gc.Equals, 3)
+}
+
+func (s *controllerSuite) TestMachinesOffsetPlainList(c *gc.C) {
+	server, controller := createTestServerController(c, s)
+	// MAAS versions that don't paginate ignore the offset.
+	server.AddGetResponse("/api/2.0/machines/?offset=1", http.StatusOK, "["+numberedMachines(c, 1, 4)+"]")
+	server.AddGetResponse("/api/2.0/machines/?offset=10", http.StatusOK, "["+numberedMachines(c, 1, 4)+"]")
+	machines, err := controller.Machines(MachinesArgs{Offset: 1, Limit: 2})
+	c.Assert(err, jc.ErrorIsNil)
+	c.Check(machineIDs(machines), jc.DeepEquals, []string{"machine-2", "machine-3"})
+
+	machines, err = controller.Machines(MachinesArgs{Offset: 10})
+	c.Assert(err, jc.ErrorIsNil)
+	c.Check(machines, gc.HasLen, 0)
+}
+
+func (s *controllerSuite) TestDevicesOffset(c *gc.C) {
+	server, controller := createTestServerController(c, s)
+	server.AddGetResponse("/api/2.0/devices/?offset=1", http.StatusOK,
+		`{"items": [`+deviceResponse+`], "total": 2}`)
+	devices, err := controller.Devices(DevicesArgs{Offset: 1})
+	c.Assert(err, jc.ErrorIsNil)
+	c.Assert(devices, gc.HasLen, 1)
+}
+
 func (s *controllerSuite) TestStorageSpec(c *gc.C) {
 	for i, test := range []struct {
 		spec StorageSpec
@@ -834,6 +1805,14 @@ func (s *controllerSuite) TestGetFileMissing(c *gc.C) {
 	controller := s.getController(c)
 	_, err := controller.GetFile("missing")
 	c.Assert(err, jc.Satisfies, IsNoMatchError)
+
+	// The standard errors package finds the error types.
+	var noMatch *NoMatchError
+	c.Assert(stderrors.As(err, &noMatch), jc.IsTrue)
+	var svrErr ServerError
+	c.Assert(stderrors.As(err, &svrErr), jc.IsTrue)
+	c.Check(svrErr.StatusCode, gc.Equals, http.StatusNotFound)
+	c.Check(svrErr.Method, gc.Equals, "GET")
 }
 
 func (s *controllerSuite) TestAddFileArgsValidate(c *gc.C) {
@@ -963,3 +1942,84 @@ func createTestServerController(c *gc.C, suite cleanup) (*SimpleTestServer, Cont
 	c.Assert(err, jc.ErrorIsNil)
 	return server, controller
 }
+
+func (s *controllerSuite) TestIPRanges(c *gc.C) {
+	s.server.AddGetResponse("/api/2.0/ipranges/", http.StatusOK, ipRangesResponse)
+	controller := s.getController(c)
+	ipRanges, err := controller.IPRanges()
+	c.Assert(err, jc.ErrorIsNil)
+	c.Assert(ipRanges, gc.HasLen, 2)
+	c.Check(ipRanges[0].Type(), gc.Equals, "dynamic")
+	c.Check(ipRanges[1].Type(), gc.Equals, "reserved")
+}
+
+func (s *controllerSuite) TestCreateIPRange(c *gc.C) {
+	s.server.AddPostResponse("/api/2.0/ipranges/?op=", http.StatusOK, ipRangeResponse)
+	controller := s.getController(c)
+	ipRange, err := controller.CreateIPRange(CreateIPRangeArgs{
+		Type:    IPRangeTypeDynamic,
+		StartIP: "192.168.100.100",
+		EndIP:   "192.168.100.200",
+		Subnet:  &subnet{id: 1},
+		Comment: "pxe",
+	})
+	c.Assert(err, jc.ErrorIsNil)
+	c.Check(ipRange.ID(), gc.Equals, 1)
+
+	form := s.server.LastRequest().PostForm
+	// There should be one entry in the form values for each of the args.
+	c.Check(form, gc.HasLen, 5)
+	c.Check(form.Get("type"), gc.Equals, "dynamic")
+	c.Check(form.Get("start_ip"), gc.Equals, "192.168.100.100")
+	c.Check(form.Get("end_ip"), gc.Equals, "192.168.100.200")
+	c.Check(form.Get("subnet"), gc.Equals, "1")
+	c.Check(form.Get("comment"), gc.Equals, "pxe")
+}
+
+func (s *controllerSuite) TestCreateIPRangeBadRequest(c *gc.C) {
+	s.server.AddPostResponse("/api/2.0/ipranges/?op=", http.StatusBadRequest, "range overlaps")
+	controller := s.getController(c)
+	_, err := controller.CreateIPRange(CreateIPRangeArgs{
+		Type:    IPRangeTypeReserved,
+		StartIP: "192.168.100.10",
+		EndIP:   "192.168.100.19",
+	})
+	c.Assert(err, jc.Satisfies, IsBadRequestError)
+	c.Check(err.Error(), gc.Equals, "range overlaps")
+}
+
+func (s *controllerSuite) TestCreateIPRangeArgsValidate(c *gc.C) {
+	for i, test := range []struct {
+		args    CreateIPRangeArgs
+		errText string
+	}{{
+		args: CreateIPRangeArgs{Type: IPRangeTypeReserved, StartIP: "10.0.0.1", EndIP: "10.0.0.9"},
+	}, {
+		args:    CreateIPRangeArgs{StartIP: "10.0.0.1", EndIP: "10.0.0.9"},
+		errText: "missing Type not valid",
+	}, {
+		args:    CreateIPRangeArgs{Type: "static", StartIP: "10.0.0.1", EndIP: "10.0.0.9"},
+		errText: `Type "static" not valid`,
+	}, {
+		args:    CreateIPRangeArgs{Type: IPRangeTypeDynamic, EndIP: "10.0.0.9"},
+		errText: "missing StartIP not valid",
+	}, {
+		args:    CreateIPRangeArgs{Type: IPRangeTypeDynamic, StartIP: "10.0.0", EndIP: "10.0.0.9"},
+		errText: `StartIP "10.0.0" not valid`,
+	}, {
+		args:    CreateIPRangeArgs{Type: IPRangeTypeDynamic, StartIP: "10.0.0.1"},
+		errText: "missing EndIP not valid",
+	}, {
+		args:    CreateIPRangeArgs{Type: IPRangeTypeDynamic, StartIP: "10.0.0.1", EndIP: "10.0.0.x"},
+		errText: `EndIP "10.0.0.x" not valid`,
+	}} {
+		c.Logf("test %d", i)
+		err := test.args.Validate()
+		if test.errText == "" {
+			c.Check(err, jc.ErrorIsNil)
+		} else {
+			c.Check(err, jc.Satisfies, errors.IsNotValid)
+			c.Check(err.Error(), gc.Equals, test.errText)
+		}
+	}
+}
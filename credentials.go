@@ -0,0 +1,95 @@
+// Copyright 2022 Canonical Ltd.
+// Licensed under the LGPLv3, see LICENCE file for details.
+
+package gomaasapi
+
+import (
+	"net/http"
+	"sync"
+
+	"github.com/juju/errors"
+)
+
+// CredentialProvider provides the API key of a controller, for services
+// whose keys are rotated, see ControllerArgs.CredentialProvider. APIKey is
+// called before each request is signed, so it should be cheap.
+type CredentialProvider interface {
+	APIKey() (string, error)
+}
+
+// CredentialProviderFunc is an adapter to use a function as a
+// CredentialProvider.
+type CredentialProviderFunc func() (string, error)
+
+// APIKey implements CredentialProvider.
+func (f CredentialProviderFunc) APIKey() (string, error) {
+	return f()
+}
+
+// rotatingSigner signs requests with the current API key of a controller,
+// which is either set with Controller.SetAPIKey or returned by a provider.
+// It is shared by the controllers derived with a different priority or
+// context, so they use the same key.
+type rotatingSigner struct {
+	provider CredentialProvider
+
+	mu     sync.Mutex
+	key    string
+	signer OAuthSigner
+}
+
+var _ OAuthSigner = (*rotatingSigner)(nil)
+
+func newRotatingSigner(apiKey string, provider CredentialProvider) (*rotatingSigner, error) {
+	signer, err := newAPIKeySigner(apiKey)
+	if err != nil {
+		return nil, errors.Trace(err)
+	}
+	return &rotatingSigner{provider: provider, key: apiKey, signer: signer}, nil
+}
+
+// OAuthSign implements OAuthSigner.
+func (s *rotatingSigner) OAuthSign(request *http.Request) error {
+	signer, err := s.current()
+	if err != nil {
+		return errors.Trace(err)
+	}
+	return signer.OAuthSign(request)
+}
+
+// current returns the signer of the current key.
+func (s *rotatingSigner) current() (OAuthSigner, error) {
+	if s.provider == nil {
+		s.mu.Lock()
+		defer s.mu.Unlock()
+		return s.signer, nil
+	}
+	apiKey, err := s.provider.APIKey()
+	if err != nil {
+		return nil, errors.Annotate(err, "getting API key")
+	}
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	if apiKey != s.key {
+		signer, err := newAPIKeySigner(apiKey)
+		if err != nil {
+			return nil, errors.Trace(err)
+		}
+		s.key, s.signer = apiKey, signer
+	}
+	return s.signer, nil
+}
+
+func (s *rotatingSigner) setAPIKey(apiKey string) error {
+	if s.provider != nil {
+		return errors.NotSupportedf("setting the API key of a controller using a CredentialProvider")
+	}
+	signer, err := newAPIKeySigner(apiKey)
+	if err != nil {
+		return errors.Trace(err)
+	}
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.key, s.signer = apiKey, signer
+	return nil
+}
@@ -0,0 +1,389 @@
+// Copyright 2022 Canonical Ltd.
+// Licensed under the LGPLv3, see LICENCE file for details.
+
+package gomaasapi
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"io"
+	"net/http"
+	"net/url"
+	"sort"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/juju/errors"
+)
+
+// redacted replaces the sanitized values of the exchanges.
+const redacted = "REDACTED"
+
+// DebugRecorderArgs is an argument struct for creating a DebugRecorder.
+type DebugRecorderArgs struct {
+	// Writer, if set, receives a text dump of each exchange once its
+	// response has been read. Writes are serialized.
+	Writer io.Writer
+	// Size is the number of exchanges kept for DebugLog, the oldest being
+	// dropped first. It defaults to 100.
+	Size int
+	// MaxBodySize is the number of bytes of each body that are recorded.
+	// It defaults to 64KiB.
+	MaxBodySize int
+}
+
+// Validate ensures the limits aren't negative.
+func (a DebugRecorderArgs) Validate() error {
+	if a.Size < 0 {
+		return errors.NotValidf("negative Size")
+	}
+	if a.MaxBodySize < 0 {
+		return errors.NotValidf("negative MaxBodySize")
+	}
+	return nil
+}
+
+// DebugExchange is a request sent to MAAS and its response, as recorded by
+// a DebugRecorder. The credentials, cookies and the fields of the bodies
+// that hold passwords, secrets, tokens or keys are replaced by "REDACTED".
+type DebugExchange struct {
+	// Time is when the request was sent.
+	Time          time.Time
+	Method        string
+	URL           string
+	RequestHeader http.Header
+	RequestBody   []byte
+	// StatusCode is the status of the response, or 0 if there was none.
+	StatusCode     int
+	ResponseHeader http.Header
+	ResponseBody   []byte
+	// Duration is the time until the response headers were received.
+	Duration time.Duration
+	// Error is the error of the request that got no response.
+	Error string
+	// Truncated is true if a body was larger than the MaxBodySize.
+	Truncated bool
+}
+
+// String returns a text dump of the exchange.
+func (e DebugExchange) String() string {
+	var b strings.Builder
+	fmt.Fprintf(&b, "--- %s %s %s (%v)\n", e.Time.UTC().Format(time.RFC3339Nano), e.Method, e.URL, e.Duration)
+	writeDebugHeader(&b, "> ", e.RequestHeader)
+	writeDebugBody(&b, "> ", e.RequestBody)
+	if e.Error != "" {
+		fmt.Fprintf(&b, "< error: %s\n", e.Error)
+		return b.String()
+	}
+	fmt.Fprintf(&b, "< %d %s\n", e.StatusCode, http.StatusText(e.StatusCode))
+	writeDebugHeader(&b, "< ", e.ResponseHeader)
+	writeDebugBody(&b, "< ", e.ResponseBody)
+	if e.Truncated {
+		b.WriteString("(truncated)\n")
+	}
+	return b.String()
+}
+
+func writeDebugHeader(w io.Writer, prefix string, header http.Header) {
+	names := make([]string, 0, len(header))
+	for name := range header {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+	for _, name := range names {
+		for _, value := range header[name] {
+			fmt.Fprintf(w, "%s%s: %s\n", prefix, name, value)
+		}
+	}
+}
+
+func writeDebugBody(w io.Writer, prefix string, body []byte) {
+	if len(body) == 0 {
+		return
+	}
+	fmt.Fprintf(w, "%s\n", strings.TrimSuffix(prefix, " "))
+	for _, line := range strings.Split(strings.TrimSuffix(string(body), "\n"), "\n") {
+		fmt.Fprintf(w, "%s%s\n", prefix, line)
+	}
+}
+
+// DebugRecorder records the requests sent to MAAS and their responses, so
+// that unexpected payloads can be inspected or attached to bug reports, see
+// ControllerArgs.Debug and Controller.DebugLog. A recorder may be shared
+// between controllers.
+type DebugRecorder struct {
+	writer      io.Writer
+	size        int
+	maxBodySize int
+
+	mu        sync.Mutex
+	exchanges []DebugExchange
+	// next is the index of the slot the next exchange is recorded in,
+	// once exchanges is full.
+	next int
+}
+
+// NewDebugRecorder returns a new recorder, without exchanges.
+func NewDebugRecorder(args DebugRecorderArgs) (*DebugRecorder, error) {
+	if err := args.Validate(); err != nil {
+		return nil, errors.Trace(err)
+	}
+	size := args.Size
+	if size == 0 {
+		size = 100
+	}
+	maxBodySize := args.MaxBodySize
+	if maxBodySize == 0 {
+		maxBodySize = 64 << 10
+	}
+	return &DebugRecorder{
+		writer:      args.Writer,
+		size:        size,
+		maxBodySize: maxBodySize,
+	}, nil
+}
+
+// Exchanges returns the exchanges kept, oldest first.
+func (r *DebugRecorder) Exchanges() []DebugExchange {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	result := make([]DebugExchange, 0, len(r.exchanges))
+	result = append(result, r.exchanges[r.next:]...)
+	return append(result, r.exchanges[:r.next]...)
+}
+
+// Clear drops the exchanges kept.
+func (r *DebugRecorder) Clear() {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	r.exchanges = nil
+	r.next = 0
+}
+
+func (r *DebugRecorder) record(exchange DebugExchange) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	if len(r.exchanges) < r.size {
+		r.exchanges = append(r.exchanges, exchange)
+	} else {
+		r.exchanges[r.next] = exchange
+		r.next = (r.next + 1) % r.size
+	}
+	if r.writer != nil {
+		if _, err := io.WriteString(r.writer, exchange.String()); err != nil {
+			logger.Debugf("writing debug exchange: %v", err)
+		}
+	}
+}
+
+// middleware returns a middleware recording the exchanges. The exchanges
+// are recorded once their responses are closed, so that the bodies are
+// read by the caller as they would be without the recorder.
+func (r *DebugRecorder) middleware() Middleware {
+	return func(next Doer) Doer {
+		return DoerFunc(func(request *http.Request) (*http.Response, error) {
+			exchange := DebugExchange{
+				Time:          time.Now(),
+				Method:        request.Method,
+				URL:           request.URL.String(),
+				RequestHeader: sanitizeDebugHeader(request.Header),
+			}
+			if request.Body != nil {
+				content, err := readAndClose(request.Body)
+				if err != nil {
+					return nil, errors.Trace(err)
+				}
+				request.Body = io.NopCloser(bytes.NewReader(content))
+				exchange.RequestBody = r.body(request.Header.Get("Content-Type"), content, &exchange.Truncated)
+			}
+			response, err := next.Do(request)
+			exchange.Duration = time.Since(exchange.Time)
+			if err != nil {
+				exchange.Error = err.Error()
+				r.record(exchange)
+				return nil, err
+			}
+			exchange.StatusCode = response.StatusCode
+			exchange.ResponseHeader = sanitizeDebugHeader(response.Header)
+			response.Body = &debugBody{
+				ReadCloser: response.Body,
+				recorder:   r,
+				exchange:   exchange,
+				mediaType:  response.Header.Get("Content-Type"),
+			}
+			return response, nil
+		})
+	}
+}
+
+// body returns the sanitized content, truncated to the maximum size.
+func (r *DebugRecorder) body(contentType string, content []byte, truncated *bool) []byte {
+	if strings.HasPrefix(contentType, "multipart/") {
+		// The parts may hold secrets as well as files.
+		return []byte(fmt.Sprintf("[multipart body of %d bytes]", len(content)))
+	}
+	if len(content) <= r.maxBodySize {
+		return sanitizeDebugBody(contentType, content)
+	}
+	*truncated = true
+	if sanitizedContentType(contentType) {
+		// A truncated body can't be parsed to be sanitized.
+		return []byte(fmt.Sprintf("[body of more than %d bytes]", r.maxBodySize))
+	}
+	return content[:r.maxBodySize]
+}
+
+// debugBody records the exchange of the response when it is closed, with
+// the content read until then.
+type debugBody struct {
+	io.ReadCloser
+	recorder  *DebugRecorder
+	exchange  DebugExchange
+	mediaType string
+	content   bytes.Buffer
+	once      sync.Once
+}
+
+// Read implements io.Reader.
+func (b *debugBody) Read(p []byte) (int, error) {
+	n, err := b.ReadCloser.Read(p)
+	if room := b.recorder.maxBodySize + 1 - b.content.Len(); room > 0 {
+		if room > n {
+			room = n
+		}
+		b.content.Write(p[:room])
+	}
+	return n, err
+}
+
+// Close implements io.Closer.
+func (b *debugBody) Close() error {
+	err := b.ReadCloser.Close()
+	b.once.Do(func() {
+		b.exchange.ResponseBody = b.recorder.body(b.mediaType, b.content.Bytes(), &b.exchange.Truncated)
+		b.recorder.record(b.exchange)
+	})
+	return err
+}
+
+// sanitizedHeaders hold credentials.
+var sanitizedHeaders = []string{"Authorization", "Cookie", "Set-Cookie", "X-Csrftoken"}
+
+func sanitizeDebugHeader(header http.Header) http.Header {
+	result := header.Clone()
+	for _, name := range sanitizedHeaders {
+		if _, ok := result[name]; ok {
+			result[name] = []string{redacted}
+		}
+	}
+	return result
+}
+
+// sanitizedContentType returns true if the secrets of bodies of the content
+// type are redacted.
+func sanitizedContentType(contentType string) bool {
+	return strings.HasPrefix(contentType, "application/x-www-form-urlencoded") ||
+		strings.HasPrefix(contentType, "application/json")
+}
+
+// sanitizedField returns true if the field of a body holds a secret, such
+// as "password", "power_pass", "token_secret", or a key such as the private
+// key of an LXD VM host or a "license_key".
+func sanitizedField(name string) bool {
+	name = strings.ToLower(name)
+	for _, secret := range []string{"password", "power_pass", "secret", "token"} {
+		if strings.Contains(name, secret) {
+			return true
+		}
+	}
+	return name == "key" || strings.HasSuffix(name, "_key")
+}
+
+// sanitizedFields returns the names of the fields of a body that hold
+// secrets. A certificate is only redacted when it is sent with a key, as
+// for the LXD VM hosts, since it may then be the one the key is for.
+func sanitizedFields(names []string) map[string]bool {
+	result := make(map[string]bool)
+	var certificates []string
+	for _, name := range names {
+		switch {
+		case sanitizedField(name):
+			result[name] = true
+		case strings.ToLower(name) == "certificate":
+			certificates = append(certificates, name)
+		}
+	}
+	if len(result) > 0 {
+		for _, name := range certificates {
+			result[name] = true
+		}
+	}
+	return result
+}
+
+// sanitizeDebugBody redacts the secrets of form-encoded and JSON bodies.
+// Other bodies are returned unchanged.
+func sanitizeDebugBody(contentType string, content []byte) []byte {
+	switch {
+	case strings.HasPrefix(contentType, "application/x-www-form-urlencoded"):
+		values, err := url.ParseQuery(string(content))
+		if err != nil {
+			return content
+		}
+		names := make([]string, 0, len(values))
+		for name := range values {
+			names = append(names, name)
+		}
+		for name := range sanitizedFields(names) {
+			values[name] = []string{redacted}
+		}
+		return []byte(values.Encode())
+	case strings.HasPrefix(contentType, "application/json"):
+		decoder := json.NewDecoder(bytes.NewReader(content))
+		decoder.UseNumber()
+		var value interface{}
+		if err := decoder.Decode(&value); err != nil {
+			return content
+		}
+		if !sanitizeDebugJSON(value) {
+			return content
+		}
+		sanitized, err := json.Marshal(value)
+		if err != nil {
+			return content
+		}
+		return sanitized
+	}
+	return content
+}
+
+// sanitizeDebugJSON redacts the secrets of the decoded JSON value in place,
+// and returns whether any were found.
+func sanitizeDebugJSON(value interface{}) bool {
+	found := false
+	switch value := value.(type) {
+	case map[string]interface{}:
+		names := make([]string, 0, len(value))
+		for name := range value {
+			names = append(names, name)
+		}
+		secrets := sanitizedFields(names)
+		for name, field := range value {
+			if secrets[name] {
+				value[name] = redacted
+				found = true
+			} else if sanitizeDebugJSON(field) {
+				found = true
+			}
+		}
+	case []interface{}:
+		for _, item := range value {
+			if sanitizeDebugJSON(item) {
+				found = true
+			}
+		}
+	}
+	return found
+}
@@ -0,0 +1,279 @@
+// Copyright 2022 Canonical Ltd.
+// Licensed under the LGPLv3, see LICENCE file for details.
+
+package gomaasapi
+
+import (
+	"bytes"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"net/url"
+
+	"github.com/juju/errors"
+	"github.com/juju/testing"
+	jc "github.com/juju/testing/checkers"
+	gc "gopkg.in/check.v1"
+)
+
+type debugLogSuite struct {
+	testing.LoggingCleanupSuite
+}
+
+var _ = gc.Suite(&debugLogSuite{})
+
+func (s *debugLogSuite) newController(c *gc.C, server *SimpleTestServer, recorder *DebugRecorder) Controller {
+	server.AddGetResponse("/api/2.0/users/?op=whoami", http.StatusOK, `"captain awesome"`)
+	server.AddGetResponse("/api/2.0/version/", http.StatusOK, versionResponse)
+	server.Start()
+	s.AddCleanup(func(*gc.C) { server.Close() })
+	controller, err := NewController(ControllerArgs{
+		BaseURL: server.URL,
+		APIKey:  "fake:as:key",
+		Debug:   recorder,
+	})
+	c.Assert(err, jc.ErrorIsNil)
+	return controller
+}
+
+func (s *debugLogSuite) TestValidate(c *gc.C) {
+	_, err := NewDebugRecorder(DebugRecorderArgs{Size: -1})
+	c.Check(err, jc.Satisfies, errors.IsNotValid)
+	_, err = NewDebugRecorder(DebugRecorderArgs{MaxBodySize: -1})
+	c.Check(err, jc.Satisfies, errors.IsNotValid)
+}
+
+func (s *debugLogSuite) TestDebugLog(c *gc.C) {
+	recorder, err := NewDebugRecorder(DebugRecorderArgs{})
+	c.Assert(err, jc.ErrorIsNil)
+	server := NewSimpleServer()
+	server.AddGetResponse("/api/2.0/zones/", http.StatusOK, zoneResponse)
+	controller := s.newController(c, server, recorder)
+	_, err = controller.Zones()
+	c.Assert(err, jc.ErrorIsNil)
+
+	exchanges := controller.DebugLog()
+	c.Assert(exchanges, gc.HasLen, 3)
+	c.Check(exchanges[0].URL, gc.Equals, server.URL+"/api/2.0/version/")
+	c.Check(exchanges[1].URL, gc.Equals, server.URL+"/api/2.0/users/?op=whoami")
+	zones := exchanges[2]
+	c.Check(zones.Method, gc.Equals, "GET")
+	c.Check(zones.URL, gc.Equals, server.URL+"/api/2.0/zones/")
+	c.Check(zones.RequestHeader.Get("Authorization"), gc.Equals, "REDACTED")
+	c.Check(zones.StatusCode, gc.Equals, http.StatusOK)
+	c.Check(string(zones.ResponseBody), gc.Equals, zoneResponse)
+	c.Check(zones.Duration > 0, jc.IsTrue)
+	c.Check(zones.Truncated, jc.IsFalse)
+
+	// The signature isn't affected by the recorder.
+	c.Check(server.LastRequest().Header.Get("Authorization"), jc.Contains, `oauth_token="as"`)
+
+	recorder.Clear()
+	c.Check(controller.DebugLog(), gc.HasLen, 0)
+}
+
+func (s *debugLogSuite) TestDebugLogDisabled(c *gc.C) {
+	controller := s.newController(c, NewSimpleServer(), nil)
+	c.Check(controller.DebugLog(), gc.IsNil)
+}
+
+func (s *debugLogSuite) TestRingBuffer(c *gc.C) {
+	recorder, err := NewDebugRecorder(DebugRecorderArgs{Size: 2})
+	c.Assert(err, jc.ErrorIsNil)
+	server := NewSimpleServer()
+	for i := 0; i < 3; i++ {
+		server.AddGetResponse(fmt.Sprintf("/api/2.0/zones/%d/", i), http.StatusOK, `{"name": "zone"}`)
+	}
+	controller := s.newController(c, server, recorder)
+	for i := 0; i < 3; i++ {
+		_, err := controller.Do("GET", fmt.Sprintf("zones/%d", i), "", nil)
+		c.Assert(err, jc.ErrorIsNil)
+	}
+
+	exchanges := controller.DebugLog()
+	c.Assert(exchanges, gc.HasLen, 2)
+	c.Check(exchanges[0].URL, gc.Equals, server.URL+"/api/2.0/zones/1/")
+	c.Check(exchanges[1].URL, gc.Equals, server.URL+"/api/2.0/zones/2/")
+}
+
+func (s *debugLogSuite) TestSanitizeRequest(c *gc.C) {
+	recorder, err := NewDebugRecorder(DebugRecorderArgs{})
+	c.Assert(err, jc.ErrorIsNil)
+	server := NewSimpleServer()
+	server.AddPostResponse("/api/2.0/users/", http.StatusOK, `{"username": "bob"}`)
+	controller := s.newController(c, server, recorder)
+	_, err = controller.Do("POST", "users", "", url.Values{
+		"username": {"bob"},
+		"password": {"hunter2"},
+	})
+	c.Assert(err, jc.ErrorIsNil)
+
+	exchanges := controller.DebugLog()
+	body, err := url.ParseQuery(string(exchanges[len(exchanges)-1].RequestBody))
+	c.Assert(err, jc.ErrorIsNil)
+	c.Check(body, jc.DeepEquals, url.Values{
+		"username": {"bob"},
+		"password": {"REDACTED"},
+	})
+	// The password is still sent.
+	c.Check(server.LastRequest().PostForm.Get("password"), gc.Equals, "hunter2")
+}
+
+func (s *debugLogSuite) TestSanitizeVMHostKey(c *gc.C) {
+	recorder, err := NewDebugRecorder(DebugRecorderArgs{})
+	c.Assert(err, jc.ErrorIsNil)
+	server := NewSimpleServer()
+	server.AddPostResponse("/api/2.0/pods/?op=", http.StatusOK, updateJSONMap(c, vmHostResponse, map[string]interface{}{"type": "lxd"}))
+	controller := s.newController(c, server, recorder)
+	_, err = controller.CreateVMHost(CreateVMHostArgs{
+		Type:         VMHostTypeLXD,
+		PowerAddress: "10.0.0.2:8443",
+		Project:      "maas",
+		Certificate:  "CERTIFICATE",
+		Key:          "PRIVATEKEY",
+	})
+	c.Assert(err, jc.ErrorIsNil)
+
+	exchanges := controller.DebugLog()
+	body, err := url.ParseQuery(string(exchanges[len(exchanges)-1].RequestBody))
+	c.Assert(err, jc.ErrorIsNil)
+	c.Check(body, jc.DeepEquals, url.Values{
+		"type":          {"lxd"},
+		"power_address": {"10.0.0.2:8443"},
+		"project":       {"maas"},
+		"certificate":   {"REDACTED"},
+		"key":           {"REDACTED"},
+	})
+	c.Check(server.LastRequest().PostForm.Get("key"), gc.Equals, "PRIVATEKEY")
+}
+
+func (s *debugLogSuite) TestSanitizeLicenseKey(c *gc.C) {
+	recorder, err := NewDebugRecorder(DebugRecorderArgs{})
+	c.Assert(err, jc.ErrorIsNil)
+	server := NewSimpleServer()
+	server.AddPostResponse("/api/2.0/license-keys/?op=", http.StatusOK,
+		`{"osystem": "windows", "distro_series": "win2019", "license_key": "XXXXX-LICENSE", "resource_uri": "/api/2.0/license-key/windows/win2019/"}`)
+	controller := s.newController(c, server, recorder)
+	_, err = controller.CreateLicenseKey(CreateLicenseKeyArgs{
+		OSystem:      "windows",
+		DistroSeries: "win2019",
+		Key:          "XXXXX-LICENSE",
+	})
+	c.Assert(err, jc.ErrorIsNil)
+
+	exchanges := controller.DebugLog()
+	c.Check(string(exchanges[len(exchanges)-1].RequestBody), gc.Equals,
+		"distro_series=win2019&license_key=REDACTED&osystem=windows")
+}
+
+func (s *debugLogSuite) TestSanitizeDebugBody(c *gc.C) {
+	for i, test := range []struct {
+		contentType string
+		body        string
+		expected    string
+	}{{
+		contentType: "application/json",
+		body:        `{"key": "XXXXX-LICENSE"}`,
+		expected:    `{"key":"REDACTED"}`,
+	}, {
+		contentType: "application/json",
+		body:        `[{"name": "lxd", "certificate": "CERT", "private_key": "KEY"}]`,
+		expected:    `[{"certificate":"REDACTED","name":"lxd","private_key":"REDACTED"}]`,
+	}, {
+		// A certificate without a key is public.
+		contentType: "application/json",
+		body:        `{"name": "lxd", "certificate": "CERT"}`,
+		expected:    `{"name": "lxd", "certificate": "CERT"}`,
+	}, {
+		contentType: "application/x-www-form-urlencoded",
+		body:        "certificate=CERT&key=KEY&name=lxd",
+		expected:    "certificate=REDACTED&key=REDACTED&name=lxd",
+	}, {
+		contentType: "application/x-www-form-urlencoded",
+		body:        "keys_count=2&name=lxd",
+		expected:    "keys_count=2&name=lxd",
+	}} {
+		c.Logf("test %d", i)
+		c.Check(string(sanitizeDebugBody(test.contentType, []byte(test.body))), gc.Equals, test.expected)
+	}
+}
+
+func (s *debugLogSuite) TestSanitizeResponse(c *gc.C) {
+	mux := http.NewServeMux()
+	mux.HandleFunc("/api/2.0/users/", func(w http.ResponseWriter, r *http.Request) {
+		fmt.Fprint(w, `"captain awesome"`)
+	})
+	mux.HandleFunc("/api/2.0/version/", func(w http.ResponseWriter, r *http.Request) {
+		fmt.Fprint(w, versionResponse)
+	})
+	mux.HandleFunc("/api/2.0/account/", func(w http.ResponseWriter, r *http.Request) {
+		w.Header().Set("Content-Type", "application/json")
+		w.Header().Set("Set-Cookie", "sessionid=secret")
+		fmt.Fprint(w, `[{"name": "juju", "token_key": "tk", "token_secret": "ts", "id": 12345678901234567890}]`)
+	})
+	server := httptest.NewServer(mux)
+	defer server.Close()
+
+	var output bytes.Buffer
+	recorder, err := NewDebugRecorder(DebugRecorderArgs{Writer: &output})
+	c.Assert(err, jc.ErrorIsNil)
+	controller, err := NewController(ControllerArgs{
+		BaseURL: server.URL,
+		APIKey:  "fake:as:key",
+		Debug:   recorder,
+	})
+	c.Assert(err, jc.ErrorIsNil)
+	result, err := controller.Do("POST", "account", "list_authorisation_tokens", nil)
+	c.Assert(err, jc.ErrorIsNil)
+	tokens, err := result.GetArray()
+	c.Assert(err, jc.ErrorIsNil)
+	c.Check(tokens, gc.HasLen, 1)
+
+	exchanges := controller.DebugLog()
+	exchange := exchanges[len(exchanges)-1]
+	c.Check(string(exchange.ResponseBody), gc.Equals, `[{"id":12345678901234567890,"name":"juju","token_key":"REDACTED","token_secret":"REDACTED"}]`)
+	c.Check(exchange.ResponseHeader.Get("Set-Cookie"), gc.Equals, "REDACTED")
+
+	dump := output.String()
+	c.Check(dump, jc.Contains, "--- ")
+	c.Check(dump, jc.Contains, "POST "+server.URL+"/api/2.0/account/?op=list_authorisation_tokens")
+	c.Check(dump, jc.Contains, "> Authorization: REDACTED\n")
+	c.Check(dump, jc.Contains, "< 200 OK\n")
+	c.Check(dump, jc.Contains, "< Set-Cookie: REDACTED\n")
+	c.Check(dump, gc.Not(jc.Contains), `"ts"`)
+	c.Check(dump, gc.Not(jc.Contains), "sessionid")
+}
+
+func (s *debugLogSuite) TestTruncated(c *gc.C) {
+	recorder, err := NewDebugRecorder(DebugRecorderArgs{MaxBodySize: 4})
+	c.Assert(err, jc.ErrorIsNil)
+	server := NewSimpleServer()
+	server.AddGetResponse("/api/2.0/files/?filename=notes&op=get", http.StatusOK, "not JSON")
+	controller := s.newController(c, server, recorder)
+	result, err := controller.Do("GET", "files", "get", url.Values{"filename": {"notes"}})
+	c.Assert(err, jc.ErrorIsNil)
+	content, err := result.GetBytes()
+	c.Assert(err, jc.ErrorIsNil)
+	c.Check(string(content), gc.Equals, "not JSON")
+
+	exchanges := controller.DebugLog()
+	exchange := exchanges[len(exchanges)-1]
+	c.Check(string(exchange.ResponseBody), gc.Equals, "not ")
+	c.Check(exchange.Truncated, jc.IsTrue)
+}
+
+func (s *debugLogSuite) TestTransportError(c *gc.C) {
+	recorder, err := NewDebugRecorder(DebugRecorderArgs{})
+	c.Assert(err, jc.ErrorIsNil)
+	server := NewSimpleServer()
+	controller := s.newController(c, server, recorder)
+	server.Close()
+	_, err = controller.Do("GET", "zones", "", nil)
+	c.Assert(err, gc.NotNil)
+
+	exchanges := controller.DebugLog()
+	exchange := exchanges[len(exchanges)-1]
+	c.Check(exchange.StatusCode, gc.Equals, 0)
+	c.Check(exchange.Error, gc.Not(gc.Equals), "")
+	c.Check(exchange.String(), jc.Contains, "< error: ")
+}
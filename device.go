@@ -61,6 +61,18 @@ func (d *device) IPAddresses() []string {
 	return d.ipAddresses
 }
 
+// setController sets the controller of the device and of the zone and the
+// pool it returns, once it is read.
+func (d *device) setController(c *controller) {
+	d.controller = c
+	if d.zone != nil {
+		d.zone.controller = c
+	}
+	if d.pool != nil {
+		d.pool.controller = c
+	}
+}
+
 // Zone implements Device.
 func (d *device) Zone() Zone {
 	if d.zone == nil {
@@ -144,17 +156,17 @@ func (d *device) CreateInterface(args CreateInterfaceArgs) (Interface, error) {
 		if svrErr, ok := errors.Cause(err).(ServerError); ok {
 			switch svrErr.StatusCode {
 			case http.StatusNotFound, http.StatusConflict:
-				return nil, errors.Wrap(err, NewBadRequestError(svrErr.BodyMessage))
+				return nil, wrapError(err, NewBadRequestError(svrErr.BodyMessage))
 			case http.StatusForbidden:
-				return nil, errors.Wrap(err, NewPermissionError(svrErr.BodyMessage))
+				return nil, wrapError(err, NewPermissionError(svrErr.BodyMessage))
 			case http.StatusServiceUnavailable:
-				return nil, errors.Wrap(err, NewCannotCompleteError(svrErr.BodyMessage))
+				return nil, wrapError(err, NewCannotCompleteError(svrErr.BodyMessage))
 			}
 		}
 		return nil, NewUnexpectedError(err)
 	}
 
-	iface, err := readInterface(d.controller.apiVersion, result)
+	iface, err := readInterface(d.controller.schemaVersion(), result)
 	if err != nil {
 		return nil, errors.Trace(err)
 	}
@@ -172,9 +184,9 @@ func (d *device) Delete() error {
 		if svrErr, ok := errors.Cause(err).(ServerError); ok {
 			switch svrErr.StatusCode {
 			case http.StatusNotFound:
-				return errors.Wrap(err, NewNoMatchError(svrErr.BodyMessage))
+				return wrapError(err, NewNoMatchError(svrErr.BodyMessage))
 			case http.StatusForbidden:
-				return errors.Wrap(err, NewPermissionError(svrErr.BodyMessage))
+				return wrapError(err, NewPermissionError(svrErr.BodyMessage))
 			}
 		}
 		return NewUnexpectedError(err)
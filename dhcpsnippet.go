@@ -0,0 +1,476 @@
+// Copyright 2022 Canonical Ltd.
+// Licensed under the LGPLv3, see LICENCE file for details.
+
+package gomaasapi
+
+import (
+	"fmt"
+	"net/http"
+
+	"github.com/juju/errors"
+	"github.com/juju/schema"
+	"github.com/juju/version"
+)
+
+type dhcpSnippet struct {
+	controller *controller
+
+	resourceURI string
+
+	id            int
+	name          string
+	value         string
+	description   string
+	enabled       bool
+	globalSnippet bool
+
+	// node is the system ID of the node the snippet is scoped to, if any.
+	node   string
+	subnet *subnet
+}
+
+func (s *dhcpSnippet) updateFrom(other *dhcpSnippet) {
+	s.resourceURI = other.resourceURI
+	s.id = other.id
+	s.name = other.name
+	s.value = other.value
+	s.description = other.description
+	s.enabled = other.enabled
+	s.globalSnippet = other.globalSnippet
+	s.node = other.node
+	s.subnet = other.subnet
+}
+
+// ID implements DHCPSnippet.
+func (s *dhcpSnippet) ID() int {
+	return s.id
+}
+
+// Name implements DHCPSnippet.
+func (s *dhcpSnippet) Name() string {
+	return s.name
+}
+
+// Value implements DHCPSnippet.
+func (s *dhcpSnippet) Value() string {
+	return s.value
+}
+
+// Description implements DHCPSnippet.
+func (s *dhcpSnippet) Description() string {
+	return s.description
+}
+
+// Enabled implements DHCPSnippet.
+func (s *dhcpSnippet) Enabled() bool {
+	return s.enabled
+}
+
+// Global implements DHCPSnippet.
+func (s *dhcpSnippet) Global() bool {
+	return s.globalSnippet
+}
+
+// Node implements DHCPSnippet.
+func (s *dhcpSnippet) Node() string {
+	return s.node
+}
+
+// Subnet implements DHCPSnippet.
+func (s *dhcpSnippet) Subnet() Subnet {
+	if s.subnet == nil {
+		return nil
+	}
+	s.subnet.controller = s.controller
+	return s.subnet
+}
+
+// UpdateDHCPSnippetArgs is an argument struct for DHCPSnippet.Update. Only
+// the fields that are set are changed. At most one of Machine, Subnet and
+// Global may be set, to change the scope of the snippet.
+type UpdateDHCPSnippetArgs struct {
+	Name        string
+	Value       string
+	Description string
+	Enabled     *bool
+
+	Machine Machine
+	Subnet  Subnet
+	// Global makes the snippet apply to all the DHCP configuration.
+	Global bool
+}
+
+// Validate ensures that the snippet isn't given more than one scope.
+func (a *UpdateDHCPSnippetArgs) Validate() error {
+	scopes := 0
+	for _, set := range []bool{a.Machine != nil, a.Subnet != nil, a.Global} {
+		if set {
+			scopes++
+		}
+	}
+	if scopes > 1 {
+		return errors.NotValidf("more than one of Machine, Subnet and Global")
+	}
+	return nil
+}
+
+// Update implements DHCPSnippet.
+func (s *dhcpSnippet) Update(args UpdateDHCPSnippetArgs) error {
+	if err := args.Validate(); err != nil {
+		return errors.Trace(err)
+	}
+	params := NewURLParams()
+	params.MaybeAdd("name", args.Name)
+	params.MaybeAdd("value", args.Value)
+	params.MaybeAdd("description", args.Description)
+	if args.Enabled != nil {
+		params.Values.Add("enabled", fmt.Sprint(*args.Enabled))
+	}
+	if args.Machine != nil {
+		params.Values.Add("node", args.Machine.SystemID())
+	}
+	if args.Subnet != nil {
+		params.Values.Add("subnet", fmt.Sprint(args.Subnet.ID()))
+	}
+	params.MaybeAddBool("global_snippet", args.Global)
+	source, err := s.controller.put(s.resourceURI, params.Values)
+	if err != nil {
+		if svrErr, ok := errors.Cause(err).(ServerError); ok {
+			switch svrErr.StatusCode {
+			case http.StatusNotFound:
+				return wrapError(err, NewNoMatchError(svrErr.BodyMessage))
+			case http.StatusForbidden:
+				return wrapError(err, NewPermissionError(svrErr.BodyMessage))
+			case http.StatusBadRequest:
+				return wrapError(err, NewBadRequestError(svrErr.BodyMessage))
+			}
+		}
+		return NewUnexpectedError(err)
+	}
+
+	response, err := readDHCPSnippet(s.controller.schemaVersion(), source)
+	if err != nil {
+		return errors.Trace(err)
+	}
+	s.updateFrom(response)
+	return nil
+}
+
+// Enable implements DHCPSnippet.
+func (s *dhcpSnippet) Enable() error {
+	enabled := true
+	return s.Update(UpdateDHCPSnippetArgs{Enabled: &enabled})
+}
+
+// Disable implements DHCPSnippet.
+func (s *dhcpSnippet) Disable() error {
+	enabled := false
+	return s.Update(UpdateDHCPSnippetArgs{Enabled: &enabled})
+}
+
+// Delete implements DHCPSnippet.
+func (s *dhcpSnippet) Delete() error {
+	err := s.controller.delete(s.resourceURI)
+	if err != nil {
+		if svrErr, ok := errors.Cause(err).(ServerError); ok {
+			switch svrErr.StatusCode {
+			case http.StatusNotFound:
+				return wrapError(err, NewNoMatchError(svrErr.BodyMessage))
+			case http.StatusForbidden:
+				return wrapError(err, NewPermissionError(svrErr.BodyMessage))
+			}
+		}
+		return NewUnexpectedError(err)
+	}
+	return nil
+}
+
+// DHCPSnippetArgs is an argument struct for the methods of Controller that
+// create DHCP snippets. Name and Value are required.
+type DHCPSnippetArgs struct {
+	Name string
+	// Value is the snippet of ISC DHCP server configuration.
+	Value       string
+	Description string
+	// Enabled, if not nil, sets whether the snippet is added to the DHCP
+	// configuration. MAAS enables new snippets by default.
+	Enabled *bool
+}
+
+// Validate ensures that the Name and Value are set.
+func (a *DHCPSnippetArgs) Validate() error {
+	if a.Name == "" {
+		return errors.NotValidf("missing Name")
+	}
+	if a.Value == "" {
+		return errors.NotValidf("missing Value")
+	}
+	return nil
+}
+
+func (a *DHCPSnippetArgs) params() *URLParams {
+	params := NewURLParams()
+	params.Values.Add("name", a.Name)
+	params.Values.Add("value", a.Value)
+	params.MaybeAdd("description", a.Description)
+	if a.Enabled != nil {
+		params.Values.Add("enabled", fmt.Sprint(*a.Enabled))
+	}
+	return params
+}
+
+// DHCPSnippets implements Controller.
+func (c *controller) DHCPSnippets() ([]DHCPSnippet, error) {
+	snippets, err := c.dhcpSnippets()
+	if err != nil {
+		return nil, errors.Trace(err)
+	}
+	result := make([]DHCPSnippet, len(snippets))
+	for i, snippet := range snippets {
+		result[i] = snippet
+	}
+	return result, nil
+}
+
+// CreateDHCPSnippet implements Controller.
+func (c *controller) CreateDHCPSnippet(args DHCPSnippetArgs) (DHCPSnippet, error) {
+	if err := args.Validate(); err != nil {
+		return nil, errors.Trace(err)
+	}
+	params := args.params()
+	params.Values.Add("global_snippet", "true")
+	snippet, err := c.createDHCPSnippet(params)
+	if err != nil {
+		return nil, errors.Trace(err)
+	}
+	return snippet, nil
+}
+
+// CreateMachineDHCPSnippet implements Controller.
+func (c *controller) CreateMachineDHCPSnippet(machine Machine, args DHCPSnippetArgs) (DHCPSnippet, error) {
+	if err := args.Validate(); err != nil {
+		return nil, errors.Trace(err)
+	}
+	params := args.params()
+	params.Values.Add("node", machine.SystemID())
+	snippet, err := c.createDHCPSnippet(params)
+	if err != nil {
+		return nil, errors.Trace(err)
+	}
+	return snippet, nil
+}
+
+// CreateSubnetDHCPSnippet implements Controller.
+func (c *controller) CreateSubnetDHCPSnippet(subnet Subnet, args DHCPSnippetArgs) (DHCPSnippet, error) {
+	if err := args.Validate(); err != nil {
+		return nil, errors.Trace(err)
+	}
+	params := args.params()
+	params.Values.Add("subnet", fmt.Sprint(subnet.ID()))
+	snippet, err := c.createDHCPSnippet(params)
+	if err != nil {
+		return nil, errors.Trace(err)
+	}
+	return snippet, nil
+}
+
+// DeleteMachineDHCPSnippets implements Controller.
+func (c *controller) DeleteMachineDHCPSnippets(machine Machine) error {
+	systemID := machine.SystemID()
+	return c.deleteDHCPSnippets(func(s *dhcpSnippet) bool {
+		return s.node == systemID
+	})
+}
+
+// DeleteSubnetDHCPSnippets implements Controller.
+func (c *controller) DeleteSubnetDHCPSnippets(subnet Subnet) error {
+	id := subnet.ID()
+	return c.deleteDHCPSnippets(func(s *dhcpSnippet) bool {
+		return s.subnet != nil && s.subnet.id == id
+	})
+}
+
+func (c *controller) createDHCPSnippet(params *URLParams) (*dhcpSnippet, error) {
+	source, err := c.post("dhcp-snippets", "", params.Values)
+	if err != nil {
+		if svrErr, ok := errors.Cause(err).(ServerError); ok {
+			switch svrErr.StatusCode {
+			case http.StatusBadRequest:
+				return nil, wrapError(err, NewBadRequestError(svrErr.BodyMessage))
+			case http.StatusForbidden:
+				return nil, wrapError(err, NewPermissionError(svrErr.BodyMessage))
+			}
+		}
+		return nil, NewUnexpectedError(err)
+	}
+
+	snippet, err := readDHCPSnippet(c.schemaVersion(), source)
+	if err != nil {
+		return nil, errors.Trace(err)
+	}
+	snippet.controller = c
+	return snippet, nil
+}
+
+// deleteDHCPSnippets deletes the snippets that match. Snippets that have
+// already gone, such as the ones MAAS removes along with their node or
+// subnet, are skipped.
+func (c *controller) deleteDHCPSnippets(match func(*dhcpSnippet) bool) error {
+	snippets, err := c.dhcpSnippets()
+	if err != nil {
+		return errors.Trace(err)
+	}
+	for _, snippet := range snippets {
+		if !match(snippet) {
+			continue
+		}
+		if err := snippet.Delete(); err != nil && !IsNoMatchError(err) {
+			return errors.Annotatef(err, "deleting DHCP snippet %q", snippet.name)
+		}
+	}
+	return nil
+}
+
+func (c *controller) dhcpSnippets() ([]*dhcpSnippet, error) {
+	source, err := c.getList("dhcp-snippets", nil, 0)
+	if err != nil {
+		return nil, NewUnexpectedError(err)
+	}
+	snippets, err := readDHCPSnippets(c.schemaVersion(), source)
+	if err != nil {
+		return nil, errors.Trace(err)
+	}
+	for _, snippet := range snippets {
+		snippet.controller = c
+	}
+	return snippets, nil
+}
+
+func readDHCPSnippet(controllerVersion version.Number, source interface{}) (*dhcpSnippet, error) {
+	readFunc, err := getDHCPSnippetDeserializationFunc(controllerVersion)
+	if err != nil {
+		return nil, errors.Trace(err)
+	}
+
+	checker := schema.StringMap(schema.Any())
+	coerced, err := checker.Coerce(source, nil)
+	if err != nil {
+		return nil, WrapWithDeserializationError(err, "dhcp snippet base schema check failed")
+	}
+	valid := coerced.(map[string]interface{})
+	return readFunc(valid)
+}
+
+func readDHCPSnippets(controllerVersion version.Number, source interface{}) ([]*dhcpSnippet, error) {
+	readFunc, err := getDHCPSnippetDeserializationFunc(controllerVersion)
+	if err != nil {
+		return nil, errors.Trace(err)
+	}
+
+	checker := schema.List(schema.StringMap(schema.Any()))
+	coerced, err := checker.Coerce(source, nil)
+	if err != nil {
+		return nil, WrapWithDeserializationError(err, "dhcp snippet base schema check failed")
+	}
+	valid := coerced.([]interface{})
+	return readDHCPSnippetList(valid, readFunc)
+}
+
+func getDHCPSnippetDeserializationFunc(controllerVersion version.Number) (dhcpSnippetDeserializationFunc, error) {
+	var deserialisationVersion version.Number
+	for v := range dhcpSnippetDeserializationFuncs {
+		if v.Compare(deserialisationVersion) > 0 && v.Compare(controllerVersion) <= 0 {
+			deserialisationVersion = v
+		}
+	}
+	if deserialisationVersion == version.Zero {
+		return nil, NewUnsupportedVersionError("no dhcp snippet read func for version %s", controllerVersion)
+	}
+	return dhcpSnippetDeserializationFuncs[deserialisationVersion], nil
+}
+
+// readDHCPSnippetList expects the values of the sourceList to be string maps.
+func readDHCPSnippetList(sourceList []interface{}, readFunc dhcpSnippetDeserializationFunc) ([]*dhcpSnippet, error) {
+	result := make([]*dhcpSnippet, 0, len(sourceList))
+	for i, value := range sourceList {
+		source, ok := value.(map[string]interface{})
+		if !ok {
+			return nil, NewDeserializationError("unexpected value for dhcp snippet %d, %T", i, value)
+		}
+		snippet, err := readFunc(source)
+		if err != nil {
+			return nil, errors.Annotatef(err, "dhcp snippet %d", i)
+		}
+		result = append(result, snippet)
+	}
+	return result, nil
+}
+
+type dhcpSnippetDeserializationFunc func(map[string]interface{}) (*dhcpSnippet, error)
+
+var dhcpSnippetDeserializationFuncs = map[version.Number]dhcpSnippetDeserializationFunc{
+	twoDotOh: dhcpSnippet_2_0,
+}
+
+func dhcpSnippet_2_0(source map[string]interface{}) (*dhcpSnippet, error) {
+	fields := schema.Fields{
+		"resource_uri": schema.String(),
+
+		"id":             schema.ForceInt(),
+		"name":           schema.String(),
+		"value":          schema.String(),
+		"description":    schema.OneOf(schema.Nil(""), schema.String()),
+		"enabled":        schema.Bool(),
+		"global_snippet": schema.Bool(),
+
+		// The node is either its system ID or the node itself, depending
+		// on the version of MAAS.
+		"node": schema.OneOf(schema.Nil(""), schema.String(), schema.FieldMap(
+			schema.Fields{"system_id": schema.String()},
+			nil,
+		)),
+		"subnet": schema.OneOf(schema.Nil(""), schema.StringMap(schema.Any())),
+	}
+	defaults := schema.Defaults{
+		"description": "",
+		"node":        nil,
+		"subnet":      nil,
+	}
+	checker := schema.FieldMap(fields, defaults)
+	coerced, err := checker.Coerce(source, nil)
+	if err != nil {
+		return nil, WrapWithDeserializationError(err, "dhcp snippet 2.0 schema check failed")
+	}
+	valid := coerced.(map[string]interface{})
+	// From here we know that the map returned from the schema coercion
+	// contains fields of the right type.
+
+	var subnet *subnet
+	if subnetMap, ok := valid["subnet"].(map[string]interface{}); ok {
+		if subnet, err = subnet_2_0(subnetMap); err != nil {
+			return nil, errors.Trace(err)
+		}
+	}
+	var node string
+	switch value := valid["node"].(type) {
+	case string:
+		node = value
+	case map[string]interface{}:
+		node = value["system_id"].(string)
+	}
+	description, _ := valid["description"].(string)
+	result := &dhcpSnippet{
+		resourceURI: valid["resource_uri"].(string),
+
+		id:            valid["id"].(int),
+		name:          valid["name"].(string),
+		value:         valid["value"].(string),
+		description:   description,
+		enabled:       valid["enabled"].(bool),
+		globalSnippet: valid["global_snippet"].(bool),
+
+		node:   node,
+		subnet: subnet,
+	}
+	return result, nil
+}
@@ -0,0 +1,360 @@
+// Copyright 2022 Canonical Ltd.
+// Licensed under the LGPLv3, see LICENCE file for details.
+
+package gomaasapi
+
+import (
+	"net/http"
+
+	"github.com/juju/errors"
+	"github.com/juju/testing"
+	jc "github.com/juju/testing/checkers"
+	"github.com/juju/version"
+	gc "gopkg.in/check.v1"
+)
+
+type dhcpSnippetSuite struct {
+	testing.LoggingCleanupSuite
+}
+
+var _ = gc.Suite(&dhcpSnippetSuite{})
+
+func (*dhcpSnippetSuite) TestReadDHCPSnippetsBadSchema(c *gc.C) {
+	_, err := readDHCPSnippets(twoDotOh, "wat?")
+	c.Check(err, jc.Satisfies, IsDeserializationError)
+	c.Assert(err.Error(), gc.Equals, `dhcp snippet base schema check failed: expected list, got string("wat?")`)
+}
+
+func (*dhcpSnippetSuite) TestReadDHCPSnippets(c *gc.C) {
+	snippets, err := readDHCPSnippets(twoDotOh, parseJSON(c, dhcpSnippetsResponse))
+	c.Assert(err, jc.ErrorIsNil)
+	c.Assert(snippets, gc.HasLen, 3)
+
+	snippet := snippets[0]
+	c.Check(snippet.ID(), gc.Equals, 1)
+	c.Check(snippet.Name(), gc.Equals, "pxe-override")
+	c.Check(snippet.Value(), gc.Equals, `filename "custom.pxe";`)
+	c.Check(snippet.Description(), gc.Equals, "custom boot loader")
+	c.Check(snippet.Enabled(), jc.IsTrue)
+	c.Check(snippet.Global(), jc.IsFalse)
+	c.Check(snippet.Node(), gc.Equals, "4y3ha3")
+	c.Check(snippet.Subnet(), gc.IsNil)
+
+	snippet = snippets[1]
+	c.Check(snippet.Node(), gc.Equals, "")
+	c.Check(snippet.Enabled(), jc.IsFalse)
+	subnet := snippet.Subnet()
+	c.Assert(subnet, gc.NotNil)
+	c.Check(subnet.ID(), gc.Equals, 1)
+
+	snippet = snippets[2]
+	c.Check(snippet.Global(), jc.IsTrue)
+	c.Check(snippet.Node(), gc.Equals, "4y3ha4")
+	c.Check(snippet.Description(), gc.Equals, "")
+}
+
+func (*dhcpSnippetSuite) TestLowVersion(c *gc.C) {
+	_, err := readDHCPSnippets(version.MustParse("1.9.0"), parseJSON(c, dhcpSnippetsResponse))
+	c.Assert(err, jc.Satisfies, IsUnsupportedVersionError)
+	c.Assert(err.Error(), gc.Equals, `no dhcp snippet read func for version 1.9.0`)
+}
+
+func (*dhcpSnippetSuite) TestHighVersion(c *gc.C) {
+	snippets, err := readDHCPSnippets(version.MustParse("2.1.9"), parseJSON(c, dhcpSnippetsResponse))
+	c.Assert(err, jc.ErrorIsNil)
+	c.Assert(snippets, gc.HasLen, 3)
+}
+
+func (*dhcpSnippetSuite) TestDHCPSnippetArgsValidate(c *gc.C) {
+	for i, test := range []struct {
+		args    DHCPSnippetArgs
+		errText string
+	}{{
+		args: DHCPSnippetArgs{Name: "pxe", Value: "next-server 10.0.0.1;"},
+	}, {
+		args:    DHCPSnippetArgs{Value: "next-server 10.0.0.1;"},
+		errText: "missing Name not valid",
+	}, {
+		args:    DHCPSnippetArgs{Name: "pxe"},
+		errText: "missing Value not valid",
+	}} {
+		c.Logf("test %d", i)
+		err := test.args.Validate()
+		if test.errText == "" {
+			c.Check(err, jc.ErrorIsNil)
+		} else {
+			c.Check(err, jc.Satisfies, errors.IsNotValid)
+			c.Check(err.Error(), gc.Equals, test.errText)
+		}
+	}
+}
+
+func (s *dhcpSnippetSuite) getServerAndSnippet(c *gc.C) (*SimpleTestServer, DHCPSnippet) {
+	server, controller := createTestServerController(c, s)
+	server.AddGetResponse("/api/2.0/dhcp-snippets/", http.StatusOK, dhcpSnippetsResponse)
+	snippets, err := controller.DHCPSnippets()
+	c.Assert(err, jc.ErrorIsNil)
+	c.Assert(snippets, gc.HasLen, 3)
+	server.ResetRequests()
+	return server, snippets[0]
+}
+
+func (s *dhcpSnippetSuite) TestDHCPSnippets(c *gc.C) {
+	_, snippet := s.getServerAndSnippet(c)
+	c.Check(snippet.Name(), gc.Equals, "pxe-override")
+	c.Check(snippet.Node(), gc.Equals, "4y3ha3")
+}
+
+func (s *dhcpSnippetSuite) TestDHCPSnippetsForbidden(c *gc.C) {
+	server, controller := createTestServerController(c, s)
+	server.AddGetResponse("/api/2.0/dhcp-snippets/", http.StatusForbidden, "admins only")
+	_, err := controller.DHCPSnippets()
+	c.Assert(err, jc.Satisfies, IsUnexpectedError)
+}
+
+func (s *dhcpSnippetSuite) TestCreateDHCPSnippet(c *gc.C) {
+	server, controller := createTestServerController(c, s)
+	response := updateJSONMap(c, dhcpSnippetResponse, map[string]interface{}{
+		"node":           nil,
+		"global_snippet": true,
+	})
+	server.AddPostResponse("/api/2.0/dhcp-snippets/?op=", http.StatusOK, response)
+	snippet, err := controller.CreateDHCPSnippet(DHCPSnippetArgs{
+		Name:  "pxe-override",
+		Value: `filename "custom.pxe";`,
+	})
+	c.Assert(err, jc.ErrorIsNil)
+	c.Check(snippet.Global(), jc.IsTrue)
+	c.Check(snippet.Node(), gc.Equals, "")
+
+	form := server.LastRequest().PostForm
+	c.Check(form, gc.HasLen, 3)
+	c.Check(form.Get("global_snippet"), gc.Equals, "true")
+}
+
+func (s *dhcpSnippetSuite) TestUpdate(c *gc.C) {
+	server, snippet := s.getServerAndSnippet(c)
+	response := updateJSONMap(c, dhcpSnippetResponse, map[string]interface{}{
+		"value": `filename "other.pxe";`,
+		"node":  "4y3ha6",
+	})
+	server.AddPutResponse("/MAAS/api/2.0/dhcp-snippets/1/", http.StatusOK, response)
+	err := snippet.Update(UpdateDHCPSnippetArgs{
+		Value:   `filename "other.pxe";`,
+		Machine: &machine{systemID: "4y3ha6"},
+	})
+	c.Assert(err, jc.ErrorIsNil)
+	c.Check(snippet.Value(), gc.Equals, `filename "other.pxe";`)
+	c.Check(snippet.Node(), gc.Equals, "4y3ha6")
+
+	form := server.LastRequest().PostForm
+	c.Check(form, gc.HasLen, 2)
+	c.Check(form.Get("value"), gc.Equals, `filename "other.pxe";`)
+	c.Check(form.Get("node"), gc.Equals, "4y3ha6")
+}
+
+func (s *dhcpSnippetSuite) TestUpdateGlobal(c *gc.C) {
+	server, snippet := s.getServerAndSnippet(c)
+	server.AddPutResponse("/MAAS/api/2.0/dhcp-snippets/1/", http.StatusOK, dhcpSnippetResponse)
+	err := snippet.Update(UpdateDHCPSnippetArgs{Global: true})
+	c.Assert(err, jc.ErrorIsNil)
+
+	form := server.LastRequest().PostForm
+	c.Check(form, gc.HasLen, 1)
+	c.Check(form.Get("global_snippet"), gc.Equals, "true")
+}
+
+func (s *dhcpSnippetSuite) TestUpdateInvalid(c *gc.C) {
+	server, snippet := s.getServerAndSnippet(c)
+	err := snippet.Update(UpdateDHCPSnippetArgs{
+		Subnet: &subnet{id: 1},
+		Global: true,
+	})
+	c.Assert(err, jc.Satisfies, errors.IsNotValid)
+	c.Check(err.Error(), gc.Equals, "more than one of Machine, Subnet and Global not valid")
+	c.Check(server.RequestCount(), gc.Equals, 0)
+}
+
+func (s *dhcpSnippetSuite) TestUpdateBadRequest(c *gc.C) {
+	server, snippet := s.getServerAndSnippet(c)
+	server.AddPutResponse("/MAAS/api/2.0/dhcp-snippets/1/", http.StatusBadRequest, "invalid dhcpd syntax")
+	err := snippet.Update(UpdateDHCPSnippetArgs{Value: "option"})
+	c.Assert(err, jc.Satisfies, IsBadRequestError)
+	c.Check(snippet.Value(), gc.Equals, `filename "custom.pxe";`)
+}
+
+func (s *dhcpSnippetSuite) TestDisable(c *gc.C) {
+	server, snippet := s.getServerAndSnippet(c)
+	response := updateJSONMap(c, dhcpSnippetResponse, map[string]interface{}{
+		"enabled": false,
+	})
+	server.AddPutResponse("/MAAS/api/2.0/dhcp-snippets/1/", http.StatusOK, response)
+	err := snippet.Disable()
+	c.Assert(err, jc.ErrorIsNil)
+	c.Check(snippet.Enabled(), jc.IsFalse)
+
+	form := server.LastRequest().PostForm
+	c.Check(form, gc.HasLen, 1)
+	c.Check(form.Get("enabled"), gc.Equals, "false")
+}
+
+func (s *dhcpSnippetSuite) TestEnableForbidden(c *gc.C) {
+	server, snippet := s.getServerAndSnippet(c)
+	server.AddPutResponse("/MAAS/api/2.0/dhcp-snippets/1/", http.StatusForbidden, "admins only")
+	err := snippet.Enable()
+	c.Assert(err, jc.Satisfies, IsPermissionError)
+	c.Check(server.LastRequest().PostForm.Get("enabled"), gc.Equals, "true")
+}
+
+func (s *dhcpSnippetSuite) TestCreateMachineDHCPSnippet(c *gc.C) {
+	server, controller := createTestServerController(c, s)
+	server.AddPostResponse("/api/2.0/dhcp-snippets/?op=", http.StatusOK, dhcpSnippetResponse)
+	enabled := true
+	snippet, err := controller.CreateMachineDHCPSnippet(&machine{systemID: "4y3ha3"}, DHCPSnippetArgs{
+		Name:        "pxe-override",
+		Value:       `filename "custom.pxe";`,
+		Description: "custom boot loader",
+		Enabled:     &enabled,
+	})
+	c.Assert(err, jc.ErrorIsNil)
+	c.Check(snippet.Node(), gc.Equals, "4y3ha3")
+
+	form := server.LastRequest().PostForm
+	c.Check(form, gc.HasLen, 5)
+	c.Check(form.Get("name"), gc.Equals, "pxe-override")
+	c.Check(form.Get("value"), gc.Equals, `filename "custom.pxe";`)
+	c.Check(form.Get("description"), gc.Equals, "custom boot loader")
+	c.Check(form.Get("enabled"), gc.Equals, "true")
+	c.Check(form.Get("node"), gc.Equals, "4y3ha3")
+}
+
+func (s *dhcpSnippetSuite) TestCreateSubnetDHCPSnippet(c *gc.C) {
+	server, controller := createTestServerController(c, s)
+	server.AddPostResponse("/api/2.0/dhcp-snippets/?op=", http.StatusOK, dhcpSnippetResponse)
+	_, err := controller.CreateSubnetDHCPSnippet(&subnet{id: 1}, DHCPSnippetArgs{
+		Name:  "ntp",
+		Value: "option ntp-servers 10.0.0.1;",
+	})
+	c.Assert(err, jc.ErrorIsNil)
+
+	form := server.LastRequest().PostForm
+	c.Check(form, gc.HasLen, 3)
+	c.Check(form.Get("subnet"), gc.Equals, "1")
+}
+
+func (s *dhcpSnippetSuite) TestCreateDHCPSnippetInvalid(c *gc.C) {
+	server, controller := createTestServerController(c, s)
+	server.ResetRequests()
+	_, err := controller.CreateSubnetDHCPSnippet(&subnet{id: 1}, DHCPSnippetArgs{Name: "ntp"})
+	c.Assert(err, jc.Satisfies, errors.IsNotValid)
+	c.Check(server.RequestCount(), gc.Equals, 0)
+}
+
+func (s *dhcpSnippetSuite) TestCreateDHCPSnippetBadRequest(c *gc.C) {
+	server, controller := createTestServerController(c, s)
+	server.AddPostResponse("/api/2.0/dhcp-snippets/?op=", http.StatusBadRequest, "invalid dhcpd syntax")
+	_, err := controller.CreateMachineDHCPSnippet(&machine{systemID: "4y3ha3"}, DHCPSnippetArgs{
+		Name:  "broken",
+		Value: "option",
+	})
+	c.Assert(err, jc.Satisfies, IsBadRequestError)
+	c.Check(err.Error(), gc.Equals, "invalid dhcpd syntax")
+}
+
+func (s *dhcpSnippetSuite) TestDeleteMachineDHCPSnippets(c *gc.C) {
+	server, controller := createTestServerController(c, s)
+	server.AddGetResponse("/api/2.0/dhcp-snippets/", http.StatusOK, dhcpSnippetsResponse)
+	server.AddDeleteResponse("/MAAS/api/2.0/dhcp-snippets/1/", http.StatusNoContent, "")
+	err := controller.DeleteMachineDHCPSnippets(&machine{systemID: "4y3ha3"})
+	c.Assert(err, jc.ErrorIsNil)
+	c.Check(server.LastRequest().Method, gc.Equals, "DELETE")
+	c.Check(server.LastRequest().URL.Path, gc.Equals, "/MAAS/api/2.0/dhcp-snippets/1/")
+}
+
+func (s *dhcpSnippetSuite) TestDeleteSubnetDHCPSnippetsAlreadyGone(c *gc.C) {
+	server, controller := createTestServerController(c, s)
+	server.AddGetResponse("/api/2.0/dhcp-snippets/", http.StatusOK, dhcpSnippetsResponse)
+	// No delete response is registered, so the server reports that the
+	// snippet doesn't exist.
+	err := controller.DeleteSubnetDHCPSnippets(&subnet{id: 1})
+	c.Assert(err, jc.ErrorIsNil)
+	c.Check(server.LastRequest().URL.Path, gc.Equals, "/MAAS/api/2.0/dhcp-snippets/2/")
+}
+
+func (s *dhcpSnippetSuite) TestDeleteDHCPSnippetsForbidden(c *gc.C) {
+	server, controller := createTestServerController(c, s)
+	server.AddGetResponse("/api/2.0/dhcp-snippets/", http.StatusOK, dhcpSnippetsResponse)
+	server.AddDeleteResponse("/MAAS/api/2.0/dhcp-snippets/3/", http.StatusForbidden, "admins only")
+	err := controller.DeleteMachineDHCPSnippets(&machine{systemID: "4y3ha4"})
+	c.Assert(err, jc.Satisfies, IsPermissionError)
+	c.Check(err, gc.ErrorMatches, `deleting DHCP snippet "search-domain": admins only`)
+}
+
+const (
+	dhcpSnippetResponse = `
+{
+    "id": 1,
+    "name": "pxe-override",
+    "value": "filename \"custom.pxe\";",
+    "description": "custom boot loader",
+    "history": [],
+    "enabled": true,
+    "node": "4y3ha3",
+    "subnet": null,
+    "iprange": null,
+    "global_snippet": false,
+    "resource_uri": "/MAAS/api/2.0/dhcp-snippets/1/"
+}
+`
+	dhcpSnippetsResponse = "[" + dhcpSnippetResponse + `,
+{
+    "id": 2,
+    "name": "ntp",
+    "value": "option ntp-servers 10.0.0.1;",
+    "description": "",
+    "history": [],
+    "enabled": false,
+    "node": null,
+    "subnet": {
+        "gateway_ip": "192.168.100.1",
+        "name": "192.168.100.0/24",
+        "vlan": {
+            "fabric": "fabric-0",
+            "resource_uri": "/MAAS/api/2.0/vlans/1/",
+            "name": "untagged",
+            "secondary_rack": null,
+            "primary_rack": "4y3h7n",
+            "vid": 0,
+            "dhcp_on": true,
+            "id": 1,
+            "mtu": 1500
+        },
+        "space": "space-0",
+        "id": 1,
+        "resource_uri": "/MAAS/api/2.0/subnets/1/",
+        "dns_servers": [],
+        "cidr": "192.168.100.0/24",
+        "rdns_mode": 2
+    },
+    "iprange": null,
+    "global_snippet": false,
+    "resource_uri": "/MAAS/api/2.0/dhcp-snippets/2/"
+},
+{
+    "id": 3,
+    "name": "search-domain",
+    "value": "option domain-search \"example.com\";",
+    "description": null,
+    "history": [],
+    "enabled": true,
+    "node": {
+        "system_id": "4y3ha4",
+        "hostname": "lowlier-glady"
+    },
+    "subnet": null,
+    "iprange": null,
+    "global_snippet": true,
+    "resource_uri": "/MAAS/api/2.0/dhcp-snippets/3/"
+}
+]`
+)
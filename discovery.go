@@ -0,0 +1,346 @@
+// Copyright 2022 Canonical Ltd.
+// Licensed under the LGPLv3, see LICENCE file for details.
+
+package gomaasapi
+
+import (
+	"net"
+	"net/http"
+	"time"
+
+	"github.com/juju/errors"
+	"github.com/juju/schema"
+	"github.com/juju/version"
+)
+
+// discoveryTimeFormats are the formats MAAS uses for the times of
+// discoveries, depending on its version.
+var discoveryTimeFormats = []string{
+	"2006-01-02T15:04:05.999999999",
+	time.RFC3339Nano,
+}
+
+type discovery struct {
+	discoveryID     string
+	ip              string
+	macAddress      string
+	macOrganization string
+	hostname        string
+	fabricName      string
+	vid             int
+	observer        string
+	observerName    string
+	lastSeen        time.Time
+}
+
+// ID implements Discovery.
+func (d *discovery) ID() string {
+	return d.discoveryID
+}
+
+// IP implements Discovery.
+func (d *discovery) IP() string {
+	return d.ip
+}
+
+// MACAddress implements Discovery.
+func (d *discovery) MACAddress() string {
+	return d.macAddress
+}
+
+// MACOrganization implements Discovery.
+func (d *discovery) MACOrganization() string {
+	return d.macOrganization
+}
+
+// Hostname implements Discovery.
+func (d *discovery) Hostname() string {
+	return d.hostname
+}
+
+// FabricName implements Discovery.
+func (d *discovery) FabricName() string {
+	return d.fabricName
+}
+
+// VID implements Discovery.
+func (d *discovery) VID() int {
+	return d.vid
+}
+
+// Observer implements Discovery.
+func (d *discovery) Observer() string {
+	return d.observer
+}
+
+// ObserverHostname implements Discovery.
+func (d *discovery) ObserverHostname() string {
+	return d.observerName
+}
+
+// LastSeen implements Discovery.
+func (d *discovery) LastSeen() time.Time {
+	return d.lastSeen
+}
+
+// Discoveries implements Controller.
+func (c *controller) Discoveries() ([]Discovery, error) {
+	source, err := c.getList("discovery", nil, 0)
+	if err != nil {
+		return nil, NewUnexpectedError(err)
+	}
+	discoveries, err := readDiscoveries(c.schemaVersion(), source)
+	if err != nil {
+		return nil, errors.Trace(err)
+	}
+	var result []Discovery
+	for _, d := range discoveries {
+		result = append(result, d)
+	}
+	return result, nil
+}
+
+// ClearDiscoveriesArgs is an argument struct for
+// Controller.ClearDiscoveries. At least one of the fields must be set.
+type ClearDiscoveriesArgs struct {
+	// MDNS clears the hostnames observed through mDNS.
+	MDNS bool
+	// Neighbours clears the neighbours observed on the networks.
+	Neighbours bool
+	// All clears everything that has been discovered.
+	All bool
+}
+
+// Validate ensures that something is cleared.
+func (a *ClearDiscoveriesArgs) Validate() error {
+	if !a.MDNS && !a.Neighbours && !a.All {
+		return errors.NotValidf("missing MDNS, Neighbours or All")
+	}
+	return nil
+}
+
+// ClearDiscoveries implements Controller.
+func (c *controller) ClearDiscoveries(args ClearDiscoveriesArgs) error {
+	if err := args.Validate(); err != nil {
+		return errors.Trace(err)
+	}
+	params := NewURLParams()
+	params.MaybeAddBool("mdns", args.MDNS)
+	params.MaybeAddBool("neighbours", args.Neighbours)
+	params.MaybeAddBool("all", args.All)
+	_, err := c._postRaw("discovery", "clear", params.Values, nil)
+	if err != nil {
+		if svrErr, ok := errors.Cause(err).(ServerError); ok {
+			switch svrErr.StatusCode {
+			case http.StatusBadRequest:
+				return wrapError(err, NewBadRequestError(svrErr.BodyMessage))
+			case http.StatusForbidden:
+				return wrapError(err, NewPermissionError(svrErr.BodyMessage))
+			}
+		}
+		return NewUnexpectedError(err)
+	}
+	return nil
+}
+
+// ScanNetworksArgs is an argument struct for Controller.ScanNetworks. All
+// the fields are optional.
+type ScanNetworksArgs struct {
+	// CIDRs are the networks to scan. If none are given, MAAS scans all
+	// the subnets that have active discovery enabled, or all the subnets
+	// if Force is set.
+	CIDRs []string
+	// Force scans the networks even if they have been scanned recently, or
+	// if active discovery is disabled for them.
+	Force bool
+	// AlwaysUsePing uses ping rather than nmap, even if nmap is installed
+	// on the rack controllers.
+	AlwaysUsePing bool
+	// Slow limits the scan to a rate that shouldn't flood the network.
+	Slow bool
+	// Threads is the number of concurrent threads to use on each rack
+	// controller. Zero uses the MAAS default.
+	Threads int
+}
+
+// Validate ensures that the CIDRs are valid.
+func (a *ScanNetworksArgs) Validate() error {
+	for _, cidr := range a.CIDRs {
+		if _, _, err := net.ParseCIDR(cidr); err != nil {
+			return errors.NotValidf("CIDR %q", cidr)
+		}
+	}
+	if a.Threads < 0 {
+		return errors.NotValidf("Threads %d", a.Threads)
+	}
+	return nil
+}
+
+// ScanNetworks implements Controller.
+func (c *controller) ScanNetworks(args ScanNetworksArgs) (ScanResult, error) {
+	result, err := c.scanNetworks(args)
+	if err != nil {
+		return nil, errors.Trace(err)
+	}
+	return result, nil
+}
+
+func (c *controller) scanNetworks(args ScanNetworksArgs) (*scanResult, error) {
+	if err := args.Validate(); err != nil {
+		return nil, errors.Trace(err)
+	}
+	params := NewURLParams()
+	params.MaybeAddMany("cidr", args.CIDRs)
+	params.MaybeAddBool("force", args.Force)
+	params.MaybeAddBool("always_use_ping", args.AlwaysUsePing)
+	params.MaybeAddBool("slow", args.Slow)
+	params.MaybeAddInt("threads", args.Threads)
+	requested := time.Now().UTC()
+	source, err := c.post("discovery", "scan", params.Values)
+	if err != nil {
+		if svrErr, ok := errors.Cause(err).(ServerError); ok {
+			switch svrErr.StatusCode {
+			case http.StatusBadRequest:
+				return nil, wrapError(err, NewBadRequestError(svrErr.BodyMessage))
+			case http.StatusForbidden:
+				return nil, wrapError(err, NewPermissionError(svrErr.BodyMessage))
+			}
+		}
+		return nil, NewUnexpectedError(err)
+	}
+
+	result, err := readScanResult(c.schemaVersion(), source)
+	if err != nil {
+		return nil, errors.Trace(err)
+	}
+	result.requested = requested
+	return result, nil
+}
+
+func readDiscoveries(controllerVersion version.Number, source interface{}) ([]*discovery, error) {
+	readFunc, err := getDiscoveryDeserializationFunc(controllerVersion)
+	if err != nil {
+		return nil, errors.Trace(err)
+	}
+
+	checker := schema.List(schema.StringMap(schema.Any()))
+	coerced, err := checker.Coerce(source, nil)
+	if err != nil {
+		return nil, WrapWithDeserializationError(err, "discovery base schema check failed")
+	}
+	valid := coerced.([]interface{})
+	return readDiscoveryList(valid, readFunc)
+}
+
+func getDiscoveryDeserializationFunc(controllerVersion version.Number) (discoveryDeserializationFunc, error) {
+	var deserialisationVersion version.Number
+	for v := range discoveryDeserializationFuncs {
+		if v.Compare(deserialisationVersion) > 0 && v.Compare(controllerVersion) <= 0 {
+			deserialisationVersion = v
+		}
+	}
+	if deserialisationVersion == version.Zero {
+		return nil, NewUnsupportedVersionError("no discovery read func for version %s", controllerVersion)
+	}
+	return discoveryDeserializationFuncs[deserialisationVersion], nil
+}
+
+// readDiscoveryList expects the values of the sourceList to be string maps.
+func readDiscoveryList(sourceList []interface{}, readFunc discoveryDeserializationFunc) ([]*discovery, error) {
+	result := make([]*discovery, 0, len(sourceList))
+	for i, value := range sourceList {
+		source, ok := value.(map[string]interface{})
+		if !ok {
+			return nil, NewDeserializationError("unexpected value for discovery %d, %T", i, value)
+		}
+		discovery, err := readFunc(source)
+		if err != nil {
+			return nil, errors.Annotatef(err, "discovery %d", i)
+		}
+		result = append(result, discovery)
+	}
+	return result, nil
+}
+
+type discoveryDeserializationFunc func(map[string]interface{}) (*discovery, error)
+
+var discoveryDeserializationFuncs = map[version.Number]discoveryDeserializationFunc{
+	twoDotOh: discovery_2_0,
+}
+
+func discovery_2_0(source map[string]interface{}) (*discovery, error) {
+	fields := schema.Fields{
+		"discovery_id":     schema.String(),
+		"ip":               schema.OneOf(schema.Nil(""), schema.String()),
+		"mac_address":      schema.OneOf(schema.Nil(""), schema.String()),
+		"mac_organization": schema.OneOf(schema.Nil(""), schema.String()),
+		"hostname":         schema.OneOf(schema.Nil(""), schema.String()),
+		"fabric_name":      schema.OneOf(schema.Nil(""), schema.String()),
+		"vid":              schema.OneOf(schema.Nil(""), schema.ForceInt()),
+		"observer": schema.OneOf(schema.Nil(""), schema.FieldMap(
+			schema.Fields{
+				"system_id": schema.String(),
+				"hostname":  schema.String(),
+			},
+			schema.Defaults{"hostname": ""},
+		)),
+		"last_seen": schema.String(),
+	}
+	defaults := schema.Defaults{
+		"ip":               nil,
+		"mac_address":      nil,
+		"mac_organization": nil,
+		"hostname":         nil,
+		"fabric_name":      nil,
+		"vid":              nil,
+		"observer":         nil,
+	}
+	checker := schema.FieldMap(fields, defaults)
+	coerced, err := checker.Coerce(source, nil)
+	if err != nil {
+		return nil, WrapWithDeserializationError(err, "discovery 2.0 schema check failed")
+	}
+	valid := coerced.(map[string]interface{})
+	// From here we know that the map returned from the schema coercion
+	// contains fields of the right type.
+
+	lastSeen, err := parseDiscoveryTime(valid["last_seen"].(string))
+	if err != nil {
+		return nil, errors.Trace(err)
+	}
+	var observer, observerName string
+	if observerMap, ok := valid["observer"].(map[string]interface{}); ok {
+		observer = observerMap["system_id"].(string)
+		observerName = observerMap["hostname"].(string)
+	}
+	ip, _ := valid["ip"].(string)
+	macAddress, _ := valid["mac_address"].(string)
+	macOrganization, _ := valid["mac_organization"].(string)
+	hostname, _ := valid["hostname"].(string)
+	fabricName, _ := valid["fabric_name"].(string)
+	vid, _ := valid["vid"].(int)
+	result := &discovery{
+		discoveryID:     valid["discovery_id"].(string),
+		ip:              ip,
+		macAddress:      macAddress,
+		macOrganization: macOrganization,
+		hostname:        hostname,
+		fabricName:      fabricName,
+		vid:             vid,
+		observer:        observer,
+		observerName:    observerName,
+		lastSeen:        lastSeen,
+	}
+	return result, nil
+}
+
+// parseDiscoveryTime parses a time reported by MAAS. Times without a zone
+// are in UTC.
+func parseDiscoveryTime(value string) (time.Time, error) {
+	for _, format := range discoveryTimeFormats {
+		if t, err := time.Parse(format, value); err == nil {
+			return t.UTC(), nil
+		}
+	}
+	return time.Time{}, NewDeserializationError("unexpected time %q", value)
+}
@@ -0,0 +1,184 @@
+// Copyright 2022 Canonical Ltd.
+// Licensed under the LGPLv3, see LICENCE file for details.
+
+package gomaasapi
+
+import (
+	"net/http"
+	"time"
+
+	"github.com/juju/errors"
+	"github.com/juju/testing"
+	jc "github.com/juju/testing/checkers"
+	"github.com/juju/version"
+	gc "gopkg.in/check.v1"
+)
+
+type discoverySuite struct {
+	testing.LoggingCleanupSuite
+}
+
+var _ = gc.Suite(&discoverySuite{})
+
+func (*discoverySuite) TestReadDiscoveriesBadSchema(c *gc.C) {
+	_, err := readDiscoveries(twoDotOh, "wat?")
+	c.Check(err, jc.Satisfies, IsDeserializationError)
+	c.Assert(err.Error(), gc.Equals, `discovery base schema check failed: expected list, got string("wat?")`)
+}
+
+func (*discoverySuite) TestReadDiscoveries(c *gc.C) {
+	discoveries, err := readDiscoveries(twoDotOh, parseJSON(c, discoveriesResponse))
+	c.Assert(err, jc.ErrorIsNil)
+	c.Assert(discoveries, gc.HasLen, 2)
+
+	d := discoveries[0]
+	c.Check(d.ID(), gc.Equals, "MTkyLjE2OC4xMDAuNTAsNTI6NTQ6MDA6MTI6MzQ6NTY=")
+	c.Check(d.IP(), gc.Equals, "192.168.100.50")
+	c.Check(d.MACAddress(), gc.Equals, "52:54:00:12:34:56")
+	c.Check(d.MACOrganization(), gc.Equals, "QEMU virtual NIC")
+	c.Check(d.Hostname(), gc.Equals, "printer")
+	c.Check(d.FabricName(), gc.Equals, "fabric-0")
+	c.Check(d.VID(), gc.Equals, 0)
+	c.Check(d.Observer(), gc.Equals, "4y3h7n")
+	c.Check(d.ObserverHostname(), gc.Equals, "rack-1")
+	c.Check(d.LastSeen(), gc.Equals, time.Date(2022, 3, 1, 10, 20, 30, 563000000, time.UTC))
+
+	d = discoveries[1]
+	c.Check(d.Hostname(), gc.Equals, "")
+	c.Check(d.MACOrganization(), gc.Equals, "")
+	c.Check(d.VID(), gc.Equals, 100)
+	c.Check(d.LastSeen(), gc.Equals, time.Date(2022, 3, 1, 9, 0, 0, 0, time.UTC))
+}
+
+func (*discoverySuite) TestReadDiscoveriesBadTime(c *gc.C) {
+	source := parseJSON(c, discoveriesResponse).([]interface{})
+	source[0].(map[string]interface{})["last_seen"] = "yesterday"
+	_, err := readDiscoveries(twoDotOh, source)
+	c.Check(err, jc.Satisfies, IsDeserializationError)
+	c.Check(err.Error(), gc.Equals, `discovery 0: unexpected time "yesterday"`)
+}
+
+func (*discoverySuite) TestLowVersion(c *gc.C) {
+	_, err := readDiscoveries(version.MustParse("1.9.0"), parseJSON(c, discoveriesResponse))
+	c.Assert(err, jc.Satisfies, IsUnsupportedVersionError)
+}
+
+func (*discoverySuite) TestHighVersion(c *gc.C) {
+	discoveries, err := readDiscoveries(version.MustParse("2.1.9"), parseJSON(c, discoveriesResponse))
+	c.Assert(err, jc.ErrorIsNil)
+	c.Assert(discoveries, gc.HasLen, 2)
+}
+
+func (s *discoverySuite) TestDiscoveries(c *gc.C) {
+	server, controller := createTestServerController(c, s)
+	server.AddGetResponse("/api/2.0/discovery/", http.StatusOK, discoveriesResponse)
+	discoveries, err := controller.Discoveries()
+	c.Assert(err, jc.ErrorIsNil)
+	c.Check(discoveries, gc.HasLen, 2)
+}
+
+func (s *discoverySuite) TestDiscoveriesServerError(c *gc.C) {
+	server, controller := createTestServerController(c, s)
+	server.AddGetResponse("/api/2.0/discovery/", http.StatusInternalServerError, "boom")
+	_, err := controller.Discoveries()
+	c.Assert(err, jc.Satisfies, IsUnexpectedError)
+}
+
+func (s *discoverySuite) TestClearDiscoveries(c *gc.C) {
+	server, controller := createTestServerController(c, s)
+	server.AddPostResponse("/api/2.0/discovery/?op=clear", http.StatusNoContent, "")
+	err := controller.ClearDiscoveries(ClearDiscoveriesArgs{Neighbours: true, MDNS: true})
+	c.Assert(err, jc.ErrorIsNil)
+
+	form := server.LastRequest().PostForm
+	c.Check(form, gc.HasLen, 2)
+	c.Check(form.Get("neighbours"), gc.Equals, "true")
+	c.Check(form.Get("mdns"), gc.Equals, "true")
+}
+
+func (s *discoverySuite) TestClearDiscoveriesInvalid(c *gc.C) {
+	server, controller := createTestServerController(c, s)
+	server.ResetRequests()
+	err := controller.ClearDiscoveries(ClearDiscoveriesArgs{})
+	c.Assert(err, jc.Satisfies, errors.IsNotValid)
+	c.Check(err.Error(), gc.Equals, "missing MDNS, Neighbours or All not valid")
+	c.Check(server.RequestCount(), gc.Equals, 0)
+}
+
+func (s *discoverySuite) TestClearDiscoveriesForbidden(c *gc.C) {
+	server, controller := createTestServerController(c, s)
+	server.AddPostResponse("/api/2.0/discovery/?op=clear", http.StatusForbidden, "admins only")
+	err := controller.ClearDiscoveries(ClearDiscoveriesArgs{All: true})
+	c.Assert(err, jc.Satisfies, IsPermissionError)
+}
+
+func (s *discoverySuite) TestScanNetworks(c *gc.C) {
+	server, controller := createTestServerController(c, s)
+	server.AddPostResponse("/api/2.0/discovery/?op=scan", http.StatusOK, scanResultResponse)
+	result, err := controller.ScanNetworks(ScanNetworksArgs{
+		CIDRs: []string{"192.168.100.0/24", "10.0.0.0/8"},
+		Slow:  true,
+	})
+	c.Assert(err, jc.ErrorIsNil)
+	c.Check(result.StartedOn(), jc.DeepEquals, []string{"4y3h7n"})
+
+	form := server.LastRequest().PostForm
+	c.Check(form, gc.HasLen, 2)
+	c.Check(form["cidr"], jc.DeepEquals, []string{"192.168.100.0/24", "10.0.0.0/8"})
+	c.Check(form.Get("slow"), gc.Equals, "true")
+}
+
+func (s *discoverySuite) TestScanNetworksAll(c *gc.C) {
+	server, controller := createTestServerController(c, s)
+	server.AddPostResponse("/api/2.0/discovery/?op=scan", http.StatusOK, scanResultResponse)
+	_, err := controller.ScanNetworks(ScanNetworksArgs{Force: true})
+	c.Assert(err, jc.ErrorIsNil)
+
+	form := server.LastRequest().PostForm
+	c.Check(form, gc.HasLen, 1)
+	c.Check(form.Get("force"), gc.Equals, "true")
+}
+
+func (s *discoverySuite) TestScanNetworksInvalid(c *gc.C) {
+	server, controller := createTestServerController(c, s)
+	server.ResetRequests()
+	_, err := controller.ScanNetworks(ScanNetworksArgs{CIDRs: []string{"192.168.100.0"}})
+	c.Assert(err, jc.Satisfies, errors.IsNotValid)
+	c.Check(err.Error(), gc.Equals, `CIDR "192.168.100.0" not valid`)
+	c.Check(server.RequestCount(), gc.Equals, 0)
+}
+
+const discoveriesResponse = `
+[
+    {
+        "discovery_id": "MTkyLjE2OC4xMDAuNTAsNTI6NTQ6MDA6MTI6MzQ6NTY=",
+        "ip": "192.168.100.50",
+        "mac_address": "52:54:00:12:34:56",
+        "mac_organization": "QEMU virtual NIC",
+        "hostname": "printer",
+        "fabric_name": "fabric-0",
+        "vid": 0,
+        "observer": {
+            "system_id": "4y3h7n",
+            "hostname": "rack-1",
+            "interface_id": 1,
+            "interface_name": "eth0"
+        },
+        "last_seen": "2022-03-01T10:20:30.563"
+    },
+    {
+        "discovery_id": "MTAuMC4wLjcsMDA6MTY6M2U6MDE6MDI6MDM=",
+        "ip": "10.0.0.7",
+        "mac_address": "00:16:3e:01:02:03",
+        "mac_organization": null,
+        "hostname": null,
+        "fabric_name": "fabric-1",
+        "vid": 100,
+        "observer": {
+            "system_id": "4y3h7n",
+            "hostname": "rack-1"
+        },
+        "last_seen": "2022-03-01T10:00:00+01:00"
+    }
+]
+`
@@ -0,0 +1,256 @@
+// Copyright 2022 Canonical Ltd.
+// Licensed under the LGPLv3, see LICENCE file for details.
+
+package gomaasapi
+
+import (
+	"fmt"
+	"net/http"
+	"strings"
+
+	"github.com/juju/errors"
+	"github.com/juju/schema"
+	"github.com/juju/version"
+)
+
+type dnsResource struct {
+	controller *controller
+
+	resourceURI string
+
+	id         int
+	fqdn       string
+	addressTTL *int
+
+	ipAddresses     []string
+	resourceRecords []*dnsResourceRecord
+}
+
+func (d *dnsResource) updateFrom(other *dnsResource) {
+	d.resourceURI = other.resourceURI
+	d.id = other.id
+	d.fqdn = other.fqdn
+	d.addressTTL = other.addressTTL
+	d.ipAddresses = other.ipAddresses
+	d.resourceRecords = other.resourceRecords
+}
+
+// ID implements DNSResource.
+func (d *dnsResource) ID() int {
+	return d.id
+}
+
+// FQDN implements DNSResource.
+func (d *dnsResource) FQDN() string {
+	return d.fqdn
+}
+
+// AddressTTL implements DNSResource.
+func (d *dnsResource) AddressTTL() *int {
+	return d.addressTTL
+}
+
+// IPAddresses implements DNSResource.
+func (d *dnsResource) IPAddresses() []string {
+	return d.ipAddresses
+}
+
+// ResourceRecords implements DNSResource.
+func (d *dnsResource) ResourceRecords() []DNSResourceRecord {
+	result := make([]DNSResourceRecord, len(d.resourceRecords))
+	for i, record := range d.resourceRecords {
+		record.controller = d.controller
+		result[i] = record
+	}
+	return result
+}
+
+// UpdateDNSResourceArgs is an argument struct for DNSResource.Update. Only
+// the fields that are set are changed.
+type UpdateDNSResourceArgs struct {
+	FQDN       string
+	AddressTTL *int
+	// IPAddresses replaces the addresses the name resolves to. MAAS
+	// reserves the addresses that aren't already known to it.
+	IPAddresses []string
+}
+
+// Update implements DNSResource.
+func (d *dnsResource) Update(args UpdateDNSResourceArgs) error {
+	params := NewURLParams()
+	params.MaybeAdd("fqdn", args.FQDN)
+	if args.AddressTTL != nil {
+		params.Values.Add("address_ttl", fmt.Sprint(*args.AddressTTL))
+	}
+	params.MaybeAdd("ip_addresses", strings.Join(args.IPAddresses, " "))
+	source, err := d.controller.put(d.resourceURI, params.Values)
+	if err != nil {
+		if svrErr, ok := errors.Cause(err).(ServerError); ok {
+			switch svrErr.StatusCode {
+			case http.StatusNotFound:
+				return wrapError(err, NewNoMatchError(svrErr.BodyMessage))
+			case http.StatusForbidden:
+				return wrapError(err, NewPermissionError(svrErr.BodyMessage))
+			case http.StatusBadRequest:
+				return wrapError(err, NewBadRequestError(svrErr.BodyMessage))
+			}
+		}
+		return NewUnexpectedError(err)
+	}
+
+	response, err := readDNSResource(d.controller.schemaVersion(), source)
+	if err != nil {
+		return errors.Trace(err)
+	}
+	d.updateFrom(response)
+	return nil
+}
+
+// Delete implements DNSResource.
+func (d *dnsResource) Delete() error {
+	err := d.controller.delete(d.resourceURI)
+	if err != nil {
+		if svrErr, ok := errors.Cause(err).(ServerError); ok {
+			switch svrErr.StatusCode {
+			case http.StatusNotFound:
+				return wrapError(err, NewNoMatchError(svrErr.BodyMessage))
+			case http.StatusForbidden:
+				return wrapError(err, NewPermissionError(svrErr.BodyMessage))
+			}
+		}
+		return NewUnexpectedError(err)
+	}
+	return nil
+}
+
+func readDNSResource(controllerVersion version.Number, source interface{}) (*dnsResource, error) {
+	readFunc, err := getDNSResourceDeserializationFunc(controllerVersion)
+	if err != nil {
+		return nil, errors.Trace(err)
+	}
+
+	checker := schema.StringMap(schema.Any())
+	coerced, err := checker.Coerce(source, nil)
+	if err != nil {
+		return nil, WrapWithDeserializationError(err, "dnsresource base schema check failed")
+	}
+	valid := coerced.(map[string]interface{})
+	return readFunc(valid)
+}
+
+func readDNSResources(controllerVersion version.Number, source interface{}) ([]*dnsResource, error) {
+	readFunc, err := getDNSResourceDeserializationFunc(controllerVersion)
+	if err != nil {
+		return nil, errors.Trace(err)
+	}
+
+	checker := schema.List(schema.StringMap(schema.Any()))
+	coerced, err := checker.Coerce(source, nil)
+	if err != nil {
+		return nil, WrapWithDeserializationError(err, "dnsresource base schema check failed")
+	}
+	valid := coerced.([]interface{})
+	return readDNSResourceList(valid, readFunc)
+}
+
+func getDNSResourceDeserializationFunc(controllerVersion version.Number) (dnsResourceDeserializationFunc, error) {
+	var deserialisationVersion version.Number
+	for v := range dnsResourceDeserializationFuncs {
+		if v.Compare(deserialisationVersion) > 0 && v.Compare(controllerVersion) <= 0 {
+			deserialisationVersion = v
+		}
+	}
+	if deserialisationVersion == version.Zero {
+		return nil, NewUnsupportedVersionError("no dnsresource read func for version %s", controllerVersion)
+	}
+	return dnsResourceDeserializationFuncs[deserialisationVersion], nil
+}
+
+// readDNSResourceList expects the values of the sourceList to be string maps.
+func readDNSResourceList(sourceList []interface{}, readFunc dnsResourceDeserializationFunc) ([]*dnsResource, error) {
+	result := make([]*dnsResource, 0, len(sourceList))
+	for i, value := range sourceList {
+		source, ok := value.(map[string]interface{})
+		if !ok {
+			return nil, NewDeserializationError("unexpected value for dnsresource %d, %T", i, value)
+		}
+		dnsResource, err := readFunc(source)
+		if err != nil {
+			return nil, errors.Annotatef(err, "dnsresource %d", i)
+		}
+		result = append(result, dnsResource)
+	}
+	return result, nil
+}
+
+type dnsResourceDeserializationFunc func(map[string]interface{}) (*dnsResource, error)
+
+var dnsResourceDeserializationFuncs = map[version.Number]dnsResourceDeserializationFunc{
+	twoDotOh: dnsResource_2_0,
+}
+
+func dnsResource_2_0(source map[string]interface{}) (*dnsResource, error) {
+	fields := schema.Fields{
+		"resource_uri": schema.String(),
+
+		"id":          schema.ForceInt(),
+		"fqdn":        schema.String(),
+		"address_ttl": schema.OneOf(schema.Nil(""), schema.ForceInt()),
+
+		// The addresses are full IP address objects, only the address
+		// itself is of interest.
+		"ip_addresses": schema.List(schema.FieldMap(
+			schema.Fields{"ip": schema.OneOf(schema.Nil(""), schema.String())},
+			schema.Defaults{"ip": ""},
+		)),
+		"resource_records": schema.List(schema.StringMap(schema.Any())),
+	}
+	defaults := schema.Defaults{
+		"address_ttl":      nil,
+		"ip_addresses":     []interface{}{},
+		"resource_records": []interface{}{},
+	}
+	checker := schema.FieldMap(fields, defaults)
+	coerced, err := checker.Coerce(source, nil)
+	if err != nil {
+		return nil, WrapWithDeserializationError(err, "dnsresource 2.0 schema check failed")
+	}
+	valid := coerced.(map[string]interface{})
+	// From here we know that the map returned from the schema coercion
+	// contains fields of the right type.
+
+	var addressTTL *int
+	if ttl, ok := valid["address_ttl"].(int); ok {
+		addressTTL = &ttl
+	}
+	var ipAddresses []string
+	for _, value := range valid["ip_addresses"].([]interface{}) {
+		if ip, _ := value.(map[string]interface{})["ip"].(string); ip != "" {
+			ipAddresses = append(ipAddresses, ip)
+		}
+	}
+	// The records of the resource are reported without a name, they share
+	// the name of the resource.
+	resourceRecords := make([]*dnsResourceRecord, 0)
+	for i, value := range valid["resource_records"].([]interface{}) {
+		record, err := dnsResourceRecord_2_0(value.(map[string]interface{}))
+		if err != nil {
+			return nil, errors.Annotatef(err, "resource record %d", i)
+		}
+		if record.fqdn == "" {
+			record.fqdn = valid["fqdn"].(string)
+		}
+		resourceRecords = append(resourceRecords, record)
+	}
+	result := &dnsResource{
+		resourceURI: valid["resource_uri"].(string),
+
+		id:         valid["id"].(int),
+		fqdn:       valid["fqdn"].(string),
+		addressTTL: addressTTL,
+
+		ipAddresses:     ipAddresses,
+		resourceRecords: resourceRecords,
+	}
+	return result, nil
+}
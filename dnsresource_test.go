@@ -0,0 +1,228 @@
+// Copyright 2022 Canonical Ltd.
+// Licensed under the LGPLv3, see LICENCE file for details.
+
+package gomaasapi
+
+import (
+	"net/http"
+
+	"github.com/juju/errors"
+	"github.com/juju/testing"
+	jc "github.com/juju/testing/checkers"
+	"github.com/juju/version"
+	gc "gopkg.in/check.v1"
+)
+
+type dnsResourceSuite struct {
+	testing.LoggingCleanupSuite
+}
+
+var _ = gc.Suite(&dnsResourceSuite{})
+
+func (*dnsResourceSuite) TestReadDNSResourcesBadSchema(c *gc.C) {
+	_, err := readDNSResources(twoDotOh, "wat?")
+	c.Check(err, jc.Satisfies, IsDeserializationError)
+	c.Assert(err.Error(), gc.Equals, `dnsresource base schema check failed: expected list, got string("wat?")`)
+}
+
+func (*dnsResourceSuite) TestReadDNSResources(c *gc.C) {
+	dnsResources, err := readDNSResources(twoDotOh, parseJSON(c, dnsResourcesResponse))
+	c.Assert(err, jc.ErrorIsNil)
+	c.Assert(dnsResources, gc.HasLen, 2)
+
+	dnsResource := dnsResources[0]
+	c.Check(dnsResource.ID(), gc.Equals, 1)
+	c.Check(dnsResource.FQDN(), gc.Equals, "www.maas")
+	c.Check(dnsResource.AddressTTL(), gc.IsNil)
+	c.Check(dnsResource.IPAddresses(), jc.DeepEquals, []string{"192.168.100.4"})
+
+	dnsResource = dnsResources[1]
+	c.Assert(dnsResource.AddressTTL(), gc.NotNil)
+	c.Check(*dnsResource.AddressTTL(), gc.Equals, 300)
+	c.Check(dnsResource.IPAddresses(), jc.DeepEquals, []string{"10.0.0.1", "10.0.0.2"})
+	records := dnsResource.ResourceRecords()
+	c.Assert(records, gc.HasLen, 1)
+	c.Check(records[0].ID(), gc.Equals, 7)
+	c.Check(records[0].FQDN(), gc.Equals, "other.maas")
+	c.Check(records[0].Type(), gc.Equals, "TXT")
+	c.Check(records[0].Data(), gc.Equals, "owner=external-dns")
+	c.Check(records[0].TTL(), gc.IsNil)
+}
+
+func (s *dnsResourceSuite) getServerAndDNSResource(c *gc.C) (*SimpleTestServer, DNSResource) {
+	server, controller := createTestServerController(c, s)
+	server.AddGetResponse("/api/2.0/dnsresources/", http.StatusOK, dnsResourcesResponse)
+	dnsResources, err := controller.DNSResources()
+	c.Assert(err, jc.ErrorIsNil)
+	server.ResetRequests()
+	return server, dnsResources[1]
+}
+
+func (s *dnsResourceSuite) TestCreateDNSResource(c *gc.C) {
+	server, controller := createTestServerController(c, s)
+	server.AddPostResponse("/api/2.0/dnsresources/?op=", http.StatusOK, dnsResourceResponse)
+	ttl := 60
+	dnsResource, err := controller.CreateDNSResource(CreateDNSResourceArgs{
+		Name:        "www",
+		Domain:      "maas",
+		AddressTTL:  &ttl,
+		IPAddresses: []string{"192.168.100.4", "2001:db8::4"},
+	})
+	c.Assert(err, jc.ErrorIsNil)
+	c.Check(dnsResource.FQDN(), gc.Equals, "www.maas")
+
+	form := server.LastRequest().PostForm
+	c.Check(form, gc.HasLen, 4)
+	c.Check(form.Get("name"), gc.Equals, "www")
+	c.Check(form.Get("domain"), gc.Equals, "maas")
+	c.Check(form.Get("address_ttl"), gc.Equals, "60")
+	c.Check(form.Get("ip_addresses"), gc.Equals, "192.168.100.4 2001:db8::4")
+}
+
+func (s *dnsResourceSuite) TestCreateDNSResourceBadRequest(c *gc.C) {
+	server, controller := createTestServerController(c, s)
+	server.AddPostResponse("/api/2.0/dnsresources/?op=", http.StatusBadRequest, "name already in use")
+	_, err := controller.CreateDNSResource(CreateDNSResourceArgs{FQDN: "www.maas"})
+	c.Assert(err, jc.Satisfies, IsBadRequestError)
+	c.Check(err.Error(), gc.Equals, "name already in use")
+}
+
+func (*dnsResourceSuite) TestCreateDNSResourceArgsValidate(c *gc.C) {
+	for i, test := range []struct {
+		args    CreateDNSResourceArgs
+		errText string
+	}{{
+		args: CreateDNSResourceArgs{FQDN: "www.maas", IPAddresses: []string{"10.0.0.1"}},
+	}, {
+		args: CreateDNSResourceArgs{Name: "www"},
+	}, {
+		args:    CreateDNSResourceArgs{Domain: "maas"},
+		errText: "missing FQDN or Name not valid",
+	}, {
+		args:    CreateDNSResourceArgs{FQDN: "www.maas", Domain: "maas"},
+		errText: "both FQDN and Name or Domain not valid",
+	}, {
+		args:    CreateDNSResourceArgs{FQDN: "www.maas", IPAddresses: []string{"www"}},
+		errText: `IP address "www" not valid`,
+	}} {
+		c.Logf("test %d", i)
+		err := test.args.Validate()
+		if test.errText == "" {
+			c.Check(err, jc.ErrorIsNil)
+		} else {
+			c.Check(err, jc.Satisfies, errors.IsNotValid)
+			c.Check(err.Error(), gc.Equals, test.errText)
+		}
+	}
+}
+
+func (s *dnsResourceSuite) TestUpdate(c *gc.C) {
+	server, dnsResource := s.getServerAndDNSResource(c)
+	server.AddPutResponse("/MAAS/api/2.0/dnsresources/2/", http.StatusOK, dnsResourceResponse)
+	ttl := 0
+	err := dnsResource.Update(UpdateDNSResourceArgs{
+		AddressTTL:  &ttl,
+		IPAddresses: []string{"192.168.100.4"},
+	})
+	c.Assert(err, jc.ErrorIsNil)
+	c.Check(dnsResource.FQDN(), gc.Equals, "www.maas")
+	c.Check(dnsResource.IPAddresses(), jc.DeepEquals, []string{"192.168.100.4"})
+	c.Check(dnsResource.ResourceRecords(), gc.HasLen, 0)
+
+	form := server.LastRequest().PostForm
+	c.Check(form, gc.HasLen, 2)
+	c.Check(form.Get("address_ttl"), gc.Equals, "0")
+	c.Check(form.Get("ip_addresses"), gc.Equals, "192.168.100.4")
+}
+
+func (s *dnsResourceSuite) TestUpdateMissing(c *gc.C) {
+	_, dnsResource := s.getServerAndDNSResource(c)
+	err := dnsResource.Update(UpdateDNSResourceArgs{FQDN: "www.maas"})
+	c.Assert(err, jc.Satisfies, IsNoMatchError)
+}
+
+func (s *dnsResourceSuite) TestDelete(c *gc.C) {
+	server, dnsResource := s.getServerAndDNSResource(c)
+	server.AddDeleteResponse("/MAAS/api/2.0/dnsresources/2/", http.StatusNoContent, "")
+	err := dnsResource.Delete()
+	c.Assert(err, jc.ErrorIsNil)
+}
+
+func (s *dnsResourceSuite) TestDeleteForbidden(c *gc.C) {
+	server, dnsResource := s.getServerAndDNSResource(c)
+	server.AddDeleteResponse("/MAAS/api/2.0/dnsresources/2/", http.StatusForbidden, "")
+	err := dnsResource.Delete()
+	c.Assert(err, jc.Satisfies, IsPermissionError)
+}
+
+func (s *dnsResourceSuite) TestEmbeddedRecordDelete(c *gc.C) {
+	server, dnsResource := s.getServerAndDNSResource(c)
+	// The embedded record has no resource URI, so it is derived from the
+	// ID of the record.
+	server.AddDeleteResponse("/api/2.0/dnsresourcerecords/7/", http.StatusNoContent, "")
+	err := dnsResource.ResourceRecords()[0].Delete()
+	c.Assert(err, jc.ErrorIsNil)
+}
+
+func (*dnsResourceSuite) TestLowVersion(c *gc.C) {
+	_, err := readDNSResources(version.MustParse("1.9.0"), parseJSON(c, dnsResourcesResponse))
+	c.Assert(err, jc.Satisfies, IsUnsupportedVersionError)
+}
+
+func (*dnsResourceSuite) TestHighVersion(c *gc.C) {
+	dnsResources, err := readDNSResources(version.MustParse("2.1.9"), parseJSON(c, dnsResourcesResponse))
+	c.Assert(err, jc.ErrorIsNil)
+	c.Assert(dnsResources, gc.HasLen, 2)
+}
+
+const dnsResourcesResponse = `
+[
+    {
+        "id": 1,
+        "fqdn": "www.maas",
+        "address_ttl": null,
+        "ip_addresses": [
+            {
+                "alloc_type": 4,
+                "alloc_type_name": "User reserved",
+                "created": "2022-03-01T10:20:30.000",
+                "ip": "192.168.100.4"
+            }
+        ],
+        "resource_records": [],
+        "resource_uri": "/MAAS/api/2.0/dnsresources/1/"
+    },
+    {
+        "id": 2,
+        "fqdn": "other.maas",
+        "address_ttl": 300,
+        "ip_addresses": [
+            {"ip": "10.0.0.1"},
+            {"ip": "10.0.0.2"},
+            {"ip": null}
+        ],
+        "resource_records": [
+            {
+                "id": 7,
+                "rrtype": "TXT",
+                "rrdata": "owner=external-dns",
+                "ttl": null
+            }
+        ],
+        "resource_uri": "/MAAS/api/2.0/dnsresources/2/"
+    }
+]
+`
+
+const dnsResourceResponse = `
+{
+    "id": 2,
+    "fqdn": "www.maas",
+    "address_ttl": 60,
+    "ip_addresses": [
+        {"ip": "192.168.100.4"}
+    ],
+    "resource_records": [],
+    "resource_uri": "/MAAS/api/2.0/dnsresources/2/"
+}
+`
@@ -0,0 +1,244 @@
+// Copyright 2022 Canonical Ltd.
+// Licensed under the LGPLv3, see LICENCE file for details.
+
+package gomaasapi
+
+import (
+	"fmt"
+	"net/http"
+
+	"github.com/juju/errors"
+	"github.com/juju/schema"
+	"github.com/juju/version"
+)
+
+// DNSRecordType is the type of a DNS resource record.
+type DNSRecordType string
+
+// The types of the records that are managed as DNS resource records. The
+// address records of a name, A and AAAA, are the IP addresses of its
+// DNSResource instead.
+const (
+	DNSRecordTypeCNAME DNSRecordType = "CNAME"
+	DNSRecordTypeMX    DNSRecordType = "MX"
+	DNSRecordTypeNS    DNSRecordType = "NS"
+	DNSRecordTypeSRV   DNSRecordType = "SRV"
+	DNSRecordTypeSSHFP DNSRecordType = "SSHFP"
+	DNSRecordTypeTXT   DNSRecordType = "TXT"
+)
+
+type dnsResourceRecord struct {
+	controller *controller
+
+	resourceURI string
+
+	id     int
+	fqdn   string
+	rrType string
+	rrData string
+	ttl    *int
+}
+
+func (r *dnsResourceRecord) updateFrom(other *dnsResourceRecord) {
+	r.resourceURI = other.resourceURI
+	r.id = other.id
+	r.fqdn = other.fqdn
+	r.rrType = other.rrType
+	r.rrData = other.rrData
+	r.ttl = other.ttl
+}
+
+// ID implements DNSResourceRecord.
+func (r *dnsResourceRecord) ID() int {
+	return r.id
+}
+
+// FQDN implements DNSResourceRecord.
+func (r *dnsResourceRecord) FQDN() string {
+	return r.fqdn
+}
+
+// Type implements DNSResourceRecord.
+func (r *dnsResourceRecord) Type() string {
+	return r.rrType
+}
+
+// Data implements DNSResourceRecord.
+func (r *dnsResourceRecord) Data() string {
+	return r.rrData
+}
+
+// TTL implements DNSResourceRecord.
+func (r *dnsResourceRecord) TTL() *int {
+	return r.ttl
+}
+
+// uri returns the resource URI of the record. The records embedded in a DNS
+// resource aren't always reported with one.
+func (r *dnsResourceRecord) uri() string {
+	if r.resourceURI != "" {
+		return r.resourceURI
+	}
+	return fmt.Sprintf("dnsresourcerecords/%d/", r.id)
+}
+
+// UpdateDNSResourceRecordArgs is an argument struct for
+// DNSResourceRecord.Update. Only the fields that are set are changed.
+type UpdateDNSResourceRecordArgs struct {
+	Data string
+	TTL  *int
+}
+
+// Update implements DNSResourceRecord.
+func (r *dnsResourceRecord) Update(args UpdateDNSResourceRecordArgs) error {
+	params := NewURLParams()
+	params.MaybeAdd("rrdata", args.Data)
+	if args.TTL != nil {
+		params.Values.Add("ttl", fmt.Sprint(*args.TTL))
+	}
+	source, err := r.controller.put(r.uri(), params.Values)
+	if err != nil {
+		if svrErr, ok := errors.Cause(err).(ServerError); ok {
+			switch svrErr.StatusCode {
+			case http.StatusNotFound:
+				return wrapError(err, NewNoMatchError(svrErr.BodyMessage))
+			case http.StatusForbidden:
+				return wrapError(err, NewPermissionError(svrErr.BodyMessage))
+			case http.StatusBadRequest:
+				return wrapError(err, NewBadRequestError(svrErr.BodyMessage))
+			}
+		}
+		return NewUnexpectedError(err)
+	}
+
+	response, err := readDNSResourceRecord(r.controller.schemaVersion(), source)
+	if err != nil {
+		return errors.Trace(err)
+	}
+	r.updateFrom(response)
+	return nil
+}
+
+// Delete implements DNSResourceRecord.
+func (r *dnsResourceRecord) Delete() error {
+	err := r.controller.delete(r.uri())
+	if err != nil {
+		if svrErr, ok := errors.Cause(err).(ServerError); ok {
+			switch svrErr.StatusCode {
+			case http.StatusNotFound:
+				return wrapError(err, NewNoMatchError(svrErr.BodyMessage))
+			case http.StatusForbidden:
+				return wrapError(err, NewPermissionError(svrErr.BodyMessage))
+			}
+		}
+		return NewUnexpectedError(err)
+	}
+	return nil
+}
+
+func readDNSResourceRecord(controllerVersion version.Number, source interface{}) (*dnsResourceRecord, error) {
+	readFunc, err := getDNSResourceRecordDeserializationFunc(controllerVersion)
+	if err != nil {
+		return nil, errors.Trace(err)
+	}
+
+	checker := schema.StringMap(schema.Any())
+	coerced, err := checker.Coerce(source, nil)
+	if err != nil {
+		return nil, WrapWithDeserializationError(err, "dnsresourcerecord base schema check failed")
+	}
+	valid := coerced.(map[string]interface{})
+	return readFunc(valid)
+}
+
+func readDNSResourceRecords(controllerVersion version.Number, source interface{}) ([]*dnsResourceRecord, error) {
+	readFunc, err := getDNSResourceRecordDeserializationFunc(controllerVersion)
+	if err != nil {
+		return nil, errors.Trace(err)
+	}
+
+	checker := schema.List(schema.StringMap(schema.Any()))
+	coerced, err := checker.Coerce(source, nil)
+	if err != nil {
+		return nil, WrapWithDeserializationError(err, "dnsresourcerecord base schema check failed")
+	}
+	valid := coerced.([]interface{})
+	return readDNSResourceRecordList(valid, readFunc)
+}
+
+func getDNSResourceRecordDeserializationFunc(controllerVersion version.Number) (dnsResourceRecordDeserializationFunc, error) {
+	var deserialisationVersion version.Number
+	for v := range dnsResourceRecordDeserializationFuncs {
+		if v.Compare(deserialisationVersion) > 0 && v.Compare(controllerVersion) <= 0 {
+			deserialisationVersion = v
+		}
+	}
+	if deserialisationVersion == version.Zero {
+		return nil, NewUnsupportedVersionError("no dnsresourcerecord read func for version %s", controllerVersion)
+	}
+	return dnsResourceRecordDeserializationFuncs[deserialisationVersion], nil
+}
+
+// readDNSResourceRecordList expects the values of the sourceList to be
+// string maps.
+func readDNSResourceRecordList(sourceList []interface{}, readFunc dnsResourceRecordDeserializationFunc) ([]*dnsResourceRecord, error) {
+	result := make([]*dnsResourceRecord, 0, len(sourceList))
+	for i, value := range sourceList {
+		source, ok := value.(map[string]interface{})
+		if !ok {
+			return nil, NewDeserializationError("unexpected value for dnsresourcerecord %d, %T", i, value)
+		}
+		record, err := readFunc(source)
+		if err != nil {
+			return nil, errors.Annotatef(err, "dnsresourcerecord %d", i)
+		}
+		result = append(result, record)
+	}
+	return result, nil
+}
+
+type dnsResourceRecordDeserializationFunc func(map[string]interface{}) (*dnsResourceRecord, error)
+
+var dnsResourceRecordDeserializationFuncs = map[version.Number]dnsResourceRecordDeserializationFunc{
+	twoDotOh: dnsResourceRecord_2_0,
+}
+
+func dnsResourceRecord_2_0(source map[string]interface{}) (*dnsResourceRecord, error) {
+	fields := schema.Fields{
+		"resource_uri": schema.String(),
+
+		"id":     schema.ForceInt(),
+		"fqdn":   schema.String(),
+		"rrtype": schema.String(),
+		"rrdata": schema.String(),
+		"ttl":    schema.OneOf(schema.Nil(""), schema.ForceInt()),
+	}
+	defaults := schema.Defaults{
+		"resource_uri": "",
+		"fqdn":         "",
+		"ttl":          nil,
+	}
+	checker := schema.FieldMap(fields, defaults)
+	coerced, err := checker.Coerce(source, nil)
+	if err != nil {
+		return nil, WrapWithDeserializationError(err, "dnsresourcerecord 2.0 schema check failed")
+	}
+	valid := coerced.(map[string]interface{})
+	// From here we know that the map returned from the schema coercion
+	// contains fields of the right type.
+
+	var ttl *int
+	if value, ok := valid["ttl"].(int); ok {
+		ttl = &value
+	}
+	result := &dnsResourceRecord{
+		resourceURI: valid["resource_uri"].(string),
+
+		id:     valid["id"].(int),
+		fqdn:   valid["fqdn"].(string),
+		rrType: valid["rrtype"].(string),
+		rrData: valid["rrdata"].(string),
+		ttl:    ttl,
+	}
+	return result, nil
+}
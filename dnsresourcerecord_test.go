@@ -0,0 +1,204 @@
+// Copyright 2022 Canonical Ltd.
+// Licensed under the LGPLv3, see LICENCE file for details.
+
+package gomaasapi
+
+import (
+	"net/http"
+
+	"github.com/juju/errors"
+	"github.com/juju/testing"
+	jc "github.com/juju/testing/checkers"
+	"github.com/juju/version"
+	gc "gopkg.in/check.v1"
+)
+
+type dnsResourceRecordSuite struct {
+	testing.LoggingCleanupSuite
+}
+
+var _ = gc.Suite(&dnsResourceRecordSuite{})
+
+func (*dnsResourceRecordSuite) TestReadDNSResourceRecordsBadSchema(c *gc.C) {
+	_, err := readDNSResourceRecords(twoDotOh, "wat?")
+	c.Check(err, jc.Satisfies, IsDeserializationError)
+	c.Assert(err.Error(), gc.Equals, `dnsresourcerecord base schema check failed: expected list, got string("wat?")`)
+}
+
+func (*dnsResourceRecordSuite) TestReadDNSResourceRecords(c *gc.C) {
+	records, err := readDNSResourceRecords(twoDotOh, parseJSON(c, dnsResourceRecordsResponse))
+	c.Assert(err, jc.ErrorIsNil)
+	c.Assert(records, gc.HasLen, 2)
+
+	record := records[0]
+	c.Check(record.ID(), gc.Equals, 7)
+	c.Check(record.FQDN(), gc.Equals, "mail.maas")
+	c.Check(record.Type(), gc.Equals, "MX")
+	c.Check(record.Data(), gc.Equals, "10 mx1.example.com")
+	c.Check(record.TTL(), gc.IsNil)
+
+	record = records[1]
+	c.Check(record.Type(), gc.Equals, "SRV")
+	c.Assert(record.TTL(), gc.NotNil)
+	c.Check(*record.TTL(), gc.Equals, 300)
+}
+
+func (*dnsResourceRecordSuite) TestLowVersion(c *gc.C) {
+	_, err := readDNSResourceRecords(version.MustParse("1.9.0"), parseJSON(c, dnsResourceRecordsResponse))
+	c.Assert(err, jc.Satisfies, IsUnsupportedVersionError)
+}
+
+func (*dnsResourceRecordSuite) TestHighVersion(c *gc.C) {
+	records, err := readDNSResourceRecords(version.MustParse("2.1.9"), parseJSON(c, dnsResourceRecordsResponse))
+	c.Assert(err, jc.ErrorIsNil)
+	c.Assert(records, gc.HasLen, 2)
+}
+
+func (s *dnsResourceRecordSuite) getServerAndRecord(c *gc.C) (*SimpleTestServer, DNSResourceRecord) {
+	server, controller := createTestServerController(c, s)
+	server.AddGetResponse("/api/2.0/dnsresourcerecords/", http.StatusOK, dnsResourceRecordsResponse)
+	records, err := controller.DNSResourceRecords(DNSResourceRecordsArgs{})
+	c.Assert(err, jc.ErrorIsNil)
+	c.Assert(records, gc.HasLen, 2)
+	server.ResetRequests()
+	return server, records[0]
+}
+
+func (s *dnsResourceRecordSuite) TestDNSResourceRecordsArgs(c *gc.C) {
+	server, controller := createTestServerController(c, s)
+	server.AddGetResponse("/api/2.0/dnsresourcerecords/?domain=maas&name=mail&rrtype=MX", http.StatusOK, dnsResourceRecordsResponse)
+	records, err := controller.DNSResourceRecords(DNSResourceRecordsArgs{
+		Domain: "maas",
+		Name:   "mail",
+		Type:   DNSRecordTypeMX,
+	})
+	c.Assert(err, jc.ErrorIsNil)
+	c.Check(records, gc.HasLen, 2)
+}
+
+func (s *dnsResourceRecordSuite) TestCreateDNSResourceRecord(c *gc.C) {
+	server, controller := createTestServerController(c, s)
+	server.AddPostResponse("/api/2.0/dnsresourcerecords/?op=", http.StatusOK, dnsResourceRecordResponse)
+	ttl := 300
+	record, err := controller.CreateDNSResourceRecord(CreateDNSResourceRecordArgs{
+		FQDN: "_sip._tcp.maas",
+		Type: DNSRecordTypeSRV,
+		Data: "0 5 5060 sip.example.com",
+		TTL:  &ttl,
+	})
+	c.Assert(err, jc.ErrorIsNil)
+	c.Check(record.Type(), gc.Equals, "SRV")
+
+	form := server.LastRequest().PostForm
+	c.Check(form, gc.HasLen, 4)
+	c.Check(form.Get("fqdn"), gc.Equals, "_sip._tcp.maas")
+	c.Check(form.Get("rrtype"), gc.Equals, "SRV")
+	c.Check(form.Get("rrdata"), gc.Equals, "0 5 5060 sip.example.com")
+	c.Check(form.Get("ttl"), gc.Equals, "300")
+}
+
+func (s *dnsResourceRecordSuite) TestCreateDNSResourceRecordForbidden(c *gc.C) {
+	server, controller := createTestServerController(c, s)
+	server.AddPostResponse("/api/2.0/dnsresourcerecords/?op=", http.StatusForbidden, "admins only")
+	_, err := controller.CreateDNSResourceRecord(CreateDNSResourceRecordArgs{
+		Name: "www",
+		Type: DNSRecordTypeCNAME,
+		Data: "web.example.com",
+	})
+	c.Assert(err, jc.Satisfies, IsPermissionError)
+}
+
+func (*dnsResourceRecordSuite) TestCreateDNSResourceRecordArgsValidate(c *gc.C) {
+	for i, test := range []struct {
+		args    CreateDNSResourceRecordArgs
+		errText string
+	}{{
+		args: CreateDNSResourceRecordArgs{Name: "www", Type: DNSRecordTypeTXT, Data: "hello"},
+	}, {
+		args:    CreateDNSResourceRecordArgs{Type: DNSRecordTypeTXT, Data: "hello"},
+		errText: "missing FQDN or Name not valid",
+	}, {
+		args:    CreateDNSResourceRecordArgs{Name: "www", Data: "hello"},
+		errText: "missing Type not valid",
+	}, {
+		args:    CreateDNSResourceRecordArgs{Name: "www", Type: "aaaa", Data: "2001:db8::1"},
+		errText: `address record Type "aaaa" not valid`,
+	}, {
+		args:    CreateDNSResourceRecordArgs{Name: "www", Type: DNSRecordTypeTXT},
+		errText: "missing Data not valid",
+	}} {
+		c.Logf("test %d", i)
+		err := test.args.Validate()
+		if test.errText == "" {
+			c.Check(err, jc.ErrorIsNil)
+		} else {
+			c.Check(err, jc.Satisfies, errors.IsNotValid)
+			c.Check(err.Error(), gc.Equals, test.errText)
+		}
+	}
+}
+
+func (s *dnsResourceRecordSuite) TestUpdate(c *gc.C) {
+	server, record := s.getServerAndRecord(c)
+	response := updateJSONMap(c, dnsResourceRecordResponse, map[string]interface{}{
+		"id":           7,
+		"fqdn":         "mail.maas",
+		"rrtype":       "MX",
+		"rrdata":       "20 mx2.example.com",
+		"resource_uri": "/MAAS/api/2.0/dnsresourcerecords/7/",
+	})
+	server.AddPutResponse("/MAAS/api/2.0/dnsresourcerecords/7/", http.StatusOK, response)
+	err := record.Update(UpdateDNSResourceRecordArgs{Data: "20 mx2.example.com"})
+	c.Assert(err, jc.ErrorIsNil)
+	c.Check(record.Data(), gc.Equals, "20 mx2.example.com")
+
+	form := server.LastRequest().PostForm
+	c.Check(form, gc.HasLen, 1)
+	c.Check(form.Get("rrdata"), gc.Equals, "20 mx2.example.com")
+}
+
+func (s *dnsResourceRecordSuite) TestUpdateBadRequest(c *gc.C) {
+	server, record := s.getServerAndRecord(c)
+	server.AddPutResponse("/MAAS/api/2.0/dnsresourcerecords/7/", http.StatusBadRequest, "invalid MX data")
+	err := record.Update(UpdateDNSResourceRecordArgs{Data: "mx2"})
+	c.Assert(err, jc.Satisfies, IsBadRequestError)
+	c.Check(record.Data(), gc.Equals, "10 mx1.example.com")
+}
+
+func (s *dnsResourceRecordSuite) TestDelete(c *gc.C) {
+	server, record := s.getServerAndRecord(c)
+	server.AddDeleteResponse("/MAAS/api/2.0/dnsresourcerecords/7/", http.StatusNoContent, "")
+	err := record.Delete()
+	c.Assert(err, jc.ErrorIsNil)
+}
+
+func (s *dnsResourceRecordSuite) TestDeleteMissing(c *gc.C) {
+	_, record := s.getServerAndRecord(c)
+	err := record.Delete()
+	c.Assert(err, jc.Satisfies, IsNoMatchError)
+}
+
+const (
+	dnsResourceRecordResponse = `
+{
+    "id": 8,
+    "fqdn": "_sip._tcp.maas",
+    "rrtype": "SRV",
+    "rrdata": "0 5 5060 sip.example.com",
+    "ttl": 300,
+    "resource_uri": "/MAAS/api/2.0/dnsresourcerecords/8/"
+}
+`
+	dnsResourceRecordsResponse = `
+[
+    {
+        "id": 7,
+        "fqdn": "mail.maas",
+        "rrtype": "MX",
+        "rrdata": "10 mx1.example.com",
+        "ttl": null,
+        "resource_uri": "/MAAS/api/2.0/dnsresourcerecords/7/"
+    },` + dnsResourceRecordResponse + `
+]
+`
+)
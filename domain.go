@@ -4,18 +4,39 @@
 package gomaasapi
 
 import (
+	"fmt"
+	"net/http"
+
 	"github.com/juju/errors"
 	"github.com/juju/schema"
 	"github.com/juju/version"
 )
 
 type domain struct {
+	controller *controller
+
 	authoritative       bool
 	resourceRecordCount int
 	ttl                 *int
 	resourceURI         string
 	id                  int
 	name                string
+	isDefault           bool
+}
+
+func (domain *domain) updateFrom(other *domain) {
+	domain.authoritative = other.authoritative
+	domain.resourceRecordCount = other.resourceRecordCount
+	domain.ttl = other.ttl
+	domain.resourceURI = other.resourceURI
+	domain.id = other.id
+	domain.name = other.name
+	domain.isDefault = other.isDefault
+}
+
+// ID implements Domain interface
+func (domain *domain) ID() int {
+	return domain.id
 }
 
 // Name implements Domain interface
@@ -23,6 +44,124 @@ func (domain *domain) Name() string {
 	return domain.name
 }
 
+// Authoritative implements Domain interface
+func (domain *domain) Authoritative() bool {
+	return domain.authoritative
+}
+
+// TTL implements Domain interface
+func (domain *domain) TTL() *int {
+	return domain.ttl
+}
+
+// ResourceRecordCount implements Domain interface
+func (domain *domain) ResourceRecordCount() int {
+	return domain.resourceRecordCount
+}
+
+// IsDefault implements Domain interface
+func (domain *domain) IsDefault() bool {
+	return domain.isDefault
+}
+
+// UpdateDomainArgs is an argument struct for Domain.Update. Only the fields
+// that are set are changed.
+type UpdateDomainArgs struct {
+	Name          string
+	Authoritative *bool
+	TTL           *int
+}
+
+// Update implements Domain interface
+func (domain *domain) Update(args UpdateDomainArgs) error {
+	params := NewURLParams()
+	params.MaybeAdd("name", args.Name)
+	if args.Authoritative != nil {
+		params.Values.Add("authoritative", fmt.Sprint(*args.Authoritative))
+	}
+	if args.TTL != nil {
+		params.Values.Add("ttl", fmt.Sprint(*args.TTL))
+	}
+	source, err := domain.controller.put(domain.resourceURI, params.Values)
+	if err != nil {
+		if svrErr, ok := errors.Cause(err).(ServerError); ok {
+			switch svrErr.StatusCode {
+			case http.StatusNotFound:
+				return wrapError(err, NewNoMatchError(svrErr.BodyMessage))
+			case http.StatusForbidden:
+				return wrapError(err, NewPermissionError(svrErr.BodyMessage))
+			case http.StatusBadRequest:
+				return wrapError(err, NewBadRequestError(svrErr.BodyMessage))
+			}
+		}
+		return NewUnexpectedError(err)
+	}
+
+	response, err := readDomain(domain.controller.schemaVersion(), source)
+	if err != nil {
+		return errors.Trace(err)
+	}
+	domain.updateFrom(response)
+	return nil
+}
+
+// Delete implements Domain interface
+func (domain *domain) Delete() error {
+	err := domain.controller.delete(domain.resourceURI)
+	if err != nil {
+		if svrErr, ok := errors.Cause(err).(ServerError); ok {
+			switch svrErr.StatusCode {
+			case http.StatusNotFound:
+				return wrapError(err, NewNoMatchError(svrErr.BodyMessage))
+			case http.StatusForbidden:
+				return wrapError(err, NewPermissionError(svrErr.BodyMessage))
+			case http.StatusBadRequest, http.StatusConflict:
+				// MAAS refuses to delete the default domain, or a domain
+				// that still has records.
+				return wrapError(err, NewBadRequestError(svrErr.BodyMessage))
+			}
+		}
+		return NewUnexpectedError(err)
+	}
+	return nil
+}
+
+// SetDefault implements Domain interface
+func (domain *domain) SetDefault() error {
+	source, err := domain.controller.post(domain.resourceURI, "set_default", nil)
+	if err != nil {
+		if svrErr, ok := errors.Cause(err).(ServerError); ok {
+			switch svrErr.StatusCode {
+			case http.StatusNotFound:
+				return wrapError(err, NewNoMatchError(svrErr.BodyMessage))
+			case http.StatusForbidden:
+				return wrapError(err, NewPermissionError(svrErr.BodyMessage))
+			}
+		}
+		return NewUnexpectedError(err)
+	}
+
+	response, err := readDomain(domain.controller.schemaVersion(), source)
+	if err != nil {
+		return errors.Trace(err)
+	}
+	domain.updateFrom(response)
+	// Older versions of MAAS don't report whether the domain is the
+	// default.
+	domain.isDefault = true
+	return nil
+}
+
+func readDomain(controllerVersion version.Number, source interface{}) (*domain, error) {
+	checker := schema.StringMap(schema.Any())
+	coerced, err := checker.Coerce(source, nil)
+	if err != nil {
+		return nil, errors.Annotatef(err, "domain base schema check failed")
+	}
+	valid := coerced.(map[string]interface{})
+	return domain_(valid)
+}
+
 func readDomains(controllerVersion version.Number, source interface{}) ([]*domain, error) {
 	checker := schema.List(schema.StringMap(schema.Any()))
 	coerced, err := checker.Coerce(source, nil)
@@ -41,8 +180,13 @@ func domain_(source map[string]interface{}) (*domain, error) {
 		"resource_uri":          schema.String(),
 		"id":                    schema.ForceInt(),
 		"name":                  schema.String(),
+		"is_default":            schema.Bool(),
+	}
+	defaults := schema.Defaults{
+		// Older versions of MAAS don't report the default domain.
+		"is_default": false,
 	}
-	checker := schema.FieldMap(fields, nil) // no defaults
+	checker := schema.FieldMap(fields, defaults)
 	coerced, err := checker.Coerce(source, nil)
 	if err != nil {
 		return nil, errors.Annotatef(err, "domain schema check failed")
@@ -62,6 +206,7 @@ func domain_(source map[string]interface{}) (*domain, error) {
 		resourceRecordCount: valid["resource_record_count"].(int),
 		resourceURI:         valid["resource_uri"].(string),
 		ttl:                 ttl,
+		isDefault:           valid["is_default"].(bool),
 	}
 
 	return result, nil
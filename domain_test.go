@@ -4,11 +4,17 @@
 package gomaasapi
 
 import (
+	"net/http"
+
+	"github.com/juju/errors"
+	"github.com/juju/testing"
 	jc "github.com/juju/testing/checkers"
 	gc "gopkg.in/check.v1"
 )
 
-type domainSuite struct{}
+type domainSuite struct {
+	testing.LoggingCleanupSuite
+}
 
 var _ = gc.Suite(&domainSuite{})
 
@@ -23,6 +29,115 @@ func (*domainSuite) TestReadDomains(c *gc.C) {
 	c.Assert(domains, gc.HasLen, 2)
 	c.Assert(domains[0].Name(), gc.Equals, "maas")
 	c.Assert(domains[1].Name(), gc.Equals, "anotherDomain.com")
+
+	c.Check(domains[0].ID(), gc.Equals, 0)
+	c.Check(domains[0].Authoritative(), jc.IsTrue)
+	c.Check(domains[0].TTL(), gc.IsNil)
+	c.Check(domains[0].ResourceRecordCount(), gc.Equals, 3)
+	c.Check(domains[0].IsDefault(), jc.IsTrue)
+	c.Assert(domains[1].TTL(), gc.NotNil)
+	c.Check(*domains[1].TTL(), gc.Equals, 10)
+	c.Check(domains[1].IsDefault(), jc.IsFalse)
+}
+
+func (s *domainSuite) getServerAndDomain(c *gc.C) (*SimpleTestServer, Domain) {
+	server, controller := createTestServerController(c, s)
+	server.AddGetResponse("/api/2.0/domains/", http.StatusOK, domainResponse)
+	domains, err := controller.Domains()
+	c.Assert(err, jc.ErrorIsNil)
+	server.ResetRequests()
+	return server, domains[1]
+}
+
+func (s *domainSuite) TestCreateDomain(c *gc.C) {
+	server, controller := createTestServerController(c, s)
+	server.AddPostResponse("/api/2.0/domains/?op=", http.StatusOK, domainItemResponse)
+	authoritative := false
+	ttl := 3600
+	domain, err := controller.CreateDomain(CreateDomainArgs{
+		Name:          "example.com",
+		Authoritative: &authoritative,
+		TTL:           &ttl,
+	})
+	c.Assert(err, jc.ErrorIsNil)
+	c.Check(domain.ID(), gc.Equals, 2)
+	c.Check(domain.Name(), gc.Equals, "example.com")
+	c.Check(domain.Authoritative(), jc.IsFalse)
+
+	form := server.LastRequest().PostForm
+	c.Check(form, gc.HasLen, 3)
+	c.Check(form.Get("name"), gc.Equals, "example.com")
+	c.Check(form.Get("authoritative"), gc.Equals, "false")
+	c.Check(form.Get("ttl"), gc.Equals, "3600")
+}
+
+func (s *domainSuite) TestCreateDomainInvalid(c *gc.C) {
+	server, controller := createTestServerController(c, s)
+	server.ResetRequests()
+	_, err := controller.CreateDomain(CreateDomainArgs{})
+	c.Assert(err, jc.Satisfies, errors.IsNotValid)
+	c.Check(err.Error(), gc.Equals, "missing Name not valid")
+	c.Check(server.RequestCount(), gc.Equals, 0)
+}
+
+func (s *domainSuite) TestCreateDomainBadRequest(c *gc.C) {
+	server, controller := createTestServerController(c, s)
+	server.AddPostResponse("/api/2.0/domains/?op=", http.StatusBadRequest, "Domain with this Name already exists.")
+	_, err := controller.CreateDomain(CreateDomainArgs{Name: "maas"})
+	c.Assert(err, jc.Satisfies, IsBadRequestError)
+}
+
+func (s *domainSuite) TestUpdate(c *gc.C) {
+	server, domain := s.getServerAndDomain(c)
+	server.AddPutResponse("/MAAS/api/2.0/domains/1/", http.StatusOK, domainItemResponse)
+	ttl := 3600
+	err := domain.Update(UpdateDomainArgs{Name: "example.com", TTL: &ttl})
+	c.Assert(err, jc.ErrorIsNil)
+	c.Check(domain.Name(), gc.Equals, "example.com")
+
+	form := server.LastRequest().PostForm
+	c.Check(form, gc.HasLen, 2)
+	c.Check(form.Get("name"), gc.Equals, "example.com")
+	c.Check(form.Get("ttl"), gc.Equals, "3600")
+}
+
+func (s *domainSuite) TestUpdateForbidden(c *gc.C) {
+	server, domain := s.getServerAndDomain(c)
+	server.AddPutResponse("/MAAS/api/2.0/domains/1/", http.StatusForbidden, "admins only")
+	err := domain.Update(UpdateDomainArgs{Name: "example.com"})
+	c.Assert(err, jc.Satisfies, IsPermissionError)
+	c.Check(domain.Name(), gc.Equals, "anotherDomain.com")
+}
+
+func (s *domainSuite) TestDelete(c *gc.C) {
+	server, domain := s.getServerAndDomain(c)
+	server.AddDeleteResponse("/MAAS/api/2.0/domains/1/", http.StatusNoContent, "")
+	err := domain.Delete()
+	c.Assert(err, jc.ErrorIsNil)
+}
+
+func (s *domainSuite) TestDeleteDefault(c *gc.C) {
+	server, domain := s.getServerAndDomain(c)
+	server.AddDeleteResponse("/MAAS/api/2.0/domains/1/", http.StatusBadRequest, "cannot delete the default domain")
+	err := domain.Delete()
+	c.Assert(err, jc.Satisfies, IsBadRequestError)
+}
+
+func (s *domainSuite) TestSetDefault(c *gc.C) {
+	server, domain := s.getServerAndDomain(c)
+	// The response of older versions of MAAS doesn't include is_default.
+	server.AddPostResponse("/MAAS/api/2.0/domains/1/?op=set_default", http.StatusOK, domainItemResponse)
+	err := domain.SetDefault()
+	c.Assert(err, jc.ErrorIsNil)
+	c.Check(domain.IsDefault(), jc.IsTrue)
+	c.Check(server.LastRequest().Method, gc.Equals, "POST")
+}
+
+func (s *domainSuite) TestSetDefaultMissing(c *gc.C) {
+	_, domain := s.getServerAndDomain(c)
+	err := domain.SetDefault()
+	c.Assert(err, jc.Satisfies, IsNoMatchError)
+	c.Check(domain.IsDefault(), jc.IsFalse)
 }
 
 var domainResponse = `
@@ -33,6 +148,7 @@ var domainResponse = `
         "name": "maas",
         "id": 0,
         "ttl": null,
+        "is_default": true,
         "resource_record_count": 3
     }, {
         "authoritative": "true",
@@ -44,3 +160,14 @@ var domainResponse = `
     }
 ]
 `
+
+var domainItemResponse = `
+{
+    "authoritative": false,
+    "resource_uri": "/MAAS/api/2.0/domains/2/",
+    "name": "example.com",
+    "id": 2,
+    "ttl": 3600,
+    "resource_record_count": 0
+}
+`
@@ -9,6 +9,40 @@ import (
 	"github.com/juju/errors"
 )
 
+// The error types of the package are found with the standard errors
+// package, as in
+//
+//	var noMatch *gomaasapi.NoMatchError
+//	if errors.As(err, &noMatch) { ... }
+//
+// as well as with the IsXxxError functions. The ServerError of a failed
+// request, if any, also remains in the chain of the errors derived from it.
+
+// wrappedError is the result of wrapError. The standard errors package finds
+// both the new descriptive error and the wrapped one in its chain.
+type wrappedError struct {
+	*errors.Err
+	descriptive error
+}
+
+// Unwrap returns the descriptive error, followed by the wrapped one. The
+// standard errors package follows both from Go 1.20, which the module
+// requires.
+func (e *wrappedError) Unwrap() []error {
+	return []error{e.descriptive, e.Err.Unwrap()}
+}
+
+// wrapError is errors.Wrap for the descriptive errors of the package, which
+// errors.Wrap hides from the standard errors package.
+func wrapError(other, newDescriptive error) error {
+	err := &wrappedError{
+		Err:         errors.Wrap(other, newDescriptive).(*errors.Err),
+		descriptive: newDescriptive,
+	}
+	err.SetLocation(1)
+	return err
+}
+
 // NoMatchError is returned when the requested action cannot be performed
 // due to being unable to service due to no entities available that match the
 // request.
@@ -38,7 +72,9 @@ type UnexpectedError struct {
 func NewUnexpectedError(err error) error {
 	uerr := &UnexpectedError{Err: errors.NewErr("unexpected: %v", err)}
 	uerr.SetLocation(1)
-	return errors.Wrap(err, uerr)
+	wrapped := wrapError(err, uerr).(*wrappedError)
+	wrapped.SetLocation(1)
+	return wrapped
 }
 
 // IsUnexpectedError returns true if err is an UnexpectedError.
@@ -70,7 +106,9 @@ func IsUnsupportedVersionError(err error) bool {
 func WrapWithUnsupportedVersionError(err error) error {
 	uerr := &UnsupportedVersionError{Err: errors.NewErr("unsupported version: %v", err)}
 	uerr.SetLocation(1)
-	return errors.Wrap(err, uerr)
+	wrapped := wrapError(err, uerr).(*wrappedError)
+	wrapped.SetLocation(1)
+	return wrapped
 }
 
 // DeserializationError types are returned when the returned JSON data from
@@ -95,13 +133,10 @@ func WrapWithDeserializationError(err error, format string, args ...interface{})
 	// previous error, but wrap it in the new type.
 	derr := &DeserializationError{Err: errors.NewErr(message + ": " + err.Error())}
 	derr.SetLocation(1)
-	wrapped := errors.Wrap(err, derr)
+	wrapped := wrapError(err, derr).(*wrappedError)
 	// We want the location of the wrapped error to be the caller of this function,
 	// not the line above.
-	if errType, ok := wrapped.(*errors.Err); ok {
-		// We know it is because that is what Wrap returns.
-		errType.SetLocation(1)
-	}
+	wrapped.SetLocation(1)
 	return wrapped
 }
 
@@ -111,6 +146,59 @@ func IsDeserializationError(err error) bool {
 	return ok
 }
 
+// PartialResultError is returned alongside the items that could be read when
+// a listing was requested with invalid items skipped, and some of the items
+// failed to deserialize.
+type PartialResultError struct {
+	errors.Err
+	// Failures maps each skipped item to the reason it couldn't be read.
+	// Items are identified by their ID if the response included one, and
+	// by their position in the response otherwise.
+	Failures map[string]error
+}
+
+// NewPartialResultError constructs a new PartialResultError for the skipped
+// items of the given kind and sets the location.
+func NewPartialResultError(kind string, failures map[string]error) error {
+	err := &PartialResultError{
+		Err:      errors.NewErr("skipped %d invalid %s", len(failures), kind),
+		Failures: failures,
+	}
+	err.SetLocation(1)
+	return err
+}
+
+// IsPartialResultError returns true if err is a PartialResultError.
+func IsPartialResultError(err error) bool {
+	_, ok := errors.Cause(err).(*PartialResultError)
+	return ok
+}
+
+// InUseError is returned when deleting a resource that other resources
+// still depend on, if the deletion was requested with a usage check.
+type InUseError struct {
+	errors.Err
+	// Usage lists what depends on the resource.
+	Usage ResourceUsage
+}
+
+// NewInUseError constructs a new InUseError for the resource described and
+// sets the location.
+func NewInUseError(resource string, usage ResourceUsage) error {
+	err := &InUseError{
+		Err:   errors.NewErr("%s in use by %s", resource, usage),
+		Usage: usage,
+	}
+	err.SetLocation(1)
+	return err
+}
+
+// IsInUseError returns true if err is an InUseError.
+func IsInUseError(err error) bool {
+	_, ok := errors.Cause(err).(*InUseError)
+	return ok
+}
+
 // BadRequestError is returned when the requested action cannot be performed
 // due to bad or incorrect parameters passed to the server.
 type BadRequestError struct {
@@ -167,3 +255,29 @@ func IsCannotCompleteError(err error) bool {
 	_, ok := errors.Cause(err).(*CannotCompleteError)
 	return ok
 }
+
+// BulkError is returned by the operations run on many objects at once, such
+// as Controller.ForEachMachine, when the operation failed for some of them.
+type BulkError struct {
+	errors.Err
+	// Failures maps the IDs of the objects the operation failed for to
+	// their error.
+	Failures map[string]error
+}
+
+// NewBulkError constructs a new BulkError for the failures of the operation
+// run on count objects of the given kind and sets the location.
+func NewBulkError(kind string, count int, failures map[string]error) error {
+	err := &BulkError{
+		Err:      errors.NewErr("%d of %d %s failed", len(failures), count, kind),
+		Failures: failures,
+	}
+	err.SetLocation(1)
+	return err
+}
+
+// IsBulkError returns true if err is a BulkError.
+func IsBulkError(err error) bool {
+	_, ok := errors.Cause(err).(*BulkError)
+	return ok
+}
@@ -4,6 +4,7 @@
 package gomaasapi
 
 import (
+	stderrors "errors"
 	"strings"
 
 	"github.com/juju/errors"
@@ -82,3 +83,52 @@ func (*errorTypesSuite) TestCannotCompleteError(c *gc.C) {
 	c.Assert(err, jc.Satisfies, IsCannotCompleteError)
 	c.Assert(err.Error(), gc.Equals, "server says no")
 }
+
+func (*errorTypesSuite) TestPartialResultError(c *gc.C) {
+	failures := map[string]error{"abc": errors.New("bad")}
+	err := NewPartialResultError("machines", failures)
+	c.Assert(err, gc.NotNil)
+	c.Assert(err, jc.Satisfies, IsPartialResultError)
+	c.Assert(err.Error(), gc.Equals, "skipped 1 invalid machines")
+	c.Assert(errors.Cause(err).(*PartialResultError).Failures, jc.DeepEquals, failures)
+}
+
+func (*errorTypesSuite) TestInUseError(c *gc.C) {
+	usage := ResourceUsage{
+		Machines: []string{"4y3ha3"},
+		Links:    []string{"untasted-markita:eth0", "untasted-markita:eth1"},
+	}
+	err := NewInUseError("subnet 10.0.0.0/24", usage)
+	c.Assert(err, gc.NotNil)
+	c.Assert(err, jc.Satisfies, IsInUseError)
+	c.Assert(err.Error(), gc.Equals, "subnet 10.0.0.0/24 in use by 1 machine, 2 links")
+	c.Assert(errors.Cause(err).(*InUseError).Usage, jc.DeepEquals, usage)
+}
+
+func (*errorTypesSuite) TestStandardErrorsAs(c *gc.C) {
+	base := errors.Trace(ServerError{error: errors.New("boom"), StatusCode: 404})
+	err := errors.Annotate(errors.Trace(wrapError(base, NewNoMatchError("missing"))), "reading zone")
+	c.Assert(err, jc.Satisfies, IsNoMatchError)
+	c.Assert(err.Error(), gc.Equals, "reading zone: missing")
+
+	var noMatch *NoMatchError
+	c.Assert(stderrors.As(err, &noMatch), jc.IsTrue)
+	c.Check(noMatch.Error(), gc.Equals, "missing")
+	var svrErr ServerError
+	c.Assert(stderrors.As(err, &svrErr), jc.IsTrue)
+	c.Check(svrErr.StatusCode, gc.Equals, 404)
+	var permission *PermissionError
+	c.Check(stderrors.As(err, &permission), jc.IsFalse)
+}
+
+func (*errorTypesSuite) TestStandardErrorsAsWrapped(c *gc.C) {
+	base := errors.New("base error")
+	var unexpected *UnexpectedError
+	c.Check(stderrors.As(errors.Trace(NewUnexpectedError(base)), &unexpected), jc.IsTrue)
+	c.Check(stderrors.Is(NewUnexpectedError(base), base), jc.IsTrue)
+	var deserialization *DeserializationError
+	c.Check(stderrors.As(WrapWithDeserializationError(base, "foo"), &deserialization), jc.IsTrue)
+	var unsupported *UnsupportedVersionError
+	c.Check(stderrors.As(WrapWithUnsupportedVersionError(base), &unsupported), jc.IsTrue)
+	c.Check(stderrors.As(errors.Trace(NewUnsupportedVersionError("old")), &unsupported), jc.IsTrue)
+}
@@ -0,0 +1,324 @@
+// Copyright 2022 Canonical Ltd.
+// Licensed under the LGPLv3, see LICENCE file for details.
+
+package gomaasapi
+
+import (
+	"net/http"
+	"net/url"
+	"time"
+
+	"github.com/juju/collections/set"
+	"github.com/juju/errors"
+	"github.com/juju/schema"
+	"github.com/juju/version"
+)
+
+// The levels of events, from the least to the most severe. AUDIT events
+// record the actions of users.
+const (
+	EventLevelDebug    = "DEBUG"
+	EventLevelInfo     = "INFO"
+	EventLevelWarning  = "WARNING"
+	EventLevelError    = "ERROR"
+	EventLevelCritical = "CRITICAL"
+	EventLevelAudit    = "AUDIT"
+)
+
+var eventLevels = set.NewStrings(
+	EventLevelDebug,
+	EventLevelInfo,
+	EventLevelWarning,
+	EventLevelError,
+	EventLevelCritical,
+	EventLevelAudit,
+)
+
+// maxEventsLimit is the most events MAAS returns in a page.
+const maxEventsLimit = 1000
+
+type event struct {
+	controller *controller
+
+	id          int
+	node        string
+	hostname    string
+	username    string
+	level       string
+	type_       string
+	description string
+	created     string
+}
+
+// ID implements Event.
+func (e *event) ID() int {
+	return e.id
+}
+
+// Node implements Event.
+func (e *event) Node() string {
+	return e.node
+}
+
+// Hostname implements Event.
+func (e *event) Hostname() string {
+	return e.hostname
+}
+
+// Username implements Event.
+func (e *event) Username() string {
+	return e.username
+}
+
+// Level implements Event.
+func (e *event) Level() string {
+	return e.level
+}
+
+// Type implements Event.
+func (e *event) Type() string {
+	return e.type_
+}
+
+// Description implements Event.
+func (e *event) Description() string {
+	return e.description
+}
+
+// Created implements Event.
+func (e *event) Created() time.Time {
+	if e.created == "" {
+		return time.Time{}
+	}
+	var location *time.Location
+	if e.controller != nil {
+		location = e.controller.timeLocation
+	}
+	created, err := parseTimestamp(e.created, location)
+	if err != nil {
+		logger.Debugf("event %d: %v", e.id, err)
+		return time.Time{}
+	}
+	return created
+}
+
+// EventsArgs is an argument struct for selecting events. All the fields are
+// optional.
+type EventsArgs struct {
+	Hostnames    []string
+	MACAddresses []string
+	SystemIDs    []string
+	Zone         string
+	AgentName    string
+	Owner        string
+	// Level is the least severe level of the events, such as
+	// EventLevelWarning. MAAS defaults to EventLevelInfo.
+	Level string
+	// Limit is the number of events in each page, at most 1000. MAAS
+	// defaults to 100.
+	Limit int
+	// After and Before select the events with IDs greater or less than
+	// theirs. At most one of them can be set.
+	After  int
+	Before int
+}
+
+// Validate ensures that the level and the limit are valid, and that the
+// events aren't selected both after and before an event.
+func (a *EventsArgs) Validate() error {
+	if a.Level != "" && !eventLevels.Contains(a.Level) {
+		return errors.NotValidf("Level %q", a.Level)
+	}
+	if a.Limit < 0 || a.Limit > maxEventsLimit {
+		return errors.NotValidf("Limit %d", a.Limit)
+	}
+	if a.After != 0 && a.Before != 0 {
+		return errors.NotValidf("both After and Before")
+	}
+	return nil
+}
+
+type eventsPage struct {
+	controller *controller
+
+	events []*event
+	next   string
+}
+
+// Events implements EventsPage.
+func (p *eventsPage) Events() []Event {
+	result := make([]Event, len(p.events))
+	for i, e := range p.events {
+		result[i] = e
+	}
+	return result
+}
+
+// Next implements EventsPage.
+func (p *eventsPage) Next() (EventsPage, error) {
+	if len(p.events) == 0 || p.next == "" {
+		return nil, nil
+	}
+	nextURL, err := url.Parse(p.next)
+	if err != nil {
+		return nil, NewDeserializationError("bad next page link %q: %v", p.next, err)
+	}
+	query := nextURL.Query()
+	op := query.Get("op")
+	query.Del("op")
+	return p.controller.readEventsPage(nextURL.Path, op, query)
+}
+
+// Events implements Controller.
+func (c *controller) Events(args EventsArgs) (EventsPage, error) {
+	if err := args.Validate(); err != nil {
+		return nil, errors.Trace(err)
+	}
+	params := NewURLParams()
+	params.MaybeAddMany("hostname", args.Hostnames)
+	params.MaybeAddMany("mac_address", args.MACAddresses)
+	params.MaybeAddMany("id", args.SystemIDs)
+	params.MaybeAdd("zone", args.Zone)
+	params.MaybeAdd("agent_name", args.AgentName)
+	params.MaybeAdd("owner", args.Owner)
+	params.MaybeAdd("level", args.Level)
+	params.MaybeAddInt("limit", args.Limit)
+	params.MaybeAddInt("after", args.After)
+	params.MaybeAddInt("before", args.Before)
+	page, err := c.readEventsPage("events", "query", params.Values)
+	if err != nil {
+		return nil, errors.Trace(err)
+	}
+	return page, nil
+}
+
+func (c *controller) readEventsPage(path, op string, params url.Values) (*eventsPage, error) {
+	source, err := c._get(path, op, params)
+	if err != nil {
+		if svrErr, ok := errors.Cause(err).(ServerError); ok {
+			switch svrErr.StatusCode {
+			case http.StatusBadRequest:
+				return nil, wrapError(err, NewBadRequestError(svrErr.BodyMessage))
+			case http.StatusForbidden:
+				return nil, wrapError(err, NewPermissionError(svrErr.BodyMessage))
+			}
+		}
+		return nil, NewUnexpectedError(err)
+	}
+	page, err := readEventsPage(c.schemaVersion(), source)
+	if err != nil {
+		return nil, errors.Trace(err)
+	}
+	page.controller = c
+	for _, e := range page.events {
+		e.controller = c
+	}
+	return page, nil
+}
+
+func readEventsPage(controllerVersion version.Number, source interface{}) (*eventsPage, error) {
+	readFunc, err := getEventDeserializationFunc(controllerVersion)
+	if err != nil {
+		return nil, errors.Trace(err)
+	}
+
+	checker := schema.FieldMap(
+		schema.Fields{
+			"events":   schema.List(schema.StringMap(schema.Any())),
+			"next_uri": schema.OneOf(schema.Nil(""), schema.String()),
+		},
+		schema.Defaults{"next_uri": ""},
+	)
+	coerced, err := checker.Coerce(source, nil)
+	if err != nil {
+		return nil, WrapWithDeserializationError(err, "event base schema check failed")
+	}
+	valid := coerced.(map[string]interface{})
+	events, err := readEventList(valid["events"].([]interface{}), readFunc)
+	if err != nil {
+		return nil, errors.Trace(err)
+	}
+	next, _ := valid["next_uri"].(string)
+	return &eventsPage{events: events, next: next}, nil
+}
+
+func getEventDeserializationFunc(controllerVersion version.Number) (eventDeserializationFunc, error) {
+	var deserialisationVersion version.Number
+	for v := range eventDeserializationFuncs {
+		if v.Compare(deserialisationVersion) > 0 && v.Compare(controllerVersion) <= 0 {
+			deserialisationVersion = v
+		}
+	}
+	if deserialisationVersion == version.Zero {
+		return nil, NewUnsupportedVersionError("no event read func for version %s", controllerVersion)
+	}
+	return eventDeserializationFuncs[deserialisationVersion], nil
+}
+
+// readEventList expects the values of the sourceList to be string maps.
+func readEventList(sourceList []interface{}, readFunc eventDeserializationFunc) ([]*event, error) {
+	result := make([]*event, 0, len(sourceList))
+	for i, value := range sourceList {
+		source, ok := value.(map[string]interface{})
+		if !ok {
+			return nil, NewDeserializationError("unexpected value for event %d, %T", i, value)
+		}
+		event, err := readFunc(source)
+		if err != nil {
+			return nil, errors.Annotatef(err, "event %d", i)
+		}
+		result = append(result, event)
+	}
+	return result, nil
+}
+
+type eventDeserializationFunc func(map[string]interface{}) (*event, error)
+
+var eventDeserializationFuncs = map[version.Number]eventDeserializationFunc{
+	twoDotOh: event_2_0,
+}
+
+func event_2_0(source map[string]interface{}) (*event, error) {
+	fields := schema.Fields{
+		"id":          schema.ForceInt(),
+		"node":        schema.OneOf(schema.Nil(""), schema.String()),
+		"hostname":    schema.OneOf(schema.Nil(""), schema.String()),
+		"username":    schema.OneOf(schema.Nil(""), schema.String()),
+		"level":       schema.String(),
+		"type":        schema.String(),
+		"description": schema.OneOf(schema.Nil(""), schema.String()),
+		"created":     schema.OneOf(schema.Nil(""), schema.String()),
+	}
+	defaults := schema.Defaults{
+		"node":        "",
+		"hostname":    "",
+		"username":    "",
+		"description": "",
+		"created":     "",
+	}
+	checker := schema.FieldMap(fields, defaults)
+	coerced, err := checker.Coerce(source, nil)
+	if err != nil {
+		return nil, WrapWithDeserializationError(err, "event 2.0 schema check failed")
+	}
+	valid := coerced.(map[string]interface{})
+	// From here we know that the map returned from the schema coercion
+	// contains fields of the right type.
+
+	node, _ := valid["node"].(string)
+	hostname, _ := valid["hostname"].(string)
+	username, _ := valid["username"].(string)
+	description, _ := valid["description"].(string)
+	created, _ := valid["created"].(string)
+	result := &event{
+		id:          valid["id"].(int),
+		node:        node,
+		hostname:    hostname,
+		username:    username,
+		level:       valid["level"].(string),
+		type_:       valid["type"].(string),
+		description: description,
+		created:     created,
+	}
+	return result, nil
+}
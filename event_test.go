@@ -0,0 +1,173 @@
+// Copyright 2022 Canonical Ltd.
+// Licensed under the LGPLv3, see LICENCE file for details.
+
+package gomaasapi
+
+import (
+	"net/http"
+	"time"
+
+	"github.com/juju/errors"
+	"github.com/juju/testing"
+	jc "github.com/juju/testing/checkers"
+	"github.com/juju/version"
+	gc "gopkg.in/check.v1"
+)
+
+type eventSuite struct {
+	testing.LoggingCleanupSuite
+}
+
+var _ = gc.Suite(&eventSuite{})
+
+func (*eventSuite) TestReadEventsPageBadSchema(c *gc.C) {
+	_, err := readEventsPage(twoDotOh, "wat?")
+	c.Check(err, jc.Satisfies, IsDeserializationError)
+	c.Assert(err.Error(), gc.Equals, `event base schema check failed: expected map, got string("wat?")`)
+}
+
+func (*eventSuite) TestReadEventsPage(c *gc.C) {
+	page, err := readEventsPage(twoDotOh, parseJSON(c, eventsResponse))
+	c.Assert(err, jc.ErrorIsNil)
+	c.Assert(page.next, gc.Equals, "/api/2.0/events/?op=query&limit=2&before=1003")
+	events := page.Events()
+	c.Assert(events, gc.HasLen, 2)
+
+	event := events[0]
+	c.Check(event.ID(), gc.Equals, 1004)
+	c.Check(event.Node(), gc.Equals, "4y3ha3")
+	c.Check(event.Hostname(), gc.Equals, "untasted-markita")
+	c.Check(event.Username(), gc.Equals, "admin")
+	c.Check(event.Level(), gc.Equals, EventLevelInfo)
+	c.Check(event.Type(), gc.Equals, "Deployed")
+	c.Check(event.Description(), gc.Equals, "Deployed ubuntu/jammy")
+	c.Check(event.Created(), gc.Equals, time.Date(2022, 3, 1, 10, 30, 0, 0, time.UTC))
+
+	event = events[1]
+	c.Check(event.Node(), gc.Equals, "")
+	c.Check(event.Hostname(), gc.Equals, "")
+	c.Check(event.Description(), gc.Equals, "")
+	c.Check(event.Level(), gc.Equals, EventLevelAudit)
+}
+
+func (*eventSuite) TestLowVersion(c *gc.C) {
+	_, err := readEventsPage(version.MustParse("1.9.0"), parseJSON(c, eventsResponse))
+	c.Assert(err, jc.Satisfies, IsUnsupportedVersionError)
+}
+
+func (*eventSuite) TestHighVersion(c *gc.C) {
+	page, err := readEventsPage(version.MustParse("2.1.9"), parseJSON(c, eventsResponse))
+	c.Assert(err, jc.ErrorIsNil)
+	c.Assert(page.Events(), gc.HasLen, 2)
+}
+
+func (s *eventSuite) TestEventsArgsValidate(c *gc.C) {
+	for i, test := range []struct {
+		args    EventsArgs
+		message string
+	}{{
+		args: EventsArgs{},
+	}, {
+		args:    EventsArgs{Level: "info"},
+		message: `Level "info"`,
+	}, {
+		args:    EventsArgs{Limit: 1001},
+		message: "Limit 1001",
+	}, {
+		args:    EventsArgs{Limit: -1},
+		message: "Limit -1",
+	}, {
+		args:    EventsArgs{After: 10, Before: 20},
+		message: "both After and Before",
+	}, {
+		args: EventsArgs{Level: EventLevelWarning, Limit: 1000, After: 10},
+	}} {
+		c.Logf("test %d", i)
+		err := test.args.Validate()
+		if test.message == "" {
+			c.Check(err, jc.ErrorIsNil)
+		} else {
+			c.Check(err, jc.Satisfies, errors.IsNotValid)
+			c.Check(err.Error(), gc.Equals, test.message+" not valid")
+		}
+	}
+}
+
+func (s *eventSuite) TestEventsPages(c *gc.C) {
+	server, controller := createTestServerController(c, s)
+	server.AddGetResponse("/api/2.0/events/?hostname=untasted-markita&level=AUDIT&limit=2&op=query", http.StatusOK, eventsResponse)
+	server.AddGetResponse("/api/2.0/events/?before=1003&limit=2&op=query", http.StatusOK, `
+{
+    "count": 1,
+    "events": [{"id": 1001, "level": "INFO", "type": "Commissioning"}],
+    "next_uri": "/api/2.0/events/?op=query&limit=2&before=1001",
+    "prev_uri": "/api/2.0/events/?op=query&limit=2&after=1001"
+}`)
+	server.AddGetResponse("/api/2.0/events/?before=1001&limit=2&op=query", http.StatusOK, `
+{
+    "count": 0,
+    "events": [],
+    "next_uri": "/api/2.0/events/?op=query&limit=2&before=0",
+    "prev_uri": "/api/2.0/events/?op=query&limit=2&after=0"
+}`)
+
+	page, err := controller.Events(EventsArgs{
+		Hostnames: []string{"untasted-markita"},
+		Level:     EventLevelAudit,
+		Limit:     2,
+	})
+	c.Assert(err, jc.ErrorIsNil)
+	var ids []int
+	for page != nil {
+		for _, event := range page.Events() {
+			ids = append(ids, event.ID())
+		}
+		page, err = page.Next()
+		c.Assert(err, jc.ErrorIsNil)
+	}
+	c.Assert(ids, jc.DeepEquals, []int{1004, 1003, 1001})
+	c.Assert(server.RequestCount(), gc.Equals, 5)
+}
+
+func (s *eventSuite) TestEventsValidates(c *gc.C) {
+	_, controller := createTestServerController(c, s)
+	_, err := controller.Events(EventsArgs{Level: "LOUD"})
+	c.Assert(err, jc.Satisfies, errors.IsNotValid)
+}
+
+func (s *eventSuite) TestEventsBadRequest(c *gc.C) {
+	server, controller := createTestServerController(c, s)
+	server.AddGetResponse("/api/2.0/events/?op=query", http.StatusBadRequest, "bad zone")
+	_, err := controller.Events(EventsArgs{})
+	c.Assert(err, jc.Satisfies, IsBadRequestError)
+}
+
+const eventsResponse = `
+{
+    "count": 2,
+    "events": [
+        {
+            "username": "admin",
+            "node": "4y3ha3",
+            "hostname": "untasted-markita",
+            "id": 1004,
+            "level": "INFO",
+            "created": "Tue, 01 Mar. 2022 10:30:00",
+            "type": "Deployed",
+            "description": "Deployed ubuntu/jammy"
+        },
+        {
+            "username": "admin",
+            "node": null,
+            "hostname": null,
+            "id": 1003,
+            "level": "AUDIT",
+            "created": "Tue, 01 Mar. 2022 10:00:00",
+            "type": "Settings",
+            "description": null
+        }
+    ],
+    "next_uri": "/api/2.0/events/?op=query&limit=2&before=1003",
+    "prev_uri": "/api/2.0/events/?op=query&limit=2&after=1004"
+}
+`
@@ -4,14 +4,15 @@
 package gomaasapi
 
 import (
+	"net/http"
+
 	"github.com/juju/errors"
 	"github.com/juju/schema"
 	"github.com/juju/version"
 )
 
 type fabric struct {
-	// Add the controller in when we need to do things with the fabric.
-	// controller Controller
+	controller *controller
 
 	resourceURI string
 
@@ -41,11 +42,68 @@ func (f *fabric) ClassType() string {
 func (f *fabric) VLANs() []VLAN {
 	var result []VLAN
 	for _, v := range f.vlans {
+		v.controller = f.controller
 		result = append(result, v)
 	}
 	return result
 }
 
+// CreateVLANArgs is an argument struct for Fabric.CreateVLAN. Only VID is
+// required.
+type CreateVLANArgs struct {
+	VID         int
+	Name        string
+	MTU         int
+	Description string
+	// Space is the name of the space the VLAN is in.
+	Space string
+}
+
+// Validate ensures that the VID is a valid VLAN tag.
+func (a *CreateVLANArgs) Validate() error {
+	// The untagged VLAN, with VID 0, is created by MAAS with the fabric.
+	if a.VID < 1 || a.VID > 4094 {
+		return errors.NotValidf("VID %d", a.VID)
+	}
+	return nil
+}
+
+// CreateVLAN implements Fabric.
+func (f *fabric) CreateVLAN(args CreateVLANArgs) (VLAN, error) {
+	if err := args.Validate(); err != nil {
+		return nil, errors.Trace(err)
+	}
+	params := NewURLParams()
+	params.MaybeAddInt("vid", args.VID)
+	params.MaybeAdd("name", args.Name)
+	params.MaybeAddInt("mtu", args.MTU)
+	params.MaybeAdd("description", args.Description)
+	params.MaybeAdd("space", args.Space)
+	source, err := f.controller.post(f.resourceURI+"vlans/", "", params.Values)
+	f.controller.lookups.invalidate(lookupVLANsByID)
+	if err != nil {
+		if svrErr, ok := errors.Cause(err).(ServerError); ok {
+			switch svrErr.StatusCode {
+			case http.StatusNotFound:
+				return nil, wrapError(err, NewNoMatchError(svrErr.BodyMessage))
+			case http.StatusForbidden:
+				return nil, wrapError(err, NewPermissionError(svrErr.BodyMessage))
+			case http.StatusBadRequest:
+				return nil, wrapError(err, NewBadRequestError(svrErr.BodyMessage))
+			}
+		}
+		return nil, NewUnexpectedError(err)
+	}
+
+	vlan, err := readVLAN(f.controller.schemaVersion(), source)
+	if err != nil {
+		return nil, errors.Trace(err)
+	}
+	vlan.controller = f.controller
+	f.vlans = append(f.vlans, vlan)
+	return vlan, nil
+}
+
 func readFabrics(controllerVersion version.Number, source interface{}) ([]*fabric, error) {
 	checker := schema.List(schema.StringMap(schema.Any()))
 	coerced, err := checker.Coerce(source, nil)
@@ -4,12 +4,18 @@
 package gomaasapi
 
 import (
+	"net/http"
+
+	"github.com/juju/errors"
+	"github.com/juju/testing"
 	jc "github.com/juju/testing/checkers"
 	"github.com/juju/version"
 	gc "gopkg.in/check.v1"
 )
 
-type fabricSuite struct{}
+type fabricSuite struct {
+	testing.LoggingCleanupSuite
+}
 
 var _ = gc.Suite(&fabricSuite{})
 
@@ -43,6 +49,57 @@ func (*fabricSuite) TestHighVersion(c *gc.C) {
 	c.Assert(fabrics, gc.HasLen, 2)
 }
 
+func (s *fabricSuite) getServerAndFabric(c *gc.C) (*SimpleTestServer, Fabric) {
+	server, controller := createTestServerController(c, s)
+	server.AddGetResponse("/api/2.0/fabrics/", http.StatusOK, fabricResponse)
+	fabrics, err := controller.Fabrics()
+	c.Assert(err, jc.ErrorIsNil)
+	server.ResetRequests()
+	return server, fabrics[1]
+}
+
+func (s *fabricSuite) TestCreateVLAN(c *gc.C) {
+	server, fabric := s.getServerAndFabric(c)
+	server.AddPostResponse("/MAAS/api/2.0/fabrics/1/vlans/?op=", http.StatusOK, vlanItemResponse)
+	vlan, err := fabric.CreateVLAN(CreateVLANArgs{
+		VID:         100,
+		Name:        "storage",
+		MTU:         9000,
+		Description: "storage network",
+		Space:       "storage",
+	})
+	c.Assert(err, jc.ErrorIsNil)
+	c.Check(vlan.VID(), gc.Equals, 100)
+	c.Check(fabric.VLANs(), gc.HasLen, 2)
+
+	form := server.LastRequest().PostForm
+	c.Check(form, gc.HasLen, 5)
+	c.Check(form.Get("vid"), gc.Equals, "100")
+	c.Check(form.Get("name"), gc.Equals, "storage")
+	c.Check(form.Get("mtu"), gc.Equals, "9000")
+	c.Check(form.Get("description"), gc.Equals, "storage network")
+	c.Check(form.Get("space"), gc.Equals, "storage")
+
+	// The new VLAN can be updated straight away.
+	server.AddPutResponse("/MAAS/api/2.0/vlans/5010/", http.StatusOK, vlanItemResponse)
+	c.Assert(vlan.Update(UpdateVLANArgs{MTU: 1500}), jc.ErrorIsNil)
+}
+
+func (s *fabricSuite) TestCreateVLANInvalid(c *gc.C) {
+	server, fabric := s.getServerAndFabric(c)
+	_, err := fabric.CreateVLAN(CreateVLANArgs{VID: 4095})
+	c.Assert(err, jc.Satisfies, errors.IsNotValid)
+	c.Check(err.Error(), gc.Equals, "VID 4095 not valid")
+	c.Check(server.RequestCount(), gc.Equals, 0)
+}
+
+func (s *fabricSuite) TestCreateVLANBadRequest(c *gc.C) {
+	server, fabric := s.getServerAndFabric(c)
+	server.AddPostResponse("/MAAS/api/2.0/fabrics/1/vlans/?op=", http.StatusBadRequest, "VLAN with this VID already exists")
+	_, err := fabric.CreateVLAN(CreateVLANArgs{VID: 100})
+	c.Assert(err, jc.Satisfies, IsBadRequestError)
+}
+
 var fabricResponse = `
 [
     {
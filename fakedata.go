@@ -0,0 +1,264 @@
+// Copyright 2022 Canonical Ltd.
+// Licensed under the LGPLv3, see LICENCE file for details.
+
+package gomaasapi
+
+import (
+	"encoding/json"
+	"fmt"
+	"math/rand"
+)
+
+// GenerateMachinesArgs is an argument struct for GenerateMachines.
+type GenerateMachinesArgs struct {
+	// Count is the number of machines to generate.
+	Count int
+	// Interfaces is the number of interfaces per machine. The first
+	// interface is the boot interface. At least one interface is always
+	// generated.
+	Interfaces int
+	// Disks is the number of disks per machine, each with a single
+	// partition.
+	Disks int
+	// Seed is used to generate the random parts of the machines, such as
+	// the system IDs and sizes. The same arguments always generate the
+	// same machines.
+	Seed int64
+}
+
+// GenerateMachines returns synthetic machine payloads, as MAAS returns them
+// from the machines endpoint. The machines are valid for all the API versions
+// supported by the package, and are intended to load test code that reads
+// many machines from a SimpleTestServer.
+func GenerateMachines(args GenerateMachinesArgs) []map[string]interface{} {
+	generator := &machineGenerator{
+		rand: rand.New(rand.NewSource(args.Seed)),
+		args: args,
+	}
+	result := make([]map[string]interface{}, args.Count)
+	for i := range result {
+		result[i] = generator.machine(i)
+	}
+	return result
+}
+
+// GenerateMachinesJSON returns the machines from GenerateMachines encoded as
+// a JSON list, ready to be used as the response of a SimpleTestServer.
+func GenerateMachinesJSON(args GenerateMachinesArgs) string {
+	bytes, err := json.Marshal(GenerateMachines(args))
+	if err != nil {
+		// The machines only contain basic types, so this can't happen.
+		panic(err)
+	}
+	return string(bytes)
+}
+
+type machineGenerator struct {
+	rand *rand.Rand
+	args GenerateMachinesArgs
+
+	nextID int
+}
+
+var (
+	generatedArchitectures = []string{"amd64/generic", "arm64/generic", "ppc64el/generic"}
+	generatedStatuses      = []string{"Ready", "Allocated", "Deployed", "Deploying"}
+	generatedPowerStates   = []string{"on", "off"}
+	generatedTags          = []string{"virtual", "ssd", "gpu", "compute", "storage"}
+)
+
+const systemIDCharacters = "abcdefghijklmnopqrstuvwxyz0123456789"
+
+func (g *machineGenerator) id() int {
+	g.nextID++
+	return g.nextID
+}
+
+func (g *machineGenerator) choice(values []string) string {
+	return values[g.rand.Intn(len(values))]
+}
+
+func (g *machineGenerator) systemID() string {
+	result := make([]byte, 6)
+	for i := range result {
+		result[i] = systemIDCharacters[g.rand.Intn(len(systemIDCharacters))]
+	}
+	return string(result)
+}
+
+func (g *machineGenerator) machine(index int) map[string]interface{} {
+	systemID := g.systemID()
+	hostname := fmt.Sprintf("machine-%04d", index)
+	nodeURI := fmt.Sprintf("/MAAS/api/2.0/nodes/%s/", systemID)
+
+	interfaceCount := g.args.Interfaces
+	if interfaceCount < 1 {
+		interfaceCount = 1
+	}
+	var (
+		interfaces  []interface{}
+		ipAddresses []interface{}
+	)
+	for i := 0; i < interfaceCount; i++ {
+		address := fmt.Sprintf("10.%d.%d.%d", i, index/250, index%250+2)
+		interfaces = append(interfaces, g.iface(nodeURI, index, i, address))
+		ipAddresses = append(ipAddresses, address)
+	}
+
+	var disks []interface{}
+	for i := 0; i < g.args.Disks; i++ {
+		disks = append(disks, g.disk(nodeURI, i))
+	}
+
+	var tags []interface{}
+	for _, tag := range generatedTags {
+		if g.rand.Intn(2) == 0 {
+			tags = append(tags, tag)
+		}
+	}
+
+	return map[string]interface{}{
+		"resource_uri": fmt.Sprintf("/MAAS/api/2.0/machines/%s/", systemID),
+		"system_id":    systemID,
+		"hostname":     hostname,
+		"fqdn":         hostname + ".maas",
+		"tag_names":    orEmpty(tags),
+		"owner_data":   map[string]interface{}{},
+
+		"osystem":       "ubuntu",
+		"distro_series": "jammy",
+		"hwe_kernel":    "ga-22.04",
+		"architecture":  g.choice(generatedArchitectures),
+		"memory":        1024 * (1 + g.rand.Intn(64)),
+		"cpu_count":     1 + g.rand.Intn(64),
+		"hardware_info": map[string]interface{}{
+			"system_vendor": "Generated",
+		},
+
+		"ip_addresses":   ipAddresses,
+		"power_state":    g.choice(generatedPowerStates),
+		"status_name":    g.choice(generatedStatuses),
+		"status_message": nil,
+		"updated":        fmt.Sprintf("2022-03-01T%02d:%02d:00.000", index/60%24, index%60),
+
+		"boot_interface": interfaces[0],
+		"interface_set":  interfaces,
+		"zone": map[string]interface{}{
+			"name":         "default",
+			"description":  "",
+			"resource_uri": "/MAAS/api/2.0/zones/default/",
+		},
+		"pool": map[string]interface{}{
+			"name":         "default",
+			"description":  "",
+			"resource_uri": "/MAAS/api/2.0/resourcepool/0/",
+		},
+
+		"physicalblockdevice_set": orEmpty(disks),
+		"blockdevice_set":         orEmpty(disks),
+		"virtualblockdevice_set":  []interface{}{},
+	}
+}
+
+func (g *machineGenerator) iface(nodeURI string, machine, index int, address string) map[string]interface{} {
+	id := g.id()
+	vlan := map[string]interface{}{
+		"id":             5000 + index,
+		"resource_uri":   fmt.Sprintf("/MAAS/api/2.0/vlans/%d/", 5000+index),
+		"name":           "untagged",
+		"fabric":         fmt.Sprintf("fabric-%d", index),
+		"vid":            0,
+		"mtu":            1500,
+		"dhcp_on":        index == 0,
+		"primary_rack":   nil,
+		"secondary_rack": nil,
+	}
+	subnet := map[string]interface{}{
+		"resource_uri": fmt.Sprintf("/MAAS/api/2.0/subnets/%d/", index+1),
+		"id":           index + 1,
+		"name":         fmt.Sprintf("10.%d.0.0/16", index),
+		"space":        "undefined",
+		"gateway_ip":   fmt.Sprintf("10.%d.0.1", index),
+		"cidr":         fmt.Sprintf("10.%d.0.0/16", index),
+		"vlan":         vlan,
+		"dns_servers":  []interface{}{},
+	}
+	return map[string]interface{}{
+		"resource_uri": fmt.Sprintf("%sinterfaces/%d/", nodeURI, id),
+
+		"id":      id,
+		"name":    fmt.Sprintf("eth%d", index),
+		"type":    "physical",
+		"enabled": true,
+		"tags":    []interface{}{},
+
+		"vlan": vlan,
+		"links": []interface{}{
+			map[string]interface{}{
+				"id":         g.id(),
+				"mode":       "auto",
+				"subnet":     subnet,
+				"ip_address": address,
+			},
+		},
+
+		"mac_address":     fmt.Sprintf("52:54:00:%02x:%02x:%02x", index, machine/256%256, machine%256),
+		"effective_mtu":   1500,
+		"link_connected":  true,
+		"link_speed":      1000,
+		"interface_speed": 1000,
+
+		"parents":  []interface{}{},
+		"children": []interface{}{},
+	}
+}
+
+func (g *machineGenerator) disk(nodeURI string, index int) map[string]interface{} {
+	id := g.id()
+	name := "sd" + string(rune('a'+index%26))
+	if index >= 26 {
+		name += fmt.Sprint(index / 26)
+	}
+	size := uint64(1+g.rand.Intn(1024)) * (1 << 30)
+	uri := fmt.Sprintf("%sblockdevices/%d/", nodeURI, id)
+	partitionID := g.id()
+	partition := map[string]interface{}{
+		"resource_uri": fmt.Sprintf("%spartition/%d", uri, partitionID),
+		"id":           partitionID,
+		"path":         fmt.Sprintf("/dev/disk/by-dname/%s-part1", name),
+		"uuid":         nil,
+		"used_for":     "Unused",
+		"size":         size - (1 << 20),
+		"tags":         []interface{}{},
+		"filesystem":   nil,
+	}
+	return map[string]interface{}{
+		"resource_uri": uri,
+
+		"id":       id,
+		"uuid":     nil,
+		"name":     name,
+		"model":    "QEMU HARDDISK",
+		"serial":   fmt.Sprintf("QM%05d", id),
+		"id_path":  nil,
+		"path":     "/dev/disk/by-dname/" + name,
+		"used_for": "GPT partitioned with 1 partition",
+		"tags":     []interface{}{"rotary"},
+
+		"block_size": 512,
+		"used_size":  size - (1 << 20),
+		"size":       size,
+
+		"filesystem": nil,
+		"partitions": []interface{}{partition},
+	}
+}
+
+// orEmpty makes sure that empty lists are encoded as such rather than as
+// null, since MAAS always returns lists.
+func orEmpty(values []interface{}) []interface{} {
+	if values == nil {
+		return []interface{}{}
+	}
+	return values
+}
@@ -0,0 +1,81 @@
+// Copyright 2022 Canonical Ltd.
+// Licensed under the LGPLv3, see LICENCE file for details.
+
+package gomaasapi
+
+import (
+	"fmt"
+	"net/http"
+
+	"github.com/juju/testing"
+	jc "github.com/juju/testing/checkers"
+	gc "gopkg.in/check.v1"
+)
+
+type fakeDataSuite struct {
+	testing.LoggingCleanupSuite
+}
+
+var _ = gc.Suite(&fakeDataSuite{})
+
+func (*fakeDataSuite) TestGenerateMachines(c *gc.C) {
+	source := GenerateMachinesJSON(GenerateMachinesArgs{
+		Count:      20,
+		Interfaces: 3,
+		Disks:      2,
+	})
+	machines, err := readMachines(twoDotOh, parseJSON(c, source))
+	c.Assert(err, jc.ErrorIsNil)
+	c.Assert(machines, gc.HasLen, 20)
+
+	systemIDs := make(map[string]bool)
+	for i, machine := range machines {
+		c.Check(machine.Hostname(), gc.Equals, fmt.Sprintf("machine-%04d", i))
+		c.Check(machine.InterfaceSet(), gc.HasLen, 3)
+		c.Check(machine.BootInterface().Name(), gc.Equals, "eth0")
+		c.Check(machine.PhysicalBlockDevices(), gc.HasLen, 2)
+		c.Check(machine.BlockDevices()[1].Name(), gc.Equals, "sdb")
+		c.Check(machine.BlockDevices()[1].Partitions(), gc.HasLen, 1)
+		c.Check(machine.IPAddresses(), gc.HasLen, 3)
+		c.Check(machine.Updated().IsZero(), jc.IsFalse)
+		systemIDs[machine.SystemID()] = true
+	}
+	c.Check(systemIDs, gc.HasLen, 20)
+}
+
+func (*fakeDataSuite) TestGenerateMachinesDefaults(c *gc.C) {
+	machines, err := readMachines(twoDotOh, parseJSON(c, GenerateMachinesJSON(GenerateMachinesArgs{Count: 1})))
+	c.Assert(err, jc.ErrorIsNil)
+	c.Assert(machines, gc.HasLen, 1)
+	c.Check(machines[0].InterfaceSet(), gc.HasLen, 1)
+	c.Check(machines[0].BlockDevices(), gc.HasLen, 0)
+}
+
+func (*fakeDataSuite) TestGenerateMachinesDeterministic(c *gc.C) {
+	args := GenerateMachinesArgs{Count: 5, Interfaces: 2, Disks: 2, Seed: 42}
+	c.Check(GenerateMachinesJSON(args), gc.Equals, GenerateMachinesJSON(args))
+	other := args
+	other.Seed = 43
+	c.Check(GenerateMachinesJSON(args), gc.Not(gc.Equals), GenerateMachinesJSON(other))
+}
+
+func (s *fakeDataSuite) TestGenerateMachinesServer(c *gc.C) {
+	server, controller := createTestServerController(c, s)
+	server.AddGetResponse("/api/2.0/machines/", http.StatusOK, GenerateMachinesJSON(GenerateMachinesArgs{Count: 300}))
+	machines, err := controller.Machines(MachinesArgs{})
+	c.Assert(err, jc.ErrorIsNil)
+	c.Assert(machines, gc.HasLen, 300)
+}
+
+func (*fakeDataSuite) BenchmarkReadMachines(c *gc.C) {
+	source := parseJSON(c, GenerateMachinesJSON(GenerateMachinesArgs{
+		Count:      1000,
+		Interfaces: 4,
+		Disks:      4,
+	}))
+	c.ResetTimer()
+	for i := 0; i < c.N; i++ {
+		_, err := readMachines(twoDotOh, source)
+		c.Assert(err, jc.ErrorIsNil)
+	}
+}
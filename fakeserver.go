@@ -0,0 +1,960 @@
+// Copyright 2022 Canonical Ltd.
+// Licensed under the LGPLv3, see LICENCE file for details.
+
+package gomaasapi
+
+import (
+	"encoding/json"
+	"fmt"
+	"net"
+	"net/http"
+	"net/http/httptest"
+	"net/url"
+	"sort"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+)
+
+// fakeAPIPath is the path of the API served by a FakeServer.
+const fakeAPIPath = "/MAAS/api/2.0/"
+
+// FakeServerUser is the user that controllers of a FakeServer are
+// authenticated as, and that owns the machines they allocate.
+const FakeServerUser = "admin"
+
+// FakeMachine is a machine of a FakeServer. Zero values are replaced by
+// defaults when the machine is added.
+type FakeMachine struct {
+	// SystemID defaults to a generated ID.
+	SystemID string
+	// Hostname defaults to a name derived from the system ID.
+	Hostname string
+	// Status is the status name of the machine, such as "Ready" or
+	// "Deployed". It defaults to "Ready".
+	Status string
+	// Architecture defaults to "amd64/generic".
+	Architecture string
+	// CPUCount defaults to 1.
+	CPUCount int
+	// Memory is in MiB, and defaults to 1024.
+	Memory int
+	Tags   []string
+	// Zone and Pool default to "default".
+	Zone string
+	Pool string
+	// PowerState defaults to "on" for the deployed machines, and to "off"
+	// for the others.
+	PowerState   string
+	Owner        string
+	AgentName    string
+	OwnerData    map[string]string
+	OSystem      string
+	DistroSeries string
+}
+
+// FakeSubnet is a subnet of a FakeServer.
+type FakeSubnet struct {
+	// ID defaults to a generated ID.
+	ID int
+	// CIDR is required.
+	CIDR string
+	// Name defaults to the CIDR.
+	Name string
+	// Space defaults to "undefined".
+	Space     string
+	GatewayIP string
+	// VID is the ID of the VLAN of the subnet, 0 for the untagged VLAN.
+	VID int
+}
+
+// FakeTag is a tag of a FakeServer. The machines are tagged through
+// FakeMachine.Tags.
+type FakeTag struct {
+	Name       string
+	Comment    string
+	Definition string
+	KernelOpts string
+}
+
+// FakeVMHost is a VM host of a FakeServer.
+type FakeVMHost struct {
+	// ID defaults to a generated ID.
+	ID int
+	// Name defaults to a name derived from the ID.
+	Name string
+	// Type defaults to "lxd".
+	Type string
+	Tags []string
+	// Zone and Pool default to "default".
+	Zone         string
+	Pool         string
+	Cores        int
+	Memory       int
+	LocalStorage int
+}
+
+// FakeFixtures are the objects a FakeServer starts with.
+type FakeFixtures struct {
+	Machines []FakeMachine
+	Subnets  []FakeSubnet
+	Tags     []FakeTag
+	VMHosts  []FakeVMHost
+}
+
+// FakeServer is an in-memory MAAS serving the parts of the 2.0 API used to
+// manage machines, subnets, tags and VM hosts, so that code using a
+// Controller can be tested without a live MAAS. Unlike a SimpleTestServer,
+// it keeps its state between requests: allocating a machine makes it
+// Allocated to FakeServerUser, deploying it makes it Deployed, and
+// releasing it makes it Ready again. Deployments complete immediately.
+// Requests the fake doesn't serve fail with a 404 or a 405 status.
+type FakeServer struct {
+	*httptest.Server
+
+	mu       sync.Mutex
+	machines map[string]*fakeMachine
+	// machineIDs are the system IDs of the machines in the order they
+	// were added, in which they are listed and allocated.
+	machineIDs []string
+	subnets    []*FakeSubnet
+	tags       []*FakeTag
+	vmHosts    []*FakeVMHost
+	nextID     int
+}
+
+// fakeMachine is a FakeMachine with the time it was last changed.
+type fakeMachine struct {
+	FakeMachine
+	updated time.Time
+}
+
+// NewFakeServer returns a started FakeServer holding the fixtures. It must be
+// closed once done with.
+func NewFakeServer(fixtures FakeFixtures) *FakeServer {
+	server := &FakeServer{
+		machines: make(map[string]*fakeMachine),
+	}
+	for _, m := range fixtures.Machines {
+		server.AddMachine(m)
+	}
+	for _, subnet := range fixtures.Subnets {
+		server.AddSubnet(subnet)
+	}
+	for _, tag := range fixtures.Tags {
+		server.AddTag(tag)
+	}
+	for _, vmHost := range fixtures.VMHosts {
+		server.AddVMHost(vmHost)
+	}
+	server.Server = httptest.NewServer(http.HandlerFunc(server.handler))
+	return server
+}
+
+// NewFakeController returns a Controller for the FakeServer, authenticated
+// as FakeServerUser.
+func NewFakeController(server *FakeServer) (Controller, error) {
+	return NewController(ControllerArgs{
+		BaseURL: server.BaseURL(),
+		APIKey:  "fake:fake:fake",
+	})
+}
+
+// BaseURL returns the URL controllers of the server are created with.
+func (s *FakeServer) BaseURL() string {
+	return s.URL + "/MAAS/"
+}
+
+// AddMachine adds a machine, and returns its system ID.
+func (s *FakeServer) AddMachine(m FakeMachine) string {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	if m.SystemID == "" {
+		m.SystemID = fmt.Sprintf("fake%02d", s.newID())
+	}
+	if m.Hostname == "" {
+		m.Hostname = "machine-" + m.SystemID
+	}
+	if m.Status == "" {
+		m.Status = "Ready"
+	}
+	if m.Architecture == "" {
+		m.Architecture = "amd64/generic"
+	}
+	if m.CPUCount == 0 {
+		m.CPUCount = 1
+	}
+	if m.Memory == 0 {
+		m.Memory = 1024
+	}
+	if m.Zone == "" {
+		m.Zone = "default"
+	}
+	if m.Pool == "" {
+		m.Pool = "default"
+	}
+	if m.PowerState == "" {
+		m.PowerState = "off"
+		if m.Status == "Deployed" {
+			m.PowerState = "on"
+		}
+	}
+	if m.OSystem == "" {
+		m.OSystem = "ubuntu"
+	}
+	if m.DistroSeries == "" {
+		m.DistroSeries = "jammy"
+	}
+	m = copyFakeMachine(m)
+	if _, found := s.machines[m.SystemID]; !found {
+		s.machineIDs = append(s.machineIDs, m.SystemID)
+	}
+	s.machines[m.SystemID] = &fakeMachine{FakeMachine: m, updated: time.Now()}
+	return m.SystemID
+}
+
+// Machine returns the current state of the machine with the system ID.
+func (s *FakeServer) Machine(systemID string) (FakeMachine, bool) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	m, found := s.machines[systemID]
+	if !found {
+		return FakeMachine{}, false
+	}
+	return copyFakeMachine(m.FakeMachine), true
+}
+
+// SetMachineStatus changes the status of the machine with the system ID,
+// such as to simulate a failed deployment. It returns false if there is no
+// such machine.
+func (s *FakeServer) SetMachineStatus(systemID, status string) bool {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	m, found := s.machines[systemID]
+	if !found {
+		return false
+	}
+	m.Status = status
+	m.updated = time.Now()
+	return true
+}
+
+// AddSubnet adds a subnet, and returns its ID.
+func (s *FakeServer) AddSubnet(subnet FakeSubnet) int {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	return s.addSubnet(subnet).ID
+}
+
+func (s *FakeServer) addSubnet(subnet FakeSubnet) *FakeSubnet {
+	if subnet.ID == 0 {
+		subnet.ID = s.newID()
+	}
+	if subnet.Name == "" {
+		subnet.Name = subnet.CIDR
+	}
+	if subnet.Space == "" {
+		subnet.Space = "undefined"
+	}
+	s.subnets = append(s.subnets, &subnet)
+	return &subnet
+}
+
+// Subnets returns the current subnets.
+func (s *FakeServer) Subnets() []FakeSubnet {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	result := make([]FakeSubnet, len(s.subnets))
+	for i, subnet := range s.subnets {
+		result[i] = *subnet
+	}
+	return result
+}
+
+// AddTag adds a tag.
+func (s *FakeServer) AddTag(tag FakeTag) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.tags = append(s.tags, &tag)
+}
+
+// Tags returns the current tags.
+func (s *FakeServer) Tags() []FakeTag {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	result := make([]FakeTag, len(s.tags))
+	for i, tag := range s.tags {
+		result[i] = *tag
+	}
+	return result
+}
+
+// AddVMHost adds a VM host, and returns its ID.
+func (s *FakeServer) AddVMHost(vmHost FakeVMHost) int {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	if vmHost.ID == 0 {
+		vmHost.ID = s.newID()
+	}
+	if vmHost.Name == "" {
+		vmHost.Name = fmt.Sprintf("vmhost-%d", vmHost.ID)
+	}
+	if vmHost.Type == "" {
+		vmHost.Type = "lxd"
+	}
+	if vmHost.Zone == "" {
+		vmHost.Zone = "default"
+	}
+	if vmHost.Pool == "" {
+		vmHost.Pool = "default"
+	}
+	vmHost.Tags = append([]string(nil), vmHost.Tags...)
+	s.vmHosts = append(s.vmHosts, &vmHost)
+	return vmHost.ID
+}
+
+func (s *FakeServer) newID() int {
+	s.nextID++
+	return s.nextID
+}
+
+func copyFakeMachine(m FakeMachine) FakeMachine {
+	m.Tags = append([]string(nil), m.Tags...)
+	ownerData := make(map[string]string, len(m.OwnerData))
+	for key, value := range m.OwnerData {
+		ownerData[key] = value
+	}
+	m.OwnerData = ownerData
+	return m
+}
+
+// fakeError is an error response of a FakeServer.
+type fakeError struct {
+	status  int
+	message string
+}
+
+func newFakeError(status int, format string, args ...interface{}) *fakeError {
+	return &fakeError{status: status, message: fmt.Sprintf(format, args...)}
+}
+
+// fakeNotFound is the error MAAS returns for unknown objects.
+var fakeNotFound = &fakeError{status: http.StatusNotFound, message: "Not Found"}
+
+// fakeHandler serves the requests to a collection, or to one of its
+// objects, with the values of the query and of the form.
+type fakeHandler func(r *http.Request, op string, path []string) (interface{}, *fakeError)
+
+func (s *FakeServer) handler(w http.ResponseWriter, r *http.Request) {
+	if !strings.HasPrefix(r.URL.Path, fakeAPIPath) {
+		http.NotFound(w, r)
+		return
+	}
+	var err error
+	if strings.HasPrefix(r.Header.Get("Content-Type"), "multipart/form-data;") {
+		err = r.ParseMultipartForm(2 << 20)
+	} else {
+		err = r.ParseForm()
+	}
+	if err != nil {
+		http.Error(w, err.Error(), http.StatusBadRequest)
+		return
+	}
+	op := r.URL.Query().Get("op")
+	r.Form.Del("op")
+	path := strings.Split(strings.Trim(strings.TrimPrefix(r.URL.Path, fakeAPIPath), "/"), "/")
+	handlers := map[string]fakeHandler{
+		"version":  s.versionHandler,
+		"users":    s.usersHandler,
+		"machines": s.machinesHandler,
+		"subnets":  s.subnetsHandler,
+		"tags":     s.tagsHandler,
+		"pods":     s.vmHostsHandler,
+		"vm-hosts": s.vmHostsHandler,
+	}
+	handler, found := handlers[path[0]]
+	if !found {
+		http.NotFound(w, r)
+		return
+	}
+
+	s.mu.Lock()
+	result, fakeErr := handler(r, op, path[1:])
+	s.mu.Unlock()
+	if fakeErr != nil {
+		http.Error(w, fakeErr.message, fakeErr.status)
+		return
+	}
+	if result == nil {
+		w.WriteHeader(http.StatusNoContent)
+		return
+	}
+	w.Header().Set("Content-Type", "application/json")
+	if err := json.NewEncoder(w).Encode(result); err != nil {
+		logger.Debugf("writing fake response: %v", err)
+	}
+}
+
+// fakeMethodNotAllowed is the error of the requests that a handler doesn't
+// serve.
+func fakeMethodNotAllowed(r *http.Request, op string) *fakeError {
+	return newFakeError(http.StatusMethodNotAllowed, "%s %s with op %q isn't supported by the fake", r.Method, r.URL.Path, op)
+}
+
+func (s *FakeServer) versionHandler(r *http.Request, op string, path []string) (interface{}, *fakeError) {
+	if r.Method != http.MethodGet && r.Method != http.MethodHead || len(path) > 0 {
+		return nil, fakeMethodNotAllowed(r, op)
+	}
+	return map[string]interface{}{
+		"version":      "3.2.0",
+		"subversion":   "",
+		"capabilities": []string{"networks-management", "static-ipaddresses", "ipv6-deployment-ubuntu", "devices-management", "storage-deployment-ubuntu", "network-deployment-ubuntu"},
+	}, nil
+}
+
+func (s *FakeServer) usersHandler(r *http.Request, op string, path []string) (interface{}, *fakeError) {
+	if r.Method != http.MethodGet || op != "whoami" || len(path) > 0 {
+		return nil, fakeMethodNotAllowed(r, op)
+	}
+	return map[string]interface{}{
+		"username":     FakeServerUser,
+		"email":        FakeServerUser + "@example.com",
+		"is_superuser": true,
+		"is_local":     true,
+	}, nil
+}
+
+func (s *FakeServer) machinesHandler(r *http.Request, op string, path []string) (interface{}, *fakeError) {
+	if len(path) == 0 {
+		switch {
+		case r.Method == http.MethodGet && op == "":
+			return s.listMachines(r.Form), nil
+		case r.Method == http.MethodPost && op == "allocate":
+			return s.allocateMachine(r.Form)
+		case r.Method == http.MethodPost && op == "release":
+			return s.releaseMachines(r.Form)
+		}
+		return nil, fakeMethodNotAllowed(r, op)
+	}
+	m, found := s.machines[path[0]]
+	if !found || len(path) > 1 {
+		return nil, fakeNotFound
+	}
+	switch {
+	case r.Method == http.MethodGet && op == "":
+	case r.Method == http.MethodPost && op == "deploy":
+		if m.Status != "Allocated" {
+			return nil, newFakeError(http.StatusConflict, "Machine cannot be deployed in its current state (%s).", m.Status)
+		}
+		if osystem := r.Form.Get("osystem"); osystem != "" {
+			m.OSystem = osystem
+		}
+		if series := r.Form.Get("distro_series"); series != "" {
+			m.DistroSeries = series
+		}
+		m.Status = "Deployed"
+		m.PowerState = "on"
+	case r.Method == http.MethodPost && op == "release":
+		if !fakeReleasable(m.Status) {
+			return nil, newFakeError(http.StatusConflict, "Machine cannot be released in its current state (%s).", m.Status)
+		}
+		m.release()
+	case r.Method == http.MethodPost && op == "set_owner_data":
+		for key := range r.Form {
+			if value := r.Form.Get(key); value != "" {
+				m.OwnerData[key] = value
+			} else {
+				delete(m.OwnerData, key)
+			}
+		}
+	default:
+		return nil, fakeMethodNotAllowed(r, op)
+	}
+	if r.Method != http.MethodGet {
+		m.updated = time.Now()
+	}
+	return m.render(), nil
+}
+
+func (s *FakeServer) listMachines(query url.Values) []interface{} {
+	result := []interface{}{}
+	for _, id := range s.machineIDs {
+		m := s.machines[id]
+		if fakeMachineMatches(m, query) {
+			result = append(result, m.render())
+		}
+	}
+	return result
+}
+
+// fakeMachineMatches reports whether the machine matches the filters of a
+// listing.
+func fakeMachineMatches(m *fakeMachine, query url.Values) bool {
+	if ids, ok := query["id"]; ok && !contains(ids, m.SystemID) {
+		return false
+	}
+	if hostnames, ok := query["hostname"]; ok && !contains(hostnames, m.Hostname) {
+		return false
+	}
+	for key, value := range map[string]string{
+		"zone":       m.Zone,
+		"pool":       m.Pool,
+		"agent_name": m.AgentName,
+	} {
+		if want, ok := query[key]; ok && want[0] != value {
+			return false
+		}
+	}
+	for _, tag := range query["tags"] {
+		if !contains(m.Tags, tag) {
+			return false
+		}
+	}
+	return true
+}
+
+func (s *FakeServer) allocateMachine(form url.Values) (interface{}, *fakeError) {
+	for _, constraint := range []string{"storage", "interfaces", "not_subnets"} {
+		if _, ok := form[constraint]; ok {
+			return nil, newFakeError(http.StatusBadRequest, "constraint %q isn't supported by the fake", constraint)
+		}
+	}
+	var minCPUCount, minMemory int
+	for key, value := range map[string]*int{"cpu_count": &minCPUCount, "mem": &minMemory} {
+		if form.Get(key) == "" {
+			continue
+		}
+		parsed, err := strconv.ParseFloat(form.Get(key), 64)
+		if err != nil {
+			return nil, newFakeError(http.StatusBadRequest, "Invalid %s: %q", key, form.Get(key))
+		}
+		*value = int(parsed)
+	}
+	for _, id := range s.machineIDs {
+		m := s.machines[id]
+		switch {
+		case m.Status != "Ready",
+			form.Get("name") != "" && form.Get("name") != m.Hostname,
+			form.Get("system_id") != "" && form.Get("system_id") != m.SystemID,
+			form.Get("arch") != "" && !strings.HasPrefix(m.Architecture, form.Get("arch")),
+			m.CPUCount < minCPUCount,
+			m.Memory < minMemory,
+			form.Get("zone") != "" && form.Get("zone") != m.Zone,
+			form.Get("pool") != "" && form.Get("pool") != m.Pool,
+			contains(form["not_in_zone"], m.Zone),
+			contains(form["not_in_pool"], m.Pool),
+			!fakeContainsAll(m.Tags, form["tags"]),
+			fakeContainsAny(m.Tags, form["not_tags"]):
+			continue
+		}
+		if form.Get("dry_run") != "true" {
+			m.Status = "Allocated"
+			m.Owner = FakeServerUser
+			m.AgentName = form.Get("agent_name")
+			m.updated = time.Now()
+		}
+		result := m.render()
+		result["constraints_by_type"] = map[string]interface{}{}
+		return result, nil
+	}
+	return nil, newFakeError(http.StatusConflict, "No available machine matches constraints.")
+}
+
+func (s *FakeServer) releaseMachines(form url.Values) (interface{}, *fakeError) {
+	var unknown, conflicting []string
+	for _, id := range form["machines"] {
+		m, found := s.machines[id]
+		switch {
+		case !found:
+			unknown = append(unknown, id)
+		case !fakeReleasable(m.Status):
+			conflicting = append(conflicting, id)
+		}
+	}
+	if len(unknown) > 0 {
+		return nil, newFakeError(http.StatusBadRequest, "Unknown machine(s): %s.", strings.Join(unknown, ", "))
+	}
+	if len(conflicting) > 0 {
+		return nil, newFakeError(http.StatusConflict, "Machine(s) cannot be released in their current state: %s.", strings.Join(conflicting, ", "))
+	}
+	released := []string{}
+	for _, id := range form["machines"] {
+		m := s.machines[id]
+		m.release()
+		m.updated = time.Now()
+		released = append(released, id)
+	}
+	return released, nil
+}
+
+// fakeReleasable reports whether machines with the status can be released.
+func fakeReleasable(status string) bool {
+	switch status {
+	case "Allocated", "Deploying", "Deployed", "Failed deployment", "Failed releasing", "Failed disk erasing":
+		return true
+	}
+	return false
+}
+
+func (m *fakeMachine) release() {
+	m.Status = "Ready"
+	m.PowerState = "off"
+	m.Owner = ""
+	m.AgentName = ""
+	m.OwnerData = make(map[string]string)
+}
+
+// render returns the machine as MAAS returns it.
+func (m *fakeMachine) render() map[string]interface{} {
+	ownerData := make(map[string]interface{}, len(m.OwnerData))
+	for key, value := range m.OwnerData {
+		ownerData[key] = value
+	}
+	var owner interface{}
+	if m.Owner != "" {
+		owner = m.Owner
+	}
+	return map[string]interface{}{
+		"resource_uri": fmt.Sprintf("%smachines/%s/", fakeAPIPath, m.SystemID),
+		"system_id":    m.SystemID,
+		"hostname":     m.Hostname,
+		"fqdn":         m.Hostname + ".maas",
+		"tag_names":    fakeStrings(m.Tags),
+		"owner":        owner,
+		"owner_data":   ownerData,
+
+		"osystem":       m.OSystem,
+		"distro_series": m.DistroSeries,
+		"architecture":  m.Architecture,
+		"memory":        m.Memory,
+		"cpu_count":     m.CPUCount,
+		"hardware_info": map[string]interface{}{},
+
+		"ip_addresses":   []interface{}{},
+		"power_state":    m.PowerState,
+		"status_name":    m.Status,
+		"status_message": nil,
+		"updated":        m.updated.UTC().Format("2006-01-02T15:04:05.999999"),
+
+		"boot_interface": nil,
+		"interface_set":  []interface{}{},
+		"zone":           renderFakeZone(m.Zone),
+		"pool":           renderFakePool(m.Pool),
+
+		"physicalblockdevice_set": []interface{}{},
+		"blockdevice_set":         []interface{}{},
+		"virtualblockdevice_set":  []interface{}{},
+	}
+}
+
+func renderFakeZone(name string) map[string]interface{} {
+	return map[string]interface{}{
+		"name":         name,
+		"description":  "",
+		"resource_uri": fmt.Sprintf("%szones/%s/", fakeAPIPath, name),
+	}
+}
+
+func renderFakePool(name string) map[string]interface{} {
+	return map[string]interface{}{
+		"name":         name,
+		"description":  "",
+		"resource_uri": fmt.Sprintf("%sresourcepool/%s/", fakeAPIPath, name),
+	}
+}
+
+func (s *FakeServer) subnetsHandler(r *http.Request, op string, path []string) (interface{}, *fakeError) {
+	if len(path) == 0 {
+		switch {
+		case r.Method == http.MethodGet && op == "":
+			result := []interface{}{}
+			for _, subnet := range s.subnets {
+				result = append(result, renderFakeSubnet(subnet))
+			}
+			return result, nil
+		case r.Method == http.MethodPost && op == "":
+			return s.createSubnet(r.Form)
+		}
+		return nil, fakeMethodNotAllowed(r, op)
+	}
+	index := -1
+	for i, subnet := range s.subnets {
+		if strconv.Itoa(subnet.ID) == path[0] {
+			index = i
+		}
+	}
+	if index < 0 || len(path) > 1 {
+		return nil, fakeNotFound
+	}
+	switch {
+	case r.Method == http.MethodGet && op == "":
+		return renderFakeSubnet(s.subnets[index]), nil
+	case r.Method == http.MethodDelete:
+		s.subnets = append(s.subnets[:index], s.subnets[index+1:]...)
+		return nil, nil
+	}
+	return nil, fakeMethodNotAllowed(r, op)
+}
+
+func (s *FakeServer) createSubnet(form url.Values) (interface{}, *fakeError) {
+	cidr := form.Get("cidr")
+	if _, _, err := net.ParseCIDR(cidr); err != nil {
+		return nil, newFakeError(http.StatusBadRequest, `{"cidr": ["Enter a valid CIDR."]}`)
+	}
+	for _, subnet := range s.subnets {
+		if subnet.CIDR == cidr {
+			return nil, newFakeError(http.StatusBadRequest, `{"cidr": ["Subnet with this Cidr already exists."]}`)
+		}
+	}
+	return renderFakeSubnet(s.addSubnet(FakeSubnet{
+		CIDR:      cidr,
+		Name:      form.Get("name"),
+		GatewayIP: form.Get("gateway_ip"),
+	})), nil
+}
+
+// renderFakeSubnet returns the subnet as MAAS returns it.
+func renderFakeSubnet(subnet *FakeSubnet) map[string]interface{} {
+	var gateway interface{}
+	if subnet.GatewayIP != "" {
+		gateway = subnet.GatewayIP
+	}
+	vlanName := "untagged"
+	if subnet.VID != 0 {
+		vlanName = strconv.Itoa(subnet.VID)
+	}
+	return map[string]interface{}{
+		"resource_uri": fmt.Sprintf("%ssubnets/%d/", fakeAPIPath, subnet.ID),
+		"id":           subnet.ID,
+		"name":         subnet.Name,
+		"space":        subnet.Space,
+		"gateway_ip":   gateway,
+		"cidr":         subnet.CIDR,
+		"dns_servers":  []interface{}{},
+		"vlan": map[string]interface{}{
+			"id":             5000 + subnet.VID,
+			"resource_uri":   fmt.Sprintf("%svlans/%d/", fakeAPIPath, 5000+subnet.VID),
+			"name":           vlanName,
+			"fabric":         "fabric-0",
+			"vid":            subnet.VID,
+			"mtu":            1500,
+			"dhcp_on":        false,
+			"primary_rack":   nil,
+			"secondary_rack": nil,
+		},
+	}
+}
+
+func (s *FakeServer) tagsHandler(r *http.Request, op string, path []string) (interface{}, *fakeError) {
+	if len(path) == 0 {
+		switch {
+		case r.Method == http.MethodGet && op == "":
+			result := []interface{}{}
+			for _, tag := range s.tags {
+				result = append(result, renderFakeTag(tag))
+			}
+			return result, nil
+		case r.Method == http.MethodPost && op == "":
+			return s.createTag(r.Form)
+		}
+		return nil, fakeMethodNotAllowed(r, op)
+	}
+	index := -1
+	for i, tag := range s.tags {
+		if tag.Name == path[0] {
+			index = i
+		}
+	}
+	if index < 0 || len(path) > 1 {
+		return nil, fakeNotFound
+	}
+	tag := s.tags[index]
+	switch {
+	case r.Method == http.MethodGet && op == "":
+		return renderFakeTag(tag), nil
+	case r.Method == http.MethodGet && (op == "machines" || op == "nodes"):
+		result := []interface{}{}
+		for _, id := range s.machineIDs {
+			if m := s.machines[id]; contains(m.Tags, tag.Name) {
+				rendered := m.render()
+				rendered["node_type_name"] = "Machine"
+				result = append(result, rendered)
+			}
+		}
+		return result, nil
+	case r.Method == http.MethodPost && op == "update_nodes":
+		return s.updateTagNodes(tag, r.Form)
+	case r.Method == http.MethodPut:
+		return s.updateTag(tag, r.Form)
+	case r.Method == http.MethodDelete:
+		for _, m := range s.machines {
+			m.Tags = fakeWithout(m.Tags, tag.Name)
+		}
+		s.tags = append(s.tags[:index], s.tags[index+1:]...)
+		return nil, nil
+	}
+	return nil, fakeMethodNotAllowed(r, op)
+}
+
+func (s *FakeServer) createTag(form url.Values) (interface{}, *fakeError) {
+	name := form.Get("name")
+	if name == "" {
+		return nil, newFakeError(http.StatusBadRequest, `{"name": ["This field is required."]}`)
+	}
+	for _, tag := range s.tags {
+		if tag.Name == name {
+			return nil, newFakeError(http.StatusBadRequest, `{"name": ["Tag with this Name already exists."]}`)
+		}
+	}
+	tag := &FakeTag{
+		Name:       name,
+		Comment:    form.Get("comment"),
+		Definition: form.Get("definition"),
+		KernelOpts: form.Get("kernel_opts"),
+	}
+	s.tags = append(s.tags, tag)
+	return renderFakeTag(tag), nil
+}
+
+func (s *FakeServer) updateTag(tag *FakeTag, form url.Values) (interface{}, *fakeError) {
+	if name := form.Get("name"); name != "" && name != tag.Name {
+		for _, other := range s.tags {
+			if other.Name == name {
+				return nil, newFakeError(http.StatusBadRequest, `{"name": ["Tag with this Name already exists."]}`)
+			}
+		}
+		for _, m := range s.machines {
+			if contains(m.Tags, tag.Name) {
+				m.Tags = append(fakeWithout(m.Tags, tag.Name), name)
+			}
+		}
+		tag.Name = name
+	}
+	for key, value := range map[string]*string{
+		"comment":     &tag.Comment,
+		"definition":  &tag.Definition,
+		"kernel_opts": &tag.KernelOpts,
+	} {
+		if _, ok := form[key]; ok {
+			*value = form.Get(key)
+		}
+	}
+	return renderFakeTag(tag), nil
+}
+
+func (s *FakeServer) updateTagNodes(tag *FakeTag, form url.Values) (interface{}, *fakeError) {
+	if definition, ok := form["definition"]; ok && definition[0] != tag.Definition {
+		return nil, newFakeError(http.StatusConflict, "Definition supplied '%s' doesn't match current definition '%s'", definition[0], tag.Definition)
+	}
+	added, removed := 0, 0
+	for _, id := range form["add"] {
+		if m, found := s.machines[id]; found && !contains(m.Tags, tag.Name) {
+			m.Tags = append(m.Tags, tag.Name)
+			m.updated = time.Now()
+			added++
+		}
+	}
+	for _, id := range form["remove"] {
+		if m, found := s.machines[id]; found && contains(m.Tags, tag.Name) {
+			m.Tags = fakeWithout(m.Tags, tag.Name)
+			m.updated = time.Now()
+			removed++
+		}
+	}
+	return map[string]interface{}{"added": added, "removed": removed}, nil
+}
+
+// renderFakeTag returns the tag as MAAS returns it.
+func renderFakeTag(tag *FakeTag) map[string]interface{} {
+	return map[string]interface{}{
+		"resource_uri": fmt.Sprintf("%stags/%s/", fakeAPIPath, tag.Name),
+		"name":         tag.Name,
+		"comment":      tag.Comment,
+		"definition":   tag.Definition,
+		"kernel_opts":  tag.KernelOpts,
+	}
+}
+
+func (s *FakeServer) vmHostsHandler(r *http.Request, op string, path []string) (interface{}, *fakeError) {
+	if r.Method != http.MethodGet || op != "" {
+		return nil, fakeMethodNotAllowed(r, op)
+	}
+	if len(path) == 0 {
+		result := []interface{}{}
+		for _, vmHost := range s.vmHosts {
+			result = append(result, renderFakeVMHost(vmHost))
+		}
+		return result, nil
+	}
+	for _, vmHost := range s.vmHosts {
+		if strconv.Itoa(vmHost.ID) == path[0] && len(path) == 1 {
+			return renderFakeVMHost(vmHost), nil
+		}
+	}
+	return nil, fakeNotFound
+}
+
+// renderFakeVMHost returns the VM host as MAAS returns it.
+func renderFakeVMHost(vmHost *FakeVMHost) map[string]interface{} {
+	resources := func(cores, memory, localStorage int) map[string]interface{} {
+		return map[string]interface{}{
+			"cores":         cores,
+			"memory":        memory,
+			"local_storage": localStorage,
+		}
+	}
+	return map[string]interface{}{
+		"resource_uri":  fmt.Sprintf("%spods/%d/", fakeAPIPath, vmHost.ID),
+		"id":            vmHost.ID,
+		"name":          vmHost.Name,
+		"type":          vmHost.Type,
+		"architectures": []string{"amd64/generic"},
+		"capabilities":  []string{"composable", "dynamic_local_storage"},
+		"tags":          fakeStrings(vmHost.Tags),
+		"zone":          renderFakeZone(vmHost.Zone),
+		"pool":          renderFakePool(vmHost.Pool),
+		"total":         resources(vmHost.Cores, vmHost.Memory, vmHost.LocalStorage),
+		"used":          resources(0, 0, 0),
+		"available":     resources(vmHost.Cores, vmHost.Memory, vmHost.LocalStorage),
+	}
+}
+
+// fakeStrings makes sure that empty lists are encoded as such rather than
+// as null, since MAAS always returns lists.
+func fakeStrings(values []string) []string {
+	if values == nil {
+		return []string{}
+	}
+	result := append([]string(nil), values...)
+	sort.Strings(result)
+	return result
+}
+
+func fakeContainsAll(values, wanted []string) bool {
+	for _, want := range wanted {
+		if !contains(values, want) {
+			return false
+		}
+	}
+	return true
+}
+
+func fakeContainsAny(values, unwanted []string) bool {
+	for _, v := range unwanted {
+		if contains(values, v) {
+			return true
+		}
+	}
+	return false
+}
+
+func fakeWithout(values []string, value string) []string {
+	var result []string
+	for _, v := range values {
+		if v != value {
+			result = append(result, v)
+		}
+	}
+	return result
+}
@@ -0,0 +1,239 @@
+// Copyright 2022 Canonical Ltd.
+// Licensed under the LGPLv3, see LICENCE file for details.
+
+package gomaasapi
+
+import (
+	"net/http"
+
+	"github.com/juju/errors"
+	"github.com/juju/testing"
+	jc "github.com/juju/testing/checkers"
+	gc "gopkg.in/check.v1"
+)
+
+type fakeServerSuite struct {
+	testing.LoggingCleanupSuite
+}
+
+var _ = gc.Suite(&fakeServerSuite{})
+
+func (s *fakeServerSuite) newController(c *gc.C, fixtures FakeFixtures) (*FakeServer, Controller) {
+	server := NewFakeServer(fixtures)
+	s.AddCleanup(func(*gc.C) { server.Close() })
+	controller, err := NewFakeController(server)
+	c.Assert(err, jc.ErrorIsNil)
+	return server, controller
+}
+
+func (s *fakeServerSuite) TestMachines(c *gc.C) {
+	_, controller := s.newController(c, FakeFixtures{
+		Machines: []FakeMachine{
+			{SystemID: "a", Hostname: "alpha", Tags: []string{"gpu"}},
+			{SystemID: "b", Zone: "east", Status: "Deployed"},
+		},
+	})
+	machines, err := controller.Machines(MachinesArgs{})
+	c.Assert(err, jc.ErrorIsNil)
+	c.Assert(machines, gc.HasLen, 2)
+	c.Check(machines[0].SystemID(), gc.Equals, "a")
+	c.Check(machines[0].Hostname(), gc.Equals, "alpha")
+	c.Check(machines[0].Tags(), jc.DeepEquals, []string{"gpu"})
+	c.Check(machines[0].StatusName(), gc.Equals, "Ready")
+	c.Check(machines[1].Hostname(), gc.Equals, "machine-b")
+	c.Check(machines[1].Zone().Name(), gc.Equals, "east")
+	c.Check(machines[1].PowerState(), gc.Equals, "on")
+
+	machines, err = controller.Machines(MachinesArgs{Zone: "east"})
+	c.Assert(err, jc.ErrorIsNil)
+	c.Assert(machines, gc.HasLen, 1)
+	c.Check(machines[0].SystemID(), gc.Equals, "b")
+
+	machines, err = controller.Machines(MachinesArgs{SystemIDs: []string{"a", "missing"}, Tags: []string{"gpu"}})
+	c.Assert(err, jc.ErrorIsNil)
+	c.Assert(machines, gc.HasLen, 1)
+	c.Check(machines[0].SystemID(), gc.Equals, "a")
+}
+
+func (s *fakeServerSuite) TestAllocateDeployRelease(c *gc.C) {
+	server, controller := s.newController(c, FakeFixtures{
+		Machines: []FakeMachine{
+			{SystemID: "small", CPUCount: 2},
+			{SystemID: "big", CPUCount: 16, Tags: []string{"gpu"}},
+		},
+	})
+
+	machine, _, err := controller.AllocateMachine(AllocateMachineArgs{MinCPUCount: 8, AgentName: "juju"})
+	c.Assert(err, jc.ErrorIsNil)
+	c.Check(machine.SystemID(), gc.Equals, "big")
+	c.Check(machine.StatusName(), gc.Equals, "Allocated")
+	state, found := server.Machine("big")
+	c.Assert(found, jc.IsTrue)
+	c.Check(state.Owner, gc.Equals, FakeServerUser)
+	c.Check(state.AgentName, gc.Equals, "juju")
+
+	// The only machine with 8 CPUs is taken.
+	_, _, err = controller.AllocateMachine(AllocateMachineArgs{MinCPUCount: 8})
+	c.Check(err, jc.Satisfies, IsNoMatchError)
+
+	err = machine.Start(StartArgs{DistroSeries: "focal"})
+	c.Assert(err, jc.ErrorIsNil)
+	c.Check(machine.StatusName(), gc.Equals, "Deployed")
+	c.Check(machine.PowerState(), gc.Equals, "on")
+	c.Check(machine.DistroSeries(), gc.Equals, "focal")
+
+	// Deployed machines can't be deployed again.
+	err = machine.Start(StartArgs{})
+	c.Check(err, jc.Satisfies, IsBadRequestError)
+
+	err = machine.SetOwnerData(map[string]string{"model": "default"})
+	c.Assert(err, jc.ErrorIsNil)
+	machines, err := controller.Machines(MachinesArgs{OwnerData: map[string]string{"model": "default"}})
+	c.Assert(err, jc.ErrorIsNil)
+	c.Check(machines, gc.HasLen, 1)
+
+	err = controller.ReleaseMachines(ReleaseMachinesArgs{SystemIDs: []string{"big"}})
+	c.Assert(err, jc.ErrorIsNil)
+	state, _ = server.Machine("big")
+	c.Check(state.Status, gc.Equals, "Ready")
+	c.Check(state.Owner, gc.Equals, "")
+	c.Check(state.OwnerData, gc.HasLen, 0)
+	c.Check(state.PowerState, gc.Equals, "off")
+
+	err = controller.ReleaseMachines(ReleaseMachinesArgs{SystemIDs: []string{"big"}})
+	c.Check(err, jc.Satisfies, IsCannotCompleteError)
+	err = controller.ReleaseMachines(ReleaseMachinesArgs{SystemIDs: []string{"missing"}})
+	c.Check(err, jc.Satisfies, IsBadRequestError)
+}
+
+func (s *fakeServerSuite) TestAllocateConstraints(c *gc.C) {
+	_, controller := s.newController(c, FakeFixtures{
+		Machines: []FakeMachine{
+			{SystemID: "a", Zone: "west", Tags: []string{"gpu"}},
+			{SystemID: "b", Zone: "east", Tags: []string{"gpu", "ssd"}},
+			{SystemID: "c", Zone: "east", Architecture: "arm64/generic"},
+		},
+	})
+	for _, test := range []struct {
+		args     AllocateMachineArgs
+		expected string
+	}{
+		{AllocateMachineArgs{Zone: "east"}, "b"},
+		{AllocateMachineArgs{NotTags: []string{"ssd"}, NotInZone: []string{"west"}}, "c"},
+		{AllocateMachineArgs{Tags: []string{"gpu"}}, "a"},
+		{AllocateMachineArgs{Architecture: "arm64"}, "c"},
+		{AllocateMachineArgs{Hostname: "machine-a"}, "a"},
+	} {
+		c.Logf("%+v", test.args)
+		args := test.args
+		args.DryRun = true
+		machine, _, err := controller.AllocateMachine(args)
+		c.Assert(err, jc.ErrorIsNil)
+		c.Check(machine.SystemID(), gc.Equals, test.expected)
+	}
+	_, _, err := controller.AllocateMachine(AllocateMachineArgs{
+		Storage: []StorageSpec{{Size: 10}},
+	})
+	c.Check(err, gc.ErrorMatches, `(?s).*constraint "storage" isn't supported by the fake.*`)
+}
+
+func (s *fakeServerSuite) TestRecoverFromFailedDeployment(c *gc.C) {
+	server, controller := s.newController(c, FakeFixtures{
+		Machines: []FakeMachine{{SystemID: "a"}},
+	})
+	machine, _, err := controller.AllocateMachine(AllocateMachineArgs{})
+	c.Assert(err, jc.ErrorIsNil)
+	c.Assert(server.SetMachineStatus("a", "Failed deployment"), jc.IsTrue)
+	machines, err := controller.Machines(MachinesArgs{})
+	c.Assert(err, jc.ErrorIsNil)
+	machine = machines[0]
+
+	plan, err := machine.RecoverFromFailure(RecoverFromFailureArgs{})
+	c.Assert(err, jc.ErrorIsNil)
+	c.Check(plan, jc.DeepEquals, []RecoveryAction{RecoveryActionRelease})
+	c.Check(machine.StatusName(), gc.Equals, "Ready")
+}
+
+func (s *fakeServerSuite) TestSubnets(c *gc.C) {
+	server, controller := s.newController(c, FakeFixtures{
+		Subnets: []FakeSubnet{{CIDR: "10.0.0.0/24", GatewayIP: "10.0.0.1"}},
+	})
+	subnets, err := controller.Subnets()
+	c.Assert(err, jc.ErrorIsNil)
+	c.Assert(subnets, gc.HasLen, 1)
+	c.Check(subnets[0].CIDR(), gc.Equals, "10.0.0.0/24")
+	c.Check(subnets[0].Name(), gc.Equals, "10.0.0.0/24")
+	c.Check(subnets[0].Gateway(), gc.Equals, "10.0.0.1")
+	c.Check(subnets[0].VLAN().VID(), gc.Equals, 0)
+
+	subnet, err := controller.CreateSubnet(CreateSubnetArgs{CIDR: "192.168.0.0/16", Name: "lab"})
+	c.Assert(err, jc.ErrorIsNil)
+	c.Check(subnet.Name(), gc.Equals, "lab")
+	_, err = controller.CreateSubnet(CreateSubnetArgs{CIDR: "192.168.0.0/16"})
+	c.Check(err, jc.Satisfies, IsBadRequestError)
+	c.Check(server.Subnets(), gc.HasLen, 2)
+
+	err = subnet.Delete()
+	c.Assert(err, jc.ErrorIsNil)
+	c.Check(server.Subnets(), gc.HasLen, 1)
+}
+
+func (s *fakeServerSuite) TestTags(c *gc.C) {
+	server, controller := s.newController(c, FakeFixtures{
+		Machines: []FakeMachine{{SystemID: "a"}, {SystemID: "b", Tags: []string{"gpu"}}},
+		Tags:     []FakeTag{{Name: "gpu", Comment: "graphics"}},
+	})
+	tags, err := controller.Tags()
+	c.Assert(err, jc.ErrorIsNil)
+	c.Assert(tags, gc.HasLen, 1)
+	c.Check(tags[0].Comment(), gc.Equals, "graphics")
+
+	tag, err := controller.CreateTag(CreateTagArgs{Name: "ssd"})
+	c.Assert(err, jc.ErrorIsNil)
+	_, err = controller.CreateTag(CreateTagArgs{Name: "ssd"})
+	c.Check(err, jc.Satisfies, IsBadRequestError)
+
+	added, removed, err := tag.UpdateNodes(UpdateTagNodesArgs{Add: []string{"a", "b"}})
+	c.Assert(err, jc.ErrorIsNil)
+	c.Check(added, gc.Equals, 2)
+	c.Check(removed, gc.Equals, 0)
+	machines, err := tag.Machines()
+	c.Assert(err, jc.ErrorIsNil)
+	c.Check(machines, gc.HasLen, 2)
+	state, _ := server.Machine("b")
+	c.Check(state.Tags, jc.DeepEquals, []string{"gpu", "ssd"})
+
+	err = tags[0].Delete()
+	c.Assert(err, jc.ErrorIsNil)
+	state, _ = server.Machine("b")
+	c.Check(state.Tags, jc.DeepEquals, []string{"ssd"})
+	c.Check(server.Tags(), gc.HasLen, 1)
+}
+
+func (s *fakeServerSuite) TestVMHosts(c *gc.C) {
+	_, controller := s.newController(c, FakeFixtures{
+		VMHosts: []FakeVMHost{{Name: "kvm01", Cores: 32, Memory: 65536, Tags: []string{"pod"}}},
+	})
+	vmHosts, err := controller.VMHosts()
+	c.Assert(err, jc.ErrorIsNil)
+	c.Assert(vmHosts, gc.HasLen, 1)
+	c.Check(vmHosts[0].Name(), gc.Equals, "kvm01")
+	c.Check(vmHosts[0].Type(), gc.Equals, "lxd")
+	c.Check(vmHosts[0].Tags(), jc.DeepEquals, []string{"pod"})
+
+	vmHost, err := controller.VMHost(vmHosts[0].ID())
+	c.Assert(err, jc.ErrorIsNil)
+	c.Check(vmHost.Name(), gc.Equals, "kvm01")
+	_, err = controller.VMHost(404)
+	c.Check(err, jc.Satisfies, IsNoMatchError)
+}
+
+func (s *fakeServerSuite) TestUnsupportedRequest(c *gc.C) {
+	_, controller := s.newController(c, FakeFixtures{})
+	_, err := controller.Zones()
+	c.Check(err, gc.NotNil)
+	_, err = controller.Do("POST", "machines", "accept_all", nil)
+	svrErr, ok := GetServerError(errors.Cause(err))
+	c.Assert(ok, jc.IsTrue)
+	c.Check(svrErr.StatusCode, gc.Equals, http.StatusMethodNotAllowed)
+}
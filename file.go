@@ -40,9 +40,9 @@ func (f *file) Delete() error {
 		if svrErr, ok := errors.Cause(err).(ServerError); ok {
 			switch svrErr.StatusCode {
 			case http.StatusNotFound:
-				return errors.Wrap(err, NewNoMatchError(svrErr.BodyMessage))
+				return wrapError(err, NewNoMatchError(svrErr.BodyMessage))
 			case http.StatusForbidden:
-				return errors.Wrap(err, NewPermissionError(svrErr.BodyMessage))
+				return wrapError(err, NewPermissionError(svrErr.BodyMessage))
 			}
 		}
 		return NewUnexpectedError(err)
@@ -71,9 +71,9 @@ func (f *file) readFromServer() ([]byte, error) {
 		if svrErr, ok := errors.Cause(err).(ServerError); ok {
 			switch svrErr.StatusCode {
 			case http.StatusNotFound:
-				return nil, errors.Wrap(err, NewNoMatchError(svrErr.BodyMessage))
+				return nil, wrapError(err, NewNoMatchError(svrErr.BodyMessage))
 			case http.StatusForbidden:
-				return nil, errors.Wrap(err, NewPermissionError(svrErr.BodyMessage))
+				return nil, wrapError(err, NewPermissionError(svrErr.BodyMessage))
 			}
 		}
 		return nil, NewUnexpectedError(err)
@@ -5,12 +5,28 @@ package gomaasapi
 
 import "github.com/juju/schema"
 
+// FileSystemType is the type of filesystem that a storage device can be
+// formatted with.
+type FileSystemType string
+
+// The filesystem types that MAAS can format storage devices with.
+const (
+	FileSystemExt2    FileSystemType = "ext2"
+	FileSystemExt4    FileSystemType = "ext4"
+	FileSystemXFS     FileSystemType = "xfs"
+	FileSystemBtrfs   FileSystemType = "btrfs"
+	FileSystemSwap    FileSystemType = "swap"
+	FileSystemFAT32   FileSystemType = "fat32"
+	FileSystemVFAT    FileSystemType = "vfat"
+	FileSystemZFSRoot FileSystemType = "zfsroot"
+)
+
 type filesystem struct {
-	fstype     string
-	mountPoint string
-	label      string
-	uuid       string
-	// no idea what the mount_options are as a value type, so ignoring for now.
+	fstype       string
+	mountPoint   string
+	mountOptions string
+	label        string
+	uuid         string
 }
 
 // Type implements FileSystem.
@@ -23,6 +39,11 @@ func (f *filesystem) MountPoint() string {
 	return f.mountPoint
 }
 
+// MountOptions implements FileSystem.
+func (f *filesystem) MountOptions() string {
+	return f.mountOptions
+}
+
 // Label implements FileSystem.
 func (f *filesystem) Label() string {
 	return f.label
@@ -38,16 +59,16 @@ func (f *filesystem) UUID() string {
 
 func filesystem2_0(source map[string]interface{}) (*filesystem, error) {
 	fields := schema.Fields{
-		"fstype":      schema.String(),
-		"mount_point": schema.OneOf(schema.Nil(""), schema.String()),
-		"label":       schema.OneOf(schema.Nil(""), schema.String()),
-		"uuid":        schema.String(),
-		// TODO: mount_options when we know the type (note it can be
-		// nil).
+		"fstype":        schema.String(),
+		"mount_point":   schema.OneOf(schema.Nil(""), schema.String()),
+		"mount_options": schema.OneOf(schema.Nil(""), schema.String()),
+		"label":         schema.OneOf(schema.Nil(""), schema.String()),
+		"uuid":          schema.String(),
 	}
 	defaults := schema.Defaults{
-		"mount_point": "",
-		"label":       "",
+		"mount_point":   "",
+		"mount_options": "",
+		"label":         "",
 	}
 	checker := schema.FieldMap(fields, defaults)
 	coerced, err := checker.Coerce(source, nil)
@@ -58,12 +79,14 @@ func filesystem2_0(source map[string]interface{}) (*filesystem, error) {
 	// From here we know that the map returned from the schema coercion
 	// contains fields of the right type.
 	mount_point, _ := valid["mount_point"].(string)
+	mountOptions, _ := valid["mount_options"].(string)
 	label, _ := valid["label"].(string)
 	result := &filesystem{
-		fstype:     valid["fstype"].(string),
-		mountPoint: mount_point,
-		label:      label,
-		uuid:       valid["uuid"].(string),
+		fstype:       valid["fstype"].(string),
+		mountPoint:   mount_point,
+		mountOptions: mountOptions,
+		label:        label,
+		uuid:         valid["uuid"].(string),
 	}
 	return result, nil
 }
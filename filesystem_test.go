@@ -14,30 +14,34 @@ var _ = gc.Suite(&filesystemSuite{})
 
 func (*filesystemSuite) TestParse2_0(c *gc.C) {
 	source := map[string]interface{}{
-		"fstype":      "ext4",
-		"mount_point": "/",
-		"label":       "root",
-		"uuid":        "fake-uuid",
+		"fstype":        "ext4",
+		"mount_point":   "/",
+		"mount_options": "noatime,errors=remount-ro",
+		"label":         "root",
+		"uuid":          "fake-uuid",
 	}
 	fs, err := filesystem2_0(source)
 	c.Assert(err, jc.ErrorIsNil)
 	c.Check(fs.Type(), gc.Equals, "ext4")
 	c.Check(fs.MountPoint(), gc.Equals, "/")
+	c.Check(fs.MountOptions(), gc.Equals, "noatime,errors=remount-ro")
 	c.Check(fs.Label(), gc.Equals, "root")
 	c.Check(fs.UUID(), gc.Equals, "fake-uuid")
 }
 
 func (*filesystemSuite) TestParse2_Defaults(c *gc.C) {
 	source := map[string]interface{}{
-		"fstype":      "ext4",
-		"mount_point": nil,
-		"label":       nil,
-		"uuid":        "fake-uuid",
+		"fstype":        "ext4",
+		"mount_point":   nil,
+		"mount_options": nil,
+		"label":         nil,
+		"uuid":          "fake-uuid",
 	}
 	fs, err := filesystem2_0(source)
 	c.Assert(err, jc.ErrorIsNil)
 	c.Check(fs.Type(), gc.Equals, "ext4")
 	c.Check(fs.MountPoint(), gc.Equals, "")
+	c.Check(fs.MountOptions(), gc.Equals, "")
 	c.Check(fs.Label(), gc.Equals, "")
 	c.Check(fs.UUID(), gc.Equals, "fake-uuid")
 }
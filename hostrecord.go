@@ -0,0 +1,91 @@
+// Copyright 2022 Canonical Ltd.
+// Licensed under the LGPLv3, see LICENCE file for details.
+
+package gomaasapi
+
+import (
+	"strings"
+)
+
+// HostRecord is an address of a machine with the DNS names that resolve to
+// it, like a line of an /etc/hosts file.
+type HostRecord struct {
+	Address string
+	Names   []string
+}
+
+// String returns the record in /etc/hosts format.
+func (r HostRecord) String() string {
+	return strings.Join(append([]string{r.Address}, r.Names...), " ")
+}
+
+// HostRecordNames returns the distinct names of the records, in the order
+// they first appear. The result is suitable for the subject alternative
+// names of a certificate.
+func HostRecordNames(records []HostRecord) []string {
+	var result []string
+	seen := make(map[string]bool)
+	for _, record := range records {
+		for _, name := range record.Names {
+			if !seen[name] {
+				seen[name] = true
+				result = append(result, name)
+			}
+		}
+	}
+	return result
+}
+
+// hostRecords collects the names of the addresses of a machine, keeping the
+// addresses in the order they are added.
+type hostRecords struct {
+	addresses []string
+	names     map[string][]string
+}
+
+func newHostRecords() *hostRecords {
+	return &hostRecords{names: make(map[string][]string)}
+}
+
+func (h *hostRecords) has(address string) bool {
+	_, found := h.names[address]
+	return found
+}
+
+func (h *hostRecords) add(address, name string) {
+	if address == "" {
+		return
+	}
+	names, found := h.names[address]
+	if !found {
+		h.addresses = append(h.addresses, address)
+	}
+	for _, existing := range names {
+		if existing == name {
+			return
+		}
+	}
+	h.names[address] = append(names, name)
+}
+
+func (h *hostRecords) records() []HostRecord {
+	result := make([]HostRecord, len(h.addresses))
+	for i, address := range h.addresses {
+		result[i] = HostRecord{Address: address, Names: h.names[address]}
+	}
+	return result
+}
+
+// interfaceDNSLabel converts an interface name into the label MAAS uses for
+// the interface in DNS, such as "eth0-100" for "eth0.100".
+func interfaceDNSLabel(name string) string {
+	return strings.Map(func(r rune) rune {
+		switch {
+		case r >= 'a' && r <= 'z', r >= '0' && r <= '9', r == '-':
+			return r
+		case r >= 'A' && r <= 'Z':
+			return r - 'A' + 'a'
+		}
+		return '-'
+	}, name)
+}
@@ -0,0 +1,55 @@
+// Copyright 2022 Canonical Ltd.
+// Licensed under the LGPLv3, see LICENCE file for details.
+
+package gomaasapi
+
+import (
+	jc "github.com/juju/testing/checkers"
+	gc "gopkg.in/check.v1"
+)
+
+type hostRecordSuite struct{}
+
+var _ = gc.Suite(&hostRecordSuite{})
+
+func (*hostRecordSuite) TestString(c *gc.C) {
+	record := HostRecord{Address: "10.0.0.1", Names: []string{"a.maas", "b.maas"}}
+	c.Assert(record.String(), gc.Equals, "10.0.0.1 a.maas b.maas")
+}
+
+func (*hostRecordSuite) TestHostRecordNames(c *gc.C) {
+	names := HostRecordNames([]HostRecord{
+		{Address: "10.0.0.1", Names: []string{"a.maas", "b.maas"}},
+		{Address: "10.0.0.2", Names: []string{"c.maas", "a.maas"}},
+	})
+	c.Assert(names, jc.DeepEquals, []string{"a.maas", "b.maas", "c.maas"})
+	c.Assert(HostRecordNames(nil), gc.HasLen, 0)
+}
+
+func (*hostRecordSuite) TestHostRecordsAdd(c *gc.C) {
+	records := newHostRecords()
+	records.add("10.0.0.2", "b.maas")
+	records.add("10.0.0.1", "a.maas")
+	records.add("10.0.0.2", "b.maas")
+	records.add("10.0.0.2", "c.maas")
+	records.add("", "d.maas")
+	c.Check(records.has("10.0.0.1"), jc.IsTrue)
+	c.Check(records.has("10.0.0.3"), jc.IsFalse)
+	c.Assert(records.records(), jc.DeepEquals, []HostRecord{
+		{Address: "10.0.0.2", Names: []string{"b.maas", "c.maas"}},
+		{Address: "10.0.0.1", Names: []string{"a.maas"}},
+	})
+}
+
+func (*hostRecordSuite) TestInterfaceDNSLabel(c *gc.C) {
+	for _, test := range []struct {
+		name  string
+		label string
+	}{
+		{"eth0", "eth0"},
+		{"eth0.100", "eth0-100"},
+		{"br_Ex", "br-ex"},
+	} {
+		c.Check(interfaceDNSLabel(test.name), gc.Equals, test.label)
+	}
+}
@@ -24,14 +24,21 @@ type interface_ struct {
 	enabled bool
 	tags    []string
 
-	vlan  *vlan
-	links []*link
+	vlan       *vlan
+	links      []*link
+	discovered []*discoveredLink
 
 	macAddress   string
 	effectiveMTU int
 
+	linkConnected  bool
+	linkSpeed      int
+	interfaceSpeed int
+
 	parents  []string
 	children []string
+
+	params *interfaceParams
 }
 
 func (i *interface_) updateFrom(other *interface_) {
@@ -43,10 +50,15 @@ func (i *interface_) updateFrom(other *interface_) {
 	i.tags = other.tags
 	i.vlan = other.vlan
 	i.links = other.links
+	i.discovered = other.discovered
 	i.macAddress = other.macAddress
 	i.effectiveMTU = other.effectiveMTU
+	i.linkConnected = other.linkConnected
+	i.linkSpeed = other.linkSpeed
+	i.interfaceSpeed = other.interfaceSpeed
 	i.parents = other.parents
 	i.children = other.children
+	i.params = other.params
 }
 
 // ID implements Interface.
@@ -89,14 +101,27 @@ func (i *interface_) VLAN() VLAN {
 	if i.vlan == nil {
 		return nil
 	}
+	i.vlan.controller = i.controller
 	return i.vlan
 }
 
 // Links implements Interface.
 func (i *interface_) Links() []Link {
 	result := make([]Link, len(i.links))
-	for i, link := range i.links {
-		result[i] = link
+	for index, link := range i.links {
+		if link.subnet != nil {
+			link.subnet.controller = i.controller
+		}
+		result[index] = link
+	}
+	return result
+}
+
+// Discovered implements Interface.
+func (i *interface_) Discovered() []DiscoveredLink {
+	result := make([]DiscoveredLink, len(i.discovered))
+	for i, discovered := range i.discovered {
+		result[i] = discovered
 	}
 	return result
 }
@@ -111,6 +136,21 @@ func (i *interface_) EffectiveMTU() int {
 	return i.effectiveMTU
 }
 
+// LinkConnected implements Interface.
+func (i *interface_) LinkConnected() bool {
+	return i.linkConnected
+}
+
+// LinkSpeed implements Interface.
+func (i *interface_) LinkSpeed() int {
+	return i.linkSpeed
+}
+
+// InterfaceSpeed implements Interface.
+func (i *interface_) InterfaceSpeed() int {
+	return i.interfaceSpeed
+}
+
 // UpdateInterfaceArgs is an argument struct for calling Interface.Update.
 type UpdateInterfaceArgs struct {
 	Name       string
@@ -125,6 +165,14 @@ func (a *UpdateInterfaceArgs) vlanID() int {
 	return a.VLAN.ID()
 }
 
+// Params implements Interface.
+func (i *interface_) Params() InterfaceParams {
+	if i.params == nil {
+		return nil
+	}
+	return i.params
+}
+
 // Update implements Interface.
 func (i *interface_) Update(args UpdateInterfaceArgs) error {
 	var empty UpdateInterfaceArgs
@@ -140,15 +188,15 @@ func (i *interface_) Update(args UpdateInterfaceArgs) error {
 		if svrErr, ok := errors.Cause(err).(ServerError); ok {
 			switch svrErr.StatusCode {
 			case http.StatusNotFound:
-				return errors.Wrap(err, NewNoMatchError(svrErr.BodyMessage))
+				return wrapError(err, NewNoMatchError(svrErr.BodyMessage))
 			case http.StatusForbidden:
-				return errors.Wrap(err, NewPermissionError(svrErr.BodyMessage))
+				return wrapError(err, NewPermissionError(svrErr.BodyMessage))
 			}
 		}
 		return NewUnexpectedError(err)
 	}
 
-	response, err := readInterface(i.controller.apiVersion, source)
+	response, err := readInterface(i.controller.schemaVersion(), source)
 	if err != nil {
 		return errors.Trace(err)
 	}
@@ -163,9 +211,9 @@ func (i *interface_) Delete() error {
 		if svrErr, ok := errors.Cause(err).(ServerError); ok {
 			switch svrErr.StatusCode {
 			case http.StatusNotFound:
-				return errors.Wrap(err, NewNoMatchError(svrErr.BodyMessage))
+				return wrapError(err, NewNoMatchError(svrErr.BodyMessage))
 			case http.StatusForbidden:
-				return errors.Wrap(err, NewPermissionError(svrErr.BodyMessage))
+				return wrapError(err, NewPermissionError(svrErr.BodyMessage))
 			}
 		}
 		return NewUnexpectedError(err)
@@ -248,17 +296,17 @@ func (i *interface_) LinkSubnet(args LinkSubnetArgs) error {
 		if svrErr, ok := errors.Cause(err).(ServerError); ok {
 			switch svrErr.StatusCode {
 			case http.StatusNotFound, http.StatusBadRequest:
-				return errors.Wrap(err, NewBadRequestError(svrErr.BodyMessage))
+				return wrapError(err, NewBadRequestError(svrErr.BodyMessage))
 			case http.StatusForbidden:
-				return errors.Wrap(err, NewPermissionError(svrErr.BodyMessage))
+				return wrapError(err, NewPermissionError(svrErr.BodyMessage))
 			case http.StatusServiceUnavailable:
-				return errors.Wrap(err, NewCannotCompleteError(svrErr.BodyMessage))
+				return wrapError(err, NewCannotCompleteError(svrErr.BodyMessage))
 			}
 		}
 		return NewUnexpectedError(err)
 	}
 
-	response, err := readInterface(i.controller.apiVersion, source)
+	response, err := readInterface(i.controller.schemaVersion(), source)
 	if err != nil {
 		return errors.Trace(err)
 	}
@@ -291,15 +339,15 @@ func (i *interface_) UnlinkSubnet(subnet Subnet) error {
 		if svrErr, ok := errors.Cause(err).(ServerError); ok {
 			switch svrErr.StatusCode {
 			case http.StatusNotFound, http.StatusBadRequest:
-				return errors.Wrap(err, NewBadRequestError(svrErr.BodyMessage))
+				return wrapError(err, NewBadRequestError(svrErr.BodyMessage))
 			case http.StatusForbidden:
-				return errors.Wrap(err, NewPermissionError(svrErr.BodyMessage))
+				return wrapError(err, NewPermissionError(svrErr.BodyMessage))
 			}
 		}
 		return NewUnexpectedError(err)
 	}
 
-	response, err := readInterface(i.controller.apiVersion, source)
+	response, err := readInterface(i.controller.schemaVersion(), source)
 	if err != nil {
 		return errors.Trace(err)
 	}
@@ -308,6 +356,15 @@ func (i *interface_) UnlinkSubnet(subnet Subnet) error {
 	return nil
 }
 
+func (i *interface_) hasLink(id int) bool {
+	for _, link := range i.links {
+		if link.ID() == id {
+			return true
+		}
+	}
+	return false
+}
+
 func readInterface(controllerVersion version.Number, source interface{}) (*interface_, error) {
 	readFunc, err := getInterfaceDeserializationFunc(controllerVersion)
 	if err != nil {
@@ -383,17 +440,30 @@ func interface_2_0(source map[string]interface{}) (*interface_, error) {
 		"enabled": schema.Bool(),
 		"tags":    schema.OneOf(schema.Nil(""), schema.List(schema.String())),
 
-		"vlan":  schema.OneOf(schema.Nil(""), schema.StringMap(schema.Any())),
-		"links": schema.List(schema.StringMap(schema.Any())),
+		"vlan":       schema.OneOf(schema.Nil(""), schema.StringMap(schema.Any())),
+		"links":      schema.List(schema.StringMap(schema.Any())),
+		"discovered": schema.OneOf(schema.Nil(""), schema.List(schema.StringMap(schema.Any()))),
 
 		"mac_address":   schema.OneOf(schema.Nil(""), schema.String()),
 		"effective_mtu": schema.ForceInt(),
 
+		"link_connected":  schema.Bool(),
+		"link_speed":      schema.ForceInt(),
+		"interface_speed": schema.ForceInt(),
+
 		"parents":  schema.List(schema.String()),
 		"children": schema.List(schema.String()),
+
+		"params": schema.Any(),
 	}
 	defaults := schema.Defaults{
 		"mac_address": "",
+		"params":      schema.Omit,
+		"discovered":  nil,
+
+		"link_connected":  true,
+		"link_speed":      0,
+		"interface_speed": 0,
 	}
 	checker := schema.FieldMap(fields, defaults)
 	coerced, err := checker.Coerce(source, nil)
@@ -417,6 +487,19 @@ func interface_2_0(source map[string]interface{}) (*interface_, error) {
 	if err != nil {
 		return nil, errors.Trace(err)
 	}
+	var discovered []*discoveredLink
+	if discoveredList, ok := valid["discovered"].([]interface{}); ok {
+		discovered, err = readDiscoveredLinkList(discoveredList, discoveredLink_2_0)
+		if err != nil {
+			return nil, errors.Trace(err)
+		}
+	}
+
+	params, err := interfaceParams2_0(valid["type"].(string), valid["params"])
+	if err != nil {
+		return nil, errors.Trace(err)
+	}
+
 	macAddress, _ := valid["mac_address"].(string)
 	result := &interface_{
 		resourceURI: valid["resource_uri"].(string),
@@ -427,14 +510,21 @@ func interface_2_0(source map[string]interface{}) (*interface_, error) {
 		enabled: valid["enabled"].(bool),
 		tags:    convertToStringSlice(valid["tags"]),
 
-		vlan:  vlan,
-		links: links,
+		vlan:       vlan,
+		links:      links,
+		discovered: discovered,
 
 		macAddress:   macAddress,
 		effectiveMTU: valid["effective_mtu"].(int),
 
+		linkConnected:  valid["link_connected"].(bool),
+		linkSpeed:      valid["link_speed"].(int),
+		interfaceSpeed: valid["interface_speed"].(int),
+
 		parents:  convertToStringSlice(valid["parents"]),
 		children: convertToStringSlice(valid["children"]),
+
+		params: params,
 	}
 	return result, nil
 }
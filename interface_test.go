@@ -45,6 +45,7 @@ func (*interfaceSuite) TestReadInterfacesNulls(c *gc.C) {
 	c.Check(iface.MACAddress(), gc.Equals, "")
 	c.Check(iface.Tags(), jc.DeepEquals, []string{})
 	c.Check(iface.VLAN(), gc.IsNil)
+	c.Check(iface.Discovered(), gc.HasLen, 0)
 }
 
 func (s *interfaceSuite) checkInterface(c *gc.C, iface *interface_) {
@@ -67,6 +68,13 @@ func (s *interfaceSuite) checkInterface(c *gc.C, iface *interface_) {
 	links := iface.Links()
 	c.Assert(links, gc.HasLen, 1)
 	c.Check(links[0].ID(), gc.Equals, 69)
+
+	discovered := iface.Discovered()
+	c.Assert(discovered, gc.HasLen, 1)
+	c.Check(discovered[0].IPAddress(), gc.Equals, "192.168.100.20")
+	c.Check(discovered[0].Subnet(), gc.IsNil)
+
+	c.Check(iface.Params(), gc.IsNil)
 }
 
 func (s *interfaceSuite) TestReadInterfaces(c *gc.C) {
@@ -90,6 +98,25 @@ func (s *interfaceSuite) TestReadInterfaceNilMAC(c *gc.C) {
 	c.Assert(result.MACAddress(), gc.Equals, "")
 }
 
+func (s *interfaceSuite) TestReadInterfaceSpeeds(c *gc.C) {
+	// Older versions of MAAS don't report speeds or the link state.
+	result, err := readInterface(twoDotOh, parseJSON(c, interfaceResponse))
+	c.Assert(err, jc.ErrorIsNil)
+	c.Check(result.LinkConnected(), jc.IsTrue)
+	c.Check(result.LinkSpeed(), gc.Equals, 0)
+	c.Check(result.InterfaceSpeed(), gc.Equals, 0)
+
+	json := parseJSON(c, interfaceResponse)
+	json.(map[string]interface{})["link_connected"] = false
+	json.(map[string]interface{})["link_speed"] = 1000
+	json.(map[string]interface{})["interface_speed"] = 10000
+	result, err = readInterface(twoDotOh, json)
+	c.Assert(err, jc.ErrorIsNil)
+	c.Check(result.LinkConnected(), jc.IsFalse)
+	c.Check(result.LinkSpeed(), gc.Equals, 1000)
+	c.Check(result.InterfaceSpeed(), gc.Equals, 10000)
+}
+
 func (*interfaceSuite) TestLowVersion(c *gc.C) {
 	_, err := readInterfaces(version.MustParse("1.9.0"), parseJSON(c, interfacesResponse))
 	c.Assert(err, jc.Satisfies, IsUnsupportedVersionError)
@@ -409,7 +436,7 @@ const (
     "effective_mtu": 1500,
     "mac_address": "52:54:00:c9:6a:45",
     "children": ["eth0.1", "eth0.2"],
-    "discovered": [],
+    "discovered": [{"ip_address": "192.168.100.20", "subnet": null}],
     "params": "some params",
     "vlan": {
         "resource_uri": "/MAAS/api/2.0/vlans/1/",
@@ -0,0 +1,220 @@
+// Copyright 2022 Canonical Ltd.
+// Licensed under the LGPLv3, see LICENCE file for details.
+
+package gomaasapi
+
+import (
+	"encoding/json"
+
+	"github.com/juju/schema"
+)
+
+type interfaceParams struct {
+	raw map[string]interface{}
+
+	mtu      int
+	acceptRA bool
+	autoconf bool
+
+	bond   *bondParams
+	bridge *bridgeParams
+}
+
+// MTU implements InterfaceParams.
+func (p *interfaceParams) MTU() int {
+	return p.mtu
+}
+
+// AcceptRA implements InterfaceParams.
+func (p *interfaceParams) AcceptRA() bool {
+	return p.acceptRA
+}
+
+// Autoconf implements InterfaceParams.
+func (p *interfaceParams) Autoconf() bool {
+	return p.autoconf
+}
+
+// Bond implements InterfaceParams.
+func (p *interfaceParams) Bond() BondParams {
+	if p.bond == nil {
+		return nil
+	}
+	return p.bond
+}
+
+// Bridge implements InterfaceParams.
+func (p *interfaceParams) Bridge() BridgeParams {
+	if p.bridge == nil {
+		return nil
+	}
+	return p.bridge
+}
+
+// Raw implements InterfaceParams.
+func (p *interfaceParams) Raw() map[string]interface{} {
+	if p.raw == nil {
+		return nil
+	}
+	result := make(map[string]interface{}, len(p.raw))
+	for key, value := range p.raw {
+		result[key] = value
+	}
+	return result
+}
+
+type bondParams struct {
+	mode           string
+	miimon         int
+	downDelay      int
+	upDelay        int
+	lacpRate       string
+	xmitHashPolicy string
+	numGratARP     int
+}
+
+// Mode implements BondParams.
+func (b *bondParams) Mode() string {
+	return b.mode
+}
+
+// MIIMon implements BondParams.
+func (b *bondParams) MIIMon() int {
+	return b.miimon
+}
+
+// DownDelay implements BondParams.
+func (b *bondParams) DownDelay() int {
+	return b.downDelay
+}
+
+// UpDelay implements BondParams.
+func (b *bondParams) UpDelay() int {
+	return b.upDelay
+}
+
+// LACPRate implements BondParams.
+func (b *bondParams) LACPRate() string {
+	return b.lacpRate
+}
+
+// XmitHashPolicy implements BondParams.
+func (b *bondParams) XmitHashPolicy() string {
+	return b.xmitHashPolicy
+}
+
+// NumGratARP implements BondParams.
+func (b *bondParams) NumGratARP() int {
+	return b.numGratARP
+}
+
+type bridgeParams struct {
+	type_ string
+	stp   bool
+	fd    int
+}
+
+// Type implements BridgeParams.
+func (b *bridgeParams) Type() string {
+	return b.type_
+}
+
+// STP implements BridgeParams.
+func (b *bridgeParams) STP() bool {
+	return b.stp
+}
+
+// FD implements BridgeParams.
+func (b *bridgeParams) FD() int {
+	return b.fd
+}
+
+// There is no need for controller based parsing of interface params. They
+// are only read as part of the interface parsing.
+
+// interfaceParams2_0 reads the params of an interface of the given type. The
+// params are usually a JSON object, but MAAS returns an empty string when
+// there are no params, and older versions may return the object encoded as a
+// string. Anything that isn't an object results in nil params.
+func interfaceParams2_0(interfaceType string, source interface{}) (*interfaceParams, error) {
+	if encoded, ok := source.(string); ok {
+		var decoded map[string]interface{}
+		if err := json.Unmarshal([]byte(encoded), &decoded); err != nil {
+			return nil, nil
+		}
+		source = decoded
+	}
+	raw, ok := source.(map[string]interface{})
+	if !ok || raw == nil {
+		return nil, nil
+	}
+
+	fields := schema.Fields{
+		"mtu":       schema.ForceInt(),
+		"accept_ra": schema.Bool(),
+		"autoconf":  schema.Bool(),
+
+		"bond_mode":             schema.String(),
+		"bond_miimon":           schema.ForceInt(),
+		"bond_downdelay":        schema.ForceInt(),
+		"bond_updelay":          schema.ForceInt(),
+		"bond_lacp_rate":        schema.String(),
+		"bond_xmit_hash_policy": schema.String(),
+		"bond_num_grat_arp":     schema.ForceInt(),
+
+		"bridge_type": schema.String(),
+		"bridge_stp":  schema.Bool(),
+		"bridge_fd":   schema.ForceInt(),
+	}
+	defaults := schema.Defaults{
+		"mtu":       0,
+		"accept_ra": false,
+		"autoconf":  false,
+
+		"bond_mode":             "",
+		"bond_miimon":           0,
+		"bond_downdelay":        0,
+		"bond_updelay":          0,
+		"bond_lacp_rate":        "",
+		"bond_xmit_hash_policy": "",
+		"bond_num_grat_arp":     0,
+
+		"bridge_type": "",
+		"bridge_stp":  false,
+		"bridge_fd":   0,
+	}
+	checker := schema.FieldMap(fields, defaults)
+	coerced, err := checker.Coerce(raw, nil)
+	if err != nil {
+		return nil, WrapWithDeserializationError(err, "interface params 2.0 schema check failed")
+	}
+	valid := coerced.(map[string]interface{})
+	// From here we know that the map returned from the schema coercion
+	// contains fields of the right type.
+
+	result := &interfaceParams{
+		raw:      raw,
+		mtu:      valid["mtu"].(int),
+		acceptRA: valid["accept_ra"].(bool),
+		autoconf: valid["autoconf"].(bool),
+	}
+	switch interfaceType {
+	case "bond":
+		result.bond = &bondParams{
+			mode:           valid["bond_mode"].(string),
+			miimon:         valid["bond_miimon"].(int),
+			downDelay:      valid["bond_downdelay"].(int),
+			upDelay:        valid["bond_updelay"].(int),
+			lacpRate:       valid["bond_lacp_rate"].(string),
+			xmitHashPolicy: valid["bond_xmit_hash_policy"].(string),
+			numGratARP:     valid["bond_num_grat_arp"].(int),
+		}
+	case "bridge":
+		result.bridge = &bridgeParams{
+			type_: valid["bridge_type"].(string),
+			stp:   valid["bridge_stp"].(bool),
+			fd:    valid["bridge_fd"].(int),
+		}
+	}
+	return result, nil
+}
@@ -0,0 +1,100 @@
+// Copyright 2022 Canonical Ltd.
+// Licensed under the LGPLv3, see LICENCE file for details.
+
+package gomaasapi
+
+import (
+	jc "github.com/juju/testing/checkers"
+	gc "gopkg.in/check.v1"
+)
+
+type interfaceParamsSuite struct{}
+
+var _ = gc.Suite(&interfaceParamsSuite{})
+
+func (*interfaceParamsSuite) TestReadEmpty(c *gc.C) {
+	for i, source := range []interface{}{nil, "", "some params", []interface{}{}} {
+		c.Logf("test %d", i)
+		params, err := interfaceParams2_0("physical", source)
+		c.Check(err, jc.ErrorIsNil)
+		c.Check(params, gc.IsNil)
+	}
+}
+
+func (*interfaceParamsSuite) TestReadBadSchema(c *gc.C) {
+	_, err := interfaceParams2_0("bond", map[string]interface{}{"bond_miimon": "many"})
+	c.Check(err, jc.Satisfies, IsDeserializationError)
+	c.Check(err, gc.ErrorMatches, `interface params 2.0 schema check failed: .*`)
+}
+
+func (*interfaceParamsSuite) TestReadBond(c *gc.C) {
+	params, err := interfaceParams2_0("bond", parseJSON(c, bondParamsResponse))
+	c.Assert(err, jc.ErrorIsNil)
+	c.Check(params.MTU(), gc.Equals, 9000)
+	c.Check(params.AcceptRA(), jc.IsTrue)
+	c.Check(params.Autoconf(), jc.IsFalse)
+	c.Check(params.Bridge(), gc.IsNil)
+
+	bond := params.Bond()
+	c.Assert(bond, gc.NotNil)
+	c.Check(bond.Mode(), gc.Equals, "802.3ad")
+	c.Check(bond.MIIMon(), gc.Equals, 100)
+	c.Check(bond.DownDelay(), gc.Equals, 0)
+	c.Check(bond.UpDelay(), gc.Equals, 0)
+	c.Check(bond.LACPRate(), gc.Equals, "fast")
+	c.Check(bond.XmitHashPolicy(), gc.Equals, "layer3+4")
+	c.Check(bond.NumGratARP(), gc.Equals, 1)
+
+	c.Check(params.Raw()["bond_mode"], gc.Equals, "802.3ad")
+}
+
+func (*interfaceParamsSuite) TestReadBridge(c *gc.C) {
+	params, err := interfaceParams2_0("bridge", parseJSON(c, bridgeParamsResponse))
+	c.Assert(err, jc.ErrorIsNil)
+	c.Check(params.Bond(), gc.IsNil)
+
+	bridge := params.Bridge()
+	c.Assert(bridge, gc.NotNil)
+	c.Check(bridge.Type(), gc.Equals, "ovs")
+	c.Check(bridge.STP(), jc.IsTrue)
+	c.Check(bridge.FD(), gc.Equals, 15)
+}
+
+func (*interfaceParamsSuite) TestReadEncodedString(c *gc.C) {
+	params, err := interfaceParams2_0("bridge", bridgeParamsResponse)
+	c.Assert(err, jc.ErrorIsNil)
+	c.Assert(params.Bridge(), gc.NotNil)
+	c.Check(params.Bridge().Type(), gc.Equals, "ovs")
+}
+
+func (*interfaceParamsSuite) TestRawCopies(c *gc.C) {
+	params, err := interfaceParams2_0("bridge", parseJSON(c, bridgeParamsResponse))
+	c.Assert(err, jc.ErrorIsNil)
+	raw := params.Raw()
+	raw["bridge_type"] = "standard"
+	c.Check(params.Raw()["bridge_type"], gc.Equals, "ovs")
+}
+
+const (
+	bondParamsResponse = `
+{
+    "mtu": 9000,
+    "accept_ra": true,
+    "autoconf": false,
+    "bond_mode": "802.3ad",
+    "bond_miimon": 100,
+    "bond_downdelay": 0,
+    "bond_updelay": 0,
+    "bond_lacp_rate": "fast",
+    "bond_xmit_hash_policy": "layer3+4",
+    "bond_num_grat_arp": 1
+}
+`
+	bridgeParamsResponse = `
+{
+    "bridge_type": "ovs",
+    "bridge_stp": true,
+    "bridge_fd": 15
+}
+`
+)
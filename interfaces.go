@@ -3,7 +3,14 @@
 
 package gomaasapi
 
-import "github.com/juju/collections/set"
+import (
+	"context"
+	"io"
+	"net/url"
+	"time"
+
+	"github.com/juju/collections/set"
+)
 
 const (
 	// Capability constants.
@@ -18,39 +25,353 @@ const (
 // Controller represents an API connection to a MAAS Controller. Since the API
 // is restful, there is no long held connection to the API server, but instead
 // HTTP calls are made and JSON response structures parsed.
+//
+// A Controller is safe for concurrent use by multiple goroutines, as are the
+// Machines it returns, which may be read while they are updated. Other
+// entities are safe to read concurrently, but not while they are updated,
+// for instance by Interface.Update.
 type Controller interface {
 	// APIVersionInfo returns the version and subversion strings for the MAAS
 	// controller.
 	APIVersionInfo() (string, string, error)
 
+	// RackControllers returns the rack controllers that match the args.
+	RackControllers(RackControllersArgs) ([]RackController, error)
+
+	// RackController returns the rack controller with the system ID, or a
+	// NoMatchError if there isn't one.
+	RackController(systemID string) (RackController, error)
+
+	// PowerTypes returns the power drivers that the rack controllers
+	// support.
+	PowerTypes() ([]PowerType, error)
+
+	// RegionControllers returns the region controllers. Only
+	// administrators can list them.
+	RegionControllers() ([]RegionController, error)
+
+	// VersionSkew compares the version of the region with the versions of
+	// all the rack controllers.
+	VersionSkew() (VersionSkew, error)
+
 	// Capabilities returns a set of capabilities as defined by the string
 	// constants.
 	Capabilities() set.Strings
 
+	// ServerVersion returns the API version, the version and the
+	// capabilities of MAAS, as read when the controller was created. The
+	// responses of MAAS are read according to its release.
+	ServerVersion() ServerInfo
+
+	// ServerTime returns the current time of the MAAS region, as reported
+	// by the Date header of its responses, in UTC.
+	ServerTime() (time.Time, error)
+
+	// WithAPIKey returns a Controller for the same MAAS region that signs
+	// its requests with the specified API key. The version and capabilities
+	// of the region are shared, so no requests are made, and the credentials
+	// are not checked until used. Entities read through the returned
+	// Controller perform their operations with the new credentials, which
+	// allows a single privileged operation without a second NewController.
+	//
+	// If the APIKey is not valid, a NotValid error is returned.
+	WithAPIKey(apiKey string) (Controller, error)
+
+	// SetAPIKey replaces the API key signing the requests of the
+	// controller, and of the controllers derived from it with WithPriority
+	// or WithContext, so that the key can be rotated. Requests already
+	// sent complete with the previous key. It returns a NotSupported error
+	// if the controller uses a CredentialProvider, and a NotValid error if
+	// the key is malformed.
+	SetAPIKey(apiKey string) error
+
+	// WithPriority returns a Controller for the same MAAS region whose
+	// requests are queued with the given priority. It only has an effect
+	// if the controller was created with a RequestQueue. Entities read
+	// through the returned Controller use the same priority.
+	WithPriority(Priority) Controller
+
+	// WithContext returns a Controller for the same MAAS region whose
+	// requests are made with the given context. The requests, and any
+	// waits for retries or for the RequestQueue, are cancelled when the
+	// context is done. Entities read through the returned Controller use
+	// the same context.
+	WithContext(context.Context) Controller
+
+	// Do sends a signed request to an endpoint of the MAAS API that the
+	// Controller doesn't cover, such as one added by a newer release. The
+	// path is relative to the versioned API URL, such as "machines/", or
+	// absolute, such as the resource URI of an object. The op is sent in
+	// the query, and the params in the query of GET and DELETE requests
+	// or in the form-encoded body of POST and PUT requests. The response
+	// is returned as parsed JSON, or as raw bytes if it isn't JSON. If
+	// MAAS responds with an error status, the cause of the error is a
+	// ServerError, see GetServerError.
+	Do(method, path, op string, params url.Values) (JSONObject, error)
+
+	// DoContent is Do sending the content as the body of a POST or PUT
+	// request, with the given content type.
+	DoContent(method, path, contentType string, content io.Reader) (JSONObject, error)
+
+	// DebugLog returns the requests sent to MAAS and their responses, as
+	// recorded by the DebugRecorder of the controller, oldest first. It
+	// returns nil if the controller was created without one.
+	DebugLog() []DebugExchange
+
+	// Watch connects to the websocket API of MAAS and reports the changes
+	// to the objects of the given kinds, so that they don't need to be
+	// polled. The watcher reconnects when its connection is lost, until
+	// it is closed or the context is done. The websocket is opened with the
+	// dialer, proxy and TLS configuration of the transport of the
+	// controller, which must be an *http.Transport, and the middleware of
+	// the client doesn't apply to it.
+	Watch(ctx context.Context, args WatchArgs) (Watcher, error)
+
 	BootResources() ([]BootResource, error)
 
+	// UploadBootResource creates a boot resource and uploads its content
+	// in chunks. If MAAS already has the content, from an earlier upload
+	// of the same resource, it isn't sent again.
+	UploadBootResource(UploadBootResourceArgs) (BootResource, error)
+
+	// ImportBootResources starts importing the boot resources selected
+	// from the boot sources into the region. The rack controllers sync
+	// the images from the region once the import completes.
+	ImportBootResources() error
+
+	// StopImportBootResources stops the running import of boot resources.
+	StopImportBootResources() error
+
+	// IsImportingBootResources reports whether the region is importing
+	// boot resources.
+	IsImportingBootResources() (bool, error)
+
+	// BootSources returns the sources the region imports the boot
+	// resources from.
+	BootSources() ([]BootSource, error)
+
+	// CreateBootSource adds a source to import the boot resources from,
+	// such as a local mirror.
+	CreateBootSource(CreateBootSourceArgs) (BootSource, error)
+
+	// VMHosts returns the hosts that MAAS composes virtual machines on,
+	// which older versions of MAAS call pods.
+	VMHosts() ([]VMHost, error)
+
+	// VMHost returns the VM host with the given ID.
+	VMHost(id int) (VMHost, error)
+
+	// CreateVMHost adds a VM host. MAAS connects to the hypervisor and
+	// discovers its resources before returning.
+	CreateVMHost(CreateVMHostArgs) (VMHost, error)
+
+	// Scripts returns the commissioning and testing scripts that match
+	// the args.
+	Scripts(ScriptsArgs) ([]Script, error)
+
+	// UploadScript adds a commissioning or testing script.
+	UploadScript(UploadScriptArgs) (Script, error)
+
+	// Events returns the first page of the events that match the args,
+	// newest first. The older events are read with EventsPage.Next.
+	Events(EventsArgs) (EventsPage, error)
+
+	// ChangeFeed returns a feed that queries the events periodically, and
+	// reports the machines with new events, so that only those need to be
+	// read again. It stops when it is closed or the context is done.
+	ChangeFeed(ctx context.Context, args ChangeFeedArgs) (ChangeFeed, error)
+
+	// Users returns the users of MAAS.
+	Users() ([]User, error)
+
+	// CreateUser adds a user, which requires an administrator.
+	CreateUser(CreateUserArgs) (User, error)
+
+	// WhoAmI returns the user the controller is authenticated as.
+	WhoAmI() (User, error)
+
+	// SSHKeys returns the SSH public keys of the authenticated user, which
+	// MAAS installs on the machines the user deploys.
+	SSHKeys() ([]SSHKey, error)
+
+	// AddSSHKey adds an SSH public key to the authenticated user.
+	AddSSHKey(key string) (SSHKey, error)
+
+	// ImportSSHKeys imports the SSH public keys of a Launchpad or GitHub
+	// account, given as "lp:<username>" or "gh:<username>", and returns
+	// the imported keys.
+	ImportSSHKeys(keySource string) ([]SSHKey, error)
+
+	// SSLKeys returns the SSL certificates of the authenticated user, which
+	// MAAS installs on the Windows machines the user deploys for WinRM.
+	SSLKeys() ([]SSLKey, error)
+
+	// AddSSLKey adds a PEM encoded SSL certificate to the authenticated
+	// user.
+	AddSSLKey(key string) (SSLKey, error)
+
+	// CreateAuthorisationToken creates an API token for the authenticated
+	// user. Its APIKey can be used with WithAPIKey to rotate keys.
+	CreateAuthorisationToken(name string) (AuthorisationToken, error)
+
+	// AuthorisationTokens returns the API tokens of the authenticated user.
+	AuthorisationTokens() ([]AuthorisationToken, error)
+
+	// DeleteAuthorisationToken revokes the API token with the token key.
+	DeleteAuthorisationToken(tokenKey string) error
+
+	// LicenseKeys returns the license keys MAAS deploys machines with, such
+	// as Windows product keys.
+	LicenseKeys() ([]LicenseKey, error)
+
+	// CreateLicenseKey adds the license key of an operating system release.
+	CreateLicenseKey(CreateLicenseKeyArgs) (LicenseKey, error)
+
+	// PackageRepositories returns the APT repositories that deployed
+	// machines are configured with, including the Ubuntu archives.
+	PackageRepositories() ([]PackageRepository, error)
+
+	// CreatePackageRepository adds an APT repository, such as a mirror or
+	// a PPA. The Name and URL are required.
+	CreatePackageRepository(PackageRepositoryArgs) (PackageRepository, error)
+
 	// Fabrics returns the list of Fabrics defined in the MAAS controller.
 	Fabrics() ([]Fabric, error)
 
 	// Spaces returns the list of Spaces defined in the MAAS controller.
 	Spaces() ([]Space, error)
 
+	// CreateSpace creates a space. Subnets are added to the space by
+	// assigning their VLANs to it, see Space.AssignVLAN.
+	CreateSpace(CreateSpaceArgs) (Space, error)
+
+	// Subnets returns the list of Subnets defined in the MAAS controller.
+	Subnets() ([]Subnet, error)
+
+	// SubnetByID returns the subnet with the ID, or a NoMatchError if
+	// there isn't one.
+	SubnetByID(id int) (Subnet, error)
+
+	// SubnetByCIDR returns the subnet of the network, or a NoMatchError if
+	// there isn't one. Host bits of the CIDR are ignored.
+	SubnetByCIDR(cidr string) (Subnet, error)
+
+	// VLANByID returns the VLAN with the ID, or a NoMatchError if there
+	// isn't one.
+	VLANByID(id int) (VLAN, error)
+
+	// FabricByName returns the fabric with the name, or a NoMatchError if
+	// there isn't one.
+	FabricByName(name string) (Fabric, error)
+
+	// SpaceByName returns the space with the name, or a NoMatchError if
+	// there isn't one.
+	SpaceByName(name string) (Space, error)
+
+	// ClearLookupCache drops the listings cached by SubnetByCIDR,
+	// VLANByID, FabricByName and SpaceByName. The listings are cached for
+	// a short time, and refreshed when a lookup doesn't find a match, or
+	// once spaces, subnets or VLANs are changed through the controller.
+	ClearLookupCache()
+
+	// CreateSubnet creates a subnet on a VLAN.
+	CreateSubnet(CreateSubnetArgs) (Subnet, error)
+
+	// SubnetUsage lists the machines, interfaces and reserved addresses on
+	// the subnet.
+	SubnetUsage(Subnet) (ResourceUsage, error)
+
+	// DeleteSubnet deletes a subnet, optionally checking that nothing
+	// depends on it first.
+	DeleteSubnet(DeleteSubnetArgs) error
+
+	// VLANUsage lists the subnets, machines, interfaces and reserved
+	// addresses on the VLAN.
+	VLANUsage(VLAN) (ResourceUsage, error)
+
+	// DeleteVLAN deletes a VLAN, optionally checking that nothing depends
+	// on it first.
+	DeleteVLAN(DeleteVLANArgs) error
+
 	// StaticRoutes returns the list of StaticRoutes defined in the MAAS controller.
 	StaticRoutes() ([]StaticRoute, error)
 
 	// Zones lists all the zones known to the MAAS controller.
 	Zones() ([]Zone, error)
 
+	// CreateZone creates a zone, which requires an administrator.
+	CreateZone(CreateZoneArgs) (Zone, error)
+
 	// Pools lists all the pools known to the MAAS controller.
 	Pools() ([]Pool, error)
 
+	// CreatePool creates a resource pool, which requires an administrator.
+	CreatePool(CreatePoolArgs) (Pool, error)
+
 	// Machines returns a list of machines that match the params.
 	Machines(MachinesArgs) ([]Machine, error)
 
+	// MachinesPager returns a pager reading the machines that match the
+	// params in pages of pageSize machines, so that large listings don't
+	// need to be held in memory at once. The machines are decoded as the
+	// listing is received. MAAS versions that don't paginate the machines
+	// send the whole listing at once, in which case it is requested once
+	// and its decoded machines are kept until they are returned. The
+	// Limit and Offset of the args must not be set.
+	MachinesPager(args MachinesArgs, pageSize int) (MachinesPager, error)
+
+	// WalkMachines calls fn with each machine that matches the params.
+	// Machines are decoded one at a time as the listing is received, so
+	// that the raw listing isn't held in memory at once. The decoded
+	// machines of each page of the listing are buffered, and fn is only
+	// called once the response of the page has been closed, so that fn can
+	// make requests of its own, and its time doesn't count against the
+	// call timeout. MAAS versions that don't paginate the machines send
+	// the whole listing as a single page, so all the matching machines
+	// are decoded and held before fn is first called; only paginated
+	// listings bound the machines held. WalkMachines stops at, and returns,
+	// the first error of fn. With SkipInvalid, the machines that fail to
+	// deserialize are reported by a *PartialResultError once all the
+	// machines have been walked.
+	WalkMachines(args MachinesArgs, fn func(Machine) error) error
+
+	// ForEachMachine runs fn with each of the machines of the args, with
+	// up to Parallelism calls running at once, so that operations such as
+	// powering on or releasing hundreds of machines don't need their own
+	// goroutines. The machines are read in a single request, and make
+	// their requests with the context. The machines that aren't
+	// found, those for which fn fails, and those not started before the
+	// context is done are reported by a *BulkError once the running calls
+	// have returned.
+	ForEachMachine(ctx context.Context, args ForEachMachineArgs, fn func(Machine) error) error
+
+	// MachinesChangedSince returns the machines that have been updated
+	// after the time specified, so that periodic syncs can process deltas.
+	// MAAS doesn't filter by update time, so the filtering is done by the
+	// client. Machines are always returned if MAAS doesn't report when
+	// they were updated.
+	MachinesChangedSince(since time.Time) ([]Machine, error)
+
 	// AllocateMachine will attempt to allocate a machine to the user.
 	// If successful, the allocated machine is returned.
 	AllocateMachine(AllocateMachineArgs) (Machine, ConstraintMatches, error)
 
+	// LeaseMachine records a lease of the machine in its owner data, see
+	// Lease.
+	LeaseMachine(Machine, LeaseMachineArgs) (Lease, error)
+
+	// Leases returns the leases of the machines that match the args.
+	// Machines that aren't leased are left out.
+	Leases(MachinesArgs) ([]Lease, error)
+
+	// ListExpiredLeases returns the leases that have expired.
+	ListExpiredLeases() ([]Lease, error)
+
+	// CheckBootOrder reports the machines that match the args whose
+	// firmware is unlikely to boot from the network first, judging by how
+	// they last booted, their status and their power parameters.
+	CheckBootOrder(MachinesArgs) ([]BootOrderWarning, error)
+
 	// ReleaseMachines will stop the specified machines, and release them
 	// from the user making them available to be allocated again.
 	ReleaseMachines(ReleaseMachinesArgs) error
@@ -76,8 +397,112 @@ type Controller interface {
 	// Returns the DNS Domain Managed By MAAS
 	Domains() ([]Domain, error)
 
+	// CreateDomain creates a DNS domain managed by MAAS.
+	CreateDomain(CreateDomainArgs) (Domain, error)
+
+	// DNSResources returns the DNS resources that have been added to the
+	// domains managed by MAAS.
+	DNSResources() ([]DNSResource, error)
+
+	// CreateDNSResource adds a name to a domain managed by MAAS.
+	CreateDNSResource(CreateDNSResourceArgs) (DNSResource, error)
+
+	// DNSResourceRecords returns the DNS resource records that match the
+	// args, such as the CNAME, MX, SRV and TXT records of the domains.
+	DNSResourceRecords(DNSResourceRecordsArgs) ([]DNSResourceRecord, error)
+
+	// CreateDNSResourceRecord adds a record other than an address record
+	// to a domain managed by MAAS.
+	CreateDNSResourceRecord(CreateDNSResourceRecordArgs) (DNSResourceRecord, error)
+
+	// HostRecords returns the addresses of the machine together with all
+	// the DNS names that resolve to them: the FQDN of the machine, the
+	// names of its non-boot interfaces and any DNS resources pointing at
+	// its addresses.
+	HostRecords(Machine) ([]HostRecord, error)
+
+	// IPAddresses returns the IP addresses reserved by the user, or by
+	// all users if requested by an administrator.
+	IPAddresses(IPAddressesArgs) ([]IPAddress, error)
+
+	// ReserveIPAddress reserves an IP address, so that MAAS won't assign
+	// it to nodes. If the IP isn't specified, a free address of the subnet
+	// is picked.
+	ReserveIPAddress(ReserveIPArgs) (IPAddress, error)
+
+	// ReleaseIPAddress releases an IP address reserved by
+	// ReserveIPAddress.
+	ReleaseIPAddress(ip string) error
+
+	// IPRanges returns the dynamic and reserved IP ranges of all subnets.
+	IPRanges() ([]IPRange, error)
+
+	// CreateIPRange creates a dynamic or reserved IP range.
+	CreateIPRange(CreateIPRangeArgs) (IPRange, error)
+
+	// DHCPSnippets returns all the DHCP snippets, whatever their scope.
+	DHCPSnippets() ([]DHCPSnippet, error)
+
+	// CreateDHCPSnippet creates a DHCP snippet that applies globally.
+	CreateDHCPSnippet(DHCPSnippetArgs) (DHCPSnippet, error)
+
+	// CreateMachineDHCPSnippet creates a DHCP snippet that only applies to
+	// the machine.
+	CreateMachineDHCPSnippet(Machine, DHCPSnippetArgs) (DHCPSnippet, error)
+
+	// CreateSubnetDHCPSnippet creates a DHCP snippet that only applies to
+	// the subnet.
+	CreateSubnetDHCPSnippet(Subnet, DHCPSnippetArgs) (DHCPSnippet, error)
+
+	// DeleteMachineDHCPSnippets removes the DHCP snippets of the machine,
+	// for example before it is released to another user.
+	DeleteMachineDHCPSnippets(Machine) error
+
+	// DeleteSubnetDHCPSnippets removes the DHCP snippets of the subnet.
+	DeleteSubnetDHCPSnippets(Subnet) error
+
+	// Discoveries returns the neighbours the rack controllers have
+	// observed on the networks they are connected to.
+	Discoveries() ([]Discovery, error)
+
+	// ClearDiscoveries removes the observations of the rack controllers.
+	ClearDiscoveries(ClearDiscoveriesArgs) error
+
+	// ScanNetworks triggers an active discovery scan of the networks on
+	// the rack controllers that have access to them. The neighbours found
+	// are reported as discoveries.
+	ScanNetworks(ScanNetworksArgs) (ScanResult, error)
+
+	// GetConfig returns the value of a MAAS configuration item, as decoded
+	// from JSON. MAAS returns a BadRequestError for unknown names.
+	GetConfig(name string) (interface{}, error)
+
+	// SetConfig sets a MAAS configuration item, which requires an
+	// administrator.
+	SetConfig(name, value string) error
+
+	// Settings returns the commonly used configuration items.
+	Settings() (Settings, error)
+
+	// UpdateSettings sets the commonly used configuration items, one
+	// after another.
+	UpdateSettings(UpdateSettingsArgs) error
+
+	// ProxySettings returns the HTTP proxy configuration of the region.
+	ProxySettings() (ProxySettings, error)
+
+	// MachineProxy returns the HTTP proxy that MAAS configures the machine
+	// with when it is deployed, or the empty string if it isn't given one.
+	// See ProxySettings.ProxyURL for how it is resolved.
+	MachineProxy(Machine) (string, error)
+
 	// Returns the list of MAAS tags
 	Tags() ([]Tag, error)
+
+	// CreateTag creates a tag, which requires an administrator. MAAS
+	// evaluates the definition of the tag against all the nodes in the
+	// background.
+	CreateTag(CreateTagArgs) (Tag, error)
 }
 
 // File represents a file stored in the MAAS controller.
@@ -109,6 +534,9 @@ type Fabric interface {
 	ClassType() string
 
 	VLANs() []VLAN
+
+	// CreateVLAN creates a tagged VLAN on the fabric.
+	CreateVLAN(CreateVLANArgs) (VLAN, error)
 }
 
 // VLAN represents an instance of a Virtual LAN. VLANs are a common way to
@@ -143,6 +571,26 @@ type VLAN interface {
 
 	PrimaryRack() string
 	SecondaryRack() string
+
+	// ExternalDHCP is the IP address of a DHCP server not managed by MAAS
+	// that has been observed on the VLAN. It is empty if there is none.
+	ExternalDHCP() string
+	// RelayVLAN is the VLAN that DHCP requests are relayed to, nil if DHCP
+	// isn't relayed. It may only have the ID set.
+	RelayVLAN() VLAN
+
+	Description() string
+	// Space is the name of the space the VLAN is in, empty if it isn't in
+	// one.
+	Space() string
+
+	// Update changes the VLAN, including enabling DHCP on it or relaying
+	// DHCP to another VLAN.
+	Update(UpdateVLANArgs) error
+
+	// Delete removes the VLAN. Controller.DeleteVLAN can check that
+	// nothing depends on the VLAN first.
+	Delete() error
 }
 
 // Zone represents a physical zone that a Machine is in. The meaning of a
@@ -150,20 +598,94 @@ type VLAN interface {
 // or a data centre. Users can then allocate nodes from specific physical zones,
 // to suit their redundancy or performance requirements.
 type Zone interface {
+	ID() int
 	Name() string
 	Description() string
+
+	// Update changes the name or the description of the zone.
+	Update(UpdateZoneArgs) error
+
+	// Delete removes the zone. The machines in the zone are moved to the
+	// default zone, which can't be deleted.
+	Delete() error
 }
 
 // Pool is just a logical separation of resources.
 type Pool interface {
+	ID() int
 	// The name of the resource pool
 	Name() string
 	Description() string
+
+	// Update changes the name or the description of the pool.
+	Update(UpdatePoolArgs) error
+
+	// Delete removes the pool. The machines in the pool are moved to the
+	// default pool, which can't be deleted.
+	Delete() error
 }
 
 type Domain interface {
+	ID() int
 	// The name of the Domain
 	Name() string
+	// Authoritative is true if MAAS is the authoritative DNS server of the
+	// domain.
+	Authoritative() bool
+	// TTL is nil if the default TTL of MAAS applies.
+	TTL() *int
+	ResourceRecordCount() int
+	// IsDefault is true if new nodes are added to the domain. It is only
+	// reported by newer versions of MAAS.
+	IsDefault() bool
+
+	// Update changes the name, authority or TTL of the domain.
+	Update(UpdateDomainArgs) error
+
+	// Delete removes the domain. The default domain can't be deleted.
+	Delete() error
+
+	// SetDefault makes the domain the one new nodes are added to.
+	SetDefault() error
+}
+
+// DNSResource is a name in a MAAS managed domain along with the addresses
+// it resolves to.
+type DNSResource interface {
+	ID() int
+	FQDN() string
+	// AddressTTL is nil if the TTL of the domain applies.
+	AddressTTL() *int
+	IPAddresses() []string
+
+	// ResourceRecords returns the records of the name other than its
+	// address records.
+	ResourceRecords() []DNSResourceRecord
+
+	// Update changes the name, the TTL or the addresses of the resource.
+	Update(UpdateDNSResourceArgs) error
+
+	// Delete removes the name and its address records.
+	Delete() error
+}
+
+// DNSResourceRecord is a record, other than an address record, of a name in
+// a MAAS managed domain.
+type DNSResourceRecord interface {
+	ID() int
+	FQDN() string
+	// Type is the type of the record, such as "CNAME" or "MX".
+	Type() string
+	// Data is the content of the record in zone file format.
+	Data() string
+	// TTL is nil if the TTL of the domain applies.
+	TTL() *int
+
+	// Update changes the content or the TTL of the record.
+	Update(UpdateDNSResourceRecordArgs) error
+
+	// Delete removes the record.
+	Delete() error
 }
 
 // BootResource is the bomb... find something to say here.
@@ -174,6 +696,324 @@ type BootResource interface {
 	Architecture() string
 	SubArchitectures() set.Strings
 	KernelFlavor() string
+
+	// Delete removes the boot resource.
+	Delete() error
+}
+
+// BootSource is a simplestreams location the region imports boot
+// resources from.
+type BootSource interface {
+	ID() int
+	URL() string
+
+	// KeyringFilename is the path on the region controller of the keyring
+	// that verifies the images.
+	KeyringFilename() string
+
+	// KeyringData is the content of the keyring that verifies the images,
+	// when it was given rather than a KeyringFilename.
+	KeyringData() []byte
+
+	// Selections returns which of the images of the source are imported.
+	Selections() ([]BootSourceSelection, error)
+
+	// CreateSelection adds images of the source to import.
+	CreateSelection(CreateBootSourceSelectionArgs) (BootSourceSelection, error)
+
+	// Update changes the location or the keyring of the source.
+	Update(UpdateBootSourceArgs) error
+
+	// Delete removes the source, along with its selections.
+	Delete() error
+}
+
+// BootSourceSelection selects images of a BootSource to import.
+type BootSourceSelection interface {
+	ID() int
+	BootSourceID() int
+	OS() string
+	Release() string
+
+	// Architectures, SubArchitectures and Labels select the images of the
+	// release. MAAS reports "*" for all of them.
+	Architectures() []string
+	SubArchitectures() []string
+	Labels() []string
+
+	// Update changes the images that are selected.
+	Update(UpdateBootSourceSelectionArgs) error
+
+	// Delete removes the selection.
+	Delete() error
+}
+
+// VMHost is a host that MAAS composes virtual machines on, such as a virsh
+// or LXD host.
+type VMHost interface {
+	ID() int
+	Name() string
+
+	// Type is the power type of the host, "virsh" or "lxd".
+	Type() string
+
+	// Version is the version of the hypervisor, if MAAS reports it.
+	Version() string
+
+	Architectures() []string
+	Capabilities() []string
+	Tags() []string
+
+	// Host is the system ID of the machine the VM host runs on, if it is
+	// managed by MAAS.
+	Host() string
+
+	Zone() Zone
+	Pool() Pool
+
+	// CPUOverCommitRatio and MemoryOverCommitRatio are how many times the
+	// cores and the memory of the host can be allocated to virtual
+	// machines.
+	CPUOverCommitRatio() float64
+	MemoryOverCommitRatio() float64
+
+	// DefaultStoragePool is the ID of the pool disks are allocated from
+	// when no pool is given.
+	DefaultStoragePool() string
+	StoragePools() []VMHostStoragePool
+
+	// Total, Used and Available report the resources of the host, without
+	// the over-commit ratios applied.
+	Total() VMHostResources
+	Used() VMHostResources
+	Available() VMHostResources
+
+	// Update changes the settings of the VM host.
+	Update(UpdateVMHostArgs) error
+
+	// Parameters returns the power parameters MAAS connects to the host
+	// with, such as the address, the LXD project and the certificate
+	// MAAS authenticates with. Only administrators can read them.
+	Parameters() (map[string]interface{}, error)
+
+	// VirtualMachines returns the machines that are virtual machines on
+	// the host, both those MAAS composed and those it discovered when
+	// refreshing the host.
+	VirtualMachines() ([]Machine, error)
+
+	// Compose creates a virtual machine on the host, and returns the
+	// machine that MAAS commissions for it.
+	Compose(ComposeArgs) (Machine, error)
+
+	// Refresh asks MAAS to query the host for its resources and virtual
+	// machines, and updates the VM host with the result.
+	Refresh() error
+
+	// Delete removes the VM host. The virtual machines on it are not
+	// deleted from the hypervisor.
+	Delete() error
+}
+
+// Script is a commissioning or testing script that MAAS runs on machines.
+type Script interface {
+	ID() int
+	Name() string
+	Title() string
+	Description() string
+	Tags() []string
+
+	// Type is when the script is run, ScriptTypeCommissioning,
+	// ScriptTypeTesting or ScriptTypeRelease.
+	Type() string
+
+	// HardwareType is the hardware the script is for, such as
+	// HardwareTypeStorage.
+	HardwareType() string
+
+	// Timeout is how long the script may run for, or zero if it isn't
+	// limited.
+	Timeout() time.Duration
+
+	// Destructive is whether the script erases the disks of the machine.
+	Destructive() bool
+
+	// Default is whether the script is shipped with MAAS.
+	Default() bool
+
+	// Parameters are the parameters declared in the metadata of the
+	// script, keyed by name.
+	Parameters() map[string]interface{}
+
+	// History returns the uploaded revisions of the script, newest first.
+	History() []ScriptRevision
+
+	// Update changes the script, uploading a new revision if content is
+	// given.
+	Update(UpdateScriptArgs) error
+
+	// Delete removes the script. The scripts shipped with MAAS can't be
+	// deleted.
+	Delete() error
+
+	// Revert makes an earlier revision of the script current. The
+	// revision is either its ID, or a negative number of revisions to go
+	// back.
+	Revert(to int) error
+
+	// Download returns the content of the given revision of the script,
+	// or of the current revision if it is zero.
+	Download(revision int) ([]byte, error)
+}
+
+// Event is something that happened to a node, or an action of a user.
+type Event interface {
+	ID() int
+
+	// Node is the system ID of the node, and Hostname its hostname. They
+	// are empty for events that aren't about a node.
+	Node() string
+	Hostname() string
+
+	// Username is the user that caused the event, if any.
+	Username() string
+
+	// Level is the severity of the event, such as EventLevelInfo.
+	Level() string
+
+	// Type is a short description of the event, such as "Deployed".
+	Type() string
+	Description() string
+	Created() time.Time
+}
+
+// Watcher reports the changes to MAAS objects, as returned by
+// Controller.Watch.
+type Watcher interface {
+	// Changes returns the channel on which the changes are sent. It is
+	// closed once the watcher has stopped.
+	Changes() <-chan ChangeEvent
+
+	// Close stops the watcher.
+	Close() error
+}
+
+// MachinesPager reads machines page by page, as returned by
+// Controller.MachinesPager.
+type MachinesPager interface {
+	// NextPage returns the machines of the next page. It returns no
+	// machines once all the machines have been read. Pages may hold fewer
+	// machines than the page size when the OwnerData or ChangedSince
+	// filters are used.
+	NextPage() ([]Machine, error)
+
+	// All returns the machines of all the remaining pages.
+	All() ([]Machine, error)
+}
+
+// EventsPage is a page of events, as returned by Controller.Events.
+type EventsPage interface {
+	Events() []Event
+
+	// Next returns the page of the events older than those of this page,
+	// with the same selection. It returns nil when there are no more
+	// events.
+	Next() (EventsPage, error)
+}
+
+// ChangeFeed reports the machines that changed, as returned by
+// Controller.ChangeFeed.
+type ChangeFeed interface {
+	// Changes returns the channel on which the changes are sent. It is
+	// closed once the feed has stopped.
+	Changes() <-chan MachineChanges
+
+	// Close stops the feed.
+	Close() error
+}
+
+// User is an account on MAAS.
+type User interface {
+	Username() string
+	Email() string
+
+	// IsAdmin reports whether the user is an administrator of MAAS.
+	IsAdmin() bool
+
+	// IsLocal reports whether the user is managed by MAAS rather than by
+	// an external authentication service.
+	IsLocal() bool
+
+	// Delete removes the user.
+	Delete() error
+}
+
+// SSHKey is an SSH public key of a user.
+type SSHKey interface {
+	ID() int
+	Key() string
+
+	// KeySource is the account the key was imported from, such as
+	// "lp:<username>", or empty if the key was added directly.
+	KeySource() string
+
+	// Delete removes the key.
+	Delete() error
+}
+
+// SSLKey is an SSL certificate of a user.
+type SSLKey interface {
+	ID() int
+
+	// Key is the PEM encoded certificate.
+	Key() string
+
+	// Delete removes the certificate.
+	Delete() error
+}
+
+// LicenseKey is the license key of an operating system release, which MAAS
+// configures the machines deployed with the release to use.
+type LicenseKey interface {
+	OSystem() string
+	DistroSeries() string
+	Key() string
+
+	// Update replaces the key.
+	Update(key string) error
+
+	// Delete removes the key.
+	Delete() error
+}
+
+// PackageRepository is an APT repository that MAAS configures deployed
+// machines with.
+type PackageRepository interface {
+	ID() int
+	Name() string
+	URL() string
+	Distributions() []string
+	Components() []string
+
+	// DisabledPockets and DisabledComponents are the parts of the Ubuntu
+	// archive that aren't used.
+	DisabledPockets() []string
+	DisabledComponents() []string
+
+	Architectures() []string
+
+	// Key is the armored GPG key that signs the repository, if any.
+	Key() string
+
+	Enabled() bool
+
+	// DisableSources reports whether the deb-src lines are left out.
+	DisableSources() bool
+
+	// Update changes the fields of the repository that are set.
+	Update(PackageRepositoryArgs) error
+
+	// Delete removes the repository.
+	Delete() error
 }
 
 // Device represents some form of device in MAAS.
@@ -203,7 +1043,8 @@ type Device interface {
 	Delete() error
 }
 
-// Machine represents a physical machine.
+// Machine represents a physical machine. Its methods may be called by
+// multiple goroutines, including the ones updating it such as Start.
 type Machine interface {
 	OwnerDataHolder
 
@@ -215,12 +1056,45 @@ type Machine interface {
 	OperatingSystem() string
 	DistroSeries() string
 	Architecture() string
+	// DeployedImage returns the image and kernel the machine is running.
+	// The boolean result is false if the machine isn't deployed.
+	DeployedImage() (DeployedImage, bool)
 	Memory() int
 	CPUCount() int
 	HardwareInfo() map[string]string
 
 	IPAddresses() []string
 	PowerState() string
+	// PowerType is the power driver MAAS controls the machine with, such
+	// as "ipmi", "redfish", "virsh" or "manual".
+	PowerType() string
+	// PowerParameters returns the parameters of the power driver, such as
+	// its address and credentials. Only administrators can read them.
+	PowerParameters() (map[string]interface{}, error)
+	// BIOSBootMethod is how the firmware of the machine last booted from
+	// the network, such as "pxe" or "uefi". It is empty if MAAS hasn't seen
+	// the machine network boot.
+	BIOSBootMethod() string
+
+	// Netboot reports whether the machine boots from the network. MAAS
+	// turns it off for machines deployed to disk, so they boot locally.
+	Netboot() bool
+	// SetNetboot sets whether the machine boots from the network. MAAS only
+	// permits it for machines that the user can edit.
+	SetNetboot(enabled bool) error
+	// EphemeralDeploy reports whether the machine was deployed to memory
+	// rather than installed to disk.
+	EphemeralDeploy() bool
+	// HardwareSync reports whether MAAS keeps the hardware of the deployed
+	// machine in sync. It is always false before MAAS 3.2.
+	HardwareSync() bool
+	// DPU reports whether the machine is a data processing unit. It is
+	// always false before MAAS 3.2.
+	DPU() bool
+	// Diskless reports whether the machine is deployed and running from
+	// memory, either because an ephemeral deployment was requested or
+	// because the machine has no disks to install to.
+	Diskless() bool
 
 	// Devices returns a list of devices that match the params and have
 	// this Machine as the parent.
@@ -233,6 +1107,10 @@ type Machine interface {
 	StatusName() string
 	StatusMessage() string
 
+	// Updated is when the machine was last updated in MAAS, in UTC. It is
+	// the zero time if MAAS doesn't report it.
+	Updated() time.Time
+
 	// BootInterface returns the interface that was used to boot the Machine.
 	BootInterface() Interface
 	// InterfaceSet returns all the interfaces for the Machine.
@@ -240,6 +1118,33 @@ type Machine interface {
 	// Interface returns the interface for the machine that matches the id
 	// specified. If there is no match, nil is returned.
 	Interface(id int) Interface
+	// InterfaceByName returns the interface for the machine that matches the
+	// name specified. If there is no match, nil is returned.
+	InterfaceByName(name string) Interface
+	// InterfaceParents returns the parents of the interface specified, such
+	// as the members of a bond.
+	InterfaceParents(Interface) []Interface
+	// InterfaceChildren returns the children of the interface specified, such
+	// as the VLAN interfaces or bridges built on top of it.
+	InterfaceChildren(Interface) []Interface
+	// WalkInterfaces calls walkFn for each interface of the machine, starting
+	// with the interfaces without parents and then visiting the children of
+	// each interface, depth first. Each interface is visited once, with
+	// depth being the number of interfaces it is built upon. If walkFn
+	// returns an error, the walk stops and the error is returned.
+	WalkInterfaces(walkFn func(iface Interface, depth int) error) error
+
+	// SetDefaultGateway sets the default gateway of the machine to the
+	// gateway of the subnet of the specified link on the interface. If the
+	// linkID is zero, MAAS picks the gateway from the links of the interface.
+	// The IPv4 and IPv6 default gateways are set independently, based on the
+	// address family of the subnet.
+	SetDefaultGateway(interfaceID, linkID int) error
+
+	// PXEDHCPProvider returns what provides DHCP on the VLAN that the machine
+	// boots from. Unless the DHCP is managed by MAAS, the machine is not
+	// expected to be able to netboot.
+	PXEDHCPProvider() DHCPProvider
 
 	// PhysicalBlockDevices returns all the physical block devices on the machine.
 	PhysicalBlockDevices() []BlockDevice
@@ -249,6 +1154,9 @@ type Machine interface {
 
 	// BlockDevices returns all the physical and virtual block devices on the machine.
 	BlockDevices() []BlockDevice
+	// VirtualBlockDevices returns the virtual block devices on the machine,
+	// such as RAID arrays and LVM logical volumes.
+	VirtualBlockDevices() []BlockDevice
 	// BlockDevice returns the block device for the machine that matches the
 	// id specified. If there is no match, nil is returned.
 	BlockDevice(id int) BlockDevice
@@ -257,12 +1165,50 @@ type Machine interface {
 	// id specified. If there is no match, nil is returned.
 	Partition(id int) Partition
 
+	// RAIDs returns the software RAID arrays configured on the machine.
+	RAIDs() ([]RAID, error)
+
+	// CreateRAID creates a software RAID array from block devices and
+	// partitions of the machine. The array is exposed to the machine as a
+	// new virtual block device.
+	CreateRAID(CreateRAIDArgs) (RAID, error)
+
+	// VolumeGroups returns the LVM volume groups configured on the machine.
+	VolumeGroups() ([]VolumeGroup, error)
+
+	// CreateVolumeGroup creates an LVM volume group from block devices and
+	// partitions of the machine.
+	CreateVolumeGroup(CreateVolumeGroupArgs) (VolumeGroup, error)
+
+	// CreateBlockDevice adds a physical block device to the machine, for
+	// disks that commissioning didn't detect.
+	CreateBlockDevice(CreateBlockDeviceArgs) (BlockDevice, error)
+
+	// CaptureLayout returns a snapshot of the interfaces and storage of
+	// the machine, including its RAID arrays and LVM volume groups.
+	CaptureLayout() (MachineLayout, error)
+
+	// VMFSDatastores returns the VMFS datastores configured on the machine.
+	// Datastores are only used when deploying VMware ESXi.
+	VMFSDatastores() ([]VMFSDatastore, error)
+
+	// CreateVMFSDatastore creates a VMFS datastore from block devices and
+	// partitions of the machine.
+	CreateVMFSDatastore(CreateVMFSDatastoreArgs) (VMFSDatastore, error)
+
 	Zone() Zone
 	Pool() Pool
 
 	// Start the machine and install the operating system specified in the args.
 	Start(StartArgs) error
 
+	// RecoverFromFailure performs the actions needed to move the machine out
+	// of a failed status, such as re-commissioning a machine that failed
+	// commissioning. The actions planned for the status are returned, and
+	// with DryRun set they are returned without being performed. There is
+	// nothing to do for machines that are not in a failed status.
+	RecoverFromFailure(RecoverFromFailureArgs) ([]RecoveryAction, error)
+
 	// CreateDevice creates a new Device with this Machine as the parent.
 	// The device will have one interface that is linked to the specified subnet.
 	CreateDevice(CreateMachineDeviceArgs) (Device, error)
@@ -272,7 +1218,18 @@ type Machine interface {
 type Space interface {
 	ID() int
 	Name() string
+	Description() string
 	Subnets() []Subnet
+
+	// Update changes the name or description of the space.
+	Update(UpdateSpaceArgs) error
+
+	// Delete removes the space. Its VLANs are left without a space.
+	Delete() error
+
+	// AssignVLAN puts the VLAN, and so its subnets, in the space. Use
+	// VLAN.Update with ClearSpace to take it out again.
+	AssignVLAN(VLAN) error
 }
 
 // Subnet refers to an IP range on a VLAN.
@@ -289,6 +1246,237 @@ type Subnet interface {
 	// DNSServers is a list of ip addresses of the DNS servers for the subnet.
 	// This list may be empty.
 	DNSServers() []string
+
+	// AllowProxy reports whether the MAAS proxy accepts requests from the
+	// subnet.
+	AllowProxy() bool
+
+	// Managed reports whether MAAS manages the allocation of addresses in
+	// the subnet.
+	Managed() bool
+
+	// ActiveDiscovery reports whether the rack controllers periodically
+	// scan the subnet for neighbours.
+	ActiveDiscovery() bool
+
+	// AllowDNS reports whether the MAAS DNS servers resolve queries from
+	// the subnet.
+	AllowDNS() bool
+
+	// DisabledBootArchitectures returns the boot architectures that MAAS
+	// won't respond to on the subnet. This list may be empty.
+	DisabledBootArchitectures() []string
+
+	// Scan triggers an active discovery scan of the subnet on the rack
+	// controllers that have access to it. The scan runs asynchronously,
+	// the neighbours found are reported as discoveries.
+	Scan(SubnetScanArgs) (ScanResult, error)
+
+	// LastScan returns the result of the last scan triggered through this
+	// subnet, or nil if it hasn't been scanned. MAAS doesn't record scans,
+	// so scans triggered by other clients are not reported.
+	LastScan() ScanResult
+
+	// ReservedIPRanges returns the ranges of addresses of the subnet that
+	// are in use or reserved, such as dynamic ranges and the gateway.
+	ReservedIPRanges() ([]SubnetAddressRange, error)
+
+	// UnreservedIPRanges returns the ranges of addresses of the subnet that
+	// are free to be allocated.
+	UnreservedIPRanges() ([]SubnetAddressRange, error)
+
+	// Statistics returns a summary of the address usage of the subnet.
+	Statistics() (SubnetStatistics, error)
+
+	// IPAddresses returns the addresses of the subnet that are in use,
+	// along with their owner and the node they are assigned to.
+	IPAddresses() ([]SubnetIPAddress, error)
+
+	// Update changes the subnet, see UpdateSubnetArgs.
+	Update(UpdateSubnetArgs) error
+
+	// Delete removes the subnet.
+	Delete() error
+}
+
+// IPAddress is an IP address allocated by MAAS.
+type IPAddress interface {
+	IP() string
+	// AllocType is the MAAS allocation type, see AllocTypeName for a
+	// description of it.
+	AllocType() int
+	// AllocTypeName describes how the address was allocated, such as
+	// "User reserved" for the addresses reserved with ReserveIPAddress.
+	AllocTypeName() string
+	// Created is when the address was allocated, or the zero time if MAAS
+	// doesn't report it.
+	Created() time.Time
+	// Owner is the name of the user that allocated the address.
+	Owner() string
+	// Subnet returns the subnet of the address, or nil if it isn't in a
+	// subnet known to MAAS.
+	Subnet() Subnet
+}
+
+// IPRange is a range of addresses of a subnet that MAAS either hands out
+// with DHCP or never assigns, depending on its type.
+type IPRange interface {
+	ID() int
+	// Type is either "dynamic" or "reserved", see IPRangeType.
+	Type() string
+	StartIP() string
+	EndIP() string
+	Comment() string
+	// Owner is the name of the user that created the range.
+	Owner() string
+	Subnet() Subnet
+
+	// Update changes the addresses or comment of the range.
+	Update(UpdateIPRangeArgs) error
+
+	// Delete removes the range.
+	Delete() error
+}
+
+// RackController is a MAAS controller that provides DHCP, TFTP and power
+// control to the machines of the networks it is connected to.
+type RackController interface {
+	SystemID() string
+	Hostname() string
+	FQDN() string
+	Architecture() string
+	// Version is the version of MAAS the rack controller runs. It is empty
+	// for older rack controllers, which don't report it.
+	Version() string
+	IPAddresses() []string
+
+	// Services returns the status of the MAAS services on the rack
+	// controller.
+	Services() []ServiceStatus
+
+	// Service returns the status of the named service, and false if the
+	// rack controller doesn't report it.
+	Service(name string) (ServiceStatus, bool)
+
+	// InterfaceSet returns the network interfaces of the rack controller.
+	InterfaceSet() []Interface
+
+	// ImportBootImages makes the rack controller sync its boot images
+	// from the region.
+	ImportBootImages() error
+
+	// BootImagesStatus returns whether the boot images of the rack
+	// controller are in sync with the region: "synced", "syncing",
+	// "out-of-sync" or "unknown".
+	BootImagesStatus() (string, error)
+}
+
+// RegionController is a MAAS controller that serves the API, the web UI
+// and DNS, and coordinates the rack controllers.
+type RegionController interface {
+	SystemID() string
+	Hostname() string
+	FQDN() string
+	Architecture() string
+	// Version is the version of MAAS the region controller runs.
+	Version() string
+	IPAddresses() []string
+
+	// Services returns the status of the MAAS services on the region
+	// controller, such as "regiond", "bind9", "ntp_region" and "proxy".
+	Services() []ServiceStatus
+
+	// Service returns the status of the named service, and false if the
+	// region controller doesn't report it.
+	Service(name string) (ServiceStatus, bool)
+
+	// Processes returns the status of the region processes. MAAS doesn't
+	// report the processes individually, the regiond service is degraded
+	// if some of them aren't running and its StatusInfo says how many
+	// are.
+	Processes() ServiceStatus
+
+	// InterfaceSet returns the network interfaces of the region
+	// controller.
+	InterfaceSet() []Interface
+}
+
+// DHCPSnippet is a piece of ISC DHCP server configuration that MAAS adds
+// to the configuration of its DHCP servers. A snippet applies to a single
+// node, to a subnet, or globally.
+type DHCPSnippet interface {
+	ID() int
+	Name() string
+	Value() string
+	Description() string
+	Enabled() bool
+	// Global is true if the snippet isn't scoped to a node or subnet.
+	Global() bool
+	// Node is the system ID of the node the snippet applies to, if any.
+	Node() string
+	// Subnet is the subnet the snippet applies to, if any.
+	Subnet() Subnet
+
+	// Update changes the snippet, including its scope.
+	Update(UpdateDHCPSnippetArgs) error
+
+	// Enable adds the snippet to the DHCP configuration.
+	Enable() error
+
+	// Disable leaves the snippet out of the DHCP configuration without
+	// removing it.
+	Disable() error
+
+	// Delete removes the snippet.
+	Delete() error
+}
+
+// Discovery is a neighbour that a rack controller has observed on one of its
+// networks, such as a device that MAAS doesn't know about.
+type Discovery interface {
+	ID() string
+	IP() string
+	MACAddress() string
+	// MACOrganization is the vendor registered for the MAC address, if
+	// known.
+	MACOrganization() string
+	// Hostname is the name observed through mDNS, if any.
+	Hostname() string
+	FabricName() string
+	VID() int
+	// Observer is the system ID of the rack controller that observed the
+	// neighbour.
+	Observer() string
+	ObserverHostname() string
+	// LastSeen is the last time the neighbour was observed, in UTC.
+	LastSeen() time.Time
+}
+
+// ScanResult is the response of MAAS to a request for an active discovery
+// scan. The identifiers of the rack controllers are their system IDs.
+type ScanResult interface {
+	// Result is a human readable summary of the scan request.
+	Result() string
+
+	// StartedOn are the rack controllers the scan was started on.
+	StartedOn() []string
+
+	// AttemptedOn are the rack controllers that were asked to scan.
+	AttemptedOn() []string
+
+	// FailedOn are the rack controllers that failed to start the scan.
+	FailedOn() []string
+
+	// FailedToConnectTo are the rack controllers that couldn't be
+	// contacted.
+	FailedToConnectTo() []string
+
+	// RPCErrors maps rack controllers to the error they returned.
+	RPCErrors() map[string]string
+
+	// Requested is the time the scan was requested, in UTC, according to
+	// the clock of the client.
+	Requested() time.Time
 }
 
 // StaticRoute defines an explicit route that users have requested to be added
@@ -326,12 +1514,26 @@ type Interface interface {
 
 	VLAN() VLAN
 	Links() []Link
+	// Discovered returns the addresses observed on the interface during
+	// commissioning, which may differ from the configured Links.
+	Discovered() []DiscoveredLink
 
 	MACAddress() string
 	EffectiveMTU() int
 
-	// Params is a JSON field, and defaults to an empty string, but is almost
-	// always a JSON object in practice. Gleefully ignoring it until we need it.
+	// LinkConnected reports whether the interface has a carrier. MAAS
+	// versions that don't report it are assumed to be connected.
+	LinkConnected() bool
+	// LinkSpeed is the speed negotiated on the link, in Mbit/s. It is zero
+	// if unknown.
+	LinkSpeed() int
+	// InterfaceSpeed is the maximum speed supported by the interface, in
+	// Mbit/s. It is zero if unknown.
+	InterfaceSpeed() int
+
+	// Params returns the type specific parameters of the interface, such as
+	// the bond mode for bonds. Params is nil if MAAS returned no params.
+	Params() InterfaceParams
 
 	// Update the name, mac address or VLAN.
 	Update(UpdateInterfaceArgs) error
@@ -348,6 +1550,47 @@ type Interface interface {
 	UnlinkSubnet(Subnet) error
 }
 
+// InterfaceParams represents the parameters of an Interface. Which of the
+// parameters are set depends on the type of the interface.
+type InterfaceParams interface {
+	// MTU is the MTU requested for the interface, zero if not set.
+	MTU() int
+	// AcceptRA and Autoconf only apply to IPv6.
+	AcceptRA() bool
+	Autoconf() bool
+
+	// Bond returns the bond parameters. It is nil unless the interface is
+	// a bond.
+	Bond() BondParams
+	// Bridge returns the bridge parameters. It is nil unless the interface
+	// is a bridge.
+	Bridge() BridgeParams
+
+	// Raw returns a copy of the params as returned by MAAS, including any
+	// that aren't otherwise exposed.
+	Raw() map[string]interface{}
+}
+
+// BondParams represents the parameters of a bond interface.
+type BondParams interface {
+	// Mode is the bonding mode, e.g. "active-backup" or "802.3ad".
+	Mode() string
+	MIIMon() int
+	DownDelay() int
+	UpDelay() int
+	LACPRate() string
+	XmitHashPolicy() string
+	NumGratARP() int
+}
+
+// BridgeParams represents the parameters of a bridge interface.
+type BridgeParams interface {
+	// Type is the bridge type, either "standard" or "ovs".
+	Type() string
+	STP() bool
+	FD() int
+}
+
 // Link represents a network link between an Interface and a Subnet.
 type Link interface {
 	ID() int
@@ -358,12 +1601,24 @@ type Link interface {
 	IPAddress() string
 }
 
+// DiscoveredLink represents an address that was observed on an Interface,
+// along with the Subnet it belongs to.
+type DiscoveredLink interface {
+	// Subnet may be nil if the address is not in a subnet known to MAAS.
+	Subnet() Subnet
+	IPAddress() string
+}
+
 // FileSystem represents a formatted filesystem mounted at a location.
 type FileSystem interface {
-	// Type is the format type, e.g. "ext4".
+	// Type is the format type, e.g. "ext4". The types that storage
+	// devices can be formatted with are the FileSystemType values.
 	Type() string
 
 	MountPoint() string
+	// MountOptions are the options passed to mount, empty if there are
+	// none.
+	MountOptions() string
 	Label() string
 	UUID() string
 }
@@ -385,12 +1640,33 @@ type StorageDevice interface {
 
 	// FileSystem may be nil if not mounted.
 	FileSystem() FileSystem
+
+	// Format formats the storage device with a filesystem, replacing the
+	// FileSystem of the device.
+	Format(FormatStorageDeviceArgs) error
+
+	// Unformat removes the filesystem from the storage device.
+	Unformat() error
+
+	// Mount mounts the filesystem of the storage device.
+	Mount(MountStorageDeviceArgs) error
+
+	// Unmount unmounts the filesystem of the storage device.
+	Unmount() error
 }
 
 // Partition represents a partition of a block device. It may be mounted
 // as a filesystem.
 type Partition interface {
 	StorageDevice
+
+	// Delete removes the partition from its block device.
+	Delete() error
+
+	// Resize changes the size of the partition to the number of bytes
+	// specified. MAAS versions that don't support resizing partitions
+	// return an error satisfying errors.IsNotSupported.
+	Resize(size uint64) error
 }
 
 // BlockDevice represents an entire block device on the machine.
@@ -399,6 +1675,7 @@ type BlockDevice interface {
 
 	Name() string
 	Model() string
+	Serial() string
 	IDPath() string
 
 	BlockSize() uint64
@@ -406,10 +1683,107 @@ type BlockDevice interface {
 
 	Partitions() []Partition
 
+	// Parent returns the RAID array, volume group or bcache set backing a
+	// virtual block device. It is nil for physical block devices, and for
+	// virtual block devices if MAAS doesn't report the parent.
+	Parent() *BlockDeviceParent
+
+	// Update changes the attributes of the block device.
+	Update(UpdateBlockDeviceArgs) error
+
+	// Delete removes the block device from the machine.
+	Delete() error
+
+	// CreatePartition creates a new partition on the block device, and
+	// adds it to the partitions of the block device.
+	CreatePartition(CreatePartitionArgs) (Partition, error)
+
 	// There are some other attributes for block devices, but we can
 	// expose them on an as needed basis.
 }
 
+// RAID represents a software RAID array on a machine.
+type RAID interface {
+	ID() int
+	Name() string
+	UUID() string
+	Level() RAIDLevel
+	// Size is the usable size of the array in bytes.
+	Size() uint64
+
+	// Devices returns the active block devices and partitions of the
+	// array.
+	Devices() []StorageDevice
+	// SpareDevices returns the spare block devices and partitions of the
+	// array.
+	SpareDevices() []StorageDevice
+	// VirtualDevice is the block device that represents the array, such
+	// as md0. It may be nil.
+	VirtualDevice() BlockDevice
+
+	// Update changes the name or UUID of the array, or adds and removes
+	// devices.
+	Update(UpdateRAIDArgs) error
+
+	// Delete removes the array. The devices of the array are released.
+	Delete() error
+}
+
+// VolumeGroup represents an LVM volume group on a machine.
+type VolumeGroup interface {
+	ID() int
+	Name() string
+	UUID() string
+
+	// Size, UsedSize and AvailableSize are in bytes.
+	Size() uint64
+	UsedSize() uint64
+	AvailableSize() uint64
+
+	// Devices returns the block devices and partitions that are the
+	// physical volumes of the volume group.
+	Devices() []StorageDevice
+
+	// LogicalVolumes returns the logical volumes of the volume group. They
+	// are virtual block devices of the machine.
+	LogicalVolumes() []BlockDevice
+
+	// CreateLogicalVolume creates a logical volume in the volume group.
+	CreateLogicalVolume(CreateLogicalVolumeArgs) (BlockDevice, error)
+
+	// DeleteLogicalVolume deletes one of the logical volumes of the volume
+	// group.
+	DeleteLogicalVolume(BlockDevice) error
+
+	// Delete removes the volume group and its logical volumes.
+	Delete() error
+}
+
+// VMFSDatastore represents a VMFS datastore on a machine that is deployed
+// with VMware ESXi.
+type VMFSDatastore interface {
+	ID() int
+	Name() string
+	UUID() string
+	// Size is in bytes.
+	Size() uint64
+
+	// FSType is the VMFS version of the datastore, such as "vmfs6".
+	FSType() string
+	MountPoint() string
+
+	// Devices returns the block devices and partitions that make up the
+	// datastore.
+	Devices() []StorageDevice
+
+	// Update changes the name or UUID of the datastore, or adds devices to
+	// it.
+	Update(UpdateVMFSDatastoreArgs) error
+
+	// Delete removes the datastore.
+	Delete() error
+}
+
 // OwnerDataHolder represents any MAAS object that can store key/value
 // data.
 type OwnerDataHolder interface {
@@ -425,10 +1799,54 @@ type OwnerDataHolder interface {
 	SetOwnerData(map[string]string) error
 }
 
+// Lease is a claim on a machine by a holder until an expiry time, kept in
+// the owner data of the machine. It lets users that share machines find the
+// ones that are no longer needed.
+type Lease interface {
+	Machine() Machine
+	Holder() string
+	Purpose() string
+	// Expiry is the zero time if the recorded expiry isn't valid.
+	Expiry() time.Time
+	Expired() bool
+
+	// Renew extends the lease until the duration from now.
+	Renew(time.Duration) error
+
+	// Reclaim ends the lease, and releases the machine if requested.
+	Reclaim(ReclaimLeaseArgs) error
+}
+
 // Tag represents a MAAS tag.
 type Tag interface {
 	Name() string
 	Comment() string
 	Definition() string
 	KernelOpts() string
+
+	// Update changes the fields of the tag that are set. Changing the
+	// definition re-evaluates it against all the nodes.
+	Update(UpdateTagArgs) error
+
+	// Delete removes the tag from the nodes and from MAAS.
+	Delete() error
+
+	// Rebuild re-evaluates the definition of the tag against all the
+	// nodes, in the background.
+	Rebuild() error
+
+	// Machines returns the machines with the tag.
+	Machines() ([]Machine, error)
+
+	// Devices returns the devices with the tag.
+	Devices() ([]Device, error)
+
+	// Nodes returns all the nodes with the tag, including the rack and
+	// region controllers.
+	Nodes() ([]TaggedNode, error)
+
+	// UpdateNodes tags and untags nodes in bulk, and returns the number of
+	// nodes added and removed. The system IDs are sent in batches, so an
+	// error may leave some of the nodes changed.
+	UpdateNodes(UpdateTagNodesArgs) (added int, removed int, err error)
 }
@@ -0,0 +1,195 @@
+// Copyright 2022 Canonical Ltd.
+// Licensed under the LGPLv3, see LICENCE file for details.
+
+package gomaasapi
+
+import (
+	"time"
+
+	"github.com/juju/errors"
+	"github.com/juju/schema"
+	"github.com/juju/version"
+)
+
+type ipAddress struct {
+	controller *controller
+
+	resourceURI string
+
+	ip            string
+	allocType     int
+	allocTypeName string
+	// created is kept as returned by MAAS, and parsed in the time location
+	// of the controller when requested.
+	created string
+	owner   string
+
+	subnet *subnet
+}
+
+// IP implements IPAddress.
+func (a *ipAddress) IP() string {
+	return a.ip
+}
+
+// AllocType implements IPAddress.
+func (a *ipAddress) AllocType() int {
+	return a.allocType
+}
+
+// AllocTypeName implements IPAddress.
+func (a *ipAddress) AllocTypeName() string {
+	return a.allocTypeName
+}
+
+// Created implements IPAddress.
+func (a *ipAddress) Created() time.Time {
+	if a.created == "" {
+		return time.Time{}
+	}
+	var location *time.Location
+	if a.controller != nil {
+		location = a.controller.timeLocation
+	}
+	created, err := parseTimestamp(a.created, location)
+	if err != nil {
+		logger.Debugf("ip address %s: %v", a.ip, err)
+		return time.Time{}
+	}
+	return created
+}
+
+// Owner implements IPAddress.
+func (a *ipAddress) Owner() string {
+	return a.owner
+}
+
+// Subnet implements IPAddress.
+func (a *ipAddress) Subnet() Subnet {
+	if a.subnet == nil {
+		return nil
+	}
+	a.subnet.controller = a.controller
+	return a.subnet
+}
+
+func readIPAddress(controllerVersion version.Number, source interface{}) (*ipAddress, error) {
+	readFunc, err := getIPAddressDeserializationFunc(controllerVersion)
+	if err != nil {
+		return nil, errors.Trace(err)
+	}
+
+	checker := schema.StringMap(schema.Any())
+	coerced, err := checker.Coerce(source, nil)
+	if err != nil {
+		return nil, WrapWithDeserializationError(err, "ip address base schema check failed")
+	}
+	valid := coerced.(map[string]interface{})
+	return readFunc(valid)
+}
+
+func readIPAddresses(controllerVersion version.Number, source interface{}) ([]*ipAddress, error) {
+	readFunc, err := getIPAddressDeserializationFunc(controllerVersion)
+	if err != nil {
+		return nil, errors.Trace(err)
+	}
+
+	checker := schema.List(schema.StringMap(schema.Any()))
+	coerced, err := checker.Coerce(source, nil)
+	if err != nil {
+		return nil, WrapWithDeserializationError(err, "ip address base schema check failed")
+	}
+	valid := coerced.([]interface{})
+	return readIPAddressList(valid, readFunc)
+}
+
+func getIPAddressDeserializationFunc(controllerVersion version.Number) (ipAddressDeserializationFunc, error) {
+	var deserialisationVersion version.Number
+	for v := range ipAddressDeserializationFuncs {
+		if v.Compare(deserialisationVersion) > 0 && v.Compare(controllerVersion) <= 0 {
+			deserialisationVersion = v
+		}
+	}
+	if deserialisationVersion == version.Zero {
+		return nil, NewUnsupportedVersionError("no ip address read func for version %s", controllerVersion)
+	}
+	return ipAddressDeserializationFuncs[deserialisationVersion], nil
+}
+
+// readIPAddressList expects the values of the sourceList to be string maps.
+func readIPAddressList(sourceList []interface{}, readFunc ipAddressDeserializationFunc) ([]*ipAddress, error) {
+	result := make([]*ipAddress, 0, len(sourceList))
+	for i, value := range sourceList {
+		source, ok := value.(map[string]interface{})
+		if !ok {
+			return nil, NewDeserializationError("unexpected value for ip address %d, %T", i, value)
+		}
+		address, err := readFunc(source)
+		if err != nil {
+			return nil, errors.Annotatef(err, "ip address %d", i)
+		}
+		result = append(result, address)
+	}
+	return result, nil
+}
+
+type ipAddressDeserializationFunc func(map[string]interface{}) (*ipAddress, error)
+
+var ipAddressDeserializationFuncs = map[version.Number]ipAddressDeserializationFunc{
+	twoDotOh: ipAddress_2_0,
+}
+
+func ipAddress_2_0(source map[string]interface{}) (*ipAddress, error) {
+	fields := schema.Fields{
+		"resource_uri": schema.String(),
+
+		"ip":              schema.String(),
+		"alloc_type":      schema.ForceInt(),
+		"alloc_type_name": schema.String(),
+		"created":         schema.OneOf(schema.Nil(""), schema.String()),
+		"owner": schema.OneOf(schema.Nil(""), schema.FieldMap(
+			schema.Fields{"username": schema.String()},
+			nil,
+		)),
+
+		"subnet": schema.OneOf(schema.Nil(""), schema.StringMap(schema.Any())),
+	}
+	defaults := schema.Defaults{
+		"alloc_type_name": "",
+		"created":         nil,
+		"owner":           nil,
+		"subnet":          nil,
+	}
+	checker := schema.FieldMap(fields, defaults)
+	coerced, err := checker.Coerce(source, nil)
+	if err != nil {
+		return nil, WrapWithDeserializationError(err, "ip address 2.0 schema check failed")
+	}
+	valid := coerced.(map[string]interface{})
+	// From here we know that the map returned from the schema coercion
+	// contains fields of the right type.
+
+	var subnet *subnet
+	if subnetMap, ok := valid["subnet"].(map[string]interface{}); ok {
+		if subnet, err = subnet_2_0(subnetMap); err != nil {
+			return nil, errors.Trace(err)
+		}
+	}
+	var owner string
+	if ownerMap, ok := valid["owner"].(map[string]interface{}); ok {
+		owner = ownerMap["username"].(string)
+	}
+	created, _ := valid["created"].(string)
+	result := &ipAddress{
+		resourceURI: valid["resource_uri"].(string),
+
+		ip:            valid["ip"].(string),
+		allocType:     valid["alloc_type"].(int),
+		allocTypeName: valid["alloc_type_name"].(string),
+		created:       created,
+		owner:         owner,
+
+		subnet: subnet,
+	}
+	return result, nil
+}
@@ -0,0 +1,115 @@
+// Copyright 2022 Canonical Ltd.
+// Licensed under the LGPLv3, see LICENCE file for details.
+
+package gomaasapi
+
+import (
+	"time"
+
+	jc "github.com/juju/testing/checkers"
+	"github.com/juju/version"
+	gc "gopkg.in/check.v1"
+)
+
+type ipAddressSuite struct{}
+
+var _ = gc.Suite(&ipAddressSuite{})
+
+func (*ipAddressSuite) TestReadIPAddressesBadSchema(c *gc.C) {
+	_, err := readIPAddresses(twoDotOh, "wat?")
+	c.Check(err, jc.Satisfies, IsDeserializationError)
+	c.Assert(err.Error(), gc.Equals, `ip address base schema check failed: expected list, got string("wat?")`)
+}
+
+func (*ipAddressSuite) TestReadIPAddresses(c *gc.C) {
+	addresses, err := readIPAddresses(twoDotOh, parseJSON(c, ipAddressesResponse))
+	c.Assert(err, jc.ErrorIsNil)
+	c.Assert(addresses, gc.HasLen, 2)
+
+	address := addresses[0]
+	c.Check(address.IP(), gc.Equals, "192.168.100.20")
+	c.Check(address.AllocType(), gc.Equals, 4)
+	c.Check(address.AllocTypeName(), gc.Equals, "User reserved")
+	c.Check(address.Created(), gc.Equals, time.Date(2022, 3, 4, 12, 30, 0, 0, time.UTC))
+	c.Check(address.Owner(), gc.Equals, "admin")
+	subnet := address.Subnet()
+	c.Assert(subnet, gc.NotNil)
+	c.Check(subnet.CIDR(), gc.Equals, "192.168.100.0/24")
+
+	address = addresses[1]
+	c.Check(address.Owner(), gc.Equals, "")
+	c.Check(address.Created().IsZero(), jc.IsTrue)
+	c.Check(address.Subnet(), gc.IsNil)
+}
+
+func (*ipAddressSuite) TestCreatedLocation(c *gc.C) {
+	address, err := readIPAddress(twoDotOh, parseJSON(c, ipAddressResponse))
+	c.Assert(err, jc.ErrorIsNil)
+	address.controller = &controller{timeLocation: time.FixedZone("UTC-1", -60*60)}
+	c.Check(address.Created(), gc.Equals, time.Date(2022, 3, 4, 13, 30, 0, 0, time.UTC))
+}
+
+func (*ipAddressSuite) TestLowVersion(c *gc.C) {
+	_, err := readIPAddresses(version.MustParse("1.9.0"), parseJSON(c, ipAddressesResponse))
+	c.Assert(err, jc.Satisfies, IsUnsupportedVersionError)
+	c.Assert(err.Error(), gc.Equals, `no ip address read func for version 1.9.0`)
+}
+
+func (*ipAddressSuite) TestHighVersion(c *gc.C) {
+	addresses, err := readIPAddresses(version.MustParse("2.1.9"), parseJSON(c, ipAddressesResponse))
+	c.Assert(err, jc.ErrorIsNil)
+	c.Assert(addresses, gc.HasLen, 2)
+}
+
+const (
+	ipAddressResponse = `
+{
+    "alloc_type": 4,
+    "alloc_type_name": "User reserved",
+    "created": "2022-03-04T12:30:00.000",
+    "ip": "192.168.100.20",
+    "owner": {
+        "is_superuser": true,
+        "username": "admin",
+        "email": "admin@example.com",
+        "is_local": true,
+        "resource_uri": "/MAAS/api/2.0/users/admin/"
+    },
+    "subnet": {
+        "gateway_ip": "192.168.100.1",
+        "name": "192.168.100.0/24",
+        "vlan": {
+            "fabric": "fabric-0",
+            "resource_uri": "/MAAS/api/2.0/vlans/1/",
+            "name": "untagged",
+            "secondary_rack": null,
+            "primary_rack": "4y3h7n",
+            "vid": 0,
+            "dhcp_on": true,
+            "id": 1,
+            "mtu": 1500
+        },
+        "space": "space-0",
+        "id": 1,
+        "resource_uri": "/MAAS/api/2.0/subnets/1/",
+        "dns_servers": [],
+        "cidr": "192.168.100.0/24",
+        "rdns_mode": 2
+    },
+    "interface_set": [],
+    "resource_uri": "/MAAS/api/2.0/ipaddresses/"
+}
+`
+	ipAddressesResponse = "[" + ipAddressResponse + `,
+{
+    "alloc_type": 4,
+    "alloc_type_name": "User reserved",
+    "created": null,
+    "ip": "10.0.0.5",
+    "owner": null,
+    "subnet": null,
+    "interface_set": [],
+    "resource_uri": "/MAAS/api/2.0/ipaddresses/"
+}
+]`
+)
@@ -0,0 +1,283 @@
+// Copyright 2022 Canonical Ltd.
+// Licensed under the LGPLv3, see LICENCE file for details.
+
+package gomaasapi
+
+import (
+	"net"
+	"net/http"
+
+	"github.com/juju/errors"
+	"github.com/juju/schema"
+	"github.com/juju/version"
+)
+
+// IPRangeType is the type of the IP range type constants used for
+// CreateIPRangeArgs.
+type IPRangeType string
+
+const (
+	// IPRangeTypeDynamic - The addresses of the range are handed out by the
+	// MAAS DHCP server. A managed subnet needs a dynamic range before DHCP
+	// can be enabled on its VLAN.
+	IPRangeTypeDynamic IPRangeType = "dynamic"
+
+	// IPRangeTypeReserved - The addresses of the range are never assigned
+	// by MAAS.
+	IPRangeTypeReserved IPRangeType = "reserved"
+)
+
+type ipRange struct {
+	controller *controller
+
+	resourceURI string
+
+	id      int
+	type_   string
+	startIP string
+	endIP   string
+	comment string
+	owner   string
+
+	subnet *subnet
+}
+
+func (r *ipRange) updateFrom(other *ipRange) {
+	r.resourceURI = other.resourceURI
+	r.id = other.id
+	r.type_ = other.type_
+	r.startIP = other.startIP
+	r.endIP = other.endIP
+	r.comment = other.comment
+	r.owner = other.owner
+	r.subnet = other.subnet
+}
+
+// ID implements IPRange.
+func (r *ipRange) ID() int {
+	return r.id
+}
+
+// Type implements IPRange.
+func (r *ipRange) Type() string {
+	return r.type_
+}
+
+// StartIP implements IPRange.
+func (r *ipRange) StartIP() string {
+	return r.startIP
+}
+
+// EndIP implements IPRange.
+func (r *ipRange) EndIP() string {
+	return r.endIP
+}
+
+// Comment implements IPRange.
+func (r *ipRange) Comment() string {
+	return r.comment
+}
+
+// Owner implements IPRange.
+func (r *ipRange) Owner() string {
+	return r.owner
+}
+
+// Subnet implements IPRange.
+func (r *ipRange) Subnet() Subnet {
+	if r.subnet == nil {
+		return nil
+	}
+	r.subnet.controller = r.controller
+	return r.subnet
+}
+
+// UpdateIPRangeArgs is an argument struct for IPRange.Update. Only the
+// fields that are set are changed.
+type UpdateIPRangeArgs struct {
+	StartIP string
+	EndIP   string
+	Comment string
+}
+
+// Validate ensures that the addresses that are set are valid.
+func (a *UpdateIPRangeArgs) Validate() error {
+	if a.StartIP != "" && net.ParseIP(a.StartIP) == nil {
+		return errors.NotValidf("StartIP %q", a.StartIP)
+	}
+	if a.EndIP != "" && net.ParseIP(a.EndIP) == nil {
+		return errors.NotValidf("EndIP %q", a.EndIP)
+	}
+	return nil
+}
+
+// Update implements IPRange.
+func (r *ipRange) Update(args UpdateIPRangeArgs) error {
+	if err := args.Validate(); err != nil {
+		return errors.Trace(err)
+	}
+	params := NewURLParams()
+	params.MaybeAdd("start_ip", args.StartIP)
+	params.MaybeAdd("end_ip", args.EndIP)
+	params.MaybeAdd("comment", args.Comment)
+	source, err := r.controller.put(r.resourceURI, params.Values)
+	if err != nil {
+		if svrErr, ok := errors.Cause(err).(ServerError); ok {
+			switch svrErr.StatusCode {
+			case http.StatusNotFound:
+				return wrapError(err, NewNoMatchError(svrErr.BodyMessage))
+			case http.StatusForbidden:
+				return wrapError(err, NewPermissionError(svrErr.BodyMessage))
+			case http.StatusBadRequest:
+				return wrapError(err, NewBadRequestError(svrErr.BodyMessage))
+			}
+		}
+		return NewUnexpectedError(err)
+	}
+
+	response, err := readIPRange(r.controller.schemaVersion(), source)
+	if err != nil {
+		return errors.Trace(err)
+	}
+	r.updateFrom(response)
+	return nil
+}
+
+// Delete implements IPRange.
+func (r *ipRange) Delete() error {
+	err := r.controller.delete(r.resourceURI)
+	if err != nil {
+		if svrErr, ok := errors.Cause(err).(ServerError); ok {
+			switch svrErr.StatusCode {
+			case http.StatusNotFound:
+				return wrapError(err, NewNoMatchError(svrErr.BodyMessage))
+			case http.StatusForbidden:
+				return wrapError(err, NewPermissionError(svrErr.BodyMessage))
+			}
+		}
+		return NewUnexpectedError(err)
+	}
+	return nil
+}
+
+func readIPRange(controllerVersion version.Number, source interface{}) (*ipRange, error) {
+	readFunc, err := getIPRangeDeserializationFunc(controllerVersion)
+	if err != nil {
+		return nil, errors.Trace(err)
+	}
+
+	checker := schema.StringMap(schema.Any())
+	coerced, err := checker.Coerce(source, nil)
+	if err != nil {
+		return nil, WrapWithDeserializationError(err, "ip range base schema check failed")
+	}
+	valid := coerced.(map[string]interface{})
+	return readFunc(valid)
+}
+
+func readIPRanges(controllerVersion version.Number, source interface{}) ([]*ipRange, error) {
+	readFunc, err := getIPRangeDeserializationFunc(controllerVersion)
+	if err != nil {
+		return nil, errors.Trace(err)
+	}
+
+	checker := schema.List(schema.StringMap(schema.Any()))
+	coerced, err := checker.Coerce(source, nil)
+	if err != nil {
+		return nil, WrapWithDeserializationError(err, "ip range base schema check failed")
+	}
+	valid := coerced.([]interface{})
+	return readIPRangeList(valid, readFunc)
+}
+
+func getIPRangeDeserializationFunc(controllerVersion version.Number) (ipRangeDeserializationFunc, error) {
+	var deserialisationVersion version.Number
+	for v := range ipRangeDeserializationFuncs {
+		if v.Compare(deserialisationVersion) > 0 && v.Compare(controllerVersion) <= 0 {
+			deserialisationVersion = v
+		}
+	}
+	if deserialisationVersion == version.Zero {
+		return nil, NewUnsupportedVersionError("no ip range read func for version %s", controllerVersion)
+	}
+	return ipRangeDeserializationFuncs[deserialisationVersion], nil
+}
+
+// readIPRangeList expects the values of the sourceList to be string maps.
+func readIPRangeList(sourceList []interface{}, readFunc ipRangeDeserializationFunc) ([]*ipRange, error) {
+	result := make([]*ipRange, 0, len(sourceList))
+	for i, value := range sourceList {
+		source, ok := value.(map[string]interface{})
+		if !ok {
+			return nil, NewDeserializationError("unexpected value for ip range %d, %T", i, value)
+		}
+		ipRange, err := readFunc(source)
+		if err != nil {
+			return nil, errors.Annotatef(err, "ip range %d", i)
+		}
+		result = append(result, ipRange)
+	}
+	return result, nil
+}
+
+type ipRangeDeserializationFunc func(map[string]interface{}) (*ipRange, error)
+
+var ipRangeDeserializationFuncs = map[version.Number]ipRangeDeserializationFunc{
+	twoDotOh: ipRange_2_0,
+}
+
+func ipRange_2_0(source map[string]interface{}) (*ipRange, error) {
+	fields := schema.Fields{
+		"resource_uri": schema.String(),
+
+		"id":       schema.ForceInt(),
+		"type":     schema.String(),
+		"start_ip": schema.String(),
+		"end_ip":   schema.String(),
+		"comment":  schema.OneOf(schema.Nil(""), schema.String()),
+		"user": schema.OneOf(schema.Nil(""), schema.FieldMap(
+			schema.Fields{"username": schema.String()},
+			nil,
+		)),
+
+		"subnet": schema.OneOf(schema.Nil(""), schema.StringMap(schema.Any())),
+	}
+	defaults := schema.Defaults{
+		"comment": "",
+		"user":    nil,
+		"subnet":  nil,
+	}
+	checker := schema.FieldMap(fields, defaults)
+	coerced, err := checker.Coerce(source, nil)
+	if err != nil {
+		return nil, WrapWithDeserializationError(err, "ip range 2.0 schema check failed")
+	}
+	valid := coerced.(map[string]interface{})
+	// From here we know that the map returned from the schema coercion
+	// contains fields of the right type.
+
+	var subnet *subnet
+	if subnetMap, ok := valid["subnet"].(map[string]interface{}); ok {
+		if subnet, err = subnet_2_0(subnetMap); err != nil {
+			return nil, errors.Trace(err)
+		}
+	}
+	var owner string
+	if userMap, ok := valid["user"].(map[string]interface{}); ok {
+		owner = userMap["username"].(string)
+	}
+	comment, _ := valid["comment"].(string)
+	result := &ipRange{
+		resourceURI: valid["resource_uri"].(string),
+
+		id:      valid["id"].(int),
+		type_:   valid["type"].(string),
+		startIP: valid["start_ip"].(string),
+		endIP:   valid["end_ip"].(string),
+		comment: comment,
+		owner:   owner,
+
+		subnet: subnet,
+	}
+	return result, nil
+}
@@ -0,0 +1,179 @@
+// Copyright 2022 Canonical Ltd.
+// Licensed under the LGPLv3, see LICENCE file for details.
+
+package gomaasapi
+
+import (
+	"net/http"
+
+	"github.com/juju/errors"
+	"github.com/juju/testing"
+	jc "github.com/juju/testing/checkers"
+	"github.com/juju/version"
+	gc "gopkg.in/check.v1"
+)
+
+type ipRangeSuite struct {
+	testing.LoggingCleanupSuite
+}
+
+var _ = gc.Suite(&ipRangeSuite{})
+
+func (*ipRangeSuite) TestReadIPRangesBadSchema(c *gc.C) {
+	_, err := readIPRanges(twoDotOh, "wat?")
+	c.Check(err, jc.Satisfies, IsDeserializationError)
+	c.Assert(err.Error(), gc.Equals, `ip range base schema check failed: expected list, got string("wat?")`)
+}
+
+func (*ipRangeSuite) TestReadIPRanges(c *gc.C) {
+	ipRanges, err := readIPRanges(twoDotOh, parseJSON(c, ipRangesResponse))
+	c.Assert(err, jc.ErrorIsNil)
+	c.Assert(ipRanges, gc.HasLen, 2)
+
+	ipRange := ipRanges[0]
+	c.Check(ipRange.ID(), gc.Equals, 1)
+	c.Check(ipRange.Type(), gc.Equals, "dynamic")
+	c.Check(ipRange.StartIP(), gc.Equals, "192.168.100.100")
+	c.Check(ipRange.EndIP(), gc.Equals, "192.168.100.200")
+	c.Check(ipRange.Comment(), gc.Equals, "")
+	c.Check(ipRange.Owner(), gc.Equals, "")
+	subnet := ipRange.Subnet()
+	c.Assert(subnet, gc.NotNil)
+	c.Check(subnet.CIDR(), gc.Equals, "192.168.100.0/24")
+
+	ipRange = ipRanges[1]
+	c.Check(ipRange.Type(), gc.Equals, "reserved")
+	c.Check(ipRange.Comment(), gc.Equals, "load balancers")
+	c.Check(ipRange.Owner(), gc.Equals, "admin")
+}
+
+func (*ipRangeSuite) TestLowVersion(c *gc.C) {
+	_, err := readIPRanges(version.MustParse("1.9.0"), parseJSON(c, ipRangesResponse))
+	c.Assert(err, jc.Satisfies, IsUnsupportedVersionError)
+	c.Assert(err.Error(), gc.Equals, `no ip range read func for version 1.9.0`)
+}
+
+func (*ipRangeSuite) TestHighVersion(c *gc.C) {
+	ipRanges, err := readIPRanges(version.MustParse("2.1.9"), parseJSON(c, ipRangesResponse))
+	c.Assert(err, jc.ErrorIsNil)
+	c.Assert(ipRanges, gc.HasLen, 2)
+}
+
+func (s *ipRangeSuite) getServerAndIPRange(c *gc.C) (*SimpleTestServer, *ipRange) {
+	server, controller := createTestServerController(c, s)
+	server.AddGetResponse("/api/2.0/ipranges/", http.StatusOK, ipRangesResponse)
+	ipRanges, err := controller.IPRanges()
+	c.Assert(err, jc.ErrorIsNil)
+	ipRange := ipRanges[0].(*ipRange)
+	server.ResetRequests()
+	return server, ipRange
+}
+
+func (s *ipRangeSuite) TestUpdate(c *gc.C) {
+	server, ipRange := s.getServerAndIPRange(c)
+	response := updateJSONMap(c, ipRangeResponse, map[string]interface{}{
+		"end_ip":  "192.168.100.150",
+		"comment": "shrunk",
+	})
+	server.AddPutResponse(ipRange.resourceURI, http.StatusOK, response)
+	err := ipRange.Update(UpdateIPRangeArgs{
+		EndIP:   "192.168.100.150",
+		Comment: "shrunk",
+	})
+	c.Assert(err, jc.ErrorIsNil)
+	c.Check(ipRange.EndIP(), gc.Equals, "192.168.100.150")
+	c.Check(ipRange.Comment(), gc.Equals, "shrunk")
+
+	form := server.LastRequest().PostForm
+	c.Check(form, gc.HasLen, 2)
+	c.Check(form.Get("end_ip"), gc.Equals, "192.168.100.150")
+	c.Check(form.Get("comment"), gc.Equals, "shrunk")
+}
+
+func (s *ipRangeSuite) TestUpdateInvalid(c *gc.C) {
+	server, ipRange := s.getServerAndIPRange(c)
+	err := ipRange.Update(UpdateIPRangeArgs{StartIP: "192.168.100"})
+	c.Assert(err, jc.Satisfies, errors.IsNotValid)
+	c.Check(server.RequestCount(), gc.Equals, 0)
+}
+
+func (s *ipRangeSuite) TestUpdateBadRequest(c *gc.C) {
+	server, ipRange := s.getServerAndIPRange(c)
+	server.AddPutResponse(ipRange.resourceURI, http.StatusBadRequest, "overlaps reserved range")
+	err := ipRange.Update(UpdateIPRangeArgs{EndIP: "192.168.100.250"})
+	c.Assert(err, jc.Satisfies, IsBadRequestError)
+	c.Check(err.Error(), gc.Equals, "overlaps reserved range")
+}
+
+func (s *ipRangeSuite) TestDelete(c *gc.C) {
+	server, ipRange := s.getServerAndIPRange(c)
+	server.AddDeleteResponse(ipRange.resourceURI, http.StatusNoContent, "")
+	err := ipRange.Delete()
+	c.Assert(err, jc.ErrorIsNil)
+}
+
+func (s *ipRangeSuite) TestDeleteMissing(c *gc.C) {
+	_, ipRange := s.getServerAndIPRange(c)
+	err := ipRange.Delete()
+	c.Assert(err, jc.Satisfies, IsNoMatchError)
+}
+
+func (s *ipRangeSuite) TestDeleteForbidden(c *gc.C) {
+	server, ipRange := s.getServerAndIPRange(c)
+	server.AddDeleteResponse(ipRange.resourceURI, http.StatusForbidden, "")
+	err := ipRange.Delete()
+	c.Assert(err, jc.Satisfies, IsPermissionError)
+}
+
+const (
+	ipRangeResponse = `
+{
+    "id": 1,
+    "type": "dynamic",
+    "start_ip": "192.168.100.100",
+    "end_ip": "192.168.100.200",
+    "comment": "",
+    "user": null,
+    "subnet": {
+        "gateway_ip": "192.168.100.1",
+        "name": "192.168.100.0/24",
+        "vlan": {
+            "fabric": "fabric-0",
+            "resource_uri": "/MAAS/api/2.0/vlans/1/",
+            "name": "untagged",
+            "secondary_rack": null,
+            "primary_rack": "4y3h7n",
+            "vid": 0,
+            "dhcp_on": true,
+            "id": 1,
+            "mtu": 1500
+        },
+        "space": "space-0",
+        "id": 1,
+        "resource_uri": "/MAAS/api/2.0/subnets/1/",
+        "dns_servers": [],
+        "cidr": "192.168.100.0/24",
+        "rdns_mode": 2
+    },
+    "resource_uri": "/MAAS/api/2.0/ipranges/1/"
+}
+`
+	ipRangesResponse = "[" + ipRangeResponse + `,
+{
+    "id": 2,
+    "type": "reserved",
+    "start_ip": "192.168.100.10",
+    "end_ip": "192.168.100.19",
+    "comment": "load balancers",
+    "user": {
+        "is_superuser": true,
+        "username": "admin",
+        "email": "admin@example.com",
+        "is_local": true,
+        "resource_uri": "/MAAS/api/2.0/users/admin/"
+    },
+    "subnet": null,
+    "resource_uri": "/MAAS/api/2.0/ipranges/2/"
+}
+]`
+)
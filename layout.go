@@ -0,0 +1,187 @@
+// Copyright 2022 Canonical Ltd.
+// Licensed under the LGPLv3, see LICENCE file for details.
+
+package gomaasapi
+
+import (
+	"github.com/juju/errors"
+)
+
+// MachineLayout is a snapshot of the network and storage configuration of a
+// machine. It only holds plain values, so it can be stored, compared, or
+// used to configure another machine the same way.
+//
+// Storage devices are referred to by their path, such as
+// "/dev/disk/by-dname/sda-part1", since the paths are derived from the
+// device names and are the same on machines with the same disks.
+type MachineLayout struct {
+	Interfaces   []InterfaceLayout
+	BlockDevices []BlockDeviceLayout
+	RAIDs        []RAIDLayout
+	VolumeGroups []VolumeGroupLayout
+}
+
+// InterfaceLayout describes an interface of a machine.
+type InterfaceLayout struct {
+	Name    string
+	Type    string
+	Parents []string
+	Enabled bool
+	Tags    []string
+	MTU     int
+	// VLAN is the MAAS ID of the VLAN of the interface, zero if the
+	// interface isn't connected to a VLAN.
+	VLAN int
+
+	Links []LinkLayout
+}
+
+// LinkLayout describes a link between an interface and a subnet.
+type LinkLayout struct {
+	Mode   string
+	Subnet string
+	// IPAddress is only set for links with a static address.
+	IPAddress string
+}
+
+// BlockDeviceLayout describes a block device and its partitions.
+type BlockDeviceLayout struct {
+	Name       string
+	Path       string
+	Size       uint64
+	Tags       []string
+	FileSystem *FileSystemLayout
+	Partitions []PartitionLayout
+}
+
+// PartitionLayout describes a partition of a block device.
+type PartitionLayout struct {
+	Path       string
+	Size       uint64
+	FileSystem *FileSystemLayout
+}
+
+// FileSystemLayout describes the filesystem of a storage device.
+type FileSystemLayout struct {
+	FSType       string
+	Label        string
+	MountPoint   string
+	MountOptions string
+}
+
+// RAIDLayout describes a software RAID array.
+type RAIDLayout struct {
+	Name         string
+	Level        RAIDLevel
+	Devices      []string
+	SpareDevices []string
+}
+
+// VolumeGroupLayout describes an LVM volume group and its logical volumes.
+type VolumeGroupLayout struct {
+	Name           string
+	Devices        []string
+	LogicalVolumes []BlockDeviceLayout
+}
+
+// CaptureLayout implements Machine.
+func (m *machine) CaptureLayout() (MachineLayout, error) {
+	raids, err := m.RAIDs()
+	if err != nil {
+		return MachineLayout{}, errors.Trace(err)
+	}
+	volumeGroups, err := m.VolumeGroups()
+	if err != nil {
+		return MachineLayout{}, errors.Trace(err)
+	}
+
+	var layout MachineLayout
+	for _, iface := range m.InterfaceSet() {
+		layout.Interfaces = append(layout.Interfaces, interfaceLayout(iface))
+	}
+	for _, blockDevice := range m.PhysicalBlockDevices() {
+		layout.BlockDevices = append(layout.BlockDevices, blockDeviceLayout(blockDevice))
+	}
+	for _, raid := range raids {
+		layout.RAIDs = append(layout.RAIDs, RAIDLayout{
+			Name:         raid.Name(),
+			Level:        raid.Level(),
+			Devices:      storageDevicePaths(raid.Devices()),
+			SpareDevices: storageDevicePaths(raid.SpareDevices()),
+		})
+	}
+	for _, volumeGroup := range volumeGroups {
+		vgLayout := VolumeGroupLayout{
+			Name:    volumeGroup.Name(),
+			Devices: storageDevicePaths(volumeGroup.Devices()),
+		}
+		for _, volume := range volumeGroup.LogicalVolumes() {
+			vgLayout.LogicalVolumes = append(vgLayout.LogicalVolumes, blockDeviceLayout(volume))
+		}
+		layout.VolumeGroups = append(layout.VolumeGroups, vgLayout)
+	}
+	return layout, nil
+}
+
+func interfaceLayout(iface Interface) InterfaceLayout {
+	result := InterfaceLayout{
+		Name:    iface.Name(),
+		Type:    iface.Type(),
+		Parents: iface.Parents(),
+		Enabled: iface.Enabled(),
+		Tags:    iface.Tags(),
+		MTU:     iface.EffectiveMTU(),
+	}
+	if vlan := iface.VLAN(); vlan != nil {
+		result.VLAN = vlan.ID()
+	}
+	for _, link := range iface.Links() {
+		linkLayout := LinkLayout{Mode: link.Mode()}
+		if subnet := link.Subnet(); subnet != nil {
+			linkLayout.Subnet = subnet.CIDR()
+		}
+		if link.Mode() == "static" {
+			linkLayout.IPAddress = link.IPAddress()
+		}
+		result.Links = append(result.Links, linkLayout)
+	}
+	return result
+}
+
+func blockDeviceLayout(blockDevice BlockDevice) BlockDeviceLayout {
+	result := BlockDeviceLayout{
+		Name:       blockDevice.Name(),
+		Path:       blockDevice.Path(),
+		Size:       blockDevice.Size(),
+		Tags:       blockDevice.Tags(),
+		FileSystem: fileSystemLayout(blockDevice.FileSystem()),
+	}
+	for _, partition := range blockDevice.Partitions() {
+		result.Partitions = append(result.Partitions, PartitionLayout{
+			Path:       partition.Path(),
+			Size:       partition.Size(),
+			FileSystem: fileSystemLayout(partition.FileSystem()),
+		})
+	}
+	return result
+}
+
+func fileSystemLayout(fs FileSystem) *FileSystemLayout {
+	if fs == nil {
+		return nil
+	}
+	return &FileSystemLayout{
+		FSType:       fs.Type(),
+		Label:        fs.Label(),
+		MountPoint:   fs.MountPoint(),
+		MountOptions: fs.MountOptions(),
+	}
+}
+
+func storageDevicePaths(devices []StorageDevice) []string {
+	var result []string
+	for _, device := range devices {
+		result = append(result, device.Path())
+	}
+	return result
+}
@@ -0,0 +1,86 @@
+// Copyright 2022 Canonical Ltd.
+// Licensed under the LGPLv3, see LICENCE file for details.
+
+package gomaasapi
+
+import (
+	"net/http"
+
+	"github.com/juju/testing"
+	jc "github.com/juju/testing/checkers"
+	gc "gopkg.in/check.v1"
+)
+
+type layoutSuite struct {
+	testing.LoggingCleanupSuite
+}
+
+var _ = gc.Suite(&layoutSuite{})
+
+func (s *layoutSuite) getServerAndMachine(c *gc.C) (*SimpleTestServer, Machine) {
+	server, controller := createTestServerController(c, s)
+	server.AddGetResponse("/api/2.0/machines/", http.StatusOK, "["+machineResponse+"]")
+	machines, err := controller.Machines(MachinesArgs{})
+	c.Assert(err, jc.ErrorIsNil)
+	c.Assert(machines, gc.HasLen, 1)
+	return server, machines[0]
+}
+
+func (s *layoutSuite) TestCaptureLayout(c *gc.C) {
+	server, machine := s.getServerAndMachine(c)
+	server.AddGetResponse("/api/2.0/nodes/4y3ha3/raids/", http.StatusOK, raidsResponse)
+	server.AddGetResponse("/api/2.0/nodes/4y3ha3/volume-groups/", http.StatusOK, volumeGroupsResponse)
+	layout, err := machine.CaptureLayout()
+	c.Assert(err, jc.ErrorIsNil)
+
+	c.Assert(layout.Interfaces, gc.HasLen, 2)
+	c.Check(layout.Interfaces[0], jc.DeepEquals, InterfaceLayout{
+		Name:    "eth0",
+		Type:    "physical",
+		Parents: []string{},
+		Enabled: true,
+		Tags:    []string{},
+		MTU:     1500,
+		VLAN:    1,
+		Links:   []LinkLayout{{Mode: "auto", Subnet: "192.168.100.0/24"}},
+	})
+
+	c.Assert(layout.BlockDevices, gc.HasLen, 2)
+	sda := layout.BlockDevices[0]
+	c.Check(sda.Name, gc.Equals, "sda")
+	c.Check(sda.Path, gc.Equals, "/dev/disk/by-dname/sda")
+	c.Check(sda.Size, gc.Equals, uint64(8589934592))
+	c.Check(sda.FileSystem, gc.IsNil)
+	c.Assert(sda.Partitions, gc.HasLen, 1)
+	c.Check(sda.Partitions[0].Path, gc.Equals, "/dev/disk/by-dname/sda-part1")
+	c.Assert(sda.Partitions[0].FileSystem, gc.NotNil)
+	c.Check(*sda.Partitions[0].FileSystem, jc.DeepEquals, FileSystemLayout{
+		FSType:     "ext4",
+		Label:      "root",
+		MountPoint: "/",
+	})
+
+	c.Check(layout.RAIDs, jc.DeepEquals, []RAIDLayout{{
+		Name:    "md0",
+		Level:   RAID1,
+		Devices: []string{"/dev/disk/by-dname/sda", "/dev/disk/by-dname/sdb-part1"},
+	}})
+
+	c.Assert(layout.VolumeGroups, gc.HasLen, 1)
+	c.Check(layout.VolumeGroups[0].Name, gc.Equals, "vgroot")
+	c.Check(layout.VolumeGroups[0].Devices, jc.DeepEquals, []string{"/dev/disk/by-dname/sdb-part1"})
+	c.Assert(layout.VolumeGroups[0].LogicalVolumes, gc.HasLen, 1)
+	c.Check(layout.VolumeGroups[0].LogicalVolumes[0].Name, gc.Equals, "vgroot-lvroot")
+}
+
+func (s *layoutSuite) TestCaptureLayoutStaticAddress(c *gc.C) {
+	static := &link{mode: "static", ipAddress: "192.168.100.10"}
+	layout := interfaceLayout(&interface_{name: "eth1", links: []*link{static}})
+	c.Check(layout.Links, jc.DeepEquals, []LinkLayout{{Mode: "static", IPAddress: "192.168.100.10"}})
+}
+
+func (s *layoutSuite) TestCaptureLayoutError(c *gc.C) {
+	_, machine := s.getServerAndMachine(c)
+	_, err := machine.CaptureLayout()
+	c.Assert(err, jc.Satisfies, IsUnexpectedError)
+}
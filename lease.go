@@ -0,0 +1,194 @@
+// Copyright 2022 Canonical Ltd.
+// Licensed under the LGPLv3, see LICENCE file for details.
+
+package gomaasapi
+
+import (
+	"time"
+
+	"github.com/juju/errors"
+)
+
+// The owner data keys that hold the lease of a machine. Leases are a
+// convention of this package rather than a MAAS feature, so any user that
+// can edit the owner data of a machine can change its lease.
+const (
+	LeaseHolderKey  = "lease_holder"
+	LeasePurposeKey = "lease_purpose"
+	// LeaseExpiryKey holds the expiry time in RFC 3339 format.
+	LeaseExpiryKey = "lease_expiry"
+)
+
+// timeNow is patched by the tests.
+var timeNow = time.Now
+
+type lease struct {
+	controller *controller
+
+	machine Machine
+	holder  string
+	purpose string
+	expiry  time.Time
+}
+
+// Machine implements Lease.
+func (l *lease) Machine() Machine {
+	return l.machine
+}
+
+// Holder implements Lease.
+func (l *lease) Holder() string {
+	return l.holder
+}
+
+// Purpose implements Lease.
+func (l *lease) Purpose() string {
+	return l.purpose
+}
+
+// Expiry implements Lease.
+func (l *lease) Expiry() time.Time {
+	return l.expiry
+}
+
+// Expired implements Lease.
+func (l *lease) Expired() bool {
+	return !timeNow().Before(l.expiry)
+}
+
+// Renew implements Lease.
+func (l *lease) Renew(duration time.Duration) error {
+	if duration <= 0 {
+		return errors.NotValidf("lease duration %v", duration)
+	}
+	expiry := timeNow().Add(duration).UTC()
+	err := l.machine.SetOwnerData(map[string]string{
+		LeaseExpiryKey: expiry.Format(time.RFC3339),
+	})
+	if err != nil {
+		return errors.Annotatef(err, "renewing lease of %s", l.machine.SystemID())
+	}
+	l.expiry = expiry
+	return nil
+}
+
+// ReclaimLeaseArgs is an argument struct for Lease.Reclaim.
+type ReclaimLeaseArgs struct {
+	// Release, if true, also releases the machine. MAAS clears the owner
+	// data of machines when they are released.
+	Release bool
+	// Comment is recorded in the machine's event log when it is released.
+	Comment string
+}
+
+// Reclaim implements Lease.
+func (l *lease) Reclaim(args ReclaimLeaseArgs) error {
+	if args.Release {
+		err := l.controller.ReleaseMachines(ReleaseMachinesArgs{
+			SystemIDs: []string{l.machine.SystemID()},
+			Comment:   args.Comment,
+		})
+		return errors.Annotatef(err, "releasing %s", l.machine.SystemID())
+	}
+	err := l.machine.SetOwnerData(map[string]string{
+		LeaseHolderKey:  "",
+		LeasePurposeKey: "",
+		LeaseExpiryKey:  "",
+	})
+	return errors.Annotatef(err, "clearing lease of %s", l.machine.SystemID())
+}
+
+// LeaseMachineArgs is an argument struct for Controller.LeaseMachine.
+// Holder and Duration are required.
+type LeaseMachineArgs struct {
+	Holder   string
+	Purpose  string
+	Duration time.Duration
+}
+
+// Validate ensures that the Holder is set and the Duration is positive.
+func (a *LeaseMachineArgs) Validate() error {
+	if a.Holder == "" {
+		return errors.NotValidf("missing Holder")
+	}
+	if a.Duration <= 0 {
+		return errors.NotValidf("lease duration %v", a.Duration)
+	}
+	return nil
+}
+
+// LeaseMachine implements Controller.
+func (c *controller) LeaseMachine(machine Machine, args LeaseMachineArgs) (Lease, error) {
+	if err := args.Validate(); err != nil {
+		return nil, errors.Trace(err)
+	}
+	expiry := timeNow().Add(args.Duration).UTC()
+	err := machine.SetOwnerData(map[string]string{
+		LeaseHolderKey:  args.Holder,
+		LeasePurposeKey: args.Purpose,
+		LeaseExpiryKey:  expiry.Format(time.RFC3339),
+	})
+	if err != nil {
+		return nil, errors.Annotatef(err, "leasing %s", machine.SystemID())
+	}
+	return &lease{
+		controller: c,
+		machine:    machine,
+		holder:     args.Holder,
+		purpose:    args.Purpose,
+		expiry:     expiry,
+	}, nil
+}
+
+// Leases implements Controller.
+func (c *controller) Leases(args MachinesArgs) ([]Lease, error) {
+	machines, err := c.Machines(args)
+	if err != nil {
+		return nil, errors.Trace(err)
+	}
+	var result []Lease
+	for _, m := range machines {
+		if l := c.readLease(m); l != nil {
+			result = append(result, l)
+		}
+	}
+	return result, nil
+}
+
+// ListExpiredLeases implements Controller.
+func (c *controller) ListExpiredLeases() ([]Lease, error) {
+	leases, err := c.Leases(MachinesArgs{})
+	if err != nil {
+		return nil, errors.Trace(err)
+	}
+	var result []Lease
+	for _, l := range leases {
+		if l.Expired() {
+			result = append(result, l)
+		}
+	}
+	return result, nil
+}
+
+// readLease returns the lease of the machine, or nil if it isn't leased. A
+// lease with an expiry that can't be parsed is treated as expired, so that
+// the machine can be reclaimed.
+func (c *controller) readLease(m Machine) *lease {
+	ownerData := m.OwnerData()
+	holder := ownerData[LeaseHolderKey]
+	if holder == "" {
+		return nil
+	}
+	expiry, err := time.Parse(time.RFC3339, ownerData[LeaseExpiryKey])
+	if err != nil {
+		logger.Debugf("machine %s: invalid lease expiry: %v", m.SystemID(), err)
+		expiry = time.Time{}
+	}
+	return &lease{
+		controller: c,
+		machine:    m,
+		holder:     holder,
+		purpose:    ownerData[LeasePurposeKey],
+		expiry:     expiry,
+	}
+}
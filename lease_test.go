@@ -0,0 +1,210 @@
+// Copyright 2022 Canonical Ltd.
+// Licensed under the LGPLv3, see LICENCE file for details.
+
+package gomaasapi
+
+import (
+	"net/http"
+	"time"
+
+	"github.com/juju/errors"
+	"github.com/juju/testing"
+	jc "github.com/juju/testing/checkers"
+	gc "gopkg.in/check.v1"
+)
+
+type leaseSuite struct {
+	testing.LoggingCleanupSuite
+	now time.Time
+}
+
+var _ = gc.Suite(&leaseSuite{})
+
+func (s *leaseSuite) SetUpTest(c *gc.C) {
+	s.LoggingCleanupSuite.SetUpTest(c)
+	s.now = time.Date(2022, 6, 1, 12, 0, 0, 0, time.UTC)
+	s.PatchValue(&timeNow, func() time.Time { return s.now })
+}
+
+// leasedMachinesResponse returns the machines with the first leased until
+// the expiry, and the second with an expiry that isn't valid.
+func (s *leaseSuite) leasedMachinesResponse(c *gc.C, expiry string) string {
+	first := updateJSONMap(c, machineResponse, map[string]interface{}{
+		"owner_data": map[string]string{
+			LeaseHolderKey:  "ci",
+			LeasePurposeKey: "nightly",
+			LeaseExpiryKey:  expiry,
+		},
+	})
+	second := updateJSONMap(c, machineResponse, map[string]interface{}{
+		"system_id":    "4y3ha4",
+		"resource_uri": "/MAAS/api/2.0/machines/4y3ha4/",
+		"owner_data": map[string]string{
+			LeaseHolderKey: "alice",
+			LeaseExpiryKey: "tomorrow",
+		},
+	})
+	return "[" + first + "," + second + "]"
+}
+
+func (s *leaseSuite) TestLeaseMachine(c *gc.C) {
+	server, controller := createTestServerController(c, s)
+	server.AddGetResponse("/api/2.0/machines/", http.StatusOK, "["+machineResponse+"]")
+	machines, err := controller.Machines(MachinesArgs{})
+	c.Assert(err, jc.ErrorIsNil)
+	server.AddPostResponse("/MAAS/api/2.0/machines/4y3ha3/?op=set_owner_data", http.StatusOK, machineResponse)
+
+	lease, err := controller.LeaseMachine(machines[0], LeaseMachineArgs{
+		Holder:   "ci",
+		Purpose:  "nightly",
+		Duration: 2 * time.Hour,
+	})
+	c.Assert(err, jc.ErrorIsNil)
+	c.Check(lease.Machine(), gc.Equals, machines[0])
+	c.Check(lease.Holder(), gc.Equals, "ci")
+	c.Check(lease.Purpose(), gc.Equals, "nightly")
+	c.Check(lease.Expiry(), gc.Equals, s.now.Add(2*time.Hour))
+	c.Check(lease.Expired(), jc.IsFalse)
+
+	form := server.LastRequest().PostForm
+	c.Check(form, gc.HasLen, 3)
+	c.Check(form.Get(LeaseHolderKey), gc.Equals, "ci")
+	c.Check(form.Get(LeasePurposeKey), gc.Equals, "nightly")
+	c.Check(form.Get(LeaseExpiryKey), gc.Equals, "2022-06-01T14:00:00Z")
+}
+
+func (s *leaseSuite) TestLeaseMachineArgsValidate(c *gc.C) {
+	for i, test := range []struct {
+		args    LeaseMachineArgs
+		errText string
+	}{{
+		args: LeaseMachineArgs{Holder: "ci", Duration: time.Minute},
+	}, {
+		args:    LeaseMachineArgs{Duration: time.Minute},
+		errText: "missing Holder not valid",
+	}, {
+		args:    LeaseMachineArgs{Holder: "ci"},
+		errText: "lease duration 0s not valid",
+	}} {
+		c.Logf("test %d", i)
+		err := test.args.Validate()
+		if test.errText == "" {
+			c.Check(err, jc.ErrorIsNil)
+		} else {
+			c.Check(err, jc.Satisfies, errors.IsNotValid)
+			c.Check(err.Error(), gc.Equals, test.errText)
+		}
+	}
+}
+
+func (s *leaseSuite) TestLeases(c *gc.C) {
+	server, controller := createTestServerController(c, s)
+	response := s.leasedMachinesResponse(c, "2022-06-01T13:00:00Z")
+	// The other machines aren't leased.
+	response = response[:len(response)-1] + "," + altMachineResponse + "]"
+	server.AddGetResponse("/api/2.0/machines/", http.StatusOK, response)
+
+	leases, err := controller.Leases(MachinesArgs{})
+	c.Assert(err, jc.ErrorIsNil)
+	c.Assert(leases, gc.HasLen, 2)
+	c.Check(leases[0].Machine().SystemID(), gc.Equals, "4y3ha3")
+	c.Check(leases[0].Holder(), gc.Equals, "ci")
+	c.Check(leases[0].Purpose(), gc.Equals, "nightly")
+	c.Check(leases[0].Expiry(), gc.Equals, time.Date(2022, 6, 1, 13, 0, 0, 0, time.UTC))
+	c.Check(leases[0].Expired(), jc.IsFalse)
+	c.Check(leases[1].Holder(), gc.Equals, "alice")
+	c.Check(leases[1].Expiry().IsZero(), jc.IsTrue)
+	c.Check(leases[1].Expired(), jc.IsTrue)
+}
+
+func (s *leaseSuite) TestListExpiredLeases(c *gc.C) {
+	server, controller := createTestServerController(c, s)
+	server.AddGetResponse("/api/2.0/machines/", http.StatusOK, s.leasedMachinesResponse(c, "2022-06-01T12:00:00Z"))
+
+	leases, err := controller.ListExpiredLeases()
+	c.Assert(err, jc.ErrorIsNil)
+	c.Assert(leases, gc.HasLen, 2)
+	c.Check(leases[0].Holder(), gc.Equals, "ci")
+	c.Check(leases[1].Holder(), gc.Equals, "alice")
+}
+
+func (s *leaseSuite) TestListExpiredLeasesNoneExpired(c *gc.C) {
+	server, controller := createTestServerController(c, s)
+	server.AddGetResponse("/api/2.0/machines/", http.StatusOK, "["+updateJSONMap(c, machineResponse, map[string]interface{}{
+		"owner_data": map[string]string{
+			LeaseHolderKey: "ci",
+			LeaseExpiryKey: "2022-06-01T12:00:01Z",
+		},
+	})+"]")
+
+	leases, err := controller.ListExpiredLeases()
+	c.Assert(err, jc.ErrorIsNil)
+	c.Check(leases, gc.HasLen, 0)
+}
+
+func (s *leaseSuite) getLease(c *gc.C) (*SimpleTestServer, Lease) {
+	server, controller := createTestServerController(c, s)
+	server.AddGetResponse("/api/2.0/machines/", http.StatusOK, s.leasedMachinesResponse(c, "2022-06-01T11:00:00Z"))
+	leases, err := controller.Leases(MachinesArgs{})
+	c.Assert(err, jc.ErrorIsNil)
+	server.ResetRequests()
+	return server, leases[0]
+}
+
+func (s *leaseSuite) TestRenew(c *gc.C) {
+	server, lease := s.getLease(c)
+	server.AddPostResponse("/MAAS/api/2.0/machines/4y3ha3/?op=set_owner_data", http.StatusOK, machineResponse)
+	c.Assert(lease.Expired(), jc.IsTrue)
+
+	err := lease.Renew(30 * time.Minute)
+	c.Assert(err, jc.ErrorIsNil)
+	c.Check(lease.Expiry(), gc.Equals, s.now.Add(30*time.Minute))
+	c.Check(lease.Expired(), jc.IsFalse)
+
+	form := server.LastRequest().PostForm
+	c.Check(form, gc.HasLen, 1)
+	c.Check(form.Get(LeaseExpiryKey), gc.Equals, "2022-06-01T12:30:00Z")
+}
+
+func (s *leaseSuite) TestRenewFailure(c *gc.C) {
+	server, lease := s.getLease(c)
+	server.AddPostResponse("/MAAS/api/2.0/machines/4y3ha3/?op=set_owner_data", http.StatusForbidden, "not yours")
+
+	err := lease.Renew(30 * time.Minute)
+	c.Assert(err, gc.ErrorMatches, "renewing lease of 4y3ha3: .*")
+	c.Check(lease.Expired(), jc.IsTrue)
+}
+
+func (s *leaseSuite) TestRenewInvalid(c *gc.C) {
+	server, lease := s.getLease(c)
+	err := lease.Renew(-time.Minute)
+	c.Assert(err, jc.Satisfies, errors.IsNotValid)
+	c.Check(server.RequestCount(), gc.Equals, 0)
+}
+
+func (s *leaseSuite) TestReclaim(c *gc.C) {
+	server, lease := s.getLease(c)
+	server.AddPostResponse("/MAAS/api/2.0/machines/4y3ha3/?op=set_owner_data", http.StatusOK, machineResponse)
+
+	err := lease.Reclaim(ReclaimLeaseArgs{})
+	c.Assert(err, jc.ErrorIsNil)
+
+	form := server.LastRequest().PostForm
+	c.Check(form, gc.HasLen, 3)
+	c.Check(form.Get(LeaseHolderKey), gc.Equals, "")
+	c.Check(form.Get(LeasePurposeKey), gc.Equals, "")
+	c.Check(form.Get(LeaseExpiryKey), gc.Equals, "")
+}
+
+func (s *leaseSuite) TestReclaimRelease(c *gc.C) {
+	server, lease := s.getLease(c)
+	server.AddPostResponse("/api/2.0/machines/?op=release", http.StatusOK, "[]")
+
+	err := lease.Reclaim(ReclaimLeaseArgs{Release: true, Comment: "lease expired"})
+	c.Assert(err, jc.ErrorIsNil)
+
+	c.Check(server.RequestCount(), gc.Equals, 1)
+	form := server.LastRequest().PostForm
+	c.Check(form["machines"], jc.DeepEquals, []string{"4y3ha3"})
+	c.Check(form.Get("comment"), gc.Equals, "lease expired")
+}
@@ -0,0 +1,257 @@
+// Copyright 2022 Canonical Ltd.
+// Licensed under the LGPLv3, see LICENCE file for details.
+
+package gomaasapi
+
+import (
+	"net/http"
+
+	"github.com/juju/errors"
+	"github.com/juju/schema"
+	"github.com/juju/version"
+)
+
+type licenseKey struct {
+	controller *controller
+
+	resourceURI string
+
+	osystem      string
+	distroSeries string
+	key          string
+}
+
+func (k *licenseKey) updateFrom(other *licenseKey) {
+	k.resourceURI = other.resourceURI
+	k.osystem = other.osystem
+	k.distroSeries = other.distroSeries
+	k.key = other.key
+}
+
+// OSystem implements LicenseKey.
+func (k *licenseKey) OSystem() string {
+	return k.osystem
+}
+
+// DistroSeries implements LicenseKey.
+func (k *licenseKey) DistroSeries() string {
+	return k.distroSeries
+}
+
+// Key implements LicenseKey.
+func (k *licenseKey) Key() string {
+	return k.key
+}
+
+// Update implements LicenseKey.
+func (k *licenseKey) Update(key string) error {
+	if key == "" {
+		return errors.NotValidf("missing key")
+	}
+	params := NewURLParams()
+	params.Values.Add("license_key", key)
+	source, err := k.controller.put(k.resourceURI, params.Values)
+	if err != nil {
+		if svrErr, ok := errors.Cause(err).(ServerError); ok {
+			switch svrErr.StatusCode {
+			case http.StatusNotFound:
+				return wrapError(err, NewNoMatchError(svrErr.BodyMessage))
+			case http.StatusForbidden:
+				return wrapError(err, NewPermissionError(svrErr.BodyMessage))
+			case http.StatusBadRequest:
+				return wrapError(err, NewBadRequestError(svrErr.BodyMessage))
+			}
+		}
+		return NewUnexpectedError(err)
+	}
+
+	response, err := readLicenseKey(k.controller.schemaVersion(), source)
+	if err != nil {
+		return errors.Trace(err)
+	}
+	k.updateFrom(response)
+	return nil
+}
+
+// Delete implements LicenseKey.
+func (k *licenseKey) Delete() error {
+	err := k.controller.delete(k.resourceURI)
+	if err != nil {
+		if svrErr, ok := errors.Cause(err).(ServerError); ok {
+			switch svrErr.StatusCode {
+			case http.StatusNotFound:
+				return wrapError(err, NewNoMatchError(svrErr.BodyMessage))
+			case http.StatusForbidden:
+				return wrapError(err, NewPermissionError(svrErr.BodyMessage))
+			}
+		}
+		return NewUnexpectedError(err)
+	}
+	return nil
+}
+
+// LicenseKeys implements Controller.
+func (c *controller) LicenseKeys() ([]LicenseKey, error) {
+	source, err := c.getList("license-keys", nil, 0)
+	if err != nil {
+		if svrErr, ok := errors.Cause(err).(ServerError); ok {
+			if svrErr.StatusCode == http.StatusForbidden {
+				return nil, wrapError(err, NewPermissionError(svrErr.BodyMessage))
+			}
+		}
+		return nil, NewUnexpectedError(err)
+	}
+	keys, err := readLicenseKeys(c.schemaVersion(), source)
+	if err != nil {
+		return nil, errors.Trace(err)
+	}
+	var result []LicenseKey
+	for _, k := range keys {
+		k.controller = c
+		result = append(result, k)
+	}
+	return result, nil
+}
+
+// CreateLicenseKeyArgs is an argument struct for Controller.CreateLicenseKey.
+type CreateLicenseKeyArgs struct {
+	// OSystem is the operating system the key is for, such as "windows".
+	OSystem string
+	// DistroSeries is the release the key is for, such as "win2016".
+	DistroSeries string
+	Key          string
+}
+
+// Validate ensures that all the fields are set.
+func (a *CreateLicenseKeyArgs) Validate() error {
+	if a.OSystem == "" {
+		return errors.NotValidf("missing OSystem")
+	}
+	if a.DistroSeries == "" {
+		return errors.NotValidf("missing DistroSeries")
+	}
+	if a.Key == "" {
+		return errors.NotValidf("missing Key")
+	}
+	return nil
+}
+
+// CreateLicenseKey implements Controller.
+func (c *controller) CreateLicenseKey(args CreateLicenseKeyArgs) (LicenseKey, error) {
+	if err := args.Validate(); err != nil {
+		return nil, errors.Trace(err)
+	}
+	params := NewURLParams()
+	params.Values.Add("osystem", args.OSystem)
+	params.Values.Add("distro_series", args.DistroSeries)
+	params.Values.Add("license_key", args.Key)
+	source, err := c.post("license-keys", "", params.Values)
+	if err != nil {
+		if svrErr, ok := errors.Cause(err).(ServerError); ok {
+			switch svrErr.StatusCode {
+			case http.StatusForbidden:
+				return nil, wrapError(err, NewPermissionError(svrErr.BodyMessage))
+			case http.StatusBadRequest:
+				return nil, wrapError(err, NewBadRequestError(svrErr.BodyMessage))
+			}
+		}
+		return nil, NewUnexpectedError(err)
+	}
+	key, err := readLicenseKey(c.schemaVersion(), source)
+	if err != nil {
+		return nil, errors.Trace(err)
+	}
+	key.controller = c
+	return key, nil
+}
+
+func readLicenseKey(controllerVersion version.Number, source interface{}) (*licenseKey, error) {
+	readFunc, err := getLicenseKeyDeserializationFunc(controllerVersion)
+	if err != nil {
+		return nil, errors.Trace(err)
+	}
+
+	checker := schema.StringMap(schema.Any())
+	coerced, err := checker.Coerce(source, nil)
+	if err != nil {
+		return nil, WrapWithDeserializationError(err, "license key base schema check failed")
+	}
+	valid := coerced.(map[string]interface{})
+	return readFunc(valid)
+}
+
+func readLicenseKeys(controllerVersion version.Number, source interface{}) ([]*licenseKey, error) {
+	readFunc, err := getLicenseKeyDeserializationFunc(controllerVersion)
+	if err != nil {
+		return nil, errors.Trace(err)
+	}
+
+	checker := schema.List(schema.StringMap(schema.Any()))
+	coerced, err := checker.Coerce(source, nil)
+	if err != nil {
+		return nil, WrapWithDeserializationError(err, "license key base schema check failed")
+	}
+	valid := coerced.([]interface{})
+	return readLicenseKeyList(valid, readFunc)
+}
+
+func getLicenseKeyDeserializationFunc(controllerVersion version.Number) (licenseKeyDeserializationFunc, error) {
+	var deserialisationVersion version.Number
+	for v := range licenseKeyDeserializationFuncs {
+		if v.Compare(deserialisationVersion) > 0 && v.Compare(controllerVersion) <= 0 {
+			deserialisationVersion = v
+		}
+	}
+	if deserialisationVersion == version.Zero {
+		return nil, NewUnsupportedVersionError("no license key read func for version %s", controllerVersion)
+	}
+	return licenseKeyDeserializationFuncs[deserialisationVersion], nil
+}
+
+// readLicenseKeyList expects the values of the sourceList to be string maps.
+func readLicenseKeyList(sourceList []interface{}, readFunc licenseKeyDeserializationFunc) ([]*licenseKey, error) {
+	result := make([]*licenseKey, 0, len(sourceList))
+	for i, value := range sourceList {
+		source, ok := value.(map[string]interface{})
+		if !ok {
+			return nil, NewDeserializationError("unexpected value for license key %d, %T", i, value)
+		}
+		key, err := readFunc(source)
+		if err != nil {
+			return nil, errors.Annotatef(err, "license key %d", i)
+		}
+		result = append(result, key)
+	}
+	return result, nil
+}
+
+type licenseKeyDeserializationFunc func(map[string]interface{}) (*licenseKey, error)
+
+var licenseKeyDeserializationFuncs = map[version.Number]licenseKeyDeserializationFunc{
+	twoDotOh: licenseKey_2_0,
+}
+
+func licenseKey_2_0(source map[string]interface{}) (*licenseKey, error) {
+	fields := schema.Fields{
+		"resource_uri":  schema.String(),
+		"osystem":       schema.String(),
+		"distro_series": schema.String(),
+		"license_key":   schema.String(),
+	}
+	checker := schema.FieldMap(fields, nil) // no defaults
+	coerced, err := checker.Coerce(source, nil)
+	if err != nil {
+		return nil, WrapWithDeserializationError(err, "license key 2.0 schema check failed")
+	}
+	valid := coerced.(map[string]interface{})
+	// From here we know that the map returned from the schema coercion
+	// contains fields of the right type.
+
+	result := &licenseKey{
+		resourceURI:  valid["resource_uri"].(string),
+		osystem:      valid["osystem"].(string),
+		distroSeries: valid["distro_series"].(string),
+		key:          valid["license_key"].(string),
+	}
+	return result, nil
+}
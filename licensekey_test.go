@@ -0,0 +1,173 @@
+// Copyright 2022 Canonical Ltd.
+// Licensed under the LGPLv3, see LICENCE file for details.
+
+package gomaasapi
+
+import (
+	"net/http"
+
+	"github.com/juju/errors"
+	"github.com/juju/testing"
+	jc "github.com/juju/testing/checkers"
+	"github.com/juju/version"
+	gc "gopkg.in/check.v1"
+)
+
+type licenseKeySuite struct {
+	testing.LoggingCleanupSuite
+}
+
+var _ = gc.Suite(&licenseKeySuite{})
+
+func (*licenseKeySuite) TestReadLicenseKeysBadSchema(c *gc.C) {
+	_, err := readLicenseKeys(twoDotOh, "wat?")
+	c.Check(err, jc.Satisfies, IsDeserializationError)
+	c.Assert(err.Error(), gc.Equals, `license key base schema check failed: expected list, got string("wat?")`)
+}
+
+func (*licenseKeySuite) TestReadLicenseKeys(c *gc.C) {
+	keys, err := readLicenseKeys(twoDotOh, parseJSON(c, licenseKeysResponse))
+	c.Assert(err, jc.ErrorIsNil)
+	c.Assert(keys, gc.HasLen, 1)
+	key := keys[0]
+	c.Check(key.OSystem(), gc.Equals, "windows")
+	c.Check(key.DistroSeries(), gc.Equals, "win2016")
+	c.Check(key.Key(), gc.Equals, "AAAAA-BBBBB-CCCCC-DDDDD-EEEEE")
+}
+
+func (*licenseKeySuite) TestLowVersion(c *gc.C) {
+	_, err := readLicenseKeys(version.MustParse("1.9.0"), parseJSON(c, licenseKeysResponse))
+	c.Assert(err, jc.Satisfies, IsUnsupportedVersionError)
+}
+
+func (*licenseKeySuite) TestHighVersion(c *gc.C) {
+	keys, err := readLicenseKeys(version.MustParse("2.1.9"), parseJSON(c, licenseKeysResponse))
+	c.Assert(err, jc.ErrorIsNil)
+	c.Assert(keys, gc.HasLen, 1)
+}
+
+func (s *licenseKeySuite) getKey(c *gc.C) (*SimpleTestServer, LicenseKey) {
+	server, controller := createTestServerController(c, s)
+	server.AddGetResponse("/api/2.0/license-keys/", http.StatusOK, licenseKeysResponse)
+	keys, err := controller.LicenseKeys()
+	c.Assert(err, jc.ErrorIsNil)
+	c.Assert(keys, gc.HasLen, 1)
+	return server, keys[0]
+}
+
+func (s *licenseKeySuite) TestLicenseKeysPaginated(c *gc.C) {
+	server, controller := createTestServerController(c, s)
+	server.AddGetResponse("/api/2.0/license-keys/", http.StatusOK, `{"items": `+licenseKeysResponse+`, "next": null}`)
+	result, err := controller.LicenseKeys()
+	c.Assert(err, jc.ErrorIsNil)
+	c.Check(result, gc.HasLen, 1)
+}
+
+func (s *licenseKeySuite) TestUpdate(c *gc.C) {
+	server, key := s.getKey(c)
+	server.AddPutResponse("/api/2.0/license-key/windows/win2016/", http.StatusOK, `
+{
+    "osystem": "windows",
+    "distro_series": "win2016",
+    "license_key": "FFFFF-GGGGG-HHHHH-IIIII-JJJJJ",
+    "resource_uri": "/api/2.0/license-key/windows/win2016/"
+}`)
+	err := key.Update("FFFFF-GGGGG-HHHHH-IIIII-JJJJJ")
+	c.Assert(err, jc.ErrorIsNil)
+	c.Check(key.Key(), gc.Equals, "FFFFF-GGGGG-HHHHH-IIIII-JJJJJ")
+	c.Check(server.LastRequest().PostForm.Get("license_key"), gc.Equals, "FFFFF-GGGGG-HHHHH-IIIII-JJJJJ")
+}
+
+func (s *licenseKeySuite) TestUpdateMissing(c *gc.C) {
+	_, key := s.getKey(c)
+	err := key.Update("")
+	c.Assert(err, jc.Satisfies, errors.IsNotValid)
+}
+
+func (s *licenseKeySuite) TestUpdateBadRequest(c *gc.C) {
+	server, key := s.getKey(c)
+	server.AddPutResponse("/api/2.0/license-key/windows/win2016/", http.StatusBadRequest, "Invalid license key.")
+	err := key.Update("nope")
+	c.Assert(err, jc.Satisfies, IsBadRequestError)
+}
+
+func (s *licenseKeySuite) TestDelete(c *gc.C) {
+	server, key := s.getKey(c)
+	server.AddDeleteResponse("/api/2.0/license-key/windows/win2016/", http.StatusNoContent, "")
+	err := key.Delete()
+	c.Assert(err, jc.ErrorIsNil)
+}
+
+func (s *licenseKeySuite) TestDeleteForbidden(c *gc.C) {
+	server, key := s.getKey(c)
+	server.AddDeleteResponse("/api/2.0/license-key/windows/win2016/", http.StatusForbidden, "admins only")
+	err := key.Delete()
+	c.Assert(err, jc.Satisfies, IsPermissionError)
+}
+
+func (s *licenseKeySuite) TestCreateLicenseKeyArgsValidate(c *gc.C) {
+	for i, test := range []struct {
+		args    CreateLicenseKeyArgs
+		message string
+	}{{
+		args:    CreateLicenseKeyArgs{DistroSeries: "win2016", Key: "k"},
+		message: "missing OSystem",
+	}, {
+		args:    CreateLicenseKeyArgs{OSystem: "windows", Key: "k"},
+		message: "missing DistroSeries",
+	}, {
+		args:    CreateLicenseKeyArgs{OSystem: "windows", DistroSeries: "win2016"},
+		message: "missing Key",
+	}, {
+		args: CreateLicenseKeyArgs{OSystem: "windows", DistroSeries: "win2016", Key: "k"},
+	}} {
+		c.Logf("test %d", i)
+		err := test.args.Validate()
+		if test.message == "" {
+			c.Check(err, jc.ErrorIsNil)
+		} else {
+			c.Check(err, jc.Satisfies, errors.IsNotValid)
+			c.Check(err.Error(), gc.Equals, test.message+" not valid")
+		}
+	}
+}
+
+func (s *licenseKeySuite) TestCreateLicenseKey(c *gc.C) {
+	server, controller := createTestServerController(c, s)
+	server.AddPostResponse("/api/2.0/license-keys/?op=", http.StatusOK, `
+{
+    "osystem": "windows",
+    "distro_series": "win2019",
+    "license_key": "AAAAA-BBBBB-CCCCC-DDDDD-EEEEE",
+    "resource_uri": "/api/2.0/license-key/windows/win2019/"
+}`)
+	key, err := controller.CreateLicenseKey(CreateLicenseKeyArgs{
+		OSystem:      "windows",
+		DistroSeries: "win2019",
+		Key:          "AAAAA-BBBBB-CCCCC-DDDDD-EEEEE",
+	})
+	c.Assert(err, jc.ErrorIsNil)
+	c.Check(key.DistroSeries(), gc.Equals, "win2019")
+	form := server.LastRequest().PostForm
+	c.Check(form.Get("osystem"), gc.Equals, "windows")
+	c.Check(form.Get("distro_series"), gc.Equals, "win2019")
+	c.Check(form.Get("license_key"), gc.Equals, "AAAAA-BBBBB-CCCCC-DDDDD-EEEEE")
+}
+
+func (s *licenseKeySuite) TestCreateLicenseKeyBadRequest(c *gc.C) {
+	server, controller := createTestServerController(c, s)
+	server.AddPostResponse("/api/2.0/license-keys/?op=", http.StatusBadRequest, "License key for windows/win2019 already exists.")
+	_, err := controller.CreateLicenseKey(CreateLicenseKeyArgs{OSystem: "windows", DistroSeries: "win2019", Key: "k"})
+	c.Assert(err, jc.Satisfies, IsBadRequestError)
+}
+
+const licenseKeysResponse = `
+[
+    {
+        "osystem": "windows",
+        "distro_series": "win2016",
+        "license_key": "AAAAA-BBBBB-CCCCC-DDDDD-EEEEE",
+        "resource_uri": "/api/2.0/license-key/windows/win2016/"
+    }
+]
+`
@@ -122,3 +122,74 @@ func link_2_0(source map[string]interface{}) (*link, error) {
 	}
 	return result, nil
 }
+
+type discoveredLink struct {
+	subnet    *subnet
+	ipAddress string
+}
+
+// Subnet implements DiscoveredLink.
+func (d *discoveredLink) Subnet() Subnet {
+	if d.subnet == nil {
+		return nil
+	}
+	return d.subnet
+}
+
+// IPAddress implements DiscoveredLink.
+func (d *discoveredLink) IPAddress() string {
+	return d.ipAddress
+}
+
+// readDiscoveredLinkList expects the values of the sourceList to be string maps.
+func readDiscoveredLinkList(sourceList []interface{}, readFunc discoveredLinkDeserializationFunc) ([]*discoveredLink, error) {
+	result := make([]*discoveredLink, 0, len(sourceList))
+	for i, value := range sourceList {
+		source, ok := value.(map[string]interface{})
+		if !ok {
+			return nil, NewDeserializationError("unexpected value for discovered link %d, %T", i, value)
+		}
+		discovered, err := readFunc(source)
+		if err != nil {
+			return nil, errors.Annotatef(err, "discovered link %d", i)
+		}
+		result = append(result, discovered)
+	}
+	return result, nil
+}
+
+type discoveredLinkDeserializationFunc func(map[string]interface{}) (*discoveredLink, error)
+
+func discoveredLink_2_0(source map[string]interface{}) (*discoveredLink, error) {
+	fields := schema.Fields{
+		"subnet":     schema.OneOf(schema.Nil(""), schema.StringMap(schema.Any())),
+		"ip_address": schema.OneOf(schema.Nil(""), schema.String()),
+	}
+	defaults := schema.Defaults{
+		"ip_address": "",
+		"subnet":     schema.Omit,
+	}
+	checker := schema.FieldMap(fields, defaults)
+	coerced, err := checker.Coerce(source, nil)
+	if err != nil {
+		return nil, WrapWithDeserializationError(err, "discovered link 2.0 schema check failed")
+	}
+	valid := coerced.(map[string]interface{})
+	// From here we know that the map returned from the schema coercion
+	// contains fields of the right type.
+
+	var subnet *subnet
+	if value, ok := valid["subnet"].(map[string]interface{}); ok {
+		subnet, err = subnet_2_0(value)
+		if err != nil {
+			return nil, errors.Trace(err)
+		}
+	}
+
+	ipAddress, _ := valid["ip_address"].(string)
+	result := &discoveredLink{
+		subnet:    subnet,
+		ipAddress: ipAddress,
+	}
+	return result, nil
+}
@@ -39,6 +39,25 @@ func (*linkSuite) TestReadLinks(c *gc.C) {
 	c.Assert(links[1].IPAddress(), gc.Equals, "")
 }
 
+func (*linkSuite) TestReadDiscoveredLinks(c *gc.C) {
+	discovered, err := readDiscoveredLinkList(parseJSON(c, discoveredLinksResponse).([]interface{}), discoveredLink_2_0)
+	c.Assert(err, jc.ErrorIsNil)
+	c.Assert(discovered, gc.HasLen, 2)
+	c.Check(discovered[0].IPAddress(), gc.Equals, "192.168.100.20")
+	subnet := discovered[0].Subnet()
+	c.Assert(subnet, gc.NotNil)
+	c.Check(subnet.CIDR(), gc.Equals, "192.168.100.0/24")
+	// Second address is not in a known subnet.
+	c.Check(discovered[1].IPAddress(), gc.Equals, "10.0.0.3")
+	c.Check(discovered[1].Subnet(), gc.IsNil)
+}
+
+func (*linkSuite) TestReadDiscoveredLinksBadSchema(c *gc.C) {
+	_, err := readDiscoveredLinkList([]interface{}{"wat?"}, discoveredLink_2_0)
+	c.Check(err, jc.Satisfies, IsDeserializationError)
+	c.Check(err.Error(), gc.Equals, `unexpected value for discovered link 0, string`)
+}
+
 func (*linkSuite) TestLowVersion(c *gc.C) {
 	_, err := readLinks(version.MustParse("1.9.0"), parseJSON(c, linksResponse))
 	c.Assert(err, jc.Satisfies, IsUnsupportedVersionError)
@@ -105,3 +124,36 @@ var linksResponse = `
     }
 ]
 `
+
+var discoveredLinksResponse = `
+[
+    {
+        "ip_address": "192.168.100.20",
+        "subnet": {
+            "resource_uri": "/MAAS/api/2.0/subnets/1/",
+            "id": 1,
+            "rdns_mode": 2,
+            "vlan": {
+                "resource_uri": "/MAAS/api/2.0/vlans/1/",
+                "id": 1,
+                "secondary_rack": null,
+                "mtu": 1500,
+                "primary_rack": "4y3h7n",
+                "name": "untagged",
+                "fabric": "fabric-0",
+                "dhcp_on": true,
+                "vid": 0
+            },
+            "dns_servers": [],
+            "space": "space-0",
+            "name": "192.168.100.0/24",
+            "gateway_ip": "192.168.100.1",
+            "cidr": "192.168.100.0/24"
+        }
+    },
+    {
+        "ip_address": "10.0.0.3",
+        "subnet": null
+    }
+]
+`
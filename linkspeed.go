@@ -0,0 +1,52 @@
+// Copyright 2022 Canonical Ltd.
+// Licensed under the LGPLv3, see LICENCE file for details.
+
+package gomaasapi
+
+import "fmt"
+
+// LinkSpeedMismatch describes an interface whose link negotiated a lower
+// speed than the interface supports, usually due to a cabling or switch
+// port fault.
+type LinkSpeedMismatch struct {
+	Machine   Machine
+	Interface Interface
+}
+
+// String returns a human readable description of the mismatch.
+func (m LinkSpeedMismatch) String() string {
+	return fmt.Sprintf(
+		"%s: %s negotiated %d Mbit/s on a %d Mbit/s interface",
+		m.Machine.Hostname(), m.Interface.Name(),
+		m.Interface.LinkSpeed(), m.Interface.InterfaceSpeed(),
+	)
+}
+
+// LinkSpeedMismatches returns the physical interfaces of the machines whose
+// negotiated link speed is below the speed supported by the interface.
+// Disconnected interfaces, and interfaces for which MAAS doesn't know either
+// speed, are not reported.
+func LinkSpeedMismatches(machines []Machine) []LinkSpeedMismatch {
+	var result []LinkSpeedMismatch
+	for _, machine := range machines {
+		for _, iface := range machine.InterfaceSet() {
+			if linkSpeedMismatched(iface) {
+				result = append(result, LinkSpeedMismatch{
+					Machine:   machine,
+					Interface: iface,
+				})
+			}
+		}
+	}
+	return result
+}
+
+func linkSpeedMismatched(iface Interface) bool {
+	if iface.Type() != "physical" || !iface.LinkConnected() {
+		return false
+	}
+	if iface.LinkSpeed() == 0 || iface.InterfaceSpeed() == 0 {
+		return false
+	}
+	return iface.LinkSpeed() < iface.InterfaceSpeed()
+}
@@ -0,0 +1,46 @@
+// Copyright 2022 Canonical Ltd.
+// Licensed under the LGPLv3, see LICENCE file for details.
+
+package gomaasapi
+
+import (
+	gc "gopkg.in/check.v1"
+)
+
+type linkSpeedSuite struct{}
+
+var _ = gc.Suite(&linkSpeedSuite{})
+
+func (*linkSpeedSuite) TestLinkSpeedMismatches(c *gc.C) {
+	node1 := &machine{
+		hostname: "node1",
+		interfaceSet: []*interface_{
+			{name: "eth0", type_: "physical", linkConnected: true, linkSpeed: 1000, interfaceSpeed: 10000},
+			{name: "eth1", type_: "physical", linkConnected: true, linkSpeed: 10000, interfaceSpeed: 10000},
+			// Disconnected interfaces don't negotiate a speed.
+			{name: "eth2", type_: "physical", linkConnected: false, linkSpeed: 0, interfaceSpeed: 10000},
+			// Unknown speeds are ignored.
+			{name: "eth3", type_: "physical", linkConnected: true, linkSpeed: 0, interfaceSpeed: 1000},
+			{name: "eth4", type_: "physical", linkConnected: true, linkSpeed: 1000, interfaceSpeed: 0},
+			// Only physical interfaces are checked.
+			{name: "bond0", type_: "bond", linkConnected: true, linkSpeed: 1000, interfaceSpeed: 2000},
+		},
+	}
+	node2 := &machine{
+		hostname: "node2",
+		interfaceSet: []*interface_{
+			{name: "eno1", type_: "physical", linkConnected: true, linkSpeed: 100, interfaceSpeed: 1000},
+		},
+	}
+	mismatches := LinkSpeedMismatches([]Machine{node1, node2})
+	c.Assert(mismatches, gc.HasLen, 2)
+	c.Check(mismatches[0].Machine, gc.Equals, node1)
+	c.Check(mismatches[0].Interface.Name(), gc.Equals, "eth0")
+	c.Check(mismatches[0].String(), gc.Equals, "node1: eth0 negotiated 1000 Mbit/s on a 10000 Mbit/s interface")
+	c.Check(mismatches[1].Machine, gc.Equals, node2)
+	c.Check(mismatches[1].Interface.Name(), gc.Equals, "eno1")
+}
+
+func (*linkSpeedSuite) TestLinkSpeedMismatchesNone(c *gc.C) {
+	c.Assert(LinkSpeedMismatches(nil), gc.HasLen, 0)
+}
@@ -0,0 +1,180 @@
+// Copyright 2022 Canonical Ltd.
+// Licensed under the LGPLv3, see LICENCE file for details.
+
+package gomaasapi
+
+import (
+	"encoding/json"
+	"io"
+	"net/url"
+	"strconv"
+
+	"github.com/juju/errors"
+)
+
+// errStopList is returned by the item functions of streamList to stop
+// reading the collection.
+var errStopList = errors.New("stop reading list")
+
+// streamList reads a collection like getListRange, but decodes the items
+// one at a time from the responses and passes them to decode, so that the
+// collection is never held in memory at once. Items are numbered from zero
+// across pages. Once the response of each page has been closed, flush is
+// called: work that makes requests of its own must be done there, as the
+// response holds its slot of the RequestQueue, and its timeout, until then.
+// A plain list is a single page, so all its items are decoded before flush
+// is first called.
+func (c *controller) streamList(path string, params url.Values, offset, limit int, decode func(i int, item interface{}) error, flush func() error) error {
+	query := make(url.Values)
+	for key, values := range params {
+		query[key] = values
+	}
+	if offset > 0 {
+		query.Set("offset", strconv.Itoa(offset))
+	}
+	count := 0
+	each := func(skip bool) func(interface{}) error {
+		skipped := 0
+		return func(item interface{}) error {
+			// Plain lists start at the beginning of the collection.
+			if skip && skipped < offset {
+				skipped++
+				return nil
+			}
+			if limit > 0 && count >= limit {
+				return errStopList
+			}
+			i := count
+			count++
+			return decode(i, item)
+		}
+	}
+	for {
+		page, read, err := c.streamPage(path, query, each)
+		stopped := errors.Cause(err) == errStopList
+		if err != nil && !stopped {
+			return errors.Trace(err)
+		}
+		if err := flush(); err != nil {
+			return errors.Trace(err)
+		}
+		if stopped || page == nil || read == 0 || limit > 0 && count >= limit {
+			return nil
+		}
+		switch {
+		case page.next != "":
+			nextURL, err := url.Parse(page.next)
+			if err != nil {
+				return NewDeserializationError("bad next page link %q: %v", page.next, err)
+			}
+			path = nextURL.Path
+			query = nextURL.Query()
+		case page.total > offset+count:
+			query.Set("offset", strconv.Itoa(offset+count))
+		default:
+			return nil
+		}
+	}
+}
+
+// streamPage requests one page of a collection and decodes its items with
+// the function returned by each, which is told whether the response is a
+// plain list. It returns nil for plain lists, and otherwise the links of the
+// page, along with the number of items read.
+func (c *controller) streamPage(path string, query url.Values, each func(plain bool) func(interface{}) error) (*page, int, error) {
+	path = EnsureTrailingSlash(path)
+	logger.Tracef("request: GET %s%s?%s (streamed)", c.client.APIURL, path, query.Encode())
+	stream, err := c.client.GetStream(&url.URL{Path: path}, "", query)
+	if err != nil {
+		return nil, 0, errors.Trace(err)
+	}
+	defer stream.Close()
+	return decodeListStream(stream, each)
+}
+
+// decodeListStream decodes either a plain list of items, or a page object
+// holding the items along with the next and total fields.
+func decodeListStream(reader io.Reader, each func(plain bool) func(interface{}) error) (*page, int, error) {
+	decoder := json.NewDecoder(reader)
+	token, err := decoder.Token()
+	if err != nil {
+		return nil, 0, errors.Trace(err)
+	}
+	switch token {
+	case json.Delim('['):
+		read, err := decodeListItems(decoder, each(true))
+		if err != nil {
+			return nil, read, errors.Trace(err)
+		}
+		return nil, read, nil
+	case json.Delim('{'):
+	default:
+		return nil, 0, NewDeserializationError("expected a list or a page, got %v", token)
+	}
+	result := &page{}
+	read := -1
+	for decoder.More() {
+		token, err := decoder.Token()
+		if err != nil {
+			return nil, 0, errors.Trace(err)
+		}
+		switch key := token.(string); key {
+		case "items":
+			token, err := decoder.Token()
+			if err != nil {
+				return nil, 0, errors.Trace(err)
+			}
+			if token != json.Delim('[') {
+				return nil, 0, NewDeserializationError("page schema check failed: items: expected list, got %v", token)
+			}
+			if read, err = decodeListItems(decoder, each(false)); err != nil {
+				return nil, read, errors.Trace(err)
+			}
+		case "next":
+			var next *string
+			if err := decoder.Decode(&next); err != nil {
+				return nil, 0, NewDeserializationError("page schema check failed: next: %v", err)
+			}
+			if next != nil {
+				result.next = *next
+			}
+		case "total":
+			var total *float64
+			if err := decoder.Decode(&total); err != nil {
+				return nil, 0, NewDeserializationError("page schema check failed: total: %v", err)
+			}
+			if total != nil {
+				result.total = int(*total)
+			}
+		default:
+			var skip json.RawMessage
+			if err := decoder.Decode(&skip); err != nil {
+				return nil, 0, errors.Trace(err)
+			}
+		}
+	}
+	if read < 0 {
+		return nil, 0, NewDeserializationError("page schema check failed: items: expected list, got nothing")
+	}
+	return result, read, nil
+}
+
+// decodeListItems passes the items of the list being decoded to fn, and
+// consumes the end of the list.
+func decodeListItems(decoder *json.Decoder, fn func(interface{}) error) (int, error) {
+	count := 0
+	for decoder.More() {
+		var item interface{}
+		if err := decoder.Decode(&item); err != nil {
+			return count, errors.Trace(err)
+		}
+		count++
+		if err := fn(item); err != nil {
+			return count, errors.Trace(err)
+		}
+	}
+	if _, err := decoder.Token(); err != nil {
+		return count, errors.Trace(err)
+	}
+	return count, nil
+}
@@ -0,0 +1,264 @@
+// Copyright 2022 Canonical Ltd.
+// Licensed under the LGPLv3, see LICENCE file for details.
+
+package gomaasapi
+
+import (
+	"net/http"
+	"strings"
+	"time"
+
+	"github.com/juju/errors"
+	"github.com/juju/testing"
+	jc "github.com/juju/testing/checkers"
+	gc "gopkg.in/check.v1"
+)
+
+type listStreamSuite struct {
+	testing.LoggingCleanupSuite
+}
+
+var _ = gc.Suite(&listStreamSuite{})
+
+func collectItems(items *[]interface{}, plain *bool) func(bool) func(interface{}) error {
+	return func(isPlain bool) func(interface{}) error {
+		*plain = isPlain
+		return func(item interface{}) error {
+			*items = append(*items, item)
+			return nil
+		}
+	}
+}
+
+func (*listStreamSuite) TestDecodePlainList(c *gc.C) {
+	var (
+		items []interface{}
+		plain bool
+	)
+	page, read, err := decodeListStream(strings.NewReader(`[{"a": 1}, "b"]`), collectItems(&items, &plain))
+	c.Assert(err, jc.ErrorIsNil)
+	c.Check(page, gc.IsNil)
+	c.Check(read, gc.Equals, 2)
+	c.Check(plain, jc.IsTrue)
+	c.Check(items, jc.DeepEquals, []interface{}{map[string]interface{}{"a": 1.0}, "b"})
+}
+
+func (*listStreamSuite) TestDecodePage(c *gc.C) {
+	var (
+		items []interface{}
+		plain bool
+	)
+	source := `{"total": 5, "extra": {"nested": [1, 2]}, "items": [1, 2], "next": "/api/2.0/machines/?page=2"}`
+	page, read, err := decodeListStream(strings.NewReader(source), collectItems(&items, &plain))
+	c.Assert(err, jc.ErrorIsNil)
+	c.Check(read, gc.Equals, 2)
+	c.Check(plain, jc.IsFalse)
+	c.Check(items, jc.DeepEquals, []interface{}{1.0, 2.0})
+	c.Check(page.next, gc.Equals, "/api/2.0/machines/?page=2")
+	c.Check(page.total, gc.Equals, 5)
+
+	page, read, err = decodeListStream(strings.NewReader(`{"items": [], "next": null}`), collectItems(&items, &plain))
+	c.Assert(err, jc.ErrorIsNil)
+	c.Check(read, gc.Equals, 0)
+	c.Check(page.next, gc.Equals, "")
+}
+
+func (*listStreamSuite) TestDecodeErrors(c *gc.C) {
+	var (
+		items []interface{}
+		plain bool
+	)
+	for i, test := range []struct {
+		source  string
+		message string
+	}{{
+		source:  `"machines"`,
+		message: "expected a list or a page, got machines",
+	}, {
+		source:  `{"total": 1}`,
+		message: "page schema check failed: items: expected list, got nothing",
+	}, {
+		source:  `{"items": {}}`,
+		message: "page schema check failed: items: expected list, got {",
+	}, {
+		source:  `{"items": [], "total": "lots"}`,
+		message: "page schema check failed: total: .*",
+	}} {
+		c.Logf("test %d: %s", i, test.source)
+		_, _, err := decodeListStream(strings.NewReader(test.source), collectItems(&items, &plain))
+		c.Check(err, jc.Satisfies, IsDeserializationError)
+		c.Check(err, gc.ErrorMatches, test.message)
+	}
+	_, _, err := decodeListStream(strings.NewReader(`[{"a": `), collectItems(&items, &plain))
+	c.Check(err, gc.NotNil)
+}
+
+func (s *listStreamSuite) walk(c *gc.C, controller Controller, args MachinesArgs) []string {
+	var ids []string
+	err := controller.WalkMachines(args, func(m Machine) error {
+		ids = append(ids, m.SystemID())
+		return nil
+	})
+	c.Assert(err, jc.ErrorIsNil)
+	return ids
+}
+
+func (s *listStreamSuite) TestWalkMachinesPlainList(c *gc.C) {
+	server, controller := createTestServerController(c, s)
+	server.AddGetResponse("/api/2.0/machines/?zone=foo", http.StatusOK, "["+numberedMachines(c, 1, 3)+"]")
+	ids := s.walk(c, controller, MachinesArgs{Zone: "foo"})
+	c.Check(ids, jc.DeepEquals, []string{"machine-1", "machine-2", "machine-3"})
+}
+
+func (s *listStreamSuite) TestWalkMachinesOffsetLimit(c *gc.C) {
+	server, controller := createTestServerController(c, s)
+	server.AddGetResponse("/api/2.0/machines/?offset=1", http.StatusOK, "["+numberedMachines(c, 1, 4)+"]")
+	ids := s.walk(c, controller, MachinesArgs{Offset: 1, Limit: 2})
+	c.Check(ids, jc.DeepEquals, []string{"machine-2", "machine-3"})
+}
+
+func (s *listStreamSuite) TestWalkMachinesPaginated(c *gc.C) {
+	server, controller := createTestServerController(c, s)
+	server.AddGetResponse("/api/2.0/machines/", http.StatusOK,
+		`{"items": [`+numberedMachines(c, 1, 2)+`], "next": "/api/2.0/machines/?page=2"}`)
+	server.AddGetResponse("/api/2.0/machines/?page=2", http.StatusOK,
+		`{"items": [`+numberedMachines(c, 3, 3)+`], "total": 4}`)
+	server.AddGetResponse("/api/2.0/machines/?offset=3&page=2", http.StatusOK,
+		`{"items": [`+numberedMachines(c, 4, 4)+`], "total": 4}`)
+	ids := s.walk(c, controller, MachinesArgs{})
+	c.Check(ids, jc.DeepEquals, []string{"machine-1", "machine-2", "machine-3", "machine-4"})
+}
+
+func (s *listStreamSuite) TestWalkMachinesPaginatedLimit(c *gc.C) {
+	server, controller := createTestServerController(c, s)
+	server.AddGetResponse("/api/2.0/machines/", http.StatusOK,
+		`{"items": [`+numberedMachines(c, 1, 2)+`], "next": "/api/2.0/machines/?page=2"}`)
+	ids := s.walk(c, controller, MachinesArgs{Limit: 2})
+	c.Check(ids, jc.DeepEquals, []string{"machine-1", "machine-2"})
+	// The second page should not have been requested.
+	c.Check(server.LastRequest().URL.String(), gc.Equals, "/api/2.0/machines/")
+}
+
+func (s *listStreamSuite) TestWalkMachinesFilters(c *gc.C) {
+	server, controller := createTestServerController(c, s)
+	owned := updateJSONMap(c, machineResponse, map[string]interface{}{
+		"system_id":  "owned",
+		"owner_data": map[string]interface{}{"owner": "me"},
+	})
+	server.AddGetResponse("/api/2.0/machines/", http.StatusOK, "["+numberedMachines(c, 1, 2)+", "+owned+"]")
+	ids := s.walk(c, controller, MachinesArgs{OwnerData: map[string]string{"owner": "me"}})
+	c.Check(ids, jc.DeepEquals, []string{"owned"})
+}
+
+func (s *listStreamSuite) TestWalkMachinesStopsOnError(c *gc.C) {
+	server, controller := createTestServerController(c, s)
+	server.AddGetResponse("/api/2.0/machines/", http.StatusOK, "["+numberedMachines(c, 1, 3)+"]")
+	stop := errors.New("enough")
+	var ids []string
+	err := controller.WalkMachines(MachinesArgs{}, func(m Machine) error {
+		ids = append(ids, m.SystemID())
+		if len(ids) == 2 {
+			return stop
+		}
+		return nil
+	})
+	c.Assert(err, gc.Equals, stop)
+	c.Check(ids, jc.DeepEquals, []string{"machine-1", "machine-2"})
+}
+
+func (s *listStreamSuite) TestWalkMachinesInvalid(c *gc.C) {
+	server, controller := createTestServerController(c, s)
+	list := "[" + numberedMachines(c, 1, 1) + `, {"system_id": "broken"}, ` + numberedMachines(c, 3, 3) + "]"
+	server.AddGetResponse("/api/2.0/machines/", http.StatusOK, list)
+	server.AddGetResponse("/api/2.0/machines/", http.StatusOK, list)
+
+	err := controller.WalkMachines(MachinesArgs{}, func(Machine) error { return nil })
+	c.Assert(err, jc.Satisfies, IsDeserializationError)
+	c.Check(err, gc.ErrorMatches, "machine 1: .*")
+
+	var ids []string
+	err = controller.WalkMachines(MachinesArgs{SkipInvalid: true}, func(m Machine) error {
+		ids = append(ids, m.SystemID())
+		return nil
+	})
+	c.Assert(err, jc.Satisfies, IsPartialResultError)
+	c.Check(errors.Cause(err).(*PartialResultError).Failures, gc.HasLen, 1)
+	c.Check(errors.Cause(err).(*PartialResultError).Failures["broken"], gc.NotNil)
+	c.Check(ids, jc.DeepEquals, []string{"machine-1", "machine-3"})
+}
+
+func (s *listStreamSuite) TestWalkMachinesServerError(c *gc.C) {
+	server, controller := createTestServerController(c, s)
+	server.AddGetResponse("/api/2.0/machines/", http.StatusInternalServerError, "boom")
+	err := controller.WalkMachines(MachinesArgs{}, func(Machine) error { return nil })
+	c.Assert(err, jc.Satisfies, IsUnexpectedError)
+}
+
+func (s *listStreamSuite) TestWalkMachinesRequestsFromCallback(c *gc.C) {
+	server := NewSimpleServer()
+	server.AddGetResponse("/api/2.0/users/?op=whoami", http.StatusOK, `"captain awesome"`)
+	server.AddGetResponse("/api/2.0/version/", http.StatusOK, versionResponse)
+	server.AddGetResponse("/api/2.0/machines/", http.StatusOK, "["+numberedMachines(c, 1, 2)+"]")
+	server.AddGetResponse("/api/2.0/zones/", http.StatusOK, zoneResponse)
+	server.AddGetResponse("/api/2.0/zones/", http.StatusOK, zoneResponse)
+	server.Start()
+	s.AddCleanup(func(*gc.C) { server.Close() })
+
+	// The only slot of the queue must be free while the callback runs.
+	queue, err := NewRequestQueue(RequestQueueArgs{MaxConcurrent: 1})
+	c.Assert(err, jc.ErrorIsNil)
+	controller, err := NewController(ControllerArgs{
+		BaseURL:      server.URL,
+		APIKey:       "fake:as:key",
+		RequestQueue: queue,
+	})
+	c.Assert(err, jc.ErrorIsNil)
+
+	done := make(chan error, 1)
+	go func() {
+		done <- controller.WalkMachines(MachinesArgs{}, func(Machine) error {
+			_, err := controller.Zones()
+			return err
+		})
+	}()
+	select {
+	case err := <-done:
+		c.Assert(err, jc.ErrorIsNil)
+	case <-time.After(testing.LongWait):
+		c.Fatalf("WalkMachines blocked on a request made by its callback")
+	}
+}
+
+// decodedBeforeFlush returns the number of items streamList decodes before
+// it first calls flush.
+func decodedBeforeFlush(c *gc.C, maas Controller) int {
+	decoded, held := 0, -1
+	err := maas.(*controller).streamList("machines", nil, 0, 0, func(int, interface{}) error {
+		decoded++
+		return nil
+	}, func() error {
+		if held < 0 {
+			held = decoded
+		}
+		return nil
+	})
+	c.Assert(err, jc.ErrorIsNil)
+	return held
+}
+
+func (s *listStreamSuite) TestPlainListHeldBeforeFlush(c *gc.C) {
+	server, controller := createTestServerController(c, s)
+	server.AddGetResponse("/api/2.0/machines/", http.StatusOK, "["+numberedMachines(c, 1, 5)+"]")
+	// A plain list is a single page, so WalkMachines holds all of its
+	// machines before the first callback.
+	c.Check(decodedBeforeFlush(c, controller), gc.Equals, 5)
+}
+
+func (s *listStreamSuite) TestPaginatedHeldBeforeFlush(c *gc.C) {
+	server, controller := createTestServerController(c, s)
+	server.AddGetResponse("/api/2.0/machines/", http.StatusOK,
+		`{"items": [`+numberedMachines(c, 1, 2)+`], "next": "/api/2.0/machines/?page=2"}`)
+	server.AddGetResponse("/api/2.0/machines/?page=2", http.StatusOK,
+		`{"items": [`+numberedMachines(c, 3, 5)+`], "next": null}`)
+	c.Check(decodedBeforeFlush(c, controller), gc.Equals, 2)
+}
@@ -0,0 +1,264 @@
+// Copyright 2022 Canonical Ltd.
+// Licensed under the LGPLv3, see LICENCE file for details.
+
+package gomaasapi
+
+import (
+	"encoding/json"
+	"net/http"
+	"net/url"
+	"os"
+	"path/filepath"
+	"strings"
+
+	"github.com/juju/errors"
+	"github.com/juju/schema"
+	"github.com/juju/version"
+)
+
+// CLIProfile is a profile saved by the "maas login" command of the MAAS
+// CLI.
+type CLIProfile struct {
+	Name string
+	// URL is the versioned API URL of MAAS.
+	URL string
+	// APIKey is empty for the profiles of anonymous logins.
+	APIKey string
+}
+
+// DefaultCLIProfilesPath returns the path of the database the MAAS CLI
+// keeps its profiles in, ~/.maascli.db. If it doesn't exist but the one of
+// the CLI of the MAAS snap does, the path of the latter is returned.
+func DefaultCLIProfilesPath() (string, error) {
+	home, err := os.UserHomeDir()
+	if err != nil {
+		return "", errors.Trace(err)
+	}
+	path := filepath.Join(home, ".maascli.db")
+	if _, err := os.Stat(path); os.IsNotExist(err) {
+		snapPath := filepath.Join(home, "snap", "maas", "current", ".maascli.db")
+		if _, err := os.Stat(snapPath); err == nil {
+			return snapPath, nil
+		}
+	}
+	return path, nil
+}
+
+// ReadCLIProfiles reads the profiles saved by the MAAS CLI in the SQLite
+// database at path, or at DefaultCLIProfilesPath if path is empty. If the
+// database has a write-ahead log that hasn't been checkpointed, which would
+// hold the profiles saved since, a NotSupported error is returned.
+func ReadCLIProfiles(path string) ([]CLIProfile, error) {
+	if path == "" {
+		var err error
+		if path, err = DefaultCLIProfilesPath(); err != nil {
+			return nil, errors.Trace(err)
+		}
+	}
+	data, err := os.ReadFile(path)
+	if os.IsNotExist(err) {
+		return nil, errors.NotFoundf("MAAS CLI profiles %q", path)
+	} else if err != nil {
+		return nil, errors.Trace(err)
+	}
+	// Profiles saved since the write-ahead log was last checkpointed are
+	// only in the log, which isn't read.
+	if info, err := os.Stat(path + "-wal"); err == nil && info.Size() > 0 {
+		return nil, errors.NotSupportedf("MAAS CLI profiles %q with the write-ahead log %q", path, path+"-wal")
+	}
+	rows, err := readSQLiteTable(data, "profiles")
+	if err != nil {
+		return nil, errors.Annotatef(err, "reading %q", path)
+	}
+	profiles := make([]CLIProfile, 0, len(rows))
+	for _, row := range rows {
+		// The columns are the ID, the name and the profile as JSON.
+		if len(row) < 3 {
+			return nil, errors.NotValidf("MAAS CLI profile with %d columns", len(row))
+		}
+		var content []byte
+		switch value := row[2].(type) {
+		case string:
+			content = []byte(value)
+		case []byte:
+			content = value
+		default:
+			return nil, errors.NotValidf("MAAS CLI profile %v", row[1])
+		}
+		profile, err := ParseCLIProfile(content)
+		if err != nil {
+			return nil, errors.Annotatef(err, "MAAS CLI profile %v", row[1])
+		}
+		profiles = append(profiles, profile)
+	}
+	return profiles, nil
+}
+
+// ReadCLIProfile returns the named profile of the MAAS CLI, read from the
+// database at path as ReadCLIProfiles does. If name is empty, the database
+// must hold a single profile.
+func ReadCLIProfile(path, name string) (CLIProfile, error) {
+	profiles, err := ReadCLIProfiles(path)
+	if err != nil {
+		return CLIProfile{}, errors.Trace(err)
+	}
+	var profile *CLIProfile
+	for i := range profiles {
+		if name == "" || profiles[i].Name == name {
+			if profile != nil {
+				return CLIProfile{}, errors.NotValidf("empty profile name with %d profiles", len(profiles))
+			}
+			profile = &profiles[i]
+		}
+	}
+	if profile == nil {
+		return CLIProfile{}, errors.NotFoundf("MAAS CLI profile %q", name)
+	}
+	return *profile, nil
+}
+
+// ParseCLIProfile parses a profile as the MAAS CLI saves it, a JSON object
+// with the name, the URL and the credentials of the profile.
+func ParseCLIProfile(content []byte) (CLIProfile, error) {
+	var profile struct {
+		Name        string   `json:"name"`
+		URL         string   `json:"url"`
+		Credentials []string `json:"credentials"`
+	}
+	if err := json.Unmarshal(content, &profile); err != nil {
+		return CLIProfile{}, errors.NewNotValid(err, "profile")
+	}
+	result := CLIProfile{Name: profile.Name, URL: profile.URL}
+	switch len(profile.Credentials) {
+	case 0:
+	case 3:
+		result.APIKey = strings.Join(profile.Credentials, ":")
+	default:
+		return CLIProfile{}, errors.NotValidf("credentials")
+	}
+	return result, nil
+}
+
+// NewControllerFromCLIProfile creates a controller with the URL and the API
+// key of the profile of the MAAS CLI, as returned by ReadCLIProfile. The
+// other fields of args are used as by NewController.
+func NewControllerFromCLIProfile(profile CLIProfile, args ControllerArgs) (Controller, error) {
+	if profile.URL == "" {
+		return nil, errors.NotValidf("MAAS CLI profile %q without URL", profile.Name)
+	}
+	if profile.APIKey == "" {
+		return nil, errors.NotValidf("anonymous MAAS CLI profile %q", profile.Name)
+	}
+	args.BaseURL = profile.URL
+	args.APIKey = profile.APIKey
+	args.CredentialProvider = nil
+	return NewController(args)
+}
+
+// LoginArgs is an argument struct for Login.
+type LoginArgs struct {
+	ControllerArgs
+
+	Username string
+	Password string
+	// TokenName is the name of the API key created for the login. It
+	// defaults to "gomaasapi".
+	TokenName string
+}
+
+// Validate ensures that the base URL and the credentials are set.
+func (a LoginArgs) Validate() error {
+	if a.BaseURL == "" {
+		return errors.NotValidf("missing BaseURL")
+	}
+	if a.Username == "" {
+		return errors.NotValidf("missing Username")
+	}
+	if a.Password == "" {
+		return errors.NotValidf("missing Password")
+	}
+	return nil
+}
+
+// Login exchanges the username and the password of a MAAS user for a new
+// API key, like the "maas login" command does when it isn't given a key,
+// and creates a controller using it. The API key is returned as well, so
+// that it can be saved and used instead of the password next time. The
+// APIKey and CredentialProvider of the args are ignored.
+//
+// If the credentials are incorrect, a PermissionError is returned.
+func Login(args LoginArgs) (Controller, string, error) {
+	if err := args.Validate(); err != nil {
+		return nil, "", errors.Trace(err)
+	}
+	base, apiVersion, includesVersion := SplitVersionedURL(args.BaseURL)
+	if !includesVersion {
+		apiVersion = supportedAPIVersions[len(supportedAPIVersions)-1]
+	}
+	httpClient, err := args.httpClient()
+	if err != nil {
+		return nil, "", errors.Trace(err)
+	}
+	client, err := NewAnonymousClient(base, apiVersion)
+	if err != nil {
+		return nil, "", NewUnexpectedError(err)
+	}
+	args.configure(client, httpClient)
+	tokenName := args.TokenName
+	if tokenName == "" {
+		tokenName = "gomaasapi"
+	}
+	major, minor, err := version.ParseMajorMinor(apiVersion)
+	if err != nil {
+		return nil, "", NewUnsupportedVersionError("version %s", apiVersion)
+	}
+	c := &controller{client: client, apiVersion: version.Number{Major: major, Minor: minor}}
+	params := url.Values{
+		"username": {args.Username},
+		"password": {args.Password},
+		"consumer": {tokenName},
+	}
+	// The view is at the root of MAAS rather than in the API.
+	source, err := c.post("../../accounts/authenticate", "", params)
+	if err != nil {
+		if svrErr, ok := errors.Cause(err).(ServerError); ok {
+			switch svrErr.StatusCode {
+			case http.StatusUnauthorized, http.StatusForbidden:
+				return nil, "", wrapError(err, NewPermissionError(svrErr.BodyMessage))
+			case http.StatusBadRequest:
+				return nil, "", wrapError(err, NewBadRequestError(svrErr.BodyMessage))
+			}
+		}
+		return nil, "", NewUnexpectedError(err)
+	}
+	apiKey, err := readLoginToken(source)
+	if err != nil {
+		return nil, "", errors.Trace(err)
+	}
+	args.ControllerArgs.APIKey = apiKey
+	args.ControllerArgs.CredentialProvider = nil
+	controller, err := NewController(args.ControllerArgs)
+	if err != nil {
+		return nil, "", errors.Trace(err)
+	}
+	return controller, apiKey, nil
+}
+
+// readLoginToken returns the API key of the token created by a login.
+func readLoginToken(source interface{}) (string, error) {
+	checker := schema.FieldMap(schema.Fields{
+		"consumer_key": schema.String(),
+		"token_key":    schema.String(),
+		"token_secret": schema.String(),
+	}, nil)
+	coerced, err := checker.Coerce(source, nil)
+	if err != nil {
+		return "", WrapWithDeserializationError(err, "login token schema check failed")
+	}
+	valid := coerced.(map[string]interface{})
+	return strings.Join([]string{
+		valid["consumer_key"].(string),
+		valid["token_key"].(string),
+		valid["token_secret"].(string),
+	}, ":"), nil
+}
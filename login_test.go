@@ -0,0 +1,257 @@
+// Copyright 2022 Canonical Ltd.
+// Licensed under the LGPLv3, see LICENCE file for details.
+
+package gomaasapi
+
+import (
+	"net/http"
+	"os"
+	"path/filepath"
+
+	"github.com/juju/errors"
+	"github.com/juju/testing"
+	jc "github.com/juju/testing/checkers"
+	gc "gopkg.in/check.v1"
+)
+
+type loginSuite struct {
+	testing.LoggingCleanupSuite
+}
+
+var _ = gc.Suite(&loginSuite{})
+
+const loginResponse = `{
+    "name": "gomaasapi",
+    "consumer_key": "consumer",
+    "token_key": "token",
+    "token_secret": "secret"
+}`
+
+func (s *loginSuite) TestReadCLIProfiles(c *gc.C) {
+	// The profile of admin is large enough to continue on overflow pages,
+	// and the profiles span several pages of the table.
+	profiles, err := ReadCLIProfiles("testdata/maascli.db")
+	c.Assert(err, jc.ErrorIsNil)
+	c.Assert(profiles, gc.HasLen, 32)
+	c.Check(profiles[0], jc.DeepEquals, CLIProfile{
+		Name:   "admin",
+		URL:    "http://maas.example.com:5240/MAAS/api/2.0/",
+		APIKey: "ck:tk:ts",
+	})
+	c.Check(profiles[1], jc.DeepEquals, CLIProfile{
+		Name: "anonymous",
+		URL:  "http://maas.example.com:5240/MAAS/api/2.0/",
+	})
+	c.Check(profiles[31], jc.DeepEquals, CLIProfile{
+		Name:   "extra-29",
+		URL:    "http://maas29.example.com/MAAS/api/2.0/",
+		APIKey: "c29:t29:s29",
+	})
+}
+
+func (s *loginSuite) TestReadCLIProfilesMissing(c *gc.C) {
+	_, err := ReadCLIProfiles(filepath.Join(c.MkDir(), "missing.db"))
+	c.Assert(err, jc.Satisfies, errors.IsNotFound)
+}
+
+func (s *loginSuite) TestReadCLIProfilesInvalid(c *gc.C) {
+	path := filepath.Join(c.MkDir(), "maascli.db")
+	err := os.WriteFile(path, []byte("not a database"), 0600)
+	c.Assert(err, jc.ErrorIsNil)
+	_, err = ReadCLIProfiles(path)
+	c.Assert(err, jc.Satisfies, errors.IsNotValid)
+
+	// A truncated database is reported as invalid too.
+	data, err := os.ReadFile("testdata/maascli.db")
+	c.Assert(err, jc.ErrorIsNil)
+	err = os.WriteFile(path, data[:1024], 0600)
+	c.Assert(err, jc.ErrorIsNil)
+	_, err = ReadCLIProfiles(path)
+	c.Assert(err, jc.Satisfies, errors.IsNotValid)
+}
+
+func (s *loginSuite) TestReadCLIProfilesCorrupt(c *gc.C) {
+	data, err := os.ReadFile("testdata/maascli.db")
+	c.Assert(err, jc.ErrorIsNil)
+	// Corrupt files must be reported as errors rather than panic. The
+	// damaged byte and its value cover headers, cell offsets and varints.
+	for offset := 16; offset < len(data); offset += 7 {
+		for _, value := range []byte{0x00, 0x7f, 0xff} {
+			corrupt := append([]byte(nil), data...)
+			corrupt[offset] = value
+			_, _ = readSQLiteTable(corrupt, "profiles")
+		}
+	}
+	for size := 100; size < len(data); size += 97 {
+		_, err := readSQLiteTable(data[:size], "profiles")
+		c.Check(err, gc.NotNil)
+	}
+}
+
+func (s *loginSuite) TestReadCLIProfilesWAL(c *gc.C) {
+	data, err := os.ReadFile("testdata/maascli.db")
+	c.Assert(err, jc.ErrorIsNil)
+	path := filepath.Join(c.MkDir(), "maascli.db")
+	c.Assert(os.WriteFile(path, data, 0600), jc.ErrorIsNil)
+
+	// An empty log holds no profiles.
+	c.Assert(os.WriteFile(path+"-wal", nil, 0600), jc.ErrorIsNil)
+	_, err = ReadCLIProfiles(path)
+	c.Assert(err, jc.ErrorIsNil)
+
+	c.Assert(os.WriteFile(path+"-wal", []byte("frames"), 0600), jc.ErrorIsNil)
+	_, err = ReadCLIProfiles(path)
+	c.Assert(err, jc.Satisfies, errors.IsNotSupported)
+}
+
+func (s *loginSuite) TestReadSQLiteTableMissing(c *gc.C) {
+	data, err := os.ReadFile("testdata/maascli.db")
+	c.Assert(err, jc.ErrorIsNil)
+	_, err = readSQLiteTable(data, "missing")
+	c.Assert(err, jc.Satisfies, errors.IsNotFound)
+}
+
+func (s *loginSuite) TestReadCLIProfile(c *gc.C) {
+	profile, err := ReadCLIProfile("testdata/maascli.db", "extra-29")
+	c.Assert(err, jc.ErrorIsNil)
+	c.Check(profile.APIKey, gc.Equals, "c29:t29:s29")
+	_, err = ReadCLIProfile("testdata/maascli.db", "missing")
+	c.Check(err, jc.Satisfies, errors.IsNotFound)
+	_, err = ReadCLIProfile("testdata/maascli.db", "")
+	c.Check(err, jc.Satisfies, errors.IsNotValid)
+}
+
+func (s *loginSuite) TestParseCLIProfile(c *gc.C) {
+	profile, err := ParseCLIProfile([]byte(`{
+    "name": "admin",
+    "url": "http://maas.example.com:5240/MAAS/api/2.0/",
+    "credentials": ["ck", "tk", "ts"],
+    "description": {"resources": []}
+}`))
+	c.Assert(err, jc.ErrorIsNil)
+	c.Check(profile, jc.DeepEquals, CLIProfile{
+		Name:   "admin",
+		URL:    "http://maas.example.com:5240/MAAS/api/2.0/",
+		APIKey: "ck:tk:ts",
+	})
+
+	profile, err = ParseCLIProfile([]byte(`{"name": "anonymous", "url": "http://maas.example.com:5240/MAAS/api/2.0/", "credentials": null}`))
+	c.Assert(err, jc.ErrorIsNil)
+	c.Check(profile, jc.DeepEquals, CLIProfile{
+		Name: "anonymous",
+		URL:  "http://maas.example.com:5240/MAAS/api/2.0/",
+	})
+}
+
+func (s *loginSuite) TestParseCLIProfileInvalid(c *gc.C) {
+	_, err := ParseCLIProfile([]byte("not JSON"))
+	c.Check(err, jc.Satisfies, errors.IsNotValid)
+	_, err = ParseCLIProfile([]byte(`{"name": "admin", "credentials": ["ck", "tk"]}`))
+	c.Check(err, jc.Satisfies, errors.IsNotValid)
+}
+
+func (s *loginSuite) TestNewControllerFromCLIProfile(c *gc.C) {
+	server := s.newServer(c)
+	server.AddGetResponse("/api/2.0/zones/", http.StatusOK, zoneResponse)
+	controller, err := NewControllerFromCLIProfile(CLIProfile{
+		Name:   "admin",
+		URL:    server.URL + "/api/2.0/",
+		APIKey: "ck:tk:ts",
+	}, ControllerArgs{})
+	c.Assert(err, jc.ErrorIsNil)
+	_, err = controller.Zones()
+	c.Assert(err, jc.ErrorIsNil)
+	c.Check(server.LastRequest().Header.Get("Authorization"), jc.Contains, `oauth_token="tk"`)
+}
+
+func (s *loginSuite) TestNewControllerFromCLIProfileErrors(c *gc.C) {
+	_, err := NewControllerFromCLIProfile(CLIProfile{Name: "anonymous", URL: "http://maas.invalid/MAAS/api/2.0/"}, ControllerArgs{})
+	c.Check(err, jc.Satisfies, errors.IsNotValid)
+	_, err = NewControllerFromCLIProfile(CLIProfile{Name: "admin", APIKey: "ck:tk:ts"}, ControllerArgs{})
+	c.Check(err, jc.Satisfies, errors.IsNotValid)
+}
+
+func (s *loginSuite) newServer(c *gc.C) *SimpleTestServer {
+	server := NewSimpleServer()
+	server.AddGetResponse("/api/2.0/users/?op=whoami", http.StatusOK, `"captain awesome"`)
+	server.AddGetResponse("/api/2.0/version/", http.StatusOK, versionResponse)
+	server.Start()
+	s.AddCleanup(func(*gc.C) { server.Close() })
+	return server
+}
+
+func (s *loginSuite) TestLogin(c *gc.C) {
+	server := s.newServer(c)
+	server.AddPostResponse("/accounts/authenticate/?op=", http.StatusOK, loginResponse)
+
+	controller, apiKey, err := Login(LoginArgs{
+		ControllerArgs: ControllerArgs{BaseURL: server.URL},
+		Username:       "admin",
+		Password:       "secret",
+	})
+	c.Assert(err, jc.ErrorIsNil)
+	c.Check(apiKey, gc.Equals, "consumer:token:secret")
+
+	request := server.requests[0]
+	c.Check(request.URL.Path, gc.Equals, "/accounts/authenticate/")
+	c.Check(request.Header.Get("Authorization"), gc.Equals, "")
+	c.Check(request.PostForm.Get("username"), gc.Equals, "admin")
+	c.Check(request.PostForm.Get("password"), gc.Equals, "secret")
+	c.Check(request.PostForm.Get("consumer"), gc.Equals, "gomaasapi")
+
+	// The controller uses the new key.
+	server.AddGetResponse("/api/2.0/zones/", http.StatusOK, zoneResponse)
+	_, err = controller.Zones()
+	c.Assert(err, jc.ErrorIsNil)
+	c.Check(server.LastRequest().Header.Get("Authorization"), jc.Contains, `oauth_token="token"`)
+}
+
+func (s *loginSuite) TestLoginVersionedURL(c *gc.C) {
+	server := NewSimpleServer()
+	server.AddPostResponse("/MAAS/accounts/authenticate/?op=", http.StatusOK, loginResponse)
+	server.AddGetResponse("/MAAS/api/2.0/users/?op=whoami", http.StatusOK, `"captain awesome"`)
+	server.AddGetResponse("/MAAS/api/2.0/version/", http.StatusOK, versionResponse)
+	server.Start()
+	defer server.Close()
+
+	_, _, err := Login(LoginArgs{
+		ControllerArgs: ControllerArgs{BaseURL: server.URL + "/MAAS/api/2.0/"},
+		Username:       "admin",
+		Password:       "secret",
+		TokenName:      "juju",
+	})
+	c.Assert(err, jc.ErrorIsNil)
+	c.Check(server.requests[0].PostForm.Get("consumer"), gc.Equals, "juju")
+}
+
+func (s *loginSuite) TestLoginBadCredentials(c *gc.C) {
+	server := s.newServer(c)
+	server.AddPostResponse("/accounts/authenticate/?op=", http.StatusForbidden, "")
+
+	_, _, err := Login(LoginArgs{
+		ControllerArgs: ControllerArgs{BaseURL: server.URL},
+		Username:       "admin",
+		Password:       "wrong",
+	})
+	c.Assert(err, jc.Satisfies, IsPermissionError)
+}
+
+func (s *loginSuite) TestLoginUnexpected(c *gc.C) {
+	server := s.newServer(c)
+	server.AddPostResponse("/accounts/authenticate/?op=", http.StatusInternalServerError, "boom")
+
+	_, _, err := Login(LoginArgs{
+		ControllerArgs: ControllerArgs{BaseURL: server.URL},
+		Username:       "admin",
+		Password:       "secret",
+	})
+	c.Assert(err, jc.Satisfies, IsUnexpectedError)
+}
+
+func (s *loginSuite) TestLoginValidate(c *gc.C) {
+	_, _, err := Login(LoginArgs{
+		ControllerArgs: ControllerArgs{BaseURL: "http://maas.invalid/MAAS/"},
+		Username:       "admin",
+	})
+	c.Assert(err, jc.Satisfies, errors.IsNotValid)
+}
@@ -0,0 +1,236 @@
+// Copyright 2022 Canonical Ltd.
+// Licensed under the LGPLv3, see LICENCE file for details.
+
+package gomaasapi
+
+import (
+	"fmt"
+	"net"
+	"net/http"
+	"strconv"
+	"sync"
+	"time"
+
+	"github.com/juju/errors"
+)
+
+// lookupCacheTTL is how long the listings fetched by the lookup methods of
+// the controller are reused.
+var lookupCacheTTL = 30 * time.Second
+
+// The kinds of entities the lookup cache indexes.
+const (
+	lookupSubnetsByCIDR = "subnet"
+	lookupVLANsByID     = "vlan"
+	lookupFabricsByName = "fabric"
+	lookupSpacesByName  = "space"
+)
+
+// lookupDependents lists, for each kind changed through the controller,
+// the other kinds whose entities embed it, and so go stale along with it.
+var lookupDependents = map[string][]string{
+	lookupSpacesByName:  {lookupSubnetsByCIDR, lookupVLANsByID, lookupFabricsByName},
+	lookupSubnetsByCIDR: {lookupSpacesByName},
+	lookupVLANsByID:     {lookupFabricsByName, lookupSubnetsByCIDR, lookupSpacesByName},
+}
+
+// lookupCache holds indexes of listings by the kind of entity. The zero
+// value is ready to use, and a nil cache fetches the listings every time.
+type lookupCache struct {
+	mu      sync.Mutex
+	indexes map[string]*lookupIndex
+	// generation is incremented whenever indexes are dropped, so that
+	// listings fetched before then aren't stored.
+	generation uint64
+}
+
+type lookupIndex struct {
+	fetched time.Time
+	entries map[string]interface{}
+}
+
+// lookup returns the entry with the key from the index of the kind. The
+// index is fetched if it has expired, or if it doesn't have the key, so that
+// entities created since the index was fetched are found.
+func (l *lookupCache) lookup(kind, key string, fetch func() (map[string]interface{}, error)) (interface{}, error) {
+	if l == nil {
+		l = &lookupCache{}
+	}
+	// The lock isn't held while fetching, so that a slow listing doesn't
+	// hold up the lookups of other kinds.
+	l.mu.Lock()
+	index := l.indexes[kind]
+	if index != nil && timeNow().Sub(index.fetched) < lookupCacheTTL {
+		if entry, ok := index.entries[key]; ok {
+			l.mu.Unlock()
+			return entry, nil
+		}
+	}
+	generation := l.generation
+	l.mu.Unlock()
+	entries, err := fetch()
+	if err != nil {
+		return nil, errors.Trace(err)
+	}
+	l.mu.Lock()
+	if l.generation == generation {
+		if l.indexes == nil {
+			l.indexes = make(map[string]*lookupIndex)
+		}
+		l.indexes[kind] = &lookupIndex{fetched: timeNow(), entries: entries}
+	}
+	l.mu.Unlock()
+	if entry, ok := entries[key]; ok {
+		return entry, nil
+	}
+	return nil, NewNoMatchError(fmt.Sprintf("no %s %q", kind, key))
+}
+
+// clear drops all the indexes.
+func (l *lookupCache) clear() {
+	if l == nil {
+		return
+	}
+	l.mu.Lock()
+	defer l.mu.Unlock()
+	l.indexes = nil
+	l.generation++
+}
+
+// invalidate drops the index of the kind, along with those of its
+// dependents, once an entity of the kind has been changed. It is called
+// whether or not the change succeeded, as a request that failed, such as
+// one that timed out, may still have been applied.
+func (l *lookupCache) invalidate(kind string) {
+	if l == nil {
+		return
+	}
+	l.mu.Lock()
+	defer l.mu.Unlock()
+	delete(l.indexes, kind)
+	for _, dependent := range lookupDependents[kind] {
+		delete(l.indexes, dependent)
+	}
+	l.generation++
+}
+
+// ClearLookupCache implements Controller.
+func (c *controller) ClearLookupCache() {
+	c.lookups.clear()
+}
+
+// SubnetByID implements Controller.
+func (c *controller) SubnetByID(id int) (Subnet, error) {
+	source, err := c.get(fmt.Sprintf("subnets/%d", id))
+	if err != nil {
+		if svrErr, ok := errors.Cause(err).(ServerError); ok {
+			switch svrErr.StatusCode {
+			case http.StatusNotFound:
+				return nil, wrapError(err, NewNoMatchError(svrErr.BodyMessage))
+			case http.StatusForbidden:
+				return nil, wrapError(err, NewPermissionError(svrErr.BodyMessage))
+			}
+		}
+		return nil, NewUnexpectedError(err)
+	}
+	subnet, err := readSubnet(c.schemaVersion(), source)
+	if err != nil {
+		return nil, errors.Trace(err)
+	}
+	subnet.controller = c
+	return subnet, nil
+}
+
+// SubnetByCIDR implements Controller.
+func (c *controller) SubnetByCIDR(cidr string) (Subnet, error) {
+	key, err := normalizeCIDR(cidr)
+	if err != nil {
+		return nil, errors.Trace(err)
+	}
+	entry, err := c.lookups.lookup(lookupSubnetsByCIDR, key, func() (map[string]interface{}, error) {
+		subnets, err := c.Subnets()
+		if err != nil {
+			return nil, errors.Trace(err)
+		}
+		entries := make(map[string]interface{}, len(subnets))
+		for _, subnet := range subnets {
+			if key, err := normalizeCIDR(subnet.CIDR()); err == nil {
+				entries[key] = subnet
+			}
+		}
+		return entries, nil
+	})
+	if err != nil {
+		return nil, errors.Trace(err)
+	}
+	return entry.(Subnet), nil
+}
+
+// normalizeCIDR returns the canonical form of the CIDR, so that
+// "10.0.0.1/8" and "10.0.0.0/8" are the same network.
+func normalizeCIDR(cidr string) (string, error) {
+	_, network, err := net.ParseCIDR(cidr)
+	if err != nil {
+		return "", errors.NotValidf("CIDR %q", cidr)
+	}
+	return network.String(), nil
+}
+
+// VLANByID implements Controller.
+func (c *controller) VLANByID(id int) (VLAN, error) {
+	entry, err := c.lookups.lookup(lookupVLANsByID, strconv.Itoa(id), func() (map[string]interface{}, error) {
+		fabrics, err := c.Fabrics()
+		if err != nil {
+			return nil, errors.Trace(err)
+		}
+		entries := make(map[string]interface{})
+		for _, fabric := range fabrics {
+			for _, vlan := range fabric.VLANs() {
+				entries[strconv.Itoa(vlan.ID())] = vlan
+			}
+		}
+		return entries, nil
+	})
+	if err != nil {
+		return nil, errors.Trace(err)
+	}
+	return entry.(VLAN), nil
+}
+
+// FabricByName implements Controller.
+func (c *controller) FabricByName(name string) (Fabric, error) {
+	entry, err := c.lookups.lookup(lookupFabricsByName, name, func() (map[string]interface{}, error) {
+		fabrics, err := c.Fabrics()
+		if err != nil {
+			return nil, errors.Trace(err)
+		}
+		entries := make(map[string]interface{}, len(fabrics))
+		for _, fabric := range fabrics {
+			entries[fabric.Name()] = fabric
+		}
+		return entries, nil
+	})
+	if err != nil {
+		return nil, errors.Trace(err)
+	}
+	return entry.(Fabric), nil
+}
+
+// SpaceByName implements Controller.
+func (c *controller) SpaceByName(name string) (Space, error) {
+	entry, err := c.lookups.lookup(lookupSpacesByName, name, func() (map[string]interface{}, error) {
+		spaces, err := c.Spaces()
+		if err != nil {
+			return nil, errors.Trace(err)
+		}
+		entries := make(map[string]interface{}, len(spaces))
+		for _, space := range spaces {
+			entries[space.Name()] = space
+		}
+		return entries, nil
+	})
+	if err != nil {
+		return nil, errors.Trace(err)
+	}
+	return entry.(Space), nil
+}
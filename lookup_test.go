@@ -0,0 +1,186 @@
+// Copyright 2022 Canonical Ltd.
+// Licensed under the LGPLv3, see LICENCE file for details.
+
+package gomaasapi
+
+import (
+	"net/http"
+	"time"
+
+	"github.com/juju/errors"
+	"github.com/juju/testing"
+	jc "github.com/juju/testing/checkers"
+	gc "gopkg.in/check.v1"
+)
+
+type lookupSuite struct {
+	testing.LoggingCleanupSuite
+}
+
+var _ = gc.Suite(&lookupSuite{})
+
+func (s *lookupSuite) TestSubnetByID(c *gc.C) {
+	server, controller := createTestServerController(c, s)
+	server.AddGetResponse("/api/2.0/subnets/34/", http.StatusOK, subnetItemResponse)
+	subnet, err := controller.SubnetByID(34)
+	c.Assert(err, jc.ErrorIsNil)
+	c.Check(subnet.CIDR(), gc.Equals, "192.168.122.0/24")
+	c.Check(subnet.VLAN().ID(), gc.Equals, 5001)
+}
+
+func (s *lookupSuite) TestSubnetByIDMissing(c *gc.C) {
+	_, controller := createTestServerController(c, s)
+	_, err := controller.SubnetByID(34)
+	c.Assert(err, jc.Satisfies, IsNoMatchError)
+}
+
+func (s *lookupSuite) TestSubnetByCIDR(c *gc.C) {
+	server, controller := createTestServerController(c, s)
+	server.AddGetResponse("/api/2.0/subnets/", http.StatusOK, subnetResponse)
+	subnet, err := controller.SubnetByCIDR("192.168.122.1/24")
+	c.Assert(err, jc.ErrorIsNil)
+	c.Check(subnet.ID(), gc.Equals, 34)
+
+	// The listing is reused by later lookups.
+	server.ResetRequests()
+	subnet, err = controller.SubnetByCIDR("192.168.100.0/24")
+	c.Assert(err, jc.ErrorIsNil)
+	c.Check(subnet.ID(), gc.Equals, 1)
+	c.Check(server.RequestCount(), gc.Equals, 0)
+}
+
+func (s *lookupSuite) TestSubnetByCIDRInvalid(c *gc.C) {
+	server, controller := createTestServerController(c, s)
+	server.ResetRequests()
+	_, err := controller.SubnetByCIDR("192.168.122.0")
+	c.Assert(err, jc.Satisfies, errors.IsNotValid)
+	c.Check(server.RequestCount(), gc.Equals, 0)
+}
+
+func (s *lookupSuite) TestSubnetByCIDRMissRefetches(c *gc.C) {
+	server, controller := createTestServerController(c, s)
+	server.AddGetResponse("/api/2.0/subnets/", http.StatusOK, subnetResponse)
+	server.AddGetResponse("/api/2.0/subnets/", http.StatusOK, subnetResponse)
+	_, err := controller.SubnetByCIDR("192.168.100.0/24")
+	c.Assert(err, jc.ErrorIsNil)
+
+	server.ResetRequests()
+	_, err = controller.SubnetByCIDR("10.0.0.0/8")
+	c.Assert(err, jc.Satisfies, IsNoMatchError)
+	c.Check(err.Error(), gc.Equals, `no subnet "10.0.0.0/8"`)
+	c.Check(server.RequestCount(), gc.Equals, 1)
+}
+
+func (s *lookupSuite) TestLookupCacheExpires(c *gc.C) {
+	now := time.Date(2022, 3, 1, 10, 0, 0, 0, time.UTC)
+	s.PatchValue(&timeNow, func() time.Time { return now })
+	server, controller := createTestServerController(c, s)
+	server.AddGetResponse("/api/2.0/spaces/", http.StatusOK, spacesResponse)
+	server.AddGetResponse("/api/2.0/spaces/", http.StatusOK, spacesResponse)
+	_, err := controller.SpaceByName("space-0")
+	c.Assert(err, jc.ErrorIsNil)
+
+	server.ResetRequests()
+	now = now.Add(lookupCacheTTL)
+	space, err := controller.SpaceByName("space-0")
+	c.Assert(err, jc.ErrorIsNil)
+	c.Check(space.ID(), gc.Equals, 0)
+	c.Check(server.RequestCount(), gc.Equals, 1)
+}
+
+func (s *lookupSuite) TestClearLookupCache(c *gc.C) {
+	server, controller := createTestServerController(c, s)
+	server.AddGetResponse("/api/2.0/fabrics/", http.StatusOK, fabricResponse)
+	server.AddGetResponse("/api/2.0/fabrics/", http.StatusOK, fabricResponse)
+	_, err := controller.FabricByName("fabric-1")
+	c.Assert(err, jc.ErrorIsNil)
+
+	server.ResetRequests()
+	controller.ClearLookupCache()
+	fabric, err := controller.FabricByName("fabric-1")
+	c.Assert(err, jc.ErrorIsNil)
+	c.Check(fabric.ID(), gc.Equals, 1)
+	c.Check(server.RequestCount(), gc.Equals, 1)
+}
+
+func (s *lookupSuite) TestVLANByID(c *gc.C) {
+	server, controller := createTestServerController(c, s)
+	server.AddGetResponse("/api/2.0/fabrics/", http.StatusOK, fabricResponse)
+	vlan, err := controller.VLANByID(5001)
+	c.Assert(err, jc.ErrorIsNil)
+	c.Check(vlan.Fabric(), gc.Equals, "fabric-1")
+
+	// VLANs found through the lookup can be changed.
+	server.AddDeleteResponse("/MAAS/api/2.0/vlans/5001/", http.StatusNoContent, "")
+	c.Assert(vlan.Delete(), jc.ErrorIsNil)
+}
+
+func (s *lookupSuite) TestSpaceByNameError(c *gc.C) {
+	server, controller := createTestServerController(c, s)
+	server.AddGetResponse("/api/2.0/spaces/", http.StatusInternalServerError, "boom")
+	_, err := controller.SpaceByName("space-0")
+	c.Assert(err, jc.Satisfies, IsUnexpectedError)
+}
+
+func (s *lookupSuite) TestDeletedSpaceIsNotFound(c *gc.C) {
+	server, controller := createTestServerController(c, s)
+	server.AddGetResponse("/api/2.0/spaces/", http.StatusOK, spacesResponse)
+	server.AddGetResponse("/api/2.0/spaces/", http.StatusOK, "[]")
+	found, err := controller.SpaceByName("space-0")
+	c.Assert(err, jc.ErrorIsNil)
+
+	server.AddDeleteResponse(found.(*space).resourceURI, http.StatusNoContent, "")
+	c.Assert(found.Delete(), jc.ErrorIsNil)
+	_, err = controller.SpaceByName("space-0")
+	c.Assert(err, jc.Satisfies, IsNoMatchError)
+}
+
+func (s *lookupSuite) TestInvalidateDependents(c *gc.C) {
+	var cache lookupCache
+	fetches := 0
+	fetch := func() (map[string]interface{}, error) {
+		fetches++
+		return map[string]interface{}{"key": "value"}, nil
+	}
+	for _, kind := range []string{lookupSpacesByName, lookupSubnetsByCIDR, lookupFabricsByName} {
+		_, err := cache.lookup(kind, "key", fetch)
+		c.Assert(err, jc.ErrorIsNil)
+	}
+
+	// Subnets are embedded in spaces, but not in fabrics.
+	cache.invalidate(lookupSubnetsByCIDR)
+	fetches = 0
+	for _, kind := range []string{lookupSpacesByName, lookupSubnetsByCIDR, lookupFabricsByName} {
+		_, err := cache.lookup(kind, "key", fetch)
+		c.Assert(err, jc.ErrorIsNil)
+	}
+	c.Check(fetches, gc.Equals, 2)
+}
+
+func (s *lookupSuite) TestLookupDoesNotBlockOtherLookups(c *gc.C) {
+	var cache lookupCache
+	fetching := make(chan struct{})
+	unblock := make(chan struct{})
+	done := make(chan error, 1)
+	go func() {
+		_, err := cache.lookup(lookupSpacesByName, "key", func() (map[string]interface{}, error) {
+			close(fetching)
+			<-unblock
+			return map[string]interface{}{"key": "value"}, nil
+		})
+		done <- err
+	}()
+	<-fetching
+
+	entry, err := cache.lookup(lookupFabricsByName, "key", func() (map[string]interface{}, error) {
+		return map[string]interface{}{"key": "fabric"}, nil
+	})
+	c.Assert(err, jc.ErrorIsNil)
+	c.Check(entry, gc.Equals, "fabric")
+
+	// Listings fetched before the cache is cleared aren't kept.
+	cache.clear()
+	close(unblock)
+	c.Assert(<-done, jc.ErrorIsNil)
+	c.Check(cache.indexes[lookupSpacesByName], gc.IsNil)
+}
@@ -7,6 +7,9 @@ import (
 	"fmt"
 	"net/http"
 	"net/url"
+	"strings"
+	"sync"
+	"time"
 
 	"github.com/juju/errors"
 	"github.com/juju/schema"
@@ -16,9 +19,13 @@ import (
 type machine struct {
 	controller *controller
 
+	// resourceURI and systemID identify the machine, so they never change.
 	resourceURI string
+	systemID    string
 
-	systemID  string
+	// mu guards the fields below, which are replaced when the machine is
+	// updated, such as by Start, while other goroutines may be reading it.
+	mu        sync.RWMutex
 	hostname  string
 	fqdn      string
 	tags      []string
@@ -26,48 +33,120 @@ type machine struct {
 
 	operatingSystem string
 	distroSeries    string
+	hweKernel       string
 	architecture    string
 	memory          int
 	cpuCount        int
 	hardwareInfo    map[string]string
 
-	ipAddresses []string
-	powerState  string
+	ipAddresses     []string
+	powerState      string
+	powerType       string
+	biosBootMethod  string
+	netboot         bool
+	ephemeralDeploy bool
+	hardwareSync    bool
+	dpu             bool
 
 	// NOTE: consider some form of status struct
 	statusName    string
 	statusMessage string
 
-	bootInterface *interface_
-	interfaceSet  []*interface_
-	zone          *zone
-	pool          *pool
+	// updated is kept as returned by MAAS, and parsed in the time location
+	// of the controller when requested.
+	updated string
+
+	zone *zone
+	pool *pool
 	// Don't really know the difference between these two lists:
 	physicalBlockDevices []*blockdevice
 	blockDevices         []*blockdevice
+
+	// The interfaces and virtual block devices aren't updated.
+	bootInterface       *interface_
+	interfaceSet        []*interface_
+	virtualBlockDevices []*blockdevice
+}
+
+// setController sets the controller of the machine and of the entities it
+// includes. It must be called before the machine is shared.
+func (m *machine) setController(c *controller) {
+	m.controller = c
+	if m.zone != nil {
+		m.zone.controller = c
+	}
+	if m.pool != nil {
+		m.pool.controller = c
+	}
+	if m.bootInterface != nil {
+		m.bootInterface.controller = c
+	}
+	for _, iface := range m.interfaceSet {
+		iface.controller = c
+	}
+	for _, devices := range [][]*blockdevice{m.physicalBlockDevices, m.blockDevices, m.virtualBlockDevices} {
+		for _, device := range devices {
+			device.controller = c
+		}
+	}
 }
 
+// updateFrom replaces the state of the machine with the one of other, which
+// is a new read of the same machine.
 func (m *machine) updateFrom(other *machine) {
-	m.resourceURI = other.resourceURI
-	m.systemID = other.systemID
+	if other.zone != nil {
+		other.zone.controller = m.controller
+	}
+	if other.pool != nil {
+		other.pool.controller = m.controller
+	}
+	m.mu.Lock()
+	defer m.mu.Unlock()
 	m.hostname = other.hostname
 	m.fqdn = other.fqdn
 	m.operatingSystem = other.operatingSystem
 	m.distroSeries = other.distroSeries
+	m.hweKernel = other.hweKernel
 	m.architecture = other.architecture
 	m.memory = other.memory
 	m.cpuCount = other.cpuCount
 	m.hardwareInfo = other.hardwareInfo
 	m.ipAddresses = other.ipAddresses
 	m.powerState = other.powerState
+	m.powerType = other.powerType
+	m.biosBootMethod = other.biosBootMethod
+	m.netboot = other.netboot
+	m.ephemeralDeploy = other.ephemeralDeploy
+	m.hardwareSync = other.hardwareSync
+	m.dpu = other.dpu
 	m.statusName = other.statusName
 	m.statusMessage = other.statusMessage
+	m.updated = other.updated
 	m.zone = other.zone
 	m.pool = other.pool
 	m.tags = other.tags
 	m.ownerData = other.ownerData
 }
 
+// Updated implements Machine.
+func (m *machine) Updated() time.Time {
+	m.mu.RLock()
+	defer m.mu.RUnlock()
+	if m.updated == "" {
+		return time.Time{}
+	}
+	var location *time.Location
+	if m.controller != nil {
+		location = m.controller.timeLocation
+	}
+	updated, err := parseTimestamp(m.updated, location)
+	if err != nil {
+		logger.Debugf("machine %s: %v", m.systemID, err)
+		return time.Time{}
+	}
+	return updated
+}
+
 // SystemID implements Machine.
 func (m *machine) SystemID() string {
 	return m.systemID
@@ -75,21 +154,29 @@ func (m *machine) SystemID() string {
 
 // Hostname implements Machine.
 func (m *machine) Hostname() string {
+	m.mu.RLock()
+	defer m.mu.RUnlock()
 	return m.hostname
 }
 
 // FQDN implements Machine.
 func (m *machine) FQDN() string {
+	m.mu.RLock()
+	defer m.mu.RUnlock()
 	return m.fqdn
 }
 
 // Tags implements Machine.
 func (m *machine) Tags() []string {
+	m.mu.RLock()
+	defer m.mu.RUnlock()
 	return m.tags
 }
 
 // Pool implements Machine
 func (m *machine) Pool() Pool {
+	m.mu.RLock()
+	defer m.mu.RUnlock()
 	if m.pool == nil {
 		return nil
 	}
@@ -98,21 +185,29 @@ func (m *machine) Pool() Pool {
 
 // IPAddresses implements Machine.
 func (m *machine) IPAddresses() []string {
+	m.mu.RLock()
+	defer m.mu.RUnlock()
 	return m.ipAddresses
 }
 
 // Memory implements Machine.
 func (m *machine) Memory() int {
+	m.mu.RLock()
+	defer m.mu.RUnlock()
 	return m.memory
 }
 
 // CPUCount implements Machine.
 func (m *machine) CPUCount() int {
+	m.mu.RLock()
+	defer m.mu.RUnlock()
 	return m.cpuCount
 }
 
 // HardwareInfo implements Machine.
 func (m *machine) HardwareInfo() map[string]string {
+	m.mu.RLock()
+	defer m.mu.RUnlock()
 	if m.hardwareInfo == nil {
 		return nil
 	}
@@ -126,11 +221,50 @@ func (m *machine) HardwareInfo() map[string]string {
 
 // PowerState implements Machine.
 func (m *machine) PowerState() string {
+	m.mu.RLock()
+	defer m.mu.RUnlock()
 	return m.powerState
 }
 
+// PowerType implements Machine.
+func (m *machine) PowerType() string {
+	m.mu.RLock()
+	defer m.mu.RUnlock()
+	return m.powerType
+}
+
+// PowerParameters implements Machine.
+func (m *machine) PowerParameters() (map[string]interface{}, error) {
+	source, err := m.controller.getOp(m.resourceURI, "power_parameters")
+	if err != nil {
+		if svrErr, ok := errors.Cause(err).(ServerError); ok {
+			switch svrErr.StatusCode {
+			case http.StatusNotFound:
+				return nil, wrapError(err, NewNoMatchError(svrErr.BodyMessage))
+			case http.StatusForbidden:
+				return nil, wrapError(err, NewPermissionError(svrErr.BodyMessage))
+			}
+		}
+		return nil, NewUnexpectedError(err)
+	}
+	params, ok := source.(map[string]interface{})
+	if !ok {
+		return nil, NewDeserializationError("unexpected power parameters %T", source)
+	}
+	return params, nil
+}
+
+// BIOSBootMethod implements Machine.
+func (m *machine) BIOSBootMethod() string {
+	m.mu.RLock()
+	defer m.mu.RUnlock()
+	return m.biosBootMethod
+}
+
 // Zone implements Machine.
 func (m *machine) Zone() Zone {
+	m.mu.RLock()
+	defer m.mu.RUnlock()
 	if m.zone == nil {
 		return nil
 	}
@@ -142,7 +276,6 @@ func (m *machine) BootInterface() Interface {
 	if m.bootInterface == nil {
 		return nil
 	}
-	m.bootInterface.controller = m.controller
 	return m.bootInterface
 }
 
@@ -150,7 +283,6 @@ func (m *machine) BootInterface() Interface {
 func (m *machine) InterfaceSet() []Interface {
 	result := make([]Interface, len(m.interfaceSet))
 	for i, v := range m.interfaceSet {
-		v.controller = m.controller
 		result[i] = v
 	}
 	return result
@@ -160,40 +292,269 @@ func (m *machine) InterfaceSet() []Interface {
 func (m *machine) Interface(id int) Interface {
 	for _, iface := range m.interfaceSet {
 		if iface.ID() == id {
-			iface.controller = m.controller
 			return iface
 		}
 	}
 	return nil
 }
 
+// InterfaceByName implements Machine.
+func (m *machine) InterfaceByName(name string) Interface {
+	for _, iface := range m.interfaceSet {
+		if iface.Name() == name {
+			return iface
+		}
+	}
+	return nil
+}
+
+// InterfaceParents implements Machine.
+func (m *machine) InterfaceParents(iface Interface) []Interface {
+	return m.interfacesByName(iface.Parents())
+}
+
+// InterfaceChildren implements Machine.
+func (m *machine) InterfaceChildren(iface Interface) []Interface {
+	return m.interfacesByName(iface.Children())
+}
+
+func (m *machine) interfacesByName(names []string) []Interface {
+	var result []Interface
+	for _, name := range names {
+		if iface := m.InterfaceByName(name); iface != nil {
+			result = append(result, iface)
+		}
+	}
+	return result
+}
+
+// WalkInterfaces implements Machine.
+func (m *machine) WalkInterfaces(walkFn func(iface Interface, depth int) error) error {
+	// An interface with several parents, like a bond, is reached once
+	// through each of them, but only visited the first time.
+	visited := make(map[int]bool)
+	var walk func(iface Interface, depth int) error
+	walk = func(iface Interface, depth int) error {
+		if visited[iface.ID()] {
+			return nil
+		}
+		visited[iface.ID()] = true
+		if err := walkFn(iface, depth); err != nil {
+			return err
+		}
+		for _, child := range m.InterfaceChildren(iface) {
+			if err := walk(child, depth+1); err != nil {
+				return err
+			}
+		}
+		return nil
+	}
+	for _, iface := range m.InterfaceSet() {
+		if len(m.InterfaceParents(iface)) != 0 {
+			continue
+		}
+		if err := walk(iface, 0); err != nil {
+			return errors.Trace(err)
+		}
+	}
+	return nil
+}
+
+// SetDefaultGateway implements Machine.
+func (m *machine) SetDefaultGateway(interfaceID, linkID int) error {
+	var iface *interface_
+	for _, candidate := range m.interfaceSet {
+		if candidate.ID() == interfaceID {
+			iface = candidate
+			break
+		}
+	}
+	if iface == nil {
+		return errors.NotValidf("interface %d", interfaceID)
+	}
+	if linkID != 0 && !iface.hasLink(linkID) {
+		return errors.NotValidf("link %d on interface %q", linkID, iface.Name())
+	}
+	params := NewURLParams()
+	params.MaybeAddInt("link_id", linkID)
+	source, err := m.controller.post(iface.resourceURI, "set_default_gateway", params.Values)
+	if err != nil {
+		if svrErr, ok := errors.Cause(err).(ServerError); ok {
+			switch svrErr.StatusCode {
+			case http.StatusNotFound, http.StatusBadRequest:
+				return wrapError(err, NewBadRequestError(svrErr.BodyMessage))
+			case http.StatusForbidden:
+				return wrapError(err, NewPermissionError(svrErr.BodyMessage))
+			}
+		}
+		return NewUnexpectedError(err)
+	}
+
+	response, err := readInterface(m.controller.schemaVersion(), source)
+	if err != nil {
+		return errors.Trace(err)
+	}
+	iface.updateFrom(response)
+	return nil
+}
+
+// PXEDHCPProvider implements Machine.
+func (m *machine) PXEDHCPProvider() DHCPProvider {
+	return VLANDHCPProvider(m.pxeVLAN())
+}
+
+// pxeVLAN returns the VLAN of the boot interface. If the boot interface has no
+// VLAN set, the VLAN of the subnets it is linked to is used instead.
+func (m *machine) pxeVLAN() VLAN {
+	if m.bootInterface == nil {
+		return nil
+	}
+	if vlan := m.bootInterface.VLAN(); vlan != nil {
+		return vlan
+	}
+	for _, link := range m.bootInterface.links {
+		if subnet := link.Subnet(); subnet != nil && subnet.VLAN() != nil {
+			return subnet.VLAN()
+		}
+	}
+	return nil
+}
+
 // OperatingSystem implements Machine.
 func (m *machine) OperatingSystem() string {
+	m.mu.RLock()
+	defer m.mu.RUnlock()
 	return m.operatingSystem
 }
 
 // DistroSeries implements Machine.
 func (m *machine) DistroSeries() string {
+	m.mu.RLock()
+	defer m.mu.RUnlock()
 	return m.distroSeries
 }
 
 // Architecture implements Machine.
 func (m *machine) Architecture() string {
+	m.mu.RLock()
+	defer m.mu.RUnlock()
 	return m.architecture
 }
 
+// DeployedImage describes the operating system image and kernel a machine
+// was deployed with.
+type DeployedImage struct {
+	OperatingSystem string
+	DistroSeries    string
+	// Kernel is the hardware enablement kernel in use, such as "ga-22.04"
+	// or "hwe-22.04". MAAS may leave it empty for images that don't use
+	// a separate kernel.
+	Kernel string
+	// Architecture includes the sub-architecture, such as "amd64/generic".
+	Architecture string
+}
+
+// DeployedImage implements Machine.
+func (m *machine) DeployedImage() (DeployedImage, bool) {
+	m.mu.RLock()
+	defer m.mu.RUnlock()
+	// MAAS keeps default values for osystem and distro_series on machines
+	// that aren't deployed, so they only describe the image in use once
+	// deployment has completed.
+	if m.statusName != "Deployed" {
+		return DeployedImage{}, false
+	}
+	return DeployedImage{
+		OperatingSystem: m.operatingSystem,
+		DistroSeries:    m.distroSeries,
+		Kernel:          m.hweKernel,
+		Architecture:    m.architecture,
+	}, true
+}
+
+// Netboot implements Machine.
+func (m *machine) Netboot() bool {
+	m.mu.RLock()
+	defer m.mu.RUnlock()
+	return m.netboot
+}
+
+// EphemeralDeploy implements Machine.
+func (m *machine) EphemeralDeploy() bool {
+	m.mu.RLock()
+	defer m.mu.RUnlock()
+	return m.ephemeralDeploy
+}
+
+// HardwareSync implements Machine.
+func (m *machine) HardwareSync() bool {
+	m.mu.RLock()
+	defer m.mu.RUnlock()
+	return m.hardwareSync
+}
+
+// DPU implements Machine.
+func (m *machine) DPU() bool {
+	m.mu.RLock()
+	defer m.mu.RUnlock()
+	return m.dpu
+}
+
+// Diskless implements Machine.
+func (m *machine) Diskless() bool {
+	m.mu.RLock()
+	defer m.mu.RUnlock()
+	if m.statusName != "Deployed" {
+		return false
+	}
+	// MAAS deploys machines without disks to memory, even if an ephemeral
+	// deployment wasn't requested.
+	return m.ephemeralDeploy || len(m.physicalBlockDevices) == 0
+}
+
+// SetNetboot implements Machine.
+func (m *machine) SetNetboot(enabled bool) error {
+	params := make(url.Values)
+	params.Add("netboot", fmt.Sprint(enabled))
+	result, err := m.controller.put(m.resourceURI, params)
+	if err != nil {
+		if svrErr, ok := errors.Cause(err).(ServerError); ok {
+			switch svrErr.StatusCode {
+			case http.StatusNotFound, http.StatusConflict, http.StatusBadRequest:
+				return wrapError(err, NewBadRequestError(svrErr.BodyMessage))
+			case http.StatusForbidden:
+				return wrapError(err, NewPermissionError(svrErr.BodyMessage))
+			}
+		}
+		return NewUnexpectedError(err)
+	}
+
+	machine, err := readMachine(m.controller.schemaVersion(), result)
+	if err != nil {
+		return errors.Trace(err)
+	}
+	m.updateFrom(machine)
+	return nil
+}
+
 // StatusName implements Machine.
 func (m *machine) StatusName() string {
+	m.mu.RLock()
+	defer m.mu.RUnlock()
 	return m.statusName
 }
 
 // StatusMessage implements Machine.
 func (m *machine) StatusMessage() string {
+	m.mu.RLock()
+	defer m.mu.RUnlock()
 	return m.statusMessage
 }
 
 // PhysicalBlockDevices implements Machine.
 func (m *machine) PhysicalBlockDevices() []BlockDevice {
+	m.mu.RLock()
+	defer m.mu.RUnlock()
 	result := make([]BlockDevice, len(m.physicalBlockDevices))
 	for i, v := range m.physicalBlockDevices {
 		result[i] = v
@@ -208,6 +569,8 @@ func (m *machine) PhysicalBlockDevice(id int) BlockDevice {
 
 // BlockDevices implements Machine.
 func (m *machine) BlockDevices() []BlockDevice {
+	m.mu.RLock()
+	defer m.mu.RUnlock()
 	result := make([]BlockDevice, len(m.blockDevices))
 	for i, v := range m.blockDevices {
 		result[i] = v
@@ -215,6 +578,15 @@ func (m *machine) BlockDevices() []BlockDevice {
 	return result
 }
 
+// VirtualBlockDevices implements Machine.
+func (m *machine) VirtualBlockDevices() []BlockDevice {
+	result := make([]BlockDevice, len(m.virtualBlockDevices))
+	for i, v := range m.virtualBlockDevices {
+		result[i] = v
+	}
+	return result
+}
+
 // BlockDevice implements Machine.
 func (m *machine) BlockDevice(id int) BlockDevice {
 	return blockDeviceById(id, m.BlockDevices())
@@ -245,6 +617,272 @@ func partitionById(id int, blockDevices []BlockDevice) Partition {
 	return nil
 }
 
+// CreateBlockDeviceArgs is an argument struct for Machine.CreateBlockDevice.
+// The Name, Size and BlockSize are required, and the device must be
+// identified either by IDPath, or by Model and Serial.
+type CreateBlockDeviceArgs struct {
+	Name      string
+	Model     string
+	Serial    string
+	IDPath    string
+	Size      uint64
+	BlockSize uint64
+}
+
+// Validate ensures that the required fields are set and that the device can
+// be identified.
+func (a *CreateBlockDeviceArgs) Validate() error {
+	if a.Name == "" {
+		return errors.NotValidf("missing Name")
+	}
+	if a.Size == 0 {
+		return errors.NotValidf("missing Size")
+	}
+	if a.BlockSize == 0 {
+		return errors.NotValidf("missing BlockSize")
+	}
+	if a.IDPath == "" && (a.Model == "" || a.Serial == "") {
+		return errors.NotValidf("missing IDPath, or Model and Serial")
+	}
+	return nil
+}
+
+// CreateBlockDevice implements Machine.
+func (m *machine) CreateBlockDevice(args CreateBlockDeviceArgs) (BlockDevice, error) {
+	if err := args.Validate(); err != nil {
+		return nil, errors.Trace(err)
+	}
+	params := NewURLParams()
+	params.Values.Add("name", args.Name)
+	params.MaybeAdd("model", args.Model)
+	params.MaybeAdd("serial", args.Serial)
+	params.MaybeAdd("id_path", args.IDPath)
+	params.Values.Add("size", fmt.Sprint(args.Size))
+	params.Values.Add("block_size", fmt.Sprint(args.BlockSize))
+	source, err := m.controller.post(m.storagePath("blockdevices"), "", params.Values)
+	if err != nil {
+		return nil, errors.Trace(storageDeviceError(err))
+	}
+
+	blockDevice, err := readBlockDevice(m.controller.schemaVersion(), source)
+	if err != nil {
+		return nil, errors.Trace(err)
+	}
+	blockDevice.controller = m.controller
+	m.mu.Lock()
+	m.physicalBlockDevices = append(m.physicalBlockDevices, blockDevice)
+	m.blockDevices = append(m.blockDevices, blockDevice)
+	m.mu.Unlock()
+	return blockDevice, nil
+}
+
+// RAIDs implements Machine.
+func (m *machine) RAIDs() ([]RAID, error) {
+	source, err := m.controller.get(m.storagePath("raids"))
+	if err != nil {
+		return nil, NewUnexpectedError(err)
+	}
+	raids, err := readRAIDs(m.controller.schemaVersion(), source)
+	if err != nil {
+		return nil, errors.Trace(err)
+	}
+	var result []RAID
+	for _, raid := range raids {
+		raid.controller = m.controller
+		result = append(result, raid)
+	}
+	return result, nil
+}
+
+// CreateRAIDArgs is an argument struct for Machine.CreateRAID. The Level and
+// at least one block device or partition are required. MAAS generates the
+// Name and UUID if they aren't specified.
+type CreateRAIDArgs struct {
+	Name  string
+	UUID  string
+	Level RAIDLevel
+
+	Devices         []BlockDevice
+	Partitions      []Partition
+	SpareDevices    []BlockDevice
+	SparePartitions []Partition
+}
+
+// Validate ensures that the Level is known and that there is something to
+// build the array from.
+func (a *CreateRAIDArgs) Validate() error {
+	switch a.Level {
+	case RAID0, RAID1, RAID5, RAID6, RAID10:
+	case "":
+		return errors.NotValidf("missing Level")
+	default:
+		return errors.NotValidf("Level %q", a.Level)
+	}
+	if len(a.Devices) == 0 && len(a.Partitions) == 0 {
+		return errors.NotValidf("missing Devices and Partitions")
+	}
+	return nil
+}
+
+// CreateRAID implements Machine.
+func (m *machine) CreateRAID(args CreateRAIDArgs) (RAID, error) {
+	if err := args.Validate(); err != nil {
+		return nil, errors.Trace(err)
+	}
+	params := NewURLParams()
+	params.MaybeAdd("name", args.Name)
+	params.MaybeAdd("uuid", args.UUID)
+	params.Values.Add("level", string(args.Level))
+	params.MaybeAddMany("block_devices", blockDeviceIDs(args.Devices))
+	params.MaybeAddMany("partitions", partitionIDs(args.Partitions))
+	params.MaybeAddMany("spare_devices", blockDeviceIDs(args.SpareDevices))
+	params.MaybeAddMany("spare_partitions", partitionIDs(args.SparePartitions))
+	source, err := m.controller.post(m.storagePath("raids"), "", params.Values)
+	if err != nil {
+		return nil, errors.Trace(storageDeviceError(err))
+	}
+
+	raid, err := readRAID(m.controller.schemaVersion(), source)
+	if err != nil {
+		return nil, errors.Trace(err)
+	}
+	raid.controller = m.controller
+	return raid, nil
+}
+
+// VolumeGroups implements Machine.
+func (m *machine) VolumeGroups() ([]VolumeGroup, error) {
+	source, err := m.controller.get(m.storagePath("volume-groups"))
+	if err != nil {
+		return nil, NewUnexpectedError(err)
+	}
+	volumeGroups, err := readVolumeGroups(m.controller.schemaVersion(), source)
+	if err != nil {
+		return nil, errors.Trace(err)
+	}
+	var result []VolumeGroup
+	for _, volumeGroup := range volumeGroups {
+		volumeGroup.controller = m.controller
+		result = append(result, volumeGroup)
+	}
+	return result, nil
+}
+
+// CreateVolumeGroupArgs is an argument struct for Machine.CreateVolumeGroup.
+// The Name and at least one block device or partition are required.
+type CreateVolumeGroupArgs struct {
+	Name string
+	UUID string
+
+	Devices    []BlockDevice
+	Partitions []Partition
+}
+
+// Validate ensures that the Name is set and that there is something to build
+// the volume group from.
+func (a *CreateVolumeGroupArgs) Validate() error {
+	if a.Name == "" {
+		return errors.NotValidf("missing Name")
+	}
+	if len(a.Devices) == 0 && len(a.Partitions) == 0 {
+		return errors.NotValidf("missing Devices and Partitions")
+	}
+	return nil
+}
+
+// CreateVolumeGroup implements Machine.
+func (m *machine) CreateVolumeGroup(args CreateVolumeGroupArgs) (VolumeGroup, error) {
+	if err := args.Validate(); err != nil {
+		return nil, errors.Trace(err)
+	}
+	params := NewURLParams()
+	params.Values.Add("name", args.Name)
+	params.MaybeAdd("uuid", args.UUID)
+	params.MaybeAddMany("block_devices", blockDeviceIDs(args.Devices))
+	params.MaybeAddMany("partitions", partitionIDs(args.Partitions))
+	source, err := m.controller.post(m.storagePath("volume-groups"), "", params.Values)
+	if err != nil {
+		return nil, errors.Trace(storageDeviceError(err))
+	}
+
+	volumeGroup, err := readVolumeGroup(m.controller.schemaVersion(), source)
+	if err != nil {
+		return nil, errors.Trace(err)
+	}
+	volumeGroup.controller = m.controller
+	return volumeGroup, nil
+}
+
+// VMFSDatastores implements Machine.
+func (m *machine) VMFSDatastores() ([]VMFSDatastore, error) {
+	source, err := m.controller.get(m.storagePath("vmfs-datastores"))
+	if err != nil {
+		return nil, NewUnexpectedError(err)
+	}
+	datastores, err := readVMFSDatastores(m.controller.schemaVersion(), source)
+	if err != nil {
+		return nil, errors.Trace(err)
+	}
+	var result []VMFSDatastore
+	for _, datastore := range datastores {
+		datastore.controller = m.controller
+		result = append(result, datastore)
+	}
+	return result, nil
+}
+
+// CreateVMFSDatastoreArgs is an argument struct for
+// Machine.CreateVMFSDatastore. The Name and at least one block device or
+// partition are required.
+type CreateVMFSDatastoreArgs struct {
+	Name string
+	UUID string
+
+	Devices    []BlockDevice
+	Partitions []Partition
+}
+
+// Validate ensures that the Name is set and that there is something to build
+// the datastore from.
+func (a *CreateVMFSDatastoreArgs) Validate() error {
+	if a.Name == "" {
+		return errors.NotValidf("missing Name")
+	}
+	if len(a.Devices) == 0 && len(a.Partitions) == 0 {
+		return errors.NotValidf("missing Devices and Partitions")
+	}
+	return nil
+}
+
+// CreateVMFSDatastore implements Machine.
+func (m *machine) CreateVMFSDatastore(args CreateVMFSDatastoreArgs) (VMFSDatastore, error) {
+	if err := args.Validate(); err != nil {
+		return nil, errors.Trace(err)
+	}
+	params := NewURLParams()
+	params.Values.Add("name", args.Name)
+	params.MaybeAdd("uuid", args.UUID)
+	params.MaybeAddMany("block_devices", blockDeviceIDs(args.Devices))
+	params.MaybeAddMany("partitions", partitionIDs(args.Partitions))
+	source, err := m.controller.post(m.storagePath("vmfs-datastores"), "", params.Values)
+	if err != nil {
+		return nil, errors.Trace(storageDeviceError(err))
+	}
+
+	datastore, err := readVMFSDatastore(m.controller.schemaVersion(), source)
+	if err != nil {
+		return nil, errors.Trace(err)
+	}
+	datastore.controller = m.controller
+	return datastore, nil
+}
+
+// storagePath returns the path of a storage collection of the machine. The
+// storage collections are under the nodes endpoint rather than machines.
+func (m *machine) storagePath(collection string) string {
+	return fmt.Sprintf("nodes/%s/%s/", m.systemID, collection)
+}
+
 // Devices implements Machine.
 func (m *machine) Devices(args DevicesArgs) ([]Device, error) {
 	// Perhaps in the future, MAAS will give us a way to query just for the
@@ -266,35 +904,184 @@ func (m *machine) Devices(args DevicesArgs) ([]Device, error) {
 // method.
 type StartArgs struct {
 	// UserData needs to be Base64 encoded user data for cloud-init.
-	UserData     string
+	UserData string
+	// DistroSeries is passed through to MAAS as is. MAAS accepts either a
+	// bare release ("jammy") or one qualified by the operating system
+	// ("ubuntu/jammy"). Prefer OSystem and Release, DistroSeries is kept
+	// for compatibility.
 	DistroSeries string
-	Kernel       string
-	Comment      string
+	// OSystem is the operating system to deploy, e.g. "ubuntu" or "centos".
+	// If Release is not specified, MAAS uses the default release for the
+	// operating system.
+	OSystem string
+	// Release is the release of the operating system to deploy, e.g.
+	// "jammy". It must not be qualified by the operating system.
+	Release string
+	Kernel  string
+	Comment string
+	// EphemeralDeploy deploys the operating system to memory rather than
+	// to disk. The machine then boots from the network every time.
+	EphemeralDeploy bool
+}
+
+// Validate ensures that DistroSeries is not mixed with Release, and that the
+// operating system is not specified in more than one way.
+func (a *StartArgs) Validate() error {
+	if a.DistroSeries != "" && a.Release != "" {
+		return errors.NotValidf("specifying both DistroSeries and Release")
+	}
+	if strings.Contains(a.Release, "/") {
+		return errors.NotValidf("Release %q qualified by operating system", a.Release)
+	}
+	if strings.Contains(a.OSystem, "/") {
+		return errors.NotValidf("OSystem %q", a.OSystem)
+	}
+	if a.OSystem != "" {
+		if osystem, _, ok := splitDistroSeries(a.DistroSeries); ok && osystem != a.OSystem {
+			return errors.NotValidf("DistroSeries %q with OSystem %q", a.DistroSeries, a.OSystem)
+		}
+	}
+	return nil
+}
+
+func (a *StartArgs) distroSeries() string {
+	if a.Release != "" {
+		return a.Release
+	}
+	return a.DistroSeries
+}
+
+// splitDistroSeries splits an operating system qualified distro series, such
+// as "ubuntu/jammy", into its parts. The bool return value is false if the
+// series isn't qualified.
+func splitDistroSeries(series string) (string, string, bool) {
+	parts := strings.SplitN(series, "/", 2)
+	if len(parts) != 2 {
+		return "", series, false
+	}
+	return parts[0], parts[1], true
 }
 
 // Start implements Machine.
 func (m *machine) Start(args StartArgs) error {
+	if err := args.Validate(); err != nil {
+		return errors.Trace(err)
+	}
 	params := NewURLParams()
 	params.MaybeAdd("user_data", args.UserData)
-	params.MaybeAdd("distro_series", args.DistroSeries)
+	params.MaybeAdd("osystem", args.OSystem)
+	params.MaybeAdd("distro_series", args.distroSeries())
 	params.MaybeAdd("hwe_kernel", args.Kernel)
 	params.MaybeAdd("comment", args.Comment)
+	params.MaybeAddBool("ephemeral_deploy", args.EphemeralDeploy)
 	result, err := m.controller.post(m.resourceURI, "deploy", params.Values)
 	if err != nil {
 		if svrErr, ok := errors.Cause(err).(ServerError); ok {
 			switch svrErr.StatusCode {
 			case http.StatusNotFound, http.StatusConflict:
-				return errors.Wrap(err, NewBadRequestError(svrErr.BodyMessage))
+				return wrapError(err, NewBadRequestError(svrErr.BodyMessage))
+			case http.StatusForbidden:
+				return wrapError(err, NewPermissionError(svrErr.BodyMessage))
+			case http.StatusServiceUnavailable:
+				return wrapError(err, NewCannotCompleteError(svrErr.BodyMessage))
+			}
+		}
+		return NewUnexpectedError(err)
+	}
+
+	machine, err := readMachine(m.controller.schemaVersion(), result)
+	if err != nil {
+		return errors.Trace(err)
+	}
+	m.updateFrom(machine)
+	return nil
+}
+
+// RecoveryAction is an operation performed on a machine to move it out of
+// a failed state.
+type RecoveryAction string
+
+const (
+	// RecoveryActionCommission re-commissions the machine.
+	RecoveryActionCommission RecoveryAction = "commission"
+
+	// RecoveryActionRelease releases the machine again, which returns it to
+	// Ready once the release completes.
+	RecoveryActionRelease RecoveryAction = "release"
+
+	// RecoveryActionMarkFixed marks a broken machine as fixed.
+	RecoveryActionMarkFixed RecoveryAction = "mark_fixed"
+
+	// RecoveryActionExitRescueMode takes the machine out of rescue mode
+	// again, which returns it to the status it had before entering it.
+	RecoveryActionExitRescueMode RecoveryAction = "exit_rescue_mode"
+)
+
+// recoveryPlans maps the failed statuses to the actions that move a machine
+// out of them. MAAS only aborts operations in progress, such as
+// commissioning or deploying, and not failed ones, so abort is never
+// planned.
+var recoveryPlans = map[string][]RecoveryAction{
+	"Failed commissioning":        {RecoveryActionCommission},
+	"Failed testing":              {RecoveryActionCommission},
+	"Failed deployment":           {RecoveryActionRelease},
+	"Failed releasing":            {RecoveryActionRelease},
+	"Failed disk erasing":         {RecoveryActionRelease},
+	"Failed entering rescue mode": {RecoveryActionExitRescueMode},
+	"Failed exiting rescue mode":  {RecoveryActionExitRescueMode},
+	"Broken":                      {RecoveryActionMarkFixed, RecoveryActionCommission},
+}
+
+// RecoverFromFailureArgs is an argument struct for passing parameters to the
+// Machine.RecoverFromFailure method.
+type RecoverFromFailureArgs struct {
+	// DryRun, if true, returns the plan without performing any actions.
+	DryRun bool
+	// Comment is recorded in the machine's event log for each action.
+	Comment string
+}
+
+// RecoverFromFailure implements Machine.
+func (m *machine) RecoverFromFailure(args RecoverFromFailureArgs) ([]RecoveryAction, error) {
+	statusName := m.StatusName()
+	if !strings.HasPrefix(statusName, "Failed") && statusName != "Broken" {
+		// Nothing to recover from.
+		return nil, nil
+	}
+	plan, ok := recoveryPlans[statusName]
+	if !ok {
+		return nil, errors.NotSupportedf("recovering from status %q", statusName)
+	}
+	if args.DryRun {
+		return plan, nil
+	}
+	for _, action := range plan {
+		if err := m.performRecoveryAction(action, args.Comment); err != nil {
+			return plan, errors.Annotatef(err, "recovery action %q", action)
+		}
+	}
+	return plan, nil
+}
+
+func (m *machine) performRecoveryAction(action RecoveryAction, comment string) error {
+	params := NewURLParams()
+	params.MaybeAdd("comment", comment)
+	result, err := m.controller.post(m.resourceURI, string(action), params.Values)
+	if err != nil {
+		if svrErr, ok := errors.Cause(err).(ServerError); ok {
+			switch svrErr.StatusCode {
+			case http.StatusNotFound, http.StatusConflict, http.StatusBadRequest:
+				return wrapError(err, NewBadRequestError(svrErr.BodyMessage))
 			case http.StatusForbidden:
-				return errors.Wrap(err, NewPermissionError(svrErr.BodyMessage))
+				return wrapError(err, NewPermissionError(svrErr.BodyMessage))
 			case http.StatusServiceUnavailable:
-				return errors.Wrap(err, NewCannotCompleteError(svrErr.BodyMessage))
+				return wrapError(err, NewCannotCompleteError(svrErr.BodyMessage))
 			}
 		}
 		return NewUnexpectedError(err)
 	}
 
-	machine, err := readMachine(m.controller.apiVersion, result)
+	machine, err := readMachine(m.controller.schemaVersion(), result)
 	if err != nil {
 		return errors.Trace(err)
 	}
@@ -423,6 +1210,8 @@ func (m *machine) linkDeviceInterfaceToSubnet(iface Interface, subnetToUse Subne
 
 // OwnerData implements OwnerDataHolder.
 func (m *machine) OwnerData() map[string]string {
+	m.mu.RLock()
+	defer m.mu.RUnlock()
 	result := make(map[string]string)
 	for key, value := range m.ownerData {
 		result[key] = value
@@ -440,7 +1229,7 @@ func (m *machine) SetOwnerData(ownerData map[string]string) error {
 	if err != nil {
 		return errors.Trace(err)
 	}
-	machine, err := readMachine(m.controller.apiVersion, result)
+	machine, err := readMachine(m.controller.schemaVersion(), result)
 	if err != nil {
 		return errors.Trace(err)
 	}
@@ -494,23 +1283,68 @@ func getMachineDeserializationFunc(controllerVersion version.Number) (machineDes
 func readMachineList(sourceList []interface{}, readFunc machineDeserializationFunc) ([]*machine, error) {
 	result := make([]*machine, 0, len(sourceList))
 	for i, value := range sourceList {
-		source, ok := value.(map[string]interface{})
-		if !ok {
-			return nil, NewDeserializationError("unexpected value for machine %d, %T", i, value)
-		}
-		machine, err := readFunc(source)
+		machine, _, err := readMachineListItem(i, value, readFunc)
 		if err != nil {
-			return nil, errors.Annotatef(err, "machine %d", i)
+			return nil, errors.Trace(err)
 		}
 		result = append(result, machine)
 	}
 	return result, nil
 }
 
+// readMachineListItem reads the machine at index i of a listing. It also
+// returns the ID of the item for reporting failures, which is the system ID
+// if the item has one.
+func readMachineListItem(i int, value interface{}, readFunc machineDeserializationFunc) (*machine, string, error) {
+	id := fmt.Sprintf("machine %d", i)
+	source, ok := value.(map[string]interface{})
+	if !ok {
+		return nil, id, NewDeserializationError("unexpected value for machine %d, %T", i, value)
+	}
+	if systemID, ok := source["system_id"].(string); ok && systemID != "" {
+		id = systemID
+	}
+	machine, err := readFunc(source)
+	if err != nil {
+		return nil, id, errors.Annotatef(err, "machine %d", i)
+	}
+	return machine, id, nil
+}
+
+// readMachinesSkipInvalid reads the machines from the source like
+// readMachines, but machines that fail to deserialize are left out of the
+// result and their errors returned keyed by system ID.
+func readMachinesSkipInvalid(controllerVersion version.Number, source interface{}) ([]*machine, map[string]error, error) {
+	readFunc, err := getMachineDeserializationFunc(controllerVersion)
+	if err != nil {
+		return nil, nil, errors.Trace(err)
+	}
+
+	checker := schema.List(schema.Any())
+	coerced, err := checker.Coerce(source, nil)
+	if err != nil {
+		return nil, nil, WrapWithDeserializationError(err, "machine base schema check failed")
+	}
+	valid := coerced.([]interface{})
+	result := make([]*machine, 0, len(valid))
+	failures := make(map[string]error)
+	for i, value := range valid {
+		machine, id, err := readMachineListItem(i, value, readFunc)
+		if err != nil {
+			logger.Debugf("skipping machine %s: %v", id, err)
+			failures[id] = err
+			continue
+		}
+		result = append(result, machine)
+	}
+	return result, failures, nil
+}
+
 type machineDeserializationFunc func(map[string]interface{}) (*machine, error)
 
 var machineDeserializationFuncs = map[version.Number]machineDeserializationFunc{
-	twoDotOh: machine_2_0,
+	twoDotOh:    machine_2_0,
+	threeDotTwo: machine_3_2,
 }
 
 func machine_2_0(source map[string]interface{}) (*machine, error) {
@@ -525,15 +1359,24 @@ func machine_2_0(source map[string]interface{}) (*machine, error) {
 
 		"osystem":       schema.String(),
 		"distro_series": schema.String(),
+		"hwe_kernel":    schema.OneOf(schema.Nil(""), schema.String()),
 		"architecture":  schema.OneOf(schema.Nil(""), schema.String()),
 		"memory":        schema.ForceInt(),
 		"cpu_count":     schema.ForceInt(),
 		"hardware_info": schema.OneOf(schema.Nil(""), schema.StringMap(schema.String())),
 
-		"ip_addresses":   schema.List(schema.String()),
-		"power_state":    schema.String(),
-		"status_name":    schema.String(),
-		"status_message": schema.OneOf(schema.Nil(""), schema.String()),
+		"ip_addresses": schema.List(schema.String()),
+		"power_state":  schema.String(),
+		"power_type":   schema.OneOf(schema.Nil(""), schema.String()),
+		// The boot method is only known once the machine has booted from
+		// the network.
+		"bios_boot_method": schema.OneOf(schema.Nil(""), schema.String()),
+		// Older versions of MAAS don't report netboot or ephemeral_deploy.
+		"netboot":          schema.Bool(),
+		"ephemeral_deploy": schema.Bool(),
+		"status_name":      schema.String(),
+		"status_message":   schema.OneOf(schema.Nil(""), schema.String()),
+		"updated":          schema.OneOf(schema.Nil(""), schema.String()),
 
 		"boot_interface": schema.OneOf(schema.Nil(""), schema.StringMap(schema.Any())),
 		"interface_set":  schema.List(schema.StringMap(schema.Any())),
@@ -542,9 +1385,17 @@ func machine_2_0(source map[string]interface{}) (*machine, error) {
 
 		"physicalblockdevice_set": schema.List(schema.StringMap(schema.Any())),
 		"blockdevice_set":         schema.List(schema.StringMap(schema.Any())),
+		"virtualblockdevice_set":  schema.List(schema.StringMap(schema.Any())),
 	}
 	defaults := schema.Defaults{
-		"architecture": "",
+		"architecture":           "",
+		"hwe_kernel":             nil,
+		"power_type":             "",
+		"bios_boot_method":       nil,
+		"netboot":                true,
+		"ephemeral_deploy":       false,
+		"updated":                nil,
+		"virtualblockdevice_set": []interface{}{},
 	}
 
 	checker := schema.FieldMap(fields, defaults)
@@ -591,6 +1442,11 @@ func machine_2_0(source map[string]interface{}) (*machine, error) {
 		return nil, errors.Trace(err)
 	}
 
+	virtualBlockDevices, err := readBlockDeviceList(valid["virtualblockdevice_set"].([]interface{}), blockdevice_2_0)
+	if err != nil {
+		return nil, errors.Trace(err)
+	}
+
 	var hardwareInfo map[string]string
 	if validHardwareInfo, ok := valid["hardware_info"].(map[string]interface{}); ok {
 		hardwareInfo = make(map[string]string, len(validHardwareInfo))
@@ -603,8 +1459,12 @@ func machine_2_0(source map[string]interface{}) (*machine, error) {
 		}
 	}
 
+	hweKernel, _ := valid["hwe_kernel"].(string)
 	architecture, _ := valid["architecture"].(string)
 	statusMessage, _ := valid["status_message"].(string)
+	powerType, _ := valid["power_type"].(string)
+	biosBootMethod, _ := valid["bios_boot_method"].(string)
+	updated, _ := valid["updated"].(string)
 	result := &machine{
 		resourceURI: valid["resource_uri"].(string),
 
@@ -616,15 +1476,21 @@ func machine_2_0(source map[string]interface{}) (*machine, error) {
 
 		operatingSystem: valid["osystem"].(string),
 		distroSeries:    valid["distro_series"].(string),
+		hweKernel:       hweKernel,
 		architecture:    architecture,
 		memory:          valid["memory"].(int),
 		cpuCount:        valid["cpu_count"].(int),
 		hardwareInfo:    hardwareInfo,
 
-		ipAddresses:   convertToStringSlice(valid["ip_addresses"]),
-		powerState:    valid["power_state"].(string),
-		statusName:    valid["status_name"].(string),
-		statusMessage: statusMessage,
+		ipAddresses:     convertToStringSlice(valid["ip_addresses"]),
+		powerState:      valid["power_state"].(string),
+		powerType:       powerType,
+		biosBootMethod:  biosBootMethod,
+		netboot:         valid["netboot"].(bool),
+		ephemeralDeploy: valid["ephemeral_deploy"].(bool),
+		statusName:      valid["status_name"].(string),
+		statusMessage:   statusMessage,
+		updated:         updated,
 
 		bootInterface:        bootInterface,
 		interfaceSet:         interfaceSet,
@@ -632,11 +1498,38 @@ func machine_2_0(source map[string]interface{}) (*machine, error) {
 		pool:                 pool,
 		physicalBlockDevices: physicalBlockDevices,
 		blockDevices:         blockDevices,
+		virtualBlockDevices:  virtualBlockDevices,
 	}
 
 	return result, nil
 }
 
+// machine_3_2 reads the fields that MAAS 3.2 added to machines, for
+// hardware sync and data processing units, on top of the 2.0 ones.
+func machine_3_2(source map[string]interface{}) (*machine, error) {
+	result, err := machine_2_0(source)
+	if err != nil {
+		return nil, errors.Trace(err)
+	}
+	fields := schema.Fields{
+		"enable_hw_sync": schema.Bool(),
+		"is_dpu":         schema.Bool(),
+	}
+	defaults := schema.Defaults{
+		"enable_hw_sync": false,
+		"is_dpu":         false,
+	}
+	checker := schema.FieldMap(fields, defaults)
+	coerced, err := checker.Coerce(source, nil)
+	if err != nil {
+		return nil, WrapWithDeserializationError(err, "machine 3.2 schema check failed")
+	}
+	valid := coerced.(map[string]interface{})
+	result.hardwareSync = valid["enable_hw_sync"].(bool)
+	result.dpu = valid["is_dpu"].(bool)
+	return result, nil
+}
+
 func convertToStringSlice(field interface{}) []string {
 	if field == nil {
 		return nil
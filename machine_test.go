@@ -6,6 +6,7 @@ package gomaasapi
 import (
 	"fmt"
 	"net/http"
+	"time"
 
 	"github.com/juju/errors"
 	"github.com/juju/testing"
@@ -53,6 +54,9 @@ func (s *machineSuite) TestReadMachines(c *gc.C) {
 	hardwareInfo := machine.HardwareInfo()
 	c.Check(hardwareInfo, gc.NotNil)
 	c.Check(hardwareInfo["chassis_serial"], gc.Equals, "#dabeef")
+
+	// MAAS hasn't seen the other machines network boot.
+	c.Check(machines[1].BIOSBootMethod(), gc.Equals, "")
 }
 
 func (s *machineSuite) TestReadMachinesWithoutHardwareInfo(c *gc.C) {
@@ -81,6 +85,8 @@ func (*machineSuite) checkMachine(c *gc.C, machine Machine) {
 	c.Check(machine.Memory(), gc.Equals, 1024)
 	c.Check(machine.CPUCount(), gc.Equals, 1)
 	c.Check(machine.PowerState(), gc.Equals, "on")
+	c.Check(machine.PowerType(), gc.Equals, "virsh")
+	c.Check(machine.BIOSBootMethod(), gc.Equals, "pxe")
 	c.Check(machine.Zone().Name(), gc.Equals, "default")
 	c.Check(machine.Pool().Name(), gc.Equals, "default")
 	c.Check(machine.OperatingSystem(), gc.Equals, "ubuntu")
@@ -114,6 +120,16 @@ func (*machineSuite) checkMachine(c *gc.C, machine Machine) {
 	c.Assert(machine.PhysicalBlockDevice(id), jc.DeepEquals, blockDevices[0])
 	c.Assert(machine.PhysicalBlockDevice(id+5), gc.IsNil)
 
+	blockDevices = machine.VirtualBlockDevices()
+	c.Assert(blockDevices, gc.HasLen, 1)
+	c.Assert(blockDevices[0].Name(), gc.Equals, "md0")
+	c.Assert(blockDevices[0].Parent(), jc.DeepEquals, &BlockDeviceParent{
+		ID:   2,
+		UUID: "a8ea1e2c-3a8d-4ea6-9c9f-5e8ba1e0b5a4",
+		Type: "raid-0",
+	})
+	c.Assert(machine.PhysicalBlockDevices()[0].Parent(), gc.IsNil)
+
 	pool := machine.Pool()
 	c.Check(pool, gc.NotNil)
 	c.Check(pool.Name(), gc.Equals, "default")
@@ -123,6 +139,7 @@ func (*machineSuite) TestReadMachinesNilValues(c *gc.C) {
 	json := parseJSON(c, machinesResponse)
 	data := json.([]interface{})[0].(map[string]interface{})
 	data["architecture"] = nil
+	data["hwe_kernel"] = nil
 	data["status_message"] = nil
 	data["boot_interface"] = nil
 	data["pool"] = nil
@@ -132,12 +149,211 @@ func (*machineSuite) TestReadMachinesNilValues(c *gc.C) {
 	c.Assert(machines, gc.HasLen, 3)
 	machine := machines[0]
 	c.Check(machine.Architecture(), gc.Equals, "")
+	image, ok := machine.DeployedImage()
+	c.Check(ok, jc.IsTrue)
+	c.Check(image.Kernel, gc.Equals, "")
 	c.Check(machine.StatusMessage(), gc.Equals, "")
 	c.Check(machine.BootInterface(), gc.IsNil)
 	c.Check(machine.Pool(), gc.IsNil)
 	c.Check(machine.HardwareInfo(), gc.IsNil)
 }
 
+func (*machineSuite) TestReadMachinesNetboot(c *gc.C) {
+	machines, err := readMachines(twoDotOh, parseJSON(c, machinesResponse))
+	c.Assert(err, jc.ErrorIsNil)
+	c.Check(machines[0].Netboot(), jc.IsFalse)
+	c.Check(machines[0].EphemeralDeploy(), jc.IsFalse)
+	c.Check(machines[0].Diskless(), jc.IsFalse)
+	// MAAS versions that don't report netboot always netboot machines.
+	c.Check(machines[1].Netboot(), jc.IsTrue)
+}
+
+func (*machineSuite) TestDiskless(c *gc.C) {
+	for i, test := range []struct {
+		status    string
+		ephemeral bool
+		disks     []*blockdevice
+		diskless  bool
+	}{{
+		status:   "Deployed",
+		disks:    []*blockdevice{{name: "sda"}},
+		diskless: false,
+	}, {
+		status:    "Deployed",
+		ephemeral: true,
+		disks:     []*blockdevice{{name: "sda"}},
+		diskless:  true,
+	}, {
+		status:   "Deployed",
+		diskless: true,
+	}, {
+		status:    "Deploying",
+		ephemeral: true,
+		diskless:  false,
+	}} {
+		c.Logf("test %d", i)
+		m := &machine{
+			statusName:           test.status,
+			ephemeralDeploy:      test.ephemeral,
+			physicalBlockDevices: test.disks,
+		}
+		c.Check(m.Diskless(), gc.Equals, test.diskless)
+	}
+}
+
+func bondedMachine() *machine {
+	return &machine{
+		interfaceSet: []*interface_{
+			{id: 1, name: "eth0", children: []string{"bond0"}},
+			{id: 2, name: "eth1", children: []string{"bond0"}},
+			{id: 3, name: "bond0", parents: []string{"eth0", "eth1"}, children: []string{"bond0.10", "br0"}},
+			{id: 4, name: "bond0.10", parents: []string{"bond0"}},
+			{id: 5, name: "br0", parents: []string{"bond0"}},
+		},
+	}
+}
+
+func interfaceNames(interfaces []Interface) []string {
+	var names []string
+	for _, iface := range interfaces {
+		names = append(names, iface.Name())
+	}
+	return names
+}
+
+func (*machineSuite) TestInterfaceByName(c *gc.C) {
+	machine := bondedMachine()
+	iface := machine.InterfaceByName("bond0")
+	c.Assert(iface, gc.NotNil)
+	c.Check(iface.ID(), gc.Equals, 3)
+	c.Check(machine.InterfaceByName("eth2"), gc.IsNil)
+}
+
+func (*machineSuite) TestInterfaceParentsAndChildren(c *gc.C) {
+	machine := bondedMachine()
+	bond := machine.InterfaceByName("bond0")
+	c.Check(interfaceNames(machine.InterfaceParents(bond)), jc.DeepEquals, []string{"eth0", "eth1"})
+	c.Check(interfaceNames(machine.InterfaceChildren(bond)), jc.DeepEquals, []string{"bond0.10", "br0"})
+	c.Check(machine.InterfaceChildren(machine.InterfaceByName("br0")), gc.HasLen, 0)
+}
+
+func (*machineSuite) TestWalkInterfaces(c *gc.C) {
+	machine := bondedMachine()
+	var visited []string
+	err := machine.WalkInterfaces(func(iface Interface, depth int) error {
+		visited = append(visited, fmt.Sprintf("%d:%s", depth, iface.Name()))
+		return nil
+	})
+	c.Assert(err, jc.ErrorIsNil)
+	c.Check(visited, jc.DeepEquals, []string{
+		"0:eth0", "1:bond0", "2:bond0.10", "2:br0", "0:eth1",
+	})
+}
+
+func (*machineSuite) TestWalkInterfacesError(c *gc.C) {
+	machine := bondedMachine()
+	var visited []string
+	err := machine.WalkInterfaces(func(iface Interface, depth int) error {
+		visited = append(visited, iface.Name())
+		if iface.Name() == "bond0" {
+			return errors.New("boom")
+		}
+		return nil
+	})
+	c.Assert(err, gc.ErrorMatches, "boom")
+	c.Check(visited, jc.DeepEquals, []string{"eth0", "bond0"})
+}
+
+func (*machineSuite) TestPXEDHCPProvider(c *gc.C) {
+	machines, err := readMachines(twoDotOh, parseJSON(c, machinesResponse))
+	c.Assert(err, jc.ErrorIsNil)
+	machine := machines[0]
+	c.Check(machine.PXEDHCPProvider(), gc.Equals, DHCPProviderMAAS)
+
+	machine.bootInterface.vlan.dhcp = false
+	c.Check(machine.PXEDHCPProvider(), gc.Equals, DHCPProviderNone)
+
+	// Without a VLAN on the boot interface, the VLAN of the linked subnet
+	// is used.
+	machine.bootInterface.vlan = nil
+	machine.bootInterface.links = []*link{{
+		subnet: &subnet{vlan: &vlan{externalDHCP: "10.0.0.1"}},
+	}}
+	c.Check(machine.PXEDHCPProvider(), gc.Equals, DHCPProviderExternal)
+
+	machine.bootInterface = nil
+	c.Check(machine.PXEDHCPProvider(), gc.Equals, DHCPProviderUnknown)
+}
+
+func (*machineSuite) TestReadMachinesSkipInvalid(c *gc.C) {
+	json := parseJSON(c, machinesResponse)
+	list := json.([]interface{})
+	list[1].(map[string]interface{})["interface_set"] = []interface{}{
+		map[string]interface{}{"id": "not a number"},
+	}
+	list = append(list, "garbage")
+	machines, failures, err := readMachinesSkipInvalid(twoDotOh, list)
+	c.Assert(err, jc.ErrorIsNil)
+	c.Assert(machines, gc.HasLen, 2)
+	c.Check(machines[0].SystemID(), gc.Equals, "4y3ha3")
+	c.Check(machines[1].SystemID(), gc.Equals, "4y3ha6")
+	c.Assert(failures, gc.HasLen, 2)
+	c.Check(failures["4y3ha4"], jc.Satisfies, IsDeserializationError)
+	c.Check(failures["4y3ha4"], gc.ErrorMatches, "machine 1: .*")
+	c.Check(failures["machine 3"], gc.ErrorMatches, "unexpected value for machine 3, string")
+}
+
+func (*machineSuite) TestReadMachinesSkipInvalidBadList(c *gc.C) {
+	_, _, err := readMachinesSkipInvalid(twoDotOh, "wat?")
+	c.Check(err, jc.Satisfies, IsDeserializationError)
+}
+
+func (*machineSuite) TestDeployedImage(c *gc.C) {
+	machines, err := readMachines(twoDotOh, parseJSON(c, machinesResponse))
+	c.Assert(err, jc.ErrorIsNil)
+	image, ok := machines[0].DeployedImage()
+	c.Assert(ok, jc.IsTrue)
+	c.Check(image, jc.DeepEquals, DeployedImage{
+		OperatingSystem: "ubuntu",
+		DistroSeries:    "trusty",
+		Kernel:          "hwe-t",
+		Architecture:    "amd64/generic",
+	})
+}
+
+func (*machineSuite) TestDeployedImageNotDeployed(c *gc.C) {
+	json := updateJSONMap(c, machineResponse, map[string]interface{}{
+		"status_name": "Ready",
+	})
+	machine, err := readMachine(twoDotOh, parseJSON(c, json))
+	c.Assert(err, jc.ErrorIsNil)
+	image, ok := machine.DeployedImage()
+	c.Check(ok, jc.IsFalse)
+	c.Check(image, jc.DeepEquals, DeployedImage{})
+}
+
+func (*machineSuite) TestUpdated(c *gc.C) {
+	c.Check((&machine{}).Updated().IsZero(), jc.IsTrue)
+	c.Check((&machine{updated: "garbage"}).Updated().IsZero(), jc.IsTrue)
+
+	updated := (&machine{updated: "2022-03-01T10:20:30.500"}).Updated()
+	c.Check(updated, gc.Equals, time.Date(2022, time.March, 1, 10, 20, 30, 500000000, time.UTC))
+
+	located := &machine{
+		updated:    "2022-03-01T10:20:30",
+		controller: &controller{timeLocation: time.FixedZone("UTC-2", -2*60*60)},
+	}
+	c.Check(located.Updated(), gc.Equals, time.Date(2022, time.March, 1, 12, 20, 30, 0, time.UTC))
+}
+
+func (*machineSuite) TestReadMachineUpdated(c *gc.C) {
+	json := parseJSON(c, machineResponse)
+	json.(map[string]interface{})["updated"] = "2022-03-01T10:20:30.000"
+	machine, err := readMachine(twoDotOh, json)
+	c.Assert(err, jc.ErrorIsNil)
+	c.Check(machine.Updated(), gc.Equals, time.Date(2022, time.March, 1, 10, 20, 30, 0, time.UTC))
+}
+
 func (*machineSuite) TestLowVersion(c *gc.C) {
 	_, err := readMachines(version.MustParse("1.9.0"), parseJSON(c, machinesResponse))
 	c.Assert(err, jc.Satisfies, IsUnsupportedVersionError)
@@ -150,6 +366,35 @@ func (*machineSuite) TestHighVersion(c *gc.C) {
 	c.Assert(machines, gc.HasLen, 3)
 }
 
+func (*machineSuite) TestReadMachineHardwareSync(c *gc.C) {
+	json := updateJSONMap(c, machineResponse, map[string]interface{}{
+		"enable_hw_sync": true,
+		"is_dpu":         true,
+	})
+	found, err := readMachine(threeDotTwo, parseJSON(c, json))
+	c.Assert(err, jc.ErrorIsNil)
+	c.Check(found.HardwareSync(), jc.IsTrue)
+	c.Check(found.DPU(), jc.IsTrue)
+
+	// MAAS releases before 3.2 don't report the fields.
+	found, err = readMachine(threeDotTwo, parseJSON(c, machineResponse))
+	c.Assert(err, jc.ErrorIsNil)
+	c.Check(found.HardwareSync(), jc.IsFalse)
+	c.Check(found.DPU(), jc.IsFalse)
+
+	found, err = readMachine(twoDotOh, parseJSON(c, json))
+	c.Assert(err, jc.ErrorIsNil)
+	c.Check(found.HardwareSync(), jc.IsFalse)
+}
+
+func (*machineSuite) TestReadMachineHardwareSyncInvalid(c *gc.C) {
+	json := updateJSONMap(c, machineResponse, map[string]interface{}{
+		"enable_hw_sync": "yes please",
+	})
+	_, err := readMachine(threeDotTwo, parseJSON(c, json))
+	c.Check(err, jc.Satisfies, IsDeserializationError)
+}
+
 func (s *machineSuite) getServerAndMachine(c *gc.C) (*SimpleTestServer, *machine) {
 	server, controller := createTestServerController(c, s)
 	// Just have machines return one machine
@@ -162,6 +407,22 @@ func (s *machineSuite) getServerAndMachine(c *gc.C) (*SimpleTestServer, *machine
 	return server, machine
 }
 
+func (s *machineSuite) TestPoolDelete(c *gc.C) {
+	server, machine := s.getServerAndMachine(c)
+	pool := machine.Pool()
+	server.AddDeleteResponse("/MAAS/api/2.0/pools/default/", http.StatusBadRequest, "The default resource pool cannot be deleted.")
+	err := pool.Delete()
+	c.Assert(err, jc.Satisfies, IsBadRequestError)
+}
+
+func (s *machineSuite) TestZoneUpdate(c *gc.C) {
+	server, machine := s.getServerAndMachine(c)
+	zone := machine.Zone()
+	server.AddPutResponse("/MAAS/api/2.0/zones/default/", http.StatusForbidden, "admins only")
+	err := zone.Update(UpdateZoneArgs{Description: "everything"})
+	c.Assert(err, jc.Satisfies, IsPermissionError)
+}
+
 func (s *machineSuite) TestStart(c *gc.C) {
 	server, machine := s.getServerAndMachine(c)
 	response := updateJSONMap(c, machineResponse, map[string]interface{}{
@@ -190,6 +451,172 @@ func (s *machineSuite) TestStart(c *gc.C) {
 	c.Check(form.Get("comment"), gc.Equals, "a comment")
 }
 
+func (s *machineSuite) TestStartConcurrentReads(c *gc.C) {
+	server, machine := s.getServerAndMachine(c)
+	const starts = 10
+	for i := 0; i < starts; i++ {
+		response := updateJSONMap(c, machineResponse, map[string]interface{}{
+			"status_name": fmt.Sprintf("Deploying %d", i),
+		})
+		server.AddPostResponse(machine.resourceURI+"?op=deploy", http.StatusOK, response)
+	}
+
+	done := make(chan struct{})
+	go func() {
+		defer close(done)
+		for i := 0; i < starts; i++ {
+			c.Check(machine.Start(StartArgs{}), jc.ErrorIsNil)
+		}
+	}()
+	for reading := true; reading; {
+		select {
+		case <-done:
+			reading = false
+		default:
+		}
+		machine.StatusName()
+		machine.Zone()
+		machine.Pool()
+		machine.OwnerData()
+		machine.BlockDevices()
+	}
+	c.Check(machine.StatusName(), gc.Equals, "Deploying 9")
+	c.Check(machine.Zone().Name(), gc.Equals, "default")
+}
+
+func (s *machineSuite) TestStartEphemeral(c *gc.C) {
+	server, machine := s.getServerAndMachine(c)
+	response := updateJSONMap(c, machineResponse, map[string]interface{}{
+		"status_name":      "Deploying",
+		"ephemeral_deploy": true,
+	})
+	server.AddPostResponse(machine.resourceURI+"?op=deploy", http.StatusOK, response)
+
+	err := machine.Start(StartArgs{EphemeralDeploy: true})
+	c.Assert(err, jc.ErrorIsNil)
+	c.Check(machine.EphemeralDeploy(), jc.IsTrue)
+
+	form := server.LastRequest().PostForm
+	c.Assert(form, gc.HasLen, 1)
+	c.Check(form.Get("ephemeral_deploy"), gc.Equals, "true")
+}
+
+func (s *machineSuite) TestSetNetboot(c *gc.C) {
+	server, machine := s.getServerAndMachine(c)
+	response := updateJSONMap(c, machineResponse, map[string]interface{}{
+		"netboot": true,
+	})
+	server.AddPutResponse(machine.resourceURI, http.StatusOK, response)
+
+	err := machine.SetNetboot(true)
+	c.Assert(err, jc.ErrorIsNil)
+	c.Check(machine.Netboot(), jc.IsTrue)
+
+	form := server.LastRequest().PostForm
+	c.Check(form.Get("netboot"), gc.Equals, "true")
+}
+
+func (s *machineSuite) TestSetNetbootForbidden(c *gc.C) {
+	server, machine := s.getServerAndMachine(c)
+	server.AddPutResponse(machine.resourceURI, http.StatusForbidden, "not your machine")
+
+	err := machine.SetNetboot(true)
+	c.Assert(err, jc.Satisfies, IsPermissionError)
+	c.Check(err.Error(), gc.Equals, "not your machine")
+	c.Check(machine.Netboot(), jc.IsFalse)
+}
+
+func (s *machineSuite) TestPowerParameters(c *gc.C) {
+	server, machine := s.getServerAndMachine(c)
+	server.AddGetResponse(machine.resourceURI+"?op=power_parameters", http.StatusOK, `{
+		"power_address": "10.0.0.20",
+		"power_user": "maas",
+		"power_boot_type": "efi"
+	}`)
+
+	params, err := machine.PowerParameters()
+	c.Assert(err, jc.ErrorIsNil)
+	c.Check(params, jc.DeepEquals, map[string]interface{}{
+		"power_address":   "10.0.0.20",
+		"power_user":      "maas",
+		"power_boot_type": "efi",
+	})
+}
+
+func (s *machineSuite) TestPowerParametersForbidden(c *gc.C) {
+	server, machine := s.getServerAndMachine(c)
+	server.AddGetResponse(machine.resourceURI+"?op=power_parameters", http.StatusForbidden, "admins only")
+
+	_, err := machine.PowerParameters()
+	c.Assert(err, jc.Satisfies, IsPermissionError)
+}
+
+func (s *machineSuite) TestSetNetbootUnexpected(c *gc.C) {
+	server, machine := s.getServerAndMachine(c)
+	server.AddPutResponse(machine.resourceURI, http.StatusInternalServerError, "boom")
+
+	err := machine.SetNetboot(true)
+	c.Assert(err, jc.Satisfies, IsUnexpectedError)
+}
+
+func (s *machineSuite) TestStartArgsValidate(c *gc.C) {
+	for i, test := range []struct {
+		args    StartArgs
+		errText string
+	}{{
+		args: StartArgs{DistroSeries: "ubuntu/jammy"},
+	}, {
+		args: StartArgs{OSystem: "ubuntu", Release: "jammy"},
+	}, {
+		args: StartArgs{OSystem: "ubuntu", DistroSeries: "ubuntu/jammy"},
+	}, {
+		args: StartArgs{OSystem: "centos"},
+	}, {
+		args:    StartArgs{DistroSeries: "jammy", Release: "jammy"},
+		errText: "specifying both DistroSeries and Release not valid",
+	}, {
+		args:    StartArgs{OSystem: "ubuntu", Release: "ubuntu/jammy"},
+		errText: `Release "ubuntu/jammy" qualified by operating system not valid`,
+	}, {
+		args:    StartArgs{OSystem: "ubuntu/jammy"},
+		errText: `OSystem "ubuntu/jammy" not valid`,
+	}, {
+		args:    StartArgs{OSystem: "centos", DistroSeries: "ubuntu/jammy"},
+		errText: `DistroSeries "ubuntu/jammy" with OSystem "centos" not valid`,
+	}} {
+		c.Logf("test %d", i)
+		err := test.args.Validate()
+		if test.errText == "" {
+			c.Check(err, jc.ErrorIsNil)
+		} else {
+			c.Check(err, jc.Satisfies, errors.IsNotValid)
+			c.Check(err.Error(), gc.Equals, test.errText)
+		}
+	}
+}
+
+func (s *machineSuite) TestStartOSystemRelease(c *gc.C) {
+	server, machine := s.getServerAndMachine(c)
+	server.AddPostResponse(machine.resourceURI+"?op=deploy", http.StatusOK, machineResponse)
+
+	err := machine.Start(StartArgs{
+		OSystem: "ubuntu",
+		Release: "jammy",
+	})
+	c.Assert(err, jc.ErrorIsNil)
+
+	form := server.LastRequest().PostForm
+	c.Assert(form, gc.HasLen, 2)
+	c.Check(form.Get("osystem"), gc.Equals, "ubuntu")
+	c.Check(form.Get("distro_series"), gc.Equals, "jammy")
+}
+
+func (s *machineSuite) TestStartValidates(c *gc.C) {
+	_, machine := s.getServerAndMachine(c)
+	err := machine.Start(StartArgs{DistroSeries: "jammy", Release: "jammy"})
+	c.Assert(err, jc.Satisfies, errors.IsNotValid)
+}
+
 func (s *machineSuite) TestStartMachineNotFound(c *gc.C) {
 	server, machine := s.getServerAndMachine(c)
 	server.AddPostResponse(machine.resourceURI+"?op=deploy", http.StatusNotFound, "can't find machine")
@@ -230,6 +657,584 @@ func (s *machineSuite) TestStartMachineUnknown(c *gc.C) {
 	c.Assert(err.Error(), gc.Equals, "unexpected: ServerError: 405 Method Not Allowed (wat?)")
 }
 
+func (s *machineSuite) TestRecoverFromFailureNotFailed(c *gc.C) {
+	server, machine := s.getServerAndMachine(c)
+	plan, err := machine.RecoverFromFailure(RecoverFromFailureArgs{})
+	c.Assert(err, jc.ErrorIsNil)
+	c.Assert(plan, gc.HasLen, 0)
+	c.Assert(server.RequestCount(), gc.Equals, 0)
+}
+
+func (s *machineSuite) TestRecoverFromFailureUnsupported(c *gc.C) {
+	_, machine := s.getServerAndMachine(c)
+	// A failed status that newer versions of MAAS could add.
+	machine.statusName = "Failed upgrading"
+	_, err := machine.RecoverFromFailure(RecoverFromFailureArgs{})
+	c.Assert(err, jc.Satisfies, errors.IsNotSupported)
+}
+
+func (s *machineSuite) TestRecoverFromFailureDryRun(c *gc.C) {
+	server, machine := s.getServerAndMachine(c)
+	for status, expected := range map[string][]RecoveryAction{
+		"Failed commissioning":        {RecoveryActionCommission},
+		"Failed deployment":           {RecoveryActionRelease},
+		"Failed disk erasing":         {RecoveryActionRelease},
+		"Failed entering rescue mode": {RecoveryActionExitRescueMode},
+		"Failed exiting rescue mode":  {RecoveryActionExitRescueMode},
+		"Broken":                      {RecoveryActionMarkFixed, RecoveryActionCommission},
+	} {
+		c.Logf("status %q", status)
+		machine.statusName = status
+		plan, err := machine.RecoverFromFailure(RecoverFromFailureArgs{DryRun: true})
+		c.Check(err, jc.ErrorIsNil)
+		c.Check(plan, jc.DeepEquals, expected)
+	}
+	c.Assert(server.RequestCount(), gc.Equals, 0)
+}
+
+func (s *machineSuite) TestRecoverFromFailure(c *gc.C) {
+	server, machine := s.getServerAndMachine(c)
+	machine.statusName = "Broken"
+	server.AddPostResponse(machine.resourceURI+"?op=mark_fixed", http.StatusOK, updateJSONMap(c, machineResponse, map[string]interface{}{
+		"status_name": "Ready",
+	}))
+	server.AddPostResponse(machine.resourceURI+"?op=commission", http.StatusOK, updateJSONMap(c, machineResponse, map[string]interface{}{
+		"status_name": "Commissioning",
+	}))
+
+	plan, err := machine.RecoverFromFailure(RecoverFromFailureArgs{Comment: "fixed the disk"})
+	c.Assert(err, jc.ErrorIsNil)
+	c.Assert(plan, jc.DeepEquals, []RecoveryAction{RecoveryActionMarkFixed, RecoveryActionCommission})
+	c.Assert(machine.StatusName(), gc.Equals, "Commissioning")
+
+	requests := server.LastNRequests(2)
+	c.Assert(requests, gc.HasLen, 2)
+	c.Check(requests[0].URL.Query().Get("op"), gc.Equals, "mark_fixed")
+	c.Check(requests[0].PostForm.Get("comment"), gc.Equals, "fixed the disk")
+	c.Check(requests[1].URL.Query().Get("op"), gc.Equals, "commission")
+}
+
+func (s *machineSuite) TestRecoverFromFailureRescueMode(c *gc.C) {
+	server, machine := s.getServerAndMachine(c)
+	machine.statusName = "Failed entering rescue mode"
+	server.AddPostResponse(machine.resourceURI+"?op=exit_rescue_mode", http.StatusOK, updateJSONMap(c, machineResponse, map[string]interface{}{
+		"status_name": "Exiting rescue mode",
+	}))
+
+	plan, err := machine.RecoverFromFailure(RecoverFromFailureArgs{})
+	c.Assert(err, jc.ErrorIsNil)
+	c.Assert(plan, jc.DeepEquals, []RecoveryAction{RecoveryActionExitRescueMode})
+	c.Assert(machine.StatusName(), gc.Equals, "Exiting rescue mode")
+}
+
+func (s *machineSuite) TestRecoverFromFailureConflict(c *gc.C) {
+	server, machine := s.getServerAndMachine(c)
+	machine.statusName = "Failed deployment"
+	server.AddPostResponse(machine.resourceURI+"?op=release", http.StatusConflict, "machine busy")
+	_, err := machine.RecoverFromFailure(RecoverFromFailureArgs{})
+	c.Assert(err, jc.Satisfies, IsBadRequestError)
+	c.Assert(err.Error(), gc.Equals, `recovery action "release": machine busy`)
+}
+
+func (s *machineSuite) TestSetDefaultGateway(c *gc.C) {
+	server, machine := s.getServerAndMachine(c)
+	response := updateJSONMap(c, interfaceResponse, map[string]interface{}{
+		"id":   35,
+		"name": "eth0-updated",
+	})
+	server.AddPostResponse("/MAAS/api/2.0/nodes/4y3ha3/interfaces/35/?op=set_default_gateway", http.StatusOK, response)
+	err := machine.SetDefaultGateway(35, 82)
+	c.Assert(err, jc.ErrorIsNil)
+	c.Assert(machine.Interface(35).Name(), gc.Equals, "eth0-updated")
+
+	request := server.LastRequest()
+	c.Assert(request.PostForm.Get("link_id"), gc.Equals, "82")
+}
+
+func (s *machineSuite) TestSetDefaultGatewayNoLink(c *gc.C) {
+	server, machine := s.getServerAndMachine(c)
+	server.AddPostResponse("/MAAS/api/2.0/nodes/4y3ha3/interfaces/35/?op=set_default_gateway", http.StatusOK, interfaceResponse)
+	err := machine.SetDefaultGateway(35, 0)
+	c.Assert(err, jc.ErrorIsNil)
+
+	request := server.LastRequest()
+	_, found := request.PostForm["link_id"]
+	c.Assert(found, jc.IsFalse)
+}
+
+func (s *machineSuite) TestSetDefaultGatewayValidates(c *gc.C) {
+	server, machine := s.getServerAndMachine(c)
+	err := machine.SetDefaultGateway(1234, 0)
+	c.Check(err, jc.Satisfies, errors.IsNotValid)
+	c.Check(err.Error(), gc.Equals, "interface 1234 not valid")
+	err = machine.SetDefaultGateway(35, 1234)
+	c.Check(err, jc.Satisfies, errors.IsNotValid)
+	c.Check(err.Error(), gc.Equals, `link 1234 on interface "eth0" not valid`)
+	c.Check(server.RequestCount(), gc.Equals, 0)
+}
+
+func (s *machineSuite) TestSetDefaultGatewayBadRequest(c *gc.C) {
+	server, machine := s.getServerAndMachine(c)
+	server.AddPostResponse("/MAAS/api/2.0/nodes/4y3ha3/interfaces/35/?op=set_default_gateway", http.StatusBadRequest, "no gateway")
+	err := machine.SetDefaultGateway(35, 82)
+	c.Assert(err, jc.Satisfies, IsBadRequestError)
+	c.Assert(err.Error(), gc.Equals, "no gateway")
+}
+
+func (s *machineSuite) TestCreatePartition(c *gc.C) {
+	server, machine := s.getServerAndMachine(c)
+	server.AddPostResponse("/MAAS/api/2.0/nodes/4y3ha3/blockdevices/34/partitions/?op=", http.StatusOK, partitionResponse)
+	blockDevice := machine.BlockDevice(34)
+	partition, err := blockDevice.CreatePartition(CreatePartitionArgs{
+		Size:     1073741824,
+		Bootable: true,
+	})
+	c.Assert(err, jc.ErrorIsNil)
+	c.Assert(partition.ID(), gc.Equals, 2)
+	c.Assert(partition.Size(), gc.Equals, uint64(1073741824))
+	c.Assert(blockDevice.Partitions(), gc.HasLen, 2)
+	c.Assert(machine.Partition(2), gc.NotNil)
+
+	form := server.LastRequest().PostForm
+	c.Assert(form.Get("size"), gc.Equals, "1073741824")
+	c.Assert(form.Get("bootable"), gc.Equals, "true")
+	_, found := form["uuid"]
+	c.Assert(found, jc.IsFalse)
+}
+
+func (s *machineSuite) TestCreatePartitionBadRequest(c *gc.C) {
+	server, machine := s.getServerAndMachine(c)
+	server.AddPostResponse("/MAAS/api/2.0/nodes/4y3ha3/blockdevices/34/partitions/?op=", http.StatusBadRequest, "no space")
+	blockDevice := machine.BlockDevice(34)
+	_, err := blockDevice.CreatePartition(CreatePartitionArgs{})
+	c.Assert(err, jc.Satisfies, IsBadRequestError)
+	c.Assert(blockDevice.Partitions(), gc.HasLen, 1)
+}
+
+func (s *machineSuite) TestCreateBlockDeviceArgsValidate(c *gc.C) {
+	for i, test := range []struct {
+		args    CreateBlockDeviceArgs
+		errText string
+	}{{
+		errText: "missing Name not valid",
+	}, {
+		args:    CreateBlockDeviceArgs{Name: "sdc"},
+		errText: "missing Size not valid",
+	}, {
+		args:    CreateBlockDeviceArgs{Name: "sdc", Size: 8589934592},
+		errText: "missing BlockSize not valid",
+	}, {
+		args:    CreateBlockDeviceArgs{Name: "sdc", Size: 8589934592, BlockSize: 512, Model: "QEMU HARDDISK"},
+		errText: "missing IDPath, or Model and Serial not valid",
+	}, {
+		args: CreateBlockDeviceArgs{Name: "sdc", Size: 8589934592, BlockSize: 512, Model: "QEMU HARDDISK", Serial: "QM00003"},
+	}, {
+		args: CreateBlockDeviceArgs{Name: "sdc", Size: 8589934592, BlockSize: 512, IDPath: "/dev/sdc"},
+	}} {
+		c.Logf("test %d", i)
+		err := test.args.Validate()
+		if test.errText == "" {
+			c.Check(err, jc.ErrorIsNil)
+		} else {
+			c.Check(err, jc.Satisfies, errors.IsNotValid)
+			c.Check(err.Error(), gc.Equals, test.errText)
+		}
+	}
+}
+
+func (s *machineSuite) TestCreateBlockDevice(c *gc.C) {
+	server, machine := s.getServerAndMachine(c)
+	response := updateJSONMap(c, blockdeviceResponse, map[string]interface{}{
+		"id":           99,
+		"name":         "sdc",
+		"resource_uri": "/MAAS/api/2.0/nodes/4y3ha3/blockdevices/99/",
+	})
+	server.AddPostResponse("/api/2.0/nodes/4y3ha3/blockdevices/?op=", http.StatusOK, response)
+	blockDevice, err := machine.CreateBlockDevice(CreateBlockDeviceArgs{
+		Name:      "sdc",
+		IDPath:    "/dev/sdc",
+		Size:      8589934592,
+		BlockSize: 512,
+	})
+	c.Assert(err, jc.ErrorIsNil)
+	c.Check(blockDevice.ID(), gc.Equals, 99)
+	c.Check(machine.BlockDevice(99), gc.NotNil)
+	c.Check(machine.PhysicalBlockDevices(), gc.HasLen, 3)
+
+	form := server.LastRequest().PostForm
+	c.Check(form.Get("name"), gc.Equals, "sdc")
+	c.Check(form.Get("id_path"), gc.Equals, "/dev/sdc")
+	c.Check(form.Get("size"), gc.Equals, "8589934592")
+	c.Check(form.Get("block_size"), gc.Equals, "512")
+	_, found := form["model"]
+	c.Check(found, jc.IsFalse)
+}
+
+func (s *machineSuite) TestCreateBlockDeviceValidates(c *gc.C) {
+	server, machine := s.getServerAndMachine(c)
+	_, err := machine.CreateBlockDevice(CreateBlockDeviceArgs{})
+	c.Assert(err, jc.Satisfies, errors.IsNotValid)
+	c.Assert(server.RequestCount(), gc.Equals, 0)
+}
+
+func (s *machineSuite) TestUpdateBlockDevice(c *gc.C) {
+	server, machine := s.getServerAndMachine(c)
+	response := updateJSONMap(c, blockdeviceResponse, map[string]interface{}{
+		"name":   "root",
+		"serial": "QM00009",
+	})
+	server.AddPutResponse("/MAAS/api/2.0/nodes/4y3ha3/blockdevices/34/", http.StatusOK, response)
+	blockDevice := machine.BlockDevice(34)
+	err := blockDevice.Update(UpdateBlockDeviceArgs{Name: "root", Serial: "QM00009"})
+	c.Assert(err, jc.ErrorIsNil)
+	c.Check(blockDevice.Name(), gc.Equals, "root")
+	c.Check(blockDevice.Serial(), gc.Equals, "QM00009")
+
+	form := server.LastRequest().PostForm
+	c.Check(form.Get("name"), gc.Equals, "root")
+	c.Check(form.Get("serial"), gc.Equals, "QM00009")
+	_, found := form["size"]
+	c.Check(found, jc.IsFalse)
+}
+
+func (s *machineSuite) TestUpdateBlockDeviceBadRequest(c *gc.C) {
+	server, machine := s.getServerAndMachine(c)
+	server.AddPutResponse("/MAAS/api/2.0/nodes/4y3ha3/blockdevices/34/", http.StatusBadRequest, "in use")
+	err := machine.BlockDevice(34).Update(UpdateBlockDeviceArgs{Size: 1})
+	c.Assert(err, jc.Satisfies, IsBadRequestError)
+}
+
+func (s *machineSuite) TestDeleteBlockDevice(c *gc.C) {
+	server, machine := s.getServerAndMachine(c)
+	server.AddDeleteResponse("/MAAS/api/2.0/nodes/4y3ha3/blockdevices/34/", http.StatusNoContent, "")
+	err := machine.BlockDevice(34).Delete()
+	c.Assert(err, jc.ErrorIsNil)
+	c.Assert(server.LastRequest().Method, gc.Equals, "DELETE")
+}
+
+func (s *machineSuite) TestDeleteBlockDeviceNotFound(c *gc.C) {
+	_, machine := s.getServerAndMachine(c)
+	err := machine.BlockDevice(34).Delete()
+	c.Assert(err, jc.Satisfies, IsNoMatchError)
+}
+
+func (s *machineSuite) TestDeletePartition(c *gc.C) {
+	server, machine := s.getServerAndMachine(c)
+	server.AddDeleteResponse("/MAAS/api/2.0/nodes/4y3ha3/blockdevices/34/partition/1/", http.StatusNoContent, "")
+	err := machine.Partition(1).Delete()
+	c.Assert(err, jc.ErrorIsNil)
+	c.Assert(server.LastRequest().Method, gc.Equals, "DELETE")
+}
+
+func (s *machineSuite) TestDeletePartitionNotFound(c *gc.C) {
+	_, machine := s.getServerAndMachine(c)
+	err := machine.Partition(1).Delete()
+	c.Assert(err, jc.Satisfies, IsNoMatchError)
+}
+
+func (s *machineSuite) TestResizePartition(c *gc.C) {
+	server, machine := s.getServerAndMachine(c)
+	response := updateJSONMap(c, partitionResponse, map[string]interface{}{
+		"id":           1,
+		"resource_uri": "/MAAS/api/2.0/nodes/4y3ha3/blockdevices/34/partition/1",
+		"size":         2147483648,
+	})
+	server.AddPutResponse("/MAAS/api/2.0/nodes/4y3ha3/blockdevices/34/partition/1/", http.StatusOK, response)
+	partition := machine.Partition(1)
+	err := partition.Resize(2147483648)
+	c.Assert(err, jc.ErrorIsNil)
+	c.Assert(partition.Size(), gc.Equals, uint64(2147483648))
+	c.Assert(server.LastRequest().PostForm.Get("size"), gc.Equals, "2147483648")
+}
+
+func (s *machineSuite) TestResizePartitionNotSupported(c *gc.C) {
+	server, machine := s.getServerAndMachine(c)
+	server.AddPutResponse("/MAAS/api/2.0/nodes/4y3ha3/blockdevices/34/partition/1/", http.StatusMethodNotAllowed, "")
+	err := machine.Partition(1).Resize(2147483648)
+	c.Assert(err, jc.Satisfies, errors.IsNotSupported)
+}
+
+func (s *machineSuite) TestResizePartitionValidates(c *gc.C) {
+	_, machine := s.getServerAndMachine(c)
+	err := machine.Partition(1).Resize(0)
+	c.Assert(err, jc.Satisfies, errors.IsNotValid)
+}
+
+func (s *machineSuite) TestFormatBlockDevice(c *gc.C) {
+	server, machine := s.getServerAndMachine(c)
+	response := updateJSONMap(c, blockdeviceResponse, map[string]interface{}{
+		"filesystem": map[string]interface{}{
+			"fstype":      "xfs",
+			"mount_point": nil,
+			"label":       "data",
+			"uuid":        "0c1f8a2e-6d1f-4b85-9d9c-4e1b36d1c0a5",
+		},
+	})
+	server.AddPostResponse("/MAAS/api/2.0/nodes/4y3ha3/blockdevices/34/?op=format", http.StatusOK, response)
+	blockDevice := machine.BlockDevice(34)
+	c.Assert(blockDevice.FileSystem(), gc.IsNil)
+	err := blockDevice.Format(FormatStorageDeviceArgs{FSType: FileSystemXFS, Label: "data"})
+	c.Assert(err, jc.ErrorIsNil)
+	fs := blockDevice.FileSystem()
+	c.Assert(fs, gc.NotNil)
+	c.Check(fs.Type(), gc.Equals, "xfs")
+	c.Check(fs.Label(), gc.Equals, "data")
+
+	form := server.LastRequest().PostForm
+	c.Check(form.Get("fstype"), gc.Equals, "xfs")
+	c.Check(form.Get("label"), gc.Equals, "data")
+}
+
+func (s *machineSuite) TestFormatBlockDeviceValidates(c *gc.C) {
+	server, machine := s.getServerAndMachine(c)
+	err := machine.BlockDevice(34).Format(FormatStorageDeviceArgs{})
+	c.Assert(err, jc.Satisfies, errors.IsNotValid)
+	c.Assert(server.RequestCount(), gc.Equals, 0)
+}
+
+func (s *machineSuite) TestMountBlockDevice(c *gc.C) {
+	server, machine := s.getServerAndMachine(c)
+	server.AddPostResponse("/MAAS/api/2.0/nodes/4y3ha3/blockdevices/34/?op=mount", http.StatusOK, blockdeviceResponse)
+	blockDevice := machine.BlockDevice(34)
+	err := blockDevice.Mount(MountStorageDeviceArgs{MountPoint: "/srv"})
+	c.Assert(err, jc.ErrorIsNil)
+	c.Check(blockDevice.FileSystem().MountPoint(), gc.Equals, "/srv")
+	c.Check(server.LastRequest().PostForm.Get("mount_point"), gc.Equals, "/srv")
+}
+
+func (s *machineSuite) TestUnmountBlockDeviceConflict(c *gc.C) {
+	server, machine := s.getServerAndMachine(c)
+	server.AddPostResponse("/MAAS/api/2.0/nodes/4y3ha3/blockdevices/34/?op=unmount", http.StatusConflict, "machine is deployed")
+	err := machine.BlockDevice(34).Unmount()
+	c.Assert(err, jc.Satisfies, IsBadRequestError)
+}
+
+func (s *machineSuite) TestFormatPartition(c *gc.C) {
+	server, machine := s.getServerAndMachine(c)
+	response := updateJSONMap(c, partitionResponse, map[string]interface{}{
+		"id":           1,
+		"resource_uri": "/MAAS/api/2.0/nodes/4y3ha3/blockdevices/34/partition/1",
+		"filesystem": map[string]interface{}{
+			"fstype":      "btrfs",
+			"mount_point": nil,
+			"label":       nil,
+			"uuid":        "0c1f8a2e-6d1f-4b85-9d9c-4e1b36d1c0a5",
+		},
+	})
+	server.AddPostResponse("/MAAS/api/2.0/nodes/4y3ha3/blockdevices/34/partition/1/?op=format", http.StatusOK, response)
+	partition := machine.Partition(1)
+	err := partition.Format(FormatStorageDeviceArgs{FSType: "btrfs"})
+	c.Assert(err, jc.ErrorIsNil)
+	c.Check(partition.FileSystem().Type(), gc.Equals, "btrfs")
+	// The partition is updated in place, so the block device sees it too.
+	c.Check(machine.BlockDevice(34).Partitions()[0].FileSystem().Type(), gc.Equals, "btrfs")
+}
+
+func (s *machineSuite) TestUnformatPartition(c *gc.C) {
+	server, machine := s.getServerAndMachine(c)
+	response := updateJSONMap(c, partitionResponse, map[string]interface{}{
+		"id":           1,
+		"resource_uri": "/MAAS/api/2.0/nodes/4y3ha3/blockdevices/34/partition/1",
+	})
+	server.AddPostResponse("/MAAS/api/2.0/nodes/4y3ha3/blockdevices/34/partition/1/?op=unformat", http.StatusOK, response)
+	partition := machine.Partition(1)
+	err := partition.Unformat()
+	c.Assert(err, jc.ErrorIsNil)
+	c.Check(partition.FileSystem(), gc.IsNil)
+}
+
+func (s *machineSuite) TestMountPartitionValidates(c *gc.C) {
+	server, machine := s.getServerAndMachine(c)
+	err := machine.Partition(1).Mount(MountStorageDeviceArgs{})
+	c.Assert(err, jc.Satisfies, errors.IsNotValid)
+	c.Assert(server.RequestCount(), gc.Equals, 0)
+}
+
+func (s *machineSuite) TestRAIDs(c *gc.C) {
+	server, machine := s.getServerAndMachine(c)
+	server.AddGetResponse("/api/2.0/nodes/4y3ha3/raids/", http.StatusOK, raidsResponse)
+	raids, err := machine.RAIDs()
+	c.Assert(err, jc.ErrorIsNil)
+	c.Assert(raids, gc.HasLen, 1)
+	c.Check(raids[0].Name(), gc.Equals, "md0")
+	c.Check(raids[0].(*raid).controller, gc.NotNil)
+}
+
+func (s *machineSuite) TestCreateRAIDArgsValidate(c *gc.C) {
+	for i, test := range []struct {
+		args    CreateRAIDArgs
+		errText string
+	}{{
+		errText: "missing Level not valid",
+	}, {
+		args:    CreateRAIDArgs{Level: "raid-3"},
+		errText: `Level "raid-3" not valid`,
+	}, {
+		args:    CreateRAIDArgs{Level: RAID1},
+		errText: "missing Devices and Partitions not valid",
+	}, {
+		args: CreateRAIDArgs{Level: RAID1, Partitions: []Partition{&partition{id: 1}}},
+	}} {
+		c.Logf("test %d", i)
+		err := test.args.Validate()
+		if test.errText == "" {
+			c.Check(err, jc.ErrorIsNil)
+		} else {
+			c.Check(err, jc.Satisfies, errors.IsNotValid)
+			c.Check(err.Error(), gc.Equals, test.errText)
+		}
+	}
+}
+
+func (s *machineSuite) TestCreateRAID(c *gc.C) {
+	server, machine := s.getServerAndMachine(c)
+	server.AddPostResponse("/api/2.0/nodes/4y3ha3/raids/?op=", http.StatusOK, raidResponse)
+	raid, err := machine.CreateRAID(CreateRAIDArgs{
+		Level:      RAID1,
+		Devices:    []BlockDevice{machine.BlockDevice(34)},
+		Partitions: []Partition{machine.Partition(101)},
+	})
+	c.Assert(err, jc.ErrorIsNil)
+	c.Check(raid.Name(), gc.Equals, "md0")
+	c.Check(raid.VirtualDevice().ID(), gc.Equals, 67)
+
+	form := server.LastRequest().PostForm
+	c.Check(form.Get("level"), gc.Equals, "raid-1")
+	c.Check(form["block_devices"], jc.DeepEquals, []string{"34"})
+	c.Check(form["partitions"], jc.DeepEquals, []string{"101"})
+	_, found := form["name"]
+	c.Check(found, jc.IsFalse)
+}
+
+func (s *machineSuite) TestCreateRAIDValidates(c *gc.C) {
+	server, machine := s.getServerAndMachine(c)
+	_, err := machine.CreateRAID(CreateRAIDArgs{})
+	c.Assert(err, jc.Satisfies, errors.IsNotValid)
+	c.Assert(server.RequestCount(), gc.Equals, 0)
+}
+
+func (s *machineSuite) TestCreateRAIDBadRequest(c *gc.C) {
+	server, machine := s.getServerAndMachine(c)
+	server.AddPostResponse("/api/2.0/nodes/4y3ha3/raids/?op=", http.StatusBadRequest, "not enough devices")
+	_, err := machine.CreateRAID(CreateRAIDArgs{
+		Level:   RAID5,
+		Devices: []BlockDevice{machine.BlockDevice(34)},
+	})
+	c.Assert(err, jc.Satisfies, IsBadRequestError)
+}
+
+func (s *machineSuite) TestVolumeGroups(c *gc.C) {
+	server, machine := s.getServerAndMachine(c)
+	server.AddGetResponse("/api/2.0/nodes/4y3ha3/volume-groups/", http.StatusOK, volumeGroupsResponse)
+	volumeGroups, err := machine.VolumeGroups()
+	c.Assert(err, jc.ErrorIsNil)
+	c.Assert(volumeGroups, gc.HasLen, 1)
+	c.Check(volumeGroups[0].Name(), gc.Equals, "vgroot")
+}
+
+func (s *machineSuite) TestCreateVolumeGroupArgsValidate(c *gc.C) {
+	for i, test := range []struct {
+		args    CreateVolumeGroupArgs
+		errText string
+	}{{
+		errText: "missing Name not valid",
+	}, {
+		args:    CreateVolumeGroupArgs{Name: "vgroot"},
+		errText: "missing Devices and Partitions not valid",
+	}, {
+		args: CreateVolumeGroupArgs{Name: "vgroot", Devices: []BlockDevice{&blockdevice{id: 1}}},
+	}} {
+		c.Logf("test %d", i)
+		err := test.args.Validate()
+		if test.errText == "" {
+			c.Check(err, jc.ErrorIsNil)
+		} else {
+			c.Check(err, jc.Satisfies, errors.IsNotValid)
+			c.Check(err.Error(), gc.Equals, test.errText)
+		}
+	}
+}
+
+func (s *machineSuite) TestCreateVolumeGroup(c *gc.C) {
+	server, machine := s.getServerAndMachine(c)
+	server.AddPostResponse("/api/2.0/nodes/4y3ha3/volume-groups/?op=", http.StatusOK, volumeGroupResponse)
+	volumeGroup, err := machine.CreateVolumeGroup(CreateVolumeGroupArgs{
+		Name:       "vgroot",
+		Partitions: []Partition{machine.Partition(101)},
+	})
+	c.Assert(err, jc.ErrorIsNil)
+	c.Check(volumeGroup.Name(), gc.Equals, "vgroot")
+
+	form := server.LastRequest().PostForm
+	c.Check(form.Get("name"), gc.Equals, "vgroot")
+	c.Check(form["partitions"], jc.DeepEquals, []string{"101"})
+	_, found := form["block_devices"]
+	c.Check(found, jc.IsFalse)
+}
+
+func (s *machineSuite) TestCreateVolumeGroupValidates(c *gc.C) {
+	server, machine := s.getServerAndMachine(c)
+	_, err := machine.CreateVolumeGroup(CreateVolumeGroupArgs{})
+	c.Assert(err, jc.Satisfies, errors.IsNotValid)
+	c.Assert(server.RequestCount(), gc.Equals, 0)
+}
+
+func (s *machineSuite) TestVMFSDatastores(c *gc.C) {
+	server, machine := s.getServerAndMachine(c)
+	server.AddGetResponse("/api/2.0/nodes/4y3ha3/vmfs-datastores/", http.StatusOK, vmfsDatastoresResponse)
+	datastores, err := machine.VMFSDatastores()
+	c.Assert(err, jc.ErrorIsNil)
+	c.Assert(datastores, gc.HasLen, 1)
+	c.Check(datastores[0].Name(), gc.Equals, "datastore1")
+}
+
+func (s *machineSuite) TestCreateVMFSDatastoreArgsValidate(c *gc.C) {
+	for i, test := range []struct {
+		args    CreateVMFSDatastoreArgs
+		errText string
+	}{{
+		errText: "missing Name not valid",
+	}, {
+		args:    CreateVMFSDatastoreArgs{Name: "datastore1"},
+		errText: "missing Devices and Partitions not valid",
+	}, {
+		args: CreateVMFSDatastoreArgs{Name: "datastore1", Partitions: []Partition{&partition{id: 1}}},
+	}} {
+		c.Logf("test %d", i)
+		err := test.args.Validate()
+		if test.errText == "" {
+			c.Check(err, jc.ErrorIsNil)
+		} else {
+			c.Check(err, jc.Satisfies, errors.IsNotValid)
+			c.Check(err.Error(), gc.Equals, test.errText)
+		}
+	}
+}
+
+func (s *machineSuite) TestCreateVMFSDatastore(c *gc.C) {
+	server, machine := s.getServerAndMachine(c)
+	server.AddPostResponse("/api/2.0/nodes/4y3ha3/vmfs-datastores/?op=", http.StatusOK, vmfsDatastoreResponse)
+	datastore, err := machine.CreateVMFSDatastore(CreateVMFSDatastoreArgs{
+		Name:       "datastore1",
+		Partitions: []Partition{machine.Partition(101)},
+	})
+	c.Assert(err, jc.ErrorIsNil)
+	c.Check(datastore.Name(), gc.Equals, "datastore1")
+
+	form := server.LastRequest().PostForm
+	c.Check(form.Get("name"), gc.Equals, "datastore1")
+	c.Check(form["partitions"], jc.DeepEquals, []string{"101"})
+	_, found := form["block_devices"]
+	c.Check(found, jc.IsFalse)
+}
+
+func (s *machineSuite) TestCreateVMFSDatastoreValidates(c *gc.C) {
+	server, machine := s.getServerAndMachine(c)
+	_, err := machine.CreateVMFSDatastore(CreateVMFSDatastoreArgs{})
+	c.Assert(err, jc.Satisfies, errors.IsNotValid)
+	c.Assert(server.RequestCount(), gc.Equals, 0)
+}
+
 func (s *machineSuite) TestDevices(c *gc.C) {
 	server, machine := s.getServerAndMachine(c)
 	server.AddGetResponse("/api/2.0/devices/", http.StatusOK, devicesResponse)
@@ -540,6 +1545,11 @@ const (
                 "partition_table_type": null,
                 "model": null,
                 "id_path": null,
+                "parent": {
+                    "id": 2,
+                    "uuid": "a8ea1e2c-3a8d-4ea6-9c9f-5e8ba1e0b5a4",
+                    "type": "raid-0"
+                },
                 "resource_uri": "/MAAS/api/2.0/nodes/xc3e6q/blockdevices/23/"
             }
          ],
@@ -793,8 +1803,11 @@ const (
             ]
         },
         "power_state": "on",
+        "netboot": false,
+        "ephemeral_deploy": false,
         "architecture": "amd64/generic",
         "power_type": "virsh",
+        "bios_boot_method": "pxe",
         "distro_series": "trusty",
         "tag_names": [
            "virtual", "magic"
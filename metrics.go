@@ -0,0 +1,114 @@
+// Copyright 2022 Canonical Ltd.
+// Licensed under the LGPLv3, see LICENCE file for details.
+
+package gomaasapi
+
+import (
+	"net/http"
+	"strings"
+	"time"
+)
+
+// RequestErrorClass classifies how a request to MAAS failed.
+type RequestErrorClass string
+
+const (
+	// RequestSucceeded is the class of requests with a 2XX response.
+	RequestSucceeded RequestErrorClass = ""
+	// RequestClientError is the class of requests with a 4XX response.
+	RequestClientError RequestErrorClass = "client"
+	// RequestServerError is the class of requests with a 5XX response, or
+	// any other unexpected status.
+	RequestServerError RequestErrorClass = "server"
+	// RequestTransportError is the class of requests that got no response,
+	// for instance because the connection failed or the request was
+	// cancelled.
+	RequestTransportError RequestErrorClass = "transport"
+)
+
+// RequestMetric describes a request sent to MAAS. Each retry of a request
+// is a separate request.
+type RequestMetric struct {
+	// Method is the HTTP method of the request.
+	Method string
+	// Endpoint is the path of the request relative to the API version,
+	// with the identifiers replaced by "{id}", such as "machines/{id}/".
+	Endpoint string
+	// Op is the op parameter of the request, if any.
+	Op string
+	// StatusCode is the status of the response, or 0 if there was none.
+	StatusCode int
+	// ErrorClass classifies the failure of the request.
+	ErrorClass RequestErrorClass
+	// Duration is the time until the response headers were received.
+	Duration time.Duration
+}
+
+// MetricsSink records metrics of the requests sent to MAAS, for instance
+// to export them to Prometheus. ObserveRequest is called concurrently if
+// requests are.
+type MetricsSink interface {
+	ObserveRequest(metric RequestMetric)
+}
+
+// metricsNow is patched by tests.
+var metricsNow = time.Now
+
+// metricsMiddleware returns a middleware recording the requests to the sink.
+func metricsMiddleware(sink MetricsSink) Middleware {
+	return func(next Doer) Doer {
+		return DoerFunc(func(request *http.Request) (*http.Response, error) {
+			start := metricsNow()
+			response, err := next.Do(request)
+			metric := RequestMetric{
+				Method:     request.Method,
+				Endpoint:   metricsEndpoint(request.URL.Path),
+				Op:         request.URL.Query().Get("op"),
+				Duration:   metricsNow().Sub(start),
+				ErrorClass: RequestTransportError,
+			}
+			if err == nil {
+				metric.StatusCode = response.StatusCode
+				metric.ErrorClass = requestErrorClass(response.StatusCode)
+			}
+			sink.ObserveRequest(metric)
+			return response, err
+		})
+	}
+}
+
+func requestErrorClass(statusCode int) RequestErrorClass {
+	switch {
+	case statusCode >= 200 && statusCode <= 299:
+		return RequestSucceeded
+	case statusCode >= 400 && statusCode <= 499:
+		return RequestClientError
+	default:
+		return RequestServerError
+	}
+}
+
+// metricsEndpoint returns the path relative to the API version, with the
+// identifiers replaced so that the number of endpoints is bounded. MAAS
+// paths alternate between collections and identifiers, such as
+// "fabrics/1/vlans/2/".
+func metricsEndpoint(path string) string {
+	if i := strings.Index(path, "/api/"); i >= 0 {
+		path = path[i+len("/api/"):]
+		// Skip the version.
+		if i := strings.Index(path, "/"); i >= 0 {
+			path = path[i+1:]
+		} else {
+			path = ""
+		}
+	}
+	segments := strings.Split(strings.Trim(path, "/"), "/")
+	for i := 1; i < len(segments); i += 2 {
+		segments[i] = "{id}"
+	}
+	endpoint := strings.Join(segments, "/")
+	if endpoint == "" {
+		return ""
+	}
+	return endpoint + "/"
+}
@@ -0,0 +1,115 @@
+// Copyright 2022 Canonical Ltd.
+// Licensed under the LGPLv3, see LICENCE file for details.
+
+package gomaasapi
+
+import (
+	"net/http"
+	"time"
+
+	"github.com/juju/testing"
+	jc "github.com/juju/testing/checkers"
+	gc "gopkg.in/check.v1"
+)
+
+type metricsSuite struct {
+	testing.LoggingCleanupSuite
+}
+
+var _ = gc.Suite(&metricsSuite{})
+
+type recordingSink struct {
+	metrics []RequestMetric
+}
+
+func (s *recordingSink) ObserveRequest(metric RequestMetric) {
+	s.metrics = append(s.metrics, metric)
+}
+
+func (*metricsSuite) TestMetricsEndpoint(c *gc.C) {
+	for i, test := range []struct {
+		path     string
+		expected string
+	}{
+		{"/MAAS/api/2.0/machines/", "machines/"},
+		{"/MAAS/api/2.0/machines/4y3ha3/", "machines/{id}/"},
+		{"/api/2.0/fabrics/1/vlans/2/", "fabrics/{id}/vlans/{id}/"},
+		{"/api/2.0/nodes/4y3ha3/blockdevices/3/partition/4", "nodes/{id}/blockdevices/{id}/partition/{id}/"},
+		{"/api/2.0/version/", "version/"},
+		{"/api/2.0/", ""},
+		{"/api/2.0", ""},
+		{"/tags/virtual/", "tags/{id}/"},
+	} {
+		c.Logf("test %d: %s", i, test.path)
+		c.Check(metricsEndpoint(test.path), gc.Equals, test.expected)
+	}
+}
+
+func (*metricsSuite) TestRequestErrorClass(c *gc.C) {
+	c.Check(requestErrorClass(http.StatusOK), gc.Equals, RequestSucceeded)
+	c.Check(requestErrorClass(http.StatusNoContent), gc.Equals, RequestSucceeded)
+	c.Check(requestErrorClass(http.StatusNotFound), gc.Equals, RequestClientError)
+	c.Check(requestErrorClass(http.StatusServiceUnavailable), gc.Equals, RequestServerError)
+	c.Check(requestErrorClass(http.StatusFound), gc.Equals, RequestServerError)
+}
+
+func (s *metricsSuite) TestControllerMetrics(c *gc.C) {
+	now := time.Date(2022, 1, 1, 0, 0, 0, 0, time.UTC)
+	s.PatchValue(&metricsNow, func() time.Time {
+		now = now.Add(time.Second)
+		return now
+	})
+	server := NewSimpleServer()
+	server.AddGetResponse("/api/2.0/users/?op=whoami", http.StatusOK, `"captain awesome"`)
+	server.AddGetResponse("/api/2.0/version/", http.StatusOK, versionResponse)
+	server.AddGetResponse("/api/2.0/machines/4y3ha3/", http.StatusNotFound, "no such machine")
+	server.Start()
+	s.AddCleanup(func(*gc.C) { server.Close() })
+
+	sink := &recordingSink{}
+	maas, err := NewController(ControllerArgs{
+		BaseURL: server.URL,
+		APIKey:  "fake:as:key",
+		Metrics: sink,
+	})
+	c.Assert(err, jc.ErrorIsNil)
+	_, err = maas.(*controller).get("machines/4y3ha3")
+	c.Assert(err, gc.NotNil)
+
+	c.Check(sink.metrics, jc.DeepEquals, []RequestMetric{{
+		Method:     "GET",
+		Endpoint:   "version/",
+		StatusCode: http.StatusOK,
+		Duration:   time.Second,
+	}, {
+		Method:     "GET",
+		Endpoint:   "users/",
+		Op:         "whoami",
+		StatusCode: http.StatusOK,
+		Duration:   time.Second,
+	}, {
+		Method:     "GET",
+		Endpoint:   "machines/{id}/",
+		StatusCode: http.StatusNotFound,
+		ErrorClass: RequestClientError,
+		Duration:   time.Second,
+	}})
+}
+
+func (s *metricsSuite) TestTransportError(c *gc.C) {
+	sink := &recordingSink{}
+	client, err := NewAnonymousClient("http://127.0.0.1:1/MAAS/", "2.0")
+	c.Assert(err, jc.ErrorIsNil)
+	client.AddMiddleware(metricsMiddleware(sink))
+	request, err := http.NewRequest("POST", "http://127.0.0.1:1/MAAS/api/2.0/machines/?op=allocate", nil)
+	c.Assert(err, jc.ErrorIsNil)
+
+	_, err = client.dispatchRequest(request)
+	c.Assert(err, gc.NotNil)
+	c.Assert(sink.metrics, gc.HasLen, 1)
+	c.Check(sink.metrics[0].Method, gc.Equals, "POST")
+	c.Check(sink.metrics[0].Endpoint, gc.Equals, "machines/")
+	c.Check(sink.metrics[0].Op, gc.Equals, "allocate")
+	c.Check(sink.metrics[0].StatusCode, gc.Equals, 0)
+	c.Check(sink.metrics[0].ErrorClass, gc.Equals, RequestTransportError)
+}
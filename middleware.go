@@ -0,0 +1,67 @@
+// Copyright 2022 Canonical Ltd.
+// Licensed under the LGPLv3, see LICENCE file for details.
+
+package gomaasapi
+
+import (
+	"net/http"
+)
+
+// Doer sends HTTP requests to MAAS. It is implemented by *http.Client.
+type Doer interface {
+	Do(request *http.Request) (*http.Response, error)
+}
+
+// DoerFunc is an adapter to use a function as a Doer.
+type DoerFunc func(request *http.Request) (*http.Response, error)
+
+// Do implements Doer.
+func (f DoerFunc) Do(request *http.Request) (*http.Response, error) {
+	return f(request)
+}
+
+// Middleware wraps the Doer that sends the requests to MAAS, for instance to
+// log the requests and their responses, or to add headers. It is called with
+// the next Doer of the chain, and returns the Doer that is used instead.
+//
+// The requests passed to the returned Doer are already signed, and each
+// retry of a request goes through the chain again.
+type Middleware func(next Doer) Doer
+
+// middlewareChain is the Doer built from the middleware of a client.
+type middlewareChain struct {
+	middleware []Middleware
+	doer       Doer
+}
+
+// Do implements Doer.
+func (c *middlewareChain) Do(request *http.Request) (*http.Response, error) {
+	return c.doer.Do(request)
+}
+
+// AddMiddleware adds middleware wrapping the HTTP client of the client,
+// after any middleware already added. The chain is built when the
+// middleware is added, around the HTTPClient of the client at that time,
+// so HTTPClient should be set first. Copies of the client made before the
+// call aren't affected.
+func (client *Client) AddMiddleware(middleware ...Middleware) {
+	chain := &middlewareChain{doer: client.httpClient()}
+	if client.middleware != nil {
+		chain.middleware = append(chain.middleware, client.middleware.middleware...)
+	}
+	chain.middleware = append(chain.middleware, middleware...)
+	// The first middleware is the outermost, so it sees the requests
+	// first.
+	for i := len(chain.middleware) - 1; i >= 0; i-- {
+		chain.doer = chain.middleware[i](chain.doer)
+	}
+	client.middleware = chain
+}
+
+// doer returns the HTTP client of the client wrapped by its middleware.
+func (client Client) doer() Doer {
+	if client.middleware == nil {
+		return client.httpClient()
+	}
+	return client.middleware
+}
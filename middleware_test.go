@@ -0,0 +1,139 @@
+// Copyright 2022 Canonical Ltd.
+// Licensed under the LGPLv3, see LICENCE file for details.
+
+package gomaasapi
+
+import (
+	"net/http"
+	"net/url"
+
+	"github.com/juju/errors"
+	"github.com/juju/testing"
+	jc "github.com/juju/testing/checkers"
+	gc "gopkg.in/check.v1"
+)
+
+type middlewareSuite struct {
+	testing.LoggingCleanupSuite
+}
+
+var _ = gc.Suite(&middlewareSuite{})
+
+// recordingMiddleware returns a middleware appending the name to calls
+// around each request.
+func recordingMiddleware(name string, calls *[]string) Middleware {
+	return func(next Doer) Doer {
+		return DoerFunc(func(request *http.Request) (*http.Response, error) {
+			*calls = append(*calls, name+" before")
+			response, err := next.Do(request)
+			*calls = append(*calls, name+" after")
+			return response, err
+		})
+	}
+}
+
+func (*middlewareSuite) TestOrder(c *gc.C) {
+	server := newFlakyServer("/some/url/", http.StatusOK, 1)
+	defer server.Close()
+	client, err := NewAnonymousClient(server.URL, "1.0")
+	c.Assert(err, jc.ErrorIsNil)
+	var calls []string
+	client.AddMiddleware(recordingMiddleware("first", &calls))
+	copied := *client
+	client.AddMiddleware(recordingMiddleware("second", &calls))
+
+	_, err = client.Get(&url.URL{Path: "/some/url/"}, "", nil)
+	c.Assert(err, jc.ErrorIsNil)
+	c.Check(calls, jc.DeepEquals, []string{"first before", "second before", "second after", "first after"})
+
+	// Copies made before adding middleware don't use it.
+	calls = nil
+	_, err = copied.Get(&url.URL{Path: "/some/url/"}, "", nil)
+	c.Assert(err, jc.ErrorIsNil)
+	c.Check(calls, jc.DeepEquals, []string{"first before", "first after"})
+}
+
+func (*middlewareSuite) TestChainBuiltOnce(c *gc.C) {
+	server := newFlakyServer("/some/url/", http.StatusOK, 3)
+	defer server.Close()
+	client, err := NewAnonymousClient(server.URL, "1.0")
+	c.Assert(err, jc.ErrorIsNil)
+	built := 0
+	client.AddMiddleware(func(next Doer) Doer {
+		built++
+		return next
+	})
+	c.Check(built, gc.Equals, 1)
+
+	for i := 0; i < 3; i++ {
+		_, err = client.Get(&url.URL{Path: "/some/url/"}, "", nil)
+		c.Assert(err, jc.ErrorIsNil)
+	}
+	c.Check(built, gc.Equals, 1)
+}
+
+func (*middlewareSuite) TestRetriesGoThroughMiddleware(c *gc.C) {
+	server := newFlakyServer("/some/url/", http.StatusServiceUnavailable, 2)
+	defer server.Close()
+	client, err := NewAnonymousClient(server.URL, "1.0")
+	c.Assert(err, jc.ErrorIsNil)
+	var statuses []int
+	client.AddMiddleware(func(next Doer) Doer {
+		return DoerFunc(func(request *http.Request) (*http.Response, error) {
+			response, err := next.Do(request)
+			if err == nil {
+				statuses = append(statuses, response.StatusCode)
+			}
+			return response, err
+		})
+	})
+
+	_, err = client.Get(&url.URL{Path: "/some/url/"}, "", nil)
+	c.Assert(err, jc.ErrorIsNil)
+	c.Check(statuses, jc.DeepEquals, []int{503, 503, 200})
+}
+
+func (*middlewareSuite) TestShortCircuit(c *gc.C) {
+	client, err := NewAnonymousClient("http://maas.invalid/", "1.0")
+	c.Assert(err, jc.ErrorIsNil)
+	client.AddMiddleware(func(next Doer) Doer {
+		return DoerFunc(func(request *http.Request) (*http.Response, error) {
+			return nil, errors.New("offline")
+		})
+	})
+
+	_, err = client.Get(&url.URL{Path: "/some/url/"}, "", nil)
+	c.Assert(err, gc.ErrorMatches, "offline")
+}
+
+func (s *middlewareSuite) TestControllerMiddleware(c *gc.C) {
+	server := NewSimpleServer()
+	server.AddGetResponse("/api/2.0/users/?op=whoami", http.StatusOK, `"captain awesome"`)
+	server.AddGetResponse("/api/2.0/version/", http.StatusOK, versionResponse)
+	server.AddGetResponse("/api/2.0/zones/", http.StatusOK, zoneResponse)
+	server.Start()
+	s.AddCleanup(func(*gc.C) { server.Close() })
+
+	var paths []string
+	controller, err := NewController(ControllerArgs{
+		BaseURL: server.URL,
+		APIKey:  "fake:as:key",
+		Middleware: []Middleware{func(next Doer) Doer {
+			return DoerFunc(func(request *http.Request) (*http.Response, error) {
+				paths = append(paths, request.URL.Path)
+				request.Header.Set("X-Request-Source", "test")
+				return next.Do(request)
+			})
+		}},
+	})
+	c.Assert(err, jc.ErrorIsNil)
+	c.Check(paths, jc.DeepEquals, []string{"/api/2.0/version/", "/api/2.0/users/"})
+
+	// Controllers with other credentials keep the middleware.
+	admin, err := controller.WithAPIKey("admin:token:secret")
+	c.Assert(err, jc.ErrorIsNil)
+	_, err = admin.Zones()
+	c.Assert(err, jc.ErrorIsNil)
+	c.Check(paths, gc.HasLen, 3)
+	c.Check(server.LastRequest().Header.Get("X-Request-Source"), gc.Equals, "test")
+}
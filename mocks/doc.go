@@ -0,0 +1,25 @@
+// Copyright 2022 Canonical Ltd.
+// Licensed under the LGPLv3, see LICENCE file for details.
+
+// Package mocks provides mocks of the interfaces of the gomaasapi package,
+// such as Controller and Machine, so that projects using it don't need to
+// maintain their own. Each mock has a function field for each method of
+// the interface, which the method calls:
+//
+//	machine := &mocks.Machine{
+//		SystemIDFunc: func() string { return "abc123" },
+//	}
+//	controller := &mocks.Controller{
+//		MachinesFunc: func(args gomaasapi.MachinesArgs) ([]gomaasapi.Machine, error) {
+//			return []gomaasapi.Machine{machine}, nil
+//		},
+//	}
+//
+// Calling a method whose function isn't set panics, so that unexpected calls
+// fail the tests. The calls are recorded, see Calls.
+//
+// The mocks are generated from the interfaces, and must be regenerated with
+// go generate when they change.
+package mocks
+
+//go:generate go run generate.go
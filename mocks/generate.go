@@ -0,0 +1,290 @@
+// Copyright 2022 Canonical Ltd.
+// Licensed under the LGPLv3, see LICENCE file for details.
+
+//go:build ignore
+// +build ignore
+
+// generate writes mocks.go, with a mock for each of the interfaces declared
+// in the interfaces.go file of the gomaasapi package. It is run by go
+// generate in this directory.
+package main
+
+import (
+	"bytes"
+	"fmt"
+	"go/ast"
+	"go/format"
+	"go/parser"
+	"go/token"
+	"io/ioutil"
+	"log"
+	"sort"
+	"strconv"
+	"strings"
+)
+
+const (
+	source = "../interfaces.go"
+	target = "mocks.go"
+	// self is the import path of the mocked package.
+	self = "github.com/juju/gomaasapi/v2"
+)
+
+// method is a method of an interface, with the types of its parameters and
+// results written as in the mocks package.
+type method struct {
+	name     string
+	params   []string
+	types    []string
+	variadic bool
+	results  []string
+}
+
+func main() {
+	fset := token.NewFileSet()
+	file, err := parser.ParseFile(fset, source, nil, 0)
+	if err != nil {
+		log.Fatal(err)
+	}
+	imports := make(map[string]string)
+	for _, spec := range file.Imports {
+		path, _ := strconv.Unquote(spec.Path.Value)
+		name := path[strings.LastIndex(path, "/")+1:]
+		if spec.Name != nil {
+			name = spec.Name.Name
+		}
+		imports[name] = path
+	}
+
+	interfaces := make(map[string]*ast.InterfaceType)
+	var names []string
+	for _, decl := range file.Decls {
+		decl, ok := decl.(*ast.GenDecl)
+		if !ok || decl.Tok != token.TYPE {
+			continue
+		}
+		for _, spec := range decl.Specs {
+			spec := spec.(*ast.TypeSpec)
+			if iface, ok := spec.Type.(*ast.InterfaceType); ok && spec.Name.IsExported() {
+				interfaces[spec.Name.Name] = iface
+				names = append(names, spec.Name.Name)
+			}
+		}
+	}
+
+	g := &generator{
+		fset:       fset,
+		interfaces: interfaces,
+		methods:    make(map[string][]method),
+		used:       map[string]bool{"gomaasapi": true},
+	}
+	var body bytes.Buffer
+	for _, name := range names {
+		methods := append([]method(nil), g.interfaceMethods(name)...)
+		sort.Slice(methods, func(i, j int) bool { return methods[i].name < methods[j].name })
+		writeMock(&body, name, methods)
+	}
+
+	var out bytes.Buffer
+	fmt.Fprintf(&out, "// Code generated by generate.go from %s; DO NOT EDIT.\n\n", source[strings.LastIndex(source, "/")+1:])
+	out.WriteString("package mocks\n\nimport (\n")
+	var used []string
+	for name := range g.used {
+		used = append(used, name)
+	}
+	sort.Strings(used)
+	// The standard packages come first, as in the rest of the module.
+	var standard, others []string
+	for _, name := range used {
+		switch path := imports[name]; {
+		case name == "gomaasapi":
+			others = append(others, fmt.Sprintf("gomaasapi %q", self))
+		case strings.Contains(path, "."):
+			others = append(others, strconv.Quote(path))
+		default:
+			standard = append(standard, strconv.Quote(path))
+		}
+	}
+	fmt.Fprintf(&out, "\t%s\n\n\t%s\n", strings.Join(standard, "\n\t"), strings.Join(others, "\n\t"))
+	out.WriteString(")\n")
+	out.Write(body.Bytes())
+
+	formatted, err := format.Source(out.Bytes())
+	if err != nil {
+		log.Fatalf("formatting %s: %v", target, err)
+	}
+	if err := ioutil.WriteFile(target, formatted, 0644); err != nil {
+		log.Fatal(err)
+	}
+}
+
+type generator struct {
+	fset       *token.FileSet
+	interfaces map[string]*ast.InterfaceType
+	// methods caches the methods of the interfaces, whose declarations are
+	// rewritten as they are read.
+	methods map[string][]method
+	// used holds the names of the packages the mocks refer to.
+	used map[string]bool
+}
+
+// interfaceMethods returns the methods of the interface, including the ones
+// of the interfaces it embeds.
+func (g *generator) interfaceMethods(name string) []method {
+	if result, ok := g.methods[name]; ok {
+		return result
+	}
+	var result []method
+	for _, field := range g.interfaces[name].Methods.List {
+		switch typ := field.Type.(type) {
+		case *ast.Ident:
+			if _, ok := g.interfaces[typ.Name]; !ok {
+				log.Fatalf("%s embeds %s, which isn't declared in %s", name, typ.Name, source)
+			}
+			result = append(result, g.interfaceMethods(typ.Name)...)
+		case *ast.FuncType:
+			result = append(result, g.method(field.Names[0].Name, typ))
+		default:
+			log.Fatalf("%s embeds an unsupported %T", name, typ)
+		}
+	}
+	g.methods[name] = result
+	return result
+}
+
+func (g *generator) method(name string, typ *ast.FuncType) method {
+	m := method{name: name}
+	for _, field := range typ.Params.List {
+		if ellipsis, ok := field.Type.(*ast.Ellipsis); ok {
+			m.variadic = true
+			field.Type = &ast.ArrayType{Elt: ellipsis.Elt}
+		}
+		paramType := g.typeString(field.Type)
+		if len(field.Names) == 0 {
+			m.params = append(m.params, "")
+			m.types = append(m.types, paramType)
+		}
+		for _, ident := range field.Names {
+			m.params = append(m.params, ident.Name)
+			m.types = append(m.types, paramType)
+		}
+	}
+	for i, param := range m.params {
+		// The receiver of the mocks is m.
+		if param == "" || param == "_" || param == "m" {
+			m.params[i] = fmt.Sprintf("arg%d", i)
+		}
+	}
+	if typ.Results != nil {
+		for _, field := range typ.Results.List {
+			resultType := g.typeString(field.Type)
+			for i := 0; i < len(field.Names) || i == 0 && len(field.Names) == 0; i++ {
+				m.results = append(m.results, resultType)
+			}
+		}
+	}
+	return m
+}
+
+// typeString returns the type as written in the mocks package, where the
+// types of the gomaasapi package are qualified.
+func (g *generator) typeString(expr ast.Expr) string {
+	var qualify func(ast.Node) bool
+	qualify = func(node ast.Node) bool {
+		switch node := node.(type) {
+		case *ast.Field:
+			// The names of the parameters of function types aren't types.
+			ast.Inspect(node.Type, qualify)
+			return false
+		case *ast.SelectorExpr:
+			g.used[node.X.(*ast.Ident).Name] = true
+			return false
+		case *ast.Ident:
+			if node.IsExported() {
+				node.Name = "gomaasapi." + node.Name
+			} else if !builtin(node.Name) {
+				log.Fatalf("%s: unexported type %s", g.fset.Position(node.Pos()), node.Name)
+			}
+		}
+		return true
+	}
+	ast.Inspect(expr, qualify)
+	var b bytes.Buffer
+	if err := format.Node(&b, g.fset, expr); err != nil {
+		log.Fatal(err)
+	}
+	return b.String()
+}
+
+func builtin(name string) bool {
+	switch name {
+	case "bool", "byte", "error", "float32", "float64", "int", "int8", "int16", "int32", "int64",
+		"interface", "rune", "string", "uint", "uint8", "uint16", "uint32", "uint64", "uintptr":
+		return true
+	}
+	return false
+}
+
+func writeMock(w *bytes.Buffer, name string, methods []method) {
+	fmt.Fprintf(w, "\n// %s is a mock of gomaasapi.%s.\n", name, name)
+	fmt.Fprintf(w, "type %s struct {\n", name)
+	for _, m := range methods {
+		fmt.Fprintf(w, "\t%sFunc func(%s) %s\n", m.name, m.signature(), m.resultList())
+	}
+	fmt.Fprintf(w, "\n\trecorder\n}\n\n")
+	fmt.Fprintf(w, "var _ gomaasapi.%s = (*%s)(nil)\n", name, name)
+	for _, m := range methods {
+		fmt.Fprintf(w, "\n// %s calls %sFunc.\n", m.name, m.name)
+		fmt.Fprintf(w, "func (m *%s) %s(%s) %s {\n", name, m.name, m.namedSignature(), m.resultList())
+		fmt.Fprintf(w, "\tm.record(%q%s)\n", m.name, prefixed(m.params))
+		fmt.Fprintf(w, "\tif m.%sFunc == nil {\n", m.name)
+		fmt.Fprintf(w, "\t\tpanic(%q)\n", fmt.Sprintf("mocks.%s.%s called without %sFunc", name, m.name, m.name))
+		fmt.Fprintf(w, "\t}\n\t")
+		if len(m.results) > 0 {
+			w.WriteString("return ")
+		}
+		args := strings.Join(m.params, ", ")
+		if m.variadic {
+			args += "..."
+		}
+		fmt.Fprintf(w, "m.%sFunc(%s)\n}\n", m.name, args)
+	}
+}
+
+func (m method) paramType(i int) string {
+	if m.variadic && i == len(m.types)-1 {
+		return "..." + strings.TrimPrefix(m.types[i], "[]")
+	}
+	return m.types[i]
+}
+
+func (m method) signature() string {
+	var types []string
+	for i := range m.types {
+		types = append(types, m.paramType(i))
+	}
+	return strings.Join(types, ", ")
+}
+
+func (m method) namedSignature() string {
+	var params []string
+	for i, param := range m.params {
+		params = append(params, param+" "+m.paramType(i))
+	}
+	return strings.Join(params, ", ")
+}
+
+func (m method) resultList() string {
+	if len(m.results) < 2 {
+		return strings.Join(m.results, "")
+	}
+	return "(" + strings.Join(m.results, ", ") + ")"
+}
+
+func prefixed(params []string) string {
+	var b strings.Builder
+	for _, param := range params {
+		b.WriteString(", " + param)
+	}
+	return b.String()
+}
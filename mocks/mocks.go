@@ -0,0 +1,6279 @@
+// Code generated by generate.go from interfaces.go; DO NOT EDIT.
+
+package mocks
+
+import (
+	"context"
+	"io"
+	"net/url"
+	"time"
+
+	"github.com/juju/collections/set"
+	gomaasapi "github.com/juju/gomaasapi/v2"
+)
+
+// Controller is a mock of gomaasapi.Controller.
+type Controller struct {
+	APIVersionInfoFunc            func() (string, string, error)
+	AddFileFunc                   func(gomaasapi.AddFileArgs) error
+	AddSSHKeyFunc                 func(string) (gomaasapi.SSHKey, error)
+	AddSSLKeyFunc                 func(string) (gomaasapi.SSLKey, error)
+	AllocateMachineFunc           func(gomaasapi.AllocateMachineArgs) (gomaasapi.Machine, gomaasapi.ConstraintMatches, error)
+	AuthorisationTokensFunc       func() ([]gomaasapi.AuthorisationToken, error)
+	BootResourcesFunc             func() ([]gomaasapi.BootResource, error)
+	BootSourcesFunc               func() ([]gomaasapi.BootSource, error)
+	CapabilitiesFunc              func() set.Strings
+	ChangeFeedFunc                func(context.Context, gomaasapi.ChangeFeedArgs) (gomaasapi.ChangeFeed, error)
+	CheckBootOrderFunc            func(gomaasapi.MachinesArgs) ([]gomaasapi.BootOrderWarning, error)
+	ClearDiscoveriesFunc          func(gomaasapi.ClearDiscoveriesArgs) error
+	ClearLookupCacheFunc          func()
+	CreateAuthorisationTokenFunc  func(string) (gomaasapi.AuthorisationToken, error)
+	CreateBootSourceFunc          func(gomaasapi.CreateBootSourceArgs) (gomaasapi.BootSource, error)
+	CreateDHCPSnippetFunc         func(gomaasapi.DHCPSnippetArgs) (gomaasapi.DHCPSnippet, error)
+	CreateDNSResourceFunc         func(gomaasapi.CreateDNSResourceArgs) (gomaasapi.DNSResource, error)
+	CreateDNSResourceRecordFunc   func(gomaasapi.CreateDNSResourceRecordArgs) (gomaasapi.DNSResourceRecord, error)
+	CreateDeviceFunc              func(gomaasapi.CreateDeviceArgs) (gomaasapi.Device, error)
+	CreateDomainFunc              func(gomaasapi.CreateDomainArgs) (gomaasapi.Domain, error)
+	CreateIPRangeFunc             func(gomaasapi.CreateIPRangeArgs) (gomaasapi.IPRange, error)
+	CreateLicenseKeyFunc          func(gomaasapi.CreateLicenseKeyArgs) (gomaasapi.LicenseKey, error)
+	CreateMachineDHCPSnippetFunc  func(gomaasapi.Machine, gomaasapi.DHCPSnippetArgs) (gomaasapi.DHCPSnippet, error)
+	CreatePackageRepositoryFunc   func(gomaasapi.PackageRepositoryArgs) (gomaasapi.PackageRepository, error)
+	CreatePoolFunc                func(gomaasapi.CreatePoolArgs) (gomaasapi.Pool, error)
+	CreateSpaceFunc               func(gomaasapi.CreateSpaceArgs) (gomaasapi.Space, error)
+	CreateSubnetFunc              func(gomaasapi.CreateSubnetArgs) (gomaasapi.Subnet, error)
+	CreateSubnetDHCPSnippetFunc   func(gomaasapi.Subnet, gomaasapi.DHCPSnippetArgs) (gomaasapi.DHCPSnippet, error)
+	CreateTagFunc                 func(gomaasapi.CreateTagArgs) (gomaasapi.Tag, error)
+	CreateUserFunc                func(gomaasapi.CreateUserArgs) (gomaasapi.User, error)
+	CreateVMHostFunc              func(gomaasapi.CreateVMHostArgs) (gomaasapi.VMHost, error)
+	CreateZoneFunc                func(gomaasapi.CreateZoneArgs) (gomaasapi.Zone, error)
+	DHCPSnippetsFunc              func() ([]gomaasapi.DHCPSnippet, error)
+	DNSResourceRecordsFunc        func(gomaasapi.DNSResourceRecordsArgs) ([]gomaasapi.DNSResourceRecord, error)
+	DNSResourcesFunc              func() ([]gomaasapi.DNSResource, error)
+	DebugLogFunc                  func() []gomaasapi.DebugExchange
+	DeleteAuthorisationTokenFunc  func(string) error
+	DeleteMachineDHCPSnippetsFunc func(gomaasapi.Machine) error
+	DeleteSubnetFunc              func(gomaasapi.DeleteSubnetArgs) error
+	DeleteSubnetDHCPSnippetsFunc  func(gomaasapi.Subnet) error
+	DeleteVLANFunc                func(gomaasapi.DeleteVLANArgs) error
+	DevicesFunc                   func(gomaasapi.DevicesArgs) ([]gomaasapi.Device, error)
+	DiscoveriesFunc               func() ([]gomaasapi.Discovery, error)
+	DoFunc                        func(string, string, string, url.Values) (gomaasapi.JSONObject, error)
+	DoContentFunc                 func(string, string, string, io.Reader) (gomaasapi.JSONObject, error)
+	DomainsFunc                   func() ([]gomaasapi.Domain, error)
+	EventsFunc                    func(gomaasapi.EventsArgs) (gomaasapi.EventsPage, error)
+	FabricByNameFunc              func(string) (gomaasapi.Fabric, error)
+	FabricsFunc                   func() ([]gomaasapi.Fabric, error)
+	FilesFunc                     func(string) ([]gomaasapi.File, error)
+	ForEachMachineFunc            func(context.Context, gomaasapi.ForEachMachineArgs, func(gomaasapi.Machine) error) error
+	GetConfigFunc                 func(string) (interface{}, error)
+	GetFileFunc                   func(string) (gomaasapi.File, error)
+	HostRecordsFunc               func(gomaasapi.Machine) ([]gomaasapi.HostRecord, error)
+	IPAddressesFunc               func(gomaasapi.IPAddressesArgs) ([]gomaasapi.IPAddress, error)
+	IPRangesFunc                  func() ([]gomaasapi.IPRange, error)
+	ImportBootResourcesFunc       func() error
+	ImportSSHKeysFunc             func(string) ([]gomaasapi.SSHKey, error)
+	IsImportingBootResourcesFunc  func() (bool, error)
+	LeaseMachineFunc              func(gomaasapi.Machine, gomaasapi.LeaseMachineArgs) (gomaasapi.Lease, error)
+	LeasesFunc                    func(gomaasapi.MachinesArgs) ([]gomaasapi.Lease, error)
+	LicenseKeysFunc               func() ([]gomaasapi.LicenseKey, error)
+	ListExpiredLeasesFunc         func() ([]gomaasapi.Lease, error)
+	MachineProxyFunc              func(gomaasapi.Machine) (string, error)
+	MachinesFunc                  func(gomaasapi.MachinesArgs) ([]gomaasapi.Machine, error)
+	MachinesChangedSinceFunc      func(time.Time) ([]gomaasapi.Machine, error)
+	MachinesPagerFunc             func(gomaasapi.MachinesArgs, int) (gomaasapi.MachinesPager, error)
+	PackageRepositoriesFunc       func() ([]gomaasapi.PackageRepository, error)
+	PoolsFunc                     func() ([]gomaasapi.Pool, error)
+	PowerTypesFunc                func() ([]gomaasapi.PowerType, error)
+	ProxySettingsFunc             func() (gomaasapi.ProxySettings, error)
+	RackControllerFunc            func(string) (gomaasapi.RackController, error)
+	RackControllersFunc           func(gomaasapi.RackControllersArgs) ([]gomaasapi.RackController, error)
+	RegionControllersFunc         func() ([]gomaasapi.RegionController, error)
+	ReleaseIPAddressFunc          func(string) error
+	ReleaseMachinesFunc           func(gomaasapi.ReleaseMachinesArgs) error
+	ReserveIPAddressFunc          func(gomaasapi.ReserveIPArgs) (gomaasapi.IPAddress, error)
+	SSHKeysFunc                   func() ([]gomaasapi.SSHKey, error)
+	SSLKeysFunc                   func() ([]gomaasapi.SSLKey, error)
+	ScanNetworksFunc              func(gomaasapi.ScanNetworksArgs) (gomaasapi.ScanResult, error)
+	ScriptsFunc                   func(gomaasapi.ScriptsArgs) ([]gomaasapi.Script, error)
+	ServerTimeFunc                func() (time.Time, error)
+	ServerVersionFunc             func() gomaasapi.ServerInfo
+	SetAPIKeyFunc                 func(string) error
+	SetConfigFunc                 func(string, string) error
+	SettingsFunc                  func() (gomaasapi.Settings, error)
+	SpaceByNameFunc               func(string) (gomaasapi.Space, error)
+	SpacesFunc                    func() ([]gomaasapi.Space, error)
+	StaticRoutesFunc              func() ([]gomaasapi.StaticRoute, error)
+	StopImportBootResourcesFunc   func() error
+	SubnetByCIDRFunc              func(string) (gomaasapi.Subnet, error)
+	SubnetByIDFunc                func(int) (gomaasapi.Subnet, error)
+	SubnetUsageFunc               func(gomaasapi.Subnet) (gomaasapi.ResourceUsage, error)
+	SubnetsFunc                   func() ([]gomaasapi.Subnet, error)
+	TagsFunc                      func() ([]gomaasapi.Tag, error)
+	UpdateSettingsFunc            func(gomaasapi.UpdateSettingsArgs) error
+	UploadBootResourceFunc        func(gomaasapi.UploadBootResourceArgs) (gomaasapi.BootResource, error)
+	UploadScriptFunc              func(gomaasapi.UploadScriptArgs) (gomaasapi.Script, error)
+	UsersFunc                     func() ([]gomaasapi.User, error)
+	VLANByIDFunc                  func(int) (gomaasapi.VLAN, error)
+	VLANUsageFunc                 func(gomaasapi.VLAN) (gomaasapi.ResourceUsage, error)
+	VMHostFunc                    func(int) (gomaasapi.VMHost, error)
+	VMHostsFunc                   func() ([]gomaasapi.VMHost, error)
+	VersionSkewFunc               func() (gomaasapi.VersionSkew, error)
+	WalkMachinesFunc              func(gomaasapi.MachinesArgs, func(gomaasapi.Machine) error) error
+	WatchFunc                     func(context.Context, gomaasapi.WatchArgs) (gomaasapi.Watcher, error)
+	WhoAmIFunc                    func() (gomaasapi.User, error)
+	WithAPIKeyFunc                func(string) (gomaasapi.Controller, error)
+	WithContextFunc               func(context.Context) gomaasapi.Controller
+	WithPriorityFunc              func(gomaasapi.Priority) gomaasapi.Controller
+	ZonesFunc                     func() ([]gomaasapi.Zone, error)
+
+	recorder
+}
+
+var _ gomaasapi.Controller = (*Controller)(nil)
+
+// APIVersionInfo calls APIVersionInfoFunc.
+func (m *Controller) APIVersionInfo() (string, string, error) {
+	m.record("APIVersionInfo")
+	if m.APIVersionInfoFunc == nil {
+		panic("mocks.Controller.APIVersionInfo called without APIVersionInfoFunc")
+	}
+	return m.APIVersionInfoFunc()
+}
+
+// AddFile calls AddFileFunc.
+func (m *Controller) AddFile(arg0 gomaasapi.AddFileArgs) error {
+	m.record("AddFile", arg0)
+	if m.AddFileFunc == nil {
+		panic("mocks.Controller.AddFile called without AddFileFunc")
+	}
+	return m.AddFileFunc(arg0)
+}
+
+// AddSSHKey calls AddSSHKeyFunc.
+func (m *Controller) AddSSHKey(key string) (gomaasapi.SSHKey, error) {
+	m.record("AddSSHKey", key)
+	if m.AddSSHKeyFunc == nil {
+		panic("mocks.Controller.AddSSHKey called without AddSSHKeyFunc")
+	}
+	return m.AddSSHKeyFunc(key)
+}
+
+// AddSSLKey calls AddSSLKeyFunc.
+func (m *Controller) AddSSLKey(key string) (gomaasapi.SSLKey, error) {
+	m.record("AddSSLKey", key)
+	if m.AddSSLKeyFunc == nil {
+		panic("mocks.Controller.AddSSLKey called without AddSSLKeyFunc")
+	}
+	return m.AddSSLKeyFunc(key)
+}
+
+// AllocateMachine calls AllocateMachineFunc.
+func (m *Controller) AllocateMachine(arg0 gomaasapi.AllocateMachineArgs) (gomaasapi.Machine, gomaasapi.ConstraintMatches, error) {
+	m.record("AllocateMachine", arg0)
+	if m.AllocateMachineFunc == nil {
+		panic("mocks.Controller.AllocateMachine called without AllocateMachineFunc")
+	}
+	return m.AllocateMachineFunc(arg0)
+}
+
+// AuthorisationTokens calls AuthorisationTokensFunc.
+func (m *Controller) AuthorisationTokens() ([]gomaasapi.AuthorisationToken, error) {
+	m.record("AuthorisationTokens")
+	if m.AuthorisationTokensFunc == nil {
+		panic("mocks.Controller.AuthorisationTokens called without AuthorisationTokensFunc")
+	}
+	return m.AuthorisationTokensFunc()
+}
+
+// BootResources calls BootResourcesFunc.
+func (m *Controller) BootResources() ([]gomaasapi.BootResource, error) {
+	m.record("BootResources")
+	if m.BootResourcesFunc == nil {
+		panic("mocks.Controller.BootResources called without BootResourcesFunc")
+	}
+	return m.BootResourcesFunc()
+}
+
+// BootSources calls BootSourcesFunc.
+func (m *Controller) BootSources() ([]gomaasapi.BootSource, error) {
+	m.record("BootSources")
+	if m.BootSourcesFunc == nil {
+		panic("mocks.Controller.BootSources called without BootSourcesFunc")
+	}
+	return m.BootSourcesFunc()
+}
+
+// Capabilities calls CapabilitiesFunc.
+func (m *Controller) Capabilities() set.Strings {
+	m.record("Capabilities")
+	if m.CapabilitiesFunc == nil {
+		panic("mocks.Controller.Capabilities called without CapabilitiesFunc")
+	}
+	return m.CapabilitiesFunc()
+}
+
+// ChangeFeed calls ChangeFeedFunc.
+func (m *Controller) ChangeFeed(ctx context.Context, args gomaasapi.ChangeFeedArgs) (gomaasapi.ChangeFeed, error) {
+	m.record("ChangeFeed", ctx, args)
+	if m.ChangeFeedFunc == nil {
+		panic("mocks.Controller.ChangeFeed called without ChangeFeedFunc")
+	}
+	return m.ChangeFeedFunc(ctx, args)
+}
+
+// CheckBootOrder calls CheckBootOrderFunc.
+func (m *Controller) CheckBootOrder(arg0 gomaasapi.MachinesArgs) ([]gomaasapi.BootOrderWarning, error) {
+	m.record("CheckBootOrder", arg0)
+	if m.CheckBootOrderFunc == nil {
+		panic("mocks.Controller.CheckBootOrder called without CheckBootOrderFunc")
+	}
+	return m.CheckBootOrderFunc(arg0)
+}
+
+// ClearDiscoveries calls ClearDiscoveriesFunc.
+func (m *Controller) ClearDiscoveries(arg0 gomaasapi.ClearDiscoveriesArgs) error {
+	m.record("ClearDiscoveries", arg0)
+	if m.ClearDiscoveriesFunc == nil {
+		panic("mocks.Controller.ClearDiscoveries called without ClearDiscoveriesFunc")
+	}
+	return m.ClearDiscoveriesFunc(arg0)
+}
+
+// ClearLookupCache calls ClearLookupCacheFunc.
+func (m *Controller) ClearLookupCache() {
+	m.record("ClearLookupCache")
+	if m.ClearLookupCacheFunc == nil {
+		panic("mocks.Controller.ClearLookupCache called without ClearLookupCacheFunc")
+	}
+	m.ClearLookupCacheFunc()
+}
+
+// CreateAuthorisationToken calls CreateAuthorisationTokenFunc.
+func (m *Controller) CreateAuthorisationToken(name string) (gomaasapi.AuthorisationToken, error) {
+	m.record("CreateAuthorisationToken", name)
+	if m.CreateAuthorisationTokenFunc == nil {
+		panic("mocks.Controller.CreateAuthorisationToken called without CreateAuthorisationTokenFunc")
+	}
+	return m.CreateAuthorisationTokenFunc(name)
+}
+
+// CreateBootSource calls CreateBootSourceFunc.
+func (m *Controller) CreateBootSource(arg0 gomaasapi.CreateBootSourceArgs) (gomaasapi.BootSource, error) {
+	m.record("CreateBootSource", arg0)
+	if m.CreateBootSourceFunc == nil {
+		panic("mocks.Controller.CreateBootSource called without CreateBootSourceFunc")
+	}
+	return m.CreateBootSourceFunc(arg0)
+}
+
+// CreateDHCPSnippet calls CreateDHCPSnippetFunc.
+func (m *Controller) CreateDHCPSnippet(arg0 gomaasapi.DHCPSnippetArgs) (gomaasapi.DHCPSnippet, error) {
+	m.record("CreateDHCPSnippet", arg0)
+	if m.CreateDHCPSnippetFunc == nil {
+		panic("mocks.Controller.CreateDHCPSnippet called without CreateDHCPSnippetFunc")
+	}
+	return m.CreateDHCPSnippetFunc(arg0)
+}
+
+// CreateDNSResource calls CreateDNSResourceFunc.
+func (m *Controller) CreateDNSResource(arg0 gomaasapi.CreateDNSResourceArgs) (gomaasapi.DNSResource, error) {
+	m.record("CreateDNSResource", arg0)
+	if m.CreateDNSResourceFunc == nil {
+		panic("mocks.Controller.CreateDNSResource called without CreateDNSResourceFunc")
+	}
+	return m.CreateDNSResourceFunc(arg0)
+}
+
+// CreateDNSResourceRecord calls CreateDNSResourceRecordFunc.
+func (m *Controller) CreateDNSResourceRecord(arg0 gomaasapi.CreateDNSResourceRecordArgs) (gomaasapi.DNSResourceRecord, error) {
+	m.record("CreateDNSResourceRecord", arg0)
+	if m.CreateDNSResourceRecordFunc == nil {
+		panic("mocks.Controller.CreateDNSResourceRecord called without CreateDNSResourceRecordFunc")
+	}
+	return m.CreateDNSResourceRecordFunc(arg0)
+}
+
+// CreateDevice calls CreateDeviceFunc.
+func (m *Controller) CreateDevice(arg0 gomaasapi.CreateDeviceArgs) (gomaasapi.Device, error) {
+	m.record("CreateDevice", arg0)
+	if m.CreateDeviceFunc == nil {
+		panic("mocks.Controller.CreateDevice called without CreateDeviceFunc")
+	}
+	return m.CreateDeviceFunc(arg0)
+}
+
+// CreateDomain calls CreateDomainFunc.
+func (m *Controller) CreateDomain(arg0 gomaasapi.CreateDomainArgs) (gomaasapi.Domain, error) {
+	m.record("CreateDomain", arg0)
+	if m.CreateDomainFunc == nil {
+		panic("mocks.Controller.CreateDomain called without CreateDomainFunc")
+	}
+	return m.CreateDomainFunc(arg0)
+}
+
+// CreateIPRange calls CreateIPRangeFunc.
+func (m *Controller) CreateIPRange(arg0 gomaasapi.CreateIPRangeArgs) (gomaasapi.IPRange, error) {
+	m.record("CreateIPRange", arg0)
+	if m.CreateIPRangeFunc == nil {
+		panic("mocks.Controller.CreateIPRange called without CreateIPRangeFunc")
+	}
+	return m.CreateIPRangeFunc(arg0)
+}
+
+// CreateLicenseKey calls CreateLicenseKeyFunc.
+func (m *Controller) CreateLicenseKey(arg0 gomaasapi.CreateLicenseKeyArgs) (gomaasapi.LicenseKey, error) {
+	m.record("CreateLicenseKey", arg0)
+	if m.CreateLicenseKeyFunc == nil {
+		panic("mocks.Controller.CreateLicenseKey called without CreateLicenseKeyFunc")
+	}
+	return m.CreateLicenseKeyFunc(arg0)
+}
+
+// CreateMachineDHCPSnippet calls CreateMachineDHCPSnippetFunc.
+func (m *Controller) CreateMachineDHCPSnippet(arg0 gomaasapi.Machine, arg1 gomaasapi.DHCPSnippetArgs) (gomaasapi.DHCPSnippet, error) {
+	m.record("CreateMachineDHCPSnippet", arg0, arg1)
+	if m.CreateMachineDHCPSnippetFunc == nil {
+		panic("mocks.Controller.CreateMachineDHCPSnippet called without CreateMachineDHCPSnippetFunc")
+	}
+	return m.CreateMachineDHCPSnippetFunc(arg0, arg1)
+}
+
+// CreatePackageRepository calls CreatePackageRepositoryFunc.
+func (m *Controller) CreatePackageRepository(arg0 gomaasapi.PackageRepositoryArgs) (gomaasapi.PackageRepository, error) {
+	m.record("CreatePackageRepository", arg0)
+	if m.CreatePackageRepositoryFunc == nil {
+		panic("mocks.Controller.CreatePackageRepository called without CreatePackageRepositoryFunc")
+	}
+	return m.CreatePackageRepositoryFunc(arg0)
+}
+
+// CreatePool calls CreatePoolFunc.
+func (m *Controller) CreatePool(arg0 gomaasapi.CreatePoolArgs) (gomaasapi.Pool, error) {
+	m.record("CreatePool", arg0)
+	if m.CreatePoolFunc == nil {
+		panic("mocks.Controller.CreatePool called without CreatePoolFunc")
+	}
+	return m.CreatePoolFunc(arg0)
+}
+
+// CreateSpace calls CreateSpaceFunc.
+func (m *Controller) CreateSpace(arg0 gomaasapi.CreateSpaceArgs) (gomaasapi.Space, error) {
+	m.record("CreateSpace", arg0)
+	if m.CreateSpaceFunc == nil {
+		panic("mocks.Controller.CreateSpace called without CreateSpaceFunc")
+	}
+	return m.CreateSpaceFunc(arg0)
+}
+
+// CreateSubnet calls CreateSubnetFunc.
+func (m *Controller) CreateSubnet(arg0 gomaasapi.CreateSubnetArgs) (gomaasapi.Subnet, error) {
+	m.record("CreateSubnet", arg0)
+	if m.CreateSubnetFunc == nil {
+		panic("mocks.Controller.CreateSubnet called without CreateSubnetFunc")
+	}
+	return m.CreateSubnetFunc(arg0)
+}
+
+// CreateSubnetDHCPSnippet calls CreateSubnetDHCPSnippetFunc.
+func (m *Controller) CreateSubnetDHCPSnippet(arg0 gomaasapi.Subnet, arg1 gomaasapi.DHCPSnippetArgs) (gomaasapi.DHCPSnippet, error) {
+	m.record("CreateSubnetDHCPSnippet", arg0, arg1)
+	if m.CreateSubnetDHCPSnippetFunc == nil {
+		panic("mocks.Controller.CreateSubnetDHCPSnippet called without CreateSubnetDHCPSnippetFunc")
+	}
+	return m.CreateSubnetDHCPSnippetFunc(arg0, arg1)
+}
+
+// CreateTag calls CreateTagFunc.
+func (m *Controller) CreateTag(arg0 gomaasapi.CreateTagArgs) (gomaasapi.Tag, error) {
+	m.record("CreateTag", arg0)
+	if m.CreateTagFunc == nil {
+		panic("mocks.Controller.CreateTag called without CreateTagFunc")
+	}
+	return m.CreateTagFunc(arg0)
+}
+
+// CreateUser calls CreateUserFunc.
+func (m *Controller) CreateUser(arg0 gomaasapi.CreateUserArgs) (gomaasapi.User, error) {
+	m.record("CreateUser", arg0)
+	if m.CreateUserFunc == nil {
+		panic("mocks.Controller.CreateUser called without CreateUserFunc")
+	}
+	return m.CreateUserFunc(arg0)
+}
+
+// CreateVMHost calls CreateVMHostFunc.
+func (m *Controller) CreateVMHost(arg0 gomaasapi.CreateVMHostArgs) (gomaasapi.VMHost, error) {
+	m.record("CreateVMHost", arg0)
+	if m.CreateVMHostFunc == nil {
+		panic("mocks.Controller.CreateVMHost called without CreateVMHostFunc")
+	}
+	return m.CreateVMHostFunc(arg0)
+}
+
+// CreateZone calls CreateZoneFunc.
+func (m *Controller) CreateZone(arg0 gomaasapi.CreateZoneArgs) (gomaasapi.Zone, error) {
+	m.record("CreateZone", arg0)
+	if m.CreateZoneFunc == nil {
+		panic("mocks.Controller.CreateZone called without CreateZoneFunc")
+	}
+	return m.CreateZoneFunc(arg0)
+}
+
+// DHCPSnippets calls DHCPSnippetsFunc.
+func (m *Controller) DHCPSnippets() ([]gomaasapi.DHCPSnippet, error) {
+	m.record("DHCPSnippets")
+	if m.DHCPSnippetsFunc == nil {
+		panic("mocks.Controller.DHCPSnippets called without DHCPSnippetsFunc")
+	}
+	return m.DHCPSnippetsFunc()
+}
+
+// DNSResourceRecords calls DNSResourceRecordsFunc.
+func (m *Controller) DNSResourceRecords(arg0 gomaasapi.DNSResourceRecordsArgs) ([]gomaasapi.DNSResourceRecord, error) {
+	m.record("DNSResourceRecords", arg0)
+	if m.DNSResourceRecordsFunc == nil {
+		panic("mocks.Controller.DNSResourceRecords called without DNSResourceRecordsFunc")
+	}
+	return m.DNSResourceRecordsFunc(arg0)
+}
+
+// DNSResources calls DNSResourcesFunc.
+func (m *Controller) DNSResources() ([]gomaasapi.DNSResource, error) {
+	m.record("DNSResources")
+	if m.DNSResourcesFunc == nil {
+		panic("mocks.Controller.DNSResources called without DNSResourcesFunc")
+	}
+	return m.DNSResourcesFunc()
+}
+
+// DebugLog calls DebugLogFunc.
+func (m *Controller) DebugLog() []gomaasapi.DebugExchange {
+	m.record("DebugLog")
+	if m.DebugLogFunc == nil {
+		panic("mocks.Controller.DebugLog called without DebugLogFunc")
+	}
+	return m.DebugLogFunc()
+}
+
+// DeleteAuthorisationToken calls DeleteAuthorisationTokenFunc.
+func (m *Controller) DeleteAuthorisationToken(tokenKey string) error {
+	m.record("DeleteAuthorisationToken", tokenKey)
+	if m.DeleteAuthorisationTokenFunc == nil {
+		panic("mocks.Controller.DeleteAuthorisationToken called without DeleteAuthorisationTokenFunc")
+	}
+	return m.DeleteAuthorisationTokenFunc(tokenKey)
+}
+
+// DeleteMachineDHCPSnippets calls DeleteMachineDHCPSnippetsFunc.
+func (m *Controller) DeleteMachineDHCPSnippets(arg0 gomaasapi.Machine) error {
+	m.record("DeleteMachineDHCPSnippets", arg0)
+	if m.DeleteMachineDHCPSnippetsFunc == nil {
+		panic("mocks.Controller.DeleteMachineDHCPSnippets called without DeleteMachineDHCPSnippetsFunc")
+	}
+	return m.DeleteMachineDHCPSnippetsFunc(arg0)
+}
+
+// DeleteSubnet calls DeleteSubnetFunc.
+func (m *Controller) DeleteSubnet(arg0 gomaasapi.DeleteSubnetArgs) error {
+	m.record("DeleteSubnet", arg0)
+	if m.DeleteSubnetFunc == nil {
+		panic("mocks.Controller.DeleteSubnet called without DeleteSubnetFunc")
+	}
+	return m.DeleteSubnetFunc(arg0)
+}
+
+// DeleteSubnetDHCPSnippets calls DeleteSubnetDHCPSnippetsFunc.
+func (m *Controller) DeleteSubnetDHCPSnippets(arg0 gomaasapi.Subnet) error {
+	m.record("DeleteSubnetDHCPSnippets", arg0)
+	if m.DeleteSubnetDHCPSnippetsFunc == nil {
+		panic("mocks.Controller.DeleteSubnetDHCPSnippets called without DeleteSubnetDHCPSnippetsFunc")
+	}
+	return m.DeleteSubnetDHCPSnippetsFunc(arg0)
+}
+
+// DeleteVLAN calls DeleteVLANFunc.
+func (m *Controller) DeleteVLAN(arg0 gomaasapi.DeleteVLANArgs) error {
+	m.record("DeleteVLAN", arg0)
+	if m.DeleteVLANFunc == nil {
+		panic("mocks.Controller.DeleteVLAN called without DeleteVLANFunc")
+	}
+	return m.DeleteVLANFunc(arg0)
+}
+
+// Devices calls DevicesFunc.
+func (m *Controller) Devices(arg0 gomaasapi.DevicesArgs) ([]gomaasapi.Device, error) {
+	m.record("Devices", arg0)
+	if m.DevicesFunc == nil {
+		panic("mocks.Controller.Devices called without DevicesFunc")
+	}
+	return m.DevicesFunc(arg0)
+}
+
+// Discoveries calls DiscoveriesFunc.
+func (m *Controller) Discoveries() ([]gomaasapi.Discovery, error) {
+	m.record("Discoveries")
+	if m.DiscoveriesFunc == nil {
+		panic("mocks.Controller.Discoveries called without DiscoveriesFunc")
+	}
+	return m.DiscoveriesFunc()
+}
+
+// Do calls DoFunc.
+func (m *Controller) Do(method string, path string, op string, params url.Values) (gomaasapi.JSONObject, error) {
+	m.record("Do", method, path, op, params)
+	if m.DoFunc == nil {
+		panic("mocks.Controller.Do called without DoFunc")
+	}
+	return m.DoFunc(method, path, op, params)
+}
+
+// DoContent calls DoContentFunc.
+func (m *Controller) DoContent(method string, path string, contentType string, content io.Reader) (gomaasapi.JSONObject, error) {
+	m.record("DoContent", method, path, contentType, content)
+	if m.DoContentFunc == nil {
+		panic("mocks.Controller.DoContent called without DoContentFunc")
+	}
+	return m.DoContentFunc(method, path, contentType, content)
+}
+
+// Domains calls DomainsFunc.
+func (m *Controller) Domains() ([]gomaasapi.Domain, error) {
+	m.record("Domains")
+	if m.DomainsFunc == nil {
+		panic("mocks.Controller.Domains called without DomainsFunc")
+	}
+	return m.DomainsFunc()
+}
+
+// Events calls EventsFunc.
+func (m *Controller) Events(arg0 gomaasapi.EventsArgs) (gomaasapi.EventsPage, error) {
+	m.record("Events", arg0)
+	if m.EventsFunc == nil {
+		panic("mocks.Controller.Events called without EventsFunc")
+	}
+	return m.EventsFunc(arg0)
+}
+
+// FabricByName calls FabricByNameFunc.
+func (m *Controller) FabricByName(name string) (gomaasapi.Fabric, error) {
+	m.record("FabricByName", name)
+	if m.FabricByNameFunc == nil {
+		panic("mocks.Controller.FabricByName called without FabricByNameFunc")
+	}
+	return m.FabricByNameFunc(name)
+}
+
+// Fabrics calls FabricsFunc.
+func (m *Controller) Fabrics() ([]gomaasapi.Fabric, error) {
+	m.record("Fabrics")
+	if m.FabricsFunc == nil {
+		panic("mocks.Controller.Fabrics called without FabricsFunc")
+	}
+	return m.FabricsFunc()
+}
+
+// Files calls FilesFunc.
+func (m *Controller) Files(prefix string) ([]gomaasapi.File, error) {
+	m.record("Files", prefix)
+	if m.FilesFunc == nil {
+		panic("mocks.Controller.Files called without FilesFunc")
+	}
+	return m.FilesFunc(prefix)
+}
+
+// ForEachMachine calls ForEachMachineFunc.
+func (m *Controller) ForEachMachine(ctx context.Context, args gomaasapi.ForEachMachineArgs, fn func(gomaasapi.Machine) error) error {
+	m.record("ForEachMachine", ctx, args, fn)
+	if m.ForEachMachineFunc == nil {
+		panic("mocks.Controller.ForEachMachine called without ForEachMachineFunc")
+	}
+	return m.ForEachMachineFunc(ctx, args, fn)
+}
+
+// GetConfig calls GetConfigFunc.
+func (m *Controller) GetConfig(name string) (interface{}, error) {
+	m.record("GetConfig", name)
+	if m.GetConfigFunc == nil {
+		panic("mocks.Controller.GetConfig called without GetConfigFunc")
+	}
+	return m.GetConfigFunc(name)
+}
+
+// GetFile calls GetFileFunc.
+func (m *Controller) GetFile(filename string) (gomaasapi.File, error) {
+	m.record("GetFile", filename)
+	if m.GetFileFunc == nil {
+		panic("mocks.Controller.GetFile called without GetFileFunc")
+	}
+	return m.GetFileFunc(filename)
+}
+
+// HostRecords calls HostRecordsFunc.
+func (m *Controller) HostRecords(arg0 gomaasapi.Machine) ([]gomaasapi.HostRecord, error) {
+	m.record("HostRecords", arg0)
+	if m.HostRecordsFunc == nil {
+		panic("mocks.Controller.HostRecords called without HostRecordsFunc")
+	}
+	return m.HostRecordsFunc(arg0)
+}
+
+// IPAddresses calls IPAddressesFunc.
+func (m *Controller) IPAddresses(arg0 gomaasapi.IPAddressesArgs) ([]gomaasapi.IPAddress, error) {
+	m.record("IPAddresses", arg0)
+	if m.IPAddressesFunc == nil {
+		panic("mocks.Controller.IPAddresses called without IPAddressesFunc")
+	}
+	return m.IPAddressesFunc(arg0)
+}
+
+// IPRanges calls IPRangesFunc.
+func (m *Controller) IPRanges() ([]gomaasapi.IPRange, error) {
+	m.record("IPRanges")
+	if m.IPRangesFunc == nil {
+		panic("mocks.Controller.IPRanges called without IPRangesFunc")
+	}
+	return m.IPRangesFunc()
+}
+
+// ImportBootResources calls ImportBootResourcesFunc.
+func (m *Controller) ImportBootResources() error {
+	m.record("ImportBootResources")
+	if m.ImportBootResourcesFunc == nil {
+		panic("mocks.Controller.ImportBootResources called without ImportBootResourcesFunc")
+	}
+	return m.ImportBootResourcesFunc()
+}
+
+// ImportSSHKeys calls ImportSSHKeysFunc.
+func (m *Controller) ImportSSHKeys(keySource string) ([]gomaasapi.SSHKey, error) {
+	m.record("ImportSSHKeys", keySource)
+	if m.ImportSSHKeysFunc == nil {
+		panic("mocks.Controller.ImportSSHKeys called without ImportSSHKeysFunc")
+	}
+	return m.ImportSSHKeysFunc(keySource)
+}
+
+// IsImportingBootResources calls IsImportingBootResourcesFunc.
+func (m *Controller) IsImportingBootResources() (bool, error) {
+	m.record("IsImportingBootResources")
+	if m.IsImportingBootResourcesFunc == nil {
+		panic("mocks.Controller.IsImportingBootResources called without IsImportingBootResourcesFunc")
+	}
+	return m.IsImportingBootResourcesFunc()
+}
+
+// LeaseMachine calls LeaseMachineFunc.
+func (m *Controller) LeaseMachine(arg0 gomaasapi.Machine, arg1 gomaasapi.LeaseMachineArgs) (gomaasapi.Lease, error) {
+	m.record("LeaseMachine", arg0, arg1)
+	if m.LeaseMachineFunc == nil {
+		panic("mocks.Controller.LeaseMachine called without LeaseMachineFunc")
+	}
+	return m.LeaseMachineFunc(arg0, arg1)
+}
+
+// Leases calls LeasesFunc.
+func (m *Controller) Leases(arg0 gomaasapi.MachinesArgs) ([]gomaasapi.Lease, error) {
+	m.record("Leases", arg0)
+	if m.LeasesFunc == nil {
+		panic("mocks.Controller.Leases called without LeasesFunc")
+	}
+	return m.LeasesFunc(arg0)
+}
+
+// LicenseKeys calls LicenseKeysFunc.
+func (m *Controller) LicenseKeys() ([]gomaasapi.LicenseKey, error) {
+	m.record("LicenseKeys")
+	if m.LicenseKeysFunc == nil {
+		panic("mocks.Controller.LicenseKeys called without LicenseKeysFunc")
+	}
+	return m.LicenseKeysFunc()
+}
+
+// ListExpiredLeases calls ListExpiredLeasesFunc.
+func (m *Controller) ListExpiredLeases() ([]gomaasapi.Lease, error) {
+	m.record("ListExpiredLeases")
+	if m.ListExpiredLeasesFunc == nil {
+		panic("mocks.Controller.ListExpiredLeases called without ListExpiredLeasesFunc")
+	}
+	return m.ListExpiredLeasesFunc()
+}
+
+// MachineProxy calls MachineProxyFunc.
+func (m *Controller) MachineProxy(arg0 gomaasapi.Machine) (string, error) {
+	m.record("MachineProxy", arg0)
+	if m.MachineProxyFunc == nil {
+		panic("mocks.Controller.MachineProxy called without MachineProxyFunc")
+	}
+	return m.MachineProxyFunc(arg0)
+}
+
+// Machines calls MachinesFunc.
+func (m *Controller) Machines(arg0 gomaasapi.MachinesArgs) ([]gomaasapi.Machine, error) {
+	m.record("Machines", arg0)
+	if m.MachinesFunc == nil {
+		panic("mocks.Controller.Machines called without MachinesFunc")
+	}
+	return m.MachinesFunc(arg0)
+}
+
+// MachinesChangedSince calls MachinesChangedSinceFunc.
+func (m *Controller) MachinesChangedSince(since time.Time) ([]gomaasapi.Machine, error) {
+	m.record("MachinesChangedSince", since)
+	if m.MachinesChangedSinceFunc == nil {
+		panic("mocks.Controller.MachinesChangedSince called without MachinesChangedSinceFunc")
+	}
+	return m.MachinesChangedSinceFunc(since)
+}
+
+// MachinesPager calls MachinesPagerFunc.
+func (m *Controller) MachinesPager(args gomaasapi.MachinesArgs, pageSize int) (gomaasapi.MachinesPager, error) {
+	m.record("MachinesPager", args, pageSize)
+	if m.MachinesPagerFunc == nil {
+		panic("mocks.Controller.MachinesPager called without MachinesPagerFunc")
+	}
+	return m.MachinesPagerFunc(args, pageSize)
+}
+
+// PackageRepositories calls PackageRepositoriesFunc.
+func (m *Controller) PackageRepositories() ([]gomaasapi.PackageRepository, error) {
+	m.record("PackageRepositories")
+	if m.PackageRepositoriesFunc == nil {
+		panic("mocks.Controller.PackageRepositories called without PackageRepositoriesFunc")
+	}
+	return m.PackageRepositoriesFunc()
+}
+
+// Pools calls PoolsFunc.
+func (m *Controller) Pools() ([]gomaasapi.Pool, error) {
+	m.record("Pools")
+	if m.PoolsFunc == nil {
+		panic("mocks.Controller.Pools called without PoolsFunc")
+	}
+	return m.PoolsFunc()
+}
+
+// PowerTypes calls PowerTypesFunc.
+func (m *Controller) PowerTypes() ([]gomaasapi.PowerType, error) {
+	m.record("PowerTypes")
+	if m.PowerTypesFunc == nil {
+		panic("mocks.Controller.PowerTypes called without PowerTypesFunc")
+	}
+	return m.PowerTypesFunc()
+}
+
+// ProxySettings calls ProxySettingsFunc.
+func (m *Controller) ProxySettings() (gomaasapi.ProxySettings, error) {
+	m.record("ProxySettings")
+	if m.ProxySettingsFunc == nil {
+		panic("mocks.Controller.ProxySettings called without ProxySettingsFunc")
+	}
+	return m.ProxySettingsFunc()
+}
+
+// RackController calls RackControllerFunc.
+func (m *Controller) RackController(systemID string) (gomaasapi.RackController, error) {
+	m.record("RackController", systemID)
+	if m.RackControllerFunc == nil {
+		panic("mocks.Controller.RackController called without RackControllerFunc")
+	}
+	return m.RackControllerFunc(systemID)
+}
+
+// RackControllers calls RackControllersFunc.
+func (m *Controller) RackControllers(arg0 gomaasapi.RackControllersArgs) ([]gomaasapi.RackController, error) {
+	m.record("RackControllers", arg0)
+	if m.RackControllersFunc == nil {
+		panic("mocks.Controller.RackControllers called without RackControllersFunc")
+	}
+	return m.RackControllersFunc(arg0)
+}
+
+// RegionControllers calls RegionControllersFunc.
+func (m *Controller) RegionControllers() ([]gomaasapi.RegionController, error) {
+	m.record("RegionControllers")
+	if m.RegionControllersFunc == nil {
+		panic("mocks.Controller.RegionControllers called without RegionControllersFunc")
+	}
+	return m.RegionControllersFunc()
+}
+
+// ReleaseIPAddress calls ReleaseIPAddressFunc.
+func (m *Controller) ReleaseIPAddress(ip string) error {
+	m.record("ReleaseIPAddress", ip)
+	if m.ReleaseIPAddressFunc == nil {
+		panic("mocks.Controller.ReleaseIPAddress called without ReleaseIPAddressFunc")
+	}
+	return m.ReleaseIPAddressFunc(ip)
+}
+
+// ReleaseMachines calls ReleaseMachinesFunc.
+func (m *Controller) ReleaseMachines(arg0 gomaasapi.ReleaseMachinesArgs) error {
+	m.record("ReleaseMachines", arg0)
+	if m.ReleaseMachinesFunc == nil {
+		panic("mocks.Controller.ReleaseMachines called without ReleaseMachinesFunc")
+	}
+	return m.ReleaseMachinesFunc(arg0)
+}
+
+// ReserveIPAddress calls ReserveIPAddressFunc.
+func (m *Controller) ReserveIPAddress(arg0 gomaasapi.ReserveIPArgs) (gomaasapi.IPAddress, error) {
+	m.record("ReserveIPAddress", arg0)
+	if m.ReserveIPAddressFunc == nil {
+		panic("mocks.Controller.ReserveIPAddress called without ReserveIPAddressFunc")
+	}
+	return m.ReserveIPAddressFunc(arg0)
+}
+
+// SSHKeys calls SSHKeysFunc.
+func (m *Controller) SSHKeys() ([]gomaasapi.SSHKey, error) {
+	m.record("SSHKeys")
+	if m.SSHKeysFunc == nil {
+		panic("mocks.Controller.SSHKeys called without SSHKeysFunc")
+	}
+	return m.SSHKeysFunc()
+}
+
+// SSLKeys calls SSLKeysFunc.
+func (m *Controller) SSLKeys() ([]gomaasapi.SSLKey, error) {
+	m.record("SSLKeys")
+	if m.SSLKeysFunc == nil {
+		panic("mocks.Controller.SSLKeys called without SSLKeysFunc")
+	}
+	return m.SSLKeysFunc()
+}
+
+// ScanNetworks calls ScanNetworksFunc.
+func (m *Controller) ScanNetworks(arg0 gomaasapi.ScanNetworksArgs) (gomaasapi.ScanResult, error) {
+	m.record("ScanNetworks", arg0)
+	if m.ScanNetworksFunc == nil {
+		panic("mocks.Controller.ScanNetworks called without ScanNetworksFunc")
+	}
+	return m.ScanNetworksFunc(arg0)
+}
+
+// Scripts calls ScriptsFunc.
+func (m *Controller) Scripts(arg0 gomaasapi.ScriptsArgs) ([]gomaasapi.Script, error) {
+	m.record("Scripts", arg0)
+	if m.ScriptsFunc == nil {
+		panic("mocks.Controller.Scripts called without ScriptsFunc")
+	}
+	return m.ScriptsFunc(arg0)
+}
+
+// ServerTime calls ServerTimeFunc.
+func (m *Controller) ServerTime() (time.Time, error) {
+	m.record("ServerTime")
+	if m.ServerTimeFunc == nil {
+		panic("mocks.Controller.ServerTime called without ServerTimeFunc")
+	}
+	return m.ServerTimeFunc()
+}
+
+// ServerVersion calls ServerVersionFunc.
+func (m *Controller) ServerVersion() gomaasapi.ServerInfo {
+	m.record("ServerVersion")
+	if m.ServerVersionFunc == nil {
+		panic("mocks.Controller.ServerVersion called without ServerVersionFunc")
+	}
+	return m.ServerVersionFunc()
+}
+
+// SetAPIKey calls SetAPIKeyFunc.
+func (m *Controller) SetAPIKey(apiKey string) error {
+	m.record("SetAPIKey", apiKey)
+	if m.SetAPIKeyFunc == nil {
+		panic("mocks.Controller.SetAPIKey called without SetAPIKeyFunc")
+	}
+	return m.SetAPIKeyFunc(apiKey)
+}
+
+// SetConfig calls SetConfigFunc.
+func (m *Controller) SetConfig(name string, value string) error {
+	m.record("SetConfig", name, value)
+	if m.SetConfigFunc == nil {
+		panic("mocks.Controller.SetConfig called without SetConfigFunc")
+	}
+	return m.SetConfigFunc(name, value)
+}
+
+// Settings calls SettingsFunc.
+func (m *Controller) Settings() (gomaasapi.Settings, error) {
+	m.record("Settings")
+	if m.SettingsFunc == nil {
+		panic("mocks.Controller.Settings called without SettingsFunc")
+	}
+	return m.SettingsFunc()
+}
+
+// SpaceByName calls SpaceByNameFunc.
+func (m *Controller) SpaceByName(name string) (gomaasapi.Space, error) {
+	m.record("SpaceByName", name)
+	if m.SpaceByNameFunc == nil {
+		panic("mocks.Controller.SpaceByName called without SpaceByNameFunc")
+	}
+	return m.SpaceByNameFunc(name)
+}
+
+// Spaces calls SpacesFunc.
+func (m *Controller) Spaces() ([]gomaasapi.Space, error) {
+	m.record("Spaces")
+	if m.SpacesFunc == nil {
+		panic("mocks.Controller.Spaces called without SpacesFunc")
+	}
+	return m.SpacesFunc()
+}
+
+// StaticRoutes calls StaticRoutesFunc.
+func (m *Controller) StaticRoutes() ([]gomaasapi.StaticRoute, error) {
+	m.record("StaticRoutes")
+	if m.StaticRoutesFunc == nil {
+		panic("mocks.Controller.StaticRoutes called without StaticRoutesFunc")
+	}
+	return m.StaticRoutesFunc()
+}
+
+// StopImportBootResources calls StopImportBootResourcesFunc.
+func (m *Controller) StopImportBootResources() error {
+	m.record("StopImportBootResources")
+	if m.StopImportBootResourcesFunc == nil {
+		panic("mocks.Controller.StopImportBootResources called without StopImportBootResourcesFunc")
+	}
+	return m.StopImportBootResourcesFunc()
+}
+
+// SubnetByCIDR calls SubnetByCIDRFunc.
+func (m *Controller) SubnetByCIDR(cidr string) (gomaasapi.Subnet, error) {
+	m.record("SubnetByCIDR", cidr)
+	if m.SubnetByCIDRFunc == nil {
+		panic("mocks.Controller.SubnetByCIDR called without SubnetByCIDRFunc")
+	}
+	return m.SubnetByCIDRFunc(cidr)
+}
+
+// SubnetByID calls SubnetByIDFunc.
+func (m *Controller) SubnetByID(id int) (gomaasapi.Subnet, error) {
+	m.record("SubnetByID", id)
+	if m.SubnetByIDFunc == nil {
+		panic("mocks.Controller.SubnetByID called without SubnetByIDFunc")
+	}
+	return m.SubnetByIDFunc(id)
+}
+
+// SubnetUsage calls SubnetUsageFunc.
+func (m *Controller) SubnetUsage(arg0 gomaasapi.Subnet) (gomaasapi.ResourceUsage, error) {
+	m.record("SubnetUsage", arg0)
+	if m.SubnetUsageFunc == nil {
+		panic("mocks.Controller.SubnetUsage called without SubnetUsageFunc")
+	}
+	return m.SubnetUsageFunc(arg0)
+}
+
+// Subnets calls SubnetsFunc.
+func (m *Controller) Subnets() ([]gomaasapi.Subnet, error) {
+	m.record("Subnets")
+	if m.SubnetsFunc == nil {
+		panic("mocks.Controller.Subnets called without SubnetsFunc")
+	}
+	return m.SubnetsFunc()
+}
+
+// Tags calls TagsFunc.
+func (m *Controller) Tags() ([]gomaasapi.Tag, error) {
+	m.record("Tags")
+	if m.TagsFunc == nil {
+		panic("mocks.Controller.Tags called without TagsFunc")
+	}
+	return m.TagsFunc()
+}
+
+// UpdateSettings calls UpdateSettingsFunc.
+func (m *Controller) UpdateSettings(arg0 gomaasapi.UpdateSettingsArgs) error {
+	m.record("UpdateSettings", arg0)
+	if m.UpdateSettingsFunc == nil {
+		panic("mocks.Controller.UpdateSettings called without UpdateSettingsFunc")
+	}
+	return m.UpdateSettingsFunc(arg0)
+}
+
+// UploadBootResource calls UploadBootResourceFunc.
+func (m *Controller) UploadBootResource(arg0 gomaasapi.UploadBootResourceArgs) (gomaasapi.BootResource, error) {
+	m.record("UploadBootResource", arg0)
+	if m.UploadBootResourceFunc == nil {
+		panic("mocks.Controller.UploadBootResource called without UploadBootResourceFunc")
+	}
+	return m.UploadBootResourceFunc(arg0)
+}
+
+// UploadScript calls UploadScriptFunc.
+func (m *Controller) UploadScript(arg0 gomaasapi.UploadScriptArgs) (gomaasapi.Script, error) {
+	m.record("UploadScript", arg0)
+	if m.UploadScriptFunc == nil {
+		panic("mocks.Controller.UploadScript called without UploadScriptFunc")
+	}
+	return m.UploadScriptFunc(arg0)
+}
+
+// Users calls UsersFunc.
+func (m *Controller) Users() ([]gomaasapi.User, error) {
+	m.record("Users")
+	if m.UsersFunc == nil {
+		panic("mocks.Controller.Users called without UsersFunc")
+	}
+	return m.UsersFunc()
+}
+
+// VLANByID calls VLANByIDFunc.
+func (m *Controller) VLANByID(id int) (gomaasapi.VLAN, error) {
+	m.record("VLANByID", id)
+	if m.VLANByIDFunc == nil {
+		panic("mocks.Controller.VLANByID called without VLANByIDFunc")
+	}
+	return m.VLANByIDFunc(id)
+}
+
+// VLANUsage calls VLANUsageFunc.
+func (m *Controller) VLANUsage(arg0 gomaasapi.VLAN) (gomaasapi.ResourceUsage, error) {
+	m.record("VLANUsage", arg0)
+	if m.VLANUsageFunc == nil {
+		panic("mocks.Controller.VLANUsage called without VLANUsageFunc")
+	}
+	return m.VLANUsageFunc(arg0)
+}
+
+// VMHost calls VMHostFunc.
+func (m *Controller) VMHost(id int) (gomaasapi.VMHost, error) {
+	m.record("VMHost", id)
+	if m.VMHostFunc == nil {
+		panic("mocks.Controller.VMHost called without VMHostFunc")
+	}
+	return m.VMHostFunc(id)
+}
+
+// VMHosts calls VMHostsFunc.
+func (m *Controller) VMHosts() ([]gomaasapi.VMHost, error) {
+	m.record("VMHosts")
+	if m.VMHostsFunc == nil {
+		panic("mocks.Controller.VMHosts called without VMHostsFunc")
+	}
+	return m.VMHostsFunc()
+}
+
+// VersionSkew calls VersionSkewFunc.
+func (m *Controller) VersionSkew() (gomaasapi.VersionSkew, error) {
+	m.record("VersionSkew")
+	if m.VersionSkewFunc == nil {
+		panic("mocks.Controller.VersionSkew called without VersionSkewFunc")
+	}
+	return m.VersionSkewFunc()
+}
+
+// WalkMachines calls WalkMachinesFunc.
+func (m *Controller) WalkMachines(args gomaasapi.MachinesArgs, fn func(gomaasapi.Machine) error) error {
+	m.record("WalkMachines", args, fn)
+	if m.WalkMachinesFunc == nil {
+		panic("mocks.Controller.WalkMachines called without WalkMachinesFunc")
+	}
+	return m.WalkMachinesFunc(args, fn)
+}
+
+// Watch calls WatchFunc.
+func (m *Controller) Watch(ctx context.Context, args gomaasapi.WatchArgs) (gomaasapi.Watcher, error) {
+	m.record("Watch", ctx, args)
+	if m.WatchFunc == nil {
+		panic("mocks.Controller.Watch called without WatchFunc")
+	}
+	return m.WatchFunc(ctx, args)
+}
+
+// WhoAmI calls WhoAmIFunc.
+func (m *Controller) WhoAmI() (gomaasapi.User, error) {
+	m.record("WhoAmI")
+	if m.WhoAmIFunc == nil {
+		panic("mocks.Controller.WhoAmI called without WhoAmIFunc")
+	}
+	return m.WhoAmIFunc()
+}
+
+// WithAPIKey calls WithAPIKeyFunc.
+func (m *Controller) WithAPIKey(apiKey string) (gomaasapi.Controller, error) {
+	m.record("WithAPIKey", apiKey)
+	if m.WithAPIKeyFunc == nil {
+		panic("mocks.Controller.WithAPIKey called without WithAPIKeyFunc")
+	}
+	return m.WithAPIKeyFunc(apiKey)
+}
+
+// WithContext calls WithContextFunc.
+func (m *Controller) WithContext(arg0 context.Context) gomaasapi.Controller {
+	m.record("WithContext", arg0)
+	if m.WithContextFunc == nil {
+		panic("mocks.Controller.WithContext called without WithContextFunc")
+	}
+	return m.WithContextFunc(arg0)
+}
+
+// WithPriority calls WithPriorityFunc.
+func (m *Controller) WithPriority(arg0 gomaasapi.Priority) gomaasapi.Controller {
+	m.record("WithPriority", arg0)
+	if m.WithPriorityFunc == nil {
+		panic("mocks.Controller.WithPriority called without WithPriorityFunc")
+	}
+	return m.WithPriorityFunc(arg0)
+}
+
+// Zones calls ZonesFunc.
+func (m *Controller) Zones() ([]gomaasapi.Zone, error) {
+	m.record("Zones")
+	if m.ZonesFunc == nil {
+		panic("mocks.Controller.Zones called without ZonesFunc")
+	}
+	return m.ZonesFunc()
+}
+
+// File is a mock of gomaasapi.File.
+type File struct {
+	AnonymousURLFunc func() string
+	DeleteFunc       func() error
+	FilenameFunc     func() string
+	ReadAllFunc      func() ([]byte, error)
+
+	recorder
+}
+
+var _ gomaasapi.File = (*File)(nil)
+
+// AnonymousURL calls AnonymousURLFunc.
+func (m *File) AnonymousURL() string {
+	m.record("AnonymousURL")
+	if m.AnonymousURLFunc == nil {
+		panic("mocks.File.AnonymousURL called without AnonymousURLFunc")
+	}
+	return m.AnonymousURLFunc()
+}
+
+// Delete calls DeleteFunc.
+func (m *File) Delete() error {
+	m.record("Delete")
+	if m.DeleteFunc == nil {
+		panic("mocks.File.Delete called without DeleteFunc")
+	}
+	return m.DeleteFunc()
+}
+
+// Filename calls FilenameFunc.
+func (m *File) Filename() string {
+	m.record("Filename")
+	if m.FilenameFunc == nil {
+		panic("mocks.File.Filename called without FilenameFunc")
+	}
+	return m.FilenameFunc()
+}
+
+// ReadAll calls ReadAllFunc.
+func (m *File) ReadAll() ([]byte, error) {
+	m.record("ReadAll")
+	if m.ReadAllFunc == nil {
+		panic("mocks.File.ReadAll called without ReadAllFunc")
+	}
+	return m.ReadAllFunc()
+}
+
+// Fabric is a mock of gomaasapi.Fabric.
+type Fabric struct {
+	ClassTypeFunc  func() string
+	CreateVLANFunc func(gomaasapi.CreateVLANArgs) (gomaasapi.VLAN, error)
+	IDFunc         func() int
+	NameFunc       func() string
+	VLANsFunc      func() []gomaasapi.VLAN
+
+	recorder
+}
+
+var _ gomaasapi.Fabric = (*Fabric)(nil)
+
+// ClassType calls ClassTypeFunc.
+func (m *Fabric) ClassType() string {
+	m.record("ClassType")
+	if m.ClassTypeFunc == nil {
+		panic("mocks.Fabric.ClassType called without ClassTypeFunc")
+	}
+	return m.ClassTypeFunc()
+}
+
+// CreateVLAN calls CreateVLANFunc.
+func (m *Fabric) CreateVLAN(arg0 gomaasapi.CreateVLANArgs) (gomaasapi.VLAN, error) {
+	m.record("CreateVLAN", arg0)
+	if m.CreateVLANFunc == nil {
+		panic("mocks.Fabric.CreateVLAN called without CreateVLANFunc")
+	}
+	return m.CreateVLANFunc(arg0)
+}
+
+// ID calls IDFunc.
+func (m *Fabric) ID() int {
+	m.record("ID")
+	if m.IDFunc == nil {
+		panic("mocks.Fabric.ID called without IDFunc")
+	}
+	return m.IDFunc()
+}
+
+// Name calls NameFunc.
+func (m *Fabric) Name() string {
+	m.record("Name")
+	if m.NameFunc == nil {
+		panic("mocks.Fabric.Name called without NameFunc")
+	}
+	return m.NameFunc()
+}
+
+// VLANs calls VLANsFunc.
+func (m *Fabric) VLANs() []gomaasapi.VLAN {
+	m.record("VLANs")
+	if m.VLANsFunc == nil {
+		panic("mocks.Fabric.VLANs called without VLANsFunc")
+	}
+	return m.VLANsFunc()
+}
+
+// VLAN is a mock of gomaasapi.VLAN.
+type VLAN struct {
+	DHCPFunc          func() bool
+	DeleteFunc        func() error
+	DescriptionFunc   func() string
+	ExternalDHCPFunc  func() string
+	FabricFunc        func() string
+	IDFunc            func() int
+	MTUFunc           func() int
+	NameFunc          func() string
+	PrimaryRackFunc   func() string
+	RelayVLANFunc     func() gomaasapi.VLAN
+	SecondaryRackFunc func() string
+	SpaceFunc         func() string
+	UpdateFunc        func(gomaasapi.UpdateVLANArgs) error
+	VIDFunc           func() int
+
+	recorder
+}
+
+var _ gomaasapi.VLAN = (*VLAN)(nil)
+
+// DHCP calls DHCPFunc.
+func (m *VLAN) DHCP() bool {
+	m.record("DHCP")
+	if m.DHCPFunc == nil {
+		panic("mocks.VLAN.DHCP called without DHCPFunc")
+	}
+	return m.DHCPFunc()
+}
+
+// Delete calls DeleteFunc.
+func (m *VLAN) Delete() error {
+	m.record("Delete")
+	if m.DeleteFunc == nil {
+		panic("mocks.VLAN.Delete called without DeleteFunc")
+	}
+	return m.DeleteFunc()
+}
+
+// Description calls DescriptionFunc.
+func (m *VLAN) Description() string {
+	m.record("Description")
+	if m.DescriptionFunc == nil {
+		panic("mocks.VLAN.Description called without DescriptionFunc")
+	}
+	return m.DescriptionFunc()
+}
+
+// ExternalDHCP calls ExternalDHCPFunc.
+func (m *VLAN) ExternalDHCP() string {
+	m.record("ExternalDHCP")
+	if m.ExternalDHCPFunc == nil {
+		panic("mocks.VLAN.ExternalDHCP called without ExternalDHCPFunc")
+	}
+	return m.ExternalDHCPFunc()
+}
+
+// Fabric calls FabricFunc.
+func (m *VLAN) Fabric() string {
+	m.record("Fabric")
+	if m.FabricFunc == nil {
+		panic("mocks.VLAN.Fabric called without FabricFunc")
+	}
+	return m.FabricFunc()
+}
+
+// ID calls IDFunc.
+func (m *VLAN) ID() int {
+	m.record("ID")
+	if m.IDFunc == nil {
+		panic("mocks.VLAN.ID called without IDFunc")
+	}
+	return m.IDFunc()
+}
+
+// MTU calls MTUFunc.
+func (m *VLAN) MTU() int {
+	m.record("MTU")
+	if m.MTUFunc == nil {
+		panic("mocks.VLAN.MTU called without MTUFunc")
+	}
+	return m.MTUFunc()
+}
+
+// Name calls NameFunc.
+func (m *VLAN) Name() string {
+	m.record("Name")
+	if m.NameFunc == nil {
+		panic("mocks.VLAN.Name called without NameFunc")
+	}
+	return m.NameFunc()
+}
+
+// PrimaryRack calls PrimaryRackFunc.
+func (m *VLAN) PrimaryRack() string {
+	m.record("PrimaryRack")
+	if m.PrimaryRackFunc == nil {
+		panic("mocks.VLAN.PrimaryRack called without PrimaryRackFunc")
+	}
+	return m.PrimaryRackFunc()
+}
+
+// RelayVLAN calls RelayVLANFunc.
+func (m *VLAN) RelayVLAN() gomaasapi.VLAN {
+	m.record("RelayVLAN")
+	if m.RelayVLANFunc == nil {
+		panic("mocks.VLAN.RelayVLAN called without RelayVLANFunc")
+	}
+	return m.RelayVLANFunc()
+}
+
+// SecondaryRack calls SecondaryRackFunc.
+func (m *VLAN) SecondaryRack() string {
+	m.record("SecondaryRack")
+	if m.SecondaryRackFunc == nil {
+		panic("mocks.VLAN.SecondaryRack called without SecondaryRackFunc")
+	}
+	return m.SecondaryRackFunc()
+}
+
+// Space calls SpaceFunc.
+func (m *VLAN) Space() string {
+	m.record("Space")
+	if m.SpaceFunc == nil {
+		panic("mocks.VLAN.Space called without SpaceFunc")
+	}
+	return m.SpaceFunc()
+}
+
+// Update calls UpdateFunc.
+func (m *VLAN) Update(arg0 gomaasapi.UpdateVLANArgs) error {
+	m.record("Update", arg0)
+	if m.UpdateFunc == nil {
+		panic("mocks.VLAN.Update called without UpdateFunc")
+	}
+	return m.UpdateFunc(arg0)
+}
+
+// VID calls VIDFunc.
+func (m *VLAN) VID() int {
+	m.record("VID")
+	if m.VIDFunc == nil {
+		panic("mocks.VLAN.VID called without VIDFunc")
+	}
+	return m.VIDFunc()
+}
+
+// Zone is a mock of gomaasapi.Zone.
+type Zone struct {
+	DeleteFunc      func() error
+	DescriptionFunc func() string
+	IDFunc          func() int
+	NameFunc        func() string
+	UpdateFunc      func(gomaasapi.UpdateZoneArgs) error
+
+	recorder
+}
+
+var _ gomaasapi.Zone = (*Zone)(nil)
+
+// Delete calls DeleteFunc.
+func (m *Zone) Delete() error {
+	m.record("Delete")
+	if m.DeleteFunc == nil {
+		panic("mocks.Zone.Delete called without DeleteFunc")
+	}
+	return m.DeleteFunc()
+}
+
+// Description calls DescriptionFunc.
+func (m *Zone) Description() string {
+	m.record("Description")
+	if m.DescriptionFunc == nil {
+		panic("mocks.Zone.Description called without DescriptionFunc")
+	}
+	return m.DescriptionFunc()
+}
+
+// ID calls IDFunc.
+func (m *Zone) ID() int {
+	m.record("ID")
+	if m.IDFunc == nil {
+		panic("mocks.Zone.ID called without IDFunc")
+	}
+	return m.IDFunc()
+}
+
+// Name calls NameFunc.
+func (m *Zone) Name() string {
+	m.record("Name")
+	if m.NameFunc == nil {
+		panic("mocks.Zone.Name called without NameFunc")
+	}
+	return m.NameFunc()
+}
+
+// Update calls UpdateFunc.
+func (m *Zone) Update(arg0 gomaasapi.UpdateZoneArgs) error {
+	m.record("Update", arg0)
+	if m.UpdateFunc == nil {
+		panic("mocks.Zone.Update called without UpdateFunc")
+	}
+	return m.UpdateFunc(arg0)
+}
+
+// Pool is a mock of gomaasapi.Pool.
+type Pool struct {
+	DeleteFunc      func() error
+	DescriptionFunc func() string
+	IDFunc          func() int
+	NameFunc        func() string
+	UpdateFunc      func(gomaasapi.UpdatePoolArgs) error
+
+	recorder
+}
+
+var _ gomaasapi.Pool = (*Pool)(nil)
+
+// Delete calls DeleteFunc.
+func (m *Pool) Delete() error {
+	m.record("Delete")
+	if m.DeleteFunc == nil {
+		panic("mocks.Pool.Delete called without DeleteFunc")
+	}
+	return m.DeleteFunc()
+}
+
+// Description calls DescriptionFunc.
+func (m *Pool) Description() string {
+	m.record("Description")
+	if m.DescriptionFunc == nil {
+		panic("mocks.Pool.Description called without DescriptionFunc")
+	}
+	return m.DescriptionFunc()
+}
+
+// ID calls IDFunc.
+func (m *Pool) ID() int {
+	m.record("ID")
+	if m.IDFunc == nil {
+		panic("mocks.Pool.ID called without IDFunc")
+	}
+	return m.IDFunc()
+}
+
+// Name calls NameFunc.
+func (m *Pool) Name() string {
+	m.record("Name")
+	if m.NameFunc == nil {
+		panic("mocks.Pool.Name called without NameFunc")
+	}
+	return m.NameFunc()
+}
+
+// Update calls UpdateFunc.
+func (m *Pool) Update(arg0 gomaasapi.UpdatePoolArgs) error {
+	m.record("Update", arg0)
+	if m.UpdateFunc == nil {
+		panic("mocks.Pool.Update called without UpdateFunc")
+	}
+	return m.UpdateFunc(arg0)
+}
+
+// Domain is a mock of gomaasapi.Domain.
+type Domain struct {
+	AuthoritativeFunc       func() bool
+	DeleteFunc              func() error
+	IDFunc                  func() int
+	IsDefaultFunc           func() bool
+	NameFunc                func() string
+	ResourceRecordCountFunc func() int
+	SetDefaultFunc          func() error
+	TTLFunc                 func() *int
+	UpdateFunc              func(gomaasapi.UpdateDomainArgs) error
+
+	recorder
+}
+
+var _ gomaasapi.Domain = (*Domain)(nil)
+
+// Authoritative calls AuthoritativeFunc.
+func (m *Domain) Authoritative() bool {
+	m.record("Authoritative")
+	if m.AuthoritativeFunc == nil {
+		panic("mocks.Domain.Authoritative called without AuthoritativeFunc")
+	}
+	return m.AuthoritativeFunc()
+}
+
+// Delete calls DeleteFunc.
+func (m *Domain) Delete() error {
+	m.record("Delete")
+	if m.DeleteFunc == nil {
+		panic("mocks.Domain.Delete called without DeleteFunc")
+	}
+	return m.DeleteFunc()
+}
+
+// ID calls IDFunc.
+func (m *Domain) ID() int {
+	m.record("ID")
+	if m.IDFunc == nil {
+		panic("mocks.Domain.ID called without IDFunc")
+	}
+	return m.IDFunc()
+}
+
+// IsDefault calls IsDefaultFunc.
+func (m *Domain) IsDefault() bool {
+	m.record("IsDefault")
+	if m.IsDefaultFunc == nil {
+		panic("mocks.Domain.IsDefault called without IsDefaultFunc")
+	}
+	return m.IsDefaultFunc()
+}
+
+// Name calls NameFunc.
+func (m *Domain) Name() string {
+	m.record("Name")
+	if m.NameFunc == nil {
+		panic("mocks.Domain.Name called without NameFunc")
+	}
+	return m.NameFunc()
+}
+
+// ResourceRecordCount calls ResourceRecordCountFunc.
+func (m *Domain) ResourceRecordCount() int {
+	m.record("ResourceRecordCount")
+	if m.ResourceRecordCountFunc == nil {
+		panic("mocks.Domain.ResourceRecordCount called without ResourceRecordCountFunc")
+	}
+	return m.ResourceRecordCountFunc()
+}
+
+// SetDefault calls SetDefaultFunc.
+func (m *Domain) SetDefault() error {
+	m.record("SetDefault")
+	if m.SetDefaultFunc == nil {
+		panic("mocks.Domain.SetDefault called without SetDefaultFunc")
+	}
+	return m.SetDefaultFunc()
+}
+
+// TTL calls TTLFunc.
+func (m *Domain) TTL() *int {
+	m.record("TTL")
+	if m.TTLFunc == nil {
+		panic("mocks.Domain.TTL called without TTLFunc")
+	}
+	return m.TTLFunc()
+}
+
+// Update calls UpdateFunc.
+func (m *Domain) Update(arg0 gomaasapi.UpdateDomainArgs) error {
+	m.record("Update", arg0)
+	if m.UpdateFunc == nil {
+		panic("mocks.Domain.Update called without UpdateFunc")
+	}
+	return m.UpdateFunc(arg0)
+}
+
+// DNSResource is a mock of gomaasapi.DNSResource.
+type DNSResource struct {
+	AddressTTLFunc      func() *int
+	DeleteFunc          func() error
+	FQDNFunc            func() string
+	IDFunc              func() int
+	IPAddressesFunc     func() []string
+	ResourceRecordsFunc func() []gomaasapi.DNSResourceRecord
+	UpdateFunc          func(gomaasapi.UpdateDNSResourceArgs) error
+
+	recorder
+}
+
+var _ gomaasapi.DNSResource = (*DNSResource)(nil)
+
+// AddressTTL calls AddressTTLFunc.
+func (m *DNSResource) AddressTTL() *int {
+	m.record("AddressTTL")
+	if m.AddressTTLFunc == nil {
+		panic("mocks.DNSResource.AddressTTL called without AddressTTLFunc")
+	}
+	return m.AddressTTLFunc()
+}
+
+// Delete calls DeleteFunc.
+func (m *DNSResource) Delete() error {
+	m.record("Delete")
+	if m.DeleteFunc == nil {
+		panic("mocks.DNSResource.Delete called without DeleteFunc")
+	}
+	return m.DeleteFunc()
+}
+
+// FQDN calls FQDNFunc.
+func (m *DNSResource) FQDN() string {
+	m.record("FQDN")
+	if m.FQDNFunc == nil {
+		panic("mocks.DNSResource.FQDN called without FQDNFunc")
+	}
+	return m.FQDNFunc()
+}
+
+// ID calls IDFunc.
+func (m *DNSResource) ID() int {
+	m.record("ID")
+	if m.IDFunc == nil {
+		panic("mocks.DNSResource.ID called without IDFunc")
+	}
+	return m.IDFunc()
+}
+
+// IPAddresses calls IPAddressesFunc.
+func (m *DNSResource) IPAddresses() []string {
+	m.record("IPAddresses")
+	if m.IPAddressesFunc == nil {
+		panic("mocks.DNSResource.IPAddresses called without IPAddressesFunc")
+	}
+	return m.IPAddressesFunc()
+}
+
+// ResourceRecords calls ResourceRecordsFunc.
+func (m *DNSResource) ResourceRecords() []gomaasapi.DNSResourceRecord {
+	m.record("ResourceRecords")
+	if m.ResourceRecordsFunc == nil {
+		panic("mocks.DNSResource.ResourceRecords called without ResourceRecordsFunc")
+	}
+	return m.ResourceRecordsFunc()
+}
+
+// Update calls UpdateFunc.
+func (m *DNSResource) Update(arg0 gomaasapi.UpdateDNSResourceArgs) error {
+	m.record("Update", arg0)
+	if m.UpdateFunc == nil {
+		panic("mocks.DNSResource.Update called without UpdateFunc")
+	}
+	return m.UpdateFunc(arg0)
+}
+
+// DNSResourceRecord is a mock of gomaasapi.DNSResourceRecord.
+type DNSResourceRecord struct {
+	DataFunc   func() string
+	DeleteFunc func() error
+	FQDNFunc   func() string
+	IDFunc     func() int
+	TTLFunc    func() *int
+	TypeFunc   func() string
+	UpdateFunc func(gomaasapi.UpdateDNSResourceRecordArgs) error
+
+	recorder
+}
+
+var _ gomaasapi.DNSResourceRecord = (*DNSResourceRecord)(nil)
+
+// Data calls DataFunc.
+func (m *DNSResourceRecord) Data() string {
+	m.record("Data")
+	if m.DataFunc == nil {
+		panic("mocks.DNSResourceRecord.Data called without DataFunc")
+	}
+	return m.DataFunc()
+}
+
+// Delete calls DeleteFunc.
+func (m *DNSResourceRecord) Delete() error {
+	m.record("Delete")
+	if m.DeleteFunc == nil {
+		panic("mocks.DNSResourceRecord.Delete called without DeleteFunc")
+	}
+	return m.DeleteFunc()
+}
+
+// FQDN calls FQDNFunc.
+func (m *DNSResourceRecord) FQDN() string {
+	m.record("FQDN")
+	if m.FQDNFunc == nil {
+		panic("mocks.DNSResourceRecord.FQDN called without FQDNFunc")
+	}
+	return m.FQDNFunc()
+}
+
+// ID calls IDFunc.
+func (m *DNSResourceRecord) ID() int {
+	m.record("ID")
+	if m.IDFunc == nil {
+		panic("mocks.DNSResourceRecord.ID called without IDFunc")
+	}
+	return m.IDFunc()
+}
+
+// TTL calls TTLFunc.
+func (m *DNSResourceRecord) TTL() *int {
+	m.record("TTL")
+	if m.TTLFunc == nil {
+		panic("mocks.DNSResourceRecord.TTL called without TTLFunc")
+	}
+	return m.TTLFunc()
+}
+
+// Type calls TypeFunc.
+func (m *DNSResourceRecord) Type() string {
+	m.record("Type")
+	if m.TypeFunc == nil {
+		panic("mocks.DNSResourceRecord.Type called without TypeFunc")
+	}
+	return m.TypeFunc()
+}
+
+// Update calls UpdateFunc.
+func (m *DNSResourceRecord) Update(arg0 gomaasapi.UpdateDNSResourceRecordArgs) error {
+	m.record("Update", arg0)
+	if m.UpdateFunc == nil {
+		panic("mocks.DNSResourceRecord.Update called without UpdateFunc")
+	}
+	return m.UpdateFunc(arg0)
+}
+
+// BootResource is a mock of gomaasapi.BootResource.
+type BootResource struct {
+	ArchitectureFunc     func() string
+	DeleteFunc           func() error
+	IDFunc               func() int
+	KernelFlavorFunc     func() string
+	NameFunc             func() string
+	SubArchitecturesFunc func() set.Strings
+	TypeFunc             func() string
+
+	recorder
+}
+
+var _ gomaasapi.BootResource = (*BootResource)(nil)
+
+// Architecture calls ArchitectureFunc.
+func (m *BootResource) Architecture() string {
+	m.record("Architecture")
+	if m.ArchitectureFunc == nil {
+		panic("mocks.BootResource.Architecture called without ArchitectureFunc")
+	}
+	return m.ArchitectureFunc()
+}
+
+// Delete calls DeleteFunc.
+func (m *BootResource) Delete() error {
+	m.record("Delete")
+	if m.DeleteFunc == nil {
+		panic("mocks.BootResource.Delete called without DeleteFunc")
+	}
+	return m.DeleteFunc()
+}
+
+// ID calls IDFunc.
+func (m *BootResource) ID() int {
+	m.record("ID")
+	if m.IDFunc == nil {
+		panic("mocks.BootResource.ID called without IDFunc")
+	}
+	return m.IDFunc()
+}
+
+// KernelFlavor calls KernelFlavorFunc.
+func (m *BootResource) KernelFlavor() string {
+	m.record("KernelFlavor")
+	if m.KernelFlavorFunc == nil {
+		panic("mocks.BootResource.KernelFlavor called without KernelFlavorFunc")
+	}
+	return m.KernelFlavorFunc()
+}
+
+// Name calls NameFunc.
+func (m *BootResource) Name() string {
+	m.record("Name")
+	if m.NameFunc == nil {
+		panic("mocks.BootResource.Name called without NameFunc")
+	}
+	return m.NameFunc()
+}
+
+// SubArchitectures calls SubArchitecturesFunc.
+func (m *BootResource) SubArchitectures() set.Strings {
+	m.record("SubArchitectures")
+	if m.SubArchitecturesFunc == nil {
+		panic("mocks.BootResource.SubArchitectures called without SubArchitecturesFunc")
+	}
+	return m.SubArchitecturesFunc()
+}
+
+// Type calls TypeFunc.
+func (m *BootResource) Type() string {
+	m.record("Type")
+	if m.TypeFunc == nil {
+		panic("mocks.BootResource.Type called without TypeFunc")
+	}
+	return m.TypeFunc()
+}
+
+// BootSource is a mock of gomaasapi.BootSource.
+type BootSource struct {
+	CreateSelectionFunc func(gomaasapi.CreateBootSourceSelectionArgs) (gomaasapi.BootSourceSelection, error)
+	DeleteFunc          func() error
+	IDFunc              func() int
+	KeyringDataFunc     func() []byte
+	KeyringFilenameFunc func() string
+	SelectionsFunc      func() ([]gomaasapi.BootSourceSelection, error)
+	URLFunc             func() string
+	UpdateFunc          func(gomaasapi.UpdateBootSourceArgs) error
+
+	recorder
+}
+
+var _ gomaasapi.BootSource = (*BootSource)(nil)
+
+// CreateSelection calls CreateSelectionFunc.
+func (m *BootSource) CreateSelection(arg0 gomaasapi.CreateBootSourceSelectionArgs) (gomaasapi.BootSourceSelection, error) {
+	m.record("CreateSelection", arg0)
+	if m.CreateSelectionFunc == nil {
+		panic("mocks.BootSource.CreateSelection called without CreateSelectionFunc")
+	}
+	return m.CreateSelectionFunc(arg0)
+}
+
+// Delete calls DeleteFunc.
+func (m *BootSource) Delete() error {
+	m.record("Delete")
+	if m.DeleteFunc == nil {
+		panic("mocks.BootSource.Delete called without DeleteFunc")
+	}
+	return m.DeleteFunc()
+}
+
+// ID calls IDFunc.
+func (m *BootSource) ID() int {
+	m.record("ID")
+	if m.IDFunc == nil {
+		panic("mocks.BootSource.ID called without IDFunc")
+	}
+	return m.IDFunc()
+}
+
+// KeyringData calls KeyringDataFunc.
+func (m *BootSource) KeyringData() []byte {
+	m.record("KeyringData")
+	if m.KeyringDataFunc == nil {
+		panic("mocks.BootSource.KeyringData called without KeyringDataFunc")
+	}
+	return m.KeyringDataFunc()
+}
+
+// KeyringFilename calls KeyringFilenameFunc.
+func (m *BootSource) KeyringFilename() string {
+	m.record("KeyringFilename")
+	if m.KeyringFilenameFunc == nil {
+		panic("mocks.BootSource.KeyringFilename called without KeyringFilenameFunc")
+	}
+	return m.KeyringFilenameFunc()
+}
+
+// Selections calls SelectionsFunc.
+func (m *BootSource) Selections() ([]gomaasapi.BootSourceSelection, error) {
+	m.record("Selections")
+	if m.SelectionsFunc == nil {
+		panic("mocks.BootSource.Selections called without SelectionsFunc")
+	}
+	return m.SelectionsFunc()
+}
+
+// URL calls URLFunc.
+func (m *BootSource) URL() string {
+	m.record("URL")
+	if m.URLFunc == nil {
+		panic("mocks.BootSource.URL called without URLFunc")
+	}
+	return m.URLFunc()
+}
+
+// Update calls UpdateFunc.
+func (m *BootSource) Update(arg0 gomaasapi.UpdateBootSourceArgs) error {
+	m.record("Update", arg0)
+	if m.UpdateFunc == nil {
+		panic("mocks.BootSource.Update called without UpdateFunc")
+	}
+	return m.UpdateFunc(arg0)
+}
+
+// BootSourceSelection is a mock of gomaasapi.BootSourceSelection.
+type BootSourceSelection struct {
+	ArchitecturesFunc    func() []string
+	BootSourceIDFunc     func() int
+	DeleteFunc           func() error
+	IDFunc               func() int
+	LabelsFunc           func() []string
+	OSFunc               func() string
+	ReleaseFunc          func() string
+	SubArchitecturesFunc func() []string
+	UpdateFunc           func(gomaasapi.UpdateBootSourceSelectionArgs) error
+
+	recorder
+}
+
+var _ gomaasapi.BootSourceSelection = (*BootSourceSelection)(nil)
+
+// Architectures calls ArchitecturesFunc.
+func (m *BootSourceSelection) Architectures() []string {
+	m.record("Architectures")
+	if m.ArchitecturesFunc == nil {
+		panic("mocks.BootSourceSelection.Architectures called without ArchitecturesFunc")
+	}
+	return m.ArchitecturesFunc()
+}
+
+// BootSourceID calls BootSourceIDFunc.
+func (m *BootSourceSelection) BootSourceID() int {
+	m.record("BootSourceID")
+	if m.BootSourceIDFunc == nil {
+		panic("mocks.BootSourceSelection.BootSourceID called without BootSourceIDFunc")
+	}
+	return m.BootSourceIDFunc()
+}
+
+// Delete calls DeleteFunc.
+func (m *BootSourceSelection) Delete() error {
+	m.record("Delete")
+	if m.DeleteFunc == nil {
+		panic("mocks.BootSourceSelection.Delete called without DeleteFunc")
+	}
+	return m.DeleteFunc()
+}
+
+// ID calls IDFunc.
+func (m *BootSourceSelection) ID() int {
+	m.record("ID")
+	if m.IDFunc == nil {
+		panic("mocks.BootSourceSelection.ID called without IDFunc")
+	}
+	return m.IDFunc()
+}
+
+// Labels calls LabelsFunc.
+func (m *BootSourceSelection) Labels() []string {
+	m.record("Labels")
+	if m.LabelsFunc == nil {
+		panic("mocks.BootSourceSelection.Labels called without LabelsFunc")
+	}
+	return m.LabelsFunc()
+}
+
+// OS calls OSFunc.
+func (m *BootSourceSelection) OS() string {
+	m.record("OS")
+	if m.OSFunc == nil {
+		panic("mocks.BootSourceSelection.OS called without OSFunc")
+	}
+	return m.OSFunc()
+}
+
+// Release calls ReleaseFunc.
+func (m *BootSourceSelection) Release() string {
+	m.record("Release")
+	if m.ReleaseFunc == nil {
+		panic("mocks.BootSourceSelection.Release called without ReleaseFunc")
+	}
+	return m.ReleaseFunc()
+}
+
+// SubArchitectures calls SubArchitecturesFunc.
+func (m *BootSourceSelection) SubArchitectures() []string {
+	m.record("SubArchitectures")
+	if m.SubArchitecturesFunc == nil {
+		panic("mocks.BootSourceSelection.SubArchitectures called without SubArchitecturesFunc")
+	}
+	return m.SubArchitecturesFunc()
+}
+
+// Update calls UpdateFunc.
+func (m *BootSourceSelection) Update(arg0 gomaasapi.UpdateBootSourceSelectionArgs) error {
+	m.record("Update", arg0)
+	if m.UpdateFunc == nil {
+		panic("mocks.BootSourceSelection.Update called without UpdateFunc")
+	}
+	return m.UpdateFunc(arg0)
+}
+
+// VMHost is a mock of gomaasapi.VMHost.
+type VMHost struct {
+	ArchitecturesFunc         func() []string
+	AvailableFunc             func() gomaasapi.VMHostResources
+	CPUOverCommitRatioFunc    func() float64
+	CapabilitiesFunc          func() []string
+	ComposeFunc               func(gomaasapi.ComposeArgs) (gomaasapi.Machine, error)
+	DefaultStoragePoolFunc    func() string
+	DeleteFunc                func() error
+	HostFunc                  func() string
+	IDFunc                    func() int
+	MemoryOverCommitRatioFunc func() float64
+	NameFunc                  func() string
+	ParametersFunc            func() (map[string]interface{}, error)
+	PoolFunc                  func() gomaasapi.Pool
+	RefreshFunc               func() error
+	StoragePoolsFunc          func() []gomaasapi.VMHostStoragePool
+	TagsFunc                  func() []string
+	TotalFunc                 func() gomaasapi.VMHostResources
+	TypeFunc                  func() string
+	UpdateFunc                func(gomaasapi.UpdateVMHostArgs) error
+	UsedFunc                  func() gomaasapi.VMHostResources
+	VersionFunc               func() string
+	VirtualMachinesFunc       func() ([]gomaasapi.Machine, error)
+	ZoneFunc                  func() gomaasapi.Zone
+
+	recorder
+}
+
+var _ gomaasapi.VMHost = (*VMHost)(nil)
+
+// Architectures calls ArchitecturesFunc.
+func (m *VMHost) Architectures() []string {
+	m.record("Architectures")
+	if m.ArchitecturesFunc == nil {
+		panic("mocks.VMHost.Architectures called without ArchitecturesFunc")
+	}
+	return m.ArchitecturesFunc()
+}
+
+// Available calls AvailableFunc.
+func (m *VMHost) Available() gomaasapi.VMHostResources {
+	m.record("Available")
+	if m.AvailableFunc == nil {
+		panic("mocks.VMHost.Available called without AvailableFunc")
+	}
+	return m.AvailableFunc()
+}
+
+// CPUOverCommitRatio calls CPUOverCommitRatioFunc.
+func (m *VMHost) CPUOverCommitRatio() float64 {
+	m.record("CPUOverCommitRatio")
+	if m.CPUOverCommitRatioFunc == nil {
+		panic("mocks.VMHost.CPUOverCommitRatio called without CPUOverCommitRatioFunc")
+	}
+	return m.CPUOverCommitRatioFunc()
+}
+
+// Capabilities calls CapabilitiesFunc.
+func (m *VMHost) Capabilities() []string {
+	m.record("Capabilities")
+	if m.CapabilitiesFunc == nil {
+		panic("mocks.VMHost.Capabilities called without CapabilitiesFunc")
+	}
+	return m.CapabilitiesFunc()
+}
+
+// Compose calls ComposeFunc.
+func (m *VMHost) Compose(arg0 gomaasapi.ComposeArgs) (gomaasapi.Machine, error) {
+	m.record("Compose", arg0)
+	if m.ComposeFunc == nil {
+		panic("mocks.VMHost.Compose called without ComposeFunc")
+	}
+	return m.ComposeFunc(arg0)
+}
+
+// DefaultStoragePool calls DefaultStoragePoolFunc.
+func (m *VMHost) DefaultStoragePool() string {
+	m.record("DefaultStoragePool")
+	if m.DefaultStoragePoolFunc == nil {
+		panic("mocks.VMHost.DefaultStoragePool called without DefaultStoragePoolFunc")
+	}
+	return m.DefaultStoragePoolFunc()
+}
+
+// Delete calls DeleteFunc.
+func (m *VMHost) Delete() error {
+	m.record("Delete")
+	if m.DeleteFunc == nil {
+		panic("mocks.VMHost.Delete called without DeleteFunc")
+	}
+	return m.DeleteFunc()
+}
+
+// Host calls HostFunc.
+func (m *VMHost) Host() string {
+	m.record("Host")
+	if m.HostFunc == nil {
+		panic("mocks.VMHost.Host called without HostFunc")
+	}
+	return m.HostFunc()
+}
+
+// ID calls IDFunc.
+func (m *VMHost) ID() int {
+	m.record("ID")
+	if m.IDFunc == nil {
+		panic("mocks.VMHost.ID called without IDFunc")
+	}
+	return m.IDFunc()
+}
+
+// MemoryOverCommitRatio calls MemoryOverCommitRatioFunc.
+func (m *VMHost) MemoryOverCommitRatio() float64 {
+	m.record("MemoryOverCommitRatio")
+	if m.MemoryOverCommitRatioFunc == nil {
+		panic("mocks.VMHost.MemoryOverCommitRatio called without MemoryOverCommitRatioFunc")
+	}
+	return m.MemoryOverCommitRatioFunc()
+}
+
+// Name calls NameFunc.
+func (m *VMHost) Name() string {
+	m.record("Name")
+	if m.NameFunc == nil {
+		panic("mocks.VMHost.Name called without NameFunc")
+	}
+	return m.NameFunc()
+}
+
+// Parameters calls ParametersFunc.
+func (m *VMHost) Parameters() (map[string]interface{}, error) {
+	m.record("Parameters")
+	if m.ParametersFunc == nil {
+		panic("mocks.VMHost.Parameters called without ParametersFunc")
+	}
+	return m.ParametersFunc()
+}
+
+// Pool calls PoolFunc.
+func (m *VMHost) Pool() gomaasapi.Pool {
+	m.record("Pool")
+	if m.PoolFunc == nil {
+		panic("mocks.VMHost.Pool called without PoolFunc")
+	}
+	return m.PoolFunc()
+}
+
+// Refresh calls RefreshFunc.
+func (m *VMHost) Refresh() error {
+	m.record("Refresh")
+	if m.RefreshFunc == nil {
+		panic("mocks.VMHost.Refresh called without RefreshFunc")
+	}
+	return m.RefreshFunc()
+}
+
+// StoragePools calls StoragePoolsFunc.
+func (m *VMHost) StoragePools() []gomaasapi.VMHostStoragePool {
+	m.record("StoragePools")
+	if m.StoragePoolsFunc == nil {
+		panic("mocks.VMHost.StoragePools called without StoragePoolsFunc")
+	}
+	return m.StoragePoolsFunc()
+}
+
+// Tags calls TagsFunc.
+func (m *VMHost) Tags() []string {
+	m.record("Tags")
+	if m.TagsFunc == nil {
+		panic("mocks.VMHost.Tags called without TagsFunc")
+	}
+	return m.TagsFunc()
+}
+
+// Total calls TotalFunc.
+func (m *VMHost) Total() gomaasapi.VMHostResources {
+	m.record("Total")
+	if m.TotalFunc == nil {
+		panic("mocks.VMHost.Total called without TotalFunc")
+	}
+	return m.TotalFunc()
+}
+
+// Type calls TypeFunc.
+func (m *VMHost) Type() string {
+	m.record("Type")
+	if m.TypeFunc == nil {
+		panic("mocks.VMHost.Type called without TypeFunc")
+	}
+	return m.TypeFunc()
+}
+
+// Update calls UpdateFunc.
+func (m *VMHost) Update(arg0 gomaasapi.UpdateVMHostArgs) error {
+	m.record("Update", arg0)
+	if m.UpdateFunc == nil {
+		panic("mocks.VMHost.Update called without UpdateFunc")
+	}
+	return m.UpdateFunc(arg0)
+}
+
+// Used calls UsedFunc.
+func (m *VMHost) Used() gomaasapi.VMHostResources {
+	m.record("Used")
+	if m.UsedFunc == nil {
+		panic("mocks.VMHost.Used called without UsedFunc")
+	}
+	return m.UsedFunc()
+}
+
+// Version calls VersionFunc.
+func (m *VMHost) Version() string {
+	m.record("Version")
+	if m.VersionFunc == nil {
+		panic("mocks.VMHost.Version called without VersionFunc")
+	}
+	return m.VersionFunc()
+}
+
+// VirtualMachines calls VirtualMachinesFunc.
+func (m *VMHost) VirtualMachines() ([]gomaasapi.Machine, error) {
+	m.record("VirtualMachines")
+	if m.VirtualMachinesFunc == nil {
+		panic("mocks.VMHost.VirtualMachines called without VirtualMachinesFunc")
+	}
+	return m.VirtualMachinesFunc()
+}
+
+// Zone calls ZoneFunc.
+func (m *VMHost) Zone() gomaasapi.Zone {
+	m.record("Zone")
+	if m.ZoneFunc == nil {
+		panic("mocks.VMHost.Zone called without ZoneFunc")
+	}
+	return m.ZoneFunc()
+}
+
+// Script is a mock of gomaasapi.Script.
+type Script struct {
+	DefaultFunc      func() bool
+	DeleteFunc       func() error
+	DescriptionFunc  func() string
+	DestructiveFunc  func() bool
+	DownloadFunc     func(int) ([]byte, error)
+	HardwareTypeFunc func() string
+	HistoryFunc      func() []gomaasapi.ScriptRevision
+	IDFunc           func() int
+	NameFunc         func() string
+	ParametersFunc   func() map[string]interface{}
+	RevertFunc       func(int) error
+	TagsFunc         func() []string
+	TimeoutFunc      func() time.Duration
+	TitleFunc        func() string
+	TypeFunc         func() string
+	UpdateFunc       func(gomaasapi.UpdateScriptArgs) error
+
+	recorder
+}
+
+var _ gomaasapi.Script = (*Script)(nil)
+
+// Default calls DefaultFunc.
+func (m *Script) Default() bool {
+	m.record("Default")
+	if m.DefaultFunc == nil {
+		panic("mocks.Script.Default called without DefaultFunc")
+	}
+	return m.DefaultFunc()
+}
+
+// Delete calls DeleteFunc.
+func (m *Script) Delete() error {
+	m.record("Delete")
+	if m.DeleteFunc == nil {
+		panic("mocks.Script.Delete called without DeleteFunc")
+	}
+	return m.DeleteFunc()
+}
+
+// Description calls DescriptionFunc.
+func (m *Script) Description() string {
+	m.record("Description")
+	if m.DescriptionFunc == nil {
+		panic("mocks.Script.Description called without DescriptionFunc")
+	}
+	return m.DescriptionFunc()
+}
+
+// Destructive calls DestructiveFunc.
+func (m *Script) Destructive() bool {
+	m.record("Destructive")
+	if m.DestructiveFunc == nil {
+		panic("mocks.Script.Destructive called without DestructiveFunc")
+	}
+	return m.DestructiveFunc()
+}
+
+// Download calls DownloadFunc.
+func (m *Script) Download(revision int) ([]byte, error) {
+	m.record("Download", revision)
+	if m.DownloadFunc == nil {
+		panic("mocks.Script.Download called without DownloadFunc")
+	}
+	return m.DownloadFunc(revision)
+}
+
+// HardwareType calls HardwareTypeFunc.
+func (m *Script) HardwareType() string {
+	m.record("HardwareType")
+	if m.HardwareTypeFunc == nil {
+		panic("mocks.Script.HardwareType called without HardwareTypeFunc")
+	}
+	return m.HardwareTypeFunc()
+}
+
+// History calls HistoryFunc.
+func (m *Script) History() []gomaasapi.ScriptRevision {
+	m.record("History")
+	if m.HistoryFunc == nil {
+		panic("mocks.Script.History called without HistoryFunc")
+	}
+	return m.HistoryFunc()
+}
+
+// ID calls IDFunc.
+func (m *Script) ID() int {
+	m.record("ID")
+	if m.IDFunc == nil {
+		panic("mocks.Script.ID called without IDFunc")
+	}
+	return m.IDFunc()
+}
+
+// Name calls NameFunc.
+func (m *Script) Name() string {
+	m.record("Name")
+	if m.NameFunc == nil {
+		panic("mocks.Script.Name called without NameFunc")
+	}
+	return m.NameFunc()
+}
+
+// Parameters calls ParametersFunc.
+func (m *Script) Parameters() map[string]interface{} {
+	m.record("Parameters")
+	if m.ParametersFunc == nil {
+		panic("mocks.Script.Parameters called without ParametersFunc")
+	}
+	return m.ParametersFunc()
+}
+
+// Revert calls RevertFunc.
+func (m *Script) Revert(to int) error {
+	m.record("Revert", to)
+	if m.RevertFunc == nil {
+		panic("mocks.Script.Revert called without RevertFunc")
+	}
+	return m.RevertFunc(to)
+}
+
+// Tags calls TagsFunc.
+func (m *Script) Tags() []string {
+	m.record("Tags")
+	if m.TagsFunc == nil {
+		panic("mocks.Script.Tags called without TagsFunc")
+	}
+	return m.TagsFunc()
+}
+
+// Timeout calls TimeoutFunc.
+func (m *Script) Timeout() time.Duration {
+	m.record("Timeout")
+	if m.TimeoutFunc == nil {
+		panic("mocks.Script.Timeout called without TimeoutFunc")
+	}
+	return m.TimeoutFunc()
+}
+
+// Title calls TitleFunc.
+func (m *Script) Title() string {
+	m.record("Title")
+	if m.TitleFunc == nil {
+		panic("mocks.Script.Title called without TitleFunc")
+	}
+	return m.TitleFunc()
+}
+
+// Type calls TypeFunc.
+func (m *Script) Type() string {
+	m.record("Type")
+	if m.TypeFunc == nil {
+		panic("mocks.Script.Type called without TypeFunc")
+	}
+	return m.TypeFunc()
+}
+
+// Update calls UpdateFunc.
+func (m *Script) Update(arg0 gomaasapi.UpdateScriptArgs) error {
+	m.record("Update", arg0)
+	if m.UpdateFunc == nil {
+		panic("mocks.Script.Update called without UpdateFunc")
+	}
+	return m.UpdateFunc(arg0)
+}
+
+// Event is a mock of gomaasapi.Event.
+type Event struct {
+	CreatedFunc     func() time.Time
+	DescriptionFunc func() string
+	HostnameFunc    func() string
+	IDFunc          func() int
+	LevelFunc       func() string
+	NodeFunc        func() string
+	TypeFunc        func() string
+	UsernameFunc    func() string
+
+	recorder
+}
+
+var _ gomaasapi.Event = (*Event)(nil)
+
+// Created calls CreatedFunc.
+func (m *Event) Created() time.Time {
+	m.record("Created")
+	if m.CreatedFunc == nil {
+		panic("mocks.Event.Created called without CreatedFunc")
+	}
+	return m.CreatedFunc()
+}
+
+// Description calls DescriptionFunc.
+func (m *Event) Description() string {
+	m.record("Description")
+	if m.DescriptionFunc == nil {
+		panic("mocks.Event.Description called without DescriptionFunc")
+	}
+	return m.DescriptionFunc()
+}
+
+// Hostname calls HostnameFunc.
+func (m *Event) Hostname() string {
+	m.record("Hostname")
+	if m.HostnameFunc == nil {
+		panic("mocks.Event.Hostname called without HostnameFunc")
+	}
+	return m.HostnameFunc()
+}
+
+// ID calls IDFunc.
+func (m *Event) ID() int {
+	m.record("ID")
+	if m.IDFunc == nil {
+		panic("mocks.Event.ID called without IDFunc")
+	}
+	return m.IDFunc()
+}
+
+// Level calls LevelFunc.
+func (m *Event) Level() string {
+	m.record("Level")
+	if m.LevelFunc == nil {
+		panic("mocks.Event.Level called without LevelFunc")
+	}
+	return m.LevelFunc()
+}
+
+// Node calls NodeFunc.
+func (m *Event) Node() string {
+	m.record("Node")
+	if m.NodeFunc == nil {
+		panic("mocks.Event.Node called without NodeFunc")
+	}
+	return m.NodeFunc()
+}
+
+// Type calls TypeFunc.
+func (m *Event) Type() string {
+	m.record("Type")
+	if m.TypeFunc == nil {
+		panic("mocks.Event.Type called without TypeFunc")
+	}
+	return m.TypeFunc()
+}
+
+// Username calls UsernameFunc.
+func (m *Event) Username() string {
+	m.record("Username")
+	if m.UsernameFunc == nil {
+		panic("mocks.Event.Username called without UsernameFunc")
+	}
+	return m.UsernameFunc()
+}
+
+// Watcher is a mock of gomaasapi.Watcher.
+type Watcher struct {
+	ChangesFunc func() <-chan gomaasapi.ChangeEvent
+	CloseFunc   func() error
+
+	recorder
+}
+
+var _ gomaasapi.Watcher = (*Watcher)(nil)
+
+// Changes calls ChangesFunc.
+func (m *Watcher) Changes() <-chan gomaasapi.ChangeEvent {
+	m.record("Changes")
+	if m.ChangesFunc == nil {
+		panic("mocks.Watcher.Changes called without ChangesFunc")
+	}
+	return m.ChangesFunc()
+}
+
+// Close calls CloseFunc.
+func (m *Watcher) Close() error {
+	m.record("Close")
+	if m.CloseFunc == nil {
+		panic("mocks.Watcher.Close called without CloseFunc")
+	}
+	return m.CloseFunc()
+}
+
+// MachinesPager is a mock of gomaasapi.MachinesPager.
+type MachinesPager struct {
+	AllFunc      func() ([]gomaasapi.Machine, error)
+	NextPageFunc func() ([]gomaasapi.Machine, error)
+
+	recorder
+}
+
+var _ gomaasapi.MachinesPager = (*MachinesPager)(nil)
+
+// All calls AllFunc.
+func (m *MachinesPager) All() ([]gomaasapi.Machine, error) {
+	m.record("All")
+	if m.AllFunc == nil {
+		panic("mocks.MachinesPager.All called without AllFunc")
+	}
+	return m.AllFunc()
+}
+
+// NextPage calls NextPageFunc.
+func (m *MachinesPager) NextPage() ([]gomaasapi.Machine, error) {
+	m.record("NextPage")
+	if m.NextPageFunc == nil {
+		panic("mocks.MachinesPager.NextPage called without NextPageFunc")
+	}
+	return m.NextPageFunc()
+}
+
+// EventsPage is a mock of gomaasapi.EventsPage.
+type EventsPage struct {
+	EventsFunc func() []gomaasapi.Event
+	NextFunc   func() (gomaasapi.EventsPage, error)
+
+	recorder
+}
+
+var _ gomaasapi.EventsPage = (*EventsPage)(nil)
+
+// Events calls EventsFunc.
+func (m *EventsPage) Events() []gomaasapi.Event {
+	m.record("Events")
+	if m.EventsFunc == nil {
+		panic("mocks.EventsPage.Events called without EventsFunc")
+	}
+	return m.EventsFunc()
+}
+
+// Next calls NextFunc.
+func (m *EventsPage) Next() (gomaasapi.EventsPage, error) {
+	m.record("Next")
+	if m.NextFunc == nil {
+		panic("mocks.EventsPage.Next called without NextFunc")
+	}
+	return m.NextFunc()
+}
+
+// ChangeFeed is a mock of gomaasapi.ChangeFeed.
+type ChangeFeed struct {
+	ChangesFunc func() <-chan gomaasapi.MachineChanges
+	CloseFunc   func() error
+
+	recorder
+}
+
+var _ gomaasapi.ChangeFeed = (*ChangeFeed)(nil)
+
+// Changes calls ChangesFunc.
+func (m *ChangeFeed) Changes() <-chan gomaasapi.MachineChanges {
+	m.record("Changes")
+	if m.ChangesFunc == nil {
+		panic("mocks.ChangeFeed.Changes called without ChangesFunc")
+	}
+	return m.ChangesFunc()
+}
+
+// Close calls CloseFunc.
+func (m *ChangeFeed) Close() error {
+	m.record("Close")
+	if m.CloseFunc == nil {
+		panic("mocks.ChangeFeed.Close called without CloseFunc")
+	}
+	return m.CloseFunc()
+}
+
+// User is a mock of gomaasapi.User.
+type User struct {
+	DeleteFunc   func() error
+	EmailFunc    func() string
+	IsAdminFunc  func() bool
+	IsLocalFunc  func() bool
+	UsernameFunc func() string
+
+	recorder
+}
+
+var _ gomaasapi.User = (*User)(nil)
+
+// Delete calls DeleteFunc.
+func (m *User) Delete() error {
+	m.record("Delete")
+	if m.DeleteFunc == nil {
+		panic("mocks.User.Delete called without DeleteFunc")
+	}
+	return m.DeleteFunc()
+}
+
+// Email calls EmailFunc.
+func (m *User) Email() string {
+	m.record("Email")
+	if m.EmailFunc == nil {
+		panic("mocks.User.Email called without EmailFunc")
+	}
+	return m.EmailFunc()
+}
+
+// IsAdmin calls IsAdminFunc.
+func (m *User) IsAdmin() bool {
+	m.record("IsAdmin")
+	if m.IsAdminFunc == nil {
+		panic("mocks.User.IsAdmin called without IsAdminFunc")
+	}
+	return m.IsAdminFunc()
+}
+
+// IsLocal calls IsLocalFunc.
+func (m *User) IsLocal() bool {
+	m.record("IsLocal")
+	if m.IsLocalFunc == nil {
+		panic("mocks.User.IsLocal called without IsLocalFunc")
+	}
+	return m.IsLocalFunc()
+}
+
+// Username calls UsernameFunc.
+func (m *User) Username() string {
+	m.record("Username")
+	if m.UsernameFunc == nil {
+		panic("mocks.User.Username called without UsernameFunc")
+	}
+	return m.UsernameFunc()
+}
+
+// SSHKey is a mock of gomaasapi.SSHKey.
+type SSHKey struct {
+	DeleteFunc    func() error
+	IDFunc        func() int
+	KeyFunc       func() string
+	KeySourceFunc func() string
+
+	recorder
+}
+
+var _ gomaasapi.SSHKey = (*SSHKey)(nil)
+
+// Delete calls DeleteFunc.
+func (m *SSHKey) Delete() error {
+	m.record("Delete")
+	if m.DeleteFunc == nil {
+		panic("mocks.SSHKey.Delete called without DeleteFunc")
+	}
+	return m.DeleteFunc()
+}
+
+// ID calls IDFunc.
+func (m *SSHKey) ID() int {
+	m.record("ID")
+	if m.IDFunc == nil {
+		panic("mocks.SSHKey.ID called without IDFunc")
+	}
+	return m.IDFunc()
+}
+
+// Key calls KeyFunc.
+func (m *SSHKey) Key() string {
+	m.record("Key")
+	if m.KeyFunc == nil {
+		panic("mocks.SSHKey.Key called without KeyFunc")
+	}
+	return m.KeyFunc()
+}
+
+// KeySource calls KeySourceFunc.
+func (m *SSHKey) KeySource() string {
+	m.record("KeySource")
+	if m.KeySourceFunc == nil {
+		panic("mocks.SSHKey.KeySource called without KeySourceFunc")
+	}
+	return m.KeySourceFunc()
+}
+
+// SSLKey is a mock of gomaasapi.SSLKey.
+type SSLKey struct {
+	DeleteFunc func() error
+	IDFunc     func() int
+	KeyFunc    func() string
+
+	recorder
+}
+
+var _ gomaasapi.SSLKey = (*SSLKey)(nil)
+
+// Delete calls DeleteFunc.
+func (m *SSLKey) Delete() error {
+	m.record("Delete")
+	if m.DeleteFunc == nil {
+		panic("mocks.SSLKey.Delete called without DeleteFunc")
+	}
+	return m.DeleteFunc()
+}
+
+// ID calls IDFunc.
+func (m *SSLKey) ID() int {
+	m.record("ID")
+	if m.IDFunc == nil {
+		panic("mocks.SSLKey.ID called without IDFunc")
+	}
+	return m.IDFunc()
+}
+
+// Key calls KeyFunc.
+func (m *SSLKey) Key() string {
+	m.record("Key")
+	if m.KeyFunc == nil {
+		panic("mocks.SSLKey.Key called without KeyFunc")
+	}
+	return m.KeyFunc()
+}
+
+// LicenseKey is a mock of gomaasapi.LicenseKey.
+type LicenseKey struct {
+	DeleteFunc       func() error
+	DistroSeriesFunc func() string
+	KeyFunc          func() string
+	OSystemFunc      func() string
+	UpdateFunc       func(string) error
+
+	recorder
+}
+
+var _ gomaasapi.LicenseKey = (*LicenseKey)(nil)
+
+// Delete calls DeleteFunc.
+func (m *LicenseKey) Delete() error {
+	m.record("Delete")
+	if m.DeleteFunc == nil {
+		panic("mocks.LicenseKey.Delete called without DeleteFunc")
+	}
+	return m.DeleteFunc()
+}
+
+// DistroSeries calls DistroSeriesFunc.
+func (m *LicenseKey) DistroSeries() string {
+	m.record("DistroSeries")
+	if m.DistroSeriesFunc == nil {
+		panic("mocks.LicenseKey.DistroSeries called without DistroSeriesFunc")
+	}
+	return m.DistroSeriesFunc()
+}
+
+// Key calls KeyFunc.
+func (m *LicenseKey) Key() string {
+	m.record("Key")
+	if m.KeyFunc == nil {
+		panic("mocks.LicenseKey.Key called without KeyFunc")
+	}
+	return m.KeyFunc()
+}
+
+// OSystem calls OSystemFunc.
+func (m *LicenseKey) OSystem() string {
+	m.record("OSystem")
+	if m.OSystemFunc == nil {
+		panic("mocks.LicenseKey.OSystem called without OSystemFunc")
+	}
+	return m.OSystemFunc()
+}
+
+// Update calls UpdateFunc.
+func (m *LicenseKey) Update(key string) error {
+	m.record("Update", key)
+	if m.UpdateFunc == nil {
+		panic("mocks.LicenseKey.Update called without UpdateFunc")
+	}
+	return m.UpdateFunc(key)
+}
+
+// PackageRepository is a mock of gomaasapi.PackageRepository.
+type PackageRepository struct {
+	ArchitecturesFunc      func() []string
+	ComponentsFunc         func() []string
+	DeleteFunc             func() error
+	DisableSourcesFunc     func() bool
+	DisabledComponentsFunc func() []string
+	DisabledPocketsFunc    func() []string
+	DistributionsFunc      func() []string
+	EnabledFunc            func() bool
+	IDFunc                 func() int
+	KeyFunc                func() string
+	NameFunc               func() string
+	URLFunc                func() string
+	UpdateFunc             func(gomaasapi.PackageRepositoryArgs) error
+
+	recorder
+}
+
+var _ gomaasapi.PackageRepository = (*PackageRepository)(nil)
+
+// Architectures calls ArchitecturesFunc.
+func (m *PackageRepository) Architectures() []string {
+	m.record("Architectures")
+	if m.ArchitecturesFunc == nil {
+		panic("mocks.PackageRepository.Architectures called without ArchitecturesFunc")
+	}
+	return m.ArchitecturesFunc()
+}
+
+// Components calls ComponentsFunc.
+func (m *PackageRepository) Components() []string {
+	m.record("Components")
+	if m.ComponentsFunc == nil {
+		panic("mocks.PackageRepository.Components called without ComponentsFunc")
+	}
+	return m.ComponentsFunc()
+}
+
+// Delete calls DeleteFunc.
+func (m *PackageRepository) Delete() error {
+	m.record("Delete")
+	if m.DeleteFunc == nil {
+		panic("mocks.PackageRepository.Delete called without DeleteFunc")
+	}
+	return m.DeleteFunc()
+}
+
+// DisableSources calls DisableSourcesFunc.
+func (m *PackageRepository) DisableSources() bool {
+	m.record("DisableSources")
+	if m.DisableSourcesFunc == nil {
+		panic("mocks.PackageRepository.DisableSources called without DisableSourcesFunc")
+	}
+	return m.DisableSourcesFunc()
+}
+
+// DisabledComponents calls DisabledComponentsFunc.
+func (m *PackageRepository) DisabledComponents() []string {
+	m.record("DisabledComponents")
+	if m.DisabledComponentsFunc == nil {
+		panic("mocks.PackageRepository.DisabledComponents called without DisabledComponentsFunc")
+	}
+	return m.DisabledComponentsFunc()
+}
+
+// DisabledPockets calls DisabledPocketsFunc.
+func (m *PackageRepository) DisabledPockets() []string {
+	m.record("DisabledPockets")
+	if m.DisabledPocketsFunc == nil {
+		panic("mocks.PackageRepository.DisabledPockets called without DisabledPocketsFunc")
+	}
+	return m.DisabledPocketsFunc()
+}
+
+// Distributions calls DistributionsFunc.
+func (m *PackageRepository) Distributions() []string {
+	m.record("Distributions")
+	if m.DistributionsFunc == nil {
+		panic("mocks.PackageRepository.Distributions called without DistributionsFunc")
+	}
+	return m.DistributionsFunc()
+}
+
+// Enabled calls EnabledFunc.
+func (m *PackageRepository) Enabled() bool {
+	m.record("Enabled")
+	if m.EnabledFunc == nil {
+		panic("mocks.PackageRepository.Enabled called without EnabledFunc")
+	}
+	return m.EnabledFunc()
+}
+
+// ID calls IDFunc.
+func (m *PackageRepository) ID() int {
+	m.record("ID")
+	if m.IDFunc == nil {
+		panic("mocks.PackageRepository.ID called without IDFunc")
+	}
+	return m.IDFunc()
+}
+
+// Key calls KeyFunc.
+func (m *PackageRepository) Key() string {
+	m.record("Key")
+	if m.KeyFunc == nil {
+		panic("mocks.PackageRepository.Key called without KeyFunc")
+	}
+	return m.KeyFunc()
+}
+
+// Name calls NameFunc.
+func (m *PackageRepository) Name() string {
+	m.record("Name")
+	if m.NameFunc == nil {
+		panic("mocks.PackageRepository.Name called without NameFunc")
+	}
+	return m.NameFunc()
+}
+
+// URL calls URLFunc.
+func (m *PackageRepository) URL() string {
+	m.record("URL")
+	if m.URLFunc == nil {
+		panic("mocks.PackageRepository.URL called without URLFunc")
+	}
+	return m.URLFunc()
+}
+
+// Update calls UpdateFunc.
+func (m *PackageRepository) Update(arg0 gomaasapi.PackageRepositoryArgs) error {
+	m.record("Update", arg0)
+	if m.UpdateFunc == nil {
+		panic("mocks.PackageRepository.Update called without UpdateFunc")
+	}
+	return m.UpdateFunc(arg0)
+}
+
+// Device is a mock of gomaasapi.Device.
+type Device struct {
+	CreateInterfaceFunc func(gomaasapi.CreateInterfaceArgs) (gomaasapi.Interface, error)
+	DeleteFunc          func() error
+	FQDNFunc            func() string
+	HostnameFunc        func() string
+	IPAddressesFunc     func() []string
+	InterfaceSetFunc    func() []gomaasapi.Interface
+	OwnerFunc           func() string
+	ParentFunc          func() string
+	PoolFunc            func() gomaasapi.Pool
+	SystemIDFunc        func() string
+	ZoneFunc            func() gomaasapi.Zone
+
+	recorder
+}
+
+var _ gomaasapi.Device = (*Device)(nil)
+
+// CreateInterface calls CreateInterfaceFunc.
+func (m *Device) CreateInterface(arg0 gomaasapi.CreateInterfaceArgs) (gomaasapi.Interface, error) {
+	m.record("CreateInterface", arg0)
+	if m.CreateInterfaceFunc == nil {
+		panic("mocks.Device.CreateInterface called without CreateInterfaceFunc")
+	}
+	return m.CreateInterfaceFunc(arg0)
+}
+
+// Delete calls DeleteFunc.
+func (m *Device) Delete() error {
+	m.record("Delete")
+	if m.DeleteFunc == nil {
+		panic("mocks.Device.Delete called without DeleteFunc")
+	}
+	return m.DeleteFunc()
+}
+
+// FQDN calls FQDNFunc.
+func (m *Device) FQDN() string {
+	m.record("FQDN")
+	if m.FQDNFunc == nil {
+		panic("mocks.Device.FQDN called without FQDNFunc")
+	}
+	return m.FQDNFunc()
+}
+
+// Hostname calls HostnameFunc.
+func (m *Device) Hostname() string {
+	m.record("Hostname")
+	if m.HostnameFunc == nil {
+		panic("mocks.Device.Hostname called without HostnameFunc")
+	}
+	return m.HostnameFunc()
+}
+
+// IPAddresses calls IPAddressesFunc.
+func (m *Device) IPAddresses() []string {
+	m.record("IPAddresses")
+	if m.IPAddressesFunc == nil {
+		panic("mocks.Device.IPAddresses called without IPAddressesFunc")
+	}
+	return m.IPAddressesFunc()
+}
+
+// InterfaceSet calls InterfaceSetFunc.
+func (m *Device) InterfaceSet() []gomaasapi.Interface {
+	m.record("InterfaceSet")
+	if m.InterfaceSetFunc == nil {
+		panic("mocks.Device.InterfaceSet called without InterfaceSetFunc")
+	}
+	return m.InterfaceSetFunc()
+}
+
+// Owner calls OwnerFunc.
+func (m *Device) Owner() string {
+	m.record("Owner")
+	if m.OwnerFunc == nil {
+		panic("mocks.Device.Owner called without OwnerFunc")
+	}
+	return m.OwnerFunc()
+}
+
+// Parent calls ParentFunc.
+func (m *Device) Parent() string {
+	m.record("Parent")
+	if m.ParentFunc == nil {
+		panic("mocks.Device.Parent called without ParentFunc")
+	}
+	return m.ParentFunc()
+}
+
+// Pool calls PoolFunc.
+func (m *Device) Pool() gomaasapi.Pool {
+	m.record("Pool")
+	if m.PoolFunc == nil {
+		panic("mocks.Device.Pool called without PoolFunc")
+	}
+	return m.PoolFunc()
+}
+
+// SystemID calls SystemIDFunc.
+func (m *Device) SystemID() string {
+	m.record("SystemID")
+	if m.SystemIDFunc == nil {
+		panic("mocks.Device.SystemID called without SystemIDFunc")
+	}
+	return m.SystemIDFunc()
+}
+
+// Zone calls ZoneFunc.
+func (m *Device) Zone() gomaasapi.Zone {
+	m.record("Zone")
+	if m.ZoneFunc == nil {
+		panic("mocks.Device.Zone called without ZoneFunc")
+	}
+	return m.ZoneFunc()
+}
+
+// Machine is a mock of gomaasapi.Machine.
+type Machine struct {
+	ArchitectureFunc         func() string
+	BIOSBootMethodFunc       func() string
+	BlockDeviceFunc          func(int) gomaasapi.BlockDevice
+	BlockDevicesFunc         func() []gomaasapi.BlockDevice
+	BootInterfaceFunc        func() gomaasapi.Interface
+	CPUCountFunc             func() int
+	CaptureLayoutFunc        func() (gomaasapi.MachineLayout, error)
+	CreateBlockDeviceFunc    func(gomaasapi.CreateBlockDeviceArgs) (gomaasapi.BlockDevice, error)
+	CreateDeviceFunc         func(gomaasapi.CreateMachineDeviceArgs) (gomaasapi.Device, error)
+	CreateRAIDFunc           func(gomaasapi.CreateRAIDArgs) (gomaasapi.RAID, error)
+	CreateVMFSDatastoreFunc  func(gomaasapi.CreateVMFSDatastoreArgs) (gomaasapi.VMFSDatastore, error)
+	CreateVolumeGroupFunc    func(gomaasapi.CreateVolumeGroupArgs) (gomaasapi.VolumeGroup, error)
+	DPUFunc                  func() bool
+	DeployedImageFunc        func() (gomaasapi.DeployedImage, bool)
+	DevicesFunc              func(gomaasapi.DevicesArgs) ([]gomaasapi.Device, error)
+	DisklessFunc             func() bool
+	DistroSeriesFunc         func() string
+	EphemeralDeployFunc      func() bool
+	FQDNFunc                 func() string
+	HardwareInfoFunc         func() map[string]string
+	HardwareSyncFunc         func() bool
+	HostnameFunc             func() string
+	IPAddressesFunc          func() []string
+	InterfaceFunc            func(int) gomaasapi.Interface
+	InterfaceByNameFunc      func(string) gomaasapi.Interface
+	InterfaceChildrenFunc    func(gomaasapi.Interface) []gomaasapi.Interface
+	InterfaceParentsFunc     func(gomaasapi.Interface) []gomaasapi.Interface
+	InterfaceSetFunc         func() []gomaasapi.Interface
+	MemoryFunc               func() int
+	NetbootFunc              func() bool
+	OperatingSystemFunc      func() string
+	OwnerDataFunc            func() map[string]string
+	PXEDHCPProviderFunc      func() gomaasapi.DHCPProvider
+	PartitionFunc            func(int) gomaasapi.Partition
+	PhysicalBlockDeviceFunc  func(int) gomaasapi.BlockDevice
+	PhysicalBlockDevicesFunc func() []gomaasapi.BlockDevice
+	PoolFunc                 func() gomaasapi.Pool
+	PowerParametersFunc      func() (map[string]interface{}, error)
+	PowerStateFunc           func() string
+	PowerTypeFunc            func() string
+	RAIDsFunc                func() ([]gomaasapi.RAID, error)
+	RecoverFromFailureFunc   func(gomaasapi.RecoverFromFailureArgs) ([]gomaasapi.RecoveryAction, error)
+	SetDefaultGatewayFunc    func(int, int) error
+	SetNetbootFunc           func(bool) error
+	SetOwnerDataFunc         func(map[string]string) error
+	StartFunc                func(gomaasapi.StartArgs) error
+	StatusMessageFunc        func() string
+	StatusNameFunc           func() string
+	SystemIDFunc             func() string
+	TagsFunc                 func() []string
+	UpdatedFunc              func() time.Time
+	VMFSDatastoresFunc       func() ([]gomaasapi.VMFSDatastore, error)
+	VirtualBlockDevicesFunc  func() []gomaasapi.BlockDevice
+	VolumeGroupsFunc         func() ([]gomaasapi.VolumeGroup, error)
+	WalkInterfacesFunc       func(func(iface gomaasapi.Interface, depth int) error) error
+	ZoneFunc                 func() gomaasapi.Zone
+
+	recorder
+}
+
+var _ gomaasapi.Machine = (*Machine)(nil)
+
+// Architecture calls ArchitectureFunc.
+func (m *Machine) Architecture() string {
+	m.record("Architecture")
+	if m.ArchitectureFunc == nil {
+		panic("mocks.Machine.Architecture called without ArchitectureFunc")
+	}
+	return m.ArchitectureFunc()
+}
+
+// BIOSBootMethod calls BIOSBootMethodFunc.
+func (m *Machine) BIOSBootMethod() string {
+	m.record("BIOSBootMethod")
+	if m.BIOSBootMethodFunc == nil {
+		panic("mocks.Machine.BIOSBootMethod called without BIOSBootMethodFunc")
+	}
+	return m.BIOSBootMethodFunc()
+}
+
+// BlockDevice calls BlockDeviceFunc.
+func (m *Machine) BlockDevice(id int) gomaasapi.BlockDevice {
+	m.record("BlockDevice", id)
+	if m.BlockDeviceFunc == nil {
+		panic("mocks.Machine.BlockDevice called without BlockDeviceFunc")
+	}
+	return m.BlockDeviceFunc(id)
+}
+
+// BlockDevices calls BlockDevicesFunc.
+func (m *Machine) BlockDevices() []gomaasapi.BlockDevice {
+	m.record("BlockDevices")
+	if m.BlockDevicesFunc == nil {
+		panic("mocks.Machine.BlockDevices called without BlockDevicesFunc")
+	}
+	return m.BlockDevicesFunc()
+}
+
+// BootInterface calls BootInterfaceFunc.
+func (m *Machine) BootInterface() gomaasapi.Interface {
+	m.record("BootInterface")
+	if m.BootInterfaceFunc == nil {
+		panic("mocks.Machine.BootInterface called without BootInterfaceFunc")
+	}
+	return m.BootInterfaceFunc()
+}
+
+// CPUCount calls CPUCountFunc.
+func (m *Machine) CPUCount() int {
+	m.record("CPUCount")
+	if m.CPUCountFunc == nil {
+		panic("mocks.Machine.CPUCount called without CPUCountFunc")
+	}
+	return m.CPUCountFunc()
+}
+
+// CaptureLayout calls CaptureLayoutFunc.
+func (m *Machine) CaptureLayout() (gomaasapi.MachineLayout, error) {
+	m.record("CaptureLayout")
+	if m.CaptureLayoutFunc == nil {
+		panic("mocks.Machine.CaptureLayout called without CaptureLayoutFunc")
+	}
+	return m.CaptureLayoutFunc()
+}
+
+// CreateBlockDevice calls CreateBlockDeviceFunc.
+func (m *Machine) CreateBlockDevice(arg0 gomaasapi.CreateBlockDeviceArgs) (gomaasapi.BlockDevice, error) {
+	m.record("CreateBlockDevice", arg0)
+	if m.CreateBlockDeviceFunc == nil {
+		panic("mocks.Machine.CreateBlockDevice called without CreateBlockDeviceFunc")
+	}
+	return m.CreateBlockDeviceFunc(arg0)
+}
+
+// CreateDevice calls CreateDeviceFunc.
+func (m *Machine) CreateDevice(arg0 gomaasapi.CreateMachineDeviceArgs) (gomaasapi.Device, error) {
+	m.record("CreateDevice", arg0)
+	if m.CreateDeviceFunc == nil {
+		panic("mocks.Machine.CreateDevice called without CreateDeviceFunc")
+	}
+	return m.CreateDeviceFunc(arg0)
+}
+
+// CreateRAID calls CreateRAIDFunc.
+func (m *Machine) CreateRAID(arg0 gomaasapi.CreateRAIDArgs) (gomaasapi.RAID, error) {
+	m.record("CreateRAID", arg0)
+	if m.CreateRAIDFunc == nil {
+		panic("mocks.Machine.CreateRAID called without CreateRAIDFunc")
+	}
+	return m.CreateRAIDFunc(arg0)
+}
+
+// CreateVMFSDatastore calls CreateVMFSDatastoreFunc.
+func (m *Machine) CreateVMFSDatastore(arg0 gomaasapi.CreateVMFSDatastoreArgs) (gomaasapi.VMFSDatastore, error) {
+	m.record("CreateVMFSDatastore", arg0)
+	if m.CreateVMFSDatastoreFunc == nil {
+		panic("mocks.Machine.CreateVMFSDatastore called without CreateVMFSDatastoreFunc")
+	}
+	return m.CreateVMFSDatastoreFunc(arg0)
+}
+
+// CreateVolumeGroup calls CreateVolumeGroupFunc.
+func (m *Machine) CreateVolumeGroup(arg0 gomaasapi.CreateVolumeGroupArgs) (gomaasapi.VolumeGroup, error) {
+	m.record("CreateVolumeGroup", arg0)
+	if m.CreateVolumeGroupFunc == nil {
+		panic("mocks.Machine.CreateVolumeGroup called without CreateVolumeGroupFunc")
+	}
+	return m.CreateVolumeGroupFunc(arg0)
+}
+
+// DPU calls DPUFunc.
+func (m *Machine) DPU() bool {
+	m.record("DPU")
+	if m.DPUFunc == nil {
+		panic("mocks.Machine.DPU called without DPUFunc")
+	}
+	return m.DPUFunc()
+}
+
+// DeployedImage calls DeployedImageFunc.
+func (m *Machine) DeployedImage() (gomaasapi.DeployedImage, bool) {
+	m.record("DeployedImage")
+	if m.DeployedImageFunc == nil {
+		panic("mocks.Machine.DeployedImage called without DeployedImageFunc")
+	}
+	return m.DeployedImageFunc()
+}
+
+// Devices calls DevicesFunc.
+func (m *Machine) Devices(arg0 gomaasapi.DevicesArgs) ([]gomaasapi.Device, error) {
+	m.record("Devices", arg0)
+	if m.DevicesFunc == nil {
+		panic("mocks.Machine.Devices called without DevicesFunc")
+	}
+	return m.DevicesFunc(arg0)
+}
+
+// Diskless calls DisklessFunc.
+func (m *Machine) Diskless() bool {
+	m.record("Diskless")
+	if m.DisklessFunc == nil {
+		panic("mocks.Machine.Diskless called without DisklessFunc")
+	}
+	return m.DisklessFunc()
+}
+
+// DistroSeries calls DistroSeriesFunc.
+func (m *Machine) DistroSeries() string {
+	m.record("DistroSeries")
+	if m.DistroSeriesFunc == nil {
+		panic("mocks.Machine.DistroSeries called without DistroSeriesFunc")
+	}
+	return m.DistroSeriesFunc()
+}
+
+// EphemeralDeploy calls EphemeralDeployFunc.
+func (m *Machine) EphemeralDeploy() bool {
+	m.record("EphemeralDeploy")
+	if m.EphemeralDeployFunc == nil {
+		panic("mocks.Machine.EphemeralDeploy called without EphemeralDeployFunc")
+	}
+	return m.EphemeralDeployFunc()
+}
+
+// FQDN calls FQDNFunc.
+func (m *Machine) FQDN() string {
+	m.record("FQDN")
+	if m.FQDNFunc == nil {
+		panic("mocks.Machine.FQDN called without FQDNFunc")
+	}
+	return m.FQDNFunc()
+}
+
+// HardwareInfo calls HardwareInfoFunc.
+func (m *Machine) HardwareInfo() map[string]string {
+	m.record("HardwareInfo")
+	if m.HardwareInfoFunc == nil {
+		panic("mocks.Machine.HardwareInfo called without HardwareInfoFunc")
+	}
+	return m.HardwareInfoFunc()
+}
+
+// HardwareSync calls HardwareSyncFunc.
+func (m *Machine) HardwareSync() bool {
+	m.record("HardwareSync")
+	if m.HardwareSyncFunc == nil {
+		panic("mocks.Machine.HardwareSync called without HardwareSyncFunc")
+	}
+	return m.HardwareSyncFunc()
+}
+
+// Hostname calls HostnameFunc.
+func (m *Machine) Hostname() string {
+	m.record("Hostname")
+	if m.HostnameFunc == nil {
+		panic("mocks.Machine.Hostname called without HostnameFunc")
+	}
+	return m.HostnameFunc()
+}
+
+// IPAddresses calls IPAddressesFunc.
+func (m *Machine) IPAddresses() []string {
+	m.record("IPAddresses")
+	if m.IPAddressesFunc == nil {
+		panic("mocks.Machine.IPAddresses called without IPAddressesFunc")
+	}
+	return m.IPAddressesFunc()
+}
+
+// Interface calls InterfaceFunc.
+func (m *Machine) Interface(id int) gomaasapi.Interface {
+	m.record("Interface", id)
+	if m.InterfaceFunc == nil {
+		panic("mocks.Machine.Interface called without InterfaceFunc")
+	}
+	return m.InterfaceFunc(id)
+}
+
+// InterfaceByName calls InterfaceByNameFunc.
+func (m *Machine) InterfaceByName(name string) gomaasapi.Interface {
+	m.record("InterfaceByName", name)
+	if m.InterfaceByNameFunc == nil {
+		panic("mocks.Machine.InterfaceByName called without InterfaceByNameFunc")
+	}
+	return m.InterfaceByNameFunc(name)
+}
+
+// InterfaceChildren calls InterfaceChildrenFunc.
+func (m *Machine) InterfaceChildren(arg0 gomaasapi.Interface) []gomaasapi.Interface {
+	m.record("InterfaceChildren", arg0)
+	if m.InterfaceChildrenFunc == nil {
+		panic("mocks.Machine.InterfaceChildren called without InterfaceChildrenFunc")
+	}
+	return m.InterfaceChildrenFunc(arg0)
+}
+
+// InterfaceParents calls InterfaceParentsFunc.
+func (m *Machine) InterfaceParents(arg0 gomaasapi.Interface) []gomaasapi.Interface {
+	m.record("InterfaceParents", arg0)
+	if m.InterfaceParentsFunc == nil {
+		panic("mocks.Machine.InterfaceParents called without InterfaceParentsFunc")
+	}
+	return m.InterfaceParentsFunc(arg0)
+}
+
+// InterfaceSet calls InterfaceSetFunc.
+func (m *Machine) InterfaceSet() []gomaasapi.Interface {
+	m.record("InterfaceSet")
+	if m.InterfaceSetFunc == nil {
+		panic("mocks.Machine.InterfaceSet called without InterfaceSetFunc")
+	}
+	return m.InterfaceSetFunc()
+}
+
+// Memory calls MemoryFunc.
+func (m *Machine) Memory() int {
+	m.record("Memory")
+	if m.MemoryFunc == nil {
+		panic("mocks.Machine.Memory called without MemoryFunc")
+	}
+	return m.MemoryFunc()
+}
+
+// Netboot calls NetbootFunc.
+func (m *Machine) Netboot() bool {
+	m.record("Netboot")
+	if m.NetbootFunc == nil {
+		panic("mocks.Machine.Netboot called without NetbootFunc")
+	}
+	return m.NetbootFunc()
+}
+
+// OperatingSystem calls OperatingSystemFunc.
+func (m *Machine) OperatingSystem() string {
+	m.record("OperatingSystem")
+	if m.OperatingSystemFunc == nil {
+		panic("mocks.Machine.OperatingSystem called without OperatingSystemFunc")
+	}
+	return m.OperatingSystemFunc()
+}
+
+// OwnerData calls OwnerDataFunc.
+func (m *Machine) OwnerData() map[string]string {
+	m.record("OwnerData")
+	if m.OwnerDataFunc == nil {
+		panic("mocks.Machine.OwnerData called without OwnerDataFunc")
+	}
+	return m.OwnerDataFunc()
+}
+
+// PXEDHCPProvider calls PXEDHCPProviderFunc.
+func (m *Machine) PXEDHCPProvider() gomaasapi.DHCPProvider {
+	m.record("PXEDHCPProvider")
+	if m.PXEDHCPProviderFunc == nil {
+		panic("mocks.Machine.PXEDHCPProvider called without PXEDHCPProviderFunc")
+	}
+	return m.PXEDHCPProviderFunc()
+}
+
+// Partition calls PartitionFunc.
+func (m *Machine) Partition(id int) gomaasapi.Partition {
+	m.record("Partition", id)
+	if m.PartitionFunc == nil {
+		panic("mocks.Machine.Partition called without PartitionFunc")
+	}
+	return m.PartitionFunc(id)
+}
+
+// PhysicalBlockDevice calls PhysicalBlockDeviceFunc.
+func (m *Machine) PhysicalBlockDevice(id int) gomaasapi.BlockDevice {
+	m.record("PhysicalBlockDevice", id)
+	if m.PhysicalBlockDeviceFunc == nil {
+		panic("mocks.Machine.PhysicalBlockDevice called without PhysicalBlockDeviceFunc")
+	}
+	return m.PhysicalBlockDeviceFunc(id)
+}
+
+// PhysicalBlockDevices calls PhysicalBlockDevicesFunc.
+func (m *Machine) PhysicalBlockDevices() []gomaasapi.BlockDevice {
+	m.record("PhysicalBlockDevices")
+	if m.PhysicalBlockDevicesFunc == nil {
+		panic("mocks.Machine.PhysicalBlockDevices called without PhysicalBlockDevicesFunc")
+	}
+	return m.PhysicalBlockDevicesFunc()
+}
+
+// Pool calls PoolFunc.
+func (m *Machine) Pool() gomaasapi.Pool {
+	m.record("Pool")
+	if m.PoolFunc == nil {
+		panic("mocks.Machine.Pool called without PoolFunc")
+	}
+	return m.PoolFunc()
+}
+
+// PowerParameters calls PowerParametersFunc.
+func (m *Machine) PowerParameters() (map[string]interface{}, error) {
+	m.record("PowerParameters")
+	if m.PowerParametersFunc == nil {
+		panic("mocks.Machine.PowerParameters called without PowerParametersFunc")
+	}
+	return m.PowerParametersFunc()
+}
+
+// PowerState calls PowerStateFunc.
+func (m *Machine) PowerState() string {
+	m.record("PowerState")
+	if m.PowerStateFunc == nil {
+		panic("mocks.Machine.PowerState called without PowerStateFunc")
+	}
+	return m.PowerStateFunc()
+}
+
+// PowerType calls PowerTypeFunc.
+func (m *Machine) PowerType() string {
+	m.record("PowerType")
+	if m.PowerTypeFunc == nil {
+		panic("mocks.Machine.PowerType called without PowerTypeFunc")
+	}
+	return m.PowerTypeFunc()
+}
+
+// RAIDs calls RAIDsFunc.
+func (m *Machine) RAIDs() ([]gomaasapi.RAID, error) {
+	m.record("RAIDs")
+	if m.RAIDsFunc == nil {
+		panic("mocks.Machine.RAIDs called without RAIDsFunc")
+	}
+	return m.RAIDsFunc()
+}
+
+// RecoverFromFailure calls RecoverFromFailureFunc.
+func (m *Machine) RecoverFromFailure(arg0 gomaasapi.RecoverFromFailureArgs) ([]gomaasapi.RecoveryAction, error) {
+	m.record("RecoverFromFailure", arg0)
+	if m.RecoverFromFailureFunc == nil {
+		panic("mocks.Machine.RecoverFromFailure called without RecoverFromFailureFunc")
+	}
+	return m.RecoverFromFailureFunc(arg0)
+}
+
+// SetDefaultGateway calls SetDefaultGatewayFunc.
+func (m *Machine) SetDefaultGateway(interfaceID int, linkID int) error {
+	m.record("SetDefaultGateway", interfaceID, linkID)
+	if m.SetDefaultGatewayFunc == nil {
+		panic("mocks.Machine.SetDefaultGateway called without SetDefaultGatewayFunc")
+	}
+	return m.SetDefaultGatewayFunc(interfaceID, linkID)
+}
+
+// SetNetboot calls SetNetbootFunc.
+func (m *Machine) SetNetboot(enabled bool) error {
+	m.record("SetNetboot", enabled)
+	if m.SetNetbootFunc == nil {
+		panic("mocks.Machine.SetNetboot called without SetNetbootFunc")
+	}
+	return m.SetNetbootFunc(enabled)
+}
+
+// SetOwnerData calls SetOwnerDataFunc.
+func (m *Machine) SetOwnerData(arg0 map[string]string) error {
+	m.record("SetOwnerData", arg0)
+	if m.SetOwnerDataFunc == nil {
+		panic("mocks.Machine.SetOwnerData called without SetOwnerDataFunc")
+	}
+	return m.SetOwnerDataFunc(arg0)
+}
+
+// Start calls StartFunc.
+func (m *Machine) Start(arg0 gomaasapi.StartArgs) error {
+	m.record("Start", arg0)
+	if m.StartFunc == nil {
+		panic("mocks.Machine.Start called without StartFunc")
+	}
+	return m.StartFunc(arg0)
+}
+
+// StatusMessage calls StatusMessageFunc.
+func (m *Machine) StatusMessage() string {
+	m.record("StatusMessage")
+	if m.StatusMessageFunc == nil {
+		panic("mocks.Machine.StatusMessage called without StatusMessageFunc")
+	}
+	return m.StatusMessageFunc()
+}
+
+// StatusName calls StatusNameFunc.
+func (m *Machine) StatusName() string {
+	m.record("StatusName")
+	if m.StatusNameFunc == nil {
+		panic("mocks.Machine.StatusName called without StatusNameFunc")
+	}
+	return m.StatusNameFunc()
+}
+
+// SystemID calls SystemIDFunc.
+func (m *Machine) SystemID() string {
+	m.record("SystemID")
+	if m.SystemIDFunc == nil {
+		panic("mocks.Machine.SystemID called without SystemIDFunc")
+	}
+	return m.SystemIDFunc()
+}
+
+// Tags calls TagsFunc.
+func (m *Machine) Tags() []string {
+	m.record("Tags")
+	if m.TagsFunc == nil {
+		panic("mocks.Machine.Tags called without TagsFunc")
+	}
+	return m.TagsFunc()
+}
+
+// Updated calls UpdatedFunc.
+func (m *Machine) Updated() time.Time {
+	m.record("Updated")
+	if m.UpdatedFunc == nil {
+		panic("mocks.Machine.Updated called without UpdatedFunc")
+	}
+	return m.UpdatedFunc()
+}
+
+// VMFSDatastores calls VMFSDatastoresFunc.
+func (m *Machine) VMFSDatastores() ([]gomaasapi.VMFSDatastore, error) {
+	m.record("VMFSDatastores")
+	if m.VMFSDatastoresFunc == nil {
+		panic("mocks.Machine.VMFSDatastores called without VMFSDatastoresFunc")
+	}
+	return m.VMFSDatastoresFunc()
+}
+
+// VirtualBlockDevices calls VirtualBlockDevicesFunc.
+func (m *Machine) VirtualBlockDevices() []gomaasapi.BlockDevice {
+	m.record("VirtualBlockDevices")
+	if m.VirtualBlockDevicesFunc == nil {
+		panic("mocks.Machine.VirtualBlockDevices called without VirtualBlockDevicesFunc")
+	}
+	return m.VirtualBlockDevicesFunc()
+}
+
+// VolumeGroups calls VolumeGroupsFunc.
+func (m *Machine) VolumeGroups() ([]gomaasapi.VolumeGroup, error) {
+	m.record("VolumeGroups")
+	if m.VolumeGroupsFunc == nil {
+		panic("mocks.Machine.VolumeGroups called without VolumeGroupsFunc")
+	}
+	return m.VolumeGroupsFunc()
+}
+
+// WalkInterfaces calls WalkInterfacesFunc.
+func (m *Machine) WalkInterfaces(walkFn func(iface gomaasapi.Interface, depth int) error) error {
+	m.record("WalkInterfaces", walkFn)
+	if m.WalkInterfacesFunc == nil {
+		panic("mocks.Machine.WalkInterfaces called without WalkInterfacesFunc")
+	}
+	return m.WalkInterfacesFunc(walkFn)
+}
+
+// Zone calls ZoneFunc.
+func (m *Machine) Zone() gomaasapi.Zone {
+	m.record("Zone")
+	if m.ZoneFunc == nil {
+		panic("mocks.Machine.Zone called without ZoneFunc")
+	}
+	return m.ZoneFunc()
+}
+
+// Space is a mock of gomaasapi.Space.
+type Space struct {
+	AssignVLANFunc  func(gomaasapi.VLAN) error
+	DeleteFunc      func() error
+	DescriptionFunc func() string
+	IDFunc          func() int
+	NameFunc        func() string
+	SubnetsFunc     func() []gomaasapi.Subnet
+	UpdateFunc      func(gomaasapi.UpdateSpaceArgs) error
+
+	recorder
+}
+
+var _ gomaasapi.Space = (*Space)(nil)
+
+// AssignVLAN calls AssignVLANFunc.
+func (m *Space) AssignVLAN(arg0 gomaasapi.VLAN) error {
+	m.record("AssignVLAN", arg0)
+	if m.AssignVLANFunc == nil {
+		panic("mocks.Space.AssignVLAN called without AssignVLANFunc")
+	}
+	return m.AssignVLANFunc(arg0)
+}
+
+// Delete calls DeleteFunc.
+func (m *Space) Delete() error {
+	m.record("Delete")
+	if m.DeleteFunc == nil {
+		panic("mocks.Space.Delete called without DeleteFunc")
+	}
+	return m.DeleteFunc()
+}
+
+// Description calls DescriptionFunc.
+func (m *Space) Description() string {
+	m.record("Description")
+	if m.DescriptionFunc == nil {
+		panic("mocks.Space.Description called without DescriptionFunc")
+	}
+	return m.DescriptionFunc()
+}
+
+// ID calls IDFunc.
+func (m *Space) ID() int {
+	m.record("ID")
+	if m.IDFunc == nil {
+		panic("mocks.Space.ID called without IDFunc")
+	}
+	return m.IDFunc()
+}
+
+// Name calls NameFunc.
+func (m *Space) Name() string {
+	m.record("Name")
+	if m.NameFunc == nil {
+		panic("mocks.Space.Name called without NameFunc")
+	}
+	return m.NameFunc()
+}
+
+// Subnets calls SubnetsFunc.
+func (m *Space) Subnets() []gomaasapi.Subnet {
+	m.record("Subnets")
+	if m.SubnetsFunc == nil {
+		panic("mocks.Space.Subnets called without SubnetsFunc")
+	}
+	return m.SubnetsFunc()
+}
+
+// Update calls UpdateFunc.
+func (m *Space) Update(arg0 gomaasapi.UpdateSpaceArgs) error {
+	m.record("Update", arg0)
+	if m.UpdateFunc == nil {
+		panic("mocks.Space.Update called without UpdateFunc")
+	}
+	return m.UpdateFunc(arg0)
+}
+
+// Subnet is a mock of gomaasapi.Subnet.
+type Subnet struct {
+	ActiveDiscoveryFunc           func() bool
+	AllowDNSFunc                  func() bool
+	AllowProxyFunc                func() bool
+	CIDRFunc                      func() string
+	DNSServersFunc                func() []string
+	DeleteFunc                    func() error
+	DisabledBootArchitecturesFunc func() []string
+	GatewayFunc                   func() string
+	IDFunc                        func() int
+	IPAddressesFunc               func() ([]gomaasapi.SubnetIPAddress, error)
+	LastScanFunc                  func() gomaasapi.ScanResult
+	ManagedFunc                   func() bool
+	NameFunc                      func() string
+	ReservedIPRangesFunc          func() ([]gomaasapi.SubnetAddressRange, error)
+	ScanFunc                      func(gomaasapi.SubnetScanArgs) (gomaasapi.ScanResult, error)
+	SpaceFunc                     func() string
+	StatisticsFunc                func() (gomaasapi.SubnetStatistics, error)
+	UnreservedIPRangesFunc        func() ([]gomaasapi.SubnetAddressRange, error)
+	UpdateFunc                    func(gomaasapi.UpdateSubnetArgs) error
+	VLANFunc                      func() gomaasapi.VLAN
+
+	recorder
+}
+
+var _ gomaasapi.Subnet = (*Subnet)(nil)
+
+// ActiveDiscovery calls ActiveDiscoveryFunc.
+func (m *Subnet) ActiveDiscovery() bool {
+	m.record("ActiveDiscovery")
+	if m.ActiveDiscoveryFunc == nil {
+		panic("mocks.Subnet.ActiveDiscovery called without ActiveDiscoveryFunc")
+	}
+	return m.ActiveDiscoveryFunc()
+}
+
+// AllowDNS calls AllowDNSFunc.
+func (m *Subnet) AllowDNS() bool {
+	m.record("AllowDNS")
+	if m.AllowDNSFunc == nil {
+		panic("mocks.Subnet.AllowDNS called without AllowDNSFunc")
+	}
+	return m.AllowDNSFunc()
+}
+
+// AllowProxy calls AllowProxyFunc.
+func (m *Subnet) AllowProxy() bool {
+	m.record("AllowProxy")
+	if m.AllowProxyFunc == nil {
+		panic("mocks.Subnet.AllowProxy called without AllowProxyFunc")
+	}
+	return m.AllowProxyFunc()
+}
+
+// CIDR calls CIDRFunc.
+func (m *Subnet) CIDR() string {
+	m.record("CIDR")
+	if m.CIDRFunc == nil {
+		panic("mocks.Subnet.CIDR called without CIDRFunc")
+	}
+	return m.CIDRFunc()
+}
+
+// DNSServers calls DNSServersFunc.
+func (m *Subnet) DNSServers() []string {
+	m.record("DNSServers")
+	if m.DNSServersFunc == nil {
+		panic("mocks.Subnet.DNSServers called without DNSServersFunc")
+	}
+	return m.DNSServersFunc()
+}
+
+// Delete calls DeleteFunc.
+func (m *Subnet) Delete() error {
+	m.record("Delete")
+	if m.DeleteFunc == nil {
+		panic("mocks.Subnet.Delete called without DeleteFunc")
+	}
+	return m.DeleteFunc()
+}
+
+// DisabledBootArchitectures calls DisabledBootArchitecturesFunc.
+func (m *Subnet) DisabledBootArchitectures() []string {
+	m.record("DisabledBootArchitectures")
+	if m.DisabledBootArchitecturesFunc == nil {
+		panic("mocks.Subnet.DisabledBootArchitectures called without DisabledBootArchitecturesFunc")
+	}
+	return m.DisabledBootArchitecturesFunc()
+}
+
+// Gateway calls GatewayFunc.
+func (m *Subnet) Gateway() string {
+	m.record("Gateway")
+	if m.GatewayFunc == nil {
+		panic("mocks.Subnet.Gateway called without GatewayFunc")
+	}
+	return m.GatewayFunc()
+}
+
+// ID calls IDFunc.
+func (m *Subnet) ID() int {
+	m.record("ID")
+	if m.IDFunc == nil {
+		panic("mocks.Subnet.ID called without IDFunc")
+	}
+	return m.IDFunc()
+}
+
+// IPAddresses calls IPAddressesFunc.
+func (m *Subnet) IPAddresses() ([]gomaasapi.SubnetIPAddress, error) {
+	m.record("IPAddresses")
+	if m.IPAddressesFunc == nil {
+		panic("mocks.Subnet.IPAddresses called without IPAddressesFunc")
+	}
+	return m.IPAddressesFunc()
+}
+
+// LastScan calls LastScanFunc.
+func (m *Subnet) LastScan() gomaasapi.ScanResult {
+	m.record("LastScan")
+	if m.LastScanFunc == nil {
+		panic("mocks.Subnet.LastScan called without LastScanFunc")
+	}
+	return m.LastScanFunc()
+}
+
+// Managed calls ManagedFunc.
+func (m *Subnet) Managed() bool {
+	m.record("Managed")
+	if m.ManagedFunc == nil {
+		panic("mocks.Subnet.Managed called without ManagedFunc")
+	}
+	return m.ManagedFunc()
+}
+
+// Name calls NameFunc.
+func (m *Subnet) Name() string {
+	m.record("Name")
+	if m.NameFunc == nil {
+		panic("mocks.Subnet.Name called without NameFunc")
+	}
+	return m.NameFunc()
+}
+
+// ReservedIPRanges calls ReservedIPRangesFunc.
+func (m *Subnet) ReservedIPRanges() ([]gomaasapi.SubnetAddressRange, error) {
+	m.record("ReservedIPRanges")
+	if m.ReservedIPRangesFunc == nil {
+		panic("mocks.Subnet.ReservedIPRanges called without ReservedIPRangesFunc")
+	}
+	return m.ReservedIPRangesFunc()
+}
+
+// Scan calls ScanFunc.
+func (m *Subnet) Scan(arg0 gomaasapi.SubnetScanArgs) (gomaasapi.ScanResult, error) {
+	m.record("Scan", arg0)
+	if m.ScanFunc == nil {
+		panic("mocks.Subnet.Scan called without ScanFunc")
+	}
+	return m.ScanFunc(arg0)
+}
+
+// Space calls SpaceFunc.
+func (m *Subnet) Space() string {
+	m.record("Space")
+	if m.SpaceFunc == nil {
+		panic("mocks.Subnet.Space called without SpaceFunc")
+	}
+	return m.SpaceFunc()
+}
+
+// Statistics calls StatisticsFunc.
+func (m *Subnet) Statistics() (gomaasapi.SubnetStatistics, error) {
+	m.record("Statistics")
+	if m.StatisticsFunc == nil {
+		panic("mocks.Subnet.Statistics called without StatisticsFunc")
+	}
+	return m.StatisticsFunc()
+}
+
+// UnreservedIPRanges calls UnreservedIPRangesFunc.
+func (m *Subnet) UnreservedIPRanges() ([]gomaasapi.SubnetAddressRange, error) {
+	m.record("UnreservedIPRanges")
+	if m.UnreservedIPRangesFunc == nil {
+		panic("mocks.Subnet.UnreservedIPRanges called without UnreservedIPRangesFunc")
+	}
+	return m.UnreservedIPRangesFunc()
+}
+
+// Update calls UpdateFunc.
+func (m *Subnet) Update(arg0 gomaasapi.UpdateSubnetArgs) error {
+	m.record("Update", arg0)
+	if m.UpdateFunc == nil {
+		panic("mocks.Subnet.Update called without UpdateFunc")
+	}
+	return m.UpdateFunc(arg0)
+}
+
+// VLAN calls VLANFunc.
+func (m *Subnet) VLAN() gomaasapi.VLAN {
+	m.record("VLAN")
+	if m.VLANFunc == nil {
+		panic("mocks.Subnet.VLAN called without VLANFunc")
+	}
+	return m.VLANFunc()
+}
+
+// IPAddress is a mock of gomaasapi.IPAddress.
+type IPAddress struct {
+	AllocTypeFunc     func() int
+	AllocTypeNameFunc func() string
+	CreatedFunc       func() time.Time
+	IPFunc            func() string
+	OwnerFunc         func() string
+	SubnetFunc        func() gomaasapi.Subnet
+
+	recorder
+}
+
+var _ gomaasapi.IPAddress = (*IPAddress)(nil)
+
+// AllocType calls AllocTypeFunc.
+func (m *IPAddress) AllocType() int {
+	m.record("AllocType")
+	if m.AllocTypeFunc == nil {
+		panic("mocks.IPAddress.AllocType called without AllocTypeFunc")
+	}
+	return m.AllocTypeFunc()
+}
+
+// AllocTypeName calls AllocTypeNameFunc.
+func (m *IPAddress) AllocTypeName() string {
+	m.record("AllocTypeName")
+	if m.AllocTypeNameFunc == nil {
+		panic("mocks.IPAddress.AllocTypeName called without AllocTypeNameFunc")
+	}
+	return m.AllocTypeNameFunc()
+}
+
+// Created calls CreatedFunc.
+func (m *IPAddress) Created() time.Time {
+	m.record("Created")
+	if m.CreatedFunc == nil {
+		panic("mocks.IPAddress.Created called without CreatedFunc")
+	}
+	return m.CreatedFunc()
+}
+
+// IP calls IPFunc.
+func (m *IPAddress) IP() string {
+	m.record("IP")
+	if m.IPFunc == nil {
+		panic("mocks.IPAddress.IP called without IPFunc")
+	}
+	return m.IPFunc()
+}
+
+// Owner calls OwnerFunc.
+func (m *IPAddress) Owner() string {
+	m.record("Owner")
+	if m.OwnerFunc == nil {
+		panic("mocks.IPAddress.Owner called without OwnerFunc")
+	}
+	return m.OwnerFunc()
+}
+
+// Subnet calls SubnetFunc.
+func (m *IPAddress) Subnet() gomaasapi.Subnet {
+	m.record("Subnet")
+	if m.SubnetFunc == nil {
+		panic("mocks.IPAddress.Subnet called without SubnetFunc")
+	}
+	return m.SubnetFunc()
+}
+
+// IPRange is a mock of gomaasapi.IPRange.
+type IPRange struct {
+	CommentFunc func() string
+	DeleteFunc  func() error
+	EndIPFunc   func() string
+	IDFunc      func() int
+	OwnerFunc   func() string
+	StartIPFunc func() string
+	SubnetFunc  func() gomaasapi.Subnet
+	TypeFunc    func() string
+	UpdateFunc  func(gomaasapi.UpdateIPRangeArgs) error
+
+	recorder
+}
+
+var _ gomaasapi.IPRange = (*IPRange)(nil)
+
+// Comment calls CommentFunc.
+func (m *IPRange) Comment() string {
+	m.record("Comment")
+	if m.CommentFunc == nil {
+		panic("mocks.IPRange.Comment called without CommentFunc")
+	}
+	return m.CommentFunc()
+}
+
+// Delete calls DeleteFunc.
+func (m *IPRange) Delete() error {
+	m.record("Delete")
+	if m.DeleteFunc == nil {
+		panic("mocks.IPRange.Delete called without DeleteFunc")
+	}
+	return m.DeleteFunc()
+}
+
+// EndIP calls EndIPFunc.
+func (m *IPRange) EndIP() string {
+	m.record("EndIP")
+	if m.EndIPFunc == nil {
+		panic("mocks.IPRange.EndIP called without EndIPFunc")
+	}
+	return m.EndIPFunc()
+}
+
+// ID calls IDFunc.
+func (m *IPRange) ID() int {
+	m.record("ID")
+	if m.IDFunc == nil {
+		panic("mocks.IPRange.ID called without IDFunc")
+	}
+	return m.IDFunc()
+}
+
+// Owner calls OwnerFunc.
+func (m *IPRange) Owner() string {
+	m.record("Owner")
+	if m.OwnerFunc == nil {
+		panic("mocks.IPRange.Owner called without OwnerFunc")
+	}
+	return m.OwnerFunc()
+}
+
+// StartIP calls StartIPFunc.
+func (m *IPRange) StartIP() string {
+	m.record("StartIP")
+	if m.StartIPFunc == nil {
+		panic("mocks.IPRange.StartIP called without StartIPFunc")
+	}
+	return m.StartIPFunc()
+}
+
+// Subnet calls SubnetFunc.
+func (m *IPRange) Subnet() gomaasapi.Subnet {
+	m.record("Subnet")
+	if m.SubnetFunc == nil {
+		panic("mocks.IPRange.Subnet called without SubnetFunc")
+	}
+	return m.SubnetFunc()
+}
+
+// Type calls TypeFunc.
+func (m *IPRange) Type() string {
+	m.record("Type")
+	if m.TypeFunc == nil {
+		panic("mocks.IPRange.Type called without TypeFunc")
+	}
+	return m.TypeFunc()
+}
+
+// Update calls UpdateFunc.
+func (m *IPRange) Update(arg0 gomaasapi.UpdateIPRangeArgs) error {
+	m.record("Update", arg0)
+	if m.UpdateFunc == nil {
+		panic("mocks.IPRange.Update called without UpdateFunc")
+	}
+	return m.UpdateFunc(arg0)
+}
+
+// RackController is a mock of gomaasapi.RackController.
+type RackController struct {
+	ArchitectureFunc     func() string
+	BootImagesStatusFunc func() (string, error)
+	FQDNFunc             func() string
+	HostnameFunc         func() string
+	IPAddressesFunc      func() []string
+	ImportBootImagesFunc func() error
+	InterfaceSetFunc     func() []gomaasapi.Interface
+	ServiceFunc          func(string) (gomaasapi.ServiceStatus, bool)
+	ServicesFunc         func() []gomaasapi.ServiceStatus
+	SystemIDFunc         func() string
+	VersionFunc          func() string
+
+	recorder
+}
+
+var _ gomaasapi.RackController = (*RackController)(nil)
+
+// Architecture calls ArchitectureFunc.
+func (m *RackController) Architecture() string {
+	m.record("Architecture")
+	if m.ArchitectureFunc == nil {
+		panic("mocks.RackController.Architecture called without ArchitectureFunc")
+	}
+	return m.ArchitectureFunc()
+}
+
+// BootImagesStatus calls BootImagesStatusFunc.
+func (m *RackController) BootImagesStatus() (string, error) {
+	m.record("BootImagesStatus")
+	if m.BootImagesStatusFunc == nil {
+		panic("mocks.RackController.BootImagesStatus called without BootImagesStatusFunc")
+	}
+	return m.BootImagesStatusFunc()
+}
+
+// FQDN calls FQDNFunc.
+func (m *RackController) FQDN() string {
+	m.record("FQDN")
+	if m.FQDNFunc == nil {
+		panic("mocks.RackController.FQDN called without FQDNFunc")
+	}
+	return m.FQDNFunc()
+}
+
+// Hostname calls HostnameFunc.
+func (m *RackController) Hostname() string {
+	m.record("Hostname")
+	if m.HostnameFunc == nil {
+		panic("mocks.RackController.Hostname called without HostnameFunc")
+	}
+	return m.HostnameFunc()
+}
+
+// IPAddresses calls IPAddressesFunc.
+func (m *RackController) IPAddresses() []string {
+	m.record("IPAddresses")
+	if m.IPAddressesFunc == nil {
+		panic("mocks.RackController.IPAddresses called without IPAddressesFunc")
+	}
+	return m.IPAddressesFunc()
+}
+
+// ImportBootImages calls ImportBootImagesFunc.
+func (m *RackController) ImportBootImages() error {
+	m.record("ImportBootImages")
+	if m.ImportBootImagesFunc == nil {
+		panic("mocks.RackController.ImportBootImages called without ImportBootImagesFunc")
+	}
+	return m.ImportBootImagesFunc()
+}
+
+// InterfaceSet calls InterfaceSetFunc.
+func (m *RackController) InterfaceSet() []gomaasapi.Interface {
+	m.record("InterfaceSet")
+	if m.InterfaceSetFunc == nil {
+		panic("mocks.RackController.InterfaceSet called without InterfaceSetFunc")
+	}
+	return m.InterfaceSetFunc()
+}
+
+// Service calls ServiceFunc.
+func (m *RackController) Service(name string) (gomaasapi.ServiceStatus, bool) {
+	m.record("Service", name)
+	if m.ServiceFunc == nil {
+		panic("mocks.RackController.Service called without ServiceFunc")
+	}
+	return m.ServiceFunc(name)
+}
+
+// Services calls ServicesFunc.
+func (m *RackController) Services() []gomaasapi.ServiceStatus {
+	m.record("Services")
+	if m.ServicesFunc == nil {
+		panic("mocks.RackController.Services called without ServicesFunc")
+	}
+	return m.ServicesFunc()
+}
+
+// SystemID calls SystemIDFunc.
+func (m *RackController) SystemID() string {
+	m.record("SystemID")
+	if m.SystemIDFunc == nil {
+		panic("mocks.RackController.SystemID called without SystemIDFunc")
+	}
+	return m.SystemIDFunc()
+}
+
+// Version calls VersionFunc.
+func (m *RackController) Version() string {
+	m.record("Version")
+	if m.VersionFunc == nil {
+		panic("mocks.RackController.Version called without VersionFunc")
+	}
+	return m.VersionFunc()
+}
+
+// RegionController is a mock of gomaasapi.RegionController.
+type RegionController struct {
+	ArchitectureFunc func() string
+	FQDNFunc         func() string
+	HostnameFunc     func() string
+	IPAddressesFunc  func() []string
+	InterfaceSetFunc func() []gomaasapi.Interface
+	ProcessesFunc    func() gomaasapi.ServiceStatus
+	ServiceFunc      func(string) (gomaasapi.ServiceStatus, bool)
+	ServicesFunc     func() []gomaasapi.ServiceStatus
+	SystemIDFunc     func() string
+	VersionFunc      func() string
+
+	recorder
+}
+
+var _ gomaasapi.RegionController = (*RegionController)(nil)
+
+// Architecture calls ArchitectureFunc.
+func (m *RegionController) Architecture() string {
+	m.record("Architecture")
+	if m.ArchitectureFunc == nil {
+		panic("mocks.RegionController.Architecture called without ArchitectureFunc")
+	}
+	return m.ArchitectureFunc()
+}
+
+// FQDN calls FQDNFunc.
+func (m *RegionController) FQDN() string {
+	m.record("FQDN")
+	if m.FQDNFunc == nil {
+		panic("mocks.RegionController.FQDN called without FQDNFunc")
+	}
+	return m.FQDNFunc()
+}
+
+// Hostname calls HostnameFunc.
+func (m *RegionController) Hostname() string {
+	m.record("Hostname")
+	if m.HostnameFunc == nil {
+		panic("mocks.RegionController.Hostname called without HostnameFunc")
+	}
+	return m.HostnameFunc()
+}
+
+// IPAddresses calls IPAddressesFunc.
+func (m *RegionController) IPAddresses() []string {
+	m.record("IPAddresses")
+	if m.IPAddressesFunc == nil {
+		panic("mocks.RegionController.IPAddresses called without IPAddressesFunc")
+	}
+	return m.IPAddressesFunc()
+}
+
+// InterfaceSet calls InterfaceSetFunc.
+func (m *RegionController) InterfaceSet() []gomaasapi.Interface {
+	m.record("InterfaceSet")
+	if m.InterfaceSetFunc == nil {
+		panic("mocks.RegionController.InterfaceSet called without InterfaceSetFunc")
+	}
+	return m.InterfaceSetFunc()
+}
+
+// Processes calls ProcessesFunc.
+func (m *RegionController) Processes() gomaasapi.ServiceStatus {
+	m.record("Processes")
+	if m.ProcessesFunc == nil {
+		panic("mocks.RegionController.Processes called without ProcessesFunc")
+	}
+	return m.ProcessesFunc()
+}
+
+// Service calls ServiceFunc.
+func (m *RegionController) Service(name string) (gomaasapi.ServiceStatus, bool) {
+	m.record("Service", name)
+	if m.ServiceFunc == nil {
+		panic("mocks.RegionController.Service called without ServiceFunc")
+	}
+	return m.ServiceFunc(name)
+}
+
+// Services calls ServicesFunc.
+func (m *RegionController) Services() []gomaasapi.ServiceStatus {
+	m.record("Services")
+	if m.ServicesFunc == nil {
+		panic("mocks.RegionController.Services called without ServicesFunc")
+	}
+	return m.ServicesFunc()
+}
+
+// SystemID calls SystemIDFunc.
+func (m *RegionController) SystemID() string {
+	m.record("SystemID")
+	if m.SystemIDFunc == nil {
+		panic("mocks.RegionController.SystemID called without SystemIDFunc")
+	}
+	return m.SystemIDFunc()
+}
+
+// Version calls VersionFunc.
+func (m *RegionController) Version() string {
+	m.record("Version")
+	if m.VersionFunc == nil {
+		panic("mocks.RegionController.Version called without VersionFunc")
+	}
+	return m.VersionFunc()
+}
+
+// DHCPSnippet is a mock of gomaasapi.DHCPSnippet.
+type DHCPSnippet struct {
+	DeleteFunc      func() error
+	DescriptionFunc func() string
+	DisableFunc     func() error
+	EnableFunc      func() error
+	EnabledFunc     func() bool
+	GlobalFunc      func() bool
+	IDFunc          func() int
+	NameFunc        func() string
+	NodeFunc        func() string
+	SubnetFunc      func() gomaasapi.Subnet
+	UpdateFunc      func(gomaasapi.UpdateDHCPSnippetArgs) error
+	ValueFunc       func() string
+
+	recorder
+}
+
+var _ gomaasapi.DHCPSnippet = (*DHCPSnippet)(nil)
+
+// Delete calls DeleteFunc.
+func (m *DHCPSnippet) Delete() error {
+	m.record("Delete")
+	if m.DeleteFunc == nil {
+		panic("mocks.DHCPSnippet.Delete called without DeleteFunc")
+	}
+	return m.DeleteFunc()
+}
+
+// Description calls DescriptionFunc.
+func (m *DHCPSnippet) Description() string {
+	m.record("Description")
+	if m.DescriptionFunc == nil {
+		panic("mocks.DHCPSnippet.Description called without DescriptionFunc")
+	}
+	return m.DescriptionFunc()
+}
+
+// Disable calls DisableFunc.
+func (m *DHCPSnippet) Disable() error {
+	m.record("Disable")
+	if m.DisableFunc == nil {
+		panic("mocks.DHCPSnippet.Disable called without DisableFunc")
+	}
+	return m.DisableFunc()
+}
+
+// Enable calls EnableFunc.
+func (m *DHCPSnippet) Enable() error {
+	m.record("Enable")
+	if m.EnableFunc == nil {
+		panic("mocks.DHCPSnippet.Enable called without EnableFunc")
+	}
+	return m.EnableFunc()
+}
+
+// Enabled calls EnabledFunc.
+func (m *DHCPSnippet) Enabled() bool {
+	m.record("Enabled")
+	if m.EnabledFunc == nil {
+		panic("mocks.DHCPSnippet.Enabled called without EnabledFunc")
+	}
+	return m.EnabledFunc()
+}
+
+// Global calls GlobalFunc.
+func (m *DHCPSnippet) Global() bool {
+	m.record("Global")
+	if m.GlobalFunc == nil {
+		panic("mocks.DHCPSnippet.Global called without GlobalFunc")
+	}
+	return m.GlobalFunc()
+}
+
+// ID calls IDFunc.
+func (m *DHCPSnippet) ID() int {
+	m.record("ID")
+	if m.IDFunc == nil {
+		panic("mocks.DHCPSnippet.ID called without IDFunc")
+	}
+	return m.IDFunc()
+}
+
+// Name calls NameFunc.
+func (m *DHCPSnippet) Name() string {
+	m.record("Name")
+	if m.NameFunc == nil {
+		panic("mocks.DHCPSnippet.Name called without NameFunc")
+	}
+	return m.NameFunc()
+}
+
+// Node calls NodeFunc.
+func (m *DHCPSnippet) Node() string {
+	m.record("Node")
+	if m.NodeFunc == nil {
+		panic("mocks.DHCPSnippet.Node called without NodeFunc")
+	}
+	return m.NodeFunc()
+}
+
+// Subnet calls SubnetFunc.
+func (m *DHCPSnippet) Subnet() gomaasapi.Subnet {
+	m.record("Subnet")
+	if m.SubnetFunc == nil {
+		panic("mocks.DHCPSnippet.Subnet called without SubnetFunc")
+	}
+	return m.SubnetFunc()
+}
+
+// Update calls UpdateFunc.
+func (m *DHCPSnippet) Update(arg0 gomaasapi.UpdateDHCPSnippetArgs) error {
+	m.record("Update", arg0)
+	if m.UpdateFunc == nil {
+		panic("mocks.DHCPSnippet.Update called without UpdateFunc")
+	}
+	return m.UpdateFunc(arg0)
+}
+
+// Value calls ValueFunc.
+func (m *DHCPSnippet) Value() string {
+	m.record("Value")
+	if m.ValueFunc == nil {
+		panic("mocks.DHCPSnippet.Value called without ValueFunc")
+	}
+	return m.ValueFunc()
+}
+
+// Discovery is a mock of gomaasapi.Discovery.
+type Discovery struct {
+	FabricNameFunc       func() string
+	HostnameFunc         func() string
+	IDFunc               func() string
+	IPFunc               func() string
+	LastSeenFunc         func() time.Time
+	MACAddressFunc       func() string
+	MACOrganizationFunc  func() string
+	ObserverFunc         func() string
+	ObserverHostnameFunc func() string
+	VIDFunc              func() int
+
+	recorder
+}
+
+var _ gomaasapi.Discovery = (*Discovery)(nil)
+
+// FabricName calls FabricNameFunc.
+func (m *Discovery) FabricName() string {
+	m.record("FabricName")
+	if m.FabricNameFunc == nil {
+		panic("mocks.Discovery.FabricName called without FabricNameFunc")
+	}
+	return m.FabricNameFunc()
+}
+
+// Hostname calls HostnameFunc.
+func (m *Discovery) Hostname() string {
+	m.record("Hostname")
+	if m.HostnameFunc == nil {
+		panic("mocks.Discovery.Hostname called without HostnameFunc")
+	}
+	return m.HostnameFunc()
+}
+
+// ID calls IDFunc.
+func (m *Discovery) ID() string {
+	m.record("ID")
+	if m.IDFunc == nil {
+		panic("mocks.Discovery.ID called without IDFunc")
+	}
+	return m.IDFunc()
+}
+
+// IP calls IPFunc.
+func (m *Discovery) IP() string {
+	m.record("IP")
+	if m.IPFunc == nil {
+		panic("mocks.Discovery.IP called without IPFunc")
+	}
+	return m.IPFunc()
+}
+
+// LastSeen calls LastSeenFunc.
+func (m *Discovery) LastSeen() time.Time {
+	m.record("LastSeen")
+	if m.LastSeenFunc == nil {
+		panic("mocks.Discovery.LastSeen called without LastSeenFunc")
+	}
+	return m.LastSeenFunc()
+}
+
+// MACAddress calls MACAddressFunc.
+func (m *Discovery) MACAddress() string {
+	m.record("MACAddress")
+	if m.MACAddressFunc == nil {
+		panic("mocks.Discovery.MACAddress called without MACAddressFunc")
+	}
+	return m.MACAddressFunc()
+}
+
+// MACOrganization calls MACOrganizationFunc.
+func (m *Discovery) MACOrganization() string {
+	m.record("MACOrganization")
+	if m.MACOrganizationFunc == nil {
+		panic("mocks.Discovery.MACOrganization called without MACOrganizationFunc")
+	}
+	return m.MACOrganizationFunc()
+}
+
+// Observer calls ObserverFunc.
+func (m *Discovery) Observer() string {
+	m.record("Observer")
+	if m.ObserverFunc == nil {
+		panic("mocks.Discovery.Observer called without ObserverFunc")
+	}
+	return m.ObserverFunc()
+}
+
+// ObserverHostname calls ObserverHostnameFunc.
+func (m *Discovery) ObserverHostname() string {
+	m.record("ObserverHostname")
+	if m.ObserverHostnameFunc == nil {
+		panic("mocks.Discovery.ObserverHostname called without ObserverHostnameFunc")
+	}
+	return m.ObserverHostnameFunc()
+}
+
+// VID calls VIDFunc.
+func (m *Discovery) VID() int {
+	m.record("VID")
+	if m.VIDFunc == nil {
+		panic("mocks.Discovery.VID called without VIDFunc")
+	}
+	return m.VIDFunc()
+}
+
+// ScanResult is a mock of gomaasapi.ScanResult.
+type ScanResult struct {
+	AttemptedOnFunc       func() []string
+	FailedOnFunc          func() []string
+	FailedToConnectToFunc func() []string
+	RPCErrorsFunc         func() map[string]string
+	RequestedFunc         func() time.Time
+	ResultFunc            func() string
+	StartedOnFunc         func() []string
+
+	recorder
+}
+
+var _ gomaasapi.ScanResult = (*ScanResult)(nil)
+
+// AttemptedOn calls AttemptedOnFunc.
+func (m *ScanResult) AttemptedOn() []string {
+	m.record("AttemptedOn")
+	if m.AttemptedOnFunc == nil {
+		panic("mocks.ScanResult.AttemptedOn called without AttemptedOnFunc")
+	}
+	return m.AttemptedOnFunc()
+}
+
+// FailedOn calls FailedOnFunc.
+func (m *ScanResult) FailedOn() []string {
+	m.record("FailedOn")
+	if m.FailedOnFunc == nil {
+		panic("mocks.ScanResult.FailedOn called without FailedOnFunc")
+	}
+	return m.FailedOnFunc()
+}
+
+// FailedToConnectTo calls FailedToConnectToFunc.
+func (m *ScanResult) FailedToConnectTo() []string {
+	m.record("FailedToConnectTo")
+	if m.FailedToConnectToFunc == nil {
+		panic("mocks.ScanResult.FailedToConnectTo called without FailedToConnectToFunc")
+	}
+	return m.FailedToConnectToFunc()
+}
+
+// RPCErrors calls RPCErrorsFunc.
+func (m *ScanResult) RPCErrors() map[string]string {
+	m.record("RPCErrors")
+	if m.RPCErrorsFunc == nil {
+		panic("mocks.ScanResult.RPCErrors called without RPCErrorsFunc")
+	}
+	return m.RPCErrorsFunc()
+}
+
+// Requested calls RequestedFunc.
+func (m *ScanResult) Requested() time.Time {
+	m.record("Requested")
+	if m.RequestedFunc == nil {
+		panic("mocks.ScanResult.Requested called without RequestedFunc")
+	}
+	return m.RequestedFunc()
+}
+
+// Result calls ResultFunc.
+func (m *ScanResult) Result() string {
+	m.record("Result")
+	if m.ResultFunc == nil {
+		panic("mocks.ScanResult.Result called without ResultFunc")
+	}
+	return m.ResultFunc()
+}
+
+// StartedOn calls StartedOnFunc.
+func (m *ScanResult) StartedOn() []string {
+	m.record("StartedOn")
+	if m.StartedOnFunc == nil {
+		panic("mocks.ScanResult.StartedOn called without StartedOnFunc")
+	}
+	return m.StartedOnFunc()
+}
+
+// StaticRoute is a mock of gomaasapi.StaticRoute.
+type StaticRoute struct {
+	DestinationFunc func() gomaasapi.Subnet
+	GatewayIPFunc   func() string
+	MetricFunc      func() int
+	SourceFunc      func() gomaasapi.Subnet
+
+	recorder
+}
+
+var _ gomaasapi.StaticRoute = (*StaticRoute)(nil)
+
+// Destination calls DestinationFunc.
+func (m *StaticRoute) Destination() gomaasapi.Subnet {
+	m.record("Destination")
+	if m.DestinationFunc == nil {
+		panic("mocks.StaticRoute.Destination called without DestinationFunc")
+	}
+	return m.DestinationFunc()
+}
+
+// GatewayIP calls GatewayIPFunc.
+func (m *StaticRoute) GatewayIP() string {
+	m.record("GatewayIP")
+	if m.GatewayIPFunc == nil {
+		panic("mocks.StaticRoute.GatewayIP called without GatewayIPFunc")
+	}
+	return m.GatewayIPFunc()
+}
+
+// Metric calls MetricFunc.
+func (m *StaticRoute) Metric() int {
+	m.record("Metric")
+	if m.MetricFunc == nil {
+		panic("mocks.StaticRoute.Metric called without MetricFunc")
+	}
+	return m.MetricFunc()
+}
+
+// Source calls SourceFunc.
+func (m *StaticRoute) Source() gomaasapi.Subnet {
+	m.record("Source")
+	if m.SourceFunc == nil {
+		panic("mocks.StaticRoute.Source called without SourceFunc")
+	}
+	return m.SourceFunc()
+}
+
+// Interface is a mock of gomaasapi.Interface.
+type Interface struct {
+	ChildrenFunc       func() []string
+	DeleteFunc         func() error
+	DiscoveredFunc     func() []gomaasapi.DiscoveredLink
+	EffectiveMTUFunc   func() int
+	EnabledFunc        func() bool
+	IDFunc             func() int
+	InterfaceSpeedFunc func() int
+	LinkConnectedFunc  func() bool
+	LinkSpeedFunc      func() int
+	LinkSubnetFunc     func(gomaasapi.LinkSubnetArgs) error
+	LinksFunc          func() []gomaasapi.Link
+	MACAddressFunc     func() string
+	NameFunc           func() string
+	ParamsFunc         func() gomaasapi.InterfaceParams
+	ParentsFunc        func() []string
+	TagsFunc           func() []string
+	TypeFunc           func() string
+	UnlinkSubnetFunc   func(gomaasapi.Subnet) error
+	UpdateFunc         func(gomaasapi.UpdateInterfaceArgs) error
+	VLANFunc           func() gomaasapi.VLAN
+
+	recorder
+}
+
+var _ gomaasapi.Interface = (*Interface)(nil)
+
+// Children calls ChildrenFunc.
+func (m *Interface) Children() []string {
+	m.record("Children")
+	if m.ChildrenFunc == nil {
+		panic("mocks.Interface.Children called without ChildrenFunc")
+	}
+	return m.ChildrenFunc()
+}
+
+// Delete calls DeleteFunc.
+func (m *Interface) Delete() error {
+	m.record("Delete")
+	if m.DeleteFunc == nil {
+		panic("mocks.Interface.Delete called without DeleteFunc")
+	}
+	return m.DeleteFunc()
+}
+
+// Discovered calls DiscoveredFunc.
+func (m *Interface) Discovered() []gomaasapi.DiscoveredLink {
+	m.record("Discovered")
+	if m.DiscoveredFunc == nil {
+		panic("mocks.Interface.Discovered called without DiscoveredFunc")
+	}
+	return m.DiscoveredFunc()
+}
+
+// EffectiveMTU calls EffectiveMTUFunc.
+func (m *Interface) EffectiveMTU() int {
+	m.record("EffectiveMTU")
+	if m.EffectiveMTUFunc == nil {
+		panic("mocks.Interface.EffectiveMTU called without EffectiveMTUFunc")
+	}
+	return m.EffectiveMTUFunc()
+}
+
+// Enabled calls EnabledFunc.
+func (m *Interface) Enabled() bool {
+	m.record("Enabled")
+	if m.EnabledFunc == nil {
+		panic("mocks.Interface.Enabled called without EnabledFunc")
+	}
+	return m.EnabledFunc()
+}
+
+// ID calls IDFunc.
+func (m *Interface) ID() int {
+	m.record("ID")
+	if m.IDFunc == nil {
+		panic("mocks.Interface.ID called without IDFunc")
+	}
+	return m.IDFunc()
+}
+
+// InterfaceSpeed calls InterfaceSpeedFunc.
+func (m *Interface) InterfaceSpeed() int {
+	m.record("InterfaceSpeed")
+	if m.InterfaceSpeedFunc == nil {
+		panic("mocks.Interface.InterfaceSpeed called without InterfaceSpeedFunc")
+	}
+	return m.InterfaceSpeedFunc()
+}
+
+// LinkConnected calls LinkConnectedFunc.
+func (m *Interface) LinkConnected() bool {
+	m.record("LinkConnected")
+	if m.LinkConnectedFunc == nil {
+		panic("mocks.Interface.LinkConnected called without LinkConnectedFunc")
+	}
+	return m.LinkConnectedFunc()
+}
+
+// LinkSpeed calls LinkSpeedFunc.
+func (m *Interface) LinkSpeed() int {
+	m.record("LinkSpeed")
+	if m.LinkSpeedFunc == nil {
+		panic("mocks.Interface.LinkSpeed called without LinkSpeedFunc")
+	}
+	return m.LinkSpeedFunc()
+}
+
+// LinkSubnet calls LinkSubnetFunc.
+func (m *Interface) LinkSubnet(arg0 gomaasapi.LinkSubnetArgs) error {
+	m.record("LinkSubnet", arg0)
+	if m.LinkSubnetFunc == nil {
+		panic("mocks.Interface.LinkSubnet called without LinkSubnetFunc")
+	}
+	return m.LinkSubnetFunc(arg0)
+}
+
+// Links calls LinksFunc.
+func (m *Interface) Links() []gomaasapi.Link {
+	m.record("Links")
+	if m.LinksFunc == nil {
+		panic("mocks.Interface.Links called without LinksFunc")
+	}
+	return m.LinksFunc()
+}
+
+// MACAddress calls MACAddressFunc.
+func (m *Interface) MACAddress() string {
+	m.record("MACAddress")
+	if m.MACAddressFunc == nil {
+		panic("mocks.Interface.MACAddress called without MACAddressFunc")
+	}
+	return m.MACAddressFunc()
+}
+
+// Name calls NameFunc.
+func (m *Interface) Name() string {
+	m.record("Name")
+	if m.NameFunc == nil {
+		panic("mocks.Interface.Name called without NameFunc")
+	}
+	return m.NameFunc()
+}
+
+// Params calls ParamsFunc.
+func (m *Interface) Params() gomaasapi.InterfaceParams {
+	m.record("Params")
+	if m.ParamsFunc == nil {
+		panic("mocks.Interface.Params called without ParamsFunc")
+	}
+	return m.ParamsFunc()
+}
+
+// Parents calls ParentsFunc.
+func (m *Interface) Parents() []string {
+	m.record("Parents")
+	if m.ParentsFunc == nil {
+		panic("mocks.Interface.Parents called without ParentsFunc")
+	}
+	return m.ParentsFunc()
+}
+
+// Tags calls TagsFunc.
+func (m *Interface) Tags() []string {
+	m.record("Tags")
+	if m.TagsFunc == nil {
+		panic("mocks.Interface.Tags called without TagsFunc")
+	}
+	return m.TagsFunc()
+}
+
+// Type calls TypeFunc.
+func (m *Interface) Type() string {
+	m.record("Type")
+	if m.TypeFunc == nil {
+		panic("mocks.Interface.Type called without TypeFunc")
+	}
+	return m.TypeFunc()
+}
+
+// UnlinkSubnet calls UnlinkSubnetFunc.
+func (m *Interface) UnlinkSubnet(arg0 gomaasapi.Subnet) error {
+	m.record("UnlinkSubnet", arg0)
+	if m.UnlinkSubnetFunc == nil {
+		panic("mocks.Interface.UnlinkSubnet called without UnlinkSubnetFunc")
+	}
+	return m.UnlinkSubnetFunc(arg0)
+}
+
+// Update calls UpdateFunc.
+func (m *Interface) Update(arg0 gomaasapi.UpdateInterfaceArgs) error {
+	m.record("Update", arg0)
+	if m.UpdateFunc == nil {
+		panic("mocks.Interface.Update called without UpdateFunc")
+	}
+	return m.UpdateFunc(arg0)
+}
+
+// VLAN calls VLANFunc.
+func (m *Interface) VLAN() gomaasapi.VLAN {
+	m.record("VLAN")
+	if m.VLANFunc == nil {
+		panic("mocks.Interface.VLAN called without VLANFunc")
+	}
+	return m.VLANFunc()
+}
+
+// InterfaceParams is a mock of gomaasapi.InterfaceParams.
+type InterfaceParams struct {
+	AcceptRAFunc func() bool
+	AutoconfFunc func() bool
+	BondFunc     func() gomaasapi.BondParams
+	BridgeFunc   func() gomaasapi.BridgeParams
+	MTUFunc      func() int
+	RawFunc      func() map[string]interface{}
+
+	recorder
+}
+
+var _ gomaasapi.InterfaceParams = (*InterfaceParams)(nil)
+
+// AcceptRA calls AcceptRAFunc.
+func (m *InterfaceParams) AcceptRA() bool {
+	m.record("AcceptRA")
+	if m.AcceptRAFunc == nil {
+		panic("mocks.InterfaceParams.AcceptRA called without AcceptRAFunc")
+	}
+	return m.AcceptRAFunc()
+}
+
+// Autoconf calls AutoconfFunc.
+func (m *InterfaceParams) Autoconf() bool {
+	m.record("Autoconf")
+	if m.AutoconfFunc == nil {
+		panic("mocks.InterfaceParams.Autoconf called without AutoconfFunc")
+	}
+	return m.AutoconfFunc()
+}
+
+// Bond calls BondFunc.
+func (m *InterfaceParams) Bond() gomaasapi.BondParams {
+	m.record("Bond")
+	if m.BondFunc == nil {
+		panic("mocks.InterfaceParams.Bond called without BondFunc")
+	}
+	return m.BondFunc()
+}
+
+// Bridge calls BridgeFunc.
+func (m *InterfaceParams) Bridge() gomaasapi.BridgeParams {
+	m.record("Bridge")
+	if m.BridgeFunc == nil {
+		panic("mocks.InterfaceParams.Bridge called without BridgeFunc")
+	}
+	return m.BridgeFunc()
+}
+
+// MTU calls MTUFunc.
+func (m *InterfaceParams) MTU() int {
+	m.record("MTU")
+	if m.MTUFunc == nil {
+		panic("mocks.InterfaceParams.MTU called without MTUFunc")
+	}
+	return m.MTUFunc()
+}
+
+// Raw calls RawFunc.
+func (m *InterfaceParams) Raw() map[string]interface{} {
+	m.record("Raw")
+	if m.RawFunc == nil {
+		panic("mocks.InterfaceParams.Raw called without RawFunc")
+	}
+	return m.RawFunc()
+}
+
+// BondParams is a mock of gomaasapi.BondParams.
+type BondParams struct {
+	DownDelayFunc      func() int
+	LACPRateFunc       func() string
+	MIIMonFunc         func() int
+	ModeFunc           func() string
+	NumGratARPFunc     func() int
+	UpDelayFunc        func() int
+	XmitHashPolicyFunc func() string
+
+	recorder
+}
+
+var _ gomaasapi.BondParams = (*BondParams)(nil)
+
+// DownDelay calls DownDelayFunc.
+func (m *BondParams) DownDelay() int {
+	m.record("DownDelay")
+	if m.DownDelayFunc == nil {
+		panic("mocks.BondParams.DownDelay called without DownDelayFunc")
+	}
+	return m.DownDelayFunc()
+}
+
+// LACPRate calls LACPRateFunc.
+func (m *BondParams) LACPRate() string {
+	m.record("LACPRate")
+	if m.LACPRateFunc == nil {
+		panic("mocks.BondParams.LACPRate called without LACPRateFunc")
+	}
+	return m.LACPRateFunc()
+}
+
+// MIIMon calls MIIMonFunc.
+func (m *BondParams) MIIMon() int {
+	m.record("MIIMon")
+	if m.MIIMonFunc == nil {
+		panic("mocks.BondParams.MIIMon called without MIIMonFunc")
+	}
+	return m.MIIMonFunc()
+}
+
+// Mode calls ModeFunc.
+func (m *BondParams) Mode() string {
+	m.record("Mode")
+	if m.ModeFunc == nil {
+		panic("mocks.BondParams.Mode called without ModeFunc")
+	}
+	return m.ModeFunc()
+}
+
+// NumGratARP calls NumGratARPFunc.
+func (m *BondParams) NumGratARP() int {
+	m.record("NumGratARP")
+	if m.NumGratARPFunc == nil {
+		panic("mocks.BondParams.NumGratARP called without NumGratARPFunc")
+	}
+	return m.NumGratARPFunc()
+}
+
+// UpDelay calls UpDelayFunc.
+func (m *BondParams) UpDelay() int {
+	m.record("UpDelay")
+	if m.UpDelayFunc == nil {
+		panic("mocks.BondParams.UpDelay called without UpDelayFunc")
+	}
+	return m.UpDelayFunc()
+}
+
+// XmitHashPolicy calls XmitHashPolicyFunc.
+func (m *BondParams) XmitHashPolicy() string {
+	m.record("XmitHashPolicy")
+	if m.XmitHashPolicyFunc == nil {
+		panic("mocks.BondParams.XmitHashPolicy called without XmitHashPolicyFunc")
+	}
+	return m.XmitHashPolicyFunc()
+}
+
+// BridgeParams is a mock of gomaasapi.BridgeParams.
+type BridgeParams struct {
+	FDFunc   func() int
+	STPFunc  func() bool
+	TypeFunc func() string
+
+	recorder
+}
+
+var _ gomaasapi.BridgeParams = (*BridgeParams)(nil)
+
+// FD calls FDFunc.
+func (m *BridgeParams) FD() int {
+	m.record("FD")
+	if m.FDFunc == nil {
+		panic("mocks.BridgeParams.FD called without FDFunc")
+	}
+	return m.FDFunc()
+}
+
+// STP calls STPFunc.
+func (m *BridgeParams) STP() bool {
+	m.record("STP")
+	if m.STPFunc == nil {
+		panic("mocks.BridgeParams.STP called without STPFunc")
+	}
+	return m.STPFunc()
+}
+
+// Type calls TypeFunc.
+func (m *BridgeParams) Type() string {
+	m.record("Type")
+	if m.TypeFunc == nil {
+		panic("mocks.BridgeParams.Type called without TypeFunc")
+	}
+	return m.TypeFunc()
+}
+
+// Link is a mock of gomaasapi.Link.
+type Link struct {
+	IDFunc        func() int
+	IPAddressFunc func() string
+	ModeFunc      func() string
+	SubnetFunc    func() gomaasapi.Subnet
+
+	recorder
+}
+
+var _ gomaasapi.Link = (*Link)(nil)
+
+// ID calls IDFunc.
+func (m *Link) ID() int {
+	m.record("ID")
+	if m.IDFunc == nil {
+		panic("mocks.Link.ID called without IDFunc")
+	}
+	return m.IDFunc()
+}
+
+// IPAddress calls IPAddressFunc.
+func (m *Link) IPAddress() string {
+	m.record("IPAddress")
+	if m.IPAddressFunc == nil {
+		panic("mocks.Link.IPAddress called without IPAddressFunc")
+	}
+	return m.IPAddressFunc()
+}
+
+// Mode calls ModeFunc.
+func (m *Link) Mode() string {
+	m.record("Mode")
+	if m.ModeFunc == nil {
+		panic("mocks.Link.Mode called without ModeFunc")
+	}
+	return m.ModeFunc()
+}
+
+// Subnet calls SubnetFunc.
+func (m *Link) Subnet() gomaasapi.Subnet {
+	m.record("Subnet")
+	if m.SubnetFunc == nil {
+		panic("mocks.Link.Subnet called without SubnetFunc")
+	}
+	return m.SubnetFunc()
+}
+
+// DiscoveredLink is a mock of gomaasapi.DiscoveredLink.
+type DiscoveredLink struct {
+	IPAddressFunc func() string
+	SubnetFunc    func() gomaasapi.Subnet
+
+	recorder
+}
+
+var _ gomaasapi.DiscoveredLink = (*DiscoveredLink)(nil)
+
+// IPAddress calls IPAddressFunc.
+func (m *DiscoveredLink) IPAddress() string {
+	m.record("IPAddress")
+	if m.IPAddressFunc == nil {
+		panic("mocks.DiscoveredLink.IPAddress called without IPAddressFunc")
+	}
+	return m.IPAddressFunc()
+}
+
+// Subnet calls SubnetFunc.
+func (m *DiscoveredLink) Subnet() gomaasapi.Subnet {
+	m.record("Subnet")
+	if m.SubnetFunc == nil {
+		panic("mocks.DiscoveredLink.Subnet called without SubnetFunc")
+	}
+	return m.SubnetFunc()
+}
+
+// FileSystem is a mock of gomaasapi.FileSystem.
+type FileSystem struct {
+	LabelFunc        func() string
+	MountOptionsFunc func() string
+	MountPointFunc   func() string
+	TypeFunc         func() string
+	UUIDFunc         func() string
+
+	recorder
+}
+
+var _ gomaasapi.FileSystem = (*FileSystem)(nil)
+
+// Label calls LabelFunc.
+func (m *FileSystem) Label() string {
+	m.record("Label")
+	if m.LabelFunc == nil {
+		panic("mocks.FileSystem.Label called without LabelFunc")
+	}
+	return m.LabelFunc()
+}
+
+// MountOptions calls MountOptionsFunc.
+func (m *FileSystem) MountOptions() string {
+	m.record("MountOptions")
+	if m.MountOptionsFunc == nil {
+		panic("mocks.FileSystem.MountOptions called without MountOptionsFunc")
+	}
+	return m.MountOptionsFunc()
+}
+
+// MountPoint calls MountPointFunc.
+func (m *FileSystem) MountPoint() string {
+	m.record("MountPoint")
+	if m.MountPointFunc == nil {
+		panic("mocks.FileSystem.MountPoint called without MountPointFunc")
+	}
+	return m.MountPointFunc()
+}
+
+// Type calls TypeFunc.
+func (m *FileSystem) Type() string {
+	m.record("Type")
+	if m.TypeFunc == nil {
+		panic("mocks.FileSystem.Type called without TypeFunc")
+	}
+	return m.TypeFunc()
+}
+
+// UUID calls UUIDFunc.
+func (m *FileSystem) UUID() string {
+	m.record("UUID")
+	if m.UUIDFunc == nil {
+		panic("mocks.FileSystem.UUID called without UUIDFunc")
+	}
+	return m.UUIDFunc()
+}
+
+// StorageDevice is a mock of gomaasapi.StorageDevice.
+type StorageDevice struct {
+	FileSystemFunc func() gomaasapi.FileSystem
+	FormatFunc     func(gomaasapi.FormatStorageDeviceArgs) error
+	IDFunc         func() int
+	MountFunc      func(gomaasapi.MountStorageDeviceArgs) error
+	PathFunc       func() string
+	SizeFunc       func() uint64
+	TagsFunc       func() []string
+	TypeFunc       func() string
+	UUIDFunc       func() string
+	UnformatFunc   func() error
+	UnmountFunc    func() error
+	UsedForFunc    func() string
+
+	recorder
+}
+
+var _ gomaasapi.StorageDevice = (*StorageDevice)(nil)
+
+// FileSystem calls FileSystemFunc.
+func (m *StorageDevice) FileSystem() gomaasapi.FileSystem {
+	m.record("FileSystem")
+	if m.FileSystemFunc == nil {
+		panic("mocks.StorageDevice.FileSystem called without FileSystemFunc")
+	}
+	return m.FileSystemFunc()
+}
+
+// Format calls FormatFunc.
+func (m *StorageDevice) Format(arg0 gomaasapi.FormatStorageDeviceArgs) error {
+	m.record("Format", arg0)
+	if m.FormatFunc == nil {
+		panic("mocks.StorageDevice.Format called without FormatFunc")
+	}
+	return m.FormatFunc(arg0)
+}
+
+// ID calls IDFunc.
+func (m *StorageDevice) ID() int {
+	m.record("ID")
+	if m.IDFunc == nil {
+		panic("mocks.StorageDevice.ID called without IDFunc")
+	}
+	return m.IDFunc()
+}
+
+// Mount calls MountFunc.
+func (m *StorageDevice) Mount(arg0 gomaasapi.MountStorageDeviceArgs) error {
+	m.record("Mount", arg0)
+	if m.MountFunc == nil {
+		panic("mocks.StorageDevice.Mount called without MountFunc")
+	}
+	return m.MountFunc(arg0)
+}
+
+// Path calls PathFunc.
+func (m *StorageDevice) Path() string {
+	m.record("Path")
+	if m.PathFunc == nil {
+		panic("mocks.StorageDevice.Path called without PathFunc")
+	}
+	return m.PathFunc()
+}
+
+// Size calls SizeFunc.
+func (m *StorageDevice) Size() uint64 {
+	m.record("Size")
+	if m.SizeFunc == nil {
+		panic("mocks.StorageDevice.Size called without SizeFunc")
+	}
+	return m.SizeFunc()
+}
+
+// Tags calls TagsFunc.
+func (m *StorageDevice) Tags() []string {
+	m.record("Tags")
+	if m.TagsFunc == nil {
+		panic("mocks.StorageDevice.Tags called without TagsFunc")
+	}
+	return m.TagsFunc()
+}
+
+// Type calls TypeFunc.
+func (m *StorageDevice) Type() string {
+	m.record("Type")
+	if m.TypeFunc == nil {
+		panic("mocks.StorageDevice.Type called without TypeFunc")
+	}
+	return m.TypeFunc()
+}
+
+// UUID calls UUIDFunc.
+func (m *StorageDevice) UUID() string {
+	m.record("UUID")
+	if m.UUIDFunc == nil {
+		panic("mocks.StorageDevice.UUID called without UUIDFunc")
+	}
+	return m.UUIDFunc()
+}
+
+// Unformat calls UnformatFunc.
+func (m *StorageDevice) Unformat() error {
+	m.record("Unformat")
+	if m.UnformatFunc == nil {
+		panic("mocks.StorageDevice.Unformat called without UnformatFunc")
+	}
+	return m.UnformatFunc()
+}
+
+// Unmount calls UnmountFunc.
+func (m *StorageDevice) Unmount() error {
+	m.record("Unmount")
+	if m.UnmountFunc == nil {
+		panic("mocks.StorageDevice.Unmount called without UnmountFunc")
+	}
+	return m.UnmountFunc()
+}
+
+// UsedFor calls UsedForFunc.
+func (m *StorageDevice) UsedFor() string {
+	m.record("UsedFor")
+	if m.UsedForFunc == nil {
+		panic("mocks.StorageDevice.UsedFor called without UsedForFunc")
+	}
+	return m.UsedForFunc()
+}
+
+// Partition is a mock of gomaasapi.Partition.
+type Partition struct {
+	DeleteFunc     func() error
+	FileSystemFunc func() gomaasapi.FileSystem
+	FormatFunc     func(gomaasapi.FormatStorageDeviceArgs) error
+	IDFunc         func() int
+	MountFunc      func(gomaasapi.MountStorageDeviceArgs) error
+	PathFunc       func() string
+	ResizeFunc     func(uint64) error
+	SizeFunc       func() uint64
+	TagsFunc       func() []string
+	TypeFunc       func() string
+	UUIDFunc       func() string
+	UnformatFunc   func() error
+	UnmountFunc    func() error
+	UsedForFunc    func() string
+
+	recorder
+}
+
+var _ gomaasapi.Partition = (*Partition)(nil)
+
+// Delete calls DeleteFunc.
+func (m *Partition) Delete() error {
+	m.record("Delete")
+	if m.DeleteFunc == nil {
+		panic("mocks.Partition.Delete called without DeleteFunc")
+	}
+	return m.DeleteFunc()
+}
+
+// FileSystem calls FileSystemFunc.
+func (m *Partition) FileSystem() gomaasapi.FileSystem {
+	m.record("FileSystem")
+	if m.FileSystemFunc == nil {
+		panic("mocks.Partition.FileSystem called without FileSystemFunc")
+	}
+	return m.FileSystemFunc()
+}
+
+// Format calls FormatFunc.
+func (m *Partition) Format(arg0 gomaasapi.FormatStorageDeviceArgs) error {
+	m.record("Format", arg0)
+	if m.FormatFunc == nil {
+		panic("mocks.Partition.Format called without FormatFunc")
+	}
+	return m.FormatFunc(arg0)
+}
+
+// ID calls IDFunc.
+func (m *Partition) ID() int {
+	m.record("ID")
+	if m.IDFunc == nil {
+		panic("mocks.Partition.ID called without IDFunc")
+	}
+	return m.IDFunc()
+}
+
+// Mount calls MountFunc.
+func (m *Partition) Mount(arg0 gomaasapi.MountStorageDeviceArgs) error {
+	m.record("Mount", arg0)
+	if m.MountFunc == nil {
+		panic("mocks.Partition.Mount called without MountFunc")
+	}
+	return m.MountFunc(arg0)
+}
+
+// Path calls PathFunc.
+func (m *Partition) Path() string {
+	m.record("Path")
+	if m.PathFunc == nil {
+		panic("mocks.Partition.Path called without PathFunc")
+	}
+	return m.PathFunc()
+}
+
+// Resize calls ResizeFunc.
+func (m *Partition) Resize(size uint64) error {
+	m.record("Resize", size)
+	if m.ResizeFunc == nil {
+		panic("mocks.Partition.Resize called without ResizeFunc")
+	}
+	return m.ResizeFunc(size)
+}
+
+// Size calls SizeFunc.
+func (m *Partition) Size() uint64 {
+	m.record("Size")
+	if m.SizeFunc == nil {
+		panic("mocks.Partition.Size called without SizeFunc")
+	}
+	return m.SizeFunc()
+}
+
+// Tags calls TagsFunc.
+func (m *Partition) Tags() []string {
+	m.record("Tags")
+	if m.TagsFunc == nil {
+		panic("mocks.Partition.Tags called without TagsFunc")
+	}
+	return m.TagsFunc()
+}
+
+// Type calls TypeFunc.
+func (m *Partition) Type() string {
+	m.record("Type")
+	if m.TypeFunc == nil {
+		panic("mocks.Partition.Type called without TypeFunc")
+	}
+	return m.TypeFunc()
+}
+
+// UUID calls UUIDFunc.
+func (m *Partition) UUID() string {
+	m.record("UUID")
+	if m.UUIDFunc == nil {
+		panic("mocks.Partition.UUID called without UUIDFunc")
+	}
+	return m.UUIDFunc()
+}
+
+// Unformat calls UnformatFunc.
+func (m *Partition) Unformat() error {
+	m.record("Unformat")
+	if m.UnformatFunc == nil {
+		panic("mocks.Partition.Unformat called without UnformatFunc")
+	}
+	return m.UnformatFunc()
+}
+
+// Unmount calls UnmountFunc.
+func (m *Partition) Unmount() error {
+	m.record("Unmount")
+	if m.UnmountFunc == nil {
+		panic("mocks.Partition.Unmount called without UnmountFunc")
+	}
+	return m.UnmountFunc()
+}
+
+// UsedFor calls UsedForFunc.
+func (m *Partition) UsedFor() string {
+	m.record("UsedFor")
+	if m.UsedForFunc == nil {
+		panic("mocks.Partition.UsedFor called without UsedForFunc")
+	}
+	return m.UsedForFunc()
+}
+
+// BlockDevice is a mock of gomaasapi.BlockDevice.
+type BlockDevice struct {
+	BlockSizeFunc       func() uint64
+	CreatePartitionFunc func(gomaasapi.CreatePartitionArgs) (gomaasapi.Partition, error)
+	DeleteFunc          func() error
+	FileSystemFunc      func() gomaasapi.FileSystem
+	FormatFunc          func(gomaasapi.FormatStorageDeviceArgs) error
+	IDFunc              func() int
+	IDPathFunc          func() string
+	ModelFunc           func() string
+	MountFunc           func(gomaasapi.MountStorageDeviceArgs) error
+	NameFunc            func() string
+	ParentFunc          func() *gomaasapi.BlockDeviceParent
+	PartitionsFunc      func() []gomaasapi.Partition
+	PathFunc            func() string
+	SerialFunc          func() string
+	SizeFunc            func() uint64
+	TagsFunc            func() []string
+	TypeFunc            func() string
+	UUIDFunc            func() string
+	UnformatFunc        func() error
+	UnmountFunc         func() error
+	UpdateFunc          func(gomaasapi.UpdateBlockDeviceArgs) error
+	UsedForFunc         func() string
+	UsedSizeFunc        func() uint64
+
+	recorder
+}
+
+var _ gomaasapi.BlockDevice = (*BlockDevice)(nil)
+
+// BlockSize calls BlockSizeFunc.
+func (m *BlockDevice) BlockSize() uint64 {
+	m.record("BlockSize")
+	if m.BlockSizeFunc == nil {
+		panic("mocks.BlockDevice.BlockSize called without BlockSizeFunc")
+	}
+	return m.BlockSizeFunc()
+}
+
+// CreatePartition calls CreatePartitionFunc.
+func (m *BlockDevice) CreatePartition(arg0 gomaasapi.CreatePartitionArgs) (gomaasapi.Partition, error) {
+	m.record("CreatePartition", arg0)
+	if m.CreatePartitionFunc == nil {
+		panic("mocks.BlockDevice.CreatePartition called without CreatePartitionFunc")
+	}
+	return m.CreatePartitionFunc(arg0)
+}
+
+// Delete calls DeleteFunc.
+func (m *BlockDevice) Delete() error {
+	m.record("Delete")
+	if m.DeleteFunc == nil {
+		panic("mocks.BlockDevice.Delete called without DeleteFunc")
+	}
+	return m.DeleteFunc()
+}
+
+// FileSystem calls FileSystemFunc.
+func (m *BlockDevice) FileSystem() gomaasapi.FileSystem {
+	m.record("FileSystem")
+	if m.FileSystemFunc == nil {
+		panic("mocks.BlockDevice.FileSystem called without FileSystemFunc")
+	}
+	return m.FileSystemFunc()
+}
+
+// Format calls FormatFunc.
+func (m *BlockDevice) Format(arg0 gomaasapi.FormatStorageDeviceArgs) error {
+	m.record("Format", arg0)
+	if m.FormatFunc == nil {
+		panic("mocks.BlockDevice.Format called without FormatFunc")
+	}
+	return m.FormatFunc(arg0)
+}
+
+// ID calls IDFunc.
+func (m *BlockDevice) ID() int {
+	m.record("ID")
+	if m.IDFunc == nil {
+		panic("mocks.BlockDevice.ID called without IDFunc")
+	}
+	return m.IDFunc()
+}
+
+// IDPath calls IDPathFunc.
+func (m *BlockDevice) IDPath() string {
+	m.record("IDPath")
+	if m.IDPathFunc == nil {
+		panic("mocks.BlockDevice.IDPath called without IDPathFunc")
+	}
+	return m.IDPathFunc()
+}
+
+// Model calls ModelFunc.
+func (m *BlockDevice) Model() string {
+	m.record("Model")
+	if m.ModelFunc == nil {
+		panic("mocks.BlockDevice.Model called without ModelFunc")
+	}
+	return m.ModelFunc()
+}
+
+// Mount calls MountFunc.
+func (m *BlockDevice) Mount(arg0 gomaasapi.MountStorageDeviceArgs) error {
+	m.record("Mount", arg0)
+	if m.MountFunc == nil {
+		panic("mocks.BlockDevice.Mount called without MountFunc")
+	}
+	return m.MountFunc(arg0)
+}
+
+// Name calls NameFunc.
+func (m *BlockDevice) Name() string {
+	m.record("Name")
+	if m.NameFunc == nil {
+		panic("mocks.BlockDevice.Name called without NameFunc")
+	}
+	return m.NameFunc()
+}
+
+// Parent calls ParentFunc.
+func (m *BlockDevice) Parent() *gomaasapi.BlockDeviceParent {
+	m.record("Parent")
+	if m.ParentFunc == nil {
+		panic("mocks.BlockDevice.Parent called without ParentFunc")
+	}
+	return m.ParentFunc()
+}
+
+// Partitions calls PartitionsFunc.
+func (m *BlockDevice) Partitions() []gomaasapi.Partition {
+	m.record("Partitions")
+	if m.PartitionsFunc == nil {
+		panic("mocks.BlockDevice.Partitions called without PartitionsFunc")
+	}
+	return m.PartitionsFunc()
+}
+
+// Path calls PathFunc.
+func (m *BlockDevice) Path() string {
+	m.record("Path")
+	if m.PathFunc == nil {
+		panic("mocks.BlockDevice.Path called without PathFunc")
+	}
+	return m.PathFunc()
+}
+
+// Serial calls SerialFunc.
+func (m *BlockDevice) Serial() string {
+	m.record("Serial")
+	if m.SerialFunc == nil {
+		panic("mocks.BlockDevice.Serial called without SerialFunc")
+	}
+	return m.SerialFunc()
+}
+
+// Size calls SizeFunc.
+func (m *BlockDevice) Size() uint64 {
+	m.record("Size")
+	if m.SizeFunc == nil {
+		panic("mocks.BlockDevice.Size called without SizeFunc")
+	}
+	return m.SizeFunc()
+}
+
+// Tags calls TagsFunc.
+func (m *BlockDevice) Tags() []string {
+	m.record("Tags")
+	if m.TagsFunc == nil {
+		panic("mocks.BlockDevice.Tags called without TagsFunc")
+	}
+	return m.TagsFunc()
+}
+
+// Type calls TypeFunc.
+func (m *BlockDevice) Type() string {
+	m.record("Type")
+	if m.TypeFunc == nil {
+		panic("mocks.BlockDevice.Type called without TypeFunc")
+	}
+	return m.TypeFunc()
+}
+
+// UUID calls UUIDFunc.
+func (m *BlockDevice) UUID() string {
+	m.record("UUID")
+	if m.UUIDFunc == nil {
+		panic("mocks.BlockDevice.UUID called without UUIDFunc")
+	}
+	return m.UUIDFunc()
+}
+
+// Unformat calls UnformatFunc.
+func (m *BlockDevice) Unformat() error {
+	m.record("Unformat")
+	if m.UnformatFunc == nil {
+		panic("mocks.BlockDevice.Unformat called without UnformatFunc")
+	}
+	return m.UnformatFunc()
+}
+
+// Unmount calls UnmountFunc.
+func (m *BlockDevice) Unmount() error {
+	m.record("Unmount")
+	if m.UnmountFunc == nil {
+		panic("mocks.BlockDevice.Unmount called without UnmountFunc")
+	}
+	return m.UnmountFunc()
+}
+
+// Update calls UpdateFunc.
+func (m *BlockDevice) Update(arg0 gomaasapi.UpdateBlockDeviceArgs) error {
+	m.record("Update", arg0)
+	if m.UpdateFunc == nil {
+		panic("mocks.BlockDevice.Update called without UpdateFunc")
+	}
+	return m.UpdateFunc(arg0)
+}
+
+// UsedFor calls UsedForFunc.
+func (m *BlockDevice) UsedFor() string {
+	m.record("UsedFor")
+	if m.UsedForFunc == nil {
+		panic("mocks.BlockDevice.UsedFor called without UsedForFunc")
+	}
+	return m.UsedForFunc()
+}
+
+// UsedSize calls UsedSizeFunc.
+func (m *BlockDevice) UsedSize() uint64 {
+	m.record("UsedSize")
+	if m.UsedSizeFunc == nil {
+		panic("mocks.BlockDevice.UsedSize called without UsedSizeFunc")
+	}
+	return m.UsedSizeFunc()
+}
+
+// RAID is a mock of gomaasapi.RAID.
+type RAID struct {
+	DeleteFunc        func() error
+	DevicesFunc       func() []gomaasapi.StorageDevice
+	IDFunc            func() int
+	LevelFunc         func() gomaasapi.RAIDLevel
+	NameFunc          func() string
+	SizeFunc          func() uint64
+	SpareDevicesFunc  func() []gomaasapi.StorageDevice
+	UUIDFunc          func() string
+	UpdateFunc        func(gomaasapi.UpdateRAIDArgs) error
+	VirtualDeviceFunc func() gomaasapi.BlockDevice
+
+	recorder
+}
+
+var _ gomaasapi.RAID = (*RAID)(nil)
+
+// Delete calls DeleteFunc.
+func (m *RAID) Delete() error {
+	m.record("Delete")
+	if m.DeleteFunc == nil {
+		panic("mocks.RAID.Delete called without DeleteFunc")
+	}
+	return m.DeleteFunc()
+}
+
+// Devices calls DevicesFunc.
+func (m *RAID) Devices() []gomaasapi.StorageDevice {
+	m.record("Devices")
+	if m.DevicesFunc == nil {
+		panic("mocks.RAID.Devices called without DevicesFunc")
+	}
+	return m.DevicesFunc()
+}
+
+// ID calls IDFunc.
+func (m *RAID) ID() int {
+	m.record("ID")
+	if m.IDFunc == nil {
+		panic("mocks.RAID.ID called without IDFunc")
+	}
+	return m.IDFunc()
+}
+
+// Level calls LevelFunc.
+func (m *RAID) Level() gomaasapi.RAIDLevel {
+	m.record("Level")
+	if m.LevelFunc == nil {
+		panic("mocks.RAID.Level called without LevelFunc")
+	}
+	return m.LevelFunc()
+}
+
+// Name calls NameFunc.
+func (m *RAID) Name() string {
+	m.record("Name")
+	if m.NameFunc == nil {
+		panic("mocks.RAID.Name called without NameFunc")
+	}
+	return m.NameFunc()
+}
+
+// Size calls SizeFunc.
+func (m *RAID) Size() uint64 {
+	m.record("Size")
+	if m.SizeFunc == nil {
+		panic("mocks.RAID.Size called without SizeFunc")
+	}
+	return m.SizeFunc()
+}
+
+// SpareDevices calls SpareDevicesFunc.
+func (m *RAID) SpareDevices() []gomaasapi.StorageDevice {
+	m.record("SpareDevices")
+	if m.SpareDevicesFunc == nil {
+		panic("mocks.RAID.SpareDevices called without SpareDevicesFunc")
+	}
+	return m.SpareDevicesFunc()
+}
+
+// UUID calls UUIDFunc.
+func (m *RAID) UUID() string {
+	m.record("UUID")
+	if m.UUIDFunc == nil {
+		panic("mocks.RAID.UUID called without UUIDFunc")
+	}
+	return m.UUIDFunc()
+}
+
+// Update calls UpdateFunc.
+func (m *RAID) Update(arg0 gomaasapi.UpdateRAIDArgs) error {
+	m.record("Update", arg0)
+	if m.UpdateFunc == nil {
+		panic("mocks.RAID.Update called without UpdateFunc")
+	}
+	return m.UpdateFunc(arg0)
+}
+
+// VirtualDevice calls VirtualDeviceFunc.
+func (m *RAID) VirtualDevice() gomaasapi.BlockDevice {
+	m.record("VirtualDevice")
+	if m.VirtualDeviceFunc == nil {
+		panic("mocks.RAID.VirtualDevice called without VirtualDeviceFunc")
+	}
+	return m.VirtualDeviceFunc()
+}
+
+// VolumeGroup is a mock of gomaasapi.VolumeGroup.
+type VolumeGroup struct {
+	AvailableSizeFunc       func() uint64
+	CreateLogicalVolumeFunc func(gomaasapi.CreateLogicalVolumeArgs) (gomaasapi.BlockDevice, error)
+	DeleteFunc              func() error
+	DeleteLogicalVolumeFunc func(gomaasapi.BlockDevice) error
+	DevicesFunc             func() []gomaasapi.StorageDevice
+	IDFunc                  func() int
+	LogicalVolumesFunc      func() []gomaasapi.BlockDevice
+	NameFunc                func() string
+	SizeFunc                func() uint64
+	UUIDFunc                func() string
+	UsedSizeFunc            func() uint64
+
+	recorder
+}
+
+var _ gomaasapi.VolumeGroup = (*VolumeGroup)(nil)
+
+// AvailableSize calls AvailableSizeFunc.
+func (m *VolumeGroup) AvailableSize() uint64 {
+	m.record("AvailableSize")
+	if m.AvailableSizeFunc == nil {
+		panic("mocks.VolumeGroup.AvailableSize called without AvailableSizeFunc")
+	}
+	return m.AvailableSizeFunc()
+}
+
+// CreateLogicalVolume calls CreateLogicalVolumeFunc.
+func (m *VolumeGroup) CreateLogicalVolume(arg0 gomaasapi.CreateLogicalVolumeArgs) (gomaasapi.BlockDevice, error) {
+	m.record("CreateLogicalVolume", arg0)
+	if m.CreateLogicalVolumeFunc == nil {
+		panic("mocks.VolumeGroup.CreateLogicalVolume called without CreateLogicalVolumeFunc")
+	}
+	return m.CreateLogicalVolumeFunc(arg0)
+}
+
+// Delete calls DeleteFunc.
+func (m *VolumeGroup) Delete() error {
+	m.record("Delete")
+	if m.DeleteFunc == nil {
+		panic("mocks.VolumeGroup.Delete called without DeleteFunc")
+	}
+	return m.DeleteFunc()
+}
+
+// DeleteLogicalVolume calls DeleteLogicalVolumeFunc.
+func (m *VolumeGroup) DeleteLogicalVolume(arg0 gomaasapi.BlockDevice) error {
+	m.record("DeleteLogicalVolume", arg0)
+	if m.DeleteLogicalVolumeFunc == nil {
+		panic("mocks.VolumeGroup.DeleteLogicalVolume called without DeleteLogicalVolumeFunc")
+	}
+	return m.DeleteLogicalVolumeFunc(arg0)
+}
+
+// Devices calls DevicesFunc.
+func (m *VolumeGroup) Devices() []gomaasapi.StorageDevice {
+	m.record("Devices")
+	if m.DevicesFunc == nil {
+		panic("mocks.VolumeGroup.Devices called without DevicesFunc")
+	}
+	return m.DevicesFunc()
+}
+
+// ID calls IDFunc.
+func (m *VolumeGroup) ID() int {
+	m.record("ID")
+	if m.IDFunc == nil {
+		panic("mocks.VolumeGroup.ID called without IDFunc")
+	}
+	return m.IDFunc()
+}
+
+// LogicalVolumes calls LogicalVolumesFunc.
+func (m *VolumeGroup) LogicalVolumes() []gomaasapi.BlockDevice {
+	m.record("LogicalVolumes")
+	if m.LogicalVolumesFunc == nil {
+		panic("mocks.VolumeGroup.LogicalVolumes called without LogicalVolumesFunc")
+	}
+	return m.LogicalVolumesFunc()
+}
+
+// Name calls NameFunc.
+func (m *VolumeGroup) Name() string {
+	m.record("Name")
+	if m.NameFunc == nil {
+		panic("mocks.VolumeGroup.Name called without NameFunc")
+	}
+	return m.NameFunc()
+}
+
+// Size calls SizeFunc.
+func (m *VolumeGroup) Size() uint64 {
+	m.record("Size")
+	if m.SizeFunc == nil {
+		panic("mocks.VolumeGroup.Size called without SizeFunc")
+	}
+	return m.SizeFunc()
+}
+
+// UUID calls UUIDFunc.
+func (m *VolumeGroup) UUID() string {
+	m.record("UUID")
+	if m.UUIDFunc == nil {
+		panic("mocks.VolumeGroup.UUID called without UUIDFunc")
+	}
+	return m.UUIDFunc()
+}
+
+// UsedSize calls UsedSizeFunc.
+func (m *VolumeGroup) UsedSize() uint64 {
+	m.record("UsedSize")
+	if m.UsedSizeFunc == nil {
+		panic("mocks.VolumeGroup.UsedSize called without UsedSizeFunc")
+	}
+	return m.UsedSizeFunc()
+}
+
+// VMFSDatastore is a mock of gomaasapi.VMFSDatastore.
+type VMFSDatastore struct {
+	DeleteFunc     func() error
+	DevicesFunc    func() []gomaasapi.StorageDevice
+	FSTypeFunc     func() string
+	IDFunc         func() int
+	MountPointFunc func() string
+	NameFunc       func() string
+	SizeFunc       func() uint64
+	UUIDFunc       func() string
+	UpdateFunc     func(gomaasapi.UpdateVMFSDatastoreArgs) error
+
+	recorder
+}
+
+var _ gomaasapi.VMFSDatastore = (*VMFSDatastore)(nil)
+
+// Delete calls DeleteFunc.
+func (m *VMFSDatastore) Delete() error {
+	m.record("Delete")
+	if m.DeleteFunc == nil {
+		panic("mocks.VMFSDatastore.Delete called without DeleteFunc")
+	}
+	return m.DeleteFunc()
+}
+
+// Devices calls DevicesFunc.
+func (m *VMFSDatastore) Devices() []gomaasapi.StorageDevice {
+	m.record("Devices")
+	if m.DevicesFunc == nil {
+		panic("mocks.VMFSDatastore.Devices called without DevicesFunc")
+	}
+	return m.DevicesFunc()
+}
+
+// FSType calls FSTypeFunc.
+func (m *VMFSDatastore) FSType() string {
+	m.record("FSType")
+	if m.FSTypeFunc == nil {
+		panic("mocks.VMFSDatastore.FSType called without FSTypeFunc")
+	}
+	return m.FSTypeFunc()
+}
+
+// ID calls IDFunc.
+func (m *VMFSDatastore) ID() int {
+	m.record("ID")
+	if m.IDFunc == nil {
+		panic("mocks.VMFSDatastore.ID called without IDFunc")
+	}
+	return m.IDFunc()
+}
+
+// MountPoint calls MountPointFunc.
+func (m *VMFSDatastore) MountPoint() string {
+	m.record("MountPoint")
+	if m.MountPointFunc == nil {
+		panic("mocks.VMFSDatastore.MountPoint called without MountPointFunc")
+	}
+	return m.MountPointFunc()
+}
+
+// Name calls NameFunc.
+func (m *VMFSDatastore) Name() string {
+	m.record("Name")
+	if m.NameFunc == nil {
+		panic("mocks.VMFSDatastore.Name called without NameFunc")
+	}
+	return m.NameFunc()
+}
+
+// Size calls SizeFunc.
+func (m *VMFSDatastore) Size() uint64 {
+	m.record("Size")
+	if m.SizeFunc == nil {
+		panic("mocks.VMFSDatastore.Size called without SizeFunc")
+	}
+	return m.SizeFunc()
+}
+
+// UUID calls UUIDFunc.
+func (m *VMFSDatastore) UUID() string {
+	m.record("UUID")
+	if m.UUIDFunc == nil {
+		panic("mocks.VMFSDatastore.UUID called without UUIDFunc")
+	}
+	return m.UUIDFunc()
+}
+
+// Update calls UpdateFunc.
+func (m *VMFSDatastore) Update(arg0 gomaasapi.UpdateVMFSDatastoreArgs) error {
+	m.record("Update", arg0)
+	if m.UpdateFunc == nil {
+		panic("mocks.VMFSDatastore.Update called without UpdateFunc")
+	}
+	return m.UpdateFunc(arg0)
+}
+
+// OwnerDataHolder is a mock of gomaasapi.OwnerDataHolder.
+type OwnerDataHolder struct {
+	OwnerDataFunc    func() map[string]string
+	SetOwnerDataFunc func(map[string]string) error
+
+	recorder
+}
+
+var _ gomaasapi.OwnerDataHolder = (*OwnerDataHolder)(nil)
+
+// OwnerData calls OwnerDataFunc.
+func (m *OwnerDataHolder) OwnerData() map[string]string {
+	m.record("OwnerData")
+	if m.OwnerDataFunc == nil {
+		panic("mocks.OwnerDataHolder.OwnerData called without OwnerDataFunc")
+	}
+	return m.OwnerDataFunc()
+}
+
+// SetOwnerData calls SetOwnerDataFunc.
+func (m *OwnerDataHolder) SetOwnerData(arg0 map[string]string) error {
+	m.record("SetOwnerData", arg0)
+	if m.SetOwnerDataFunc == nil {
+		panic("mocks.OwnerDataHolder.SetOwnerData called without SetOwnerDataFunc")
+	}
+	return m.SetOwnerDataFunc(arg0)
+}
+
+// Lease is a mock of gomaasapi.Lease.
+type Lease struct {
+	ExpiredFunc func() bool
+	ExpiryFunc  func() time.Time
+	HolderFunc  func() string
+	MachineFunc func() gomaasapi.Machine
+	PurposeFunc func() string
+	ReclaimFunc func(gomaasapi.ReclaimLeaseArgs) error
+	RenewFunc   func(time.Duration) error
+
+	recorder
+}
+
+var _ gomaasapi.Lease = (*Lease)(nil)
+
+// Expired calls ExpiredFunc.
+func (m *Lease) Expired() bool {
+	m.record("Expired")
+	if m.ExpiredFunc == nil {
+		panic("mocks.Lease.Expired called without ExpiredFunc")
+	}
+	return m.ExpiredFunc()
+}
+
+// Expiry calls ExpiryFunc.
+func (m *Lease) Expiry() time.Time {
+	m.record("Expiry")
+	if m.ExpiryFunc == nil {
+		panic("mocks.Lease.Expiry called without ExpiryFunc")
+	}
+	return m.ExpiryFunc()
+}
+
+// Holder calls HolderFunc.
+func (m *Lease) Holder() string {
+	m.record("Holder")
+	if m.HolderFunc == nil {
+		panic("mocks.Lease.Holder called without HolderFunc")
+	}
+	return m.HolderFunc()
+}
+
+// Machine calls MachineFunc.
+func (m *Lease) Machine() gomaasapi.Machine {
+	m.record("Machine")
+	if m.MachineFunc == nil {
+		panic("mocks.Lease.Machine called without MachineFunc")
+	}
+	return m.MachineFunc()
+}
+
+// Purpose calls PurposeFunc.
+func (m *Lease) Purpose() string {
+	m.record("Purpose")
+	if m.PurposeFunc == nil {
+		panic("mocks.Lease.Purpose called without PurposeFunc")
+	}
+	return m.PurposeFunc()
+}
+
+// Reclaim calls ReclaimFunc.
+func (m *Lease) Reclaim(arg0 gomaasapi.ReclaimLeaseArgs) error {
+	m.record("Reclaim", arg0)
+	if m.ReclaimFunc == nil {
+		panic("mocks.Lease.Reclaim called without ReclaimFunc")
+	}
+	return m.ReclaimFunc(arg0)
+}
+
+// Renew calls RenewFunc.
+func (m *Lease) Renew(arg0 time.Duration) error {
+	m.record("Renew", arg0)
+	if m.RenewFunc == nil {
+		panic("mocks.Lease.Renew called without RenewFunc")
+	}
+	return m.RenewFunc(arg0)
+}
+
+// Tag is a mock of gomaasapi.Tag.
+type Tag struct {
+	CommentFunc     func() string
+	DefinitionFunc  func() string
+	DeleteFunc      func() error
+	DevicesFunc     func() ([]gomaasapi.Device, error)
+	KernelOptsFunc  func() string
+	MachinesFunc    func() ([]gomaasapi.Machine, error)
+	NameFunc        func() string
+	NodesFunc       func() ([]gomaasapi.TaggedNode, error)
+	RebuildFunc     func() error
+	UpdateFunc      func(gomaasapi.UpdateTagArgs) error
+	UpdateNodesFunc func(gomaasapi.UpdateTagNodesArgs) (int, int, error)
+
+	recorder
+}
+
+var _ gomaasapi.Tag = (*Tag)(nil)
+
+// Comment calls CommentFunc.
+func (m *Tag) Comment() string {
+	m.record("Comment")
+	if m.CommentFunc == nil {
+		panic("mocks.Tag.Comment called without CommentFunc")
+	}
+	return m.CommentFunc()
+}
+
+// Definition calls DefinitionFunc.
+func (m *Tag) Definition() string {
+	m.record("Definition")
+	if m.DefinitionFunc == nil {
+		panic("mocks.Tag.Definition called without DefinitionFunc")
+	}
+	return m.DefinitionFunc()
+}
+
+// Delete calls DeleteFunc.
+func (m *Tag) Delete() error {
+	m.record("Delete")
+	if m.DeleteFunc == nil {
+		panic("mocks.Tag.Delete called without DeleteFunc")
+	}
+	return m.DeleteFunc()
+}
+
+// Devices calls DevicesFunc.
+func (m *Tag) Devices() ([]gomaasapi.Device, error) {
+	m.record("Devices")
+	if m.DevicesFunc == nil {
+		panic("mocks.Tag.Devices called without DevicesFunc")
+	}
+	return m.DevicesFunc()
+}
+
+// KernelOpts calls KernelOptsFunc.
+func (m *Tag) KernelOpts() string {
+	m.record("KernelOpts")
+	if m.KernelOptsFunc == nil {
+		panic("mocks.Tag.KernelOpts called without KernelOptsFunc")
+	}
+	return m.KernelOptsFunc()
+}
+
+// Machines calls MachinesFunc.
+func (m *Tag) Machines() ([]gomaasapi.Machine, error) {
+	m.record("Machines")
+	if m.MachinesFunc == nil {
+		panic("mocks.Tag.Machines called without MachinesFunc")
+	}
+	return m.MachinesFunc()
+}
+
+// Name calls NameFunc.
+func (m *Tag) Name() string {
+	m.record("Name")
+	if m.NameFunc == nil {
+		panic("mocks.Tag.Name called without NameFunc")
+	}
+	return m.NameFunc()
+}
+
+// Nodes calls NodesFunc.
+func (m *Tag) Nodes() ([]gomaasapi.TaggedNode, error) {
+	m.record("Nodes")
+	if m.NodesFunc == nil {
+		panic("mocks.Tag.Nodes called without NodesFunc")
+	}
+	return m.NodesFunc()
+}
+
+// Rebuild calls RebuildFunc.
+func (m *Tag) Rebuild() error {
+	m.record("Rebuild")
+	if m.RebuildFunc == nil {
+		panic("mocks.Tag.Rebuild called without RebuildFunc")
+	}
+	return m.RebuildFunc()
+}
+
+// Update calls UpdateFunc.
+func (m *Tag) Update(arg0 gomaasapi.UpdateTagArgs) error {
+	m.record("Update", arg0)
+	if m.UpdateFunc == nil {
+		panic("mocks.Tag.Update called without UpdateFunc")
+	}
+	return m.UpdateFunc(arg0)
+}
+
+// UpdateNodes calls UpdateNodesFunc.
+func (m *Tag) UpdateNodes(arg0 gomaasapi.UpdateTagNodesArgs) (int, int, error) {
+	m.record("UpdateNodes", arg0)
+	if m.UpdateNodesFunc == nil {
+		panic("mocks.Tag.UpdateNodes called without UpdateNodesFunc")
+	}
+	return m.UpdateNodesFunc(arg0)
+}
@@ -0,0 +1,70 @@
+// Copyright 2022 Canonical Ltd.
+// Licensed under the LGPLv3, see LICENCE file for details.
+
+package mocks_test
+
+import (
+	stdtesting "testing"
+
+	"github.com/juju/errors"
+	jc "github.com/juju/testing/checkers"
+	gc "gopkg.in/check.v1"
+
+	gomaasapi "github.com/juju/gomaasapi/v2"
+	"github.com/juju/gomaasapi/v2/mocks"
+)
+
+func Test(t *stdtesting.T) {
+	gc.TestingT(t)
+}
+
+type mocksSuite struct{}
+
+var _ = gc.Suite(&mocksSuite{})
+
+func (*mocksSuite) TestFunctionsCalled(c *gc.C) {
+	machine := &mocks.Machine{
+		SystemIDFunc: func() string { return "abc123" },
+	}
+	controller := &mocks.Controller{
+		MachinesFunc: func(args gomaasapi.MachinesArgs) ([]gomaasapi.Machine, error) {
+			return []gomaasapi.Machine{machine}, nil
+		},
+		SubnetByIDFunc: func(id int) (gomaasapi.Subnet, error) {
+			return nil, errors.NotFoundf("subnet %d", id)
+		},
+	}
+	var maas gomaasapi.Controller = controller
+
+	machines, err := maas.Machines(gomaasapi.MachinesArgs{Hostnames: []string{"foo"}})
+	c.Assert(err, jc.ErrorIsNil)
+	c.Assert(machines, gc.HasLen, 1)
+	c.Check(machines[0].SystemID(), gc.Equals, "abc123")
+	_, err = maas.SubnetByID(42)
+	c.Check(err, jc.Satisfies, errors.IsNotFound)
+
+	c.Check(controller.Calls(), jc.DeepEquals, []mocks.Call{
+		{Method: "Machines", Args: []interface{}{gomaasapi.MachinesArgs{Hostnames: []string{"foo"}}}},
+		{Method: "SubnetByID", Args: []interface{}{42}},
+	})
+	c.Check(machine.Calls(), jc.DeepEquals, []mocks.Call{{Method: "SystemID", Args: []interface{}{}}})
+}
+
+func (*mocksSuite) TestEmbeddedInterfaces(c *gc.C) {
+	var data map[string]string
+	machine := &mocks.Machine{
+		SetOwnerDataFunc: func(ownerData map[string]string) error {
+			data = ownerData
+			return nil
+		},
+	}
+	var holder gomaasapi.OwnerDataHolder = machine
+	c.Assert(holder.SetOwnerData(map[string]string{"owner": "juju"}), jc.ErrorIsNil)
+	c.Check(data, jc.DeepEquals, map[string]string{"owner": "juju"})
+}
+
+func (*mocksSuite) TestUnsetFunctionPanics(c *gc.C) {
+	zone := &mocks.Zone{}
+	c.Check(func() { zone.Name() }, gc.PanicMatches, `mocks.Zone.Name called without NameFunc`)
+	c.Check(zone.Calls(), jc.DeepEquals, []mocks.Call{{Method: "Name", Args: []interface{}{}}})
+}
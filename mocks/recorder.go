@@ -0,0 +1,32 @@
+// Copyright 2022 Canonical Ltd.
+// Licensed under the LGPLv3, see LICENCE file for details.
+
+package mocks
+
+import "sync"
+
+// Call is a call of a method of a mock.
+type Call struct {
+	Method string
+	Args   []interface{}
+}
+
+// recorder records the calls of the methods of a mock. The mocks may be
+// called concurrently.
+type recorder struct {
+	mu    sync.Mutex
+	calls []Call
+}
+
+func (r *recorder) record(method string, args ...interface{}) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	r.calls = append(r.calls, Call{Method: method, Args: args})
+}
+
+// Calls returns the calls of the methods of the mock, in order.
+func (r *recorder) Calls() []Call {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	return append([]Call(nil), r.calls...)
+}
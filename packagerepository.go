@@ -0,0 +1,353 @@
+// Copyright 2022 Canonical Ltd.
+// Licensed under the LGPLv3, see LICENCE file for details.
+
+package gomaasapi
+
+import (
+	"fmt"
+	"net/http"
+
+	"github.com/juju/errors"
+	"github.com/juju/schema"
+	"github.com/juju/version"
+)
+
+type packageRepository struct {
+	controller *controller
+
+	resourceURI string
+
+	id                 int
+	name               string
+	url                string
+	distributions      []string
+	components         []string
+	disabledPockets    []string
+	disabledComponents []string
+	architectures      []string
+	key                string
+	enabled            bool
+	disableSources     bool
+}
+
+func (r *packageRepository) updateFrom(other *packageRepository) {
+	r.resourceURI = other.resourceURI
+	r.id = other.id
+	r.name = other.name
+	r.url = other.url
+	r.distributions = other.distributions
+	r.components = other.components
+	r.disabledPockets = other.disabledPockets
+	r.disabledComponents = other.disabledComponents
+	r.architectures = other.architectures
+	r.key = other.key
+	r.enabled = other.enabled
+	r.disableSources = other.disableSources
+}
+
+// ID implements PackageRepository.
+func (r *packageRepository) ID() int {
+	return r.id
+}
+
+// Name implements PackageRepository.
+func (r *packageRepository) Name() string {
+	return r.name
+}
+
+// URL implements PackageRepository.
+func (r *packageRepository) URL() string {
+	return r.url
+}
+
+// Distributions implements PackageRepository.
+func (r *packageRepository) Distributions() []string {
+	return r.distributions
+}
+
+// Components implements PackageRepository.
+func (r *packageRepository) Components() []string {
+	return r.components
+}
+
+// DisabledPockets implements PackageRepository.
+func (r *packageRepository) DisabledPockets() []string {
+	return r.disabledPockets
+}
+
+// DisabledComponents implements PackageRepository.
+func (r *packageRepository) DisabledComponents() []string {
+	return r.disabledComponents
+}
+
+// Architectures implements PackageRepository.
+func (r *packageRepository) Architectures() []string {
+	return r.architectures
+}
+
+// Key implements PackageRepository.
+func (r *packageRepository) Key() string {
+	return r.key
+}
+
+// Enabled implements PackageRepository.
+func (r *packageRepository) Enabled() bool {
+	return r.enabled
+}
+
+// DisableSources implements PackageRepository.
+func (r *packageRepository) DisableSources() bool {
+	return r.disableSources
+}
+
+// PackageRepositoryArgs is an argument struct for
+// Controller.CreatePackageRepository and PackageRepository.Update. When
+// updating, only the fields that are set are changed.
+type PackageRepositoryArgs struct {
+	// Name and URL are required when creating a repository.
+	Name string
+	URL  string
+	// Distributions are the suites of a custom repository, such as
+	// "jammy".
+	Distributions []string
+	Components    []string
+	// DisabledPockets and DisabledComponents turn off parts of the Ubuntu
+	// archive, such as the "backports" pocket or the "multiverse"
+	// component.
+	DisabledPockets    []string
+	DisabledComponents []string
+	Architectures      []string
+	// Key is the armored GPG key that signs the repository.
+	Key            string
+	Enabled        *bool
+	DisableSources *bool
+}
+
+func (a *PackageRepositoryArgs) params() *URLParams {
+	params := NewURLParams()
+	params.MaybeAdd("name", a.Name)
+	params.MaybeAdd("url", a.URL)
+	params.MaybeAddMany("distributions", a.Distributions)
+	params.MaybeAddMany("components", a.Components)
+	params.MaybeAddMany("disabled_pockets", a.DisabledPockets)
+	params.MaybeAddMany("disabled_components", a.DisabledComponents)
+	params.MaybeAddMany("arches", a.Architectures)
+	params.MaybeAdd("key", a.Key)
+	if a.Enabled != nil {
+		params.Values.Add("enabled", fmt.Sprint(*a.Enabled))
+	}
+	if a.DisableSources != nil {
+		params.Values.Add("disable_sources", fmt.Sprint(*a.DisableSources))
+	}
+	return params
+}
+
+// Update implements PackageRepository.
+func (r *packageRepository) Update(args PackageRepositoryArgs) error {
+	source, err := r.controller.put(r.resourceURI, args.params().Values)
+	if err != nil {
+		if svrErr, ok := errors.Cause(err).(ServerError); ok {
+			switch svrErr.StatusCode {
+			case http.StatusNotFound:
+				return wrapError(err, NewNoMatchError(svrErr.BodyMessage))
+			case http.StatusForbidden:
+				return wrapError(err, NewPermissionError(svrErr.BodyMessage))
+			case http.StatusBadRequest:
+				return wrapError(err, NewBadRequestError(svrErr.BodyMessage))
+			}
+		}
+		return NewUnexpectedError(err)
+	}
+
+	response, err := readPackageRepository(r.controller.schemaVersion(), source)
+	if err != nil {
+		return errors.Trace(err)
+	}
+	r.updateFrom(response)
+	return nil
+}
+
+// Delete implements PackageRepository.
+func (r *packageRepository) Delete() error {
+	err := r.controller.delete(r.resourceURI)
+	if err != nil {
+		if svrErr, ok := errors.Cause(err).(ServerError); ok {
+			switch svrErr.StatusCode {
+			case http.StatusNotFound:
+				return wrapError(err, NewNoMatchError(svrErr.BodyMessage))
+			case http.StatusForbidden:
+				return wrapError(err, NewPermissionError(svrErr.BodyMessage))
+			case http.StatusBadRequest:
+				// MAAS refuses to delete the default Ubuntu archives.
+				return wrapError(err, NewBadRequestError(svrErr.BodyMessage))
+			}
+		}
+		return NewUnexpectedError(err)
+	}
+	return nil
+}
+
+// PackageRepositories implements Controller.
+func (c *controller) PackageRepositories() ([]PackageRepository, error) {
+	source, err := c.getList("package-repositories", nil, 0)
+	if err != nil {
+		return nil, NewUnexpectedError(err)
+	}
+	repositories, err := readPackageRepositories(c.schemaVersion(), source)
+	if err != nil {
+		return nil, errors.Trace(err)
+	}
+	var result []PackageRepository
+	for _, r := range repositories {
+		r.controller = c
+		result = append(result, r)
+	}
+	return result, nil
+}
+
+// CreatePackageRepository implements Controller.
+func (c *controller) CreatePackageRepository(args PackageRepositoryArgs) (PackageRepository, error) {
+	if args.Name == "" {
+		return nil, errors.NotValidf("missing Name")
+	}
+	if args.URL == "" {
+		return nil, errors.NotValidf("missing URL")
+	}
+	source, err := c.post("package-repositories", "", args.params().Values)
+	if err != nil {
+		if svrErr, ok := errors.Cause(err).(ServerError); ok {
+			switch svrErr.StatusCode {
+			case http.StatusForbidden:
+				return nil, wrapError(err, NewPermissionError(svrErr.BodyMessage))
+			case http.StatusBadRequest:
+				return nil, wrapError(err, NewBadRequestError(svrErr.BodyMessage))
+			}
+		}
+		return nil, NewUnexpectedError(err)
+	}
+	repository, err := readPackageRepository(c.schemaVersion(), source)
+	if err != nil {
+		return nil, errors.Trace(err)
+	}
+	repository.controller = c
+	return repository, nil
+}
+
+func readPackageRepository(controllerVersion version.Number, source interface{}) (*packageRepository, error) {
+	readFunc, err := getPackageRepositoryDeserializationFunc(controllerVersion)
+	if err != nil {
+		return nil, errors.Trace(err)
+	}
+
+	checker := schema.StringMap(schema.Any())
+	coerced, err := checker.Coerce(source, nil)
+	if err != nil {
+		return nil, WrapWithDeserializationError(err, "package repository base schema check failed")
+	}
+	valid := coerced.(map[string]interface{})
+	return readFunc(valid)
+}
+
+func readPackageRepositories(controllerVersion version.Number, source interface{}) ([]*packageRepository, error) {
+	readFunc, err := getPackageRepositoryDeserializationFunc(controllerVersion)
+	if err != nil {
+		return nil, errors.Trace(err)
+	}
+
+	checker := schema.List(schema.StringMap(schema.Any()))
+	coerced, err := checker.Coerce(source, nil)
+	if err != nil {
+		return nil, WrapWithDeserializationError(err, "package repository base schema check failed")
+	}
+	valid := coerced.([]interface{})
+	return readPackageRepositoryList(valid, readFunc)
+}
+
+func getPackageRepositoryDeserializationFunc(controllerVersion version.Number) (packageRepositoryDeserializationFunc, error) {
+	var deserialisationVersion version.Number
+	for v := range packageRepositoryDeserializationFuncs {
+		if v.Compare(deserialisationVersion) > 0 && v.Compare(controllerVersion) <= 0 {
+			deserialisationVersion = v
+		}
+	}
+	if deserialisationVersion == version.Zero {
+		return nil, NewUnsupportedVersionError("no package repository read func for version %s", controllerVersion)
+	}
+	return packageRepositoryDeserializationFuncs[deserialisationVersion], nil
+}
+
+// readPackageRepositoryList expects the values of the sourceList to be
+// string maps.
+func readPackageRepositoryList(sourceList []interface{}, readFunc packageRepositoryDeserializationFunc) ([]*packageRepository, error) {
+	result := make([]*packageRepository, 0, len(sourceList))
+	for i, value := range sourceList {
+		source, ok := value.(map[string]interface{})
+		if !ok {
+			return nil, NewDeserializationError("unexpected value for package repository %d, %T", i, value)
+		}
+		repository, err := readFunc(source)
+		if err != nil {
+			return nil, errors.Annotatef(err, "package repository %d", i)
+		}
+		result = append(result, repository)
+	}
+	return result, nil
+}
+
+type packageRepositoryDeserializationFunc func(map[string]interface{}) (*packageRepository, error)
+
+var packageRepositoryDeserializationFuncs = map[version.Number]packageRepositoryDeserializationFunc{
+	twoDotOh: packageRepository_2_0,
+}
+
+func packageRepository_2_0(source map[string]interface{}) (*packageRepository, error) {
+	fields := schema.Fields{
+		"resource_uri":        schema.String(),
+		"id":                  schema.ForceInt(),
+		"name":                schema.String(),
+		"url":                 schema.String(),
+		"distributions":       schema.List(schema.String()),
+		"components":          schema.List(schema.String()),
+		"disabled_pockets":    schema.List(schema.String()),
+		"disabled_components": schema.List(schema.String()),
+		"arches":              schema.List(schema.String()),
+		"key":                 schema.OneOf(schema.Nil(""), schema.String()),
+		"enabled":             schema.Bool(),
+		"disable_sources":     schema.Bool(),
+	}
+	// The disabled components and sources were added in MAAS 2.3.
+	defaults := schema.Defaults{
+		"distributions":       []interface{}{},
+		"components":          []interface{}{},
+		"disabled_pockets":    []interface{}{},
+		"disabled_components": []interface{}{},
+		"arches":              []interface{}{},
+		"key":                 "",
+		"disable_sources":     false,
+	}
+	checker := schema.FieldMap(fields, defaults)
+	coerced, err := checker.Coerce(source, nil)
+	if err != nil {
+		return nil, WrapWithDeserializationError(err, "package repository 2.0 schema check failed")
+	}
+	valid := coerced.(map[string]interface{})
+	// From here we know that the map returned from the schema coercion
+	// contains fields of the right type.
+
+	key, _ := valid["key"].(string)
+	result := &packageRepository{
+		resourceURI:        valid["resource_uri"].(string),
+		id:                 valid["id"].(int),
+		name:               valid["name"].(string),
+		url:                valid["url"].(string),
+		distributions:      convertToStringSlice(valid["distributions"]),
+		components:         convertToStringSlice(valid["components"]),
+		disabledPockets:    convertToStringSlice(valid["disabled_pockets"]),
+		disabledComponents: convertToStringSlice(valid["disabled_components"]),
+		architectures:      convertToStringSlice(valid["arches"]),
+		key:                key,
+		enabled:            valid["enabled"].(bool),
+		disableSources:     valid["disable_sources"].(bool),
+	}
+	return result, nil
+}
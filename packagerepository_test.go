@@ -0,0 +1,201 @@
+// Copyright 2022 Canonical Ltd.
+// Licensed under the LGPLv3, see LICENCE file for details.
+
+package gomaasapi
+
+import (
+	"net/http"
+
+	"github.com/juju/errors"
+	"github.com/juju/testing"
+	jc "github.com/juju/testing/checkers"
+	"github.com/juju/version"
+	gc "gopkg.in/check.v1"
+)
+
+type packageRepositorySuite struct {
+	testing.LoggingCleanupSuite
+}
+
+var _ = gc.Suite(&packageRepositorySuite{})
+
+func (*packageRepositorySuite) TestReadPackageRepositoriesBadSchema(c *gc.C) {
+	_, err := readPackageRepositories(twoDotOh, "wat?")
+	c.Check(err, jc.Satisfies, IsDeserializationError)
+	c.Assert(err.Error(), gc.Equals, `package repository base schema check failed: expected list, got string("wat?")`)
+}
+
+func (*packageRepositorySuite) TestReadPackageRepositories(c *gc.C) {
+	repositories, err := readPackageRepositories(twoDotOh, parseJSON(c, packageRepositoriesResponse))
+	c.Assert(err, jc.ErrorIsNil)
+	c.Assert(repositories, gc.HasLen, 2)
+
+	repository := repositories[0]
+	c.Check(repository.ID(), gc.Equals, 1)
+	c.Check(repository.Name(), gc.Equals, "main_archive")
+	c.Check(repository.URL(), gc.Equals, "http://archive.ubuntu.com/ubuntu")
+	c.Check(repository.Distributions(), gc.HasLen, 0)
+	c.Check(repository.Components(), gc.HasLen, 0)
+	c.Check(repository.DisabledPockets(), jc.DeepEquals, []string{"backports"})
+	c.Check(repository.DisabledComponents(), jc.DeepEquals, []string{"multiverse"})
+	c.Check(repository.Architectures(), jc.DeepEquals, []string{"amd64", "i386"})
+	c.Check(repository.Key(), gc.Equals, "")
+	c.Check(repository.Enabled(), jc.IsTrue)
+	c.Check(repository.DisableSources(), jc.IsTrue)
+
+	repository = repositories[1]
+	c.Check(repository.Distributions(), jc.DeepEquals, []string{"jammy"})
+	c.Check(repository.Components(), jc.DeepEquals, []string{"main"})
+	c.Check(repository.Key(), gc.Equals, "-----BEGIN PGP PUBLIC KEY BLOCK-----")
+	c.Check(repository.Enabled(), jc.IsFalse)
+	c.Check(repository.DisableSources(), jc.IsFalse)
+}
+
+func (*packageRepositorySuite) TestLowVersion(c *gc.C) {
+	_, err := readPackageRepositories(version.MustParse("1.9.0"), parseJSON(c, packageRepositoriesResponse))
+	c.Assert(err, jc.Satisfies, IsUnsupportedVersionError)
+}
+
+func (*packageRepositorySuite) TestHighVersion(c *gc.C) {
+	repositories, err := readPackageRepositories(version.MustParse("2.1.9"), parseJSON(c, packageRepositoriesResponse))
+	c.Assert(err, jc.ErrorIsNil)
+	c.Assert(repositories, gc.HasLen, 2)
+}
+
+func (s *packageRepositorySuite) getRepository(c *gc.C) (*SimpleTestServer, PackageRepository) {
+	server, controller := createTestServerController(c, s)
+	server.AddGetResponse("/api/2.0/package-repositories/", http.StatusOK, packageRepositoriesResponse)
+	repositories, err := controller.PackageRepositories()
+	c.Assert(err, jc.ErrorIsNil)
+	c.Assert(repositories, gc.HasLen, 2)
+	return server, repositories[1]
+}
+
+func (s *packageRepositorySuite) TestPackageRepositoriesPaginated(c *gc.C) {
+	server, controller := createTestServerController(c, s)
+	server.AddGetResponse("/api/2.0/package-repositories/", http.StatusOK, `{"items": `+packageRepositoriesResponse+`, "next": null}`)
+	result, err := controller.PackageRepositories()
+	c.Assert(err, jc.ErrorIsNil)
+	c.Check(result, gc.HasLen, 2)
+}
+
+func (s *packageRepositorySuite) TestUpdate(c *gc.C) {
+	server, repository := s.getRepository(c)
+	response := updateJSONMap(c, packageRepositoryResponse, map[string]interface{}{
+		"enabled":       true,
+		"distributions": []string{"jammy", "focal"},
+	})
+	server.AddPutResponse("/api/2.0/package-repositories/2/", http.StatusOK, response)
+	enabled := true
+	err := repository.Update(PackageRepositoryArgs{
+		Distributions: []string{"jammy", "focal"},
+		Enabled:       &enabled,
+	})
+	c.Assert(err, jc.ErrorIsNil)
+	c.Check(repository.Enabled(), jc.IsTrue)
+	c.Check(repository.Distributions(), jc.DeepEquals, []string{"jammy", "focal"})
+
+	form := server.LastRequest().PostForm
+	c.Check(form["distributions"], jc.DeepEquals, []string{"jammy", "focal"})
+	c.Check(form.Get("enabled"), gc.Equals, "true")
+	c.Check(form["name"], gc.IsNil)
+	c.Check(form["disable_sources"], gc.IsNil)
+}
+
+func (s *packageRepositorySuite) TestUpdateBadRequest(c *gc.C) {
+	server, repository := s.getRepository(c)
+	server.AddPutResponse("/api/2.0/package-repositories/2/", http.StatusBadRequest, "bad url")
+	err := repository.Update(PackageRepositoryArgs{URL: "nope"})
+	c.Assert(err, jc.Satisfies, IsBadRequestError)
+}
+
+func (s *packageRepositorySuite) TestDelete(c *gc.C) {
+	server, repository := s.getRepository(c)
+	server.AddDeleteResponse("/api/2.0/package-repositories/2/", http.StatusNoContent, "")
+	err := repository.Delete()
+	c.Assert(err, jc.ErrorIsNil)
+}
+
+func (s *packageRepositorySuite) TestDeleteBadRequest(c *gc.C) {
+	server, repository := s.getRepository(c)
+	server.AddDeleteResponse("/api/2.0/package-repositories/2/", http.StatusBadRequest, "Cannot delete the default Ubuntu archive")
+	err := repository.Delete()
+	c.Assert(err, jc.Satisfies, IsBadRequestError)
+}
+
+func (s *packageRepositorySuite) TestCreatePackageRepository(c *gc.C) {
+	server, controller := createTestServerController(c, s)
+	server.AddPostResponse("/api/2.0/package-repositories/?op=", http.StatusOK, packageRepositoryResponse)
+	disabled := false
+	repository, err := controller.CreatePackageRepository(PackageRepositoryArgs{
+		Name:          "mirror",
+		URL:           "http://mirror.example.com/tools",
+		Distributions: []string{"jammy"},
+		Components:    []string{"main"},
+		Key:           "-----BEGIN PGP PUBLIC KEY BLOCK-----",
+		Enabled:       &disabled,
+	})
+	c.Assert(err, jc.ErrorIsNil)
+	c.Check(repository.ID(), gc.Equals, 2)
+
+	form := server.LastRequest().PostForm
+	c.Check(form.Get("name"), gc.Equals, "mirror")
+	c.Check(form.Get("url"), gc.Equals, "http://mirror.example.com/tools")
+	c.Check(form["distributions"], jc.DeepEquals, []string{"jammy"})
+	c.Check(form["components"], jc.DeepEquals, []string{"main"})
+	c.Check(form.Get("key"), gc.Equals, "-----BEGIN PGP PUBLIC KEY BLOCK-----")
+	c.Check(form.Get("enabled"), gc.Equals, "false")
+}
+
+func (s *packageRepositorySuite) TestCreatePackageRepositoryValidates(c *gc.C) {
+	_, controller := createTestServerController(c, s)
+	_, err := controller.CreatePackageRepository(PackageRepositoryArgs{URL: "http://mirror.example.com/tools"})
+	c.Check(err, jc.Satisfies, errors.IsNotValid)
+	_, err = controller.CreatePackageRepository(PackageRepositoryArgs{Name: "mirror"})
+	c.Check(err, jc.Satisfies, errors.IsNotValid)
+}
+
+func (s *packageRepositorySuite) TestCreatePackageRepositoryForbidden(c *gc.C) {
+	server, controller := createTestServerController(c, s)
+	server.AddPostResponse("/api/2.0/package-repositories/?op=", http.StatusForbidden, "admins only")
+	_, err := controller.CreatePackageRepository(PackageRepositoryArgs{Name: "mirror", URL: "http://mirror.example.com/tools"})
+	c.Assert(err, jc.Satisfies, IsPermissionError)
+}
+
+const (
+	packageRepositoryResponse = `
+{
+    "id": 2,
+    "name": "mirror",
+    "url": "http://mirror.example.com/tools",
+    "distributions": ["jammy"],
+    "disabled_pockets": [],
+    "disabled_components": [],
+    "disable_sources": false,
+    "components": ["main"],
+    "arches": ["amd64"],
+    "key": "-----BEGIN PGP PUBLIC KEY BLOCK-----",
+    "enabled": false,
+    "resource_uri": "/api/2.0/package-repositories/2/"
+}
+`
+	packageRepositoriesResponse = `
+[
+    {
+        "id": 1,
+        "name": "main_archive",
+        "url": "http://archive.ubuntu.com/ubuntu",
+        "distributions": [],
+        "disabled_pockets": ["backports"],
+        "disabled_components": ["multiverse"],
+        "disable_sources": true,
+        "components": [],
+        "arches": ["amd64", "i386"],
+        "key": null,
+        "enabled": true,
+        "resource_uri": "/api/2.0/package-repositories/1/"
+    },
+` + packageRepositoryResponse + `
+]
+`
+)
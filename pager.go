@@ -0,0 +1,161 @@
+// Copyright 2022 Canonical Ltd.
+// Licensed under the LGPLv3, see LICENCE file for details.
+
+package gomaasapi
+
+import (
+	"net/url"
+	"strconv"
+
+	"github.com/juju/errors"
+)
+
+// machinesPager reads the listing of machines a page at a time, decoding
+// the machines as they are received, and returns them pageSize at a time.
+// MAAS versions that don't paginate the machines send the whole listing at
+// once, so it is read a single time and its machines returned from memory.
+type machinesPager struct {
+	controller *controller
+	args       MachinesArgs
+	pageSize   int
+
+	// path and query request the next page of the listing.
+	path  string
+	query url.Values
+	// read is the number of machines read from the listing, before the
+	// OwnerData and ChangedSince filters are applied.
+	read int
+	done bool
+
+	// pending holds the machines read but not yet returned, and failures
+	// the machines that were skipped since the last page was returned.
+	pending  []Machine
+	failures map[string]error
+}
+
+func newMachinesPager(c *controller, args MachinesArgs, pageSize int) *machinesPager {
+	return &machinesPager{
+		controller: c,
+		args:       args,
+		pageSize:   pageSize,
+		path:       "machines",
+		query:      args.params().Values,
+	}
+}
+
+// NextPage implements MachinesPager.
+func (p *machinesPager) NextPage() ([]Machine, error) {
+	// Pages emptied by the filters are skipped, as no machines mean that
+	// all the machines have been read.
+	for len(p.pending) < p.pageSize && !p.done {
+		if err := p.fetch(); err != nil {
+			return nil, errors.Trace(err)
+		}
+	}
+	count := len(p.pending)
+	if count > p.pageSize {
+		count = p.pageSize
+	}
+	machines := p.pending[:count:count]
+	p.pending = p.pending[count:]
+	if len(p.failures) > 0 {
+		failures := p.failures
+		p.failures = nil
+		return machines, NewPartialResultError("machines", failures)
+	}
+	return machines, nil
+}
+
+// fetch reads the next page of the listing into the pending machines.
+func (p *machinesPager) fetch() error {
+	c := p.controller
+	readFunc, err := getMachineDeserializationFunc(c.schemaVersion())
+	if err != nil {
+		return errors.Trace(err)
+	}
+	// The page is read again in full if it fails, so the machines it
+	// skipped are forgotten too.
+	read, pending := p.read, len(p.pending)
+	failures := make(map[string]error, len(p.failures))
+	for id, err := range p.failures {
+		failures[id] = err
+	}
+	each := func(bool) func(interface{}) error {
+		return func(item interface{}) error {
+			m, id, err := readMachineListItem(p.read, item, readFunc)
+			p.read++
+			if err != nil {
+				if !p.args.SkipInvalid {
+					return errors.Trace(err)
+				}
+				logger.Debugf("skipping machine %s: %v", id, err)
+				if p.failures == nil {
+					p.failures = make(map[string]error)
+				}
+				p.failures[id] = err
+				return nil
+			}
+			m.setController(c)
+			if ownerDataMatches(m.ownerData, p.args.OwnerData) && changedSince(m, p.args.ChangedSince) {
+				p.pending = append(p.pending, m)
+			}
+			return nil
+		}
+	}
+	page, count, err := c.streamPage(p.path, p.query, each)
+	if err != nil {
+		p.read, p.pending, p.failures = read, p.pending[:pending], failures
+		if IsDeserializationError(err) {
+			return errors.Trace(err)
+		}
+		return NewUnexpectedError(err)
+	}
+	switch {
+	case page == nil || count == 0:
+		// Plain lists hold all the machines.
+		p.done = true
+	case page.next != "":
+		nextURL, err := url.Parse(page.next)
+		if err != nil {
+			return NewDeserializationError("bad next page link %q: %v", page.next, err)
+		}
+		p.path = nextURL.Path
+		p.query = nextURL.Query()
+	case page.total > p.read:
+		p.query.Set("offset", strconv.Itoa(p.read))
+	default:
+		p.done = true
+	}
+	return nil
+}
+
+// All implements MachinesPager.
+func (p *machinesPager) All() ([]Machine, error) {
+	var (
+		result   []Machine
+		failures map[string]error
+	)
+	for {
+		machines, err := p.NextPage()
+		if err != nil {
+			partial, ok := errors.Cause(err).(*PartialResultError)
+			if !ok {
+				return nil, errors.Trace(err)
+			}
+			if failures == nil {
+				failures = make(map[string]error)
+			}
+			for key, failure := range partial.Failures {
+				failures[key] = failure
+			}
+		}
+		if len(machines) == 0 && err == nil {
+			break
+		}
+		result = append(result, machines...)
+	}
+	if len(failures) > 0 {
+		return result, NewPartialResultError("machines", failures)
+	}
+	return result, nil
+}
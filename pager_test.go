@@ -0,0 +1,190 @@
+// Copyright 2022 Canonical Ltd.
+// Licensed under the LGPLv3, see LICENCE file for details.
+
+package gomaasapi
+
+import (
+	"fmt"
+	"net/http"
+	"strings"
+
+	"github.com/juju/errors"
+	"github.com/juju/testing"
+	jc "github.com/juju/testing/checkers"
+	gc "gopkg.in/check.v1"
+)
+
+type pagerSuite struct {
+	testing.LoggingCleanupSuite
+}
+
+var _ = gc.Suite(&pagerSuite{})
+
+// numberedMachines returns the JSON of machines with the system IDs
+// machine-<first> to machine-<last>.
+func numberedMachines(c *gc.C, first, last int) string {
+	var machines []string
+	for i := first; i <= last; i++ {
+		machines = append(machines, updateJSONMap(c, machineResponse, map[string]interface{}{
+			"system_id": fmt.Sprintf("machine-%d", i),
+		}))
+	}
+	return strings.Join(machines, ", ")
+}
+
+func machineIDs(machines []Machine) []string {
+	result := make([]string, len(machines))
+	for i, m := range machines {
+		result[i] = m.SystemID()
+	}
+	return result
+}
+
+func (s *pagerSuite) TestValidation(c *gc.C) {
+	_, controller := createTestServerController(c, s)
+	_, err := controller.MachinesPager(MachinesArgs{}, 0)
+	c.Check(err, jc.Satisfies, errors.IsNotValid)
+	_, err = controller.MachinesPager(MachinesArgs{Limit: 10}, 10)
+	c.Check(err, jc.Satisfies, errors.IsNotValid)
+	_, err = controller.MachinesPager(MachinesArgs{Offset: 10}, 10)
+	c.Check(err, jc.Satisfies, errors.IsNotValid)
+}
+
+func (s *pagerSuite) TestPlainList(c *gc.C) {
+	server, controller := createTestServerController(c, s)
+	server.AddGetResponse("/api/2.0/machines/", http.StatusOK, "["+numberedMachines(c, 1, 3)+"]")
+	pager, err := controller.MachinesPager(MachinesArgs{}, 2)
+	c.Assert(err, jc.ErrorIsNil)
+
+	server.ResetRequests()
+	machines, err := pager.NextPage()
+	c.Assert(err, jc.ErrorIsNil)
+	c.Check(machineIDs(machines), jc.DeepEquals, []string{"machine-1", "machine-2"})
+	machines, err = pager.NextPage()
+	c.Assert(err, jc.ErrorIsNil)
+	c.Check(machineIDs(machines), jc.DeepEquals, []string{"machine-3"})
+	machines, err = pager.NextPage()
+	c.Assert(err, jc.ErrorIsNil)
+	c.Check(machines, gc.HasLen, 0)
+
+	// MAAS versions that don't paginate send the whole listing, so it is
+	// only requested once.
+	c.Check(server.RequestCount(), gc.Equals, 1)
+}
+
+func (s *pagerSuite) TestPaginated(c *gc.C) {
+	server, controller := createTestServerController(c, s)
+	server.AddGetResponse("/api/2.0/machines/?zone=foo", http.StatusOK,
+		`{"items": [`+numberedMachines(c, 1, 2)+`], "total": 3}`)
+	server.AddGetResponse("/api/2.0/machines/?offset=2&zone=foo", http.StatusOK,
+		`{"items": [`+numberedMachines(c, 3, 3)+`], "total": 3}`)
+	pager, err := controller.MachinesPager(MachinesArgs{Zone: "foo"}, 2)
+	c.Assert(err, jc.ErrorIsNil)
+
+	machines, err := pager.All()
+	c.Assert(err, jc.ErrorIsNil)
+	c.Check(machineIDs(machines), jc.DeepEquals, []string{"machine-1", "machine-2", "machine-3"})
+	c.Check(server.RequestCount(), gc.Equals, 4)
+}
+
+func (s *pagerSuite) TestLargerServerPages(c *gc.C) {
+	server, controller := createTestServerController(c, s)
+	server.AddGetResponse("/api/2.0/machines/", http.StatusOK,
+		`{"items": [`+numberedMachines(c, 1, 3)+`], "next": "/api/2.0/machines/?page=2"}`)
+	server.AddGetResponse("/api/2.0/machines/?page=2", http.StatusOK,
+		`{"items": [`+numberedMachines(c, 4, 4)+`], "next": null}`)
+	pager, err := controller.MachinesPager(MachinesArgs{}, 2)
+	c.Assert(err, jc.ErrorIsNil)
+
+	server.ResetRequests()
+	machines, err := pager.NextPage()
+	c.Assert(err, jc.ErrorIsNil)
+	c.Check(machineIDs(machines), jc.DeepEquals, []string{"machine-1", "machine-2"})
+	c.Check(server.RequestCount(), gc.Equals, 1)
+	machines, err = pager.NextPage()
+	c.Assert(err, jc.ErrorIsNil)
+	c.Check(machineIDs(machines), jc.DeepEquals, []string{"machine-3", "machine-4"})
+	machines, err = pager.NextPage()
+	c.Assert(err, jc.ErrorIsNil)
+	c.Check(machines, gc.HasLen, 0)
+	c.Check(server.RequestCount(), gc.Equals, 2)
+}
+
+func (s *pagerSuite) TestExactPages(c *gc.C) {
+	server, controller := createTestServerController(c, s)
+	server.AddGetResponse("/api/2.0/machines/", http.StatusOK,
+		`{"items": [`+numberedMachines(c, 1, 2)+`], "total": 2}`)
+	server.AddGetResponse("/api/2.0/machines/?offset=2", http.StatusOK,
+		`{"items": [], "total": 2}`)
+	pager, err := controller.MachinesPager(MachinesArgs{}, 2)
+	c.Assert(err, jc.ErrorIsNil)
+
+	machines, err := pager.All()
+	c.Assert(err, jc.ErrorIsNil)
+	c.Check(machines, gc.HasLen, 2)
+}
+
+func (s *pagerSuite) TestFilteredPagesAreSkipped(c *gc.C) {
+	server, controller := createTestServerController(c, s)
+	server.AddGetResponse("/api/2.0/machines/", http.StatusOK,
+		`{"items": [`+numberedMachines(c, 1, 2)+`], "total": 3}`)
+	server.AddGetResponse("/api/2.0/machines/?offset=2", http.StatusOK,
+		`{"items": [`+updateJSONMap(c, machineResponse, map[string]interface{}{
+			"system_id":  "machine-3",
+			"owner_data": map[string]interface{}{"owner": "me"},
+		})+`], "total": 3}`)
+	pager, err := controller.MachinesPager(MachinesArgs{OwnerData: map[string]string{"owner": "me"}}, 2)
+	c.Assert(err, jc.ErrorIsNil)
+
+	machines, err := pager.NextPage()
+	c.Assert(err, jc.ErrorIsNil)
+	c.Check(machineIDs(machines), jc.DeepEquals, []string{"machine-3"})
+}
+
+func (s *pagerSuite) TestAllSkipInvalid(c *gc.C) {
+	server, controller := createTestServerController(c, s)
+	server.AddGetResponse("/api/2.0/machines/", http.StatusOK,
+		`{"items": [`+numberedMachines(c, 1, 1)+`, {"system_id": "broken"}], "total": 3}`)
+	server.AddGetResponse("/api/2.0/machines/?offset=2", http.StatusOK,
+		`{"items": [`+numberedMachines(c, 3, 3)+`], "total": 3}`)
+	pager, err := controller.MachinesPager(MachinesArgs{SkipInvalid: true}, 2)
+	c.Assert(err, jc.ErrorIsNil)
+
+	machines, err := pager.All()
+	c.Assert(err, jc.Satisfies, IsPartialResultError)
+	c.Check(errors.Cause(err).(*PartialResultError).Failures, gc.HasLen, 1)
+	c.Check(machineIDs(machines), jc.DeepEquals, []string{"machine-1", "machine-3"})
+}
+
+func (s *pagerSuite) TestRetryAfterFailedPage(c *gc.C) {
+	server, controller := createTestServerController(c, s)
+	page := `{"items": [{"system_id": "broken"}, ` + numberedMachines(c, 1, 2) + `], "total": 3}`
+	// The first response is cut short after a machine was skipped, which
+	// isn't in the listing read again.
+	server.AddRouteResponses(SimpleRoute{Method: "GET", Path: "/api/2.0/machines/"},
+		SimpleResponse{Status: http.StatusOK, Body: `{"items": [{"system_id": "gone"}, {"system_id": `},
+		SimpleResponse{Status: http.StatusOK, Body: page},
+	)
+	pager, err := controller.MachinesPager(MachinesArgs{SkipInvalid: true}, 2)
+	c.Assert(err, jc.ErrorIsNil)
+
+	_, err = pager.NextPage()
+	c.Assert(err, gc.NotNil)
+
+	machines, err := pager.NextPage()
+	c.Assert(err, jc.Satisfies, IsPartialResultError)
+	failures := errors.Cause(err).(*PartialResultError).Failures
+	c.Check(failures, gc.HasLen, 1)
+	c.Check(failures["broken"], gc.NotNil)
+	c.Check(machineIDs(machines), jc.DeepEquals, []string{"machine-1", "machine-2"})
+}
+
+func (s *pagerSuite) TestError(c *gc.C) {
+	server, controller := createTestServerController(c, s)
+	server.AddGetResponse("/api/2.0/machines/", http.StatusInternalServerError, "boom")
+	pager, err := controller.MachinesPager(MachinesArgs{}, 2)
+	c.Assert(err, jc.ErrorIsNil)
+
+	_, err = pager.All()
+	c.Assert(err, jc.Satisfies, IsUnexpectedError)
+}
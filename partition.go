@@ -4,12 +4,18 @@
 package gomaasapi
 
 import (
+	"fmt"
+	"net/http"
+	"net/url"
+
 	"github.com/juju/errors"
 	"github.com/juju/schema"
 	"github.com/juju/version"
 )
 
 type partition struct {
+	controller *controller
+
 	resourceURI string
 
 	id      int
@@ -65,14 +71,120 @@ func (p *partition) Tags() []string {
 	return p.tags
 }
 
+// Delete implements Partition.
+func (p *partition) Delete() error {
+	err := p.controller.delete(p.resourceURI)
+	if err != nil {
+		return errors.Trace(storageDeviceError(err))
+	}
+	return nil
+}
+
+// Resize implements Partition.
+func (p *partition) Resize(size uint64) error {
+	if size == 0 {
+		return errors.NotValidf("zero size")
+	}
+	params := NewURLParams()
+	params.Values.Add("size", fmt.Sprint(size))
+	source, err := p.controller.put(p.resourceURI, params.Values)
+	if err != nil {
+		if svrErr, ok := errors.Cause(err).(ServerError); ok && svrErr.StatusCode == http.StatusMethodNotAllowed {
+			return errors.NewNotSupported(err, "resizing partitions")
+		}
+		return errors.Trace(storageDeviceError(err))
+	}
+
+	response, err := readPartition(p.controller.schemaVersion(), source)
+	if err != nil {
+		return errors.Trace(err)
+	}
+	p.updateFrom(response)
+	return nil
+}
+
+// Format implements StorageDevice.
+func (p *partition) Format(args FormatStorageDeviceArgs) error {
+	if err := args.Validate(); err != nil {
+		return errors.Trace(err)
+	}
+	return p.performOp("format", args.params())
+}
+
+// Unformat implements StorageDevice.
+func (p *partition) Unformat() error {
+	return p.performOp("unformat", nil)
+}
+
+// Mount implements StorageDevice.
+func (p *partition) Mount(args MountStorageDeviceArgs) error {
+	if err := args.Validate(); err != nil {
+		return errors.Trace(err)
+	}
+	return p.performOp("mount", args.params())
+}
+
+// Unmount implements StorageDevice.
+func (p *partition) Unmount() error {
+	return p.performOp("unmount", nil)
+}
+
+func (p *partition) performOp(op string, params url.Values) error {
+	source, err := p.controller.post(p.resourceURI, op, params)
+	if err != nil {
+		return errors.Trace(storageDeviceError(err))
+	}
+
+	response, err := readPartition(p.controller.schemaVersion(), source)
+	if err != nil {
+		return errors.Trace(err)
+	}
+	p.updateFrom(response)
+	return nil
+}
+
+func (p *partition) updateFrom(other *partition) {
+	p.resourceURI = other.resourceURI
+	p.id = other.id
+	p.path = other.path
+	p.uuid = other.uuid
+	p.usedFor = other.usedFor
+	p.size = other.size
+	p.tags = other.tags
+	p.filesystem = other.filesystem
+}
+
+func readPartition(controllerVersion version.Number, source interface{}) (*partition, error) {
+	readFunc, err := getPartitionDeserializationFunc(controllerVersion)
+	if err != nil {
+		return nil, errors.Trace(err)
+	}
+
+	checker := schema.StringMap(schema.Any())
+	coerced, err := checker.Coerce(source, nil)
+	if err != nil {
+		return nil, WrapWithDeserializationError(err, "partition base schema check failed")
+	}
+	valid := coerced.(map[string]interface{})
+	return readFunc(valid)
+}
+
 func readPartitions(controllerVersion version.Number, source interface{}) ([]*partition, error) {
+	readFunc, err := getPartitionDeserializationFunc(controllerVersion)
+	if err != nil {
+		return nil, errors.Trace(err)
+	}
+
 	checker := schema.List(schema.StringMap(schema.Any()))
 	coerced, err := checker.Coerce(source, nil)
 	if err != nil {
 		return nil, WrapWithDeserializationError(err, "partition base schema check failed")
 	}
 	valid := coerced.([]interface{})
+	return readPartitionList(valid, readFunc)
+}
 
+func getPartitionDeserializationFunc(controllerVersion version.Number) (partitionDeserializationFunc, error) {
 	var deserialisationVersion version.Number
 	for v := range partitionDeserializationFuncs {
 		if v.Compare(deserialisationVersion) > 0 && v.Compare(controllerVersion) <= 0 {
@@ -82,8 +194,7 @@ func readPartitions(controllerVersion version.Number, source interface{}) ([]*pa
 	if deserialisationVersion == version.Zero {
 		return nil, NewUnsupportedVersionError("no partition read func for version %s", controllerVersion)
 	}
-	readFunc := partitionDeserializationFuncs[deserialisationVersion]
-	return readPartitionList(valid, readFunc)
+	return partitionDeserializationFuncs[deserialisationVersion], nil
 }
 
 // readPartitionList expects the values of the sourceList to be string maps.
@@ -92,3 +92,18 @@ var partitionsResponse = `
     }
 ]
 `
+
+var partitionResponse = `
+{
+    "bootable": true,
+    "id": 2,
+    "path": "/dev/disk/by-dname/sda-part2",
+    "filesystem": null,
+    "type": "partition",
+    "resource_uri": "/MAAS/api/2.0/nodes/4y3ha3/blockdevices/34/partition/2",
+    "uuid": "e2b5b1f4-4d3e-4a65-a0b5-0b4a4c7d1a2f",
+    "used_for": "",
+    "size": 1073741824,
+    "tags": []
+}
+`
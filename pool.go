@@ -4,21 +4,35 @@
 package gomaasapi
 
 import (
+	"net/http"
+
 	"github.com/juju/errors"
 	"github.com/juju/schema"
 	"github.com/juju/version"
 )
 
 type pool struct {
-	// Add the controller in when we need to do things with the pool.
-	// controller Controller
+	controller *controller
 
 	resourceURI string
 
+	id          int
 	name        string
 	description string
 }
 
+func (p *pool) updateFrom(other *pool) {
+	p.resourceURI = other.resourceURI
+	p.id = other.id
+	p.name = other.name
+	p.description = other.description
+}
+
+// ID implements Pool.
+func (p *pool) ID() int {
+	return p.id
+}
+
 // Name implements Pool.
 func (p *pool) Name() string {
 	return p.name
@@ -29,9 +43,77 @@ func (p *pool) Description() string {
 	return p.description
 }
 
-func readPools(controllerVersion version.Number, source interface{}) ([]*pool, error) {
-	var deserialisationVersion version.Number
+// UpdatePoolArgs is an argument struct for Pool.Update. Only the fields
+// that are set are changed.
+type UpdatePoolArgs struct {
+	Name        string
+	Description string
+}
+
+// Update implements Pool.
+func (p *pool) Update(args UpdatePoolArgs) error {
+	params := NewURLParams()
+	params.MaybeAdd("name", args.Name)
+	params.MaybeAdd("description", args.Description)
+	source, err := p.controller.put(p.resourceURI, params.Values)
+	if err != nil {
+		if svrErr, ok := errors.Cause(err).(ServerError); ok {
+			switch svrErr.StatusCode {
+			case http.StatusNotFound:
+				return wrapError(err, NewNoMatchError(svrErr.BodyMessage))
+			case http.StatusForbidden:
+				return wrapError(err, NewPermissionError(svrErr.BodyMessage))
+			case http.StatusBadRequest:
+				return wrapError(err, NewBadRequestError(svrErr.BodyMessage))
+			}
+		}
+		return NewUnexpectedError(err)
+	}
+
+	response, err := readPool(p.controller.schemaVersion(), source)
+	if err != nil {
+		return errors.Trace(err)
+	}
+	p.updateFrom(response)
+	return nil
+}
 
+// Delete implements Pool.
+func (p *pool) Delete() error {
+	err := p.controller.delete(p.resourceURI)
+	if err != nil {
+		if svrErr, ok := errors.Cause(err).(ServerError); ok {
+			switch svrErr.StatusCode {
+			case http.StatusNotFound:
+				return wrapError(err, NewNoMatchError(svrErr.BodyMessage))
+			case http.StatusForbidden:
+				return wrapError(err, NewPermissionError(svrErr.BodyMessage))
+			case http.StatusBadRequest:
+				// MAAS refuses to delete the default pool.
+				return wrapError(err, NewBadRequestError(svrErr.BodyMessage))
+			}
+		}
+		return NewUnexpectedError(err)
+	}
+	return nil
+}
+
+func readPool(controllerVersion version.Number, source interface{}) (*pool, error) {
+	readFunc, err := getPoolDeserializationFunc(controllerVersion)
+	if err != nil {
+		return nil, errors.Trace(err)
+	}
+
+	checker := schema.StringMap(schema.Any())
+	coerced, err := checker.Coerce(source, nil)
+	if err != nil {
+		return nil, errors.Annotatef(err, "pool base schema check failed")
+	}
+	valid := coerced.(map[string]interface{})
+	return readFunc(valid)
+}
+
+func readPools(controllerVersion version.Number, source interface{}) ([]*pool, error) {
 	checker := schema.List(schema.StringMap(schema.Any()))
 	coerced, err := checker.Coerce(source, nil)
 
@@ -41,18 +123,24 @@ func readPools(controllerVersion version.Number, source interface{}) ([]*pool, e
 
 	valid := coerced.([]interface{})
 
+	readFunc, err := getPoolDeserializationFunc(controllerVersion)
+	if err != nil {
+		return nil, errors.Trace(err)
+	}
+	return readPoolList(valid, readFunc)
+}
+
+func getPoolDeserializationFunc(controllerVersion version.Number) (poolDeserializationFunc, error) {
+	var deserialisationVersion version.Number
 	for v := range poolDeserializationFuncs {
 		if v.Compare(deserialisationVersion) > 0 && v.Compare(controllerVersion) <= 0 {
 			deserialisationVersion = v
 		}
 	}
-
 	if deserialisationVersion == version.Zero {
 		return nil, errors.Errorf("no pool read func for version %s", controllerVersion)
 	}
-
-	readFunc := poolDeserializationFuncs[deserialisationVersion]
-	return readPoolList(valid, readFunc)
+	return poolDeserializationFuncs[deserialisationVersion], nil
 }
 
 // readPoolList expects the values of the sourceList to be string maps.
@@ -81,12 +169,16 @@ var poolDeserializationFuncs = map[version.Number]poolDeserializationFunc{
 
 func pool_2_0(source map[string]interface{}) (*pool, error) {
 	fields := schema.Fields{
+		"id":           schema.ForceInt(),
 		"name":         schema.String(),
 		"description":  schema.String(),
 		"resource_uri": schema.String(),
 	}
-
-	checker := schema.FieldMap(fields, nil) // no defaults
+	// The ID isn't included in the pools of older MAAS versions.
+	defaults := schema.Defaults{
+		"id": 0,
+	}
+	checker := schema.FieldMap(fields, defaults)
 
 	coerced, err := checker.Coerce(source, nil)
 	if err != nil {
@@ -97,6 +189,7 @@ func pool_2_0(source map[string]interface{}) (*pool, error) {
 	// contains fields of the right type.
 
 	result := &pool{
+		id:          valid["id"].(int),
 		name:        valid["name"].(string),
 		description: valid["description"].(string),
 		resourceURI: valid["resource_uri"].(string),
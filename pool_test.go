@@ -4,12 +4,18 @@
 package gomaasapi
 
 import (
+	"net/http"
+
+	"github.com/juju/errors"
+	"github.com/juju/testing"
 	jc "github.com/juju/testing/checkers"
 	"github.com/juju/version"
 	gc "gopkg.in/check.v1"
 )
 
-type poolSuite struct{}
+type poolSuite struct {
+	testing.LoggingCleanupSuite
+}
 
 var _ = gc.Suite(&poolSuite{})
 
@@ -44,6 +50,88 @@ func (*poolSuite) TestHighVersion(c *gc.C) {
 	c.Assert(pools, gc.HasLen, 2)
 }
 
+func (s *poolSuite) getServerAndPool(c *gc.C) (*SimpleTestServer, Pool) {
+	server, controller := createTestServerController(c, s)
+	server.AddGetResponse("/api/2.0/pools/", http.StatusOK, poolResponse)
+	pools, err := controller.Pools()
+	c.Assert(err, jc.ErrorIsNil)
+	c.Assert(pools, gc.HasLen, 2)
+	return server, pools[1]
+}
+
+func (s *poolSuite) TestUpdate(c *gc.C) {
+	server, pool := s.getServerAndPool(c)
+	server.AddPutResponse("/MAAS/api/2.0/pools/swimming_is_fun/", http.StatusOK, `
+{
+    "id": 1,
+    "description": "team blue",
+    "resource_uri": "/MAAS/api/2.0/pools/blue/",
+    "name": "blue"
+}`)
+	err := pool.Update(UpdatePoolArgs{Name: "blue", Description: "team blue"})
+	c.Assert(err, jc.ErrorIsNil)
+	c.Check(pool.ID(), gc.Equals, 1)
+	c.Check(pool.Name(), gc.Equals, "blue")
+	c.Check(pool.Description(), gc.Equals, "team blue")
+
+	form := server.LastRequest().PostForm
+	c.Check(form.Get("name"), gc.Equals, "blue")
+	c.Check(form.Get("description"), gc.Equals, "team blue")
+}
+
+func (s *poolSuite) TestUpdateBadRequest(c *gc.C) {
+	server, pool := s.getServerAndPool(c)
+	server.AddPutResponse("/MAAS/api/2.0/pools/swimming_is_fun/", http.StatusBadRequest, "name taken")
+	err := pool.Update(UpdatePoolArgs{Name: "default"})
+	c.Assert(err, jc.Satisfies, IsBadRequestError)
+}
+
+func (s *poolSuite) TestDelete(c *gc.C) {
+	server, pool := s.getServerAndPool(c)
+	server.AddDeleteResponse("/MAAS/api/2.0/pools/swimming_is_fun/", http.StatusNoContent, "")
+	err := pool.Delete()
+	c.Assert(err, jc.ErrorIsNil)
+}
+
+func (s *poolSuite) TestDeleteDefault(c *gc.C) {
+	server, pool := s.getServerAndPool(c)
+	server.AddDeleteResponse("/MAAS/api/2.0/pools/swimming_is_fun/", http.StatusBadRequest, "The default resource pool cannot be deleted.")
+	err := pool.Delete()
+	c.Assert(err, jc.Satisfies, IsBadRequestError)
+}
+
+func (s *poolSuite) TestCreatePool(c *gc.C) {
+	server, controller := createTestServerController(c, s)
+	server.AddPostResponse("/api/2.0/pools/?op=", http.StatusOK, `
+{
+    "id": 2,
+    "description": "team red",
+    "resource_uri": "/MAAS/api/2.0/resourcepool/2/",
+    "name": "red"
+}`)
+	pool, err := controller.CreatePool(CreatePoolArgs{Name: "red", Description: "team red"})
+	c.Assert(err, jc.ErrorIsNil)
+	c.Check(pool.ID(), gc.Equals, 2)
+	c.Check(pool.Name(), gc.Equals, "red")
+
+	form := server.LastRequest().PostForm
+	c.Check(form.Get("name"), gc.Equals, "red")
+	c.Check(form.Get("description"), gc.Equals, "team red")
+}
+
+func (s *poolSuite) TestCreatePoolValidates(c *gc.C) {
+	_, controller := createTestServerController(c, s)
+	_, err := controller.CreatePool(CreatePoolArgs{Description: "nameless"})
+	c.Assert(err, jc.Satisfies, errors.IsNotValid)
+}
+
+func (s *poolSuite) TestCreatePoolForbidden(c *gc.C) {
+	server, controller := createTestServerController(c, s)
+	server.AddPostResponse("/api/2.0/pools/?op=", http.StatusForbidden, "admins only")
+	_, err := controller.CreatePool(CreatePoolArgs{Name: "red"})
+	c.Assert(err, jc.Satisfies, IsPermissionError)
+}
+
 var poolResponse = `
 [
     {
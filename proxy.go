@@ -0,0 +1,170 @@
+// Copyright 2022 Canonical Ltd.
+// Licensed under the LGPLv3, see LICENCE file for details.
+
+package gomaasapi
+
+import (
+	"fmt"
+	"net"
+	"net/http"
+	"net/url"
+	"strconv"
+
+	"github.com/juju/errors"
+	"github.com/juju/schema"
+	"github.com/juju/version"
+)
+
+// ProxySettings is the HTTP proxy configuration of a MAAS region, which
+// decides the proxy that deployed machines are configured with.
+type ProxySettings struct {
+	// Enabled is false if machines aren't given a proxy.
+	Enabled bool
+	// HTTPProxy is the external proxy configured by the administrator, if
+	// any.
+	HTTPProxy string
+	// UsePeerProxy means that machines use the MAAS proxy, which forwards
+	// requests to HTTPProxy.
+	UsePeerProxy bool
+	// Port is the port the MAAS proxy listens on.
+	Port int
+	// PreferIPv4 means that machines reach the MAAS proxy on an IPv4
+	// address, even if they have IPv6 connectivity.
+	PreferIPv4 bool
+	// ProxyHost is the host machines reach the MAAS proxy on. MAAS gives
+	// machines the address of the rack controller they boot from, which
+	// isn't visible through the API, so it is set to the host of the
+	// region API. The two are the same when the region and rack
+	// controllers are colocated.
+	ProxyHost string
+}
+
+// ProxyURL returns the proxy that MAAS configures a machine with at deploy
+// time, given the subnet the machine boots from, or the empty string if the
+// machine isn't given a proxy. The subnet may be nil if it isn't known.
+func (s ProxySettings) ProxyURL(subnet Subnet) string {
+	if !s.Enabled {
+		return ""
+	}
+	if s.HTTPProxy != "" && !s.UsePeerProxy {
+		return s.HTTPProxy
+	}
+	// Machines use the MAAS proxy, which refuses requests from subnets
+	// that aren't allowed to use it.
+	if subnet != nil && !subnet.AllowProxy() {
+		return ""
+	}
+	return fmt.Sprintf("http://%s/", net.JoinHostPort(s.ProxyHost, strconv.Itoa(s.Port)))
+}
+
+// proxyConfigNames are the MAAS configuration items that make up the proxy
+// settings.
+var proxyConfigNames = []string{
+	"enable_http_proxy",
+	"http_proxy",
+	"use_peer_proxy",
+	"maas_proxy_port",
+	"prefer_v4_proxy",
+}
+
+// ProxySettings implements Controller.
+func (c *controller) ProxySettings() (ProxySettings, error) {
+	source := make(map[string]interface{})
+	for _, name := range proxyConfigNames {
+		value, err := c.getConfig(name)
+		if svrErr, ok := errors.Cause(err).(ServerError); ok && svrErr.StatusCode == http.StatusBadRequest {
+			// MAAS rejects the names of settings it doesn't have.
+			continue
+		}
+		if err != nil {
+			return ProxySettings{}, errors.Annotatef(NewUnexpectedError(err), "reading %s", name)
+		}
+		if value != nil {
+			source[name] = value
+		}
+	}
+	settings, err := readProxySettings(c.schemaVersion(), source)
+	if err != nil {
+		return ProxySettings{}, errors.Trace(err)
+	}
+	settings.ProxyHost = c.client.APIURL.Hostname()
+	return settings, nil
+}
+
+// MachineProxy implements Controller.
+func (c *controller) MachineProxy(machine Machine) (string, error) {
+	settings, err := c.ProxySettings()
+	if err != nil {
+		return "", errors.Trace(err)
+	}
+	var subnet Subnet
+	if iface := machine.BootInterface(); iface != nil {
+		for _, link := range iface.Links() {
+			if subnet = link.Subnet(); subnet != nil {
+				break
+			}
+		}
+	}
+	return settings.ProxyURL(subnet), nil
+}
+
+func (c *controller) getConfig(name string) (interface{}, error) {
+	params := make(url.Values)
+	params.Add("name", name)
+	return c._get("maas", "get_config", params)
+}
+
+func readProxySettings(controllerVersion version.Number, source map[string]interface{}) (ProxySettings, error) {
+	var deserialisationVersion version.Number
+	for v := range proxySettingsDeserializationFuncs {
+		if v.Compare(deserialisationVersion) > 0 && v.Compare(controllerVersion) <= 0 {
+			deserialisationVersion = v
+		}
+	}
+	if deserialisationVersion == version.Zero {
+		return ProxySettings{}, NewUnsupportedVersionError("no proxy settings read func for version %s", controllerVersion)
+	}
+	return proxySettingsDeserializationFuncs[deserialisationVersion](source)
+}
+
+type proxySettingsDeserializationFunc func(map[string]interface{}) (ProxySettings, error)
+
+var proxySettingsDeserializationFuncs = map[version.Number]proxySettingsDeserializationFunc{
+	twoDotOh: proxySettings_2_0,
+}
+
+func proxySettings_2_0(source map[string]interface{}) (ProxySettings, error) {
+	fields := schema.Fields{
+		"enable_http_proxy": schema.Bool(),
+		"http_proxy":        schema.String(),
+		"use_peer_proxy":    schema.Bool(),
+		"maas_proxy_port":   schema.ForceInt(),
+		"prefer_v4_proxy":   schema.Bool(),
+	}
+	// The values that aren't set are missing from the source. The port
+	// and IPv4 preference were added in MAAS 2.5, older versions use the
+	// default port.
+	defaults := schema.Defaults{
+		"enable_http_proxy": false,
+		"http_proxy":        "",
+		"use_peer_proxy":    false,
+		"maas_proxy_port":   8000,
+		"prefer_v4_proxy":   false,
+	}
+	checker := schema.FieldMap(fields, defaults)
+	coerced, err := checker.Coerce(source, nil)
+	if err != nil {
+		return ProxySettings{}, WrapWithDeserializationError(err, "proxy settings 2.0 schema check failed")
+	}
+	valid := coerced.(map[string]interface{})
+	// From here we know that the map returned from the schema coercion
+	// contains fields of the right type.
+
+	return ProxySettings{
+		Enabled:      valid["enable_http_proxy"].(bool),
+		HTTPProxy:    valid["http_proxy"].(string),
+		UsePeerProxy: valid["use_peer_proxy"].(bool),
+		Port:         valid["maas_proxy_port"].(int),
+		PreferIPv4:   valid["prefer_v4_proxy"].(bool),
+	}, nil
+}
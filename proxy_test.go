@@ -0,0 +1,138 @@
+// Copyright 2022 Canonical Ltd.
+// Licensed under the LGPLv3, see LICENCE file for details.
+
+package gomaasapi
+
+import (
+	"net/http"
+
+	"github.com/juju/testing"
+	jc "github.com/juju/testing/checkers"
+	"github.com/juju/version"
+	gc "gopkg.in/check.v1"
+)
+
+type proxySuite struct {
+	testing.LoggingCleanupSuite
+}
+
+var _ = gc.Suite(&proxySuite{})
+
+func (*proxySuite) TestProxyURL(c *gc.C) {
+	allowed := &subnet{allowProxy: true}
+	denied := &subnet{allowProxy: false}
+	for i, test := range []struct {
+		settings ProxySettings
+		subnet   Subnet
+		expected string
+	}{{
+		settings: ProxySettings{HTTPProxy: "http://squid.example.com:3128/"},
+		expected: "",
+	}, {
+		settings: ProxySettings{Enabled: true, Port: 8000, ProxyHost: "10.0.0.2"},
+		expected: "http://10.0.0.2:8000/",
+	}, {
+		settings: ProxySettings{Enabled: true, Port: 8000, ProxyHost: "10.0.0.2"},
+		subnet:   allowed,
+		expected: "http://10.0.0.2:8000/",
+	}, {
+		settings: ProxySettings{Enabled: true, Port: 8000, ProxyHost: "10.0.0.2"},
+		subnet:   denied,
+		expected: "",
+	}, {
+		settings: ProxySettings{Enabled: true, Port: 8000, ProxyHost: "2001:db8::2"},
+		expected: "http://[2001:db8::2]:8000/",
+	}, {
+		settings: ProxySettings{Enabled: true, HTTPProxy: "http://squid.example.com:3128/", Port: 8000, ProxyHost: "10.0.0.2"},
+		subnet:   denied,
+		expected: "http://squid.example.com:3128/",
+	}, {
+		settings: ProxySettings{Enabled: true, HTTPProxy: "http://squid.example.com:3128/", UsePeerProxy: true, Port: 8000, ProxyHost: "10.0.0.2"},
+		subnet:   allowed,
+		expected: "http://10.0.0.2:8000/",
+	}} {
+		c.Logf("test %d", i)
+		c.Check(test.settings.ProxyURL(test.subnet), gc.Equals, test.expected)
+	}
+}
+
+func (*proxySuite) TestReadProxySettingsDefaults(c *gc.C) {
+	settings, err := readProxySettings(twoDotOh, map[string]interface{}{})
+	c.Assert(err, jc.ErrorIsNil)
+	c.Check(settings, jc.DeepEquals, ProxySettings{Port: 8000})
+}
+
+func (*proxySuite) TestReadProxySettingsBadSchema(c *gc.C) {
+	_, err := readProxySettings(twoDotOh, map[string]interface{}{"maas_proxy_port": "lots"})
+	c.Assert(err, jc.Satisfies, IsDeserializationError)
+}
+
+func (*proxySuite) TestLowVersion(c *gc.C) {
+	_, err := readProxySettings(version.MustParse("1.9.0"), map[string]interface{}{})
+	c.Assert(err, jc.Satisfies, IsUnsupportedVersionError)
+}
+
+func (s *proxySuite) addConfig(server *SimpleTestServer, name string, status int, body string) {
+	server.AddGetResponse("/api/2.0/maas/?name="+name+"&op=get_config", status, body)
+}
+
+func (s *proxySuite) TestProxySettings(c *gc.C) {
+	server, controller := createTestServerController(c, s)
+	s.addConfig(server, "enable_http_proxy", http.StatusOK, "true")
+	s.addConfig(server, "http_proxy", http.StatusOK, `"http://squid.example.com:3128/"`)
+	s.addConfig(server, "use_peer_proxy", http.StatusOK, "true")
+	s.addConfig(server, "maas_proxy_port", http.StatusOK, "8080")
+	s.addConfig(server, "prefer_v4_proxy", http.StatusOK, "false")
+
+	settings, err := controller.ProxySettings()
+	c.Assert(err, jc.ErrorIsNil)
+	c.Check(settings, jc.DeepEquals, ProxySettings{
+		Enabled:      true,
+		HTTPProxy:    "http://squid.example.com:3128/",
+		UsePeerProxy: true,
+		Port:         8080,
+		ProxyHost:    "127.0.0.1",
+	})
+}
+
+func (s *proxySuite) TestProxySettingsOldMAAS(c *gc.C) {
+	server, controller := createTestServerController(c, s)
+	s.addConfig(server, "enable_http_proxy", http.StatusOK, "true")
+	s.addConfig(server, "http_proxy", http.StatusOK, "null")
+	s.addConfig(server, "use_peer_proxy", http.StatusOK, "false")
+	s.addConfig(server, "maas_proxy_port", http.StatusBadRequest, "unknown config name")
+	s.addConfig(server, "prefer_v4_proxy", http.StatusBadRequest, "unknown config name")
+
+	settings, err := controller.ProxySettings()
+	c.Assert(err, jc.ErrorIsNil)
+	c.Check(settings, jc.DeepEquals, ProxySettings{
+		Enabled:   true,
+		Port:      8000,
+		ProxyHost: "127.0.0.1",
+	})
+}
+
+func (s *proxySuite) TestProxySettingsError(c *gc.C) {
+	server, controller := createTestServerController(c, s)
+	s.addConfig(server, "enable_http_proxy", http.StatusInternalServerError, "boom")
+
+	_, err := controller.ProxySettings()
+	c.Assert(err, jc.Satisfies, IsUnexpectedError)
+	c.Check(err, gc.ErrorMatches, "reading enable_http_proxy: .*boom.*")
+}
+
+func (s *proxySuite) TestMachineProxy(c *gc.C) {
+	server, controller := createTestServerController(c, s)
+	server.AddGetResponse("/api/2.0/machines/", http.StatusOK, "["+machineResponse+"]")
+	machines, err := controller.Machines(MachinesArgs{})
+	c.Assert(err, jc.ErrorIsNil)
+	s.addConfig(server, "enable_http_proxy", http.StatusOK, "true")
+	s.addConfig(server, "http_proxy", http.StatusOK, `""`)
+	s.addConfig(server, "use_peer_proxy", http.StatusOK, "false")
+	s.addConfig(server, "maas_proxy_port", http.StatusOK, "8000")
+	s.addConfig(server, "prefer_v4_proxy", http.StatusOK, "false")
+
+	proxy, err := controller.MachineProxy(machines[0])
+	c.Assert(err, jc.ErrorIsNil)
+	c.Check(proxy, gc.Equals, "http://127.0.0.1:8000/")
+}
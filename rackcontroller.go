@@ -0,0 +1,454 @@
+// Copyright 2022 Canonical Ltd.
+// Licensed under the LGPLv3, see LICENCE file for details.
+
+package gomaasapi
+
+import (
+	"net/http"
+
+	"github.com/juju/errors"
+	"github.com/juju/schema"
+	"github.com/juju/version"
+)
+
+// ServiceStatus is the status of a service that MAAS runs on a controller,
+// such as "rackd", "dhcpd" or "tftp".
+type ServiceStatus struct {
+	Name string
+	// Status is one of "running", "degraded", "dead", "off" or "unknown".
+	// Services that aren't needed, such as dhcpd on a rack controller
+	// that doesn't serve DHCP, are "off".
+	Status string
+	// StatusInfo explains the status, if it isn't running.
+	StatusInfo string
+}
+
+// PowerType describes a power driver that the rack controllers support.
+type PowerType struct {
+	Name        string
+	Description string
+	// Chassis is true if the driver controls a chassis of machines, such
+	// as a virsh host or a VMware cluster, that can be probed for
+	// machines.
+	Chassis bool
+	// Queryable is true if the power state of machines can be queried.
+	Queryable bool
+	// MissingPackages are the packages that have to be installed on the
+	// rack controllers for the driver to work.
+	MissingPackages []string
+}
+
+type rackController struct {
+	controller *controller
+
+	resourceURI string
+
+	systemID     string
+	hostname     string
+	fqdn         string
+	architecture string
+	version      string
+	ipAddresses  []string
+
+	services     []ServiceStatus
+	interfaceSet []*interface_
+}
+
+// SystemID implements RackController.
+func (r *rackController) SystemID() string {
+	return r.systemID
+}
+
+// Hostname implements RackController.
+func (r *rackController) Hostname() string {
+	return r.hostname
+}
+
+// FQDN implements RackController.
+func (r *rackController) FQDN() string {
+	return r.fqdn
+}
+
+// Architecture implements RackController.
+func (r *rackController) Architecture() string {
+	return r.architecture
+}
+
+// Version implements RackController.
+func (r *rackController) Version() string {
+	return r.version
+}
+
+// IPAddresses implements RackController.
+func (r *rackController) IPAddresses() []string {
+	return r.ipAddresses
+}
+
+// Services implements RackController.
+func (r *rackController) Services() []ServiceStatus {
+	result := make([]ServiceStatus, len(r.services))
+	copy(result, r.services)
+	return result
+}
+
+// Service implements RackController.
+func (r *rackController) Service(name string) (ServiceStatus, bool) {
+	for _, service := range r.services {
+		if service.Name == name {
+			return service, true
+		}
+	}
+	return ServiceStatus{}, false
+}
+
+// InterfaceSet implements RackController.
+func (r *rackController) InterfaceSet() []Interface {
+	result := make([]Interface, len(r.interfaceSet))
+	for i, v := range r.interfaceSet {
+		v.controller = r.controller
+		result[i] = v
+	}
+	return result
+}
+
+// ImportBootImages implements RackController.
+func (r *rackController) ImportBootImages() error {
+	_, err := r.controller._postRaw(r.resourceURI, "import_boot_images", nil, nil)
+	if err != nil {
+		if svrErr, ok := errors.Cause(err).(ServerError); ok {
+			switch svrErr.StatusCode {
+			case http.StatusNotFound:
+				return wrapError(err, NewNoMatchError(svrErr.BodyMessage))
+			case http.StatusForbidden:
+				return wrapError(err, NewPermissionError(svrErr.BodyMessage))
+			case http.StatusServiceUnavailable:
+				return wrapError(err, NewCannotCompleteError(svrErr.BodyMessage))
+			}
+		}
+		return NewUnexpectedError(err)
+	}
+	return nil
+}
+
+// BootImagesStatus implements RackController.
+func (r *rackController) BootImagesStatus() (string, error) {
+	source, err := r.controller.getOp(r.resourceURI, "list_boot_images")
+	if err != nil {
+		if svrErr, ok := errors.Cause(err).(ServerError); ok {
+			switch svrErr.StatusCode {
+			case http.StatusNotFound:
+				return "", wrapError(err, NewNoMatchError(svrErr.BodyMessage))
+			case http.StatusForbidden:
+				return "", wrapError(err, NewPermissionError(svrErr.BodyMessage))
+			}
+		}
+		return "", NewUnexpectedError(err)
+	}
+	checker := schema.FieldMap(schema.Fields{"status": schema.String()}, nil)
+	coerced, err := checker.Coerce(source, nil)
+	if err != nil {
+		return "", WrapWithDeserializationError(err, "boot images schema check failed")
+	}
+	return coerced.(map[string]interface{})["status"].(string), nil
+}
+
+// RackControllersArgs is an argument struct for selecting rack controllers.
+// Only the rack controllers that match the specified criteria are returned.
+type RackControllersArgs struct {
+	Hostnames []string
+	SystemIDs []string
+	Domain    string
+	Zone      string
+}
+
+// RackControllers implements Controller.
+func (c *controller) RackControllers(args RackControllersArgs) ([]RackController, error) {
+	racks, err := c.rackControllers(args)
+	if err != nil {
+		return nil, errors.Trace(err)
+	}
+	var result []RackController
+	for _, r := range racks {
+		result = append(result, r)
+	}
+	return result, nil
+}
+
+func (c *controller) rackControllers(args RackControllersArgs) ([]*rackController, error) {
+	params := NewURLParams()
+	params.MaybeAddMany("hostname", args.Hostnames)
+	params.MaybeAddMany("id", args.SystemIDs)
+	params.MaybeAdd("domain", args.Domain)
+	params.MaybeAdd("zone", args.Zone)
+	source, err := c.getList("rackcontrollers", params.Values, 0)
+	if err != nil {
+		return nil, NewUnexpectedError(err)
+	}
+	racks, err := readRackControllers(c.schemaVersion(), source)
+	if err != nil {
+		return nil, errors.Trace(err)
+	}
+	for _, r := range racks {
+		r.controller = c
+	}
+	return racks, nil
+}
+
+// RackController implements Controller.
+func (c *controller) RackController(systemID string) (RackController, error) {
+	source, err := c.get("rackcontrollers/" + systemID)
+	if err != nil {
+		if svrErr, ok := errors.Cause(err).(ServerError); ok {
+			switch svrErr.StatusCode {
+			case http.StatusNotFound:
+				return nil, wrapError(err, NewNoMatchError(svrErr.BodyMessage))
+			case http.StatusForbidden:
+				return nil, wrapError(err, NewPermissionError(svrErr.BodyMessage))
+			}
+		}
+		return nil, NewUnexpectedError(err)
+	}
+	rack, err := readRackController(c.schemaVersion(), source)
+	if err != nil {
+		return nil, errors.Trace(err)
+	}
+	rack.controller = c
+	return rack, nil
+}
+
+// PowerTypes implements Controller.
+func (c *controller) PowerTypes() ([]PowerType, error) {
+	source, err := c.getOp("rackcontrollers", "describe_power_types")
+	if err != nil {
+		if svrErr, ok := errors.Cause(err).(ServerError); ok {
+			switch svrErr.StatusCode {
+			case http.StatusForbidden:
+				return nil, wrapError(err, NewPermissionError(svrErr.BodyMessage))
+			case http.StatusServiceUnavailable:
+				// MAAS can't describe the power types until a rack
+				// controller is connected.
+				return nil, wrapError(err, NewCannotCompleteError(svrErr.BodyMessage))
+			}
+		}
+		return nil, NewUnexpectedError(err)
+	}
+	powerTypes, err := readPowerTypes(c.schemaVersion(), source)
+	if err != nil {
+		return nil, errors.Trace(err)
+	}
+	return powerTypes, nil
+}
+
+func readRackController(controllerVersion version.Number, source interface{}) (*rackController, error) {
+	readFunc, err := getRackControllerDeserializationFunc(controllerVersion)
+	if err != nil {
+		return nil, errors.Trace(err)
+	}
+
+	checker := schema.StringMap(schema.Any())
+	coerced, err := checker.Coerce(source, nil)
+	if err != nil {
+		return nil, WrapWithDeserializationError(err, "rack controller base schema check failed")
+	}
+	valid := coerced.(map[string]interface{})
+	return readFunc(valid)
+}
+
+func readRackControllers(controllerVersion version.Number, source interface{}) ([]*rackController, error) {
+	readFunc, err := getRackControllerDeserializationFunc(controllerVersion)
+	if err != nil {
+		return nil, errors.Trace(err)
+	}
+
+	checker := schema.List(schema.StringMap(schema.Any()))
+	coerced, err := checker.Coerce(source, nil)
+	if err != nil {
+		return nil, WrapWithDeserializationError(err, "rack controller base schema check failed")
+	}
+	valid := coerced.([]interface{})
+	return readRackControllerList(valid, readFunc)
+}
+
+func getRackControllerDeserializationFunc(controllerVersion version.Number) (rackControllerDeserializationFunc, error) {
+	var deserialisationVersion version.Number
+	for v := range rackControllerDeserializationFuncs {
+		if v.Compare(deserialisationVersion) > 0 && v.Compare(controllerVersion) <= 0 {
+			deserialisationVersion = v
+		}
+	}
+	if deserialisationVersion == version.Zero {
+		return nil, NewUnsupportedVersionError("no rack controller read func for version %s", controllerVersion)
+	}
+	return rackControllerDeserializationFuncs[deserialisationVersion], nil
+}
+
+// readRackControllerList expects the values of the sourceList to be string
+// maps.
+func readRackControllerList(sourceList []interface{}, readFunc rackControllerDeserializationFunc) ([]*rackController, error) {
+	result := make([]*rackController, 0, len(sourceList))
+	for i, value := range sourceList {
+		source, ok := value.(map[string]interface{})
+		if !ok {
+			return nil, NewDeserializationError("unexpected value for rack controller %d, %T", i, value)
+		}
+		rack, err := readFunc(source)
+		if err != nil {
+			return nil, errors.Annotatef(err, "rack controller %d", i)
+		}
+		result = append(result, rack)
+	}
+	return result, nil
+}
+
+type rackControllerDeserializationFunc func(map[string]interface{}) (*rackController, error)
+
+var rackControllerDeserializationFuncs = map[version.Number]rackControllerDeserializationFunc{
+	twoDotOh: rackController_2_0,
+}
+
+func rackController_2_0(source map[string]interface{}) (*rackController, error) {
+	fields := schema.Fields{
+		"resource_uri": schema.String(),
+
+		"system_id":    schema.String(),
+		"hostname":     schema.String(),
+		"fqdn":         schema.String(),
+		"architecture": schema.OneOf(schema.Nil(""), schema.String()),
+		// Older rack controllers don't report their version.
+		"version":      schema.OneOf(schema.Nil(""), schema.String()),
+		"ip_addresses": schema.List(schema.String()),
+
+		"service_set":   serviceSetSchema,
+		"interface_set": schema.List(schema.StringMap(schema.Any())),
+	}
+	defaults := schema.Defaults{
+		"fqdn":          "",
+		"architecture":  nil,
+		"version":       nil,
+		"ip_addresses":  []interface{}{},
+		"service_set":   []interface{}{},
+		"interface_set": []interface{}{},
+	}
+	checker := schema.FieldMap(fields, defaults)
+	coerced, err := checker.Coerce(source, nil)
+	if err != nil {
+		return nil, WrapWithDeserializationError(err, "rack controller 2.0 schema check failed")
+	}
+	valid := coerced.(map[string]interface{})
+	// From here we know that the map returned from the schema coercion
+	// contains fields of the right type.
+
+	interfaceSet, err := readInterfaceList(valid["interface_set"].([]interface{}), interface_2_0)
+	if err != nil {
+		return nil, errors.Trace(err)
+	}
+	architecture, _ := valid["architecture"].(string)
+	rackVersion, _ := valid["version"].(string)
+	result := &rackController{
+		resourceURI: valid["resource_uri"].(string),
+
+		systemID:     valid["system_id"].(string),
+		hostname:     valid["hostname"].(string),
+		fqdn:         valid["fqdn"].(string),
+		architecture: architecture,
+		version:      rackVersion,
+		ipAddresses:  convertToStringSlice(valid["ip_addresses"]),
+
+		services:     readServiceSet(valid["service_set"]),
+		interfaceSet: interfaceSet,
+	}
+	return result, nil
+}
+
+// serviceSetSchema checks the service_set of a controller.
+var serviceSetSchema = schema.List(schema.FieldMap(
+	schema.Fields{
+		"name":        schema.String(),
+		"status":      schema.String(),
+		"status_info": schema.OneOf(schema.Nil(""), schema.String()),
+	},
+	schema.Defaults{"status_info": ""},
+))
+
+// readServiceSet expects a service_set checked by serviceSetSchema.
+func readServiceSet(source interface{}) []ServiceStatus {
+	var result []ServiceStatus
+	for _, value := range source.([]interface{}) {
+		service := value.(map[string]interface{})
+		statusInfo, _ := service["status_info"].(string)
+		result = append(result, ServiceStatus{
+			Name:       service["name"].(string),
+			Status:     service["status"].(string),
+			StatusInfo: statusInfo,
+		})
+	}
+	return result
+}
+
+func readPowerTypes(controllerVersion version.Number, source interface{}) ([]PowerType, error) {
+	var deserialisationVersion version.Number
+	for v := range powerTypeDeserializationFuncs {
+		if v.Compare(deserialisationVersion) > 0 && v.Compare(controllerVersion) <= 0 {
+			deserialisationVersion = v
+		}
+	}
+	if deserialisationVersion == version.Zero {
+		return nil, NewUnsupportedVersionError("no power type read func for version %s", controllerVersion)
+	}
+	readFunc := powerTypeDeserializationFuncs[deserialisationVersion]
+
+	checker := schema.List(schema.StringMap(schema.Any()))
+	coerced, err := checker.Coerce(source, nil)
+	if err != nil {
+		return nil, WrapWithDeserializationError(err, "power type base schema check failed")
+	}
+	valid := coerced.([]interface{})
+	result := make([]PowerType, 0, len(valid))
+	for i, value := range valid {
+		powerType, err := readFunc(value.(map[string]interface{}))
+		if err != nil {
+			return nil, errors.Annotatef(err, "power type %d", i)
+		}
+		result = append(result, powerType)
+	}
+	return result, nil
+}
+
+type powerTypeDeserializationFunc func(map[string]interface{}) (PowerType, error)
+
+var powerTypeDeserializationFuncs = map[version.Number]powerTypeDeserializationFunc{
+	twoDotOh: powerType_2_0,
+}
+
+func powerType_2_0(source map[string]interface{}) (PowerType, error) {
+	fields := schema.Fields{
+		"name":             schema.String(),
+		"description":      schema.String(),
+		"chassis":          schema.Bool(),
+		"queryable":        schema.Bool(),
+		"missing_packages": schema.List(schema.String()),
+	}
+	defaults := schema.Defaults{
+		"description": "",
+		"chassis":     false,
+		// Older versions of MAAS query all the power drivers.
+		"queryable":        true,
+		"missing_packages": []interface{}{},
+	}
+	checker := schema.FieldMap(fields, defaults)
+	coerced, err := checker.Coerce(source, nil)
+	if err != nil {
+		return PowerType{}, WrapWithDeserializationError(err, "power type 2.0 schema check failed")
+	}
+	valid := coerced.(map[string]interface{})
+	// From here we know that the map returned from the schema coercion
+	// contains fields of the right type.
+
+	return PowerType{
+		Name:            valid["name"].(string),
+		Description:     valid["description"].(string),
+		Chassis:         valid["chassis"].(bool),
+		Queryable:       valid["queryable"].(bool),
+		MissingPackages: convertToStringSlice(valid["missing_packages"]),
+	}, nil
+}
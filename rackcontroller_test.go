@@ -0,0 +1,233 @@
+// Copyright 2022 Canonical Ltd.
+// Licensed under the LGPLv3, see LICENCE file for details.
+
+package gomaasapi
+
+import (
+	"net/http"
+
+	"github.com/juju/testing"
+	jc "github.com/juju/testing/checkers"
+	"github.com/juju/version"
+	gc "gopkg.in/check.v1"
+)
+
+type rackControllerSuite struct {
+	testing.LoggingCleanupSuite
+}
+
+var _ = gc.Suite(&rackControllerSuite{})
+
+func (*rackControllerSuite) TestReadRackControllersBadSchema(c *gc.C) {
+	_, err := readRackControllers(twoDotOh, "wat?")
+	c.Check(err, jc.Satisfies, IsDeserializationError)
+	c.Assert(err.Error(), gc.Equals, `rack controller base schema check failed: expected list, got string("wat?")`)
+}
+
+func (*rackControllerSuite) TestReadRackControllers(c *gc.C) {
+	racks, err := readRackControllers(twoDotOh, parseJSON(c, rackControllersResponse))
+	c.Assert(err, jc.ErrorIsNil)
+	c.Assert(racks, gc.HasLen, 1)
+
+	rack := racks[0]
+	c.Check(rack.SystemID(), gc.Equals, "4y3h7n")
+	c.Check(rack.Hostname(), gc.Equals, "rack-1")
+	c.Check(rack.FQDN(), gc.Equals, "rack-1.maas")
+	c.Check(rack.Architecture(), gc.Equals, "amd64/generic")
+	c.Check(rack.Version(), gc.Equals, "3.2.6-12016-g.19812b4da")
+	c.Check(rack.IPAddresses(), jc.DeepEquals, []string{"192.168.100.2"})
+	c.Check(rack.Services(), jc.DeepEquals, []ServiceStatus{
+		{Name: "rackd", Status: "running"},
+		{Name: "dhcpd", Status: "dead", StatusInfo: "dhcpd failed to start"},
+		{Name: "tftp", Status: "running"},
+	})
+	c.Check(rack.InterfaceSet(), gc.HasLen, 1)
+	c.Check(rack.InterfaceSet()[0].MACAddress(), gc.Equals, "52:54:00:c9:6a:45")
+}
+
+func (*rackControllerSuite) TestReadRackControllersVersionsOnly(c *gc.C) {
+	racks, err := readRackControllers(twoDotOh, parseJSON(c, rackVersionsResponse))
+	c.Assert(err, jc.ErrorIsNil)
+	c.Assert(racks, gc.HasLen, 3)
+	c.Check(racks[0].Version(), gc.Equals, "2.5.0-7442-gdf67a1b54-0ubuntu1")
+	c.Check(racks[2].Version(), gc.Equals, "")
+	c.Check(racks[2].Services(), gc.HasLen, 0)
+	c.Check(racks[2].InterfaceSet(), gc.HasLen, 0)
+}
+
+func (*rackControllerSuite) TestLowVersion(c *gc.C) {
+	_, err := readRackControllers(version.MustParse("1.9.0"), parseJSON(c, rackControllersResponse))
+	c.Assert(err, jc.Satisfies, IsUnsupportedVersionError)
+}
+
+func (*rackControllerSuite) TestHighVersion(c *gc.C) {
+	racks, err := readRackControllers(version.MustParse("2.1.9"), parseJSON(c, rackControllersResponse))
+	c.Assert(err, jc.ErrorIsNil)
+	c.Assert(racks, gc.HasLen, 1)
+}
+
+func (*rackControllerSuite) TestService(c *gc.C) {
+	rack := &rackController{services: []ServiceStatus{{Name: "tftp", Status: "running"}}}
+	service, ok := rack.Service("tftp")
+	c.Check(ok, jc.IsTrue)
+	c.Check(service.Status, gc.Equals, "running")
+	_, ok = rack.Service("dhcpd")
+	c.Check(ok, jc.IsFalse)
+}
+
+func (s *rackControllerSuite) TestRackControllers(c *gc.C) {
+	server, controller := createTestServerController(c, s)
+	server.AddGetResponse("/api/2.0/rackcontrollers/?hostname=rack-1&zone=default", http.StatusOK, rackControllersResponse)
+	racks, err := controller.RackControllers(RackControllersArgs{
+		Hostnames: []string{"rack-1"},
+		Zone:      "default",
+	})
+	c.Assert(err, jc.ErrorIsNil)
+	c.Assert(racks, gc.HasLen, 1)
+	c.Check(racks[0].SystemID(), gc.Equals, "4y3h7n")
+}
+
+func (s *rackControllerSuite) TestRackControllersForbidden(c *gc.C) {
+	server, controller := createTestServerController(c, s)
+	server.AddGetResponse("/api/2.0/rackcontrollers/", http.StatusForbidden, "admins only")
+	_, err := controller.RackControllers(RackControllersArgs{})
+	c.Assert(err, jc.Satisfies, IsUnexpectedError)
+}
+
+func (s *rackControllerSuite) TestRackController(c *gc.C) {
+	server, controller := createTestServerController(c, s)
+	server.AddGetResponse("/api/2.0/rackcontrollers/4y3h7n/", http.StatusOK, rackControllerResponse)
+	rack, err := controller.RackController("4y3h7n")
+	c.Assert(err, jc.ErrorIsNil)
+	c.Check(rack.Hostname(), gc.Equals, "rack-1")
+}
+
+func (s *rackControllerSuite) TestRackControllerMissing(c *gc.C) {
+	_, controller := createTestServerController(c, s)
+	_, err := controller.RackController("4y3h7n")
+	c.Assert(err, jc.Satisfies, IsNoMatchError)
+}
+
+func (s *rackControllerSuite) getServerAndRack(c *gc.C) (*SimpleTestServer, RackController) {
+	server, controller := createTestServerController(c, s)
+	server.AddGetResponse("/api/2.0/rackcontrollers/4y3h7n/", http.StatusOK, rackControllerResponse)
+	rack, err := controller.RackController("4y3h7n")
+	c.Assert(err, jc.ErrorIsNil)
+	server.ResetRequests()
+	return server, rack
+}
+
+func (s *rackControllerSuite) TestImportBootImages(c *gc.C) {
+	server, rack := s.getServerAndRack(c)
+	server.AddPostResponse("/MAAS/api/2.0/rackcontrollers/4y3h7n/?op=import_boot_images", http.StatusOK, "Import of boot images started on rack-1")
+	err := rack.ImportBootImages()
+	c.Assert(err, jc.ErrorIsNil)
+	c.Check(server.LastRequest().Method, gc.Equals, "POST")
+}
+
+func (s *rackControllerSuite) TestImportBootImagesDisconnected(c *gc.C) {
+	server, rack := s.getServerAndRack(c)
+	server.AddPostResponse("/MAAS/api/2.0/rackcontrollers/4y3h7n/?op=import_boot_images", http.StatusServiceUnavailable, "Unable to connect to rack controller")
+	err := rack.ImportBootImages()
+	c.Assert(err, jc.Satisfies, IsCannotCompleteError)
+}
+
+func (s *rackControllerSuite) TestBootImagesStatus(c *gc.C) {
+	server, rack := s.getServerAndRack(c)
+	server.AddGetResponse("/MAAS/api/2.0/rackcontrollers/4y3h7n/?op=list_boot_images", http.StatusOK, `{
+		"connected": true,
+		"status": "syncing",
+		"images": []
+	}`)
+	status, err := rack.BootImagesStatus()
+	c.Assert(err, jc.ErrorIsNil)
+	c.Check(status, gc.Equals, "syncing")
+}
+
+func (s *rackControllerSuite) TestBootImagesStatusForbidden(c *gc.C) {
+	server, rack := s.getServerAndRack(c)
+	server.AddGetResponse("/MAAS/api/2.0/rackcontrollers/4y3h7n/?op=list_boot_images", http.StatusForbidden, "admins only")
+	_, err := rack.BootImagesStatus()
+	c.Assert(err, jc.Satisfies, IsPermissionError)
+}
+
+func (s *rackControllerSuite) TestPowerTypes(c *gc.C) {
+	server, controller := createTestServerController(c, s)
+	server.AddGetResponse("/api/2.0/rackcontrollers/?op=describe_power_types", http.StatusOK, powerTypesResponse)
+	powerTypes, err := controller.PowerTypes()
+	c.Assert(err, jc.ErrorIsNil)
+	c.Check(powerTypes, jc.DeepEquals, []PowerType{{
+		Name:            "ipmi",
+		Description:     "IPMI",
+		Queryable:       true,
+		MissingPackages: []string{"ipmitool"},
+	}, {
+		Name:            "virsh",
+		Description:     "Virsh (virtual systems)",
+		Chassis:         true,
+		Queryable:       true,
+		MissingPackages: []string{},
+	}, {
+		Name:            "manual",
+		Description:     "Manual",
+		Queryable:       false,
+		MissingPackages: []string{},
+	}})
+}
+
+func (s *rackControllerSuite) TestPowerTypesNoRacks(c *gc.C) {
+	server, controller := createTestServerController(c, s)
+	server.AddGetResponse("/api/2.0/rackcontrollers/?op=describe_power_types", http.StatusServiceUnavailable, "no rack controllers connected")
+	_, err := controller.PowerTypes()
+	c.Assert(err, jc.Satisfies, IsCannotCompleteError)
+}
+
+const (
+	rackControllerResponse = `
+{
+    "system_id": "4y3h7n",
+    "hostname": "rack-1",
+    "fqdn": "rack-1.maas",
+    "architecture": "amd64/generic",
+    "version": "3.2.6-12016-g.19812b4da",
+    "ip_addresses": ["192.168.100.2"],
+    "node_type_name": "Rack controller",
+    "service_set": [
+        {"name": "rackd", "status": "running", "status_info": ""},
+        {"name": "dhcpd", "status": "dead", "status_info": "dhcpd failed to start"},
+        {"name": "tftp", "status": "running", "status_info": null}
+    ],
+    "interface_set": [` + interfaceResponse + `],
+    "resource_uri": "/MAAS/api/2.0/rackcontrollers/4y3h7n/"
+}
+`
+	rackControllersResponse = "[" + rackControllerResponse + "]"
+
+	powerTypesResponse = `
+[
+    {
+        "name": "ipmi",
+        "description": "IPMI",
+        "driver_type": "power",
+        "chassis": false,
+        "can_probe": false,
+        "queryable": true,
+        "missing_packages": ["ipmitool"],
+        "fields": []
+    },
+    {
+        "name": "virsh",
+        "description": "Virsh (virtual systems)",
+        "chassis": true,
+        "can_probe": true,
+        "missing_packages": [],
+        "fields": []
+    },
+    {
+        "name": "manual",
+        "description": "Manual",
+        "queryable": false
+    }
+]
+`
+)
@@ -0,0 +1,291 @@
+// Copyright 2022 Canonical Ltd.
+// Licensed under the LGPLv3, see LICENCE file for details.
+
+package gomaasapi
+
+import (
+	"fmt"
+
+	"github.com/juju/errors"
+	"github.com/juju/schema"
+	"github.com/juju/version"
+)
+
+// RAIDLevel is the level of a software RAID array.
+type RAIDLevel string
+
+// The RAID levels supported by MAAS.
+const (
+	RAID0  RAIDLevel = "raid-0"
+	RAID1  RAIDLevel = "raid-1"
+	RAID5  RAIDLevel = "raid-5"
+	RAID6  RAIDLevel = "raid-6"
+	RAID10 RAIDLevel = "raid-10"
+)
+
+type raid struct {
+	controller *controller
+
+	resourceURI string
+
+	id    int
+	name  string
+	uuid  string
+	level string
+	size  uint64
+
+	devices       []StorageDevice
+	spareDevices  []StorageDevice
+	virtualDevice *blockdevice
+}
+
+func (r *raid) updateFrom(other *raid) {
+	r.resourceURI = other.resourceURI
+	r.id = other.id
+	r.name = other.name
+	r.uuid = other.uuid
+	r.level = other.level
+	r.size = other.size
+	r.devices = other.devices
+	r.spareDevices = other.spareDevices
+	r.virtualDevice = other.virtualDevice
+}
+
+// ID implements RAID.
+func (r *raid) ID() int {
+	return r.id
+}
+
+// Name implements RAID.
+func (r *raid) Name() string {
+	return r.name
+}
+
+// UUID implements RAID.
+func (r *raid) UUID() string {
+	return r.uuid
+}
+
+// Level implements RAID.
+func (r *raid) Level() RAIDLevel {
+	return RAIDLevel(r.level)
+}
+
+// Size implements RAID.
+func (r *raid) Size() uint64 {
+	return r.size
+}
+
+// Devices implements RAID.
+func (r *raid) Devices() []StorageDevice {
+	setStorageDeviceController(r.devices, r.controller)
+	return r.devices
+}
+
+// SpareDevices implements RAID.
+func (r *raid) SpareDevices() []StorageDevice {
+	setStorageDeviceController(r.spareDevices, r.controller)
+	return r.spareDevices
+}
+
+// VirtualDevice implements RAID.
+func (r *raid) VirtualDevice() BlockDevice {
+	if r.virtualDevice == nil {
+		return nil
+	}
+	r.virtualDevice.controller = r.controller
+	return r.virtualDevice
+}
+
+// UpdateRAIDArgs is an argument struct for RAID.Update. Only the fields that
+// are set are changed.
+type UpdateRAIDArgs struct {
+	Name string
+	UUID string
+
+	AddDevices            []BlockDevice
+	RemoveDevices         []BlockDevice
+	AddPartitions         []Partition
+	RemovePartitions      []Partition
+	AddSpareDevices       []BlockDevice
+	RemoveSpareDevices    []BlockDevice
+	AddSparePartitions    []Partition
+	RemoveSparePartitions []Partition
+}
+
+// Update implements RAID.
+func (r *raid) Update(args UpdateRAIDArgs) error {
+	params := NewURLParams()
+	params.MaybeAdd("name", args.Name)
+	params.MaybeAdd("uuid", args.UUID)
+	params.MaybeAddMany("add_block_devices", blockDeviceIDs(args.AddDevices))
+	params.MaybeAddMany("remove_block_devices", blockDeviceIDs(args.RemoveDevices))
+	params.MaybeAddMany("add_partitions", partitionIDs(args.AddPartitions))
+	params.MaybeAddMany("remove_partitions", partitionIDs(args.RemovePartitions))
+	params.MaybeAddMany("add_spare_devices", blockDeviceIDs(args.AddSpareDevices))
+	params.MaybeAddMany("remove_spare_devices", blockDeviceIDs(args.RemoveSpareDevices))
+	params.MaybeAddMany("add_spare_partitions", partitionIDs(args.AddSparePartitions))
+	params.MaybeAddMany("remove_spare_partitions", partitionIDs(args.RemoveSparePartitions))
+	source, err := r.controller.put(r.resourceURI, params.Values)
+	if err != nil {
+		return errors.Trace(storageDeviceError(err))
+	}
+
+	response, err := readRAID(r.controller.schemaVersion(), source)
+	if err != nil {
+		return errors.Trace(err)
+	}
+	r.updateFrom(response)
+	return nil
+}
+
+// Delete implements RAID.
+func (r *raid) Delete() error {
+	err := r.controller.delete(r.resourceURI)
+	if err != nil {
+		return errors.Trace(storageDeviceError(err))
+	}
+	return nil
+}
+
+func blockDeviceIDs(devices []BlockDevice) []string {
+	var result []string
+	for _, device := range devices {
+		result = append(result, fmt.Sprint(device.ID()))
+	}
+	return result
+}
+
+func partitionIDs(partitions []Partition) []string {
+	var result []string
+	for _, partition := range partitions {
+		result = append(result, fmt.Sprint(partition.ID()))
+	}
+	return result
+}
+
+func readRAID(controllerVersion version.Number, source interface{}) (*raid, error) {
+	readFunc, err := getRAIDDeserializationFunc(controllerVersion)
+	if err != nil {
+		return nil, errors.Trace(err)
+	}
+
+	checker := schema.StringMap(schema.Any())
+	coerced, err := checker.Coerce(source, nil)
+	if err != nil {
+		return nil, WrapWithDeserializationError(err, "raid base schema check failed")
+	}
+	valid := coerced.(map[string]interface{})
+	return readFunc(valid)
+}
+
+func readRAIDs(controllerVersion version.Number, source interface{}) ([]*raid, error) {
+	readFunc, err := getRAIDDeserializationFunc(controllerVersion)
+	if err != nil {
+		return nil, errors.Trace(err)
+	}
+
+	checker := schema.List(schema.StringMap(schema.Any()))
+	coerced, err := checker.Coerce(source, nil)
+	if err != nil {
+		return nil, WrapWithDeserializationError(err, "raid base schema check failed")
+	}
+	valid := coerced.([]interface{})
+	return readRAIDList(valid, readFunc)
+}
+
+func getRAIDDeserializationFunc(controllerVersion version.Number) (raidDeserializationFunc, error) {
+	var deserialisationVersion version.Number
+	for v := range raidDeserializationFuncs {
+		if v.Compare(deserialisationVersion) > 0 && v.Compare(controllerVersion) <= 0 {
+			deserialisationVersion = v
+		}
+	}
+	if deserialisationVersion == version.Zero {
+		return nil, NewUnsupportedVersionError("no raid read func for version %s", controllerVersion)
+	}
+	return raidDeserializationFuncs[deserialisationVersion], nil
+}
+
+// readRAIDList expects the values of the sourceList to be string maps.
+func readRAIDList(sourceList []interface{}, readFunc raidDeserializationFunc) ([]*raid, error) {
+	result := make([]*raid, 0, len(sourceList))
+	for i, value := range sourceList {
+		source, ok := value.(map[string]interface{})
+		if !ok {
+			return nil, NewDeserializationError("unexpected value for raid %d, %T", i, value)
+		}
+		raid, err := readFunc(source)
+		if err != nil {
+			return nil, errors.Annotatef(err, "raid %d", i)
+		}
+		result = append(result, raid)
+	}
+	return result, nil
+}
+
+type raidDeserializationFunc func(map[string]interface{}) (*raid, error)
+
+var raidDeserializationFuncs = map[version.Number]raidDeserializationFunc{
+	twoDotOh: raid_2_0,
+}
+
+func raid_2_0(source map[string]interface{}) (*raid, error) {
+	fields := schema.Fields{
+		"resource_uri": schema.String(),
+
+		"id":    schema.ForceInt(),
+		"name":  schema.String(),
+		"uuid":  schema.OneOf(schema.Nil(""), schema.String()),
+		"level": schema.String(),
+		"size":  schema.ForceUint(),
+
+		"devices":        schema.List(schema.StringMap(schema.Any())),
+		"spare_devices":  schema.List(schema.StringMap(schema.Any())),
+		"virtual_device": schema.OneOf(schema.Nil(""), schema.StringMap(schema.Any())),
+	}
+	defaults := schema.Defaults{
+		"uuid":           "",
+		"spare_devices":  []interface{}{},
+		"virtual_device": nil,
+	}
+	checker := schema.FieldMap(fields, defaults)
+	coerced, err := checker.Coerce(source, nil)
+	if err != nil {
+		return nil, WrapWithDeserializationError(err, "raid 2.0 schema check failed")
+	}
+	valid := coerced.(map[string]interface{})
+	// From here we know that the map returned from the schema coercion
+	// contains fields of the right type.
+
+	devices, err := readStorageDeviceList(valid["devices"].([]interface{}))
+	if err != nil {
+		return nil, errors.Annotate(err, "devices")
+	}
+	spareDevices, err := readStorageDeviceList(valid["spare_devices"].([]interface{}))
+	if err != nil {
+		return nil, errors.Annotate(err, "spare devices")
+	}
+	var virtualDevice *blockdevice
+	if deviceSource, ok := valid["virtual_device"].(map[string]interface{}); ok {
+		if virtualDevice, err = blockdevice_2_0(deviceSource); err != nil {
+			return nil, errors.Annotate(err, "virtual device")
+		}
+	}
+
+	uuid, _ := valid["uuid"].(string)
+	result := &raid{
+		resourceURI: valid["resource_uri"].(string),
+
+		id:    valid["id"].(int),
+		name:  valid["name"].(string),
+		uuid:  uuid,
+		level: valid["level"].(string),
+		size:  valid["size"].(uint64),
+
+		devices:       devices,
+		spareDevices:  spareDevices,
+		virtualDevice: virtualDevice,
+	}
+	return result, nil
+}
@@ -0,0 +1,190 @@
+// Copyright 2022 Canonical Ltd.
+// Licensed under the LGPLv3, see LICENCE file for details.
+
+package gomaasapi
+
+import (
+	"net/http"
+
+	"github.com/juju/testing"
+	jc "github.com/juju/testing/checkers"
+	"github.com/juju/version"
+	gc "gopkg.in/check.v1"
+)
+
+type raidSuite struct {
+	testing.LoggingCleanupSuite
+}
+
+var _ = gc.Suite(&raidSuite{})
+
+func (*raidSuite) TestNilVirtualDevice(c *gc.C) {
+	var empty raid
+	c.Check(empty.VirtualDevice() == nil, jc.IsTrue)
+}
+
+func (*raidSuite) TestReadRAIDsBadSchema(c *gc.C) {
+	_, err := readRAIDs(twoDotOh, "wat?")
+	c.Check(err, jc.Satisfies, IsDeserializationError)
+	c.Assert(err.Error(), gc.Equals, `raid base schema check failed: expected list, got string("wat?")`)
+}
+
+func (*raidSuite) TestReadRAIDs(c *gc.C) {
+	raids, err := readRAIDs(twoDotOh, parseJSON(c, raidsResponse))
+	c.Assert(err, jc.ErrorIsNil)
+	c.Assert(raids, gc.HasLen, 1)
+	raid := raids[0]
+
+	c.Check(raid.ID(), gc.Equals, 66)
+	c.Check(raid.Name(), gc.Equals, "md0")
+	c.Check(raid.UUID(), gc.Equals, "3e1d5c4a-51d1-4f4e-9d6c-0a6b2d6e0c9b")
+	c.Check(raid.Level(), gc.Equals, RAID1)
+	c.Check(raid.Size(), gc.Equals, uint64(8581545984))
+
+	devices := raid.Devices()
+	c.Assert(devices, gc.HasLen, 2)
+	c.Check(devices[0].Type(), gc.Equals, "blockdevice")
+	c.Check(devices[0].ID(), gc.Equals, 34)
+	c.Check(devices[1].Type(), gc.Equals, "partition")
+	c.Check(devices[1].ID(), gc.Equals, 101)
+
+	spares := raid.SpareDevices()
+	c.Assert(spares, gc.HasLen, 0)
+
+	virtual := raid.VirtualDevice()
+	c.Assert(virtual, gc.NotNil)
+	c.Check(virtual.Name(), gc.Equals, "md0")
+	c.Check(virtual.ID(), gc.Equals, 67)
+}
+
+func (*raidSuite) TestLowVersion(c *gc.C) {
+	_, err := readRAIDs(version.MustParse("1.9.0"), parseJSON(c, raidsResponse))
+	c.Assert(err, jc.Satisfies, IsUnsupportedVersionError)
+}
+
+func (*raidSuite) TestHighVersion(c *gc.C) {
+	raids, err := readRAIDs(version.MustParse("2.1.9"), parseJSON(c, raidsResponse))
+	c.Assert(err, jc.ErrorIsNil)
+	c.Assert(raids, gc.HasLen, 1)
+}
+
+func (s *raidSuite) getServerAndRAID(c *gc.C) (*SimpleTestServer, *raid) {
+	server, ctrl := createTestServerController(c, s)
+	raid, err := readRAID(twoDotOh, parseJSON(c, raidResponse))
+	c.Assert(err, jc.ErrorIsNil)
+	raid.controller = ctrl.(*controller)
+	server.ResetRequests()
+	return server, raid
+}
+
+func (s *raidSuite) TestUpdate(c *gc.C) {
+	server, raid := s.getServerAndRAID(c)
+	response := updateJSONMap(c, raidResponse, map[string]interface{}{
+		"name": "md-data",
+	})
+	server.AddPutResponse("/MAAS/api/2.0/nodes/4y3ha3/raid/66/", http.StatusOK, response)
+	err := raid.Update(UpdateRAIDArgs{
+		Name:            "md-data",
+		AddSpareDevices: []BlockDevice{&blockdevice{id: 98}},
+		RemovePartitions: []Partition{
+			&partition{id: 101},
+		},
+	})
+	c.Assert(err, jc.ErrorIsNil)
+	c.Check(raid.Name(), gc.Equals, "md-data")
+
+	form := server.LastRequest().PostForm
+	c.Check(form.Get("name"), gc.Equals, "md-data")
+	c.Check(form["add_spare_devices"], jc.DeepEquals, []string{"98"})
+	c.Check(form["remove_partitions"], jc.DeepEquals, []string{"101"})
+	_, found := form["add_block_devices"]
+	c.Check(found, jc.IsFalse)
+}
+
+func (s *raidSuite) TestDelete(c *gc.C) {
+	server, raid := s.getServerAndRAID(c)
+	server.AddDeleteResponse("/MAAS/api/2.0/nodes/4y3ha3/raid/66/", http.StatusNoContent, "")
+	err := raid.Delete()
+	c.Assert(err, jc.ErrorIsNil)
+}
+
+func (s *raidSuite) TestDeleteNotFound(c *gc.C) {
+	_, raid := s.getServerAndRAID(c)
+	err := raid.Delete()
+	c.Assert(err, jc.Satisfies, IsNoMatchError)
+}
+
+const raidResponse = `
+{
+    "id": 66,
+    "system_id": "4y3ha3",
+    "name": "md0",
+    "uuid": "3e1d5c4a-51d1-4f4e-9d6c-0a6b2d6e0c9b",
+    "level": "raid-1",
+    "size": 8581545984,
+    "human_size": "8.6 GB",
+    "resource_uri": "/MAAS/api/2.0/nodes/4y3ha3/raid/66/",
+    "devices": [
+        {
+            "path": "/dev/disk/by-dname/sda",
+            "name": "sda",
+            "used_for": "Active raid-1 device for md0",
+            "partitions": [],
+            "filesystem": null,
+            "id_path": "/dev/disk/by-id/ata-QEMU_HARDDISK_QM00001",
+            "resource_uri": "/MAAS/api/2.0/nodes/4y3ha3/blockdevices/34/",
+            "id": 34,
+            "serial": "QM00001",
+            "type": "physical",
+            "block_size": 4096,
+            "used_size": 8589934592,
+            "available_size": 0,
+            "partition_table_type": null,
+            "uuid": null,
+            "size": 8589934592,
+            "model": "QEMU HARDDISK",
+            "tags": []
+        },
+        {
+            "bootable": false,
+            "id": 101,
+            "path": "/dev/disk/by-dname/sdb-part1",
+            "filesystem": {
+                "fstype": "raid",
+                "mount_point": null,
+                "label": null,
+                "mount_options": null,
+                "uuid": "fcd7745e-f1b5-4f5d-9575-9b0bb796b753"
+            },
+            "type": "partition",
+            "resource_uri": "/MAAS/api/2.0/nodes/4y3ha3/blockdevices/98/partition/101",
+            "uuid": "6199b7c9-b66f-40f6-a238-a938a58a0ae0",
+            "used_for": "Active raid-1 device for md0",
+            "size": 8581545984
+        }
+    ],
+    "spare_devices": [],
+    "virtual_device": {
+        "path": "/dev/disk/by-dname/md0",
+        "name": "md0",
+        "used_for": "Unused",
+        "partitions": [],
+        "filesystem": null,
+        "id_path": null,
+        "resource_uri": "/MAAS/api/2.0/nodes/4y3ha3/blockdevices/67/",
+        "id": 67,
+        "serial": null,
+        "type": "virtual",
+        "block_size": 4096,
+        "used_size": 0,
+        "available_size": 8581545984,
+        "partition_table_type": null,
+        "uuid": "3e1d5c4a-51d1-4f4e-9d6c-0a6b2d6e0c9b",
+        "size": 8581545984,
+        "model": null,
+        "tags": []
+    }
+}
+`
+
+var raidsResponse = "[" + raidResponse + "]"
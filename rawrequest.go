@@ -0,0 +1,87 @@
+// Copyright 2022 Canonical Ltd.
+// Licensed under the LGPLv3, see LICENCE file for details.
+
+package gomaasapi
+
+import (
+	"io"
+	"net/http"
+	"net/url"
+	"strings"
+
+	"github.com/juju/errors"
+)
+
+// Do implements Controller.
+func (c *controller) Do(method, path, op string, params url.Values) (JSONObject, error) {
+	method = strings.ToUpper(method)
+	if params == nil {
+		params = make(url.Values)
+	}
+	if params.Get("op") != "" {
+		return JSONObject{}, errors.NotValidf("op in params")
+	}
+	query := make(url.Values)
+	if op != "" {
+		query.Set("op", op)
+	}
+	var body io.Reader
+	switch method {
+	case http.MethodGet, http.MethodDelete:
+		for key, values := range params {
+			query[key] = values
+		}
+	case http.MethodPost, http.MethodPut:
+		body = strings.NewReader(params.Encode())
+	default:
+		return JSONObject{}, errors.NotSupportedf("method %q", method)
+	}
+	uri := c.client.GetURL(&url.URL{Path: EnsureTrailingSlash(path)})
+	uri.RawQuery = query.Encode()
+	request, err := c.client.newRequest(method, uri.String(), body)
+	if err != nil {
+		return JSONObject{}, errors.Trace(err)
+	}
+	if body != nil {
+		request.Header.Set("Content-Type", "application/x-www-form-urlencoded")
+	}
+	return c.doRequest(request)
+}
+
+// DoContent implements Controller.
+func (c *controller) DoContent(method, path, contentType string, content io.Reader) (JSONObject, error) {
+	method = strings.ToUpper(method)
+	switch method {
+	case http.MethodPost, http.MethodPut:
+	default:
+		return JSONObject{}, errors.NotSupportedf("method %q with content", method)
+	}
+	uri := c.client.GetURL(&url.URL{Path: EnsureTrailingSlash(path)})
+	request, err := c.client.newRequest(method, uri.String(), content)
+	if err != nil {
+		return JSONObject{}, errors.Trace(err)
+	}
+	request.Header.Set("Content-Type", contentType)
+	return c.doRequest(request)
+}
+
+// doRequest sends the request and parses the response, which is read as
+// raw bytes if it isn't JSON.
+func (c *controller) doRequest(request *http.Request) (JSONObject, error) {
+	requestID := nextRequestID()
+	logger.Tracef("request %x: %s %s", requestID, request.Method, request.URL)
+	body, err := c.client.dispatchRequest(request)
+	if err != nil {
+		logger.Tracef("response %x: error: %q", requestID, err.Error())
+		return JSONObject{}, errors.Trace(err)
+	}
+	logger.Tracef("response %x: %s", requestID, string(body))
+	if body == nil {
+		body = []byte{}
+	}
+	result, err := Parse(*c.client, body)
+	if err != nil {
+		return JSONObject{}, NewDeserializationError("response to %s %s: %v", request.Method, request.URL.Path, err)
+	}
+	return result, nil
+}
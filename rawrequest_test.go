@@ -0,0 +1,112 @@
+// Copyright 2022 Canonical Ltd.
+// Licensed under the LGPLv3, see LICENCE file for details.
+
+package gomaasapi
+
+import (
+	"io/ioutil"
+	"net/http"
+	"net/url"
+	"strings"
+
+	"github.com/juju/errors"
+	"github.com/juju/testing"
+	jc "github.com/juju/testing/checkers"
+	gc "gopkg.in/check.v1"
+)
+
+type rawRequestSuite struct {
+	testing.LoggingCleanupSuite
+}
+
+var _ = gc.Suite(&rawRequestSuite{})
+
+func (s *rawRequestSuite) TestDoGet(c *gc.C) {
+	server, controller := createTestServerController(c, s)
+	server.AddGetResponse("/api/2.0/vm-hosts/?op=parameters&type=lxd", http.StatusOK, `{"power_address": "https://10.0.0.1:8443"}`)
+
+	result, err := controller.Do("GET", "vm-hosts", "parameters", url.Values{"type": {"lxd"}})
+	c.Assert(err, jc.ErrorIsNil)
+	values, err := result.GetMap()
+	c.Assert(err, jc.ErrorIsNil)
+	address, err := values["power_address"].GetString()
+	c.Assert(err, jc.ErrorIsNil)
+	c.Check(address, gc.Equals, "https://10.0.0.1:8443")
+
+	request := server.LastRequest()
+	c.Check(request.Method, gc.Equals, "GET")
+	c.Check(request.Header.Get("Authorization"), jc.Contains, `oauth_consumer_key="fake"`)
+}
+
+func (s *rawRequestSuite) TestDoPost(c *gc.C) {
+	server, controller := createTestServerController(c, s)
+	server.AddPostResponse("/api/2.0/machines/abc123/?op=set_workload_annotations", http.StatusOK, `{"system_id": "abc123"}`)
+
+	result, err := controller.Do("post", "machines/abc123/", "set_workload_annotations", url.Values{"owner": {"juju"}})
+	c.Assert(err, jc.ErrorIsNil)
+	values, err := result.GetMap()
+	c.Assert(err, jc.ErrorIsNil)
+	c.Check(values, gc.HasLen, 1)
+
+	request := server.LastRequest()
+	c.Check(request.Method, gc.Equals, "POST")
+	c.Check(request.PostForm.Get("owner"), gc.Equals, "juju")
+	c.Check(request.URL.Query().Get("owner"), gc.Equals, "")
+}
+
+func (s *rawRequestSuite) TestDoAbsolutePath(c *gc.C) {
+	server, controller := createTestServerController(c, s)
+	server.AddDeleteResponse("/api/2.0/notifications/3/", http.StatusNoContent, "")
+
+	result, err := controller.Do("DELETE", "/api/2.0/notifications/3", "", nil)
+	c.Assert(err, jc.ErrorIsNil)
+	bytes, err := result.GetBytes()
+	c.Assert(err, jc.ErrorIsNil)
+	c.Check(bytes, gc.HasLen, 0)
+}
+
+func (s *rawRequestSuite) TestDoRawResponse(c *gc.C) {
+	server, controller := createTestServerController(c, s)
+	server.AddGetResponse("/api/2.0/files/?filename=notes&op=get", http.StatusOK, "not JSON")
+
+	result, err := controller.Do("GET", "files", "get", url.Values{"filename": {"notes"}})
+	c.Assert(err, jc.ErrorIsNil)
+	bytes, err := result.GetBytes()
+	c.Assert(err, jc.ErrorIsNil)
+	c.Check(string(bytes), gc.Equals, "not JSON")
+}
+
+func (s *rawRequestSuite) TestDoServerError(c *gc.C) {
+	server, controller := createTestServerController(c, s)
+	server.AddGetResponse("/api/2.0/switches/", http.StatusNotFound, "no such endpoint")
+
+	_, err := controller.Do("GET", "switches", "", nil)
+	svrErr, ok := GetServerError(err)
+	c.Assert(ok, jc.IsTrue)
+	c.Check(svrErr.StatusCode, gc.Equals, http.StatusNotFound)
+	c.Check(svrErr.BodyMessage, gc.Equals, "no such endpoint")
+}
+
+func (s *rawRequestSuite) TestDoInvalid(c *gc.C) {
+	_, controller := createTestServerController(c, s)
+	_, err := controller.Do("GET", "machines", "", url.Values{"op": {"list"}})
+	c.Check(err, jc.Satisfies, errors.IsNotValid)
+	_, err = controller.Do("PATCH", "machines", "", nil)
+	c.Check(err, jc.Satisfies, errors.IsNotSupported)
+	_, err = controller.DoContent("GET", "machines", "text/plain", strings.NewReader("content"))
+	c.Check(err, jc.Satisfies, errors.IsNotSupported)
+}
+
+func (s *rawRequestSuite) TestDoContent(c *gc.C) {
+	server, controller := createTestServerController(c, s)
+	server.AddPutResponse("/api/2.0/scripts/smartctl/", http.StatusOK, `{"name": "smartctl"}`)
+
+	_, err := controller.DoContent("PUT", "scripts/smartctl", "application/octet-stream", strings.NewReader("#!/bin/sh"))
+	c.Assert(err, jc.ErrorIsNil)
+
+	request := server.LastRequest()
+	c.Check(request.Header.Get("Content-Type"), gc.Equals, "application/octet-stream")
+	content, err := ioutil.ReadAll(request.Body)
+	c.Assert(err, jc.ErrorIsNil)
+	c.Check(string(content), gc.Equals, "#!/bin/sh")
+}
@@ -0,0 +1,205 @@
+// Copyright 2022 Canonical Ltd.
+// Licensed under the LGPLv3, see LICENCE file for details.
+
+package gomaasapi
+
+import (
+	"net/http"
+
+	"github.com/juju/errors"
+	"github.com/juju/schema"
+	"github.com/juju/version"
+)
+
+type regionController struct {
+	controller *controller
+
+	resourceURI string
+
+	systemID     string
+	hostname     string
+	fqdn         string
+	architecture string
+	version      string
+	ipAddresses  []string
+
+	services     []ServiceStatus
+	interfaceSet []*interface_
+}
+
+// SystemID implements RegionController.
+func (r *regionController) SystemID() string {
+	return r.systemID
+}
+
+// Hostname implements RegionController.
+func (r *regionController) Hostname() string {
+	return r.hostname
+}
+
+// FQDN implements RegionController.
+func (r *regionController) FQDN() string {
+	return r.fqdn
+}
+
+// Architecture implements RegionController.
+func (r *regionController) Architecture() string {
+	return r.architecture
+}
+
+// Version implements RegionController.
+func (r *regionController) Version() string {
+	return r.version
+}
+
+// IPAddresses implements RegionController.
+func (r *regionController) IPAddresses() []string {
+	return r.ipAddresses
+}
+
+// Services implements RegionController.
+func (r *regionController) Services() []ServiceStatus {
+	result := make([]ServiceStatus, len(r.services))
+	copy(result, r.services)
+	return result
+}
+
+// Service implements RegionController.
+func (r *regionController) Service(name string) (ServiceStatus, bool) {
+	for _, service := range r.services {
+		if service.Name == name {
+			return service, true
+		}
+	}
+	return ServiceStatus{}, false
+}
+
+// Processes implements RegionController.
+func (r *regionController) Processes() ServiceStatus {
+	service, ok := r.Service("regiond")
+	if !ok {
+		return ServiceStatus{Name: "regiond", Status: "unknown"}
+	}
+	return service
+}
+
+// InterfaceSet implements RegionController.
+func (r *regionController) InterfaceSet() []Interface {
+	result := make([]Interface, len(r.interfaceSet))
+	for i, v := range r.interfaceSet {
+		v.controller = r.controller
+		result[i] = v
+	}
+	return result
+}
+
+// RegionControllers implements Controller.
+func (c *controller) RegionControllers() ([]RegionController, error) {
+	source, err := c.getList("regioncontrollers", nil, 0)
+	if err != nil {
+		if svrErr, ok := errors.Cause(err).(ServerError); ok {
+			if svrErr.StatusCode == http.StatusForbidden {
+				return nil, wrapError(err, NewPermissionError(svrErr.BodyMessage))
+			}
+		}
+		return nil, NewUnexpectedError(err)
+	}
+	regions, err := readRegionControllers(c.schemaVersion(), source)
+	if err != nil {
+		return nil, errors.Trace(err)
+	}
+	var result []RegionController
+	for _, r := range regions {
+		r.controller = c
+		result = append(result, r)
+	}
+	return result, nil
+}
+
+func readRegionControllers(controllerVersion version.Number, source interface{}) ([]*regionController, error) {
+	var deserialisationVersion version.Number
+	for v := range regionControllerDeserializationFuncs {
+		if v.Compare(deserialisationVersion) > 0 && v.Compare(controllerVersion) <= 0 {
+			deserialisationVersion = v
+		}
+	}
+	if deserialisationVersion == version.Zero {
+		return nil, NewUnsupportedVersionError("no region controller read func for version %s", controllerVersion)
+	}
+	readFunc := regionControllerDeserializationFuncs[deserialisationVersion]
+
+	checker := schema.List(schema.StringMap(schema.Any()))
+	coerced, err := checker.Coerce(source, nil)
+	if err != nil {
+		return nil, WrapWithDeserializationError(err, "region controller base schema check failed")
+	}
+	valid := coerced.([]interface{})
+	result := make([]*regionController, 0, len(valid))
+	for i, value := range valid {
+		region, err := readFunc(value.(map[string]interface{}))
+		if err != nil {
+			return nil, errors.Annotatef(err, "region controller %d", i)
+		}
+		result = append(result, region)
+	}
+	return result, nil
+}
+
+type regionControllerDeserializationFunc func(map[string]interface{}) (*regionController, error)
+
+var regionControllerDeserializationFuncs = map[version.Number]regionControllerDeserializationFunc{
+	twoDotOh: regionController_2_0,
+}
+
+func regionController_2_0(source map[string]interface{}) (*regionController, error) {
+	fields := schema.Fields{
+		"resource_uri": schema.String(),
+
+		"system_id":    schema.String(),
+		"hostname":     schema.String(),
+		"fqdn":         schema.String(),
+		"architecture": schema.OneOf(schema.Nil(""), schema.String()),
+		"version":      schema.OneOf(schema.Nil(""), schema.String()),
+		"ip_addresses": schema.List(schema.String()),
+
+		"service_set":   serviceSetSchema,
+		"interface_set": schema.List(schema.StringMap(schema.Any())),
+	}
+	defaults := schema.Defaults{
+		"fqdn":          "",
+		"architecture":  nil,
+		"version":       nil,
+		"ip_addresses":  []interface{}{},
+		"service_set":   []interface{}{},
+		"interface_set": []interface{}{},
+	}
+	checker := schema.FieldMap(fields, defaults)
+	coerced, err := checker.Coerce(source, nil)
+	if err != nil {
+		return nil, WrapWithDeserializationError(err, "region controller 2.0 schema check failed")
+	}
+	valid := coerced.(map[string]interface{})
+	// From here we know that the map returned from the schema coercion
+	// contains fields of the right type.
+
+	interfaceSet, err := readInterfaceList(valid["interface_set"].([]interface{}), interface_2_0)
+	if err != nil {
+		return nil, errors.Trace(err)
+	}
+	architecture, _ := valid["architecture"].(string)
+	regionVersion, _ := valid["version"].(string)
+	result := &regionController{
+		resourceURI: valid["resource_uri"].(string),
+
+		systemID:     valid["system_id"].(string),
+		hostname:     valid["hostname"].(string),
+		fqdn:         valid["fqdn"].(string),
+		architecture: architecture,
+		version:      regionVersion,
+		ipAddresses:  convertToStringSlice(valid["ip_addresses"]),
+
+		services:     readServiceSet(valid["service_set"]),
+		interfaceSet: interfaceSet,
+	}
+	return result, nil
+}
@@ -0,0 +1,106 @@
+// Copyright 2022 Canonical Ltd.
+// Licensed under the LGPLv3, see LICENCE file for details.
+
+package gomaasapi
+
+import (
+	"net/http"
+
+	"github.com/juju/testing"
+	jc "github.com/juju/testing/checkers"
+	"github.com/juju/version"
+	gc "gopkg.in/check.v1"
+)
+
+type regionControllerSuite struct {
+	testing.LoggingCleanupSuite
+}
+
+var _ = gc.Suite(&regionControllerSuite{})
+
+func (*regionControllerSuite) TestReadRegionControllersBadSchema(c *gc.C) {
+	_, err := readRegionControllers(twoDotOh, "wat?")
+	c.Check(err, jc.Satisfies, IsDeserializationError)
+	c.Assert(err.Error(), gc.Equals, `region controller base schema check failed: expected list, got string("wat?")`)
+}
+
+func (*regionControllerSuite) TestReadRegionControllers(c *gc.C) {
+	regions, err := readRegionControllers(twoDotOh, parseJSON(c, regionControllersResponse))
+	c.Assert(err, jc.ErrorIsNil)
+	c.Assert(regions, gc.HasLen, 2)
+
+	region := regions[0]
+	c.Check(region.SystemID(), gc.Equals, "4y3h7m")
+	c.Check(region.Hostname(), gc.Equals, "region-1")
+	c.Check(region.FQDN(), gc.Equals, "region-1.maas")
+	c.Check(region.Architecture(), gc.Equals, "amd64/generic")
+	c.Check(region.Version(), gc.Equals, "3.2.6-12016-g.19812b4da")
+	c.Check(region.IPAddresses(), jc.DeepEquals, []string{"192.168.100.1"})
+	c.Check(region.Services(), gc.HasLen, 3)
+	c.Check(region.Processes(), jc.DeepEquals, ServiceStatus{
+		Name:       "regiond",
+		Status:     "degraded",
+		StatusInfo: "2 processes running but 4 were expected.",
+	})
+	bind, ok := region.Service("bind9")
+	c.Check(ok, jc.IsTrue)
+	c.Check(bind.Status, gc.Equals, "running")
+	c.Check(region.InterfaceSet(), gc.HasLen, 1)
+
+	region = regions[1]
+	c.Check(region.Version(), gc.Equals, "")
+	c.Check(region.Processes(), jc.DeepEquals, ServiceStatus{Name: "regiond", Status: "unknown"})
+}
+
+func (*regionControllerSuite) TestLowVersion(c *gc.C) {
+	_, err := readRegionControllers(version.MustParse("1.9.0"), parseJSON(c, regionControllersResponse))
+	c.Assert(err, jc.Satisfies, IsUnsupportedVersionError)
+}
+
+func (*regionControllerSuite) TestHighVersion(c *gc.C) {
+	regions, err := readRegionControllers(version.MustParse("2.1.9"), parseJSON(c, regionControllersResponse))
+	c.Assert(err, jc.ErrorIsNil)
+	c.Assert(regions, gc.HasLen, 2)
+}
+
+func (s *regionControllerSuite) TestRegionControllers(c *gc.C) {
+	server, controller := createTestServerController(c, s)
+	server.AddGetResponse("/api/2.0/regioncontrollers/", http.StatusOK, regionControllersResponse)
+	regions, err := controller.RegionControllers()
+	c.Assert(err, jc.ErrorIsNil)
+	c.Assert(regions, gc.HasLen, 2)
+	c.Check(regions[0].InterfaceSet()[0].ID(), gc.Equals, 40)
+}
+
+func (s *regionControllerSuite) TestRegionControllersForbidden(c *gc.C) {
+	server, controller := createTestServerController(c, s)
+	server.AddGetResponse("/api/2.0/regioncontrollers/", http.StatusForbidden, "admins only")
+	_, err := controller.RegionControllers()
+	c.Assert(err, jc.Satisfies, IsPermissionError)
+}
+
+const regionControllersResponse = `
+[
+    {
+        "system_id": "4y3h7m",
+        "hostname": "region-1",
+        "fqdn": "region-1.maas",
+        "architecture": "amd64/generic",
+        "version": "3.2.6-12016-g.19812b4da",
+        "ip_addresses": ["192.168.100.1"],
+        "service_set": [
+            {"name": "regiond", "status": "degraded", "status_info": "2 processes running but 4 were expected."},
+            {"name": "bind9", "status": "running", "status_info": ""},
+            {"name": "proxy", "status": "off", "status_info": "disabled"}
+        ],
+        "interface_set": [` + interfaceResponse + `],
+        "resource_uri": "/MAAS/api/2.0/regioncontrollers/4y3h7m/"
+    },
+    {
+        "system_id": "4y3h7r",
+        "hostname": "region-2",
+        "version": null,
+        "resource_uri": "/MAAS/api/2.0/regioncontrollers/4y3h7r/"
+    }
+]
+`
@@ -0,0 +1,252 @@
+// Copyright 2022 Canonical Ltd.
+// Licensed under the LGPLv3, see LICENCE file for details.
+
+package gomaasapi
+
+import (
+	"context"
+	"fmt"
+	"sync"
+	"time"
+
+	"github.com/juju/errors"
+)
+
+// Priority orders the requests waiting in a RequestQueue.
+type Priority int
+
+const (
+	// PriorityBackground is for bulk work such as periodic reconciliation.
+	PriorityBackground Priority = -1
+	// PriorityNormal is the priority of controllers that haven't been
+	// given one with Controller.WithPriority.
+	PriorityNormal Priority = 0
+	// PriorityHigh is for interactive requests that a user is waiting on.
+	PriorityHigh Priority = 1
+
+	priorityCount = 3
+)
+
+// String returns the name of the priority.
+func (p Priority) String() string {
+	switch p {
+	case PriorityBackground:
+		return "background"
+	case PriorityNormal:
+		return "normal"
+	case PriorityHigh:
+		return "high"
+	}
+	return fmt.Sprintf("priority(%d)", int(p))
+}
+
+func (p Priority) valid() bool {
+	return p >= PriorityBackground && p <= PriorityHigh
+}
+
+// index is the position of the priority in the queues, lowest first.
+func (p Priority) index() int {
+	return int(p - PriorityBackground)
+}
+
+// RequestQueueArgs is an argument struct for NewRequestQueue.
+type RequestQueueArgs struct {
+	// MaxConcurrent is the number of requests that may be in flight at
+	// once. It is required.
+	MaxConcurrent int
+	// MaxSkips is the number of times a waiting request can be passed
+	// over in favour of higher priority requests before it is sent
+	// anyway. It guarantees that background requests make progress under
+	// a constant load of interactive requests. Defaults to 10.
+	MaxSkips int
+}
+
+// Validate ensures that MaxConcurrent is positive and MaxSkips isn't
+// negative.
+func (a *RequestQueueArgs) Validate() error {
+	if a.MaxConcurrent <= 0 {
+		return errors.NotValidf("MaxConcurrent %d", a.MaxConcurrent)
+	}
+	if a.MaxSkips < 0 {
+		return errors.NotValidf("MaxSkips %d", a.MaxSkips)
+	}
+	return nil
+}
+
+// RequestQueueStats is a snapshot of the state of a RequestQueue.
+type RequestQueueStats struct {
+	// Active is the number of requests in flight.
+	Active int
+	// Waiting is the number of requests queued for each priority.
+	Waiting map[Priority]int
+	// Dispatched is the number of requests sent for each priority since
+	// the queue was created.
+	Dispatched map[Priority]uint64
+	// WaitTime is the total time requests of each priority spent queued.
+	WaitTime map[Priority]time.Duration
+}
+
+// RequestQueue limits the number of concurrent requests sent to MAAS, and
+// sends the queued requests in priority order. The same queue can be shared
+// by several controllers, see ControllerArgs.RequestQueue.
+type RequestQueue struct {
+	maxConcurrent int
+	maxSkips      int
+
+	mu         sync.Mutex
+	active     int
+	waiting    [priorityCount][]chan struct{}
+	skipped    [priorityCount]int
+	dispatched [priorityCount]uint64
+	waitTime   [priorityCount]time.Duration
+}
+
+// NewRequestQueue returns a new, empty, request queue.
+func NewRequestQueue(args RequestQueueArgs) (*RequestQueue, error) {
+	if err := args.Validate(); err != nil {
+		return nil, errors.Trace(err)
+	}
+	maxSkips := args.MaxSkips
+	if maxSkips == 0 {
+		maxSkips = 10
+	}
+	return &RequestQueue{
+		maxConcurrent: args.MaxConcurrent,
+		maxSkips:      maxSkips,
+	}, nil
+}
+
+// acquire blocks until a request of the given priority may be sent. The
+// returned function must be called once the request has completed.
+func (q *RequestQueue) acquire(priority Priority) func() {
+	// The background context is never done, so there is no error.
+	release, _ := q.acquireContext(context.Background(), priority)
+	return release
+}
+
+// acquireContext is acquire, but gives up waiting when the context is done,
+// in which case the error of the context is returned.
+func (q *RequestQueue) acquireContext(ctx context.Context, priority Priority) (func(), error) {
+	if !priority.valid() {
+		priority = PriorityNormal
+	}
+	start := time.Now()
+	q.mu.Lock()
+	if q.active < q.maxConcurrent && q.waitingCount() == 0 {
+		q.active++
+		q.dispatched[priority.index()]++
+		q.mu.Unlock()
+		return q.release, nil
+	}
+	ready := make(chan struct{}, 1)
+	index := priority.index()
+	q.waiting[index] = append(q.waiting[index], ready)
+	q.mu.Unlock()
+
+	select {
+	case <-ready:
+	case <-ctx.Done():
+		q.mu.Lock()
+		removed := q.removeWaiting(index, ready)
+		q.mu.Unlock()
+		if !removed {
+			// The slot was handed over as the context was done, so it
+			// is passed on to the next request.
+			<-ready
+			q.release()
+		}
+		return nil, ctx.Err()
+	}
+	// The slot of the released request has been handed over, so active
+	// is already accounted for.
+	q.mu.Lock()
+	q.waitTime[index] += time.Since(start)
+	q.mu.Unlock()
+	return q.release, nil
+}
+
+// removeWaiting removes the ready channel of a request from the waiting
+// requests, and reports whether it was still waiting. The mutex must be
+// held.
+func (q *RequestQueue) removeWaiting(index int, ready chan struct{}) bool {
+	for i, waiting := range q.waiting[index] {
+		if waiting == ready {
+			q.waiting[index] = append(q.waiting[index][:i:i], q.waiting[index][i+1:]...)
+			return true
+		}
+	}
+	return false
+}
+
+func (q *RequestQueue) release() {
+	q.mu.Lock()
+	defer q.mu.Unlock()
+	next, ok := q.next()
+	if !ok {
+		q.active--
+		return
+	}
+	ready := q.waiting[next][0]
+	q.waiting[next] = q.waiting[next][1:]
+	q.dispatched[next]++
+	ready <- struct{}{}
+}
+
+// next chooses the queue index of the next request to send. Requests that
+// have been skipped too often go first, lowest priority first, otherwise the
+// highest priority wins. Every other waiting priority counts as skipped.
+func (q *RequestQueue) next() (int, bool) {
+	chosen := -1
+	for p := 0; p < priorityCount; p++ {
+		if len(q.waiting[p]) > 0 && q.skipped[p] >= q.maxSkips {
+			chosen = p
+			break
+		}
+	}
+	if chosen < 0 {
+		for p := priorityCount - 1; p >= 0; p-- {
+			if len(q.waiting[p]) > 0 {
+				chosen = p
+				break
+			}
+		}
+	}
+	if chosen < 0 {
+		return 0, false
+	}
+	for p := 0; p < priorityCount; p++ {
+		if p == chosen {
+			q.skipped[p] = 0
+		} else if len(q.waiting[p]) > 0 {
+			q.skipped[p]++
+		}
+	}
+	return chosen, true
+}
+
+func (q *RequestQueue) waitingCount() int {
+	count := 0
+	for _, waiting := range q.waiting {
+		count += len(waiting)
+	}
+	return count
+}
+
+// Stats returns the current state of the queue.
+func (q *RequestQueue) Stats() RequestQueueStats {
+	q.mu.Lock()
+	defer q.mu.Unlock()
+	stats := RequestQueueStats{
+		Active:     q.active,
+		Waiting:    make(map[Priority]int),
+		Dispatched: make(map[Priority]uint64),
+		WaitTime:   make(map[Priority]time.Duration),
+	}
+	for p := 0; p < priorityCount; p++ {
+		priority := Priority(p) + PriorityBackground
+		stats.Waiting[priority] = len(q.waiting[p])
+		stats.Dispatched[priority] = q.dispatched[p]
+		stats.WaitTime[priority] = q.waitTime[p]
+	}
+	return stats
+}
@@ -0,0 +1,254 @@
+// Copyright 2022 Canonical Ltd.
+// Licensed under the LGPLv3, see LICENCE file for details.
+
+package gomaasapi
+
+import (
+	"context"
+	"net/http"
+	"time"
+
+	"github.com/juju/errors"
+	"github.com/juju/testing"
+	jc "github.com/juju/testing/checkers"
+	gc "gopkg.in/check.v1"
+)
+
+type requestQueueSuite struct {
+	testing.LoggingCleanupSuite
+}
+
+var _ = gc.Suite(&requestQueueSuite{})
+
+func (*requestQueueSuite) TestValidate(c *gc.C) {
+	_, err := NewRequestQueue(RequestQueueArgs{})
+	c.Check(err, jc.Satisfies, errors.IsNotValid)
+	c.Check(err, gc.ErrorMatches, "MaxConcurrent 0 not valid")
+	_, err = NewRequestQueue(RequestQueueArgs{MaxConcurrent: 1, MaxSkips: -1})
+	c.Check(err, jc.Satisfies, errors.IsNotValid)
+	c.Check(err, gc.ErrorMatches, "MaxSkips -1 not valid")
+}
+
+func (*requestQueueSuite) TestPriorityString(c *gc.C) {
+	c.Check(PriorityBackground.String(), gc.Equals, "background")
+	c.Check(PriorityNormal.String(), gc.Equals, "normal")
+	c.Check(PriorityHigh.String(), gc.Equals, "high")
+	c.Check(Priority(7).String(), gc.Equals, "priority(7)")
+}
+
+func (*requestQueueSuite) TestConcurrencyLimit(c *gc.C) {
+	queue, err := NewRequestQueue(RequestQueueArgs{MaxConcurrent: 2})
+	c.Assert(err, jc.ErrorIsNil)
+	release1 := queue.acquire(PriorityNormal)
+	release2 := queue.acquire(PriorityNormal)
+	c.Check(queue.Stats().Active, gc.Equals, 2)
+
+	acquired := make(chan struct{})
+	go func() {
+		queue.acquire(PriorityNormal)()
+		close(acquired)
+	}()
+	waitForQueued(c, queue, 1)
+	select {
+	case <-acquired:
+		c.Fatalf("request sent while the queue was full")
+	default:
+	}
+
+	release1()
+	waitForAcquired(c, acquired)
+	release2()
+	stats := queue.Stats()
+	c.Check(stats.Active, gc.Equals, 0)
+	c.Check(stats.Dispatched[PriorityNormal], gc.Equals, uint64(3))
+}
+
+func (*requestQueueSuite) TestAcquireContextCancelled(c *gc.C) {
+	queue, err := NewRequestQueue(RequestQueueArgs{MaxConcurrent: 1})
+	c.Assert(err, jc.ErrorIsNil)
+	release := queue.acquire(PriorityNormal)
+
+	ctx, cancel := context.WithCancel(context.Background())
+	done := make(chan error, 1)
+	go func() {
+		_, err := queue.acquireContext(ctx, PriorityNormal)
+		done <- err
+	}()
+	waitForQueued(c, queue, 1)
+	cancel()
+	select {
+	case err := <-done:
+		c.Assert(err, gc.Equals, context.Canceled)
+	case <-time.After(testing.LongWait):
+		c.Fatalf("timed out waiting for the request to be cancelled")
+	}
+	waitForQueued(c, queue, 0)
+
+	// The cancelled request doesn't take the slot.
+	release()
+	queue.acquire(PriorityNormal)()
+	stats := queue.Stats()
+	c.Check(stats.Active, gc.Equals, 0)
+	c.Check(stats.Dispatched[PriorityNormal], gc.Equals, uint64(2))
+}
+
+func (s *requestQueueSuite) TestWithContext(c *gc.C) {
+	server := NewSimpleServer()
+	server.AddGetResponse("/api/2.0/users/?op=whoami", http.StatusOK, `"captain awesome"`)
+	server.AddGetResponse("/api/2.0/version/", http.StatusOK, versionResponse)
+	server.AddGetResponse("/api/2.0/zones/", http.StatusOK, zoneResponse)
+	server.Start()
+	s.AddCleanup(func(*gc.C) { server.Close() })
+
+	queue, err := NewRequestQueue(RequestQueueArgs{MaxConcurrent: 1})
+	c.Assert(err, jc.ErrorIsNil)
+	controller, err := NewController(ControllerArgs{
+		BaseURL:      server.URL,
+		APIKey:       "fake:as:key",
+		RequestQueue: queue,
+	})
+	c.Assert(err, jc.ErrorIsNil)
+	release := queue.acquire(PriorityHigh)
+	defer release()
+
+	ctx, cancel := context.WithTimeout(context.Background(), 10*time.Millisecond)
+	defer cancel()
+	server.ResetRequests()
+	_, err = controller.WithContext(ctx).Zones()
+	c.Assert(err, jc.Satisfies, IsUnexpectedError)
+	c.Assert(err, gc.ErrorMatches, ".*context deadline exceeded")
+	c.Check(server.RequestCount(), gc.Equals, 0)
+	c.Check(queue.Stats().Waiting[PriorityNormal], gc.Equals, 0)
+}
+
+func (*requestQueueSuite) TestPriorityOrder(c *gc.C) {
+	queue, err := NewRequestQueue(RequestQueueArgs{MaxConcurrent: 1})
+	c.Assert(err, jc.ErrorIsNil)
+	release := queue.acquire(PriorityNormal)
+
+	order := make(chan string, 4)
+	queueRequest(c, queue, PriorityBackground, "background", order, 1)
+	queueRequest(c, queue, PriorityNormal, "normal", order, 2)
+	queueRequest(c, queue, PriorityHigh, "high-1", order, 3)
+	queueRequest(c, queue, PriorityHigh, "high-2", order, 4)
+
+	stats := queue.Stats()
+	c.Check(stats.Waiting, jc.DeepEquals, map[Priority]int{
+		PriorityBackground: 1,
+		PriorityNormal:     1,
+		PriorityHigh:       2,
+	})
+
+	release()
+	c.Check(readOrder(c, order, 4), jc.DeepEquals, []string{"high-1", "high-2", "normal", "background"})
+	stats = queue.Stats()
+	c.Check(stats.Active, gc.Equals, 0)
+	c.Check(stats.Dispatched, jc.DeepEquals, map[Priority]uint64{
+		PriorityBackground: 1,
+		PriorityNormal:     2,
+		PriorityHigh:       2,
+	})
+	c.Check(stats.WaitTime[PriorityBackground] > 0, jc.IsTrue)
+}
+
+func (*requestQueueSuite) TestFairness(c *gc.C) {
+	queue, err := NewRequestQueue(RequestQueueArgs{MaxConcurrent: 1, MaxSkips: 1})
+	c.Assert(err, jc.ErrorIsNil)
+	release := queue.acquire(PriorityHigh)
+
+	order := make(chan string, 4)
+	queueRequest(c, queue, PriorityBackground, "background", order, 1)
+	queueRequest(c, queue, PriorityHigh, "high-1", order, 2)
+	queueRequest(c, queue, PriorityHigh, "high-2", order, 3)
+	queueRequest(c, queue, PriorityHigh, "high-3", order, 4)
+
+	release()
+	c.Check(readOrder(c, order, 4), jc.DeepEquals, []string{"high-1", "background", "high-2", "high-3"})
+}
+
+func (*requestQueueSuite) TestInvalidPriority(c *gc.C) {
+	queue, err := NewRequestQueue(RequestQueueArgs{MaxConcurrent: 1})
+	c.Assert(err, jc.ErrorIsNil)
+	queue.acquire(Priority(42))()
+	c.Check(queue.Stats().Dispatched[PriorityNormal], gc.Equals, uint64(1))
+}
+
+func (s *requestQueueSuite) TestControllerPriority(c *gc.C) {
+	server := NewSimpleServer()
+	server.AddGetResponse("/api/2.0/users/?op=whoami", http.StatusOK, `"captain awesome"`)
+	server.AddGetResponse("/api/2.0/version/", http.StatusOK, versionResponse)
+	server.AddGetResponse("/api/2.0/zones/", http.StatusOK, zoneResponse)
+	server.AddGetResponse("/api/2.0/zones/", http.StatusOK, zoneResponse)
+	server.Start()
+	s.AddCleanup(func(*gc.C) { server.Close() })
+
+	queue, err := NewRequestQueue(RequestQueueArgs{MaxConcurrent: 1})
+	c.Assert(err, jc.ErrorIsNil)
+	controller, err := NewController(ControllerArgs{
+		BaseURL:      server.URL,
+		APIKey:       "fake:as:key",
+		RequestQueue: queue,
+	})
+	c.Assert(err, jc.ErrorIsNil)
+	normal := queue.Stats().Dispatched[PriorityNormal]
+	c.Check(normal > 0, jc.IsTrue)
+
+	_, err = controller.Zones()
+	c.Assert(err, jc.ErrorIsNil)
+	_, err = controller.WithPriority(PriorityHigh).Zones()
+	c.Assert(err, jc.ErrorIsNil)
+
+	stats := queue.Stats()
+	c.Check(stats.Dispatched[PriorityNormal], gc.Equals, normal+1)
+	c.Check(stats.Dispatched[PriorityHigh], gc.Equals, uint64(1))
+	c.Check(stats.Active, gc.Equals, 0)
+}
+
+// queueRequest queues a request that reports its label once sent, and waits
+// until the expected number of requests are queued.
+func queueRequest(c *gc.C, queue *RequestQueue, priority Priority, label string, order chan<- string, queued int) {
+	go func() {
+		release := queue.acquire(priority)
+		order <- label
+		release()
+	}()
+	waitForQueued(c, queue, queued)
+}
+
+func waitForQueued(c *gc.C, queue *RequestQueue, count int) {
+	timeout := time.After(testing.LongWait)
+	for {
+		queue.mu.Lock()
+		waiting := queue.waitingCount()
+		queue.mu.Unlock()
+		if waiting == count {
+			return
+		}
+		select {
+		case <-timeout:
+			c.Fatalf("timed out waiting for %d queued requests", count)
+		case <-time.After(time.Millisecond):
+		}
+	}
+}
+
+func waitForAcquired(c *gc.C, acquired <-chan struct{}) {
+	select {
+	case <-acquired:
+	case <-time.After(testing.LongWait):
+		c.Fatalf("timed out waiting for the request to be sent")
+	}
+}
+
+func readOrder(c *gc.C, order <-chan string, count int) []string {
+	var result []string
+	for i := 0; i < count; i++ {
+		select {
+		case label := <-order:
+			result = append(result, label)
+		case <-time.After(testing.LongWait):
+			c.Fatalf("timed out waiting for requests, got %v", result)
+		}
+	}
+	return result
+}
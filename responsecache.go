@@ -0,0 +1,293 @@
+// Copyright 2022 Canonical Ltd.
+// Licensed under the LGPLv3, see LICENCE file for details.
+
+package gomaasapi
+
+import (
+	"bytes"
+	"container/list"
+	"io"
+	"net/http"
+	"strconv"
+	"strings"
+	"sync"
+
+	"github.com/juju/errors"
+)
+
+// ResponseCacheArgs is an argument struct for creating a ResponseCache.
+type ResponseCacheArgs struct {
+	// MaxEntries is the number of responses kept at most, the least
+	// recently used being dropped first. It defaults to 1000.
+	MaxEntries int
+	// MaxBodySize is the size in bytes of the largest response body that
+	// is kept. It defaults to 10MiB.
+	MaxBodySize int64
+}
+
+// Validate ensures the limits aren't negative.
+func (a ResponseCacheArgs) Validate() error {
+	if a.MaxEntries < 0 {
+		return errors.NotValidf("negative MaxEntries")
+	}
+	if a.MaxBodySize < 0 {
+		return errors.NotValidf("negative MaxBodySize")
+	}
+	return nil
+}
+
+// ResponseCacheStats counts the GET requests that went through a cache.
+type ResponseCacheStats struct {
+	// Entries is the number of responses currently kept.
+	Entries int
+	// Hits is the number of requests answered from the cache, after MAAS
+	// confirmed the kept response was unchanged.
+	Hits uint64
+	// Misses is the number of requests for which MAAS returned a new
+	// response.
+	Misses uint64
+}
+
+// ResponseCache keeps the responses to GET requests that MAAS returns with
+// an ETag or a Last-Modified header, and sends them as validators on the
+// next request of the same URL. When MAAS answers that the resource is
+// unchanged, the kept response is returned instead, which spares MAAS the
+// rendering of the response and the client its transfer.
+//
+// Every request is still sent to MAAS, with the credentials of the client
+// sending it, so a cache never returns stale responses. Responses are kept
+// by URL and by the OAuth consumer and token keys the request is signed
+// with, so a cache may be shared by controllers with different credentials
+// without one of them being answered with a response fetched by another,
+// see ControllerArgs.ResponseCache.
+type ResponseCache struct {
+	maxEntries  int
+	maxBodySize int64
+
+	mu      sync.Mutex
+	entries map[string]*list.Element
+	order   *list.List
+	hits    uint64
+	misses  uint64
+}
+
+type cachedResponse struct {
+	key          string
+	etag         string
+	lastModified string
+	header       http.Header
+	body         []byte
+}
+
+// NewResponseCache returns a new, empty, response cache.
+func NewResponseCache(args ResponseCacheArgs) (*ResponseCache, error) {
+	if err := args.Validate(); err != nil {
+		return nil, errors.Trace(err)
+	}
+	maxEntries := args.MaxEntries
+	if maxEntries == 0 {
+		maxEntries = 1000
+	}
+	maxBodySize := args.MaxBodySize
+	if maxBodySize == 0 {
+		maxBodySize = 10 << 20
+	}
+	return &ResponseCache{
+		maxEntries:  maxEntries,
+		maxBodySize: maxBodySize,
+		entries:     make(map[string]*list.Element),
+		order:       list.New(),
+	}, nil
+}
+
+// Stats returns the current counts of the cache.
+func (c *ResponseCache) Stats() ResponseCacheStats {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	return ResponseCacheStats{
+		Entries: c.order.Len(),
+		Hits:    c.hits,
+		Misses:  c.misses,
+	}
+}
+
+// Clear drops all the kept responses.
+func (c *ResponseCache) Clear() {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	c.entries = make(map[string]*list.Element)
+	c.order.Init()
+}
+
+func (c *ResponseCache) lookup(key string) *cachedResponse {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	element, ok := c.entries[key]
+	if !ok {
+		return nil
+	}
+	c.order.MoveToFront(element)
+	return element.Value.(*cachedResponse)
+}
+
+func (c *ResponseCache) store(entry *cachedResponse) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	c.misses++
+	if element, ok := c.entries[entry.key]; ok {
+		element.Value = entry
+		c.order.MoveToFront(element)
+		return
+	}
+	c.entries[entry.key] = c.order.PushFront(entry)
+	for c.order.Len() > c.maxEntries {
+		oldest := c.order.Back()
+		c.order.Remove(oldest)
+		delete(c.entries, oldest.Value.(*cachedResponse).key)
+	}
+}
+
+func (c *ResponseCache) drop(key string) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	c.misses++
+	if element, ok := c.entries[key]; ok {
+		c.order.Remove(element)
+		delete(c.entries, key)
+	}
+}
+
+func (c *ResponseCache) hit() {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	c.hits++
+}
+
+// middleware returns the middleware revalidating and keeping the responses.
+// It must be the outermost middleware, so that the others see the
+// conditional requests and the 304 responses actually exchanged with MAAS.
+func (c *ResponseCache) middleware() Middleware {
+	return func(next Doer) Doer {
+		return DoerFunc(func(request *http.Request) (*http.Response, error) {
+			if request.Method != http.MethodGet {
+				return next.Do(request)
+			}
+			key := responseCacheKey(request)
+			entry := c.lookup(key)
+			if entry != nil {
+				// The request is already signed, which doesn't cover
+				// the headers, so a copy can be made conditional.
+				request = request.Clone(request.Context())
+				if entry.etag != "" {
+					request.Header.Set("If-None-Match", entry.etag)
+				}
+				if entry.lastModified != "" {
+					request.Header.Set("If-Modified-Since", entry.lastModified)
+				}
+			}
+			response, err := next.Do(request)
+			if err != nil {
+				return nil, err
+			}
+			if response.StatusCode == http.StatusNotModified && entry != nil {
+				response.Body.Close()
+				c.hit()
+				return entry.response(request), nil
+			}
+			if response.StatusCode != http.StatusOK {
+				return response, nil
+			}
+			etag := response.Header.Get("ETag")
+			lastModified := response.Header.Get("Last-Modified")
+			if etag == "" && lastModified == "" {
+				c.drop(key)
+				return response, nil
+			}
+			return c.keep(key, etag, lastModified, response)
+		})
+	}
+}
+
+// keep stores the response if its body isn't too large. Only the first
+// MaxBodySize + 1 bytes are read, so larger bodies are still streamed.
+func (c *ResponseCache) keep(key, etag, lastModified string, response *http.Response) (*http.Response, error) {
+	if response.ContentLength > c.maxBodySize {
+		c.drop(key)
+		return response, nil
+	}
+	body, err := io.ReadAll(io.LimitReader(response.Body, c.maxBodySize+1))
+	if err != nil {
+		response.Body.Close()
+		return nil, errors.Trace(err)
+	}
+	if int64(len(body)) > c.maxBodySize {
+		c.drop(key)
+		response.Body = readCloser{
+			Reader: io.MultiReader(bytes.NewReader(body), response.Body),
+			Closer: response.Body,
+		}
+		return response, nil
+	}
+	response.Body.Close()
+	c.store(&cachedResponse{
+		key:          key,
+		etag:         etag,
+		lastModified: lastModified,
+		header:       response.Header.Clone(),
+		body:         body,
+	})
+	response.Body = io.NopCloser(bytes.NewReader(body))
+	return response, nil
+}
+
+// responseCacheKey returns the key the response to the signed request is
+// kept by: its URL, along with the identity of its credentials.
+func responseCacheKey(request *http.Request) string {
+	return credentialIdentity(request.Header.Get("Authorization")) + " " + request.URL.String()
+}
+
+// credentialIdentity returns the consumer and token keys of an OAuth
+// Authorization header. Other headers are returned whole, as nothing else
+// tells their credentials apart.
+func credentialIdentity(authorization string) string {
+	params, ok := strings.CutPrefix(authorization, "OAuth ")
+	if !ok {
+		return authorization
+	}
+	var consumerKey, tokenKey string
+	for _, param := range strings.Split(params, ",") {
+		name, value, _ := strings.Cut(strings.TrimSpace(param), "=")
+		switch name {
+		case "oauth_consumer_key":
+			consumerKey = value
+		case "oauth_token":
+			tokenKey = value
+		}
+	}
+	if consumerKey == "" && tokenKey == "" {
+		return authorization
+	}
+	return consumerKey + ":" + tokenKey
+}
+
+// response returns a new 200 response with the kept header and body.
+func (r *cachedResponse) response(request *http.Request) *http.Response {
+	header := r.header.Clone()
+	header.Set("Content-Length", strconv.Itoa(len(r.body)))
+	return &http.Response{
+		Status:        "200 OK",
+		StatusCode:    http.StatusOK,
+		Proto:         "HTTP/1.1",
+		ProtoMajor:    1,
+		ProtoMinor:    1,
+		Header:        header,
+		Body:          io.NopCloser(bytes.NewReader(r.body)),
+		ContentLength: int64(len(r.body)),
+		Request:       request,
+	}
+}
+
+type readCloser struct {
+	io.Reader
+	io.Closer
+}
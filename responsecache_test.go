@@ -0,0 +1,273 @@
+// Copyright 2022 Canonical Ltd.
+// Licensed under the LGPLv3, see LICENCE file for details.
+
+package gomaasapi
+
+import (
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"net/url"
+	"strings"
+
+	"github.com/juju/errors"
+	"github.com/juju/testing"
+	jc "github.com/juju/testing/checkers"
+	gc "gopkg.in/check.v1"
+)
+
+type responseCacheSuite struct {
+	testing.LoggingCleanupSuite
+}
+
+var _ = gc.Suite(&responseCacheSuite{})
+
+// etagServer serves body with an ETag derived from its version, answering
+// 304 to requests that already have that version.
+type etagServer struct {
+	*httptest.Server
+	version     int
+	body        string
+	conditional []string
+}
+
+func newETagServer(body string) *etagServer {
+	server := &etagServer{version: 1, body: body}
+	server.Server = httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		etag := fmt.Sprintf(`"v%d"`, server.version)
+		server.conditional = append(server.conditional, r.Header.Get("If-None-Match"))
+		w.Header().Set("ETag", etag)
+		if r.Header.Get("If-None-Match") == etag {
+			w.WriteHeader(http.StatusNotModified)
+			return
+		}
+		fmt.Fprint(w, server.body)
+	}))
+	return server
+}
+
+func (s *responseCacheSuite) newClient(c *gc.C, server *etagServer, cache *ResponseCache) *Client {
+	client, err := NewAnonymousClient(server.URL, "1.0")
+	c.Assert(err, jc.ErrorIsNil)
+	client.AddMiddleware(cache.middleware())
+	return client
+}
+
+func (s *responseCacheSuite) TestValidate(c *gc.C) {
+	_, err := NewResponseCache(ResponseCacheArgs{MaxEntries: -1})
+	c.Check(err, jc.Satisfies, errors.IsNotValid)
+	_, err = NewResponseCache(ResponseCacheArgs{MaxBodySize: -1})
+	c.Check(err, jc.Satisfies, errors.IsNotValid)
+}
+
+func (s *responseCacheSuite) TestRevalidates(c *gc.C) {
+	server := newETagServer("first")
+	defer server.Close()
+	cache, err := NewResponseCache(ResponseCacheArgs{})
+	c.Assert(err, jc.ErrorIsNil)
+	client := s.newClient(c, server, cache)
+	uri := &url.URL{Path: "/some/url/"}
+
+	for i := 0; i < 2; i++ {
+		content, err := client.Get(uri, "", nil)
+		c.Assert(err, jc.ErrorIsNil)
+		c.Check(string(content), gc.Equals, "first")
+	}
+	c.Check(server.conditional, jc.DeepEquals, []string{"", `"v1"`})
+	c.Check(cache.Stats(), jc.DeepEquals, ResponseCacheStats{Entries: 1, Hits: 1, Misses: 1})
+
+	// A changed resource is returned and replaces the kept response.
+	server.version, server.body = 2, "second"
+	for i := 0; i < 2; i++ {
+		content, err := client.Get(uri, "", nil)
+		c.Assert(err, jc.ErrorIsNil)
+		c.Check(string(content), gc.Equals, "second")
+	}
+	c.Check(server.conditional, jc.DeepEquals, []string{"", `"v1"`, `"v1"`, `"v2"`})
+	c.Check(cache.Stats(), jc.DeepEquals, ResponseCacheStats{Entries: 1, Hits: 2, Misses: 2})
+}
+
+func (s *responseCacheSuite) TestKeyedByQuery(c *gc.C) {
+	server := newETagServer("body")
+	defer server.Close()
+	cache, err := NewResponseCache(ResponseCacheArgs{})
+	c.Assert(err, jc.ErrorIsNil)
+	client := s.newClient(c, server, cache)
+	uri := &url.URL{Path: "/some/url/"}
+
+	_, err = client.Get(uri, "op", url.Values{"a": {"1"}})
+	c.Assert(err, jc.ErrorIsNil)
+	_, err = client.Get(uri, "op", url.Values{"a": {"2"}})
+	c.Assert(err, jc.ErrorIsNil)
+	c.Check(server.conditional, jc.DeepEquals, []string{"", ""})
+	c.Check(cache.Stats().Entries, gc.Equals, 2)
+}
+
+func (s *responseCacheSuite) TestKeyedByCredentials(c *gc.C) {
+	server := newETagServer("body")
+	defer server.Close()
+	cache, err := NewResponseCache(ResponseCacheArgs{})
+	c.Assert(err, jc.ErrorIsNil)
+	var clients []*Client
+	for _, apiKey := range []string{"ck:tk1:ts", "ck:tk2:ts", "ck:tk1:ts"} {
+		client, err := NewAuthenticatedClient(server.URL+"/api/1.0/", apiKey)
+		c.Assert(err, jc.ErrorIsNil)
+		client.AddMiddleware(cache.middleware())
+		clients = append(clients, client)
+	}
+	uri := &url.URL{Path: "/some/url/"}
+
+	for _, client := range clients {
+		_, err := client.Get(uri, "", nil)
+		c.Assert(err, jc.ErrorIsNil)
+	}
+	// The response fetched with the first token isn't used for the
+	// second, but is for the first again.
+	c.Check(server.conditional, jc.DeepEquals, []string{"", "", `"v1"`})
+	c.Check(cache.Stats(), jc.DeepEquals, ResponseCacheStats{Entries: 2, Hits: 1, Misses: 2})
+}
+
+func (s *responseCacheSuite) TestOnlyGET(c *gc.C) {
+	server := newETagServer("body")
+	defer server.Close()
+	cache, err := NewResponseCache(ResponseCacheArgs{})
+	c.Assert(err, jc.ErrorIsNil)
+	client := s.newClient(c, server, cache)
+	uri := &url.URL{Path: "/some/url/"}
+
+	for i := 0; i < 2; i++ {
+		_, err = client.Post(uri, "op", nil, nil)
+		c.Assert(err, jc.ErrorIsNil)
+	}
+	c.Check(server.conditional, jc.DeepEquals, []string{"", ""})
+	c.Check(cache.Stats(), jc.DeepEquals, ResponseCacheStats{})
+}
+
+func (s *responseCacheSuite) TestEvictsLeastRecentlyUsed(c *gc.C) {
+	server := newETagServer("body")
+	defer server.Close()
+	cache, err := NewResponseCache(ResponseCacheArgs{MaxEntries: 2})
+	c.Assert(err, jc.ErrorIsNil)
+	client := s.newClient(c, server, cache)
+
+	for _, path := range []string{"/a/", "/b/", "/a/", "/c/", "/a/", "/b/"} {
+		_, err := client.Get(&url.URL{Path: path}, "", nil)
+		c.Assert(err, jc.ErrorIsNil)
+	}
+	// /b/ was dropped when /c/ was kept, /a/ being used more recently.
+	c.Check(server.conditional, jc.DeepEquals, []string{"", "", `"v1"`, "", `"v1"`, ""})
+	c.Check(cache.Stats().Entries, gc.Equals, 2)
+}
+
+func (s *responseCacheSuite) TestLargeBodyNotKept(c *gc.C) {
+	body := strings.Repeat("x", 100)
+	server := newETagServer(body)
+	defer server.Close()
+	cache, err := NewResponseCache(ResponseCacheArgs{MaxBodySize: 10})
+	c.Assert(err, jc.ErrorIsNil)
+	client := s.newClient(c, server, cache)
+	uri := &url.URL{Path: "/some/url/"}
+
+	for i := 0; i < 2; i++ {
+		content, err := client.Get(uri, "", nil)
+		c.Assert(err, jc.ErrorIsNil)
+		c.Check(string(content), gc.Equals, body)
+	}
+	c.Check(server.conditional, jc.DeepEquals, []string{"", ""})
+	c.Check(cache.Stats(), jc.DeepEquals, ResponseCacheStats{Misses: 2})
+}
+
+func (s *responseCacheSuite) TestNoValidators(c *gc.C) {
+	cache, err := NewResponseCache(ResponseCacheArgs{})
+	c.Assert(err, jc.ErrorIsNil)
+	server := newFlakyServer("/some/url/", http.StatusOK, 0)
+	defer server.Close()
+	client, err := NewAnonymousClient(server.URL, "1.0")
+	c.Assert(err, jc.ErrorIsNil)
+	client.AddMiddleware(cache.middleware())
+
+	_, err = client.Get(&url.URL{Path: "/some/url/"}, "", nil)
+	c.Assert(err, jc.ErrorIsNil)
+	c.Check(cache.Stats(), jc.DeepEquals, ResponseCacheStats{Misses: 1})
+}
+
+func (s *responseCacheSuite) TestClear(c *gc.C) {
+	server := newETagServer("body")
+	defer server.Close()
+	cache, err := NewResponseCache(ResponseCacheArgs{})
+	c.Assert(err, jc.ErrorIsNil)
+	client := s.newClient(c, server, cache)
+	uri := &url.URL{Path: "/some/url/"}
+
+	_, err = client.Get(uri, "", nil)
+	c.Assert(err, jc.ErrorIsNil)
+	cache.Clear()
+	_, err = client.Get(uri, "", nil)
+	c.Assert(err, jc.ErrorIsNil)
+	c.Check(server.conditional, jc.DeepEquals, []string{"", ""})
+}
+
+func (s *responseCacheSuite) TestMiddlewareSeesNotModified(c *gc.C) {
+	server := newETagServer("body")
+	defer server.Close()
+	cache, err := NewResponseCache(ResponseCacheArgs{})
+	c.Assert(err, jc.ErrorIsNil)
+	client := s.newClient(c, server, cache)
+	var statuses []int
+	client.AddMiddleware(func(next Doer) Doer {
+		return DoerFunc(func(request *http.Request) (*http.Response, error) {
+			response, err := next.Do(request)
+			if err == nil {
+				statuses = append(statuses, response.StatusCode)
+			}
+			return response, err
+		})
+	})
+	uri := &url.URL{Path: "/some/url/"}
+
+	for i := 0; i < 2; i++ {
+		content, err := client.Get(uri, "", nil)
+		c.Assert(err, jc.ErrorIsNil)
+		c.Check(string(content), gc.Equals, "body")
+	}
+	c.Check(statuses, jc.DeepEquals, []int{200, 304})
+}
+
+func (s *responseCacheSuite) TestController(c *gc.C) {
+	var conditional []string
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		switch r.URL.Path {
+		case "/api/2.0/users/":
+			fmt.Fprint(w, `"captain awesome"`)
+		case "/api/2.0/version/":
+			fmt.Fprint(w, versionResponse)
+		case "/api/2.0/zones/":
+			conditional = append(conditional, r.Header.Get("If-None-Match"))
+			w.Header().Set("ETag", `"zones"`)
+			if r.Header.Get("If-None-Match") == `"zones"` {
+				w.WriteHeader(http.StatusNotModified)
+				return
+			}
+			fmt.Fprint(w, zoneResponse)
+		default:
+			http.NotFound(w, r)
+		}
+	}))
+	defer server.Close()
+	cache, err := NewResponseCache(ResponseCacheArgs{})
+	c.Assert(err, jc.ErrorIsNil)
+	maas, err := NewController(ControllerArgs{
+		BaseURL:       server.URL,
+		APIKey:        "fake:as:key",
+		ResponseCache: cache,
+	})
+	c.Assert(err, jc.ErrorIsNil)
+
+	for i := 0; i < 2; i++ {
+		zones, err := maas.Zones()
+		c.Assert(err, jc.ErrorIsNil)
+		c.Check(zones, gc.HasLen, 2)
+	}
+	c.Check(conditional, jc.DeepEquals, []string{"", `"zones"`})
+	c.Check(cache.Stats().Hits, gc.Equals, uint64(1))
+}
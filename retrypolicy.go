@@ -0,0 +1,151 @@
+// Copyright 2022 Canonical Ltd.
+// Licensed under the LGPLv3, see LICENCE file for details.
+
+package gomaasapi
+
+import (
+	"math"
+	"math/rand"
+	"net/http"
+	"strconv"
+	"time"
+
+	"github.com/juju/errors"
+)
+
+// RetryPolicy defines which failed requests the client sends again, and how
+// long it waits before doing so.
+//
+// A 503 response with a Retry-After header is always retried after the wait
+// requested by the server. Responses with one of the StatusCodes are retried
+// after the Retry-After wait if the header is set, and after an exponential
+// backoff otherwise. Requests are retried regardless of their method, so only
+// status codes that mean the server didn't act on the request should be
+// listed.
+type RetryPolicy struct {
+	// MaxAttempts is the number of times a request is sent at most. If it
+	// is zero, requests are sent at most NumberOfRetries + 1 times.
+	MaxAttempts int
+	// Backoff is the wait before the first retry of a response without a
+	// Retry-After header. It doubles with each following retry.
+	Backoff time.Duration
+	// MaxBackoff, if set, limits the wait computed from Backoff.
+	MaxBackoff time.Duration
+	// Jitter is the fraction of the backoff, between 0 and 1, that is
+	// randomly taken off each wait, so that clients don't retry in step.
+	Jitter float64
+	// StatusCodes are the response status codes that are retried, in
+	// addition to 503 responses with a Retry-After header.
+	StatusCodes []int
+}
+
+// DefaultRetryPolicy is used by clients that don't set a RetryPolicy. It
+// only retries 503 responses with a Retry-After header.
+var DefaultRetryPolicy = RetryPolicy{MaxAttempts: NumberOfRetries + 1}
+
+// retryJitter returns the random fraction of the jitter applied to a wait.
+// It is a variable so tests can patch it.
+var retryJitter = rand.Float64
+
+// Validate checks the values of the policy are in range.
+func (p RetryPolicy) Validate() error {
+	if p.MaxAttempts < 0 {
+		return errors.NotValidf("negative MaxAttempts")
+	}
+	if p.Backoff < 0 {
+		return errors.NotValidf("negative Backoff")
+	}
+	if p.MaxBackoff < 0 {
+		return errors.NotValidf("negative MaxBackoff")
+	}
+	if p.Jitter < 0 || p.Jitter > 1 {
+		return errors.NotValidf("Jitter %v", p.Jitter)
+	}
+	return nil
+}
+
+func (p RetryPolicy) maxAttempts() int {
+	if p.MaxAttempts == 0 {
+		return NumberOfRetries + 1
+	}
+	return p.MaxAttempts
+}
+
+// retryWait returns how long to wait before sending again a request that
+// failed with err on the given attempt, starting from 1, and false if the
+// request shouldn't be retried.
+func (p RetryPolicy) retryWait(attempt int, err error) (time.Duration, bool) {
+	if attempt >= p.maxAttempts() {
+		return 0, false
+	}
+	serverError, ok := errors.Cause(err).(ServerError)
+	if !ok {
+		return 0, false
+	}
+	retryable := p.retryable(serverError.StatusCode)
+	if serverError.StatusCode == http.StatusServiceUnavailable || retryable {
+		if wait, ok := retryAfter(serverError.Header); ok {
+			return wait, true
+		}
+	}
+	if !retryable {
+		return 0, false
+	}
+	return p.backoff(attempt), true
+}
+
+func (p RetryPolicy) retryable(statusCode int) bool {
+	for _, code := range p.StatusCodes {
+		if code == statusCode {
+			return true
+		}
+	}
+	return false
+}
+
+// backoff returns the exponential backoff before the retry that follows the
+// given attempt.
+func (p RetryPolicy) backoff(attempt int) time.Duration {
+	wait := p.Backoff
+	for i := 1; i < attempt && wait > 0; i++ {
+		if p.MaxBackoff > 0 && wait >= p.MaxBackoff {
+			break
+		}
+		// Stop doubling before the wait overflows.
+		if wait > math.MaxInt64/2 {
+			break
+		}
+		wait *= 2
+	}
+	if p.MaxBackoff > 0 && wait > p.MaxBackoff {
+		wait = p.MaxBackoff
+	}
+	if p.Jitter > 0 {
+		wait -= time.Duration(p.Jitter * retryJitter() * float64(wait))
+	}
+	return wait
+}
+
+// retryAfter returns the wait requested by a Retry-After header, which is
+// either a number of seconds or an HTTP date.
+func retryAfter(header http.Header) (time.Duration, bool) {
+	value := header.Get(RetryAfterHeaderName)
+	if value == "" {
+		return 0, false
+	}
+	if seconds, err := strconv.Atoi(value); err == nil {
+		if seconds < 0 {
+			return 0, false
+		}
+		return time.Duration(seconds) * time.Second, true
+	}
+	when, err := http.ParseTime(value)
+	if err != nil {
+		return 0, false
+	}
+	wait := time.Until(when)
+	if wait < 0 {
+		wait = 0
+	}
+	return wait, true
+}
@@ -0,0 +1,126 @@
+// Copyright 2022 Canonical Ltd.
+// Licensed under the LGPLv3, see LICENCE file for details.
+
+package gomaasapi
+
+import (
+	"net/http"
+	"time"
+
+	"github.com/juju/errors"
+	"github.com/juju/testing"
+	jc "github.com/juju/testing/checkers"
+	gc "gopkg.in/check.v1"
+)
+
+type retryPolicySuite struct {
+	testing.LoggingCleanupSuite
+}
+
+var _ = gc.Suite(&retryPolicySuite{})
+
+func serverErrorWithStatus(status int, retryAfter string) error {
+	header := http.Header{}
+	if retryAfter != "" {
+		header.Set(RetryAfterHeaderName, retryAfter)
+	}
+	return errors.Trace(ServerError{
+		error:      errors.New("boom"),
+		StatusCode: status,
+		Header:     header,
+	})
+}
+
+func (*retryPolicySuite) TestValidate(c *gc.C) {
+	c.Check(RetryPolicy{}.Validate(), jc.ErrorIsNil)
+	c.Check(DefaultRetryPolicy.Validate(), jc.ErrorIsNil)
+	for i, policy := range []RetryPolicy{
+		{MaxAttempts: -1},
+		{Backoff: -time.Second},
+		{MaxBackoff: -time.Second},
+		{Jitter: -0.1},
+		{Jitter: 1.5},
+	} {
+		c.Logf("test %d", i)
+		c.Check(policy.Validate(), jc.Satisfies, errors.IsNotValid)
+	}
+}
+
+func (*retryPolicySuite) TestDefaultRetriesOnlyRetryAfter(c *gc.C) {
+	policy := DefaultRetryPolicy
+	wait, ok := policy.retryWait(1, serverErrorWithStatus(http.StatusServiceUnavailable, "3"))
+	c.Check(ok, jc.IsTrue)
+	c.Check(wait, gc.Equals, 3*time.Second)
+
+	_, ok = policy.retryWait(1, serverErrorWithStatus(http.StatusServiceUnavailable, ""))
+	c.Check(ok, jc.IsFalse)
+	_, ok = policy.retryWait(1, serverErrorWithStatus(http.StatusBadGateway, "3"))
+	c.Check(ok, jc.IsFalse)
+	_, ok = policy.retryWait(1, errors.New("connection refused"))
+	c.Check(ok, jc.IsFalse)
+}
+
+func (*retryPolicySuite) TestMaxAttempts(c *gc.C) {
+	err := serverErrorWithStatus(http.StatusServiceUnavailable, "0")
+	_, ok := DefaultRetryPolicy.retryWait(NumberOfRetries, err)
+	c.Check(ok, jc.IsTrue)
+	_, ok = DefaultRetryPolicy.retryWait(NumberOfRetries+1, err)
+	c.Check(ok, jc.IsFalse)
+
+	policy := RetryPolicy{MaxAttempts: 1}
+	_, ok = policy.retryWait(1, err)
+	c.Check(ok, jc.IsFalse)
+}
+
+func (*retryPolicySuite) TestStatusCodesBackoff(c *gc.C) {
+	policy := RetryPolicy{
+		MaxAttempts: 10,
+		Backoff:     time.Second,
+		MaxBackoff:  5 * time.Second,
+		StatusCodes: []int{http.StatusBadGateway, http.StatusServiceUnavailable},
+	}
+	for i, expected := range []time.Duration{
+		time.Second, 2 * time.Second, 4 * time.Second, 5 * time.Second, 5 * time.Second,
+	} {
+		c.Logf("attempt %d", i+1)
+		wait, ok := policy.retryWait(i+1, serverErrorWithStatus(http.StatusBadGateway, ""))
+		c.Check(ok, jc.IsTrue)
+		c.Check(wait, gc.Equals, expected)
+	}
+	// Retry-After takes precedence over the backoff.
+	wait, ok := policy.retryWait(3, serverErrorWithStatus(http.StatusServiceUnavailable, "1"))
+	c.Check(ok, jc.IsTrue)
+	c.Check(wait, gc.Equals, time.Second)
+
+	_, ok = policy.retryWait(1, serverErrorWithStatus(http.StatusInternalServerError, ""))
+	c.Check(ok, jc.IsFalse)
+}
+
+func (*retryPolicySuite) TestBackoffDoesntOverflow(c *gc.C) {
+	policy := RetryPolicy{Backoff: time.Hour}
+	c.Check(policy.backoff(100) > 0, jc.IsTrue)
+}
+
+func (s *retryPolicySuite) TestJitter(c *gc.C) {
+	s.PatchValue(&retryJitter, func() float64 { return 0.5 })
+	policy := RetryPolicy{Backoff: 4 * time.Second, Jitter: 0.5}
+	c.Check(policy.backoff(1), gc.Equals, 3*time.Second)
+	c.Check(policy.backoff(2), gc.Equals, 6*time.Second)
+}
+
+func (*retryPolicySuite) TestRetryAfterDate(c *gc.C) {
+	when := time.Now().Add(time.Minute).UTC().Format(http.TimeFormat)
+	wait, ok := retryAfter(http.Header{RetryAfterHeaderName: {when}})
+	c.Check(ok, jc.IsTrue)
+	c.Check(wait > 50*time.Second && wait <= time.Minute, jc.IsTrue)
+
+	past := time.Now().Add(-time.Minute).UTC().Format(http.TimeFormat)
+	wait, ok = retryAfter(http.Header{RetryAfterHeaderName: {past}})
+	c.Check(ok, jc.IsTrue)
+	c.Check(wait, gc.Equals, time.Duration(0))
+
+	_, ok = retryAfter(http.Header{RetryAfterHeaderName: {"soon"}})
+	c.Check(ok, jc.IsFalse)
+	_, ok = retryAfter(http.Header{RetryAfterHeaderName: {"-1"}})
+	c.Check(ok, jc.IsFalse)
+}
@@ -0,0 +1,123 @@
+// Copyright 2022 Canonical Ltd.
+// Licensed under the LGPLv3, see LICENCE file for details.
+
+package gomaasapi
+
+import (
+	"time"
+
+	"github.com/juju/schema"
+	"github.com/juju/version"
+)
+
+type scanResult struct {
+	result            string
+	startedOn         []string
+	attemptedOn       []string
+	failedOn          []string
+	failedToConnectTo []string
+	rpcErrors         map[string]string
+
+	requested time.Time
+}
+
+// Result implements ScanResult.
+func (r *scanResult) Result() string {
+	return r.result
+}
+
+// StartedOn implements ScanResult.
+func (r *scanResult) StartedOn() []string {
+	return r.startedOn
+}
+
+// AttemptedOn implements ScanResult.
+func (r *scanResult) AttemptedOn() []string {
+	return r.attemptedOn
+}
+
+// FailedOn implements ScanResult.
+func (r *scanResult) FailedOn() []string {
+	return r.failedOn
+}
+
+// FailedToConnectTo implements ScanResult.
+func (r *scanResult) FailedToConnectTo() []string {
+	return r.failedToConnectTo
+}
+
+// RPCErrors implements ScanResult.
+func (r *scanResult) RPCErrors() map[string]string {
+	result := make(map[string]string, len(r.rpcErrors))
+	for key, value := range r.rpcErrors {
+		result[key] = value
+	}
+	return result
+}
+
+// Requested implements ScanResult.
+func (r *scanResult) Requested() time.Time {
+	return r.requested
+}
+
+func readScanResult(controllerVersion version.Number, source interface{}) (*scanResult, error) {
+	var deserialisationVersion version.Number
+	for v := range scanResultDeserializationFuncs {
+		if v.Compare(deserialisationVersion) > 0 && v.Compare(controllerVersion) <= 0 {
+			deserialisationVersion = v
+		}
+	}
+	if deserialisationVersion == version.Zero {
+		return nil, NewUnsupportedVersionError("no scan result read func for version %s", controllerVersion)
+	}
+
+	checker := schema.StringMap(schema.Any())
+	coerced, err := checker.Coerce(source, nil)
+	if err != nil {
+		return nil, WrapWithDeserializationError(err, "scan result base schema check failed")
+	}
+	valid := coerced.(map[string]interface{})
+	return scanResultDeserializationFuncs[deserialisationVersion](valid)
+}
+
+type scanResultDeserializationFunc func(map[string]interface{}) (*scanResult, error)
+
+var scanResultDeserializationFuncs = map[version.Number]scanResultDeserializationFunc{
+	twoDotOh: scanResult_2_0,
+}
+
+func scanResult_2_0(source map[string]interface{}) (*scanResult, error) {
+	fields := schema.Fields{
+		"result":               schema.String(),
+		"scan_started_on":      schema.List(schema.String()),
+		"scan_attempted_on":    schema.List(schema.String()),
+		"scan_failed":          schema.List(schema.String()),
+		"failed_to_connect_to": schema.List(schema.String()),
+		"rpc_errors":           schema.StringMap(schema.String()),
+	}
+	defaults := schema.Defaults{
+		"scan_started_on":      []interface{}{},
+		"scan_attempted_on":    []interface{}{},
+		"scan_failed":          []interface{}{},
+		"failed_to_connect_to": []interface{}{},
+		"rpc_errors":           map[string]interface{}{},
+	}
+	checker := schema.FieldMap(fields, defaults)
+	coerced, err := checker.Coerce(source, nil)
+	if err != nil {
+		return nil, WrapWithDeserializationError(err, "scan result 2.0 schema check failed")
+	}
+	valid := coerced.(map[string]interface{})
+	// From here we know that the map returned from the schema coercion
+	// contains fields of the right type.
+
+	result := &scanResult{
+		result:            valid["result"].(string),
+		startedOn:         convertToStringSlice(valid["scan_started_on"]),
+		attemptedOn:       convertToStringSlice(valid["scan_attempted_on"]),
+		failedOn:          convertToStringSlice(valid["scan_failed"]),
+		failedToConnectTo: convertToStringSlice(valid["failed_to_connect_to"]),
+		rpcErrors:         convertToStringMap(valid["rpc_errors"]),
+	}
+	return result, nil
+}
@@ -0,0 +1,56 @@
+// Copyright 2022 Canonical Ltd.
+// Licensed under the LGPLv3, see LICENCE file for details.
+
+package gomaasapi
+
+import (
+	jc "github.com/juju/testing/checkers"
+	"github.com/juju/version"
+	gc "gopkg.in/check.v1"
+)
+
+type scanResultSuite struct{}
+
+var _ = gc.Suite(&scanResultSuite{})
+
+func (*scanResultSuite) TestReadScanResultBadSchema(c *gc.C) {
+	_, err := readScanResult(twoDotOh, "wat?")
+	c.Check(err, jc.Satisfies, IsDeserializationError)
+	c.Assert(err.Error(), gc.Equals, `scan result base schema check failed: expected map, got string("wat?")`)
+}
+
+func (*scanResultSuite) TestReadScanResult(c *gc.C) {
+	result, err := readScanResult(twoDotOh, parseJSON(c, scanResultResponse))
+	c.Assert(err, jc.ErrorIsNil)
+	c.Check(result.Result(), gc.Equals, "Active discovery scan started on rack controller: 4y3h7n.")
+	c.Check(result.StartedOn(), jc.DeepEquals, []string{"4y3h7n"})
+	c.Check(result.AttemptedOn(), jc.DeepEquals, []string{"4y3h7n", "xc3e6q"})
+	c.Check(result.FailedOn(), jc.DeepEquals, []string{})
+	c.Check(result.FailedToConnectTo(), jc.DeepEquals, []string{"xc3e6q"})
+	c.Check(result.RPCErrors(), jc.DeepEquals, map[string]string{"xc3e6q": "connection refused"})
+}
+
+func (*scanResultSuite) TestReadScanResultMinimal(c *gc.C) {
+	result, err := readScanResult(twoDotOh, map[string]interface{}{
+		"result": "Unable to run network discovery; no suitable interfaces.",
+	})
+	c.Assert(err, jc.ErrorIsNil)
+	c.Check(result.StartedOn(), gc.HasLen, 0)
+	c.Check(result.RPCErrors(), gc.HasLen, 0)
+}
+
+func (*scanResultSuite) TestLowVersion(c *gc.C) {
+	_, err := readScanResult(version.MustParse("1.9.0"), parseJSON(c, scanResultResponse))
+	c.Assert(err, jc.Satisfies, IsUnsupportedVersionError)
+}
+
+const scanResultResponse = `
+{
+    "result": "Active discovery scan started on rack controller: 4y3h7n.",
+    "scan_started_on": ["4y3h7n"],
+    "scan_attempted_on": ["4y3h7n", "xc3e6q"],
+    "scan_failed": [],
+    "failed_to_connect_to": ["xc3e6q"],
+    "rpc_errors": {"xc3e6q": "connection refused"}
+}
+`
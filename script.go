@@ -0,0 +1,623 @@
+// Copyright 2022 Canonical Ltd.
+// Licensed under the LGPLv3, see LICENCE file for details.
+
+package gomaasapi
+
+import (
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"regexp"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/juju/errors"
+	"github.com/juju/schema"
+	"github.com/juju/version"
+)
+
+// The types of scripts, which decide when they are run.
+const (
+	ScriptTypeCommissioning = "commissioning"
+	ScriptTypeTesting       = "testing"
+	ScriptTypeRelease       = "release"
+)
+
+// scriptTypes are the names of the types of scripts, which MAAS reports by
+// number.
+var scriptTypes = map[int]string{
+	0: ScriptTypeCommissioning,
+	2: ScriptTypeTesting,
+	3: ScriptTypeRelease,
+}
+
+// The types of hardware that scripts are for.
+const (
+	HardwareTypeNode    = "node"
+	HardwareTypeCPU     = "cpu"
+	HardwareTypeMemory  = "memory"
+	HardwareTypeStorage = "storage"
+	HardwareTypeNetwork = "network"
+	HardwareTypeGPU     = "gpu"
+)
+
+// hardwareTypes are the names of the types of hardware, which MAAS reports
+// by number.
+var hardwareTypes = map[int]string{
+	0: HardwareTypeNode,
+	1: HardwareTypeCPU,
+	2: HardwareTypeMemory,
+	3: HardwareTypeStorage,
+	4: HardwareTypeNetwork,
+	5: HardwareTypeGPU,
+}
+
+// ScriptRevision is an uploaded version of a script.
+type ScriptRevision struct {
+	ID      int
+	Comment string
+	// Created is when the revision was uploaded, or the zero time if MAAS
+	// didn't report it.
+	Created time.Time
+}
+
+type scriptRevision struct {
+	id      int
+	comment string
+	created string
+}
+
+type script struct {
+	controller *controller
+
+	resourceURI string
+
+	id           int
+	name         string
+	title        string
+	description  string
+	tags         []string
+	type_        string
+	hardwareType string
+	timeout      time.Duration
+	destructive  bool
+	default_     bool
+	parameters   map[string]interface{}
+	history      []scriptRevision
+}
+
+func (s *script) updateFrom(other *script) {
+	s.resourceURI = other.resourceURI
+	s.id = other.id
+	s.name = other.name
+	s.title = other.title
+	s.description = other.description
+	s.tags = other.tags
+	s.type_ = other.type_
+	s.hardwareType = other.hardwareType
+	s.timeout = other.timeout
+	s.destructive = other.destructive
+	s.default_ = other.default_
+	s.parameters = other.parameters
+	s.history = other.history
+}
+
+// ID implements Script.
+func (s *script) ID() int {
+	return s.id
+}
+
+// Name implements Script.
+func (s *script) Name() string {
+	return s.name
+}
+
+// Title implements Script.
+func (s *script) Title() string {
+	return s.title
+}
+
+// Description implements Script.
+func (s *script) Description() string {
+	return s.description
+}
+
+// Tags implements Script.
+func (s *script) Tags() []string {
+	return s.tags
+}
+
+// Type implements Script.
+func (s *script) Type() string {
+	return s.type_
+}
+
+// HardwareType implements Script.
+func (s *script) HardwareType() string {
+	return s.hardwareType
+}
+
+// Timeout implements Script.
+func (s *script) Timeout() time.Duration {
+	return s.timeout
+}
+
+// Destructive implements Script.
+func (s *script) Destructive() bool {
+	return s.destructive
+}
+
+// Default implements Script.
+func (s *script) Default() bool {
+	return s.default_
+}
+
+// Parameters implements Script.
+func (s *script) Parameters() map[string]interface{} {
+	return s.parameters
+}
+
+// History implements Script.
+func (s *script) History() []ScriptRevision {
+	var location *time.Location
+	if s.controller != nil {
+		location = s.controller.timeLocation
+	}
+	result := make([]ScriptRevision, len(s.history))
+	for i, revision := range s.history {
+		result[i] = ScriptRevision{ID: revision.id, Comment: revision.comment}
+		if revision.created == "" {
+			continue
+		}
+		created, err := parseTimestamp(revision.created, location)
+		if err != nil {
+			logger.Debugf("script %s revision %d: %v", s.name, revision.id, err)
+			continue
+		}
+		result[i].Created = created
+	}
+	return result
+}
+
+// UpdateScriptArgs is an argument struct for Script.Update. Only the fields
+// that are set are changed.
+type UpdateScriptArgs struct {
+	Title       string
+	Description string
+	// Tags replaces the tags of the script.
+	Tags         []string
+	Type         string
+	HardwareType string
+	Timeout      time.Duration
+	// Destructive is whether the script erases the disks of the machine.
+	Destructive *bool
+	// Content uploads a new revision of the script. MAAS reads the
+	// parameters of the script from the metadata embedded in it.
+	Content []byte
+	// Comment describes the new revision.
+	Comment string
+}
+
+// Validate ensures that the timeout isn't negative.
+func (a *UpdateScriptArgs) Validate() error {
+	if a.Timeout < 0 {
+		return errors.NotValidf("Timeout %v", a.Timeout)
+	}
+	return nil
+}
+
+// Update implements Script.
+func (s *script) Update(args UpdateScriptArgs) error {
+	if err := args.Validate(); err != nil {
+		return errors.Trace(err)
+	}
+	params := NewURLParams()
+	params.MaybeAdd("title", args.Title)
+	params.MaybeAdd("description", args.Description)
+	params.MaybeAdd("tags", strings.Join(args.Tags, ","))
+	params.MaybeAdd("type", args.Type)
+	params.MaybeAdd("hardware_type", args.HardwareType)
+	params.MaybeAddInt("timeout", int(args.Timeout/time.Second))
+	if args.Destructive != nil {
+		params.Values.Add("destructive", fmt.Sprint(*args.Destructive))
+	}
+	params.MaybeAdd("comment", args.Comment)
+	var files map[string][]byte
+	if len(args.Content) > 0 {
+		files = map[string][]byte{"script": args.Content}
+	}
+	source, err := s.controller.putFiles(s.resourceURI, params.Values, files)
+	if err != nil {
+		if svrErr, ok := errors.Cause(err).(ServerError); ok {
+			switch svrErr.StatusCode {
+			case http.StatusNotFound:
+				return wrapError(err, NewNoMatchError(svrErr.BodyMessage))
+			case http.StatusForbidden:
+				return wrapError(err, NewPermissionError(svrErr.BodyMessage))
+			case http.StatusBadRequest:
+				return wrapError(err, NewBadRequestError(svrErr.BodyMessage))
+			}
+		}
+		return NewUnexpectedError(err)
+	}
+
+	response, err := readScript(s.controller.schemaVersion(), source)
+	if err != nil {
+		return errors.Trace(err)
+	}
+	s.updateFrom(response)
+	return nil
+}
+
+// Delete implements Script.
+func (s *script) Delete() error {
+	err := s.controller.delete(s.resourceURI)
+	if err != nil {
+		if svrErr, ok := errors.Cause(err).(ServerError); ok {
+			switch svrErr.StatusCode {
+			case http.StatusNotFound:
+				return wrapError(err, NewNoMatchError(svrErr.BodyMessage))
+			case http.StatusForbidden:
+				return wrapError(err, NewPermissionError(svrErr.BodyMessage))
+			case http.StatusBadRequest:
+				return wrapError(err, NewBadRequestError(svrErr.BodyMessage))
+			}
+		}
+		return NewUnexpectedError(err)
+	}
+	return nil
+}
+
+// Revert implements Script.
+func (s *script) Revert(to int) error {
+	if to == 0 {
+		return errors.NotValidf("revision 0")
+	}
+	params := NewURLParams()
+	params.Values.Add("to", fmt.Sprint(to))
+	source, err := s.controller.post(s.resourceURI, "revert", params.Values)
+	if err != nil {
+		if svrErr, ok := errors.Cause(err).(ServerError); ok {
+			switch svrErr.StatusCode {
+			case http.StatusNotFound:
+				return wrapError(err, NewNoMatchError(svrErr.BodyMessage))
+			case http.StatusForbidden:
+				return wrapError(err, NewPermissionError(svrErr.BodyMessage))
+			case http.StatusBadRequest:
+				return wrapError(err, NewBadRequestError(svrErr.BodyMessage))
+			}
+		}
+		return NewUnexpectedError(err)
+	}
+
+	response, err := readScript(s.controller.schemaVersion(), source)
+	if err != nil {
+		return errors.Trace(err)
+	}
+	s.updateFrom(response)
+	return nil
+}
+
+// Download implements Script.
+func (s *script) Download(revision int) ([]byte, error) {
+	params := NewURLParams()
+	params.MaybeAddInt("revision", revision)
+	content, err := s.controller._getRaw(s.resourceURI, "download", params.Values)
+	if err != nil {
+		if svrErr, ok := errors.Cause(err).(ServerError); ok {
+			switch svrErr.StatusCode {
+			case http.StatusNotFound:
+				return nil, wrapError(err, NewNoMatchError(svrErr.BodyMessage))
+			case http.StatusForbidden:
+				return nil, wrapError(err, NewPermissionError(svrErr.BodyMessage))
+			}
+		}
+		return nil, NewUnexpectedError(err)
+	}
+	return content, nil
+}
+
+// ScriptsArgs is an argument struct for selecting scripts. All the fields are
+// optional.
+type ScriptsArgs struct {
+	Type         string
+	HardwareType string
+	// Filters selects the scripts by name or tag.
+	Filters []string
+}
+
+// Scripts implements Controller.
+func (c *controller) Scripts(args ScriptsArgs) ([]Script, error) {
+	params := NewURLParams()
+	params.MaybeAdd("type", args.Type)
+	params.MaybeAdd("hardware_type", args.HardwareType)
+	params.MaybeAdd("filters", strings.Join(args.Filters, ","))
+	source, err := c.getList("scripts", params.Values, 0)
+	if err != nil {
+		return nil, NewUnexpectedError(err)
+	}
+	scripts, err := readScripts(c.schemaVersion(), source)
+	if err != nil {
+		return nil, errors.Trace(err)
+	}
+	var result []Script
+	for _, s := range scripts {
+		s.controller = c
+		result = append(result, s)
+	}
+	return result, nil
+}
+
+// UploadScriptArgs is an argument struct for Controller.UploadScript.
+type UploadScriptArgs struct {
+	// Name is the name of the script. Required.
+	Name        string
+	Title       string
+	Description string
+	Tags        []string
+	// Type is ScriptTypeCommissioning or ScriptTypeTesting. If it isn't
+	// set, it is read from the metadata embedded in the script, or MAAS
+	// defaults to testing.
+	Type         string
+	HardwareType string
+	Timeout      time.Duration
+	// Destructive is whether the script erases the disks of the machine.
+	Destructive bool
+	// Content is the script. MAAS reads the parameters of the script from
+	// the metadata embedded in it. Required.
+	Content []byte
+	// Comment describes the first revision.
+	Comment string
+}
+
+// Validate ensures that the name and the content are set.
+func (a *UploadScriptArgs) Validate() error {
+	if a.Name == "" {
+		return errors.NotValidf("missing Name")
+	}
+	if len(a.Content) == 0 {
+		return errors.NotValidf("missing Content")
+	}
+	if a.Timeout < 0 {
+		return errors.NotValidf("Timeout %v", a.Timeout)
+	}
+	return nil
+}
+
+// UploadScript implements Controller.
+func (c *controller) UploadScript(args UploadScriptArgs) (Script, error) {
+	if err := args.Validate(); err != nil {
+		return nil, errors.Trace(err)
+	}
+	params := NewURLParams()
+	params.Values.Add("name", args.Name)
+	params.MaybeAdd("title", args.Title)
+	params.MaybeAdd("description", args.Description)
+	params.MaybeAdd("tags", strings.Join(args.Tags, ","))
+	params.MaybeAdd("type", args.Type)
+	params.MaybeAdd("hardware_type", args.HardwareType)
+	params.MaybeAddInt("timeout", int(args.Timeout/time.Second))
+	params.MaybeAddBool("destructive", args.Destructive)
+	params.MaybeAdd("comment", args.Comment)
+	files := map[string][]byte{"script": args.Content}
+	bytes, err := c._postRaw("scripts", "", params.Values, files)
+	if err != nil {
+		if svrErr, ok := errors.Cause(err).(ServerError); ok {
+			switch svrErr.StatusCode {
+			case http.StatusForbidden:
+				return nil, wrapError(err, NewPermissionError(svrErr.BodyMessage))
+			case http.StatusBadRequest:
+				return nil, wrapError(err, NewBadRequestError(svrErr.BodyMessage))
+			}
+		}
+		return nil, NewUnexpectedError(err)
+	}
+
+	var source interface{}
+	if err := json.Unmarshal(bytes, &source); err != nil {
+		return nil, errors.Trace(err)
+	}
+	script, err := readScript(c.schemaVersion(), source)
+	if err != nil {
+		return nil, errors.Trace(err)
+	}
+	script.controller = c
+	return script, nil
+}
+
+func readScript(controllerVersion version.Number, source interface{}) (*script, error) {
+	readFunc, err := getScriptDeserializationFunc(controllerVersion)
+	if err != nil {
+		return nil, errors.Trace(err)
+	}
+
+	checker := schema.StringMap(schema.Any())
+	coerced, err := checker.Coerce(source, nil)
+	if err != nil {
+		return nil, WrapWithDeserializationError(err, "script base schema check failed")
+	}
+	valid := coerced.(map[string]interface{})
+	return readFunc(valid)
+}
+
+func readScripts(controllerVersion version.Number, source interface{}) ([]*script, error) {
+	readFunc, err := getScriptDeserializationFunc(controllerVersion)
+	if err != nil {
+		return nil, errors.Trace(err)
+	}
+
+	checker := schema.List(schema.StringMap(schema.Any()))
+	coerced, err := checker.Coerce(source, nil)
+	if err != nil {
+		return nil, WrapWithDeserializationError(err, "script base schema check failed")
+	}
+	valid := coerced.([]interface{})
+	return readScriptList(valid, readFunc)
+}
+
+func getScriptDeserializationFunc(controllerVersion version.Number) (scriptDeserializationFunc, error) {
+	var deserialisationVersion version.Number
+	for v := range scriptDeserializationFuncs {
+		if v.Compare(deserialisationVersion) > 0 && v.Compare(controllerVersion) <= 0 {
+			deserialisationVersion = v
+		}
+	}
+	if deserialisationVersion == version.Zero {
+		return nil, NewUnsupportedVersionError("no script read func for version %s", controllerVersion)
+	}
+	return scriptDeserializationFuncs[deserialisationVersion], nil
+}
+
+// readScriptList expects the values of the sourceList to be string maps.
+func readScriptList(sourceList []interface{}, readFunc scriptDeserializationFunc) ([]*script, error) {
+	result := make([]*script, 0, len(sourceList))
+	for i, value := range sourceList {
+		source, ok := value.(map[string]interface{})
+		if !ok {
+			return nil, NewDeserializationError("unexpected value for script %d, %T", i, value)
+		}
+		script, err := readFunc(source)
+		if err != nil {
+			return nil, errors.Annotatef(err, "script %d", i)
+		}
+		result = append(result, script)
+	}
+	return result, nil
+}
+
+type scriptDeserializationFunc func(map[string]interface{}) (*script, error)
+
+var scriptDeserializationFuncs = map[version.Number]scriptDeserializationFunc{
+	twoDotOh: script_2_0,
+}
+
+func script_2_0(source map[string]interface{}) (*script, error) {
+	fields := schema.Fields{
+		"resource_uri":  schema.String(),
+		"id":            schema.ForceInt(),
+		"name":          schema.String(),
+		"title":         schema.String(),
+		"description":   schema.String(),
+		"tags":          schema.List(schema.String()),
+		"type":          schema.ForceInt(),
+		"script_type":   schema.ForceInt(),
+		"hardware_type": schema.ForceInt(),
+		"timeout":       schema.OneOf(schema.Nil(""), schema.String()),
+		"destructive":   schema.Bool(),
+		"default":       schema.Bool(),
+		"parameters":    schema.OneOf(schema.Nil(""), schema.StringMap(schema.Any())),
+		"history": schema.List(schema.FieldMap(
+			schema.Fields{
+				"id":      schema.ForceInt(),
+				"comment": schema.OneOf(schema.Nil(""), schema.String()),
+				"created": schema.OneOf(schema.Nil(""), schema.String()),
+			},
+			schema.Defaults{"comment": "", "created": ""},
+		)),
+	}
+	defaults := schema.Defaults{
+		"title":         "",
+		"description":   "",
+		"tags":          []interface{}{},
+		"type":          schema.Omit,
+		"script_type":   schema.Omit,
+		"hardware_type": 0,
+		"timeout":       nil,
+		"destructive":   false,
+		"default":       false,
+		"parameters":    nil,
+		"history":       []interface{}{},
+	}
+	checker := schema.FieldMap(fields, defaults)
+	coerced, err := checker.Coerce(source, nil)
+	if err != nil {
+		return nil, WrapWithDeserializationError(err, "script 2.0 schema check failed")
+	}
+	valid := coerced.(map[string]interface{})
+	// From here we know that the map returned from the schema coercion
+	// contains fields of the right type.
+
+	// MAAS 3.0 renamed the type of the script.
+	scriptType, ok := valid["script_type"].(int)
+	if !ok {
+		scriptType, ok = valid["type"].(int)
+	}
+	if !ok {
+		return nil, NewDeserializationError("script %q has no type", valid["name"])
+	}
+	timeout, _ := valid["timeout"].(string)
+	duration, err := parseScriptTimeout(timeout)
+	if err != nil {
+		return nil, errors.Trace(err)
+	}
+	var history []scriptRevision
+	for _, value := range valid["history"].([]interface{}) {
+		revision := value.(map[string]interface{})
+		comment, _ := revision["comment"].(string)
+		created, _ := revision["created"].(string)
+		history = append(history, scriptRevision{
+			id:      revision["id"].(int),
+			comment: comment,
+			created: created,
+		})
+	}
+	parameters, _ := valid["parameters"].(map[string]interface{})
+	result := &script{
+		resourceURI:  valid["resource_uri"].(string),
+		id:           valid["id"].(int),
+		name:         valid["name"].(string),
+		title:        valid["title"].(string),
+		description:  valid["description"].(string),
+		tags:         convertToStringSlice(valid["tags"]),
+		type_:        scriptTypeName(scriptType),
+		hardwareType: hardwareTypeName(valid["hardware_type"].(int)),
+		timeout:      duration,
+		destructive:  valid["destructive"].(bool),
+		default_:     valid["default"].(bool),
+		parameters:   parameters,
+		history:      history,
+	}
+	return result, nil
+}
+
+func scriptTypeName(value int) string {
+	if name, ok := scriptTypes[value]; ok {
+		return name
+	}
+	return strconv.Itoa(value)
+}
+
+func hardwareTypeName(value int) string {
+	if name, ok := hardwareTypes[value]; ok {
+		return name
+	}
+	return strconv.Itoa(value)
+}
+
+// scriptTimeoutPattern matches the timeouts of scripts, which MAAS formats
+// as Python timedeltas, such as "0:05:00" or "1 day, 2:00:00".
+var scriptTimeoutPattern = regexp.MustCompile(`^(?:(\d+) days?, )?(\d+):(\d{2}):(\d{2})(?:\.\d+)?$`)
+
+// parseScriptTimeout parses the timeout of a script. An empty timeout is
+// no timeout.
+func parseScriptTimeout(value string) (time.Duration, error) {
+	if value == "" {
+		return 0, nil
+	}
+	match := scriptTimeoutPattern.FindStringSubmatch(value)
+	if match == nil {
+		return 0, NewDeserializationError("unexpected script timeout %q", value)
+	}
+	var days int
+	if match[1] != "" {
+		days, _ = strconv.Atoi(match[1])
+	}
+	hours, _ := strconv.Atoi(match[2])
+	minutes, _ := strconv.Atoi(match[3])
+	seconds, _ := strconv.Atoi(match[4])
+	return time.Duration(days)*24*time.Hour +
+		time.Duration(hours)*time.Hour +
+		time.Duration(minutes)*time.Minute +
+		time.Duration(seconds)*time.Second, nil
+}
@@ -0,0 +1,318 @@
+// Copyright 2022 Canonical Ltd.
+// Licensed under the LGPLv3, see LICENCE file for details.
+
+package gomaasapi
+
+import (
+	"net/http"
+	"time"
+
+	"github.com/juju/errors"
+	"github.com/juju/testing"
+	jc "github.com/juju/testing/checkers"
+	"github.com/juju/version"
+	gc "gopkg.in/check.v1"
+)
+
+type scriptSuite struct {
+	testing.LoggingCleanupSuite
+}
+
+var _ = gc.Suite(&scriptSuite{})
+
+func (*scriptSuite) TestReadScriptsBadSchema(c *gc.C) {
+	_, err := readScripts(twoDotOh, "wat?")
+	c.Check(err, jc.Satisfies, IsDeserializationError)
+	c.Assert(err.Error(), gc.Equals, `script base schema check failed: expected list, got string("wat?")`)
+}
+
+func (*scriptSuite) TestReadScripts(c *gc.C) {
+	scripts, err := readScripts(twoDotOh, parseJSON(c, scriptsResponse))
+	c.Assert(err, jc.ErrorIsNil)
+	c.Assert(scripts, gc.HasLen, 2)
+
+	script := scripts[0]
+	c.Check(script.ID(), gc.Equals, 1)
+	c.Check(script.Name(), gc.Equals, "smartctl-validate")
+	c.Check(script.Title(), gc.Equals, "Storage status")
+	c.Check(script.Description(), gc.Equals, "Validate SMART health for all drives in parallel.")
+	c.Check(script.Tags(), jc.DeepEquals, []string{"storage", "commissioning"})
+	c.Check(script.Type(), gc.Equals, ScriptTypeTesting)
+	c.Check(script.HardwareType(), gc.Equals, HardwareTypeStorage)
+	c.Check(script.Timeout(), gc.Equals, 5*time.Minute)
+	c.Check(script.Destructive(), jc.IsFalse)
+	c.Check(script.Default(), jc.IsTrue)
+	c.Check(script.Parameters(), jc.DeepEquals, map[string]interface{}{
+		"storage": map[string]interface{}{"type": "storage"},
+	})
+	c.Check(script.History(), jc.DeepEquals, []ScriptRevision{{
+		ID:      3,
+		Comment: "Created by maas-2.4.0",
+		Created: time.Date(2018, 10, 9, 21, 52, 16, 0, time.UTC),
+	}})
+
+	script = scripts[1]
+	c.Check(script.Type(), gc.Equals, ScriptTypeCommissioning)
+	c.Check(script.HardwareType(), gc.Equals, HardwareTypeNode)
+	c.Check(script.Timeout(), gc.Equals, 26*time.Hour+30*time.Second)
+	c.Check(script.Parameters(), gc.IsNil)
+	c.Check(script.History(), gc.HasLen, 0)
+}
+
+func (*scriptSuite) TestReadScriptsOldType(c *gc.C) {
+	json := updateJSONMap(c, scriptResponse, map[string]interface{}{"type": 0})
+	source := parseJSON(c, json).(map[string]interface{})
+	delete(source, "script_type")
+	script, err := readScript(twoDotOh, source)
+	c.Assert(err, jc.ErrorIsNil)
+	c.Assert(script.Type(), gc.Equals, ScriptTypeCommissioning)
+}
+
+func (*scriptSuite) TestReadScriptsNoType(c *gc.C) {
+	source := parseJSON(c, scriptResponse).(map[string]interface{})
+	delete(source, "script_type")
+	_, err := readScript(twoDotOh, source)
+	c.Assert(err, jc.Satisfies, IsDeserializationError)
+}
+
+func (*scriptSuite) TestParseScriptTimeout(c *gc.C) {
+	for i, test := range []struct {
+		value    string
+		expected time.Duration
+	}{
+		{"", 0},
+		{"0:00:00", 0},
+		{"0:05:00", 5 * time.Minute},
+		{"12:00:30", 12*time.Hour + 30*time.Second},
+		{"1 day, 1:00:00", 25 * time.Hour},
+		{"2 days, 0:00:01.500000", 48*time.Hour + time.Second},
+	} {
+		c.Logf("test %d: %q", i, test.value)
+		timeout, err := parseScriptTimeout(test.value)
+		c.Check(err, jc.ErrorIsNil)
+		c.Check(timeout, gc.Equals, test.expected)
+	}
+	_, err := parseScriptTimeout("5 minutes")
+	c.Assert(err, jc.Satisfies, IsDeserializationError)
+}
+
+func (*scriptSuite) TestLowVersion(c *gc.C) {
+	_, err := readScripts(version.MustParse("1.9.0"), parseJSON(c, scriptsResponse))
+	c.Assert(err, jc.Satisfies, IsUnsupportedVersionError)
+}
+
+func (*scriptSuite) TestHighVersion(c *gc.C) {
+	scripts, err := readScripts(version.MustParse("2.1.9"), parseJSON(c, scriptsResponse))
+	c.Assert(err, jc.ErrorIsNil)
+	c.Assert(scripts, gc.HasLen, 2)
+}
+
+func (s *scriptSuite) getServerAndScript(c *gc.C) (*SimpleTestServer, Script) {
+	server, controller := createTestServerController(c, s)
+	server.AddGetResponse("/api/2.0/scripts/", http.StatusOK, scriptsResponse)
+	scripts, err := controller.Scripts(ScriptsArgs{})
+	c.Assert(err, jc.ErrorIsNil)
+	c.Assert(scripts, gc.HasLen, 2)
+	return server, scripts[0]
+}
+
+func (s *scriptSuite) TestScriptsArgs(c *gc.C) {
+	server, controller := createTestServerController(c, s)
+	server.AddGetResponse("/api/2.0/scripts/?filters=storage%2Cfio&hardware_type=storage&type=testing", http.StatusOK, scriptsResponse)
+	scripts, err := controller.Scripts(ScriptsArgs{
+		Type:         ScriptTypeTesting,
+		HardwareType: HardwareTypeStorage,
+		Filters:      []string{"storage", "fio"},
+	})
+	c.Assert(err, jc.ErrorIsNil)
+	c.Assert(scripts, gc.HasLen, 2)
+}
+
+func (s *scriptSuite) TestUploadScriptArgsValidate(c *gc.C) {
+	for i, test := range []struct {
+		args    UploadScriptArgs
+		message string
+	}{{
+		args:    UploadScriptArgs{},
+		message: "missing Name",
+	}, {
+		args:    UploadScriptArgs{Name: "fio"},
+		message: "missing Content",
+	}, {
+		args:    UploadScriptArgs{Name: "fio", Content: []byte("#!/bin/sh"), Timeout: -time.Second},
+		message: "Timeout -1s",
+	}, {
+		args: UploadScriptArgs{Name: "fio", Content: []byte("#!/bin/sh")},
+	}} {
+		c.Logf("test %d", i)
+		err := test.args.Validate()
+		if test.message == "" {
+			c.Check(err, jc.ErrorIsNil)
+		} else {
+			c.Check(err, jc.Satisfies, errors.IsNotValid)
+			c.Check(err.Error(), gc.Equals, test.message+" not valid")
+		}
+	}
+}
+
+func (s *scriptSuite) TestUploadScript(c *gc.C) {
+	server, controller := createTestServerController(c, s)
+	server.AddPostResponse("/api/2.0/scripts/?op=", http.StatusOK, scriptResponse)
+	script, err := controller.UploadScript(UploadScriptArgs{
+		Name:         "smartctl-validate",
+		Tags:         []string{"storage", "commissioning"},
+		Type:         ScriptTypeTesting,
+		HardwareType: HardwareTypeStorage,
+		Timeout:      5 * time.Minute,
+		Content:      []byte("#!/bin/sh\necho ok\n"),
+		Comment:      "first",
+	})
+	c.Assert(err, jc.ErrorIsNil)
+	c.Assert(script.Name(), gc.Equals, "smartctl-validate")
+
+	request := server.LastRequest()
+	form := request.PostForm
+	c.Check(form.Get("name"), gc.Equals, "smartctl-validate")
+	c.Check(form.Get("tags"), gc.Equals, "storage,commissioning")
+	c.Check(form.Get("type"), gc.Equals, "testing")
+	c.Check(form.Get("hardware_type"), gc.Equals, "storage")
+	c.Check(form.Get("timeout"), gc.Equals, "300")
+	c.Check(form.Get("comment"), gc.Equals, "first")
+	c.Check(form["destructive"], gc.HasLen, 0)
+	c.Check(readUploadedFile(c, request, "script"), gc.Equals, "#!/bin/sh\necho ok\n")
+}
+
+func (s *scriptSuite) TestUploadScriptBadRequest(c *gc.C) {
+	server, controller := createTestServerController(c, s)
+	server.AddPostResponse("/api/2.0/scripts/?op=", http.StatusBadRequest, "name already in use")
+	_, err := controller.UploadScript(UploadScriptArgs{Name: "fio", Content: []byte("#!/bin/sh")})
+	c.Assert(err, jc.Satisfies, IsBadRequestError)
+	c.Assert(err.Error(), gc.Equals, "name already in use")
+}
+
+func (s *scriptSuite) TestUpdate(c *gc.C) {
+	server, script := s.getServerAndScript(c)
+	response := updateJSONMap(c, scriptResponse, map[string]interface{}{"destructive": true})
+	server.AddPutResponse("/api/2.0/scripts/smartctl-validate/", http.StatusOK, response)
+	destructive := true
+	err := script.Update(UpdateScriptArgs{Destructive: &destructive, Title: "SMART"})
+	c.Assert(err, jc.ErrorIsNil)
+	c.Check(script.Destructive(), jc.IsTrue)
+
+	request := server.LastRequest()
+	c.Check(request.MultipartForm, gc.IsNil)
+	c.Check(request.PostForm.Get("destructive"), gc.Equals, "true")
+	c.Check(request.PostForm.Get("title"), gc.Equals, "SMART")
+	c.Check(request.PostForm["timeout"], gc.HasLen, 0)
+}
+
+func (s *scriptSuite) TestUpdateContent(c *gc.C) {
+	server, script := s.getServerAndScript(c)
+	server.AddPutResponse("/api/2.0/scripts/smartctl-validate/", http.StatusOK, scriptResponse)
+	err := script.Update(UpdateScriptArgs{Content: []byte("#!/bin/sh\n"), Comment: "fix"})
+	c.Assert(err, jc.ErrorIsNil)
+
+	request := server.LastRequest()
+	c.Check(request.PostForm.Get("comment"), gc.Equals, "fix")
+	c.Check(readUploadedFile(c, request, "script"), gc.Equals, "#!/bin/sh\n")
+}
+
+func (s *scriptSuite) TestDelete(c *gc.C) {
+	server, script := s.getServerAndScript(c)
+	server.AddDeleteResponse("/api/2.0/scripts/smartctl-validate/", http.StatusNoContent, "")
+	err := script.Delete()
+	c.Assert(err, jc.ErrorIsNil)
+}
+
+func (s *scriptSuite) TestDeleteDefault(c *gc.C) {
+	server, script := s.getServerAndScript(c)
+	server.AddDeleteResponse("/api/2.0/scripts/smartctl-validate/", http.StatusBadRequest, "default scripts can't be deleted")
+	err := script.Delete()
+	c.Assert(err, jc.Satisfies, IsBadRequestError)
+}
+
+func (s *scriptSuite) TestRevert(c *gc.C) {
+	server, script := s.getServerAndScript(c)
+	response := updateJSONMap(c, scriptResponse, map[string]interface{}{"title": "Reverted"})
+	server.AddPostResponse("/api/2.0/scripts/smartctl-validate/?op=revert", http.StatusOK, response)
+	err := script.Revert(-1)
+	c.Assert(err, jc.ErrorIsNil)
+	c.Check(script.Title(), gc.Equals, "Reverted")
+	c.Check(server.LastRequest().PostForm.Get("to"), gc.Equals, "-1")
+}
+
+func (s *scriptSuite) TestRevertZero(c *gc.C) {
+	_, script := s.getServerAndScript(c)
+	err := script.Revert(0)
+	c.Assert(err, jc.Satisfies, errors.IsNotValid)
+}
+
+func (s *scriptSuite) TestDownload(c *gc.C) {
+	server, script := s.getServerAndScript(c)
+	server.AddGetResponse("/api/2.0/scripts/smartctl-validate/?op=download", http.StatusOK, "#!/bin/sh\n")
+	content, err := script.Download(0)
+	c.Assert(err, jc.ErrorIsNil)
+	c.Assert(string(content), gc.Equals, "#!/bin/sh\n")
+}
+
+func (s *scriptSuite) TestDownloadRevision(c *gc.C) {
+	server, script := s.getServerAndScript(c)
+	server.AddGetResponse("/api/2.0/scripts/smartctl-validate/?op=download&revision=3", http.StatusOK, "#!/bin/bash\n")
+	content, err := script.Download(3)
+	c.Assert(err, jc.ErrorIsNil)
+	c.Assert(string(content), gc.Equals, "#!/bin/bash\n")
+}
+
+func (s *scriptSuite) TestDownloadMissingRevision(c *gc.C) {
+	_, script := s.getServerAndScript(c)
+	_, err := script.Download(42)
+	c.Assert(err, jc.Satisfies, IsNoMatchError)
+}
+
+const scriptResponse = `
+{
+    "id": 1,
+    "name": "smartctl-validate",
+    "title": "Storage status",
+    "description": "Validate SMART health for all drives in parallel.",
+    "tags": ["storage", "commissioning"],
+    "script_type": 2,
+    "type_name": "Testing script",
+    "hardware_type": 3,
+    "hardware_type_name": "Storage",
+    "parallel": 2,
+    "parameters": {"storage": {"type": "storage"}},
+    "packages": {"apt": ["smartmontools"]},
+    "timeout": "0:05:00",
+    "destructive": false,
+    "default": true,
+    "for_hardware": [],
+    "may_reboot": false,
+    "recommission": false,
+    "history": [
+        {
+            "id": 3,
+            "comment": "Created by maas-2.4.0",
+            "created": "Tue, 09 Oct. 2018 21:52:16"
+        }
+    ],
+    "resource_uri": "/api/2.0/scripts/smartctl-validate"
+}
+`
+
+const scriptsResponse = "[" + scriptResponse + `,
+{
+    "id": 2,
+    "name": "burn-in",
+    "title": "",
+    "description": "",
+    "tags": [],
+    "script_type": 0,
+    "hardware_type": 0,
+    "parameters": null,
+    "timeout": "1 day, 2:00:30",
+    "destructive": false,
+    "default": false,
+    "resource_uri": "/api/2.0/scripts/burn-in"
+}
+]`
@@ -0,0 +1,96 @@
+// Copyright 2022 Canonical Ltd.
+// Licensed under the LGPLv3, see LICENCE file for details.
+
+package gomaasapi
+
+import (
+	"net/http"
+	"strconv"
+
+	"github.com/juju/collections/set"
+	"github.com/juju/errors"
+	"github.com/juju/version"
+)
+
+// ServerInfo describes a MAAS server, as returned by DiscoverServer and
+// Controller.ServerVersion.
+type ServerInfo struct {
+	// APIVersion is the version of the API the information was read
+	// from, such as "2.0".
+	APIVersion string
+	// Version is the version of MAAS as reported by the server, such as
+	// "3.2.6" or "2.5.0 from source", see VersionNumber.
+	Version    string
+	Subversion string
+	// Capabilities are the capabilities of the server, such as
+	// NetworksManagement.
+	Capabilities set.Strings
+}
+
+// VersionNumber returns the numeric part of the version of MAAS, so that
+// versions can be compared, for instance to know whether the server has
+// resource pools, which were added in 2.5.0.
+func (i ServerInfo) VersionNumber() (version.Number, error) {
+	parts := releasePattern.FindStringSubmatch(i.Version)
+	if parts == nil {
+		return version.Zero, errors.NotValidf("MAAS version %q", i.Version)
+	}
+	var number version.Number
+	number.Major, _ = strconv.Atoi(parts[1])
+	number.Minor, _ = strconv.Atoi(parts[2])
+	if parts[3] != "" {
+		number.Patch, _ = strconv.Atoi(parts[3])
+	}
+	return number, nil
+}
+
+// DiscoverServer reads the version and the capabilities of the MAAS server
+// at args.BaseURL without authenticating, so that tools can check the
+// server before asking for credentials. The APIKey and CredentialProvider
+// of the args are ignored, and the other fields are used like NewController
+// does.
+//
+// If the server doesn't support any of the API versions of this package,
+// an UnsupportedVersionError is returned.
+func DiscoverServer(args ControllerArgs) (ServerInfo, error) {
+	httpClient, err := args.httpClient()
+	if err != nil {
+		return ServerInfo{}, errors.Trace(err)
+	}
+	base, apiVersion, includesVersion := SplitVersionedURL(args.BaseURL)
+	if includesVersion {
+		if !supportedVersion(apiVersion) {
+			return ServerInfo{}, NewUnsupportedVersionError("version %s", apiVersion)
+		}
+		return discoverServerWithVersion(args, httpClient, base, apiVersion)
+	}
+	for _, apiVersion := range supportedAPIVersions {
+		info, err := discoverServerWithVersion(args, httpClient, args.BaseURL, apiVersion)
+		if IsUnsupportedVersionError(err) {
+			continue
+		}
+		return info, errors.Trace(err)
+	}
+	return ServerInfo{}, NewUnsupportedVersionError("controller at %s does not support any of %s", args.BaseURL, supportedAPIVersions)
+}
+
+func discoverServerWithVersion(args ControllerArgs, httpClient *http.Client, baseURL, apiVersion string) (ServerInfo, error) {
+	major, minor, err := version.ParseMajorMinor(apiVersion)
+	if err != nil {
+		return ServerInfo{}, errors.Errorf("bad version defined in supported versions: %q", apiVersion)
+	}
+	client, err := NewAnonymousClient(baseURL, apiVersion)
+	if err != nil {
+		return ServerInfo{}, NewUnexpectedError(err)
+	}
+	args.configure(client, httpClient)
+	c := &controller{client: client, apiVersion: version.Number{Major: major, Minor: minor}}
+	info, err := c.readServerInfo()
+	if err != nil {
+		if IsUnsupportedVersionError(err) || IsDeserializationError(err) {
+			return ServerInfo{}, errors.Trace(err)
+		}
+		return ServerInfo{}, NewUnexpectedError(err)
+	}
+	return info, nil
+}
@@ -0,0 +1,108 @@
+// Copyright 2022 Canonical Ltd.
+// Licensed under the LGPLv3, see LICENCE file for details.
+
+package gomaasapi
+
+import (
+	"net/http"
+
+	"github.com/juju/errors"
+	"github.com/juju/testing"
+	jc "github.com/juju/testing/checkers"
+	"github.com/juju/version"
+	gc "gopkg.in/check.v1"
+)
+
+type serverInfoSuite struct {
+	testing.LoggingCleanupSuite
+}
+
+var _ = gc.Suite(&serverInfoSuite{})
+
+func (s *serverInfoSuite) TestDiscoverServer(c *gc.C) {
+	server := NewSimpleServer()
+	server.AddGetResponse("/api/2.0/version/", http.StatusOK, versionResponse)
+	server.Start()
+	defer server.Close()
+
+	info, err := DiscoverServer(ControllerArgs{BaseURL: server.URL})
+	c.Assert(err, jc.ErrorIsNil)
+	c.Check(info.APIVersion, gc.Equals, "2.0")
+	c.Check(info.Version, gc.Equals, "2.5.0 from source")
+	c.Check(info.Subversion, gc.Equals, "git+2f25a2cc0930c0e411106f119bc455c161d75b1a")
+	c.Check(info.Capabilities.Contains(NetworksManagement), jc.IsTrue)
+	number, err := info.VersionNumber()
+	c.Assert(err, jc.ErrorIsNil)
+	c.Check(number, gc.Equals, version.MustParse("2.5.0"))
+
+	// The request isn't signed.
+	c.Check(server.RequestCount(), gc.Equals, 1)
+	c.Check(server.LastRequest().Header.Get("Authorization"), gc.Equals, "")
+}
+
+func (s *serverInfoSuite) TestDiscoverServerVersionedURL(c *gc.C) {
+	server := NewSimpleServer()
+	server.AddGetResponse("/MAAS/api/2.0/version/", http.StatusOK, versionResponse)
+	server.Start()
+	defer server.Close()
+
+	info, err := DiscoverServer(ControllerArgs{BaseURL: server.URL + "/MAAS/api/2.0/", APIKey: "fake:as:key"})
+	c.Assert(err, jc.ErrorIsNil)
+	c.Check(info.APIVersion, gc.Equals, "2.0")
+	c.Check(server.LastRequest().Header.Get("Authorization"), gc.Equals, "")
+}
+
+func (s *serverInfoSuite) TestDiscoverServerUnsupportedVersion(c *gc.C) {
+	_, err := DiscoverServer(ControllerArgs{BaseURL: "http://maas.invalid/MAAS/api/1.0/"})
+	c.Assert(err, jc.Satisfies, IsUnsupportedVersionError)
+
+	server := NewSimpleServer()
+	server.AddGetResponse("/api/2.0/version/", http.StatusGone, "cya")
+	server.Start()
+	defer server.Close()
+	_, err = DiscoverServer(ControllerArgs{BaseURL: server.URL})
+	c.Assert(err, jc.Satisfies, IsUnsupportedVersionError)
+}
+
+func (s *serverInfoSuite) TestDiscoverServerError(c *gc.C) {
+	server := NewSimpleServer()
+	server.AddGetResponse("/api/2.0/version/", http.StatusInternalServerError, "boom")
+	server.Start()
+	defer server.Close()
+
+	_, err := DiscoverServer(ControllerArgs{BaseURL: server.URL})
+	c.Assert(err, jc.Satisfies, IsUnexpectedError)
+}
+
+func (s *serverInfoSuite) TestDiscoverServerMiddleware(c *gc.C) {
+	server := NewSimpleServer()
+	server.AddGetResponse("/api/2.0/version/", http.StatusOK, versionResponse)
+	server.Start()
+	defer server.Close()
+
+	var calls []string
+	_, err := DiscoverServer(ControllerArgs{
+		BaseURL:    server.URL,
+		Middleware: []Middleware{recordingMiddleware("first", &calls)},
+	})
+	c.Assert(err, jc.ErrorIsNil)
+	c.Check(calls, jc.DeepEquals, []string{"first before", "first after"})
+}
+
+func (s *serverInfoSuite) TestVersionNumber(c *gc.C) {
+	for _, test := range []struct {
+		version  string
+		expected version.Number
+	}{
+		{"3.2.6", version.MustParse("3.2.6")},
+		{"2.5.0 from source", version.MustParse("2.5.0")},
+		{"3.3.0~beta1-14185-g.73fc5ce24", version.MustParse("3.3.0")},
+		{"2.9", version.MustParse("2.9.0")},
+	} {
+		number, err := ServerInfo{Version: test.version}.VersionNumber()
+		c.Check(err, jc.ErrorIsNil)
+		c.Check(number, gc.Equals, test.expected, gc.Commentf(test.version))
+	}
+	_, err := ServerInfo{Version: "unknown"}.VersionNumber()
+	c.Check(err, jc.Satisfies, errors.IsNotValid)
+}
@@ -4,23 +4,33 @@
 package gomaasapi
 
 import (
+	"net/http"
+
 	"github.com/juju/errors"
 	"github.com/juju/schema"
 	"github.com/juju/version"
 )
 
 type space struct {
-	// Add the controller in when we need to do things with the space.
-	// controller Controller
+	controller *controller
 
 	resourceURI string
 
-	id   int
-	name string
+	id          int
+	name        string
+	description string
 
 	subnets []*subnet
 }
 
+func (s *space) updateFrom(other *space) {
+	s.resourceURI = other.resourceURI
+	s.id = other.id
+	s.name = other.name
+	s.description = other.description
+	s.subnets = other.subnets
+}
+
 // Id implements Space.
 func (s *space) ID() int {
 	return s.id
@@ -31,15 +41,97 @@ func (s *space) Name() string {
 	return s.name
 }
 
+// Description implements Space.
+func (s *space) Description() string {
+	return s.description
+}
+
 // Subnets implements Space.
 func (s *space) Subnets() []Subnet {
 	var result []Subnet
 	for _, subnet := range s.subnets {
+		subnet.controller = s.controller
 		result = append(result, subnet)
 	}
 	return result
 }
 
+// UpdateSpaceArgs is an argument struct for Space.Update. Only the fields
+// that are set are changed.
+type UpdateSpaceArgs struct {
+	Name        string
+	Description string
+}
+
+// Update implements Space.
+func (s *space) Update(args UpdateSpaceArgs) error {
+	params := NewURLParams()
+	params.MaybeAdd("name", args.Name)
+	params.MaybeAdd("description", args.Description)
+	source, err := s.controller.put(s.resourceURI, params.Values)
+	s.controller.lookups.invalidate(lookupSpacesByName)
+	if err != nil {
+		if svrErr, ok := errors.Cause(err).(ServerError); ok {
+			switch svrErr.StatusCode {
+			case http.StatusNotFound:
+				return wrapError(err, NewNoMatchError(svrErr.BodyMessage))
+			case http.StatusForbidden:
+				return wrapError(err, NewPermissionError(svrErr.BodyMessage))
+			case http.StatusBadRequest:
+				return wrapError(err, NewBadRequestError(svrErr.BodyMessage))
+			}
+		}
+		return NewUnexpectedError(err)
+	}
+
+	response, err := readSpace(s.controller.schemaVersion(), source)
+	if err != nil {
+		return errors.Trace(err)
+	}
+	s.updateFrom(response)
+	return nil
+}
+
+// Delete implements Space.
+func (s *space) Delete() error {
+	err := s.controller.delete(s.resourceURI)
+	s.controller.lookups.invalidate(lookupSpacesByName)
+	if err != nil {
+		if svrErr, ok := errors.Cause(err).(ServerError); ok {
+			switch svrErr.StatusCode {
+			case http.StatusNotFound:
+				return wrapError(err, NewNoMatchError(svrErr.BodyMessage))
+			case http.StatusForbidden:
+				return wrapError(err, NewPermissionError(svrErr.BodyMessage))
+			case http.StatusBadRequest, http.StatusConflict:
+				return wrapError(err, NewBadRequestError(svrErr.BodyMessage))
+			}
+		}
+		return NewUnexpectedError(err)
+	}
+	return nil
+}
+
+// AssignVLAN implements Space.
+func (s *space) AssignVLAN(v VLAN) error {
+	return errors.Trace(v.Update(UpdateVLANArgs{Space: s.name}))
+}
+
+func readSpace(controllerVersion version.Number, source interface{}) (*space, error) {
+	readFunc, err := getSpaceDeserializationFunc(controllerVersion)
+	if err != nil {
+		return nil, errors.Trace(err)
+	}
+
+	checker := schema.StringMap(schema.Any())
+	coerced, err := checker.Coerce(source, nil)
+	if err != nil {
+		return nil, errors.Annotatef(err, "space base schema check failed")
+	}
+	valid := coerced.(map[string]interface{})
+	return readFunc(valid)
+}
+
 func readSpaces(controllerVersion version.Number, source interface{}) ([]*space, error) {
 	checker := schema.List(schema.StringMap(schema.Any()))
 	coerced, err := checker.Coerce(source, nil)
@@ -48,6 +140,14 @@ func readSpaces(controllerVersion version.Number, source interface{}) ([]*space,
 	}
 	valid := coerced.([]interface{})
 
+	readFunc, err := getSpaceDeserializationFunc(controllerVersion)
+	if err != nil {
+		return nil, errors.Trace(err)
+	}
+	return readSpaceList(valid, readFunc)
+}
+
+func getSpaceDeserializationFunc(controllerVersion version.Number) (spaceDeserializationFunc, error) {
 	var deserialisationVersion version.Number
 	for v := range spaceDeserializationFuncs {
 		if v.Compare(deserialisationVersion) > 0 && v.Compare(controllerVersion) <= 0 {
@@ -57,8 +157,7 @@ func readSpaces(controllerVersion version.Number, source interface{}) ([]*space,
 	if deserialisationVersion == version.Zero {
 		return nil, errors.Errorf("no space read func for version %s", controllerVersion)
 	}
-	readFunc := spaceDeserializationFuncs[deserialisationVersion]
-	return readSpaceList(valid, readFunc)
+	return spaceDeserializationFuncs[deserialisationVersion], nil
 }
 
 // readSpaceList expects the values of the sourceList to be string maps.
@@ -89,9 +188,13 @@ func space_2_0(source map[string]interface{}) (*space, error) {
 		"resource_uri": schema.String(),
 		"id":           schema.ForceInt(),
 		"name":         schema.String(),
+		"description":  schema.OneOf(schema.Nil(""), schema.String()),
 		"subnets":      schema.List(schema.StringMap(schema.Any())),
 	}
-	checker := schema.FieldMap(fields, nil) // no defaults
+	defaults := schema.Defaults{
+		"description": nil,
+	}
+	checker := schema.FieldMap(fields, defaults)
 	coerced, err := checker.Coerce(source, nil)
 	if err != nil {
 		return nil, errors.Annotatef(err, "space 2.0 schema check failed")
@@ -105,10 +208,12 @@ func space_2_0(source map[string]interface{}) (*space, error) {
 		return nil, errors.Trace(err)
 	}
 
+	description, _ := valid["description"].(string)
 	result := &space{
 		resourceURI: valid["resource_uri"].(string),
 		id:          valid["id"].(int),
 		name:        valid["name"].(string),
+		description: description,
 		subnets:     subnets,
 	}
 	return result, nil
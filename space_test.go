@@ -4,12 +4,18 @@
 package gomaasapi
 
 import (
+	"net/http"
+
+	"github.com/juju/errors"
+	"github.com/juju/testing"
 	jc "github.com/juju/testing/checkers"
 	"github.com/juju/version"
 	gc "gopkg.in/check.v1"
 )
 
-type spaceSuite struct{}
+type spaceSuite struct {
+	testing.LoggingCleanupSuite
+}
 
 var _ = gc.Suite(&spaceSuite{})
 
@@ -29,6 +35,94 @@ func (*spaceSuite) TestReadSpaces(c *gc.C) {
 	subnets := space.Subnets()
 	c.Assert(subnets, gc.HasLen, 2)
 	c.Assert(subnets[0].ID(), gc.Equals, 34)
+	c.Assert(space.Description(), gc.Equals, "")
+}
+
+func (s *spaceSuite) getServerAndSpace(c *gc.C) (*SimpleTestServer, *space) {
+	server, controller := createTestServerController(c, s)
+	server.AddGetResponse("/api/2.0/spaces/", http.StatusOK, spacesResponse)
+	spaces, err := controller.Spaces()
+	c.Assert(err, jc.ErrorIsNil)
+	server.ResetRequests()
+	return server, spaces[0].(*space)
+}
+
+func (s *spaceSuite) TestCreateSpace(c *gc.C) {
+	server, controller := createTestServerController(c, s)
+	server.AddPostResponse("/api/2.0/spaces/?op=", http.StatusOK, spaceItemResponse)
+	space, err := controller.CreateSpace(CreateSpaceArgs{
+		Name:        "storage",
+		Description: "storage networks",
+	})
+	c.Assert(err, jc.ErrorIsNil)
+	c.Check(space.ID(), gc.Equals, 2)
+	c.Check(space.Name(), gc.Equals, "storage")
+	c.Check(space.Description(), gc.Equals, "storage networks")
+	c.Check(space.Subnets(), gc.HasLen, 0)
+
+	form := server.LastRequest().PostForm
+	c.Check(form, gc.HasLen, 2)
+	c.Check(form.Get("name"), gc.Equals, "storage")
+	c.Check(form.Get("description"), gc.Equals, "storage networks")
+}
+
+func (s *spaceSuite) TestCreateSpaceInvalid(c *gc.C) {
+	_, controller := createTestServerController(c, s)
+	_, err := controller.CreateSpace(CreateSpaceArgs{})
+	c.Assert(err, jc.Satisfies, errors.IsNotValid)
+	c.Check(err.Error(), gc.Equals, "missing Name not valid")
+}
+
+func (s *spaceSuite) TestCreateSpaceBadRequest(c *gc.C) {
+	server, controller := createTestServerController(c, s)
+	server.AddPostResponse("/api/2.0/spaces/?op=", http.StatusBadRequest, "Space with this Name already exists.")
+	_, err := controller.CreateSpace(CreateSpaceArgs{Name: "space-0"})
+	c.Assert(err, jc.Satisfies, IsBadRequestError)
+}
+
+func (s *spaceSuite) TestUpdate(c *gc.C) {
+	server, space := s.getServerAndSpace(c)
+	server.AddPutResponse(space.resourceURI, http.StatusOK, spaceItemResponse)
+	err := space.Update(UpdateSpaceArgs{Name: "storage"})
+	c.Assert(err, jc.ErrorIsNil)
+	c.Check(space.Name(), gc.Equals, "storage")
+
+	form := server.LastRequest().PostForm
+	c.Check(form, gc.HasLen, 1)
+	c.Check(form.Get("name"), gc.Equals, "storage")
+}
+
+func (s *spaceSuite) TestUpdateForbidden(c *gc.C) {
+	server, space := s.getServerAndSpace(c)
+	server.AddPutResponse(space.resourceURI, http.StatusForbidden, "admins only")
+	err := space.Update(UpdateSpaceArgs{Name: "storage"})
+	c.Assert(err, jc.Satisfies, IsPermissionError)
+	c.Check(space.Name(), gc.Equals, "space-0")
+}
+
+func (s *spaceSuite) TestDelete(c *gc.C) {
+	server, space := s.getServerAndSpace(c)
+	server.AddDeleteResponse(space.resourceURI, http.StatusNoContent, "")
+	err := space.Delete()
+	c.Assert(err, jc.ErrorIsNil)
+}
+
+func (s *spaceSuite) TestDeleteMissing(c *gc.C) {
+	_, space := s.getServerAndSpace(c)
+	err := space.Delete()
+	c.Assert(err, jc.Satisfies, IsNoMatchError)
+}
+
+func (s *spaceSuite) TestAssignVLAN(c *gc.C) {
+	server, space := s.getServerAndSpace(c)
+	vlan := space.Subnets()[0].VLAN()
+	server.AddPutResponse("/MAAS/api/2.0/vlans/5001/", http.StatusOK, vlanItemResponse)
+	err := space.AssignVLAN(vlan)
+	c.Assert(err, jc.ErrorIsNil)
+
+	form := server.LastRequest().PostForm
+	c.Check(form, gc.HasLen, 1)
+	c.Check(form.Get("space"), gc.Equals, "space-0")
 }
 
 func (*spaceSuite) TestLowVersion(c *gc.C) {
@@ -42,6 +136,16 @@ func (*spaceSuite) TestHighVersion(c *gc.C) {
 	c.Assert(spaces, gc.HasLen, 1)
 }
 
+var spaceItemResponse = `
+{
+    "subnets": [],
+    "resource_uri": "/MAAS/api/2.0/spaces/2/",
+    "id": 2,
+    "name": "storage",
+    "description": "storage networks"
+}
+`
+
 var spacesResponse = `
 [
     {
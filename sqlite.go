@@ -0,0 +1,303 @@
+// Copyright 2022 Canonical Ltd.
+// Licensed under the LGPLv3, see LICENCE file for details.
+
+package gomaasapi
+
+import (
+	"bytes"
+	"encoding/binary"
+	"math"
+
+	"github.com/juju/errors"
+)
+
+// The SQLite file format is documented at
+// https://www.sqlite.org/fileformat.html. Only what's needed to read the
+// rows of a table is implemented, which is enough for the small database
+// the MAAS CLI keeps its profiles in. Every offset read from the file is
+// checked before it is used, so that corrupt files are reported as not
+// valid.
+
+const (
+	sqliteHeader        = "SQLite format 3\x00"
+	sqliteInteriorTable = 0x05
+	sqliteLeafTable     = 0x0d
+)
+
+type sqliteFile struct {
+	data       []byte
+	pageSize   int
+	usableSize int
+	// visited holds the pages read, so that loops in corrupt files are
+	// detected.
+	visited map[int]bool
+}
+
+// readSQLiteTable returns the rows of the named table of an SQLite
+// database. The values of the columns are nil, int64, float64, string or
+// []byte. A column that is an alias of the rowid, such as an INTEGER
+// PRIMARY KEY, is nil.
+func readSQLiteTable(data []byte, table string) ([][]interface{}, error) {
+	if len(data) < 100 || !bytes.HasPrefix(data, []byte(sqliteHeader)) {
+		return nil, errors.NotValidf("SQLite database")
+	}
+	pageSize := int(binary.BigEndian.Uint16(data[16:]))
+	if pageSize == 1 {
+		pageSize = 65536
+	}
+	if pageSize < 512 || pageSize&(pageSize-1) != 0 {
+		return nil, errors.NotValidf("SQLite page size %d", pageSize)
+	}
+	usableSize := pageSize - int(data[20])
+	if usableSize < 480 {
+		return nil, errors.NotValidf("SQLite usable page size %d", usableSize)
+	}
+	f := &sqliteFile{
+		data:       data,
+		pageSize:   pageSize,
+		usableSize: usableSize,
+		visited:    make(map[int]bool),
+	}
+	// The schema table, with the root page of each table, is rooted in
+	// the first page.
+	root := 0
+	err := f.walk(1, func(record []interface{}) error {
+		if len(record) >= 4 && record[0] == "table" && record[1] == table {
+			rootPage, ok := record[3].(int64)
+			if !ok || rootPage < 1 {
+				return errors.NotValidf("root page of table %q", table)
+			}
+			root = int(rootPage)
+		}
+		return nil
+	})
+	if err != nil {
+		return nil, errors.Trace(err)
+	}
+	if root == 0 {
+		return nil, errors.NotFoundf("table %q", table)
+	}
+	var rows [][]interface{}
+	err = f.walk(root, func(record []interface{}) error {
+		rows = append(rows, record)
+		return nil
+	})
+	if err != nil {
+		return nil, errors.Trace(err)
+	}
+	return rows, nil
+}
+
+// page returns the content of the numbered page, starting from 1. Each page
+// may only be read once.
+func (f *sqliteFile) page(number int) ([]byte, error) {
+	if number < 1 || number > len(f.data)/f.pageSize {
+		return nil, errors.NotValidf("SQLite page %d", number)
+	}
+	if f.visited[number] {
+		return nil, errors.NotValidf("SQLite page %d read twice", number)
+	}
+	f.visited[number] = true
+	start := (number - 1) * f.pageSize
+	return f.data[start : start+f.pageSize], nil
+}
+
+// walk calls fn with the records of the table b-tree rooted in the page, in
+// rowid order.
+func (f *sqliteFile) walk(number int, fn func([]interface{}) error) error {
+	page, err := f.page(number)
+	if err != nil {
+		return errors.Trace(err)
+	}
+	headerStart := 0
+	if number == 1 {
+		// The first page starts with the header of the file.
+		headerStart = 100
+	}
+	header := page[headerStart:]
+	headerSize := 8
+	if header[0] == sqliteInteriorTable {
+		headerSize = 12
+	}
+	cells := int(binary.BigEndian.Uint16(header[3:]))
+	if headerSize+2*cells > len(header) {
+		return errors.NotValidf("SQLite page %d with %d cells", number, cells)
+	}
+	// cell returns the offset in the page of the content of the cell.
+	cell := func(i int) (int, error) {
+		offset := int(binary.BigEndian.Uint16(header[headerSize+2*i:]))
+		if offset < headerStart+headerSize || offset >= f.usableSize {
+			return 0, errors.NotValidf("SQLite cell offset %d of page %d", offset, number)
+		}
+		return offset, nil
+	}
+	switch header[0] {
+	case sqliteLeafTable:
+		for i := 0; i < cells; i++ {
+			offset, err := cell(i)
+			if err != nil {
+				return errors.Trace(err)
+			}
+			payload, err := f.payload(page, offset)
+			if err != nil {
+				return errors.Trace(err)
+			}
+			record, err := decodeSQLiteRecord(payload)
+			if err != nil {
+				return errors.Trace(err)
+			}
+			if err := fn(record); err != nil {
+				return errors.Trace(err)
+			}
+		}
+	case sqliteInteriorTable:
+		for i := 0; i < cells; i++ {
+			offset, err := cell(i)
+			if err != nil {
+				return errors.Trace(err)
+			}
+			if offset+4 > f.usableSize {
+				return errors.NotValidf("SQLite cell offset %d of page %d", offset, number)
+			}
+			child := int(binary.BigEndian.Uint32(page[offset:]))
+			if err := f.walk(child, fn); err != nil {
+				return errors.Trace(err)
+			}
+		}
+		rightMost := int(binary.BigEndian.Uint32(header[8:]))
+		return f.walk(rightMost, fn)
+	default:
+		return errors.NotValidf("SQLite table page %d of type %d", number, header[0])
+	}
+	return nil
+}
+
+// payload returns the payload of the leaf cell at the offset of the page,
+// reading the overflow pages it continues on.
+func (f *sqliteFile) payload(page []byte, offset int) ([]byte, error) {
+	cell := page[offset:f.usableSize]
+	size, n, ok := sqliteVarint(cell)
+	if !ok {
+		return nil, errors.NotValidf("SQLite payload size")
+	}
+	cell = cell[n:]
+	// Skip the rowid.
+	if _, n, ok = sqliteVarint(cell); !ok {
+		return nil, errors.NotValidf("SQLite rowid")
+	}
+	cell = cell[n:]
+	if size < 0 || size > int64(len(f.data)) {
+		return nil, errors.NotValidf("SQLite payload size %d", size)
+	}
+	total := int(size)
+	maxLocal := f.usableSize - 35
+	if total <= maxLocal {
+		if total > len(cell) {
+			return nil, errors.NotValidf("SQLite payload size %d", size)
+		}
+		return cell[:total], nil
+	}
+	minLocal := (f.usableSize-12)*32/255 - 23
+	local := minLocal + (total-minLocal)%(f.usableSize-4)
+	if local > maxLocal {
+		local = minLocal
+	}
+	if local+4 > len(cell) {
+		return nil, errors.NotValidf("SQLite payload size %d", size)
+	}
+	payload := make([]byte, 0, total)
+	payload = append(payload, cell[:local]...)
+	next := int(binary.BigEndian.Uint32(cell[local:]))
+	for len(payload) < total {
+		overflow, err := f.page(next)
+		if err != nil {
+			return nil, errors.Trace(err)
+		}
+		next = int(binary.BigEndian.Uint32(overflow))
+		content := overflow[4:f.usableSize]
+		if remaining := total - len(payload); remaining < len(content) {
+			content = content[:remaining]
+		}
+		payload = append(payload, content...)
+	}
+	return payload, nil
+}
+
+// decodeSQLiteRecord returns the values of the columns of a record.
+func decodeSQLiteRecord(payload []byte) ([]interface{}, error) {
+	headerSize, offset, ok := sqliteVarint(payload)
+	if !ok || headerSize < int64(offset) || headerSize > int64(len(payload)) {
+		return nil, errors.NotValidf("SQLite record header size %d", headerSize)
+	}
+	var serialTypes []int64
+	for offset < int(headerSize) {
+		serialType, n, ok := sqliteVarint(payload[offset:headerSize])
+		if !ok {
+			return nil, errors.NotValidf("SQLite record header")
+		}
+		serialTypes = append(serialTypes, serialType)
+		offset += n
+	}
+	body := payload[headerSize:]
+	record := make([]interface{}, len(serialTypes))
+	for i, serialType := range serialTypes {
+		var size int
+		switch {
+		case serialType >= 1 && serialType <= 6:
+			size = []int{1, 2, 3, 4, 6, 8}[serialType-1]
+		case serialType == 7:
+			size = 8
+		case serialType >= 12 && serialType%2 == 0:
+			size = int((serialType - 12) / 2)
+		case serialType >= 13:
+			size = int((serialType - 13) / 2)
+		}
+		if size > len(body) {
+			return nil, errors.NotValidf("SQLite record of %d bytes with column of %d bytes", len(payload), size)
+		}
+		switch {
+		case serialType == 0:
+			record[i] = nil
+		case serialType >= 1 && serialType <= 6:
+			var value uint64
+			for _, b := range body[:size] {
+				value = value<<8 | uint64(b)
+			}
+			// Extend the sign of the big-endian integer.
+			shift := uint(64 - 8*size)
+			record[i] = int64(value<<shift) >> shift
+		case serialType == 7:
+			record[i] = math.Float64frombits(binary.BigEndian.Uint64(body))
+		case serialType == 8 || serialType == 9:
+			record[i] = serialType - 8
+		case serialType >= 12 && serialType%2 == 0:
+			record[i] = append([]byte(nil), body[:size]...)
+		case serialType >= 13:
+			record[i] = string(body[:size])
+		default:
+			return nil, errors.NotValidf("SQLite serial type %d", serialType)
+		}
+		body = body[size:]
+	}
+	return record, nil
+}
+
+// sqliteVarint decodes the variable-length integer at the start of data,
+// and returns it with its size. It returns false if data ends before the
+// integer does.
+func sqliteVarint(data []byte) (int64, int, bool) {
+	var value uint64
+	for i := 0; i < 8; i++ {
+		if i >= len(data) {
+			return 0, 0, false
+		}
+		value = value<<7 | uint64(data[i]&0x7f)
+		if data[i]&0x80 == 0 {
+			return int64(value), i + 1, true
+		}
+	}
+	if len(data) < 9 {
+		return 0, 0, false
+	}
+	return int64(value<<8 | uint64(data[8])), 9, true
+}
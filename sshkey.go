@@ -0,0 +1,219 @@
+// Copyright 2022 Canonical Ltd.
+// Licensed under the LGPLv3, see LICENCE file for details.
+
+package gomaasapi
+
+import (
+	"net/http"
+	"strings"
+
+	"github.com/juju/errors"
+	"github.com/juju/schema"
+	"github.com/juju/version"
+)
+
+type sshKey struct {
+	controller *controller
+
+	resourceURI string
+
+	id        int
+	key       string
+	keySource string
+}
+
+// ID implements SSHKey.
+func (k *sshKey) ID() int {
+	return k.id
+}
+
+// Key implements SSHKey.
+func (k *sshKey) Key() string {
+	return k.key
+}
+
+// KeySource implements SSHKey.
+func (k *sshKey) KeySource() string {
+	return k.keySource
+}
+
+// Delete implements SSHKey.
+func (k *sshKey) Delete() error {
+	err := k.controller.delete(k.resourceURI)
+	if err != nil {
+		if svrErr, ok := errors.Cause(err).(ServerError); ok {
+			switch svrErr.StatusCode {
+			case http.StatusNotFound:
+				return wrapError(err, NewNoMatchError(svrErr.BodyMessage))
+			case http.StatusForbidden:
+				return wrapError(err, NewPermissionError(svrErr.BodyMessage))
+			}
+		}
+		return NewUnexpectedError(err)
+	}
+	return nil
+}
+
+// SSHKeys implements Controller.
+func (c *controller) SSHKeys() ([]SSHKey, error) {
+	source, err := c.getList("sshkeys", nil, 0)
+	if err != nil {
+		return nil, NewUnexpectedError(err)
+	}
+	keys, err := readSSHKeys(c.schemaVersion(), source)
+	if err != nil {
+		return nil, errors.Trace(err)
+	}
+	return c.sshKeysResult(keys), nil
+}
+
+// AddSSHKey implements Controller.
+func (c *controller) AddSSHKey(key string) (SSHKey, error) {
+	if key == "" {
+		return nil, errors.NotValidf("missing key")
+	}
+	params := NewURLParams()
+	params.Values.Add("key", key)
+	source, err := c.post("sshkeys", "", params.Values)
+	if err != nil {
+		if svrErr, ok := errors.Cause(err).(ServerError); ok {
+			if svrErr.StatusCode == http.StatusBadRequest {
+				return nil, wrapError(err, NewBadRequestError(svrErr.BodyMessage))
+			}
+		}
+		return nil, NewUnexpectedError(err)
+	}
+	result, err := readSSHKey(c.schemaVersion(), source)
+	if err != nil {
+		return nil, errors.Trace(err)
+	}
+	result.controller = c
+	return result, nil
+}
+
+// ImportSSHKeys implements Controller.
+func (c *controller) ImportSSHKeys(keySource string) ([]SSHKey, error) {
+	if !strings.HasPrefix(keySource, "lp:") && !strings.HasPrefix(keySource, "gh:") {
+		return nil, errors.NotValidf("key source %q", keySource)
+	}
+	params := NewURLParams()
+	params.Values.Add("keysource", keySource)
+	source, err := c.post("sshkeys", "import", params.Values)
+	if err != nil {
+		if svrErr, ok := errors.Cause(err).(ServerError); ok {
+			if svrErr.StatusCode == http.StatusBadRequest {
+				return nil, wrapError(err, NewBadRequestError(svrErr.BodyMessage))
+			}
+		}
+		return nil, NewUnexpectedError(err)
+	}
+	keys, err := readSSHKeys(c.schemaVersion(), source)
+	if err != nil {
+		return nil, errors.Trace(err)
+	}
+	return c.sshKeysResult(keys), nil
+}
+
+func (c *controller) sshKeysResult(keys []*sshKey) []SSHKey {
+	var result []SSHKey
+	for _, k := range keys {
+		k.controller = c
+		result = append(result, k)
+	}
+	return result
+}
+
+func readSSHKey(controllerVersion version.Number, source interface{}) (*sshKey, error) {
+	readFunc, err := getSSHKeyDeserializationFunc(controllerVersion)
+	if err != nil {
+		return nil, errors.Trace(err)
+	}
+
+	checker := schema.StringMap(schema.Any())
+	coerced, err := checker.Coerce(source, nil)
+	if err != nil {
+		return nil, WrapWithDeserializationError(err, "SSH key base schema check failed")
+	}
+	valid := coerced.(map[string]interface{})
+	return readFunc(valid)
+}
+
+func readSSHKeys(controllerVersion version.Number, source interface{}) ([]*sshKey, error) {
+	readFunc, err := getSSHKeyDeserializationFunc(controllerVersion)
+	if err != nil {
+		return nil, errors.Trace(err)
+	}
+
+	checker := schema.List(schema.StringMap(schema.Any()))
+	coerced, err := checker.Coerce(source, nil)
+	if err != nil {
+		return nil, WrapWithDeserializationError(err, "SSH key base schema check failed")
+	}
+	valid := coerced.([]interface{})
+	return readSSHKeyList(valid, readFunc)
+}
+
+func getSSHKeyDeserializationFunc(controllerVersion version.Number) (sshKeyDeserializationFunc, error) {
+	var deserialisationVersion version.Number
+	for v := range sshKeyDeserializationFuncs {
+		if v.Compare(deserialisationVersion) > 0 && v.Compare(controllerVersion) <= 0 {
+			deserialisationVersion = v
+		}
+	}
+	if deserialisationVersion == version.Zero {
+		return nil, NewUnsupportedVersionError("no SSH key read func for version %s", controllerVersion)
+	}
+	return sshKeyDeserializationFuncs[deserialisationVersion], nil
+}
+
+// readSSHKeyList expects the values of the sourceList to be string maps.
+func readSSHKeyList(sourceList []interface{}, readFunc sshKeyDeserializationFunc) ([]*sshKey, error) {
+	result := make([]*sshKey, 0, len(sourceList))
+	for i, value := range sourceList {
+		source, ok := value.(map[string]interface{})
+		if !ok {
+			return nil, NewDeserializationError("unexpected value for SSH key %d, %T", i, value)
+		}
+		key, err := readFunc(source)
+		if err != nil {
+			return nil, errors.Annotatef(err, "SSH key %d", i)
+		}
+		result = append(result, key)
+	}
+	return result, nil
+}
+
+type sshKeyDeserializationFunc func(map[string]interface{}) (*sshKey, error)
+
+var sshKeyDeserializationFuncs = map[version.Number]sshKeyDeserializationFunc{
+	twoDotOh: sshKey_2_0,
+}
+
+func sshKey_2_0(source map[string]interface{}) (*sshKey, error) {
+	fields := schema.Fields{
+		"resource_uri": schema.String(),
+		"id":           schema.ForceInt(),
+		"key":          schema.String(),
+		"keysource":    schema.OneOf(schema.Nil(""), schema.String()),
+	}
+	defaults := schema.Defaults{
+		"keysource": "",
+	}
+	checker := schema.FieldMap(fields, defaults)
+	coerced, err := checker.Coerce(source, nil)
+	if err != nil {
+		return nil, WrapWithDeserializationError(err, "SSH key 2.0 schema check failed")
+	}
+	valid := coerced.(map[string]interface{})
+	// From here we know that the map returned from the schema coercion
+	// contains fields of the right type.
+
+	keySource, _ := valid["keysource"].(string)
+	result := &sshKey{
+		resourceURI: valid["resource_uri"].(string),
+		id:          valid["id"].(int),
+		key:         valid["key"].(string),
+		keySource:   keySource,
+	}
+	return result, nil
+}
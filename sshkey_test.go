@@ -0,0 +1,143 @@
+// Copyright 2022 Canonical Ltd.
+// Licensed under the LGPLv3, see LICENCE file for details.
+
+package gomaasapi
+
+import (
+	"net/http"
+
+	"github.com/juju/errors"
+	"github.com/juju/testing"
+	jc "github.com/juju/testing/checkers"
+	"github.com/juju/version"
+	gc "gopkg.in/check.v1"
+)
+
+type sshKeySuite struct {
+	testing.LoggingCleanupSuite
+}
+
+var _ = gc.Suite(&sshKeySuite{})
+
+func (*sshKeySuite) TestReadSSHKeysBadSchema(c *gc.C) {
+	_, err := readSSHKeys(twoDotOh, "wat?")
+	c.Check(err, jc.Satisfies, IsDeserializationError)
+	c.Assert(err.Error(), gc.Equals, `SSH key base schema check failed: expected list, got string("wat?")`)
+}
+
+func (*sshKeySuite) TestReadSSHKeys(c *gc.C) {
+	keys, err := readSSHKeys(twoDotOh, parseJSON(c, sshKeysResponse))
+	c.Assert(err, jc.ErrorIsNil)
+	c.Assert(keys, gc.HasLen, 2)
+
+	key := keys[0]
+	c.Check(key.ID(), gc.Equals, 1)
+	c.Check(key.Key(), gc.Equals, "ssh-ed25519 AAAAC3NzaC1lZDI1NTE5AAAAIF lab@example")
+	c.Check(key.KeySource(), gc.Equals, "")
+
+	c.Check(keys[1].KeySource(), gc.Equals, "gh:lab")
+}
+
+func (*sshKeySuite) TestLowVersion(c *gc.C) {
+	_, err := readSSHKeys(version.MustParse("1.9.0"), parseJSON(c, sshKeysResponse))
+	c.Assert(err, jc.Satisfies, IsUnsupportedVersionError)
+}
+
+func (*sshKeySuite) TestHighVersion(c *gc.C) {
+	keys, err := readSSHKeys(version.MustParse("2.1.9"), parseJSON(c, sshKeysResponse))
+	c.Assert(err, jc.ErrorIsNil)
+	c.Assert(keys, gc.HasLen, 2)
+}
+
+func (s *sshKeySuite) TestSSHKeys(c *gc.C) {
+	server, controller := createTestServerController(c, s)
+	server.AddGetResponse("/api/2.0/sshkeys/", http.StatusOK, sshKeysResponse)
+	keys, err := controller.SSHKeys()
+	c.Assert(err, jc.ErrorIsNil)
+	c.Assert(keys, gc.HasLen, 2)
+
+	server.AddDeleteResponse("/api/2.0/sshkeys/2/", http.StatusNoContent, "")
+	err = keys[1].Delete()
+	c.Assert(err, jc.ErrorIsNil)
+
+	err = keys[0].Delete()
+	c.Assert(err, jc.Satisfies, IsNoMatchError)
+}
+
+func (s *sshKeySuite) TestSSHKeysPaginated(c *gc.C) {
+	server, controller := createTestServerController(c, s)
+	server.AddGetResponse("/api/2.0/sshkeys/", http.StatusOK, `{"items": `+sshKeysResponse+`, "next": null}`)
+	result, err := controller.SSHKeys()
+	c.Assert(err, jc.ErrorIsNil)
+	c.Check(result, gc.HasLen, 2)
+}
+
+func (s *sshKeySuite) TestAddSSHKey(c *gc.C) {
+	server, controller := createTestServerController(c, s)
+	server.AddPostResponse("/api/2.0/sshkeys/?op=", http.StatusOK, `
+{
+    "id": 3,
+    "key": "ssh-rsa AAAAB3NzaC1yc2E ci@example",
+    "keysource": null,
+    "resource_uri": "/api/2.0/sshkeys/3/"
+}`)
+	key, err := controller.AddSSHKey("ssh-rsa AAAAB3NzaC1yc2E ci@example")
+	c.Assert(err, jc.ErrorIsNil)
+	c.Check(key.ID(), gc.Equals, 3)
+	c.Check(server.LastRequest().PostForm.Get("key"), gc.Equals, "ssh-rsa AAAAB3NzaC1yc2E ci@example")
+}
+
+func (s *sshKeySuite) TestAddSSHKeyMissing(c *gc.C) {
+	_, controller := createTestServerController(c, s)
+	_, err := controller.AddSSHKey("")
+	c.Assert(err, jc.Satisfies, errors.IsNotValid)
+}
+
+func (s *sshKeySuite) TestAddSSHKeyBadRequest(c *gc.C) {
+	server, controller := createTestServerController(c, s)
+	server.AddPostResponse("/api/2.0/sshkeys/?op=", http.StatusBadRequest, "Invalid SSH public key.")
+	_, err := controller.AddSSHKey("nonsense")
+	c.Assert(err, jc.Satisfies, IsBadRequestError)
+}
+
+func (s *sshKeySuite) TestImportSSHKeys(c *gc.C) {
+	server, controller := createTestServerController(c, s)
+	server.AddPostResponse("/api/2.0/sshkeys/?op=import", http.StatusOK, `
+[
+    {
+        "id": 2,
+        "key": "ssh-rsa AAAAB3NzaC1yc2E lab@github",
+        "keysource": "gh:lab",
+        "resource_uri": "/api/2.0/sshkeys/2/"
+    }
+]`)
+	keys, err := controller.ImportSSHKeys("gh:lab")
+	c.Assert(err, jc.ErrorIsNil)
+	c.Assert(keys, gc.HasLen, 1)
+	c.Check(keys[0].KeySource(), gc.Equals, "gh:lab")
+	c.Check(server.LastRequest().PostForm.Get("keysource"), gc.Equals, "gh:lab")
+}
+
+func (s *sshKeySuite) TestImportSSHKeysBadSource(c *gc.C) {
+	_, controller := createTestServerController(c, s)
+	_, err := controller.ImportSSHKeys("lab")
+	c.Assert(err, jc.Satisfies, errors.IsNotValid)
+	c.Assert(err.Error(), gc.Equals, `key source "lab" not valid`)
+}
+
+const sshKeysResponse = `
+[
+    {
+        "id": 1,
+        "key": "ssh-ed25519 AAAAC3NzaC1lZDI1NTE5AAAAIF lab@example",
+        "keysource": null,
+        "resource_uri": "/api/2.0/sshkeys/1/"
+    },
+    {
+        "id": 2,
+        "key": "ssh-rsa AAAAB3NzaC1yc2E lab@github",
+        "keysource": "gh:lab",
+        "resource_uri": "/api/2.0/sshkeys/2/"
+    }
+]
+`
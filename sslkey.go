@@ -0,0 +1,179 @@
+// Copyright 2022 Canonical Ltd.
+// Licensed under the LGPLv3, see LICENCE file for details.
+
+package gomaasapi
+
+import (
+	"net/http"
+
+	"github.com/juju/errors"
+	"github.com/juju/schema"
+	"github.com/juju/version"
+)
+
+type sslKey struct {
+	controller *controller
+
+	resourceURI string
+
+	id  int
+	key string
+}
+
+// ID implements SSLKey.
+func (k *sslKey) ID() int {
+	return k.id
+}
+
+// Key implements SSLKey.
+func (k *sslKey) Key() string {
+	return k.key
+}
+
+// Delete implements SSLKey.
+func (k *sslKey) Delete() error {
+	err := k.controller.delete(k.resourceURI)
+	if err != nil {
+		if svrErr, ok := errors.Cause(err).(ServerError); ok {
+			switch svrErr.StatusCode {
+			case http.StatusNotFound:
+				return wrapError(err, NewNoMatchError(svrErr.BodyMessage))
+			case http.StatusForbidden:
+				return wrapError(err, NewPermissionError(svrErr.BodyMessage))
+			}
+		}
+		return NewUnexpectedError(err)
+	}
+	return nil
+}
+
+// SSLKeys implements Controller.
+func (c *controller) SSLKeys() ([]SSLKey, error) {
+	source, err := c.getList("sslkeys", nil, 0)
+	if err != nil {
+		return nil, NewUnexpectedError(err)
+	}
+	keys, err := readSSLKeys(c.schemaVersion(), source)
+	if err != nil {
+		return nil, errors.Trace(err)
+	}
+	var result []SSLKey
+	for _, k := range keys {
+		k.controller = c
+		result = append(result, k)
+	}
+	return result, nil
+}
+
+// AddSSLKey implements Controller.
+func (c *controller) AddSSLKey(key string) (SSLKey, error) {
+	if key == "" {
+		return nil, errors.NotValidf("missing key")
+	}
+	params := NewURLParams()
+	params.Values.Add("key", key)
+	source, err := c.post("sslkeys", "", params.Values)
+	if err != nil {
+		if svrErr, ok := errors.Cause(err).(ServerError); ok {
+			if svrErr.StatusCode == http.StatusBadRequest {
+				return nil, wrapError(err, NewBadRequestError(svrErr.BodyMessage))
+			}
+		}
+		return nil, NewUnexpectedError(err)
+	}
+	result, err := readSSLKey(c.schemaVersion(), source)
+	if err != nil {
+		return nil, errors.Trace(err)
+	}
+	result.controller = c
+	return result, nil
+}
+
+func readSSLKey(controllerVersion version.Number, source interface{}) (*sslKey, error) {
+	readFunc, err := getSSLKeyDeserializationFunc(controllerVersion)
+	if err != nil {
+		return nil, errors.Trace(err)
+	}
+
+	checker := schema.StringMap(schema.Any())
+	coerced, err := checker.Coerce(source, nil)
+	if err != nil {
+		return nil, WrapWithDeserializationError(err, "SSL key base schema check failed")
+	}
+	valid := coerced.(map[string]interface{})
+	return readFunc(valid)
+}
+
+func readSSLKeys(controllerVersion version.Number, source interface{}) ([]*sslKey, error) {
+	readFunc, err := getSSLKeyDeserializationFunc(controllerVersion)
+	if err != nil {
+		return nil, errors.Trace(err)
+	}
+
+	checker := schema.List(schema.StringMap(schema.Any()))
+	coerced, err := checker.Coerce(source, nil)
+	if err != nil {
+		return nil, WrapWithDeserializationError(err, "SSL key base schema check failed")
+	}
+	valid := coerced.([]interface{})
+	return readSSLKeyList(valid, readFunc)
+}
+
+func getSSLKeyDeserializationFunc(controllerVersion version.Number) (sslKeyDeserializationFunc, error) {
+	var deserialisationVersion version.Number
+	for v := range sslKeyDeserializationFuncs {
+		if v.Compare(deserialisationVersion) > 0 && v.Compare(controllerVersion) <= 0 {
+			deserialisationVersion = v
+		}
+	}
+	if deserialisationVersion == version.Zero {
+		return nil, NewUnsupportedVersionError("no SSL key read func for version %s", controllerVersion)
+	}
+	return sslKeyDeserializationFuncs[deserialisationVersion], nil
+}
+
+// readSSLKeyList expects the values of the sourceList to be string maps.
+func readSSLKeyList(sourceList []interface{}, readFunc sslKeyDeserializationFunc) ([]*sslKey, error) {
+	result := make([]*sslKey, 0, len(sourceList))
+	for i, value := range sourceList {
+		source, ok := value.(map[string]interface{})
+		if !ok {
+			return nil, NewDeserializationError("unexpected value for SSL key %d, %T", i, value)
+		}
+		key, err := readFunc(source)
+		if err != nil {
+			return nil, errors.Annotatef(err, "SSL key %d", i)
+		}
+		result = append(result, key)
+	}
+	return result, nil
+}
+
+type sslKeyDeserializationFunc func(map[string]interface{}) (*sslKey, error)
+
+var sslKeyDeserializationFuncs = map[version.Number]sslKeyDeserializationFunc{
+	twoDotOh: sslKey_2_0,
+}
+
+func sslKey_2_0(source map[string]interface{}) (*sslKey, error) {
+	fields := schema.Fields{
+		"resource_uri": schema.String(),
+		"id":           schema.ForceInt(),
+		"key":          schema.String(),
+	}
+	checker := schema.FieldMap(fields, nil) // no defaults
+	coerced, err := checker.Coerce(source, nil)
+	if err != nil {
+		return nil, WrapWithDeserializationError(err, "SSL key 2.0 schema check failed")
+	}
+	valid := coerced.(map[string]interface{})
+	// From here we know that the map returned from the schema coercion
+	// contains fields of the right type.
+
+	result := &sslKey{
+		resourceURI: valid["resource_uri"].(string),
+		id:          valid["id"].(int),
+		key:         valid["key"].(string),
+	}
+	return result, nil
+}
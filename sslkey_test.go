@@ -0,0 +1,106 @@
+// Copyright 2022 Canonical Ltd.
+// Licensed under the LGPLv3, see LICENCE file for details.
+
+package gomaasapi
+
+import (
+	"net/http"
+
+	"github.com/juju/errors"
+	"github.com/juju/testing"
+	jc "github.com/juju/testing/checkers"
+	"github.com/juju/version"
+	gc "gopkg.in/check.v1"
+)
+
+type sslKeySuite struct {
+	testing.LoggingCleanupSuite
+}
+
+var _ = gc.Suite(&sslKeySuite{})
+
+func (*sslKeySuite) TestReadSSLKeysBadSchema(c *gc.C) {
+	_, err := readSSLKeys(twoDotOh, "wat?")
+	c.Check(err, jc.Satisfies, IsDeserializationError)
+	c.Assert(err.Error(), gc.Equals, `SSL key base schema check failed: expected list, got string("wat?")`)
+}
+
+func (*sslKeySuite) TestReadSSLKeys(c *gc.C) {
+	keys, err := readSSLKeys(twoDotOh, parseJSON(c, sslKeysResponse))
+	c.Assert(err, jc.ErrorIsNil)
+	c.Assert(keys, gc.HasLen, 1)
+	c.Check(keys[0].ID(), gc.Equals, 1)
+	c.Check(keys[0].Key(), gc.Equals, "-----BEGIN CERTIFICATE-----\nMIIB\n-----END CERTIFICATE-----\n")
+}
+
+func (*sslKeySuite) TestLowVersion(c *gc.C) {
+	_, err := readSSLKeys(version.MustParse("1.9.0"), parseJSON(c, sslKeysResponse))
+	c.Assert(err, jc.Satisfies, IsUnsupportedVersionError)
+}
+
+func (*sslKeySuite) TestHighVersion(c *gc.C) {
+	keys, err := readSSLKeys(version.MustParse("2.1.9"), parseJSON(c, sslKeysResponse))
+	c.Assert(err, jc.ErrorIsNil)
+	c.Assert(keys, gc.HasLen, 1)
+}
+
+func (s *sslKeySuite) TestSSLKeys(c *gc.C) {
+	server, controller := createTestServerController(c, s)
+	server.AddGetResponse("/api/2.0/sslkeys/", http.StatusOK, sslKeysResponse)
+	keys, err := controller.SSLKeys()
+	c.Assert(err, jc.ErrorIsNil)
+	c.Assert(keys, gc.HasLen, 1)
+
+	server.AddDeleteResponse("/api/2.0/sslkeys/1/", http.StatusNoContent, "")
+	err = keys[0].Delete()
+	c.Assert(err, jc.ErrorIsNil)
+
+	server.AddDeleteResponse("/api/2.0/sslkeys/1/", http.StatusNotFound, "Not Found")
+	err = keys[0].Delete()
+	c.Assert(err, jc.Satisfies, IsNoMatchError)
+}
+
+func (s *sslKeySuite) TestSSLKeysPaginated(c *gc.C) {
+	server, controller := createTestServerController(c, s)
+	server.AddGetResponse("/api/2.0/sslkeys/", http.StatusOK, `{"items": `+sslKeysResponse+`, "next": null}`)
+	result, err := controller.SSLKeys()
+	c.Assert(err, jc.ErrorIsNil)
+	c.Check(result, gc.HasLen, 1)
+}
+
+func (s *sslKeySuite) TestAddSSLKey(c *gc.C) {
+	server, controller := createTestServerController(c, s)
+	server.AddPostResponse("/api/2.0/sslkeys/?op=", http.StatusOK, `
+{
+    "id": 2,
+    "key": "-----BEGIN CERTIFICATE-----\nMIIC\n-----END CERTIFICATE-----\n",
+    "resource_uri": "/api/2.0/sslkeys/2/"
+}`)
+	key, err := controller.AddSSLKey("-----BEGIN CERTIFICATE-----\nMIIC\n-----END CERTIFICATE-----\n")
+	c.Assert(err, jc.ErrorIsNil)
+	c.Check(key.ID(), gc.Equals, 2)
+	c.Check(server.LastRequest().PostForm.Get("key"), gc.Equals, "-----BEGIN CERTIFICATE-----\nMIIC\n-----END CERTIFICATE-----\n")
+}
+
+func (s *sslKeySuite) TestAddSSLKeyMissing(c *gc.C) {
+	_, controller := createTestServerController(c, s)
+	_, err := controller.AddSSLKey("")
+	c.Assert(err, jc.Satisfies, errors.IsNotValid)
+}
+
+func (s *sslKeySuite) TestAddSSLKeyBadRequest(c *gc.C) {
+	server, controller := createTestServerController(c, s)
+	server.AddPostResponse("/api/2.0/sslkeys/?op=", http.StatusBadRequest, "Invalid SSL key.")
+	_, err := controller.AddSSLKey("nonsense")
+	c.Assert(err, jc.Satisfies, IsBadRequestError)
+}
+
+const sslKeysResponse = `
+[
+    {
+        "id": 1,
+        "key": "-----BEGIN CERTIFICATE-----\nMIIB\n-----END CERTIFICATE-----\n",
+        "resource_uri": "/api/2.0/sslkeys/1/"
+    }
+]
+`
@@ -0,0 +1,111 @@
+// Copyright 2022 Canonical Ltd.
+// Licensed under the LGPLv3, see LICENCE file for details.
+
+package gomaasapi
+
+import (
+	"net/http"
+	"net/url"
+
+	"github.com/juju/errors"
+)
+
+// FormatStorageDeviceArgs is an argument struct for StorageDevice.Format.
+// FSType is required, MAAS generates a UUID if one isn't specified.
+type FormatStorageDeviceArgs struct {
+	FSType FileSystemType
+	Label  string
+	UUID   string
+}
+
+// Validate ensures that the FSType is set.
+func (a *FormatStorageDeviceArgs) Validate() error {
+	if a.FSType == "" {
+		return errors.NotValidf("missing FSType")
+	}
+	return nil
+}
+
+func (a *FormatStorageDeviceArgs) params() url.Values {
+	params := NewURLParams()
+	params.Values.Add("fstype", string(a.FSType))
+	params.MaybeAdd("label", a.Label)
+	params.MaybeAdd("uuid", a.UUID)
+	return params.Values
+}
+
+// MountStorageDeviceArgs is an argument struct for StorageDevice.Mount.
+// MountPoint is required, MountOptions are passed to mount as is.
+type MountStorageDeviceArgs struct {
+	MountPoint   string
+	MountOptions string
+}
+
+// Validate ensures that the MountPoint is set.
+func (a *MountStorageDeviceArgs) Validate() error {
+	if a.MountPoint == "" {
+		return errors.NotValidf("missing MountPoint")
+	}
+	return nil
+}
+
+func (a *MountStorageDeviceArgs) params() url.Values {
+	params := NewURLParams()
+	params.Values.Add("mount_point", a.MountPoint)
+	params.MaybeAdd("mount_options", a.MountOptions)
+	return params.Values
+}
+
+// storageDeviceError maps the errors returned by MAAS for operations on block
+// devices and partitions.
+func storageDeviceError(err error) error {
+	if svrErr, ok := errors.Cause(err).(ServerError); ok {
+		switch svrErr.StatusCode {
+		case http.StatusNotFound:
+			return wrapError(err, NewNoMatchError(svrErr.BodyMessage))
+		case http.StatusForbidden:
+			return wrapError(err, NewPermissionError(svrErr.BodyMessage))
+		case http.StatusBadRequest, http.StatusConflict:
+			return wrapError(err, NewBadRequestError(svrErr.BodyMessage))
+		}
+	}
+	return NewUnexpectedError(err)
+}
+
+// readStorageDeviceList reads a list holding both block devices and
+// partitions, as used by RAIDs and volume groups. MAAS tells them apart by
+// their type.
+func readStorageDeviceList(sourceList []interface{}) ([]StorageDevice, error) {
+	result := make([]StorageDevice, 0, len(sourceList))
+	for i, value := range sourceList {
+		source, ok := value.(map[string]interface{})
+		if !ok {
+			return nil, NewDeserializationError("unexpected value for storage device %d, %T", i, value)
+		}
+		var (
+			device StorageDevice
+			err    error
+		)
+		if source["type"] == "partition" {
+			device, err = partition_2_0(source)
+		} else {
+			device, err = blockdevice_2_0(source)
+		}
+		if err != nil {
+			return nil, errors.Annotatef(err, "storage device %d", i)
+		}
+		result = append(result, device)
+	}
+	return result, nil
+}
+
+func setStorageDeviceController(devices []StorageDevice, controller *controller) {
+	for _, device := range devices {
+		switch device := device.(type) {
+		case *blockdevice:
+			device.controller = controller
+		case *partition:
+			device.controller = controller
+		}
+	}
+}
@@ -0,0 +1,39 @@
+// Copyright 2022 Canonical Ltd.
+// Licensed under the LGPLv3, see LICENCE file for details.
+
+package gomaasapi
+
+import (
+	"github.com/juju/errors"
+	jc "github.com/juju/testing/checkers"
+	gc "gopkg.in/check.v1"
+)
+
+type storageDeviceSuite struct{}
+
+var _ = gc.Suite(&storageDeviceSuite{})
+
+func (*storageDeviceSuite) TestFormatArgsValidate(c *gc.C) {
+	args := FormatStorageDeviceArgs{}
+	err := args.Validate()
+	c.Check(err, jc.Satisfies, errors.IsNotValid)
+	c.Check(err.Error(), gc.Equals, "missing FSType not valid")
+
+	args = FormatStorageDeviceArgs{FSType: "ext4"}
+	c.Check(args.Validate(), jc.ErrorIsNil)
+	c.Check(args.params().Encode(), gc.Equals, "fstype=ext4")
+
+	args = FormatStorageDeviceArgs{FSType: "xfs", Label: "data", UUID: "some-uuid"}
+	c.Check(args.params().Encode(), gc.Equals, "fstype=xfs&label=data&uuid=some-uuid")
+}
+
+func (*storageDeviceSuite) TestMountArgsValidate(c *gc.C) {
+	args := MountStorageDeviceArgs{}
+	err := args.Validate()
+	c.Check(err, jc.Satisfies, errors.IsNotValid)
+	c.Check(err.Error(), gc.Equals, "missing MountPoint not valid")
+
+	args = MountStorageDeviceArgs{MountPoint: "/srv", MountOptions: "noatime"}
+	c.Check(args.Validate(), jc.ErrorIsNil)
+	c.Check(args.params().Encode(), gc.Equals, "mount_options=noatime&mount_point=%2Fsrv")
+}
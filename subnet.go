@@ -4,14 +4,17 @@
 package gomaasapi
 
 import (
+	"fmt"
+	"net/http"
+	"strings"
+
 	"github.com/juju/errors"
 	"github.com/juju/schema"
 	"github.com/juju/version"
 )
 
 type subnet struct {
-	// Add the controller in when we need to do things with the subnet.
-	// controller Controller
+	controller *controller
 
 	resourceURI string
 
@@ -24,6 +27,30 @@ type subnet struct {
 	cidr    string
 
 	dnsServers []string
+	allowProxy bool
+
+	managed                   bool
+	activeDiscovery           bool
+	allowDNS                  bool
+	disabledBootArchitectures []string
+
+	lastScan *scanResult
+}
+
+func (s *subnet) updateFrom(other *subnet) {
+	s.resourceURI = other.resourceURI
+	s.id = other.id
+	s.name = other.name
+	s.space = other.space
+	s.vlan = other.vlan
+	s.gateway = other.gateway
+	s.cidr = other.cidr
+	s.dnsServers = other.dnsServers
+	s.allowProxy = other.allowProxy
+	s.managed = other.managed
+	s.activeDiscovery = other.activeDiscovery
+	s.allowDNS = other.allowDNS
+	s.disabledBootArchitectures = other.disabledBootArchitectures
 }
 
 // ID implements Subnet.
@@ -46,6 +73,7 @@ func (s *subnet) VLAN() VLAN {
 	if s.vlan == nil {
 		return nil
 	}
+	s.vlan.controller = s.controller
 	return s.vlan
 }
 
@@ -64,6 +92,200 @@ func (s *subnet) DNSServers() []string {
 	return s.dnsServers
 }
 
+// AllowProxy implements Subnet.
+func (s *subnet) AllowProxy() bool {
+	return s.allowProxy
+}
+
+// Managed implements Subnet.
+func (s *subnet) Managed() bool {
+	return s.managed
+}
+
+// ActiveDiscovery implements Subnet.
+func (s *subnet) ActiveDiscovery() bool {
+	return s.activeDiscovery
+}
+
+// AllowDNS implements Subnet.
+func (s *subnet) AllowDNS() bool {
+	return s.allowDNS
+}
+
+// DisabledBootArchitectures implements Subnet.
+func (s *subnet) DisabledBootArchitectures() []string {
+	return s.disabledBootArchitectures
+}
+
+// SubnetScanArgs is an argument struct for Subnet.Scan. All the fields are
+// optional.
+type SubnetScanArgs struct {
+	// Force scans the subnet even if it has been scanned recently, or if
+	// active discovery is disabled for it.
+	Force bool
+	// AlwaysUsePing uses ping rather than nmap, even if nmap is installed on
+	// the rack controllers.
+	AlwaysUsePing bool
+	// Slow limits the scan to a rate that shouldn't flood the network.
+	Slow bool
+	// Threads is the number of concurrent threads to use on each rack
+	// controller. Zero uses the MAAS default.
+	Threads int
+}
+
+// Scan implements Subnet.
+func (s *subnet) Scan(args SubnetScanArgs) (ScanResult, error) {
+	result, err := s.controller.scanNetworks(ScanNetworksArgs{
+		CIDRs:         []string{s.cidr},
+		Force:         args.Force,
+		AlwaysUsePing: args.AlwaysUsePing,
+		Slow:          args.Slow,
+		Threads:       args.Threads,
+	})
+	if err != nil {
+		return nil, errors.Trace(err)
+	}
+	s.lastScan = result
+	return result, nil
+}
+
+// LastScan implements Subnet.
+func (s *subnet) LastScan() ScanResult {
+	if s.lastScan == nil {
+		return nil
+	}
+	return s.lastScan
+}
+
+// UpdateSubnetArgs is an argument struct for Subnet.Update. Only the fields
+// that are set are changed.
+type UpdateSubnetArgs struct {
+	Name      string
+	VLAN      VLAN
+	GatewayIP string
+	// DNSServers replaces the DNS servers of the subnet.
+	DNSServers []string
+	// Managed, if not nil, sets whether MAAS manages the allocation of
+	// addresses in the subnet.
+	Managed *bool
+	// ActiveDiscovery, if not nil, sets whether the rack controllers
+	// periodically scan the subnet for neighbours.
+	ActiveDiscovery *bool
+	// AllowProxy, if not nil, sets whether the MAAS proxy accepts requests
+	// from the subnet.
+	AllowProxy *bool
+	// AllowDNS, if not nil, sets whether the MAAS DNS servers resolve
+	// queries from the subnet.
+	AllowDNS *bool
+	// DisabledBootArchitectures replaces the boot architectures that MAAS
+	// won't respond to on the subnet, such as "pxe" or "uefi_ebc".
+	DisabledBootArchitectures []string
+	// ClearDisabledBootArchitectures enables all the boot architectures on
+	// the subnet.
+	ClearDisabledBootArchitectures bool
+}
+
+// Validate ensures that the boot architectures aren't both set and cleared.
+func (a *UpdateSubnetArgs) Validate() error {
+	if len(a.DisabledBootArchitectures) > 0 && a.ClearDisabledBootArchitectures {
+		return errors.NotValidf("both DisabledBootArchitectures and ClearDisabledBootArchitectures")
+	}
+	return nil
+}
+
+func (a *UpdateSubnetArgs) vlanID() int {
+	if a.VLAN == nil {
+		return 0
+	}
+	return a.VLAN.ID()
+}
+
+// Update implements Subnet.
+func (s *subnet) Update(args UpdateSubnetArgs) error {
+	if err := args.Validate(); err != nil {
+		return errors.Trace(err)
+	}
+	params := NewURLParams()
+	params.MaybeAdd("name", args.Name)
+	params.MaybeAddInt("vlan", args.vlanID())
+	params.MaybeAdd("gateway_ip", args.GatewayIP)
+	params.MaybeAdd("dns_servers", strings.Join(args.DNSServers, ","))
+	if args.Managed != nil {
+		params.Values.Add("managed", fmt.Sprint(*args.Managed))
+	}
+	if args.ActiveDiscovery != nil {
+		params.Values.Add("active_discovery", fmt.Sprint(*args.ActiveDiscovery))
+	}
+	if args.AllowProxy != nil {
+		params.Values.Add("allow_proxy", fmt.Sprint(*args.AllowProxy))
+	}
+	if args.AllowDNS != nil {
+		params.Values.Add("allow_dns", fmt.Sprint(*args.AllowDNS))
+	}
+	if args.ClearDisabledBootArchitectures {
+		params.Values.Add("disabled_boot_architectures", "")
+	} else {
+		params.MaybeAdd("disabled_boot_architectures", strings.Join(args.DisabledBootArchitectures, ","))
+	}
+	source, err := s.controller.put(s.resourceURI, params.Values)
+	s.controller.lookups.invalidate(lookupSubnetsByCIDR)
+	if err != nil {
+		if svrErr, ok := errors.Cause(err).(ServerError); ok {
+			switch svrErr.StatusCode {
+			case http.StatusNotFound:
+				return wrapError(err, NewNoMatchError(svrErr.BodyMessage))
+			case http.StatusForbidden:
+				return wrapError(err, NewPermissionError(svrErr.BodyMessage))
+			case http.StatusBadRequest:
+				return wrapError(err, NewBadRequestError(svrErr.BodyMessage))
+			}
+		}
+		return NewUnexpectedError(err)
+	}
+
+	response, err := readSubnet(s.controller.schemaVersion(), source)
+	if err != nil {
+		return errors.Trace(err)
+	}
+	s.updateFrom(response)
+	return nil
+}
+
+// Delete implements Subnet.
+func (s *subnet) Delete() error {
+	err := s.controller.delete(s.resourceURI)
+	s.controller.lookups.invalidate(lookupSubnetsByCIDR)
+	if err != nil {
+		if svrErr, ok := errors.Cause(err).(ServerError); ok {
+			switch svrErr.StatusCode {
+			case http.StatusNotFound:
+				return wrapError(err, NewNoMatchError(svrErr.BodyMessage))
+			case http.StatusForbidden:
+				return wrapError(err, NewPermissionError(svrErr.BodyMessage))
+			case http.StatusBadRequest, http.StatusConflict:
+				return wrapError(err, NewBadRequestError(svrErr.BodyMessage))
+			}
+		}
+		return NewUnexpectedError(err)
+	}
+	return nil
+}
+
+func readSubnet(controllerVersion version.Number, source interface{}) (*subnet, error) {
+	readFunc, err := getSubnetDeserializationFunc(controllerVersion)
+	if err != nil {
+		return nil, errors.Trace(err)
+	}
+
+	checker := schema.StringMap(schema.Any())
+	coerced, err := checker.Coerce(source, nil)
+	if err != nil {
+		return nil, errors.Annotatef(err, "subnet base schema check failed")
+	}
+	valid := coerced.(map[string]interface{})
+	return readFunc(valid)
+}
+
 func readSubnets(controllerVersion version.Number, source interface{}) ([]*subnet, error) {
 	checker := schema.List(schema.StringMap(schema.Any()))
 	coerced, err := checker.Coerce(source, nil)
@@ -72,6 +294,14 @@ func readSubnets(controllerVersion version.Number, source interface{}) ([]*subne
 	}
 	valid := coerced.([]interface{})
 
+	readFunc, err := getSubnetDeserializationFunc(controllerVersion)
+	if err != nil {
+		return nil, errors.Trace(err)
+	}
+	return readSubnetList(valid, readFunc)
+}
+
+func getSubnetDeserializationFunc(controllerVersion version.Number) (subnetDeserializationFunc, error) {
 	var deserialisationVersion version.Number
 	for v := range subnetDeserializationFuncs {
 		if v.Compare(deserialisationVersion) > 0 && v.Compare(controllerVersion) <= 0 {
@@ -81,8 +311,7 @@ func readSubnets(controllerVersion version.Number, source interface{}) ([]*subne
 	if deserialisationVersion == version.Zero {
 		return nil, errors.Errorf("no subnet read func for version %s", controllerVersion)
 	}
-	readFunc := subnetDeserializationFuncs[deserialisationVersion]
-	return readSubnetList(valid, readFunc)
+	return subnetDeserializationFuncs[deserialisationVersion], nil
 }
 
 // readSubnetList expects the values of the sourceList to be string maps.
@@ -118,8 +347,23 @@ func subnet_2_0(source map[string]interface{}) (*subnet, error) {
 		"cidr":         schema.String(),
 		"vlan":         schema.StringMap(schema.Any()),
 		"dns_servers":  schema.OneOf(schema.Nil(""), schema.List(schema.String())),
+		"allow_proxy":  schema.Bool(),
+
+		"managed":                     schema.Bool(),
+		"active_discovery":            schema.Bool(),
+		"allow_dns":                   schema.Bool(),
+		"disabled_boot_architectures": schema.OneOf(schema.Nil(""), schema.List(schema.String())),
 	}
-	checker := schema.FieldMap(fields, nil) // no defaults
+	defaults := schema.Defaults{
+		// Older versions of MAAS don't report these fields, and behave as
+		// if they had their default values.
+		"allow_proxy":                 true,
+		"managed":                     true,
+		"active_discovery":            false,
+		"allow_dns":                   true,
+		"disabled_boot_architectures": nil,
+	}
+	checker := schema.FieldMap(fields, defaults)
 	coerced, err := checker.Coerce(source, nil)
 	if err != nil {
 		return nil, errors.Annotatef(err, "subnet 2.0 schema check failed")
@@ -147,6 +391,12 @@ func subnet_2_0(source map[string]interface{}) (*subnet, error) {
 		gateway:     gateway,
 		cidr:        valid["cidr"].(string),
 		dnsServers:  convertToStringSlice(valid["dns_servers"]),
+		allowProxy:  valid["allow_proxy"].(bool),
+
+		managed:                   valid["managed"].(bool),
+		activeDiscovery:           valid["active_discovery"].(bool),
+		allowDNS:                  valid["allow_dns"].(bool),
+		disabledBootArchitectures: convertToStringSlice(valid["disabled_boot_architectures"]),
 	}
 	return result, nil
 }
@@ -4,12 +4,19 @@
 package gomaasapi
 
 import (
+	"net/http"
+	"time"
+
+	"github.com/juju/errors"
+	"github.com/juju/testing"
 	jc "github.com/juju/testing/checkers"
 	"github.com/juju/version"
 	gc "gopkg.in/check.v1"
 )
 
-type subnetSuite struct{}
+type subnetSuite struct {
+	testing.LoggingCleanupSuite
+}
 
 var _ = gc.Suite(&subnetSuite{})
 
@@ -18,6 +25,194 @@ func (*subnetSuite) TestNilVLAN(c *gc.C) {
 	c.Check(empty.VLAN() == nil, jc.IsTrue)
 }
 
+func (s *subnetSuite) getServerAndSubnet(c *gc.C) (*SimpleTestServer, *subnet) {
+	server, controller := createTestServerController(c, s)
+	server.AddGetResponse("/api/2.0/spaces/", http.StatusOK, spacesResponse)
+	spaces, err := controller.Spaces()
+	c.Assert(err, jc.ErrorIsNil)
+	subnet := spaces[0].Subnets()[0].(*subnet)
+	server.ResetRequests()
+	return server, subnet
+}
+
+func (s *subnetSuite) TestScan(c *gc.C) {
+	server, subnet := s.getServerAndSubnet(c)
+	server.AddPostResponse("/api/2.0/discovery/?op=scan", http.StatusOK, scanResultResponse)
+	c.Assert(subnet.LastScan(), gc.IsNil)
+	before := time.Now().UTC()
+	result, err := subnet.Scan(SubnetScanArgs{
+		Force:   true,
+		Threads: 4,
+	})
+	c.Assert(err, jc.ErrorIsNil)
+	c.Check(result.StartedOn(), jc.DeepEquals, []string{"4y3h7n"})
+	c.Check(result.Requested().Before(before), jc.IsFalse)
+	c.Check(subnet.LastScan(), gc.Equals, result)
+
+	form := server.LastRequest().PostForm
+	c.Check(form.Get("cidr"), gc.Equals, "192.168.122.0/24")
+	c.Check(form.Get("force"), gc.Equals, "true")
+	c.Check(form.Get("threads"), gc.Equals, "4")
+	_, found := form["slow"]
+	c.Check(found, jc.IsFalse)
+}
+
+func (s *subnetSuite) TestScanForbidden(c *gc.C) {
+	server, subnet := s.getServerAndSubnet(c)
+	server.AddPostResponse("/api/2.0/discovery/?op=scan", http.StatusForbidden, "admins only")
+	_, err := subnet.Scan(SubnetScanArgs{})
+	c.Assert(err, jc.Satisfies, IsPermissionError)
+	c.Assert(subnet.LastScan(), gc.IsNil)
+}
+
+func (s *subnetSuite) TestUpdate(c *gc.C) {
+	server, subnet := s.getServerAndSubnet(c)
+	response := updateJSONMap(c, subnetItemResponse, map[string]interface{}{
+		"name":        "frontend",
+		"gateway_ip":  "192.168.122.254",
+		"dns_servers": []string{"192.168.122.2", "192.168.122.3"},
+	})
+	server.AddPutResponse(subnet.resourceURI, http.StatusOK, response)
+	managed := false
+	err := subnet.Update(UpdateSubnetArgs{
+		Name:       "frontend",
+		GatewayIP:  "192.168.122.254",
+		DNSServers: []string{"192.168.122.2", "192.168.122.3"},
+		Managed:    &managed,
+	})
+	c.Assert(err, jc.ErrorIsNil)
+	c.Check(subnet.Name(), gc.Equals, "frontend")
+	c.Check(subnet.Gateway(), gc.Equals, "192.168.122.254")
+	c.Check(subnet.DNSServers(), jc.DeepEquals, []string{"192.168.122.2", "192.168.122.3"})
+
+	form := server.LastRequest().PostForm
+	c.Check(form, gc.HasLen, 4)
+	c.Check(form.Get("dns_servers"), gc.Equals, "192.168.122.2,192.168.122.3")
+	c.Check(form.Get("managed"), gc.Equals, "false")
+}
+
+func (s *subnetSuite) TestUpdateVLAN(c *gc.C) {
+	server, subnet := s.getServerAndSubnet(c)
+	server.AddPutResponse(subnet.resourceURI, http.StatusOK, subnetItemResponse)
+	err := subnet.Update(UpdateSubnetArgs{VLAN: &fakeVLAN{id: 5001}})
+	c.Assert(err, jc.ErrorIsNil)
+
+	form := server.LastRequest().PostForm
+	c.Check(form, gc.HasLen, 1)
+	c.Check(form.Get("vlan"), gc.Equals, "5001")
+}
+
+func (s *subnetSuite) TestUpdateModernFields(c *gc.C) {
+	server, subnet := s.getServerAndSubnet(c)
+	response := updateJSONMap(c, subnetItemResponse, map[string]interface{}{
+		"active_discovery":            true,
+		"allow_dns":                   false,
+		"allow_proxy":                 false,
+		"disabled_boot_architectures": []string{"pxe", "uefi_ebc"},
+	})
+	server.AddPutResponse(subnet.resourceURI, http.StatusOK, response)
+	yes, no := true, false
+	err := subnet.Update(UpdateSubnetArgs{
+		ActiveDiscovery:           &yes,
+		AllowProxy:                &no,
+		AllowDNS:                  &no,
+		DisabledBootArchitectures: []string{"pxe", "uefi_ebc"},
+	})
+	c.Assert(err, jc.ErrorIsNil)
+	c.Check(subnet.ActiveDiscovery(), jc.IsTrue)
+	c.Check(subnet.AllowProxy(), jc.IsFalse)
+	c.Check(subnet.AllowDNS(), jc.IsFalse)
+	c.Check(subnet.DisabledBootArchitectures(), jc.DeepEquals, []string{"pxe", "uefi_ebc"})
+
+	form := server.LastRequest().PostForm
+	c.Check(form, gc.HasLen, 4)
+	c.Check(form.Get("active_discovery"), gc.Equals, "true")
+	c.Check(form.Get("allow_proxy"), gc.Equals, "false")
+	c.Check(form.Get("allow_dns"), gc.Equals, "false")
+	c.Check(form.Get("disabled_boot_architectures"), gc.Equals, "pxe,uefi_ebc")
+}
+
+func (s *subnetSuite) TestUpdateClearDisabledBootArchitectures(c *gc.C) {
+	server, subnet := s.getServerAndSubnet(c)
+	server.AddPutResponse(subnet.resourceURI, http.StatusOK, subnetItemResponse)
+	err := subnet.Update(UpdateSubnetArgs{ClearDisabledBootArchitectures: true})
+	c.Assert(err, jc.ErrorIsNil)
+	c.Check(subnet.DisabledBootArchitectures(), gc.HasLen, 0)
+
+	form := server.LastRequest().PostForm
+	c.Check(form, gc.HasLen, 1)
+	c.Check(form["disabled_boot_architectures"], jc.DeepEquals, []string{""})
+}
+
+func (s *subnetSuite) TestUpdateInvalid(c *gc.C) {
+	server, subnet := s.getServerAndSubnet(c)
+	err := subnet.Update(UpdateSubnetArgs{
+		DisabledBootArchitectures:      []string{"pxe"},
+		ClearDisabledBootArchitectures: true,
+	})
+	c.Assert(err, jc.Satisfies, errors.IsNotValid)
+	c.Check(err.Error(), gc.Equals, "both DisabledBootArchitectures and ClearDisabledBootArchitectures not valid")
+	c.Check(server.RequestCount(), gc.Equals, 0)
+}
+
+func (s *subnetSuite) TestUpdateBadRequest(c *gc.C) {
+	server, subnet := s.getServerAndSubnet(c)
+	server.AddPutResponse(subnet.resourceURI, http.StatusBadRequest, "bad gateway")
+	err := subnet.Update(UpdateSubnetArgs{GatewayIP: "10.0.0.1"})
+	c.Assert(err, jc.Satisfies, IsBadRequestError)
+	c.Check(err.Error(), gc.Equals, "bad gateway")
+}
+
+func (s *subnetSuite) TestDelete(c *gc.C) {
+	server, subnet := s.getServerAndSubnet(c)
+	server.AddDeleteResponse(subnet.resourceURI, http.StatusNoContent, "")
+	err := subnet.Delete()
+	c.Assert(err, jc.ErrorIsNil)
+}
+
+func (s *subnetSuite) TestDeleteMissing(c *gc.C) {
+	_, subnet := s.getServerAndSubnet(c)
+	err := subnet.Delete()
+	c.Assert(err, jc.Satisfies, IsNoMatchError)
+}
+
+func (s *subnetSuite) TestDeleteForbidden(c *gc.C) {
+	server, subnet := s.getServerAndSubnet(c)
+	server.AddDeleteResponse(subnet.resourceURI, http.StatusForbidden, "")
+	err := subnet.Delete()
+	c.Assert(err, jc.Satisfies, IsPermissionError)
+}
+
+func (*subnetSuite) TestReadSubnet(c *gc.C) {
+	subnet, err := readSubnet(twoDotOh, parseJSON(c, subnetItemResponse))
+	c.Assert(err, jc.ErrorIsNil)
+	c.Check(subnet.ID(), gc.Equals, 34)
+	c.Check(subnet.CIDR(), gc.Equals, "192.168.122.0/24")
+	// Older versions of MAAS don't report these fields.
+	c.Check(subnet.Managed(), jc.IsTrue)
+	c.Check(subnet.ActiveDiscovery(), jc.IsFalse)
+	c.Check(subnet.AllowProxy(), jc.IsTrue)
+	c.Check(subnet.AllowDNS(), jc.IsTrue)
+	c.Check(subnet.DisabledBootArchitectures(), gc.HasLen, 0)
+}
+
+func (*subnetSuite) TestReadSubnetModernFields(c *gc.C) {
+	source := updateJSONMap(c, subnetItemResponse, map[string]interface{}{
+		"managed":                     false,
+		"active_discovery":            true,
+		"allow_proxy":                 false,
+		"allow_dns":                   false,
+		"disabled_boot_architectures": []string{"uefi_ebc"},
+	})
+	subnet, err := readSubnet(twoDotOh, parseJSON(c, source))
+	c.Assert(err, jc.ErrorIsNil)
+	c.Check(subnet.Managed(), jc.IsFalse)
+	c.Check(subnet.ActiveDiscovery(), jc.IsTrue)
+	c.Check(subnet.AllowProxy(), jc.IsFalse)
+	c.Check(subnet.AllowDNS(), jc.IsFalse)
+	c.Check(subnet.DisabledBootArchitectures(), jc.DeepEquals, []string{"uefi_ebc"})
+}
+
 func (*subnetSuite) TestReadSubnetsBadSchema(c *gc.C) {
 	_, err := readSubnets(twoDotOh, "wat?")
 	c.Assert(err.Error(), gc.Equals, `subnet base schema check failed: expected list, got string("wat?")`)
@@ -97,3 +292,27 @@ var subnetResponse = `
     }
 ]
 `
+
+const subnetItemResponse = `
+{
+    "gateway_ip": null,
+    "name": "192.168.122.0/24",
+    "vlan": {
+        "fabric": "fabric-1",
+        "resource_uri": "/MAAS/api/2.0/vlans/5001/",
+        "name": "untagged",
+        "secondary_rack": null,
+        "primary_rack": null,
+        "vid": 0,
+        "dhcp_on": false,
+        "id": 5001,
+        "mtu": 1500
+    },
+    "space": "space-0",
+    "id": 34,
+    "resource_uri": "/MAAS/api/2.0/subnets/34/",
+    "dns_servers": null,
+    "cidr": "192.168.122.0/24",
+    "rdns_mode": 2
+}
+`
@@ -0,0 +1,326 @@
+// Copyright 2022 Canonical Ltd.
+// Licensed under the LGPLv3, see LICENCE file for details.
+
+package gomaasapi
+
+import (
+	"net/http"
+	"time"
+
+	"github.com/juju/errors"
+	"github.com/juju/schema"
+	"github.com/juju/version"
+)
+
+// SubnetAddressRange is a range of addresses of a subnet, as reported by
+// Subnet.ReservedIPRanges and Subnet.UnreservedIPRanges.
+type SubnetAddressRange struct {
+	Start        string
+	End          string
+	NumAddresses int
+	// Purpose lists what the addresses of a reserved range are used for,
+	// such as "reserved", "dynamic" or "gateway-ip". It is empty for
+	// unreserved ranges.
+	Purpose []string
+}
+
+// SubnetStatistics summarises the address usage of a subnet.
+type SubnetStatistics struct {
+	TotalAddresses   int
+	NumAvailable     int
+	NumUnavailable   int
+	LargestAvailable int
+	// Usage is the fraction of the addresses that are unavailable, between
+	// zero and one.
+	Usage float64
+	// UsageString and AvailableString are the percentages as formatted
+	// by MAAS, such as "12%".
+	UsageString     string
+	AvailableString string
+	FirstAddress    string
+	LastAddress     string
+	IPVersion       int
+}
+
+// SubnetIPAddress is an address in use in a subnet.
+type SubnetIPAddress struct {
+	IP string
+	// AllocType describes how the address was allocated, such as "Auto",
+	// "Sticky", "User reserved" or "DHCP".
+	AllocType string
+	Created   time.Time
+	Updated   time.Time
+	// User is the owner of the address, if any.
+	User string
+	// The node fields are empty for addresses that aren't assigned to a
+	// node.
+	SystemID string
+	Hostname string
+	NodeType string
+}
+
+// ReservedIPRanges implements Subnet.
+func (s *subnet) ReservedIPRanges() ([]SubnetAddressRange, error) {
+	source, err := s.controller.getOp(s.resourceURI, "reserved_ip_ranges")
+	if err != nil {
+		return nil, errors.Trace(subnetQueryError(err))
+	}
+	return readSubnetAddressRanges(s.controller.schemaVersion(), source)
+}
+
+// UnreservedIPRanges implements Subnet.
+func (s *subnet) UnreservedIPRanges() ([]SubnetAddressRange, error) {
+	source, err := s.controller.getOp(s.resourceURI, "unreserved_ip_ranges")
+	if err != nil {
+		return nil, errors.Trace(subnetQueryError(err))
+	}
+	return readSubnetAddressRanges(s.controller.schemaVersion(), source)
+}
+
+// Statistics implements Subnet.
+func (s *subnet) Statistics() (SubnetStatistics, error) {
+	source, err := s.controller.getOp(s.resourceURI, "statistics")
+	if err != nil {
+		return SubnetStatistics{}, errors.Trace(subnetQueryError(err))
+	}
+	return readSubnetStatistics(s.controller.schemaVersion(), source)
+}
+
+// IPAddresses implements Subnet.
+func (s *subnet) IPAddresses() ([]SubnetIPAddress, error) {
+	params := NewURLParams()
+	params.MaybeAddBool("with_username", true)
+	params.MaybeAddBool("with_summary", true)
+	source, err := s.controller._get(s.resourceURI, "ip_addresses", params.Values)
+	if err != nil {
+		return nil, errors.Trace(subnetQueryError(err))
+	}
+	return readSubnetIPAddresses(s.controller.schemaVersion(), s.controller.timeLocation, source)
+}
+
+func subnetQueryError(err error) error {
+	if svrErr, ok := errors.Cause(err).(ServerError); ok {
+		switch svrErr.StatusCode {
+		case http.StatusNotFound:
+			return wrapError(err, NewNoMatchError(svrErr.BodyMessage))
+		case http.StatusForbidden:
+			return wrapError(err, NewPermissionError(svrErr.BodyMessage))
+		}
+	}
+	return NewUnexpectedError(err)
+}
+
+func readSubnetAddressRanges(controllerVersion version.Number, source interface{}) ([]SubnetAddressRange, error) {
+	var deserialisationVersion version.Number
+	for v := range subnetAddressRangeDeserializationFuncs {
+		if v.Compare(deserialisationVersion) > 0 && v.Compare(controllerVersion) <= 0 {
+			deserialisationVersion = v
+		}
+	}
+	if deserialisationVersion == version.Zero {
+		return nil, NewUnsupportedVersionError("no subnet address range read func for version %s", controllerVersion)
+	}
+	readFunc := subnetAddressRangeDeserializationFuncs[deserialisationVersion]
+
+	checker := schema.List(schema.StringMap(schema.Any()))
+	coerced, err := checker.Coerce(source, nil)
+	if err != nil {
+		return nil, WrapWithDeserializationError(err, "subnet address range base schema check failed")
+	}
+	valid := coerced.([]interface{})
+	result := make([]SubnetAddressRange, 0, len(valid))
+	for i, value := range valid {
+		addressRange, err := readFunc(value.(map[string]interface{}))
+		if err != nil {
+			return nil, errors.Annotatef(err, "subnet address range %d", i)
+		}
+		result = append(result, addressRange)
+	}
+	return result, nil
+}
+
+type subnetAddressRangeDeserializationFunc func(map[string]interface{}) (SubnetAddressRange, error)
+
+var subnetAddressRangeDeserializationFuncs = map[version.Number]subnetAddressRangeDeserializationFunc{
+	twoDotOh: subnetAddressRange_2_0,
+}
+
+func subnetAddressRange_2_0(source map[string]interface{}) (SubnetAddressRange, error) {
+	fields := schema.Fields{
+		"start":         schema.String(),
+		"end":           schema.String(),
+		"num_addresses": schema.ForceInt(),
+		"purpose":       schema.List(schema.String()),
+	}
+	defaults := schema.Defaults{
+		"purpose": []interface{}{},
+	}
+	checker := schema.FieldMap(fields, defaults)
+	coerced, err := checker.Coerce(source, nil)
+	if err != nil {
+		return SubnetAddressRange{}, WrapWithDeserializationError(err, "subnet address range 2.0 schema check failed")
+	}
+	valid := coerced.(map[string]interface{})
+	// From here we know that the map returned from the schema coercion
+	// contains fields of the right type.
+
+	return SubnetAddressRange{
+		Start:        valid["start"].(string),
+		End:          valid["end"].(string),
+		NumAddresses: valid["num_addresses"].(int),
+		Purpose:      convertToStringSlice(valid["purpose"]),
+	}, nil
+}
+
+func readSubnetStatistics(controllerVersion version.Number, source interface{}) (SubnetStatistics, error) {
+	var deserialisationVersion version.Number
+	for v := range subnetStatisticsDeserializationFuncs {
+		if v.Compare(deserialisationVersion) > 0 && v.Compare(controllerVersion) <= 0 {
+			deserialisationVersion = v
+		}
+	}
+	if deserialisationVersion == version.Zero {
+		return SubnetStatistics{}, NewUnsupportedVersionError("no subnet statistics read func for version %s", controllerVersion)
+	}
+
+	checker := schema.StringMap(schema.Any())
+	coerced, err := checker.Coerce(source, nil)
+	if err != nil {
+		return SubnetStatistics{}, WrapWithDeserializationError(err, "subnet statistics base schema check failed")
+	}
+	valid := coerced.(map[string]interface{})
+	return subnetStatisticsDeserializationFuncs[deserialisationVersion](valid)
+}
+
+type subnetStatisticsDeserializationFunc func(map[string]interface{}) (SubnetStatistics, error)
+
+var subnetStatisticsDeserializationFuncs = map[version.Number]subnetStatisticsDeserializationFunc{
+	twoDotOh: subnetStatistics_2_0,
+}
+
+func subnetStatistics_2_0(source map[string]interface{}) (SubnetStatistics, error) {
+	fields := schema.Fields{
+		"total_addresses":   schema.ForceInt(),
+		"num_available":     schema.ForceInt(),
+		"num_unavailable":   schema.ForceInt(),
+		"largest_available": schema.ForceInt(),
+		"usage":             schema.Float(),
+		"usage_string":      schema.String(),
+		"available_string":  schema.String(),
+		"first_address":     schema.String(),
+		"last_address":      schema.String(),
+		"ip_version":        schema.ForceInt(),
+	}
+	checker := schema.FieldMap(fields, nil) // no defaults
+	coerced, err := checker.Coerce(source, nil)
+	if err != nil {
+		return SubnetStatistics{}, WrapWithDeserializationError(err, "subnet statistics 2.0 schema check failed")
+	}
+	valid := coerced.(map[string]interface{})
+	// From here we know that the map returned from the schema coercion
+	// contains fields of the right type.
+
+	return SubnetStatistics{
+		TotalAddresses:   valid["total_addresses"].(int),
+		NumAvailable:     valid["num_available"].(int),
+		NumUnavailable:   valid["num_unavailable"].(int),
+		LargestAvailable: valid["largest_available"].(int),
+		Usage:            valid["usage"].(float64),
+		UsageString:      valid["usage_string"].(string),
+		AvailableString:  valid["available_string"].(string),
+		FirstAddress:     valid["first_address"].(string),
+		LastAddress:      valid["last_address"].(string),
+		IPVersion:        valid["ip_version"].(int),
+	}, nil
+}
+
+func readSubnetIPAddresses(controllerVersion version.Number, location *time.Location, source interface{}) ([]SubnetIPAddress, error) {
+	var deserialisationVersion version.Number
+	for v := range subnetIPAddressDeserializationFuncs {
+		if v.Compare(deserialisationVersion) > 0 && v.Compare(controllerVersion) <= 0 {
+			deserialisationVersion = v
+		}
+	}
+	if deserialisationVersion == version.Zero {
+		return nil, NewUnsupportedVersionError("no subnet ip address read func for version %s", controllerVersion)
+	}
+	readFunc := subnetIPAddressDeserializationFuncs[deserialisationVersion]
+
+	checker := schema.List(schema.StringMap(schema.Any()))
+	coerced, err := checker.Coerce(source, nil)
+	if err != nil {
+		return nil, WrapWithDeserializationError(err, "subnet ip address base schema check failed")
+	}
+	valid := coerced.([]interface{})
+	result := make([]SubnetIPAddress, 0, len(valid))
+	for i, value := range valid {
+		address, err := readFunc(value.(map[string]interface{}), location)
+		if err != nil {
+			return nil, errors.Annotatef(err, "subnet ip address %d", i)
+		}
+		result = append(result, address)
+	}
+	return result, nil
+}
+
+type subnetIPAddressDeserializationFunc func(map[string]interface{}, *time.Location) (SubnetIPAddress, error)
+
+var subnetIPAddressDeserializationFuncs = map[version.Number]subnetIPAddressDeserializationFunc{
+	twoDotOh: subnetIPAddress_2_0,
+}
+
+func subnetIPAddress_2_0(source map[string]interface{}, location *time.Location) (SubnetIPAddress, error) {
+	fields := schema.Fields{
+		"ip":              schema.String(),
+		"alloc_type_name": schema.String(),
+		"created":         schema.String(),
+		"updated":         schema.String(),
+		"user":            schema.OneOf(schema.Nil(""), schema.String()),
+		"node_summary": schema.OneOf(schema.Nil(""), schema.FieldMap(
+			schema.Fields{
+				"system_id":      schema.String(),
+				"hostname":       schema.String(),
+				"node_type_name": schema.String(),
+			},
+			schema.Defaults{
+				"hostname":       "",
+				"node_type_name": "",
+			},
+		)),
+	}
+	defaults := schema.Defaults{
+		"user":         nil,
+		"node_summary": nil,
+	}
+	checker := schema.FieldMap(fields, defaults)
+	coerced, err := checker.Coerce(source, nil)
+	if err != nil {
+		return SubnetIPAddress{}, WrapWithDeserializationError(err, "subnet ip address 2.0 schema check failed")
+	}
+	valid := coerced.(map[string]interface{})
+	// From here we know that the map returned from the schema coercion
+	// contains fields of the right type.
+
+	created, err := parseTimestamp(valid["created"].(string), location)
+	if err != nil {
+		return SubnetIPAddress{}, WrapWithDeserializationError(err, "created")
+	}
+	updated, err := parseTimestamp(valid["updated"].(string), location)
+	if err != nil {
+		return SubnetIPAddress{}, WrapWithDeserializationError(err, "updated")
+	}
+	user, _ := valid["user"].(string)
+	result := SubnetIPAddress{
+		IP:        valid["ip"].(string),
+		AllocType: valid["alloc_type_name"].(string),
+		Created:   created,
+		Updated:   updated,
+		User:      user,
+	}
+	if summary, ok := valid["node_summary"].(map[string]interface{}); ok {
+		result.SystemID = summary["system_id"].(string)
+		result.Hostname = summary["hostname"].(string)
+		result.NodeType = summary["node_type_name"].(string)
+	}
+	return result, nil
+}
@@ -0,0 +1,206 @@
+// Copyright 2022 Canonical Ltd.
+// Licensed under the LGPLv3, see LICENCE file for details.
+
+package gomaasapi
+
+import (
+	"net/http"
+	"time"
+
+	"github.com/juju/testing"
+	jc "github.com/juju/testing/checkers"
+	"github.com/juju/version"
+	gc "gopkg.in/check.v1"
+)
+
+type subnetUsageSuite struct {
+	testing.LoggingCleanupSuite
+}
+
+var _ = gc.Suite(&subnetUsageSuite{})
+
+func (s *subnetUsageSuite) getServerAndSubnet(c *gc.C) (*SimpleTestServer, *subnet) {
+	server, controller := createTestServerController(c, s)
+	server.AddGetResponse("/api/2.0/subnets/", http.StatusOK, subnetResponse)
+	subnets, err := controller.Subnets()
+	c.Assert(err, jc.ErrorIsNil)
+	server.ResetRequests()
+	return server, subnets[0].(*subnet)
+}
+
+func (s *subnetUsageSuite) TestReservedIPRanges(c *gc.C) {
+	server, subnet := s.getServerAndSubnet(c)
+	server.AddGetResponse(subnet.resourceURI+"?op=reserved_ip_ranges", http.StatusOK, reservedIPRangesResponse)
+	ranges, err := subnet.ReservedIPRanges()
+	c.Assert(err, jc.ErrorIsNil)
+	c.Assert(ranges, jc.DeepEquals, []SubnetAddressRange{{
+		Start:        "192.168.100.1",
+		End:          "192.168.100.1",
+		NumAddresses: 1,
+		Purpose:      []string{"gateway-ip"},
+	}, {
+		Start:        "192.168.100.100",
+		End:          "192.168.100.199",
+		NumAddresses: 100,
+		Purpose:      []string{"dynamic"},
+	}})
+}
+
+func (s *subnetUsageSuite) TestUnreservedIPRanges(c *gc.C) {
+	server, subnet := s.getServerAndSubnet(c)
+	server.AddGetResponse(subnet.resourceURI+"?op=unreserved_ip_ranges", http.StatusOK, unreservedIPRangesResponse)
+	ranges, err := subnet.UnreservedIPRanges()
+	c.Assert(err, jc.ErrorIsNil)
+	c.Assert(ranges, jc.DeepEquals, []SubnetAddressRange{{
+		Start:        "192.168.100.2",
+		End:          "192.168.100.99",
+		NumAddresses: 98,
+		Purpose:      []string{},
+	}})
+}
+
+func (s *subnetUsageSuite) TestIPRangesMissing(c *gc.C) {
+	_, subnet := s.getServerAndSubnet(c)
+	_, err := subnet.UnreservedIPRanges()
+	c.Assert(err, jc.Satisfies, IsNoMatchError)
+}
+
+func (s *subnetUsageSuite) TestStatistics(c *gc.C) {
+	server, subnet := s.getServerAndSubnet(c)
+	server.AddGetResponse(subnet.resourceURI+"?op=statistics", http.StatusOK, subnetStatisticsResponse)
+	stats, err := subnet.Statistics()
+	c.Assert(err, jc.ErrorIsNil)
+	c.Assert(stats, jc.DeepEquals, SubnetStatistics{
+		TotalAddresses:   254,
+		NumAvailable:     152,
+		NumUnavailable:   102,
+		LargestAvailable: 98,
+		Usage:            0.4015748031496063,
+		UsageString:      "40%",
+		AvailableString:  "60%",
+		FirstAddress:     "192.168.100.1",
+		LastAddress:      "192.168.100.254",
+		IPVersion:        4,
+	})
+}
+
+func (s *subnetUsageSuite) TestStatisticsForbidden(c *gc.C) {
+	server, subnet := s.getServerAndSubnet(c)
+	server.AddGetResponse(subnet.resourceURI+"?op=statistics", http.StatusForbidden, "no")
+	_, err := subnet.Statistics()
+	c.Assert(err, jc.Satisfies, IsPermissionError)
+}
+
+func (s *subnetUsageSuite) TestIPAddresses(c *gc.C) {
+	server, subnet := s.getServerAndSubnet(c)
+	server.AddGetResponse(subnet.resourceURI+"?op=ip_addresses&with_summary=true&with_username=true", http.StatusOK, subnetIPAddressesResponse)
+	addresses, err := subnet.IPAddresses()
+	c.Assert(err, jc.ErrorIsNil)
+	c.Assert(addresses, jc.DeepEquals, []SubnetIPAddress{{
+		IP:        "192.168.100.4",
+		AllocType: "Auto",
+		Created:   time.Date(2022, 3, 1, 10, 0, 0, 0, time.UTC),
+		Updated:   time.Date(2022, 3, 1, 10, 5, 0, 0, time.UTC),
+		User:      "admin",
+		SystemID:  "4y3ha3",
+		Hostname:  "untasted-markita",
+		NodeType:  "Machine",
+	}, {
+		IP:        "192.168.100.150",
+		AllocType: "DHCP",
+		Created:   time.Date(2022, 3, 2, 8, 30, 0, 0, time.UTC),
+		Updated:   time.Date(2022, 3, 2, 8, 30, 0, 0, time.UTC),
+	}})
+}
+
+func (*subnetUsageSuite) TestReadSubnetIPAddressesLocation(c *gc.C) {
+	location := time.FixedZone("UTC+2", 2*60*60)
+	addresses, err := readSubnetIPAddresses(twoDotOh, location, parseJSON(c, subnetIPAddressesResponse))
+	c.Assert(err, jc.ErrorIsNil)
+	c.Check(addresses[0].Created, gc.Equals, time.Date(2022, 3, 1, 8, 0, 0, 0, time.UTC))
+}
+
+func (*subnetUsageSuite) TestReadSubnetIPAddressesBadTimestamp(c *gc.C) {
+	source := parseJSON(c, subnetIPAddressesResponse)
+	source.([]interface{})[1].(map[string]interface{})["created"] = "yesterday"
+	_, err := readSubnetIPAddresses(twoDotOh, nil, source)
+	c.Assert(err, jc.Satisfies, IsDeserializationError)
+}
+
+func (*subnetUsageSuite) TestLowVersion(c *gc.C) {
+	_, err := readSubnetAddressRanges(version.MustParse("1.9.0"), parseJSON(c, reservedIPRangesResponse))
+	c.Assert(err, jc.Satisfies, IsUnsupportedVersionError)
+	_, err = readSubnetStatistics(version.MustParse("1.9.0"), parseJSON(c, subnetStatisticsResponse))
+	c.Assert(err, jc.Satisfies, IsUnsupportedVersionError)
+	_, err = readSubnetIPAddresses(version.MustParse("1.9.0"), nil, parseJSON(c, subnetIPAddressesResponse))
+	c.Assert(err, jc.Satisfies, IsUnsupportedVersionError)
+}
+
+const (
+	reservedIPRangesResponse = `
+[
+    {
+        "start": "192.168.100.1",
+        "end": "192.168.100.1",
+        "purpose": ["gateway-ip"],
+        "num_addresses": 1
+    },
+    {
+        "start": "192.168.100.100",
+        "end": "192.168.100.199",
+        "purpose": ["dynamic"],
+        "num_addresses": 100
+    }
+]
+`
+	unreservedIPRangesResponse = `
+[
+    {
+        "start": "192.168.100.2",
+        "end": "192.168.100.99",
+        "num_addresses": 98
+    }
+]
+`
+	subnetStatisticsResponse = `
+{
+    "num_available": 152,
+    "largest_available": 98,
+    "num_unavailable": 102,
+    "total_addresses": 254,
+    "usage": 0.4015748031496063,
+    "usage_string": "40%",
+    "available_string": "60%",
+    "first_address": "192.168.100.1",
+    "last_address": "192.168.100.254",
+    "ip_version": 4
+}
+`
+	subnetIPAddressesResponse = `
+[
+    {
+        "ip": "192.168.100.4",
+        "alloc_type": 0,
+        "alloc_type_name": "Auto",
+        "created": "2022-03-01T10:00:00.000",
+        "updated": "2022-03-01T10:05:00.000",
+        "user": "admin",
+        "node_summary": {
+            "system_id": "4y3ha3",
+            "node_type": 0,
+            "node_type_name": "Machine",
+            "hostname": "untasted-markita",
+            "fqdn": "untasted-markita.maas",
+            "via": "eth0"
+        }
+    },
+    {
+        "ip": "192.168.100.150",
+        "alloc_type": 6,
+        "alloc_type_name": "DHCP",
+        "created": "2022-03-02T08:30:00.000",
+        "updated": "2022-03-02T08:30:00.000"
+    }
+]
+`
+)
@@ -4,12 +4,17 @@
 package gomaasapi
 
 import (
+	"net/http"
+
+	"github.com/juju/collections/set"
 	"github.com/juju/errors"
 	"github.com/juju/schema"
 	"github.com/juju/version"
 )
 
 type tag struct {
+	controller *controller
+
 	resourceURI string
 
 	name       string
@@ -18,20 +23,307 @@ type tag struct {
 	kernelOpts string
 }
 
-func (tag tag) Name() string {
-	return tag.name
+func (t *tag) updateFrom(other *tag) {
+	t.resourceURI = other.resourceURI
+	t.name = other.name
+	t.comment = other.comment
+	t.definition = other.definition
+	t.kernelOpts = other.kernelOpts
+}
+
+// Name implements Tag.
+func (t *tag) Name() string {
+	return t.name
+}
+
+// Comment implements Tag.
+func (t *tag) Comment() string {
+	return t.comment
+}
+
+// Definition implements Tag.
+func (t *tag) Definition() string {
+	return t.definition
+}
+
+// KernelOpts implements Tag.
+func (t *tag) KernelOpts() string {
+	return t.kernelOpts
+}
+
+// UpdateTagArgs is an argument struct for Tag.Update. Only the fields that
+// are set are changed.
+type UpdateTagArgs struct {
+	Name       string
+	Comment    string
+	Definition string
+	KernelOpts string
+}
+
+// Update implements Tag.
+func (t *tag) Update(args UpdateTagArgs) error {
+	params := NewURLParams()
+	params.MaybeAdd("name", args.Name)
+	params.MaybeAdd("comment", args.Comment)
+	params.MaybeAdd("definition", args.Definition)
+	params.MaybeAdd("kernel_opts", args.KernelOpts)
+	source, err := t.controller.put(t.resourceURI, params.Values)
+	if err != nil {
+		if svrErr, ok := errors.Cause(err).(ServerError); ok {
+			switch svrErr.StatusCode {
+			case http.StatusNotFound:
+				return wrapError(err, NewNoMatchError(svrErr.BodyMessage))
+			case http.StatusForbidden:
+				return wrapError(err, NewPermissionError(svrErr.BodyMessage))
+			case http.StatusBadRequest:
+				return wrapError(err, NewBadRequestError(svrErr.BodyMessage))
+			}
+		}
+		return NewUnexpectedError(err)
+	}
+
+	response, err := readTag(t.controller.schemaVersion(), source)
+	if err != nil {
+		return errors.Trace(err)
+	}
+	t.updateFrom(response)
+	return nil
+}
+
+// Delete implements Tag.
+func (t *tag) Delete() error {
+	err := t.controller.delete(t.resourceURI)
+	if err != nil {
+		if svrErr, ok := errors.Cause(err).(ServerError); ok {
+			switch svrErr.StatusCode {
+			case http.StatusNotFound:
+				return wrapError(err, NewNoMatchError(svrErr.BodyMessage))
+			case http.StatusForbidden:
+				return wrapError(err, NewPermissionError(svrErr.BodyMessage))
+			}
+		}
+		return NewUnexpectedError(err)
+	}
+	return nil
+}
+
+// Rebuild implements Tag.
+func (t *tag) Rebuild() error {
+	_, err := t.controller._postRaw(t.resourceURI, "rebuild", nil, nil)
+	if err != nil {
+		if svrErr, ok := errors.Cause(err).(ServerError); ok {
+			switch svrErr.StatusCode {
+			case http.StatusNotFound:
+				return wrapError(err, NewNoMatchError(svrErr.BodyMessage))
+			case http.StatusForbidden:
+				return wrapError(err, NewPermissionError(svrErr.BodyMessage))
+			case http.StatusBadRequest:
+				// MAAS can't rebuild tags without a definition.
+				return wrapError(err, NewBadRequestError(svrErr.BodyMessage))
+			}
+		}
+		return NewUnexpectedError(err)
+	}
+	return nil
+}
+
+// Machines implements Tag.
+func (t *tag) Machines() ([]Machine, error) {
+	source, err := t.getNodes("machines")
+	if err != nil {
+		return nil, errors.Trace(err)
+	}
+	machines, err := readMachines(t.controller.schemaVersion(), source)
+	if err != nil {
+		return nil, errors.Trace(err)
+	}
+	var result []Machine
+	for _, m := range machines {
+		m.setController(t.controller)
+		result = append(result, m)
+	}
+	return result, nil
+}
+
+// Devices implements Tag.
+func (t *tag) Devices() ([]Device, error) {
+	source, err := t.getNodes("devices")
+	if err != nil {
+		return nil, errors.Trace(err)
+	}
+	devices, err := readDevices(t.controller.schemaVersion(), source)
+	if err != nil {
+		return nil, errors.Trace(err)
+	}
+	var result []Device
+	for _, d := range devices {
+		d.setController(t.controller)
+		result = append(result, d)
+	}
+	return result, nil
+}
+
+// tagUpdateNodesBatchSize is the most system IDs sent in a single
+// update_nodes request.
+var tagUpdateNodesBatchSize = 500
+
+// UpdateTagNodesArgs is an argument struct for Tag.UpdateNodes.
+type UpdateTagNodesArgs struct {
+	// Add and Remove are the system IDs of the nodes to tag and untag.
+	Add    []string
+	Remove []string
+	// Definition, if set, must match the definition of the tag for MAAS
+	// to change the nodes. It stops the changes if the tag is redefined
+	// concurrently.
+	Definition string
+}
+
+// Validate ensures that there are nodes to add or remove, and that no node
+// is both added and removed.
+func (a *UpdateTagNodesArgs) Validate() error {
+	if len(a.Add) == 0 && len(a.Remove) == 0 {
+		return errors.NotValidf("missing Add and Remove")
+	}
+	removed := set.NewStrings(a.Remove...)
+	for _, systemID := range a.Add {
+		if removed.Contains(systemID) {
+			return errors.NotValidf("adding and removing %q", systemID)
+		}
+	}
+	return nil
+}
+
+// UpdateNodes implements Tag.
+func (t *tag) UpdateNodes(args UpdateTagNodesArgs) (int, int, error) {
+	if err := args.Validate(); err != nil {
+		return 0, 0, errors.Trace(err)
+	}
+	var added, removed int
+	for _, batch := range []struct {
+		name      string
+		systemIDs []string
+	}{
+		{"add", args.Add},
+		{"remove", args.Remove},
+	} {
+		for start := 0; start < len(batch.systemIDs); start += tagUpdateNodesBatchSize {
+			end := start + tagUpdateNodesBatchSize
+			if end > len(batch.systemIDs) {
+				end = len(batch.systemIDs)
+			}
+			params := NewURLParams()
+			params.MaybeAddMany(batch.name, batch.systemIDs[start:end])
+			params.MaybeAdd("definition", args.Definition)
+			batchAdded, batchRemoved, err := t.updateNodes(params)
+			if err != nil {
+				return added, removed, errors.Trace(err)
+			}
+			added += batchAdded
+			removed += batchRemoved
+		}
+	}
+	return added, removed, nil
 }
 
-func (tag tag) Comment() string {
-	return tag.comment
+func (t *tag) updateNodes(params *URLParams) (int, int, error) {
+	source, err := t.controller.post(t.resourceURI, "update_nodes", params.Values)
+	if err != nil {
+		if svrErr, ok := errors.Cause(err).(ServerError); ok {
+			switch svrErr.StatusCode {
+			case http.StatusNotFound:
+				return 0, 0, wrapError(err, NewNoMatchError(svrErr.BodyMessage))
+			case http.StatusForbidden:
+				return 0, 0, wrapError(err, NewPermissionError(svrErr.BodyMessage))
+			case http.StatusConflict:
+				// The definition doesn't match the tag.
+				return 0, 0, wrapError(err, NewCannotCompleteError(svrErr.BodyMessage))
+			case http.StatusBadRequest:
+				return 0, 0, wrapError(err, NewBadRequestError(svrErr.BodyMessage))
+			}
+		}
+		return 0, 0, NewUnexpectedError(err)
+	}
+	checker := schema.FieldMap(
+		schema.Fields{
+			"added":   schema.ForceInt(),
+			"removed": schema.ForceInt(),
+		},
+		schema.Defaults{"added": 0, "removed": 0},
+	)
+	coerced, err := checker.Coerce(source, nil)
+	if err != nil {
+		return 0, 0, WrapWithDeserializationError(err, "tag update nodes schema check failed")
+	}
+	valid := coerced.(map[string]interface{})
+	return valid["added"].(int), valid["removed"].(int), nil
 }
 
-func (tag tag) Definition() string {
-	return tag.definition
+// TaggedNode is a node with a tag, which may be a machine, a device or a
+// controller.
+type TaggedNode struct {
+	SystemID string
+	Hostname string
+	// Type is the kind of the node, such as "Machine" or "Rack
+	// controller".
+	Type string
 }
 
-func (tag tag) KernelOpts() string {
-	return tag.kernelOpts
+// Nodes implements Tag.
+func (t *tag) Nodes() ([]TaggedNode, error) {
+	source, err := t.getNodes("nodes")
+	if err != nil {
+		return nil, errors.Trace(err)
+	}
+	checker := schema.List(schema.FieldMap(
+		schema.Fields{
+			"system_id":      schema.String(),
+			"hostname":       schema.String(),
+			"node_type_name": schema.String(),
+		},
+		schema.Defaults{"node_type_name": ""},
+	))
+	coerced, err := checker.Coerce(source, nil)
+	if err != nil {
+		return nil, WrapWithDeserializationError(err, "tagged node schema check failed")
+	}
+	var result []TaggedNode
+	for _, value := range coerced.([]interface{}) {
+		valid := value.(map[string]interface{})
+		result = append(result, TaggedNode{
+			SystemID: valid["system_id"].(string),
+			Hostname: valid["hostname"].(string),
+			Type:     valid["node_type_name"].(string),
+		})
+	}
+	return result, nil
+}
+
+func (t *tag) getNodes(op string) (interface{}, error) {
+	source, err := t.controller.getOp(t.resourceURI, op)
+	if err != nil {
+		if svrErr, ok := errors.Cause(err).(ServerError); ok {
+			if svrErr.StatusCode == http.StatusNotFound {
+				return nil, wrapError(err, NewNoMatchError(svrErr.BodyMessage))
+			}
+		}
+		return nil, NewUnexpectedError(err)
+	}
+	return source, nil
+}
+
+func readTag(controllerVersion version.Number, source interface{}) (*tag, error) {
+	readFunc, err := getTagDeserializationFunc(controllerVersion)
+	if err != nil {
+		return nil, errors.Trace(err)
+	}
+
+	checker := schema.StringMap(schema.Any())
+	coerced, err := checker.Coerce(source, nil)
+	if err != nil {
+		return nil, WrapWithDeserializationError(err, "tag base schema check failed")
+	}
+	valid := coerced.(map[string]interface{})
+	return readFunc(valid)
 }
 
 func readTags(controllerVersion version.Number, source interface{}) ([]*tag, error) {
@@ -1,6 +1,9 @@
 package gomaasapi
 
 import (
+	"net/http"
+
+	"github.com/juju/errors"
 	"github.com/juju/testing"
 	jc "github.com/juju/testing/checkers"
 	gc "gopkg.in/check.v1"
@@ -48,3 +51,200 @@ var tagsResponse = `[
 		"kernel_opts": ""
 	}
 ]`
+
+func (s *tagSuite) getServerAndTag(c *gc.C) (*SimpleTestServer, Tag) {
+	server, controller := createTestServerController(c, s)
+	server.AddGetResponse("/api/2.0/tags/", http.StatusOK, "["+tagResponse+"]")
+	tags, err := controller.Tags()
+	c.Assert(err, jc.ErrorIsNil)
+	c.Assert(tags, gc.HasLen, 1)
+	return server, tags[0]
+}
+
+func (s *tagSuite) TestCreateTag(c *gc.C) {
+	server, controller := createTestServerController(c, s)
+	server.AddPostResponse("/api/2.0/tags/?op=", http.StatusOK, tagResponse)
+	tag, err := controller.CreateTag(CreateTagArgs{
+		Name:       "nvme",
+		Comment:    "machines with NVMe disks",
+		Definition: "//node[@class='storage']/capabilities/capability[@id='nvme']",
+		KernelOpts: "nvme_core.multipath=0",
+	})
+	c.Assert(err, jc.ErrorIsNil)
+	c.Check(tag.Name(), gc.Equals, "nvme")
+	c.Check(tag.KernelOpts(), gc.Equals, "nvme_core.multipath=0")
+
+	form := server.LastRequest().PostForm
+	c.Check(form.Get("name"), gc.Equals, "nvme")
+	c.Check(form.Get("comment"), gc.Equals, "machines with NVMe disks")
+	c.Check(form.Get("definition"), gc.Equals, "//node[@class='storage']/capabilities/capability[@id='nvme']")
+	c.Check(form.Get("kernel_opts"), gc.Equals, "nvme_core.multipath=0")
+}
+
+func (s *tagSuite) TestCreateTagValidates(c *gc.C) {
+	_, controller := createTestServerController(c, s)
+	_, err := controller.CreateTag(CreateTagArgs{Comment: "nameless"})
+	c.Assert(err, jc.Satisfies, errors.IsNotValid)
+}
+
+func (s *tagSuite) TestCreateTagBadRequest(c *gc.C) {
+	server, controller := createTestServerController(c, s)
+	server.AddPostResponse("/api/2.0/tags/?op=", http.StatusBadRequest, "Invalid xpath expression")
+	_, err := controller.CreateTag(CreateTagArgs{Name: "broken", Definition: "//["})
+	c.Assert(err, jc.Satisfies, IsBadRequestError)
+}
+
+func (s *tagSuite) TestUpdate(c *gc.C) {
+	server, tag := s.getServerAndTag(c)
+	response := updateJSONMap(c, tagResponse, map[string]interface{}{
+		"comment": "fast disks",
+	})
+	server.AddPutResponse("/api/2.0/tags/nvme/", http.StatusOK, response)
+	err := tag.Update(UpdateTagArgs{Comment: "fast disks"})
+	c.Assert(err, jc.ErrorIsNil)
+	c.Check(tag.Comment(), gc.Equals, "fast disks")
+
+	form := server.LastRequest().PostForm
+	c.Check(form.Get("comment"), gc.Equals, "fast disks")
+	c.Check(form["definition"], gc.IsNil)
+}
+
+func (s *tagSuite) TestUpdateNotFound(c *gc.C) {
+	server, tag := s.getServerAndTag(c)
+	server.AddPutResponse("/api/2.0/tags/nvme/", http.StatusNotFound, "Not Found")
+	err := tag.Update(UpdateTagArgs{Comment: "gone"})
+	c.Assert(err, jc.Satisfies, IsNoMatchError)
+}
+
+func (s *tagSuite) TestDelete(c *gc.C) {
+	server, tag := s.getServerAndTag(c)
+	server.AddDeleteResponse("/api/2.0/tags/nvme/", http.StatusNoContent, "")
+	err := tag.Delete()
+	c.Assert(err, jc.ErrorIsNil)
+}
+
+func (s *tagSuite) TestRebuild(c *gc.C) {
+	server, tag := s.getServerAndTag(c)
+	server.AddPostResponse("/api/2.0/tags/nvme/?op=rebuild", http.StatusOK, `{"rebuilding": "nvme"}`)
+	err := tag.Rebuild()
+	c.Assert(err, jc.ErrorIsNil)
+}
+
+func (s *tagSuite) TestRebuildBadRequest(c *gc.C) {
+	server, tag := s.getServerAndTag(c)
+	server.AddPostResponse("/api/2.0/tags/nvme/?op=rebuild", http.StatusBadRequest, "Cannot rebuild a tag without a definition.")
+	err := tag.Rebuild()
+	c.Assert(err, jc.Satisfies, IsBadRequestError)
+}
+
+func (s *tagSuite) TestMachines(c *gc.C) {
+	server, tag := s.getServerAndTag(c)
+	server.AddGetResponse("/api/2.0/tags/nvme/?op=machines", http.StatusOK, "["+machineResponse+"]")
+	machines, err := tag.Machines()
+	c.Assert(err, jc.ErrorIsNil)
+	c.Assert(machines, gc.HasLen, 1)
+	c.Assert(machines[0].SystemID(), gc.Equals, "4y3ha3")
+}
+
+func (s *tagSuite) TestDevices(c *gc.C) {
+	server, tag := s.getServerAndTag(c)
+	server.AddGetResponse("/api/2.0/tags/nvme/?op=devices", http.StatusOK, "["+deviceResponse+"]")
+	devices, err := tag.Devices()
+	c.Assert(err, jc.ErrorIsNil)
+	c.Assert(devices, gc.HasLen, 1)
+}
+
+func (s *tagSuite) TestNodes(c *gc.C) {
+	server, tag := s.getServerAndTag(c)
+	server.AddGetResponse("/api/2.0/tags/nvme/?op=nodes", http.StatusOK, `
+[
+    {"system_id": "4y3ha3", "hostname": "untasted-markita", "node_type_name": "Machine"},
+    {"system_id": "xfer8d", "hostname": "rack1", "node_type_name": "Rack controller"}
+]`)
+	nodes, err := tag.Nodes()
+	c.Assert(err, jc.ErrorIsNil)
+	c.Assert(nodes, jc.DeepEquals, []TaggedNode{
+		{SystemID: "4y3ha3", Hostname: "untasted-markita", Type: "Machine"},
+		{SystemID: "xfer8d", Hostname: "rack1", Type: "Rack controller"},
+	})
+}
+
+func (s *tagSuite) TestNodesNotFound(c *gc.C) {
+	server, tag := s.getServerAndTag(c)
+	server.AddGetResponse("/api/2.0/tags/nvme/?op=nodes", http.StatusNotFound, "Not Found")
+	_, err := tag.Nodes()
+	c.Assert(err, jc.Satisfies, IsNoMatchError)
+}
+
+func (s *tagSuite) TestUpdateTagNodesArgsValidate(c *gc.C) {
+	for i, test := range []struct {
+		args    UpdateTagNodesArgs
+		message string
+	}{{
+		args:    UpdateTagNodesArgs{},
+		message: "missing Add and Remove",
+	}, {
+		args:    UpdateTagNodesArgs{Add: []string{"a", "b"}, Remove: []string{"b"}},
+		message: `adding and removing "b"`,
+	}, {
+		args: UpdateTagNodesArgs{Remove: []string{"b"}},
+	}} {
+		c.Logf("test %d", i)
+		err := test.args.Validate()
+		if test.message == "" {
+			c.Check(err, jc.ErrorIsNil)
+		} else {
+			c.Check(err, jc.Satisfies, errors.IsNotValid)
+			c.Check(err.Error(), gc.Equals, test.message+" not valid")
+		}
+	}
+}
+
+func (s *tagSuite) TestUpdateNodes(c *gc.C) {
+	s.PatchValue(&tagUpdateNodesBatchSize, 2)
+	server, tag := s.getServerAndTag(c)
+	server.AddPostResponse("/api/2.0/tags/nvme/?op=update_nodes", http.StatusOK, `{"added": 2, "removed": 0}`)
+	server.AddPostResponse("/api/2.0/tags/nvme/?op=update_nodes", http.StatusOK, `{"added": 1, "removed": 0}`)
+	server.AddPostResponse("/api/2.0/tags/nvme/?op=update_nodes", http.StatusOK, `{"added": 0, "removed": 1}`)
+	added, removed, err := tag.UpdateNodes(UpdateTagNodesArgs{
+		Add:        []string{"a", "b", "c"},
+		Remove:     []string{"d"},
+		Definition: tag.Definition(),
+	})
+	c.Assert(err, jc.ErrorIsNil)
+	c.Check(added, gc.Equals, 3)
+	c.Check(removed, gc.Equals, 1)
+
+	requests := server.LastNRequests(3)
+	c.Check(requests[0].PostForm["add"], jc.DeepEquals, []string{"a", "b"})
+	c.Check(requests[1].PostForm["add"], jc.DeepEquals, []string{"c"})
+	c.Check(requests[2].PostForm["add"], gc.IsNil)
+	c.Check(requests[2].PostForm["remove"], jc.DeepEquals, []string{"d"})
+	for _, request := range requests {
+		c.Check(request.PostForm.Get("definition"), gc.Equals, tag.Definition())
+	}
+}
+
+func (s *tagSuite) TestUpdateNodesPartialFailure(c *gc.C) {
+	s.PatchValue(&tagUpdateNodesBatchSize, 2)
+	server, tag := s.getServerAndTag(c)
+	server.AddPostResponse("/api/2.0/tags/nvme/?op=update_nodes", http.StatusOK, `{"added": 2, "removed": 0}`)
+	server.AddPostResponse("/api/2.0/tags/nvme/?op=update_nodes", http.StatusConflict, "Definition supplied 'old' doesn't match current definition")
+	added, removed, err := tag.UpdateNodes(UpdateTagNodesArgs{
+		Add:        []string{"a", "b", "c"},
+		Definition: "old",
+	})
+	c.Assert(err, jc.Satisfies, IsCannotCompleteError)
+	c.Check(added, gc.Equals, 2)
+	c.Check(removed, gc.Equals, 0)
+}
+
+const tagResponse = `
+{
+    "resource_uri": "/api/2.0/tags/nvme/",
+    "name": "nvme",
+    "comment": "machines with NVMe disks",
+    "definition": "//node[@class='storage']/capabilities/capability[@id='nvme']",
+    "kernel_opts": "nvme_core.multipath=0"
+}
+`
@@ -4,11 +4,19 @@
 package gomaasapi
 
 import (
+	"bytes"
 	"fmt"
+	"io"
 	"net/http"
 	"net/http/httptest"
+	"net/url"
+	"regexp"
+	"sort"
 	"strings"
+	"sync"
 	"time"
+
+	"github.com/juju/errors"
 )
 
 type singleServingServer struct {
@@ -119,9 +127,150 @@ type simpleResponse struct {
 	body   string
 }
 
+// SimpleResponse is a response of a SimpleTestServer route.
+type SimpleResponse struct {
+	Status int
+	Body   string
+	// Header, if set, is added to the headers of the response, such as
+	// to send a Retry-After header.
+	Header http.Header
+
+	// The following fields inject faults, so that the timeouts and the
+	// retries of the client can be tested. Malformed responses are sent
+	// with a Body that isn't valid JSON.
+
+	// Delay, if set, is waited before responding, unless the request is
+	// cancelled first. The server keeps answering other requests in the
+	// meantime, including those sent by a client that gave up waiting.
+	Delay time.Duration
+	// Drop, if true, closes the connection instead of responding.
+	Drop bool
+	// TruncateAfter, if set, closes the connection after sending that
+	// many bytes of the Body, once the headers announced all of it.
+	TruncateAfter int
+	// ChunkSize, if set, sends the Body in chunks of that many bytes, with
+	// ChunkDelay between them, rather than all at once.
+	ChunkSize  int
+	ChunkDelay time.Duration
+}
+
+// write sends the response, injecting its faults.
+func (r SimpleResponse) write(writer http.ResponseWriter, request *http.Request) {
+	if !sleepUnlessDone(request, r.Delay) {
+		return
+	}
+	if r.Drop {
+		closeConnection(writer)
+		return
+	}
+	for key, values := range r.Header {
+		writer.Header()[key] = values
+	}
+	if r.TruncateAfter > 0 && r.TruncateAfter < len(r.Body) {
+		writer.Header().Set("Content-Length", fmt.Sprint(len(r.Body)))
+		writer.WriteHeader(r.Status)
+		fmt.Fprint(writer, r.Body[:r.TruncateAfter])
+		closeConnection(writer)
+		return
+	}
+	writer.WriteHeader(r.Status)
+	if r.ChunkSize <= 0 {
+		fmt.Fprint(writer, r.Body)
+		return
+	}
+	for start := 0; start < len(r.Body); start += r.ChunkSize {
+		if start > 0 && !sleepUnlessDone(request, r.ChunkDelay) {
+			return
+		}
+		end := start + r.ChunkSize
+		if end > len(r.Body) {
+			end = len(r.Body)
+		}
+		fmt.Fprint(writer, r.Body[start:end])
+		if flusher, ok := writer.(http.Flusher); ok {
+			flusher.Flush()
+		}
+	}
+}
+
+// sleepUnlessDone waits for the duration, and returns false if the request
+// was cancelled first.
+func sleepUnlessDone(request *http.Request, d time.Duration) bool {
+	if d <= 0 {
+		return true
+	}
+	select {
+	case <-time.After(d):
+		return true
+	case <-request.Context().Done():
+		return false
+	}
+}
+
+// closeConnection closes the connection of the response, flushing what was
+// written.
+func closeConnection(writer http.ResponseWriter) {
+	hijacker, ok := writer.(http.Hijacker)
+	if !ok {
+		panic("response writer can't be hijacked")
+	}
+	conn, _, err := hijacker.Hijack()
+	if err != nil {
+		panic(err)
+	}
+	conn.Close()
+}
+
+// SimpleRoute selects the requests that a SimpleTestServer answers with
+// the responses or the handler of the route, see AddRouteResponses and
+// AddRouteHandler.
+type SimpleRoute struct {
+	// Method, if set, is the method of the requests. HEAD requests match
+	// GET routes.
+	Method string
+	// Path is a regular expression that must match the whole path of the
+	// requests, such as "/api/2.0/machines/[^/]+/".
+	Path string
+	// Op, if set, is the op query parameter of the requests. Use "-" for
+	// requests without one.
+	Op string
+}
+
+// simpleRoute is a SimpleRoute with its responses or handler.
+type simpleRoute struct {
+	SimpleRoute
+	path      *regexp.Regexp
+	responses []SimpleResponse
+	handler   http.HandlerFunc
+	calls     int
+}
+
+func (r *simpleRoute) matches(request *http.Request) bool {
+	method := request.Method
+	if method == "HEAD" {
+		method = "GET"
+	}
+	if r.Method != "" && r.Method != method || !r.path.MatchString(request.URL.Path) {
+		return false
+	}
+	op, found := request.URL.Query()["op"]
+	switch {
+	case r.Op == "":
+		return true
+	case r.Op == "-":
+		return !found
+	}
+	return found && op[0] == r.Op
+}
+
 type SimpleTestServer struct {
 	*httptest.Server
 
+	// mu guards the fields below, which the handlers of concurrent
+	// requests change. It isn't held while responding, so that delayed
+	// responses and route handlers don't hold up other requests.
+	mu sync.Mutex
+
 	getResponses        map[string][]simpleResponse
 	getResponseIndex    map[string]int
 	putResponses        map[string][]simpleResponse
@@ -131,6 +280,8 @@ type SimpleTestServer struct {
 	deleteResponses     map[string][]simpleResponse
 	deleteResponseIndex map[string]int
 
+	routes []*simpleRoute
+
 	requests []*http.Request
 }
 
@@ -151,25 +302,198 @@ func NewSimpleServer() *SimpleTestServer {
 
 func (s *SimpleTestServer) AddGetResponse(path string, status int, body string) {
 	logger.Debugf("add get response for: %s, %d", path, status)
+	s.mu.Lock()
+	defer s.mu.Unlock()
 	s.getResponses[path] = append(s.getResponses[path], simpleResponse{status: status, body: body})
 }
 
 func (s *SimpleTestServer) AddPutResponse(path string, status int, body string) {
 	logger.Debugf("add put response for: %s, %d", path, status)
+	s.mu.Lock()
+	defer s.mu.Unlock()
 	s.putResponses[path] = append(s.putResponses[path], simpleResponse{status: status, body: body})
 }
 
 func (s *SimpleTestServer) AddPostResponse(path string, status int, body string) {
 	logger.Debugf("add post response for: %s, %d", path, status)
+	s.mu.Lock()
+	defer s.mu.Unlock()
 	s.postResponses[path] = append(s.postResponses[path], simpleResponse{status: status, body: body})
 }
 
 func (s *SimpleTestServer) AddDeleteResponse(path string, status int, body string) {
 	logger.Debugf("add delete response for: %s, %d", path, status)
+	s.mu.Lock()
+	defer s.mu.Unlock()
 	s.deleteResponses[path] = append(s.deleteResponses[path], simpleResponse{status: status, body: body})
 }
 
+// AddRouteResponses adds responses to the requests selected by the route,
+// which are sent in order, one for each request, the last one being sent
+// again once the others have been. They allow testing how conflicts or
+// transient errors are retried, such as with a 409 response followed by a
+// 200 one. The responses added for exact paths with AddGetResponse and
+// the others take precedence over the routes, which are matched in the
+// order they were added. It panics if the path isn't a valid regular
+// expression or if there are no responses.
+func (s *SimpleTestServer) AddRouteResponses(route SimpleRoute, responses ...SimpleResponse) {
+	if len(responses) == 0 {
+		panic("no responses for route " + route.Path)
+	}
+	logger.Debugf("add responses for route: %s %s, op %q", route.Method, route.Path, route.Op)
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.routes = append(s.routes, &simpleRoute{
+		SimpleRoute: route,
+		path:        regexp.MustCompile("^(?:" + route.Path + ")$"),
+		responses:   responses,
+	})
+}
+
+// AddRouteHandler adds a handler answering the requests selected by the
+// route, such as to build the responses from the requests. The requests
+// are recorded, and their forms parsed, before they are handled.
+func (s *SimpleTestServer) AddRouteHandler(route SimpleRoute, handler http.HandlerFunc) {
+	logger.Debugf("add handler for route: %s %s, op %q", route.Method, route.Path, route.Op)
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.routes = append(s.routes, &simpleRoute{
+		SimpleRoute: route,
+		path:        regexp.MustCompile("^(?:" + route.Path + ")$"),
+		handler:     handler,
+	})
+}
+
+// RouteCalls returns the number of requests that matched the route with
+// the given path, among those added with AddRouteResponses and
+// AddRouteHandler.
+func (s *SimpleTestServer) RouteCalls(path string) int {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	calls := 0
+	for _, route := range s.routes {
+		if route.Path == path {
+			calls += route.calls
+		}
+	}
+	return calls
+}
+
+// RequestsMatching returns the requests whose path matches the path, a
+// regular expression that must match the whole path as for SimpleRoute,
+// oldest first. It panics if the path isn't a valid regular expression.
+func (s *SimpleTestServer) RequestsMatching(path string) []*http.Request {
+	pattern := regexp.MustCompile("^(?:" + path + ")$")
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	var result []*http.Request
+	for _, request := range s.requests {
+		if pattern.MatchString(request.URL.Path) {
+			result = append(result, request)
+		}
+	}
+	return result
+}
+
+// ExpectPostForm returns an error unless a POST request matching the path,
+// as for RequestsMatching, was sent with the values in its form. Other
+// values of the form are ignored.
+func (s *SimpleTestServer) ExpectPostForm(path string, values url.Values) error {
+	var forms []string
+	for _, request := range s.RequestsMatching(path) {
+		if request.Method != "POST" {
+			continue
+		}
+		if formContains(request.PostForm, values) {
+			return nil
+		}
+		forms = append(forms, request.PostForm.Encode())
+	}
+	if len(forms) == 0 {
+		return errors.Errorf("no POST request to %s", path)
+	}
+	return errors.Errorf("no POST request to %s with %s, got %s", path, values.Encode(), strings.Join(forms, ", "))
+}
+
+func formContains(form, values url.Values) bool {
+	for key, expected := range values {
+		actual := form[key]
+		if len(actual) != len(expected) {
+			return false
+		}
+		for i := range expected {
+			if actual[i] != expected[i] {
+				return false
+			}
+		}
+	}
+	return true
+}
+
+// Unconsumed returns the responses that were added but haven't been sent,
+// described by their method and path, sorted. The responses of a route are
+// consumed once each of them has been sent.
+func (s *SimpleTestServer) Unconsumed() []string {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	var result []string
+	for _, added := range []struct {
+		method    string
+		responses map[string][]simpleResponse
+		index     map[string]int
+	}{
+		{"GET", s.getResponses, s.getResponseIndex},
+		{"PUT", s.putResponses, s.putResponseIndex},
+		{"POST", s.postResponses, s.postResponseIndex},
+		{"DELETE", s.deleteResponses, s.deleteResponseIndex},
+	} {
+		for path, responses := range added.responses {
+			for i := added.index[path]; i < len(responses); i++ {
+				result = append(result, added.method+" "+path)
+			}
+		}
+	}
+	for _, route := range s.routes {
+		for i := route.calls; i < len(route.responses); i++ {
+			result = append(result, strings.TrimSpace(route.Method+" "+route.Path))
+		}
+	}
+	sort.Strings(result)
+	return result
+}
+
+// VerifyConsumed returns an error listing the responses that were added but
+// haven't been sent, so that tests can check at teardown that the client
+// sent all the requests they expected.
+func (s *SimpleTestServer) VerifyConsumed() error {
+	if unconsumed := s.Unconsumed(); len(unconsumed) > 0 {
+		return errors.Errorf("unconsumed responses: %s", strings.Join(unconsumed, ", "))
+	}
+	return nil
+}
+
+// TestCleaner is the part of testing.TB that ExpectConsumed uses, so that
+// other test frameworks can be adapted to it.
+type TestCleaner interface {
+	Cleanup(func())
+	Errorf(format string, args ...interface{})
+}
+
+// ExpectConsumed registers a cleanup of the test that fails it if some
+// responses added to the server haven't been sent, see VerifyConsumed. It
+// is usually called with the *testing.T of the test once the server is
+// created.
+func (s *SimpleTestServer) ExpectConsumed(t TestCleaner) {
+	t.Cleanup(func() {
+		if err := s.VerifyConsumed(); err != nil {
+			t.Errorf("%v", err)
+		}
+	})
+}
+
 func (s *SimpleTestServer) LastRequest() *http.Request {
+	s.mu.Lock()
+	defer s.mu.Unlock()
 	pos := len(s.requests) - 1
 	if pos < 0 {
 		return nil
@@ -178,18 +502,24 @@ func (s *SimpleTestServer) LastRequest() *http.Request {
 }
 
 func (s *SimpleTestServer) LastNRequests(n int) []*http.Request {
+	s.mu.Lock()
+	defer s.mu.Unlock()
 	start := len(s.requests) - n
 	if start < 0 {
 		start = 0
 	}
-	return s.requests[start:]
+	return append([]*http.Request(nil), s.requests[start:]...)
 }
 
 func (s *SimpleTestServer) RequestCount() int {
+	s.mu.Lock()
+	defer s.mu.Unlock()
 	return len(s.requests)
 }
 
 func (s *SimpleTestServer) ResetRequests() {
+	s.mu.Lock()
+	defer s.mu.Unlock()
 	s.requests = nil
 }
 
@@ -201,7 +531,7 @@ func (s *SimpleTestServer) handler(writer http.ResponseWriter, request *http.Req
 		responseIndex map[string]int
 	)
 	switch method {
-	case "GET":
+	case "GET", "HEAD":
 		responses = s.getResponses
 		responseIndex = s.getResponseIndex
 		_, err = readAndClose(request.Body)
@@ -211,7 +541,16 @@ func (s *SimpleTestServer) handler(writer http.ResponseWriter, request *http.Req
 	case "PUT":
 		responses = s.putResponses
 		responseIndex = s.putResponseIndex
-		err = request.ParseForm()
+		if request.Header.Get("Content-Type") == "application/octet-stream" {
+			// Keep the content, so that tests can check it.
+			var content []byte
+			content, err = readAndClose(request.Body)
+			request.Body = io.NopCloser(bytes.NewReader(content))
+		} else if strings.HasPrefix(request.Header.Get("Content-Type"), "multipart/form-data;") {
+			err = request.ParseMultipartForm(2 << 20)
+		} else {
+			err = request.ParseForm()
+		}
 		if err != nil {
 			panic(err)
 		}
@@ -237,18 +576,48 @@ func (s *SimpleTestServer) handler(writer http.ResponseWriter, request *http.Req
 	default:
 		panic("unsupported method " + method)
 	}
+	s.mu.Lock()
 	s.requests = append(s.requests, request)
 	uri := request.URL.String()
 	testResponses, found := responses[uri]
 	if !found {
-		errorMsg := fmt.Sprintf("Error 404: page not found ('%v').", uri)
-		http.Error(writer, errorMsg, http.StatusNotFound)
-	} else {
-		index := responseIndex[uri]
-		response := testResponses[index]
-		responseIndex[uri] = index + 1
+		s.mu.Unlock()
+		s.routeHandler(writer, request)
+		return
+	}
+	index := responseIndex[uri]
+	response := testResponses[index]
+	responseIndex[uri] = index + 1
+	s.mu.Unlock()
 
-		writer.WriteHeader(response.status)
-		fmt.Fprint(writer, response.body)
+	writer.WriteHeader(response.status)
+	fmt.Fprint(writer, response.body)
+}
+
+// routeHandler answers the requests that aren't answered by the responses of
+// their exact paths.
+func (s *SimpleTestServer) routeHandler(writer http.ResponseWriter, request *http.Request) {
+	s.mu.Lock()
+	for _, route := range s.routes {
+		if !route.matches(request) {
+			continue
+		}
+		route.calls++
+		if route.handler != nil {
+			s.mu.Unlock()
+			route.handler(writer, request)
+			return
+		}
+		index := route.calls - 1
+		if index >= len(route.responses) {
+			index = len(route.responses) - 1
+		}
+		response := route.responses[index]
+		s.mu.Unlock()
+		response.write(writer, request)
+		return
 	}
+	s.mu.Unlock()
+	errorMsg := fmt.Sprintf("Error 404: page not found ('%v').", request.URL.String())
+	http.Error(writer, errorMsg, http.StatusNotFound)
 }
@@ -0,0 +1,322 @@
+// Copyright 2022 Canonical Ltd.
+// Licensed under the LGPLv3, see LICENCE file for details.
+
+package gomaasapi
+
+import (
+	"context"
+	"fmt"
+	"net/http"
+	"net/url"
+	stdtesting "testing"
+	"time"
+
+	"github.com/juju/testing"
+	jc "github.com/juju/testing/checkers"
+	gc "gopkg.in/check.v1"
+)
+
+type simpleTestServerSuite struct {
+	testing.LoggingCleanupSuite
+}
+
+var _ = gc.Suite(&simpleTestServerSuite{})
+
+// The tests of the standard testing package can expect the responses to be
+// consumed.
+var _ TestCleaner = (*stdtesting.T)(nil)
+
+// suiteCleaner adapts the cleanups of a gocheck suite to TestCleaner.
+type suiteCleaner struct {
+	suite cleanup
+	c     *gc.C
+}
+
+// Cleanup implements TestCleaner.
+func (s *suiteCleaner) Cleanup(f func()) {
+	s.suite.AddCleanup(func(c *gc.C) {
+		s.c = c
+		f()
+	})
+}
+
+// Errorf implements TestCleaner.
+func (s *suiteCleaner) Errorf(format string, args ...interface{}) {
+	s.c.Errorf(format, args...)
+}
+
+// expectConsumed checks at teardown that all the responses added to the
+// server have been sent.
+func expectConsumed(suite cleanup, server *SimpleTestServer) {
+	server.ExpectConsumed(&suiteCleaner{suite: suite})
+}
+
+// recordingCleaner is a TestCleaner running its cleanups on demand.
+type recordingCleaner struct {
+	cleanups []func()
+	errors   []string
+}
+
+// Cleanup implements TestCleaner.
+func (r *recordingCleaner) Cleanup(f func()) {
+	r.cleanups = append(r.cleanups, f)
+}
+
+// Errorf implements TestCleaner.
+func (r *recordingCleaner) Errorf(format string, args ...interface{}) {
+	r.errors = append(r.errors, fmt.Sprintf(format, args...))
+}
+
+func (s *simpleTestServerSuite) TestRouteResponsesInOrder(c *gc.C) {
+	server, controller := createTestServerController(c, s)
+	server.AddRouteResponses(SimpleRoute{Method: "POST", Path: "/api/2.0/machines/[^/]+/", Op: "deploy"},
+		SimpleResponse{Status: http.StatusConflict, Body: "busy"},
+		SimpleResponse{Status: http.StatusOK, Body: `{"status_name": "Deploying"}`},
+	)
+
+	for i, expected := range []int{http.StatusConflict, http.StatusOK, http.StatusOK} {
+		c.Logf("call %d", i)
+		_, err := controller.Do("POST", fmt.Sprintf("machines/abc%d", i), "deploy", nil)
+		if expected == http.StatusOK {
+			c.Check(err, jc.ErrorIsNil)
+			continue
+		}
+		svrErr, ok := GetServerError(err)
+		c.Assert(ok, jc.IsTrue)
+		c.Check(svrErr.StatusCode, gc.Equals, expected)
+		c.Check(svrErr.BodyMessage, gc.Equals, "busy")
+	}
+	c.Check(server.RouteCalls("/api/2.0/machines/[^/]+/"), gc.Equals, 3)
+}
+
+func (s *simpleTestServerSuite) TestRouteOp(c *gc.C) {
+	server, controller := createTestServerController(c, s)
+	route := SimpleRoute{Method: "GET", Path: "/api/2.0/machines/abc/"}
+	server.AddRouteResponses(SimpleRoute{Method: "GET", Path: route.Path, Op: "details"}, SimpleResponse{Status: http.StatusOK, Body: `"details"`})
+	server.AddRouteResponses(SimpleRoute{Method: "GET", Path: route.Path, Op: "-"}, SimpleResponse{Status: http.StatusOK, Body: `"machine"`})
+
+	for op, expected := range map[string]string{"details": "details", "": "machine"} {
+		result, err := controller.Do("GET", "machines/abc", op, nil)
+		c.Assert(err, jc.ErrorIsNil)
+		value, err := result.GetString()
+		c.Assert(err, jc.ErrorIsNil)
+		c.Check(value, gc.Equals, expected)
+	}
+	_, err := controller.Do("GET", "machines/abc", "power_parameters", nil)
+	svrErr, ok := GetServerError(err)
+	c.Assert(ok, jc.IsTrue)
+	c.Check(svrErr.StatusCode, gc.Equals, http.StatusNotFound)
+	_, err = controller.Do("POST", "machines/abc", "details", nil)
+	c.Check(err, gc.NotNil)
+}
+
+func (s *simpleTestServerSuite) TestExactResponsesFirst(c *gc.C) {
+	server, controller := createTestServerController(c, s)
+	server.AddRouteResponses(SimpleRoute{Path: "/api/2.0/zones/.*"}, SimpleResponse{Status: http.StatusServiceUnavailable})
+	server.AddGetResponse("/api/2.0/zones/", http.StatusOK, zoneResponse)
+
+	zones, err := controller.Zones()
+	c.Assert(err, jc.ErrorIsNil)
+	c.Check(zones, gc.HasLen, 2)
+	c.Check(server.RouteCalls("/api/2.0/zones/.*"), gc.Equals, 0)
+}
+
+func (s *simpleTestServerSuite) TestRouteHeader(c *gc.C) {
+	server, controller := createTestServerController(c, s)
+	server.AddRouteResponses(SimpleRoute{Path: "/api/2.0/zones/"},
+		SimpleResponse{Status: http.StatusServiceUnavailable, Header: http.Header{RetryAfterHeaderName: {"0"}}},
+		SimpleResponse{Status: http.StatusOK, Body: zoneResponse},
+	)
+
+	zones, err := controller.Zones()
+	c.Assert(err, jc.ErrorIsNil)
+	c.Check(zones, gc.HasLen, 2)
+	c.Check(server.RouteCalls("/api/2.0/zones/"), gc.Equals, 2)
+}
+
+func (s *simpleTestServerSuite) TestRouteHandler(c *gc.C) {
+	server, controller := createTestServerController(c, s)
+	server.AddRouteHandler(SimpleRoute{Method: "POST", Path: "/api/2.0/tags/", Op: "-"}, func(w http.ResponseWriter, r *http.Request) {
+		fmt.Fprintf(w, `{"resource_uri": "/MAAS/api/2.0/tags/%s/", "name": %q}`, r.PostForm.Get("name"), r.PostForm.Get("name"))
+	})
+
+	result, err := controller.Do("POST", "tags", "", url.Values{"name": {"gpu"}})
+	c.Assert(err, jc.ErrorIsNil)
+	values, err := result.GetMap()
+	c.Assert(err, jc.ErrorIsNil)
+	name, err := values["name"].GetString()
+	c.Assert(err, jc.ErrorIsNil)
+	c.Check(name, gc.Equals, "gpu")
+	c.Check(server.LastRequest().PostForm.Get("name"), gc.Equals, "gpu")
+}
+
+func (s *simpleTestServerSuite) TestInvalidRoute(c *gc.C) {
+	server := NewSimpleServer()
+	c.Check(func() { server.AddRouteResponses(SimpleRoute{Path: "("}, SimpleResponse{}) }, gc.PanicMatches, ".*missing closing.*")
+	c.Check(func() { server.AddRouteResponses(SimpleRoute{Path: "/"}) }, gc.PanicMatches, "no responses for route /")
+}
+
+func (s *simpleTestServerSuite) TestRequestsMatching(c *gc.C) {
+	server, controller := createTestServerController(c, s)
+	expectConsumed(s, server)
+	server.AddRouteResponses(SimpleRoute{Path: "/api/2.0/machines/[^/]+/"}, SimpleResponse{Status: http.StatusOK, Body: "{}"})
+	server.AddGetResponse("/api/2.0/zones/", http.StatusOK, zoneResponse)
+
+	_, err := controller.Do("GET", "machines/a", "", nil)
+	c.Assert(err, jc.ErrorIsNil)
+	_, err = controller.Zones()
+	c.Assert(err, jc.ErrorIsNil)
+	_, err = controller.Do("POST", "machines/b", "deploy", url.Values{"distro_series": {"jammy"}})
+	c.Assert(err, jc.ErrorIsNil)
+
+	requests := server.RequestsMatching("/api/2.0/machines/.*")
+	c.Assert(requests, gc.HasLen, 2)
+	c.Check(requests[0].URL.Path, gc.Equals, "/api/2.0/machines/a/")
+	c.Check(requests[1].URL.Path, gc.Equals, "/api/2.0/machines/b/")
+	c.Check(server.RequestsMatching("/api/2.0/zones/"), gc.HasLen, 1)
+
+	c.Check(server.ExpectPostForm("/api/2.0/machines/b/", url.Values{"distro_series": {"jammy"}}), jc.ErrorIsNil)
+	c.Check(server.ExpectPostForm("/api/2.0/machines/b/", url.Values{"distro_series": {"focal"}}), gc.ErrorMatches,
+		`no POST request to /api/2.0/machines/b/ with distro_series=focal, got distro_series=jammy`)
+	c.Check(server.ExpectPostForm("/api/2.0/machines/a/", nil), gc.ErrorMatches, `no POST request to /api/2.0/machines/a/`)
+}
+
+func (s *simpleTestServerSuite) TestVerifyConsumed(c *gc.C) {
+	server, controller := createTestServerController(c, s)
+	server.AddGetResponse("/api/2.0/zones/", http.StatusOK, zoneResponse)
+	server.AddGetResponse("/api/2.0/zones/", http.StatusOK, zoneResponse)
+	server.AddRouteResponses(SimpleRoute{Method: "POST", Path: "/api/2.0/tags/"},
+		SimpleResponse{Status: http.StatusConflict},
+		SimpleResponse{Status: http.StatusOK, Body: "{}"},
+	)
+	c.Check(server.Unconsumed(), jc.DeepEquals, []string{
+		"GET /api/2.0/zones/", "GET /api/2.0/zones/", "POST /api/2.0/tags/", "POST /api/2.0/tags/",
+	})
+
+	_, err := controller.Zones()
+	c.Assert(err, jc.ErrorIsNil)
+	_, err = controller.Do("POST", "tags", "", nil)
+	c.Assert(err, gc.NotNil)
+	c.Check(server.VerifyConsumed(), gc.ErrorMatches, "unconsumed responses: GET /api/2.0/zones/, POST /api/2.0/tags/")
+
+	_, err = controller.Zones()
+	c.Assert(err, jc.ErrorIsNil)
+	_, err = controller.Do("POST", "tags", "", nil)
+	c.Assert(err, jc.ErrorIsNil)
+	c.Check(server.VerifyConsumed(), jc.ErrorIsNil)
+}
+
+func (s *simpleTestServerSuite) TestExpectConsumed(c *gc.C) {
+	server, controller := createTestServerController(c, s)
+	var cleaner recordingCleaner
+	server.ExpectConsumed(&cleaner)
+	server.AddGetResponse("/api/2.0/zones/", http.StatusOK, zoneResponse)
+	c.Assert(cleaner.cleanups, gc.HasLen, 1)
+	cleaner.cleanups[0]()
+	c.Check(cleaner.errors, jc.DeepEquals, []string{"unconsumed responses: GET /api/2.0/zones/"})
+
+	cleaner.errors = nil
+	_, err := controller.Zones()
+	c.Assert(err, jc.ErrorIsNil)
+	cleaner.cleanups[0]()
+	c.Check(cleaner.errors, gc.HasLen, 0)
+}
+
+func (s *simpleTestServerSuite) TestDelay(c *gc.C) {
+	server := NewSimpleServer()
+	server.AddGetResponse("/api/2.0/users/?op=whoami", http.StatusOK, `"captain awesome"`)
+	server.AddGetResponse("/api/2.0/version/", http.StatusOK, versionResponse)
+	server.AddRouteResponses(SimpleRoute{Path: "/api/2.0/zones/"},
+		SimpleResponse{Status: http.StatusOK, Body: zoneResponse, Delay: time.Minute},
+		SimpleResponse{Status: http.StatusOK, Body: zoneResponse, Delay: 10 * time.Millisecond},
+	)
+	server.Start()
+	defer server.Close()
+	controller, err := NewController(ControllerArgs{
+		BaseURL:  server.URL,
+		APIKey:   "fake:as:key",
+		Timeouts: Timeouts{Call: time.Second},
+	})
+	c.Assert(err, jc.ErrorIsNil)
+
+	start := time.Now()
+	_, err = controller.Zones()
+	c.Check(err, gc.ErrorMatches, ".*context deadline exceeded.*")
+	c.Check(time.Since(start) < 30*time.Second, jc.IsTrue)
+
+	start = time.Now()
+	zones, err := controller.Zones()
+	c.Assert(err, jc.ErrorIsNil)
+	c.Check(zones, gc.HasLen, 2)
+	c.Check(time.Since(start) >= 10*time.Millisecond, jc.IsTrue)
+}
+
+func (s *simpleTestServerSuite) TestDelayDoesNotHoldUpOtherRequests(c *gc.C) {
+	server, controller := createTestServerController(c, s)
+	server.AddRouteResponses(SimpleRoute{Path: "/api/2.0/zones/"},
+		SimpleResponse{Status: http.StatusOK, Body: zoneResponse, Delay: time.Minute})
+	server.AddRouteResponses(SimpleRoute{Path: "/api/2.0/tags/"}, SimpleResponse{Status: http.StatusOK, Body: "{}"})
+
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+	done := make(chan error, 1)
+	go func() {
+		_, err := controller.WithContext(ctx).Zones()
+		done <- err
+	}()
+	for server.RouteCalls("/api/2.0/zones/") == 0 {
+		time.Sleep(time.Millisecond)
+	}
+
+	// The server answers, and can be inspected, while the response of the
+	// first request is being delayed.
+	_, err := controller.Do("POST", "tags", "", nil)
+	c.Assert(err, jc.ErrorIsNil)
+	c.Check(server.RequestsMatching("/api/2.0/tags/"), gc.HasLen, 1)
+	c.Check(server.Unconsumed(), gc.HasLen, 0)
+
+	cancel()
+	c.Check(<-done, gc.ErrorMatches, ".*context canceled.*")
+}
+
+func (s *simpleTestServerSuite) TestDrop(c *gc.C) {
+	server, controller := createTestServerController(c, s)
+	server.AddRouteResponses(SimpleRoute{Path: "/api/2.0/tags/"}, SimpleResponse{Drop: true}, SimpleResponse{Status: http.StatusOK, Body: "{}"})
+
+	_, err := controller.Do("POST", "tags", "", nil)
+	c.Check(err, gc.ErrorMatches, ".*EOF.*")
+	_, err = controller.Do("POST", "tags", "", nil)
+	c.Check(err, jc.ErrorIsNil)
+}
+
+func (s *simpleTestServerSuite) TestTruncateAfter(c *gc.C) {
+	server, controller := createTestServerController(c, s)
+	server.AddRouteResponses(SimpleRoute{Path: "/api/2.0/zones/"}, SimpleResponse{Status: http.StatusOK, Body: zoneResponse, TruncateAfter: 10})
+
+	_, err := controller.Zones()
+	c.Check(err, gc.ErrorMatches, ".*unexpected EOF.*")
+}
+
+func (s *simpleTestServerSuite) TestMalformedJSON(c *gc.C) {
+	server, controller := createTestServerController(c, s)
+	server.AddRouteResponses(SimpleRoute{Path: "/api/2.0/zones/"}, SimpleResponse{Status: http.StatusOK, Body: `[{"name": `})
+
+	_, err := controller.Zones()
+	c.Check(err, gc.NotNil)
+}
+
+func (s *simpleTestServerSuite) TestChunks(c *gc.C) {
+	server, controller := createTestServerController(c, s)
+	server.AddRouteResponses(SimpleRoute{Path: "/api/2.0/zones/"}, SimpleResponse{
+		Status:     http.StatusOK,
+		Body:       zoneResponse,
+		ChunkSize:  len(zoneResponse)/3 + 1,
+		ChunkDelay: 10 * time.Millisecond,
+	})
+
+	start := time.Now()
+	zones, err := controller.Zones()
+	c.Assert(err, jc.ErrorIsNil)
+	c.Check(zones, gc.HasLen, 2)
+	c.Check(time.Since(start) >= 20*time.Millisecond, jc.IsTrue)
+}
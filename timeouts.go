@@ -0,0 +1,62 @@
+// Copyright 2022 Canonical Ltd.
+// Licensed under the LGPLv3, see LICENCE file for details.
+
+package gomaasapi
+
+import (
+	"net"
+	"net/http"
+	"time"
+
+	"github.com/juju/errors"
+)
+
+// Timeouts bound the time spent on the requests to MAAS, so that a
+// region or a rack controller that stops responding doesn't block the
+// callers forever. Zero values disable the timeouts. They apply in
+// addition to the context of the controller, if any.
+type Timeouts struct {
+	// Connect bounds the time taken to establish a connection to MAAS,
+	// including the TLS handshake.
+	Connect time.Duration
+	// ResponseHeader bounds the time waited for the headers of a response
+	// once the request is sent.
+	ResponseHeader time.Duration
+	// Call bounds the time of a whole call, including the retries, the
+	// waits in the request queue and the reading of the response.
+	Call time.Duration
+}
+
+// Validate ensures that none of the timeouts are negative.
+func (t Timeouts) Validate() error {
+	if t.Connect < 0 {
+		return errors.NotValidf("negative Connect timeout")
+	}
+	if t.ResponseHeader < 0 {
+		return errors.NotValidf("negative ResponseHeader timeout")
+	}
+	if t.Call < 0 {
+		return errors.NotValidf("negative Call timeout")
+	}
+	return nil
+}
+
+// needsTransport reports whether the timeouts are set on the transport.
+func (t Timeouts) needsTransport() bool {
+	return t.Connect > 0 || t.ResponseHeader > 0
+}
+
+// apply sets the timeouts on the transport.
+func (t Timeouts) apply(httpTransport *http.Transport) {
+	if t.Connect > 0 {
+		dialer := &net.Dialer{
+			Timeout:   t.Connect,
+			KeepAlive: 30 * time.Second,
+		}
+		httpTransport.DialContext = dialer.DialContext
+		httpTransport.TLSHandshakeTimeout = t.Connect
+	}
+	if t.ResponseHeader > 0 {
+		httpTransport.ResponseHeaderTimeout = t.ResponseHeader
+	}
+}
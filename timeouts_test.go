@@ -0,0 +1,158 @@
+// Copyright 2022 Canonical Ltd.
+// Licensed under the LGPLv3, see LICENCE file for details.
+
+package gomaasapi
+
+import (
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"time"
+
+	"github.com/juju/errors"
+	"github.com/juju/testing"
+	jc "github.com/juju/testing/checkers"
+	gc "gopkg.in/check.v1"
+)
+
+type timeoutsSuite struct {
+	testing.LoggingCleanupSuite
+}
+
+var _ = gc.Suite(&timeoutsSuite{})
+
+// newZonesServer returns a server that controllers can be created for,
+// whose zones are served by the handler.
+func (s *timeoutsSuite) newZonesServer(zones http.HandlerFunc) *httptest.Server {
+	mux := http.NewServeMux()
+	mux.HandleFunc("/api/2.0/users/", func(w http.ResponseWriter, r *http.Request) {
+		fmt.Fprint(w, `"captain awesome"`)
+	})
+	mux.HandleFunc("/api/2.0/version/", func(w http.ResponseWriter, r *http.Request) {
+		fmt.Fprint(w, versionResponse)
+	})
+	mux.HandleFunc("/api/2.0/zones/", zones)
+	server := httptest.NewServer(mux)
+	s.AddCleanup(func(*gc.C) { server.Close() })
+	return server
+}
+
+func (s *timeoutsSuite) TestValidate(c *gc.C) {
+	c.Check(Timeouts{}.Validate(), jc.ErrorIsNil)
+	c.Check(Timeouts{Connect: time.Second, ResponseHeader: time.Second, Call: time.Second}.Validate(), jc.ErrorIsNil)
+	c.Check(Timeouts{Connect: -1}.Validate(), gc.ErrorMatches, "negative Connect timeout not valid")
+	c.Check(Timeouts{ResponseHeader: -1}.Validate(), gc.ErrorMatches, "negative ResponseHeader timeout not valid")
+	c.Check(Timeouts{Call: -1}.Validate(), gc.ErrorMatches, "negative Call timeout not valid")
+
+	_, err := NewController(ControllerArgs{
+		BaseURL:  "http://maas.invalid/MAAS/",
+		APIKey:   "fake:as:key",
+		Timeouts: Timeouts{Call: -time.Second},
+	})
+	c.Check(err, jc.Satisfies, errors.IsNotValid)
+}
+
+func (s *timeoutsSuite) TestCallTimeout(c *gc.C) {
+	release := make(chan struct{})
+	defer close(release)
+	server := s.newZonesServer(func(w http.ResponseWriter, r *http.Request) {
+		select {
+		case <-release:
+		case <-r.Context().Done():
+		}
+	})
+	maas, err := NewController(ControllerArgs{
+		BaseURL:  server.URL,
+		APIKey:   "fake:as:key",
+		Timeouts: Timeouts{Call: 50 * time.Millisecond},
+	})
+	c.Assert(err, jc.ErrorIsNil)
+
+	start := time.Now()
+	_, err = maas.Zones()
+	c.Assert(err, gc.ErrorMatches, ".*context deadline exceeded.*")
+	c.Check(time.Since(start) < 10*time.Second, jc.IsTrue)
+
+	_, err = maas.ServerTime()
+	c.Check(err, jc.ErrorIsNil)
+
+	// Controllers derived with other credentials keep the timeout.
+	admin, err := maas.WithAPIKey("admin:token:secret")
+	c.Assert(err, jc.ErrorIsNil)
+	_, err = admin.Zones()
+	c.Assert(err, gc.ErrorMatches, ".*context deadline exceeded.*")
+}
+
+func (s *timeoutsSuite) TestCallTimeoutIncludesRetries(c *gc.C) {
+	server := s.newZonesServer(func(w http.ResponseWriter, r *http.Request) {
+		w.Header().Set(RetryAfterHeaderName, "10")
+		w.WriteHeader(http.StatusServiceUnavailable)
+	})
+	maas, err := NewController(ControllerArgs{
+		BaseURL:  server.URL,
+		APIKey:   "fake:as:key",
+		Timeouts: Timeouts{Call: 50 * time.Millisecond},
+	})
+	c.Assert(err, jc.ErrorIsNil)
+
+	start := time.Now()
+	_, err = maas.Zones()
+	c.Assert(err, gc.ErrorMatches, ".*context deadline exceeded")
+	c.Check(time.Since(start) < 10*time.Second, jc.IsTrue)
+}
+
+func (s *timeoutsSuite) TestResponseHeaderTimeout(c *gc.C) {
+	release := make(chan struct{})
+	defer close(release)
+	server := s.newZonesServer(func(w http.ResponseWriter, r *http.Request) {
+		select {
+		case <-release:
+		case <-r.Context().Done():
+		}
+	})
+	maas, err := NewController(ControllerArgs{
+		BaseURL:     server.URL,
+		APIKey:      "fake:as:key",
+		Timeouts:    Timeouts{ResponseHeader: 50 * time.Millisecond},
+		RetryPolicy: &RetryPolicy{},
+	})
+	c.Assert(err, jc.ErrorIsNil)
+
+	_, err = maas.Zones()
+	c.Assert(err, gc.ErrorMatches, ".*timeout awaiting response headers.*")
+}
+
+func (s *timeoutsSuite) TestTransportIsCopied(c *gc.C) {
+	transport := &http.Transport{MaxIdleConns: 7}
+	server := s.newZonesServer(func(w http.ResponseWriter, r *http.Request) {
+		fmt.Fprint(w, zoneResponse)
+	})
+	maas, err := NewController(ControllerArgs{
+		BaseURL:    server.URL,
+		APIKey:     "fake:as:key",
+		HTTPClient: &http.Client{Transport: transport},
+		Timeouts:   Timeouts{Connect: 3 * time.Second, ResponseHeader: 5 * time.Second},
+	})
+	c.Assert(err, jc.ErrorIsNil)
+
+	used := maas.(*controller).client.HTTPClient.Transport.(*http.Transport)
+	c.Check(used == transport, jc.IsFalse)
+	c.Check(used.MaxIdleConns, gc.Equals, 7)
+	c.Check(used.TLSHandshakeTimeout, gc.Equals, 3*time.Second)
+	c.Check(used.ResponseHeaderTimeout, gc.Equals, 5*time.Second)
+	c.Check(used.DialContext, gc.NotNil)
+	c.Check(transport.ResponseHeaderTimeout, gc.Equals, time.Duration(0))
+
+	_, err = maas.Zones()
+	c.Assert(err, jc.ErrorIsNil)
+}
+
+func (s *timeoutsSuite) TestUnsupportedTransport(c *gc.C) {
+	_, err := NewController(ControllerArgs{
+		BaseURL:   "http://maas.invalid/MAAS/",
+		APIKey:    "fake:as:key",
+		Transport: &recordingTransport{},
+		Timeouts:  Timeouts{Connect: time.Second},
+	})
+	c.Assert(err, jc.Satisfies, errors.IsNotValid)
+}
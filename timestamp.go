@@ -0,0 +1,35 @@
+// Copyright 2022 Canonical Ltd.
+// Licensed under the LGPLv3, see LICENCE file for details.
+
+package gomaasapi
+
+import (
+	"time"
+
+	"github.com/juju/errors"
+)
+
+// timestampLayouts are the layouts that MAAS uses for timestamps. Only the
+// first includes a timezone, MAAS otherwise returns naive timestamps in the
+// timezone of the region, which is UTC.
+var timestampLayouts = []string{
+	time.RFC3339Nano,
+	"2006-01-02T15:04:05.999999",
+	"2006-01-02 15:04:05.999999",
+	"Mon, 02 Jan. 2006 15:04:05",
+}
+
+// parseTimestamp parses a timestamp returned by MAAS. Timestamps that don't
+// include a timezone are interpreted in the location specified, defaulting
+// to UTC if it is nil. The result is always normalized to UTC.
+func parseTimestamp(value string, location *time.Location) (time.Time, error) {
+	if location == nil {
+		location = time.UTC
+	}
+	for _, layout := range timestampLayouts {
+		if parsed, err := time.ParseInLocation(layout, value, location); err == nil {
+			return parsed.UTC(), nil
+		}
+	}
+	return time.Time{}, errors.NotValidf("timestamp %q", value)
+}
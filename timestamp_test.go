@@ -0,0 +1,56 @@
+// Copyright 2022 Canonical Ltd.
+// Licensed under the LGPLv3, see LICENCE file for details.
+
+package gomaasapi
+
+import (
+	"time"
+
+	"github.com/juju/errors"
+	jc "github.com/juju/testing/checkers"
+	gc "gopkg.in/check.v1"
+)
+
+type timestampSuite struct{}
+
+var _ = gc.Suite(&timestampSuite{})
+
+func (*timestampSuite) TestParseTimestamp(c *gc.C) {
+	expected := time.Date(2019, time.March, 4, 10, 20, 30, 0, time.UTC)
+	for i, value := range []string{
+		"2019-03-04T10:20:30Z",
+		"2019-03-04T12:20:30+02:00",
+		"2019-03-04T10:20:30",
+		"2019-03-04 10:20:30",
+		"Mon, 04 Mar. 2019 10:20:30",
+	} {
+		c.Logf("test %d: %s", i, value)
+		parsed, err := parseTimestamp(value, nil)
+		c.Check(err, jc.ErrorIsNil)
+		c.Check(parsed, gc.Equals, expected)
+	}
+}
+
+func (*timestampSuite) TestParseTimestampFractional(c *gc.C) {
+	parsed, err := parseTimestamp("2019-03-04T10:20:30.250000", nil)
+	c.Assert(err, jc.ErrorIsNil)
+	c.Assert(parsed, gc.Equals, time.Date(2019, time.March, 4, 10, 20, 30, 250000000, time.UTC))
+}
+
+func (*timestampSuite) TestParseTimestampLocation(c *gc.C) {
+	location := time.FixedZone("UTC+2", 2*60*60)
+	// Naive timestamps are interpreted in the location.
+	parsed, err := parseTimestamp("2019-03-04 12:20:30", location)
+	c.Assert(err, jc.ErrorIsNil)
+	c.Assert(parsed, gc.Equals, time.Date(2019, time.March, 4, 10, 20, 30, 0, time.UTC))
+	// The location doesn't override an explicit timezone.
+	parsed, err = parseTimestamp("2019-03-04T10:20:30Z", location)
+	c.Assert(err, jc.ErrorIsNil)
+	c.Assert(parsed, gc.Equals, time.Date(2019, time.March, 4, 10, 20, 30, 0, time.UTC))
+}
+
+func (*timestampSuite) TestParseTimestampInvalid(c *gc.C) {
+	_, err := parseTimestamp("yesterday", nil)
+	c.Assert(err, jc.Satisfies, errors.IsNotValid)
+	c.Assert(err.Error(), gc.Equals, `timestamp "yesterday" not valid`)
+}
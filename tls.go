@@ -0,0 +1,75 @@
+// Copyright 2022 Canonical Ltd.
+// Licensed under the LGPLv3, see LICENCE file for details.
+
+package gomaasapi
+
+import (
+	"crypto/tls"
+	"crypto/x509"
+	"net/http"
+
+	"github.com/juju/errors"
+)
+
+// TLSConfig holds the settings used to connect to a MAAS region over HTTPS,
+// for instance one using a self-signed certificate.
+type TLSConfig struct {
+	// CACertificates, if set, holds the PEM encoded certificates that the
+	// certificate of the server is verified against, instead of the
+	// certificate authorities of the system.
+	CACertificates []byte
+	// ClientCertificate and ClientKey, if set, are the PEM encoded
+	// certificate and private key presented to the server.
+	ClientCertificate []byte
+	ClientKey         []byte
+	// ServerName, if set, is the name the certificate of the server is
+	// verified against, instead of the host of the BaseURL.
+	ServerName string
+	// InsecureSkipVerify disables the verification of the certificate of
+	// the server. It should only be used for testing.
+	InsecureSkipVerify bool
+}
+
+// Validate checks the certificates and key of the config can be read.
+func (c TLSConfig) Validate() error {
+	_, err := c.tlsConfig()
+	return errors.Trace(err)
+}
+
+func (c TLSConfig) tlsConfig() (*tls.Config, error) {
+	config := &tls.Config{
+		ServerName:         c.ServerName,
+		InsecureSkipVerify: c.InsecureSkipVerify,
+	}
+	if len(c.CACertificates) > 0 {
+		pool := x509.NewCertPool()
+		if !pool.AppendCertsFromPEM(c.CACertificates) {
+			return nil, errors.NotValidf("CACertificates without PEM certificates")
+		}
+		config.RootCAs = pool
+	}
+	if len(c.ClientCertificate) > 0 || len(c.ClientKey) > 0 {
+		if len(c.ClientCertificate) == 0 {
+			return nil, errors.NotValidf("ClientKey without ClientCertificate")
+		}
+		if len(c.ClientKey) == 0 {
+			return nil, errors.NotValidf("ClientCertificate without ClientKey")
+		}
+		certificate, err := tls.X509KeyPair(c.ClientCertificate, c.ClientKey)
+		if err != nil {
+			return nil, errors.NewNotValid(err, "client certificate")
+		}
+		config.Certificates = []tls.Certificate{certificate}
+	}
+	return config, nil
+}
+
+// apply sets the config on the transport.
+func (c TLSConfig) apply(httpTransport *http.Transport) error {
+	config, err := c.tlsConfig()
+	if err != nil {
+		return errors.Trace(err)
+	}
+	httpTransport.TLSClientConfig = config
+	return nil
+}
@@ -0,0 +1,183 @@
+// Copyright 2022 Canonical Ltd.
+// Licensed under the LGPLv3, see LICENCE file for details.
+
+package gomaasapi
+
+import (
+	"crypto/ecdsa"
+	"crypto/elliptic"
+	"crypto/rand"
+	"crypto/tls"
+	"crypto/x509"
+	"crypto/x509/pkix"
+	"encoding/pem"
+	"io"
+	"log"
+	"math/big"
+	"net/http"
+	"time"
+
+	"github.com/juju/errors"
+	"github.com/juju/testing"
+	jc "github.com/juju/testing/checkers"
+	gc "gopkg.in/check.v1"
+)
+
+type tlsSuite struct {
+	testing.LoggingCleanupSuite
+}
+
+var _ = gc.Suite(&tlsSuite{})
+
+// newClientCertificate returns a PEM encoded self-signed certificate and its
+// key.
+func newClientCertificate(c *gc.C) ([]byte, []byte) {
+	key, err := ecdsa.GenerateKey(elliptic.P256(), rand.Reader)
+	c.Assert(err, jc.ErrorIsNil)
+	template := &x509.Certificate{
+		SerialNumber: big.NewInt(1),
+		Subject:      pkix.Name{CommonName: "client"},
+		NotBefore:    time.Now().Add(-time.Hour),
+		NotAfter:     time.Now().Add(time.Hour),
+		ExtKeyUsage:  []x509.ExtKeyUsage{x509.ExtKeyUsageClientAuth},
+	}
+	der, err := x509.CreateCertificate(rand.Reader, template, template, &key.PublicKey, key)
+	c.Assert(err, jc.ErrorIsNil)
+	keyDER, err := x509.MarshalECPrivateKey(key)
+	c.Assert(err, jc.ErrorIsNil)
+	return pem.EncodeToMemory(&pem.Block{Type: "CERTIFICATE", Bytes: der}),
+		pem.EncodeToMemory(&pem.Block{Type: "EC PRIVATE KEY", Bytes: keyDER})
+}
+
+func (s *tlsSuite) startTLSServer(c *gc.C, config *tls.Config) (*SimpleTestServer, []byte) {
+	server := NewSimpleServer()
+	server.AddGetResponse("/api/2.0/users/?op=whoami", http.StatusOK, `"captain awesome"`)
+	server.AddGetResponse("/api/2.0/version/", http.StatusOK, versionResponse)
+	server.TLS = config
+	// Failed handshakes are expected, don't log them.
+	server.Config.ErrorLog = log.New(io.Discard, "", 0)
+	server.StartTLS()
+	s.AddCleanup(func(*gc.C) { server.Close() })
+	caPEM := pem.EncodeToMemory(&pem.Block{Type: "CERTIFICATE", Bytes: server.Certificate().Raw})
+	return server, caPEM
+}
+
+func (*tlsSuite) TestValidate(c *gc.C) {
+	certPEM, keyPEM := newClientCertificate(c)
+	c.Check(TLSConfig{}.Validate(), jc.ErrorIsNil)
+	c.Check(TLSConfig{CACertificates: certPEM}.Validate(), jc.ErrorIsNil)
+	c.Check(TLSConfig{ClientCertificate: certPEM, ClientKey: keyPEM}.Validate(), jc.ErrorIsNil)
+
+	for i, test := range []struct {
+		config  TLSConfig
+		message string
+	}{{
+		config:  TLSConfig{CACertificates: []byte("junk")},
+		message: "CACertificates without PEM certificates not valid",
+	}, {
+		config:  TLSConfig{ClientCertificate: certPEM},
+		message: "ClientCertificate without ClientKey not valid",
+	}, {
+		config:  TLSConfig{ClientKey: keyPEM},
+		message: "ClientKey without ClientCertificate not valid",
+	}, {
+		config:  TLSConfig{ClientCertificate: keyPEM, ClientKey: certPEM},
+		message: "client certificate: .*",
+	}} {
+		c.Logf("test %d", i)
+		err := test.config.Validate()
+		c.Check(err, jc.Satisfies, errors.IsNotValid)
+		c.Check(err, gc.ErrorMatches, test.message)
+	}
+}
+
+func (s *tlsSuite) TestCACertificates(c *gc.C) {
+	server, caPEM := s.startTLSServer(c, nil)
+	controller, err := NewController(ControllerArgs{
+		BaseURL:   server.URL,
+		APIKey:    "fake:as:key",
+		TLSConfig: &TLSConfig{CACertificates: caPEM},
+	})
+	c.Assert(err, jc.ErrorIsNil)
+	c.Check(controller.Capabilities().Contains(NetworksManagement), jc.IsTrue)
+}
+
+func (s *tlsSuite) TestUnknownAuthority(c *gc.C) {
+	server, _ := s.startTLSServer(c, nil)
+	_, err := NewController(ControllerArgs{
+		BaseURL: server.URL,
+		APIKey:  "fake:as:key",
+	})
+	c.Assert(err, gc.ErrorMatches, ".*certificate signed by unknown authority.*")
+}
+
+func (s *tlsSuite) TestInsecureSkipVerify(c *gc.C) {
+	server, _ := s.startTLSServer(c, nil)
+	_, err := NewController(ControllerArgs{
+		BaseURL:   server.URL,
+		APIKey:    "fake:as:key",
+		TLSConfig: &TLSConfig{InsecureSkipVerify: true},
+	})
+	c.Assert(err, jc.ErrorIsNil)
+}
+
+func (s *tlsSuite) TestServerName(c *gc.C) {
+	server, caPEM := s.startTLSServer(c, nil)
+	// The certificate of the test server is valid for example.com.
+	_, err := NewController(ControllerArgs{
+		BaseURL:   server.URL,
+		APIKey:    "fake:as:key",
+		TLSConfig: &TLSConfig{CACertificates: caPEM, ServerName: "example.com"},
+	})
+	c.Assert(err, jc.ErrorIsNil)
+
+	_, err = NewController(ControllerArgs{
+		BaseURL:   server.URL,
+		APIKey:    "fake:as:key",
+		TLSConfig: &TLSConfig{CACertificates: caPEM, ServerName: "maas.example.org"},
+	})
+	c.Assert(err, gc.ErrorMatches, ".*certificate is valid for .*, not maas.example.org.*")
+}
+
+func (s *tlsSuite) TestClientCertificate(c *gc.C) {
+	certPEM, keyPEM := newClientCertificate(c)
+	server, caPEM := s.startTLSServer(c, &tls.Config{ClientAuth: tls.RequireAnyClientCert})
+	_, err := NewController(ControllerArgs{
+		BaseURL: server.URL,
+		APIKey:  "fake:as:key",
+		TLSConfig: &TLSConfig{
+			CACertificates:    caPEM,
+			ClientCertificate: certPEM,
+			ClientKey:         keyPEM,
+		},
+	})
+	c.Assert(err, jc.ErrorIsNil)
+	c.Check(server.LastRequest().TLS.PeerCertificates, gc.HasLen, 1)
+}
+
+func (s *tlsSuite) TestHTTPClientTransportIsCopied(c *gc.C) {
+	server, caPEM := s.startTLSServer(c, nil)
+	transport := &http.Transport{MaxIdleConns: 7}
+	httpClient := &http.Client{Transport: transport}
+	maas, err := NewController(ControllerArgs{
+		BaseURL:    server.URL,
+		APIKey:     "fake:as:key",
+		HTTPClient: httpClient,
+		TLSConfig:  &TLSConfig{CACertificates: caPEM},
+	})
+	c.Assert(err, jc.ErrorIsNil)
+	used := maas.(*controller).client.HTTPClient.Transport.(*http.Transport)
+	c.Check(used.MaxIdleConns, gc.Equals, 7)
+	c.Check(used.TLSClientConfig.RootCAs, gc.NotNil)
+	c.Check(transport.TLSClientConfig == nil || transport.TLSClientConfig.RootCAs == nil, jc.IsTrue)
+}
+
+func (*tlsSuite) TestUnsupportedTransport(c *gc.C) {
+	_, err := NewController(ControllerArgs{
+		BaseURL:   "https://maas.example.com/MAAS/",
+		APIKey:    "fake:as:key",
+		Transport: &recordingTransport{},
+		TLSConfig: &TLSConfig{},
+	})
+	c.Assert(err, jc.Satisfies, errors.IsNotValid)
+}
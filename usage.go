@@ -0,0 +1,86 @@
+// Copyright 2022 Canonical Ltd.
+// Licensed under the LGPLv3, see LICENCE file for details.
+
+package gomaasapi
+
+import (
+	"fmt"
+	"strings"
+
+	"github.com/juju/collections/set"
+)
+
+// ResourceUsage lists the resources that depend on a subnet or a VLAN, as
+// returned by Controller.SubnetUsage and Controller.VLANUsage.
+type ResourceUsage struct {
+	// Subnets are the CIDRs of the subnets on a VLAN.
+	Subnets []string
+	// Machines are the system IDs of the machines with interfaces on the
+	// subnet or VLAN.
+	Machines []string
+	// Links describe the interfaces on the subnet or VLAN, such as
+	// "untasted-markita:eth0".
+	Links []string
+	// Reservations are the IP addresses reserved in the subnet, or in the
+	// subnets of the VLAN.
+	Reservations []string
+}
+
+// InUse returns true if anything depends on the resource.
+func (u ResourceUsage) InUse() bool {
+	return len(u.Subnets)+len(u.Machines)+len(u.Links)+len(u.Reservations) > 0
+}
+
+// String describes how many resources of each kind depend on the resource.
+func (u ResourceUsage) String() string {
+	var parts []string
+	for _, count := range []struct {
+		n    int
+		kind string
+	}{
+		{len(u.Subnets), "subnet"},
+		{len(u.Machines), "machine"},
+		{len(u.Links), "link"},
+		{len(u.Reservations), "reservation"},
+	} {
+		switch count.n {
+		case 0:
+		case 1:
+			parts = append(parts, "1 "+count.kind)
+		default:
+			parts = append(parts, fmt.Sprintf("%d %ss", count.n, count.kind))
+		}
+	}
+	if len(parts) == 0 {
+		return "nothing"
+	}
+	return strings.Join(parts, ", ")
+}
+
+// usageCollector accumulates the usage of a resource, keeping each machine
+// once.
+type usageCollector struct {
+	usage    ResourceUsage
+	machines set.Strings
+}
+
+func newUsageCollector() *usageCollector {
+	return &usageCollector{machines: set.NewStrings()}
+}
+
+func (u *usageCollector) addLink(machine Machine, iface Interface) {
+	u.usage.Links = append(u.usage.Links, machine.Hostname()+":"+iface.Name())
+	if !u.machines.Contains(machine.SystemID()) {
+		u.machines.Add(machine.SystemID())
+		u.usage.Machines = append(u.usage.Machines, machine.SystemID())
+	}
+}
+
+func interfaceOnSubnet(iface Interface, subnetID int) bool {
+	for _, link := range iface.Links() {
+		if subnet := link.Subnet(); subnet != nil && subnet.ID() == subnetID {
+			return true
+		}
+	}
+	return false
+}
@@ -0,0 +1,40 @@
+// Copyright 2022 Canonical Ltd.
+// Licensed under the LGPLv3, see LICENCE file for details.
+
+package gomaasapi
+
+import (
+	jc "github.com/juju/testing/checkers"
+	gc "gopkg.in/check.v1"
+)
+
+type usageSuite struct{}
+
+var _ = gc.Suite(&usageSuite{})
+
+func (*usageSuite) TestInUse(c *gc.C) {
+	c.Check(ResourceUsage{}.InUse(), jc.IsFalse)
+	c.Check(ResourceUsage{Subnets: []string{"10.0.0.0/24"}}.InUse(), jc.IsTrue)
+	c.Check(ResourceUsage{Reservations: []string{"10.0.0.5"}}.InUse(), jc.IsTrue)
+}
+
+func (*usageSuite) TestString(c *gc.C) {
+	c.Check(ResourceUsage{}.String(), gc.Equals, "nothing")
+	c.Check(ResourceUsage{
+		Subnets:      []string{"10.0.0.0/24", "10.0.1.0/24"},
+		Machines:     []string{"4y3ha3"},
+		Links:        []string{"untasted-markita:eth0"},
+		Reservations: []string{"10.0.0.5", "10.0.0.6", "10.0.1.5"},
+	}.String(), gc.Equals, "2 subnets, 1 machine, 1 link, 3 reservations")
+}
+
+func (*usageSuite) TestCollectorKeepsMachinesOnce(c *gc.C) {
+	machine := &machine{systemID: "4y3ha3", hostname: "untasted-markita"}
+	usage := newUsageCollector()
+	usage.addLink(machine, &interface_{name: "eth0"})
+	usage.addLink(machine, &interface_{name: "eth1"})
+	c.Check(usage.usage, jc.DeepEquals, ResourceUsage{
+		Machines: []string{"4y3ha3"},
+		Links:    []string{"untasted-markita:eth0", "untasted-markita:eth1"},
+	})
+}
@@ -0,0 +1,267 @@
+// Copyright 2022 Canonical Ltd.
+// Licensed under the LGPLv3, see LICENCE file for details.
+
+package gomaasapi
+
+import (
+	"net/http"
+
+	"github.com/juju/errors"
+	"github.com/juju/schema"
+	"github.com/juju/version"
+)
+
+type user struct {
+	controller *controller
+
+	resourceURI string
+
+	username string
+	email    string
+	admin    bool
+	local    bool
+}
+
+// Username implements User.
+func (u *user) Username() string {
+	return u.username
+}
+
+// Email implements User.
+func (u *user) Email() string {
+	return u.email
+}
+
+// IsAdmin implements User.
+func (u *user) IsAdmin() bool {
+	return u.admin
+}
+
+// IsLocal implements User.
+func (u *user) IsLocal() bool {
+	return u.local
+}
+
+// Delete implements User.
+func (u *user) Delete() error {
+	err := u.controller.delete(u.resourceURI)
+	if err != nil {
+		if svrErr, ok := errors.Cause(err).(ServerError); ok {
+			switch svrErr.StatusCode {
+			case http.StatusNotFound:
+				return wrapError(err, NewNoMatchError(svrErr.BodyMessage))
+			case http.StatusForbidden:
+				return wrapError(err, NewPermissionError(svrErr.BodyMessage))
+			case http.StatusBadRequest:
+				return wrapError(err, NewBadRequestError(svrErr.BodyMessage))
+			}
+		}
+		return NewUnexpectedError(err)
+	}
+	return nil
+}
+
+// Users implements Controller.
+func (c *controller) Users() ([]User, error) {
+	source, err := c.getList("users", nil, 0)
+	if err != nil {
+		if svrErr, ok := errors.Cause(err).(ServerError); ok {
+			if svrErr.StatusCode == http.StatusForbidden {
+				return nil, wrapError(err, NewPermissionError(svrErr.BodyMessage))
+			}
+		}
+		return nil, NewUnexpectedError(err)
+	}
+	users, err := readUsers(c.schemaVersion(), source)
+	if err != nil {
+		return nil, errors.Trace(err)
+	}
+	var result []User
+	for _, u := range users {
+		u.controller = c
+		result = append(result, u)
+	}
+	return result, nil
+}
+
+// WhoAmI implements Controller.
+func (c *controller) WhoAmI() (User, error) {
+	source, err := c.getOp("users", "whoami")
+	if err != nil {
+		if svrErr, ok := errors.Cause(err).(ServerError); ok {
+			if svrErr.StatusCode == http.StatusUnauthorized {
+				return nil, wrapError(err, NewPermissionError(svrErr.BodyMessage))
+			}
+		}
+		return nil, NewUnexpectedError(err)
+	}
+	result, err := readUser(c.schemaVersion(), source)
+	if err != nil {
+		return nil, errors.Trace(err)
+	}
+	result.controller = c
+	return result, nil
+}
+
+// CreateUserArgs is an argument struct for Controller.CreateUser.
+type CreateUserArgs struct {
+	Username string
+	Email    string
+	Password string
+	// Admin makes the user an administrator of MAAS.
+	Admin bool
+}
+
+// Validate ensures that the username, email and password are set.
+func (a *CreateUserArgs) Validate() error {
+	if a.Username == "" {
+		return errors.NotValidf("missing Username")
+	}
+	if a.Email == "" {
+		return errors.NotValidf("missing Email")
+	}
+	if a.Password == "" {
+		return errors.NotValidf("missing Password")
+	}
+	return nil
+}
+
+// CreateUser implements Controller.
+func (c *controller) CreateUser(args CreateUserArgs) (User, error) {
+	if err := args.Validate(); err != nil {
+		return nil, errors.Trace(err)
+	}
+	params := NewURLParams()
+	params.Values.Add("username", args.Username)
+	params.Values.Add("email", args.Email)
+	params.Values.Add("password", args.Password)
+	// MAAS requires is_superuser, and parses it as a number.
+	if args.Admin {
+		params.Values.Add("is_superuser", "1")
+	} else {
+		params.Values.Add("is_superuser", "0")
+	}
+	source, err := c.post("users", "", params.Values)
+	if err != nil {
+		if svrErr, ok := errors.Cause(err).(ServerError); ok {
+			switch svrErr.StatusCode {
+			case http.StatusForbidden:
+				return nil, wrapError(err, NewPermissionError(svrErr.BodyMessage))
+			case http.StatusBadRequest:
+				return nil, wrapError(err, NewBadRequestError(svrErr.BodyMessage))
+			}
+		}
+		return nil, NewUnexpectedError(err)
+	}
+	result, err := readUser(c.schemaVersion(), source)
+	if err != nil {
+		return nil, errors.Trace(err)
+	}
+	result.controller = c
+	return result, nil
+}
+
+func readUser(controllerVersion version.Number, source interface{}) (*user, error) {
+	readFunc, err := getUserDeserializationFunc(controllerVersion)
+	if err != nil {
+		return nil, errors.Trace(err)
+	}
+
+	checker := schema.StringMap(schema.Any())
+	coerced, err := checker.Coerce(source, nil)
+	if err != nil {
+		return nil, WrapWithDeserializationError(err, "user base schema check failed")
+	}
+	valid := coerced.(map[string]interface{})
+	return readFunc(valid)
+}
+
+func readUsers(controllerVersion version.Number, source interface{}) ([]*user, error) {
+	readFunc, err := getUserDeserializationFunc(controllerVersion)
+	if err != nil {
+		return nil, errors.Trace(err)
+	}
+
+	checker := schema.List(schema.StringMap(schema.Any()))
+	coerced, err := checker.Coerce(source, nil)
+	if err != nil {
+		return nil, WrapWithDeserializationError(err, "user base schema check failed")
+	}
+	valid := coerced.([]interface{})
+	return readUserList(valid, readFunc)
+}
+
+func getUserDeserializationFunc(controllerVersion version.Number) (userDeserializationFunc, error) {
+	var deserialisationVersion version.Number
+	for v := range userDeserializationFuncs {
+		if v.Compare(deserialisationVersion) > 0 && v.Compare(controllerVersion) <= 0 {
+			deserialisationVersion = v
+		}
+	}
+	if deserialisationVersion == version.Zero {
+		return nil, NewUnsupportedVersionError("no user read func for version %s", controllerVersion)
+	}
+	return userDeserializationFuncs[deserialisationVersion], nil
+}
+
+// readUserList expects the values of the sourceList to be string maps.
+func readUserList(sourceList []interface{}, readFunc userDeserializationFunc) ([]*user, error) {
+	result := make([]*user, 0, len(sourceList))
+	for i, value := range sourceList {
+		source, ok := value.(map[string]interface{})
+		if !ok {
+			return nil, NewDeserializationError("unexpected value for user %d, %T", i, value)
+		}
+		user, err := readFunc(source)
+		if err != nil {
+			return nil, errors.Annotatef(err, "user %d", i)
+		}
+		result = append(result, user)
+	}
+	return result, nil
+}
+
+type userDeserializationFunc func(map[string]interface{}) (*user, error)
+
+var userDeserializationFuncs = map[version.Number]userDeserializationFunc{
+	twoDotOh: user_2_0,
+}
+
+func user_2_0(source map[string]interface{}) (*user, error) {
+	fields := schema.Fields{
+		"resource_uri": schema.String(),
+		"username":     schema.String(),
+		"email":        schema.OneOf(schema.Nil(""), schema.String()),
+		"is_superuser": schema.Bool(),
+		"is_local":     schema.Bool(),
+	}
+	defaults := schema.Defaults{
+		// The whoami op doesn't include the resource URI.
+		"resource_uri": "",
+		"email":        "",
+		"is_local":     true,
+	}
+	checker := schema.FieldMap(fields, defaults)
+	coerced, err := checker.Coerce(source, nil)
+	if err != nil {
+		return nil, WrapWithDeserializationError(err, "user 2.0 schema check failed")
+	}
+	valid := coerced.(map[string]interface{})
+	// From here we know that the map returned from the schema coercion
+	// contains fields of the right type.
+
+	username := valid["username"].(string)
+	resourceURI := valid["resource_uri"].(string)
+	if resourceURI == "" {
+		resourceURI = "users/" + username
+	}
+	email, _ := valid["email"].(string)
+	result := &user{
+		resourceURI: resourceURI,
+		username:    username,
+		email:       email,
+		admin:       valid["is_superuser"].(bool),
+		local:       valid["is_local"].(bool),
+	}
+	return result, nil
+}
@@ -0,0 +1,192 @@
+// Copyright 2022 Canonical Ltd.
+// Licensed under the LGPLv3, see LICENCE file for details.
+
+package gomaasapi
+
+import (
+	"net/http"
+
+	"github.com/juju/errors"
+	"github.com/juju/testing"
+	jc "github.com/juju/testing/checkers"
+	"github.com/juju/version"
+	gc "gopkg.in/check.v1"
+)
+
+type userSuite struct {
+	testing.LoggingCleanupSuite
+}
+
+var _ = gc.Suite(&userSuite{})
+
+func (*userSuite) TestReadUsersBadSchema(c *gc.C) {
+	_, err := readUsers(twoDotOh, "wat?")
+	c.Check(err, jc.Satisfies, IsDeserializationError)
+	c.Assert(err.Error(), gc.Equals, `user base schema check failed: expected list, got string("wat?")`)
+}
+
+func (*userSuite) TestReadUsers(c *gc.C) {
+	users, err := readUsers(twoDotOh, parseJSON(c, usersResponse))
+	c.Assert(err, jc.ErrorIsNil)
+	c.Assert(users, gc.HasLen, 2)
+
+	user := users[0]
+	c.Check(user.resourceURI, gc.Equals, "/api/2.0/users/admin/")
+	c.Check(user.Username(), gc.Equals, "admin")
+	c.Check(user.Email(), gc.Equals, "admin@example.com")
+	c.Check(user.IsAdmin(), jc.IsTrue)
+	c.Check(user.IsLocal(), jc.IsTrue)
+
+	user = users[1]
+	c.Check(user.Username(), gc.Equals, "lab")
+	c.Check(user.Email(), gc.Equals, "")
+	c.Check(user.IsAdmin(), jc.IsFalse)
+	c.Check(user.IsLocal(), jc.IsFalse)
+}
+
+func (*userSuite) TestLowVersion(c *gc.C) {
+	_, err := readUsers(version.MustParse("1.9.0"), parseJSON(c, usersResponse))
+	c.Assert(err, jc.Satisfies, IsUnsupportedVersionError)
+}
+
+func (*userSuite) TestHighVersion(c *gc.C) {
+	users, err := readUsers(version.MustParse("2.1.9"), parseJSON(c, usersResponse))
+	c.Assert(err, jc.ErrorIsNil)
+	c.Assert(users, gc.HasLen, 2)
+}
+
+func (s *userSuite) TestUsers(c *gc.C) {
+	server, controller := createTestServerController(c, s)
+	server.AddGetResponse("/api/2.0/users/", http.StatusOK, usersResponse)
+	users, err := controller.Users()
+	c.Assert(err, jc.ErrorIsNil)
+	c.Assert(users, gc.HasLen, 2)
+	c.Assert(users[1].Username(), gc.Equals, "lab")
+}
+
+func (s *userSuite) TestUsersForbidden(c *gc.C) {
+	server, controller := createTestServerController(c, s)
+	server.AddGetResponse("/api/2.0/users/", http.StatusForbidden, "admins only")
+	_, err := controller.Users()
+	c.Assert(err, jc.Satisfies, IsPermissionError)
+}
+
+func (s *userSuite) TestWhoAmI(c *gc.C) {
+	server, controller := createTestServerController(c, s)
+	server.AddGetResponse("/api/2.0/users/?op=whoami", http.StatusOK, `
+{
+    "is_superuser": false,
+    "username": "lab",
+    "email": "lab@example.com",
+    "is_local": true
+}`)
+	user, err := controller.WhoAmI()
+	c.Assert(err, jc.ErrorIsNil)
+	c.Check(user.Username(), gc.Equals, "lab")
+	c.Check(user.Email(), gc.Equals, "lab@example.com")
+	c.Check(user.IsAdmin(), jc.IsFalse)
+
+	server.AddDeleteResponse("/api/2.0/users/lab/", http.StatusNoContent, "")
+	err = user.Delete()
+	c.Assert(err, jc.ErrorIsNil)
+}
+
+func (s *userSuite) TestCreateUserArgsValidate(c *gc.C) {
+	for i, test := range []struct {
+		args    CreateUserArgs
+		message string
+	}{{
+		args:    CreateUserArgs{Email: "lab@example.com", Password: "secret"},
+		message: "missing Username",
+	}, {
+		args:    CreateUserArgs{Username: "lab", Password: "secret"},
+		message: "missing Email",
+	}, {
+		args:    CreateUserArgs{Username: "lab", Email: "lab@example.com"},
+		message: "missing Password",
+	}, {
+		args: CreateUserArgs{Username: "lab", Email: "lab@example.com", Password: "secret"},
+	}} {
+		c.Logf("test %d", i)
+		err := test.args.Validate()
+		if test.message == "" {
+			c.Check(err, jc.ErrorIsNil)
+		} else {
+			c.Check(err, jc.Satisfies, errors.IsNotValid)
+			c.Check(err.Error(), gc.Equals, test.message+" not valid")
+		}
+	}
+}
+
+func (s *userSuite) TestCreateUser(c *gc.C) {
+	server, controller := createTestServerController(c, s)
+	server.AddPostResponse("/api/2.0/users/?op=", http.StatusOK, `
+{
+    "is_superuser": true,
+    "username": "lab",
+    "email": "lab@example.com",
+    "is_local": true,
+    "resource_uri": "/api/2.0/users/lab/"
+}`)
+	user, err := controller.CreateUser(CreateUserArgs{
+		Username: "lab",
+		Email:    "lab@example.com",
+		Password: "secret",
+		Admin:    true,
+	})
+	c.Assert(err, jc.ErrorIsNil)
+	c.Check(user.Username(), gc.Equals, "lab")
+	c.Check(user.IsAdmin(), jc.IsTrue)
+
+	form := server.LastRequest().PostForm
+	c.Check(form.Get("username"), gc.Equals, "lab")
+	c.Check(form.Get("email"), gc.Equals, "lab@example.com")
+	c.Check(form.Get("password"), gc.Equals, "secret")
+	c.Check(form.Get("is_superuser"), gc.Equals, "1")
+}
+
+func (s *userSuite) TestCreateUserBadRequest(c *gc.C) {
+	server, controller := createTestServerController(c, s)
+	server.AddPostResponse("/api/2.0/users/?op=", http.StatusBadRequest, "username taken")
+	_, err := controller.CreateUser(CreateUserArgs{Username: "lab", Email: "lab@example.com", Password: "secret"})
+	c.Assert(err, jc.Satisfies, IsBadRequestError)
+	c.Assert(server.LastRequest().PostForm.Get("is_superuser"), gc.Equals, "0")
+}
+
+func (s *userSuite) TestDelete(c *gc.C) {
+	server, controller := createTestServerController(c, s)
+	server.AddGetResponse("/api/2.0/users/", http.StatusOK, usersResponse)
+	users, err := controller.Users()
+	c.Assert(err, jc.ErrorIsNil)
+	server.AddDeleteResponse("/api/2.0/users/lab/", http.StatusNoContent, "")
+	err = users[1].Delete()
+	c.Assert(err, jc.ErrorIsNil)
+}
+
+func (s *userSuite) TestDeleteNotFound(c *gc.C) {
+	server, controller := createTestServerController(c, s)
+	server.AddGetResponse("/api/2.0/users/", http.StatusOK, usersResponse)
+	users, err := controller.Users()
+	c.Assert(err, jc.ErrorIsNil)
+	err = users[1].Delete()
+	c.Assert(err, jc.Satisfies, IsNoMatchError)
+}
+
+const usersResponse = `
+[
+    {
+        "is_superuser": true,
+        "username": "admin",
+        "email": "admin@example.com",
+        "is_local": true,
+        "resource_uri": "/api/2.0/users/admin/"
+    },
+    {
+        "is_superuser": false,
+        "username": "lab",
+        "email": null,
+        "is_local": false,
+        "resource_uri": "/api/2.0/users/lab/"
+    }
+]
+`
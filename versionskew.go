@@ -0,0 +1,96 @@
+// Copyright 2022 Canonical Ltd.
+// Licensed under the LGPLv3, see LICENCE file for details.
+
+package gomaasapi
+
+import (
+	"regexp"
+
+	"github.com/juju/errors"
+)
+
+// RackVersion is the version of MAAS a rack controller runs.
+type RackVersion struct {
+	SystemID string
+	Hostname string
+	// Version is as reported by the rack controller, such as
+	// "3.2.6-12016-g.19812b4da". It is empty if the rack controller hasn't
+	// reported it, which older rack controllers don't.
+	Version string
+	// Matches is true if the rack controller runs the same release as the
+	// region. It is false if the version isn't known.
+	Matches bool
+}
+
+// VersionSkew compares the versions of MAAS that the region and the rack
+// controllers run. MAAS doesn't support rack controllers that run a
+// different release than the region, and deployments through them tend to
+// fail, so upgrades are complete once the versions are consistent.
+type VersionSkew struct {
+	// RegionVersion is the version reported by the region API.
+	RegionVersion string
+	Racks         []RackVersion
+}
+
+// Consistent returns true if all the rack controllers run the same release
+// as the region.
+func (s VersionSkew) Consistent() bool {
+	return len(s.Mismatched()) == 0
+}
+
+// Mismatched returns the rack controllers that don't run the same release
+// as the region, or whose version isn't known.
+func (s VersionSkew) Mismatched() []RackVersion {
+	var result []RackVersion
+	for _, rack := range s.Racks {
+		if !rack.Matches {
+			result = append(result, rack)
+		}
+	}
+	return result
+}
+
+// releasePattern matches the release at the start of a MAAS version, such
+// as "2.5.0" in "2.5.0 from source" or "3.2" in "3.2~beta1".
+var releasePattern = regexp.MustCompile(`^(\d+)\.(\d+)(?:\.(\d+))?`)
+
+// maasRelease returns the major, minor and patch numbers of the MAAS
+// version, or the empty string if it isn't recognised. A missing patch
+// number is treated as zero.
+func maasRelease(v string) string {
+	match := releasePattern.FindStringSubmatch(v)
+	if match == nil {
+		return ""
+	}
+	patch := match[3]
+	if patch == "" {
+		patch = "0"
+	}
+	return match[1] + "." + match[2] + "." + patch
+}
+
+// VersionSkew implements Controller.
+func (c *controller) VersionSkew() (VersionSkew, error) {
+	regionVersion, _, err := c.APIVersionInfo()
+	if err != nil {
+		return VersionSkew{}, errors.Annotate(err, "reading region version")
+	}
+	rackControllers, err := c.rackControllers(RackControllersArgs{})
+	if err != nil {
+		return VersionSkew{}, errors.Trace(err)
+	}
+	release := maasRelease(regionVersion)
+	racks := make([]RackVersion, len(rackControllers))
+	for i, rack := range rackControllers {
+		racks[i] = RackVersion{
+			SystemID: rack.systemID,
+			Hostname: rack.hostname,
+			Version:  rack.version,
+			Matches:  release != "" && maasRelease(rack.version) == release,
+		}
+	}
+	return VersionSkew{
+		RegionVersion: regionVersion,
+		Racks:         racks,
+	}, nil
+}
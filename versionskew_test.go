@@ -0,0 +1,102 @@
+// Copyright 2022 Canonical Ltd.
+// Licensed under the LGPLv3, see LICENCE file for details.
+
+package gomaasapi
+
+import (
+	"net/http"
+
+	"github.com/juju/testing"
+	jc "github.com/juju/testing/checkers"
+	gc "gopkg.in/check.v1"
+)
+
+type versionSkewSuite struct {
+	testing.LoggingCleanupSuite
+}
+
+var _ = gc.Suite(&versionSkewSuite{})
+
+func (*versionSkewSuite) TestMAASRelease(c *gc.C) {
+	for i, test := range []struct {
+		version  string
+		expected string
+	}{
+		{"2.5.0 from source", "2.5.0"},
+		{"3.2.6-12016-g.19812b4da", "3.2.6"},
+		{"3.3~beta2", "3.3.0"},
+		{"3.3.0~beta2-13046-g.5a6b5b5e5", "3.3.0"},
+		{"", ""},
+		{"unknown", ""},
+	} {
+		c.Logf("test %d: %q", i, test.version)
+		c.Check(maasRelease(test.version), gc.Equals, test.expected)
+	}
+}
+
+func (s *versionSkewSuite) TestVersionSkew(c *gc.C) {
+	server, controller := createTestServerController(c, s)
+	server.AddGetResponse("/api/2.0/version/", http.StatusOK, versionResponse)
+	server.AddGetResponse("/api/2.0/rackcontrollers/", http.StatusOK, rackVersionsResponse)
+
+	skew, err := controller.VersionSkew()
+	c.Assert(err, jc.ErrorIsNil)
+	c.Check(skew.RegionVersion, gc.Equals, "2.5.0 from source")
+	c.Check(skew.Consistent(), jc.IsFalse)
+	c.Assert(skew.Racks, gc.HasLen, 3)
+	c.Check(skew.Racks[0], jc.DeepEquals, RackVersion{
+		SystemID: "4y3h7n",
+		Hostname: "rack-1",
+		Version:  "2.5.0-7442-gdf67a1b54-0ubuntu1",
+		Matches:  true,
+	})
+	mismatched := skew.Mismatched()
+	c.Assert(mismatched, gc.HasLen, 2)
+	c.Check(mismatched[0].Hostname, gc.Equals, "rack-2")
+	c.Check(mismatched[1].Hostname, gc.Equals, "rack-3")
+}
+
+func (s *versionSkewSuite) TestVersionSkewConsistent(c *gc.C) {
+	server, controller := createTestServerController(c, s)
+	server.AddGetResponse("/api/2.0/version/", http.StatusOK, versionResponse)
+	server.AddGetResponse("/api/2.0/rackcontrollers/", http.StatusOK, `[
+		{"system_id": "4y3h7n", "hostname": "rack-1", "version": "2.5.0-7442-gdf67a1b54-0ubuntu1", "resource_uri": "/MAAS/api/2.0/rackcontrollers/4y3h7n/"}
+	]`)
+
+	skew, err := controller.VersionSkew()
+	c.Assert(err, jc.ErrorIsNil)
+	c.Check(skew.Consistent(), jc.IsTrue)
+	c.Check(skew.Mismatched(), gc.HasLen, 0)
+}
+
+func (s *versionSkewSuite) TestVersionSkewForbidden(c *gc.C) {
+	server, controller := createTestServerController(c, s)
+	server.AddGetResponse("/api/2.0/version/", http.StatusOK, versionResponse)
+	server.AddGetResponse("/api/2.0/rackcontrollers/", http.StatusForbidden, "admins only")
+
+	_, err := controller.VersionSkew()
+	c.Assert(err, jc.Satisfies, IsUnexpectedError)
+}
+
+const rackVersionsResponse = `
+[
+    {
+        "system_id": "4y3h7n",
+        "hostname": "rack-1",
+        "version": "2.5.0-7442-gdf67a1b54-0ubuntu1",
+        "resource_uri": "/MAAS/api/2.0/rackcontrollers/4y3h7n/"
+    },
+    {
+        "system_id": "4y3h7p",
+        "hostname": "rack-2",
+        "version": "2.4.2-7034-g2f5deb8b8-0ubuntu1",
+        "resource_uri": "/MAAS/api/2.0/rackcontrollers/4y3h7p/"
+    },
+    {
+        "system_id": "4y3h7q",
+        "hostname": "rack-3",
+        "version": null,
+        "resource_uri": "/MAAS/api/2.0/rackcontrollers/4y3h7q/"
+    }
+]
+`
@@ -4,14 +4,16 @@
 package gomaasapi
 
 import (
+	"fmt"
+	"net/http"
+
 	"github.com/juju/errors"
 	"github.com/juju/schema"
 	"github.com/juju/version"
 )
 
 type vlan struct {
-	// Add the controller in when we need to do things with the vlan.
-	// controller Controller
+	controller *controller
 
 	resourceURI string
 
@@ -25,6 +27,28 @@ type vlan struct {
 
 	primaryRack   string
 	secondaryRack string
+
+	externalDHCP string
+	relayVLAN    *vlan
+
+	description string
+	space       string
+}
+
+func (v *vlan) updateFrom(other *vlan) {
+	v.resourceURI = other.resourceURI
+	v.id = other.id
+	v.name = other.name
+	v.fabric = other.fabric
+	v.vid = other.vid
+	v.mtu = other.mtu
+	v.dhcp = other.dhcp
+	v.primaryRack = other.primaryRack
+	v.secondaryRack = other.secondaryRack
+	v.externalDHCP = other.externalDHCP
+	v.relayVLAN = other.relayVLAN
+	v.description = other.description
+	v.space = other.space
 }
 
 // ID implements VLAN.
@@ -67,6 +91,204 @@ func (v *vlan) SecondaryRack() string {
 	return v.secondaryRack
 }
 
+// ExternalDHCP implements VLAN.
+func (v *vlan) ExternalDHCP() string {
+	return v.externalDHCP
+}
+
+// RelayVLAN implements VLAN.
+func (v *vlan) RelayVLAN() VLAN {
+	if v.relayVLAN == nil {
+		return nil
+	}
+	return v.relayVLAN
+}
+
+// Description implements VLAN.
+func (v *vlan) Description() string {
+	return v.description
+}
+
+// Space implements VLAN.
+func (v *vlan) Space() string {
+	return v.space
+}
+
+// UpdateVLANArgs is an argument struct for VLAN.Update. Only the fields
+// that are set are changed.
+type UpdateVLANArgs struct {
+	Name        string
+	VID         int
+	MTU         int
+	Description string
+	// Space is the name of the space to put the VLAN in.
+	Space string
+	// ClearSpace takes the VLAN out of its space.
+	ClearSpace bool
+	// DHCPOn, if not nil, enables or disables DHCP served by MAAS. Enabling
+	// DHCP requires a primary rack, which is the rack controller that serves
+	// it, and a managed subnet with a dynamic IP range on the VLAN.
+	DHCPOn *bool
+	// PrimaryRack and SecondaryRack are the system IDs of the rack
+	// controllers that serve DHCP on the VLAN.
+	PrimaryRack   string
+	SecondaryRack string
+	// RelayVLAN, if set, relays DHCP requests on the VLAN to the MAAS DHCP
+	// server of the relay VLAN. MAAS can't serve DHCP on a VLAN that relays
+	// it.
+	RelayVLAN VLAN
+	// ClearRelayVLAN stops relaying DHCP requests.
+	ClearRelayVLAN bool
+}
+
+// Validate ensures that the DHCP settings are consistent.
+func (a *UpdateVLANArgs) Validate() error {
+	if a.Space != "" && a.ClearSpace {
+		return errors.NotValidf("both Space and ClearSpace")
+	}
+	if a.RelayVLAN != nil && a.ClearRelayVLAN {
+		return errors.NotValidf("both RelayVLAN and ClearRelayVLAN")
+	}
+	if a.RelayVLAN != nil && a.DHCPOn != nil && *a.DHCPOn {
+		return errors.NotValidf("enabling DHCP with a RelayVLAN")
+	}
+	return nil
+}
+
+// Update implements VLAN.
+func (v *vlan) Update(args UpdateVLANArgs) error {
+	if err := args.Validate(); err != nil {
+		return errors.Trace(err)
+	}
+	if args.DHCPOn != nil && *args.DHCPOn && args.PrimaryRack == "" && v.primaryRack == "" {
+		return errors.NotValidf("enabling DHCP without a PrimaryRack")
+	}
+	if args.RelayVLAN != nil && args.RelayVLAN.ID() == v.id {
+		return errors.NotValidf("relaying DHCP to the same VLAN")
+	}
+	params := NewURLParams()
+	params.MaybeAdd("name", args.Name)
+	params.MaybeAddInt("vid", args.VID)
+	params.MaybeAddInt("mtu", args.MTU)
+	params.MaybeAdd("description", args.Description)
+	params.MaybeAdd("space", args.Space)
+	if args.ClearSpace {
+		// MAAS takes VLANs out of their space when it is set to nothing.
+		params.Values.Add("space", "")
+	}
+	if args.DHCPOn != nil {
+		params.Values.Add("dhcp_on", fmt.Sprint(*args.DHCPOn))
+	}
+	params.MaybeAdd("primary_rack", args.PrimaryRack)
+	params.MaybeAdd("secondary_rack", args.SecondaryRack)
+	if args.RelayVLAN != nil {
+		params.MaybeAddInt("relay_vlan", args.RelayVLAN.ID())
+	} else if args.ClearRelayVLAN {
+		// MAAS clears the relay VLAN when it is set to nothing.
+		params.Values.Add("relay_vlan", "")
+	}
+	source, err := v.controller.put(v.resourceURI, params.Values)
+	v.controller.lookups.invalidate(lookupVLANsByID)
+	if err != nil {
+		if svrErr, ok := errors.Cause(err).(ServerError); ok {
+			switch svrErr.StatusCode {
+			case http.StatusNotFound:
+				return wrapError(err, NewNoMatchError(svrErr.BodyMessage))
+			case http.StatusForbidden:
+				return wrapError(err, NewPermissionError(svrErr.BodyMessage))
+			case http.StatusBadRequest:
+				return wrapError(err, NewBadRequestError(svrErr.BodyMessage))
+			}
+		}
+		return NewUnexpectedError(err)
+	}
+
+	response, err := readVLAN(v.controller.schemaVersion(), source)
+	if err != nil {
+		return errors.Trace(err)
+	}
+	v.updateFrom(response)
+	return nil
+}
+
+// Delete implements VLAN.
+func (v *vlan) Delete() error {
+	err := v.controller.delete(v.resourceURI)
+	v.controller.lookups.invalidate(lookupVLANsByID)
+	if err != nil {
+		if svrErr, ok := errors.Cause(err).(ServerError); ok {
+			switch svrErr.StatusCode {
+			case http.StatusNotFound:
+				return wrapError(err, NewNoMatchError(svrErr.BodyMessage))
+			case http.StatusForbidden:
+				return wrapError(err, NewPermissionError(svrErr.BodyMessage))
+			case http.StatusBadRequest, http.StatusConflict:
+				return wrapError(err, NewBadRequestError(svrErr.BodyMessage))
+			}
+		}
+		return NewUnexpectedError(err)
+	}
+	return nil
+}
+
+// DHCPProvider describes what provides DHCP on a VLAN.
+type DHCPProvider string
+
+const (
+	// DHCPProviderUnknown is used when the VLAN is not known.
+	DHCPProviderUnknown DHCPProvider = ""
+
+	// DHCPProviderMAAS - DHCP is served by the rack controllers of the VLAN.
+	DHCPProviderMAAS DHCPProvider = "maas"
+
+	// DHCPProviderRelay - DHCP requests are relayed to MAAS DHCP running on
+	// another VLAN.
+	DHCPProviderRelay DHCPProvider = "relay"
+
+	// DHCPProviderExternal - DHCP is not managed by MAAS, but an external DHCP
+	// server has been observed on the VLAN.
+	DHCPProviderExternal DHCPProvider = "external"
+
+	// DHCPProviderNone - there is no known DHCP server on the VLAN.
+	DHCPProviderNone DHCPProvider = "none"
+)
+
+// Managed returns true if the DHCP is managed by MAAS, either directly or
+// through a relay. Machines need managed DHCP to netboot.
+func (p DHCPProvider) Managed() bool {
+	return p == DHCPProviderMAAS || p == DHCPProviderRelay
+}
+
+// VLANDHCPProvider returns what provides DHCP on the specified VLAN.
+func VLANDHCPProvider(vlan VLAN) DHCPProvider {
+	switch {
+	case vlan == nil:
+		return DHCPProviderUnknown
+	case vlan.DHCP():
+		return DHCPProviderMAAS
+	case vlan.RelayVLAN() != nil:
+		return DHCPProviderRelay
+	case vlan.ExternalDHCP() != "":
+		return DHCPProviderExternal
+	}
+	return DHCPProviderNone
+}
+
+func readVLAN(controllerVersion version.Number, source interface{}) (*vlan, error) {
+	readFunc, err := getVLANDeserializationFunc(controllerVersion)
+	if err != nil {
+		return nil, errors.Trace(err)
+	}
+
+	checker := schema.StringMap(schema.Any())
+	coerced, err := checker.Coerce(source, nil)
+	if err != nil {
+		return nil, errors.Annotatef(err, "vlan base schema check failed")
+	}
+	valid := coerced.(map[string]interface{})
+	return readFunc(valid)
+}
+
 func readVLANs(controllerVersion version.Number, source interface{}) ([]*vlan, error) {
 	checker := schema.List(schema.StringMap(schema.Any()))
 	coerced, err := checker.Coerce(source, nil)
@@ -75,6 +297,14 @@ func readVLANs(controllerVersion version.Number, source interface{}) ([]*vlan, e
 	}
 	valid := coerced.([]interface{})
 
+	readFunc, err := getVLANDeserializationFunc(controllerVersion)
+	if err != nil {
+		return nil, errors.Trace(err)
+	}
+	return readVLANList(valid, readFunc)
+}
+
+func getVLANDeserializationFunc(controllerVersion version.Number) (vlanDeserializationFunc, error) {
 	var deserialisationVersion version.Number
 	for v := range vlanDeserializationFuncs {
 		if v.Compare(deserialisationVersion) > 0 && v.Compare(controllerVersion) <= 0 {
@@ -84,8 +314,7 @@ func readVLANs(controllerVersion version.Number, source interface{}) ([]*vlan, e
 	if deserialisationVersion == version.Zero {
 		return nil, errors.Errorf("no vlan read func for version %s", controllerVersion)
 	}
-	readFunc := vlanDeserializationFuncs[deserialisationVersion]
-	return readVLANList(valid, readFunc)
+	return vlanDeserializationFuncs[deserialisationVersion], nil
 }
 
 func readVLANList(sourceList []interface{}, readFunc vlanDeserializationFunc) ([]*vlan, error) {
@@ -122,8 +351,21 @@ func vlan_2_0(source map[string]interface{}) (*vlan, error) {
 		// racks are not always set.
 		"primary_rack":   schema.OneOf(schema.Nil(""), schema.String()),
 		"secondary_rack": schema.OneOf(schema.Nil(""), schema.String()),
+
+		"external_dhcp": schema.OneOf(schema.Nil(""), schema.String()),
+		// The relay VLAN may be returned as an ID or as a VLAN.
+		"relay_vlan": schema.OneOf(schema.Nil(""), schema.ForceInt(), schema.StringMap(schema.Any())),
+
+		"description": schema.OneOf(schema.Nil(""), schema.String()),
+		"space":       schema.OneOf(schema.Nil(""), schema.String()),
+	}
+	defaults := schema.Defaults{
+		"external_dhcp": nil,
+		"relay_vlan":    nil,
+		"description":   nil,
+		"space":         nil,
 	}
-	checker := schema.FieldMap(fields, nil)
+	checker := schema.FieldMap(fields, defaults)
 	coerced, err := checker.Coerce(source, nil)
 	if err != nil {
 		return nil, errors.Annotatef(err, "vlan 2.0 schema check failed")
@@ -138,6 +380,23 @@ func vlan_2_0(source map[string]interface{}) (*vlan, error) {
 	primary_rack, _ := valid["primary_rack"].(string)
 	secondary_rack, _ := valid["secondary_rack"].(string)
 	name, _ := valid["name"].(string)
+	externalDHCP, _ := valid["external_dhcp"].(string)
+	description, _ := valid["description"].(string)
+	space, _ := valid["space"].(string)
+	if space == "undefined" {
+		// MAAS reports VLANs that aren't in a space this way.
+		space = ""
+	}
+
+	var relayVLAN *vlan
+	switch relay := valid["relay_vlan"].(type) {
+	case int:
+		relayVLAN = &vlan{id: relay}
+	case map[string]interface{}:
+		if relayVLAN, err = vlan_2_0(relay); err != nil {
+			return nil, errors.Annotatef(err, "relay vlan")
+		}
+	}
 
 	result := &vlan{
 		resourceURI:   valid["resource_uri"].(string),
@@ -149,6 +408,10 @@ func vlan_2_0(source map[string]interface{}) (*vlan, error) {
 		dhcp:          valid["dhcp_on"].(bool),
 		primaryRack:   primary_rack,
 		secondaryRack: secondary_rack,
+		externalDHCP:  externalDHCP,
+		relayVLAN:     relayVLAN,
+		description:   description,
+		space:         space,
 	}
 	return result, nil
 }
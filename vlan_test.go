@@ -4,12 +4,18 @@
 package gomaasapi
 
 import (
+	"net/http"
+
+	"github.com/juju/errors"
+	"github.com/juju/testing"
 	jc "github.com/juju/testing/checkers"
 	"github.com/juju/version"
 	gc "gopkg.in/check.v1"
 )
 
-type vlanSuite struct{}
+type vlanSuite struct {
+	testing.LoggingCleanupSuite
+}
 
 var _ = gc.Suite(&vlanSuite{})
 
@@ -63,6 +69,255 @@ func (s *vlanSuite) TestReadVLANsWithoutName(c *gc.C) {
 	})
 }
 
+func (s *vlanSuite) TestReadVLANsDHCP(c *gc.C) {
+	vlans, err := readVLANs(twoDotOh, parseJSON(c, vlanResponseDHCP))
+	c.Assert(err, jc.ErrorIsNil)
+	c.Assert(vlans, gc.HasLen, 3)
+
+	c.Check(vlans[0].ExternalDHCP(), gc.Equals, "192.168.0.1")
+	c.Check(vlans[0].RelayVLAN(), gc.IsNil)
+
+	relay := vlans[1].RelayVLAN()
+	c.Assert(relay, gc.NotNil)
+	c.Check(relay.ID(), gc.Equals, 1)
+
+	relay = vlans[2].RelayVLAN()
+	c.Assert(relay, gc.NotNil)
+	c.Check(relay.ID(), gc.Equals, 1)
+	c.Check(relay.Name(), gc.Equals, "untagged")
+	c.Check(relay.DHCP(), jc.IsTrue)
+}
+
+func (*vlanSuite) TestVLANDHCPProvider(c *gc.C) {
+	for i, test := range []struct {
+		vlan     VLAN
+		expected DHCPProvider
+		managed  bool
+	}{{
+		expected: DHCPProviderUnknown,
+	}, {
+		vlan:     &vlan{dhcp: true},
+		expected: DHCPProviderMAAS,
+		managed:  true,
+	}, {
+		vlan:     &vlan{relayVLAN: &vlan{id: 1}},
+		expected: DHCPProviderRelay,
+		managed:  true,
+	}, {
+		vlan:     &vlan{externalDHCP: "192.168.0.1"},
+		expected: DHCPProviderExternal,
+	}, {
+		vlan:     &vlan{},
+		expected: DHCPProviderNone,
+	}} {
+		c.Logf("test %d", i)
+		provider := VLANDHCPProvider(test.vlan)
+		c.Check(provider, gc.Equals, test.expected)
+		c.Check(provider.Managed(), gc.Equals, test.managed)
+	}
+}
+
+func (*vlanSuite) TestReadVLANDescriptionAndSpace(c *gc.C) {
+	v, err := readVLAN(twoDotOh, parseJSON(c, vlanItemResponse))
+	c.Assert(err, jc.ErrorIsNil)
+	c.Check(v.Description(), gc.Equals, "storage network")
+	c.Check(v.Space(), gc.Equals, "storage")
+
+	vlans, err := readVLANs(twoDotOh, parseJSON(c, vlanResponseWithName))
+	c.Assert(err, jc.ErrorIsNil)
+	c.Check(vlans[0].Description(), gc.Equals, "")
+	c.Check(vlans[0].Space(), gc.Equals, "")
+}
+
+func (*vlanSuite) TestReadVLANUndefinedSpace(c *gc.C) {
+	v, err := readVLAN(twoDotOh, parseJSON(c, updateJSONMap(c, vlanItemResponse, map[string]interface{}{
+		"space": "undefined",
+	})))
+	c.Assert(err, jc.ErrorIsNil)
+	c.Check(v.Space(), gc.Equals, "")
+}
+
+func (s *vlanSuite) getServerAndVLAN(c *gc.C) (*SimpleTestServer, *vlan) {
+	server, controller := createTestServerController(c, s)
+	server.AddGetResponse("/api/2.0/fabrics/", http.StatusOK, fabricResponse)
+	fabrics, err := controller.Fabrics()
+	c.Assert(err, jc.ErrorIsNil)
+	// The untagged VLAN of fabric-1 has no rack controllers.
+	v := fabrics[1].VLANs()[0].(*vlan)
+	server.ResetRequests()
+	return server, v
+}
+
+func (s *vlanSuite) TestUpdateEnableDHCP(c *gc.C) {
+	server, v := s.getServerAndVLAN(c)
+	response := updateJSONMap(c, vlanItemResponse, map[string]interface{}{
+		"id":             5001,
+		"dhcp_on":        true,
+		"primary_rack":   "4y3h7n",
+		"secondary_rack": "4y3h7p",
+	})
+	server.AddPutResponse(v.resourceURI, http.StatusOK, response)
+	dhcpOn := true
+	err := v.Update(UpdateVLANArgs{
+		DHCPOn:        &dhcpOn,
+		PrimaryRack:   "4y3h7n",
+		SecondaryRack: "4y3h7p",
+	})
+	c.Assert(err, jc.ErrorIsNil)
+	c.Check(v.DHCP(), jc.IsTrue)
+	c.Check(v.PrimaryRack(), gc.Equals, "4y3h7n")
+	c.Check(v.SecondaryRack(), gc.Equals, "4y3h7p")
+
+	form := server.LastRequest().PostForm
+	c.Check(form, gc.HasLen, 3)
+	c.Check(form.Get("dhcp_on"), gc.Equals, "true")
+	c.Check(form.Get("primary_rack"), gc.Equals, "4y3h7n")
+	c.Check(form.Get("secondary_rack"), gc.Equals, "4y3h7p")
+}
+
+func (s *vlanSuite) TestUpdateEnableDHCPWithoutRack(c *gc.C) {
+	server, v := s.getServerAndVLAN(c)
+	dhcpOn := true
+	err := v.Update(UpdateVLANArgs{DHCPOn: &dhcpOn})
+	c.Assert(err, jc.Satisfies, errors.IsNotValid)
+	c.Check(err.Error(), gc.Equals, "enabling DHCP without a PrimaryRack not valid")
+	c.Check(server.RequestCount(), gc.Equals, 0)
+}
+
+func (s *vlanSuite) TestUpdate(c *gc.C) {
+	server, v := s.getServerAndVLAN(c)
+	server.AddPutResponse(v.resourceURI, http.StatusOK, vlanItemResponse)
+	dhcpOn := false
+	err := v.Update(UpdateVLANArgs{
+		Name:        "storage",
+		VID:         100,
+		MTU:         9000,
+		Description: "storage network",
+		Space:       "storage",
+		DHCPOn:      &dhcpOn,
+	})
+	c.Assert(err, jc.ErrorIsNil)
+	c.Check(v.Name(), gc.Equals, "storage")
+	c.Check(v.MTU(), gc.Equals, 9000)
+	c.Check(v.Space(), gc.Equals, "storage")
+
+	form := server.LastRequest().PostForm
+	c.Check(form, gc.HasLen, 6)
+	c.Check(form.Get("vid"), gc.Equals, "100")
+	c.Check(form.Get("mtu"), gc.Equals, "9000")
+	c.Check(form.Get("dhcp_on"), gc.Equals, "false")
+}
+
+func (s *vlanSuite) TestUpdateBadRequest(c *gc.C) {
+	server, v := s.getServerAndVLAN(c)
+	server.AddPutResponse(v.resourceURI, http.StatusBadRequest, "dhcp can only be turned on when a dynamic IP range is defined")
+	dhcpOn := true
+	err := v.Update(UpdateVLANArgs{DHCPOn: &dhcpOn, PrimaryRack: "4y3h7n"})
+	c.Assert(err, jc.Satisfies, IsBadRequestError)
+	c.Check(err.Error(), gc.Equals, "dhcp can only be turned on when a dynamic IP range is defined")
+}
+
+func (s *vlanSuite) TestUpdateSetRelayVLAN(c *gc.C) {
+	server, v := s.getServerAndVLAN(c)
+	response := updateJSONMap(c, vlanItemResponse, map[string]interface{}{
+		"relay_vlan": 1,
+	})
+	server.AddPutResponse(v.resourceURI, http.StatusOK, response)
+	err := v.Update(UpdateVLANArgs{RelayVLAN: &fakeVLAN{id: 1}})
+	c.Assert(err, jc.ErrorIsNil)
+	c.Assert(v.RelayVLAN(), gc.NotNil)
+	c.Check(v.RelayVLAN().ID(), gc.Equals, 1)
+	c.Check(VLANDHCPProvider(v), gc.Equals, DHCPProviderRelay)
+
+	form := server.LastRequest().PostForm
+	c.Check(form, gc.HasLen, 1)
+	c.Check(form.Get("relay_vlan"), gc.Equals, "1")
+}
+
+func (s *vlanSuite) TestUpdateClearRelayVLAN(c *gc.C) {
+	server, v := s.getServerAndVLAN(c)
+	v.relayVLAN = &vlan{id: 1}
+	server.AddPutResponse(v.resourceURI, http.StatusOK, vlanItemResponse)
+	err := v.Update(UpdateVLANArgs{ClearRelayVLAN: true})
+	c.Assert(err, jc.ErrorIsNil)
+	c.Check(v.RelayVLAN(), gc.IsNil)
+
+	form := server.LastRequest().PostForm
+	c.Check(form, gc.HasLen, 1)
+	values, found := form["relay_vlan"]
+	c.Check(found, jc.IsTrue)
+	c.Check(values, jc.DeepEquals, []string{""})
+}
+
+func (s *vlanSuite) TestUpdateClearSpace(c *gc.C) {
+	server, v := s.getServerAndVLAN(c)
+	server.AddPutResponse(v.resourceURI, http.StatusOK, updateJSONMap(c, vlanItemResponse, map[string]interface{}{
+		"space": "undefined",
+	}))
+	err := v.Update(UpdateVLANArgs{ClearSpace: true})
+	c.Assert(err, jc.ErrorIsNil)
+	c.Check(v.Space(), gc.Equals, "")
+
+	form := server.LastRequest().PostForm
+	c.Check(form, gc.HasLen, 1)
+	c.Check(form["space"], jc.DeepEquals, []string{""})
+}
+
+func (s *vlanSuite) TestUpdateRelayToSelf(c *gc.C) {
+	server, v := s.getServerAndVLAN(c)
+	err := v.Update(UpdateVLANArgs{RelayVLAN: v})
+	c.Assert(err, jc.Satisfies, errors.IsNotValid)
+	c.Check(err.Error(), gc.Equals, "relaying DHCP to the same VLAN not valid")
+	c.Check(server.RequestCount(), gc.Equals, 0)
+}
+
+func (*vlanSuite) TestUpdateVLANArgsValidate(c *gc.C) {
+	on := true
+	off := false
+	for i, test := range []struct {
+		args    UpdateVLANArgs
+		errText string
+	}{{
+		args: UpdateVLANArgs{RelayVLAN: &fakeVLAN{id: 1}},
+	}, {
+		args: UpdateVLANArgs{RelayVLAN: &fakeVLAN{id: 1}, DHCPOn: &off},
+	}, {
+		args: UpdateVLANArgs{ClearRelayVLAN: true, DHCPOn: &on},
+	}, {
+		args:    UpdateVLANArgs{Space: "storage", ClearSpace: true},
+		errText: "both Space and ClearSpace not valid",
+	}, {
+		args:    UpdateVLANArgs{RelayVLAN: &fakeVLAN{id: 1}, ClearRelayVLAN: true},
+		errText: "both RelayVLAN and ClearRelayVLAN not valid",
+	}, {
+		args:    UpdateVLANArgs{RelayVLAN: &fakeVLAN{id: 1}, DHCPOn: &on},
+		errText: "enabling DHCP with a RelayVLAN not valid",
+	}} {
+		c.Logf("test %d", i)
+		err := test.args.Validate()
+		if test.errText == "" {
+			c.Check(err, jc.ErrorIsNil)
+		} else {
+			c.Check(err, jc.Satisfies, errors.IsNotValid)
+			c.Check(err.Error(), gc.Equals, test.errText)
+		}
+	}
+}
+
+func (s *vlanSuite) TestDelete(c *gc.C) {
+	server, v := s.getServerAndVLAN(c)
+	server.AddDeleteResponse(v.resourceURI, http.StatusNoContent, "")
+	err := v.Delete()
+	c.Assert(err, jc.ErrorIsNil)
+}
+
+func (s *vlanSuite) TestDeleteForbidden(c *gc.C) {
+	server, v := s.getServerAndVLAN(c)
+	server.AddDeleteResponse(v.resourceURI, http.StatusForbidden, "")
+	err := v.Delete()
+	c.Assert(err, jc.Satisfies, IsPermissionError)
+}
+
 func (*vlanSuite) TestLowVersion(c *gc.C) {
 	_, err := readVLANs(version.MustParse("1.9.0"), parseJSON(c, vlanResponseWithName))
 	c.Assert(err.Error(), gc.Equals, `no vlan read func for version 1.9.0`)
@@ -75,6 +330,21 @@ func (*vlanSuite) TestHighVersion(c *gc.C) {
 }
 
 const (
+	vlanItemResponse = `
+{
+    "name": "storage",
+    "vid": 100,
+    "primary_rack": null,
+    "resource_uri": "/MAAS/api/2.0/vlans/5010/",
+    "id": 5010,
+    "secondary_rack": null,
+    "fabric": "fabric-1",
+    "mtu": 9000,
+    "dhcp_on": false,
+    "description": "storage network",
+    "space": "storage"
+}
+`
 	vlanResponseWithName = `
 [
     {
@@ -105,5 +375,58 @@ const (
         "secondary_rack": null
     }
 ]
+`
+	vlanResponseDHCP = `
+[
+    {
+        "dhcp_on": false,
+        "id": 2,
+        "mtu": 1500,
+        "fabric": "fabric-0",
+        "vid": 10,
+        "primary_rack": null,
+        "name": null,
+        "external_dhcp": "192.168.0.1",
+        "relay_vlan": null,
+        "resource_uri": "/MAAS/api/2.0/vlans/2/",
+        "secondary_rack": null
+    },
+    {
+        "dhcp_on": false,
+        "id": 3,
+        "mtu": 1500,
+        "fabric": "fabric-0",
+        "vid": 20,
+        "primary_rack": null,
+        "name": null,
+        "external_dhcp": null,
+        "relay_vlan": 1,
+        "resource_uri": "/MAAS/api/2.0/vlans/3/",
+        "secondary_rack": null
+    },
+    {
+        "dhcp_on": false,
+        "id": 4,
+        "mtu": 1500,
+        "fabric": "fabric-0",
+        "vid": 30,
+        "primary_rack": null,
+        "name": null,
+        "external_dhcp": null,
+        "relay_vlan": {
+            "dhcp_on": true,
+            "id": 1,
+            "mtu": 1500,
+            "fabric": "fabric-0",
+            "vid": 0,
+            "primary_rack": "4y3h7n",
+            "name": "untagged",
+            "resource_uri": "/MAAS/api/2.0/vlans/1/",
+            "secondary_rack": null
+        },
+        "resource_uri": "/MAAS/api/2.0/vlans/4/",
+        "secondary_rack": null
+    }
+]
 `
 )
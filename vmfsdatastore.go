@@ -0,0 +1,242 @@
+// Copyright 2022 Canonical Ltd.
+// Licensed under the LGPLv3, see LICENCE file for details.
+
+package gomaasapi
+
+import (
+	"github.com/juju/errors"
+	"github.com/juju/schema"
+	"github.com/juju/version"
+)
+
+type vmfsDatastore struct {
+	controller *controller
+
+	resourceURI string
+
+	id   int
+	name string
+	uuid string
+	size uint64
+
+	fstype     string
+	mountPoint string
+
+	devices []StorageDevice
+}
+
+func (v *vmfsDatastore) updateFrom(other *vmfsDatastore) {
+	v.resourceURI = other.resourceURI
+	v.id = other.id
+	v.name = other.name
+	v.uuid = other.uuid
+	v.size = other.size
+	v.fstype = other.fstype
+	v.mountPoint = other.mountPoint
+	v.devices = other.devices
+}
+
+// ID implements VMFSDatastore.
+func (v *vmfsDatastore) ID() int {
+	return v.id
+}
+
+// Name implements VMFSDatastore.
+func (v *vmfsDatastore) Name() string {
+	return v.name
+}
+
+// UUID implements VMFSDatastore.
+func (v *vmfsDatastore) UUID() string {
+	return v.uuid
+}
+
+// Size implements VMFSDatastore.
+func (v *vmfsDatastore) Size() uint64 {
+	return v.size
+}
+
+// FSType implements VMFSDatastore.
+func (v *vmfsDatastore) FSType() string {
+	return v.fstype
+}
+
+// MountPoint implements VMFSDatastore.
+func (v *vmfsDatastore) MountPoint() string {
+	return v.mountPoint
+}
+
+// Devices implements VMFSDatastore.
+func (v *vmfsDatastore) Devices() []StorageDevice {
+	setStorageDeviceController(v.devices, v.controller)
+	return v.devices
+}
+
+// UpdateVMFSDatastoreArgs is an argument struct for VMFSDatastore.Update.
+// Only the fields that are set are changed. MAAS only supports growing a
+// datastore, so devices can be added but not removed.
+type UpdateVMFSDatastoreArgs struct {
+	Name string
+	UUID string
+
+	AddDevices    []BlockDevice
+	AddPartitions []Partition
+}
+
+// Update implements VMFSDatastore.
+func (v *vmfsDatastore) Update(args UpdateVMFSDatastoreArgs) error {
+	params := NewURLParams()
+	params.MaybeAdd("name", args.Name)
+	params.MaybeAdd("uuid", args.UUID)
+	params.MaybeAddMany("add_block_devices", blockDeviceIDs(args.AddDevices))
+	params.MaybeAddMany("add_partitions", partitionIDs(args.AddPartitions))
+	source, err := v.controller.put(v.resourceURI, params.Values)
+	if err != nil {
+		return errors.Trace(storageDeviceError(err))
+	}
+
+	response, err := readVMFSDatastore(v.controller.schemaVersion(), source)
+	if err != nil {
+		return errors.Trace(err)
+	}
+	v.updateFrom(response)
+	return nil
+}
+
+// Delete implements VMFSDatastore.
+func (v *vmfsDatastore) Delete() error {
+	err := v.controller.delete(v.resourceURI)
+	if err != nil {
+		return errors.Trace(storageDeviceError(err))
+	}
+	return nil
+}
+
+func readVMFSDatastore(controllerVersion version.Number, source interface{}) (*vmfsDatastore, error) {
+	readFunc, err := getVMFSDatastoreDeserializationFunc(controllerVersion)
+	if err != nil {
+		return nil, errors.Trace(err)
+	}
+
+	checker := schema.StringMap(schema.Any())
+	coerced, err := checker.Coerce(source, nil)
+	if err != nil {
+		return nil, WrapWithDeserializationError(err, "vmfs datastore base schema check failed")
+	}
+	valid := coerced.(map[string]interface{})
+	return readFunc(valid)
+}
+
+func readVMFSDatastores(controllerVersion version.Number, source interface{}) ([]*vmfsDatastore, error) {
+	readFunc, err := getVMFSDatastoreDeserializationFunc(controllerVersion)
+	if err != nil {
+		return nil, errors.Trace(err)
+	}
+
+	checker := schema.List(schema.StringMap(schema.Any()))
+	coerced, err := checker.Coerce(source, nil)
+	if err != nil {
+		return nil, WrapWithDeserializationError(err, "vmfs datastore base schema check failed")
+	}
+	valid := coerced.([]interface{})
+	return readVMFSDatastoreList(valid, readFunc)
+}
+
+func getVMFSDatastoreDeserializationFunc(controllerVersion version.Number) (vmfsDatastoreDeserializationFunc, error) {
+	var deserialisationVersion version.Number
+	for v := range vmfsDatastoreDeserializationFuncs {
+		if v.Compare(deserialisationVersion) > 0 && v.Compare(controllerVersion) <= 0 {
+			deserialisationVersion = v
+		}
+	}
+	if deserialisationVersion == version.Zero {
+		return nil, NewUnsupportedVersionError("no vmfs datastore read func for version %s", controllerVersion)
+	}
+	return vmfsDatastoreDeserializationFuncs[deserialisationVersion], nil
+}
+
+// readVMFSDatastoreList expects the values of the sourceList to be string maps.
+func readVMFSDatastoreList(sourceList []interface{}, readFunc vmfsDatastoreDeserializationFunc) ([]*vmfsDatastore, error) {
+	result := make([]*vmfsDatastore, 0, len(sourceList))
+	for i, value := range sourceList {
+		source, ok := value.(map[string]interface{})
+		if !ok {
+			return nil, NewDeserializationError("unexpected value for vmfs datastore %d, %T", i, value)
+		}
+		datastore, err := readFunc(source)
+		if err != nil {
+			return nil, errors.Annotatef(err, "vmfs datastore %d", i)
+		}
+		result = append(result, datastore)
+	}
+	return result, nil
+}
+
+type vmfsDatastoreDeserializationFunc func(map[string]interface{}) (*vmfsDatastore, error)
+
+var vmfsDatastoreDeserializationFuncs = map[version.Number]vmfsDatastoreDeserializationFunc{
+	twoDotOh: vmfsDatastore_2_0,
+}
+
+func vmfsDatastore_2_0(source map[string]interface{}) (*vmfsDatastore, error) {
+	fields := schema.Fields{
+		"resource_uri": schema.String(),
+
+		"id":   schema.ForceInt(),
+		"name": schema.String(),
+		"uuid": schema.OneOf(schema.Nil(""), schema.String()),
+		"size": schema.ForceUint(),
+
+		// The datastore filesystem only has a type and mount point, so
+		// it isn't read as a full filesystem.
+		"filesystem": schema.OneOf(schema.Nil(""), schema.FieldMap(
+			schema.Fields{
+				"fstype":      schema.String(),
+				"mount_point": schema.OneOf(schema.Nil(""), schema.String()),
+			},
+			schema.Defaults{
+				"mount_point": "",
+			},
+		)),
+
+		"devices": schema.List(schema.StringMap(schema.Any())),
+	}
+	defaults := schema.Defaults{
+		"uuid":       "",
+		"filesystem": nil,
+	}
+	checker := schema.FieldMap(fields, defaults)
+	coerced, err := checker.Coerce(source, nil)
+	if err != nil {
+		return nil, WrapWithDeserializationError(err, "vmfs datastore 2.0 schema check failed")
+	}
+	valid := coerced.(map[string]interface{})
+	// From here we know that the map returned from the schema coercion
+	// contains fields of the right type.
+
+	devices, err := readStorageDeviceList(valid["devices"].([]interface{}))
+	if err != nil {
+		return nil, errors.Annotate(err, "devices")
+	}
+
+	var fstype, mountPoint string
+	if filesystem, ok := valid["filesystem"].(map[string]interface{}); ok {
+		fstype = filesystem["fstype"].(string)
+		mountPoint, _ = filesystem["mount_point"].(string)
+	}
+	uuid, _ := valid["uuid"].(string)
+	result := &vmfsDatastore{
+		resourceURI: valid["resource_uri"].(string),
+
+		id:   valid["id"].(int),
+		name: valid["name"].(string),
+		uuid: uuid,
+		size: valid["size"].(uint64),
+
+		fstype:     fstype,
+		mountPoint: mountPoint,
+
+		devices: devices,
+	}
+	return result, nil
+}
@@ -0,0 +1,157 @@
+// Copyright 2022 Canonical Ltd.
+// Licensed under the LGPLv3, see LICENCE file for details.
+
+package gomaasapi
+
+import (
+	"net/http"
+
+	"github.com/juju/testing"
+	jc "github.com/juju/testing/checkers"
+	"github.com/juju/version"
+	gc "gopkg.in/check.v1"
+)
+
+type vmfsDatastoreSuite struct {
+	testing.LoggingCleanupSuite
+}
+
+var _ = gc.Suite(&vmfsDatastoreSuite{})
+
+func (*vmfsDatastoreSuite) TestReadVMFSDatastoresBadSchema(c *gc.C) {
+	_, err := readVMFSDatastores(twoDotOh, "wat?")
+	c.Check(err, jc.Satisfies, IsDeserializationError)
+	c.Assert(err.Error(), gc.Equals, `vmfs datastore base schema check failed: expected list, got string("wat?")`)
+}
+
+func (*vmfsDatastoreSuite) TestReadVMFSDatastores(c *gc.C) {
+	datastores, err := readVMFSDatastores(twoDotOh, parseJSON(c, vmfsDatastoresResponse))
+	c.Assert(err, jc.ErrorIsNil)
+	c.Assert(datastores, gc.HasLen, 1)
+	datastore := datastores[0]
+
+	c.Check(datastore.ID(), gc.Equals, 3)
+	c.Check(datastore.Name(), gc.Equals, "datastore1")
+	c.Check(datastore.UUID(), gc.Equals, "2ad2a4ad-4df0-4e91-8a0a-0b8a50c6f7b1")
+	c.Check(datastore.Size(), gc.Equals, uint64(8581545984))
+	c.Check(datastore.FSType(), gc.Equals, "vmfs6")
+	c.Check(datastore.MountPoint(), gc.Equals, "/vmfs/volumes/datastore1")
+
+	devices := datastore.Devices()
+	c.Assert(devices, gc.HasLen, 1)
+	c.Check(devices[0].Type(), gc.Equals, "partition")
+	c.Check(devices[0].ID(), gc.Equals, 101)
+}
+
+func (*vmfsDatastoreSuite) TestReadVMFSDatastoreNilFilesystem(c *gc.C) {
+	json := updateJSONMap(c, vmfsDatastoreResponse, map[string]interface{}{
+		"filesystem": nil,
+		"uuid":       nil,
+	})
+	datastore, err := readVMFSDatastore(twoDotOh, parseJSON(c, json))
+	c.Assert(err, jc.ErrorIsNil)
+	c.Check(datastore.FSType(), gc.Equals, "")
+	c.Check(datastore.MountPoint(), gc.Equals, "")
+	c.Check(datastore.UUID(), gc.Equals, "")
+}
+
+func (*vmfsDatastoreSuite) TestLowVersion(c *gc.C) {
+	_, err := readVMFSDatastores(version.MustParse("1.9.0"), parseJSON(c, vmfsDatastoresResponse))
+	c.Assert(err, jc.Satisfies, IsUnsupportedVersionError)
+}
+
+func (*vmfsDatastoreSuite) TestHighVersion(c *gc.C) {
+	datastores, err := readVMFSDatastores(version.MustParse("2.1.9"), parseJSON(c, vmfsDatastoresResponse))
+	c.Assert(err, jc.ErrorIsNil)
+	c.Assert(datastores, gc.HasLen, 1)
+}
+
+func (s *vmfsDatastoreSuite) getServerAndDatastore(c *gc.C) (*SimpleTestServer, *vmfsDatastore) {
+	server, ctrl := createTestServerController(c, s)
+	datastore, err := readVMFSDatastore(twoDotOh, parseJSON(c, vmfsDatastoreResponse))
+	c.Assert(err, jc.ErrorIsNil)
+	datastore.controller = ctrl.(*controller)
+	server.ResetRequests()
+	return server, datastore
+}
+
+func (s *vmfsDatastoreSuite) TestUpdate(c *gc.C) {
+	server, datastore := s.getServerAndDatastore(c)
+	response := updateJSONMap(c, vmfsDatastoreResponse, map[string]interface{}{
+		"name": "datastore2",
+		"size": 17163091968,
+	})
+	server.AddPutResponse("/MAAS/api/2.0/nodes/4y3ha3/vmfs-datastore/3/", http.StatusOK, response)
+	err := datastore.Update(UpdateVMFSDatastoreArgs{
+		Name:       "datastore2",
+		AddDevices: []BlockDevice{&blockdevice{id: 34}},
+	})
+	c.Assert(err, jc.ErrorIsNil)
+	c.Check(datastore.Name(), gc.Equals, "datastore2")
+	c.Check(datastore.Size(), gc.Equals, uint64(17163091968))
+
+	form := server.LastRequest().PostForm
+	c.Check(form.Get("name"), gc.Equals, "datastore2")
+	c.Check(form["add_block_devices"], jc.DeepEquals, []string{"34"})
+	_, found := form["add_partitions"]
+	c.Check(found, jc.IsFalse)
+}
+
+func (s *vmfsDatastoreSuite) TestUpdateBadRequest(c *gc.C) {
+	server, datastore := s.getServerAndDatastore(c)
+	server.AddPutResponse("/MAAS/api/2.0/nodes/4y3ha3/vmfs-datastore/3/", http.StatusBadRequest, "device in use")
+	err := datastore.Update(UpdateVMFSDatastoreArgs{AddDevices: []BlockDevice{&blockdevice{id: 34}}})
+	c.Assert(err, jc.Satisfies, IsBadRequestError)
+	c.Check(datastore.Name(), gc.Equals, "datastore1")
+}
+
+func (s *vmfsDatastoreSuite) TestDelete(c *gc.C) {
+	server, datastore := s.getServerAndDatastore(c)
+	server.AddDeleteResponse("/MAAS/api/2.0/nodes/4y3ha3/vmfs-datastore/3/", http.StatusNoContent, "")
+	err := datastore.Delete()
+	c.Assert(err, jc.ErrorIsNil)
+}
+
+func (s *vmfsDatastoreSuite) TestDeleteMissing(c *gc.C) {
+	server, datastore := s.getServerAndDatastore(c)
+	server.AddDeleteResponse("/MAAS/api/2.0/nodes/4y3ha3/vmfs-datastore/3/", http.StatusNotFound, "")
+	err := datastore.Delete()
+	c.Assert(err, jc.Satisfies, IsNoMatchError)
+}
+
+const vmfsDatastoreResponse = `
+{
+    "id": 3,
+    "system_id": "4y3ha3",
+    "name": "datastore1",
+    "uuid": "2ad2a4ad-4df0-4e91-8a0a-0b8a50c6f7b1",
+    "size": 8581545984,
+    "human_size": "8.6 GB",
+    "filesystem": {
+        "fstype": "vmfs6",
+        "mount_point": "/vmfs/volumes/datastore1"
+    },
+    "resource_uri": "/MAAS/api/2.0/nodes/4y3ha3/vmfs-datastore/3/",
+    "devices": [
+        {
+            "bootable": false,
+            "id": 101,
+            "path": "/dev/disk/by-dname/sdb-part1",
+            "filesystem": {
+                "fstype": "lvm-pv",
+                "mount_point": null,
+                "label": null,
+                "mount_options": null,
+                "uuid": "fcd7745e-f1b5-4f5d-9575-9b0bb796b753"
+            },
+            "type": "partition",
+            "resource_uri": "/MAAS/api/2.0/nodes/4y3ha3/blockdevices/98/partition/101",
+            "uuid": "6199b7c9-b66f-40f6-a238-a938a58a0ae0",
+            "used_for": "VMFS extent for datastore1",
+            "size": 8581545984
+        }
+    ]
+}
+`
+
+const vmfsDatastoresResponse = "[" + vmfsDatastoreResponse + "]"
@@ -0,0 +1,843 @@
+// Copyright 2022 Canonical Ltd.
+// Licensed under the LGPLv3, see LICENCE file for details.
+
+package gomaasapi
+
+import (
+	"fmt"
+	"net/http"
+	"strconv"
+	"strings"
+
+	"github.com/juju/collections/set"
+	"github.com/juju/errors"
+	"github.com/juju/schema"
+	"github.com/juju/version"
+)
+
+// VMHostResources are the amounts of the resources of a VM host.
+type VMHostResources struct {
+	Cores int
+	// Memory is in MiB.
+	Memory int
+	// LocalStorage is in bytes.
+	LocalStorage int64
+}
+
+// VMHostStoragePool is a pool of the VM host that the disks of the virtual
+// machines are allocated from.
+type VMHostStoragePool struct {
+	ID   string
+	Name string
+	// Type is the kind of the pool, such as "dir", "lvm" or "zfs".
+	Type string
+	Path string
+	// Total, Used and Available are in bytes.
+	Total     int64
+	Used      int64
+	Available int64
+	// Default is whether disks are allocated from the pool when no pool
+	// is given.
+	Default bool
+}
+
+type vmHost struct {
+	controller *controller
+
+	resourceURI string
+
+	id                    int
+	name                  string
+	type_                 string
+	version               string
+	architectures         []string
+	capabilities          []string
+	tags                  []string
+	host                  string
+	zone                  *zone
+	pool                  *pool
+	cpuOverCommitRatio    float64
+	memoryOverCommitRatio float64
+	defaultStoragePool    string
+	storagePools          []VMHostStoragePool
+	total                 VMHostResources
+	used                  VMHostResources
+	available             VMHostResources
+}
+
+// setController sets the controller of the VM host and of the zone and the
+// pool it returns, once it is read.
+func (v *vmHost) setController(c *controller) {
+	v.controller = c
+	if v.zone != nil {
+		v.zone.controller = c
+	}
+	if v.pool != nil {
+		v.pool.controller = c
+	}
+}
+
+// updateFrom replaces the state of the VM host with the one of other, which
+// is a new read of the same VM host.
+func (v *vmHost) updateFrom(other *vmHost) {
+	if other.zone != nil {
+		other.zone.controller = v.controller
+	}
+	if other.pool != nil {
+		other.pool.controller = v.controller
+	}
+	v.resourceURI = other.resourceURI
+	v.id = other.id
+	v.name = other.name
+	v.type_ = other.type_
+	v.version = other.version
+	v.architectures = other.architectures
+	v.capabilities = other.capabilities
+	v.tags = other.tags
+	v.host = other.host
+	v.zone = other.zone
+	v.pool = other.pool
+	v.cpuOverCommitRatio = other.cpuOverCommitRatio
+	v.memoryOverCommitRatio = other.memoryOverCommitRatio
+	v.defaultStoragePool = other.defaultStoragePool
+	v.storagePools = other.storagePools
+	v.total = other.total
+	v.used = other.used
+	v.available = other.available
+}
+
+// ID implements VMHost.
+func (v *vmHost) ID() int {
+	return v.id
+}
+
+// Name implements VMHost.
+func (v *vmHost) Name() string {
+	return v.name
+}
+
+// Type implements VMHost.
+func (v *vmHost) Type() string {
+	return v.type_
+}
+
+// Version implements VMHost.
+func (v *vmHost) Version() string {
+	return v.version
+}
+
+// Architectures implements VMHost.
+func (v *vmHost) Architectures() []string {
+	return v.architectures
+}
+
+// Capabilities implements VMHost.
+func (v *vmHost) Capabilities() []string {
+	return v.capabilities
+}
+
+// Tags implements VMHost.
+func (v *vmHost) Tags() []string {
+	return v.tags
+}
+
+// Host implements VMHost.
+func (v *vmHost) Host() string {
+	return v.host
+}
+
+// Zone implements VMHost.
+func (v *vmHost) Zone() Zone {
+	if v.zone == nil {
+		return nil
+	}
+	return v.zone
+}
+
+// Pool implements VMHost.
+func (v *vmHost) Pool() Pool {
+	if v.pool == nil {
+		return nil
+	}
+	return v.pool
+}
+
+// CPUOverCommitRatio implements VMHost.
+func (v *vmHost) CPUOverCommitRatio() float64 {
+	return v.cpuOverCommitRatio
+}
+
+// MemoryOverCommitRatio implements VMHost.
+func (v *vmHost) MemoryOverCommitRatio() float64 {
+	return v.memoryOverCommitRatio
+}
+
+// DefaultStoragePool implements VMHost.
+func (v *vmHost) DefaultStoragePool() string {
+	return v.defaultStoragePool
+}
+
+// StoragePools implements VMHost.
+func (v *vmHost) StoragePools() []VMHostStoragePool {
+	return v.storagePools
+}
+
+// Total implements VMHost.
+func (v *vmHost) Total() VMHostResources {
+	return v.total
+}
+
+// Used implements VMHost.
+func (v *vmHost) Used() VMHostResources {
+	return v.used
+}
+
+// Available implements VMHost.
+func (v *vmHost) Available() VMHostResources {
+	return v.available
+}
+
+// UpdateVMHostArgs is an argument struct for VMHost.Update. Only the fields
+// that are set are changed.
+type UpdateVMHostArgs struct {
+	Name string
+	Zone string
+	Pool string
+	// Tags replaces the tags of the VM host.
+	Tags []string
+	// CPUOverCommitRatio and MemoryOverCommitRatio are how many times the
+	// cores and the memory of the VM host can be allocated to virtual
+	// machines.
+	CPUOverCommitRatio    float64
+	MemoryOverCommitRatio float64
+	// DefaultStoragePool is the ID or the name of the pool disks are
+	// allocated from when no pool is given.
+	DefaultStoragePool string
+}
+
+// Validate ensures that the over-commit ratios aren't negative.
+func (a *UpdateVMHostArgs) Validate() error {
+	if a.CPUOverCommitRatio < 0 {
+		return errors.NotValidf("CPUOverCommitRatio %v", a.CPUOverCommitRatio)
+	}
+	if a.MemoryOverCommitRatio < 0 {
+		return errors.NotValidf("MemoryOverCommitRatio %v", a.MemoryOverCommitRatio)
+	}
+	return nil
+}
+
+// Update implements VMHost.
+func (v *vmHost) Update(args UpdateVMHostArgs) error {
+	if err := args.Validate(); err != nil {
+		return errors.Trace(err)
+	}
+	params := NewURLParams()
+	params.MaybeAdd("name", args.Name)
+	params.MaybeAdd("zone", args.Zone)
+	params.MaybeAdd("pool", args.Pool)
+	params.MaybeAdd("tags", strings.Join(args.Tags, ","))
+	if args.CPUOverCommitRatio > 0 {
+		params.Values.Add("cpu_over_commit_ratio", formatRatio(args.CPUOverCommitRatio))
+	}
+	if args.MemoryOverCommitRatio > 0 {
+		params.Values.Add("memory_over_commit_ratio", formatRatio(args.MemoryOverCommitRatio))
+	}
+	params.MaybeAdd("default_storage_pool", args.DefaultStoragePool)
+	source, err := v.controller.put(v.resourceURI, params.Values)
+	if err != nil {
+		if svrErr, ok := errors.Cause(err).(ServerError); ok {
+			switch svrErr.StatusCode {
+			case http.StatusNotFound:
+				return wrapError(err, NewNoMatchError(svrErr.BodyMessage))
+			case http.StatusForbidden:
+				return wrapError(err, NewPermissionError(svrErr.BodyMessage))
+			case http.StatusBadRequest:
+				return wrapError(err, NewBadRequestError(svrErr.BodyMessage))
+			}
+		}
+		return NewUnexpectedError(err)
+	}
+
+	response, err := readVMHost(v.controller.schemaVersion(), source)
+	if err != nil {
+		return errors.Trace(err)
+	}
+	v.updateFrom(response)
+	return nil
+}
+
+func formatRatio(ratio float64) string {
+	return strconv.FormatFloat(ratio, 'f', -1, 64)
+}
+
+// Refresh implements VMHost.
+func (v *vmHost) Refresh() error {
+	source, err := v.controller.post(v.resourceURI, "refresh", nil)
+	if err != nil {
+		if svrErr, ok := errors.Cause(err).(ServerError); ok {
+			switch svrErr.StatusCode {
+			case http.StatusNotFound:
+				return wrapError(err, NewNoMatchError(svrErr.BodyMessage))
+			case http.StatusForbidden:
+				return wrapError(err, NewPermissionError(svrErr.BodyMessage))
+			case http.StatusServiceUnavailable:
+				return wrapError(err, NewCannotCompleteError(svrErr.BodyMessage))
+			}
+		}
+		return NewUnexpectedError(err)
+	}
+
+	response, err := readVMHost(v.controller.schemaVersion(), source)
+	if err != nil {
+		return errors.Trace(err)
+	}
+	v.updateFrom(response)
+	return nil
+}
+
+// Delete implements VMHost.
+func (v *vmHost) Delete() error {
+	err := v.controller.delete(v.resourceURI)
+	if err != nil {
+		if svrErr, ok := errors.Cause(err).(ServerError); ok {
+			switch svrErr.StatusCode {
+			case http.StatusNotFound:
+				return wrapError(err, NewNoMatchError(svrErr.BodyMessage))
+			case http.StatusForbidden:
+				return wrapError(err, NewPermissionError(svrErr.BodyMessage))
+			}
+		}
+		return NewUnexpectedError(err)
+	}
+	return nil
+}
+
+// ComposeArgs is an argument struct for VMHost.Compose. All the fields are
+// optional, MAAS uses its defaults for the ones that aren't set.
+type ComposeArgs struct {
+	Hostname     string
+	Architecture string
+	Cores        int
+	// PinnedCores are the cores of the host the virtual machine runs on.
+	// The number of cores of the virtual machine is the number of pinned
+	// cores, so Cores can't also be set.
+	PinnedCores []int
+	// Memory is in MiB.
+	Memory int
+	// HugepagesBacked backs the memory of the virtual machine with huge
+	// pages.
+	HugepagesBacked bool
+	// Storage are the disks of the virtual machine. The tags of the disks
+	// are the names of the storage pools they are allocated from. The
+	// first disk is the root disk.
+	Storage []StorageSpec
+	// Interfaces are the network interfaces of the virtual machine.
+	Interfaces []InterfaceSpec
+	// Domain, Zone and Pool are the IDs the machine is put in. If they
+	// aren't set, the machine is put in the default domain, and in the
+	// zone and the pool of the VM host.
+	Domain *int
+	Zone   *int
+	Pool   *int
+}
+
+// Validate ensures that the cores are set at most once, and that the disks
+// and the interfaces are valid.
+func (a *ComposeArgs) Validate() error {
+	if a.Cores < 0 {
+		return errors.NotValidf("Cores %d", a.Cores)
+	}
+	if a.Cores > 0 && len(a.PinnedCores) > 0 {
+		return errors.NotValidf("both Cores and PinnedCores")
+	}
+	if a.Memory < 0 {
+		return errors.NotValidf("Memory %d", a.Memory)
+	}
+	storageLabels := set.NewStrings()
+	for _, spec := range a.Storage {
+		if err := spec.Validate(); err != nil {
+			return errors.Annotate(err, "Storage")
+		}
+		if spec.Label != "" {
+			if storageLabels.Contains(spec.Label) {
+				return errors.NotValidf("reusing storage label %q", spec.Label)
+			}
+			storageLabels.Add(spec.Label)
+		}
+	}
+	interfaceLabels := set.NewStrings()
+	for _, spec := range a.Interfaces {
+		if err := spec.Validate(); err != nil {
+			return errors.Annotate(err, "Interfaces")
+		}
+		if interfaceLabels.Contains(spec.Label) {
+			return errors.NotValidf("reusing interface label %q", spec.Label)
+		}
+		interfaceLabels.Add(spec.Label)
+	}
+	return nil
+}
+
+func (a *ComposeArgs) storage() string {
+	var values []string
+	for _, spec := range a.Storage {
+		values = append(values, spec.String())
+	}
+	return strings.Join(values, ",")
+}
+
+func (a *ComposeArgs) interfaces() string {
+	var values []string
+	for _, spec := range a.Interfaces {
+		values = append(values, spec.String())
+	}
+	return strings.Join(values, ";")
+}
+
+// Compose implements VMHost.
+func (v *vmHost) Compose(args ComposeArgs) (Machine, error) {
+	if err := args.Validate(); err != nil {
+		return nil, errors.Trace(err)
+	}
+	params := NewURLParams()
+	params.MaybeAdd("hostname", args.Hostname)
+	params.MaybeAdd("architecture", args.Architecture)
+	params.MaybeAddInt("cores", args.Cores)
+	for _, core := range args.PinnedCores {
+		params.Values.Add("pinned_cores", fmt.Sprint(core))
+	}
+	params.MaybeAddInt("memory", args.Memory)
+	params.MaybeAddBool("hugepages_backed", args.HugepagesBacked)
+	params.MaybeAdd("storage", args.storage())
+	params.MaybeAdd("interfaces", args.interfaces())
+	if args.Domain != nil {
+		params.Values.Add("domain", fmt.Sprint(*args.Domain))
+	}
+	if args.Zone != nil {
+		params.Values.Add("zone", fmt.Sprint(*args.Zone))
+	}
+	if args.Pool != nil {
+		params.Values.Add("pool", fmt.Sprint(*args.Pool))
+	}
+	source, err := v.controller.post(v.resourceURI, "compose", params.Values)
+	if err != nil {
+		if svrErr, ok := errors.Cause(err).(ServerError); ok {
+			switch svrErr.StatusCode {
+			case http.StatusNotFound:
+				return nil, wrapError(err, NewNoMatchError(svrErr.BodyMessage))
+			case http.StatusForbidden:
+				return nil, wrapError(err, NewPermissionError(svrErr.BodyMessage))
+			case http.StatusBadRequest:
+				return nil, wrapError(err, NewBadRequestError(svrErr.BodyMessage))
+			case http.StatusServiceUnavailable:
+				return nil, wrapError(err, NewCannotCompleteError(svrErr.BodyMessage))
+			}
+		}
+		return nil, NewUnexpectedError(err)
+	}
+
+	// MAAS only returns the system ID and the URI of the machine.
+	checker := schema.FieldMap(schema.Fields{"system_id": schema.String()}, nil)
+	coerced, err := checker.Coerce(source, nil)
+	if err != nil {
+		return nil, WrapWithDeserializationError(err, "composed machine schema check failed")
+	}
+	systemID := coerced.(map[string]interface{})["system_id"].(string)
+	machines, err := v.controller.Machines(MachinesArgs{SystemIDs: []string{systemID}})
+	if err != nil {
+		return nil, errors.Trace(err)
+	}
+	if len(machines) != 1 {
+		return nil, NewNoMatchError(fmt.Sprintf("no machine %q", systemID))
+	}
+	return machines[0], nil
+}
+
+// Parameters implements VMHost.
+func (v *vmHost) Parameters() (map[string]interface{}, error) {
+	source, err := v.controller.getOp(v.resourceURI, "parameters")
+	if err != nil {
+		if svrErr, ok := errors.Cause(err).(ServerError); ok {
+			switch svrErr.StatusCode {
+			case http.StatusNotFound:
+				return nil, wrapError(err, NewNoMatchError(svrErr.BodyMessage))
+			case http.StatusForbidden:
+				return nil, wrapError(err, NewPermissionError(svrErr.BodyMessage))
+			}
+		}
+		return nil, NewUnexpectedError(err)
+	}
+	params, ok := source.(map[string]interface{})
+	if !ok {
+		return nil, NewDeserializationError("unexpected vm host parameters %T", source)
+	}
+	return params, nil
+}
+
+// The types of VM hosts.
+const (
+	VMHostTypeVirsh = "virsh"
+	VMHostTypeLXD   = "lxd"
+)
+
+// CreateVMHostArgs is an argument struct for Controller.CreateVMHost.
+type CreateVMHostArgs struct {
+	// Type is VMHostTypeVirsh or VMHostTypeLXD. Required.
+	Type string
+	// PowerAddress is the address of the hypervisor, such as
+	// "qemu+ssh://user@host/system" for virsh or "host:8443" for LXD.
+	// Required.
+	PowerAddress string
+	// PowerUser and PowerPassword are the credentials of a virsh host.
+	PowerUser     string
+	PowerPassword string
+
+	Name string
+	Zone string
+	Pool string
+	Tags []string
+
+	// Project is the LXD project the virtual machines are created in.
+	// MAAS creates the project if it doesn't exist.
+	Project string
+	// Certificate and Key are the PEM encoded client certificate and key
+	// MAAS authenticates to LXD with. If they aren't given MAAS generates
+	// a certificate, which the VM host parameters return so that it can
+	// be added to the trust store of LXD.
+	Certificate string
+	Key         string
+	// Password is the LXD trust password, which MAAS uses to add its
+	// certificate to the trust store of LXD.
+	Password string
+}
+
+// Validate ensures that the type and the address are set, and that the
+// LXD fields are only set for LXD hosts.
+func (a *CreateVMHostArgs) Validate() error {
+	switch a.Type {
+	case "":
+		return errors.NotValidf("missing Type")
+	case VMHostTypeVirsh:
+		if a.Project != "" || a.Certificate != "" || a.Key != "" || a.Password != "" {
+			return errors.NotValidf("LXD parameters for %q VM host", a.Type)
+		}
+	case VMHostTypeLXD:
+		if a.PowerUser != "" || a.PowerPassword != "" {
+			return errors.NotValidf("PowerUser or PowerPassword for %q VM host", a.Type)
+		}
+		if (a.Certificate == "") != (a.Key == "") {
+			return errors.NotValidf("Certificate without Key")
+		}
+	default:
+		return errors.NotValidf("Type %q", a.Type)
+	}
+	if a.PowerAddress == "" {
+		return errors.NotValidf("missing PowerAddress")
+	}
+	return nil
+}
+
+// CreateVMHost implements Controller.
+func (c *controller) CreateVMHost(args CreateVMHostArgs) (VMHost, error) {
+	if err := args.Validate(); err != nil {
+		return nil, errors.Trace(err)
+	}
+	params := NewURLParams()
+	params.Values.Add("type", args.Type)
+	params.Values.Add("power_address", args.PowerAddress)
+	params.MaybeAdd("power_user", args.PowerUser)
+	params.MaybeAdd("power_pass", args.PowerPassword)
+	params.MaybeAdd("name", args.Name)
+	params.MaybeAdd("zone", args.Zone)
+	params.MaybeAdd("pool", args.Pool)
+	params.MaybeAdd("tags", strings.Join(args.Tags, ","))
+	params.MaybeAdd("project", args.Project)
+	params.MaybeAdd("certificate", args.Certificate)
+	params.MaybeAdd("key", args.Key)
+	params.MaybeAdd("password", args.Password)
+	source, err := c.post("pods", "", params.Values)
+	if err != nil {
+		if svrErr, ok := errors.Cause(err).(ServerError); ok {
+			switch svrErr.StatusCode {
+			case http.StatusForbidden:
+				return nil, wrapError(err, NewPermissionError(svrErr.BodyMessage))
+			case http.StatusBadRequest:
+				return nil, wrapError(err, NewBadRequestError(svrErr.BodyMessage))
+			case http.StatusServiceUnavailable:
+				return nil, wrapError(err, NewCannotCompleteError(svrErr.BodyMessage))
+			}
+		}
+		return nil, NewUnexpectedError(err)
+	}
+
+	vmHost, err := readVMHost(c.schemaVersion(), source)
+	if err != nil {
+		return nil, errors.Trace(err)
+	}
+	vmHost.setController(c)
+	return vmHost, nil
+}
+
+// VirtualMachines implements VMHost.
+func (v *vmHost) VirtualMachines() ([]Machine, error) {
+	// MAAS has no endpoint for the virtual machines of a host. Those it
+	// composes, and those it discovers when refreshing the host, are
+	// machines of the pod.
+	machines, err := v.controller.Machines(MachinesArgs{Pod: v.name})
+	if err != nil {
+		return nil, errors.Trace(err)
+	}
+	return machines, nil
+}
+
+// VMHosts implements Controller.
+func (c *controller) VMHosts() ([]VMHost, error) {
+	// The VM hosts were called pods before MAAS 2.9, and the pods endpoint
+	// is still served, so it is used for all versions.
+	source, err := c.getList("pods", nil, 0)
+	if err != nil {
+		if svrErr, ok := errors.Cause(err).(ServerError); ok {
+			if svrErr.StatusCode == http.StatusForbidden {
+				return nil, wrapError(err, NewPermissionError(svrErr.BodyMessage))
+			}
+		}
+		return nil, NewUnexpectedError(err)
+	}
+	vmHosts, err := readVMHosts(c.schemaVersion(), source)
+	if err != nil {
+		return nil, errors.Trace(err)
+	}
+	var result []VMHost
+	for _, v := range vmHosts {
+		v.setController(c)
+		result = append(result, v)
+	}
+	return result, nil
+}
+
+// VMHost implements Controller.
+func (c *controller) VMHost(id int) (VMHost, error) {
+	source, err := c.get(fmt.Sprintf("pods/%d", id))
+	if err != nil {
+		if svrErr, ok := errors.Cause(err).(ServerError); ok {
+			switch svrErr.StatusCode {
+			case http.StatusNotFound:
+				return nil, wrapError(err, NewNoMatchError(svrErr.BodyMessage))
+			case http.StatusForbidden:
+				return nil, wrapError(err, NewPermissionError(svrErr.BodyMessage))
+			}
+		}
+		return nil, NewUnexpectedError(err)
+	}
+	vmHost, err := readVMHost(c.schemaVersion(), source)
+	if err != nil {
+		return nil, errors.Trace(err)
+	}
+	vmHost.setController(c)
+	return vmHost, nil
+}
+
+func readVMHost(controllerVersion version.Number, source interface{}) (*vmHost, error) {
+	readFunc, err := getVMHostDeserializationFunc(controllerVersion)
+	if err != nil {
+		return nil, errors.Trace(err)
+	}
+
+	checker := schema.StringMap(schema.Any())
+	coerced, err := checker.Coerce(source, nil)
+	if err != nil {
+		return nil, WrapWithDeserializationError(err, "vm host base schema check failed")
+	}
+	valid := coerced.(map[string]interface{})
+	return readFunc(valid)
+}
+
+func readVMHosts(controllerVersion version.Number, source interface{}) ([]*vmHost, error) {
+	readFunc, err := getVMHostDeserializationFunc(controllerVersion)
+	if err != nil {
+		return nil, errors.Trace(err)
+	}
+
+	checker := schema.List(schema.StringMap(schema.Any()))
+	coerced, err := checker.Coerce(source, nil)
+	if err != nil {
+		return nil, WrapWithDeserializationError(err, "vm host base schema check failed")
+	}
+	valid := coerced.([]interface{})
+	return readVMHostList(valid, readFunc)
+}
+
+func getVMHostDeserializationFunc(controllerVersion version.Number) (vmHostDeserializationFunc, error) {
+	var deserialisationVersion version.Number
+	for v := range vmHostDeserializationFuncs {
+		if v.Compare(deserialisationVersion) > 0 && v.Compare(controllerVersion) <= 0 {
+			deserialisationVersion = v
+		}
+	}
+	if deserialisationVersion == version.Zero {
+		return nil, NewUnsupportedVersionError("no vm host read func for version %s", controllerVersion)
+	}
+	return vmHostDeserializationFuncs[deserialisationVersion], nil
+}
+
+// readVMHostList expects the values of the sourceList to be string maps.
+func readVMHostList(sourceList []interface{}, readFunc vmHostDeserializationFunc) ([]*vmHost, error) {
+	result := make([]*vmHost, 0, len(sourceList))
+	for i, value := range sourceList {
+		source, ok := value.(map[string]interface{})
+		if !ok {
+			return nil, NewDeserializationError("unexpected value for vm host %d, %T", i, value)
+		}
+		vmHost, err := readFunc(source)
+		if err != nil {
+			return nil, errors.Annotatef(err, "vm host %d", i)
+		}
+		result = append(result, vmHost)
+	}
+	return result, nil
+}
+
+type vmHostDeserializationFunc func(map[string]interface{}) (*vmHost, error)
+
+var vmHostDeserializationFuncs = map[version.Number]vmHostDeserializationFunc{
+	twoDotOh: vmHost_2_0,
+}
+
+func vmHost_2_0(source map[string]interface{}) (*vmHost, error) {
+	resources := schema.FieldMap(
+		schema.Fields{
+			"cores":         schema.ForceInt(),
+			"memory":        schema.ForceInt(),
+			"local_storage": schema.ForceInt(),
+		},
+		schema.Defaults{
+			"cores":         0,
+			"memory":        0,
+			"local_storage": 0,
+		},
+	)
+	fields := schema.Fields{
+		"resource_uri":             schema.String(),
+		"id":                       schema.ForceInt(),
+		"name":                     schema.String(),
+		"type":                     schema.String(),
+		"version":                  schema.OneOf(schema.Nil(""), schema.String()),
+		"architectures":            schema.List(schema.String()),
+		"capabilities":             schema.List(schema.String()),
+		"tags":                     schema.List(schema.String()),
+		"host":                     schema.OneOf(schema.Nil(""), schema.StringMap(schema.Any())),
+		"zone":                     schema.OneOf(schema.Nil(""), schema.StringMap(schema.Any())),
+		"pool":                     schema.OneOf(schema.Nil(""), schema.StringMap(schema.Any())),
+		"cpu_over_commit_ratio":    schema.Float(),
+		"memory_over_commit_ratio": schema.Float(),
+		"default_storage_pool":     schema.OneOf(schema.Nil(""), schema.String()),
+		"storage_pools": schema.List(schema.FieldMap(
+			schema.Fields{
+				"id":        schema.String(),
+				"name":      schema.String(),
+				"type":      schema.String(),
+				"path":      schema.String(),
+				"total":     schema.ForceInt(),
+				"used":      schema.ForceInt(),
+				"available": schema.ForceInt(),
+				"default":   schema.Bool(),
+			},
+			schema.Defaults{
+				"path":      "",
+				"total":     0,
+				"used":      0,
+				"available": 0,
+				"default":   false,
+			},
+		)),
+		"total":     resources,
+		"used":      resources,
+		"available": resources,
+	}
+	defaults := schema.Defaults{
+		"version":                  nil,
+		"architectures":            []interface{}{},
+		"capabilities":             []interface{}{},
+		"tags":                     []interface{}{},
+		"host":                     nil,
+		"zone":                     nil,
+		"pool":                     nil,
+		"cpu_over_commit_ratio":    1,
+		"memory_over_commit_ratio": 1,
+		"default_storage_pool":     nil,
+		"storage_pools":            []interface{}{},
+		"used":                     map[string]interface{}{},
+		"available":                map[string]interface{}{},
+	}
+	checker := schema.FieldMap(fields, defaults)
+	coerced, err := checker.Coerce(source, nil)
+	if err != nil {
+		return nil, WrapWithDeserializationError(err, "vm host 2.0 schema check failed")
+	}
+	valid := coerced.(map[string]interface{})
+	// From here we know that the map returned from the schema coercion
+	// contains fields of the right type.
+
+	var zone *zone
+	if valid["zone"] != nil {
+		if zone, err = zone_2_0(valid["zone"].(map[string]interface{})); err != nil {
+			return nil, errors.Trace(err)
+		}
+	}
+	var pool *pool
+	if valid["pool"] != nil {
+		if pool, err = pool_2_0(valid["pool"].(map[string]interface{})); err != nil {
+			return nil, errors.Trace(err)
+		}
+	}
+	var host string
+	if hostMap, ok := valid["host"].(map[string]interface{}); ok {
+		host, _ = hostMap["system_id"].(string)
+	}
+	var storagePools []VMHostStoragePool
+	for _, value := range valid["storage_pools"].([]interface{}) {
+		poolMap := value.(map[string]interface{})
+		storagePools = append(storagePools, VMHostStoragePool{
+			ID:        poolMap["id"].(string),
+			Name:      poolMap["name"].(string),
+			Type:      poolMap["type"].(string),
+			Path:      poolMap["path"].(string),
+			Total:     int64(poolMap["total"].(int)),
+			Used:      int64(poolMap["used"].(int)),
+			Available: int64(poolMap["available"].(int)),
+			Default:   poolMap["default"].(bool),
+		})
+	}
+	hostVersion, _ := valid["version"].(string)
+	defaultStoragePool, _ := valid["default_storage_pool"].(string)
+	result := &vmHost{
+		resourceURI:           valid["resource_uri"].(string),
+		id:                    valid["id"].(int),
+		name:                  valid["name"].(string),
+		type_:                 valid["type"].(string),
+		version:               hostVersion,
+		architectures:         convertToStringSlice(valid["architectures"]),
+		capabilities:          convertToStringSlice(valid["capabilities"]),
+		tags:                  convertToStringSlice(valid["tags"]),
+		host:                  host,
+		zone:                  zone,
+		pool:                  pool,
+		cpuOverCommitRatio:    valid["cpu_over_commit_ratio"].(float64),
+		memoryOverCommitRatio: valid["memory_over_commit_ratio"].(float64),
+		defaultStoragePool:    defaultStoragePool,
+		storagePools:          storagePools,
+		total:                 readVMHostResources(valid["total"]),
+		used:                  readVMHostResources(valid["used"]),
+		available:             readVMHostResources(valid["available"]),
+	}
+	return result, nil
+}
+
+func readVMHostResources(source interface{}) VMHostResources {
+	resources := source.(map[string]interface{})
+	return VMHostResources{
+		Cores:        resources["cores"].(int),
+		Memory:       resources["memory"].(int),
+		LocalStorage: int64(resources["local_storage"].(int)),
+	}
+}
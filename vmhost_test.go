@@ -0,0 +1,486 @@
+// Copyright 2022 Canonical Ltd.
+// Licensed under the LGPLv3, see LICENCE file for details.
+
+package gomaasapi
+
+import (
+	"net/http"
+	"sync"
+
+	"github.com/juju/errors"
+	"github.com/juju/testing"
+	jc "github.com/juju/testing/checkers"
+	"github.com/juju/version"
+	gc "gopkg.in/check.v1"
+)
+
+type vmHostSuite struct {
+	testing.LoggingCleanupSuite
+}
+
+var _ = gc.Suite(&vmHostSuite{})
+
+func (*vmHostSuite) TestReadVMHostsBadSchema(c *gc.C) {
+	_, err := readVMHosts(twoDotOh, "wat?")
+	c.Check(err, jc.Satisfies, IsDeserializationError)
+	c.Assert(err.Error(), gc.Equals, `vm host base schema check failed: expected list, got string("wat?")`)
+}
+
+func (*vmHostSuite) TestReadVMHosts(c *gc.C) {
+	vmHosts, err := readVMHosts(twoDotOh, parseJSON(c, vmHostsResponse))
+	c.Assert(err, jc.ErrorIsNil)
+	c.Assert(vmHosts, gc.HasLen, 2)
+
+	vmHost := vmHosts[0]
+	c.Check(vmHost.ID(), gc.Equals, 1)
+	c.Check(vmHost.Name(), gc.Equals, "kvm-1")
+	c.Check(vmHost.Type(), gc.Equals, "virsh")
+	c.Check(vmHost.Version(), gc.Equals, "6.0.0")
+	c.Check(vmHost.Architectures(), jc.DeepEquals, []string{"amd64/generic"})
+	c.Check(vmHost.Capabilities(), jc.DeepEquals, []string{"composable", "over_commit", "storage_pools"})
+	c.Check(vmHost.Tags(), jc.DeepEquals, []string{"virtual"})
+	c.Check(vmHost.Host(), gc.Equals, "4y3h7n")
+	c.Check(vmHost.Zone().Name(), gc.Equals, "default")
+	c.Check(vmHost.Pool().Name(), gc.Equals, "default")
+	c.Check(vmHost.CPUOverCommitRatio(), gc.Equals, 2.5)
+	c.Check(vmHost.MemoryOverCommitRatio(), gc.Equals, 1.0)
+	c.Check(vmHost.DefaultStoragePool(), gc.Equals, "2d4a2c9a")
+	c.Check(vmHost.StoragePools(), jc.DeepEquals, []VMHostStoragePool{{
+		ID:        "2d4a2c9a",
+		Name:      "default",
+		Type:      "dir",
+		Path:      "/var/lib/libvirt/images",
+		Total:     500107862016,
+		Used:      21474836480,
+		Available: 478633025536,
+		Default:   true,
+	}})
+	c.Check(vmHost.Total(), jc.DeepEquals, VMHostResources{Cores: 16, Memory: 65536, LocalStorage: 500107862016})
+	c.Check(vmHost.Used(), jc.DeepEquals, VMHostResources{Cores: 4, Memory: 8192, LocalStorage: 21474836480})
+	c.Check(vmHost.Available(), jc.DeepEquals, VMHostResources{Cores: 12, Memory: 57344, LocalStorage: 478633025536})
+
+	vmHost = vmHosts[1]
+	c.Check(vmHost.Type(), gc.Equals, "lxd")
+	c.Check(vmHost.Version(), gc.Equals, "")
+	c.Check(vmHost.Host(), gc.Equals, "")
+	c.Check(vmHost.Zone(), gc.IsNil)
+	c.Check(vmHost.Pool(), gc.IsNil)
+	c.Check(vmHost.CPUOverCommitRatio(), gc.Equals, 1.0)
+	c.Check(vmHost.DefaultStoragePool(), gc.Equals, "")
+	c.Check(vmHost.StoragePools(), gc.HasLen, 0)
+	c.Check(vmHost.Used(), jc.DeepEquals, VMHostResources{})
+}
+
+func (*vmHostSuite) TestLowVersion(c *gc.C) {
+	_, err := readVMHosts(version.MustParse("1.9.0"), parseJSON(c, vmHostsResponse))
+	c.Assert(err, jc.Satisfies, IsUnsupportedVersionError)
+}
+
+func (*vmHostSuite) TestHighVersion(c *gc.C) {
+	vmHosts, err := readVMHosts(version.MustParse("2.1.9"), parseJSON(c, vmHostsResponse))
+	c.Assert(err, jc.ErrorIsNil)
+	c.Assert(vmHosts, gc.HasLen, 2)
+}
+
+func (s *vmHostSuite) getServerAndVMHost(c *gc.C) (*SimpleTestServer, VMHost) {
+	server, controller := createTestServerController(c, s)
+	server.AddGetResponse("/api/2.0/pods/", http.StatusOK, vmHostsResponse)
+	vmHosts, err := controller.VMHosts()
+	c.Assert(err, jc.ErrorIsNil)
+	c.Assert(vmHosts, gc.HasLen, 2)
+	return server, vmHosts[0]
+}
+
+func (s *vmHostSuite) TestVMHostsForbidden(c *gc.C) {
+	server, controller := createTestServerController(c, s)
+	server.AddGetResponse("/api/2.0/pods/", http.StatusForbidden, "admins only")
+	_, err := controller.VMHosts()
+	c.Assert(err, jc.Satisfies, IsPermissionError)
+}
+
+func (s *vmHostSuite) TestVMHost(c *gc.C) {
+	server, controller := createTestServerController(c, s)
+	server.AddGetResponse("/api/2.0/pods/1/", http.StatusOK, vmHostResponse)
+	vmHost, err := controller.VMHost(1)
+	c.Assert(err, jc.ErrorIsNil)
+	c.Assert(vmHost.Name(), gc.Equals, "kvm-1")
+}
+
+func (s *vmHostSuite) TestVMHostMissing(c *gc.C) {
+	_, controller := createTestServerController(c, s)
+	_, err := controller.VMHost(1)
+	c.Assert(err, jc.Satisfies, IsNoMatchError)
+}
+
+func (s *vmHostSuite) TestUpdate(c *gc.C) {
+	server, vmHost := s.getServerAndVMHost(c)
+	response := updateJSONMap(c, vmHostResponse, map[string]interface{}{
+		"name":                     "kvm-renamed",
+		"memory_over_commit_ratio": 1.5,
+	})
+	server.AddPutResponse("/api/2.0/pods/1/", http.StatusOK, response)
+	err := vmHost.Update(UpdateVMHostArgs{
+		Name:                  "kvm-renamed",
+		Tags:                  []string{"virtual", "fast"},
+		MemoryOverCommitRatio: 1.5,
+	})
+	c.Assert(err, jc.ErrorIsNil)
+	c.Check(vmHost.Name(), gc.Equals, "kvm-renamed")
+	c.Check(vmHost.MemoryOverCommitRatio(), gc.Equals, 1.5)
+
+	form := server.LastRequest().PostForm
+	c.Check(form.Get("name"), gc.Equals, "kvm-renamed")
+	c.Check(form.Get("tags"), gc.Equals, "virtual,fast")
+	c.Check(form.Get("memory_over_commit_ratio"), gc.Equals, "1.5")
+	c.Check(form["cpu_over_commit_ratio"], gc.HasLen, 0)
+	c.Check(form["zone"], gc.HasLen, 0)
+}
+
+func (s *vmHostSuite) TestUpdateValidates(c *gc.C) {
+	_, vmHost := s.getServerAndVMHost(c)
+	err := vmHost.Update(UpdateVMHostArgs{CPUOverCommitRatio: -1})
+	c.Assert(err, jc.Satisfies, errors.IsNotValid)
+	c.Assert(err.Error(), gc.Equals, "CPUOverCommitRatio -1 not valid")
+}
+
+func (s *vmHostSuite) TestUpdateBadRequest(c *gc.C) {
+	server, vmHost := s.getServerAndVMHost(c)
+	server.AddPutResponse("/api/2.0/pods/1/", http.StatusBadRequest, "bad ratio")
+	err := vmHost.Update(UpdateVMHostArgs{CPUOverCommitRatio: 100})
+	c.Assert(err, jc.Satisfies, IsBadRequestError)
+}
+
+func (s *vmHostSuite) TestRefresh(c *gc.C) {
+	server, vmHost := s.getServerAndVMHost(c)
+	response := updateJSONMap(c, vmHostResponse, map[string]interface{}{
+		"version": "7.0.0",
+	})
+	server.AddPostResponse("/api/2.0/pods/1/?op=refresh", http.StatusOK, response)
+	err := vmHost.Refresh()
+	c.Assert(err, jc.ErrorIsNil)
+	c.Check(vmHost.Version(), gc.Equals, "7.0.0")
+}
+
+func (s *vmHostSuite) TestPoolController(c *gc.C) {
+	server, host := s.getServerAndVMHost(c)
+	controller := host.(*vmHost).controller
+	c.Assert(controller, gc.NotNil)
+	var wg sync.WaitGroup
+	for i := 0; i < 2; i++ {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			c.Check(host.Zone().(*zone).controller, gc.Equals, controller)
+			c.Check(host.Pool().(*pool).controller, gc.Equals, controller)
+		}()
+	}
+	wg.Wait()
+
+	// The zone and the pool of a new read of the VM host have the
+	// controller too.
+	server.AddPostResponse("/api/2.0/pods/1/?op=refresh", http.StatusOK, vmHostResponse)
+	c.Assert(host.Refresh(), jc.ErrorIsNil)
+	c.Check(host.Zone().(*zone).controller, gc.Equals, controller)
+	c.Check(host.Pool().(*pool).controller, gc.Equals, controller)
+}
+
+func (s *vmHostSuite) TestRefreshUnavailable(c *gc.C) {
+	server, vmHost := s.getServerAndVMHost(c)
+	server.AddPostResponse("/api/2.0/pods/1/?op=refresh", http.StatusServiceUnavailable, "host unreachable")
+	err := vmHost.Refresh()
+	c.Assert(err, jc.Satisfies, IsCannotCompleteError)
+}
+
+func (s *vmHostSuite) TestDelete(c *gc.C) {
+	server, vmHost := s.getServerAndVMHost(c)
+	server.AddDeleteResponse("/api/2.0/pods/1/", http.StatusNoContent, "")
+	err := vmHost.Delete()
+	c.Assert(err, jc.ErrorIsNil)
+}
+
+func (s *vmHostSuite) TestDeleteMissing(c *gc.C) {
+	_, vmHost := s.getServerAndVMHost(c)
+	err := vmHost.Delete()
+	c.Assert(err, jc.Satisfies, IsNoMatchError)
+}
+
+const vmHostResponse = `
+{
+    "id": 1,
+    "name": "kvm-1",
+    "type": "virsh",
+    "version": "6.0.0",
+    "architectures": ["amd64/generic"],
+    "capabilities": ["composable", "over_commit", "storage_pools"],
+    "tags": ["virtual"],
+    "host": {"system_id": "4y3h7n", "__incomplete__": true},
+    "zone": {
+        "name": "default",
+        "description": "",
+        "id": 1,
+        "resource_uri": "/api/2.0/zones/default/"
+    },
+    "pool": {
+        "name": "default",
+        "description": "Default pool",
+        "id": 0,
+        "resource_uri": "/api/2.0/resourcepool/0/"
+    },
+    "cpu_over_commit_ratio": 2.5,
+    "memory_over_commit_ratio": 1,
+    "default_storage_pool": "2d4a2c9a",
+    "storage_pools": [
+        {
+            "id": "2d4a2c9a",
+            "name": "default",
+            "type": "dir",
+            "path": "/var/lib/libvirt/images",
+            "total": 500107862016,
+            "used": 21474836480,
+            "available": 478633025536,
+            "default": true
+        }
+    ],
+    "total": {"cores": 16, "memory": 65536, "local_storage": 500107862016},
+    "used": {"cores": 4, "memory": 8192, "local_storage": 21474836480},
+    "available": {"cores": 12, "memory": 57344, "local_storage": 478633025536},
+    "resource_uri": "/api/2.0/pods/1/"
+}
+`
+
+const vmHostsResponse = "[" + vmHostResponse + `,
+{
+    "id": 2,
+    "name": "lxd-1",
+    "type": "lxd",
+    "version": null,
+    "architectures": ["amd64/generic"],
+    "capabilities": ["composable"],
+    "tags": [],
+    "host": null,
+    "zone": null,
+    "pool": null,
+    "default_storage_pool": null,
+    "total": {"cores": 8, "memory": 16384, "local_storage": 0},
+    "resource_uri": "/api/2.0/pods/2/"
+}
+]`
+
+func (s *vmHostSuite) TestComposeArgsValidate(c *gc.C) {
+	for i, test := range []struct {
+		args    ComposeArgs
+		message string
+	}{{
+		args: ComposeArgs{},
+	}, {
+		args:    ComposeArgs{Cores: -1},
+		message: "Cores -1",
+	}, {
+		args:    ComposeArgs{Cores: 2, PinnedCores: []int{0, 1}},
+		message: "both Cores and PinnedCores",
+	}, {
+		args:    ComposeArgs{Memory: -1},
+		message: "Memory -1",
+	}, {
+		args:    ComposeArgs{Storage: []StorageSpec{{Size: 0}}},
+		message: "Storage: Size value 0",
+	}, {
+		args:    ComposeArgs{Storage: []StorageSpec{{Label: "root", Size: 10}, {Label: "root", Size: 20}}},
+		message: `reusing storage label "root"`,
+	}, {
+		args:    ComposeArgs{Interfaces: []InterfaceSpec{{Label: "eth0"}}},
+		message: "Interfaces: empty Space constraint",
+	}, {
+		args:    ComposeArgs{Interfaces: []InterfaceSpec{{Label: "eth0", Space: "a"}, {Label: "eth0", Space: "b"}}},
+		message: `reusing interface label "eth0"`,
+	}} {
+		c.Logf("test %d", i)
+		err := test.args.Validate()
+		if test.message == "" {
+			c.Check(err, jc.ErrorIsNil)
+		} else {
+			c.Check(err, jc.Satisfies, errors.IsNotValid)
+			c.Check(err.Error(), gc.Equals, test.message+" not valid")
+		}
+	}
+}
+
+func (s *vmHostSuite) TestCompose(c *gc.C) {
+	server, vmHost := s.getServerAndVMHost(c)
+	server.AddPostResponse("/api/2.0/pods/1/?op=compose", http.StatusOK,
+		`{"system_id": "4y3ha3", "resource_uri": "/api/2.0/machines/4y3ha3/"}`)
+	server.AddGetResponse("/api/2.0/machines/?id=4y3ha3", http.StatusOK, "["+machineResponse+"]")
+	zone, pool := 2, 0
+	machine, err := vmHost.Compose(ComposeArgs{
+		Hostname:        "vm-1",
+		PinnedCores:     []int{2, 3},
+		Memory:          4096,
+		HugepagesBacked: true,
+		Storage: []StorageSpec{
+			{Label: "root", Size: 20, Tags: []string{"default"}},
+			{Size: 100},
+		},
+		Interfaces: []InterfaceSpec{{Label: "eth0", Space: "storage"}},
+		Zone:       &zone,
+		Pool:       &pool,
+	})
+	c.Assert(err, jc.ErrorIsNil)
+	c.Assert(machine.SystemID(), gc.Equals, "4y3ha3")
+
+	requests := server.LastNRequests(2)
+	form := requests[0].PostForm
+	c.Check(form.Get("hostname"), gc.Equals, "vm-1")
+	c.Check(form["pinned_cores"], jc.DeepEquals, []string{"2", "3"})
+	c.Check(form["cores"], gc.HasLen, 0)
+	c.Check(form.Get("memory"), gc.Equals, "4096")
+	c.Check(form.Get("hugepages_backed"), gc.Equals, "true")
+	c.Check(form.Get("storage"), gc.Equals, "root:20(default),100")
+	c.Check(form.Get("interfaces"), gc.Equals, "eth0:space=storage")
+	c.Check(form.Get("zone"), gc.Equals, "2")
+	c.Check(form.Get("pool"), gc.Equals, "0")
+	c.Check(form["domain"], gc.HasLen, 0)
+}
+
+func (s *vmHostSuite) TestComposeUnavailable(c *gc.C) {
+	server, vmHost := s.getServerAndVMHost(c)
+	server.AddPostResponse("/api/2.0/pods/1/?op=compose", http.StatusServiceUnavailable, "not enough cores")
+	_, err := vmHost.Compose(ComposeArgs{Cores: 64})
+	c.Assert(err, jc.Satisfies, IsCannotCompleteError)
+	c.Assert(err.Error(), gc.Equals, "not enough cores")
+}
+
+func (s *vmHostSuite) TestComposeBadResponse(c *gc.C) {
+	server, vmHost := s.getServerAndVMHost(c)
+	server.AddPostResponse("/api/2.0/pods/1/?op=compose", http.StatusOK, `{"resource_uri": "/api/2.0/machines/4y3ha3/"}`)
+	_, err := vmHost.Compose(ComposeArgs{})
+	c.Assert(err, jc.Satisfies, IsDeserializationError)
+}
+
+func (s *vmHostSuite) TestParameters(c *gc.C) {
+	server, vmHost := s.getServerAndVMHost(c)
+	server.AddGetResponse("/api/2.0/pods/1/?op=parameters", http.StatusOK,
+		`{"power_address": "10.0.0.2:8443", "project": "maas", "certificate": "-----BEGIN CERTIFICATE-----"}`)
+	params, err := vmHost.Parameters()
+	c.Assert(err, jc.ErrorIsNil)
+	c.Assert(params, jc.DeepEquals, map[string]interface{}{
+		"power_address": "10.0.0.2:8443",
+		"project":       "maas",
+		"certificate":   "-----BEGIN CERTIFICATE-----",
+	})
+}
+
+func (s *vmHostSuite) TestParametersForbidden(c *gc.C) {
+	server, vmHost := s.getServerAndVMHost(c)
+	server.AddGetResponse("/api/2.0/pods/1/?op=parameters", http.StatusForbidden, "admins only")
+	_, err := vmHost.Parameters()
+	c.Assert(err, jc.Satisfies, IsPermissionError)
+}
+
+func (s *vmHostSuite) TestParametersBadResponse(c *gc.C) {
+	server, vmHost := s.getServerAndVMHost(c)
+	server.AddGetResponse("/api/2.0/pods/1/?op=parameters", http.StatusOK, `["wat"]`)
+	_, err := vmHost.Parameters()
+	c.Assert(err, jc.Satisfies, IsDeserializationError)
+}
+
+func (s *vmHostSuite) TestCreateVMHostArgsValidate(c *gc.C) {
+	for i, test := range []struct {
+		args    CreateVMHostArgs
+		message string
+	}{{
+		args:    CreateVMHostArgs{},
+		message: "missing Type",
+	}, {
+		args:    CreateVMHostArgs{Type: "vmware"},
+		message: `Type "vmware"`,
+	}, {
+		args:    CreateVMHostArgs{Type: VMHostTypeLXD},
+		message: "missing PowerAddress",
+	}, {
+		args:    CreateVMHostArgs{Type: VMHostTypeVirsh, PowerAddress: "qemu+ssh://host/system", Project: "maas"},
+		message: `LXD parameters for "virsh" VM host`,
+	}, {
+		args:    CreateVMHostArgs{Type: VMHostTypeLXD, PowerAddress: "host:8443", PowerUser: "root"},
+		message: `PowerUser or PowerPassword for "lxd" VM host`,
+	}, {
+		args:    CreateVMHostArgs{Type: VMHostTypeLXD, PowerAddress: "host:8443", Certificate: "cert"},
+		message: "Certificate without Key",
+	}, {
+		args: CreateVMHostArgs{Type: VMHostTypeVirsh, PowerAddress: "qemu+ssh://host/system", PowerPassword: "secret"},
+	}, {
+		args: CreateVMHostArgs{Type: VMHostTypeLXD, PowerAddress: "host:8443", Project: "maas", Certificate: "cert", Key: "key"},
+	}} {
+		c.Logf("test %d", i)
+		err := test.args.Validate()
+		if test.message == "" {
+			c.Check(err, jc.ErrorIsNil)
+		} else {
+			c.Check(err, jc.Satisfies, errors.IsNotValid)
+			c.Check(err.Error(), gc.Equals, test.message+" not valid")
+		}
+	}
+}
+
+func (s *vmHostSuite) TestCreateVMHostLXD(c *gc.C) {
+	server, controller := createTestServerController(c, s)
+	response := updateJSONMap(c, vmHostResponse, map[string]interface{}{"type": "lxd"})
+	server.AddPostResponse("/api/2.0/pods/?op=", http.StatusOK, response)
+	vmHost, err := controller.CreateVMHost(CreateVMHostArgs{
+		Type:         VMHostTypeLXD,
+		PowerAddress: "10.0.0.2:8443",
+		Name:         "lxd-1",
+		Tags:         []string{"virtual", "lxd"},
+		Project:      "maas",
+		Password:     "trust-me",
+	})
+	c.Assert(err, jc.ErrorIsNil)
+	c.Assert(vmHost.Type(), gc.Equals, "lxd")
+
+	form := server.LastRequest().PostForm
+	c.Check(form.Get("type"), gc.Equals, "lxd")
+	c.Check(form.Get("power_address"), gc.Equals, "10.0.0.2:8443")
+	c.Check(form.Get("name"), gc.Equals, "lxd-1")
+	c.Check(form.Get("tags"), gc.Equals, "virtual,lxd")
+	c.Check(form.Get("project"), gc.Equals, "maas")
+	c.Check(form.Get("password"), gc.Equals, "trust-me")
+	c.Check(form["certificate"], gc.HasLen, 0)
+	c.Check(form["key"], gc.HasLen, 0)
+	c.Check(form["power_pass"], gc.HasLen, 0)
+}
+
+func (s *vmHostSuite) TestCreateVMHostVirsh(c *gc.C) {
+	server, controller := createTestServerController(c, s)
+	server.AddPostResponse("/api/2.0/pods/?op=", http.StatusOK, vmHostResponse)
+	_, err := controller.CreateVMHost(CreateVMHostArgs{
+		Type:          VMHostTypeVirsh,
+		PowerAddress:  "qemu+ssh://ubuntu@10.0.0.3/system",
+		PowerPassword: "secret",
+	})
+	c.Assert(err, jc.ErrorIsNil)
+
+	form := server.LastRequest().PostForm
+	c.Check(form.Get("type"), gc.Equals, "virsh")
+	c.Check(form.Get("power_pass"), gc.Equals, "secret")
+}
+
+func (s *vmHostSuite) TestCreateVMHostUnavailable(c *gc.C) {
+	server, controller := createTestServerController(c, s)
+	server.AddPostResponse("/api/2.0/pods/?op=", http.StatusServiceUnavailable, "unable to connect")
+	_, err := controller.CreateVMHost(CreateVMHostArgs{Type: VMHostTypeLXD, PowerAddress: "10.0.0.2:8443"})
+	c.Assert(err, jc.Satisfies, IsCannotCompleteError)
+}
+
+func (s *vmHostSuite) TestVirtualMachines(c *gc.C) {
+	server, vmHost := s.getServerAndVMHost(c)
+	server.AddGetResponse("/api/2.0/machines/?pod=kvm-1", http.StatusOK, "["+machineResponse+"]")
+	machines, err := vmHost.VirtualMachines()
+	c.Assert(err, jc.ErrorIsNil)
+	c.Assert(machines, gc.HasLen, 1)
+	c.Assert(machines[0].SystemID(), gc.Equals, "4y3ha3")
+}
+
+func (s *vmHostSuite) TestVirtualMachinesError(c *gc.C) {
+	_, vmHost := s.getServerAndVMHost(c)
+	_, err := vmHost.VirtualMachines()
+	c.Assert(err, jc.Satisfies, IsUnexpectedError)
+}
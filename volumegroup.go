@@ -0,0 +1,272 @@
+// Copyright 2022 Canonical Ltd.
+// Licensed under the LGPLv3, see LICENCE file for details.
+
+package gomaasapi
+
+import (
+	"fmt"
+
+	"github.com/juju/errors"
+	"github.com/juju/schema"
+	"github.com/juju/version"
+)
+
+type volumeGroup struct {
+	controller *controller
+
+	resourceURI string
+
+	id   int
+	name string
+	uuid string
+
+	size          uint64
+	usedSize      uint64
+	availableSize uint64
+
+	devices        []StorageDevice
+	logicalVolumes []*blockdevice
+}
+
+// ID implements VolumeGroup.
+func (v *volumeGroup) ID() int {
+	return v.id
+}
+
+// Name implements VolumeGroup.
+func (v *volumeGroup) Name() string {
+	return v.name
+}
+
+// UUID implements VolumeGroup.
+func (v *volumeGroup) UUID() string {
+	return v.uuid
+}
+
+// Size implements VolumeGroup.
+func (v *volumeGroup) Size() uint64 {
+	return v.size
+}
+
+// UsedSize implements VolumeGroup.
+func (v *volumeGroup) UsedSize() uint64 {
+	return v.usedSize
+}
+
+// AvailableSize implements VolumeGroup.
+func (v *volumeGroup) AvailableSize() uint64 {
+	return v.availableSize
+}
+
+// Devices implements VolumeGroup.
+func (v *volumeGroup) Devices() []StorageDevice {
+	setStorageDeviceController(v.devices, v.controller)
+	return v.devices
+}
+
+// LogicalVolumes implements VolumeGroup.
+func (v *volumeGroup) LogicalVolumes() []BlockDevice {
+	result := make([]BlockDevice, len(v.logicalVolumes))
+	for i, volume := range v.logicalVolumes {
+		volume.controller = v.controller
+		result[i] = volume
+	}
+	return result
+}
+
+// CreateLogicalVolumeArgs is an argument struct for
+// VolumeGroup.CreateLogicalVolume. The Name is required. If Size is zero,
+// the logical volume uses all the available space of the volume group.
+type CreateLogicalVolumeArgs struct {
+	Name string
+	UUID string
+	Size uint64
+}
+
+// Validate ensures that the Name is set.
+func (a *CreateLogicalVolumeArgs) Validate() error {
+	if a.Name == "" {
+		return errors.NotValidf("missing Name")
+	}
+	return nil
+}
+
+// CreateLogicalVolume implements VolumeGroup.
+func (v *volumeGroup) CreateLogicalVolume(args CreateLogicalVolumeArgs) (BlockDevice, error) {
+	if err := args.Validate(); err != nil {
+		return nil, errors.Trace(err)
+	}
+	params := NewURLParams()
+	params.Values.Add("name", args.Name)
+	params.MaybeAdd("uuid", args.UUID)
+	if args.Size != 0 {
+		params.Values.Add("size", fmt.Sprint(args.Size))
+	}
+	source, err := v.controller.post(v.resourceURI, "create_logical_volume", params.Values)
+	if err != nil {
+		return nil, errors.Trace(storageDeviceError(err))
+	}
+
+	volume, err := readBlockDevice(v.controller.schemaVersion(), source)
+	if err != nil {
+		return nil, errors.Trace(err)
+	}
+	volume.controller = v.controller
+	v.logicalVolumes = append(v.logicalVolumes, volume)
+	return volume, nil
+}
+
+// DeleteLogicalVolume implements VolumeGroup.
+func (v *volumeGroup) DeleteLogicalVolume(volume BlockDevice) error {
+	if volume == nil {
+		return errors.NotValidf("missing logical volume")
+	}
+	index := -1
+	for i, candidate := range v.logicalVolumes {
+		if candidate.ID() == volume.ID() {
+			index = i
+			break
+		}
+	}
+	if index < 0 {
+		return errors.NotValidf("logical volume %q not in volume group %q", volume.Name(), v.name)
+	}
+	params := NewURLParams()
+	params.Values.Add("id", fmt.Sprint(volume.ID()))
+	// MAAS returns no content, so the raw response is ignored.
+	if _, err := v.controller._postRaw(v.resourceURI, "delete_logical_volume", params.Values, nil); err != nil {
+		return errors.Trace(storageDeviceError(err))
+	}
+	v.logicalVolumes = append(v.logicalVolumes[:index], v.logicalVolumes[index+1:]...)
+	return nil
+}
+
+// Delete implements VolumeGroup.
+func (v *volumeGroup) Delete() error {
+	err := v.controller.delete(v.resourceURI)
+	if err != nil {
+		return errors.Trace(storageDeviceError(err))
+	}
+	return nil
+}
+
+func readVolumeGroup(controllerVersion version.Number, source interface{}) (*volumeGroup, error) {
+	readFunc, err := getVolumeGroupDeserializationFunc(controllerVersion)
+	if err != nil {
+		return nil, errors.Trace(err)
+	}
+
+	checker := schema.StringMap(schema.Any())
+	coerced, err := checker.Coerce(source, nil)
+	if err != nil {
+		return nil, WrapWithDeserializationError(err, "volume group base schema check failed")
+	}
+	valid := coerced.(map[string]interface{})
+	return readFunc(valid)
+}
+
+func readVolumeGroups(controllerVersion version.Number, source interface{}) ([]*volumeGroup, error) {
+	readFunc, err := getVolumeGroupDeserializationFunc(controllerVersion)
+	if err != nil {
+		return nil, errors.Trace(err)
+	}
+
+	checker := schema.List(schema.StringMap(schema.Any()))
+	coerced, err := checker.Coerce(source, nil)
+	if err != nil {
+		return nil, WrapWithDeserializationError(err, "volume group base schema check failed")
+	}
+	valid := coerced.([]interface{})
+	return readVolumeGroupList(valid, readFunc)
+}
+
+func getVolumeGroupDeserializationFunc(controllerVersion version.Number) (volumeGroupDeserializationFunc, error) {
+	var deserialisationVersion version.Number
+	for v := range volumeGroupDeserializationFuncs {
+		if v.Compare(deserialisationVersion) > 0 && v.Compare(controllerVersion) <= 0 {
+			deserialisationVersion = v
+		}
+	}
+	if deserialisationVersion == version.Zero {
+		return nil, NewUnsupportedVersionError("no volume group read func for version %s", controllerVersion)
+	}
+	return volumeGroupDeserializationFuncs[deserialisationVersion], nil
+}
+
+// readVolumeGroupList expects the values of the sourceList to be string maps.
+func readVolumeGroupList(sourceList []interface{}, readFunc volumeGroupDeserializationFunc) ([]*volumeGroup, error) {
+	result := make([]*volumeGroup, 0, len(sourceList))
+	for i, value := range sourceList {
+		source, ok := value.(map[string]interface{})
+		if !ok {
+			return nil, NewDeserializationError("unexpected value for volume group %d, %T", i, value)
+		}
+		volumeGroup, err := readFunc(source)
+		if err != nil {
+			return nil, errors.Annotatef(err, "volume group %d", i)
+		}
+		result = append(result, volumeGroup)
+	}
+	return result, nil
+}
+
+type volumeGroupDeserializationFunc func(map[string]interface{}) (*volumeGroup, error)
+
+var volumeGroupDeserializationFuncs = map[version.Number]volumeGroupDeserializationFunc{
+	twoDotOh: volumeGroup_2_0,
+}
+
+func volumeGroup_2_0(source map[string]interface{}) (*volumeGroup, error) {
+	fields := schema.Fields{
+		"resource_uri": schema.String(),
+
+		"id":   schema.ForceInt(),
+		"name": schema.String(),
+		"uuid": schema.OneOf(schema.Nil(""), schema.String()),
+
+		"size":           schema.ForceUint(),
+		"used_size":      schema.ForceUint(),
+		"available_size": schema.ForceUint(),
+
+		"devices":         schema.List(schema.StringMap(schema.Any())),
+		"logical_volumes": schema.List(schema.StringMap(schema.Any())),
+	}
+	defaults := schema.Defaults{
+		"uuid":            "",
+		"logical_volumes": []interface{}{},
+	}
+	checker := schema.FieldMap(fields, defaults)
+	coerced, err := checker.Coerce(source, nil)
+	if err != nil {
+		return nil, WrapWithDeserializationError(err, "volume group 2.0 schema check failed")
+	}
+	valid := coerced.(map[string]interface{})
+	// From here we know that the map returned from the schema coercion
+	// contains fields of the right type.
+
+	devices, err := readStorageDeviceList(valid["devices"].([]interface{}))
+	if err != nil {
+		return nil, errors.Annotate(err, "devices")
+	}
+	logicalVolumes, err := readBlockDeviceList(valid["logical_volumes"].([]interface{}), blockdevice_2_0)
+	if err != nil {
+		return nil, errors.Annotate(err, "logical volumes")
+	}
+
+	uuid, _ := valid["uuid"].(string)
+	result := &volumeGroup{
+		resourceURI: valid["resource_uri"].(string),
+
+		id:   valid["id"].(int),
+		name: valid["name"].(string),
+		uuid: uuid,
+
+		size:          valid["size"].(uint64),
+		usedSize:      valid["used_size"].(uint64),
+		availableSize: valid["available_size"].(uint64),
+
+		devices:        devices,
+		logicalVolumes: logicalVolumes,
+	}
+	return result, nil
+}
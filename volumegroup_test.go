@@ -0,0 +1,199 @@
+// Copyright 2022 Canonical Ltd.
+// Licensed under the LGPLv3, see LICENCE file for details.
+
+package gomaasapi
+
+import (
+	"net/http"
+
+	"github.com/juju/errors"
+	"github.com/juju/testing"
+	jc "github.com/juju/testing/checkers"
+	"github.com/juju/version"
+	gc "gopkg.in/check.v1"
+)
+
+type volumeGroupSuite struct {
+	testing.LoggingCleanupSuite
+}
+
+var _ = gc.Suite(&volumeGroupSuite{})
+
+func (*volumeGroupSuite) TestReadVolumeGroupsBadSchema(c *gc.C) {
+	_, err := readVolumeGroups(twoDotOh, "wat?")
+	c.Check(err, jc.Satisfies, IsDeserializationError)
+	c.Assert(err.Error(), gc.Equals, `volume group base schema check failed: expected list, got string("wat?")`)
+}
+
+func (*volumeGroupSuite) TestReadVolumeGroups(c *gc.C) {
+	volumeGroups, err := readVolumeGroups(twoDotOh, parseJSON(c, volumeGroupsResponse))
+	c.Assert(err, jc.ErrorIsNil)
+	c.Assert(volumeGroups, gc.HasLen, 1)
+	volumeGroup := volumeGroups[0]
+
+	c.Check(volumeGroup.ID(), gc.Equals, 12)
+	c.Check(volumeGroup.Name(), gc.Equals, "vgroot")
+	c.Check(volumeGroup.UUID(), gc.Equals, "c4fa3f3c-5a6f-4d31-9d2e-3d1a7b7e0f61")
+	c.Check(volumeGroup.Size(), gc.Equals, uint64(8577351680))
+	c.Check(volumeGroup.UsedSize(), gc.Equals, uint64(4294967296))
+	c.Check(volumeGroup.AvailableSize(), gc.Equals, uint64(4282384384))
+
+	devices := volumeGroup.Devices()
+	c.Assert(devices, gc.HasLen, 1)
+	c.Check(devices[0].Type(), gc.Equals, "partition")
+	c.Check(devices[0].ID(), gc.Equals, 101)
+
+	volumes := volumeGroup.LogicalVolumes()
+	c.Assert(volumes, gc.HasLen, 1)
+	c.Check(volumes[0].Name(), gc.Equals, "vgroot-lvroot")
+	c.Check(volumes[0].Size(), gc.Equals, uint64(4294967296))
+}
+
+func (*volumeGroupSuite) TestLowVersion(c *gc.C) {
+	_, err := readVolumeGroups(version.MustParse("1.9.0"), parseJSON(c, volumeGroupsResponse))
+	c.Assert(err, jc.Satisfies, IsUnsupportedVersionError)
+}
+
+func (*volumeGroupSuite) TestHighVersion(c *gc.C) {
+	volumeGroups, err := readVolumeGroups(version.MustParse("2.1.9"), parseJSON(c, volumeGroupsResponse))
+	c.Assert(err, jc.ErrorIsNil)
+	c.Assert(volumeGroups, gc.HasLen, 1)
+}
+
+func (s *volumeGroupSuite) getServerAndVolumeGroup(c *gc.C) (*SimpleTestServer, *volumeGroup) {
+	server, ctrl := createTestServerController(c, s)
+	volumeGroup, err := readVolumeGroup(twoDotOh, parseJSON(c, volumeGroupResponse))
+	c.Assert(err, jc.ErrorIsNil)
+	volumeGroup.controller = ctrl.(*controller)
+	server.ResetRequests()
+	return server, volumeGroup
+}
+
+func (s *volumeGroupSuite) TestCreateLogicalVolume(c *gc.C) {
+	server, volumeGroup := s.getServerAndVolumeGroup(c)
+	response := updateJSONMap(c, logicalVolumeResponse, map[string]interface{}{
+		"id":   31,
+		"name": "vgroot-lvdata",
+	})
+	server.AddPostResponse("/MAAS/api/2.0/nodes/4y3ha3/volume-group/12/?op=create_logical_volume", http.StatusOK, response)
+	volume, err := volumeGroup.CreateLogicalVolume(CreateLogicalVolumeArgs{
+		Name: "lvdata",
+		Size: 1073741824,
+	})
+	c.Assert(err, jc.ErrorIsNil)
+	c.Check(volume.Name(), gc.Equals, "vgroot-lvdata")
+	c.Check(volumeGroup.LogicalVolumes(), gc.HasLen, 2)
+
+	form := server.LastRequest().PostForm
+	c.Check(form.Get("name"), gc.Equals, "lvdata")
+	c.Check(form.Get("size"), gc.Equals, "1073741824")
+}
+
+func (s *volumeGroupSuite) TestCreateLogicalVolumeValidates(c *gc.C) {
+	_, volumeGroup := s.getServerAndVolumeGroup(c)
+	_, err := volumeGroup.CreateLogicalVolume(CreateLogicalVolumeArgs{})
+	c.Assert(err, jc.Satisfies, errors.IsNotValid)
+	c.Assert(err.Error(), gc.Equals, "missing Name not valid")
+}
+
+func (s *volumeGroupSuite) TestCreateLogicalVolumeBadRequest(c *gc.C) {
+	server, volumeGroup := s.getServerAndVolumeGroup(c)
+	server.AddPostResponse("/MAAS/api/2.0/nodes/4y3ha3/volume-group/12/?op=create_logical_volume", http.StatusBadRequest, "too big")
+	_, err := volumeGroup.CreateLogicalVolume(CreateLogicalVolumeArgs{Name: "lvdata"})
+	c.Assert(err, jc.Satisfies, IsBadRequestError)
+	c.Check(volumeGroup.LogicalVolumes(), gc.HasLen, 1)
+}
+
+func (s *volumeGroupSuite) TestDeleteLogicalVolume(c *gc.C) {
+	server, volumeGroup := s.getServerAndVolumeGroup(c)
+	server.AddPostResponse("/MAAS/api/2.0/nodes/4y3ha3/volume-group/12/?op=delete_logical_volume", http.StatusNoContent, "")
+	err := volumeGroup.DeleteLogicalVolume(volumeGroup.LogicalVolumes()[0])
+	c.Assert(err, jc.ErrorIsNil)
+	c.Check(volumeGroup.LogicalVolumes(), gc.HasLen, 0)
+	c.Check(server.LastRequest().PostForm.Get("id"), gc.Equals, "30")
+}
+
+func (s *volumeGroupSuite) TestDeleteLogicalVolumeValidates(c *gc.C) {
+	server, volumeGroup := s.getServerAndVolumeGroup(c)
+	err := volumeGroup.DeleteLogicalVolume(&blockdevice{id: 99, name: "sdz"})
+	c.Check(err, jc.Satisfies, errors.IsNotValid)
+	c.Check(err.Error(), gc.Equals, `logical volume "sdz" not in volume group "vgroot" not valid`)
+	err = volumeGroup.DeleteLogicalVolume(nil)
+	c.Check(err, jc.Satisfies, errors.IsNotValid)
+	c.Check(server.RequestCount(), gc.Equals, 0)
+}
+
+func (s *volumeGroupSuite) TestDelete(c *gc.C) {
+	server, volumeGroup := s.getServerAndVolumeGroup(c)
+	server.AddDeleteResponse("/MAAS/api/2.0/nodes/4y3ha3/volume-group/12/", http.StatusNoContent, "")
+	err := volumeGroup.Delete()
+	c.Assert(err, jc.ErrorIsNil)
+}
+
+func (s *volumeGroupSuite) TestDeleteForbidden(c *gc.C) {
+	server, volumeGroup := s.getServerAndVolumeGroup(c)
+	server.AddDeleteResponse("/MAAS/api/2.0/nodes/4y3ha3/volume-group/12/", http.StatusForbidden, "")
+	err := volumeGroup.Delete()
+	c.Assert(err, jc.Satisfies, IsPermissionError)
+}
+
+const logicalVolumeResponse = `
+{
+    "path": "/dev/disk/by-dname/vgroot-lvroot",
+    "name": "vgroot-lvroot",
+    "used_for": "Unused",
+    "partitions": [],
+    "filesystem": null,
+    "id_path": null,
+    "resource_uri": "/MAAS/api/2.0/nodes/4y3ha3/blockdevices/30/",
+    "id": 30,
+    "serial": null,
+    "type": "virtual",
+    "block_size": 4096,
+    "used_size": 0,
+    "available_size": 4294967296,
+    "partition_table_type": null,
+    "uuid": "0a0d4bd5-6e32-4b8e-9a23-a7ee2b5d0f10",
+    "size": 4294967296,
+    "model": null,
+    "tags": []
+}
+`
+
+const volumeGroupResponse = `
+{
+    "id": 12,
+    "system_id": "4y3ha3",
+    "name": "vgroot",
+    "uuid": "c4fa3f3c-5a6f-4d31-9d2e-3d1a7b7e0f61",
+    "size": 8577351680,
+    "human_size": "8.6 GB",
+    "used_size": 4294967296,
+    "human_used_size": "4.3 GB",
+    "available_size": 4282384384,
+    "human_available_size": "4.3 GB",
+    "resource_uri": "/MAAS/api/2.0/nodes/4y3ha3/volume-group/12/",
+    "devices": [
+        {
+            "bootable": false,
+            "id": 101,
+            "path": "/dev/disk/by-dname/sdb-part1",
+            "filesystem": {
+                "fstype": "lvm-pv",
+                "mount_point": null,
+                "label": null,
+                "mount_options": null,
+                "uuid": "fcd7745e-f1b5-4f5d-9575-9b0bb796b753"
+            },
+            "type": "partition",
+            "resource_uri": "/MAAS/api/2.0/nodes/4y3ha3/blockdevices/98/partition/101",
+            "uuid": "6199b7c9-b66f-40f6-a238-a938a58a0ae0",
+            "used_for": "LVM volume for vgroot",
+            "size": 8581545984
+        }
+    ],
+    "logical_volumes": [` + logicalVolumeResponse + `]
+}
+`
+
+const volumeGroupsResponse = "[" + volumeGroupResponse + "]"
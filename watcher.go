@@ -0,0 +1,587 @@
+// Copyright 2022 Canonical Ltd.
+// Licensed under the LGPLv3, see LICENCE file for details.
+
+package gomaasapi
+
+import (
+	"bufio"
+	"context"
+	"crypto/tls"
+	"encoding/base64"
+	"fmt"
+	"net"
+	"net/http"
+	"net/url"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/juju/collections/set"
+	"github.com/juju/errors"
+	"golang.org/x/net/websocket"
+)
+
+// WatchKind is a kind of MAAS objects whose changes a Watcher reports.
+type WatchKind string
+
+// The kinds of objects that can be watched. Changes to the interfaces of a
+// machine are reported as updates of the machine.
+const (
+	WatchMachines WatchKind = "machine"
+	WatchDevices  WatchKind = "device"
+	WatchSubnets  WatchKind = "subnet"
+	WatchVLANs    WatchKind = "vlan"
+	WatchFabrics  WatchKind = "fabric"
+	WatchSpaces   WatchKind = "space"
+	WatchZones    WatchKind = "zone"
+)
+
+var watchKinds = set.NewStrings(
+	string(WatchMachines),
+	string(WatchDevices),
+	string(WatchSubnets),
+	string(WatchVLANs),
+	string(WatchFabrics),
+	string(WatchSpaces),
+	string(WatchZones),
+)
+
+// ChangeAction is the change made to an object reported by a Watcher.
+type ChangeAction string
+
+const (
+	ChangeCreated ChangeAction = "create"
+	ChangeUpdated ChangeAction = "update"
+	ChangeDeleted ChangeAction = "delete"
+
+	// ChangeResync is reported, without a Kind, after the watcher lost its
+	// connection to MAAS and reconnected. Changes may have been missed in
+	// the meantime, so the watched objects should be read again.
+	ChangeResync ChangeAction = "resync"
+)
+
+// ChangeEvent is a change to a MAAS object reported by a Watcher.
+type ChangeEvent struct {
+	Kind   WatchKind
+	Action ChangeAction
+	// ID is the system ID of machines and devices, and the ID of the other
+	// objects.
+	ID string
+	// Data is the object as sent by MAAS, in the format of its websocket
+	// API, which differs from the one of the REST API. It is nil when the
+	// object is deleted.
+	Data map[string]interface{}
+}
+
+// WatchArgs is an argument struct for Controller.Watch.
+type WatchArgs struct {
+	// Kinds are the kinds of objects to watch. At least one is required.
+	Kinds []WatchKind
+	// SessionID and CSRFToken, if set, authenticate the websocket with the
+	// session of a MAAS user instead of the API key, for the MAAS versions
+	// whose websocket API doesn't accept API keys.
+	SessionID string
+	CSRFToken string
+	// ReconnectDelay is the wait before connecting again after the
+	// connection to MAAS was lost. It defaults to 5 seconds.
+	ReconnectDelay time.Duration
+	// BufferSize is the number of changes buffered when they aren't
+	// received fast enough. It defaults to 100. Once the buffer is full,
+	// no more messages are read from MAAS until changes are received.
+	BufferSize int
+}
+
+// Validate ensures that the kinds are known and that the other values are
+// in range.
+func (a WatchArgs) Validate() error {
+	if len(a.Kinds) == 0 {
+		return errors.NotValidf("missing Kinds")
+	}
+	for _, kind := range a.Kinds {
+		if !watchKinds.Contains(string(kind)) {
+			return errors.NotValidf("Kind %q", kind)
+		}
+	}
+	if a.ReconnectDelay < 0 {
+		return errors.NotValidf("negative ReconnectDelay")
+	}
+	if a.BufferSize < 0 {
+		return errors.NotValidf("negative BufferSize")
+	}
+	return nil
+}
+
+// The types of the messages of the websocket API.
+const (
+	wsRequest      = 0
+	wsResponse     = 1
+	wsNotification = 2
+
+	wsResponseError = 1
+)
+
+type wsMessage struct {
+	Type      int                    `json:"type"`
+	RequestID int                    `json:"request_id,omitempty"`
+	Method    string                 `json:"method,omitempty"`
+	Params    map[string]interface{} `json:"params,omitempty"`
+
+	// Responses.
+	ResultType int         `json:"rtype,omitempty"`
+	Error      interface{} `json:"error,omitempty"`
+
+	// Notifications.
+	Name   string      `json:"name,omitempty"`
+	Action string      `json:"action,omitempty"`
+	Data   interface{} `json:"data,omitempty"`
+}
+
+// watchDialTimeout limits the time taken to connect to MAAS and to
+// subscribe to the changes.
+const watchDialTimeout = 30 * time.Second
+
+type watcher struct {
+	client *Client
+	args   WatchArgs
+	kinds  set.Strings
+
+	changes chan ChangeEvent
+	done    chan struct{}
+	stop    context.CancelFunc
+
+	mu   sync.Mutex
+	conn *websocket.Conn
+}
+
+// Watch implements Controller.
+func (c *controller) Watch(ctx context.Context, args WatchArgs) (Watcher, error) {
+	if err := args.Validate(); err != nil {
+		return nil, errors.Trace(err)
+	}
+	if args.ReconnectDelay == 0 {
+		args.ReconnectDelay = 5 * time.Second
+	}
+	if args.BufferSize == 0 {
+		args.BufferSize = 100
+	}
+	w := &watcher{
+		client:  c.client,
+		args:    args,
+		kinds:   set.NewStrings(),
+		changes: make(chan ChangeEvent, args.BufferSize),
+		done:    make(chan struct{}),
+	}
+	for _, kind := range args.Kinds {
+		w.kinds.Add(string(kind))
+	}
+	// The first connection is made before returning, so that a MAAS that
+	// can't be watched is reported straight away.
+	conn, pending, err := w.connect(ctx)
+	if err != nil {
+		return nil, errors.Trace(err)
+	}
+	ctx, w.stop = context.WithCancel(ctx)
+	go w.loop(ctx, conn, pending)
+	return w, nil
+}
+
+// Changes implements Watcher.
+func (w *watcher) Changes() <-chan ChangeEvent {
+	return w.changes
+}
+
+// Close implements Watcher.
+func (w *watcher) Close() error {
+	w.stop()
+	<-w.done
+	return nil
+}
+
+func (w *watcher) loop(ctx context.Context, conn *websocket.Conn, pending []ChangeEvent) {
+	defer close(w.done)
+	defer close(w.changes)
+	go func() {
+		// Closing the connection unblocks the pending read.
+		<-ctx.Done()
+		w.mu.Lock()
+		defer w.mu.Unlock()
+		if w.conn != nil {
+			w.conn.Close()
+		}
+	}()
+	for {
+		if !w.setConn(ctx, conn) {
+			conn.Close()
+			return
+		}
+		err := w.deliver(ctx, pending)
+		if err == nil {
+			err = w.read(ctx, conn)
+		}
+		conn.Close()
+		if ctx.Err() != nil {
+			return
+		}
+		logger.Debugf("watcher lost its connection to MAAS: %v", err)
+		if conn, pending = w.reconnect(ctx); conn == nil {
+			return
+		}
+		pending = append([]ChangeEvent{{Action: ChangeResync}}, pending...)
+	}
+}
+
+// setConn records the connection closed when the context is done, and
+// returns false if it already is.
+func (w *watcher) setConn(ctx context.Context, conn *websocket.Conn) bool {
+	w.mu.Lock()
+	defer w.mu.Unlock()
+	if ctx.Err() != nil {
+		return false
+	}
+	w.conn = conn
+	return true
+}
+
+// reconnect connects again to MAAS until it succeeds, or the context is
+// done, in which case it returns a nil connection.
+func (w *watcher) reconnect(ctx context.Context) (*websocket.Conn, []ChangeEvent) {
+	for {
+		select {
+		case <-ctx.Done():
+			return nil, nil
+		case <-time.After(w.args.ReconnectDelay):
+		}
+		conn, pending, err := w.connect(ctx)
+		if err == nil {
+			return conn, pending
+		}
+		logger.Debugf("watcher failed to reconnect to MAAS: %v", err)
+	}
+}
+
+func (w *watcher) deliver(ctx context.Context, events []ChangeEvent) error {
+	for _, event := range events {
+		select {
+		case w.changes <- event:
+		case <-ctx.Done():
+			return ctx.Err()
+		}
+	}
+	return nil
+}
+
+// read delivers the changes sent by MAAS until the connection fails.
+func (w *watcher) read(ctx context.Context, conn *websocket.Conn) error {
+	for {
+		var message wsMessage
+		if err := websocket.JSON.Receive(conn, &message); err != nil {
+			return errors.Trace(err)
+		}
+		if event, ok := w.event(message); ok {
+			if err := w.deliver(ctx, []ChangeEvent{event}); err != nil {
+				return errors.Trace(err)
+			}
+		}
+	}
+}
+
+// event returns the change reported by a message, and false if the message
+// isn't a notification of a watched kind of objects.
+func (w *watcher) event(message wsMessage) (ChangeEvent, bool) {
+	if message.Type != wsNotification || !w.kinds.Contains(message.Name) {
+		return ChangeEvent{}, false
+	}
+	event := ChangeEvent{
+		Kind:   WatchKind(message.Name),
+		Action: ChangeAction(message.Action),
+	}
+	switch data := message.Data.(type) {
+	case map[string]interface{}:
+		if event.Action != ChangeDeleted {
+			event.Data = data
+		}
+		if systemID, ok := data["system_id"]; ok {
+			event.ID = wsID(systemID)
+		} else {
+			event.ID = wsID(data["id"])
+		}
+	default:
+		// Deletions only send the ID of the object.
+		event.ID = wsID(data)
+	}
+	return event, true
+}
+
+func wsID(value interface{}) string {
+	switch value := value.(type) {
+	case nil:
+		return ""
+	case string:
+		return value
+	case float64:
+		return strconv.FormatFloat(value, 'f', -1, 64)
+	default:
+		return fmt.Sprint(value)
+	}
+}
+
+// connect opens a websocket to MAAS and subscribes to the changes of the
+// watched kinds. It returns the changes received while subscribing, and
+// gives up when the context is done.
+func (w *watcher) connect(ctx context.Context) (*websocket.Conn, []ChangeEvent, error) {
+	config, err := w.config()
+	if err != nil {
+		return nil, nil, errors.Trace(err)
+	}
+	transport, err := w.transport()
+	if err != nil {
+		return nil, nil, errors.Trace(err)
+	}
+	ctx, cancel := context.WithTimeout(ctx, watchDialTimeout)
+	defer cancel()
+	proxy, err := websocketProxy(transport, config.Location)
+	if err != nil {
+		return nil, nil, errors.Trace(err)
+	}
+	address := websocketAddress(config.Location)
+	if proxy != nil {
+		address = websocketAddress(proxy)
+	}
+	dial := transport.DialContext
+	if dial == nil {
+		dial = (&net.Dialer{}).DialContext
+	}
+	netConn, err := dial(ctx, "tcp", address)
+	if err != nil {
+		if ctx.Err() != nil {
+			return nil, nil, errors.Trace(ctx.Err())
+		}
+		return nil, nil, NewUnexpectedError(err)
+	}
+	// The connection is closed when the context is done before the
+	// subscription completes, which unblocks its reads and writes.
+	subscribed := make(chan struct{})
+	watching := make(chan struct{})
+	go func() {
+		defer close(watching)
+		select {
+		case <-ctx.Done():
+			netConn.Close()
+		case <-subscribed:
+		}
+	}()
+	conn, pending, err := w.open(ctx, netConn, transport, proxy, config)
+	close(subscribed)
+	<-watching
+	if ctx.Err() != nil {
+		if conn != nil {
+			conn.Close()
+		}
+		netConn.Close()
+		return nil, nil, errors.Trace(ctx.Err())
+	}
+	if err != nil {
+		netConn.Close()
+		return nil, nil, errors.Trace(err)
+	}
+	return conn, pending, nil
+}
+
+// open sets up the websocket over the connection to MAAS, or to its proxy,
+// and subscribes to the changes of the watched kinds.
+func (w *watcher) open(ctx context.Context, netConn net.Conn, transport *http.Transport, proxy *url.URL, config *websocket.Config) (*websocket.Conn, []ChangeEvent, error) {
+	if proxy != nil {
+		if err := connectProxy(netConn, proxy, websocketAddress(config.Location)); err != nil {
+			return nil, nil, NewUnexpectedError(err)
+		}
+	}
+	if config.Location.Scheme == "wss" {
+		var tlsConfig *tls.Config
+		if transport.TLSClientConfig != nil {
+			tlsConfig = transport.TLSClientConfig.Clone()
+		} else {
+			tlsConfig = &tls.Config{}
+		}
+		if tlsConfig.ServerName == "" {
+			tlsConfig.ServerName = config.Location.Hostname()
+		}
+		tlsConn := tls.Client(netConn, tlsConfig)
+		if err := tlsConn.HandshakeContext(ctx); err != nil {
+			return nil, nil, NewUnexpectedError(err)
+		}
+		netConn = tlsConn
+	}
+	conn, err := websocket.NewClient(config, netConn)
+	if err != nil {
+		return nil, nil, NewUnexpectedError(err)
+	}
+	pending, err := w.subscribe(conn)
+	if err != nil {
+		conn.Close()
+		return nil, nil, errors.Trace(err)
+	}
+	return conn, pending, nil
+}
+
+// transport returns the transport of the client, whose dialer, proxy and
+// TLS configuration are used for the websocket. Other round trippers can't
+// open websockets, so watching with them isn't supported.
+func (w *watcher) transport() (*http.Transport, error) {
+	roundTripper := w.client.httpClient().Transport
+	if roundTripper == nil {
+		roundTripper = http.DefaultTransport
+	}
+	transport, ok := roundTripper.(*http.Transport)
+	if !ok {
+		return nil, errors.NotSupportedf("watching with a %T transport", roundTripper)
+	}
+	return transport, nil
+}
+
+// websocketProxy returns the proxy of the transport for the websocket
+// location, if any. Only HTTP proxies are supported.
+func websocketProxy(transport *http.Transport, location *url.URL) (*url.URL, error) {
+	if transport.Proxy == nil {
+		return nil, nil
+	}
+	// The proxy of the transport is chosen for the HTTP URL of the
+	// websocket.
+	target := *location
+	target.Scheme = strings.Replace(target.Scheme, "ws", "http", 1)
+	proxy, err := transport.Proxy(&http.Request{Method: "GET", URL: &target, Header: make(http.Header)})
+	if err != nil || proxy == nil {
+		return nil, errors.Trace(err)
+	}
+	if proxy.Scheme != "http" {
+		return nil, errors.NotSupportedf("watching through a %s proxy", proxy.Scheme)
+	}
+	return proxy, nil
+}
+
+// websocketAddress returns the host and port of the ws, wss or http URL.
+func websocketAddress(location *url.URL) string {
+	port := location.Port()
+	if port == "" {
+		port = "80"
+		if location.Scheme == "wss" {
+			port = "443"
+		}
+	}
+	return net.JoinHostPort(location.Hostname(), port)
+}
+
+// connectProxy asks the HTTP proxy at the other end of the connection to
+// tunnel it to the address.
+func connectProxy(conn net.Conn, proxy *url.URL, address string) error {
+	request := &http.Request{
+		Method: "CONNECT",
+		URL:    &url.URL{Opaque: address},
+		Host:   address,
+		Header: make(http.Header),
+	}
+	if proxy.User != nil {
+		password, _ := proxy.User.Password()
+		credentials := base64.StdEncoding.EncodeToString([]byte(proxy.User.Username() + ":" + password))
+		request.Header.Set("Proxy-Authorization", "Basic "+credentials)
+	}
+	if err := request.Write(conn); err != nil {
+		return errors.Trace(err)
+	}
+	response, err := http.ReadResponse(bufio.NewReader(conn), request)
+	if err != nil {
+		return errors.Trace(err)
+	}
+	response.Body.Close()
+	if response.StatusCode != http.StatusOK {
+		return errors.Errorf("proxy %s refused to connect to %s: %s", proxy.Host, address, response.Status)
+	}
+	return nil
+}
+
+// subscribe lists the objects of each watched kind, which MAAS requires
+// before it sends their changes.
+func (w *watcher) subscribe(conn *websocket.Conn) ([]ChangeEvent, error) {
+	if err := conn.SetDeadline(time.Now().Add(watchDialTimeout)); err != nil {
+		return nil, errors.Trace(err)
+	}
+	methods := make(map[int]string)
+	for i, kind := range w.args.Kinds {
+		request := wsMessage{
+			Type:      wsRequest,
+			RequestID: i + 1,
+			Method:    string(kind) + ".list",
+			Params:    map[string]interface{}{},
+		}
+		if err := websocket.JSON.Send(conn, request); err != nil {
+			return nil, NewUnexpectedError(err)
+		}
+		methods[request.RequestID] = request.Method
+	}
+	var pending []ChangeEvent
+	for len(methods) != 0 {
+		var message wsMessage
+		if err := websocket.JSON.Receive(conn, &message); err != nil {
+			return nil, NewUnexpectedError(err)
+		}
+		if event, ok := w.event(message); ok {
+			pending = append(pending, event)
+			continue
+		}
+		method, ok := methods[message.RequestID]
+		if message.Type != wsResponse || !ok {
+			continue
+		}
+		if message.ResultType == wsResponseError {
+			return nil, errors.Errorf("%s failed: %v", method, message.Error)
+		}
+		delete(methods, message.RequestID)
+	}
+	if err := conn.SetDeadline(time.Time{}); err != nil {
+		return nil, errors.Trace(err)
+	}
+	return pending, nil
+}
+
+// config returns the websocket configuration of the MAAS of the client,
+// whose websocket API is at the ws path of its base URL.
+func (w *watcher) config() (*websocket.Config, error) {
+	base, _, _ := SplitVersionedURL(w.client.APIURL.String())
+	origin, err := url.Parse(EnsureTrailingSlash(base))
+	if err != nil {
+		return nil, errors.Trace(err)
+	}
+	location := origin.ResolveReference(&url.URL{Path: "ws"})
+	switch origin.Scheme {
+	case "https":
+		location.Scheme = "wss"
+	default:
+		location.Scheme = "ws"
+	}
+	header := make(http.Header)
+	if w.args.SessionID != "" {
+		cookies := []string{"sessionid=" + w.args.SessionID}
+		if w.args.CSRFToken != "" {
+			cookies = append(cookies, "csrftoken="+w.args.CSRFToken)
+		}
+		header.Set("Cookie", strings.Join(cookies, "; "))
+	} else {
+		request, err := http.NewRequest("GET", origin.String(), nil)
+		if err != nil {
+			return nil, errors.Trace(err)
+		}
+		if err := w.client.Signer.OAuthSign(request); err != nil {
+			return nil, errors.Trace(err)
+		}
+		header = request.Header
+	}
+	if w.args.CSRFToken != "" {
+		location.RawQuery = url.Values{"csrftoken": {w.args.CSRFToken}}.Encode()
+	}
+	config, err := websocket.NewConfig(location.String(), origin.String())
+	if err != nil {
+		return nil, errors.Trace(err)
+	}
+	config.Header = header
+	return config, nil
+}
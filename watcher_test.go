@@ -0,0 +1,380 @@
+// Copyright 2022 Canonical Ltd.
+// Licensed under the LGPLv3, see LICENCE file for details.
+
+package gomaasapi
+
+import (
+	"context"
+	"fmt"
+	"io"
+	"net"
+	"net/http"
+	"net/http/httptest"
+	"net/url"
+	"strings"
+	"time"
+
+	"github.com/juju/errors"
+	"github.com/juju/testing"
+	jc "github.com/juju/testing/checkers"
+	"golang.org/x/net/websocket"
+	gc "gopkg.in/check.v1"
+)
+
+type watcherSuite struct {
+	testing.LoggingCleanupSuite
+}
+
+var _ = gc.Suite(&watcherSuite{})
+
+// wsConnection is a websocket connection accepted by a wsServer.
+type wsConnection struct {
+	request  *http.Request
+	requests chan wsMessage
+	// send sends its messages to the client, and closing it drops the
+	// connection.
+	send chan interface{}
+}
+
+// wsServer serves the REST API needed to create a controller, and a
+// websocket API answering list requests.
+type wsServer struct {
+	*httptest.Server
+	listError   string
+	connections chan *wsConnection
+}
+
+func newWSServer() *wsServer {
+	server := newUnstartedWSServer()
+	server.Start()
+	return server
+}
+
+func newUnstartedWSServer() *wsServer {
+	server := &wsServer{connections: make(chan *wsConnection, 10)}
+	mux := http.NewServeMux()
+	mux.HandleFunc("/api/2.0/users/", func(w http.ResponseWriter, r *http.Request) {
+		fmt.Fprint(w, `"captain awesome"`)
+	})
+	mux.HandleFunc("/api/2.0/version/", func(w http.ResponseWriter, r *http.Request) {
+		fmt.Fprint(w, versionResponse)
+	})
+	mux.Handle("/ws", websocket.Server{Handler: server.handle})
+	server.Server = httptest.NewUnstartedServer(mux)
+	return server
+}
+
+func (s *wsServer) handle(ws *websocket.Conn) {
+	conn := &wsConnection{
+		request:  ws.Request(),
+		requests: make(chan wsMessage, 10),
+		send:     make(chan interface{}),
+	}
+	s.connections <- conn
+	closed := make(chan struct{})
+	go func() {
+		defer close(closed)
+		for {
+			var request wsMessage
+			if err := websocket.JSON.Receive(ws, &request); err != nil {
+				return
+			}
+			conn.requests <- request
+			response := map[string]interface{}{
+				"type":       wsResponse,
+				"request_id": request.RequestID,
+				"rtype":      0,
+				"result":     []interface{}{},
+			}
+			if s.listError != "" {
+				response["rtype"] = wsResponseError
+				response["error"] = s.listError
+			}
+			if err := websocket.JSON.Send(ws, response); err != nil {
+				return
+			}
+		}
+	}()
+	for {
+		select {
+		case message, ok := <-conn.send:
+			if !ok {
+				return
+			}
+			if err := websocket.JSON.Send(ws, message); err != nil {
+				return
+			}
+		case <-closed:
+			return
+		}
+	}
+}
+
+func (s *watcherSuite) newServerAndController(c *gc.C) (*wsServer, Controller) {
+	server := newWSServer()
+	s.AddCleanup(func(*gc.C) { server.Close() })
+	maas, err := NewController(ControllerArgs{
+		BaseURL: server.URL,
+		APIKey:  "fake:as:key",
+	})
+	c.Assert(err, jc.ErrorIsNil)
+	return server, maas
+}
+
+func (s *watcherSuite) nextConnection(c *gc.C, server *wsServer) *wsConnection {
+	select {
+	case conn := <-server.connections:
+		return conn
+	case <-time.After(testing.LongWait):
+		c.Fatalf("no websocket connection")
+	}
+	panic("unreachable")
+}
+
+func (s *watcherSuite) nextChange(c *gc.C, watcher Watcher) ChangeEvent {
+	select {
+	case event, ok := <-watcher.Changes():
+		c.Assert(ok, jc.IsTrue)
+		return event
+	case <-time.After(testing.LongWait):
+		c.Fatalf("no change reported")
+	}
+	panic("unreachable")
+}
+
+func (s *watcherSuite) assertStopped(c *gc.C, watcher Watcher) {
+	select {
+	case _, ok := <-watcher.Changes():
+		c.Assert(ok, jc.IsFalse)
+	case <-time.After(testing.LongWait):
+		c.Fatalf("watcher not stopped")
+	}
+}
+
+func notification(name, action string, data interface{}) map[string]interface{} {
+	return map[string]interface{}{
+		"type":   wsNotification,
+		"name":   name,
+		"action": action,
+		"data":   data,
+	}
+}
+
+func (*watcherSuite) TestWatchArgsValidate(c *gc.C) {
+	for _, args := range []WatchArgs{
+		{},
+		{Kinds: []WatchKind{"interface"}},
+		{Kinds: []WatchKind{WatchMachines}, ReconnectDelay: -1},
+		{Kinds: []WatchKind{WatchMachines}, BufferSize: -1},
+	} {
+		c.Check(args.Validate(), jc.Satisfies, errors.IsNotValid)
+	}
+	c.Check(WatchArgs{Kinds: []WatchKind{WatchMachines, WatchSubnets}}.Validate(), jc.ErrorIsNil)
+}
+
+func (s *watcherSuite) TestWatch(c *gc.C) {
+	server, maas := s.newServerAndController(c)
+	watcher, err := maas.Watch(context.Background(), WatchArgs{
+		Kinds: []WatchKind{WatchMachines, WatchSubnets},
+	})
+	c.Assert(err, jc.ErrorIsNil)
+	conn := s.nextConnection(c, server)
+	c.Check(conn.request.URL.Path, gc.Equals, "/ws")
+	c.Check(strings.HasPrefix(conn.request.Header.Get("Authorization"), "OAuth "), jc.IsTrue)
+	c.Check((<-conn.requests).Method, gc.Equals, "machine.list")
+	c.Check((<-conn.requests).Method, gc.Equals, "subnet.list")
+
+	machine := map[string]interface{}{"system_id": "4y3ha3", "hostname": "untasted-markita"}
+	conn.send <- notification("machine", "create", machine)
+	conn.send <- notification("device", "update", map[string]interface{}{"system_id": "ignored"})
+	conn.send <- notification("subnet", "update", map[string]interface{}{"id": 3, "cidr": "10.0.0.0/24"})
+	conn.send <- notification("machine", "delete", "4y3ha3")
+	conn.send <- notification("subnet", "delete", 3)
+
+	c.Check(s.nextChange(c, watcher), jc.DeepEquals, ChangeEvent{
+		Kind: WatchMachines, Action: ChangeCreated, ID: "4y3ha3", Data: machine,
+	})
+	c.Check(s.nextChange(c, watcher), jc.DeepEquals, ChangeEvent{
+		Kind: WatchSubnets, Action: ChangeUpdated, ID: "3",
+		Data: map[string]interface{}{"id": float64(3), "cidr": "10.0.0.0/24"},
+	})
+	c.Check(s.nextChange(c, watcher), jc.DeepEquals, ChangeEvent{
+		Kind: WatchMachines, Action: ChangeDeleted, ID: "4y3ha3",
+	})
+	c.Check(s.nextChange(c, watcher), jc.DeepEquals, ChangeEvent{
+		Kind: WatchSubnets, Action: ChangeDeleted, ID: "3",
+	})
+
+	c.Assert(watcher.Close(), jc.ErrorIsNil)
+	s.assertStopped(c, watcher)
+}
+
+func (s *watcherSuite) TestWatchSession(c *gc.C) {
+	server, maas := s.newServerAndController(c)
+	watcher, err := maas.Watch(context.Background(), WatchArgs{
+		Kinds:     []WatchKind{WatchMachines},
+		SessionID: "session",
+		CSRFToken: "token",
+	})
+	c.Assert(err, jc.ErrorIsNil)
+	defer watcher.Close()
+	conn := s.nextConnection(c, server)
+	c.Check(conn.request.Header.Get("Authorization"), gc.Equals, "")
+	c.Check(conn.request.Header.Get("Cookie"), gc.Equals, "sessionid=session; csrftoken=token")
+	c.Check(conn.request.URL.Query().Get("csrftoken"), gc.Equals, "token")
+}
+
+func (s *watcherSuite) TestWatchListError(c *gc.C) {
+	server, maas := s.newServerAndController(c)
+	server.listError = "not allowed"
+	_, err := maas.Watch(context.Background(), WatchArgs{Kinds: []WatchKind{WatchMachines}})
+	c.Assert(err, gc.ErrorMatches, "machine.list failed: not allowed")
+}
+
+func (s *watcherSuite) TestWatchNoWebsocket(c *gc.C) {
+	server := NewSimpleServer()
+	server.AddGetResponse("/api/2.0/users/?op=whoami", http.StatusOK, `"captain awesome"`)
+	server.AddGetResponse("/api/2.0/version/", http.StatusOK, versionResponse)
+	server.Start()
+	defer server.Close()
+	maas, err := NewController(ControllerArgs{BaseURL: server.URL, APIKey: "fake:as:key"})
+	c.Assert(err, jc.ErrorIsNil)
+
+	_, err = maas.Watch(context.Background(), WatchArgs{Kinds: []WatchKind{WatchMachines}})
+	c.Assert(err, jc.Satisfies, IsUnexpectedError)
+}
+
+func (s *watcherSuite) TestWatchReconnects(c *gc.C) {
+	server, maas := s.newServerAndController(c)
+	watcher, err := maas.Watch(context.Background(), WatchArgs{
+		Kinds:          []WatchKind{WatchMachines},
+		ReconnectDelay: time.Millisecond,
+	})
+	c.Assert(err, jc.ErrorIsNil)
+	defer watcher.Close()
+	close(s.nextConnection(c, server).send)
+
+	conn := s.nextConnection(c, server)
+	c.Check((<-conn.requests).Method, gc.Equals, "machine.list")
+	conn.send <- notification("machine", "update", map[string]interface{}{"system_id": "4y3ha3"})
+	c.Check(s.nextChange(c, watcher), jc.DeepEquals, ChangeEvent{Action: ChangeResync})
+	c.Check(s.nextChange(c, watcher).ID, gc.Equals, "4y3ha3")
+}
+
+func (s *watcherSuite) TestWatchContextDone(c *gc.C) {
+	server, maas := s.newServerAndController(c)
+	ctx, cancel := context.WithCancel(context.Background())
+	watcher, err := maas.Watch(ctx, WatchArgs{Kinds: []WatchKind{WatchMachines}})
+	c.Assert(err, jc.ErrorIsNil)
+	s.nextConnection(c, server)
+
+	cancel()
+	s.assertStopped(c, watcher)
+	c.Assert(watcher.Close(), jc.ErrorIsNil)
+}
+
+func (s *watcherSuite) TestWatchDialContextDone(c *gc.C) {
+	// The server accepts the websocket connection, but never completes
+	// the handshake.
+	mux := http.NewServeMux()
+	mux.HandleFunc("/api/2.0/users/", func(w http.ResponseWriter, r *http.Request) {
+		fmt.Fprint(w, `"captain awesome"`)
+	})
+	mux.HandleFunc("/api/2.0/version/", func(w http.ResponseWriter, r *http.Request) {
+		fmt.Fprint(w, versionResponse)
+	})
+	mux.HandleFunc("/ws", func(w http.ResponseWriter, r *http.Request) {
+		<-r.Context().Done()
+	})
+	server := httptest.NewServer(mux)
+	defer server.Close()
+	maas, err := NewController(ControllerArgs{BaseURL: server.URL, APIKey: "fake:as:key"})
+	c.Assert(err, jc.ErrorIsNil)
+
+	ctx, cancel := context.WithTimeout(context.Background(), 50*time.Millisecond)
+	defer cancel()
+	_, err = maas.Watch(ctx, WatchArgs{Kinds: []WatchKind{WatchMachines}})
+	c.Assert(errors.Cause(err), gc.Equals, context.DeadlineExceeded)
+}
+
+func (s *watcherSuite) TestWatchTLS(c *gc.C) {
+	server := newUnstartedWSServer()
+	server.StartTLS()
+	defer server.Close()
+	maas, err := NewController(ControllerArgs{
+		BaseURL:   server.URL,
+		APIKey:    "fake:as:key",
+		Transport: server.Client().Transport,
+	})
+	c.Assert(err, jc.ErrorIsNil)
+
+	watcher, err := maas.Watch(context.Background(), WatchArgs{Kinds: []WatchKind{WatchMachines}})
+	c.Assert(err, jc.ErrorIsNil)
+	defer watcher.Close()
+	c.Check((<-s.nextConnection(c, server).requests).Method, gc.Equals, "machine.list")
+}
+
+func (s *watcherSuite) TestWatchProxy(c *gc.C) {
+	server, _ := s.newServerAndController(c)
+	var tunnels []string
+	var authorizations []string
+	proxy := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		c.Check(r.Method, gc.Equals, "CONNECT")
+		tunnels = append(tunnels, r.Host)
+		authorizations = append(authorizations, r.Header.Get("Proxy-Authorization"))
+		target, err := net.Dial("tcp", r.Host)
+		if !c.Check(err, jc.ErrorIsNil) {
+			return
+		}
+		defer target.Close()
+		conn, _, err := w.(http.Hijacker).Hijack()
+		if !c.Check(err, jc.ErrorIsNil) {
+			return
+		}
+		defer conn.Close()
+		fmt.Fprint(conn, "HTTP/1.1 200 OK\r\n\r\n")
+		go io.Copy(target, conn)
+		io.Copy(conn, target)
+	}))
+	defer proxy.Close()
+	proxyURL, err := url.Parse(proxy.URL)
+	c.Assert(err, jc.ErrorIsNil)
+	proxyURL.User = url.UserPassword("user", "secret")
+	maas, err := NewController(ControllerArgs{
+		BaseURL: server.URL,
+		APIKey:  "fake:as:key",
+		Transport: &http.Transport{
+			// Only the websocket goes through the proxy.
+			Proxy: func(request *http.Request) (*url.URL, error) {
+				if request.URL.Path == "/ws" {
+					return proxyURL, nil
+				}
+				return nil, nil
+			},
+		},
+	})
+	c.Assert(err, jc.ErrorIsNil)
+
+	watcher, err := maas.Watch(context.Background(), WatchArgs{Kinds: []WatchKind{WatchMachines}})
+	c.Assert(err, jc.ErrorIsNil)
+	c.Check((<-s.nextConnection(c, server).requests).Method, gc.Equals, "machine.list")
+	c.Assert(watcher.Close(), jc.ErrorIsNil)
+	c.Check(tunnels, jc.DeepEquals, []string{strings.TrimPrefix(server.URL, "http://")})
+	// user:secret
+	c.Check(authorizations, jc.DeepEquals, []string{"Basic dXNlcjpzZWNyZXQ="})
+}
+
+func (s *watcherSuite) TestWatchUnsupportedTransport(c *gc.C) {
+	server := newWSServer()
+	defer server.Close()
+	maas, err := NewController(ControllerArgs{
+		BaseURL:   server.URL,
+		APIKey:    "fake:as:key",
+		Transport: &recordingTransport{},
+	})
+	c.Assert(err, jc.ErrorIsNil)
+
+	_, err = maas.Watch(context.Background(), WatchArgs{Kinds: []WatchKind{WatchMachines}})
+	c.Assert(err, jc.Satisfies, errors.IsNotSupported)
+	c.Assert(err, gc.ErrorMatches, `watching with a \*gomaasapi.recordingTransport transport not supported`)
+}
@@ -4,21 +4,35 @@
 package gomaasapi
 
 import (
+	"net/http"
+
 	"github.com/juju/errors"
 	"github.com/juju/schema"
 	"github.com/juju/version"
 )
 
 type zone struct {
-	// Add the controller in when we need to do things with the zone.
-	// controller Controller
+	controller *controller
 
 	resourceURI string
 
+	id          int
 	name        string
 	description string
 }
 
+func (z *zone) updateFrom(other *zone) {
+	z.resourceURI = other.resourceURI
+	z.id = other.id
+	z.name = other.name
+	z.description = other.description
+}
+
+// ID implements Zone.
+func (z *zone) ID() int {
+	return z.id
+}
+
 // Name implements Zone.
 func (z *zone) Name() string {
 	return z.name
@@ -29,6 +43,76 @@ func (z *zone) Description() string {
 	return z.description
 }
 
+// UpdateZoneArgs is an argument struct for Zone.Update. Only the fields
+// that are set are changed.
+type UpdateZoneArgs struct {
+	Name        string
+	Description string
+}
+
+// Update implements Zone.
+func (z *zone) Update(args UpdateZoneArgs) error {
+	params := NewURLParams()
+	params.MaybeAdd("name", args.Name)
+	params.MaybeAdd("description", args.Description)
+	source, err := z.controller.put(z.resourceURI, params.Values)
+	if err != nil {
+		if svrErr, ok := errors.Cause(err).(ServerError); ok {
+			switch svrErr.StatusCode {
+			case http.StatusNotFound:
+				return wrapError(err, NewNoMatchError(svrErr.BodyMessage))
+			case http.StatusForbidden:
+				return wrapError(err, NewPermissionError(svrErr.BodyMessage))
+			case http.StatusBadRequest:
+				return wrapError(err, NewBadRequestError(svrErr.BodyMessage))
+			}
+		}
+		return NewUnexpectedError(err)
+	}
+
+	response, err := readZone(z.controller.schemaVersion(), source)
+	if err != nil {
+		return errors.Trace(err)
+	}
+	z.updateFrom(response)
+	return nil
+}
+
+// Delete implements Zone.
+func (z *zone) Delete() error {
+	err := z.controller.delete(z.resourceURI)
+	if err != nil {
+		if svrErr, ok := errors.Cause(err).(ServerError); ok {
+			switch svrErr.StatusCode {
+			case http.StatusNotFound:
+				return wrapError(err, NewNoMatchError(svrErr.BodyMessage))
+			case http.StatusForbidden:
+				return wrapError(err, NewPermissionError(svrErr.BodyMessage))
+			case http.StatusBadRequest:
+				// MAAS refuses to delete the default zone.
+				return wrapError(err, NewBadRequestError(svrErr.BodyMessage))
+			}
+		}
+		return NewUnexpectedError(err)
+	}
+	return nil
+}
+
+func readZone(controllerVersion version.Number, source interface{}) (*zone, error) {
+	readFunc, err := getZoneDeserializationFunc(controllerVersion)
+	if err != nil {
+		return nil, errors.Trace(err)
+	}
+
+	checker := schema.StringMap(schema.Any())
+	coerced, err := checker.Coerce(source, nil)
+	if err != nil {
+		return nil, errors.Annotatef(err, "zone base schema check failed")
+	}
+	valid := coerced.(map[string]interface{})
+	return readFunc(valid)
+}
+
 func readZones(controllerVersion version.Number, source interface{}) ([]*zone, error) {
 	checker := schema.List(schema.StringMap(schema.Any()))
 	coerced, err := checker.Coerce(source, nil)
@@ -37,6 +121,14 @@ func readZones(controllerVersion version.Number, source interface{}) ([]*zone, e
 	}
 	valid := coerced.([]interface{})
 
+	readFunc, err := getZoneDeserializationFunc(controllerVersion)
+	if err != nil {
+		return nil, errors.Trace(err)
+	}
+	return readZoneList(valid, readFunc)
+}
+
+func getZoneDeserializationFunc(controllerVersion version.Number) (zoneDeserializationFunc, error) {
 	var deserialisationVersion version.Number
 	for v := range zoneDeserializationFuncs {
 		if v.Compare(deserialisationVersion) > 0 && v.Compare(controllerVersion) <= 0 {
@@ -46,8 +138,7 @@ func readZones(controllerVersion version.Number, source interface{}) ([]*zone, e
 	if deserialisationVersion == version.Zero {
 		return nil, errors.Errorf("no zone read func for version %s", controllerVersion)
 	}
-	readFunc := zoneDeserializationFuncs[deserialisationVersion]
-	return readZoneList(valid, readFunc)
+	return zoneDeserializationFuncs[deserialisationVersion], nil
 }
 
 // readZoneList expects the values of the sourceList to be string maps.
@@ -75,11 +166,16 @@ var zoneDeserializationFuncs = map[version.Number]zoneDeserializationFunc{
 
 func zone_2_0(source map[string]interface{}) (*zone, error) {
 	fields := schema.Fields{
+		"id":           schema.ForceInt(),
 		"name":         schema.String(),
 		"description":  schema.String(),
 		"resource_uri": schema.String(),
 	}
-	checker := schema.FieldMap(fields, nil) // no defaults
+	// The ID isn't included in the zones of older MAAS versions.
+	defaults := schema.Defaults{
+		"id": 0,
+	}
+	checker := schema.FieldMap(fields, defaults)
 	coerced, err := checker.Coerce(source, nil)
 	if err != nil {
 		return nil, errors.Annotatef(err, "zone 2.0 schema check failed")
@@ -89,6 +185,7 @@ func zone_2_0(source map[string]interface{}) (*zone, error) {
 	// contains fields of the right type.
 
 	result := &zone{
+		id:          valid["id"].(int),
 		name:        valid["name"].(string),
 		description: valid["description"].(string),
 		resourceURI: valid["resource_uri"].(string),
@@ -4,12 +4,18 @@
 package gomaasapi
 
 import (
+	"net/http"
+
+	"github.com/juju/errors"
+	"github.com/juju/testing"
 	jc "github.com/juju/testing/checkers"
 	"github.com/juju/version"
 	gc "gopkg.in/check.v1"
 )
 
-type zoneSuite struct{}
+type zoneSuite struct {
+	testing.LoggingCleanupSuite
+}
 
 var _ = gc.Suite(&zoneSuite{})
 
@@ -39,6 +45,86 @@ func (*zoneSuite) TestHighVersion(c *gc.C) {
 	c.Assert(zones, gc.HasLen, 2)
 }
 
+func (s *zoneSuite) getServerAndZone(c *gc.C) (*SimpleTestServer, Zone) {
+	server, controller := createTestServerController(c, s)
+	server.AddGetResponse("/api/2.0/zones/", http.StatusOK, zoneResponse)
+	zones, err := controller.Zones()
+	c.Assert(err, jc.ErrorIsNil)
+	c.Assert(zones, gc.HasLen, 2)
+	return server, zones[1]
+}
+
+func (s *zoneSuite) TestUpdate(c *gc.C) {
+	server, zone := s.getServerAndZone(c)
+	server.AddPutResponse("/MAAS/api/2.0/zones/special/", http.StatusOK, `
+{
+    "id": 2,
+    "description": "rack 2",
+    "resource_uri": "/MAAS/api/2.0/zones/rack2/",
+    "name": "rack2"
+}`)
+	err := zone.Update(UpdateZoneArgs{Name: "rack2", Description: "rack 2"})
+	c.Assert(err, jc.ErrorIsNil)
+	c.Check(zone.ID(), gc.Equals, 2)
+	c.Check(zone.Name(), gc.Equals, "rack2")
+	c.Check(zone.Description(), gc.Equals, "rack 2")
+
+	form := server.LastRequest().PostForm
+	c.Check(form.Get("name"), gc.Equals, "rack2")
+	c.Check(form.Get("description"), gc.Equals, "rack 2")
+
+	// The zone is found by its new name.
+	server.AddDeleteResponse("/MAAS/api/2.0/zones/rack2/", http.StatusNoContent, "")
+	err = zone.Delete()
+	c.Assert(err, jc.ErrorIsNil)
+}
+
+func (s *zoneSuite) TestUpdateNotFound(c *gc.C) {
+	server, zone := s.getServerAndZone(c)
+	server.AddPutResponse("/MAAS/api/2.0/zones/special/", http.StatusNotFound, "Not Found")
+	err := zone.Update(UpdateZoneArgs{Description: "gone"})
+	c.Assert(err, jc.Satisfies, IsNoMatchError)
+}
+
+func (s *zoneSuite) TestDeleteDefault(c *gc.C) {
+	server, zone := s.getServerAndZone(c)
+	server.AddDeleteResponse("/MAAS/api/2.0/zones/special/", http.StatusBadRequest, "This zone is the default zone, it cannot be deleted.")
+	err := zone.Delete()
+	c.Assert(err, jc.Satisfies, IsBadRequestError)
+}
+
+func (s *zoneSuite) TestCreateZone(c *gc.C) {
+	server, controller := createTestServerController(c, s)
+	server.AddPostResponse("/api/2.0/zones/?op=", http.StatusOK, `
+{
+    "id": 3,
+    "description": "rack 3",
+    "resource_uri": "/MAAS/api/2.0/zones/rack3/",
+    "name": "rack3"
+}`)
+	zone, err := controller.CreateZone(CreateZoneArgs{Name: "rack3", Description: "rack 3"})
+	c.Assert(err, jc.ErrorIsNil)
+	c.Check(zone.ID(), gc.Equals, 3)
+	c.Check(zone.Name(), gc.Equals, "rack3")
+
+	form := server.LastRequest().PostForm
+	c.Check(form.Get("name"), gc.Equals, "rack3")
+	c.Check(form.Get("description"), gc.Equals, "rack 3")
+}
+
+func (s *zoneSuite) TestCreateZoneValidates(c *gc.C) {
+	_, controller := createTestServerController(c, s)
+	_, err := controller.CreateZone(CreateZoneArgs{Description: "nameless"})
+	c.Assert(err, jc.Satisfies, errors.IsNotValid)
+}
+
+func (s *zoneSuite) TestCreateZoneBadRequest(c *gc.C) {
+	server, controller := createTestServerController(c, s)
+	server.AddPostResponse("/api/2.0/zones/?op=", http.StatusBadRequest, "Zone with this Name already exists.")
+	_, err := controller.CreateZone(CreateZoneArgs{Name: "default"})
+	c.Assert(err, jc.Satisfies, IsBadRequestError)
+}
+
 var zoneResponse = `
 [
     {